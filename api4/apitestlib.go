@@ -66,7 +66,7 @@ var testStore *persistentTestStore
 // complete (e.g. at the end of your TestMain implementation), you should call StopTestStore.
 func UseTestStore(container *storetest.RunningContainer, settings *model.SqlSettings) {
 	testStoreContainer = container
-	testStore = &persistentTestStore{store.NewLayeredStore(sqlstore.NewSqlSupplier(*settings, nil), nil, nil)}
+	testStore = &persistentTestStore{store.NewLayeredStore(sqlstore.NewSqlSupplier(*settings, nil), nil, nil, model.PLUGIN_SETTINGS_DEFAULT_KV_CACHE_SIZE, model.PLUGIN_SETTINGS_DEFAULT_KV_CACHE_SECONDS, 0)}
 }
 
 func StopTestStore() {
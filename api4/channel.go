@@ -32,6 +32,7 @@ func (api *API) InitChannel() {
 	api.BaseRoutes.Channel.Handle("", api.ApiSessionRequired(deleteChannel)).Methods("DELETE")
 	api.BaseRoutes.Channel.Handle("/stats", api.ApiSessionRequired(getChannelStats)).Methods("GET")
 	api.BaseRoutes.Channel.Handle("/pinned", api.ApiSessionRequired(getPinnedPosts)).Methods("GET")
+	api.BaseRoutes.Channel.Handle("/plugin_subscriptions", api.ApiSessionRequired(getChannelPluginSubscriptions)).Methods("GET")
 
 	api.BaseRoutes.ChannelForUser.Handle("/unread", api.ApiSessionRequired(getChannelUnread)).Methods("GET")
 
@@ -189,25 +190,13 @@ func convertChannelToPrivate(c *Context, w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if oldPublicChannel.Type == model.CHANNEL_PRIVATE {
-		c.Err = model.NewAppError("convertChannelToPrivate", "api.channel.convert_channel_to_private.private_channel_error", nil, "", http.StatusBadRequest)
-		return
-	}
-
-	if oldPublicChannel.Name == model.DEFAULT_CHANNEL {
-		c.Err = model.NewAppError("convertChannelToPrivate", "api.channel.convert_channel_to_private.default_channel_error", nil, "", http.StatusBadRequest)
-		return
-	}
-
 	var user *model.User
 	if user, err = c.App.GetUser(c.Session.UserId); err != nil {
 		c.Err = err
 		return
 	}
 
-	oldPublicChannel.Type = model.CHANNEL_PRIVATE
-
-	rchannel, err := c.App.UpdateChannelPrivacy(oldPublicChannel, user)
+	rchannel, err := c.App.ConvertChannelToPrivate(oldPublicChannel, user)
 	if err != nil {
 		c.Err = err
 		return
@@ -463,6 +452,26 @@ func getChannelStats(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(stats.ToJson()))
 }
 
+func getChannelPluginSubscriptions(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToChannel(c.Session, c.Params.ChannelId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	subscriptions, err := c.App.GetPluginSubscriptionsForChannel(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.PluginSubscriptionListToJson(subscriptions)))
+}
+
 func getPinnedPosts(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireChannelId()
 	if c.Err != nil {
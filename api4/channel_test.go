@@ -1534,6 +1534,52 @@ func TestGetChannelStats(t *testing.T) {
 	CheckNoError(t, resp)
 }
 
+func TestGetChannelPluginSubscriptions(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+	Client := th.Client
+	channel := th.CreatePrivateChannel()
+
+	subscription, err := th.App.CreatePluginSubscription("testplugin", &model.PluginSubscription{
+		ChannelId:   channel.Id,
+		CreatorId:   th.BasicUser.Id,
+		Description: "subscribed to a repository",
+		Payload:     []byte("secret"),
+	})
+	require.Nil(t, err)
+
+	subscriptions, resp := Client.GetChannelPluginSubscriptions(channel.Id)
+	CheckNoError(t, resp)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, subscription.Id, subscriptions[0].Id)
+	assert.Equal(t, "testplugin", subscriptions[0].PluginId)
+	assert.Equal(t, "subscribed to a repository", subscriptions[0].Description)
+	assert.Nil(t, subscriptions[0].Payload)
+
+	subscriptions, resp = Client.GetChannelPluginSubscriptions(th.BasicChannel.Id)
+	CheckNoError(t, resp)
+	assert.Empty(t, subscriptions)
+
+	_, resp = Client.GetChannelPluginSubscriptions("junk")
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.GetChannelPluginSubscriptions(model.NewId())
+	CheckForbiddenStatus(t, resp)
+
+	th.LoginBasic2()
+
+	_, resp = Client.GetChannelPluginSubscriptions(channel.Id)
+	CheckForbiddenStatus(t, resp)
+
+	Client.Logout()
+	_, resp = Client.GetChannelPluginSubscriptions(channel.Id)
+	CheckUnauthorizedStatus(t, resp)
+
+	subscriptions, resp = th.SystemAdminClient.GetChannelPluginSubscriptions(channel.Id)
+	CheckNoError(t, resp)
+	assert.Len(t, subscriptions, 1)
+}
+
 func TestGetPinnedPosts(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer th.TearDown()
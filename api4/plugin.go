@@ -6,7 +6,10 @@
 package api4
 
 import (
+	"io"
+	"io/ioutil"
 	"net/http"
+	"strconv"
 
 	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
@@ -14,6 +17,9 @@ import (
 
 const (
 	MAXIMUM_PLUGIN_FILE_SIZE = 50 * 1024 * 1024
+
+	// MAXIMUM_PLUGIN_FORM_OVERHEAD allows for the multipart encoding around an uploaded bundle.
+	MAXIMUM_PLUGIN_FORM_OVERHEAD = 1024 * 1024
 )
 
 func (api *API) InitPlugin() {
@@ -22,12 +28,31 @@ func (api *API) InitPlugin() {
 	api.BaseRoutes.Plugins.Handle("", api.ApiSessionRequired(uploadPlugin)).Methods("POST")
 	api.BaseRoutes.Plugins.Handle("", api.ApiSessionRequired(getPlugins)).Methods("GET")
 	api.BaseRoutes.Plugin.Handle("", api.ApiSessionRequired(removePlugin)).Methods("DELETE")
+	api.BaseRoutes.Plugin.Handle("/removal_preview", api.ApiSessionRequired(getPluginRemovalPreview)).Methods("GET")
+	api.BaseRoutes.Plugin.Handle("/data", api.ApiSessionRequired(exportPluginData)).Methods("GET")
+	api.BaseRoutes.Plugin.Handle("/data", api.ApiSessionRequired(importPluginData)).Methods("POST")
+	api.BaseRoutes.Plugin.Handle("/keys", api.ApiSessionRequired(getPluginKeys)).Methods("GET")
+	api.BaseRoutes.Plugin.Handle("/keys/{key:.+}", api.ApiSessionRequired(getPluginKey)).Methods("GET")
+	api.BaseRoutes.Plugin.Handle("/events", api.ApiSessionRequired(getPluginEvents)).Methods("GET")
+	api.BaseRoutes.Plugin.Handle("/webapp_replies/{webapp_reply_id:[A-Za-z0-9]+}", api.ApiSessionRequired(replyToPluginWebappMessage)).Methods("POST")
+
+	api.BaseRoutes.Plugins.Handle("/validate", api.ApiSessionRequired(validatePlugin)).Methods("POST")
+	api.BaseRoutes.Plugins.Handle("/scaffold", api.ApiSessionRequired(scaffoldPlugin)).Methods("POST")
 
 	api.BaseRoutes.Plugins.Handle("/statuses", api.ApiSessionRequired(getPluginStatuses)).Methods("GET")
 	api.BaseRoutes.Plugin.Handle("/enable", api.ApiSessionRequired(enablePlugin)).Methods("POST")
 	api.BaseRoutes.Plugin.Handle("/disable", api.ApiSessionRequired(disablePlugin)).Methods("POST")
+	api.BaseRoutes.Plugins.Handle("/states", api.ApiSessionRequired(setPluginStates)).Methods("POST")
+	api.BaseRoutes.Plugins.Handle("/remove_broken", api.ApiSessionRequired(removeBrokenPlugin)).Methods("POST")
 
 	api.BaseRoutes.Plugins.Handle("/webapp", api.ApiHandler(getWebappPlugins)).Methods("GET")
+
+	api.BaseRoutes.Plugins.Handle("/specs", api.ApiSessionRequired(getPluginApiSpecs)).Methods("GET")
+	api.BaseRoutes.Plugin.Handle("/api_spec", api.ApiSessionRequired(getPluginApiSpec)).Methods("GET")
+
+	api.BaseRoutes.Plugins.Handle("/admin_actions", api.ApiSessionRequired(getPluginAdminActions)).Methods("GET")
+	api.BaseRoutes.Plugins.Handle("/admin_actions/{admin_action_request_id:[A-Za-z0-9]+}/approve", api.ApiSessionRequired(approvePluginAdminAction)).Methods("POST")
+	api.BaseRoutes.Plugins.Handle("/admin_actions/{admin_action_request_id:[A-Za-z0-9]+}/deny", api.ApiSessionRequired(denyPluginAdminAction)).Methods("POST")
 }
 
 func uploadPlugin(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -41,6 +66,13 @@ func uploadPlugin(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	maxBundleSize := *c.App.Config().PluginSettings.MaxBundleSize
+	if r.ContentLength > maxBundleSize+MAXIMUM_PLUGIN_FORM_OVERHEAD {
+		c.Err = model.NewAppError("uploadPlugin", "app.plugin.install.bundle_too_large.app_error", map[string]interface{}{"Max": maxBundleSize}, "", http.StatusRequestEntityTooLarge)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBundleSize+MAXIMUM_PLUGIN_FORM_OVERHEAD)
+
 	if err := r.ParseMultipartForm(MAXIMUM_PLUGIN_FILE_SIZE); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -66,15 +98,123 @@ func uploadPlugin(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	manifest, unpackErr := c.App.InstallPlugin(file, false)
+	// A detached signature of the bundle may be uploaded along with it.
+	var signature io.Reader
+	if signatureArray, ok := m.File["signature"]; ok && len(signatureArray) > 0 {
+		signatureFile, err := signatureArray[0].Open()
+		if err != nil {
+			c.Err = model.NewAppError("uploadPlugin", "api.plugin.upload.signature.app_error", nil, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer signatureFile.Close()
+		signature = signatureFile
+	}
+
+	// An installed plugin with the same id is only replaced when explicitly requested.
+	force := false
+	if values, ok := m.Value["force"]; ok && len(values) > 0 {
+		force = values[0] == "true"
+	}
+
+	result, unpackErr := c.App.InstallPluginWithWarnings(file, signature, force)
 
 	if unpackErr != nil {
 		c.Err = unpackErr
 		return
 	}
 
+	for _, warning := range result.Warnings {
+		warning.Translate(c.T)
+	}
+
 	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte(manifest.ToJson()))
+	w.Write([]byte(result.ToJson()))
+}
+
+func validatePlugin(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !*c.App.Config().PluginSettings.Enable {
+		c.Err = model.NewAppError("validatePlugin", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	maxBundleSize := *c.App.Config().PluginSettings.MaxBundleSize
+	if r.ContentLength > maxBundleSize+MAXIMUM_PLUGIN_FORM_OVERHEAD {
+		c.Err = model.NewAppError("validatePlugin", "app.plugin.install.bundle_too_large.app_error", map[string]interface{}{"Max": maxBundleSize}, "", http.StatusRequestEntityTooLarge)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBundleSize+MAXIMUM_PLUGIN_FORM_OVERHEAD)
+
+	if err := r.ParseMultipartForm(MAXIMUM_PLUGIN_FILE_SIZE); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pluginArray, ok := r.MultipartForm.File["plugin"]
+	if !ok {
+		c.Err = model.NewAppError("validatePlugin", "api.plugin.upload.no_file.app_error", nil, "", http.StatusBadRequest)
+		return
+	}
+
+	if len(pluginArray) <= 0 {
+		c.Err = model.NewAppError("validatePlugin", "api.plugin.upload.array.app_error", nil, "", http.StatusBadRequest)
+		return
+	}
+
+	file, err := pluginArray[0].Open()
+	if err != nil {
+		c.Err = model.NewAppError("validatePlugin", "api.plugin.upload.file.app_error", nil, "", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	report, appErr := c.App.ValidatePlugin(file)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	for _, finding := range report.Findings {
+		finding.Translate(c.T)
+	}
+	for _, warning := range report.Warnings {
+		warning.Translate(c.T)
+	}
+
+	w.Write([]byte(report.ToJson()))
+}
+
+func scaffoldPlugin(c *Context, w http.ResponseWriter, r *http.Request) {
+	// Scaffolds are only generated for plugin developers working against their own server.
+	if !*c.App.Config().ServiceSettings.EnableDeveloper {
+		c.Err = model.NewAppError("scaffoldPlugin", "api.plugin.scaffold.developer_mode.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	request := model.PluginScaffoldRequestFromJson(r.Body)
+	if request == nil {
+		c.SetInvalidParam("scaffold_request")
+		return
+	}
+
+	archive, err := c.App.ScaffoldPluginArchive(request)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", "attachment;filename=\""+request.PluginId+".tar.gz\"")
+	w.Write(archive)
 }
 
 func getPlugins(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -94,6 +234,10 @@ func getPlugins(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	for _, info := range append(response.Active, response.Inactive...) {
+		info.StateReasonMessage = model.PluginStateReasonMessage(info.StateReason, c.T)
+	}
+
 	w.Write([]byte(response.ToJson()))
 }
 
@@ -114,6 +258,10 @@ func getPluginStatuses(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	for _, status := range response {
+		status.StateReasonMessage = model.PluginStateReasonMessage(status.StateReason, c.T)
+	}
+
 	w.Write([]byte(response.ToJson()))
 }
 
@@ -133,21 +281,265 @@ func removePlugin(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := c.App.RemovePlugin(c.Params.PluginId)
+	deleteData := false
+	if value := r.URL.Query().Get("delete_data"); value != "" {
+		var parseErr error
+		if deleteData, parseErr = strconv.ParseBool(value); parseErr != nil {
+			c.SetInvalidUrlParam("delete_data")
+			return
+		}
+	}
+
+	summary, err := c.App.RemovePluginWithOptions(c.Params.PluginId, deleteData)
 	if err != nil {
 		c.Err = err
 		return
 	}
 
+	c.LogAudit("removed " + summary.ToJson())
+
 	ReturnStatusOK(w)
 }
 
+func removeBrokenPlugin(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !*c.App.Config().PluginSettings.Enable {
+		c.Err = model.NewAppError("removeBrokenPlugin", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+	path := props["path"]
+	if path == "" {
+		c.SetInvalidParam("path")
+		return
+	}
+
+	if err := c.App.RemoveBrokenPlugin(path); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("removed broken plugin " + path)
+
+	ReturnStatusOK(w)
+}
+
+func getPluginRemovalPreview(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePluginId()
+	if c.Err != nil {
+		return
+	}
+
+	if !*c.App.Config().PluginSettings.Enable {
+		c.Err = model.NewAppError("getPluginRemovalPreview", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	inventory, err := c.App.GetPluginRemovalPreview(c.Params.PluginId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(inventory.ToJson()))
+}
+
+func exportPluginData(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePluginId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	data, err := c.App.ExportPluginData(c.Params.PluginId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+	defer data.Close()
+
+	c.LogAudit("plugin_id=" + c.Params.PluginId)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", "attachment;filename=\""+c.Params.PluginId+"_data.jsonl\"")
+	if _, err := io.Copy(w, data); err != nil {
+		// The response has already begun, so the failure can only be logged.
+		mlog.Error("Failed to export plugin data", mlog.String("plugin_id", c.Params.PluginId), mlog.Err(err))
+	}
+}
+
+func importPluginData(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePluginId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	result, err := c.App.ImportPluginDataFromReader(c.Params.PluginId, r.Body)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	for _, rowErr := range result.Errors {
+		rowErr.Error.Translate(c.T)
+	}
+
+	c.LogAudit("plugin_id=" + c.Params.PluginId + " imported=" + strconv.Itoa(result.Imported) + " errors=" + strconv.Itoa(len(result.Errors)))
+
+	w.Write([]byte(result.ToJson()))
+}
+
+func getPluginKeys(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePluginId()
+	if c.Err != nil {
+		return
+	}
+
+	if !*c.App.Config().PluginSettings.Enable {
+		c.Err = model.NewAppError("getPluginKeys", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	keys, err := c.App.ListPluginKeys(c.Params.PluginId, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("plugin_id=" + c.Params.PluginId + " page=" + strconv.Itoa(c.Params.Page) + " per_page=" + strconv.Itoa(c.Params.PerPage))
+
+	w.Write([]byte(model.ArrayToJson(keys)))
+}
+
+func getPluginEvents(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePluginId()
+	if c.Err != nil {
+		return
+	}
+
+	var since int64
+	if sinceString := r.URL.Query().Get("since"); len(sinceString) > 0 {
+		var err error
+		if since, err = strconv.ParseInt(sinceString, 10, 64); err != nil {
+			c.SetInvalidParam("since")
+			return
+		}
+	}
+
+	if !*c.App.Config().PluginSettings.Enable {
+		c.Err = model.NewAppError("getPluginEvents", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	events, err := c.App.GetPluginEvents(c.Params.PluginId, since, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.PluginEventListToJson(events)))
+}
+
+func replyToPluginWebappMessage(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePluginId().RequireWebappReplyId()
+	if c.Err != nil {
+		return
+	}
+
+	if !*c.App.Config().PluginSettings.Enable {
+		c.Err = model.NewAppError("replyToPluginWebappMessage", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	// One byte more than allowed is read so that oversized replies are rejected rather than truncated.
+	payload, err := ioutil.ReadAll(io.LimitReader(r.Body, model.PLUGIN_WEBAPP_MESSAGE_PAYLOAD_MAX_SIZE+1))
+	if err != nil {
+		c.Err = model.NewAppError("replyToPluginWebappMessage", "api.plugin.webapp_reply.read.app_error", nil, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if appErr := c.App.ReceivePluginWebappReply(c.Params.PluginId, c.Session.UserId, c.Params.WebappReplyId, payload); appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+func getPluginKey(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePluginId().RequirePluginKey()
+	if c.Err != nil {
+		return
+	}
+
+	if !*c.App.Config().PluginSettings.Enable {
+		c.Err = model.NewAppError("getPluginKey", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	value, exists, err := c.App.GetPluginKeyWithExists(c.Params.PluginId, c.Params.PluginKey)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("plugin_id=" + c.Params.PluginId + " key=" + c.Params.PluginKey)
+
+	if !exists {
+		c.Err = model.NewAppError("getPluginKey", "api.plugin.get_key.not_found.app_error", nil, "plugin_id="+c.Params.PluginId, http.StatusNotFound)
+		return
+	}
+
+	entry := &model.PluginDataEntry{Key: c.Params.PluginKey, Value: value}
+	w.Write([]byte(entry.ToJson()))
+}
+
 func getWebappPlugins(c *Context, w http.ResponseWriter, r *http.Request) {
 	if !*c.App.Config().PluginSettings.Enable {
 		c.Err = model.NewAppError("getWebappPlugins", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
 		return
 	}
 
+	teamId := r.URL.Query().Get("team_id")
+	if teamId != "" && !model.IsValidId(teamId) {
+		c.SetInvalidUrlParam("team_id")
+		return
+	}
+
 	manifests, err := c.App.GetActivePluginManifests()
 	if err != nil {
 		c.Err = err
@@ -155,7 +547,7 @@ func getWebappPlugins(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 
 	clientManifests := []*model.Manifest{}
-	for _, m := range manifests {
+	for _, m := range c.App.FilterPluginManifestsForUser(manifests, c.Session.UserId, teamId) {
 		if m.HasClient() {
 			clientManifests = append(clientManifests, m.ClientManifest())
 		}
@@ -211,3 +603,131 @@ func disablePlugin(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	ReturnStatusOK(w)
 }
+
+func setPluginStates(c *Context, w http.ResponseWriter, r *http.Request) {
+	states := model.MapBoolFromJson(r.Body)
+	if len(states) == 0 {
+		c.SetInvalidParam("states")
+		return
+	}
+
+	if !*c.App.Config().PluginSettings.Enable {
+		c.Err = model.NewAppError("setPluginStates", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	results, err := c.App.SetPluginStates(states)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(results.ToJson()))
+}
+
+func getPluginApiSpec(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePluginId()
+	if c.Err != nil {
+		return
+	}
+
+	if !*c.App.Config().PluginSettings.Enable {
+		c.Err = model.NewAppError("getPluginApiSpec", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	spec, etag, err := c.App.GetPluginApiSpec(c.Params.PluginId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if c.HandleEtag(etag, "Get Plugin API Spec", w, r) {
+		return
+	}
+
+	w.Header().Set(model.HEADER_ETAG_SERVER, etag)
+	w.Header().Set("Cache-Control", "no-cache, private")
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(spec)
+}
+
+func getPluginApiSpecs(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !*c.App.Config().PluginSettings.Enable {
+		c.Err = model.NewAppError("getPluginApiSpecs", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	specs, err := c.App.GetPluginApiSpecs()
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.PluginApiSpecListToJson(specs)))
+}
+
+func getPluginAdminActions(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !*c.App.Config().PluginSettings.Enable {
+		c.Err = model.NewAppError("getPluginAdminActions", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	requests, err := c.App.GetPendingPluginAdminActions(c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.PluginAdminActionRequestListToJson(requests)))
+}
+
+func approvePluginAdminAction(c *Context, w http.ResponseWriter, r *http.Request) {
+	resolvePluginAdminAction(c, w, r, true)
+}
+
+func denyPluginAdminAction(c *Context, w http.ResponseWriter, r *http.Request) {
+	resolvePluginAdminAction(c, w, r, false)
+}
+
+func resolvePluginAdminAction(c *Context, w http.ResponseWriter, r *http.Request, approve bool) {
+	c.RequireAdminActionRequestId()
+	if c.Err != nil {
+		return
+	}
+
+	if !*c.App.Config().PluginSettings.Enable {
+		c.Err = model.NewAppError("resolvePluginAdminAction", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	request, err := c.App.ResolvePluginAdminAction(c.Params.AdminActionRequestId, approve, c.Session.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("request_id=" + request.Id + " plugin_id=" + request.PluginId + " action=" + request.Action + " params=" + model.MapToJson(request.Params) + " status=" + request.Status)
+
+	w.Write([]byte(request.ToJson()))
+}
@@ -4,15 +4,27 @@
 package api4
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPlugin(t *testing.T) {
@@ -51,6 +63,17 @@ func TestPlugin(t *testing.T) {
 
 	assert.Equal(t, "testplugin", manifest.Id)
 
+	// Uploading an installed plugin again replaces it only when forced
+	bundle, err := ioutil.ReadFile(filepath.Join(path, "testplugin.tar.gz"))
+	require.NoError(t, err)
+
+	_, resp = th.SystemAdminClient.UploadPlugin(bytes.NewReader(bundle))
+	CheckBadRequestStatus(t, resp)
+
+	manifest, resp = th.SystemAdminClient.UploadPluginForced(bytes.NewReader(bundle))
+	CheckNoError(t, resp)
+	assert.Equal(t, "testplugin", manifest.Id)
+
 	// Upload error cases
 	_, resp = th.SystemAdminClient.UploadPlugin(bytes.NewReader([]byte("badfile")))
 	CheckBadRequestStatus(t, resp)
@@ -165,6 +188,44 @@ func TestPlugin(t *testing.T) {
 
 	assert.True(t, found)
 
+	// Webapp plugins restricted to other teams are hidden
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		cfg.PluginSettings.PluginTeamRestrictions = map[string][]string{manifest.Id: {model.NewId()}}
+	})
+
+	manifests, resp = th.Client.GetWebappPlugins()
+	CheckNoError(t, resp)
+	for _, m := range manifests {
+		assert.NotEqual(t, manifest.Id, m.Id)
+	}
+
+	manifests, resp = th.Client.GetWebappPluginsForTeam(th.BasicTeam.Id)
+	CheckNoError(t, resp)
+	for _, m := range manifests {
+		assert.NotEqual(t, manifest.Id, m.Id)
+	}
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		cfg.PluginSettings.PluginTeamRestrictions = map[string][]string{manifest.Id: {th.BasicTeam.Id}}
+	})
+
+	manifests, resp = th.Client.GetWebappPluginsForTeam(th.BasicTeam.Id)
+	CheckNoError(t, resp)
+	found = false
+	for _, m := range manifests {
+		if m.Id == manifest.Id {
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	_, resp = th.Client.GetWebappPluginsForTeam("junk")
+	CheckBadRequestStatus(t, resp)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		cfg.PluginSettings.PluginTeamRestrictions = map[string][]string{}
+	})
+
 	// Successful remove
 	ok, resp = th.SystemAdminClient.RemovePlugin(manifest.Id)
 	CheckNoError(t, resp)
@@ -186,3 +247,727 @@ func TestPlugin(t *testing.T) {
 	_, resp = th.SystemAdminClient.RemovePlugin("bad.id")
 	CheckBadRequestStatus(t, resp)
 }
+
+func TestUploadPluginWithSignature(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicKey, err := x509.MarshalPKIXPublicKey(&signingKey.PublicKey)
+	require.NoError(t, err)
+	keyFile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(keyFile.Name())
+	require.NoError(t, pem.Encode(keyFile, &pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+	require.NoError(t, keyFile.Close())
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.EnableUploads = true
+		cfg.PluginSettings.SignaturePublicKeyFiles = []string{keyFile.Name()}
+		*cfg.PluginSettings.RequirePluginSignature = true
+	})
+
+	path, _ := utils.FindDir("tests")
+	bundle, err := ioutil.ReadFile(filepath.Join(path, "testplugin.tar.gz"))
+	require.NoError(t, err)
+	digest := sha512.Sum512(bundle)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, signingKey, crypto.SHA512, digest[:])
+	require.NoError(t, err)
+
+	_, resp := th.SystemAdminClient.UploadPlugin(bytes.NewReader(bundle))
+	CheckBadRequestStatus(t, resp)
+	assert.Equal(t, "app.plugin.signature.missing.app_error", resp.Error.Id)
+
+	tampered := append(append([]byte{}, bundle...), 0)
+	_, resp = th.SystemAdminClient.UploadPluginWithSignature(bytes.NewReader(tampered), bytes.NewReader(signature), false)
+	CheckBadRequestStatus(t, resp)
+	assert.Equal(t, "app.plugin.signature.invalid.app_error", resp.Error.Id)
+
+	manifest, resp := th.SystemAdminClient.UploadPluginWithSignature(bytes.NewReader(bundle), bytes.NewReader(signature), false)
+	CheckNoError(t, resp)
+	assert.Equal(t, "testplugin", manifest.Id)
+
+	_, resp = th.SystemAdminClient.RemovePlugin(manifest.Id)
+	CheckNoError(t, resp)
+}
+
+func TestValidatePlugin(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+
+	enablePlugins := *th.App.Config().PluginSettings.Enable
+	defer th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = enablePlugins })
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = true })
+
+	path, _ := utils.FindDir("tests")
+	bundle, err := ioutil.ReadFile(filepath.Join(path, "testplugin.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, resp := th.SystemAdminClient.ValidatePlugin(bytes.NewReader(bundle))
+	CheckNoError(t, resp)
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Findings)
+	assert.Equal(t, "testplugin", report.Manifest.Id)
+
+	// Validating must not install the plugin
+	pluginsResp, resp := th.SystemAdminClient.GetPlugins()
+	CheckNoError(t, resp)
+	for _, m := range append(pluginsResp.Active, pluginsResp.Inactive...) {
+		assert.NotEqual(t, "testplugin", m.Id)
+	}
+
+	report, resp = th.SystemAdminClient.ValidatePlugin(bytes.NewReader([]byte("badfile")))
+	CheckNoError(t, resp)
+	assert.False(t, report.Valid)
+	if assert.Len(t, report.Findings, 1) {
+		assert.Equal(t, "app.plugin.extract.app_error", report.Findings[0].Id)
+	}
+
+	_, resp = th.Client.ValidatePlugin(bytes.NewReader(bundle))
+	CheckForbiddenStatus(t, resp)
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = false })
+	_, resp = th.SystemAdminClient.ValidatePlugin(bytes.NewReader(bundle))
+	CheckNotImplementedStatus(t, resp)
+}
+
+func TestScaffoldPlugin(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+
+	enableDeveloper := *th.App.Config().ServiceSettings.EnableDeveloper
+	defer th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableDeveloper = enableDeveloper })
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableDeveloper = true })
+
+	request := &model.PluginScaffoldRequest{
+		PluginId:     "com.example.scaffold",
+		Hooks:        []string{"OnActivate", "MessageWillBePosted"},
+		Capabilities: []string{model.PLUGIN_CAPABILITY_POSTS_WRITE},
+	}
+
+	archive, resp := th.SystemAdminClient.ScaffoldPlugin(request)
+	CheckNoError(t, resp)
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	require.NoError(t, err)
+	tarReader := tar.NewReader(gzipReader)
+	names := []string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, header.Name)
+	}
+	assert.Equal(t, []string{
+		"com.example.scaffold/Makefile",
+		"com.example.scaffold/go.mod",
+		"com.example.scaffold/plugin.json",
+		"com.example.scaffold/server/main.go",
+	}, names)
+
+	_, resp = th.SystemAdminClient.ScaffoldPlugin(&model.PluginScaffoldRequest{PluginId: "com.example.scaffold", Hooks: []string{"MessageWillBeDeleted"}})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.ScaffoldPlugin(&model.PluginScaffoldRequest{PluginId: "../scaffold"})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = th.Client.ScaffoldPlugin(request)
+	CheckForbiddenStatus(t, resp)
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableDeveloper = false })
+	_, resp = th.SystemAdminClient.ScaffoldPlugin(request)
+	CheckNotImplementedStatus(t, resp)
+}
+
+func TestPluginRemovalPreview(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+
+	enablePlugins := *th.App.Config().PluginSettings.Enable
+	enableUploadPlugins := *th.App.Config().PluginSettings.EnableUploads
+	defer th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = enablePlugins
+		*cfg.PluginSettings.EnableUploads = enableUploadPlugins
+	})
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.EnableUploads = true
+	})
+
+	path, _ := utils.FindDir("tests")
+	bundle, err := ioutil.ReadFile(filepath.Join(path, "testplugin.tar.gz"))
+	require.NoError(t, err)
+
+	install := func() {
+		manifest, resp := th.SystemAdminClient.UploadPlugin(bytes.NewReader(bundle))
+		CheckNoError(t, resp)
+		require.Equal(t, "testplugin", manifest.Id)
+		require.Nil(t, th.App.SetPluginKey("testplugin", "key", []byte("value")))
+	}
+
+	install()
+
+	inventory, resp := th.SystemAdminClient.GetPluginRemovalPreview("testplugin")
+	CheckNoError(t, resp)
+	assert.Equal(t, "testplugin", inventory.PluginId)
+	assert.NotEmpty(t, inventory.BundlePath)
+	assert.True(t, inventory.BundleSize > 0)
+	assert.NotEmpty(t, inventory.WebappPath)
+	assert.Equal(t, int64(1), inventory.KeyValueUsage.KeyCount)
+	assert.Equal(t, int64(len("value")), inventory.KeyValueUsage.Size)
+	assert.False(t, inventory.DataDeleted)
+
+	_, resp = th.Client.GetPluginRemovalPreview("testplugin")
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetPluginRemovalPreview("notinstalled")
+	CheckBadRequestStatus(t, resp)
+
+	// Previewing must not remove anything
+	_, err = os.Stat(inventory.BundlePath)
+	assert.NoError(t, err)
+
+	// A mistyped delete_data is refused rather than taken to keep the data
+	r, appErr := th.SystemAdminClient.DoApiDelete(th.SystemAdminClient.GetPluginRoute("testplugin") + "?delete_data=ture")
+	CheckBadRequestStatus(t, model.BuildErrorResponse(r, appErr))
+	_, err = os.Stat(inventory.BundlePath)
+	assert.NoError(t, err)
+
+	// Removing without deleting data orphans the key-value pairs
+	ok, resp := th.SystemAdminClient.RemovePluginWithData("testplugin", false)
+	CheckNoError(t, resp)
+	assert.True(t, ok)
+
+	_, err = os.Stat(inventory.BundlePath)
+	assert.True(t, os.IsNotExist(err))
+
+	value, appErr := th.App.GetPluginKey("testplugin", "key")
+	require.Nil(t, appErr)
+	assert.Equal(t, []byte("value"), value)
+
+	// Removing and deleting data purges them
+	install()
+
+	ok, resp = th.SystemAdminClient.RemovePluginWithData("testplugin", true)
+	CheckNoError(t, resp)
+	assert.True(t, ok)
+
+	value, appErr = th.App.GetPluginKey("testplugin", "key")
+	require.Nil(t, appErr)
+	assert.Nil(t, value)
+}
+
+func TestRemoveBrokenPlugin(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+
+	enablePlugins := *th.App.Config().PluginSettings.Enable
+	defer th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = enablePlugins })
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = true })
+
+	pluginDir := *th.App.Config().PluginSettings.Directory
+	corrupt := filepath.Join(pluginDir, "corrupt")
+	require.NoError(t, os.MkdirAll(corrupt, 0700))
+	defer os.RemoveAll(corrupt)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(corrupt, "plugin.json"), []byte(`{"id": `), 0600))
+
+	plugins, resp := th.SystemAdminClient.GetPlugins()
+	CheckNoError(t, resp)
+	require.Len(t, plugins.Errors, 1)
+	assert.Equal(t, corrupt, plugins.Errors[0].Path)
+	assert.NotEmpty(t, plugins.Errors[0].Reason)
+	assert.Equal(t, len(plugins.Active)+len(plugins.Inactive)+1, plugins.Limits.InstalledPlugins)
+
+	statuses, resp := th.SystemAdminClient.GetPluginStatuses()
+	CheckNoError(t, resp)
+	found := false
+	for _, status := range statuses {
+		if status.PluginPath == corrupt {
+			found = true
+			assert.Equal(t, model.PluginStateFailedToLoad, status.State)
+			assert.NotEmpty(t, status.Error)
+		}
+	}
+	assert.True(t, found)
+
+	_, resp = th.Client.RemoveBrokenPlugin(corrupt)
+	CheckForbiddenStatus(t, resp)
+
+	// Only directories that failed to load may be removed
+	_, resp = th.SystemAdminClient.RemoveBrokenPlugin(pluginDir)
+	CheckBadRequestStatus(t, resp)
+
+	ok, resp := th.SystemAdminClient.RemoveBrokenPlugin(corrupt)
+	CheckNoError(t, resp)
+	assert.True(t, ok)
+
+	_, err := os.Stat(corrupt)
+	assert.True(t, os.IsNotExist(err))
+
+	plugins, resp = th.SystemAdminClient.GetPlugins()
+	CheckNoError(t, resp)
+	assert.Empty(t, plugins.Errors)
+}
+
+func TestExportImportPluginData(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+
+	pluginId := "com.example." + model.NewId()
+	otherPluginId := "com.example." + model.NewId()
+	defer func() {
+		th.App.Srv.Store.Plugin().DeleteAllForPlugin(pluginId)
+		th.App.Srv.Store.Plugin().DeleteAllForPlugin(otherPluginId)
+	}()
+
+	require.Nil(t, th.App.SetPluginKey(pluginId, "key", []byte{0, 1, 2, 255}))
+
+	_, resp := th.Client.ExportPluginData(pluginId)
+	CheckForbiddenStatus(t, resp)
+
+	data, resp := th.SystemAdminClient.ExportPluginData(pluginId)
+	CheckNoError(t, resp)
+	assert.Equal(t, `{"key":"key","value":"AAEC/w=="}`+"\n", string(data))
+
+	_, resp = th.Client.ImportPluginData(otherPluginId, data)
+	CheckForbiddenStatus(t, resp)
+
+	result, resp := th.SystemAdminClient.ImportPluginData(otherPluginId, append(data, []byte("not json\n")...))
+	CheckNoError(t, resp)
+	assert.Equal(t, 1, result.Imported)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, 2, result.Errors[0].Line)
+	assert.Equal(t, "app.plugin.import_data.decode.app_error", result.Errors[0].Error.Id)
+	assert.NotEqual(t, result.Errors[0].Error.Id, result.Errors[0].Error.Message)
+
+	value, appErr := th.App.GetPluginKey(otherPluginId, "key")
+	require.Nil(t, appErr)
+	assert.Equal(t, []byte{0, 1, 2, 255}, value)
+}
+
+func TestGetPluginKeys(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+
+	pluginId := "com.example." + model.NewId()
+	defer func() {
+		th.App.Srv.Store.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	require.Nil(t, th.App.SetPluginKey(pluginId, "a", []byte{0, 1, 2, 255}))
+	require.Nil(t, th.App.SetPluginKey(pluginId, "b", []byte("value")))
+	require.Nil(t, th.App.SetPluginKey(pluginId, "with/slash", []byte("slashed")))
+
+	_, resp := th.Client.GetPluginKeys(pluginId, 0, 10)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.Client.GetPluginKey(pluginId, "a")
+	CheckForbiddenStatus(t, resp)
+
+	keys, resp := th.SystemAdminClient.GetPluginKeys(pluginId, 0, 10)
+	CheckNoError(t, resp)
+	assert.Equal(t, []string{"a", "b", "with/slash"}, keys)
+
+	keys, resp = th.SystemAdminClient.GetPluginKeys(pluginId, 1, 2)
+	CheckNoError(t, resp)
+	assert.Equal(t, []string{"with/slash"}, keys)
+
+	entry, resp := th.SystemAdminClient.GetPluginKey(pluginId, "a")
+	CheckNoError(t, resp)
+	assert.Equal(t, &model.PluginDataEntry{Key: "a", Value: []byte{0, 1, 2, 255}}, entry)
+
+	entry, resp = th.SystemAdminClient.GetPluginKey(pluginId, "with/slash")
+	CheckNoError(t, resp)
+	assert.Equal(t, []byte("slashed"), entry.Value)
+
+	_, resp = th.SystemAdminClient.GetPluginKey(pluginId, "missing")
+	CheckNotFoundStatus(t, resp)
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = false })
+	defer th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = true })
+
+	_, resp = th.SystemAdminClient.GetPluginKeys(pluginId, 0, 10)
+	CheckNotImplementedStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetPluginKey(pluginId, "a")
+	CheckNotImplementedStatus(t, resp)
+}
+
+func TestGetPluginEvents(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+
+	pluginId := "com.example." + model.NewId()
+	now := model.GetMillis()
+	for i, eventType := range []string{model.PLUGIN_EVENT_INSTALLED, model.PLUGIN_EVENT_ACTIVATED, model.PLUGIN_EVENT_FAILED} {
+		result := <-th.App.Srv.Store.PluginEvent().Save(&model.PluginEvent{
+			PluginId: pluginId,
+			Type:     eventType,
+			CreateAt: now + int64(i),
+		})
+		require.Nil(t, result.Err)
+	}
+
+	_, resp := th.Client.GetPluginEvents(pluginId, 0, 0, 10)
+	CheckForbiddenStatus(t, resp)
+
+	events, resp := th.SystemAdminClient.GetPluginEvents(pluginId, 0, 0, 10)
+	CheckNoError(t, resp)
+	require.Len(t, events, 3)
+	assert.Equal(t, model.PLUGIN_EVENT_INSTALLED, events[0].Type)
+	assert.Equal(t, model.PLUGIN_EVENT_FAILED, events[2].Type)
+
+	events, resp = th.SystemAdminClient.GetPluginEvents(pluginId, now+1, 0, 10)
+	CheckNoError(t, resp)
+	require.Len(t, events, 2)
+	assert.Equal(t, model.PLUGIN_EVENT_ACTIVATED, events[0].Type)
+
+	events, resp = th.SystemAdminClient.GetPluginEvents(pluginId, 0, 1, 2)
+	CheckNoError(t, resp)
+	require.Len(t, events, 1)
+	assert.Equal(t, model.PLUGIN_EVENT_FAILED, events[0].Type)
+
+	r, err := th.SystemAdminClient.DoApiGet(th.SystemAdminClient.GetPluginRoute(pluginId)+"/events?since=yesterday", "")
+	require.NotNil(t, err)
+	CheckBadRequestStatus(t, model.BuildErrorResponse(r, err))
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = false })
+	defer th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = true })
+
+	_, resp = th.SystemAdminClient.GetPluginEvents(pluginId, 0, 0, 10)
+	CheckNotImplementedStatus(t, resp)
+}
+
+func TestReplyToPluginWebappMessage(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	pluginId := "com.example." + model.NewId()
+	message := &model.PluginWebappMessage{Type: "confirm", Payload: []byte(`{"question": "Proceed?"}`)}
+
+	_, err := th.App.SendPluginWebappMessage(pluginId, th.BasicUser.Id, message)
+	require.NotNil(t, err)
+	assert.Equal(t, "app.plugin.webapp_message.not_connected.app_error", err.Id)
+
+	WebSocketClient, wsErr := th.CreateWebSocketClient()
+	require.Nil(t, wsErr)
+	defer WebSocketClient.Close()
+	WebSocketClient.Listen()
+	time.Sleep(300 * time.Millisecond)
+	resp := <-WebSocketClient.ResponseChannel
+	require.Equal(t, model.STATUS_OK, resp.Status)
+
+	replyId, err := th.App.SendPluginWebappMessage(pluginId, th.BasicUser.Id, message)
+	require.Nil(t, err)
+
+	timeout := time.After(time.Second)
+	for received := false; !received; {
+		select {
+		case event := <-WebSocketClient.EventChannel:
+			if event.Event != model.WEBSOCKET_EVENT_PLUGIN_WEBAPP_MESSAGE {
+				continue
+			}
+			assert.Equal(t, pluginId, event.Data["plugin_id"])
+			assert.Equal(t, replyId, event.Data["reply_id"])
+			assert.Equal(t, "confirm", event.Data["type"])
+			assert.Equal(t, `{"question": "Proceed?"}`, event.Data["payload"])
+			received = true
+		case <-timeout:
+			require.Fail(t, "timed out waiting for the plugin webapp message")
+		}
+	}
+
+	_, apiResp := th.Client.ReplyToPluginWebappMessage(pluginId, replyId, make([]byte, model.PLUGIN_WEBAPP_MESSAGE_PAYLOAD_MAX_SIZE+1))
+	CheckErrorMessage(t, apiResp, "app.plugin.webapp_reply.too_large.app_error")
+
+	// Only the user the message was sent to may reply.
+	th.LoginBasic2()
+	_, apiResp = th.Client.ReplyToPluginWebappMessage(pluginId, replyId, []byte("yes"))
+	CheckNotFoundStatus(t, apiResp)
+
+	th.LoginBasic()
+	ok, apiResp := th.Client.ReplyToPluginWebappMessage(pluginId, replyId, []byte("yes"))
+	CheckNoError(t, apiResp)
+	assert.True(t, ok)
+
+	// Messages can only be replied to once.
+	_, apiResp = th.Client.ReplyToPluginWebappMessage(pluginId, replyId, []byte("yes"))
+	CheckNotFoundStatus(t, apiResp)
+
+	_, apiResp = th.Client.ReplyToPluginWebappMessage(pluginId, "junk", nil)
+	CheckBadRequestStatus(t, apiResp)
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = false })
+	defer th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = true })
+
+	_, apiResp = th.Client.ReplyToPluginWebappMessage(pluginId, model.NewId(), nil)
+	CheckNotImplementedStatus(t, apiResp)
+}
+
+func TestSetPluginStates(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+
+	enablePlugins := *th.App.Config().PluginSettings.Enable
+	enableUploadPlugins := *th.App.Config().PluginSettings.EnableUploads
+	defer th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = enablePlugins
+		*cfg.PluginSettings.EnableUploads = enableUploadPlugins
+	})
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.EnableUploads = true
+	})
+
+	path, _ := utils.FindDir("tests")
+	bundle, err := ioutil.ReadFile(filepath.Join(path, "testplugin.tar.gz"))
+	require.NoError(t, err)
+
+	_, resp := th.SystemAdminClient.UploadPlugin(bytes.NewReader(bundle))
+	CheckNoError(t, resp)
+	defer th.App.RemovePlugin("testplugin")
+
+	// A plugin whose backend executable is missing fails to start
+	brokenPluginDir := filepath.Join(*th.App.Config().PluginSettings.Directory, "brokenplugin")
+	require.NoError(t, os.MkdirAll(brokenPluginDir, 0700))
+	defer os.RemoveAll(brokenPluginDir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(brokenPluginDir, "plugin.json"), []byte(`{"id": "brokenplugin", "backend": {"executable": "missing"}}`), 0600))
+
+	_, resp = th.Client.SetPluginStates(map[string]bool{"testplugin": true})
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.SetPluginStates(map[string]bool{})
+	CheckBadRequestStatus(t, resp)
+
+	results, resp := th.SystemAdminClient.SetPluginStates(map[string]bool{
+		"testplugin":   true,
+		"brokenplugin": true,
+		"notinstalled": false,
+	})
+	CheckNoError(t, resp)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, "brokenplugin", results[0].PluginId)
+	assert.Equal(t, model.PLUGIN_STATE_CHANGE_FAILED, results[0].Result)
+	assert.NotEmpty(t, results[0].Error)
+	assert.Equal(t, &model.PluginStateChangeResult{PluginId: "notinstalled", Result: model.PLUGIN_STATE_CHANGE_NOT_INSTALLED}, results[1])
+	assert.Equal(t, &model.PluginStateChangeResult{PluginId: "testplugin", Result: model.PLUGIN_STATE_CHANGE_ENABLED}, results[2])
+
+	// Only the plugin that failed to start is rolled back
+	pluginStates := th.App.Config().PluginSettings.PluginStates
+	assert.True(t, pluginStates["testplugin"].Enable)
+	assert.False(t, pluginStates["brokenplugin"].Enable)
+	assert.NotContains(t, pluginStates, "notinstalled")
+	assert.True(t, th.App.Plugins.IsActive("testplugin"))
+	assert.False(t, th.App.Plugins.IsActive("brokenplugin"))
+
+	// The reasons for each state are reported, described for the console
+	assert.Equal(t, model.PLUGIN_STATE_REASON_ADMIN, pluginStates["testplugin"].Reason)
+	assert.Equal(t, model.PLUGIN_STATE_REASON_ACTIVATION_FAILED, pluginStates["brokenplugin"].Reason)
+
+	plugins, resp := th.SystemAdminClient.GetPlugins()
+	CheckNoError(t, resp)
+	for _, info := range plugins.Inactive {
+		if info.Id == "brokenplugin" {
+			assert.False(t, info.Enabled)
+			assert.Equal(t, model.PLUGIN_STATE_REASON_ACTIVATION_FAILED, info.StateReason)
+			assert.NotEmpty(t, info.StateReasonMessage)
+			assert.NotZero(t, info.StateUpdatedAt)
+		}
+	}
+
+	statuses, resp := th.SystemAdminClient.GetPluginStatuses()
+	CheckNoError(t, resp)
+	for _, status := range statuses {
+		if status.PluginId == "testplugin" {
+			assert.True(t, status.Enabled)
+			assert.Equal(t, model.PLUGIN_STATE_REASON_ADMIN, status.StateReason)
+			assert.NotEmpty(t, status.StateReasonMessage)
+		}
+	}
+
+	results, resp = th.SystemAdminClient.SetPluginStates(map[string]bool{"testplugin": false})
+	CheckNoError(t, resp)
+	assert.Equal(t, model.PluginStateChangeResults{{PluginId: "testplugin", Result: model.PLUGIN_STATE_CHANGE_DISABLED}}, results)
+	assert.False(t, th.App.Config().PluginSettings.PluginStates["testplugin"].Enable)
+	assert.False(t, th.App.Plugins.IsActive("testplugin"))
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = false })
+	_, resp = th.SystemAdminClient.SetPluginStates(map[string]bool{"testplugin": true})
+	CheckNotImplementedStatus(t, resp)
+}
+
+func makeTestPluginBundle(t *testing.T, files map[string]string) []byte {
+	var bundle bytes.Buffer
+	gzipWriter := gzip.NewWriter(&bundle)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, contents := range files {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(contents)), Typeflag: tar.TypeReg}))
+		_, err := tarWriter.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+
+	return bundle.Bytes()
+}
+
+func TestPluginApiSpec(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+
+	enablePlugins := *th.App.Config().PluginSettings.Enable
+	enableUploadPlugins := *th.App.Config().PluginSettings.EnableUploads
+	defer th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = enablePlugins
+		*cfg.PluginSettings.EnableUploads = enableUploadPlugins
+	})
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.EnableUploads = true
+	})
+
+	spec := `{"openapi": "3.0.0", "paths": {"/status": {"get": {"summary": "Get the status"}}}}`
+	bundle := makeTestPluginBundle(t, map[string]string{
+		"plugin.json": `{"id": "apispecplugin", "api_spec": "spec.json", "webapp": {"bundle_path": "main.js"}}`,
+		"spec.json":   spec,
+		"main.js":     "",
+	})
+
+	report, resp := th.SystemAdminClient.ValidatePlugin(bytes.NewReader(bundle))
+	CheckNoError(t, resp)
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Warnings)
+
+	_, resp = th.SystemAdminClient.UploadPlugin(bytes.NewReader(bundle))
+	CheckNoError(t, resp)
+	defer th.App.RemovePlugin("apispecplugin")
+
+	// The spec is only served while the plugin is active.
+	_, resp = th.Client.GetPluginApiSpec("apispecplugin", "")
+	CheckNotFoundStatus(t, resp)
+
+	require.Nil(t, th.App.EnablePlugin("apispecplugin"))
+
+	data, resp := th.Client.GetPluginApiSpec("apispecplugin", "")
+	CheckNoError(t, resp)
+	assert.Equal(t, spec, string(data))
+	assert.NotEmpty(t, resp.Etag)
+
+	data, resp = th.Client.GetPluginApiSpec("apispecplugin", resp.Etag)
+	CheckEtag(t, data, resp)
+
+	_, resp = th.Client.GetPluginApiSpec("notinstalled", "")
+	CheckNotFoundStatus(t, resp)
+
+	specs, resp := th.SystemAdminClient.GetPluginApiSpecs()
+	CheckNoError(t, resp)
+	assert.Equal(t, []*model.PluginApiSpec{
+		{
+			PluginId: "apispecplugin",
+			Routes:   []*model.PluginApiRoute{{Method: "GET", Path: "/status", Summary: "Get the status"}},
+		},
+	}, specs)
+
+	_, resp = th.Client.GetPluginApiSpecs()
+	CheckForbiddenStatus(t, resp)
+
+	// An invalid spec is a warning rather than a reason to refuse the plugin.
+	badBundle := makeTestPluginBundle(t, map[string]string{
+		"plugin.json": `{"id": "apispecplugin", "api_spec": "spec.json", "webapp": {"bundle_path": "main.js"}}`,
+		"spec.json":   "not json",
+		"main.js":     "",
+	})
+
+	report, resp = th.SystemAdminClient.ValidatePlugin(bytes.NewReader(badBundle))
+	CheckNoError(t, resp)
+	assert.True(t, report.Valid)
+	if assert.Len(t, report.Warnings, 1) {
+		assert.Equal(t, "app.plugin.api_spec.invalid.app_error", report.Warnings[0].Id)
+	}
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = false })
+	_, resp = th.Client.GetPluginApiSpec("apispecplugin", "")
+	CheckNotImplementedStatus(t, resp)
+	_, resp = th.SystemAdminClient.GetPluginApiSpecs()
+	CheckNotImplementedStatus(t, resp)
+}
+
+func TestPluginAdminActions(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+
+	enablePlugins := *th.App.Config().PluginSettings.Enable
+	defer th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = enablePlugins })
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = true })
+
+	pluginId := model.NewId()
+	requestDeleteUser := func() (*model.User, *model.PluginAdminActionRequest) {
+		user := th.CreateUser()
+		request, err := th.App.RequestPluginAdminAction(pluginId, &model.PluginAdminActionRequest{
+			Action:      model.PLUGIN_ADMIN_ACTION_DELETE_USER,
+			Params:      model.StringMap{model.PLUGIN_ADMIN_ACTION_PARAM_USER_ID: user.Id},
+			Description: "The user has left the company",
+		})
+		require.Nil(t, err)
+		return user, request
+	}
+
+	approvedUser, approved := requestDeleteUser()
+	deniedUser, denied := requestDeleteUser()
+
+	requests, resp := th.SystemAdminClient.GetPluginAdminActions(0, 10000)
+	CheckNoError(t, resp)
+	pending := map[string]*model.PluginAdminActionRequest{}
+	for _, request := range requests {
+		pending[request.Id] = request
+	}
+	assert.Equal(t, approved, pending[approved.Id])
+	assert.Equal(t, denied, pending[denied.Id])
+
+	_, resp = th.Client.GetPluginAdminActions(0, 100)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.Client.ApprovePluginAdminAction(approved.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.Client.DenyPluginAdminAction(denied.Id)
+	CheckForbiddenStatus(t, resp)
+
+	request, resp := th.SystemAdminClient.ApprovePluginAdminAction(approved.Id)
+	CheckNoError(t, resp)
+	assert.Equal(t, model.PLUGIN_ADMIN_ACTION_STATUS_APPROVED, request.Status)
+	assert.Equal(t, th.SystemAdminUser.Id, request.ResolvedBy)
+	_, err := th.App.GetUser(approvedUser.Id)
+	assert.NotNil(t, err)
+
+	request, resp = th.SystemAdminClient.DenyPluginAdminAction(denied.Id)
+	CheckNoError(t, resp)
+	assert.Equal(t, model.PLUGIN_ADMIN_ACTION_STATUS_DENIED, request.Status)
+	_, err = th.App.GetUser(deniedUser.Id)
+	assert.Nil(t, err)
+
+	// Resolved requests are no longer pending.
+	_, resp = th.SystemAdminClient.ApprovePluginAdminAction(denied.Id)
+	CheckBadRequestStatus(t, resp)
+
+	requests, resp = th.SystemAdminClient.GetPluginAdminActions(0, 10000)
+	CheckNoError(t, resp)
+	for _, request := range requests {
+		assert.NotEqual(t, approved.Id, request.Id)
+		assert.NotEqual(t, denied.Id, request.Id)
+	}
+
+	_, resp = th.SystemAdminClient.ApprovePluginAdminAction(model.NewId())
+	CheckNotFoundStatus(t, resp)
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = false })
+	_, resp = th.SystemAdminClient.GetPluginAdminActions(0, 100)
+	CheckNotImplementedStatus(t, resp)
+}
@@ -15,6 +15,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/mattermost/mattermost-server/app"
 	"github.com/mattermost/mattermost-server/model"
 )
@@ -713,6 +716,51 @@ func TestPinPost(t *testing.T) {
 	CheckNoError(t, resp)
 }
 
+func TestAcknowledgePost(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	defer th.TearDown()
+	Client := th.Client
+
+	post := th.BasicPost
+	_, resp := Client.AcknowledgePost(th.BasicUser.Id, post.Id)
+	CheckBadRequestStatus(t, resp)
+
+	_, err := th.App.RequestPostAcknowledgement(post.Id)
+	require.Nil(t, err)
+
+	acknowledgement, resp := Client.AcknowledgePost(model.ME, post.Id)
+	CheckNoError(t, resp)
+	assert.Equal(t, post.Id, acknowledgement.PostId)
+	assert.Equal(t, th.BasicUser.Id, acknowledgement.UserId)
+
+	again, resp := Client.AcknowledgePost(th.BasicUser.Id, post.Id)
+	CheckNoError(t, resp)
+	assert.Equal(t, acknowledgement, again)
+
+	_, resp = Client.AcknowledgePost(th.BasicUser2.Id, post.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.AcknowledgePost(th.BasicUser.Id, GenerateTestId())
+	CheckForbiddenStatus(t, resp)
+
+	acknowledgements, resp := Client.GetPostAcknowledgements(post.Id)
+	CheckNoError(t, resp)
+	assert.Equal(t, []*model.PostAcknowledgement{acknowledgement}, acknowledgements)
+
+	_, resp = Client.GetPostAcknowledgements(GenerateTestId())
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetPostAcknowledgements(post.Id)
+	CheckNoError(t, resp)
+
+	Client.Logout()
+	_, resp = Client.AcknowledgePost(th.BasicUser.Id, post.Id)
+	CheckUnauthorizedStatus(t, resp)
+
+	_, resp = Client.GetPostAcknowledgements(post.Id)
+	CheckUnauthorizedStatus(t, resp)
+}
+
 func TestUnpinPost(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	defer th.TearDown()
@@ -118,6 +118,15 @@ func getUser(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if c.Session.UserId == user.Id {
+		// Plugins' settings for the user are included so that their webapp components can start
+		// without fetching them.
+		if user.PluginPreferences, err = c.App.GetPluginPreferencesForUser(user.Id); err != nil {
+			c.Err = err
+			return
+		}
+	}
+
 	etag := user.Etag(c.App.Config().PrivacySettings.ShowFullName, c.App.Config().PrivacySettings.ShowEmailAddress)
 
 	if c.HandleEtag(etag, "Get User", w, r) {
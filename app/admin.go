@@ -145,6 +145,7 @@ func (a *App) InvalidateAllCachesSkipSend() {
 	a.Srv.Store.Post().ClearCaches()
 	a.Srv.Store.FileInfo().ClearCaches()
 	a.Srv.Store.Webhook().ClearCaches()
+	a.Srv.Store.Plugin().ClearCaches()
 	a.LoadLicense()
 }
 
@@ -152,6 +153,7 @@ func (a *App) GetConfig() *model.Config {
 	json := a.Config().ToJson()
 	cfg := model.ConfigFromJson(strings.NewReader(json))
 	cfg.Sanitize()
+	sanitizePluginSecrets(cfg, a.pluginSecretSettings())
 
 	return cfg
 }
@@ -162,6 +164,10 @@ func (a *App) GetEnvironmentConfig() map[string]interface{} {
 
 func (a *App) SaveConfig(cfg *model.Config, sendConfigChangeClusterMessage bool) *model.AppError {
 	oldCfg := a.Config()
+
+	// Work on a copy, since callers commonly pass the current configuration, which other
+	// goroutines may be reading concurrently.
+	cfg = cfg.Clone()
 	cfg.SetDefaults()
 	a.Desanitize(cfg)
 
@@ -173,6 +179,10 @@ func (a *App) SaveConfig(cfg *model.Config, sendConfigChangeClusterMessage bool)
 		return err
 	}
 
+	if err := a.encodePluginSecrets(cfg); err != nil {
+		return err
+	}
+
 	if *a.Config().ClusterSettings.Enable && *a.Config().ClusterSettings.ReadOnlyConfig {
 		return model.NewAppError("saveConfig", "ent.cluster.save_config.error", nil, "", http.StatusForbidden)
 	}
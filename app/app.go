@@ -14,6 +14,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
@@ -65,6 +66,7 @@ type App struct {
 	Saml             einterfaces.SamlInterface
 
 	config                 atomic.Value
+	configLock             sync.Mutex
 	envConfig              map[string]interface{}
 	configFile             string
 	configListeners        map[string]func(*model.Config, *model.Config)
@@ -93,6 +95,45 @@ type App struct {
 	pluginCommands     []*PluginCommand
 	pluginCommandsLock sync.RWMutex
 
+	pluginInstallLock   sync.Mutex
+	pluginBundleCleanup atomic.Value
+
+	pluginBanners     map[string]*model.PluginBanner
+	pluginBannersLock sync.RWMutex
+
+	pluginOAuthProviders     map[string]*model.PluginOAuthProvider
+	pluginOAuthProvidersLock sync.RWMutex
+	pluginOAuthRefreshLock   sync.Mutex
+
+	pluginLifecycleLock     sync.Mutex
+	pluginLifecycleQueue    []pluginLifecycleEvent
+	pluginLifecycleDraining bool
+
+	pluginNotificationRateLimiter     *RateLimiter
+	pluginNotificationRateLimiterOnce sync.Once
+
+	pluginKeyValueChangeRateLimiter     *RateLimiter
+	pluginKeyValueChangeRateLimiterOnce sync.Once
+
+	pluginEventRateLimiter     *RateLimiter
+	pluginEventRateLimiterOnce sync.Once
+
+	pluginWebappReplies     map[string]*pendingPluginWebappReply
+	pluginWebappRepliesLock sync.Mutex
+
+	pluginServerHealth     *model.PluginServerHealth
+	pluginServerHealthLock sync.Mutex
+
+	pluginReadAfterWriteRequests map[string]int
+	pluginReadAfterWriteLock     sync.Mutex
+
+	pluginKeyValueUsage     map[string]*cachedPluginKeyValueUsage
+	pluginKeyValueUsageLock sync.Mutex
+
+	hashedPluginKeysMigrationComplete  bool
+	hashedPluginKeysMigrationCheckedAt time.Time
+	hashedPluginKeysMigrationLock      sync.Mutex
+
 	clientConfig        map[string]string
 	clientConfigHash    string
 	limitedClientConfig map[string]string
@@ -197,7 +238,7 @@ func New(options ...Option) (outApp *App, outErr error) {
 
 	if app.newStore == nil {
 		app.newStore = func() store.Store {
-			return store.NewLayeredStore(sqlstore.NewSqlSupplier(app.Config().SqlSettings, app.Metrics), app.Metrics, app.Cluster)
+			return store.NewLayeredStore(sqlstore.NewSqlSupplier(app.Config().SqlSettings, app.Metrics), app.Metrics, app.Cluster, *app.Config().PluginSettings.KeyValueCacheSize, *app.Config().PluginSettings.KeyValueCacheSeconds, *app.Config().PluginSettings.KeyValueMaxConcurrentOperations)
 		}
 	}
 
@@ -213,6 +254,7 @@ func New(options ...Option) (outApp *App, outErr error) {
 	}
 
 	app.EnsureDiagnosticId()
+	app.loadPluginBanners()
 	app.regenerateClientConfig()
 
 	app.initJobs()
@@ -348,6 +390,12 @@ func RegisterJobsMigrationsJobInterface(f func(*App) tjobs.MigrationsJobInterfac
 	jobsMigrationsInterface = f
 }
 
+var jobsChannelExportInterface func(*App) tjobs.ChannelExportJobInterface
+
+func RegisterJobsChannelExportJobInterface(f func(*App) tjobs.ChannelExportJobInterface) {
+	jobsChannelExportInterface = f
+}
+
 var ldapInterface func(*App) einterfaces.LdapInterface
 
 func RegisterLdapInterface(f func(*App) einterfaces.LdapInterface) {
@@ -404,6 +452,9 @@ func (a *App) initEnterprise() {
 	}
 	if metricsInterface != nil {
 		a.Metrics = metricsInterface(a)
+		if err := a.InitPluginMetricsRoutes(a.Metrics.Router()); err != nil {
+			mlog.Error("Failed to register plugin metrics routes", mlog.Err(err))
+		}
 	}
 	if mfaInterface != nil {
 		a.Mfa = mfaInterface(a)
@@ -439,6 +490,9 @@ func (a *App) initJobs() {
 	if jobsMigrationsInterface != nil {
 		a.Jobs.Migrations = jobsMigrationsInterface(a)
 	}
+	if jobsChannelExportInterface != nil {
+		a.Jobs.ChannelExport = jobsChannelExportInterface(a)
+	}
 	a.Jobs.Workers = a.Jobs.InitWorkers()
 	a.Jobs.Schedulers = a.Jobs.InitSchedulers()
 }
@@ -588,7 +642,7 @@ func (a *App) DoAdvancedPermissionsMigration() {
 		return
 	}
 
-	config := a.Config()
+	config := a.Config().Clone()
 	if *config.ServiceSettings.AllowEditPost == model.ALLOW_EDIT_POST_ALWAYS {
 		*config.ServiceSettings.PostEditTimeLimit = -1
 		if err := a.SaveConfig(config, true); err != nil {
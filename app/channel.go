@@ -98,7 +98,7 @@ func (a *App) JoinDefaultChannels(teamId string, user *model.User, shouldBeAdmin
 					}
 				} else {
 					if requestor == nil {
-						if err := a.postJoinChannelMessage(user, channel); err != nil {
+						if err := a.postJoinChannelMessage(user, channel, true); err != nil {
 							mlog.Error(fmt.Sprint("Failed to post join/leave message", err))
 						}
 					} else {
@@ -150,7 +150,7 @@ func (a *App) CreateChannelWithUser(channel *model.Channel, userId string) (*mod
 		return nil, err
 	}
 
-	a.postJoinChannelMessage(user, channel)
+	a.postJoinChannelMessage(user, channel, true)
 
 	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_CHANNEL_CREATED, "", "", userId, nil)
 	message.Add("channel_id", channel.Id)
@@ -201,13 +201,10 @@ func (a *App) CreateChannel(channel *model.Channel, addMember bool) (*model.Chan
 		}
 
 		if a.PluginsReady() {
-			a.Go(func() {
-				pluginContext := &plugin.Context{}
-				a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
-					hooks.ChannelHasBeenCreated(pluginContext, sc)
-					return true
-				}, plugin.ChannelHasBeenCreatedId)
-			})
+			pluginContext := newPluginContext()
+			a.Plugins.RunMultiPluginHookAsync(func(hooks plugin.Hooks) {
+				hooks.ChannelHasBeenCreated(pluginContext, sc)
+			}, plugin.ChannelHasBeenCreatedId)
 		}
 
 		return sc, nil
@@ -228,13 +225,10 @@ func (a *App) CreateDirectChannel(userId string, otherUserId string) (*model.Cha
 		a.InvalidateCacheForUser(otherUserId)
 
 		if a.PluginsReady() {
-			a.Go(func() {
-				pluginContext := &plugin.Context{}
-				a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
-					hooks.ChannelHasBeenCreated(pluginContext, channel)
-					return true
-				}, plugin.ChannelHasBeenCreatedId)
-			})
+			pluginContext := newPluginContext()
+			a.Plugins.RunMultiPluginHookAsync(func(hooks plugin.Hooks) {
+				hooks.ChannelHasBeenCreated(pluginContext, channel)
+			}, plugin.ChannelHasBeenCreatedId)
 		}
 
 		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_DIRECT_ADDED, "", channel.Id, "", nil)
@@ -432,19 +426,37 @@ func (a *App) UpdateChannelScheme(channel *model.Channel) (*model.Channel, *mode
 	return newChannel, nil
 }
 
+// ConvertChannelToPrivate converts a public channel to a private one. The system message announcing
+// the change is attributed to user, and omitted if user is nil.
+func (a *App) ConvertChannelToPrivate(channel *model.Channel, user *model.User) (*model.Channel, *model.AppError) {
+	if channel.Type == model.CHANNEL_PRIVATE {
+		return nil, model.NewAppError("ConvertChannelToPrivate", "api.channel.convert_channel_to_private.private_channel_error", nil, "", http.StatusBadRequest)
+	}
+
+	if channel.Name == model.DEFAULT_CHANNEL {
+		return nil, model.NewAppError("ConvertChannelToPrivate", "api.channel.convert_channel_to_private.default_channel_error", nil, "", http.StatusBadRequest)
+	}
+
+	channel.Type = model.CHANNEL_PRIVATE
+
+	return a.UpdateChannelPrivacy(channel, user)
+}
+
 func (a *App) UpdateChannelPrivacy(oldChannel *model.Channel, user *model.User) (*model.Channel, *model.AppError) {
 	if channel, err := a.UpdateChannel(oldChannel); err != nil {
 		return channel, err
 	} else {
-		if err := a.postChannelPrivacyMessage(user, channel); err != nil {
-			if channel.Type == model.CHANNEL_OPEN {
-				channel.Type = model.CHANNEL_PRIVATE
-			} else {
-				channel.Type = model.CHANNEL_OPEN
+		if user != nil {
+			if err := a.postChannelPrivacyMessage(user, channel); err != nil {
+				if channel.Type == model.CHANNEL_OPEN {
+					channel.Type = model.CHANNEL_PRIVATE
+				} else {
+					channel.Type = model.CHANNEL_OPEN
+				}
+				// revert to previous channel privacy
+				a.UpdateChannel(channel)
+				return channel, err
 			}
-			// revert to previous channel privacy
-			a.UpdateChannel(channel)
-			return channel, err
 		}
 
 		a.InvalidateCacheForChannel(channel)
@@ -480,11 +492,31 @@ func (a *App) postChannelPrivacyMessage(user *model.User, channel *model.Channel
 }
 
 func (a *App) RestoreChannel(channel *model.Channel) (*model.Channel, *model.AppError) {
-	if result := <-a.Srv.Store.Channel().Restore(channel.Id, model.GetMillis()); result.Err != nil {
+	if channel.TeamId != "" {
+		team, err := a.GetTeam(channel.TeamId)
+		if err != nil {
+			return nil, err
+		}
+
+		if team.DeleteAt > 0 {
+			return nil, model.NewAppError("RestoreChannel", "api.channel.restore_channel.team_deleted.app_error", nil, "", http.StatusBadRequest)
+		}
+	}
+
+	now := model.GetMillis()
+	if result := <-a.Srv.Store.Channel().Restore(channel.Id, now); result.Err != nil {
 		return nil, result.Err
-	} else {
-		return channel, nil
 	}
+
+	channel.DeleteAt = 0
+	channel.UpdateAt = now
+	a.InvalidateCacheForChannel(channel)
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_CHANNEL_UPDATED, "", channel.Id, "", nil)
+	message.Add("channel", channel.ToJson())
+	a.Publish(message)
+
+	return channel, nil
 }
 
 func (a *App) PatchChannel(channel *model.Channel, patch *model.ChannelPatch, userId string) (*model.Channel, *model.AppError) {
@@ -808,6 +840,13 @@ func (a *App) AddUserToChannel(user *model.User, channel *model.Channel) (*model
 }
 
 func (a *App) AddChannelMember(userId string, channel *model.Channel, userRequestorId string, postRootId string) (*model.ChannelMember, *model.AppError) {
+	return a.AddChannelMemberWithOptions(userId, channel, userRequestorId, postRootId, model.MemberAddOptions{})
+}
+
+// AddChannelMemberWithOptions adds the user to the channel, optionally suppressing the system message
+// announcing the membership or the notifications it would trigger. Websocket events and plugin hooks
+// are unaffected by the options.
+func (a *App) AddChannelMemberWithOptions(userId string, channel *model.Channel, userRequestorId string, postRootId string, opts model.MemberAddOptions) (*model.ChannelMember, *model.AppError) {
 	if result := <-a.Srv.Store.Channel().GetMember(channel.Id, userId); result.Err != nil {
 		if result.Err.Id != store.MISSING_CHANNEL_MEMBER_ERROR {
 			return nil, result.Err
@@ -836,20 +875,19 @@ func (a *App) AddChannelMember(userId string, channel *model.Channel, userReques
 	}
 
 	if a.PluginsReady() {
-		a.Go(func() {
-			pluginContext := &plugin.Context{}
-			a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
-				hooks.UserHasJoinedChannel(pluginContext, cm, userRequestor)
-				return true
-			}, plugin.UserHasJoinedChannelId)
-		})
+		pluginContext := newPluginContext()
+		a.Plugins.RunMultiPluginHookAsync(func(hooks plugin.Hooks) {
+			hooks.UserHasJoinedChannel(pluginContext, cm, userRequestor)
+		}, plugin.UserHasJoinedChannelId)
 	}
 
-	if userRequestorId == "" || userId == userRequestorId {
-		a.postJoinChannelMessage(user, channel)
+	if opts.SuppressJoinMessage {
+		mlog.Info("Suppressed channel join message", mlog.String("user_id", userId), mlog.String("channel_id", channel.Id))
+	} else if userRequestorId == "" || userId == userRequestorId {
+		a.postJoinChannelMessage(user, channel, !opts.SuppressNotifications)
 	} else {
 		a.Go(func() {
-			a.PostAddToChannelMessage(userRequestor, user, channel, postRootId)
+			a.postAddToChannelMessage(userRequestor, user, channel, postRootId, !opts.SuppressNotifications)
 		})
 	}
 
@@ -1131,6 +1169,51 @@ func (a *App) GetChannelMembersPage(channelId string, page, perPage int) (*model
 	}
 }
 
+// GetChannelMembersModifiedSince returns the members of the channel whose membership was created or
+// updated after the given time, along with the users that have since left the channel.
+func (a *App) GetChannelMembersModifiedSince(channelId string, since int64) (*model.ChannelMemberChanges, *model.AppError) {
+	mchan := a.Srv.Store.Channel().GetMembersModifiedSince(channelId, since)
+	lchan := a.Srv.Store.ChannelMemberHistory().GetUsersLeftSince(channelId, since)
+
+	result := <-mchan
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	members := result.Data.(*model.ChannelMembers)
+
+	result = <-lchan
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	leftUserIds := result.Data.([]string)
+
+	changes := &model.ChannelMemberChanges{
+		Members:        *members,
+		RemovedUserIds: []string{},
+	}
+
+	if len(leftUserIds) > 0 {
+		// Users that rejoined the channel are reported as members instead
+		result = <-a.Srv.Store.Channel().GetMembersByIds(channelId, leftUserIds)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		current := make(map[string]bool)
+		for _, member := range *result.Data.(*model.ChannelMembers) {
+			current[member.UserId] = true
+		}
+
+		for _, userId := range leftUserIds {
+			if !current[userId] {
+				changes.RemovedUserIds = append(changes.RemovedUserIds, userId)
+			}
+		}
+	}
+
+	return changes, nil
+}
+
 func (a *App) GetChannelMembersByIds(channelId string, userIds []string) (*model.ChannelMembers, *model.AppError) {
 	if result := <-a.Srv.Store.Channel().GetMembersByIds(channelId, userIds); result.Err != nil {
 		return nil, result.Err
@@ -1200,16 +1283,13 @@ func (a *App) JoinChannel(channel *model.Channel, userId string) *model.AppError
 			}
 
 			if a.PluginsReady() {
-				a.Go(func() {
-					pluginContext := &plugin.Context{}
-					a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
-						hooks.UserHasJoinedChannel(pluginContext, cm, nil)
-						return true
-					}, plugin.UserHasJoinedChannelId)
-				})
+				pluginContext := newPluginContext()
+				a.Plugins.RunMultiPluginHookAsync(func(hooks plugin.Hooks) {
+					hooks.UserHasJoinedChannel(pluginContext, cm, nil)
+				}, plugin.UserHasJoinedChannelId)
 			}
 
-			if err := a.postJoinChannelMessage(user, channel); err != nil {
+			if err := a.postJoinChannelMessage(user, channel, true); err != nil {
 				return err
 			}
 		} else {
@@ -1220,7 +1300,7 @@ func (a *App) JoinChannel(channel *model.Channel, userId string) *model.AppError
 	return nil
 }
 
-func (a *App) postJoinChannelMessage(user *model.User, channel *model.Channel) *model.AppError {
+func (a *App) postJoinChannelMessage(user *model.User, channel *model.Channel, notifyUsers bool) *model.AppError {
 	post := &model.Post{
 		ChannelId: channel.Id,
 		Message:   fmt.Sprintf(utils.T("api.channel.join_channel.post_and_forget"), user.Username),
@@ -1231,7 +1311,7 @@ func (a *App) postJoinChannelMessage(user *model.User, channel *model.Channel) *
 		},
 	}
 
-	if _, err := a.CreatePost(post, channel, false); err != nil {
+	if _, err := a.createPost(post, channel, false, notifyUsers); err != nil {
 		return model.NewAppError("postJoinChannelMessage", "api.channel.post_user_add_remove_message_and_forget.error", nil, err.Error(), http.StatusInternalServerError)
 	}
 
@@ -1317,6 +1397,10 @@ func (a *App) postLeaveChannelMessage(user *model.User, channel *model.Channel)
 }
 
 func (a *App) PostAddToChannelMessage(user *model.User, addedUser *model.User, channel *model.Channel, postRootId string) *model.AppError {
+	return a.postAddToChannelMessage(user, addedUser, channel, postRootId, true)
+}
+
+func (a *App) postAddToChannelMessage(user *model.User, addedUser *model.User, channel *model.Channel, postRootId string, notifyUsers bool) *model.AppError {
 	post := &model.Post{
 		ChannelId: channel.Id,
 		Message:   fmt.Sprintf(utils.T("api.channel.add_member.added"), addedUser.Username, user.Username),
@@ -1331,7 +1415,7 @@ func (a *App) PostAddToChannelMessage(user *model.User, addedUser *model.User, c
 		},
 	}
 
-	if _, err := a.CreatePost(post, channel, false); err != nil {
+	if _, err := a.createPost(post, channel, false, notifyUsers); err != nil {
 		return model.NewAppError("postAddToChannelMessage", "api.channel.post_user_add_remove_message_and_forget.error", nil, err.Error(), http.StatusInternalServerError)
 	}
 
@@ -1411,13 +1495,10 @@ func (a *App) removeUserFromChannel(userIdToRemove string, removerUserId string,
 			actorUser, err = a.GetUser(removerUserId)
 		}
 
-		a.Go(func() {
-			pluginContext := &plugin.Context{}
-			a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
-				hooks.UserHasLeftChannel(pluginContext, cm, actorUser)
-				return true
-			}, plugin.UserHasLeftChannelId)
-		})
+		pluginContext := newPluginContext()
+		a.Plugins.RunMultiPluginHookAsync(func(hooks plugin.Hooks) {
+			hooks.UserHasLeftChannel(pluginContext, cm, actorUser)
+		}, plugin.UserHasLeftChannelId)
 	}
 
 	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_USER_REMOVED, "", channel.Id, "", nil)
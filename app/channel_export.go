@@ -0,0 +1,463 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	CHANNEL_EXPORT_BATCH_SIZE       = 200
+	CHANNEL_EXPORT_CLEANUP_INTERVAL = 1 * time.Hour
+
+	// CHANNEL_EXPORT_DIRECTORY is the directory of the file store to which channels are exported.
+	CHANNEL_EXPORT_DIRECTORY = "exports/channels/"
+
+	channelExportJobDataPluginId  = "plugin_id"
+	channelExportJobDataChannelId = "channel_id"
+	channelExportJobDataFormat    = "format"
+	channelExportJobDataRows      = "rows"
+	channelExportJobDataFileId    = "file_id"
+	channelExportJobDataPath      = "path"
+	channelExportJobDataError     = "error"
+)
+
+// ExportChannel creates a job exporting the channel in the given format on behalf of the plugin.
+func (a *App) ExportChannel(pluginId, channelId, format string) (*model.Job, *model.AppError) {
+	if !model.IsValidChannelExportFormat(format) {
+		return nil, model.NewAppError("ExportChannel", "app.channel_export.format.app_error", map[string]interface{}{"Format": format}, "", http.StatusBadRequest)
+	}
+
+	if _, err := a.GetChannel(channelId); err != nil {
+		return nil, err
+	}
+
+	return a.Jobs.CreateJob(model.JOB_TYPE_CHANNEL_EXPORT, map[string]string{
+		channelExportJobDataPluginId:  pluginId,
+		channelExportJobDataChannelId: channelId,
+		channelExportJobDataFormat:    format,
+	})
+}
+
+// GetChannelExportStatus returns the progress of a channel export created by the plugin.
+func (a *App) GetChannelExportStatus(pluginId, jobId string) (*model.ChannelExportStatus, *model.AppError) {
+	job, err := a.Jobs.GetJob(jobId)
+	if err != nil && err.StatusCode != http.StatusNotFound {
+		return nil, err
+	}
+
+	if job == nil || job.Type != model.JOB_TYPE_CHANNEL_EXPORT || job.Data[channelExportJobDataPluginId] != pluginId {
+		return nil, model.NewAppError("GetChannelExportStatus", "app.channel_export.get_status.not_found.app_error", nil, "job_id="+jobId, http.StatusNotFound)
+	}
+
+	rows, _ := strconv.ParseInt(job.Data[channelExportJobDataRows], 10, 64)
+
+	status := &model.ChannelExportStatus{
+		JobId:     job.Id,
+		ChannelId: job.Data[channelExportJobDataChannelId],
+		Format:    job.Data[channelExportJobDataFormat],
+		Status:    job.Status,
+		Progress:  job.Progress,
+		Rows:      rows,
+		Error:     job.Data[channelExportJobDataError],
+	}
+
+	if job.Status == model.JOB_STATUS_SUCCESS {
+		status.Progress = 100
+		status.FileId = job.Data[channelExportJobDataFileId]
+		status.Path = job.Data[channelExportJobDataPath]
+	}
+
+	return status, nil
+}
+
+// RunChannelExport writes the channel export described by the job to the file store, streaming the
+// channel's members, posts and file metadata in batches, and records the exported file in the job's
+// data. The export stops when ctx is canceled, and fails if it would hold more rows than
+// PluginSettings.MaxChannelExportRows, in which case nothing is left in the file store.
+func (a *App) RunChannelExport(ctx context.Context, job *model.Job) *model.AppError {
+	channel, err := a.GetChannel(job.Data[channelExportJobDataChannelId])
+	if err != nil {
+		return err
+	}
+
+	format := job.Data[channelExportJobDataFormat]
+	if !model.IsValidChannelExportFormat(format) {
+		return model.NewAppError("RunChannelExport", "app.channel_export.format.app_error", map[string]interface{}{"Format": format}, "", http.StatusBadRequest)
+	}
+
+	path := CHANNEL_EXPORT_DIRECTORY + job.Id + "." + format
+
+	// The export is written to the file store as it is read from the database, so that the channel
+	// is never held in memory in full.
+	reader, writer := io.Pipe()
+	exported := make(chan *model.AppError, 1)
+	go func() {
+		err := a.writeChannelExport(ctx, job, channel, newChannelExportWriter(format, writer))
+		if err != nil {
+			writer.CloseWithError(err)
+		} else {
+			writer.Close()
+		}
+		exported <- err
+	}()
+
+	size, writeErr := a.WriteFile(reader, path)
+	reader.Close()
+
+	if err := <-exported; err != nil || writeErr != nil {
+		if removeErr := a.RemoveFile(path); removeErr != nil {
+			mlog.Warn("Failed to remove incomplete channel export", mlog.String("job_id", job.Id), mlog.Err(removeErr))
+		}
+
+		if err != nil {
+			return err
+		}
+		return writeErr
+	}
+
+	info := &model.FileInfo{
+		// Exported files have no creator, so they are attributed to the job that wrote them, which
+		// keeps them out of every user's files.
+		CreatorId: job.Id,
+		Path:      path,
+		Name:      channel.Name + "." + format,
+		Extension: format,
+		Size:      size,
+		MimeType:  channelExportMimeType(format),
+	}
+	if result := <-a.Srv.Store.FileInfo().Save(info); result.Err != nil {
+		a.RemoveFile(path)
+		return result.Err
+	}
+
+	job.Data[channelExportJobDataFileId] = info.Id
+	job.Data[channelExportJobDataPath] = path
+	return a.Jobs.UpdateInProgressJobData(job)
+}
+
+func (a *App) writeChannelExport(ctx context.Context, job *model.Job, channel *model.Channel, w channelExportWriter) *model.AppError {
+	maxRows := int64(*a.Config().PluginSettings.MaxChannelExportRows)
+	var rows, posts int64
+
+	writeErr := func(err error) *model.AppError {
+		return model.NewAppError("writeChannelExport", "app.channel_export.write.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	addRows := func(n int) *model.AppError {
+		if rows += int64(n); rows > maxRows {
+			return model.NewAppError("writeChannelExport", "app.channel_export.max_rows.app_error", map[string]interface{}{"Max": maxRows}, "job_id="+job.Id, http.StatusRequestEntityTooLarge)
+		}
+		return nil
+	}
+
+	// Progress is reported once each batch has been written, estimated from the number of posts
+	// exported so far.
+	reportProgress := func() *model.AppError {
+		if err := ctx.Err(); err != nil {
+			return model.NewAppError("writeChannelExport", "app.channel_export.canceled.app_error", nil, "job_id="+job.Id, http.StatusInternalServerError)
+		}
+
+		progress := int64(0)
+		if channel.TotalMsgCount > 0 {
+			progress = posts * 100 / channel.TotalMsgCount
+		}
+		if progress > 99 {
+			progress = 99
+		}
+
+		job.Data[channelExportJobDataRows] = strconv.FormatInt(rows, 10)
+		return a.Jobs.SetJobProgress(job, progress)
+	}
+
+	if err := w.WriteChannel(channel); err != nil {
+		return writeErr(err)
+	}
+
+	for offset := 0; ; offset += CHANNEL_EXPORT_BATCH_SIZE {
+		result := <-a.Srv.Store.Channel().GetMembers(channel.Id, offset, CHANNEL_EXPORT_BATCH_SIZE)
+		if result.Err != nil {
+			return result.Err
+		}
+		members := *result.Data.(*model.ChannelMembers)
+
+		if err := addRows(len(members)); err != nil {
+			return err
+		}
+		for i := range members {
+			if err := w.WriteMember(&members[i]); err != nil {
+				return writeErr(err)
+			}
+		}
+
+		if err := reportProgress(); err != nil {
+			return err
+		}
+
+		if len(members) < CHANNEL_EXPORT_BATCH_SIZE {
+			break
+		}
+	}
+
+	afterCreateAt, afterId := int64(0), ""
+	for {
+		result := <-a.Srv.Store.Post().GetPostsBatchForChannelExport(channel.Id, afterCreateAt, afterId, CHANNEL_EXPORT_BATCH_SIZE)
+		if result.Err != nil {
+			return result.Err
+		}
+		batch := result.Data.([]*model.Post)
+
+		if err := addRows(len(batch)); err != nil {
+			return err
+		}
+		for _, post := range a.FilterPostsForExport(batch, model.CHANNEL_EXPORT_TYPE) {
+			if err := w.WritePost(post); err != nil {
+				return writeErr(err)
+			}
+
+			if len(post.FileIds) > 0 {
+				result := <-a.Srv.Store.FileInfo().GetForPost(post.Id, false, false)
+				if result.Err != nil {
+					return result.Err
+				}
+				infos := result.Data.([]*model.FileInfo)
+
+				if err := addRows(len(infos)); err != nil {
+					return err
+				}
+				for _, info := range infos {
+					if err := w.WriteFile(info); err != nil {
+						return writeErr(err)
+					}
+				}
+			}
+
+			afterCreateAt, afterId = post.CreateAt, post.Id
+		}
+		posts += int64(len(batch))
+
+		if err := w.Flush(); err != nil {
+			return writeErr(err)
+		}
+
+		if err := reportProgress(); err != nil {
+			return err
+		}
+
+		if len(batch) < CHANNEL_EXPORT_BATCH_SIZE {
+			break
+		}
+	}
+
+	return nil
+}
+
+// ChannelExportHasCompleted invokes the ChannelExportHasCompleted hook of the plugin that requested the
+// export once its job has succeeded.
+func (a *App) ChannelExportHasCompleted(job *model.Job) {
+	if !a.PluginsReady() {
+		return
+	}
+
+	hooks, err := a.Plugins.HooksForPlugin(job.Data[channelExportJobDataPluginId])
+	if err != nil {
+		mlog.Debug("Unable to notify plugin of completed channel export", mlog.String("job_id", job.Id), mlog.Err(err))
+		return
+	}
+
+	hooks.ChannelExportHasCompleted(job.Id, job.Data[channelExportJobDataFileId])
+}
+
+// DeleteExpiredChannelExports deletes the channel export jobs that finished more than
+// PluginSettings.ChannelExportRetentionHours ago, along with their exported files.
+func (a *App) DeleteExpiredChannelExports() {
+	result := <-a.Srv.Store.Job().GetAllByType(model.JOB_TYPE_CHANNEL_EXPORT)
+	if result.Err != nil {
+		mlog.Error("Failed to get channel export jobs", mlog.Err(result.Err))
+		return
+	}
+
+	expiredBefore := model.GetMillis() - int64(*a.Config().PluginSettings.ChannelExportRetentionHours)*int64(time.Hour/time.Millisecond)
+
+	deleted := 0
+	for _, job := range result.Data.([]*model.Job) {
+		finished := job.Status == model.JOB_STATUS_SUCCESS || job.Status == model.JOB_STATUS_ERROR || job.Status == model.JOB_STATUS_CANCELED
+		if !finished || job.LastActivityAt >= expiredBefore {
+			continue
+		}
+
+		if path := job.Data[channelExportJobDataPath]; path != "" {
+			if err := a.RemoveFile(path); err != nil {
+				mlog.Warn("Failed to remove expired channel export", mlog.String("job_id", job.Id), mlog.Err(err))
+			}
+		}
+
+		if fileId := job.Data[channelExportJobDataFileId]; fileId != "" {
+			if result := <-a.Srv.Store.FileInfo().PermanentDelete(fileId); result.Err != nil {
+				mlog.Error("Failed to delete expired channel export", mlog.String("job_id", job.Id), mlog.Err(result.Err))
+				continue
+			}
+		}
+
+		if result := <-a.Srv.Store.Job().Delete(job.Id); result.Err != nil {
+			mlog.Error("Failed to delete expired channel export", mlog.String("job_id", job.Id), mlog.Err(result.Err))
+			continue
+		}
+
+		deleted++
+	}
+
+	if deleted > 0 {
+		mlog.Debug("Deleted expired channel exports", mlog.Int("count", deleted))
+	}
+}
+
+func channelExportMimeType(format string) string {
+	if format == model.CHANNEL_EXPORT_FORMAT_CSV {
+		return "text/csv"
+	}
+	return "application/json"
+}
+
+// channelExportWriter writes the rows of a channel export in a particular format.
+type channelExportWriter interface {
+	WriteChannel(channel *model.Channel) error
+	WriteMember(member *model.ChannelMember) error
+	WritePost(post *model.Post) error
+	WriteFile(info *model.FileInfo) error
+
+	// Flush writes any buffered rows to the underlying writer.
+	Flush() error
+}
+
+func newChannelExportWriter(format string, w io.Writer) channelExportWriter {
+	if format == model.CHANNEL_EXPORT_FORMAT_CSV {
+		return newChannelExportCSVWriter(w)
+	}
+	return &channelExportJSONWriter{json.NewEncoder(w)}
+}
+
+// channelExportJSONWriter writes each row as a line of JSON holding the type of the row and the
+// exported object, like the lines of a bulk export.
+type channelExportJSONWriter struct {
+	encoder *json.Encoder
+}
+
+type channelExportJSONLine struct {
+	Type    string               `json:"type"`
+	Channel *model.Channel       `json:"channel,omitempty"`
+	Member  *model.ChannelMember `json:"member,omitempty"`
+	Post    *model.Post          `json:"post,omitempty"`
+	File    *model.FileInfo      `json:"file,omitempty"`
+}
+
+func (w *channelExportJSONWriter) WriteChannel(channel *model.Channel) error {
+	return w.encoder.Encode(&channelExportJSONLine{Type: "channel", Channel: channel})
+}
+
+func (w *channelExportJSONWriter) WriteMember(member *model.ChannelMember) error {
+	return w.encoder.Encode(&channelExportJSONLine{Type: "member", Member: member})
+}
+
+func (w *channelExportJSONWriter) WritePost(post *model.Post) error {
+	return w.encoder.Encode(&channelExportJSONLine{Type: "post", Post: post})
+}
+
+func (w *channelExportJSONWriter) WriteFile(info *model.FileInfo) error {
+	return w.encoder.Encode(&channelExportJSONLine{Type: "file", File: info})
+}
+
+func (w *channelExportJSONWriter) Flush() error {
+	return nil
+}
+
+// channelExportCSVHeader names the columns of a CSV channel export. Each row fills in only the columns
+// that apply to its type.
+var channelExportCSVHeader = []string{"Type", "Id", "CreateAt", "UpdateAt", "UserId", "RootId", "PostId", "Name", "Message", "Roles", "Size", "MimeType"}
+
+type channelExportCSVWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func newChannelExportCSVWriter(w io.Writer) *channelExportCSVWriter {
+	return &channelExportCSVWriter{writer: csv.NewWriter(w)}
+}
+
+func (w *channelExportCSVWriter) write(row map[string]string) error {
+	if !w.headerWritten {
+		if err := w.writer.Write(channelExportCSVHeader); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	record := make([]string, len(channelExportCSVHeader))
+	for i, column := range channelExportCSVHeader {
+		record[i] = row[column]
+	}
+
+	return w.writer.Write(record)
+}
+
+func (w *channelExportCSVWriter) WriteChannel(channel *model.Channel) error {
+	return w.write(map[string]string{
+		"Type":     "channel",
+		"Id":       channel.Id,
+		"CreateAt": strconv.FormatInt(channel.CreateAt, 10),
+		"UpdateAt": strconv.FormatInt(channel.UpdateAt, 10),
+		"UserId":   channel.CreatorId,
+		"Name":     channel.Name,
+		"Message":  channel.Purpose,
+	})
+}
+
+func (w *channelExportCSVWriter) WriteMember(member *model.ChannelMember) error {
+	return w.write(map[string]string{
+		"Type":     "member",
+		"UpdateAt": strconv.FormatInt(member.LastUpdateAt, 10),
+		"UserId":   member.UserId,
+		"Roles":    member.Roles,
+	})
+}
+
+func (w *channelExportCSVWriter) WritePost(post *model.Post) error {
+	return w.write(map[string]string{
+		"Type":     "post",
+		"Id":       post.Id,
+		"CreateAt": strconv.FormatInt(post.CreateAt, 10),
+		"UpdateAt": strconv.FormatInt(post.UpdateAt, 10),
+		"UserId":   post.UserId,
+		"RootId":   post.RootId,
+		"Message":  post.Message,
+	})
+}
+
+func (w *channelExportCSVWriter) WriteFile(info *model.FileInfo) error {
+	return w.write(map[string]string{
+		"Type":     "file",
+		"Id":       info.Id,
+		"CreateAt": strconv.FormatInt(info.CreateAt, 10),
+		"UpdateAt": strconv.FormatInt(info.UpdateAt, 10),
+		"UserId":   info.CreatorId,
+		"PostId":   info.PostId,
+		"Name":     info.Name,
+		"Size":     strconv.FormatInt(info.Size, 10),
+		"MimeType": info.MimeType,
+	})
+}
+
+func (w *channelExportCSVWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func writeTestChannelExport(t *testing.T, w channelExportWriter) {
+	require.NoError(t, w.WriteChannel(&model.Channel{Id: "channelid", Name: "channel", Purpose: "purpose, with a comma"}))
+	require.NoError(t, w.WriteMember(&model.ChannelMember{ChannelId: "channelid", UserId: "userid", Roles: "channel_user"}))
+	require.NoError(t, w.WritePost(&model.Post{Id: "postid", CreateAt: 1, UserId: "userid", Message: "multi\nline \"message\""}))
+	require.NoError(t, w.WriteFile(&model.FileInfo{Id: "fileid", PostId: "postid", Name: "file.txt", Size: 5, MimeType: "text/plain"}))
+	require.NoError(t, w.Flush())
+}
+
+func TestChannelExportCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writeTestChannelExport(t, newChannelExportWriter(model.CHANNEL_EXPORT_FORMAT_CSV, &buf))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+
+	assert.Equal(t, channelExportCSVHeader, records[0])
+	for _, record := range records {
+		assert.Len(t, record, len(channelExportCSVHeader))
+	}
+
+	assert.Equal(t, []string{"channel", "channelid", "0", "0", "", "", "", "channel", "purpose, with a comma", "", "", ""}, records[1])
+	assert.Equal(t, []string{"member", "", "", "0", "userid", "", "", "", "", "channel_user", "", ""}, records[2])
+	assert.Equal(t, []string{"post", "postid", "1", "0", "userid", "", "", "", "multi\nline \"message\"", "", "", ""}, records[3])
+	assert.Equal(t, []string{"file", "fileid", "0", "0", "", "", "postid", "file.txt", "", "", "5", "text/plain"}, records[4])
+}
+
+func TestChannelExportJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writeTestChannelExport(t, newChannelExportWriter(model.CHANNEL_EXPORT_FORMAT_JSON, &buf))
+
+	var lines []channelExportJSONLine
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line channelExportJSONLine
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.Len(t, lines, 4)
+
+	assert.Equal(t, "channel", lines[0].Type)
+	assert.Equal(t, "purpose, with a comma", lines[0].Channel.Purpose)
+	assert.Equal(t, "member", lines[1].Type)
+	assert.Equal(t, "userid", lines[1].Member.UserId)
+	assert.Equal(t, "post", lines[2].Type)
+	assert.Equal(t, "multi\nline \"message\"", lines[2].Post.Message)
+	assert.Equal(t, "file", lines[3].Type)
+	assert.Equal(t, "postid", lines[3].File.PostId)
+}
+
+func TestChannelExport(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+
+	th.CreatePost(th.BasicChannel)
+	th.CreatePost(th.BasicChannel)
+
+	t.Run("invalid format", func(t *testing.T) {
+		_, err := th.App.ExportChannel(pluginId, th.BasicChannel.Id, "xml")
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+	})
+
+	t.Run("unknown channel", func(t *testing.T) {
+		_, err := th.App.ExportChannel(pluginId, model.NewId(), model.CHANNEL_EXPORT_FORMAT_CSV)
+		require.NotNil(t, err)
+	})
+
+	for _, format := range []string{model.CHANNEL_EXPORT_FORMAT_CSV, model.CHANNEL_EXPORT_FORMAT_JSON} {
+		t.Run(format, func(t *testing.T) {
+			job, err := th.App.ExportChannel(pluginId, th.BasicChannel.Id, format)
+			require.Nil(t, err)
+
+			status, err := th.App.GetChannelExportStatus(pluginId, job.Id)
+			require.Nil(t, err)
+			assert.Equal(t, model.JOB_STATUS_PENDING, status.Status)
+			assert.Empty(t, status.FileId)
+
+			// Other plugins cannot see the export.
+			_, err = th.App.GetChannelExportStatus("otherpluginid", job.Id)
+			require.NotNil(t, err)
+			assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+			job.Status = model.JOB_STATUS_IN_PROGRESS
+			require.Nil(t, (<-th.App.Srv.Store.Job().UpdateOptimistically(job, model.JOB_STATUS_PENDING)).Err)
+			require.Nil(t, th.App.RunChannelExport(context.Background(), job))
+			require.Nil(t, th.App.Jobs.SetJobSuccess(job))
+
+			status, err = th.App.GetChannelExportStatus(pluginId, job.Id)
+			require.Nil(t, err)
+			assert.Equal(t, model.JOB_STATUS_SUCCESS, status.Status)
+			assert.Equal(t, int64(100), status.Progress)
+			assert.NotEmpty(t, status.FileId)
+
+			info, err := th.App.GetFileInfo(status.FileId)
+			require.Nil(t, err)
+			assert.Equal(t, status.Path, info.Path)
+
+			data, err := th.App.ReadFile(status.Path)
+			require.Nil(t, err)
+			assert.Contains(t, string(data), th.BasicUser.Id)
+			assert.Equal(t, int64(len(data)), info.Size)
+		})
+	}
+
+	t.Run("too many rows", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.MaxChannelExportRows = 1 })
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.MaxChannelExportRows = model.PLUGIN_SETTINGS_DEFAULT_MAX_CHANNEL_EXPORT_ROWS
+		})
+
+		job, err := th.App.ExportChannel(pluginId, th.BasicChannel.Id, model.CHANNEL_EXPORT_FORMAT_JSON)
+		require.Nil(t, err)
+
+		err = th.App.RunChannelExport(context.Background(), job)
+		require.NotNil(t, err)
+		assert.Equal(t, "app.channel_export.max_rows.app_error", err.Id)
+
+		// Incomplete exports are not left behind.
+		exists, err := th.App.FileExists(CHANNEL_EXPORT_DIRECTORY + job.Id + "." + model.CHANNEL_EXPORT_FORMAT_JSON)
+		require.Nil(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("expired exports", func(t *testing.T) {
+		job, err := th.App.ExportChannel(pluginId, th.BasicChannel.Id, model.CHANNEL_EXPORT_FORMAT_CSV)
+		require.Nil(t, err)
+		job.Status = model.JOB_STATUS_IN_PROGRESS
+		require.Nil(t, (<-th.App.Srv.Store.Job().UpdateOptimistically(job, model.JOB_STATUS_PENDING)).Err)
+		require.Nil(t, th.App.RunChannelExport(context.Background(), job))
+		require.Nil(t, th.App.Jobs.SetJobSuccess(job))
+
+		th.App.DeleteExpiredChannelExports()
+		_, err = th.App.GetChannelExportStatus(pluginId, job.Id)
+		require.Nil(t, err)
+
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.ChannelExportRetentionHours = -1 })
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.ChannelExportRetentionHours = model.PLUGIN_SETTINGS_DEFAULT_CHANNEL_EXPORT_RETENTION_HOURS
+		})
+
+		th.App.DeleteExpiredChannelExports()
+		_, err = th.App.GetChannelExportStatus(pluginId, job.Id)
+		require.NotNil(t, err)
+
+		exists, err := th.App.FileExists(CHANNEL_EXPORT_DIRECTORY + job.Id + "." + model.CHANNEL_EXPORT_FORMAT_CSV)
+		require.Nil(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestChannelExportCanceled(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	job, err := th.App.ExportChannel("testpluginid", th.BasicChannel.Id, model.CHANNEL_EXPORT_FORMAT_CSV)
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = th.App.RunChannelExport(ctx, job)
+	require.NotNil(t, err)
+	assert.Equal(t, "app.channel_export.canceled.app_error", err.Id)
+}
+
+func TestChannelExportFiltersPluginProps(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+			"github.com/mattermost/mattermost-server/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) PostsWillBeExported(c *plugin.Context, posts []*model.Post, exportType string) []*model.Post {
+			for _, post := range posts {
+				for key := range post.Props {
+					post.Props[key] = "redacted " + exportType
+				}
+			}
+			return posts
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+
+	require.Len(t, th.App.Plugins.Active(), 1)
+	pluginId := th.App.Plugins.Active()[0].Manifest.Id
+
+	post, err := th.App.CreatePost(&model.Post{
+		UserId:    th.BasicUser.Id,
+		ChannelId: th.BasicChannel.Id,
+		Message:   "message",
+		Props: model.StringInterface{
+			pluginId + "_secret": "value",
+			"otherplugin_secret": "value",
+		},
+	}, th.BasicChannel, false)
+	require.Nil(t, err)
+
+	job, err := th.App.ExportChannel(pluginId, th.BasicChannel.Id, model.CHANNEL_EXPORT_FORMAT_JSON)
+	require.Nil(t, err)
+	job.Status = model.JOB_STATUS_IN_PROGRESS
+	require.Nil(t, (<-th.App.Srv.Store.Job().UpdateOptimistically(job, model.JOB_STATUS_PENDING)).Err)
+	require.Nil(t, th.App.RunChannelExport(context.Background(), job))
+
+	data, err := th.App.ReadFile(job.Data[channelExportJobDataPath])
+	require.Nil(t, err)
+
+	var exported *model.Post
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var line channelExportJSONLine
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		if line.Type == "post" && line.Post.Id == post.Id {
+			exported = line.Post
+		}
+	}
+	require.NotNil(t, exported)
+
+	assert.Equal(t, "redacted "+model.CHANNEL_EXPORT_TYPE, exported.Props[pluginId+"_secret"])
+	assert.Equal(t, "value", exported.Props["otherplugin_secret"])
+}
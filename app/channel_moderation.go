@@ -0,0 +1,255 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// CHANNEL_MODERATION_SYSTEM_KEY_PREFIX prefixes the system keys recording the moderation of each
+// channel whose posting has been restricted.
+const CHANNEL_MODERATION_SYSTEM_KEY_PREFIX = "ChannelModeration_"
+
+const CHANNEL_MODERATION_MEMBERS_PER_PAGE = 1000
+
+func channelModerationSystemKey(channelId string) string {
+	return CHANNEL_MODERATION_SYSTEM_KEY_PREFIX + channelId
+}
+
+func (a *App) getModeratableChannel(channelId string) (*model.Channel, *model.AppError) {
+	channel, err := a.GetChannel(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	if channel.Type == model.CHANNEL_DIRECT || channel.Type == model.CHANNEL_GROUP {
+		return nil, model.NewAppError("getModeratableChannel", "app.channel.moderation.direct_channel.app_error", nil, "channel_id="+channelId, http.StatusBadRequest)
+	}
+
+	return channel, nil
+}
+
+// getStoredChannelModeration returns the moderation recorded for the channel, or nil if posting in
+// it has not been restricted.
+func (a *App) getStoredChannelModeration(channelId string) *model.ChannelModeration {
+	result := <-a.Srv.Store.System().GetByName(channelModerationSystemKey(channelId))
+	if result.Err != nil {
+		return nil
+	}
+
+	return model.ChannelModerationFromJson(strings.NewReader(result.Data.(*model.System).Value))
+}
+
+// GetChannelModeration reports whether the members of a channel may post, and whether posting has
+// been restricted with SetChannelModerationForMembers.
+func (a *App) GetChannelModeration(channelId string) (*model.ChannelModeration, *model.AppError) {
+	channel, err := a.getModeratableChannel(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	userRoleName, _, err := a.GetSchemeRolesForChannel(channel.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	userRole, err := a.GetRoleByName(userRoleName)
+	if err != nil {
+		return nil, err
+	}
+
+	moderation := a.getStoredChannelModeration(channel.Id)
+	if moderation == nil {
+		moderation = &model.ChannelModeration{ChannelId: channel.Id}
+	}
+	moderation.MembersCanPost = hasPermission(userRole.Permissions, model.PERMISSION_CREATE_POST.Id)
+
+	return moderation, nil
+}
+
+// SetChannelModerationForMembers sets whether the members of a channel may post. Restricting
+// posting moves the channel to a channel scheme copied from the roles it had, without permission
+// for channel users to post but with it for channel admins. Allowing posting again returns the
+// channel to the scheme it had before, and deletes the copy.
+func (a *App) SetChannelModerationForMembers(channelId string, allowPost bool) (*model.ChannelModeration, *model.AppError) {
+	channel, err := a.getModeratableChannel(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	if allowPost {
+		err = a.restoreChannelModeration(channel)
+	} else {
+		err = a.restrictChannelModeration(channel)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return a.GetChannelModeration(channel.Id)
+}
+
+func (a *App) restrictChannelModeration(channel *model.Channel) *model.AppError {
+	if a.getStoredChannelModeration(channel.Id) != nil {
+		return nil
+	}
+
+	userRoleName, adminRoleName, err := a.GetSchemeRolesForChannel(channel.Id)
+	if err != nil {
+		return err
+	}
+
+	userRole, err := a.GetRoleByName(userRoleName)
+	if err != nil {
+		return err
+	}
+
+	adminRole, err := a.GetRoleByName(adminRoleName)
+	if err != nil {
+		return err
+	}
+
+	scheme, err := a.CreateScheme(&model.Scheme{
+		Name:        model.NewId(),
+		DisplayName: "Moderation for " + channel.DisplayName,
+		Description: "Restricts posting in the channel to its admins.",
+		Scope:       model.SCHEME_SCOPE_CHANNEL,
+	})
+	if err != nil {
+		return err
+	}
+
+	moderation := &model.ChannelModeration{
+		ChannelId: channel.Id,
+		Moderated: true,
+		SchemeId:  scheme.Id,
+	}
+	if channel.SchemeId != nil {
+		moderation.PreviousSchemeId = *channel.SchemeId
+	}
+
+	if err = a.applyChannelModerationScheme(channel, scheme, userRole, adminRole, moderation); err != nil {
+		if _, deleteErr := a.DeleteScheme(scheme.Id); deleteErr != nil {
+			mlog.Error("Failed to delete channel moderation scheme", mlog.String("scheme_id", scheme.Id), mlog.Err(deleteErr))
+		}
+		return err
+	}
+
+	a.publishChannelSchemeUpdated(channel.Id)
+
+	return nil
+}
+
+func (a *App) applyChannelModerationScheme(channel *model.Channel, scheme *model.Scheme, userRole, adminRole *model.Role, moderation *model.ChannelModeration) *model.AppError {
+	var userPermissions []string
+	for _, permission := range userRole.Permissions {
+		if permission != model.PERMISSION_CREATE_POST.Id {
+			userPermissions = append(userPermissions, permission)
+		}
+	}
+
+	adminPermissions := append([]string(nil), adminRole.Permissions...)
+	if !hasPermission(adminPermissions, model.PERMISSION_CREATE_POST.Id) {
+		adminPermissions = append(adminPermissions, model.PERMISSION_CREATE_POST.Id)
+	}
+
+	if err := a.patchRolePermissions(scheme.DefaultChannelUserRole, userPermissions); err != nil {
+		return err
+	}
+
+	if err := a.patchRolePermissions(scheme.DefaultChannelAdminRole, adminPermissions); err != nil {
+		return err
+	}
+
+	if result := <-a.Srv.Store.System().SaveOrUpdate(&model.System{Name: channelModerationSystemKey(channel.Id), Value: moderation.ToJson()}); result.Err != nil {
+		return result.Err
+	}
+
+	channel.SchemeId = &scheme.Id
+	if _, err := a.UpdateChannelScheme(channel); err != nil {
+		<-a.Srv.Store.System().PermanentDeleteByName(channelModerationSystemKey(channel.Id))
+		return err
+	}
+
+	return nil
+}
+
+func (a *App) restoreChannelModeration(channel *model.Channel) *model.AppError {
+	moderation := a.getStoredChannelModeration(channel.Id)
+	if moderation == nil {
+		return nil
+	}
+
+	// The channel keeps any scheme chosen for it by an administrator while it was moderated.
+	if channel.SchemeId != nil && *channel.SchemeId == moderation.SchemeId {
+		channel.SchemeId = nil
+		if moderation.PreviousSchemeId != "" {
+			if previous, err := a.GetScheme(moderation.PreviousSchemeId); err == nil && previous.DeleteAt == 0 {
+				channel.SchemeId = &moderation.PreviousSchemeId
+			}
+		}
+
+		if _, err := a.UpdateChannelScheme(channel); err != nil {
+			return err
+		}
+	}
+
+	if result := <-a.Srv.Store.System().PermanentDeleteByName(channelModerationSystemKey(channel.Id)); result.Err != nil {
+		return result.Err
+	}
+
+	if _, err := a.DeleteScheme(moderation.SchemeId); err != nil {
+		mlog.Error("Failed to delete channel moderation scheme", mlog.String("scheme_id", moderation.SchemeId), mlog.Err(err))
+	}
+
+	a.publishChannelSchemeUpdated(channel.Id)
+
+	return nil
+}
+
+func (a *App) patchRolePermissions(roleName string, permissions []string) *model.AppError {
+	role, err := a.GetRoleByName(roleName)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.PatchRole(role, &model.RolePatch{Permissions: &permissions})
+	return err
+}
+
+// publishChannelSchemeUpdated invalidates the cached channel roles of the channel's members and
+// tells them to reload those roles, so that clients can enable or disable posting immediately.
+func (a *App) publishChannelSchemeUpdated(channelId string) {
+	for page := 0; ; page++ {
+		members, err := a.GetChannelMembersPage(channelId, page, CHANNEL_MODERATION_MEMBERS_PER_PAGE)
+		if err != nil {
+			mlog.Error("Failed to get channel members to invalidate their roles", mlog.String("channel_id", channelId), mlog.Err(err))
+			break
+		}
+
+		for _, member := range *members {
+			a.InvalidateCacheForUser(member.UserId)
+		}
+
+		if len(*members) < CHANNEL_MODERATION_MEMBERS_PER_PAGE {
+			break
+		}
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_CHANNEL_SCHEME_UPDATED, "", channelId, "", nil)
+	a.Publish(message)
+}
+
+func hasPermission(permissions []string, permissionId string) bool {
+	for _, permission := range permissions {
+		if permission == permissionId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestChannelModeration(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	// Mark the phase 2 permissions migration as completed.
+	<-th.App.Srv.Store.System().Save(&model.System{Name: model.MIGRATION_KEY_ADVANCED_PERMISSIONS_PHASE_2, Value: "true"})
+
+	defer func() {
+		<-th.App.Srv.Store.System().PermanentDeleteByName(model.MIGRATION_KEY_ADVANCED_PERMISSIONS_PHASE_2)
+	}()
+
+	// The channel's creator is its admin.
+	adminSession := model.Session{UserId: th.BasicUser.Id, Roles: model.SYSTEM_USER_ROLE_ID}
+	th.AddUserToChannel(th.BasicUser2, th.BasicChannel)
+	memberSession := model.Session{UserId: th.BasicUser2.Id, Roles: model.SYSTEM_USER_ROLE_ID}
+
+	canPost := func(session model.Session, channelId string) bool {
+		return th.App.SessionHasPermissionToChannel(session, channelId, model.PERMISSION_CREATE_POST)
+	}
+
+	t.Run("restrict and restore", func(t *testing.T) {
+		moderation, err := th.App.GetChannelModeration(th.BasicChannel.Id)
+		require.Nil(t, err)
+		assert.True(t, moderation.MembersCanPost)
+		assert.False(t, moderation.Moderated)
+
+		moderation, err = th.App.SetChannelModerationForMembers(th.BasicChannel.Id, false)
+		require.Nil(t, err)
+		assert.False(t, moderation.MembersCanPost)
+		assert.True(t, moderation.Moderated)
+		assert.Empty(t, moderation.PreviousSchemeId)
+
+		channel, err := th.App.GetChannel(th.BasicChannel.Id)
+		require.Nil(t, err)
+		require.NotNil(t, channel.SchemeId)
+		assert.Equal(t, moderation.SchemeId, *channel.SchemeId)
+
+		assert.False(t, canPost(memberSession, th.BasicChannel.Id))
+		assert.True(t, canPost(adminSession, th.BasicChannel.Id))
+
+		// Restricting a moderated channel again changes nothing.
+		again, err := th.App.SetChannelModerationForMembers(th.BasicChannel.Id, false)
+		require.Nil(t, err)
+		assert.Equal(t, moderation.SchemeId, again.SchemeId)
+
+		// Posts created by plugins are unaffected.
+		_, err = th.App.CreatePost(&model.Post{UserId: th.BasicUser2.Id, ChannelId: th.BasicChannel.Id, Message: "from a plugin"}, th.BasicChannel, false)
+		assert.Nil(t, err)
+
+		moderation, err = th.App.SetChannelModerationForMembers(th.BasicChannel.Id, true)
+		require.Nil(t, err)
+		assert.True(t, moderation.MembersCanPost)
+		assert.False(t, moderation.Moderated)
+
+		channel, err = th.App.GetChannel(th.BasicChannel.Id)
+		require.Nil(t, err)
+		assert.True(t, channel.SchemeId == nil || *channel.SchemeId == "")
+
+		assert.True(t, canPost(memberSession, th.BasicChannel.Id))
+
+		scheme, err := th.App.GetScheme(again.SchemeId)
+		if err == nil {
+			assert.NotZero(t, scheme.DeleteAt)
+		}
+	})
+
+	t.Run("restores the previous scheme", func(t *testing.T) {
+		previous := th.SetupChannelScheme()
+		th.BasicChannel.SchemeId = &previous.Id
+		_, err := th.App.UpdateChannelScheme(th.BasicChannel)
+		require.Nil(t, err)
+
+		moderation, err := th.App.SetChannelModerationForMembers(th.BasicChannel.Id, false)
+		require.Nil(t, err)
+		assert.Equal(t, previous.Id, moderation.PreviousSchemeId)
+		assert.NotEqual(t, previous.Id, moderation.SchemeId)
+		assert.False(t, canPost(memberSession, th.BasicChannel.Id))
+
+		_, err = th.App.SetChannelModerationForMembers(th.BasicChannel.Id, true)
+		require.Nil(t, err)
+
+		channel, err := th.App.GetChannel(th.BasicChannel.Id)
+		require.Nil(t, err)
+		require.NotNil(t, channel.SchemeId)
+		assert.Equal(t, previous.Id, *channel.SchemeId)
+		assert.True(t, canPost(memberSession, th.BasicChannel.Id))
+	})
+
+	t.Run("direct channel", func(t *testing.T) {
+		channel := th.CreateDmChannel(th.BasicUser2)
+
+		_, err := th.App.SetChannelModerationForMembers(channel.Id, false)
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+	})
+}
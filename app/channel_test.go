@@ -649,3 +649,40 @@ func TestRenameChannel(t *testing.T) {
 	assert.Equal(t, "newchannelname", channel.Name)
 	assert.Equal(t, "New Display Name", channel.DisplayName)
 }
+
+func TestGetChannelMembersModifiedSince(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	channel := th.CreateChannel(th.BasicTeam)
+	user1 := th.CreateUser()
+	th.LinkUserToTeam(user1, th.BasicTeam)
+	user2 := th.CreateUser()
+	th.LinkUserToTeam(user2, th.BasicTeam)
+
+	since := model.GetMillis() - 1
+	th.AddUserToChannel(user1, channel)
+	th.AddUserToChannel(user2, channel)
+
+	changes, err := th.App.GetChannelMembersModifiedSince(channel.Id, since)
+	require.Nil(t, err)
+	assert.Len(t, changes.Members, 3)
+	assert.Empty(t, changes.RemovedUserIds)
+
+	// A user that left is reported as removed
+	require.Nil(t, th.App.RemoveUserFromChannel(user1.Id, th.BasicUser.Id, channel))
+
+	changes, err = th.App.GetChannelMembersModifiedSince(channel.Id, since)
+	require.Nil(t, err)
+	assert.Len(t, changes.Members, 2)
+	assert.Equal(t, []string{user1.Id}, changes.RemovedUserIds)
+
+	// A user that left and rejoined is reported as a member
+	require.Nil(t, th.App.RemoveUserFromChannel(user2.Id, th.BasicUser.Id, channel))
+	th.AddUserToChannel(user2, channel)
+
+	changes, err = th.App.GetChannelMembersModifiedSince(channel.Id, since)
+	require.Nil(t, err)
+	assert.Len(t, changes.Members, 2)
+	assert.Equal(t, []string{user1.Id}, changes.RemovedUserIds)
+}
@@ -78,8 +78,13 @@ func (me *ClusterDiscoveryService) Stop() {
 	me.stop <- true
 }
 
+// IsHighAvailability returns whether the server runs as one node of a licensed cluster.
+func (a *App) IsHighAvailability() bool {
+	return a.License() != nil && *a.Config().ClusterSettings.Enable && a.Cluster != nil
+}
+
 func (a *App) IsLeader() bool {
-	if a.License() != nil && *a.Config().ClusterSettings.Enable && a.Cluster != nil {
+	if a.IsHighAvailability() {
 		return a.Cluster.IsLeader()
 	} else {
 		return true
@@ -4,8 +4,10 @@
 package app
 
 import (
+	"encoding/base64"
 	"strings"
 
+	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
 )
 
@@ -21,6 +23,9 @@ func (a *App) RegisterAllClusterMessageHandlers() {
 	a.Cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL, a.ClusterInvalidateCacheForChannelHandler)
 	a.Cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_USER, a.ClusterInvalidateCacheForUserHandler)
 	a.Cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_CLEAR_SESSION_CACHE_FOR_USER, a.ClusterClearSessionCacheForUserHandler)
+	a.Cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_CLOSE_WEB_CONNS_FOR_USER, a.ClusterCloseWebConnsForUserHandler)
+	a.Cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_PLUGIN_KEY_VALUE_HAS_CHANGED, a.ClusterPluginKeyValueHasChangedHandler)
+	a.Cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_PLUGIN_WEBAPP_REPLY, a.ClusterPluginWebappReplyHandler)
 }
 
 func (a *App) ClusterPublishHandler(msg *model.ClusterMessage) {
@@ -68,3 +73,21 @@ func (a *App) ClusterInvalidateCacheForUserHandler(msg *model.ClusterMessage) {
 func (a *App) ClusterClearSessionCacheForUserHandler(msg *model.ClusterMessage) {
 	a.ClearSessionCacheForUserSkipClusterSend(msg.Data)
 }
+
+func (a *App) ClusterCloseWebConnsForUserHandler(msg *model.ClusterMessage) {
+	a.CloseWebConnsForUserSkipClusterSend(msg.Data, msg.Props["session_token"])
+}
+
+func (a *App) ClusterPluginKeyValueHasChangedHandler(msg *model.ClusterMessage) {
+	a.notifyPluginOfKeyValueChangeSkipClusterSend(msg.Props["plugin_id"], msg.Props["key"])
+}
+
+func (a *App) ClusterPluginWebappReplyHandler(msg *model.ClusterMessage) {
+	payload, err := base64.StdEncoding.DecodeString(msg.Props["payload"])
+	if err != nil {
+		mlog.Error("Failed to decode plugin webapp reply", mlog.String("reply_id", msg.Props["reply_id"]), mlog.Err(err))
+		return
+	}
+
+	a.receivePluginWebappReplySkipClusterSend(msg.Props["plugin_id"], msg.Props["user_id"], msg.Props["reply_id"], payload)
+}
@@ -22,6 +22,8 @@ import (
 	"github.com/mattermost/mattermost-server/utils"
 )
 
+// Config returns the current configuration. The returned value is shared and must be treated as
+// read-only; use UpdateConfig or SaveConfig to make changes.
 func (a *App) Config() *model.Config {
 	if cfg := a.config.Load(); cfg != nil {
 		return cfg.(*model.Config)
@@ -36,11 +38,17 @@ func (a *App) EnvironmentConfig() map[string]interface{} {
 	return map[string]interface{}{}
 }
 
+// UpdateConfig applies f to a deep copy of the current configuration, including maps such as
+// PluginSettings.PluginStates, and then replaces the current configuration with the result.
+// Updates are serialized so that concurrent callers never lose each other's changes, and readers
+// holding the previous configuration never observe the mutation.
 func (a *App) UpdateConfig(f func(*model.Config)) {
+	a.configLock.Lock()
 	old := a.Config()
 	updated := old.Clone()
 	f(updated)
 	a.config.Store(updated)
+	a.configLock.Unlock()
 
 	a.InvokeConfigListeners(old, updated)
 }
@@ -50,8 +58,6 @@ func (a *App) PersistConfig() {
 }
 
 func (a *App) LoadConfig(configFile string) *model.AppError {
-	old := a.Config()
-
 	cfg, configPath, envConfig, err := utils.LoadConfig(configFile)
 	if err != nil {
 		return err
@@ -59,7 +65,10 @@ func (a *App) LoadConfig(configFile string) *model.AppError {
 
 	a.configFile = configPath
 
+	a.configLock.Lock()
+	old := a.Config()
 	a.config.Store(cfg)
+	a.configLock.Unlock()
 	a.envConfig = envConfig
 
 	a.siteURL = strings.TrimRight(*cfg.ServiceSettings.SiteURL, "/")
@@ -223,6 +232,10 @@ func (a *App) regenerateClientConfig() {
 		a.limitedClientConfig["AsymmetricSigningPublicKey"] = base64.StdEncoding.EncodeToString(der)
 	}
 
+	banner := a.getEffectivePluginBanner()
+	applyPluginBanner(a.clientConfig, banner)
+	applyPluginBanner(a.limitedClientConfig, banner)
+
 	clientConfigJSON, _ := json.Marshal(a.clientConfig)
 	a.clientConfigHash = fmt.Sprintf("%x", md5.Sum(clientConfigJSON))
 }
@@ -263,6 +276,19 @@ func (a *App) Desanitize(cfg *model.Config) {
 		*cfg.ElasticsearchSettings.Password = *actual.ElasticsearchSettings.Password
 	}
 
+	if *cfg.PluginSettings.KeyValueEncryptionKey == model.FAKE_SETTING {
+		*cfg.PluginSettings.KeyValueEncryptionKey = *actual.PluginSettings.KeyValueEncryptionKey
+	}
+	for i := range cfg.PluginSettings.PreviousKeyValueEncryptionKeys {
+		if cfg.PluginSettings.PreviousKeyValueEncryptionKeys[i] == model.FAKE_SETTING && i < len(actual.PluginSettings.PreviousKeyValueEncryptionKeys) {
+			cfg.PluginSettings.PreviousKeyValueEncryptionKeys[i] = actual.PluginSettings.PreviousKeyValueEncryptionKeys[i]
+		}
+	}
+	if *cfg.PluginSettings.SettingsEncryptionKey == model.FAKE_SETTING {
+		*cfg.PluginSettings.SettingsEncryptionKey = *actual.PluginSettings.SettingsEncryptionKey
+	}
+	desanitizePluginSecrets(cfg, actual, a.pluginSecretSettings())
+
 	for i := range cfg.SqlSettings.DataSourceReplicas {
 		cfg.SqlSettings.DataSourceReplicas[i] = actual.SqlSettings.DataSourceReplicas[i]
 	}
@@ -4,6 +4,8 @@
 package app
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -57,6 +59,68 @@ func TestConfigListener(t *testing.T) {
 	}
 }
 
+func TestConcurrentPluginStatesUpdate(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	// Read the plugin states from a config listener, as SyncPluginsActiveState does.
+	listenerId := th.App.AddConfigListener(func(oldConfig *model.Config, newConfig *model.Config) {
+		for _, state := range oldConfig.PluginSettings.PluginStates {
+			_ = state.Enable
+		}
+		for _, state := range newConfig.PluginSettings.PluginStates {
+			_ = state.Enable
+		}
+	})
+	defer th.App.RemoveConfigListener(listenerId)
+
+	const pluginCount = 10
+	const toggles = 20
+
+	var wg sync.WaitGroup
+	for i := 0; i < pluginCount; i++ {
+		pluginId := fmt.Sprintf("plugin%d", i)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < toggles; j++ {
+				enable := j%2 == 0
+				th.App.UpdateConfig(func(cfg *model.Config) {
+					cfg.PluginSettings.PluginStates[pluginId] = &model.PluginState{Enable: enable}
+				})
+
+				for _, state := range th.App.Config().PluginSettings.PluginStates {
+					_ = state.Enable
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The last toggle of every plugin disabled it, and no update may have been lost.
+	states := th.App.Config().PluginSettings.PluginStates
+	for i := 0; i < pluginCount; i++ {
+		state, ok := states[fmt.Sprintf("plugin%d", i)]
+		if assert.True(t, ok) {
+			assert.False(t, state.Enable)
+		}
+	}
+}
+
+func TestSaveConfigDoesNotMutateArgument(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	cfg := th.App.Config().Clone()
+	cfg.PluginSettings.PluginStates = nil
+
+	// Saving must not fill in defaults on the configuration that other goroutines may be reading.
+	th.App.SaveConfig(cfg, false)
+	assert.Nil(t, cfg.PluginSettings.PluginStates)
+}
+
 func TestAsymmetricSigningKey(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
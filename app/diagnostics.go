@@ -580,14 +580,11 @@ func (a *App) trackPlugins() {
 		settingsCount := 0
 
 		pluginStates := a.Config().PluginSettings.PluginStates
-		plugins, _ := a.Plugins.Available()
+		plugins, pluginErrors, _ := a.Plugins.AvailableWithErrors()
+		brokenManifestCount = len(pluginErrors)
 
 		if pluginStates != nil && plugins != nil {
 			for _, plugin := range plugins {
-				if plugin.Manifest == nil {
-					brokenManifestCount += 1
-					continue
-				}
 				if state, ok := pluginStates[plugin.Manifest.Id]; ok && state.Enable {
 					totalEnabledCount += 1
 					if plugin.Manifest.HasServer() {
@@ -435,7 +435,7 @@ func (a *App) DoUploadFileExpectModification(now time.Time, rawTeamId string, ra
 	}
 
 	if a.PluginsReady() {
-		pluginContext := &plugin.Context{}
+		pluginContext := newPluginContext()
 		var rejectionReason string
 		a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
 			var newBytes bytes.Buffer
@@ -604,3 +604,100 @@ func (a *App) GetFileInfo(fileId string) (*model.FileInfo, *model.AppError) {
 		return result.Data.(*model.FileInfo), nil
 	}
 }
+
+// GetFileThumbnail returns the JPEG thumbnail of an image file.
+func (a *App) GetFileThumbnail(fileId string) ([]byte, *model.AppError) {
+	info, err := a.GetFileInfo(fileId)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.ThumbnailPath == "" {
+		return nil, model.NewAppError("GetFileThumbnail", "app.file.get_file_thumbnail.no_thumbnail.app_error", nil, "file_id="+fileId, http.StatusBadRequest)
+	}
+
+	return a.ReadFile(info.ThumbnailPath)
+}
+
+// DeleteFile permanently deletes the file along with its thumbnail and preview, and detaches it from
+// the posts it is attached to, recording its id in their props so that clients can show that a file
+// was deleted. Deleting a file that no longer exists does nothing.
+func (a *App) DeleteFile(fileId string) *model.AppError {
+	result := <-a.Srv.Store.FileInfo().Get(fileId)
+	if result.Err != nil {
+		if result.Err.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return result.Err
+	}
+	info := result.Data.(*model.FileInfo)
+
+	// The file is detached from its posts first, so that no post is left showing a missing file.
+	if info.PostId != "" {
+		if err := a.detachFileFromPosts(info); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range []string{info.Path, info.ThumbnailPath, info.PreviewPath} {
+		if path == "" {
+			continue
+		}
+
+		// Files already removed by an earlier attempt are skipped, so that the deletion can be retried.
+		exists, err := a.FileExists(path)
+		if err != nil {
+			return err
+		}
+		if exists {
+			if err := a.RemoveFile(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	if result := <-a.Srv.Store.FileInfo().PermanentDelete(fileId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// detachFileFromPosts removes the file from the posts it is attached to, including their edit
+// history, and notifies clients of the posts still shown.
+func (a *App) detachFileFromPosts(info *model.FileInfo) *model.AppError {
+	result := <-a.Srv.Store.Post().GetSingle(info.PostId)
+	if result.Err != nil {
+		if result.Err.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return result.Err
+	}
+	channelId := result.Data.(*model.Post).ChannelId
+
+	result = <-a.Srv.Store.Post().GetPostsWithFileId(channelId, info.Id)
+	if result.Err != nil {
+		return result.Err
+	}
+
+	for _, post := range result.Data.([]*model.Post) {
+		if !post.DetachFile(info.Id) {
+			continue
+		}
+
+		if result := <-a.Srv.Store.Post().Overwrite(post); result.Err != nil {
+			return result.Err
+		}
+		a.Srv.Store.FileInfo().InvalidateFileInfosForPostCache(post.Id)
+
+		if post.DeleteAt == 0 {
+			message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POST_EDITED, "", post.ChannelId, "", nil)
+			message.Add("post", a.PostWithProxyAddedToImageURLs(post).ToJson())
+			a.Publish(message)
+		}
+	}
+
+	a.InvalidateCacheForChannelPosts(channelId)
+
+	return nil
+}
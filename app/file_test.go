@@ -4,7 +4,11 @@
 package app
 
 import (
+	"bytes"
 	"fmt"
+	"image"
+	"image/png"
+	"net/http"
 	"os"
 	"path/filepath"
 	"testing"
@@ -161,3 +165,106 @@ func TestMigrateFilenamesToFileInfos(t *testing.T) {
 	infos = th.App.MigrateFilenamesToFileInfos(rpost)
 	assert.Equal(t, 1, len(infos))
 }
+
+func uploadTestImage(t *testing.T, th *TestHelper) *model.FileInfo {
+	t.Helper()
+
+	var data bytes.Buffer
+	require.NoError(t, png.Encode(&data, image.NewRGBA(image.Rect(0, 0, 200, 200))))
+
+	info, appErr := th.App.DoUploadFile(time.Now(), th.BasicTeam.Id, th.BasicChannel.Id, th.BasicUser.Id, "test.png", data.Bytes())
+	require.Nil(t, appErr)
+	th.App.HandleImages([]string{info.PreviewPath}, []string{info.ThumbnailPath}, [][]byte{data.Bytes()})
+
+	return info
+}
+
+func TestGetFileThumbnail(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	info := uploadTestImage(t, th)
+	defer func() {
+		th.App.DeleteFile(info.Id)
+	}()
+
+	thumbnail, appErr := th.App.GetFileThumbnail(info.Id)
+	require.Nil(t, appErr)
+	_, format, err := image.DecodeConfig(bytes.NewReader(thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+
+	text, appErr := th.App.DoUploadFile(time.Now(), th.BasicTeam.Id, th.BasicChannel.Id, th.BasicUser.Id, "test.txt", []byte("abcd"))
+	require.Nil(t, appErr)
+	defer func() {
+		th.App.DeleteFile(text.Id)
+	}()
+
+	_, appErr = th.App.GetFileThumbnail(text.Id)
+	require.NotNil(t, appErr)
+	assert.Equal(t, "app.file.get_file_thumbnail.no_thumbnail.app_error", appErr.Id)
+
+	_, appErr = th.App.GetFileThumbnail(model.NewId())
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+}
+
+func TestDeleteFile(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	info := uploadTestImage(t, th)
+	other := uploadTestImage(t, th)
+	defer func() {
+		th.App.DeleteFile(other.Id)
+	}()
+
+	// The file is attached to several posts, including the edit history of one of them.
+	var posts []*model.Post
+	for i := 0; i < 2; i++ {
+		post, appErr := th.App.CreatePost(&model.Post{
+			UserId:    th.BasicUser.Id,
+			ChannelId: th.BasicChannel.Id,
+			Message:   "message",
+			FileIds:   model.StringArray{info.Id, other.Id},
+		}, th.BasicChannel, false)
+		require.Nil(t, appErr)
+		posts = append(posts, post)
+	}
+
+	edited := *posts[0]
+	edited.Message = "edited"
+	_, appErr := th.App.UpdatePost(&edited, false)
+	require.Nil(t, appErr)
+
+	require.Nil(t, th.App.DeleteFile(info.Id))
+
+	for _, path := range []string{info.Path, info.ThumbnailPath, info.PreviewPath} {
+		exists, appErr := th.App.FileExists(path)
+		require.Nil(t, appErr)
+		assert.False(t, exists, path)
+	}
+
+	_, appErr = th.App.GetFileInfo(info.Id)
+	require.NotNil(t, appErr)
+
+	result := <-th.App.Srv.Store.Post().GetPostsWithFileId(th.BasicChannel.Id, info.Id)
+	require.Nil(t, result.Err)
+	assert.Empty(t, result.Data.([]*model.Post))
+
+	for _, post := range posts {
+		post, appErr := th.App.GetSinglePost(post.Id)
+		require.Nil(t, appErr)
+		assert.Equal(t, model.StringArray{other.Id}, post.FileIds)
+		assert.Equal(t, []string{info.Id}, post.GetDeletedFileIds())
+	}
+
+	// Files attached to the posts alongside the deleted file are kept.
+	exists, appErr := th.App.FileExists(other.Path)
+	require.Nil(t, appErr)
+	assert.True(t, exists)
+
+	// Deleting the file again does nothing.
+	require.Nil(t, th.App.DeleteFile(info.Id))
+	require.Nil(t, th.App.DeleteFile(model.NewId()))
+}
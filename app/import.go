@@ -19,23 +19,29 @@ import (
 
 	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
 	"github.com/mattermost/mattermost-server/store"
 	"github.com/mattermost/mattermost-server/utils"
 )
 
+// Lines holding plugin key-value data can be much longer than bufio.Scanner allows by default.
+const BULK_IMPORT_MAX_LINE_SIZE = 64 * 1024 * 1024
+
 // Import Data Models
 
 type LineImportData struct {
 	Type          string                   `json:"type"`
-	Scheme        *SchemeImportData        `json:"scheme"`
-	Team          *TeamImportData          `json:"team"`
-	Channel       *ChannelImportData       `json:"channel"`
-	User          *UserImportData          `json:"user"`
-	Post          *PostImportData          `json:"post"`
-	DirectChannel *DirectChannelImportData `json:"direct_channel"`
-	DirectPost    *DirectPostImportData    `json:"direct_post"`
-	Emoji         *EmojiImportData         `json:"emoji"`
-	Version       *int                     `json:"version"`
+	Scheme        *SchemeImportData        `json:"scheme,omitempty"`
+	Team          *TeamImportData          `json:"team,omitempty"`
+	Channel       *ChannelImportData       `json:"channel,omitempty"`
+	User          *UserImportData          `json:"user,omitempty"`
+	Post          *PostImportData          `json:"post,omitempty"`
+	DirectChannel *DirectChannelImportData `json:"direct_channel,omitempty"`
+	DirectPost    *DirectPostImportData    `json:"direct_post,omitempty"`
+	Emoji         *EmojiImportData         `json:"emoji,omitempty"`
+	PluginData    *PluginDataImportData    `json:"plugin_data,omitempty"`
+	PluginKV      *PluginKVImportData      `json:"plugin_kv,omitempty"`
+	Version       *int                     `json:"version,omitempty"`
 }
 
 type TeamImportData struct {
@@ -121,6 +127,26 @@ type EmojiImportData struct {
 	Image *string `json:"image"`
 }
 
+type PluginDataImportData struct {
+	PluginId  *string                     `json:"plugin_id"`
+	KeyValues *[]PluginKeyValueImportData `json:"key_values"`
+}
+
+type PluginKeyValueImportData struct {
+	Key      *string `json:"key"`
+	Value    *[]byte `json:"value"`
+	ExpireAt *int64  `json:"expire_at"`
+}
+
+// PluginKVImportData holds a single key-value pair of a plugin. The value is base64 encoded in the
+// import file.
+type PluginKVImportData struct {
+	PluginId *string `json:"plugin_id"`
+	Key      *string `json:"key"`
+	Value    *[]byte `json:"value"`
+	ExpireAt *int64  `json:"expire_at"`
+}
+
 type ReactionImportData struct {
 	User      *string `json:"user"`
 	CreateAt  *int64  `json:"create_at"`
@@ -209,22 +235,33 @@ type AttachmentImportData struct {
 // still enforced.
 //
 
-func (a *App) bulkImportWorker(dryRun bool, wg *sync.WaitGroup, lines <-chan LineImportWorkerData, errors chan<- LineImportWorkerError) {
+func (a *App) bulkImportWorker(dryRun bool, op *PluginBulkOperation, wg *sync.WaitGroup, lines <-chan LineImportWorkerData, errors chan<- LineImportWorkerError) {
 	for line := range lines {
-		if err := a.ImportLine(line.LineImportData, dryRun); err != nil {
+		if err := a.importLine(line.LineImportData, dryRun, op); err != nil {
 			errors <- LineImportWorkerError{err, line.LineNumber}
 		}
 	}
 	wg.Done()
 }
 
-func (a *App) BulkImport(fileReader io.Reader, dryRun bool, workers int) (*model.AppError, int) {
+// BulkImport imports the data of the given bulk import file. If skipPluginData is true, the key-value
+// data of plugins held in the file is neither validated nor imported.
+func (a *App) BulkImport(fileReader io.Reader, dryRun bool, workers int, skipPluginData bool) (*model.AppError, int) {
 	scanner := bufio.NewScanner(fileReader)
+	scanner.Buffer(nil, BULK_IMPORT_MAX_LINE_SIZE)
 	lineNumber := 0
 
 	a.Srv.Store.LockToMaster()
 	defer a.Srv.Store.UnlockFromMaster()
 
+	// Plugins are told of the users and team memberships created once the import ends, even if it
+	// fails part way through, since the lines already imported are kept.
+	var op *PluginBulkOperation
+	if !dryRun {
+		op = a.NewPluginBulkOperation(model.BULK_OPERATION_TYPE_IMPORT)
+		defer op.Complete()
+	}
+
 	errorsChan := make(chan LineImportWorkerError, (2*workers)+1) // size chosen to ensure it never gets filled up completely.
 	var wg sync.WaitGroup
 	var linesChan chan LineImportWorkerData
@@ -248,6 +285,10 @@ func (a *App) BulkImport(fileReader io.Reader, dryRun bool, workers int) (*model
 					return model.NewAppError("BulkImport", "app.import.bulk_import.unsupported_version.error", nil, "", http.StatusBadRequest), lineNumber
 				}
 			} else {
+				if skipPluginData && (line.Type == "plugin_kv" || line.Type == "plugin_data") {
+					continue
+				}
+
 				if line.Type != lastLineType {
 					if lastLineType != "" {
 						// Changing type. Clear out the worker queue before continuing.
@@ -266,7 +307,7 @@ func (a *App) BulkImport(fileReader io.Reader, dryRun bool, workers int) (*model
 					linesChan = make(chan LineImportWorkerData, workers)
 					for i := 0; i < workers; i++ {
 						wg.Add(1)
-						go a.bulkImportWorker(dryRun, &wg, linesChan, errorsChan)
+						go a.bulkImportWorker(dryRun, op, &wg, linesChan, errorsChan)
 					}
 				}
 
@@ -282,8 +323,10 @@ func (a *App) BulkImport(fileReader io.Reader, dryRun bool, workers int) (*model
 	}
 
 	// No more lines. Clear out the worker queue before continuing.
-	close(linesChan)
-	wg.Wait()
+	if linesChan != nil {
+		close(linesChan)
+		wg.Wait()
+	}
 
 	// Check no errors occurred while waiting for the queue to empty.
 	if len(errorsChan) != 0 {
@@ -295,6 +338,13 @@ func (a *App) BulkImport(fileReader io.Reader, dryRun bool, workers int) (*model
 		return model.NewAppError("BulkImport", "app.import.bulk_import.file_scan.error", nil, err.Error(), http.StatusInternalServerError), 0
 	}
 
+	if !dryRun && a.PluginsReady() {
+		a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
+			hooks.OnDataImported()
+			return true
+		}, plugin.OnDataImportedId)
+	}
+
 	return nil, 0
 }
 
@@ -307,6 +357,12 @@ func processImportDataFileVersionLine(line LineImportData) (int, *model.AppError
 }
 
 func (a *App) ImportLine(line LineImportData, dryRun bool) *model.AppError {
+	return a.importLine(line, dryRun, nil)
+}
+
+// importLine imports a line of a bulk import file, recording the users and team memberships it
+// creates in the given operation, if any.
+func (a *App) importLine(line LineImportData, dryRun bool, op *PluginBulkOperation) *model.AppError {
 	switch {
 	case line.Type == "scheme":
 		if line.Scheme == nil {
@@ -330,7 +386,7 @@ func (a *App) ImportLine(line LineImportData, dryRun bool) *model.AppError {
 		if line.User == nil {
 			return model.NewAppError("BulkImport", "app.import.import_line.null_user.error", nil, "", http.StatusBadRequest)
 		} else {
-			return a.ImportUser(line.User, dryRun)
+			return a.importUser(line.User, dryRun, op)
 		}
 	case line.Type == "post":
 		if line.Post == nil {
@@ -356,6 +412,18 @@ func (a *App) ImportLine(line LineImportData, dryRun bool) *model.AppError {
 		} else {
 			return a.ImportEmoji(line.Emoji, dryRun)
 		}
+	case line.Type == "plugin_data":
+		if line.PluginData == nil {
+			return model.NewAppError("BulkImport", "app.import.import_line.null_plugin_data.error", nil, "", http.StatusBadRequest)
+		} else {
+			return a.ImportPluginData(line.PluginData, dryRun)
+		}
+	case line.Type == "plugin_kv":
+		if line.PluginKV == nil {
+			return model.NewAppError("BulkImport", "app.import.import_line.null_plugin_kv.error", nil, "", http.StatusBadRequest)
+		} else {
+			return a.ImportPluginKV(line.PluginKV, dryRun)
+		}
 	default:
 		return model.NewAppError("BulkImport", "app.import.import_line.unknown_line_type.error", map[string]interface{}{"Type": line.Type}, "", http.StatusBadRequest)
 	}
@@ -763,6 +831,10 @@ func validateChannelImportData(data *ChannelImportData) *model.AppError {
 }
 
 func (a *App) ImportUser(data *UserImportData, dryRun bool) *model.AppError {
+	return a.importUser(data, dryRun, nil)
+}
+
+func (a *App) importUser(data *UserImportData, dryRun bool, op *PluginBulkOperation) *model.AppError {
 	if err := validateUserImportData(data); err != nil {
 		return err
 	}
@@ -953,6 +1025,7 @@ func (a *App) ImportUser(data *UserImportData, dryRun bool) *model.AppError {
 		if savedUser, err = a.createUser(user); err != nil {
 			return err
 		}
+		op.UserCreated(savedUser)
 	} else {
 		if hasUserChanged {
 			if savedUser, err = a.UpdateUser(user, false); err != nil {
@@ -1066,10 +1139,14 @@ func (a *App) ImportUser(data *UserImportData, dryRun bool) *model.AppError {
 		}
 	}
 
-	return a.ImportUserTeams(savedUser, data.Teams)
+	return a.importUserTeams(savedUser, data.Teams, op)
 }
 
 func (a *App) ImportUserTeams(user *model.User, data *[]UserTeamImportData) *model.AppError {
+	return a.importUserTeams(user, data, nil)
+}
+
+func (a *App) importUserTeams(user *model.User, data *[]UserTeamImportData, op *PluginBulkOperation) *model.AppError {
 	if data == nil {
 		return nil
 	}
@@ -1102,9 +1179,13 @@ func (a *App) ImportUserTeams(user *model.User, data *[]UserTeamImportData) *mod
 		}
 
 		var member *model.TeamMember
-		if member, _, err = a.joinUserToTeam(team, user); err != nil {
+		var alreadyAdded bool
+		if member, alreadyAdded, err = a.joinUserToTeam(team, user); err != nil {
 			return err
 		}
+		if !alreadyAdded {
+			op.TeamMemberChanged(member)
+		}
 
 		if member.ExplicitRoles != roles {
 			if _, err := a.UpdateTeamMemberRoles(team.Id, user.Id, roles); err != nil {
@@ -2072,6 +2153,99 @@ func validateEmojiImportData(data *EmojiImportData) *model.AppError {
 	return nil
 }
 
+func (a *App) ImportPluginData(data *PluginDataImportData, dryRun bool) *model.AppError {
+	if err := validatePluginDataImportData(data); err != nil {
+		return err
+	}
+
+	// Values must fit within the configured limit once stored, which is checked even on a dry run.
+	for _, kv := range *data.KeyValues {
+		storedValue, err := a.encodeStoredPluginKeyValue(*kv.Value)
+		if err != nil {
+			return err
+		}
+
+		if err := a.isValidPluginKeyValue(&model.PluginKeyValue{
+			PluginId: *data.PluginId,
+			Key:      *kv.Key,
+			Value:    storedValue,
+			RawKey:   *kv.Key,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// If this is a Dry Run, do not continue any further.
+	if dryRun {
+		return nil
+	}
+
+	now := model.GetMillis()
+	for _, kv := range *data.KeyValues {
+		if kv.ExpireAt == nil || *kv.ExpireAt == 0 {
+			if err := a.SetPluginKey(*data.PluginId, *kv.Key, *kv.Value); err != nil {
+				return err
+			}
+		} else if *kv.ExpireAt > now {
+			// Keys expire at the same time as they would have on the exporting server, rounded up to
+			// the second.
+			expireInSeconds := (*kv.ExpireAt - now + 999) / 1000
+			if err := a.SetPluginKeyWithExpiry(*data.PluginId, *kv.Key, *kv.Value, expireInSeconds); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ImportPluginKV imports a single key-value pair of a plugin, with the same validation and size
+// limits as ImportPluginData.
+func (a *App) ImportPluginKV(data *PluginKVImportData, dryRun bool) *model.AppError {
+	if data == nil {
+		return model.NewAppError("BulkImport", "app.import.validate_plugin_data_import_data.empty.error", nil, "", http.StatusBadRequest)
+	}
+
+	return a.ImportPluginData(&PluginDataImportData{
+		PluginId: data.PluginId,
+		KeyValues: &[]PluginKeyValueImportData{{
+			Key:      data.Key,
+			Value:    data.Value,
+			ExpireAt: data.ExpireAt,
+		}},
+	}, dryRun)
+}
+
+func validatePluginDataImportData(data *PluginDataImportData) *model.AppError {
+	if data == nil {
+		return model.NewAppError("BulkImport", "app.import.validate_plugin_data_import_data.empty.error", nil, "", http.StatusBadRequest)
+	}
+
+	if data.PluginId == nil || !plugin.IsValidId(*data.PluginId) {
+		return model.NewAppError("BulkImport", "app.import.validate_plugin_data_import_data.plugin_id_invalid.error", nil, "", http.StatusBadRequest)
+	}
+
+	if data.KeyValues == nil || len(*data.KeyValues) == 0 {
+		return model.NewAppError("BulkImport", "app.import.validate_plugin_data_import_data.key_values_missing.error", nil, "", http.StatusBadRequest)
+	}
+
+	for _, kv := range *data.KeyValues {
+		if kv.Key == nil || len(*kv.Key) == 0 || utf8.RuneCountInString(*kv.Key) > model.KEY_VALUE_KEY_MAX_RUNES {
+			return model.NewAppError("BulkImport", "app.import.validate_plugin_data_import_data.key_invalid.error", map[string]interface{}{"PluginId": *data.PluginId}, "", http.StatusBadRequest)
+		}
+
+		if kv.Value == nil {
+			return model.NewAppError("BulkImport", "app.import.validate_plugin_data_import_data.value_missing.error", map[string]interface{}{"PluginId": *data.PluginId, "Key": *kv.Key}, "", http.StatusBadRequest)
+		}
+
+		if kv.ExpireAt != nil && *kv.ExpireAt < 0 {
+			return model.NewAppError("BulkImport", "app.import.validate_plugin_data_import_data.expire_at_invalid.error", map[string]interface{}{"PluginId": *data.PluginId, "Key": *kv.Key}, "", http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
 //
 // -- Old SlackImport Functions --
 // Import functions are sutible for entering posts and users into the database without
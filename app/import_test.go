@@ -3768,6 +3768,12 @@ func TestImportImportLine(t *testing.T) {
 	if err := th.App.ImportLine(line, false); err == nil {
 		t.Fatalf("Expected an error when importing a line with type scheme with a nil scheme.")
 	}
+
+	// Try import line with plugin_kv type but nil plugin_kv.
+	line.Type = "plugin_kv"
+	if err := th.App.ImportLine(line, false); err == nil {
+		t.Fatalf("Expected an error when importing a line with type plugin_kv with a nil plugin_kv.")
+	}
 }
 
 func TestImportBulkImport(t *testing.T) {
@@ -3799,13 +3805,13 @@ func TestImportBulkImport(t *testing.T) {
 {"type": "direct_post", "direct_post": {"channel_members": ["` + username + `", "` + username2 + `", "` + username3 + `"], "user": "` + username + `", "message": "Hello Group Channel", "create_at": 123456789014}}
 {"type": "emoji", "emoji": {"name": "` + emojiName + `", "image": "` + testImage + `"}}`
 
-	if err, line := th.App.BulkImport(strings.NewReader(data1), false, 2); err != nil || line != 0 {
+	if err, line := th.App.BulkImport(strings.NewReader(data1), false, 2, false); err != nil || line != 0 {
 		t.Fatalf("BulkImport should have succeeded: %v, %v", err.Error(), line)
 	}
 
 	// Run bulk import using a string that contains a line with invalid json.
 	data2 := `{"type": "version", "version": 1`
-	if err, line := th.App.BulkImport(strings.NewReader(data2), false, 2); err == nil || line != 1 {
+	if err, line := th.App.BulkImport(strings.NewReader(data2), false, 2, false); err == nil || line != 1 {
 		t.Fatalf("Should have failed due to invalid JSON on line 1.")
 	}
 
@@ -3814,7 +3820,7 @@ func TestImportBulkImport(t *testing.T) {
 {"type": "channel", "channel": {"type": "O", "display_name": "xr6m6udffngark2uekvr3hoeny", "team": "` + teamName + `", "name": "` + channelName + `"}}
 {"type": "user", "user": {"username": "kufjgnkxkrhhfgbrip6qxkfsaa", "email": "kufjgnkxkrhhfgbrip6qxkfsaa@example.com"}}
 {"type": "user", "user": {"username": "bwshaim6qnc2ne7oqkd5b2s2rq", "email": "bwshaim6qnc2ne7oqkd5b2s2rq@example.com", "teams": [{"name": "` + teamName + `", "channels": [{"name": "` + channelName + `"}]}]}}`
-	if err, line := th.App.BulkImport(strings.NewReader(data3), false, 2); err == nil || line != 1 {
+	if err, line := th.App.BulkImport(strings.NewReader(data3), false, 2, false); err == nil || line != 1 {
 		t.Fatalf("Should have failed due to missing version line on line 1.")
 	}
 }
@@ -4097,3 +4103,157 @@ func AssertFileIdsInPost(files []*model.FileInfo, th *TestHelper, t *testing.T)
 		}
 	}
 }
+
+func TestImportValidatePluginDataImportData(t *testing.T) {
+	value := []byte("value")
+	data := PluginDataImportData{
+		PluginId: ptrStr("com.example.plugin"),
+		KeyValues: &[]PluginKeyValueImportData{
+			{Key: ptrStr("key"), Value: &value},
+			{Key: ptrStr("expiring"), Value: &value, ExpireAt: ptrInt64(model.GetMillis() + 60000)},
+		},
+	}
+
+	err := validatePluginDataImportData(&data)
+	assert.Nil(t, err, "Validation should succeed")
+
+	err = validatePluginDataImportData(nil)
+	assert.NotNil(t, err)
+
+	*data.PluginId = "invalid plugin id"
+	err = validatePluginDataImportData(&data)
+	assert.NotNil(t, err)
+
+	data.PluginId = nil
+	err = validatePluginDataImportData(&data)
+	assert.NotNil(t, err)
+
+	data.PluginId = ptrStr("com.example.plugin")
+	data.KeyValues = &[]PluginKeyValueImportData{}
+	err = validatePluginDataImportData(&data)
+	assert.NotNil(t, err)
+
+	data.KeyValues = &[]PluginKeyValueImportData{{Key: ptrStr(""), Value: &value}}
+	err = validatePluginDataImportData(&data)
+	assert.NotNil(t, err)
+
+	data.KeyValues = &[]PluginKeyValueImportData{{Key: ptrStr(strings.Repeat("k", model.KEY_VALUE_KEY_MAX_RUNES+1)), Value: &value}}
+	err = validatePluginDataImportData(&data)
+	assert.NotNil(t, err)
+
+	data.KeyValues = &[]PluginKeyValueImportData{{Key: ptrStr("key")}}
+	err = validatePluginDataImportData(&data)
+	assert.NotNil(t, err)
+
+	data.KeyValues = &[]PluginKeyValueImportData{{Key: ptrStr("key"), Value: &value, ExpireAt: ptrInt64(-1)}}
+	err = validatePluginDataImportData(&data)
+	assert.NotNil(t, err)
+}
+
+func TestImportImportPluginData(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.EnableKeyValueCompression = false
+		*cfg.PluginSettings.MaxKeyValueSizeBytes = 100
+	})
+
+	pluginId := "com.example." + model.NewId()
+	defer func() {
+		th.App.Srv.Store.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	value := []byte("value")
+	expired := []byte("expired")
+	data := PluginDataImportData{
+		PluginId: ptrStr(pluginId),
+		KeyValues: &[]PluginKeyValueImportData{
+			{Key: ptrStr("key"), Value: &value},
+			{Key: ptrStr("expiring"), Value: &value, ExpireAt: ptrInt64(model.GetMillis() + 60000)},
+			{Key: ptrStr("expired"), Value: &expired, ExpireAt: ptrInt64(model.GetMillis() - 1000)},
+		},
+	}
+
+	err := th.App.ImportPluginData(&data, true)
+	assert.Nil(t, err, "Valid plugin data should have passed dry run")
+
+	ret, err := th.App.GetPluginKey(pluginId, "key")
+	assert.Nil(t, err)
+	assert.Nil(t, ret, "Plugin data should not have been imported")
+
+	err = th.App.ImportPluginData(&data, false)
+	assert.Nil(t, err, "Valid plugin data should have succeeded apply mode")
+
+	for key, expected := range map[string][]byte{"key": value, "expiring": value, "expired": nil} {
+		ret, err = th.App.GetPluginKey(pluginId, key)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, ret, key)
+	}
+
+	large := make([]byte, 101)
+	data.KeyValues = &[]PluginKeyValueImportData{{Key: ptrStr("large"), Value: &large}}
+	err = th.App.ImportPluginData(&data, true)
+	if assert.NotNil(t, err, "Values over the size limit should have failed dry run") {
+		assert.Equal(t, "model.plugin_key_value.is_valid.value.app_error", err.Id)
+	}
+
+	err = th.App.ImportPluginData(&data, false)
+	assert.NotNil(t, err, "Values over the size limit should have failed apply mode")
+}
+
+func TestImportImportPluginKV(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.EnableKeyValueCompression = false
+		*cfg.PluginSettings.MaxKeyValueSizeBytes = 100
+	})
+
+	pluginId := "com.example." + model.NewId()
+	defer func() {
+		th.App.Srv.Store.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	value := []byte("value")
+	data := PluginKVImportData{
+		PluginId: ptrStr(pluginId),
+		Key:      ptrStr("key"),
+		Value:    &value,
+	}
+
+	err := th.App.ImportPluginKV(&data, true)
+	assert.Nil(t, err, "Valid plugin key-value should have passed dry run")
+
+	ret, err := th.App.GetPluginKey(pluginId, "key")
+	assert.Nil(t, err)
+	assert.Nil(t, ret, "Plugin key-value should not have been imported")
+
+	err = th.App.ImportPluginKV(&data, false)
+	assert.Nil(t, err, "Valid plugin key-value should have succeeded apply mode")
+
+	ret, err = th.App.GetPluginKey(pluginId, "key")
+	assert.Nil(t, err)
+	assert.Equal(t, value, ret)
+
+	err = th.App.ImportPluginKV(nil, true)
+	assert.NotNil(t, err)
+
+	data.Key = ptrStr("")
+	err = th.App.ImportPluginKV(&data, true)
+	assert.NotNil(t, err, "An empty key should have failed dry run")
+
+	data.Key = ptrStr("missing")
+	data.Value = nil
+	err = th.App.ImportPluginKV(&data, true)
+	assert.NotNil(t, err, "A missing value should have failed dry run")
+
+	large := make([]byte, 101)
+	data.Key = ptrStr("large")
+	data.Value = &large
+	err = th.App.ImportPluginKV(&data, false)
+	if assert.NotNil(t, err, "Values over the size limit should have failed apply mode") {
+		assert.Equal(t, "model.plugin_key_value.is_valid.value.app_error", err.Id)
+	}
+}
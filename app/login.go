@@ -68,7 +68,7 @@ func (a *App) AuthenticateUserForLogin(id, loginId, password, mfaToken string, l
 
 	if a.PluginsReady() {
 		var rejectionReason string
-		pluginContext := &plugin.Context{}
+		pluginContext := newPluginContext()
 		a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
 			rejectionReason = hooks.UserWillLogIn(pluginContext, user)
 			return rejectionReason == ""
@@ -78,13 +78,9 @@ func (a *App) AuthenticateUserForLogin(id, loginId, password, mfaToken string, l
 			return nil, model.NewAppError("AuthenticateUserForLogin", "Login rejected by plugin: "+rejectionReason, nil, "", http.StatusBadRequest)
 		}
 
-		a.Go(func() {
-			pluginContext := &plugin.Context{}
-			a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
-				hooks.UserHasLoggedIn(pluginContext, user)
-				return true
-			}, plugin.UserHasLoggedInId)
-		})
+		a.Plugins.RunMultiPluginHookAsync(func(hooks plugin.Hooks) {
+			hooks.UserHasLoggedIn(pluginContext, user)
+		}, plugin.UserHasLoggedInId)
 	}
 
 	return user, nil
@@ -22,6 +22,12 @@ const (
 )
 
 func (a *App) SendNotifications(post *model.Post, team *model.Team, channel *model.Channel, sender *model.User, parentPostList *model.PostList) ([]string, *model.AppError) {
+	return a.sendNotifications(post, team, channel, sender, parentPostList, true)
+}
+
+// sendNotifications processes mentions and publishes the post to the channel. Email and push
+// notifications are only sent when notifyUsers is set.
+func (a *App) sendNotifications(post *model.Post, team *model.Team, channel *model.Channel, sender *model.User, parentPostList *model.PostList, notifyUsers bool) ([]string, *model.AppError) {
 	pchan := a.Srv.Store.User().GetAllProfilesInChannel(channel.Id, true)
 	cmnchan := a.Srv.Store.Channel().GetAllChannelMembersNotifyPropsForChannel(channel.Id, true)
 	var fchan store.StoreChannel
@@ -119,6 +125,27 @@ func (a *App) SendNotifications(post *model.Post, team *model.Team, channel *mod
 			}
 		}
 
+		// get users that are explicitly following the thread
+		if len(post.RootId) > 0 {
+			if followerIds, err := a.getThreadFollowerIds(post.RootId); err != nil {
+				mlog.Warn("Failed to get thread followers", mlog.String("post_id", post.Id), mlog.Err(err))
+			} else {
+				for _, followerId := range followerIds {
+					if profileMap[followerId] == nil {
+						continue
+					}
+
+					if _, ok := threadMentionedUserIds[followerId]; !ok {
+						threadMentionedUserIds[followerId] = THREAD_ANY
+					}
+
+					if _, ok := mentionedUserIds[followerId]; !ok {
+						mentionedUserIds[followerId] = false
+					}
+				}
+			}
+		}
+
 		// prevent the user from mentioning themselves
 		if post.Props["from_webhook"] != "true" {
 			delete(mentionedUserIds, post.UserId)
@@ -180,27 +207,12 @@ func (a *App) SendNotifications(post *model.Post, team *model.Team, channel *mod
 		channelName = channel.DisplayName
 	}
 
-	if a.Config().EmailSettings.SendEmailNotifications {
+	if a.Config().EmailSettings.SendEmailNotifications && notifyUsers {
 		for _, id := range mentionedUsersList {
 			if profileMap[id] == nil {
 				continue
 			}
 
-			userAllowsEmails := profileMap[id].NotifyProps[model.EMAIL_NOTIFY_PROP] != "false"
-			if channelEmail, ok := channelMemberNotifyPropsMap[id][model.EMAIL_NOTIFY_PROP]; ok {
-				if channelEmail != model.CHANNEL_NOTIFY_DEFAULT {
-					userAllowsEmails = channelEmail != "false"
-				}
-			}
-
-			// Remove the user as recipient when the user has muted the channel.
-			if channelMuted, ok := channelMemberNotifyPropsMap[id][model.MARK_UNREAD_NOTIFY_PROP]; ok {
-				if channelMuted == model.CHANNEL_MARK_UNREAD_MENTION {
-					mlog.Debug(fmt.Sprintf("Channel muted for user_id %v, channel_mute %v", id, channelMuted))
-					userAllowsEmails = false
-				}
-			}
-
 			//If email verification is required and user email is not verified don't send email.
 			if a.Config().EmailSettings.RequireEmailVerification && !profileMap[id].EmailVerified {
 				mlog.Error(fmt.Sprintf("Skipped sending notification email to %v, address not verified. [details: user_id=%v]", profileMap[id].Email, id))
@@ -219,9 +231,7 @@ func (a *App) SendNotifications(post *model.Post, team *model.Team, channel *mod
 				}
 			}
 
-			autoResponderRelated := status.Status == model.STATUS_OUT_OF_OFFICE || post.Type == model.POST_AUTO_RESPONDER
-
-			if userAllowsEmails && status.Status != model.STATUS_ONLINE && profileMap[id].DeleteAt == 0 && !autoResponderRelated {
+			if ShouldSendEmailNotification(profileMap[id], channelMemberNotifyPropsMap[id], status, post) {
 				a.sendNotificationEmail(post, profileMap[id], channel, team, channelName, senderName, sender)
 			}
 		}
@@ -275,18 +285,7 @@ func (a *App) SendNotifications(post *model.Post, team *model.Team, channel *mod
 		}
 	}
 
-	sendPushNotifications := false
-	if *a.Config().EmailSettings.SendPushNotifications {
-		pushServer := *a.Config().EmailSettings.PushNotificationServer
-		if license := a.License(); pushServer == model.MHPNS && (license == nil || !*license.Features.MHPNS) {
-			mlog.Warn("api.post.send_notifications_and_forget.push_notification.mhpnsWarn FIXME: NOT FOUND IN TRANSLATIONS FILE")
-			sendPushNotifications = false
-		} else {
-			sendPushNotifications = true
-		}
-	}
-
-	if sendPushNotifications {
+	if a.canSendPushNotifications() && notifyUsers {
 		for _, id := range mentionedUsersList {
 			if profileMap[id] == nil {
 				continue
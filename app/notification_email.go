@@ -4,8 +4,10 @@
 package app
 
 import (
+	"bytes"
 	"fmt"
 	"html"
+	"net/http"
 	"net/url"
 	"path/filepath"
 	"strings"
@@ -17,6 +19,38 @@ import (
 	"github.com/nicksnyder/go-i18n/i18n"
 )
 
+// ShouldSendEmailNotification returns whether a user mentioned by the given post is sent an email
+// notification, assuming email notifications are enabled and the user's email address is verified
+// if required.
+func ShouldSendEmailNotification(user *model.User, channelNotifyProps model.StringMap, status *model.Status, post *model.Post) bool {
+	return user.DeleteAt == 0 &&
+		DoesNotifyPropsAllowEmailNotification(user, channelNotifyProps) &&
+		DoesStatusAllowEmailNotification(status, post)
+}
+
+func DoesNotifyPropsAllowEmailNotification(user *model.User, channelNotifyProps model.StringMap) bool {
+	// If the channel is muted do not send email notifications
+	if channelNotifyProps[model.MARK_UNREAD_NOTIFY_PROP] == model.CHANNEL_MARK_UNREAD_MENTION {
+		return false
+	}
+
+	if channelEmail, ok := channelNotifyProps[model.EMAIL_NOTIFY_PROP]; ok && channelEmail != model.CHANNEL_NOTIFY_DEFAULT {
+		return channelEmail != "false"
+	}
+
+	return user.NotifyProps[model.EMAIL_NOTIFY_PROP] != "false"
+}
+
+func DoesStatusAllowEmailNotification(status *model.Status, post *model.Post) bool {
+	if status.Status == model.STATUS_ONLINE {
+		return false
+	}
+
+	autoResponderRelated := status.Status == model.STATUS_OUT_OF_OFFICE || post.Type == model.POST_AUTO_RESPONDER
+
+	return !autoResponderRelated
+}
+
 func (a *App) sendNotificationEmail(post *model.Post, user *model.User, channel *model.Channel, team *model.Team, channelName string, senderName string, sender *model.User) *model.AppError {
 	if channel.IsGroupOrDirect() {
 		if result := <-a.Srv.Store.Team().GetTeamsByUserId(user.Id); result.Err != nil {
@@ -312,6 +346,19 @@ func getFormattedPostTime(user *model.User, post *model.Post, useMilitaryTime bo
 	}
 }
 
+// RenderMessageToHTML renders a message as it appears in the body of email notifications.
+func (a *App) RenderMessageToHTML(message string) (string, *model.AppError) {
+	t := utils.NewHTMLTemplate(a.HTMLTemplates(), "post_message")
+	t.Props["PostMessage"] = message
+
+	var text bytes.Buffer
+	if err := t.RenderToWriter(&text); err != nil {
+		return "", model.NewAppError("RenderMessageToHTML", "app.notification.render_message.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return text.String(), nil
+}
+
 func (a *App) GetMessageForNotification(post *model.Post, translateFunc i18n.TranslateFunc) string {
 	if len(strings.TrimSpace(post.Message)) != 0 || len(post.FileIds) == 0 {
 		return post.Message
@@ -0,0 +1,70 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// WouldUserBeNotified predicts which notifications the user would receive for a post by another user
+// in the channel, mentioning them or not, using the same checks as when sending notifications.
+func (a *App) WouldUserBeNotified(userId, channelId string, mention bool) (model.NotificationPrediction, *model.AppError) {
+	user, err := a.GetUser(userId)
+	if err != nil {
+		return model.NotificationPrediction{}, err
+	}
+
+	member, err := a.GetChannelMember(channelId, userId)
+	if err != nil {
+		return model.NotificationPrediction{}, err
+	}
+
+	status, err := a.GetStatus(userId)
+	if err != nil {
+		status = &model.Status{UserId: userId, Status: model.STATUS_OFFLINE, Manual: false, LastActivityAt: 0, ActiveChannel: ""}
+	}
+
+	post := &model.Post{ChannelId: channelId}
+
+	prediction := model.NotificationPrediction{
+		Desktop: ShouldSendDesktopNotification(user, member.NotifyProps, mention, status),
+		Push:    a.canSendPushNotifications() && ShouldSendPushNotification(user, member.NotifyProps, mention, status, post),
+	}
+
+	// Email notifications are only sent for mentions
+	emailSettings := a.Config().EmailSettings
+	if mention && emailSettings.SendEmailNotifications && (!emailSettings.RequireEmailVerification || user.EmailVerified) {
+		prediction.Email = ShouldSendEmailNotification(user, member.NotifyProps, status, post)
+	}
+
+	return prediction, nil
+}
+
+// ShouldSendDesktopNotification returns whether the clients of a user display a desktop notification
+// for a post in a channel, as they decide for themselves on receiving the post.
+func ShouldSendDesktopNotification(user *model.User, channelNotifyProps model.StringMap, wasMentioned bool, status *model.Status) bool {
+	return DoesNotifyPropsAllowDesktopNotification(user, channelNotifyProps, wasMentioned) &&
+		status.Status != model.STATUS_DND && status.Status != model.STATUS_OUT_OF_OFFICE
+}
+
+func DoesNotifyPropsAllowDesktopNotification(user *model.User, channelNotifyProps model.StringMap, wasMentioned bool) bool {
+	// If the channel is muted do not send desktop notifications
+	if channelNotifyProps[model.MARK_UNREAD_NOTIFY_PROP] == model.CHANNEL_MARK_UNREAD_MENTION {
+		return false
+	}
+
+	level := user.NotifyProps[model.DESKTOP_NOTIFY_PROP]
+	if channelLevel, ok := channelNotifyProps[model.DESKTOP_NOTIFY_PROP]; ok && channelLevel != model.CHANNEL_NOTIFY_DEFAULT {
+		level = channelLevel
+	}
+
+	switch level {
+	case model.USER_NOTIFY_NONE:
+		return false
+	case model.USER_NOTIFY_MENTION:
+		return wasMentioned
+	default:
+		return true
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestDoesNotifyPropsAllowDesktopNotification(t *testing.T) {
+	tt := []struct {
+		name         string
+		userLevel    string
+		channelLevel string
+		muted        bool
+		mention      bool
+		expected     bool
+	}{
+		{"user all, not mentioned", model.USER_NOTIFY_ALL, model.CHANNEL_NOTIFY_DEFAULT, false, false, true},
+		{"user mention, not mentioned", model.USER_NOTIFY_MENTION, model.CHANNEL_NOTIFY_DEFAULT, false, false, false},
+		{"user mention, mentioned", model.USER_NOTIFY_MENTION, model.CHANNEL_NOTIFY_DEFAULT, false, true, true},
+		{"user none, mentioned", model.USER_NOTIFY_NONE, model.CHANNEL_NOTIFY_DEFAULT, false, true, false},
+		{"user none, channel all", model.USER_NOTIFY_NONE, model.CHANNEL_NOTIFY_ALL, false, false, true},
+		{"user all, channel mention, not mentioned", model.USER_NOTIFY_ALL, model.CHANNEL_NOTIFY_MENTION, false, false, false},
+		{"user all, channel none, mentioned", model.USER_NOTIFY_ALL, model.CHANNEL_NOTIFY_NONE, false, true, false},
+		{"user all, channel muted, mentioned", model.USER_NOTIFY_ALL, model.CHANNEL_NOTIFY_DEFAULT, true, true, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			user := &model.User{NotifyProps: model.StringMap{model.DESKTOP_NOTIFY_PROP: tc.userLevel}}
+			channelNotifyProps := model.StringMap{
+				model.DESKTOP_NOTIFY_PROP:     tc.channelLevel,
+				model.MARK_UNREAD_NOTIFY_PROP: model.CHANNEL_MARK_UNREAD_ALL,
+			}
+			if tc.muted {
+				channelNotifyProps[model.MARK_UNREAD_NOTIFY_PROP] = model.CHANNEL_MARK_UNREAD_MENTION
+			}
+
+			assert.Equal(t, tc.expected, DoesNotifyPropsAllowDesktopNotification(user, channelNotifyProps, tc.mention))
+		})
+	}
+}
+
+func TestDoesNotifyPropsAllowEmailNotification(t *testing.T) {
+	tt := []struct {
+		name         string
+		userEmail    string
+		channelEmail string
+		muted        bool
+		expected     bool
+	}{
+		{"user true, channel default", "true", model.CHANNEL_NOTIFY_DEFAULT, false, true},
+		{"user false, channel default", "false", model.CHANNEL_NOTIFY_DEFAULT, false, false},
+		{"user false, channel true", "false", "true", false, true},
+		{"user true, channel false", "true", "false", false, false},
+		{"user true, channel muted", "true", model.CHANNEL_NOTIFY_DEFAULT, true, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			user := &model.User{NotifyProps: model.StringMap{model.EMAIL_NOTIFY_PROP: tc.userEmail}}
+			channelNotifyProps := model.StringMap{model.EMAIL_NOTIFY_PROP: tc.channelEmail}
+			if tc.muted {
+				channelNotifyProps[model.MARK_UNREAD_NOTIFY_PROP] = model.CHANNEL_MARK_UNREAD_MENTION
+			}
+
+			assert.Equal(t, tc.expected, DoesNotifyPropsAllowEmailNotification(user, channelNotifyProps))
+		})
+	}
+}
+
+func TestDoesStatusAllowEmailNotification(t *testing.T) {
+	post := &model.Post{}
+
+	assert.True(t, DoesStatusAllowEmailNotification(&model.Status{Status: model.STATUS_OFFLINE}, post))
+	assert.True(t, DoesStatusAllowEmailNotification(&model.Status{Status: model.STATUS_AWAY}, post))
+	assert.True(t, DoesStatusAllowEmailNotification(&model.Status{Status: model.STATUS_DND}, post))
+	assert.False(t, DoesStatusAllowEmailNotification(&model.Status{Status: model.STATUS_ONLINE}, post))
+	assert.False(t, DoesStatusAllowEmailNotification(&model.Status{Status: model.STATUS_OUT_OF_OFFICE}, post))
+	assert.False(t, DoesStatusAllowEmailNotification(&model.Status{Status: model.STATUS_OFFLINE}, &model.Post{Type: model.POST_AUTO_RESPONDER}))
+}
+
+func TestWouldUserBeNotified(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		cfg.EmailSettings.SendEmailNotifications = true
+		cfg.EmailSettings.RequireEmailVerification = false
+		*cfg.EmailSettings.SendPushNotifications = false
+	})
+
+	user := th.BasicUser2
+	channel := th.BasicChannel
+
+	notifyProps := user.NotifyProps
+	notifyProps[model.DESKTOP_NOTIFY_PROP] = model.USER_NOTIFY_MENTION
+	notifyProps[model.EMAIL_NOTIFY_PROP] = "true"
+	_, err := th.App.UpdateUserNotifyProps(user.Id, notifyProps)
+	require.Nil(t, err)
+	th.App.SetStatusOffline(user.Id, true)
+
+	prediction, err := th.App.WouldUserBeNotified(user.Id, channel.Id, true)
+	require.Nil(t, err)
+	assert.Equal(t, model.NotificationPrediction{Desktop: true, Push: false, Email: true}, prediction)
+
+	prediction, err = th.App.WouldUserBeNotified(user.Id, channel.Id, false)
+	require.Nil(t, err)
+	assert.Equal(t, model.NotificationPrediction{}, prediction)
+
+	t.Run("do not disturb", func(t *testing.T) {
+		th.App.SetStatusDoNotDisturb(user.Id)
+		defer th.App.SetStatusOffline(user.Id, true)
+
+		prediction, err := th.App.WouldUserBeNotified(user.Id, channel.Id, true)
+		require.Nil(t, err)
+		assert.Equal(t, model.NotificationPrediction{Desktop: false, Push: false, Email: true}, prediction)
+	})
+
+	t.Run("muted channel", func(t *testing.T) {
+		_, err := th.App.UpdateChannelMemberNotifyProps(map[string]string{model.MARK_UNREAD_NOTIFY_PROP: model.CHANNEL_MARK_UNREAD_MENTION}, channel.Id, user.Id)
+		require.Nil(t, err)
+		defer th.App.UpdateChannelMemberNotifyProps(map[string]string{model.MARK_UNREAD_NOTIFY_PROP: model.CHANNEL_MARK_UNREAD_ALL}, channel.Id, user.Id)
+
+		prediction, err := th.App.WouldUserBeNotified(user.Id, channel.Id, true)
+		require.Nil(t, err)
+		assert.Equal(t, model.NotificationPrediction{}, prediction)
+	})
+
+	t.Run("not a channel member", func(t *testing.T) {
+		_, err := th.App.WouldUserBeNotified(th.CreateUser().Id, channel.Id, true)
+		require.NotNil(t, err)
+	})
+}
@@ -12,9 +12,26 @@ import (
 	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/utils"
+	"github.com/mattermost/mattermost-server/utils/markdown"
 	"github.com/nicksnyder/go-i18n/i18n"
 )
 
+// canSendPushNotifications reports whether push notifications are enabled and permitted by the
+// license for the configured push notification server.
+func (a *App) canSendPushNotifications() bool {
+	if !*a.Config().EmailSettings.SendPushNotifications {
+		return false
+	}
+
+	pushServer := *a.Config().EmailSettings.PushNotificationServer
+	if license := a.License(); pushServer == model.MHPNS && (license == nil || !*license.Features.MHPNS) {
+		mlog.Warn("api.post.send_notifications_and_forget.push_notification.mhpnsWarn FIXME: NOT FOUND IN TRANSLATIONS FILE")
+		return false
+	}
+
+	return true
+}
+
 func (a *App) sendPushNotification(post *model.Post, user *model.User, channel *model.Channel, channelName string, sender *model.User, senderName string,
 	explicitMention, channelWideMention bool, replyToThreadType string) *model.AppError {
 	cfg := a.Config()
@@ -77,6 +94,14 @@ func (a *App) sendPushNotification(post *model.Post, user *model.User, channel *
 
 	msg.Message = a.getPushNotificationMessage(post.Message, explicitMention, channelWideMention, hasFiles, senderName, channelName, channel.Type, replyToThreadType, userLocale)
 
+	a.sendPushNotificationToSessions(msg, user.Id, sessions)
+
+	return nil
+}
+
+// sendPushNotificationToSessions sends a copy of the given message to the device of each of the
+// user's unexpired sessions.
+func (a *App) sendPushNotificationToSessions(msg model.PushNotification, userId string, sessions []*model.Session) {
 	for _, session := range sessions {
 
 		if session.IsExpired() {
@@ -86,7 +111,7 @@ func (a *App) sendPushNotification(post *model.Post, user *model.User, channel *
 		tmpMessage := *model.PushNotificationFromJson(strings.NewReader(msg.ToJson()))
 		tmpMessage.SetDeviceIdAndPlatform(session.DeviceId)
 
-		mlog.Debug(fmt.Sprintf("Sending push notification to device %v for user %v with msg of '%v'", tmpMessage.DeviceId, user.Id, msg.Message), mlog.String("user_id", user.Id))
+		mlog.Debug(fmt.Sprintf("Sending push notification to device %v for user %v with msg of '%v'", tmpMessage.DeviceId, userId, msg.Message), mlog.String("user_id", userId))
 
 		a.Go(func(session *model.Session) func() {
 			return func() {
@@ -98,8 +123,6 @@ func (a *App) sendPushNotification(post *model.Post, user *model.User, channel *
 			a.Metrics.IncrementPostSentPush()
 		}
 	}
-
-	return nil
 }
 
 func (a *App) getPushNotificationMessage(postMessage string, explicitMention, channelWideMention, hasFiles bool,
@@ -114,6 +137,7 @@ func (a *App) getPushNotificationMessage(postMessage string, explicitMention, ch
 		} else {
 			message = "@" + senderName + ": " + model.ClearMentionTags(postMessage)
 		}
+		message = markdown.TruncateForNotification(message, model.PUSH_MESSAGE_MAX_RUNES)
 	} else {
 		if channelType == model.CHANNEL_DIRECT {
 			message = userLocale("api.post.send_notifications_and_forget.push_message")
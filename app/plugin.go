@@ -6,13 +6,12 @@ package app
 import (
 	"net/http"
 	"os"
-	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/plugin"
-	"github.com/mattermost/mattermost-server/utils"
 )
 
 func (a *App) SyncPluginsActiveState() {
@@ -23,12 +22,16 @@ func (a *App) SyncPluginsActiveState() {
 	config := a.Config().PluginSettings
 
 	if *config.Enable {
-		availablePlugins, err := a.Plugins.Available()
+		availablePlugins, pluginErrors, err := a.Plugins.AvailableWithErrors()
 		if err != nil {
 			a.Log.Error("Unable to get available plugins", mlog.Err(err))
 			return
 		}
 
+		for _, pluginError := range pluginErrors {
+			a.Log.Error("Plugin could not be loaded", mlog.String("path", pluginError.Path), mlog.String("reason", pluginError.Reason))
+		}
+
 		// Deactivate any plugins that have been disabled.
 		for _, plugin := range a.Plugins.Active() {
 			// Determine if plugin is enabled
@@ -41,21 +44,20 @@ func (a *App) SyncPluginsActiveState() {
 			// If it's not enabled we need to deactivate it
 			if !pluginEnabled {
 				deactivated := a.Plugins.Deactivate(pluginId)
+				a.clearPluginBanner(pluginId)
 				if deactivated && plugin.Manifest.HasClient() {
 					message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_PLUGIN_DISABLED, "", "", "", nil)
 					message.Add("manifest", plugin.Manifest.ClientManifest())
 					a.Publish(message)
 				}
+				if deactivated {
+					a.notifyPluginsOfDeactivation(plugin.Manifest)
+				}
 			}
 		}
 
 		// Activate any plugins that have been enabled
 		for _, plugin := range availablePlugins {
-			if plugin.Manifest == nil {
-				plugin.WrapLogger(a.Log).Error("Plugin manifest could not be loaded", mlog.Err(plugin.ManifestError))
-				continue
-			}
-
 			// Determine if plugin is enabled
 			pluginId := plugin.Manifest.Id
 			pluginEnabled := false
@@ -65,6 +67,13 @@ func (a *App) SyncPluginsActiveState() {
 
 			// Activate plugin if enabled
 			if pluginEnabled {
+				if !a.Plugins.IsActive(pluginId) {
+					if appErr := a.checkPluginSignatureBeforeActivation(pluginId); appErr != nil {
+						plugin.WrapLogger(a.Log).Error("Refusing to activate plugin without a valid signature", mlog.Err(appErr))
+						continue
+					}
+				}
+
 				updatedManifest, activated, err := a.Plugins.Activate(pluginId)
 				if err != nil {
 					plugin.WrapLogger(a.Log).Error("Unable to activate plugin", mlog.Err(err))
@@ -72,9 +81,10 @@ func (a *App) SyncPluginsActiveState() {
 				}
 
 				if activated && updatedManifest.HasClient() {
-					message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_PLUGIN_ENABLED, "", "", "", nil)
-					message.Add("manifest", updatedManifest.ClientManifest())
-					a.Publish(message)
+					a.publishPluginEnabled(updatedManifest)
+				}
+				if activated {
+					a.notifyPluginsOfActivation(updatedManifest)
 				}
 			}
 		}
@@ -87,8 +97,18 @@ func (a *App) SyncPluginsActiveState() {
 	}
 }
 
+// newPluginContext returns the context passed to the plugin hooks invoked for an event, identified by
+// a new request id so that the server and plugin log messages about the event can be correlated.
+func newPluginContext() *plugin.Context {
+	return &plugin.Context{RequestId: model.NewId()}
+}
+
+// NewPluginAPI returns the API for the plugin with the given manifest, which fails calls outside of
+// the capabilities granted to the plugin.
 func (a *App) NewPluginAPI(manifest *model.Manifest) plugin.API {
-	return NewPluginAPI(a, manifest)
+	return plugin.NewCapabilityCheckedAPI(NewPluginAPI(a, manifest), func(method string) *model.AppError {
+		return a.checkPluginCapabilities(manifest, method)
+	})
 }
 
 func (a *App) InitPlugins(pluginDir, webappPluginDir string) {
@@ -113,41 +133,41 @@ func (a *App) InitPlugins(pluginDir, webappPluginDir string) {
 		mlog.Error("Failed to start up plugins", mlog.Err(err))
 		return
 	} else {
+		env.SetSchemaMigrator(a.migratePluginSchema)
+		env.SetHookQueueOverflowHandler(a.pluginHookQueueOverflowed)
+		env.SetStateChangeHandler(a.pluginStateChanged)
+		env.SetHookWorkerPoolSize(*a.Config().PluginSettings.HookWorkerPoolSize)
+		if a.Metrics != nil {
+			env.SetHookMetrics(a.Metrics)
+		}
 		a.Plugins = env
 	}
 
-	prepackagedPluginsDir, found := utils.FindDir("prepackaged_plugins")
-	if found {
-		if err := filepath.Walk(prepackagedPluginsDir, func(walkPath string, info os.FileInfo, err error) error {
-			if !strings.HasSuffix(walkPath, ".tar.gz") {
-				return nil
-			}
-
-			if fileReader, err := os.Open(walkPath); err != nil {
-				mlog.Error("Failed to open prepackaged plugin", mlog.Err(err), mlog.String("path", walkPath))
-			} else if _, err := a.InstallPlugin(fileReader, true); err != nil {
-				mlog.Error("Failed to unpack prepackaged plugin", mlog.Err(err), mlog.String("path", walkPath))
-			}
-
-			return nil
-		}); err != nil {
-			mlog.Error("Failed to complete unpacking prepackaged plugins", mlog.Err(err))
-		}
-	}
+	a.installPrepackagedPlugins()
 
 	// Sync plugin active state when config changes. Also notify plugins.
 	a.RemoveConfigListener(a.PluginConfigListenerId)
-	a.PluginConfigListenerId = a.AddConfigListener(func(*model.Config, *model.Config) {
+	a.PluginConfigListenerId = a.AddConfigListener(func(oldCfg, newCfg *model.Config) {
 		a.SyncPluginsActiveState()
 		a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
 			hooks.OnConfigurationChange()
 			return true
 		}, plugin.OnConfigurationChangeId)
+		a.recordPluginConfigChanges(oldCfg, newCfg)
 	})
 
 	a.SyncPluginsActiveState()
 }
 
+// pluginHookQueueOverflowed records a hook invocation dropped because too many were queued for a
+// plugin that is still warm starting, or is falling behind on asynchronous hooks.
+func (a *App) pluginHookQueueOverflowed(pluginId string) {
+	a.Log.Warn("Dropped hook for plugin with too many queued", mlog.String("plugin_id", pluginId))
+	if a.Metrics != nil {
+		a.Metrics.IncrementPluginHookQueueOverflow(pluginId)
+	}
+}
+
 func (a *App) ShutDownPlugins() {
 	if a.Plugins == nil {
 		return
@@ -203,8 +223,18 @@ func (a *App) EnablePlugin(id string) *model.AppError {
 		return model.NewAppError("EnablePlugin", "app.plugin.not_installed.app_error", nil, "", http.StatusBadRequest)
 	}
 
+	if manifest.DeclaresCapabilities() {
+		mlog.Info("Enabling plugin", mlog.String("plugin_id", id), mlog.String("capabilities", strings.Join(manifest.Capabilities, ",")))
+	} else {
+		mlog.Info("Enabling plugin without declared capabilities", mlog.String("plugin_id", id), mlog.String("default_capabilities", *a.Config().PluginSettings.DefaultCapabilities))
+	}
+
+	if csp := formatPluginContentSecurityPolicy(manifest); csp != "" {
+		mlog.Info("Enabling plugin with a webapp loading external resources", mlog.String("plugin_id", id), mlog.String("content_security_policy", csp), mlog.Bool("allowed", *a.Config().PluginSettings.AllowPluginCSPExtensions))
+	}
+
 	a.UpdateConfig(func(cfg *model.Config) {
-		cfg.PluginSettings.PluginStates[id] = &model.PluginState{Enable: true}
+		cfg.PluginSettings.PluginStates[id] = model.NewPluginState(true, model.PLUGIN_STATE_REASON_ADMIN)
 	})
 
 	// This call will cause SyncPluginsActiveState to be called and the plugin to be activated
@@ -244,7 +274,7 @@ func (a *App) DisablePlugin(id string) *model.AppError {
 	}
 
 	a.UpdateConfig(func(cfg *model.Config) {
-		cfg.PluginSettings.PluginStates[id] = &model.PluginState{Enable: false}
+		cfg.PluginSettings.PluginStates[id] = model.NewPluginState(false, model.PLUGIN_STATE_REASON_ADMIN)
 	})
 
 	if err := a.SaveConfig(a.Config(), true); err != nil {
@@ -254,6 +284,120 @@ func (a *App) DisablePlugin(id string) *model.AppError {
 	return nil
 }
 
+// disablePluginWithReason disables a plugin, if enabled, without it having been requested, recording
+// why it was.
+func (a *App) disablePluginWithReason(id, reason string) *model.AppError {
+	if state, ok := a.Config().PluginSettings.PluginStates[id]; !ok || !state.Enable {
+		return nil
+	}
+
+	a.UpdateConfig(func(cfg *model.Config) {
+		cfg.PluginSettings.PluginStates[id] = model.NewPluginState(false, reason)
+	})
+
+	if err := a.SaveConfig(a.Config(), true); err != nil {
+		return model.NewAppError("disablePluginWithReason", "app.plugin.config.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	a.recordPluginEvent(id, model.PLUGIN_EVENT_AUTO_DISABLED, reason)
+
+	return nil
+}
+
+// SetPluginStates enables or disables each of the given installed plugins, saving the config and
+// notifying config listeners once for all of them. Plugins that fail to start once enabled are
+// disabled again, leaving the other changes in place. The outcome for each plugin is returned,
+// ordered by plugin id.
+func (a *App) SetPluginStates(states map[string]bool) (model.PluginStateChangeResults, *model.AppError) {
+	if a.Plugins == nil || !*a.Config().PluginSettings.Enable {
+		return nil, model.NewAppError("SetPluginStates", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	plugins, err := a.Plugins.Available()
+	if err != nil {
+		return nil, model.NewAppError("SetPluginStates", "app.plugin.config.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	installed := make(map[string]bool, len(plugins))
+	for _, p := range plugins {
+		installed[p.Manifest.Id] = true
+	}
+
+	results := make(map[string]*model.PluginStateChangeResult, len(states))
+	cfg := a.Config().Clone()
+	for id, enable := range states {
+		id = strings.ToLower(id)
+
+		if !installed[id] {
+			results[id] = &model.PluginStateChangeResult{PluginId: id, Result: model.PLUGIN_STATE_CHANGE_NOT_INSTALLED}
+			continue
+		}
+
+		cfg.PluginSettings.PluginStates[id] = model.NewPluginState(enable, model.PLUGIN_STATE_REASON_ADMIN)
+		if enable {
+			results[id] = &model.PluginStateChangeResult{PluginId: id, Result: model.PLUGIN_STATE_CHANGE_ENABLED}
+		} else {
+			results[id] = &model.PluginStateChangeResult{PluginId: id, Result: model.PLUGIN_STATE_CHANGE_DISABLED}
+		}
+	}
+
+	// This call will cause SyncPluginsActiveState to be called and the plugins to be activated
+	if err := a.savePluginStatesConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	var failed []string
+	for id, result := range results {
+		if result.Result != model.PLUGIN_STATE_CHANGE_ENABLED {
+			continue
+		}
+
+		if err := a.Plugins.ActivationError(id); err != nil {
+			result.Result = model.PLUGIN_STATE_CHANGE_FAILED
+			result.Error = err.Error()
+			failed = append(failed, id)
+		} else if !a.Plugins.IsActive(id) {
+			result.Result = model.PLUGIN_STATE_CHANGE_FAILED
+			failed = append(failed, id)
+		}
+	}
+
+	if len(failed) > 0 {
+		cfg = a.Config().Clone()
+		for _, id := range failed {
+			cfg.PluginSettings.PluginStates[id] = model.NewPluginState(false, model.PLUGIN_STATE_REASON_ACTIVATION_FAILED)
+		}
+
+		if err := a.savePluginStatesConfig(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]string, 0, len(results))
+	for id := range results {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	sortedResults := make(model.PluginStateChangeResults, 0, len(ids))
+	for _, id := range ids {
+		sortedResults = append(sortedResults, results[id])
+	}
+
+	return sortedResults, nil
+}
+
+func (a *App) savePluginStatesConfig(cfg *model.Config) *model.AppError {
+	if err := a.SaveConfig(cfg, true); err != nil {
+		if err.Id == "ent.cluster.save_config.error" {
+			return model.NewAppError("SetPluginStates", "app.plugin.cluster.save_config.app_error", nil, "", http.StatusInternalServerError)
+		}
+		return model.NewAppError("SetPluginStates", "app.plugin.config.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
 func (a *App) PluginsReady() bool {
 	return a.Plugins != nil && *a.Config().PluginSettings.Enable
 }
@@ -263,19 +407,21 @@ func (a *App) GetPlugins() (*model.PluginsResponse, *model.AppError) {
 		return nil, model.NewAppError("GetPlugins", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
-	availablePlugins, err := a.Plugins.Available()
+	availablePlugins, pluginErrors, err := a.Plugins.AvailableWithErrors()
 	if err != nil {
 		return nil, model.NewAppError("GetPlugins", "app.plugin.get_plugins.app_error", nil, err.Error(), http.StatusInternalServerError)
 	}
-	resp := &model.PluginsResponse{Active: []*model.PluginInfo{}, Inactive: []*model.PluginInfo{}}
+	pluginSettings := a.Config().PluginSettings
+	resp := &model.PluginsResponse{Active: []*model.PluginInfo{}, Inactive: []*model.PluginInfo{}, Errors: pluginErrors}
 	for _, plugin := range availablePlugins {
-		if plugin.Manifest == nil {
-			continue
-		}
-
 		info := &model.PluginInfo{
 			Manifest: *plugin.Manifest,
 		}
+		if state, ok := pluginSettings.PluginStates[plugin.Manifest.Id]; ok {
+			info.Enabled = state.Enable
+			info.StateReason = state.Reason
+			info.StateUpdatedAt = state.UpdatedAt
+		}
 
 		if a.Plugins.IsActive(plugin.Manifest.Id) {
 			resp.Active = append(resp.Active, info)
@@ -284,5 +430,12 @@ func (a *App) GetPlugins() (*model.PluginsResponse, *model.AppError) {
 		}
 	}
 
+	resp.Limits = &model.PluginLimits{
+		InstalledPlugins:    len(availablePlugins) + len(pluginErrors),
+		MaxInstalledPlugins: *pluginSettings.MaxInstalledPlugins,
+		MaxBundleSize:       *pluginSettings.MaxBundleSize,
+		MaxExtractedSize:    *pluginSettings.MaxExtractedSize,
+	}
+
 	return resp, nil
 }
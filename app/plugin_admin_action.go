@@ -0,0 +1,266 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	PLUGIN_ADMIN_ACTION_EXPIRY_INTERVAL   = 1 * time.Minute
+	PLUGIN_ADMIN_ACTION_EXPIRY_BATCH_SIZE = 100
+)
+
+// RequestPluginAdminAction records a plugin's request for a privileged action and notifies any
+// system admins, who may then approve or deny it until it expires. Only the action, its parameters
+// and its description are taken from the given request.
+func (a *App) RequestPluginAdminAction(pluginId string, action *model.PluginAdminActionRequest) (*model.PluginAdminActionRequest, *model.AppError) {
+	request := &model.PluginAdminActionRequest{
+		PluginId:    pluginId,
+		Action:      action.Action,
+		Params:      action.Params,
+		Description: action.Description,
+		CreateAt:    model.GetMillis(),
+	}
+	request.ExpireAt = request.CreateAt + int64(*a.Config().PluginSettings.AdminActionRequestTTLMinutes)*60*1000
+
+	request.PreSave()
+	if err := request.IsValid(); err != nil {
+		return nil, err
+	}
+
+	// Requests that could never be carried out are rejected now rather than shown to system admins.
+	if err := a.checkPluginAdminAction(request); err != nil {
+		return nil, err
+	}
+
+	result := <-a.Srv.Store.PluginAdminActionRequest().Save(request)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	request = result.Data.(*model.PluginAdminActionRequest)
+
+	mlog.Info("Plugin requested an admin action", mlog.String("plugin_id", pluginId), mlog.String("request_id", request.Id), mlog.String("action", request.Action))
+
+	a.publishPluginAdminActionEvent(model.WEBSOCKET_EVENT_PLUGIN_ADMIN_ACTION_REQUESTED, request)
+
+	return request, nil
+}
+
+// GetPendingPluginAdminActions gets the plugin requests still waiting for a system admin, oldest
+// first.
+func (a *App) GetPendingPluginAdminActions(page, perPage int) ([]*model.PluginAdminActionRequest, *model.AppError) {
+	result := <-a.Srv.Store.PluginAdminActionRequest().GetPending(model.GetMillis(), page*perPage, perPage)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.PluginAdminActionRequest), nil
+}
+
+// ResolvePluginAdminAction approves or denies, on behalf of the given system admin, a plugin's
+// request for a privileged action. An approved action is carried out straight away, and the request
+// records whether it failed. Either way, the plugin is notified of the outcome.
+func (a *App) ResolvePluginAdminAction(requestId string, approve bool, userId string) (*model.PluginAdminActionRequest, *model.AppError) {
+	result := <-a.Srv.Store.PluginAdminActionRequest().Get(requestId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	request := result.Data.(*model.PluginAdminActionRequest)
+
+	now := model.GetMillis()
+	if request.Status == model.PLUGIN_ADMIN_ACTION_STATUS_PENDING && !request.IsPending(now) {
+		// The request expired since it was last checked for.
+		a.expirePluginAdminAction(request)
+	}
+
+	if !request.IsPending(now) {
+		return nil, model.NewAppError("ResolvePluginAdminAction", "app.plugin.admin_action.resolved.app_error", nil, "request_id="+requestId+", status="+request.Status, http.StatusBadRequest)
+	}
+
+	request.Status = model.PLUGIN_ADMIN_ACTION_STATUS_DENIED
+	if approve {
+		request.Status = model.PLUGIN_ADMIN_ACTION_STATUS_APPROVED
+	}
+	request.ResolvedBy = userId
+	request.ResolveAt = now
+
+	// The status is only updated if the request is still pending, so that an action approved by two
+	// system admins at once is only carried out once.
+	if updated, err := a.updatePluginAdminActionStatus(request, model.PLUGIN_ADMIN_ACTION_STATUS_PENDING); err != nil {
+		return nil, err
+	} else if !updated {
+		return nil, model.NewAppError("ResolvePluginAdminAction", "app.plugin.admin_action.resolved.app_error", nil, "request_id="+requestId+", status="+request.Status, http.StatusBadRequest)
+	}
+
+	if approve {
+		if err := a.executePluginAdminAction(request); err != nil {
+			mlog.Error("Failed to carry out approved plugin admin action", mlog.String("plugin_id", request.PluginId), mlog.String("request_id", request.Id), mlog.Err(err))
+
+			request.Status = model.PLUGIN_ADMIN_ACTION_STATUS_FAILED
+			request.Error = err.Error()
+			if len(request.Error) > model.PLUGIN_ADMIN_ACTION_ERROR_MAX_LENGTH {
+				request.Error = request.Error[:model.PLUGIN_ADMIN_ACTION_ERROR_MAX_LENGTH]
+			}
+			if _, err := a.updatePluginAdminActionStatus(request, model.PLUGIN_ADMIN_ACTION_STATUS_APPROVED); err != nil {
+				mlog.Error("Failed to record failed plugin admin action", mlog.String("request_id", request.Id), mlog.Err(err))
+			}
+		}
+	}
+
+	a.pluginAdminActionResolved(request)
+
+	return request, nil
+}
+
+// ExpirePluginAdminActions marks the plugin requests that were neither approved nor denied in time
+// as expired, notifying the plugins that made them.
+func (a *App) ExpirePluginAdminActions() {
+	for {
+		result := <-a.Srv.Store.PluginAdminActionRequest().GetExpired(model.GetMillis(), PLUGIN_ADMIN_ACTION_EXPIRY_BATCH_SIZE)
+		if result.Err != nil {
+			mlog.Error("Failed to get expired plugin admin action requests", mlog.Err(result.Err))
+			return
+		}
+		requests := result.Data.([]*model.PluginAdminActionRequest)
+
+		for _, request := range requests {
+			if !a.expirePluginAdminAction(request) {
+				return
+			}
+		}
+
+		if len(requests) < PLUGIN_ADMIN_ACTION_EXPIRY_BATCH_SIZE {
+			return
+		}
+	}
+}
+
+// expirePluginAdminAction marks the pending request as expired, returning false if it could not be.
+func (a *App) expirePluginAdminAction(request *model.PluginAdminActionRequest) bool {
+	request.Status = model.PLUGIN_ADMIN_ACTION_STATUS_EXPIRED
+	request.ResolveAt = model.GetMillis()
+
+	updated, err := a.updatePluginAdminActionStatus(request, model.PLUGIN_ADMIN_ACTION_STATUS_PENDING)
+	if err != nil {
+		mlog.Error("Failed to expire plugin admin action request", mlog.String("request_id", request.Id), mlog.Err(err))
+		return false
+	}
+
+	// Otherwise, the request was resolved concurrently and the plugin notified then.
+	if updated {
+		a.pluginAdminActionResolved(request)
+	}
+
+	return true
+}
+
+func (a *App) updatePluginAdminActionStatus(request *model.PluginAdminActionRequest, oldStatus string) (bool, *model.AppError) {
+	result := <-a.Srv.Store.PluginAdminActionRequest().UpdateStatus(request, oldStatus)
+	if result.Err != nil {
+		return false, result.Err
+	}
+
+	return result.Data.(bool), nil
+}
+
+// pluginAdminActionResolved notifies system admins and the plugin that made the request that it is
+// no longer pending.
+func (a *App) pluginAdminActionResolved(request *model.PluginAdminActionRequest) {
+	a.publishPluginAdminActionEvent(model.WEBSOCKET_EVENT_PLUGIN_ADMIN_ACTION_RESOLVED, request)
+
+	approved := request.Status == model.PLUGIN_ADMIN_ACTION_STATUS_APPROVED
+
+	// The plugin is notified in the background, since it need not be running, or may be slow to
+	// respond.
+	a.Go(func() {
+		if !a.PluginsReady() {
+			return
+		}
+
+		hooks, err := a.Plugins.HooksForPlugin(request.PluginId)
+		if err != nil {
+			mlog.Debug("Unable to notify plugin of resolved admin action", mlog.String("request_id", request.Id), mlog.Err(err))
+			return
+		}
+
+		hooks.AdminActionHasBeenResolved(request.Id, approved)
+	})
+}
+
+func (a *App) publishPluginAdminActionEvent(event string, request *model.PluginAdminActionRequest) {
+	// Notify any system admins.
+	message := model.NewWebSocketEvent(event, "", "", "", nil)
+	message.Add("request", request.ToJson())
+	message.Broadcast.ContainsSensitiveData = true
+	a.Publish(message)
+}
+
+// checkPluginAdminAction returns an error if the requested action could not currently be carried
+// out.
+func (a *App) checkPluginAdminAction(request *model.PluginAdminActionRequest) *model.AppError {
+	switch request.Action {
+	case model.PLUGIN_ADMIN_ACTION_DELETE_USER:
+		_, err := a.GetUser(request.Params[model.PLUGIN_ADMIN_ACTION_PARAM_USER_ID])
+		return err
+
+	case model.PLUGIN_ADMIN_ACTION_UPDATE_CONFIG:
+		cfg, err := patchPluginAdminActionConfig(a.Config(), request.Params[model.PLUGIN_ADMIN_ACTION_PARAM_CONFIG])
+		if err != nil {
+			return err
+		}
+		cfg.SetDefaults()
+		return cfg.IsValid()
+	}
+
+	return nil
+}
+
+// executePluginAdminAction carries out an approved request. This is the only way in which plugins'
+// requests are acted upon, so new actions must be added here and to the model's list of actions.
+func (a *App) executePluginAdminAction(request *model.PluginAdminActionRequest) *model.AppError {
+	switch request.Action {
+	case model.PLUGIN_ADMIN_ACTION_DELETE_USER:
+		user, err := a.GetUser(request.Params[model.PLUGIN_ADMIN_ACTION_PARAM_USER_ID])
+		if err != nil {
+			return err
+		}
+		return a.PermanentDeleteUser(user)
+
+	case model.PLUGIN_ADMIN_ACTION_UPDATE_CONFIG:
+		// The patch is applied to the configuration as it is now, which may have changed since the
+		// request was made.
+		cfg, err := patchPluginAdminActionConfig(a.Config(), request.Params[model.PLUGIN_ADMIN_ACTION_PARAM_CONFIG])
+		if err != nil {
+			return err
+		}
+		return a.SaveConfig(cfg, true)
+	}
+
+	return model.NewAppError("executePluginAdminAction", "model.plugin_admin_action_request.is_valid.action.app_error", map[string]interface{}{"Action": request.Action}, "request_id="+request.Id, http.StatusBadRequest)
+}
+
+// patchPluginAdminActionConfig returns a copy of the configuration with the settings given by the
+// JSON object patch merged into it.
+func patchPluginAdminActionConfig(cfg *model.Config, patch string) (*model.Config, *model.AppError) {
+	if !strings.HasPrefix(strings.TrimSpace(patch), "{") {
+		return nil, model.NewAppError("patchPluginAdminActionConfig", "app.plugin.admin_action.config_patch.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	cfg = cfg.Clone()
+
+	decoder := json.NewDecoder(strings.NewReader(patch))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, model.NewAppError("patchPluginAdminActionConfig", "app.plugin.admin_action.config_patch.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestRequestPluginAdminAction(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.AdminActionRequestTTLMinutes = 30
+	})
+
+	pluginId := model.NewId()
+
+	request, err := th.App.RequestPluginAdminAction(pluginId, &model.PluginAdminActionRequest{
+		Id:          model.NewId(),
+		PluginId:    "otherplugin",
+		Action:      model.PLUGIN_ADMIN_ACTION_DELETE_USER,
+		Params:      model.StringMap{model.PLUGIN_ADMIN_ACTION_PARAM_USER_ID: th.BasicUser2.Id},
+		Description: "The user has left the company",
+		Status:      model.PLUGIN_ADMIN_ACTION_STATUS_APPROVED,
+	})
+	require.Nil(t, err)
+
+	// Only the action, its parameters and its description are taken from the plugin.
+	assert.Equal(t, pluginId, request.PluginId)
+	assert.Equal(t, model.PLUGIN_ADMIN_ACTION_STATUS_PENDING, request.Status)
+	assert.Equal(t, int64(30*60*1000), request.ExpireAt-request.CreateAt)
+
+	pending, err := th.App.GetPendingPluginAdminActions(0, 10000)
+	require.Nil(t, err)
+	found := false
+	for _, r := range pending {
+		found = found || r.Id == request.Id
+	}
+	assert.True(t, found)
+
+	t.Run("unknown action", func(t *testing.T) {
+		_, err := th.App.RequestPluginAdminAction(pluginId, &model.PluginAdminActionRequest{
+			Action: "execute_command",
+			Params: model.StringMap{"command": "rm -rf /"},
+		})
+		require.NotNil(t, err)
+		assert.Equal(t, "model.plugin_admin_action_request.is_valid.action.app_error", err.Id)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, err := th.App.RequestPluginAdminAction(pluginId, &model.PluginAdminActionRequest{
+			Action: model.PLUGIN_ADMIN_ACTION_DELETE_USER,
+			Params: model.StringMap{model.PLUGIN_ADMIN_ACTION_PARAM_USER_ID: model.NewId()},
+		})
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusNotFound, err.StatusCode)
+	})
+
+	t.Run("invalid config changes", func(t *testing.T) {
+		for _, patch := range []string{
+			`[]`,
+			`{"TeamSettings": {"NotASetting": true}}`,
+			`{"TeamSettings": {"MaxUsersPerTeam": 0}}`,
+		} {
+			_, err := th.App.RequestPluginAdminAction(pluginId, &model.PluginAdminActionRequest{
+				Action: model.PLUGIN_ADMIN_ACTION_UPDATE_CONFIG,
+				Params: model.StringMap{model.PLUGIN_ADMIN_ACTION_PARAM_CONFIG: patch},
+			})
+			assert.NotNil(t, err, patch)
+		}
+	})
+}
+
+func TestResolvePluginAdminAction(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	pluginId := model.NewId()
+	adminId := th.SystemAdminUser.Id
+
+	requestDeleteUser := func(user *model.User) *model.PluginAdminActionRequest {
+		request, err := th.App.RequestPluginAdminAction(pluginId, &model.PluginAdminActionRequest{
+			Action: model.PLUGIN_ADMIN_ACTION_DELETE_USER,
+			Params: model.StringMap{model.PLUGIN_ADMIN_ACTION_PARAM_USER_ID: user.Id},
+		})
+		require.Nil(t, err)
+		return request
+	}
+
+	t.Run("deny", func(t *testing.T) {
+		user := th.CreateUser()
+		request := requestDeleteUser(user)
+
+		resolved, err := th.App.ResolvePluginAdminAction(request.Id, false, adminId)
+		require.Nil(t, err)
+		assert.Equal(t, model.PLUGIN_ADMIN_ACTION_STATUS_DENIED, resolved.Status)
+		assert.Equal(t, adminId, resolved.ResolvedBy)
+
+		_, err = th.App.GetUser(user.Id)
+		assert.Nil(t, err)
+
+		// A resolved request cannot be resolved again.
+		_, err = th.App.ResolvePluginAdminAction(request.Id, true, adminId)
+		require.NotNil(t, err)
+		assert.Equal(t, "app.plugin.admin_action.resolved.app_error", err.Id)
+
+		_, err = th.App.GetUser(user.Id)
+		assert.Nil(t, err)
+	})
+
+	t.Run("approve delete user", func(t *testing.T) {
+		user := th.CreateUser()
+		request := requestDeleteUser(user)
+
+		resolved, err := th.App.ResolvePluginAdminAction(request.Id, true, adminId)
+		require.Nil(t, err)
+		assert.Equal(t, model.PLUGIN_ADMIN_ACTION_STATUS_APPROVED, resolved.Status)
+
+		_, err = th.App.GetUser(user.Id)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("approve update config", func(t *testing.T) {
+		maxUsersPerTeam := *th.App.Config().TeamSettings.MaxUsersPerTeam
+		siteName := th.App.Config().TeamSettings.SiteName
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.TeamSettings.MaxUsersPerTeam = maxUsersPerTeam
+			cfg.TeamSettings.SiteName = siteName
+		})
+
+		request, err := th.App.RequestPluginAdminAction(pluginId, &model.PluginAdminActionRequest{
+			Action: model.PLUGIN_ADMIN_ACTION_UPDATE_CONFIG,
+			Params: model.StringMap{model.PLUGIN_ADMIN_ACTION_PARAM_CONFIG: `{"TeamSettings": {"MaxUsersPerTeam": 1234}}`},
+		})
+		require.Nil(t, err)
+
+		// Settings changed in the meantime are kept.
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			cfg.TeamSettings.SiteName = "Changed"
+		})
+
+		resolved, err := th.App.ResolvePluginAdminAction(request.Id, true, adminId)
+		require.Nil(t, err)
+		assert.Equal(t, model.PLUGIN_ADMIN_ACTION_STATUS_APPROVED, resolved.Status)
+		assert.Equal(t, 1234, *th.App.Config().TeamSettings.MaxUsersPerTeam)
+		assert.Equal(t, "Changed", th.App.Config().TeamSettings.SiteName)
+	})
+
+	t.Run("failed action", func(t *testing.T) {
+		user := th.CreateUser()
+		request := requestDeleteUser(user)
+		require.Nil(t, th.App.PermanentDeleteUser(user))
+
+		resolved, err := th.App.ResolvePluginAdminAction(request.Id, true, adminId)
+		require.Nil(t, err)
+		assert.Equal(t, model.PLUGIN_ADMIN_ACTION_STATUS_FAILED, resolved.Status)
+		assert.NotEmpty(t, resolved.Error)
+
+		result := <-th.App.Srv.Store.PluginAdminActionRequest().Get(request.Id)
+		require.Nil(t, result.Err)
+		assert.Equal(t, model.PLUGIN_ADMIN_ACTION_STATUS_FAILED, result.Data.(*model.PluginAdminActionRequest).Status)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		user := th.CreateUser()
+
+		now := model.GetMillis()
+		result := <-th.App.Srv.Store.PluginAdminActionRequest().Save(&model.PluginAdminActionRequest{
+			PluginId: pluginId,
+			Action:   model.PLUGIN_ADMIN_ACTION_DELETE_USER,
+			Params:   model.StringMap{model.PLUGIN_ADMIN_ACTION_PARAM_USER_ID: user.Id},
+			CreateAt: now - 2000,
+			ExpireAt: now - 1000,
+		})
+		require.Nil(t, result.Err)
+		request := result.Data.(*model.PluginAdminActionRequest)
+
+		_, err := th.App.ResolvePluginAdminAction(request.Id, true, adminId)
+		require.NotNil(t, err)
+		assert.Equal(t, "app.plugin.admin_action.resolved.app_error", err.Id)
+
+		result = <-th.App.Srv.Store.PluginAdminActionRequest().Get(request.Id)
+		require.Nil(t, result.Err)
+		assert.Equal(t, model.PLUGIN_ADMIN_ACTION_STATUS_EXPIRED, result.Data.(*model.PluginAdminActionRequest).Status)
+
+		_, err = th.App.GetUser(user.Id)
+		assert.Nil(t, err)
+	})
+}
+
+func TestExpirePluginAdminActions(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	now := model.GetMillis()
+	save := func(expireAt int64) *model.PluginAdminActionRequest {
+		result := <-th.App.Srv.Store.PluginAdminActionRequest().Save(&model.PluginAdminActionRequest{
+			PluginId: "testplugin",
+			Action:   model.PLUGIN_ADMIN_ACTION_DELETE_USER,
+			Params:   model.StringMap{model.PLUGIN_ADMIN_ACTION_PARAM_USER_ID: th.BasicUser.Id},
+			CreateAt: expireAt - 60*1000,
+			ExpireAt: expireAt,
+		})
+		require.Nil(t, result.Err)
+		return result.Data.(*model.PluginAdminActionRequest)
+	}
+	expired := save(now - 1000)
+	pending := save(now + 60*1000)
+
+	th.App.ExpirePluginAdminActions()
+
+	for id, status := range map[string]string{
+		expired.Id: model.PLUGIN_ADMIN_ACTION_STATUS_EXPIRED,
+		pending.Id: model.PLUGIN_ADMIN_ACTION_STATUS_PENDING,
+	} {
+		result := <-th.App.Srv.Store.PluginAdminActionRequest().Get(id)
+		require.Nil(t, result.Err)
+		assert.Equal(t, status, result.Data.(*model.PluginAdminActionRequest).Status)
+	}
+}
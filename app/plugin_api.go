@@ -8,9 +8,12 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/mattermost/mattermost-server/utils/markdown"
 )
 
 type PluginAPI struct {
@@ -40,8 +43,24 @@ func (api *PluginAPI) LoadPluginConfiguration(dest interface{}) error {
 	}
 
 	// If we have settings given we override the defaults with them
-	for setting, value := range api.app.Config().PluginSettings.Plugins[api.id] {
-		finalConfig[strings.ToLower(setting)] = value
+	config := api.app.Config()
+	secrets := pluginManifestSecretSettings(api.manifest)
+	for setting, value := range config.PluginSettings.Plugins[api.id] {
+		setting = strings.ToLower(setting)
+
+		// Secret settings may be stored encrypted, and are only ever given decrypted to the plugin.
+		if stringValue, ok := value.(string); ok && secrets[setting] {
+			key, err := newPluginSettingsEncryptionKey(config)
+			if err == nil {
+				value, err = decryptPluginSetting(stringValue, key)
+			}
+			if err != nil {
+				api.logger.Error("Error decrypting secret setting for plugin", mlog.String("key", setting), mlog.Err(err))
+				continue
+			}
+		}
+
+		finalConfig[setting] = value
 	}
 
 	if pluginSettingsJsonBytes, err := json.Marshal(finalConfig); err != nil {
@@ -73,6 +92,44 @@ func (api *PluginAPI) SaveConfig(config *model.Config) *model.AppError {
 	return api.app.SaveConfig(config, true)
 }
 
+func (api *PluginAPI) GetSiteURL() string {
+	// The config is read on every call, rather than relying on the site URL cached when the config
+	// file was loaded, so that changes made by a system administrator are seen immediately.
+	return strings.TrimRight(*api.app.Config().ServiceSettings.SiteURL, "/")
+}
+
+func (api *PluginAPI) GetSiteName() string {
+	return api.app.Config().TeamSettings.SiteName
+}
+
+func (api *PluginAPI) GetSupportEmail() string {
+	return *api.app.Config().SupportSettings.SupportEmail
+}
+
+func (api *PluginAPI) GetBrandImage() ([]byte, *model.AppError) {
+	if !*api.app.Config().TeamSettings.EnableCustomBrand {
+		return nil, model.NewAppError("GetBrandImage", "plugin.api.get_brand_image.not_found.app_error", nil, "custom branding is disabled", http.StatusNotFound)
+	}
+
+	if len(*api.app.Config().FileSettings.DriverName) == 0 {
+		return nil, model.NewAppError("GetBrandImage", "api.admin.get_brand_image.storage.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	exists, err := api.app.FileExists(BRAND_FILE_PATH + BRAND_FILE_NAME)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, model.NewAppError("GetBrandImage", "plugin.api.get_brand_image.not_found.app_error", nil, "no brand image has been uploaded", http.StatusNotFound)
+	}
+
+	return api.app.GetBrandImage()
+}
+
+func (api *PluginAPI) GetAllowedTeams() []string {
+	return api.app.GetPluginAllowedTeams(api.id)
+}
+
 func (api *PluginAPI) CreateTeam(team *model.Team) (*model.Team, *model.AppError) {
 	return api.app.CreateTeam(team)
 }
@@ -117,6 +174,14 @@ func (api *PluginAPI) GetTeamMember(teamId, userId string) (*model.TeamMember, *
 	return api.app.GetTeamMember(teamId, userId)
 }
 
+func (api *PluginAPI) GetTeamMembersModifiedSince(teamId string, since int64) ([]*model.TeamMember, *model.AppError) {
+	return api.app.GetTeamMembersModifiedSince(teamId, since)
+}
+
+func (api *PluginAPI) GetTeamStats(teamId string) (*model.TeamStats, *model.AppError) {
+	return api.app.GetTeamStats(teamId)
+}
+
 func (api *PluginAPI) UpdateTeamMemberRoles(teamId, userId, newRoles string) (*model.TeamMember, *model.AppError) {
 	return api.app.UpdateTeamMemberRoles(teamId, userId, newRoles)
 }
@@ -150,6 +215,23 @@ func (api *PluginAPI) UpdateUser(user *model.User) (*model.User, *model.AppError
 	return api.app.UpdateUser(user, true)
 }
 
+func (api *PluginAPI) GetUserNotifyProps(userId string) (model.StringMap, *model.AppError) {
+	user, err := api.app.GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.NotifyProps, nil
+}
+
+func (api *PluginAPI) SetUserPluginSetting(userId, key, value string) *model.AppError {
+	return api.app.SetUserPluginSetting(api.id, userId, key, value)
+}
+
+func (api *PluginAPI) GetUserPluginSettings(userId string) (map[string]string, *model.AppError) {
+	return api.app.GetUserPluginSettings(api.id, userId)
+}
+
 func (api *PluginAPI) GetUserStatus(userId string) (*model.Status, *model.AppError) {
 	return api.app.GetStatus(userId)
 }
@@ -175,6 +257,44 @@ func (api *PluginAPI) UpdateUserStatus(userId, status string) (*model.Status, *m
 	return api.app.GetStatus(userId)
 }
 
+func (api *PluginAPI) GetSessions(userId string) ([]*model.Session, *model.AppError) {
+	sessions, err := api.app.GetSessions(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		session.Sanitize()
+	}
+
+	return sessions, nil
+}
+
+func (api *PluginAPI) RevokeSession(sessionId string) *model.AppError {
+	session, err := api.app.GetSessionById(sessionId)
+	if err != nil {
+		return err
+	}
+
+	if err := api.app.RevokeSession(session); err != nil {
+		return err
+	}
+
+	api.app.CloseWebConnsForUser(session.UserId, session.Token)
+
+	return nil
+}
+
+func (api *PluginAPI) RevokeAllSessionsForUser(userId string) *model.AppError {
+	if err := api.app.RevokeAllSessions(userId); err != nil {
+		return err
+	}
+
+	api.app.CloseWebConnsForUser(userId, "")
+
+	return nil
+}
+
 func (api *PluginAPI) CreateChannel(channel *model.Channel) (*model.Channel, *model.AppError) {
 	return api.app.CreateChannel(channel, false)
 }
@@ -187,6 +307,22 @@ func (api *PluginAPI) DeleteChannel(channelId string) *model.AppError {
 	return api.app.DeleteChannel(channel, "")
 }
 
+func (api *PluginAPI) RestoreChannel(channelId string) (*model.Channel, *model.AppError) {
+	channel, err := api.app.GetChannel(channelId)
+	if err != nil {
+		return nil, err
+	}
+	return api.app.RestoreChannel(channel)
+}
+
+func (api *PluginAPI) ConvertChannelToPrivate(channelId string) (*model.Channel, *model.AppError) {
+	channel, err := api.app.GetChannel(channelId)
+	if err != nil {
+		return nil, err
+	}
+	return api.app.ConvertChannelToPrivate(channel, nil)
+}
+
 func (api *PluginAPI) GetPublicChannelsForTeam(teamId string, offset, limit int) (*model.ChannelList, *model.AppError) {
 	return api.app.GetPublicChannelsForTeam(teamId, offset, limit)
 }
@@ -215,7 +351,16 @@ func (api *PluginAPI) UpdateChannel(channel *model.Channel) (*model.Channel, *mo
 	return api.app.UpdateChannel(channel)
 }
 
-func (api *PluginAPI) AddChannelMember(channelId, userId string) (*model.ChannelMember, *model.AppError) {
+func (api *PluginAPI) SetChannelModerationForMembers(channelId string, allowPost bool) *model.AppError {
+	_, err := api.app.SetChannelModerationForMembers(channelId, allowPost)
+	return err
+}
+
+func (api *PluginAPI) GetChannelModeration(channelId string) (*model.ChannelModeration, *model.AppError) {
+	return api.app.GetChannelModeration(channelId)
+}
+
+func (api *PluginAPI) AddChannelMember(channelId, userId string, opts model.MemberAddOptions) (*model.ChannelMember, *model.AppError) {
 	// For now, don't allow overriding these via the plugin API.
 	userRequestorId := ""
 	postRootId := ""
@@ -225,17 +370,63 @@ func (api *PluginAPI) AddChannelMember(channelId, userId string) (*model.Channel
 		return nil, err
 	}
 
-	return api.app.AddChannelMember(userId, channel, userRequestorId, postRootId)
+	member, err := api.app.AddChannelMemberWithOptions(userId, channel, userRequestorId, postRootId, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the suppression so that admins can account for the missing join message.
+	if opts.SuppressJoinMessage || opts.SuppressNotifications {
+		api.logger.Info("Added channel member with suppressed join message or notifications",
+			mlog.String("user_id", userId),
+			mlog.String("channel_id", channelId),
+			mlog.Bool("suppress_join_message", opts.SuppressJoinMessage),
+			mlog.Bool("suppress_notifications", opts.SuppressNotifications),
+		)
+
+		audit := &model.Audit{
+			UserId:    userId,
+			Action:    "/plugins/" + api.id + "/add_channel_member",
+			ExtraInfo: fmt.Sprintf("channel_id=%v suppress_join_message=%v suppress_notifications=%v", channelId, opts.SuppressJoinMessage, opts.SuppressNotifications),
+		}
+		if result := <-api.app.Srv.Store.Audit().Save(audit); result.Err != nil {
+			api.logger.Error("Failed to save audit record", mlog.Err(result.Err))
+		}
+	}
+
+	return member, nil
 }
 
 func (api *PluginAPI) GetChannelMember(channelId, userId string) (*model.ChannelMember, *model.AppError) {
 	return api.app.GetChannelMember(channelId, userId)
 }
 
+func (api *PluginAPI) GetChannelMembersModifiedSince(channelId string, since int64) (*model.ChannelMemberChanges, *model.AppError) {
+	return api.app.GetChannelMembersModifiedSince(channelId, since)
+}
+
+func (api *PluginAPI) GetChannelStats(channelId string) (*model.ChannelStats, *model.AppError) {
+	memberCount, err := api.app.GetChannelMemberCount(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ChannelStats{ChannelId: channelId, MemberCount: memberCount}, nil
+}
+
 func (api *PluginAPI) UpdateChannelMemberRoles(channelId, userId, newRoles string) (*model.ChannelMember, *model.AppError) {
 	return api.app.UpdateChannelMemberRoles(channelId, userId, newRoles)
 }
 
+func (api *PluginAPI) GetChannelMemberNotifyProps(channelId, userId string) (model.StringMap, *model.AppError) {
+	member, err := api.app.GetChannelMember(channelId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return member.NotifyProps, nil
+}
+
 func (api *PluginAPI) UpdateChannelMemberNotifications(channelId, userId string, notifications map[string]string) (*model.ChannelMember, *model.AppError) {
 	return api.app.UpdateChannelMemberNotifyProps(notifications, channelId, userId)
 }
@@ -244,6 +435,19 @@ func (api *PluginAPI) DeleteChannelMember(channelId, userId string) *model.AppEr
 	return api.app.LeaveChannel(channelId, userId)
 }
 
+func (api *PluginAPI) ExportChannel(channelId string, format string) (string, *model.AppError) {
+	job, err := api.app.ExportChannel(api.id, channelId, format)
+	if err != nil {
+		return "", err
+	}
+
+	return job.Id, nil
+}
+
+func (api *PluginAPI) GetExportStatus(jobId string) (*model.ChannelExportStatus, *model.AppError) {
+	return api.app.GetChannelExportStatus(api.id, jobId)
+}
+
 func (api *PluginAPI) CreatePost(post *model.Post) (*model.Post, *model.AppError) {
 	return api.app.CreatePostMissingChannel(post, true)
 }
@@ -265,18 +469,177 @@ func (api *PluginAPI) UpdatePost(post *model.Post) (*model.Post, *model.AppError
 	return api.app.UpdatePost(post, false)
 }
 
+func (api *PluginAPI) AddPostMetadata(postId, namespace string, data []byte) *model.AppError {
+	return api.app.AddPostMetadata(api.id, postId, namespace, data)
+}
+
+func (api *PluginAPI) RequestPostAcknowledgement(postId string) *model.AppError {
+	_, err := api.app.RequestPostAcknowledgement(postId)
+	return err
+}
+
+func (api *PluginAPI) GetPostAcknowledgements(postId string) ([]*model.PostAcknowledgement, *model.AppError) {
+	return api.app.GetPostAcknowledgements(postId)
+}
+
+func (api *PluginAPI) FollowThreadForUser(userId, postId string) *model.AppError {
+	return api.app.FollowThread(userId, postId)
+}
+
+func (api *PluginAPI) UnfollowThreadForUser(userId, postId string) *model.AppError {
+	return api.app.UnfollowThread(userId, postId)
+}
+
+func (api *PluginAPI) DeleteFile(fileId string) *model.AppError {
+	if err := api.app.DeleteFile(fileId); err != nil {
+		return err
+	}
+
+	api.logger.Info("Deleted file", mlog.String("file_id", fileId))
+
+	audit := &model.Audit{
+		Action:    "/plugins/" + api.id + "/delete_file",
+		ExtraInfo: "file_id=" + fileId,
+	}
+	if result := <-api.app.Srv.Store.Audit().Save(audit); result.Err != nil {
+		api.logger.Error("Failed to save audit record", mlog.Err(result.Err))
+	}
+
+	return nil
+}
+
+func (api *PluginAPI) GetFileThumbnail(fileId string) ([]byte, *model.AppError) {
+	return api.app.GetFileThumbnail(fileId)
+}
+
+func (api *PluginAPI) NotifyUser(userId string, notification model.PluginNotification) *model.AppError {
+	return api.app.SendPluginNotification(api.id, userId, &notification)
+}
+
+func (api *PluginAPI) WouldUserBeNotified(userId, channelId string, mention bool) (model.NotificationPrediction, *model.AppError) {
+	return api.app.WouldUserBeNotified(userId, channelId, mention)
+}
+
+func (api *PluginAPI) SetAnnouncementBanner(banner model.PluginBanner) *model.AppError {
+	if banner.Text == "" {
+		return api.app.SetPluginBanner(api.id, nil)
+	}
+
+	return api.app.SetPluginBanner(api.id, &banner)
+}
+
+func (api *PluginAPI) CreateSubscription(subscription model.PluginSubscription) (*model.PluginSubscription, *model.AppError) {
+	return api.app.CreatePluginSubscription(api.id, &subscription)
+}
+
+func (api *PluginAPI) ListSubscriptionsForChannel(channelId string) ([]*model.PluginSubscription, *model.AppError) {
+	return api.app.GetPluginSubscriptionsForChannelByPlugin(api.id, channelId)
+}
+
+func (api *PluginAPI) DeleteSubscription(id string) *model.AppError {
+	return api.app.DeletePluginSubscription(api.id, id)
+}
+
+func (api *PluginAPI) RegisterOAuthProvider(provider model.PluginOAuthProvider) *model.AppError {
+	return api.app.RegisterPluginOAuthProvider(api.id, provider)
+}
+
+func (api *PluginAPI) GetOAuthToken(userId string) (*model.PluginOAuthToken, *model.AppError) {
+	return api.app.GetPluginOAuthToken(api.id, userId)
+}
+
+func (api *PluginAPI) RequestAdminAction(action model.PluginAdminActionRequest) (*model.PluginAdminActionRequest, *model.AppError) {
+	return api.app.RequestPluginAdminAction(api.id, &action)
+}
+
 func (api *PluginAPI) KVSet(key string, value []byte) *model.AppError {
 	return api.app.SetPluginKey(api.id, key, value)
 }
 
+func (api *PluginAPI) KVSetWithExpiry(key string, value []byte, expireInSeconds int64) *model.AppError {
+	return api.app.SetPluginKeyWithExpiry(api.id, key, value, expireInSeconds)
+}
+
+func (api *PluginAPI) KVSetMultiple(kvs map[string][]byte) *model.AppError {
+	return api.app.SetPluginKeys(api.id, kvs)
+}
+
+func (api *PluginAPI) KVSetAtomic(ops []model.PluginKVOp) *model.AppError {
+	return api.app.SetPluginKeysAtomic(api.id, ops)
+}
+
+func (api *PluginAPI) KVCompareAndSet(key string, oldValue, newValue []byte) (bool, *model.AppError) {
+	return api.app.CompareAndSetPluginKey(api.id, key, oldValue, newValue)
+}
+
+func (api *PluginAPI) KVIncrement(key string, delta int64) (int64, *model.AppError) {
+	return api.app.IncrementPluginKey(api.id, key, delta)
+}
+
 func (api *PluginAPI) KVGet(key string) ([]byte, *model.AppError) {
 	return api.app.GetPluginKey(api.id, key)
 }
 
+func (api *PluginAPI) KVGetWithExists(key string) ([]byte, bool, *model.AppError) {
+	return api.app.GetPluginKeyWithExists(api.id, key)
+}
+
+func (api *PluginAPI) KVGetWithOptions(key string, opts model.PluginKVGetOptions) ([]byte, *model.AppError) {
+	return api.app.GetPluginKeyWithOptions(api.id, key, opts)
+}
+
+func (api *PluginAPI) KVGetMultiple(keys []string) (map[string][]byte, *model.AppError) {
+	return api.app.GetPluginKeys(api.id, keys)
+}
+
+func (api *PluginAPI) KVList(page, perPage int) ([]string, *model.AppError) {
+	return api.app.ListPluginKeys(api.id, page, perPage)
+}
+
+func (api *PluginAPI) KVListWithPrefix(prefix string, page, perPage int) ([]string, *model.AppError) {
+	return api.app.ListPluginKeysWithPrefix(api.id, prefix, page, perPage)
+}
+
 func (api *PluginAPI) KVDelete(key string) *model.AppError {
 	return api.app.DeletePluginKey(api.id, key)
 }
 
+func (api *PluginAPI) KVDeleteAll() *model.AppError {
+	return api.app.DeleteAllPluginKeys(api.id)
+}
+
+func (api *PluginAPI) KVCompareAndDelete(key string, oldValue []byte) (bool, *model.AppError) {
+	return api.app.CompareAndDeletePluginKey(api.id, key, oldValue)
+}
+
+func (api *PluginAPI) KVLock(key string, ttl time.Duration) (string, *model.AppError) {
+	return api.app.LockPluginKey(api.id, key, ttl)
+}
+
+func (api *PluginAPI) KVUnlock(key, token string) *model.AppError {
+	_, err := api.app.UnlockPluginKey(api.id, key, token)
+	return err
+}
+
+func (api *PluginAPI) GetServerHealth() model.PluginServerHealth {
+	return api.app.GetPluginServerHealth()
+}
+
+func (api *PluginAPI) IsHighAvailability() bool {
+	return api.app.IsHighAvailability()
+}
+
+func (api *PluginAPI) KVSetAndNotify(key string, value []byte, event string, payload map[string]interface{}, broadcast *model.WebsocketBroadcast) *model.AppError {
+	// The write is made to the master database and committed by the time it returns.
+	if err := api.app.SetPluginKey(api.id, key, value); err != nil {
+		return err
+	}
+
+	api.PublishWebSocketEvent(event, payload, broadcast)
+
+	return nil
+}
+
 func (api *PluginAPI) PublishWebSocketEvent(event string, payload map[string]interface{}, broadcast *model.WebsocketBroadcast) {
 	api.app.Publish(&model.WebSocketEvent{
 		Event:     fmt.Sprintf("custom_%v_%v", api.id, event),
@@ -285,6 +648,42 @@ func (api *PluginAPI) PublishWebSocketEvent(event string, payload map[string]int
 	})
 }
 
+func (api *PluginAPI) WebSocketBroadcastToConnection(webConnID string, event *model.WebSocketEvent) {
+	if webConnID == "" || event == nil {
+		return
+	}
+
+	api.app.Publish(&model.WebSocketEvent{
+		Event:     fmt.Sprintf("custom_%v_%v", api.id, event.Event),
+		Data:      event.Data,
+		Broadcast: &model.WebsocketBroadcast{ConnectionId: webConnID},
+	})
+}
+
+func (api *PluginAPI) SendToUserWebapp(userId string, message model.PluginWebappMessage) (string, *model.AppError) {
+	return api.app.SendPluginWebappMessage(api.id, userId, &message)
+}
+
+func (api *PluginAPI) StripMarkdown(message string) string {
+	return markdown.StripMarkdown(message)
+}
+
+func (api *PluginAPI) TruncateForNotification(message string, maxRunes int) string {
+	return markdown.TruncateForNotification(message, maxRunes)
+}
+
+func (api *PluginAPI) RenderMessageToHTML(message string) (string, *model.AppError) {
+	return api.app.RenderMessageToHTML(message)
+}
+
+func (api *PluginAPI) GetRequestId(c *plugin.Context) string {
+	if c == nil {
+		return ""
+	}
+
+	return c.RequestId
+}
+
 func (api *PluginAPI) LogDebug(msg string, keyValuePairs ...interface{}) {
 	api.logger.Debug(msg, keyValuePairs...)
 }
@@ -0,0 +1,126 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/utils"
+)
+
+// PLUGIN_API_SPEC_MAX_SIZE is the largest OpenAPI document a plugin may declare.
+const PLUGIN_API_SPEC_MAX_SIZE = 1024 * 1024
+
+// readPluginApiSpec reads and checks the OpenAPI document declared by the manifest of the plugin in
+// the given directory, returning it along with the routes it declares.
+func readPluginApiSpec(pluginDir string, manifest *model.Manifest) ([]byte, []*model.PluginApiRoute, *model.AppError) {
+	specPath := filepath.Clean(manifest.ApiSpec)
+	if specPath == "." || utils.PathTraversesUpward(specPath) {
+		return nil, nil, model.NewAppError("readPluginApiSpec", "app.plugin.api_spec.path.app_error", nil, "path="+manifest.ApiSpec, http.StatusBadRequest)
+	}
+
+	file, err := os.Open(filepath.Join(pluginDir, specPath))
+	if err != nil {
+		return nil, nil, model.NewAppError("readPluginApiSpec", "app.plugin.api_spec.path.app_error", nil, "path="+specPath+", err="+err.Error(), http.StatusBadRequest)
+	}
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(file, PLUGIN_API_SPEC_MAX_SIZE+1))
+	if err != nil {
+		return nil, nil, model.NewAppError("readPluginApiSpec", "app.plugin.api_spec.path.app_error", nil, "path="+specPath+", err="+err.Error(), http.StatusBadRequest)
+	}
+
+	if len(data) > PLUGIN_API_SPEC_MAX_SIZE {
+		return nil, nil, model.NewAppError("readPluginApiSpec", "app.plugin.api_spec.too_large.app_error", map[string]interface{}{"Max": PLUGIN_API_SPEC_MAX_SIZE}, "path="+specPath, http.StatusBadRequest)
+	}
+
+	routes, err := model.PluginApiRoutesFromOpenApi(data)
+	if err != nil {
+		return nil, nil, model.NewAppError("readPluginApiSpec", "app.plugin.api_spec.invalid.app_error", nil, "path="+specPath+", err="+err.Error(), http.StatusBadRequest)
+	}
+
+	return data, routes, nil
+}
+
+// validatePluginBundleWarnings checks the extracted plugin bundle in the given directory for problems
+// that do not prevent its installation, but leave part of it unusable.
+func validatePluginBundleWarnings(pluginDir string, manifest *model.Manifest) []*model.AppError {
+	var warnings []*model.AppError
+
+	if manifest.HasApiSpec() {
+		if _, _, err := readPluginApiSpec(pluginDir, manifest); err != nil {
+			warnings = append(warnings, err)
+		}
+	}
+
+	// Plugins that do not declare their capabilities are granted those configured by default, so
+	// administrators cannot tell from the manifest what they may do.
+	if manifest.HasServer() && !manifest.DeclaresCapabilities() {
+		warnings = append(warnings, model.NewAppError("validatePluginBundle", "app.plugin.validate.capabilities_undeclared.app_error", nil, "", http.StatusBadRequest))
+	}
+
+	return warnings
+}
+
+// GetPluginApiSpec returns the OpenAPI document declared by the given active plugin, along with an
+// etag that changes whenever the document does.
+func (a *App) GetPluginApiSpec(pluginId string) ([]byte, string, *model.AppError) {
+	if !a.PluginsReady() {
+		return nil, "", model.NewAppError("GetPluginApiSpec", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	for _, bundle := range a.Plugins.Active() {
+		if bundle.Manifest.Id != pluginId || !bundle.Manifest.HasApiSpec() {
+			continue
+		}
+
+		data, _, appErr := readPluginApiSpec(filepath.Dir(bundle.ManifestPath), bundle.Manifest)
+		if appErr != nil {
+			mlog.Warn("Unable to read plugin API spec", mlog.String("plugin_id", pluginId), mlog.Err(appErr))
+			break
+		}
+
+		return data, model.Etag(pluginId, fmt.Sprintf("%x", sha256.Sum256(data))), nil
+	}
+
+	return nil, "", model.NewAppError("GetPluginApiSpec", "app.plugin.api_spec.not_found.app_error", nil, "plugin_id="+pluginId, http.StatusNotFound)
+}
+
+// GetPluginApiSpecs returns the routes declared by every active plugin with a valid OpenAPI
+// document, ordered by plugin id.
+func (a *App) GetPluginApiSpecs() ([]*model.PluginApiSpec, *model.AppError) {
+	if !a.PluginsReady() {
+		return nil, model.NewAppError("GetPluginApiSpecs", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	specs := []*model.PluginApiSpec{}
+	for _, bundle := range a.Plugins.Active() {
+		if !bundle.Manifest.HasApiSpec() {
+			continue
+		}
+
+		_, routes, appErr := readPluginApiSpec(filepath.Dir(bundle.ManifestPath), bundle.Manifest)
+		if appErr != nil {
+			mlog.Warn("Unable to read plugin API spec", mlog.String("plugin_id", bundle.Manifest.Id), mlog.Err(appErr))
+			continue
+		}
+
+		specs = append(specs, &model.PluginApiSpec{PluginId: bundle.Manifest.Id, Routes: routes})
+	}
+
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].PluginId < specs[j].PluginId
+	})
+
+	return specs, nil
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+const testPluginApiSpec = `{
+	"openapi": "3.0.0",
+	"paths": {
+		"/status": {"get": {"summary": "Get the status"}},
+		"/items": {"post": {}}
+	}
+}`
+
+func writeTestPluginFiles(t *testing.T, dir string, files map[string]string) {
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	}
+}
+
+func TestValidatePluginBundleWarnings(t *testing.T) {
+	for name, tc := range map[string]struct {
+		ApiSpec string
+		Files   map[string]string
+		Warning string
+	}{
+		"no spec":        {},
+		"valid spec":     {ApiSpec: "api/spec.json", Files: map[string]string{"api/spec.json": testPluginApiSpec}},
+		"missing spec":   {ApiSpec: "spec.json", Warning: "app.plugin.api_spec.path.app_error"},
+		"directory":      {ApiSpec: "api", Files: map[string]string{"api/spec.json": testPluginApiSpec}, Warning: "app.plugin.api_spec.path.app_error"},
+		"outside bundle": {ApiSpec: "../spec.json", Warning: "app.plugin.api_spec.path.app_error"},
+		"invalid spec":   {ApiSpec: "spec.json", Files: map[string]string{"spec.json": "{}"}, Warning: "app.plugin.api_spec.invalid.app_error"},
+		"large spec":     {ApiSpec: "spec.json", Files: map[string]string{"spec.json": strings.Repeat(" ", PLUGIN_API_SPEC_MAX_SIZE) + testPluginApiSpec}, Warning: "app.plugin.api_spec.too_large.app_error"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			writeTestPluginFiles(t, dir, tc.Files)
+
+			warnings := validatePluginBundleWarnings(dir, &model.Manifest{Id: "testplugin", ApiSpec: tc.ApiSpec})
+			if tc.Warning == "" {
+				assert.Empty(t, warnings)
+			} else if assert.Len(t, warnings, 1) {
+				assert.Equal(t, tc.Warning, warnings[0].Id)
+			}
+		})
+	}
+}
+
+func TestInstallPluginWithWarnings(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginDir)
+	webappPluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(webappPluginDir)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.Directory = pluginDir
+	})
+
+	env, err := plugin.NewEnvironment(th.App.NewPluginAPI, pluginDir, webappPluginDir, th.App.Log)
+	require.NoError(t, err)
+	th.App.Plugins = env
+
+	var bundle bytes.Buffer
+	gzipWriter := gzip.NewWriter(&bundle)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, contents := range map[string]string{
+		"plugin.json": `{"id": "testplugin", "api_spec": "spec.json", "webapp": {"bundle_path": "main.js"}}`,
+		"spec.json":   "not json",
+		"main.js":     "",
+	} {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(contents)), Typeflag: tar.TypeReg}))
+		_, err = tarWriter.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+
+	result, appErr := th.App.InstallPluginWithWarnings(&bundle, nil, false)
+	require.Nil(t, appErr)
+	assert.Equal(t, "testplugin", result.Manifest.Id)
+	if assert.Len(t, result.Warnings, 1) {
+		assert.Equal(t, "app.plugin.api_spec.invalid.app_error", result.Warnings[0].Id)
+	}
+
+	// The plugin is installed regardless of the warnings.
+	_, err = os.Stat(filepath.Join(pluginDir, "testplugin", "spec.json"))
+	assert.NoError(t, err)
+}
+
+func TestGetPluginApiSpecs(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = true })
+
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginDir)
+	webappPluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(webappPluginDir)
+
+	env, err := plugin.NewEnvironment(th.App.NewPluginAPI, pluginDir, webappPluginDir, th.App.Log)
+	require.NoError(t, err)
+	th.App.Plugins = env
+
+	for id, files := range map[string]map[string]string{
+		"valid":    {"plugin.json": `{"id": "valid", "api_spec": "spec.json"}`, "spec.json": testPluginApiSpec},
+		"invalid":  {"plugin.json": `{"id": "invalid", "api_spec": "spec.json"}`, "spec.json": "{}"},
+		"none":     {"plugin.json": `{"id": "none"}`},
+		"inactive": {"plugin.json": `{"id": "inactive", "api_spec": "spec.json"}`, "spec.json": testPluginApiSpec},
+	} {
+		writeTestPluginFiles(t, filepath.Join(pluginDir, id), files)
+		if id != "inactive" {
+			_, _, err := env.Activate(id)
+			require.NoError(t, err)
+		}
+	}
+
+	specs, appErr := th.App.GetPluginApiSpecs()
+	require.Nil(t, appErr)
+	assert.Equal(t, []*model.PluginApiSpec{
+		{
+			PluginId: "valid",
+			Routes: []*model.PluginApiRoute{
+				{Method: "POST", Path: "/items"},
+				{Method: "GET", Path: "/status", Summary: "Get the status"},
+			},
+		},
+	}, specs)
+
+	spec, etag, appErr := th.App.GetPluginApiSpec("valid")
+	require.Nil(t, appErr)
+	assert.Equal(t, testPluginApiSpec, string(spec))
+	assert.NotEmpty(t, etag)
+
+	for _, id := range []string{"invalid", "none", "inactive", "missing"} {
+		_, _, appErr = th.App.GetPluginApiSpec(id)
+		require.NotNil(t, appErr, id)
+		assert.Equal(t, "app.plugin.api_spec.not_found.app_error", appErr.Id)
+	}
+
+	// The etag changes along with the spec.
+	writeTestPluginFiles(t, filepath.Join(pluginDir, "valid"), map[string]string{"spec.json": `{"openapi": "3.0.0", "paths": {}}`})
+	_, newEtag, appErr := th.App.GetPluginApiSpec("valid")
+	require.Nil(t, appErr)
+	assert.NotEqual(t, etag, newEtag)
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = false })
+	_, appErr = th.App.GetPluginApiSpecs()
+	assert.NotNil(t, appErr)
+}
@@ -4,11 +4,16 @@
 package app
 
 import (
+	"bytes"
 	"encoding/json"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/plugin"
@@ -55,6 +60,257 @@ func TestPluginAPIUpdateUserStatus(t *testing.T) {
 	assert.Nil(t, status)
 }
 
+func TestPluginAPISessions(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+	api := th.SetupPluginAPI()
+
+	s := httptest.NewServer(http.HandlerFunc(dummyWebsocketHandler(t)))
+	defer s.Close()
+
+	th.App.HubStart()
+	defer th.App.HubStop()
+
+	wc1 := registerDummyWebConn(t, th.App, s.Listener.Addr(), th.BasicUser.Id)
+	wc2 := registerDummyWebConn(t, th.App, s.Listener.Addr(), th.BasicUser.Id)
+	registerDummyWebConn(t, th.App, s.Listener.Addr(), th.BasicUser2.Id)
+
+	require.Equal(t, 3, waitForWebsocketConnections(th.App, 3))
+
+	sessions, err := api.GetSessions(th.BasicUser.Id)
+	require.Nil(t, err)
+	require.Len(t, sessions, 2)
+	for _, session := range sessions {
+		assert.Equal(t, th.BasicUser.Id, session.UserId)
+		assert.Empty(t, session.Token)
+	}
+
+	t.Run("revoke one session", func(t *testing.T) {
+		require.Nil(t, api.RevokeSession(wc1.GetSession().Id))
+
+		_, err := th.App.GetSession(wc1.GetSessionToken())
+		assert.NotNil(t, err)
+		_, err = th.App.GetSession(wc2.GetSessionToken())
+		assert.Nil(t, err)
+
+		assert.Equal(t, 2, waitForWebsocketConnections(th.App, 2))
+	})
+
+	t.Run("revoke all sessions", func(t *testing.T) {
+		require.Nil(t, api.RevokeAllSessionsForUser(th.BasicUser.Id))
+
+		sessions, err := api.GetSessions(th.BasicUser.Id)
+		require.Nil(t, err)
+		assert.Empty(t, sessions)
+
+		// Only the connection of the other user is left open.
+		assert.Equal(t, 1, waitForWebsocketConnections(th.App, 1))
+	})
+
+	assert.NotNil(t, api.RevokeSession(model.NewId()))
+}
+
+// waitForWebsocketConnections waits a few seconds for the number of open websocket connections to
+// become count, returning the number last seen.
+func waitForWebsocketConnections(a *App, count int) int {
+	for i := 0; i < 50 && a.TotalWebsocketConnections() != count; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	return a.TotalWebsocketConnections()
+}
+
+func TestPluginAPISiteIdentity(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+	api := th.SetupPluginAPI()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.SiteURL = "http://example.com/chat/"
+		cfg.TeamSettings.SiteName = "Example"
+		*cfg.SupportSettings.SupportEmail = "support@example.com"
+	})
+
+	assert.Equal(t, "http://example.com/chat", api.GetSiteURL())
+	assert.Equal(t, "Example", api.GetSiteName())
+	assert.Equal(t, "support@example.com", api.GetSupportEmail())
+
+	// Changes are seen without restarting the plugin
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.SiteURL = "https://chat.example.com"
+	})
+	assert.Equal(t, "https://chat.example.com", api.GetSiteURL())
+}
+
+func TestPluginAPIGetBrandImage(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+	api := th.SetupPluginAPI()
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.TeamSettings.EnableCustomBrand = false })
+	_, err := api.GetBrandImage()
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.TeamSettings.EnableCustomBrand = true })
+	th.App.RemoveFile(BRAND_FILE_PATH + BRAND_FILE_NAME)
+	_, err = api.GetBrandImage()
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+	_, err = th.App.WriteFile(bytes.NewReader([]byte("image")), BRAND_FILE_PATH+BRAND_FILE_NAME)
+	require.Nil(t, err)
+	defer th.App.RemoveFile(BRAND_FILE_PATH + BRAND_FILE_NAME)
+
+	img, err := api.GetBrandImage()
+	require.Nil(t, err)
+	assert.Equal(t, []byte("image"), img)
+}
+
+func TestPluginAPIRenderMessageToHTML(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+	api := th.SetupPluginAPI()
+
+	expected, err := ioutil.ReadFile("../utils/testdata/post_message.html")
+	require.NoError(t, err)
+
+	html, appErr := api.RenderMessageToHTML("Hello <b>@alice</b> & **team**\n> quoted")
+	require.Nil(t, appErr)
+	assert.Equal(t, strings.TrimSuffix(string(expected), "\n"), html)
+}
+
+func TestPluginAPIAddChannelMember(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+	api := th.SetupPluginAPI()
+
+	channel := th.CreateChannel(th.BasicTeam)
+
+	hasJoinMessage := func(userId string) bool {
+		posts, err := th.App.GetPostsPage(channel.Id, 0, 100)
+		require.Nil(t, err)
+		for _, post := range posts.Posts {
+			if post.Type == model.POST_JOIN_CHANNEL && post.UserId == userId {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("default options", func(t *testing.T) {
+		user := th.CreateUser()
+		th.LinkUserToTeam(user, th.BasicTeam)
+
+		member, err := api.AddChannelMember(channel.Id, user.Id, model.MemberAddOptions{})
+		require.Nil(t, err)
+		assert.Equal(t, user.Id, member.UserId)
+		assert.True(t, hasJoinMessage(user.Id))
+
+		audits, err := th.App.GetAudits(user.Id, 10)
+		require.Nil(t, err)
+		for _, audit := range audits {
+			assert.NotEqual(t, "/plugins/pluginid/add_channel_member", audit.Action)
+		}
+	})
+
+	t.Run("suppressed join message", func(t *testing.T) {
+		user := th.CreateUser()
+		th.LinkUserToTeam(user, th.BasicTeam)
+
+		member, err := api.AddChannelMember(channel.Id, user.Id, model.MemberAddOptions{SuppressJoinMessage: true})
+		require.Nil(t, err)
+		assert.Equal(t, user.Id, member.UserId)
+		assert.False(t, hasJoinMessage(user.Id))
+
+		_, err = th.App.GetChannelMember(channel.Id, user.Id)
+		require.Nil(t, err)
+
+		audits, err := th.App.GetAudits(user.Id, 10)
+		require.Nil(t, err)
+		found := false
+		for _, audit := range audits {
+			if audit.Action == "/plugins/pluginid/add_channel_member" {
+				found = true
+				assert.Contains(t, audit.ExtraInfo, "suppress_join_message=true")
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("suppressed notifications", func(t *testing.T) {
+		user := th.CreateUser()
+		th.LinkUserToTeam(user, th.BasicTeam)
+
+		_, err := api.AddChannelMember(channel.Id, user.Id, model.MemberAddOptions{SuppressNotifications: true})
+		require.Nil(t, err)
+		assert.True(t, hasJoinMessage(user.Id))
+	})
+}
+
+func TestPluginAPIChannelLifecycle(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+	api := th.SetupPluginAPI()
+
+	townSquare, err := th.App.GetChannelByName(model.DEFAULT_CHANNEL, th.BasicTeam.Id, false)
+	require.Nil(t, err)
+
+	t.Run("archive and restore", func(t *testing.T) {
+		channel := th.CreateChannel(th.BasicTeam)
+
+		require.Nil(t, api.DeleteChannel(channel.Id))
+		archived, err := th.App.GetChannel(channel.Id)
+		require.Nil(t, err)
+		assert.NotZero(t, archived.DeleteAt)
+
+		restored, err := api.RestoreChannel(channel.Id)
+		require.Nil(t, err)
+		assert.Zero(t, restored.DeleteAt)
+
+		restored, err = th.App.GetChannel(channel.Id)
+		require.Nil(t, err)
+		assert.Zero(t, restored.DeleteAt)
+	})
+
+	t.Run("default channel cannot be archived", func(t *testing.T) {
+		err := api.DeleteChannel(townSquare.Id)
+		require.NotNil(t, err)
+		assert.Equal(t, "api.channel.delete_channel.cannot.app_error", err.Id)
+	})
+
+	t.Run("restore into archived team", func(t *testing.T) {
+		team := th.CreateTeam()
+		channel := th.CreateChannel(team)
+
+		require.Nil(t, api.DeleteChannel(channel.Id))
+		require.Nil(t, th.App.SoftDeleteTeam(team.Id))
+
+		_, err := api.RestoreChannel(channel.Id)
+		require.NotNil(t, err)
+		assert.Equal(t, "api.channel.restore_channel.team_deleted.app_error", err.Id)
+
+		archived, err := th.App.GetChannel(channel.Id)
+		require.Nil(t, err)
+		assert.NotZero(t, archived.DeleteAt)
+	})
+
+	t.Run("convert to private", func(t *testing.T) {
+		channel := th.CreateChannel(th.BasicTeam)
+
+		converted, err := api.ConvertChannelToPrivate(channel.Id)
+		require.Nil(t, err)
+		assert.Equal(t, model.CHANNEL_PRIVATE, converted.Type)
+
+		_, err = api.ConvertChannelToPrivate(channel.Id)
+		require.NotNil(t, err)
+		assert.Equal(t, "api.channel.convert_channel_to_private.private_channel_error", err.Id)
+
+		_, err = api.ConvertChannelToPrivate(townSquare.Id)
+		require.NotNil(t, err)
+		assert.Equal(t, "api.channel.convert_channel_to_private.default_channel_error", err.Id)
+	})
+}
+
 func TestPluginAPILoadPluginConfiguration(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
@@ -175,3 +431,111 @@ func TestPluginAPILoadPluginConfigurationDefaults(t *testing.T) {
 	_, ret := hooks.MessageWillBePosted(nil, nil)
 	assert.Equal(t, "override35true", ret)
 }
+
+func TestPluginAPILoadPluginConfigurationSecret(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.SettingsEncryptionKey = model.NewRandomString(32)
+	})
+	setupPluginApiTest(t,
+		`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+			"github.com/mattermost/mattermost-server/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) MessageWillBePosted(c *plugin.Context, post *model.Post) (*model.Post, string) {
+			var configuration struct {
+				MySecretSetting string
+			}
+			p.API.LoadPluginConfiguration(&configuration)
+			return nil, configuration.MySecretSetting
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`,
+		`{"id": "testloadpluginconfig", "backend": {"executable": "backend.exe"}, "settings_schema": {
+		"settings": [
+			{
+				"key": "MySecretSetting",
+				"type": "text",
+				"secret": true
+			}
+		]
+	}}`, "testloadpluginconfig", th.App)
+	hooks, err := th.App.Plugins.HooksForPlugin("testloadpluginconfig")
+	require.NoError(t, err)
+
+	cfg := th.App.GetConfig()
+	cfg.PluginSettings.Plugins["testloadpluginconfig"] = map[string]interface{}{"mysecretsetting": "thesecret"}
+	require.Nil(t, th.App.SaveConfig(cfg, false))
+
+	stored := th.App.Config().PluginSettings.Plugins["testloadpluginconfig"]["mysecretsetting"]
+	assert.NotEqual(t, "thesecret", stored)
+	assert.Equal(t, model.FAKE_SETTING, th.App.GetConfig().PluginSettings.Plugins["testloadpluginconfig"]["mysecretsetting"])
+
+	_, ret := hooks.MessageWillBePosted(nil, nil)
+	assert.Equal(t, "thesecret", ret)
+
+	// Saving the configuration as read through the API leaves the secret unchanged.
+	require.Nil(t, th.App.SaveConfig(th.App.GetConfig(), false))
+	assert.Equal(t, stored, th.App.Config().PluginSettings.Plugins["testloadpluginconfig"]["mysecretsetting"])
+
+	_, ret = hooks.MessageWillBePosted(nil, nil)
+	assert.Equal(t, "thesecret", ret)
+}
+
+// publishRecordingCluster calls onPublish with the events published to the other servers of the cluster.
+type publishRecordingCluster struct {
+	FakeClusterInterface
+	onPublish func(*model.WebSocketEvent)
+}
+
+func (c *publishRecordingCluster) SendClusterMessage(msg *model.ClusterMessage) {
+	if msg.Event == model.CLUSTER_EVENT_PUBLISH {
+		c.onPublish(model.WebSocketEventFromJson(strings.NewReader(msg.Data)))
+	}
+}
+
+func TestPluginAPIKVSetAndNotify(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+	api := th.SetupPluginAPI()
+
+	var published []string
+	th.App.Cluster = &publishRecordingCluster{
+		onPublish: func(event *model.WebSocketEvent) {
+			// The write is visible on the master by the time other servers are told of it.
+			kv, err := th.App.Srv.Store.Plugin().GetFromMaster("pluginid", "key")
+			require.Nil(t, err)
+			assert.Equal(t, []byte("value"), kv.Value)
+			published = append(published, event.Event)
+		},
+	}
+	defer func() {
+		th.App.Cluster = nil
+	}()
+
+	err := api.KVSetAndNotify("key", []byte("value"), "changed", map[string]interface{}{"key": "key"}, &model.WebsocketBroadcast{})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"custom_pluginid_changed"}, published)
+
+	// Nothing is published if the write fails.
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.EnableKeyValueCompression = false
+		*cfg.PluginSettings.MaxKeyValueSizeBytes = 100
+	})
+	err = api.KVSetAndNotify("key", make([]byte, 101), "changed", nil, &model.WebsocketBroadcast{})
+	require.NotNil(t, err)
+	assert.Len(t, published, 1)
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	PLUGIN_BANNER_SYSTEM_KEY_PREFIX = model.SYSTEM_PLUGIN_BANNERS + "_"
+	PLUGIN_BANNER_MAX_STORED_LENGTH = 1024
+)
+
+// pluginBannerRecord is the value persisted in the system store for each plugin banner. Plugin ids
+// are too long to key the record directly, so the owning plugin is recorded alongside the banner.
+type pluginBannerRecord struct {
+	PluginId string              `json:"plugin_id"`
+	Banner   *model.PluginBanner `json:"banner"`
+}
+
+func pluginBannerSystemKey(pluginId string) string {
+	return fmt.Sprintf("%v%x", PLUGIN_BANNER_SYSTEM_KEY_PREFIX, md5.Sum([]byte(pluginId)))
+}
+
+// SetPluginBanner registers the announcement banner shown on behalf of the given plugin, replacing
+// any it registered before, or clears it if banner is nil. The banner is persisted so that it is
+// shown across restarts until the plugin clears it or is deactivated.
+func (a *App) SetPluginBanner(pluginId string, banner *model.PluginBanner) *model.AppError {
+	key := pluginBannerSystemKey(pluginId)
+
+	if banner == nil {
+		if result := <-a.Srv.Store.System().PermanentDeleteByName(key); result.Err != nil {
+			return result.Err
+		}
+
+		a.updatePluginBanners(pluginId, nil)
+		return nil
+	}
+
+	if err := banner.IsValid(); err != nil {
+		return err
+	}
+
+	banner.SetDefaults()
+
+	value, _ := json.Marshal(&pluginBannerRecord{PluginId: pluginId, Banner: banner})
+	if utf8.RuneCount(value) > PLUGIN_BANNER_MAX_STORED_LENGTH {
+		return model.NewAppError("SetPluginBanner", "app.plugin.banner.too_long.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if result := <-a.Srv.Store.System().SaveOrUpdate(&model.System{Name: key, Value: string(value)}); result.Err != nil {
+		return result.Err
+	}
+
+	a.updatePluginBanners(pluginId, banner)
+	return nil
+}
+
+// clearPluginBanner removes the banner of a plugin that is being deactivated, if it has one.
+func (a *App) clearPluginBanner(pluginId string) {
+	a.pluginBannersLock.RLock()
+	_, ok := a.pluginBanners[pluginId]
+	a.pluginBannersLock.RUnlock()
+
+	if !ok {
+		return
+	}
+
+	if err := a.SetPluginBanner(pluginId, nil); err != nil {
+		mlog.Error("Failed to clear plugin banner", mlog.String("plugin_id", pluginId), mlog.Err(err))
+	}
+}
+
+// loadPluginBanners reads the plugin banners persisted in the system store.
+func (a *App) loadPluginBanners() {
+	result := <-a.Srv.Store.System().Get()
+	if result.Err != nil {
+		mlog.Error("Failed to load plugin banners", mlog.Err(result.Err))
+		return
+	}
+
+	banners := make(map[string]*model.PluginBanner)
+	for name, value := range result.Data.(model.StringMap) {
+		if !strings.HasPrefix(name, PLUGIN_BANNER_SYSTEM_KEY_PREFIX) {
+			continue
+		}
+
+		var record pluginBannerRecord
+		if err := json.Unmarshal([]byte(value), &record); err != nil || record.Banner == nil {
+			mlog.Warn("Ignoring unparseable plugin banner", mlog.String("name", name))
+			continue
+		}
+
+		banners[record.PluginId] = record.Banner
+	}
+
+	a.pluginBannersLock.Lock()
+	a.pluginBanners = banners
+	a.pluginBannersLock.Unlock()
+}
+
+func (a *App) updatePluginBanners(pluginId string, banner *model.PluginBanner) {
+	a.pluginBannersLock.Lock()
+	if a.pluginBanners == nil {
+		a.pluginBanners = make(map[string]*model.PluginBanner)
+	}
+	if banner == nil {
+		delete(a.pluginBanners, pluginId)
+	} else {
+		a.pluginBanners[pluginId] = banner
+	}
+	a.pluginBannersLock.Unlock()
+
+	a.regenerateClientConfig()
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_CONFIG_CHANGED, "", "", "", nil)
+	message.Add("config", a.ClientConfigWithComputed())
+	a.Go(func() {
+		a.Publish(message)
+	})
+}
+
+// getEffectivePluginBanner returns the plugin banner to show, if any. Banners are only shown for
+// plugins enabled in the config, and when several plugins have registered a banner, the one whose
+// plugin id sorts first is shown so that the choice does not depend on activation order.
+func (a *App) getEffectivePluginBanner() *model.PluginBanner {
+	config := a.Config().PluginSettings
+	if !*config.Enable {
+		return nil
+	}
+
+	a.pluginBannersLock.RLock()
+	defer a.pluginBannersLock.RUnlock()
+
+	var pluginIds []string
+	for pluginId := range a.pluginBanners {
+		if state, ok := config.PluginStates[pluginId]; ok && state.Enable {
+			pluginIds = append(pluginIds, pluginId)
+		}
+	}
+
+	if len(pluginIds) == 0 {
+		return nil
+	}
+
+	sort.Strings(pluginIds)
+	return a.pluginBanners[pluginIds[0]]
+}
+
+// applyPluginBanner merges a plugin banner into the given client config. A banner enabled in the
+// announcement settings always takes precedence, so the plugin banner is only applied where no
+// such banner is shown. Since the limited client config never includes the announcement settings,
+// the plugin banner is always shown on the login page.
+func applyPluginBanner(props map[string]string, banner *model.PluginBanner) {
+	if banner == nil || props["EnableBanner"] == "true" {
+		return
+	}
+
+	props["EnableBanner"] = "true"
+	props["BannerText"] = banner.Text
+	props["BannerColor"] = banner.Color
+	props["BannerTextColor"] = banner.TextColor
+	props["AllowBannerDismissal"] = strconv.FormatBool(banner.AllowDismissal)
+}
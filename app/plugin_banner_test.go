@@ -0,0 +1,132 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestApplyPluginBanner(t *testing.T) {
+	banner := &model.PluginBanner{Text: "UNCLASSIFIED//FOUO", Color: "#007a33", TextColor: "#ffffff", AllowDismissal: true}
+
+	t.Run("no banner", func(t *testing.T) {
+		props := map[string]string{"EnableBanner": "false"}
+		applyPluginBanner(props, nil)
+		assert.Equal(t, map[string]string{"EnableBanner": "false"}, props)
+	})
+
+	t.Run("no admin banner", func(t *testing.T) {
+		props := map[string]string{"EnableBanner": "false", "BannerText": ""}
+		applyPluginBanner(props, banner)
+		assert.Equal(t, map[string]string{
+			"EnableBanner":         "true",
+			"BannerText":           "UNCLASSIFIED//FOUO",
+			"BannerColor":          "#007a33",
+			"BannerTextColor":      "#ffffff",
+			"AllowBannerDismissal": "true",
+		}, props)
+	})
+
+	t.Run("limited config", func(t *testing.T) {
+		props := map[string]string{}
+		applyPluginBanner(props, banner)
+		assert.Equal(t, "true", props["EnableBanner"])
+		assert.Equal(t, "UNCLASSIFIED//FOUO", props["BannerText"])
+	})
+
+	t.Run("admin banner takes precedence", func(t *testing.T) {
+		props := map[string]string{"EnableBanner": "true", "BannerText": "admin"}
+		applyPluginBanner(props, banner)
+		assert.Equal(t, map[string]string{"EnableBanner": "true", "BannerText": "admin"}, props)
+	})
+}
+
+func TestSetPluginBanner(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		cfg.PluginSettings.PluginStates["a.plugin"] = &model.PluginState{Enable: true}
+		cfg.PluginSettings.PluginStates["b.plugin"] = &model.PluginState{Enable: true}
+		cfg.PluginSettings.PluginStates["c.plugin"] = &model.PluginState{Enable: false}
+	})
+	defer func() {
+		for _, pluginId := range []string{"a.plugin", "b.plugin", "c.plugin"} {
+			th.App.SetPluginBanner(pluginId, nil)
+		}
+	}()
+
+	t.Run("invalid banner", func(t *testing.T) {
+		err := th.App.SetPluginBanner("a.plugin", &model.PluginBanner{Text: "text", Color: "green"})
+		require.NotNil(t, err)
+		assert.Equal(t, "model.plugin_banner.is_valid.color.app_error", err.Id)
+	})
+
+	t.Run("single plugin", func(t *testing.T) {
+		require.Nil(t, th.App.SetPluginBanner("b.plugin", &model.PluginBanner{Text: "b"}))
+
+		assert.Equal(t, "true", th.App.ClientConfig()["EnableBanner"])
+		assert.Equal(t, "b", th.App.ClientConfig()["BannerText"])
+		assert.Equal(t, model.ANNOUNCEMENT_SETTINGS_DEFAULT_BANNER_COLOR, th.App.ClientConfig()["BannerColor"])
+		assert.Equal(t, "true", th.App.LimitedClientConfig()["EnableBanner"])
+		assert.Equal(t, "b", th.App.LimitedClientConfig()["BannerText"])
+	})
+
+	t.Run("plugin id sorting first takes precedence", func(t *testing.T) {
+		hash := th.App.ClientConfigHash()
+		require.Nil(t, th.App.SetPluginBanner("a.plugin", &model.PluginBanner{Text: "a"}))
+		assert.Equal(t, "a", th.App.ClientConfig()["BannerText"])
+		assert.NotEqual(t, hash, th.App.ClientConfigHash())
+
+		require.Nil(t, th.App.SetPluginBanner("a.plugin", nil))
+		assert.Equal(t, "b", th.App.ClientConfig()["BannerText"])
+	})
+
+	t.Run("disabled plugin is ignored", func(t *testing.T) {
+		require.Nil(t, th.App.SetPluginBanner("b.plugin", nil))
+		require.Nil(t, th.App.SetPluginBanner("c.plugin", &model.PluginBanner{Text: "c"}))
+
+		assert.Equal(t, "false", th.App.ClientConfig()["EnableBanner"])
+		assert.Empty(t, th.App.LimitedClientConfig()["BannerText"])
+
+		require.Nil(t, th.App.SetPluginBanner("c.plugin", nil))
+	})
+
+	t.Run("admin banner takes precedence", func(t *testing.T) {
+		require.Nil(t, th.App.SetPluginBanner("a.plugin", &model.PluginBanner{Text: "a"}))
+
+		th.App.SetLicense(model.NewTestLicense("announcement"))
+		defer th.App.SetLicense(nil)
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.AnnouncementSettings.EnableBanner = true
+			*cfg.AnnouncementSettings.BannerText = "admin"
+		})
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.AnnouncementSettings.EnableBanner = false
+		})
+
+		assert.Equal(t, "admin", th.App.ClientConfig()["BannerText"])
+		assert.Equal(t, "a", th.App.LimitedClientConfig()["BannerText"])
+	})
+
+	t.Run("persisted across restarts", func(t *testing.T) {
+		require.Nil(t, th.App.SetPluginBanner("a.plugin", &model.PluginBanner{Text: "a"}))
+
+		th.App.pluginBanners = nil
+		th.App.loadPluginBanners()
+		th.App.regenerateClientConfig()
+		assert.Equal(t, "a", th.App.ClientConfig()["BannerText"])
+
+		th.App.clearPluginBanner("a.plugin")
+		th.App.pluginBanners = nil
+		th.App.loadPluginBanners()
+		assert.Empty(t, th.App.pluginBanners)
+	})
+}
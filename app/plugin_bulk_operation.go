@@ -0,0 +1,172 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+// PluginBulkOperation collects the users created and the team memberships changed by an operation
+// changing many of them at once, such as a bulk import or an LDAP sync, so that plugins are told of
+// them once it completes rather than as each is made. Its methods may be called concurrently, and do
+// nothing on a nil operation.
+type PluginBulkOperation struct {
+	app    *App
+	opType string
+
+	mutex       sync.Mutex
+	users       []*model.User
+	teamMembers []*model.TeamMember
+}
+
+// NewPluginBulkOperation starts collecting the changes made by an operation of the given type, one of
+// the model.BULK_OPERATION_TYPE_* constants.
+func (a *App) NewPluginBulkOperation(opType string) *PluginBulkOperation {
+	return &PluginBulkOperation{app: a, opType: opType}
+}
+
+// UserCreated records a user created by the operation.
+func (op *PluginBulkOperation) UserCreated(user *model.User) {
+	if op == nil {
+		return
+	}
+
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+	op.users = append(op.users, user)
+}
+
+// TeamMemberChanged records a team membership added or, if its DeleteAt is set, removed by the
+// operation.
+func (op *PluginBulkOperation) TeamMemberChanged(teamMember *model.TeamMember) {
+	if op == nil {
+		return
+	}
+
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+	op.teamMembers = append(op.teamMembers, teamMember)
+}
+
+// Complete tells plugins of the changes made by the operation, in the background. Changes of a kind
+// made no more than PluginSettings.BulkHookThreshold times are reported with the usual hooks, while
+// the others are reported in batches to the plugins implementing the batch hooks, and in paced
+// batches of the usual hooks to the plugins that do not. Each plugin's BulkOperationHasCompleted hook
+// is invoked once it has been told of every change.
+//
+// Each plugin is told of the changes in turn, so that a plugin slow to handle them neither holds up
+// the others nor is sent the next batch before it is done with the previous one.
+func (op *PluginBulkOperation) Complete() {
+	if op == nil {
+		return
+	}
+
+	a := op.app
+	if !a.PluginsReady() {
+		return
+	}
+
+	op.mutex.Lock()
+	users, teamMembers := op.users, op.teamMembers
+	op.users, op.teamMembers = nil, nil
+	op.mutex.Unlock()
+
+	counts := map[string]int{
+		model.BULK_OPERATION_COUNT_USERS_CREATED:        len(users),
+		model.BULK_OPERATION_COUNT_TEAM_MEMBERS_CHANGED: len(teamMembers),
+	}
+
+	pluginContext := newPluginContext()
+	for _, p := range a.Plugins.Active() {
+		pluginId := p.Manifest.Id
+		a.Go(func() {
+			op.deliver(pluginId, pluginContext, users, teamMembers, counts)
+		})
+	}
+}
+
+// deliver tells the plugin of the users and team memberships changed by the operation, and then of
+// its completion.
+func (op *PluginBulkOperation) deliver(pluginId string, c *plugin.Context, users []*model.User, teamMembers []*model.TeamMember, counts map[string]int) {
+	a := op.app
+	settings := a.Config().PluginSettings
+	threshold := *settings.BulkHookThreshold
+
+	hooks, err := a.Plugins.HooksForPlugin(pluginId)
+	if err != nil {
+		mlog.Debug("Unable to tell plugin of bulk operation", mlog.String("plugin_id", pluginId), mlog.String("op_type", op.opType), mlog.Err(err))
+		return
+	}
+
+	if len(users) > threshold && a.Plugins.PluginImplementsHook(pluginId, plugin.UsersHaveBeenCreatedId) {
+		op.runInBatches(pluginId, len(users), 0, func(start, end int) {
+			hooks.UsersHaveBeenCreated(c, users[start:end])
+		})
+	} else if a.Plugins.PluginImplementsHook(pluginId, plugin.UserHasBeenCreatedId) {
+		op.runInBatches(pluginId, len(users), op.pacing(len(users)), func(start, end int) {
+			for _, user := range users[start:end] {
+				hooks.UserHasBeenCreated(c, user)
+			}
+		})
+	}
+
+	if len(teamMembers) > threshold && a.Plugins.PluginImplementsHook(pluginId, plugin.TeamMembersHaveChangedId) {
+		op.runInBatches(pluginId, len(teamMembers), 0, func(start, end int) {
+			hooks.TeamMembersHaveChanged(c, teamMembers[start:end])
+		})
+	} else if a.Plugins.PluginImplementsHook(pluginId, plugin.UserHasJoinedTeamId) || a.Plugins.PluginImplementsHook(pluginId, plugin.UserHasLeftTeamId) {
+		op.runInBatches(pluginId, len(teamMembers), op.pacing(len(teamMembers)), func(start, end int) {
+			for _, teamMember := range teamMembers[start:end] {
+				if teamMember.DeleteAt == 0 {
+					hooks.UserHasJoinedTeam(c, teamMember, nil)
+				} else {
+					hooks.UserHasLeftTeam(c, teamMember, nil)
+				}
+			}
+		})
+	}
+
+	if a.Plugins.IsActive(pluginId) {
+		hooks.BulkOperationHasCompleted(op.opType, counts)
+	}
+}
+
+// pacing returns how long to pause between batches of the usual hooks reporting the given number of
+// changes, which are only paced above PluginSettings.BulkHookThreshold.
+func (op *PluginBulkOperation) pacing(changes int) time.Duration {
+	settings := op.app.Config().PluginSettings
+	if changes <= *settings.BulkHookThreshold {
+		return 0
+	}
+
+	return time.Duration(*settings.BulkHookPacingMilliseconds) * time.Millisecond
+}
+
+// runInBatches invokes f with the bounds of consecutive batches of at most
+// PluginSettings.BulkHookBatchSize of the given number of changes, pausing between batches, until
+// done or the plugin is deactivated.
+func (op *PluginBulkOperation) runInBatches(pluginId string, changes int, pause time.Duration, f func(start, end int)) {
+	batchSize := *op.app.Config().PluginSettings.BulkHookBatchSize
+
+	for start := 0; start < changes; start += batchSize {
+		if start > 0 && pause > 0 {
+			time.Sleep(pause)
+		}
+
+		if !op.app.Plugins.IsActive(pluginId) {
+			return
+		}
+
+		end := start + batchSize
+		if end > changes {
+			end = changes
+		}
+		f(start, end)
+	}
+}
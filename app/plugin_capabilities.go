@@ -0,0 +1,60 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+// pluginHasCapability returns whether the plugin with the given manifest has been granted the
+// capability, either by declaring it or, for plugins that declare no capabilities at all, by
+// PluginSettings.DefaultCapabilities.
+func (a *App) pluginHasCapability(manifest *model.Manifest, capability string) bool {
+	if !manifest.DeclaresCapabilities() {
+		return *a.Config().PluginSettings.DefaultCapabilities == model.PLUGIN_SETTINGS_DEFAULT_CAPABILITIES_ALL
+	}
+
+	return manifest.HasCapability(capability)
+}
+
+// addedPluginCapabilities returns the capabilities granted to the plugin with the given manifest that
+// were not granted to the previous version of the plugin.
+func (a *App) addedPluginCapabilities(previous, manifest *model.Manifest) []string {
+	var added []string
+	for _, capability := range model.PluginCapabilities {
+		if a.pluginHasCapability(manifest, capability) && !a.pluginHasCapability(previous, capability) {
+			added = append(added, capability)
+		}
+	}
+
+	return added
+}
+
+// checkPluginCapabilities checks that the plugin with the given manifest has been granted the
+// capabilities required to call the given method of the plugin API.
+func (a *App) checkPluginCapabilities(manifest *model.Manifest, method string) *model.AppError {
+	capabilities, ok := plugin.APICapabilities[method]
+	if !ok {
+		return model.NewAppError("checkPluginCapabilities", "app.plugin.capability.unknown_method.app_error", map[string]interface{}{"Method": method}, "plugin_id="+manifest.Id, http.StatusForbidden)
+	}
+
+	var missing []string
+	for _, capability := range capabilities {
+		if !a.pluginHasCapability(manifest, capability) {
+			missing = append(missing, capability)
+		}
+	}
+
+	if len(missing) > 0 {
+		mlog.Warn("Plugin called an API method outside of its capabilities", mlog.String("plugin_id", manifest.Id), mlog.String("method", method), mlog.String("missing", strings.Join(missing, ",")))
+		return model.NewAppError("checkPluginCapabilities", "app.plugin.capability.denied.app_error", map[string]interface{}{"Method": method, "Capabilities": strings.Join(missing, ", ")}, "plugin_id="+manifest.Id, http.StatusForbidden)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestValidatePluginBundleCapabilities(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestPluginFiles(t, dir, map[string]string{
+		"plugin.json": `{"id": "testplugin", "capabilities": ["kv", "posts:write", "everything"], "webapp": {"bundle_path": "main.js"}}`,
+		"main.js":     "",
+	})
+
+	var a *App
+	_, findings := a.validatePluginBundle(dir)
+	if assert.Len(t, findings, 1) {
+		assert.Equal(t, "app.plugin.validate.capability.app_error", findings[0].Id)
+	}
+
+	server := &model.ManifestServer{Executable: "testplugin"}
+	warnings := validatePluginBundleWarnings(dir, &model.Manifest{Id: "testplugin", Server: server})
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, "app.plugin.validate.capabilities_undeclared.app_error", warnings[0].Id)
+	}
+
+	assert.Empty(t, validatePluginBundleWarnings(dir, &model.Manifest{Id: "testplugin", Server: server, Capabilities: []string{}}))
+	assert.Empty(t, validatePluginBundleWarnings(dir, &model.Manifest{Id: "testplugin"}))
+}
+
+func TestPluginCapabilities(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	requireDenied := func(t *testing.T, err *model.AppError) {
+		t.Helper()
+
+		require.NotNil(t, err)
+		assert.Equal(t, "app.plugin.capability.denied.app_error", err.Id)
+		assert.Equal(t, http.StatusForbidden, err.StatusCode)
+	}
+
+	t.Run("declared", func(t *testing.T) {
+		api := th.App.NewPluginAPI(&model.Manifest{Id: "testplugin", Capabilities: []string{model.PLUGIN_CAPABILITY_KV}})
+
+		require.Nil(t, api.KVSet("key", []byte("value")))
+		value, err := api.KVGet("key")
+		require.Nil(t, err)
+		assert.Equal(t, []byte("value"), value)
+
+		_, err = api.GetUser(model.NewId())
+		requireDenied(t, err)
+
+		// Methods requiring several capabilities need all of them.
+		requireDenied(t, api.KVSetAndNotify("key", []byte("value"), "event", nil, &model.WebsocketBroadcast{}))
+
+		// Methods returning no error return their zero value.
+		assert.Nil(t, api.GetConfig())
+
+		// Methods requiring no capability are always allowed.
+		assert.Equal(t, *th.App.Config().ServiceSettings.SiteURL, api.GetSiteURL())
+	})
+
+	t.Run("declared none", func(t *testing.T) {
+		api := th.App.NewPluginAPI(&model.Manifest{Id: "testplugin", Capabilities: []string{}})
+
+		requireDenied(t, api.KVSet("key", []byte("value")))
+		assert.Nil(t, api.GetConfig())
+	})
+
+	t.Run("undeclared", func(t *testing.T) {
+		api := th.App.NewPluginAPI(&model.Manifest{Id: "testplugin"})
+
+		require.Nil(t, api.KVSet("key", []byte("value")))
+		assert.NotNil(t, api.GetConfig())
+
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.DefaultCapabilities = model.PLUGIN_SETTINGS_DEFAULT_CAPABILITIES_NONE
+		})
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.DefaultCapabilities = model.PLUGIN_SETTINGS_DEFAULT_CAPABILITIES_ALL
+		})
+
+		requireDenied(t, api.KVSet("key", []byte("value")))
+		assert.Nil(t, api.GetConfig())
+
+		// Plugins declaring their capabilities are unaffected by the default.
+		api = th.App.NewPluginAPI(&model.Manifest{Id: "testplugin", Capabilities: []string{model.PLUGIN_CAPABILITY_KV}})
+		require.Nil(t, api.KVSet("key", []byte("value")))
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	PLUGIN_TEMP_DIR_PREFIX         = "plugintmp"
+	PLUGIN_TEMP_DIR_MIN_AGE        = 1 * time.Hour
+	PLUGIN_BUNDLE_CLEANUP_INTERVAL = 24 * time.Hour
+)
+
+type pluginBundleCleanupStats struct {
+	Removed   int64
+	LastRunAt int64
+}
+
+// CleanupPluginBundles removes directories in the plugin directory that do not contain a parseable
+// manifest, such as bundles left half-copied by a failed install, along with plugin install temporary
+// directories older than PLUGIN_TEMP_DIR_MIN_AGE. It does nothing unless bundle cleanup is enabled,
+// and never runs concurrently with a plugin install or removal.
+//
+// The paths removed are returned.
+func (a *App) CleanupPluginBundles() []string {
+	if a.Plugins == nil || !*a.Config().PluginSettings.Enable || !*a.Config().PluginSettings.EnableBundleCleanup {
+		return nil
+	}
+
+	a.pluginInstallLock.Lock()
+	defer a.pluginInstallLock.Unlock()
+
+	candidates, err := findOrphanedPluginDirectories(*a.Config().PluginSettings.Directory)
+	if err != nil {
+		mlog.Error("Failed to scan plugin directory for orphaned bundles", mlog.Err(err))
+	}
+
+	staleTempDirs, err := findStalePluginTempDirectories(os.TempDir(), time.Now().Add(-PLUGIN_TEMP_DIR_MIN_AGE))
+	if err != nil {
+		mlog.Error("Failed to scan temporary directory for stale plugin installs", mlog.Err(err))
+	}
+	candidates = append(candidates, staleTempDirs...)
+
+	var removed []string
+	for _, path := range candidates {
+		if err := os.RemoveAll(path); err != nil {
+			mlog.Error("Failed to remove orphaned plugin directory", mlog.String("path", path), mlog.Err(err))
+			continue
+		}
+
+		mlog.Info("Removed orphaned plugin directory", mlog.String("path", path))
+		removed = append(removed, path)
+	}
+
+	totalRemoved, _ := a.GetPluginBundleCleanupStats()
+	a.pluginBundleCleanup.Store(&pluginBundleCleanupStats{
+		Removed:   totalRemoved + int64(len(removed)),
+		LastRunAt: model.GetMillis(),
+	})
+
+	if a.Metrics != nil && len(removed) > 0 {
+		a.Metrics.IncrementPluginBundleCleanup(len(removed))
+	}
+
+	return removed
+}
+
+// GetPluginBundleCleanupStats returns the number of directories removed by CleanupPluginBundles since
+// the server started, and when it last ran.
+func (a *App) GetPluginBundleCleanupStats() (removed int64, lastRunAt int64) {
+	if stats, ok := a.pluginBundleCleanup.Load().(*pluginBundleCleanupStats); ok {
+		return stats.Removed, stats.LastRunAt
+	}
+
+	return 0, 0
+}
+
+// findOrphanedPluginDirectories returns the subdirectories of the plugin directory without a parseable
+// manifest. As when scanning for plugins, paths beginning with a dot are ignored.
+func findOrphanedPluginDirectories(pluginDir string) ([]string, error) {
+	files, err := ioutil.ReadDir(pluginDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var orphaned []string
+	for _, file := range files {
+		if !file.IsDir() || file.Name()[0] == '.' {
+			continue
+		}
+
+		path := filepath.Join(pluginDir, file.Name())
+		if info := model.BundleInfoForPath(path); info.Manifest == nil {
+			orphaned = append(orphaned, path)
+		}
+	}
+
+	return orphaned, nil
+}
+
+// findStalePluginTempDirectories returns the plugin install temporary directories within tmpDir that
+// were last modified before the given time.
+func findStalePluginTempDirectories(tmpDir string, before time.Time) ([]string, error) {
+	files, err := ioutil.ReadDir(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []string
+	for _, file := range files {
+		if file.IsDir() && strings.HasPrefix(file.Name(), PLUGIN_TEMP_DIR_PREFIX) && file.ModTime().Before(before) {
+			stale = append(stale, filepath.Join(tmpDir, file.Name()))
+		}
+	}
+
+	return stale, nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestFindOrphanedPluginDirectories(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "valid"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "valid", "plugin.json"), []byte(`{"id": "valid"}`), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "unparseable"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "unparseable", "plugin.json"), []byte(`{"id": `), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "partial", "server"), 0700))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".hidden"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "file"), []byte("file"), 0600))
+
+	orphaned, err := findOrphanedPluginDirectories(dir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{filepath.Join(dir, "unparseable"), filepath.Join(dir, "partial")}, orphaned)
+}
+
+func TestFindStalePluginTempDirectories(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	old := time.Now().Add(-2 * PLUGIN_TEMP_DIR_MIN_AGE)
+	for _, name := range []string{PLUGIN_TEMP_DIR_PREFIX + "stale", PLUGIN_TEMP_DIR_PREFIX + "fresh", "otherstale"} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, name), 0700))
+	}
+	require.NoError(t, os.Chtimes(filepath.Join(dir, PLUGIN_TEMP_DIR_PREFIX+"stale"), old, old))
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "otherstale"), old, old))
+
+	stale, err := findStalePluginTempDirectories(dir, time.Now().Add(-PLUGIN_TEMP_DIR_MIN_AGE))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, PLUGIN_TEMP_DIR_PREFIX+"stale")}, stale)
+}
+
+func TestCleanupPluginBundles(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "valid"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "valid", "plugin.json"), []byte(`{"id": "valid"}`), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "partial"), 0700))
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Directory = dir
+		*cfg.PluginSettings.EnableBundleCleanup = false
+	})
+
+	assert.Empty(t, th.App.CleanupPluginBundles())
+	_, err = os.Stat(filepath.Join(dir, "partial"))
+	assert.NoError(t, err)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.EnableBundleCleanup = true
+	})
+
+	assert.Contains(t, th.App.CleanupPluginBundles(), filepath.Join(dir, "partial"))
+	_, err = os.Stat(filepath.Join(dir, "partial"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "valid"))
+	assert.NoError(t, err)
+
+	removed, lastRunAt := th.App.GetPluginBundleCleanupStats()
+	assert.True(t, removed >= 1)
+	assert.NotZero(t, lastRunAt)
+}
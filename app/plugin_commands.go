@@ -7,11 +7,18 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
+	goi18n "github.com/nicksnyder/go-i18n/i18n"
+
+	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/plugin"
 )
 
+// PLUGIN_COMMAND_TIMEOUT is how long a plugin may take to respond to one of its commands.
+const PLUGIN_COMMAND_TIMEOUT = 30 * time.Second
+
 type PluginCommand struct {
 	Command  *model.Command
 	PluginId string
@@ -84,30 +91,144 @@ func (a *App) PluginCommandsForTeam(teamId string) []*model.Command {
 
 	var commands []*model.Command
 	for _, pc := range a.pluginCommands {
-		if pc.Command.TeamId == "" || pc.Command.TeamId == teamId {
+		if (pc.Command.TeamId == "" || pc.Command.TeamId == teamId) && a.IsPluginAllowedOnTeam(pc.PluginId, teamId) {
+			commands = append(commands, pc.Command)
+		}
+	}
+	return commands
+}
+
+func (a *App) pluginCommandsForPlugin(pluginId string) []*model.Command {
+	a.pluginCommandsLock.RLock()
+	defer a.pluginCommandsLock.RUnlock()
+
+	commands := []*model.Command{}
+	for _, pc := range a.pluginCommands {
+		if pc.PluginId == pluginId {
 			commands = append(commands, pc.Command)
 		}
 	}
 	return commands
 }
 
+// ExecutePluginCommand runs the plugin command matching the given arguments, if any. Should the
+// plugin providing it be unavailable, take longer than PLUGIN_COMMAND_TIMEOUT or crash, the command
+// is answered with an ephemeral message explaining why.
 func (a *App) ExecutePluginCommand(args *model.CommandArgs) (*model.Command, *model.CommandResponse, *model.AppError) {
 	parts := strings.Split(args.Command, " ")
 	trigger := parts[0][1:]
 	trigger = strings.ToLower(trigger)
 
+	pc := a.findPluginCommand(args.TeamId, trigger)
+	if pc == nil {
+		return nil, nil, nil
+	}
+
+	var response *model.CommandResponse
+	var appErr *model.AppError
+	if a.Plugins == nil || !a.Plugins.IsActive(pc.PluginId) {
+		appErr = model.NewAppError("ExecutePluginCommand", "app.plugin.command.disabled.app_error", nil, "plugin_id="+pc.PluginId, http.StatusInternalServerError)
+	} else if pluginHooks, err := a.Plugins.HooksForPlugin(pc.PluginId); err != nil {
+		if activationErr := a.Plugins.ActivationError(pc.PluginId); activationErr != nil {
+			err = activationErr
+		}
+		appErr = model.NewAppError("ExecutePluginCommand", "app.plugin.command.failed_to_start.app_error", nil, "plugin_id="+pc.PluginId+", err="+err.Error(), http.StatusInternalServerError)
+	} else {
+		response, appErr = executePluginCommandHook(pluginHooks, args, PLUGIN_COMMAND_TIMEOUT)
+	}
+
+	if appErr != nil && isPluginCommandFailure(appErr) {
+		mlog.Error("Plugin command failed", mlog.String("plugin_id", pc.PluginId), mlog.String("trigger", trigger), mlog.Err(appErr))
+		showErrorId := a.SessionHasPermissionTo(args.Session, model.PERMISSION_MANAGE_SYSTEM)
+		return pc.Command, pluginCommandFailureResponse(args.T, trigger, a.getPluginName(pc.PluginId), appErr, showErrorId), nil
+	}
+
+	return pc.Command, response, appErr
+}
+
+func (a *App) findPluginCommand(teamId, trigger string) *PluginCommand {
 	a.pluginCommandsLock.RLock()
 	defer a.pluginCommandsLock.RUnlock()
 
 	for _, pc := range a.pluginCommands {
-		if (pc.Command.TeamId == "" || pc.Command.TeamId == args.TeamId) && pc.Command.Trigger == trigger {
-			pluginHooks, err := a.Plugins.HooksForPlugin(pc.PluginId)
-			if err != nil {
-				return pc.Command, nil, model.NewAppError("ExecutePluginCommand", "model.plugin_command.error.app_error", nil, "err="+err.Error(), http.StatusInternalServerError)
+		if (pc.Command.TeamId == "" || pc.Command.TeamId == teamId) && pc.Command.Trigger == trigger && a.IsPluginAllowedOnTeam(pc.PluginId, teamId) {
+			return pc
+		}
+	}
+
+	return nil
+}
+
+// getPluginName returns the name of the installed plugin with the given id, or its id if the plugin
+// has no name or cannot be found.
+func (a *App) getPluginName(pluginId string) string {
+	if a.Plugins == nil {
+		return pluginId
+	}
+
+	if plugins, err := a.Plugins.Available(); err == nil {
+		for _, plugin := range plugins {
+			if plugin.Manifest.Id == pluginId && plugin.Manifest.Name != "" {
+				return plugin.Manifest.Name
 			}
-			response, appErr := pluginHooks.ExecuteCommand(&plugin.Context{}, args)
-			return pc.Command, response, appErr
 		}
 	}
-	return nil, nil, nil
+
+	return pluginId
+}
+
+// executePluginCommandHook runs the ExecuteCommand hook, returning an error should the plugin not
+// respond within the given timeout or fail to respond at all, which happens when its process
+// crashes, since failed RPC calls leave the hook's return values empty.
+func executePluginCommandHook(hooks plugin.Hooks, args *model.CommandArgs, timeout time.Duration) (*model.CommandResponse, *model.AppError) {
+	type hookResult struct {
+		response *model.CommandResponse
+		appErr   *model.AppError
+	}
+
+	done := make(chan hookResult, 1)
+	go func() {
+		response, appErr := hooks.ExecuteCommand(newPluginContext(), args)
+		done <- hookResult{response, appErr}
+	}()
+
+	select {
+	case result := <-done:
+		if result.response == nil && result.appErr == nil {
+			return nil, model.NewAppError("ExecutePluginCommand", "app.plugin.command.crashed.app_error", nil, "", http.StatusInternalServerError)
+		}
+		return result.response, result.appErr
+	case <-time.After(timeout):
+		return nil, model.NewAppError("ExecutePluginCommand", "app.plugin.command.timeout.app_error", nil, "timeout="+timeout.String(), http.StatusGatewayTimeout)
+	}
+}
+
+var pluginCommandFailureMessages = map[string]string{
+	"app.plugin.command.disabled.app_error":        "app.plugin.command.disabled.message",
+	"app.plugin.command.failed_to_start.app_error": "app.plugin.command.failed_to_start.message",
+	"app.plugin.command.timeout.app_error":         "app.plugin.command.timeout.message",
+	"app.plugin.command.crashed.app_error":         "app.plugin.command.crashed.message",
+}
+
+func isPluginCommandFailure(appErr *model.AppError) bool {
+	_, ok := pluginCommandFailureMessages[appErr.Id]
+	return ok
+}
+
+// pluginCommandFailureResponse returns the ephemeral message shown in place of the response to a
+// plugin command that failed, which names the id of the error when showErrorId is set.
+func pluginCommandFailureResponse(T goi18n.TranslateFunc, trigger, pluginName string, appErr *model.AppError, showErrorId bool) *model.CommandResponse {
+	text := T(pluginCommandFailureMessages[appErr.Id], map[string]interface{}{
+		"Trigger":    trigger,
+		"PluginName": pluginName,
+	})
+
+	if showErrorId {
+		text += " " + T("app.plugin.command.error_id", map[string]interface{}{"ErrorId": appErr.Id})
+	}
+
+	return &model.CommandResponse{
+		ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL,
+		Text:         text,
+	}
 }
@@ -0,0 +1,153 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/mattermost/mattermost-server/utils"
+)
+
+func TestExecutePluginCommandHook(t *testing.T) {
+	args := &model.CommandArgs{Command: "/test"}
+
+	t.Run("success", func(t *testing.T) {
+		hooks := &plugintest.Hooks{}
+		hooks.On("ExecuteCommand", mock.Anything, args).Return(&model.CommandResponse{Text: "ok"}, nil)
+
+		response, err := executePluginCommandHook(hooks, args, time.Second)
+		require.Nil(t, err)
+		assert.Equal(t, "ok", response.Text)
+	})
+
+	t.Run("plugin error", func(t *testing.T) {
+		hooks := &plugintest.Hooks{}
+		hooks.On("ExecuteCommand", mock.Anything, args).Return(nil, model.NewAppError("test", "plugin.error", nil, "", http.StatusBadRequest))
+
+		_, err := executePluginCommandHook(hooks, args, time.Second)
+		require.NotNil(t, err)
+		assert.Equal(t, "plugin.error", err.Id)
+		assert.False(t, isPluginCommandFailure(err))
+	})
+
+	t.Run("crashed", func(t *testing.T) {
+		hooks := &plugintest.Hooks{}
+		hooks.On("ExecuteCommand", mock.Anything, args).Return(nil, nil)
+
+		_, err := executePluginCommandHook(hooks, args, time.Second)
+		require.NotNil(t, err)
+		assert.Equal(t, "app.plugin.command.crashed.app_error", err.Id)
+		assert.True(t, isPluginCommandFailure(err))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		hooks := &plugintest.Hooks{}
+		hooks.On("ExecuteCommand", mock.Anything, args).Return(&model.CommandResponse{Text: "late"}, nil).After(time.Second)
+
+		_, err := executePluginCommandHook(hooks, args, 10*time.Millisecond)
+		require.NotNil(t, err)
+		assert.Equal(t, "app.plugin.command.timeout.app_error", err.Id)
+		assert.Equal(t, http.StatusGatewayTimeout, err.StatusCode)
+		assert.True(t, isPluginCommandFailure(err))
+	})
+}
+
+func TestPluginCommandFailureResponse(t *testing.T) {
+	utils.TranslationsPreInit()
+
+	appErr := model.NewAppError("ExecutePluginCommand", "app.plugin.command.disabled.app_error", nil, "", http.StatusInternalServerError)
+
+	response := pluginCommandFailureResponse(utils.T, "jira", "Jira", appErr, false)
+	assert.Equal(t, model.COMMAND_RESPONSE_TYPE_EPHEMERAL, response.ResponseType)
+	assert.Contains(t, response.Text, "/jira")
+	assert.Contains(t, response.Text, "Jira plugin")
+	assert.Contains(t, response.Text, "disabled")
+	assert.NotContains(t, response.Text, appErr.Id)
+
+	response = pluginCommandFailureResponse(utils.T, "jira", "Jira", appErr, true)
+	assert.Contains(t, response.Text, appErr.Id)
+}
+
+func TestExecutePluginCommandFailures(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	args := &model.CommandArgs{
+		TeamId:  th.BasicTeam.Id,
+		UserId:  th.BasicUser.Id,
+		T:       utils.T,
+		Session: model.Session{UserId: th.BasicUser.Id, Roles: model.SYSTEM_USER_ROLE_ID},
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		require.Nil(t, th.App.RegisterPluginCommand("disabledplugin", &model.Command{Trigger: "disabled"}))
+		defer th.App.UnregisterPluginCommands("disabledplugin")
+
+		args.Command = "/disabled"
+		cmd, response, err := th.App.ExecutePluginCommand(args)
+		require.Nil(t, err)
+		require.NotNil(t, cmd)
+		assert.Equal(t, model.COMMAND_RESPONSE_TYPE_EPHEMERAL, response.ResponseType)
+		assert.Contains(t, response.Text, "disabledplugin plugin")
+		assert.NotContains(t, response.Text, "app.plugin.command.disabled.app_error")
+
+		args.Session.Roles = model.SYSTEM_USER_ROLE_ID + " " + model.SYSTEM_ADMIN_ROLE_ID
+		defer func() { args.Session.Roles = model.SYSTEM_USER_ROLE_ID }()
+
+		_, response, err = th.App.ExecutePluginCommand(args)
+		require.Nil(t, err)
+		assert.Contains(t, response.Text, "app.plugin.command.disabled.app_error")
+	})
+
+	t.Run("crashed", func(t *testing.T) {
+		SetAppEnvironmentWithPlugins(t,
+			[]string{
+				`
+			package main
+
+			import (
+				"os"
+
+				"github.com/mattermost/mattermost-server/plugin"
+				"github.com/mattermost/mattermost-server/model"
+			)
+
+			type MyPlugin struct {
+				plugin.MattermostPlugin
+			}
+
+			func (p *MyPlugin) ExecuteCommand(c *plugin.Context, args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
+				os.Exit(1)
+				return nil, nil
+			}
+
+			func main() {
+				plugin.ClientMain(&MyPlugin{})
+			}
+			`,
+			}, th.App, th.App.NewPluginAPI)
+
+		active := th.App.Plugins.Active()
+		require.Len(t, active, 1)
+		pluginId := active[0].Manifest.Id
+
+		require.Nil(t, th.App.RegisterPluginCommand(pluginId, &model.Command{Trigger: "crashing"}))
+		defer th.App.UnregisterPluginCommands(pluginId)
+
+		args.Command = "/crashing"
+		cmd, response, err := th.App.ExecutePluginCommand(args)
+		require.Nil(t, err)
+		require.NotNil(t, cmd)
+		assert.Equal(t, model.COMMAND_RESPONSE_TYPE_EPHEMERAL, response.ResponseType)
+		assert.Contains(t, response.Text, "stopped unexpectedly")
+	})
+}
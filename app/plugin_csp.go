@@ -0,0 +1,92 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// WEBAPP_CONTENT_SECURITY_POLICY is the Content-Security-Policy set on the responses serving the
+// webapp, before any external origins declared by plugins are added.
+const WEBAPP_CONTENT_SECURITY_POLICY = "frame-ancestors 'self'"
+
+// WebappContentSecurityPolicy returns the Content-Security-Policy to set on the responses serving the
+// webapp. If PluginSettings.AllowPluginCSPExtensions is enabled, it allows the external origins
+// declared in the manifests of the plugins that are currently active.
+func (a *App) WebappContentSecurityPolicy() string {
+	if !*a.Config().PluginSettings.AllowPluginCSPExtensions || !a.PluginsReady() {
+		return WEBAPP_CONTENT_SECURITY_POLICY
+	}
+
+	var manifests []*model.Manifest
+	for _, info := range a.Plugins.Active() {
+		if info.Manifest != nil && info.Manifest.HasWebapp() {
+			manifests = append(manifests, info.Manifest)
+		}
+	}
+
+	return mergePluginContentSecurityPolicy(WEBAPP_CONTENT_SECURITY_POLICY, manifests)
+}
+
+// mergePluginContentSecurityPolicy adds a directive to the policy for each directive for which the
+// webapps of the given plugins declare external origins, allowing those origins along with 'self'.
+// Invalid directives and origins, which are rejected at install, are ignored.
+func mergePluginContentSecurityPolicy(policy string, manifests []*model.Manifest) string {
+	sources := make(map[string]map[string]bool)
+	for _, manifest := range manifests {
+		for directive, directiveSources := range manifest.Webapp.ContentSecurityPolicy {
+			if !model.IsValidPluginCSPDirective(directive) {
+				continue
+			}
+
+			for _, source := range directiveSources {
+				if !model.IsValidPluginCSPSource(source) {
+					continue
+				}
+
+				if sources[directive] == nil {
+					sources[directive] = make(map[string]bool)
+				}
+				sources[directive][source] = true
+			}
+		}
+	}
+
+	directives := make([]string, 0, len(sources))
+	for directive := range sources {
+		directives = append(directives, directive)
+	}
+	sort.Strings(directives)
+
+	for _, directive := range directives {
+		allowed := make([]string, 0, len(sources[directive]))
+		for source := range sources[directive] {
+			allowed = append(allowed, source)
+		}
+		sort.Strings(allowed)
+
+		policy += "; " + directive + " 'self' " + strings.Join(allowed, " ")
+	}
+
+	return policy
+}
+
+// formatPluginContentSecurityPolicy describes the external origins declared by the plugin's webapp
+// for administrators, such as "frame-src https://www.youtube.com; img-src https://maps.example.com".
+func formatPluginContentSecurityPolicy(manifest *model.Manifest) string {
+	if !manifest.HasWebapp() {
+		return ""
+	}
+
+	directives := make([]string, 0, len(manifest.Webapp.ContentSecurityPolicy))
+	for directive, sources := range manifest.Webapp.ContentSecurityPolicy {
+		directives = append(directives, directive+" "+strings.Join(sources, " "))
+	}
+	sort.Strings(directives)
+
+	return strings.Join(directives, "; ")
+}
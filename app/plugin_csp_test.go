@@ -0,0 +1,116 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+func TestValidatePluginBundleContentSecurityPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestPluginFiles(t, dir, map[string]string{
+		"plugin.json": `{"id": "testplugin", "capabilities": [], "webapp": {"bundle_path": "webapp/main.js", "content_security_policy": {
+			"frame-src": ["https://www.youtube.com", "https://*.vimeo.com"],
+			"img-src": ["https://tiles.example.com", "'unsafe-inline'"],
+			"default-src": ["*"]
+		}}}`,
+		"webapp/main.js": "",
+	})
+
+	var a *App
+	_, findings := a.validatePluginBundle(dir)
+	if assert.Len(t, findings, 2) {
+		assert.Equal(t, "app.plugin.validate.csp_directive.app_error", findings[0].Id)
+		assert.Equal(t, "app.plugin.validate.csp_source.app_error", findings[1].Id)
+	}
+}
+
+func TestMergePluginContentSecurityPolicy(t *testing.T) {
+	maps := &model.Manifest{Id: "maps", Webapp: &model.ManifestWebapp{ContentSecurityPolicy: map[string][]string{
+		"img-src":     {"https://tiles.example.com"},
+		"connect-src": {"https://api.example.com"},
+	}}}
+	video := &model.Manifest{Id: "video", Webapp: &model.ManifestWebapp{ContentSecurityPolicy: map[string][]string{
+		"frame-src": {"https://www.youtube.com"},
+		"img-src":   {"https://i.ytimg.com", "https://tiles.example.com"},
+	}}}
+	invalid := &model.Manifest{Id: "invalid", Webapp: &model.ManifestWebapp{ContentSecurityPolicy: map[string][]string{
+		"frame-ancestors": {"https://evil.example.com"},
+		"script-src":      {"'unsafe-eval'", "https://cdn.example.com; default-src *"},
+	}}}
+	plain := &model.Manifest{Id: "plain", Webapp: &model.ManifestWebapp{}}
+
+	for name, tc := range map[string]struct {
+		Manifests []*model.Manifest
+		Expected  string
+	}{
+		"no plugins":             {nil, WEBAPP_CONTENT_SECURITY_POLICY},
+		"no declared origins":    {[]*model.Manifest{plain}, WEBAPP_CONTENT_SECURITY_POLICY},
+		"one plugin":             {[]*model.Manifest{maps}, WEBAPP_CONTENT_SECURITY_POLICY + "; connect-src 'self' https://api.example.com; img-src 'self' https://tiles.example.com"},
+		"overlapping plugins":    {[]*model.Manifest{maps, video, plain}, WEBAPP_CONTENT_SECURITY_POLICY + "; connect-src 'self' https://api.example.com; frame-src 'self' https://www.youtube.com; img-src 'self' https://i.ytimg.com https://tiles.example.com"},
+		"invalid origins":        {[]*model.Manifest{invalid}, WEBAPP_CONTENT_SECURITY_POLICY},
+		"invalid and valid ones": {[]*model.Manifest{invalid, video}, WEBAPP_CONTENT_SECURITY_POLICY + "; frame-src 'self' https://www.youtube.com; img-src 'self' https://i.ytimg.com https://tiles.example.com"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, mergePluginContentSecurityPolicy(WEBAPP_CONTENT_SECURITY_POLICY, tc.Manifests))
+		})
+	}
+}
+
+func TestWebappContentSecurityPolicy(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginDir)
+	webappPluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(webappPluginDir)
+
+	writeTestPluginFiles(t, filepath.Join(pluginDir, "maps"), map[string]string{
+		"plugin.json": `{"id": "maps", "webapp": {"bundle_path": "main.js", "content_security_policy": {"img-src": ["https://tiles.example.com"]}}}`,
+		"main.js":     "",
+	})
+	writeTestPluginFiles(t, filepath.Join(pluginDir, "video"), map[string]string{
+		"plugin.json": `{"id": "video", "webapp": {"bundle_path": "main.js", "content_security_policy": {"frame-src": ["https://www.youtube.com"]}}}`,
+		"main.js":     "",
+	})
+
+	env, err := plugin.NewEnvironment(th.App.NewPluginAPI, pluginDir, webappPluginDir, th.App.Log)
+	require.NoError(t, err)
+	th.App.Plugins = env
+
+	for _, id := range []string{"maps", "video"} {
+		_, activated, err := env.Activate(id)
+		require.NoError(t, err)
+		require.True(t, activated)
+	}
+
+	// Plugins may not extend the policy unless allowed to.
+	assert.Equal(t, WEBAPP_CONTENT_SECURITY_POLICY, th.App.WebappContentSecurityPolicy())
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.AllowPluginCSPExtensions = true })
+
+	assert.Equal(t, WEBAPP_CONTENT_SECURITY_POLICY+"; frame-src 'self' https://www.youtube.com; img-src 'self' https://tiles.example.com", th.App.WebappContentSecurityPolicy())
+
+	// Deactivating a plugin removes its origins right away.
+	env.Deactivate("video")
+	assert.Equal(t, WEBAPP_CONTENT_SECURITY_POLICY+"; img-src 'self' https://tiles.example.com", th.App.WebappContentSecurityPolicy())
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = false })
+	assert.Equal(t, WEBAPP_CONTENT_SECURITY_POLICY, th.App.WebappContentSecurityPolicy())
+}
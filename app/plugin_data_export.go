@@ -0,0 +1,172 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+const PLUGIN_DATA_EXPORT_BATCH_SIZE = 100
+
+// BulkExportPluginData writes the key-value data of all plugins to writer in the bulk import
+// format, so that it can be restored on another server with BulkImport.
+//
+// Each key-value pair is written on its own "plugin_kv" line, so that no line holds more than one
+// value.
+// Expired pairs, and those stored before raw keys were recorded, are not exported.
+func (a *App) BulkExportPluginData(writer io.Writer) *model.AppError {
+	encoder := json.NewEncoder(writer)
+
+	if err := encoder.Encode(&LineImportData{Type: "version", Version: model.NewInt(1)}); err != nil {
+		return model.NewAppError("BulkExportPluginData", "app.plugin.export_data.write.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	for offset := 0; ; offset += PLUGIN_DATA_EXPORT_BATCH_SIZE {
+		kvs, appErr := a.Srv.Store.Plugin().GetAll(offset, PLUGIN_DATA_EXPORT_BATCH_SIZE)
+		if appErr != nil {
+			return appErr
+		}
+
+		for _, kv := range kvs {
+			value, err := a.decodeStoredPluginKeyValue(kv.Value)
+			if err != nil {
+				return err
+			}
+			line := &LineImportData{
+				Type: "plugin_kv",
+				PluginKV: &PluginKVImportData{
+					PluginId: &kv.PluginId,
+					Key:      &kv.RawKey,
+					Value:    &value,
+					ExpireAt: &kv.ExpireAt,
+				},
+			}
+
+			if err := encoder.Encode(line); err != nil {
+				return model.NewAppError("BulkExportPluginData", "app.plugin.export_data.write.app_error", nil, err.Error(), http.StatusInternalServerError)
+			}
+		}
+
+		if len(kvs) < PLUGIN_DATA_EXPORT_BATCH_SIZE {
+			return nil
+		}
+	}
+}
+
+// ExportPluginData returns the key-value data of the given plugin, with each pair written as a line of
+// JSON, so that it can be restored on another server with ImportPluginDataFromReader. As with bulk
+// exports, expired pairs and those stored before raw keys were recorded are not exported.
+//
+// The data is read from the store as the returned reader is consumed, and the reader must be closed
+// once done with, even if not consumed.
+func (a *App) ExportPluginData(pluginId string) (io.ReadCloser, *model.AppError) {
+	if !plugin.IsValidId(pluginId) {
+		return nil, model.NewAppError("ExportPluginData", "app.plugin.export_data.plugin_id.app_error", nil, "plugin_id="+pluginId, http.StatusBadRequest)
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(a.writePluginData(pluginId, writer))
+	}()
+
+	return reader, nil
+}
+
+func (a *App) writePluginData(pluginId string, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+
+	for offset := 0; ; offset += PLUGIN_DATA_EXPORT_BATCH_SIZE {
+		kvs, appErr := a.Srv.Store.Plugin().GetAllForPlugin(pluginId, offset, PLUGIN_DATA_EXPORT_BATCH_SIZE)
+		if appErr != nil {
+			mlog.Error("Failed to export plugin data", mlog.String("plugin_id", pluginId), mlog.Err(appErr))
+			return appErr
+		}
+
+		for _, kv := range kvs {
+			value, appErr := a.decodeStoredPluginKeyValue(kv.Value)
+			if appErr != nil {
+				mlog.Error("Failed to export plugin data", mlog.String("plugin_id", pluginId), mlog.Err(appErr))
+				return appErr
+			}
+
+			if err := encoder.Encode(&model.PluginDataEntry{
+				Key:      kv.RawKey,
+				Value:    value,
+				ExpireAt: kv.ExpireAt,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if len(kvs) < PLUGIN_DATA_EXPORT_BATCH_SIZE {
+			return nil
+		}
+	}
+}
+
+// ImportPluginDataFromReader stores the key-value pairs read from reader, in the format written by
+// ExportPluginData, for the given plugin. Existing keys are overwritten.
+//
+// Each line is validated and stored on its own, so that a line that cannot be imported is reported
+// in the result without preventing the others from being imported. Pairs that have since expired
+// are skipped.
+func (a *App) ImportPluginDataFromReader(pluginId string, reader io.Reader) (*model.PluginDataImportResult, *model.AppError) {
+	if !plugin.IsValidId(pluginId) {
+		return nil, model.NewAppError("ImportPluginDataFromReader", "app.plugin.import_data.plugin_id.app_error", nil, "plugin_id="+pluginId, http.StatusBadRequest)
+	}
+
+	result := &model.PluginDataImportResult{Errors: []*model.PluginDataImportRowError{}}
+	lines := bufio.NewReader(reader)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := lines.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return result, model.NewAppError("ImportPluginDataFromReader", "app.plugin.import_data.read.app_error", nil, err.Error(), http.StatusBadRequest)
+		}
+
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if rowErr := a.importPluginDataLine(pluginId, line); rowErr != nil {
+				rowErr.Line = lineNumber
+				result.Errors = append(result.Errors, rowErr)
+			} else {
+				result.Imported++
+			}
+		}
+
+		if err == io.EOF {
+			return result, nil
+		}
+	}
+}
+
+func (a *App) importPluginDataLine(pluginId string, line []byte) *model.PluginDataImportRowError {
+	var entry model.PluginDataEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return &model.PluginDataImportRowError{
+			Error: model.NewAppError("ImportPluginDataFromReader", "app.plugin.import_data.decode.app_error", nil, err.Error(), http.StatusBadRequest),
+		}
+	}
+
+	// Lines are imported as bulk import plugin_data lines would be, so that they are validated alike.
+	data := &PluginDataImportData{
+		PluginId: &pluginId,
+		KeyValues: &[]PluginKeyValueImportData{{
+			Key:      &entry.Key,
+			Value:    &entry.Value,
+			ExpireAt: &entry.ExpireAt,
+		}},
+	}
+	if err := a.ImportPluginData(data, false); err != nil {
+		return &model.PluginDataImportRowError{Key: entry.Key, Error: err}
+	}
+
+	return nil
+}
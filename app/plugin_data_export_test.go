@@ -0,0 +1,189 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestBulkExportPluginDataRoundTrip(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginIds := []string{"com.example." + model.NewId(), "com.example." + model.NewId()}
+	defer func() {
+		for _, pluginId := range pluginIds {
+			th.App.Srv.Store.Plugin().DeleteAllForPlugin(pluginId)
+		}
+	}()
+
+	expected := map[string]map[string][]byte{
+		pluginIds[0]: {
+			"key":         []byte("value"),
+			"compressed":  bytes.Repeat([]byte("compressible "), 1000),
+			"binary":      {0, 1, 2, 255},
+			"json/nested": []byte(`{"a": [1, 2, 3]}`),
+		},
+		pluginIds[1]: {
+			"key": []byte("other value"),
+		},
+	}
+	for pluginId, kvs := range expected {
+		require.Nil(t, th.App.SetPluginKeys(pluginId, kvs))
+	}
+	require.Nil(t, th.App.SetPluginKeyWithExpiry(pluginIds[1], "expiring", []byte("soon"), 60))
+	expected[pluginIds[1]]["expiring"] = []byte("soon")
+
+	var exported bytes.Buffer
+	require.Nil(t, th.App.BulkExportPluginData(&exported))
+
+	// Only the data of these plugins is imported back, since other tests may have stored their own.
+	var data []string
+	for i, line := range strings.Split(strings.TrimSpace(exported.String()), "\n") {
+		var decoded LineImportData
+		require.NoError(t, json.Unmarshal([]byte(line), &decoded))
+
+		if i == 0 {
+			assert.Equal(t, "version", decoded.Type)
+			data = append(data, line)
+		} else if *decoded.PluginKV.PluginId == pluginIds[0] || *decoded.PluginKV.PluginId == pluginIds[1] {
+			assert.Equal(t, "plugin_kv", decoded.Type)
+			if *decoded.PluginKV.Key == "binary" {
+				assert.Contains(t, line, `"value":"`+base64.StdEncoding.EncodeToString([]byte{0, 1, 2, 255})+`"`)
+			}
+			data = append(data, line)
+		}
+	}
+	assert.Len(t, data, 7)
+
+	for _, pluginId := range pluginIds {
+		_, appErr := th.App.Srv.Store.Plugin().DeleteAllForPlugin(pluginId)
+		require.Nil(t, appErr)
+	}
+
+	// Plugin data is left out when asked to skip it.
+	err, line := th.App.BulkImport(strings.NewReader(strings.Join(data, "\n")), false, 2, true)
+	require.Nil(t, err, "failed on line %v", line)
+	for _, pluginId := range pluginIds {
+		keys, err := th.App.ListPluginKeys(pluginId, 0, 100)
+		require.Nil(t, err)
+		assert.Empty(t, keys)
+	}
+
+	err, line = th.App.BulkImport(strings.NewReader(strings.Join(data, "\n")), false, 2, false)
+	require.Nil(t, err, "failed on line %v", line)
+
+	for pluginId, kvs := range expected {
+		keys, err := th.App.ListPluginKeys(pluginId, 0, 100)
+		require.Nil(t, err)
+		assert.Len(t, keys, len(kvs))
+
+		for key, value := range kvs {
+			ret, err := th.App.GetPluginKey(pluginId, key)
+			require.Nil(t, err)
+			assert.Equal(t, value, ret, key)
+		}
+	}
+
+	kv, err := th.App.Srv.Store.Plugin().Get(pluginIds[1], "expiring")
+	require.Nil(t, err)
+	assert.True(t, kv.ExpireAt > model.GetMillis(), "the key should still expire")
+}
+
+func TestExportImportPluginData(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "com.example." + model.NewId()
+	otherPluginId := "com.example." + model.NewId()
+	defer func() {
+		th.App.Srv.Store.Plugin().DeleteAllForPlugin(pluginId)
+		th.App.Srv.Store.Plugin().DeleteAllForPlugin(otherPluginId)
+	}()
+
+	expected := map[string][]byte{
+		"key":        []byte("value"),
+		"compressed": bytes.Repeat([]byte("compressible "), 1000),
+		"binary":     {0, 1, 2, 255},
+		"empty":      {},
+	}
+	for key, value := range expected {
+		require.Nil(t, th.App.SetPluginKey(pluginId, key, value))
+	}
+	require.Nil(t, th.App.SetPluginKeyWithExpiry(pluginId, "expiring", []byte("value"), 60))
+
+	reader, appErr := th.App.ExportPluginData(pluginId)
+	require.Nil(t, appErr)
+	exported, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Len(t, strings.Split(strings.TrimSpace(string(exported)), "\n"), len(expected)+1)
+
+	result, appErr := th.App.ImportPluginDataFromReader(otherPluginId, bytes.NewReader(exported))
+	require.Nil(t, appErr)
+	assert.Equal(t, len(expected)+1, result.Imported)
+	assert.Empty(t, result.Errors)
+
+	for key, value := range expected {
+		imported, exists, appErr := th.App.GetPluginKeyWithExists(otherPluginId, key)
+		require.Nil(t, appErr)
+		assert.True(t, exists)
+		assert.Equal(t, value, imported, key)
+	}
+
+	kv, appErr := th.App.Srv.Store.Plugin().Get(otherPluginId, "expiring")
+	require.Nil(t, appErr)
+	assert.True(t, kv.ExpireAt > model.GetMillis())
+
+	t.Run("invalid lines are reported", func(t *testing.T) {
+		// Random bytes, so that compression cannot bring the value within the limit.
+		tooLarge := make([]byte, *th.App.Config().PluginSettings.MaxKeyValueSizeBytes+1)
+		_, err := rand.Read(tooLarge)
+		require.NoError(t, err)
+
+		data := strings.Join([]string{
+			`{"key": "good", "value": "dmFsdWU="}`,
+			`not json`,
+			``,
+			`{"key": "", "value": "dmFsdWU="}`,
+			`{"key": "novalue"}`,
+			`{"key": "toolarge", "value": "` + base64.StdEncoding.EncodeToString(tooLarge) + `"}`,
+			`{"key": "alsogood", "value": ""}`,
+		}, "\n")
+
+		result, appErr := th.App.ImportPluginDataFromReader(otherPluginId, strings.NewReader(data))
+		require.Nil(t, appErr)
+		assert.Equal(t, 2, result.Imported)
+
+		lines := map[int]string{}
+		for _, rowErr := range result.Errors {
+			require.NotNil(t, rowErr.Error)
+			lines[rowErr.Line] = rowErr.Key
+		}
+		assert.Equal(t, map[int]string{2: "", 4: "", 5: "novalue", 6: "toolarge"}, lines)
+
+		value, appErr := th.App.GetPluginKey(otherPluginId, "good")
+		require.Nil(t, appErr)
+		assert.Equal(t, []byte("value"), value)
+	})
+
+	t.Run("invalid plugin id", func(t *testing.T) {
+		_, appErr := th.App.ExportPluginData("../invalid")
+		assert.NotNil(t, appErr)
+
+		_, appErr = th.App.ImportPluginDataFromReader("../invalid", strings.NewReader(""))
+		assert.NotNil(t, appErr)
+	})
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	PLUGIN_EVENT_CLEANUP_INTERVAL   = 1 * time.Hour
+	PLUGIN_EVENT_CLEANUP_BATCH_SIZE = 1000
+
+	PLUGIN_EVENT_RATE_PER_SEC      = 1
+	PLUGIN_EVENT_RATE_MAX_BURST    = 10
+	PLUGIN_EVENT_MEMORY_STORE_SIZE = 1000
+)
+
+// pluginEventRateLimitedTypes are the events that a misbehaving plugin could cause to be recorded
+// repeatedly, such as by crashing as soon as it starts, so they are rate limited for each plugin.
+var pluginEventRateLimitedTypes = map[string]bool{
+	model.PLUGIN_EVENT_ACTIVATED:      true,
+	model.PLUGIN_EVENT_DEACTIVATED:    true,
+	model.PLUGIN_EVENT_FAILED:         true,
+	model.PLUGIN_EVENT_CONFIG_CHANGED: true,
+	model.PLUGIN_EVENT_QUOTA_EXCEEDED: true,
+}
+
+// pluginEventAuditedTypes are the events that change which code runs on the server, so they are also
+// recorded in the audit log.
+var pluginEventAuditedTypes = map[string]bool{
+	model.PLUGIN_EVENT_INSTALLED:     true,
+	model.PLUGIN_EVENT_UPGRADED:      true,
+	model.PLUGIN_EVENT_REMOVED:       true,
+	model.PLUGIN_EVENT_AUTO_DISABLED: true,
+}
+
+func (a *App) getPluginEventRateLimiter() *RateLimiter {
+	a.pluginEventRateLimiterOnce.Do(func() {
+		rateLimiter, err := NewRateLimiter(&model.RateLimitSettings{
+			PerSec:           model.NewInt(PLUGIN_EVENT_RATE_PER_SEC),
+			MaxBurst:         model.NewInt(PLUGIN_EVENT_RATE_MAX_BURST),
+			MemoryStoreSize:  model.NewInt(PLUGIN_EVENT_MEMORY_STORE_SIZE),
+			VaryByRemoteAddr: model.NewBool(false),
+			VaryByUser:       model.NewBool(false),
+		})
+		if err != nil {
+			mlog.Error("Unable to create plugin event rate limiter", mlog.Err(err))
+			return
+		}
+		a.pluginEventRateLimiter = rateLimiter
+	})
+
+	return a.pluginEventRateLimiter
+}
+
+// recordPluginEvent adds an event to the plugin's event log, noting the server it happened on. Events
+// that are recorded too often for a plugin are dropped, and failures are only logged so that they
+// never interfere with managing the plugin.
+func (a *App) recordPluginEvent(pluginId, eventType, details string) {
+	if pluginEventRateLimitedTypes[eventType] {
+		if rateLimiter := a.getPluginEventRateLimiter(); rateLimiter == nil || rateLimiter.IsLimited(pluginId+":"+eventType) {
+			mlog.Debug("Dropped plugin event recorded too often", mlog.String("plugin_id", pluginId), mlog.String("type", eventType))
+			return
+		}
+	}
+
+	event := &model.PluginEvent{
+		PluginId:  pluginId,
+		Type:      eventType,
+		ClusterId: a.GetClusterId(),
+		Details:   details,
+	}
+	if result := <-a.Srv.Store.PluginEvent().Save(event); result.Err != nil {
+		mlog.Error("Failed to save plugin event", mlog.String("plugin_id", pluginId), mlog.String("type", eventType), mlog.Err(result.Err))
+	}
+
+	if pluginEventAuditedTypes[eventType] {
+		audit := &model.Audit{
+			Action:    "/plugins/" + pluginId + "/events/" + eventType,
+			ExtraInfo: details,
+		}
+		if result := <-a.Srv.Store.Audit().Save(audit); result.Err != nil {
+			mlog.Error("Failed to save audit record", mlog.String("plugin_id", pluginId), mlog.Err(result.Err))
+		}
+	}
+}
+
+// recordPluginConfigChanges records the change of the settings of each active plugin whose settings
+// differ between the given configs, as those plugins were just notified of it.
+func (a *App) recordPluginConfigChanges(oldCfg, newCfg *model.Config) {
+	if a.Plugins == nil {
+		return
+	}
+
+	for _, p := range a.Plugins.Active() {
+		id := p.Manifest.Id
+		if !reflect.DeepEqual(oldCfg.PluginSettings.Plugins[id], newCfg.PluginSettings.Plugins[id]) {
+			a.recordPluginEvent(id, model.PLUGIN_EVENT_CONFIG_CHANGED, "")
+		}
+	}
+}
+
+// GetPluginEvents gets a page of the plugin's events recorded at or after the given time, oldest
+// first. Events are kept after a plugin is removed, until they expire.
+func (a *App) GetPluginEvents(pluginId string, since int64, page, perPage int) ([]*model.PluginEvent, *model.AppError) {
+	if a.Plugins == nil || !*a.Config().PluginSettings.Enable {
+		return nil, model.NewAppError("GetPluginEvents", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	result := <-a.Srv.Store.PluginEvent().GetForPlugin(pluginId, since, page*perPage, perPage)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Data.([]*model.PluginEvent), nil
+}
+
+// DeleteExpiredPluginEvents deletes the events recorded more than PluginSettings.EventRetentionDays
+// ago, in batches so as not to hold long locks on the table.
+func (a *App) DeleteExpiredPluginEvents() {
+	before := model.GetMillis() - int64(*a.Config().PluginSettings.EventRetentionDays)*24*60*60*1000
+
+	for {
+		result := <-a.Srv.Store.PluginEvent().DeleteBefore(before, PLUGIN_EVENT_CLEANUP_BATCH_SIZE)
+		if result.Err != nil {
+			mlog.Error("Failed to delete expired plugin events", mlog.Err(result.Err))
+			return
+		}
+
+		if result.Data.(int64) < PLUGIN_EVENT_CLEANUP_BATCH_SIZE {
+			return
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestRecordPluginEvent(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "com.example." + model.NewId()
+
+	th.App.recordPluginEvent(pluginId, model.PLUGIN_EVENT_INSTALLED, "version=0.0.1")
+	for i := 0; i < 2*PLUGIN_EVENT_RATE_MAX_BURST; i++ {
+		th.App.recordPluginEvent(pluginId, model.PLUGIN_EVENT_FAILED, "exited")
+	}
+	th.App.recordPluginEvent(pluginId, model.PLUGIN_EVENT_REMOVED, "")
+
+	events, err := th.App.GetPluginEvents(pluginId, 0, 0, 100)
+	require.Nil(t, err)
+
+	counts := map[string]int{}
+	for _, event := range events {
+		counts[event.Type]++
+		assert.Equal(t, th.App.GetClusterId(), event.ClusterId)
+		if event.Type == model.PLUGIN_EVENT_INSTALLED {
+			assert.Equal(t, "version=0.0.1", event.Details)
+		}
+	}
+
+	// Flapping events are rate limited, while the others are always recorded.
+	assert.Equal(t, 1, counts[model.PLUGIN_EVENT_INSTALLED])
+	assert.Equal(t, 1, counts[model.PLUGIN_EVENT_REMOVED])
+	assert.NotZero(t, counts[model.PLUGIN_EVENT_FAILED])
+	assert.True(t, counts[model.PLUGIN_EVENT_FAILED] < 2*PLUGIN_EVENT_RATE_MAX_BURST, "failed events were not rate limited")
+
+	// Only the security-relevant events are audited.
+	result := <-th.App.Srv.Store.Audit().Get("", 0, 100)
+	require.Nil(t, result.Err)
+	actions := map[string]string{}
+	for _, audit := range result.Data.(model.Audits) {
+		actions[audit.Action] = audit.ExtraInfo
+	}
+	assert.Equal(t, "version=0.0.1", actions["/plugins/"+pluginId+"/events/installed"])
+	assert.Contains(t, actions, "/plugins/"+pluginId+"/events/removed")
+	assert.NotContains(t, actions, "/plugins/"+pluginId+"/events/failed")
+}
+
+func TestDeleteExpiredPluginEvents(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.EventRetentionDays = 1
+	})
+
+	pluginId := "com.example." + model.NewId()
+	now := model.GetMillis()
+	save := func(createAt int64) *model.PluginEvent {
+		result := <-th.App.Srv.Store.PluginEvent().Save(&model.PluginEvent{
+			PluginId: pluginId,
+			Type:     model.PLUGIN_EVENT_ACTIVATED,
+			CreateAt: createAt,
+		})
+		require.Nil(t, result.Err)
+		return result.Data.(*model.PluginEvent)
+	}
+	save(now - 25*60*60*1000)
+	kept := save(now - 23*60*60*1000)
+
+	th.App.DeleteExpiredPluginEvents()
+
+	events, err := th.App.GetPluginEvents(pluginId, 0, 0, 10)
+	require.Nil(t, err)
+	assert.Equal(t, []*model.PluginEvent{kept}, events)
+}
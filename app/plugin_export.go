@@ -0,0 +1,70 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+// FilterPostsForExport gives active plugins the chance to redact their own post props before the
+// given batch of posts is written to a compliance or message export of the given type.
+//
+// Export jobs should call this once per batch rather than once per post. When no active plugin
+// implements the PostsWillBeExported hook, the posts are returned untouched.
+func (a *App) FilterPostsForExport(posts []*model.Post, exportType string) []*model.Post {
+	if !a.PluginsReady() || len(posts) == 0 || !a.Plugins.IsHookImplemented(plugin.PostsWillBeExportedId) {
+		return posts
+	}
+
+	pluginContext := newPluginContext()
+	a.Plugins.RunMultiPluginHookWithId(func(pluginId string, hooks plugin.Hooks) bool {
+		exported := hooks.PostsWillBeExported(pluginContext, posts, exportType)
+		if len(exported) != len(posts) {
+			a.Log.Warn("Discarding posts returned by plugin for export, batch size changed", mlog.String("plugin_id", pluginId))
+			return true
+		}
+
+		posts = mergePluginExportProps(pluginId, posts, exported)
+		return true
+	}, plugin.PostsWillBeExportedId)
+
+	return posts
+}
+
+// mergePluginExportProps returns a copy of posts, taking only the props within the namespace of
+// the given plugin from exported. All other changes made by the plugin are discarded.
+func mergePluginExportProps(pluginId string, posts []*model.Post, exported []*model.Post) []*model.Post {
+	prefix := pluginId + "_"
+
+	merged := make([]*model.Post, len(posts))
+	for i, post := range posts {
+		merged[i] = post
+
+		if exported[i] == nil || exported[i].Id != post.Id {
+			continue
+		}
+
+		props := make(model.StringInterface, len(post.Props))
+		for key, value := range post.Props {
+			if !strings.HasPrefix(key, prefix) {
+				props[key] = value
+			}
+		}
+		for key, value := range exported[i].Props {
+			if strings.HasPrefix(key, prefix) {
+				props[key] = value
+			}
+		}
+
+		postCopy := *post
+		postCopy.Props = props
+		merged[i] = &postCopy
+	}
+
+	return merged
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePluginExportProps(t *testing.T) {
+	post := &model.Post{
+		Id:      model.NewId(),
+		Message: "message",
+		Props: model.StringInterface{
+			"myplugin_secret":    "value",
+			"myplugin_kept":      "value",
+			"otherplugin_secret": "value",
+		},
+	}
+
+	exported := &model.Post{
+		Id:      post.Id,
+		Message: "changed",
+		Props: model.StringInterface{
+			"myplugin_kept":  "redacted",
+			"myplugin_added": "value",
+		},
+	}
+
+	merged := mergePluginExportProps("myplugin", []*model.Post{post}, []*model.Post{exported})
+	assert.Len(t, merged, 1)
+	assert.Equal(t, "message", merged[0].Message)
+	assert.Equal(t, model.StringInterface{
+		"myplugin_kept":      "redacted",
+		"myplugin_added":     "value",
+		"otherplugin_secret": "value",
+	}, merged[0].Props)
+
+	// The original post is left untouched
+	assert.Equal(t, "value", post.Props["myplugin_secret"])
+
+	t.Run("mismatched post is ignored", func(t *testing.T) {
+		other := &model.Post{Id: model.NewId()}
+		merged := mergePluginExportProps("myplugin", []*model.Post{post}, []*model.Post{other})
+		assert.Equal(t, post, merged[0])
+	})
+}
+
+func BenchmarkMergePluginExportProps(b *testing.B) {
+	posts := make([]*model.Post, 1000)
+	exported := make([]*model.Post, len(posts))
+	for i := range posts {
+		posts[i] = &model.Post{
+			Id: model.NewId(),
+			Props: model.StringInterface{
+				"myplugin_secret": "value",
+				"from_webhook":    "true",
+			},
+		}
+		exported[i] = &model.Post{
+			Id: posts[i].Id,
+			Props: model.StringInterface{
+				"from_webhook": "true",
+			},
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mergePluginExportProps("myplugin", posts, exported)
+	}
+}
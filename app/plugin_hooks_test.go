@@ -7,12 +7,16 @@ import (
 	"bytes"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/plugin"
 	"github.com/mattermost/mattermost-server/plugin/plugintest"
@@ -512,3 +516,814 @@ func TestUserHasLoggedIn(t *testing.T) {
 		t.Errorf("Expected firstname overwrite, got default")
 	}
 }
+
+func TestHookOnWebSocketConnectAndDisconnect(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) OnWebSocketConnect(webConnID, userId string) {
+			user, _ := p.API.GetUser(userId)
+			user.FirstName = webConnID
+			p.API.UpdateUser(user)
+		}
+
+		func (p *MyPlugin) OnWebSocketDisconnect(webConnID, userId string) {
+			user, _ := p.API.GetUser(userId)
+			user.LastName = webConnID
+			p.API.UpdateUser(user)
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+
+	s := httptest.NewServer(http.HandlerFunc(dummyWebsocketHandler(t)))
+	defer s.Close()
+
+	th.App.HubStart()
+	defer th.App.HubStop()
+
+	wc := registerDummyWebConn(t, th.App, s.Listener.Addr(), th.BasicUser.Id)
+	require.NotEmpty(t, wc.Id)
+
+	time.Sleep(2 * time.Second)
+
+	user, err := th.App.GetUser(th.BasicUser.Id)
+	require.Nil(t, err)
+	assert.Equal(t, wc.Id, user.FirstName)
+	assert.NotEqual(t, wc.Id, user.LastName)
+
+	th.App.HubUnregister(wc)
+
+	time.Sleep(2 * time.Second)
+
+	user, err = th.App.GetUser(th.BasicUser.Id)
+	require.Nil(t, err)
+	assert.Equal(t, wc.Id, user.LastName)
+}
+
+func TestHookOnPluginActivatedAndDeactivated(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/model"
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) OnPluginActivated(manifest *model.Manifest) {
+			user, _ := p.API.GetUserByUsername("` + th.BasicUser.Username + `")
+			user.FirstName = manifest.Id
+			p.API.UpdateUser(user)
+		}
+
+		func (p *MyPlugin) OnPluginDeactivated(manifest *model.Manifest) {
+			user, _ := p.API.GetUserByUsername("` + th.BasicUser.Username + `")
+			user.LastName = manifest.Id
+			p.API.UpdateUser(user)
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+
+	active := th.App.Plugins.Active()
+	require.Len(t, active, 1)
+	listenerId := active[0].Manifest.Id
+
+	th.App.notifyPluginsOfActivation(&model.Manifest{Id: "dependency"})
+
+	time.Sleep(2 * time.Second)
+
+	user, err := th.App.GetUser(th.BasicUser.Id)
+	require.Nil(t, err)
+	assert.Equal(t, "dependency", user.FirstName)
+
+	// Plugins are not notified of changes to their own state
+	th.App.notifyPluginsOfActivation(active[0].Manifest)
+	th.App.notifyPluginsOfDeactivation(&model.Manifest{Id: "dependency"})
+
+	time.Sleep(2 * time.Second)
+
+	user, err = th.App.GetUser(th.BasicUser.Id)
+	require.Nil(t, err)
+	assert.NotEqual(t, listenerId, user.FirstName)
+	assert.Equal(t, "dependency", user.LastName)
+}
+
+func TestHookKVHasChanged(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) KVHasChanged(key string) {
+			user, _ := p.API.GetUserByUsername("` + th.BasicUser.Username + `")
+			user.FirstName = key
+			p.API.UpdateUser(user)
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+
+	active := th.App.Plugins.Active()
+	require.Len(t, active, 1)
+	pluginId := active[0].Manifest.Id
+
+	require.Nil(t, th.App.SetPluginKey(pluginId, "set", []byte("value")))
+
+	time.Sleep(2 * time.Second)
+
+	user, err := th.App.GetUser(th.BasicUser.Id)
+	require.Nil(t, err)
+	assert.Equal(t, "set", user.FirstName)
+
+	// Changes made on other servers are relayed by cluster message.
+	th.App.ClusterPluginKeyValueHasChangedHandler(&model.ClusterMessage{
+		Event: model.CLUSTER_EVENT_PLUGIN_KEY_VALUE_HAS_CHANGED,
+		Props: map[string]string{"plugin_id": pluginId, "key": "remote"},
+	})
+
+	time.Sleep(2 * time.Second)
+
+	user, err = th.App.GetUser(th.BasicUser.Id)
+	require.Nil(t, err)
+	assert.Equal(t, "remote", user.FirstName)
+
+	require.Nil(t, th.App.DeletePluginKey(pluginId, "deleted"))
+
+	time.Sleep(2 * time.Second)
+
+	user, err = th.App.GetUser(th.BasicUser.Id)
+	require.Nil(t, err)
+	assert.Equal(t, "deleted", user.FirstName)
+
+	// Changes to the keys of other plugins are not reported.
+	require.Nil(t, th.App.SetPluginKey("otherplugin", "other", []byte("value")))
+
+	time.Sleep(2 * time.Second)
+
+	user, err = th.App.GetUser(th.BasicUser.Id)
+	require.Nil(t, err)
+	assert.NotEqual(t, "other", user.FirstName)
+}
+
+func TestHookKVHasChangedAtomicAndBatchWrites(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"sort"
+			"strings"
+			"sync"
+
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+
+			lock    sync.Mutex
+			changed map[string]bool
+		}
+
+		func (p *MyPlugin) KVHasChanged(key string) {
+			if key == "changed" {
+				return
+			}
+			if key == "" {
+				key = "<all>"
+			}
+
+			p.lock.Lock()
+			defer p.lock.Unlock()
+
+			if p.changed == nil {
+				p.changed = make(map[string]bool)
+			}
+			p.changed[key] = true
+
+			var keys []string
+			for key := range p.changed {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			p.API.KVSet("changed", []byte(strings.Join(keys, ",")))
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+
+	active := th.App.Plugins.Active()
+	require.Len(t, active, 1)
+	pluginId := active[0].Manifest.Id
+
+	assertChanged := func(t *testing.T, expected ...string) {
+		time.Sleep(2 * time.Second)
+
+		value, err := th.App.GetPluginKey(pluginId, "changed")
+		require.Nil(t, err)
+		changed := strings.Split(string(value), ",")
+		for _, key := range expected {
+			assert.Contains(t, changed, key)
+		}
+	}
+
+	t.Run("compare and set", func(t *testing.T) {
+		set, err := th.App.CompareAndSetPluginKey(pluginId, "cas", nil, []byte("value"))
+		require.Nil(t, err)
+		require.True(t, set)
+
+		// Failed writes are not reported.
+		set, err = th.App.CompareAndSetPluginKey(pluginId, "casfailed", []byte("other"), []byte("value"))
+		require.Nil(t, err)
+		require.False(t, set)
+
+		_, err = th.App.IncrementPluginKey(pluginId, "counter", 1)
+		require.Nil(t, err)
+
+		require.Nil(t, th.App.SetPluginKey(pluginId, "cad", []byte("value")))
+		deleted, err := th.App.CompareAndDeletePluginKey(pluginId, "cad", []byte("value"))
+		require.Nil(t, err)
+		require.True(t, deleted)
+
+		assertChanged(t, "cas", "counter", "cad")
+
+		value, err := th.App.GetPluginKey(pluginId, "changed")
+		require.Nil(t, err)
+		assert.NotContains(t, strings.Split(string(value), ","), "casfailed")
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		require.Nil(t, th.App.SetPluginKeys(pluginId, map[string][]byte{
+			"batch1": []byte("value"),
+			"batch2": []byte("value"),
+		}))
+
+		require.Nil(t, th.App.SetPluginKeysAtomic(pluginId, []model.PluginKVOp{
+			{Type: model.PLUGIN_KV_OP_SET, Key: "atomic1", Value: []byte("value")},
+			{Type: model.PLUGIN_KV_OP_DELETE, Key: "batch1"},
+			{Type: model.PLUGIN_KV_OP_SET, Key: "atomic2", Value: []byte("value")},
+		}))
+
+		assertChanged(t, "batch1", "batch2", "atomic1", "atomic2")
+	})
+
+	t.Run("delete all", func(t *testing.T) {
+		require.Nil(t, th.App.DeleteAllPluginKeys(pluginId))
+
+		assertChanged(t, "<all>")
+	})
+}
+
+func TestHookAdminActionHasBeenResolved(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"fmt"
+
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) AdminActionHasBeenResolved(requestId string, approved bool) {
+			user, _ := p.API.GetUserByUsername("` + th.BasicUser.Username + `")
+			user.FirstName = requestId
+			user.LastName = fmt.Sprint(approved)
+			p.API.UpdateUser(user)
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+
+	active := th.App.Plugins.Active()
+	require.Len(t, active, 1)
+	pluginId := active[0].Manifest.Id
+
+	for approve, expected := range map[bool]string{true: "true", false: "false"} {
+		request, err := th.App.RequestPluginAdminAction(pluginId, &model.PluginAdminActionRequest{
+			Action: model.PLUGIN_ADMIN_ACTION_DELETE_USER,
+			Params: model.StringMap{model.PLUGIN_ADMIN_ACTION_PARAM_USER_ID: th.CreateUser().Id},
+		})
+		require.Nil(t, err)
+
+		_, err = th.App.ResolvePluginAdminAction(request.Id, approve, th.SystemAdminUser.Id)
+		require.Nil(t, err)
+
+		time.Sleep(2 * time.Second)
+
+		user, err := th.App.GetUser(th.BasicUser.Id)
+		require.Nil(t, err)
+		assert.Equal(t, request.Id, user.FirstName)
+		assert.Equal(t, expected, user.LastName)
+	}
+}
+
+func TestHookServeMetrics(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.MetricsSettings.Enable = true })
+
+	var mockAPI plugintest.API
+	mockAPI.On("LoadPluginConfiguration", mock.Anything).Return(nil)
+
+	SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"net/http"
+
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) ServeMetrics(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("plugin_requests_total " + r.Header.Get("Authorization") + "1"))
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, func(*model.Manifest) plugin.API { return &mockAPI })
+
+	pluginId := th.App.Plugins.Active()[0].Manifest.Id
+
+	router := mux.NewRouter()
+	require.Nil(t, th.App.InitPluginMetricsRoutes(router))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/plugins/"+pluginId+"/metrics", nil)
+	r.Header.Set("Authorization", "Bearer token")
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Equal(t, "plugin_requests_total 1", w.Body.String())
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/plugins/notaplugin/metrics", nil)
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.MetricsSettings.Enable = false })
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/plugins/"+pluginId+"/metrics", nil)
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
+
+func TestHookOnDataImported(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) OnDataImported() {
+			value, _ := p.API.KVGet("imported")
+			p.API.KVSet("imported", append(value, []byte(" and fixed up")...))
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+
+	require.Len(t, th.App.Plugins.Active(), 1)
+	pluginId := th.App.Plugins.Active()[0].Manifest.Id
+
+	data := `{"type": "version", "version": 1}
+{"type": "plugin_data", "plugin_data": {"plugin_id": "` + pluginId + `", "key_values": [{"key": "imported", "value": "aW1wb3J0ZWQ="}]}}`
+
+	// Plugins are not notified of dry runs.
+	appErr, _ := th.App.BulkImport(strings.NewReader(data), true, 2, false)
+	require.Nil(t, appErr)
+	value, appErr := th.App.GetPluginKey(pluginId, "imported")
+	require.Nil(t, appErr)
+	assert.Nil(t, value)
+
+	appErr, _ = th.App.BulkImport(strings.NewReader(data), false, 2, false)
+	require.Nil(t, appErr)
+	value, appErr = th.App.GetPluginKey(pluginId, "imported")
+	require.Nil(t, appErr)
+	assert.Equal(t, "imported and fixed up", string(value))
+}
+
+func TestHookPostHasBeenAcknowledged(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) PostHasBeenAcknowledged(c *plugin.Context, postId, userId string) {
+			value, _ := p.API.KVGet(postId)
+			p.API.KVSet(postId, append(value, []byte(userId)...))
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+
+	require.Len(t, th.App.Plugins.Active(), 1)
+	pluginId := th.App.Plugins.Active()[0].Manifest.Id
+
+	post := th.CreatePost(th.BasicChannel)
+	_, appErr := th.App.RequestPostAcknowledgement(post.Id)
+	require.Nil(t, appErr)
+
+	// The hook is only invoked the first time the user acknowledges the post.
+	for i := 0; i < 2; i++ {
+		_, appErr = th.App.AcknowledgePost(th.BasicUser.Id, post.Id)
+		require.Nil(t, appErr)
+	}
+
+	time.Sleep(2 * time.Second)
+
+	value, appErr := th.App.GetPluginKey(pluginId, post.Id)
+	require.Nil(t, appErr)
+	assert.Equal(t, th.BasicUser.Id, string(value))
+}
+
+func TestHookWebappReply(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) WebappReplyReceived(replyId string, payload []byte) {
+			user, _ := p.API.GetUserByUsername("` + th.BasicUser.Username + `")
+			user.FirstName = replyId
+			user.LastName = string(payload)
+			p.API.UpdateUser(user)
+		}
+
+		func (p *MyPlugin) WebappReplyTimedOut(replyId string) {
+			user, _ := p.API.GetUserByUsername("` + th.BasicUser.Username + `")
+			user.Nickname = replyId
+			p.API.UpdateUser(user)
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+
+	active := th.App.Plugins.Active()
+	require.Len(t, active, 1)
+	pluginId := active[0].Manifest.Id
+
+	message := &model.PluginWebappMessage{Type: "confirm", Payload: []byte(`{"question": "Proceed?"}`), TimeoutSeconds: 1}
+
+	_, err := th.App.SendPluginWebappMessage(pluginId, th.BasicUser.Id, message)
+	require.NotNil(t, err)
+	assert.Equal(t, "app.plugin.webapp_message.not_connected.app_error", err.Id)
+
+	// Users connected to other servers of a cluster are only known by their status.
+	th.App.Cluster = &FakeClusterInterface{}
+	defer func() { th.App.Cluster = nil }()
+	th.App.SetStatusOnline(th.BasicUser.Id, false)
+
+	t.Run("reply", func(t *testing.T) {
+		replyId, err := th.App.SendPluginWebappMessage(pluginId, th.BasicUser.Id, message)
+		require.Nil(t, err)
+
+		// Only the user the message was sent to may reply, and only once.
+		th.App.ClusterPluginWebappReplyHandler(&model.ClusterMessage{
+			Props: map[string]string{"plugin_id": pluginId, "user_id": th.BasicUser2.Id, "reply_id": replyId, "payload": ""},
+		})
+		require.Nil(t, th.App.ReceivePluginWebappReply(pluginId, th.BasicUser.Id, replyId, []byte("yes")))
+		require.Nil(t, th.App.ReceivePluginWebappReply(pluginId, th.BasicUser.Id, replyId, []byte("no")))
+
+		time.Sleep(2 * time.Second)
+
+		user, err := th.App.GetUser(th.BasicUser.Id)
+		require.Nil(t, err)
+		assert.Equal(t, replyId, user.FirstName)
+		assert.Equal(t, "yes", user.LastName)
+		assert.NotEqual(t, replyId, user.Nickname)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		replyId, err := th.App.SendPluginWebappMessage(pluginId, th.BasicUser.Id, message)
+		require.Nil(t, err)
+
+		time.Sleep(2 * time.Second)
+
+		user, err := th.App.GetUser(th.BasicUser.Id)
+		require.Nil(t, err)
+		assert.Equal(t, replyId, user.Nickname)
+		assert.NotEqual(t, replyId, user.FirstName)
+	})
+
+	t.Run("oversized reply", func(t *testing.T) {
+		replyId, err := th.App.SendPluginWebappMessage(pluginId, th.BasicUser.Id, message)
+		require.Nil(t, err)
+
+		err = th.App.ReceivePluginWebappReply(pluginId, th.BasicUser.Id, replyId, make([]byte, model.PLUGIN_WEBAPP_MESSAGE_PAYLOAD_MAX_SIZE+1))
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, err.StatusCode)
+	})
+}
+
+func TestHookBulkOperation(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.BulkHookThreshold = 2
+		*cfg.PluginSettings.BulkHookBatchSize = 2
+		*cfg.PluginSettings.BulkHookPacingMilliseconds = 0
+	})
+
+	SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"fmt"
+
+			"github.com/mattermost/mattermost-server/model"
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) record(event string) {
+			value, _ := p.API.KVGet("events")
+			p.API.KVSet("events", append(value, []byte(event+",")...))
+		}
+
+		func (p *MyPlugin) UserHasBeenCreated(c *plugin.Context, user *model.User) {
+			p.record("user")
+		}
+
+		func (p *MyPlugin) UsersHaveBeenCreated(c *plugin.Context, users []*model.User) {
+			p.record(fmt.Sprintf("users:%v", len(users)))
+		}
+
+		func (p *MyPlugin) TeamMembersHaveChanged(c *plugin.Context, teamMembers []*model.TeamMember) {
+			p.record(fmt.Sprintf("members:%v", len(teamMembers)))
+		}
+
+		func (p *MyPlugin) BulkOperationHasCompleted(opType string, counts map[string]int) {
+			p.record(fmt.Sprintf("%v:%v:%v", opType, counts[model.BULK_OPERATION_COUNT_USERS_CREATED], counts[model.BULK_OPERATION_COUNT_TEAM_MEMBERS_CHANGED]))
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`,
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/model"
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) record(event string) {
+			value, _ := p.API.KVGet("events")
+			p.API.KVSet("events", append(value, []byte(event+",")...))
+		}
+
+		func (p *MyPlugin) UserHasBeenCreated(c *plugin.Context, user *model.User) {
+			p.record("user")
+		}
+
+		func (p *MyPlugin) UserHasJoinedTeam(c *plugin.Context, teamMember *model.TeamMember, actor *model.User) {
+			p.record("member")
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+
+	require.Len(t, th.App.Plugins.Active(), 2)
+
+	teamName := model.NewId()
+	data := `{"type": "version", "version": 1}
+{"type": "team", "team": {"type": "O", "display_name": "Bulk", "name": "` + teamName + `"}}`
+	for i := 0; i < 3; i++ {
+		username := model.NewId()
+		data += `
+{"type": "user", "user": {"username": "` + username + `", "email": "` + username + `@example.com", "teams": [{"name": "` + teamName + `"}]}}`
+	}
+
+	appErr, _ := th.App.BulkImport(strings.NewReader(data), false, 2, false)
+	require.Nil(t, appErr)
+
+	// Plugins implementing the batch hooks receive batches, while the others receive the usual hooks.
+	expected := []string{
+		"users:2,users:1,members:2,members:1,import:3:3,",
+		"user,user,user,member,member,member,",
+	}
+
+	time.Sleep(2 * time.Second)
+
+	var events []string
+	for _, p := range th.App.Plugins.Active() {
+		value, appErr := th.App.GetPluginKey(p.Manifest.Id, "events")
+		require.Nil(t, appErr)
+		events = append(events, string(value))
+	}
+	assert.ElementsMatch(t, expected, events)
+
+	// Users created one at a time are reported as usual.
+	th.CreateUser()
+	time.Sleep(time.Second)
+	for _, p := range th.App.Plugins.Active() {
+		value, appErr := th.App.GetPluginKey(p.Manifest.Id, "events")
+		require.Nil(t, appErr)
+		assert.True(t, strings.HasSuffix(string(value), ",user,"))
+	}
+}
+
+func TestHookPostsWillBeExported(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	createPost := func(props model.StringInterface) *model.Post {
+		post, err := th.App.CreatePost(&model.Post{
+			UserId:    th.BasicUser.Id,
+			ChannelId: th.BasicChannel.Id,
+			Message:   "message",
+			Props:     props,
+		}, th.BasicChannel, false)
+		require.Nil(t, err)
+		return post
+	}
+
+	exportBatch := func() []*model.Post {
+		result := <-th.App.Srv.Store.Post().GetPostsBatchForChannelExport(th.BasicChannel.Id, 0, "", CHANNEL_EXPORT_BATCH_SIZE)
+		require.Nil(t, result.Err)
+		return th.App.FilterPostsForExport(result.Data.([]*model.Post), model.COMPLIANCE_EXPORT_TYPE_ACTIANCE)
+	}
+
+	// Without a plugin implementing the hook, posts are exported as they are stored.
+	plain := createPost(model.StringInterface{"otherplugin_secret": "value"})
+	for _, post := range exportBatch() {
+		if post.Id == plain.Id {
+			assert.Equal(t, "message", post.Message)
+			assert.Equal(t, "value", post.Props["otherplugin_secret"])
+		}
+	}
+
+	SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+			"github.com/mattermost/mattermost-server/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) PostsWillBeExported(c *plugin.Context, posts []*model.Post, exportType string) []*model.Post {
+			for _, post := range posts {
+				for key := range post.Props {
+					post.Props[key] = "redacted " + exportType
+				}
+				post.Props["injected"] = "value"
+				post.Message = "changed"
+			}
+			return posts
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+
+	require.Len(t, th.App.Plugins.Active(), 1)
+	pluginId := th.App.Plugins.Active()[0].Manifest.Id
+
+	redacted := createPost(model.StringInterface{
+		pluginId + "_secret": "value",
+		"otherplugin_secret": "value",
+	})
+
+	found := false
+	for _, post := range exportBatch() {
+		if post.Id != redacted.Id {
+			continue
+		}
+		found = true
+
+		// Only the plugin's own props are changed; everything else it did is discarded.
+		assert.Equal(t, "message", post.Message)
+		assert.Equal(t, "redacted "+model.COMPLIANCE_EXPORT_TYPE_ACTIANCE, post.Props[pluginId+"_secret"])
+		assert.Equal(t, "value", post.Props["otherplugin_secret"])
+		assert.NotContains(t, post.Props, "injected")
+	}
+	assert.True(t, found)
+
+	// The stored post is left alone.
+	stored, err := th.App.GetSinglePost(redacted.Id)
+	require.Nil(t, err)
+	assert.Equal(t, "value", stored.Props[pluginId+"_secret"])
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+const (
+	// PLUGIN_IDEMPOTENCY_KEY_PREFIX is prepended to the slot in which a delivery is remembered before
+	// hashing it into a key, so that it does not collide with the plugin's keys.
+	PLUGIN_IDEMPOTENCY_KEY_PREFIX = "\x00idempotency:"
+
+	// PLUGIN_IDEMPOTENCY_SLOTS bounds the deliveries remembered for each plugin. Each delivery is
+	// remembered in a slot chosen by its id, replacing any other delivery remembered there.
+	PLUGIN_IDEMPOTENCY_SLOTS = 10000
+
+	PLUGIN_IDEMPOTENCY_DEFAULT_TTL = 24 * time.Hour
+
+	// PLUGIN_IDEMPOTENCY_DEFAULT_PENDING_TTL is how long a delivery is held while the plugin handles
+	// it, so that a delivery abandoned by a failed server is released long before it would otherwise
+	// be forgotten.
+	PLUGIN_IDEMPOTENCY_DEFAULT_PENDING_TTL = 5 * time.Minute
+
+	// PLUGIN_IDEMPOTENCY_RETRY_AFTER_SECONDS is suggested to senders repeating a delivery the plugin is
+	// still handling.
+	PLUGIN_IDEMPOTENCY_RETRY_AFTER_SECONDS = 5
+
+	pluginIdempotencyClaimAttempts = 3
+)
+
+// pluginIdempotencyPending stands in for the status code of a delivery the plugin is still handling.
+const pluginIdempotencyPending = "pending"
+
+// idempotentPluginHandler wraps the handler of a plugin's HTTP requests so that requests to routes
+// declaring an idempotency header in the manifest are handled once for each value of the header.
+func (a *App) idempotentPluginHandler(manifest *model.Manifest, handler func(*plugin.Context, http.ResponseWriter, *http.Request)) func(*plugin.Context, http.ResponseWriter, *http.Request) {
+	if manifest == nil || len(manifest.Routes) == 0 {
+		return handler
+	}
+
+	return func(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
+		route := manifest.GetRoute(r.URL.Path)
+		if route == nil || route.IdempotencyHeader == "" {
+			handler(c, w, r)
+			return
+		}
+
+		deliveryId := r.Header.Get(route.IdempotencyHeader)
+		if deliveryId == "" {
+			handler(c, w, r)
+			return
+		}
+
+		a.serveIdempotentPluginRequest(manifest.Id, route, deliveryId, w, func(w http.ResponseWriter) {
+			handler(c, w, r)
+		})
+	}
+}
+
+// serveIdempotentPluginRequest serves a delivery to a route with replay protection. Only the first
+// request with a given delivery id is served, and repeats are answered with the status code it was
+// served with, or asked to retry while it is still being served. Deliveries served with a 5xx status
+// code are forgotten so that the sender's retries are served. A delivery is only held for a short
+// while as it is served, and remembered for the route's full TTL once its status code is recorded.
+func (a *App) serveIdempotentPluginRequest(pluginId string, route *model.ManifestRoute, deliveryId string, w http.ResponseWriter, serve func(http.ResponseWriter)) {
+	ttl := PLUGIN_IDEMPOTENCY_DEFAULT_TTL
+	if route.IdempotencyTTLSeconds > 0 {
+		ttl = time.Duration(route.IdempotencyTTLSeconds) * time.Second
+	}
+	pendingTTL := PLUGIN_IDEMPOTENCY_DEFAULT_PENDING_TTL
+	if route.IdempotencyPendingTTLSeconds > 0 {
+		pendingTTL = time.Duration(route.IdempotencyPendingTTLSeconds) * time.Second
+	}
+
+	delivery, key := pluginIdempotencyKey(route.Path, deliveryId)
+	pending := encodePluginDelivery(delivery, pluginIdempotencyPending)
+
+	claimed, status, err := a.claimPluginDelivery(pluginId, key, delivery, pending, pendingTTL)
+	if err != nil {
+		// Replay protection is best effort, so the request is still served.
+		mlog.Warn("Failed to record plugin webhook delivery", mlog.String("plugin_id", pluginId), mlog.Err(err))
+		serve(w)
+		return
+	}
+
+	if !claimed {
+		if status == "" || status == pluginIdempotencyPending {
+			w.Header().Set("Retry-After", strconv.Itoa(PLUGIN_IDEMPOTENCY_RETRY_AFTER_SECONDS))
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		code, _ := strconv.Atoi(status)
+		w.Header().Set(model.HEADER_IDEMPOTENT_REPLAY, "true")
+		w.WriteHeader(code)
+		return
+	}
+
+	recorder := &statusRecordingResponseWriter{ResponseWriter: w}
+	serve(recorder)
+
+	if recorder.statusCode() >= http.StatusInternalServerError {
+		if _, err := a.Srv.Store.Plugin().CompareAndDelete(pluginId, key, pending); err != nil {
+			mlog.Warn("Failed to forget failed plugin webhook delivery", mlog.String("plugin_id", pluginId), mlog.Err(err))
+		}
+		return
+	}
+
+	if _, err := a.Srv.Store.Plugin().CompareAndSet(&model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      key,
+		Value:    encodePluginDelivery(delivery, strconv.Itoa(recorder.statusCode())),
+		ExpireAt: model.GetMillis() + int64(ttl/time.Millisecond),
+	}, pending); err != nil {
+		mlog.Warn("Failed to record plugin webhook delivery", mlog.String("plugin_id", pluginId), mlog.Err(err))
+	}
+}
+
+// claimPluginDelivery records that the delivery is being served, holding it for the given TTL, and
+// returns whether it was claimed by this request. Otherwise, the status recorded for the delivery is
+// returned, which is empty if the slot kept changing hands.
+func (a *App) claimPluginDelivery(pluginId, key, delivery string, pending []byte, ttl time.Duration) (bool, string, *model.AppError) {
+	kv := &model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      key,
+		Value:    pending,
+		ExpireAt: model.GetMillis() + int64(ttl/time.Millisecond),
+	}
+
+	for attempt := 0; attempt < pluginIdempotencyClaimAttempts; attempt++ {
+		var previous []byte
+
+		stored, err := a.Srv.Store.Plugin().GetFromMaster(pluginId, key)
+		if err != nil && err.StatusCode != http.StatusNotFound {
+			return false, "", err
+		} else if err == nil {
+			previous = stored.Value
+
+			if previousDelivery, status := decodePluginDelivery(previous); previousDelivery == delivery {
+				return false, status, nil
+			}
+		}
+
+		// The slot is either free or remembers another delivery, which is forgotten.
+		claimed, err := a.compareAndSetStoredPluginKey(kv, previous)
+		if err != nil {
+			return false, "", err
+		} else if claimed {
+			return true, "", nil
+		}
+	}
+
+	return false, "", nil
+}
+
+// pluginIdempotencyKey returns the hash identifying a delivery to the route at path, and the key
+// under which it is remembered.
+func pluginIdempotencyKey(path, deliveryId string) (string, string) {
+	delivery := getKeyHash(path + "\x00" + deliveryId)
+	return delivery, getKeyHash(PLUGIN_IDEMPOTENCY_KEY_PREFIX + strconv.FormatUint(pluginIdempotencySlot(delivery), 10))
+}
+
+// pluginIdempotencySlot returns the slot in which the delivery is remembered.
+func pluginIdempotencySlot(delivery string) uint64 {
+	var slot uint64
+	for i := 0; i < len(delivery); i++ {
+		slot = slot*31 + uint64(delivery[i])
+	}
+	return slot % PLUGIN_IDEMPOTENCY_SLOTS
+}
+
+func encodePluginDelivery(delivery, status string) []byte {
+	return []byte(delivery + " " + status)
+}
+
+func decodePluginDelivery(value []byte) (string, string) {
+	i := bytes.IndexByte(value, ' ')
+	if i < 0 {
+		return "", ""
+	}
+	return string(value[:i]), string(value[i+1:])
+}
+
+// statusRecordingResponseWriter records the status code a response was written with.
+type statusRecordingResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecordingResponseWriter) WriteHeader(statusCode int) {
+	if w.status == 0 {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusRecordingResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends any buffered data to the client, allowing plugins to stream responses.
+func (w *statusRecordingResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// statusCode returns the status code the response was written with, which is 200 OK if the handler
+// wrote nothing.
+func (w *statusRecordingResponseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
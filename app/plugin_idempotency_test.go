@@ -0,0 +1,187 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+func TestIdempotentPluginHandler(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	manifest := &model.Manifest{
+		Id: "testpluginid",
+		Routes: []*model.ManifestRoute{
+			{Path: "/webhook", IdempotencyHeader: "X-Delivery-Id"},
+		},
+	}
+	defer th.App.DeleteAllPluginKeys(manifest.Id)
+
+	var invocations int32
+	status := http.StatusAccepted
+	handler := th.App.idempotentPluginHandler(manifest, func(_ *plugin.Context, w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&invocations, 1)
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(status)
+	})
+
+	deliver := func(path, deliveryId string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, nil)
+		if deliveryId != "" {
+			r.Header.Set("X-Delivery-Id", deliveryId)
+		}
+		w := httptest.NewRecorder()
+		handler(&plugin.Context{}, w, r)
+		return w
+	}
+
+	t.Run("repeated deliveries are answered without invoking the plugin", func(t *testing.T) {
+		atomic.StoreInt32(&invocations, 0)
+		deliveryId := model.NewId()
+
+		w := deliver("/webhook", deliveryId)
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Empty(t, w.Header().Get(model.HEADER_IDEMPOTENT_REPLAY))
+
+		w = deliver("/webhook", deliveryId)
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "true", w.Header().Get(model.HEADER_IDEMPOTENT_REPLAY))
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&invocations))
+
+		deliver("/webhook", model.NewId())
+		assert.Equal(t, int32(2), atomic.LoadInt32(&invocations))
+	})
+
+	t.Run("concurrent deliveries invoke the plugin once", func(t *testing.T) {
+		atomic.StoreInt32(&invocations, 0)
+		deliveryId := model.NewId()
+
+		var wg sync.WaitGroup
+		codes := make([]int, 10)
+		for i := range codes {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				codes[i] = deliver("/webhook", deliveryId).Code
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&invocations))
+		for _, code := range codes {
+			assert.Contains(t, []int{http.StatusAccepted, http.StatusConflict}, code)
+		}
+
+		assert.Equal(t, http.StatusAccepted, deliver("/webhook", deliveryId).Code)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&invocations))
+	})
+
+	t.Run("failed deliveries are served again", func(t *testing.T) {
+		atomic.StoreInt32(&invocations, 0)
+		deliveryId := model.NewId()
+
+		status = http.StatusInternalServerError
+		assert.Equal(t, http.StatusInternalServerError, deliver("/webhook", deliveryId).Code)
+
+		status = http.StatusAccepted
+		assert.Equal(t, http.StatusAccepted, deliver("/webhook", deliveryId).Code)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&invocations))
+	})
+
+	t.Run("deliveries are only held briefly while they are served", func(t *testing.T) {
+		deliveryId := model.NewId()
+		_, key := pluginIdempotencyKey("/webhook", deliveryId)
+
+		var held *model.PluginKeyValue
+		handler := th.App.idempotentPluginHandler(manifest, func(_ *plugin.Context, w http.ResponseWriter, r *http.Request) {
+			var err *model.AppError
+			held, err = th.App.Srv.Store.Plugin().GetFromMaster(manifest.Id, key)
+			require.Nil(t, err)
+			w.WriteHeader(http.StatusAccepted)
+		})
+
+		r := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+		r.Header.Set("X-Delivery-Id", deliveryId)
+		start := model.GetMillis()
+		handler(&plugin.Context{}, httptest.NewRecorder(), r)
+
+		require.NotNil(t, held)
+		assert.True(t, held.ExpireAt <= model.GetMillis()+int64(PLUGIN_IDEMPOTENCY_DEFAULT_PENDING_TTL/time.Millisecond))
+
+		remembered, err := th.App.Srv.Store.Plugin().GetFromMaster(manifest.Id, key)
+		require.Nil(t, err)
+		assert.True(t, remembered.ExpireAt >= start+int64(PLUGIN_IDEMPOTENCY_DEFAULT_TTL/time.Millisecond))
+	})
+
+	t.Run("stale pending deliveries are served again", func(t *testing.T) {
+		atomic.StoreInt32(&invocations, 0)
+		deliveryId := model.NewId()
+		delivery, key := pluginIdempotencyKey("/webhook", deliveryId)
+
+		hold := func(expireAt int64) {
+			_, err := th.App.Srv.Store.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+				PluginId: manifest.Id,
+				Key:      key,
+				Value:    encodePluginDelivery(delivery, pluginIdempotencyPending),
+				ExpireAt: expireAt,
+			})
+			require.Nil(t, err)
+		}
+
+		// A delivery still being served elsewhere is not served again.
+		hold(model.GetMillis() + 60*1000)
+		assert.Equal(t, http.StatusConflict, deliver("/webhook", deliveryId).Code)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&invocations))
+
+		// A delivery abandoned by a failed server is served once its hold lapses.
+		hold(model.GetMillis() - 1000)
+		assert.Equal(t, http.StatusAccepted, deliver("/webhook", deliveryId).Code)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&invocations))
+
+		w := deliver("/webhook", deliveryId)
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "true", w.Header().Get(model.HEADER_IDEMPOTENT_REPLAY))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&invocations))
+	})
+
+	t.Run("requests without the header or to other routes are always served", func(t *testing.T) {
+		atomic.StoreInt32(&invocations, 0)
+		deliveryId := model.NewId()
+
+		deliver("/webhook", "")
+		deliver("/webhook", "")
+		deliver("/other", deliveryId)
+		deliver("/other", deliveryId)
+		assert.Equal(t, int32(4), atomic.LoadInt32(&invocations))
+	})
+
+	t.Run("the plugin's keys are unaffected", func(t *testing.T) {
+		keys, err := th.App.ListPluginKeys(manifest.Id, 0, 100)
+		require.Nil(t, err)
+		assert.Empty(t, keys)
+	})
+}
+
+func TestPluginIdempotencySlot(t *testing.T) {
+	delivery := getKeyHash("/webhook\x00" + model.NewId())
+	assert.Equal(t, pluginIdempotencySlot(delivery), pluginIdempotencySlot(delivery))
+	assert.True(t, pluginIdempotencySlot(delivery) < PLUGIN_IDEMPOTENCY_SLOTS)
+
+	decodedDelivery, status := decodePluginDelivery(encodePluginDelivery(delivery, "202"))
+	assert.Equal(t, delivery, decodedDelivery)
+	assert.Equal(t, "202", status)
+}
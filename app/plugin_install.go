@@ -4,114 +4,463 @@
 package app
 
 import (
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 
 	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
-	"github.com/mattermost/mattermost-server/plugin"
 	"github.com/mattermost/mattermost-server/utils"
 )
 
-// InstallPlugin unpacks and installs a plugin but does not enable or activate it.
-func (a *App) InstallPlugin(pluginFile io.Reader, replace bool) (*model.Manifest, *model.AppError) {
-	return a.installPlugin(pluginFile, replace)
+// maxSizeReader wraps a reader and records whether more than max bytes were read from it.
+type maxSizeReader struct {
+	reader   io.Reader
+	max      int64
+	read     int64
+	exceeded bool
 }
 
-func (a *App) installPlugin(pluginFile io.Reader, replace bool) (*model.Manifest, *model.AppError) {
-	if a.Plugins == nil || !*a.Config().PluginSettings.Enable {
-		return nil, model.NewAppError("installPlugin", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+func (r *maxSizeReader) Read(p []byte) (int, error) {
+	if r.exceeded {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	if r.read > r.max {
+		r.exceeded = true
+		return n, io.ErrUnexpectedEOF
 	}
 
-	tmpDir, err := ioutil.TempDir("", "plugintmp")
+	return n, err
+}
+
+// savePluginBundle copies the given plugin bundle to a temporary file, enforcing the configured
+// bundle size limit. The caller must remove the returned file.
+func (a *App) savePluginBundle(pluginFile io.Reader) (string, *model.AppError) {
+	file, err := ioutil.TempFile("", PLUGIN_TEMP_DIR_PREFIX)
 	if err != nil {
-		return nil, model.NewAppError("installPlugin", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+		return "", model.NewAppError("savePluginBundle", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+	defer file.Close()
+
+	maxBundleSize := *a.Config().PluginSettings.MaxBundleSize
+	bundleReader := &maxSizeReader{reader: pluginFile, max: maxBundleSize}
+	if _, err := io.Copy(file, bundleReader); err != nil {
+		os.Remove(file.Name())
+		if bundleReader.exceeded {
+			return "", model.NewAppError("savePluginBundle", "app.plugin.install.bundle_too_large.app_error", map[string]interface{}{"Max": maxBundleSize}, "", http.StatusRequestEntityTooLarge)
+		}
+		return "", model.NewAppError("savePluginBundle", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return file.Name(), nil
+}
+
+// extractPluginBundle extracts the given plugin bundle, either a .tar.gz or a .zip file, into a new
+// temporary directory, enforcing the configured size limits. It returns the temporary directory,
+// which the caller must remove, along with the directory containing the plugin itself.
+func (a *App) extractPluginBundle(pluginFile io.Reader) (string, string, *model.AppError) {
+	tmpDir, err := ioutil.TempDir("", PLUGIN_TEMP_DIR_PREFIX)
+	if err != nil {
+		return "", "", model.NewAppError("extractPluginBundle", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
 	}
-	defer os.RemoveAll(tmpDir)
 
-	if err := utils.ExtractTarGz(pluginFile, tmpDir); err != nil {
-		return nil, model.NewAppError("installPlugin", "app.plugin.extract.app_error", nil, err.Error(), http.StatusBadRequest)
+	pluginSettings := a.Config().PluginSettings
+	bundleReader := &maxSizeReader{reader: pluginFile, max: *pluginSettings.MaxBundleSize}
+
+	if err := utils.ExtractArchiveWithLimit(bundleReader, tmpDir, *pluginSettings.MaxExtractedSize); err != nil {
+		os.RemoveAll(tmpDir)
+		if bundleReader.exceeded {
+			return "", "", model.NewAppError("extractPluginBundle", "app.plugin.install.bundle_too_large.app_error", map[string]interface{}{"Max": *pluginSettings.MaxBundleSize}, "", http.StatusRequestEntityTooLarge)
+		}
+		if err == utils.ErrExtractedSizeExceeded {
+			return "", "", model.NewAppError("extractPluginBundle", "app.plugin.install.extracted_too_large.app_error", map[string]interface{}{"Max": *pluginSettings.MaxExtractedSize}, "", http.StatusRequestEntityTooLarge)
+		}
+		if unsafeErr, ok := err.(*utils.UnsafeArchiveEntryError); ok {
+			return "", "", model.NewAppError("extractPluginBundle", "app.plugin.extract.unsafe_entry.app_error", map[string]interface{}{"Name": unsafeErr.Name}, unsafeErr.Error(), http.StatusBadRequest)
+		}
+		return "", "", model.NewAppError("extractPluginBundle", "app.plugin.extract.app_error", nil, err.Error(), http.StatusBadRequest)
 	}
 
 	tmpPluginDir := tmpDir
 	dir, err := ioutil.ReadDir(tmpDir)
 	if err != nil {
-		return nil, model.NewAppError("installPlugin", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+		os.RemoveAll(tmpDir)
+		return "", "", model.NewAppError("extractPluginBundle", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
 	}
 
 	if len(dir) == 1 && dir[0].IsDir() {
 		tmpPluginDir = filepath.Join(tmpPluginDir, dir[0].Name())
 	}
 
-	manifest, _, err := model.FindManifest(tmpPluginDir)
+	return tmpDir, tmpPluginDir, nil
+}
+
+// validatePluginBundle checks the extracted plugin bundle in the given directory, returning its
+// manifest, if one could be read, along with every problem that would prevent its installation.
+func (a *App) validatePluginBundle(pluginDir string) (*model.Manifest, []*model.AppError) {
+	manifest, _, err := model.FindManifest(pluginDir)
 	if err != nil {
-		return nil, model.NewAppError("installPlugin", "app.plugin.manifest.app_error", nil, err.Error(), http.StatusBadRequest)
+		return nil, []*model.AppError{model.NewAppError("validatePluginBundle", "app.plugin.manifest.app_error", nil, err.Error(), http.StatusBadRequest)}
 	}
 
-	if !plugin.IsValidId(manifest.Id) {
-		return nil, model.NewAppError("installPlugin", "app.plugin.invalid_id.app_error", map[string]interface{}{"Min": plugin.MinIdLength, "Max": plugin.MaxIdLength, "Regex": plugin.ValidIdRegex}, "", http.StatusBadRequest)
+	var findings []*model.AppError
+
+	// The files the manifest refers to are only looked for once it is known to be valid.
+	manifestErr := manifest.IsValid()
+	if manifestErr != nil {
+		findings = append(findings, manifestErr)
+	} else if err := checkPluginMinServerVersion(manifest); err != nil {
+		findings = append(findings, err)
 	}
 
-	bundles, err := a.Plugins.Available()
-	if err != nil {
-		return nil, model.NewAppError("installPlugin", "app.plugin.install.app_error", nil, err.Error(), http.StatusInternalServerError)
+	for _, capability := range manifest.Capabilities {
+		if !model.IsValidPluginCapability(capability) {
+			findings = append(findings, model.NewAppError("validatePluginBundle", "app.plugin.validate.capability.app_error", map[string]interface{}{"Capability": capability}, "", http.StatusBadRequest))
+		}
 	}
 
-	// Check that there is no plugin with the same ID
-	for _, bundle := range bundles {
-		if bundle.Manifest != nil && bundle.Manifest.Id == manifest.Id {
-			if !replace {
-				return nil, model.NewAppError("installPlugin", "app.plugin.install_id.app_error", nil, "", http.StatusBadRequest)
+	if manifestErr == nil && manifest.HasServer() {
+		executable := filepath.Clean(manifest.GetExecutableForRuntime(runtime.GOOS, runtime.GOARCH))
+		if executable == "." || utils.PathTraversesUpward(executable) {
+			findings = append(findings, model.NewAppError("validatePluginBundle", "app.plugin.validate.executable.app_error", map[string]interface{}{"Platform": runtime.GOOS + "-" + runtime.GOARCH}, "", http.StatusBadRequest))
+		} else if info, err := os.Stat(filepath.Join(pluginDir, executable)); err != nil || info.IsDir() {
+			findings = append(findings, model.NewAppError("validatePluginBundle", "app.plugin.validate.executable.app_error", map[string]interface{}{"Platform": runtime.GOOS + "-" + runtime.GOARCH}, "path="+executable, http.StatusBadRequest))
+		}
+	}
+
+	if manifest.HasWebapp() {
+		directives := make([]string, 0, len(manifest.Webapp.ContentSecurityPolicy))
+		for directive := range manifest.Webapp.ContentSecurityPolicy {
+			directives = append(directives, directive)
+		}
+		sort.Strings(directives)
+
+		for _, directive := range directives {
+			if !model.IsValidPluginCSPDirective(directive) {
+				findings = append(findings, model.NewAppError("validatePluginBundle", "app.plugin.validate.csp_directive.app_error", map[string]interface{}{"Directive": directive}, "", http.StatusBadRequest))
+				continue
 			}
 
-			if err := a.RemovePlugin(manifest.Id); err != nil {
-				return nil, model.NewAppError("installPlugin", "app.plugin.install_id_failed_remove.app_error", nil, "", http.StatusBadRequest)
+			for _, source := range manifest.Webapp.ContentSecurityPolicy[directive] {
+				if !model.IsValidPluginCSPSource(source) {
+					findings = append(findings, model.NewAppError("validatePluginBundle", "app.plugin.validate.csp_source.app_error", map[string]interface{}{"Directive": directive, "Source": source}, "", http.StatusBadRequest))
+				}
 			}
 		}
 	}
 
-	pluginPath := filepath.Join(*a.Config().PluginSettings.Directory, manifest.Id)
-	err = utils.CopyDir(tmpPluginDir, pluginPath)
+	if manifestErr == nil && manifest.HasWebapp() {
+		bundlePath := filepath.Clean(manifest.Webapp.BundlePath)
+		if info, err := os.Stat(filepath.Join(pluginDir, bundlePath)); err != nil || info.IsDir() {
+			findings = append(findings, model.NewAppError("validatePluginBundle", "app.plugin.validate.webapp_bundle.app_error", nil, "path="+bundlePath, http.StatusBadRequest))
+		}
+	}
+
+	return manifest, findings
+}
+
+// checkPluginMinServerVersion returns an error if the plugin requires a newer server than this one.
+// Development builds may provide newer APIs than their version suggests, so are assumed to satisfy
+// any requirement.
+func checkPluginMinServerVersion(manifest *model.Manifest) *model.AppError {
+	if manifest.MeetsMinServerVersion(model.CurrentVersion) {
+		return nil
+	}
+
+	if model.IsDevBuild() {
+		mlog.Warn("Plugin requires a newer server version, but this is a development build", mlog.String("plugin_id", manifest.Id), mlog.String("min_server_version", manifest.MinServerVersion), mlog.String("server_version", model.CurrentVersion))
+		return nil
+	}
+
+	return model.NewAppError("checkPluginMinServerVersion", "app.plugin.min_server_version.app_error", map[string]interface{}{"MinServerVersion": manifest.MinServerVersion, "ServerVersion": model.CurrentVersion}, "id="+manifest.Id, http.StatusBadRequest)
+}
+
+// ValidatePlugin checks whether the given plugin bundle would be accepted by InstallPlugin,
+// reporting every problem found instead of just the first. Nothing is installed.
+func (a *App) ValidatePlugin(pluginFile io.Reader) (*model.PluginValidationReport, *model.AppError) {
+	if a.Plugins == nil || !*a.Config().PluginSettings.Enable {
+		return nil, model.NewAppError("ValidatePlugin", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	report := &model.PluginValidationReport{Findings: []*model.AppError{}}
+
+	tmpDir, tmpPluginDir, appErr := a.extractPluginBundle(pluginFile)
+	if appErr != nil {
+		if appErr.StatusCode == http.StatusInternalServerError {
+			return nil, appErr
+		}
+		report.Findings = append(report.Findings, appErr)
+		return report, nil
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest, findings := a.validatePluginBundle(tmpPluginDir)
+	report.Manifest = manifest
+	report.Findings = append(report.Findings, findings...)
+	report.Valid = len(report.Findings) == 0
+	if manifest != nil {
+		report.Warnings = validatePluginBundleWarnings(tmpPluginDir, manifest)
+	}
+
+	return report, nil
+}
+
+// InstallPlugin unpacks and installs a plugin but does not enable or activate it. If replace is set,
+// an installed plugin with the same id is upgraded in place, and restarted if it is enabled. The
+// optional signature is a detached signature of the bundle, which is verified against
+// PluginSettings.SignaturePublicKeyFiles and kept so that it can be verified again before the plugin
+// is activated. Plugins without one are refused if PluginSettings.RequirePluginSignature is set.
+func (a *App) InstallPlugin(pluginFile, signature io.Reader, replace bool) (*model.Manifest, *model.AppError) {
+	result, err := a.installPlugin(pluginFile, signature, replace)
 	if err != nil {
-		return nil, model.NewAppError("installPlugin", "app.plugin.mvdir.app_error", nil, err.Error(), http.StatusInternalServerError)
+		return nil, err
+	}
+
+	return result.Manifest, nil
+}
+
+// InstallPluginWithWarnings installs a plugin like InstallPlugin, also reporting any problems found
+// with it that did not prevent its installation.
+func (a *App) InstallPluginWithWarnings(pluginFile, signature io.Reader, replace bool) (*model.PluginInstallResult, *model.AppError) {
+	return a.installPlugin(pluginFile, signature, replace)
+}
+
+func (a *App) installPlugin(pluginFile, signature io.Reader, replace bool) (*model.PluginInstallResult, *model.AppError) {
+	if a.Plugins == nil || !*a.Config().PluginSettings.Enable {
+		return nil, model.NewAppError("installPlugin", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	pluginSettings := a.Config().PluginSettings
+	if signature == nil && *pluginSettings.RequirePluginSignature {
+		return nil, model.NewAppError("installPlugin", "app.plugin.signature.missing.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	a.pluginInstallLock.Lock()
+	defer a.pluginInstallLock.Unlock()
+
+	// The bundle of a signed plugin is read twice, to verify it and to extract it, and then kept.
+	var signatureData []byte
+	var bundlePath string
+	if signature != nil {
+		var appErr *model.AppError
+		if signatureData, appErr = readPluginSignature(signature); appErr != nil {
+			return nil, appErr
+		}
+
+		if bundlePath, appErr = a.savePluginBundle(pluginFile); appErr != nil {
+			return nil, appErr
+		}
+		defer os.Remove(bundlePath)
+
+		bundleFile, err := os.Open(bundlePath)
+		if err != nil {
+			return nil, model.NewAppError("installPlugin", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		defer bundleFile.Close()
+
+		if appErr := a.verifyPluginSignature(bundleFile, signatureData); appErr != nil {
+			return nil, appErr
+		}
+
+		if _, err := bundleFile.Seek(0, io.SeekStart); err != nil {
+			return nil, model.NewAppError("installPlugin", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		pluginFile = bundleFile
 	}
 
+	tmpDir, tmpPluginDir, appErr := a.extractPluginBundle(pluginFile)
+	if appErr != nil {
+		return nil, appErr
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest, findings := a.validatePluginBundle(tmpPluginDir)
+	if len(findings) > 0 {
+		return nil, findings[0]
+	}
+	bundles, pluginErrors, err := a.Plugins.AvailableWithErrors()
+	if err != nil {
+		return nil, model.NewAppError("installPlugin", "app.plugin.install.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	// Check that there is no plugin with the same ID and that we stay within the installed plugin limit.
+	// Directories that could not be loaded as plugins still count towards the limit until removed.
+	var existing *model.BundleInfo
+	installedCount := len(pluginErrors)
+	for _, bundle := range bundles {
+		if bundle.Manifest.Id != manifest.Id {
+			installedCount++
+			continue
+		}
+
+		if !replace {
+			return nil, model.NewAppError("installPlugin", "app.plugin.install_id.app_error", nil, "", http.StatusBadRequest)
+		}
+		existing = bundle
+	}
+
+	if installedCount >= *pluginSettings.MaxInstalledPlugins {
+		return nil, model.NewAppError("installPlugin", "app.plugin.install.too_many_plugins.app_error", map[string]interface{}{"Max": *pluginSettings.MaxInstalledPlugins}, "", http.StatusBadRequest)
+	}
+
+	if existing != nil {
+		if appErr := a.replacePlugin(existing, manifest, tmpPluginDir); appErr != nil {
+			return nil, appErr
+		}
+	} else {
+		pluginPath := filepath.Join(*a.Config().PluginSettings.Directory, manifest.Id)
+		err = utils.CopyDir(tmpPluginDir, pluginPath)
+		if err != nil {
+			return nil, model.NewAppError("installPlugin", "app.plugin.mvdir.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+	}
+
+	if signatureData == nil {
+		mlog.Info("Installed a plugin without a signature", mlog.String("plugin_id", manifest.Id))
+	}
+	if err := a.storePluginSignature(manifest.Id, bundlePath, signatureData); err != nil {
+		mlog.Error("Failed to store the signature of a plugin", mlog.String("plugin_id", manifest.Id), mlog.Err(err))
+	}
+
+	// Prepackaged versions of a plugin installed again after having been removed are no longer held
+	// back.
+	a.setPluginRemoved(manifest.Id, false)
+
 	if err := a.notifyPluginStatusesChanged(); err != nil {
 		mlog.Error("failed to notify plugin status changed", mlog.Err(err))
 	}
 
-	return manifest, nil
+	if existing != nil {
+		a.recordPluginEvent(manifest.Id, model.PLUGIN_EVENT_UPGRADED, fmt.Sprintf("version=%v previous_version=%v", manifest.Version, existing.Manifest.Version))
+	} else {
+		a.recordPluginEvent(manifest.Id, model.PLUGIN_EVENT_INSTALLED, "version="+manifest.Version)
+	}
+
+	return &model.PluginInstallResult{
+		Manifest: manifest,
+		Warnings: validatePluginBundleWarnings(tmpPluginDir, manifest),
+	}, nil
 }
 
 func (a *App) RemovePlugin(id string) *model.AppError {
-	return a.removePlugin(id)
+	_, err := a.RemovePluginWithOptions(id, false)
+	return err
+}
+
+// RemovePluginWithOptions deactivates and deletes a plugin, returning a summary of what was removed.
+// The plugin's key-value data and the settings it stored for users are purged when deleteData is set,
+// and otherwise left in place so that they are available should the plugin be installed again.
+func (a *App) RemovePluginWithOptions(id string, deleteData bool) (*model.PluginRemovalInventory, *model.AppError) {
+	a.pluginInstallLock.Lock()
+	defer a.pluginInstallLock.Unlock()
+
+	return a.removePlugin(id, deleteData)
+}
+
+// RemoveBrokenPlugin deletes a directory within the plugin directory that could not be loaded as a
+// plugin. Such directories have no plugin id, so they are identified by the path reported for them in
+// PluginsResponse.Errors, and only those paths may be removed.
+func (a *App) RemoveBrokenPlugin(path string) *model.AppError {
+	if a.Plugins == nil || !*a.Config().PluginSettings.Enable {
+		return model.NewAppError("RemoveBrokenPlugin", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	a.pluginInstallLock.Lock()
+	defer a.pluginInstallLock.Unlock()
+
+	_, pluginErrors, err := a.Plugins.AvailableWithErrors()
+	if err != nil {
+		return model.NewAppError("RemoveBrokenPlugin", "app.plugin.get_plugins.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	for _, pluginError := range pluginErrors {
+		if pluginError.Path != path {
+			continue
+		}
+
+		if err := os.RemoveAll(pluginError.Path); err != nil {
+			return model.NewAppError("RemoveBrokenPlugin", "app.plugin.remove.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		return nil
+	}
+
+	return model.NewAppError("RemoveBrokenPlugin", "app.plugin.remove_broken.not_found.app_error", nil, "path="+path, http.StatusBadRequest)
 }
 
-func (a *App) removePlugin(id string) *model.AppError {
+// GetPluginRemovalPreview reports everything that removing the given plugin would affect, without
+// removing anything.
+func (a *App) GetPluginRemovalPreview(id string) (*model.PluginRemovalInventory, *model.AppError) {
 	if a.Plugins == nil || !*a.Config().PluginSettings.Enable {
-		return model.NewAppError("removePlugin", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+		return nil, model.NewAppError("GetPluginRemovalPreview", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
+	inventory, _, err := a.getPluginRemovalInventory(id)
+	return inventory, err
+}
+
+// getPluginRemovalInventory collects everything belonging to the installed plugin that its removal
+// affects, along with its manifest. Previews and removals both rely on it so that they cannot diverge.
+func (a *App) getPluginRemovalInventory(id string) (*model.PluginRemovalInventory, *model.Manifest, *model.AppError) {
 	plugins, err := a.Plugins.Available()
 	if err != nil {
-		return model.NewAppError("removePlugin", "app.plugin.deactivate.app_error", nil, err.Error(), http.StatusBadRequest)
+		return nil, nil, model.NewAppError("getPluginRemovalInventory", "app.plugin.deactivate.app_error", nil, err.Error(), http.StatusBadRequest)
 	}
 
 	var manifest *model.Manifest
-	var pluginPath string
+	inventory := &model.PluginRemovalInventory{PluginId: id}
 	for _, p := range plugins {
-		if p.Manifest != nil && p.Manifest.Id == id {
+		if p.Manifest.Id == id {
 			manifest = p.Manifest
-			pluginPath = filepath.Dir(p.ManifestPath)
+			inventory.BundlePath = filepath.Dir(p.ManifestPath)
 			break
 		}
 	}
 
 	if manifest == nil {
-		return model.NewAppError("removePlugin", "app.plugin.not_installed.app_error", nil, "", http.StatusBadRequest)
+		return nil, nil, model.NewAppError("getPluginRemovalInventory", "app.plugin.not_installed.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if inventory.BundleSize, err = directorySize(inventory.BundlePath); err != nil {
+		return nil, nil, model.NewAppError("getPluginRemovalInventory", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if manifest.HasWebapp() {
+		inventory.WebappPath = a.Plugins.WebappPath(id)
+		if inventory.WebappSize, err = directorySize(inventory.WebappPath); err != nil {
+			return nil, nil, model.NewAppError("getPluginRemovalInventory", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+	}
+
+	usage, appErr := a.Srv.Store.Plugin().GetUsage(id)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	inventory.KeyValueUsage = usage
+
+	inventory.Commands = a.pluginCommandsForPlugin(id)
+
+	return inventory, manifest, nil
+}
+
+// removePlugin must be called with pluginInstallLock held.
+func (a *App) removePlugin(id string, deleteData bool) (*model.PluginRemovalInventory, *model.AppError) {
+	if a.Plugins == nil || !*a.Config().PluginSettings.Enable {
+		return nil, model.NewAppError("removePlugin", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	inventory, manifest, appErr := a.getPluginRemovalInventory(id)
+	if appErr != nil {
+		return nil, appErr
 	}
 
 	if a.Plugins.IsActive(id) && manifest.HasClient() {
@@ -120,12 +469,211 @@ func (a *App) removePlugin(id string) *model.AppError {
 		a.Publish(message)
 	}
 
-	a.Plugins.Deactivate(id)
+	if a.Plugins.Deactivate(id) {
+		a.notifyPluginsOfDeactivation(manifest)
+	}
+	a.UnregisterPluginCommands(id)
+	a.UnregisterPluginOAuthProvider(id)
+	a.clearPluginBanner(id)
+
+	if err := os.RemoveAll(inventory.BundlePath); err != nil {
+		return nil, model.NewAppError("removePlugin", "app.plugin.remove.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+	a.removePluginSignature(id)
+
+	if inventory.WebappPath != "" {
+		if err := os.RemoveAll(inventory.WebappPath); err != nil {
+			return nil, model.NewAppError("removePlugin", "app.plugin.remove.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+	}
+
+	// Subscriptions name channels that the plugin would no longer deliver to, so they are removed
+	// even when the plugin's data is kept.
+	if result := <-a.Srv.Store.PluginSubscription().DeleteAllForPlugin(id); result.Err != nil {
+		return nil, result.Err
+	}
+
+	if result := <-a.Srv.Store.PluginRuntimeState().DeleteAllForPlugin(id); result.Err != nil {
+		return nil, result.Err
+	}
+
+	// Users connected their accounts in external services to the plugin, so the tokens it acted on
+	// their behalf with are revoked even when the plugin's data is kept.
+	if result := <-a.Srv.Store.PluginOAuthConnection().DeleteAllForPlugin(id); result.Err != nil {
+		return nil, result.Err
+	}
 
-	err = os.RemoveAll(pluginPath)
+	// Admin actions requested by the plugin are not carried out once it is gone.
+	if result := <-a.Srv.Store.PluginAdminActionRequest().DeleteAllForPlugin(id); result.Err != nil {
+		return nil, result.Err
+	}
+
+	// Clients would otherwise keep rendering metadata that nothing maintains any longer. There may
+	// be many posts to update, so they are updated in the background.
+	a.Go(func() {
+		a.removeAllPostMetadataForPlugin(id)
+	})
+
+	if deleteData {
+		if _, err := a.Srv.Store.Plugin().DeleteAllForPlugin(id); err != nil {
+			return nil, err
+		}
+		if result := <-a.Srv.Store.Preference().DeleteAllInCategory(model.PluginPreferenceCategory(id)); result.Err != nil {
+			return nil, result.Err
+		}
+		inventory.DataDeleted = true
+	}
+
+	a.recordPluginEvent(id, model.PLUGIN_EVENT_REMOVED, fmt.Sprintf("version=%v data_deleted=%v", manifest.Version, inventory.DataDeleted))
+	a.setPluginRemoved(id, true)
+
+	return inventory, nil
+}
+
+// replacePlugin upgrades, downgrades or reinstalls the installed plugin in place with the extracted
+// bundle, keeping its enabled state and data unless the new version requests capabilities that the
+// previous one was not granted, in which case it is disabled pending review. The new bundle is copied next to the installed one
+// before the running instance is deactivated, and the two are swapped by renaming them, so that the
+// previous version is left installed and running should anything fail, including activating the new
+// version. It must be called with pluginInstallLock held.
+func (a *App) replacePlugin(existing *model.BundleInfo, manifest *model.Manifest, bundlePath string) *model.AppError {
+	pluginDir := *a.Config().PluginSettings.Directory
+	pluginPath := filepath.Join(pluginDir, manifest.Id)
+
+	switch comparePluginVersions(manifest.Version, existing.Manifest.Version) {
+	case 1:
+		mlog.Info("Upgrading plugin", mlog.String("plugin_id", manifest.Id), mlog.String("previous_version", existing.Manifest.Version), mlog.String("version", manifest.Version))
+	case -1:
+		mlog.Warn("Downgrading plugin", mlog.String("plugin_id", manifest.Id), mlog.String("previous_version", existing.Manifest.Version), mlog.String("version", manifest.Version))
+	default:
+		mlog.Info("Reinstalling plugin", mlog.String("plugin_id", manifest.Id), mlog.String("version", manifest.Version))
+	}
+
+	// Directories beginning with a dot are ignored when scanning for plugins, so neither copy is
+	// loaded as a second plugin with the same id while they are being swapped.
+	stagingPath := filepath.Join(pluginDir, "."+manifest.Id+".new")
+	previousPath := filepath.Join(pluginDir, "."+manifest.Id+".old")
+	os.RemoveAll(stagingPath)
+	os.RemoveAll(previousPath)
+
+	if err := utils.CopyDir(bundlePath, stagingPath); err != nil {
+		os.RemoveAll(stagingPath)
+		return model.NewAppError("replacePlugin", "app.plugin.mvdir.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+	defer os.RemoveAll(stagingPath)
+
+	wasRunning := a.Plugins.IsActive(manifest.Id) && a.Plugins.ActivationError(manifest.Id) == nil
+	a.deactivateReplacedPlugin(existing.Manifest)
+
+	// restore puts the previous version back, and restarts it if it was running.
+	restore := func(appErr *model.AppError) *model.AppError {
+		if wasRunning {
+			a.activateReplacedPlugin(manifest.Id)
+		}
+		return appErr
+	}
+
+	if err := os.Rename(existing.Path, previousPath); err != nil {
+		return restore(model.NewAppError("replacePlugin", "app.plugin.replace.app_error", nil, err.Error(), http.StatusInternalServerError))
+	}
+
+	if err := os.Rename(stagingPath, pluginPath); err != nil {
+		if restoreErr := os.Rename(previousPath, existing.Path); restoreErr != nil {
+			mlog.Error("Failed to restore the previous version of a plugin", mlog.String("plugin_id", manifest.Id), mlog.Err(restoreErr))
+		}
+		return restore(model.NewAppError("replacePlugin", "app.plugin.replace.app_error", nil, err.Error(), http.StatusInternalServerError))
+	}
+
+	if state, ok := a.Config().PluginSettings.PluginStates[manifest.Id]; ok && state.Enable {
+		// A plugin is not granted more capabilities by being upgraded until an admin enables it again.
+		if added := a.addedPluginCapabilities(existing.Manifest, manifest); len(added) > 0 {
+			mlog.Warn("Disabling upgraded plugin until the capabilities it requests are reviewed", mlog.String("plugin_id", manifest.Id), mlog.String("capabilities", strings.Join(added, ",")))
+			if appErr := a.disablePluginWithReason(manifest.Id, model.PLUGIN_STATE_REASON_PENDING_UPGRADE_REVIEW); appErr != nil {
+				mlog.Error("Failed to disable plugin", mlog.String("plugin_id", manifest.Id), mlog.Err(appErr))
+			}
+		} else if err := a.activateReplacedPlugin(manifest.Id); err != nil && wasRunning {
+			a.deactivateReplacedPlugin(manifest)
+			if renameErr := os.Rename(pluginPath, stagingPath); renameErr == nil {
+				renameErr = os.Rename(previousPath, existing.Path)
+				if renameErr != nil {
+					mlog.Error("Failed to restore the previous version of a plugin", mlog.String("plugin_id", manifest.Id), mlog.Err(renameErr))
+				}
+			}
+			return restore(model.NewAppError("replacePlugin", "app.plugin.replace.activate.app_error", nil, err.Error(), http.StatusBadRequest))
+		}
+	}
+
+	if err := os.RemoveAll(previousPath); err != nil {
+		mlog.Warn("Failed to remove the previous version of a plugin", mlog.String("plugin_id", manifest.Id), mlog.Err(err))
+	}
+
+	return nil
+}
+
+// deactivateReplacedPlugin deactivates the plugin if it is active, notifying clients and other plugins.
+func (a *App) deactivateReplacedPlugin(manifest *model.Manifest) {
+	if a.Plugins.IsActive(manifest.Id) && manifest.HasClient() {
+		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_PLUGIN_DISABLED, "", "", "", nil)
+		message.Add("manifest", manifest.ClientManifest())
+		a.Publish(message)
+	}
+
+	if a.Plugins.Deactivate(manifest.Id) {
+		a.notifyPluginsOfDeactivation(manifest)
+	}
+}
+
+// activateReplacedPlugin activates the installed version of the plugin, notifying clients and other
+// plugins.
+func (a *App) activateReplacedPlugin(id string) error {
+	manifest, activated, err := a.Plugins.Activate(id)
 	if err != nil {
-		return model.NewAppError("removePlugin", "app.plugin.remove.app_error", nil, err.Error(), http.StatusInternalServerError)
+		mlog.Error("Unable to activate plugin", mlog.String("plugin_id", id), mlog.Err(err))
+		return err
+	}
+
+	if activated && manifest.HasClient() {
+		a.publishPluginEnabled(manifest)
+	}
+	if activated {
+		a.notifyPluginsOfActivation(manifest)
 	}
 
 	return nil
 }
+
+// comparePluginVersions compares two plugin versions by their major, minor and patch numbers,
+// returning 1 if a is newer than b, -1 if it is older, and 0 otherwise.
+func comparePluginVersions(a, b string) int {
+	aMajor, aMinor, aPatch := model.SplitVersion(strings.TrimPrefix(a, "v"))
+	bMajor, bMinor, bPatch := model.SplitVersion(strings.TrimPrefix(b, "v"))
+
+	for _, diff := range []int64{aMajor - bMajor, aMinor - bMinor, aPatch - bPatch} {
+		if diff > 0 {
+			return 1
+		} else if diff < 0 {
+			return -1
+		}
+	}
+
+	return 0
+}
+
+// directorySize returns the total size of the regular files within the given directory, which
+// need not exist.
+func directorySize(path string) (int64, error) {
+	var size int64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	return size, err
+}
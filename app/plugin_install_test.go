@@ -0,0 +1,580 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+func TestComparePluginVersions(t *testing.T) {
+	for _, tc := range []struct {
+		A, B     string
+		Expected int
+	}{
+		{"0.0.2", "0.0.1", 1},
+		{"0.2.0", "0.1.9", 1},
+		{"v2.0.0", "1.9.9", 1},
+		{"0.0.1", "0.0.2", -1},
+		{"1.0.0", "1.0.0", 0},
+		{"", "", 0},
+	} {
+		assert.Equal(t, tc.Expected, comparePluginVersions(tc.A, tc.B), "%v vs %v", tc.A, tc.B)
+	}
+}
+
+func TestInstallPluginReplace(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginDir)
+	webappPluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(webappPluginDir)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.Directory = pluginDir
+		*cfg.PluginSettings.ClientDirectory = webappPluginDir
+	})
+
+	env, err := plugin.NewEnvironment(th.App.NewPluginAPI, pluginDir, webappPluginDir, th.App.Log)
+	require.NoError(t, err)
+	th.App.Plugins = env
+
+	binaryDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(binaryDir)
+
+	for name, onActivate := range map[string]string{
+		"good": "return nil",
+		"bad":  `return fmt.Errorf("failed to activate")`,
+	} {
+		compileGo(t, `
+			package main
+
+			import (
+				"fmt"
+
+				"github.com/mattermost/mattermost-server/plugin"
+			)
+
+			var _ = fmt.Errorf
+
+			type MyPlugin struct {
+				plugin.MattermostPlugin
+			}
+
+			func (p *MyPlugin) OnActivate() error {
+				`+onActivate+`
+			}
+
+			func main() {
+				plugin.ClientMain(&MyPlugin{})
+			}
+		`, filepath.Join(binaryDir, name))
+	}
+
+	makeBundle := func(version, binary string, capabilities ...string) *bytes.Buffer {
+		executable, err := ioutil.ReadFile(filepath.Join(binaryDir, binary))
+		require.NoError(t, err)
+
+		manifest := &model.Manifest{
+			Id:           "testplugin",
+			Version:      version,
+			Backend:      &model.ManifestServer{Executable: "backend.exe"},
+			Capabilities: capabilities,
+		}
+
+		var bundle bytes.Buffer
+		gzipWriter := gzip.NewWriter(&bundle)
+		tarWriter := tar.NewWriter(gzipWriter)
+		for name, file := range map[string]struct {
+			Contents []byte
+			Mode     int64
+		}{
+			"plugin.json": {[]byte(manifest.ToJson()), 0600},
+			"backend.exe": {executable, 0700},
+		} {
+			require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: file.Mode, Size: int64(len(file.Contents)), Typeflag: tar.TypeReg}))
+			_, err = tarWriter.Write(file.Contents)
+			require.NoError(t, err)
+		}
+		require.NoError(t, tarWriter.Close())
+		require.NoError(t, gzipWriter.Close())
+
+		return &bundle
+	}
+
+	installedVersion := func() string {
+		manifest, _, err := model.FindManifest(filepath.Join(pluginDir, "testplugin"))
+		require.NoError(t, err)
+		return manifest.Version
+	}
+
+	_, appErr := th.App.InstallPlugin(makeBundle("0.0.1", "good"), nil, false)
+	require.Nil(t, appErr)
+	require.Nil(t, th.App.EnablePlugin("testplugin"))
+	require.True(t, th.App.Plugins.IsActive("testplugin"))
+	require.Nil(t, th.App.SetPluginKey("testplugin", "key", []byte("value")))
+
+	t.Run("not replacing", func(t *testing.T) {
+		_, appErr := th.App.InstallPlugin(makeBundle("0.0.2", "good"), nil, false)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.plugin.install_id.app_error", appErr.Id)
+		assert.Equal(t, "0.0.1", installedVersion())
+	})
+
+	t.Run("upgrade", func(t *testing.T) {
+		manifest, appErr := th.App.InstallPlugin(makeBundle("0.0.2", "good"), nil, true)
+		require.Nil(t, appErr)
+		assert.Equal(t, "0.0.2", manifest.Version)
+		assert.Equal(t, "0.0.2", installedVersion())
+		assert.True(t, th.App.Plugins.IsActive("testplugin"))
+		assert.Nil(t, th.App.Plugins.ActivationError("testplugin"))
+		assert.True(t, th.App.Config().PluginSettings.PluginStates["testplugin"].Enable)
+
+		// The plugin's data is kept.
+		value, appErr := th.App.GetPluginKey("testplugin", "key")
+		require.Nil(t, appErr)
+		assert.Equal(t, []byte("value"), value)
+	})
+
+	t.Run("failed activation restores the previous version", func(t *testing.T) {
+		_, appErr := th.App.InstallPlugin(makeBundle("0.0.3", "bad"), nil, true)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.plugin.replace.activate.app_error", appErr.Id)
+		assert.Equal(t, "0.0.2", installedVersion())
+		assert.True(t, th.App.Plugins.IsActive("testplugin"))
+		assert.Nil(t, th.App.Plugins.ActivationError("testplugin"))
+	})
+
+	t.Run("upgrade requesting more capabilities is held for review", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.DefaultCapabilities = model.PLUGIN_SETTINGS_DEFAULT_CAPABILITIES_NONE
+		})
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.DefaultCapabilities = model.PLUGIN_SETTINGS_DEFAULT_CAPABILITIES_ALL
+		})
+
+		_, appErr := th.App.InstallPlugin(makeBundle("0.0.4", "good", model.PLUGIN_CAPABILITY_KV), nil, true)
+		require.Nil(t, appErr)
+		assert.Equal(t, "0.0.4", installedVersion())
+		assert.False(t, th.App.Plugins.IsActive("testplugin"))
+
+		state := th.App.Config().PluginSettings.PluginStates["testplugin"]
+		assert.False(t, state.Enable)
+		assert.Equal(t, model.PLUGIN_STATE_REASON_PENDING_UPGRADE_REVIEW, state.Reason)
+
+		require.Nil(t, th.App.EnablePlugin("testplugin"))
+		assert.True(t, th.App.Plugins.IsActive("testplugin"))
+		assert.Equal(t, model.PLUGIN_STATE_REASON_ADMIN, th.App.Config().PluginSettings.PluginStates["testplugin"].Reason)
+	})
+
+	t.Run("disabled plugin is not activated", func(t *testing.T) {
+		require.Nil(t, th.App.DisablePlugin("testplugin"))
+
+		_, appErr := th.App.InstallPlugin(makeBundle("0.0.1", "good"), nil, true)
+		require.Nil(t, appErr)
+		assert.Equal(t, "0.0.1", installedVersion())
+		assert.False(t, th.App.Plugins.IsActive("testplugin"))
+	})
+
+	// Nothing is left behind from the swaps.
+	entries, err := ioutil.ReadDir(pluginDir)
+	require.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "testplugin", entries[0].Name())
+	}
+}
+
+func TestInstallPluginArchiveFormats(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginDir)
+	webappPluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(webappPluginDir)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.Directory = pluginDir
+		*cfg.PluginSettings.ClientDirectory = webappPluginDir
+	})
+
+	env, err := plugin.NewEnvironment(th.App.NewPluginAPI, pluginDir, webappPluginDir, th.App.Log)
+	require.NoError(t, err)
+	th.App.Plugins = env
+
+	files := map[string]string{
+		"plugin.json":    `{"id": "testplugin", "webapp": {"bundle_path": "webapp/main.js"}}`,
+		"webapp/main.js": "main",
+	}
+
+	makeTarGz := func() *bytes.Buffer {
+		var bundle bytes.Buffer
+		gzipWriter := gzip.NewWriter(&bundle)
+		tarWriter := tar.NewWriter(gzipWriter)
+		for name, contents := range files {
+			require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "testplugin/" + name, Mode: 0600, Size: int64(len(contents)), Typeflag: tar.TypeReg}))
+			_, err = tarWriter.Write([]byte(contents))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tarWriter.Close())
+		require.NoError(t, gzipWriter.Close())
+		return &bundle
+	}
+
+	makeZip := func(separator string) *bytes.Buffer {
+		var bundle bytes.Buffer
+		zipWriter := zip.NewWriter(&bundle)
+		for name, contents := range files {
+			writer, err := zipWriter.Create(strings.Join(append([]string{"testplugin"}, strings.Split(name, "/")...), separator))
+			require.NoError(t, err)
+			_, err = writer.Write([]byte(contents))
+			require.NoError(t, err)
+		}
+		require.NoError(t, zipWriter.Close())
+		return &bundle
+	}
+
+	for name, bundle := range map[string]*bytes.Buffer{
+		"tar.gz":         makeTarGz(),
+		"zip":            makeZip("/"),
+		"zip on windows": makeZip("\\"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			manifest, appErr := th.App.InstallPlugin(bundle, nil, true)
+			require.Nil(t, appErr)
+			assert.Equal(t, "testplugin", manifest.Id)
+
+			// The plugin's single directory is installed as the plugin, whatever the format.
+			contents, err := ioutil.ReadFile(filepath.Join(pluginDir, "testplugin", "webapp", "main.js"))
+			require.NoError(t, err)
+			assert.Equal(t, []byte("main"), contents)
+
+			entries, err := ioutil.ReadDir(pluginDir)
+			require.NoError(t, err)
+			if assert.Len(t, entries, 1) {
+				assert.Equal(t, "testplugin", entries[0].Name())
+			}
+		})
+	}
+}
+
+func TestInstallPluginSizeLimits(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginDir)
+	webappPluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(webappPluginDir)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.Directory = pluginDir
+		*cfg.PluginSettings.ClientDirectory = webappPluginDir
+		*cfg.PluginSettings.MaxBundleSize = 1024 * 1024
+		*cfg.PluginSettings.MaxExtractedSize = 10 * 1024 * 1024
+	})
+
+	env, err := plugin.NewEnvironment(th.App.NewPluginAPI, pluginDir, webappPluginDir, th.App.Log)
+	require.NoError(t, err)
+	th.App.Plugins = env
+
+	// Bundles are extracted to a temporary directory, which must be removed however extraction fails.
+	tmpDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	defer os.Setenv("TMPDIR", os.Getenv("TMPDIR"))
+	os.Setenv("TMPDIR", tmpDir)
+
+	manifest := []byte(`{"id": "testplugin", "webapp": {"bundle_path": "main.js"}}`)
+
+	// makeTarGz bundles the manifest with a main.js of the given size, written in chunks from
+	// chunk, so that a few kilobytes of zeros can expand past any limit.
+	makeTarGz := func(chunk []byte, size int) *bytes.Buffer {
+		var bundle bytes.Buffer
+		gzipWriter, err := gzip.NewWriterLevel(&bundle, gzip.BestCompression)
+		require.NoError(t, err)
+		tarWriter := tar.NewWriter(gzipWriter)
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "plugin.json", Mode: 0600, Size: int64(len(manifest)), Typeflag: tar.TypeReg}))
+		_, err = tarWriter.Write(manifest)
+		require.NoError(t, err)
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "main.js", Mode: 0600, Size: int64(size), Typeflag: tar.TypeReg}))
+		for written := 0; written < size; written += len(chunk) {
+			_, err = tarWriter.Write(chunk)
+			require.NoError(t, err)
+		}
+		require.NoError(t, tarWriter.Close())
+		require.NoError(t, gzipWriter.Close())
+		return &bundle
+	}
+
+	makeZip := func(chunk []byte, size int) *bytes.Buffer {
+		var bundle bytes.Buffer
+		zipWriter := zip.NewWriter(&bundle)
+		writer, err := zipWriter.Create("plugin.json")
+		require.NoError(t, err)
+		_, err = writer.Write(manifest)
+		require.NoError(t, err)
+		writer, err = zipWriter.Create("main.js")
+		require.NoError(t, err)
+		for written := 0; written < size; written += len(chunk) {
+			_, err = writer.Write(chunk)
+			require.NoError(t, err)
+		}
+		require.NoError(t, zipWriter.Close())
+		return &bundle
+	}
+
+	zeros := make([]byte, 64*1024)
+	random := make([]byte, 64*1024)
+	_, err = rand.Read(random)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		Bundle *bytes.Buffer
+		Error  string
+	}{
+		"tar.gz bomb":       {makeTarGz(zeros, 64*1024*1024), "app.plugin.install.extracted_too_large.app_error"},
+		"zip bomb":          {makeZip(zeros, 64*1024*1024), "app.plugin.install.extracted_too_large.app_error"},
+		"large tar.gz":      {makeTarGz(random, 2*1024*1024), "app.plugin.install.bundle_too_large.app_error"},
+		"large zip":         {makeZip(random, 2*1024*1024), "app.plugin.install.bundle_too_large.app_error"},
+		"tar.gz over limit": {makeTarGz(zeros, 10*1024*1024+1), "app.plugin.install.extracted_too_large.app_error"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if tc.Error == "app.plugin.install.extracted_too_large.app_error" {
+				// The bundle itself is well within the limit.
+				require.True(t, tc.Bundle.Len() < 1024*1024)
+			}
+
+			_, appErr := th.App.InstallPlugin(tc.Bundle, nil, false)
+			require.NotNil(t, appErr)
+			assert.Equal(t, tc.Error, appErr.Id)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, appErr.StatusCode)
+
+			entries, err := ioutil.ReadDir(tmpDir)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+
+			entries, err = ioutil.ReadDir(pluginDir)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+
+	t.Run("within limits", func(t *testing.T) {
+		_, appErr := th.App.InstallPlugin(makeTarGz(zeros, 9*1024*1024), nil, false)
+		require.Nil(t, appErr)
+
+		entries, err := ioutil.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestInstallPluginInvalidManifest(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginDir)
+	webappPluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(webappPluginDir)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.Directory = pluginDir
+	})
+
+	env, err := plugin.NewEnvironment(th.App.NewPluginAPI, pluginDir, webappPluginDir, th.App.Log)
+	require.NoError(t, err)
+	th.App.Plugins = env
+
+	for name, tc := range map[string]struct {
+		Files map[string]string
+		Error string
+	}{
+		"invalid id":         {map[string]string{"plugin.json": `{"id": "../plugin", "webapp": {"bundle_path": "main.js"}}`, "main.js": ""}, "model.manifest.is_valid.id.app_error"},
+		"invalid version":    {map[string]string{"plugin.json": `{"id": "testplugin", "version": "latest", "webapp": {"bundle_path": "main.js"}}`, "main.js": ""}, "model.manifest.is_valid.version.app_error"},
+		"no components":      {map[string]string{"plugin.json": `{"id": "testplugin"}`}, "model.manifest.is_valid.components.app_error"},
+		"missing executable": {map[string]string{"plugin.json": `{"id": "testplugin", "server": {"executable": "plugin.exe"}}`}, "app.plugin.validate.executable.app_error"},
+		"missing bundle":     {map[string]string{"plugin.json": `{"id": "testplugin", "webapp": {"bundle_path": "main.js"}}`}, "app.plugin.validate.webapp_bundle.app_error"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var bundle bytes.Buffer
+			gzipWriter := gzip.NewWriter(&bundle)
+			tarWriter := tar.NewWriter(gzipWriter)
+			for name, contents := range tc.Files {
+				require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(contents)), Typeflag: tar.TypeReg}))
+				_, err = tarWriter.Write([]byte(contents))
+				require.NoError(t, err)
+			}
+			require.NoError(t, tarWriter.Close())
+			require.NoError(t, gzipWriter.Close())
+
+			_, appErr := th.App.InstallPlugin(&bundle, nil, false)
+			require.NotNil(t, appErr)
+			assert.Equal(t, tc.Error, appErr.Id)
+			assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+
+			// Nothing is installed.
+			entries, err := ioutil.ReadDir(pluginDir)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
+
+func TestInstallPluginUnsafeEntries(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginDir)
+	webappPluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(webappPluginDir)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.Directory = pluginDir
+	})
+
+	env, err := plugin.NewEnvironment(th.App.NewPluginAPI, pluginDir, webappPluginDir, th.App.Log)
+	require.NoError(t, err)
+	th.App.Plugins = env
+
+	for name, header := range map[string]*tar.Header{
+		"traverses upwards": {Name: "../../etc/cron.d/x", Typeflag: tar.TypeReg},
+		"absolute path":     {Name: "/etc/cron.d/x", Typeflag: tar.TypeReg},
+		"escaping symlink":  {Name: "testplugin/link", Linkname: "../../etc", Typeflag: tar.TypeSymlink},
+		"hard link":         {Name: "testplugin/link", Linkname: "testplugin/plugin.json", Typeflag: tar.TypeLink},
+		"fifo":              {Name: "testplugin/fifo", Typeflag: tar.TypeFifo},
+	} {
+		t.Run(name, func(t *testing.T) {
+			manifest := `{"id": "testplugin", "webapp": {"bundle_path": "main.js"}}`
+
+			var bundle bytes.Buffer
+			gzipWriter := gzip.NewWriter(&bundle)
+			tarWriter := tar.NewWriter(gzipWriter)
+			require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "testplugin/plugin.json", Mode: 0600, Size: int64(len(manifest)), Typeflag: tar.TypeReg}))
+			_, err = tarWriter.Write([]byte(manifest))
+			require.NoError(t, err)
+			header.Mode = 0600
+			require.NoError(t, tarWriter.WriteHeader(header))
+			require.NoError(t, tarWriter.Close())
+			require.NoError(t, gzipWriter.Close())
+
+			_, appErr := th.App.InstallPlugin(&bundle, nil, false)
+			require.NotNil(t, appErr)
+			assert.Equal(t, "app.plugin.extract.unsafe_entry.app_error", appErr.Id)
+			assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+			assert.Contains(t, appErr.DetailedError, header.Name)
+
+			entries, err := ioutil.ReadDir(pluginDir)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
+
+func TestInstallPluginMinServerVersion(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginDir)
+	webappPluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(webappPluginDir)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.Directory = pluginDir
+	})
+
+	env, err := plugin.NewEnvironment(th.App.NewPluginAPI, pluginDir, webappPluginDir, th.App.Log)
+	require.NoError(t, err)
+	th.App.Plugins = env
+
+	makeBundle := func(minServerVersion string) *bytes.Buffer {
+		var bundle bytes.Buffer
+		gzipWriter := gzip.NewWriter(&bundle)
+		tarWriter := tar.NewWriter(gzipWriter)
+		for name, contents := range map[string]string{
+			"plugin.json": `{"id": "testplugin", "min_server_version": "` + minServerVersion + `", "webapp": {"bundle_path": "main.js"}}`,
+			"main.js":     "",
+		} {
+			require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(contents)), Typeflag: tar.TypeReg}))
+			_, err = tarWriter.Write([]byte(contents))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tarWriter.Close())
+		require.NoError(t, gzipWriter.Close())
+		return &bundle
+	}
+
+	buildNumber := model.BuildNumber
+	defer func() { model.BuildNumber = buildNumber }()
+	model.BuildNumber = "1234"
+
+	t.Run("server too old", func(t *testing.T) {
+		_, appErr := th.App.InstallPlugin(makeBundle("99.0.0"), nil, false)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.plugin.min_server_version.app_error", appErr.Id)
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+
+		entries, err := ioutil.ReadDir(pluginDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	for name, minServerVersion := range map[string]string{
+		"exact": model.CurrentVersion,
+		"older": "4.0.0",
+	} {
+		t.Run(name, func(t *testing.T) {
+			manifest, appErr := th.App.InstallPlugin(makeBundle(minServerVersion), nil, true)
+			require.Nil(t, appErr)
+			assert.Equal(t, minServerVersion, manifest.MinServerVersion)
+		})
+	}
+
+	t.Run("development build", func(t *testing.T) {
+		model.BuildNumber = "dev"
+		defer func() { model.BuildNumber = "1234" }()
+
+		_, appErr := th.App.InstallPlugin(makeBundle("99.0.0"), nil, true)
+		require.Nil(t, appErr)
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+const (
+	PLUGIN_KEY_VALUE_CHANGE_RATE_PER_SEC      = 10
+	PLUGIN_KEY_VALUE_CHANGE_RATE_MAX_BURST    = 100
+	PLUGIN_KEY_VALUE_CHANGE_MEMORY_STORE_SIZE = 1000
+)
+
+func (a *App) getPluginKeyValueChangeRateLimiter() *RateLimiter {
+	a.pluginKeyValueChangeRateLimiterOnce.Do(func() {
+		rateLimiter, err := NewRateLimiter(&model.RateLimitSettings{
+			PerSec:           model.NewInt(PLUGIN_KEY_VALUE_CHANGE_RATE_PER_SEC),
+			MaxBurst:         model.NewInt(PLUGIN_KEY_VALUE_CHANGE_RATE_MAX_BURST),
+			MemoryStoreSize:  model.NewInt(PLUGIN_KEY_VALUE_CHANGE_MEMORY_STORE_SIZE),
+			VaryByRemoteAddr: model.NewBool(false),
+			VaryByUser:       model.NewBool(false),
+		})
+		if err != nil {
+			mlog.Error("Unable to create plugin key-value change rate limiter", mlog.Err(err))
+			return
+		}
+		a.pluginKeyValueChangeRateLimiter = rateLimiter
+	})
+
+	return a.pluginKeyValueChangeRateLimiter
+}
+
+// notifyPluginOfKeyValueChange invokes the KVHasChanged hook of the given plugin on every server of
+// the cluster. Notifications are best-effort, so those beyond the plugin's rate limit are dropped.
+func (a *App) notifyPluginOfKeyValueChange(pluginId, key string) {
+	if !a.PluginsReady() || !a.Plugins.IsHookImplemented(plugin.KVHasChangedId) {
+		return
+	}
+
+	if rateLimiter := a.getPluginKeyValueChangeRateLimiter(); rateLimiter == nil || rateLimiter.IsLimited(pluginId) {
+		mlog.Debug("Dropped plugin key-value change notification", mlog.String("plugin_id", pluginId))
+		return
+	}
+
+	a.notifyPluginOfKeyValueChangeSkipClusterSend(pluginId, key)
+
+	if a.Cluster != nil {
+		a.Cluster.SendClusterMessage(&model.ClusterMessage{
+			Event:    model.CLUSTER_EVENT_PLUGIN_KEY_VALUE_HAS_CHANGED,
+			SendType: model.CLUSTER_SEND_BEST_EFFORT,
+			Props: map[string]string{
+				"plugin_id": pluginId,
+				"key":       key,
+			},
+		})
+	}
+}
+
+func (a *App) notifyPluginOfKeyValueChangeSkipClusterSend(pluginId, key string) {
+	if !a.PluginsReady() {
+		return
+	}
+
+	a.Go(func() {
+		hooks, err := a.Plugins.HooksForPlugin(pluginId)
+		if err != nil {
+			mlog.Debug("Unable to notify plugin of key-value change", mlog.String("plugin_id", pluginId), mlog.Err(err))
+			return
+		}
+
+		hooks.KVHasChanged(key)
+	})
+}
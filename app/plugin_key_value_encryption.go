@@ -0,0 +1,249 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// PLUGIN_KEY_VALUE_REENCRYPTION_INTERVAL is how often plugin key-value pairs encrypted with a previous
+// key are re-encrypted with the current one.
+const PLUGIN_KEY_VALUE_REENCRYPTION_INTERVAL = 1 * time.Hour
+
+// pluginKeyValueEncryptedPrefix marks values stored encrypted by SetPluginKey, and is followed by the
+// id of the key they were encrypted with, the nonce and the sealed value. Its last byte is the
+// version of that encoding. Values are compressed, if at all, before they are encrypted.
+var pluginKeyValueEncryptedPrefix = []byte("\x00MMKVE\x01")
+
+// pluginKeyValueEncryptionKeyIdSize is the length of the id identifying the key a value was encrypted
+// with, so that values encrypted before the key was rotated can still be read.
+const pluginKeyValueEncryptionKeyIdSize = 8
+
+var errPluginKeyValueUnknownEncryptionKey = errors.New("value was encrypted with an unknown key")
+
+// pluginKeyValueEncryptionKey encrypts plugin key-value pairs with AES-256-GCM, using a key derived
+// from one configured in PluginSettings.
+type pluginKeyValueEncryptionKey struct {
+	id   []byte
+	aead cipher.AEAD
+}
+
+func newPluginKeyValueEncryptionKey(secret string) (*pluginKeyValueEncryptionKey, error) {
+	key := sha256.Sum256([]byte(secret))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// The id is derived from the key rather than the configured secret, so it reveals neither.
+	id := sha256.Sum256(key[:])
+
+	return &pluginKeyValueEncryptionKey{
+		id:   id[:pluginKeyValueEncryptionKeyIdSize],
+		aead: aead,
+	}, nil
+}
+
+// encryptPluginKeyValue encrypts a value encoded by encodePluginKeyValue.
+func encryptPluginKeyValue(value []byte, key *pluginKeyValueEncryptionKey) ([]byte, error) {
+	header := make([]byte, 0, len(pluginKeyValueEncryptedPrefix)+len(key.id))
+	header = append(header, pluginKeyValueEncryptedPrefix...)
+	header = append(header, key.id...)
+
+	nonce := make([]byte, key.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "unable to generate nonce")
+	}
+
+	stored := append(header, nonce...)
+	return key.aead.Seal(stored, nonce, value, header), nil
+}
+
+// decryptPluginKeyValue reverses encryptPluginKeyValue, using whichever of keys the value was
+// encrypted with. Values that are not encrypted are returned as is, while those that have been
+// tampered with are rejected.
+func decryptPluginKeyValue(stored []byte, keys []*pluginKeyValueEncryptionKey) ([]byte, error) {
+	if !bytes.HasPrefix(stored, pluginKeyValueEncryptedPrefix) {
+		return stored, nil
+	}
+
+	headerSize := len(pluginKeyValueEncryptedPrefix) + pluginKeyValueEncryptionKeyIdSize
+	if len(stored) < headerSize {
+		return nil, errors.New("encrypted value is truncated")
+	}
+	header, id := stored[:headerSize], stored[len(pluginKeyValueEncryptedPrefix):headerSize]
+
+	for _, key := range keys {
+		if !bytes.Equal(key.id, id) {
+			continue
+		}
+
+		nonceSize := key.aead.NonceSize()
+		if len(stored) < headerSize+nonceSize {
+			return nil, errors.New("encrypted value is truncated")
+		}
+		nonce, sealed := stored[headerSize:headerSize+nonceSize], stored[headerSize+nonceSize:]
+
+		value, err := key.aead.Open([]byte{}, nonce, sealed, header)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to decrypt value")
+		}
+		return value, nil
+	}
+
+	return nil, errPluginKeyValueUnknownEncryptionKey
+}
+
+// pluginKeyValueEncryptionKeyId returns the id of the key a stored value was encrypted with, or nil
+// if it is not encrypted.
+func pluginKeyValueEncryptionKeyId(stored []byte) []byte {
+	headerSize := len(pluginKeyValueEncryptedPrefix) + pluginKeyValueEncryptionKeyIdSize
+	if !bytes.HasPrefix(stored, pluginKeyValueEncryptedPrefix) || len(stored) < headerSize {
+		return nil
+	}
+
+	return stored[len(pluginKeyValueEncryptedPrefix):headerSize]
+}
+
+// pluginKeyValueEncryptionKeys returns the key with which plugin key-value pairs are encrypted, or
+// nil if they are not, along with every key with which they may have been encrypted, including
+// those configured before the key was rotated.
+func (a *App) pluginKeyValueEncryptionKeys() (*pluginKeyValueEncryptionKey, []*pluginKeyValueEncryptionKey, *model.AppError) {
+	settings := a.Config().PluginSettings
+
+	var current *pluginKeyValueEncryptionKey
+	var keys []*pluginKeyValueEncryptionKey
+	for i, secret := range append([]string{*settings.KeyValueEncryptionKey}, settings.PreviousKeyValueEncryptionKeys...) {
+		if secret == "" {
+			continue
+		}
+
+		key, err := newPluginKeyValueEncryptionKey(secret)
+		if err != nil {
+			return nil, nil, model.NewAppError("pluginKeyValueEncryptionKeys", "app.plugin.key_value.encryption_key.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		if i == 0 {
+			current = key
+		}
+		keys = append(keys, key)
+	}
+
+	return current, keys, nil
+}
+
+// encodeStoredPluginKeyValue encodes the value according to the compression settings, then encrypts
+// it if an encryption key is configured.
+func (a *App) encodeStoredPluginKeyValue(value []byte) ([]byte, *model.AppError) {
+	settings := a.Config().PluginSettings
+	stored := encodePluginKeyValue(value, *settings.EnableKeyValueCompression, *settings.KeyValueCompressionThreshold)
+
+	key, _, appErr := a.pluginKeyValueEncryptionKeys()
+	if appErr != nil || key == nil {
+		return stored, appErr
+	}
+
+	stored, err := encryptPluginKeyValue(stored, key)
+	if err != nil {
+		return nil, model.NewAppError("encodeStoredPluginKeyValue", "app.plugin.key_value.encrypt.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return stored, nil
+}
+
+// decodeStoredPluginKeyValue reverses encodeStoredPluginKeyValue, failing if the value was encrypted
+// with a key that is no longer configured or has been tampered with.
+func (a *App) decodeStoredPluginKeyValue(stored []byte) ([]byte, *model.AppError) {
+	_, keys, appErr := a.pluginKeyValueEncryptionKeys()
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	decrypted, err := decryptPluginKeyValue(stored, keys)
+	if err != nil {
+		return nil, model.NewAppError("decodeStoredPluginKeyValue", "app.plugin.key_value.decrypt.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return decodePluginKeyValue(decrypted), nil
+}
+
+// ReencryptPluginKeyValues re-encrypts the plugin key-value pairs encrypted with any of
+// PluginSettings.PreviousKeyValueEncryptionKeys with the current key, or decrypts them if encryption
+// has since been disabled, so that the previous keys can eventually be removed. Pairs written
+// concurrently are left alone, having already been written with the current key.
+func (a *App) ReencryptPluginKeyValues() {
+	if len(a.Config().PluginSettings.PreviousKeyValueEncryptionKeys) == 0 {
+		return
+	}
+
+	current, _, appErr := a.pluginKeyValueEncryptionKeys()
+	if appErr != nil {
+		mlog.Error("Failed to re-encrypt plugin key-value pairs", mlog.Err(appErr))
+		return
+	}
+
+	reencrypted := 0
+	for offset := 0; ; offset += PLUGIN_DATA_EXPORT_BATCH_SIZE {
+		kvs, err := a.Srv.Store.Plugin().GetAll(offset, PLUGIN_DATA_EXPORT_BATCH_SIZE)
+		if err != nil {
+			mlog.Error("Failed to re-encrypt plugin key-value pairs", mlog.Err(err))
+			return
+		}
+
+		for _, kv := range kvs {
+			id := pluginKeyValueEncryptionKeyId(kv.Value)
+			if id == nil || (current != nil && bytes.Equal(id, current.id)) {
+				continue
+			}
+
+			if set, err := a.reencryptPluginKeyValue(kv); err != nil {
+				mlog.Error("Failed to re-encrypt plugin key-value pair", mlog.String("plugin_id", kv.PluginId), mlog.Err(err))
+			} else if set {
+				reencrypted++
+			}
+		}
+
+		if len(kvs) < PLUGIN_DATA_EXPORT_BATCH_SIZE {
+			break
+		}
+	}
+
+	if reencrypted > 0 {
+		mlog.Info("Re-encrypted plugin key-value pairs", mlog.Int("count", reencrypted))
+	}
+}
+
+func (a *App) reencryptPluginKeyValue(kv *model.PluginKeyValue) (bool, *model.AppError) {
+	value, err := a.decodeStoredPluginKeyValue(kv.Value)
+	if err != nil {
+		return false, err
+	}
+
+	storedValue, err := a.encodeStoredPluginKeyValue(value)
+	if err != nil {
+		return false, err
+	}
+
+	reencrypted := *kv
+	reencrypted.Value = storedValue
+
+	return a.compareAndSetStoredPluginKey(&reencrypted, kv.Value)
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func newTestPluginKeyValueEncryptionKey(t *testing.T) *pluginKeyValueEncryptionKey {
+	key, err := newPluginKeyValueEncryptionKey(model.NewRandomString(32))
+	require.NoError(t, err)
+	return key
+}
+
+func TestEncryptPluginKeyValue(t *testing.T) {
+	key := newTestPluginKeyValueEncryptionKey(t)
+	previousKey := newTestPluginKeyValueEncryptionKey(t)
+	keys := []*pluginKeyValueEncryptionKey{key, previousKey}
+
+	for name, value := range map[string][]byte{
+		"value":            []byte("value"),
+		"empty value":      {},
+		"compressed value": encodePluginKeyValue(bytes.Repeat([]byte("compressible "), 200), true, 0),
+	} {
+		t.Run(name, func(t *testing.T) {
+			stored, err := encryptPluginKeyValue(value, key)
+			require.NoError(t, err)
+			assert.True(t, bytes.HasPrefix(stored, pluginKeyValueEncryptedPrefix))
+			assert.Equal(t, key.id, pluginKeyValueEncryptionKeyId(stored))
+
+			decrypted, err := decryptPluginKeyValue(stored, keys)
+			require.NoError(t, err)
+			assert.Equal(t, value, decrypted)
+		})
+	}
+
+	t.Run("encryptions differ", func(t *testing.T) {
+		first, err := encryptPluginKeyValue([]byte("value"), key)
+		require.NoError(t, err)
+		second, err := encryptPluginKeyValue([]byte("value"), key)
+		require.NoError(t, err)
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("previous key", func(t *testing.T) {
+		stored, err := encryptPluginKeyValue([]byte("value"), previousKey)
+		require.NoError(t, err)
+
+		decrypted, err := decryptPluginKeyValue(stored, keys)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), decrypted)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		stored, err := encryptPluginKeyValue([]byte("value"), newTestPluginKeyValueEncryptionKey(t))
+		require.NoError(t, err)
+
+		_, err = decryptPluginKeyValue(stored, keys)
+		assert.Equal(t, errPluginKeyValueUnknownEncryptionKey, err)
+
+		_, err = decryptPluginKeyValue(stored, nil)
+		assert.Equal(t, errPluginKeyValueUnknownEncryptionKey, err)
+	})
+
+	t.Run("unencrypted value", func(t *testing.T) {
+		for _, value := range [][]byte{[]byte("value"), {}, nil} {
+			decrypted, err := decryptPluginKeyValue(value, keys)
+			require.NoError(t, err)
+			assert.Equal(t, value, decrypted)
+			assert.Nil(t, pluginKeyValueEncryptionKeyId(value))
+		}
+	})
+}
+
+func TestDecryptPluginKeyValueTampered(t *testing.T) {
+	key := newTestPluginKeyValueEncryptionKey(t)
+	keys := []*pluginKeyValueEncryptionKey{key}
+
+	stored, err := encryptPluginKeyValue([]byte("value"), key)
+	require.NoError(t, err)
+
+	// Flipping any bit after the prefix, whether of the key id, nonce, value or tag, must be detected.
+	for i := len(pluginKeyValueEncryptedPrefix); i < len(stored); i++ {
+		tampered := append([]byte{}, stored...)
+		tampered[i] ^= 0x01
+
+		_, err := decryptPluginKeyValue(tampered, keys)
+		assert.Error(t, err, "byte %d", i)
+	}
+
+	for i := len(pluginKeyValueEncryptedPrefix); i < len(stored); i++ {
+		_, err := decryptPluginKeyValue(stored[:i], keys)
+		assert.Error(t, err, "truncated to %d bytes", i)
+	}
+
+	_, err = decryptPluginKeyValue(append(append([]byte{}, stored...), 0), keys)
+	assert.Error(t, err)
+}
+
+func TestPluginKeyValueEncryption(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+	previousKey := model.NewRandomString(32)
+
+	getStored := func(key string) []byte {
+		kv, err := th.App.Srv.Store.Plugin().Get(pluginId, key)
+		require.Nil(t, err)
+		return kv.Value
+	}
+
+	// Written before encryption was enabled.
+	require.Nil(t, th.App.SetPluginKey(pluginId, "plaintext", []byte("plaintext value")))
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.KeyValueEncryptionKey = previousKey })
+
+	// Values that look encrypted must still be read back unchanged.
+	prefixed := append(append([]byte{}, pluginKeyValueEncryptedPrefix...), []byte("not encrypted")...)
+	kvs := map[string][]byte{
+		"key":        []byte("value"),
+		"compressed": bytes.Repeat([]byte("compressible "), 200),
+		"prefixed":   prefixed,
+	}
+	for key, value := range kvs {
+		require.Nil(t, th.App.SetPluginKey(pluginId, key, value))
+		assert.True(t, bytes.HasPrefix(getStored(key), pluginKeyValueEncryptedPrefix))
+	}
+	kvs["plaintext"] = []byte("plaintext value")
+	assert.Equal(t, []byte("plaintext value"), getStored("plaintext"))
+
+	assertValues := func(t *testing.T) {
+		for key, value := range kvs {
+			ret, err := th.App.GetPluginKey(pluginId, key)
+			require.Nil(t, err)
+			assert.Equal(t, value, ret, key)
+		}
+
+		keys := make([]string, 0, len(kvs))
+		for key := range kvs {
+			keys = append(keys, key)
+		}
+		values, err := th.App.GetPluginKeys(pluginId, keys)
+		require.Nil(t, err)
+		assert.Equal(t, kvs, values)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		assertValues(t)
+	})
+
+	t.Run("compare and set", func(t *testing.T) {
+		set, err := th.App.CompareAndSetPluginKey(pluginId, "key", []byte("value"), []byte("new value"))
+		require.Nil(t, err)
+		assert.True(t, set)
+		kvs["key"] = []byte("new value")
+
+		set, err = th.App.CompareAndSetPluginKey(pluginId, "key", []byte("value"), []byte("newer value"))
+		require.Nil(t, err)
+		assert.False(t, set)
+
+		assertValues(t)
+	})
+
+	t.Run("compare and delete", func(t *testing.T) {
+		require.Nil(t, th.App.SetPluginKey(pluginId, "deleted", []byte("value")))
+
+		deleted, err := th.App.CompareAndDeletePluginKey(pluginId, "deleted", []byte("other value"))
+		require.Nil(t, err)
+		assert.False(t, deleted)
+
+		deleted, err = th.App.CompareAndDeletePluginKey(pluginId, "deleted", []byte("value"))
+		require.Nil(t, err)
+		assert.True(t, deleted)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		stored := append([]byte{}, getStored("key")...)
+		stored[len(stored)-1] ^= 0x01
+		_, err := th.App.Srv.Store.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: "tampered", Value: stored, RawKey: "tampered"})
+		require.Nil(t, err)
+
+		_, err = th.App.GetPluginKey(pluginId, "tampered")
+		require.NotNil(t, err)
+		assert.Equal(t, "app.plugin.key_value.decrypt.app_error", err.Id)
+
+		require.Nil(t, th.App.DeletePluginKey(pluginId, "tampered"))
+	})
+
+	t.Run("rotated key", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.KeyValueEncryptionKey = model.NewRandomString(32)
+			cfg.PluginSettings.PreviousKeyValueEncryptionKeys = []string{previousKey}
+		})
+		current, _, err := th.App.pluginKeyValueEncryptionKeys()
+		require.Nil(t, err)
+
+		assertValues(t)
+
+		th.App.ReencryptPluginKeyValues()
+		for key := range kvs {
+			if key != "plaintext" {
+				assert.Equal(t, current.id, pluginKeyValueEncryptionKeyId(getStored(key)), key)
+			}
+		}
+		assert.Equal(t, []byte("plaintext value"), getStored("plaintext"))
+
+		th.App.UpdateConfig(func(cfg *model.Config) { cfg.PluginSettings.PreviousKeyValueEncryptionKeys = []string{} })
+		assertValues(t)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			cfg.PluginSettings.PreviousKeyValueEncryptionKeys = []string{*cfg.PluginSettings.KeyValueEncryptionKey}
+			*cfg.PluginSettings.KeyValueEncryptionKey = ""
+		})
+
+		th.App.ReencryptPluginKeyValues()
+		for key := range kvs {
+			assert.Nil(t, pluginKeyValueEncryptionKeyId(getStored(key)), key)
+		}
+
+		th.App.UpdateConfig(func(cfg *model.Config) { cfg.PluginSettings.PreviousKeyValueEncryptionKeys = []string{} })
+		assertValues(t)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.KeyValueEncryptionKey = model.NewRandomString(32) })
+		require.Nil(t, th.App.SetPluginKey(pluginId, "key", []byte("value")))
+
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.KeyValueEncryptionKey = model.NewRandomString(32) })
+		_, err := th.App.GetPluginKey(pluginId, "key")
+		require.NotNil(t, err)
+		assert.Equal(t, "app.plugin.key_value.decrypt.app_error", err.Id)
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	// PLUGIN_KEY_VALUE_MIGRATION_BATCH_SIZE is the most hashed plugin keys moved by each batch of the
+	// migration, so that it does not hold up plugins writing their keys for long.
+	PLUGIN_KEY_VALUE_MIGRATION_BATCH_SIZE = 100
+
+	// PLUGIN_KEY_VALUE_MIGRATION_CHECK_INTERVAL is how often a server checks whether the migration of
+	// hashed plugin keys has been completed, possibly by another server.
+	PLUGIN_KEY_VALUE_MIGRATION_CHECK_INTERVAL = 1 * time.Minute
+)
+
+// hashedPluginKeysMigrated returns whether the migration of hashed plugin keys has been completed,
+// after which keys are no longer looked up under their hash. Until then, it is checked at most once
+// every PLUGIN_KEY_VALUE_MIGRATION_CHECK_INTERVAL.
+func (a *App) hashedPluginKeysMigrated() bool {
+	a.hashedPluginKeysMigrationLock.Lock()
+	defer a.hashedPluginKeysMigrationLock.Unlock()
+
+	if a.hashedPluginKeysMigrationComplete || time.Since(a.hashedPluginKeysMigrationCheckedAt) < PLUGIN_KEY_VALUE_MIGRATION_CHECK_INTERVAL {
+		return a.hashedPluginKeysMigrationComplete
+	}
+
+	a.hashedPluginKeysMigrationCheckedAt = time.Now()
+	if result := <-a.Srv.Store.System().GetByName(model.MIGRATION_KEY_PLUGIN_KEY_VALUE_HASHED_KEYS); result.Err == nil {
+		a.hashedPluginKeysMigrationComplete = true
+	}
+
+	return a.hashedPluginKeysMigrationComplete
+}
+
+// MigrateHashedPluginKeys moves a batch of the plugin keys still stored as their hash, after those
+// progress records as looked at, to where they are stored as given, and updates progress. Plugins
+// may keep reading and writing their keys meanwhile, since each key is moved in its own transaction
+// and a key written since is kept over its hashed pair.
+func (a *App) MigrateHashedPluginKeys(progress *model.PluginKeyValueMigrationProgress) *model.AppError {
+	kvs, err := a.Srv.Store.Plugin().GetHashedKeys(progress.LastPluginId, progress.LastKey, PLUGIN_KEY_VALUE_MIGRATION_BATCH_SIZE)
+	if err != nil {
+		return err
+	}
+
+	for _, kv := range kvs {
+		// Only pairs stored under the hash of their raw key are moved, should any other pair have a
+		// raw key differing from its key.
+		if kv.Key == getKeyHash(kv.RawKey) {
+			moved, err := a.Srv.Store.Plugin().MoveHashedKey(kv.PluginId, kv.Key, kv.RawKey)
+			if err != nil {
+				return err
+			}
+			if moved {
+				progress.MovedKeys++
+			}
+		}
+
+		progress.LastPluginId = kv.PluginId
+		progress.LastKey = kv.Key
+	}
+
+	progress.Done = len(kvs) < PLUGIN_KEY_VALUE_MIGRATION_BATCH_SIZE
+
+	return nil
+}
+
+// GetPluginKeyValueMigrationStatus reports how many plugin keys are still stored as their hash, and
+// whether they are still looked up there.
+func (a *App) GetPluginKeyValueMigrationStatus() (*model.PluginKeyValueMigrationStatus, *model.AppError) {
+	status, err := a.Srv.Store.Plugin().CountHashedKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	status.Completed = (<-a.Srv.Store.System().GetByName(model.MIGRATION_KEY_PLUGIN_KEY_VALUE_HASHED_KEYS)).Err == nil
+
+	return status, nil
+}
+
+// CompletePluginKeyValueMigration moves all the plugin keys still stored as their hash without
+// waiting for the migrations job, and records the migration as completed, so that keys are no longer
+// looked up under their hash. Unless force is set, it fails if any hashed keys last written before
+// raw keys were recorded remain, since they would no longer be found.
+func (a *App) CompletePluginKeyValueMigration(force bool) *model.AppError {
+	progress := &model.PluginKeyValueMigrationProgress{}
+	for !progress.Done {
+		if err := a.MigrateHashedPluginKeys(progress); err != nil {
+			return err
+		}
+	}
+
+	status, err := a.GetPluginKeyValueMigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	if status.UnrecordedKeys > 0 {
+		if !force {
+			return model.NewAppError("CompletePluginKeyValueMigration", "app.plugin.kv.migration.unrecorded_keys.app_error", map[string]interface{}{"Count": status.UnrecordedKeys}, "", http.StatusConflict)
+		}
+		mlog.Warn("Completing the plugin key-value migration with hashed keys left that will no longer be found", mlog.Int64("unrecorded_keys", status.UnrecordedKeys))
+	}
+
+	if result := <-a.Srv.Store.System().SaveOrUpdate(&model.System{Name: model.MIGRATION_KEY_PLUGIN_KEY_VALUE_HASHED_KEYS, Value: "true"}); result.Err != nil {
+		return result.Err
+	}
+
+	a.hashedPluginKeysMigrationLock.Lock()
+	a.hashedPluginKeysMigrationComplete = true
+	a.hashedPluginKeysMigrationLock.Unlock()
+
+	mlog.Info("Completed the plugin key-value migration", mlog.Int64("moved_keys", progress.MovedKeys))
+
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// storeHashedPluginKeys stores the given number of the plugin's keys under their hash along with their
+// raw keys, as keys were stored before they were stored as given, returning the keys.
+func storeHashedPluginKeys(t *testing.T, th *TestHelper, pluginId string, count int) []string {
+	keys := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		key := "key" + strconv.Itoa(i)
+		_, err := th.App.Srv.Store.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+			PluginId: pluginId,
+			Key:      getKeyHash(key),
+			Value:    []byte("old" + strconv.Itoa(i)),
+			RawKey:   key,
+		})
+		require.Nil(t, err)
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestMigrateHashedPluginKeys(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := model.NewId()
+	keys := storeHashedPluginKeys(t, th, pluginId, PLUGIN_KEY_VALUE_MIGRATION_BATCH_SIZE+PLUGIN_KEY_VALUE_MIGRATION_BATCH_SIZE/2)
+
+	progress := &model.PluginKeyValueMigrationProgress{}
+	require.Nil(t, th.App.MigrateHashedPluginKeys(progress))
+	assert.False(t, progress.Done)
+
+	// The server restarts, and the migration resumes from the progress recorded by the job.
+	progress = model.PluginKeyValueMigrationProgressFromJson(strings.NewReader(progress.ToJson()))
+	require.NotNil(t, progress)
+	for !progress.Done {
+		require.Nil(t, th.App.MigrateHashedPluginKeys(progress))
+	}
+	assert.True(t, progress.MovedKeys >= int64(len(keys)))
+
+	for i, key := range keys {
+		kv, err := th.App.Srv.Store.Plugin().Get(pluginId, key)
+		require.Nil(t, err)
+		assert.Equal(t, []byte("old"+strconv.Itoa(i)), kv.Value)
+
+		_, err = th.App.Srv.Store.Plugin().Get(pluginId, getKeyHash(key))
+		assert.NotNil(t, err)
+	}
+
+	// Running the migration again finds nothing left to move.
+	progress = &model.PluginKeyValueMigrationProgress{}
+	for !progress.Done {
+		require.Nil(t, th.App.MigrateHashedPluginKeys(progress))
+	}
+	kvs, err := th.App.Srv.Store.Plugin().GetHashedKeys(pluginId, "", PLUGIN_KEY_VALUE_MIGRATION_BATCH_SIZE)
+	require.Nil(t, err)
+	for _, kv := range kvs {
+		assert.NotEqual(t, pluginId, kv.PluginId)
+	}
+}
+
+func TestMigrateHashedPluginKeysConcurrently(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := model.NewId()
+	keys := storeHashedPluginKeys(t, th, pluginId, 2*PLUGIN_KEY_VALUE_MIGRATION_BATCH_SIZE)
+
+	// Plugins keep writing, deleting and reading their keys while the migrations job moves them.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		progress := &model.PluginKeyValueMigrationProgress{}
+		for !progress.Done {
+			assert.Nil(t, th.App.MigrateHashedPluginKeys(progress))
+		}
+	}()
+
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+
+			switch i % 3 {
+			case 0:
+				assert.Nil(t, th.App.SetPluginKey(pluginId, key, []byte("new")))
+			case 1:
+				assert.Nil(t, th.App.DeletePluginKey(pluginId, key))
+			default:
+				value, err := th.App.GetPluginKey(pluginId, key)
+				assert.Nil(t, err)
+				assert.Equal(t, []byte("old"+strconv.Itoa(i)), value)
+			}
+		}(i, key)
+	}
+	wg.Wait()
+
+	// Neither the job nor the plugin's reads bring back an old value once it has been overwritten or
+	// deleted.
+	for i, key := range keys {
+		value, err := th.App.GetPluginKey(pluginId, key)
+		require.Nil(t, err)
+
+		switch i % 3 {
+		case 0:
+			assert.Equal(t, []byte("new"), value, key)
+		case 1:
+			assert.Nil(t, value, key)
+		default:
+			assert.Equal(t, []byte("old"+strconv.Itoa(i)), value, key)
+		}
+
+		_, appErr := th.App.Srv.Store.Plugin().Get(pluginId, getKeyHash(key))
+		assert.NotNil(t, appErr, key)
+	}
+}
+
+func TestCompletePluginKeyValueMigration(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	defer func() {
+		result := <-th.App.Srv.Store.System().PermanentDeleteByName(model.MIGRATION_KEY_PLUGIN_KEY_VALUE_HASHED_KEYS)
+		require.Nil(t, result.Err)
+	}()
+
+	pluginId := model.NewId()
+	keys := storeHashedPluginKeys(t, th, pluginId, 3)
+
+	// A key last written before raw keys were recorded.
+	_, appErr := th.App.Srv.Store.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      getKeyHash("unrecorded"),
+		Value:    []byte("value"),
+	})
+	require.Nil(t, appErr)
+
+	status, err := th.App.GetPluginKeyValueMigrationStatus()
+	require.Nil(t, err)
+	assert.False(t, status.Completed)
+	assert.True(t, status.RemainingKeys >= int64(len(keys)))
+	assert.True(t, status.UnrecordedKeys >= 1)
+
+	err = th.App.CompletePluginKeyValueMigration(false)
+	require.NotNil(t, err)
+	assert.Equal(t, "app.plugin.kv.migration.unrecorded_keys.app_error", err.Id)
+	assert.Equal(t, http.StatusConflict, err.StatusCode)
+
+	// The keys whose raw keys were recorded have been moved regardless.
+	status, err = th.App.GetPluginKeyValueMigrationStatus()
+	require.Nil(t, err)
+	assert.False(t, status.Completed)
+	assert.Equal(t, int64(0), status.RemainingKeys)
+
+	value, err := th.App.GetPluginKey(pluginId, "unrecorded")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	_, appErr = th.App.Srv.Store.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      getKeyHash("forgotten"),
+		Value:    []byte("value"),
+	})
+	require.Nil(t, appErr)
+
+	require.Nil(t, th.App.CompletePluginKeyValueMigration(true))
+
+	status, err = th.App.GetPluginKeyValueMigrationStatus()
+	require.Nil(t, err)
+	assert.True(t, status.Completed)
+	assert.True(t, th.App.hashedPluginKeysMigrated())
+
+	// Hashed keys are no longer looked up.
+	value, err = th.App.GetPluginKey(pluginId, "forgotten")
+	require.Nil(t, err)
+	assert.Nil(t, value)
+
+	values, err := th.App.GetPluginKeys(pluginId, append(keys, "forgotten"))
+	require.Nil(t, err)
+	assert.Len(t, values, len(keys))
+
+	require.Nil(t, th.App.SetPluginKey(pluginId, "forgotten", []byte("new")))
+	_, appErr = th.App.Srv.Store.Plugin().Get(pluginId, getKeyHash("forgotten"))
+	assert.Nil(t, appErr)
+}
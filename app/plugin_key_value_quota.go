@@ -0,0 +1,153 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	// PLUGIN_KEY_VALUE_USAGE_CACHE_TTL is how long the usage of a plugin's key-value store is cached
+	// for when checking writes against PluginSettings.MaxKeysPerPlugin and MaxTotalBytesPerPlugin.
+	PLUGIN_KEY_VALUE_USAGE_CACHE_TTL = 30 * time.Second
+
+	// pluginKeyValueQuotaRecheckRatio is the fraction of a quota beyond which a write is checked
+	// against the usage read afresh from the master database rather than the cached usage, so that
+	// writes are never rejected on account of stale usage, and writes from other servers are seen
+	// before the quota is reached.
+	pluginKeyValueQuotaRecheckRatio = 0.9
+)
+
+type cachedPluginKeyValueUsage struct {
+	usage    model.PluginKeyValueUsage
+	expireAt time.Time
+}
+
+// pluginKeyValueUsageDelta is how much a write grows a plugin's key-value store.
+type pluginKeyValueUsageDelta struct {
+	keys int64
+	size int64
+}
+
+// checkPluginKeyValueQuota checks that writing the given key-value pairs, with their values as stored,
+// would keep the plugin within its quotas, returning how much the write would grow its usage. Writes
+// that do not grow the usage are always allowed, so that a plugin over quota can still shrink.
+//
+// Concurrent writes are each checked against the usage before any of them, so may together exceed
+// the quotas by as much as they add.
+func (a *App) checkPluginKeyValueQuota(pluginId string, kvs []*model.PluginKeyValue) (*pluginKeyValueUsageDelta, *model.AppError) {
+	maxKeys := int64(*a.Config().PluginSettings.MaxKeysPerPlugin)
+	maxSize := *a.Config().PluginSettings.MaxTotalBytesPerPlugin
+	if maxKeys == 0 && maxSize == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(kvs))
+	for _, kv := range kvs {
+		keys = append(keys, kv.Key)
+	}
+
+	stored, err := a.Srv.Store.Plugin().GetMultipleFromMaster(pluginId, keys)
+	if err != nil {
+		mlog.Error(err.Error())
+		return nil, err
+	}
+
+	existing := make(map[string]int64)
+	for _, kv := range stored {
+		existing[kv.Key] = int64(len(kv.Value))
+	}
+
+	delta := &pluginKeyValueUsageDelta{}
+	for _, kv := range kvs {
+		size, ok := existing[kv.Key]
+		if !ok {
+			delta.keys++
+		}
+		delta.size += int64(len(kv.Value)) - size
+	}
+
+	if delta.keys <= 0 && delta.size <= 0 {
+		return delta, nil
+	}
+
+	exceeds := func(usage *model.PluginKeyValueUsage, ratio float64) bool {
+		return (maxKeys > 0 && delta.keys > 0 && float64(usage.KeyCount+delta.keys) > ratio*float64(maxKeys)) ||
+			(maxSize > 0 && delta.size > 0 && float64(usage.Size+delta.size) > ratio*float64(maxSize))
+	}
+
+	usage, err := a.getPluginKeyValueUsage(pluginId, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if exceeds(usage, pluginKeyValueQuotaRecheckRatio) {
+		if usage, err = a.getPluginKeyValueUsage(pluginId, true); err != nil {
+			return nil, err
+		}
+
+		if exceeds(usage, 1) {
+			a.recordPluginEvent(pluginId, model.PLUGIN_EVENT_QUOTA_EXCEEDED, fmt.Sprintf("key_count=%v size=%v max_keys=%v max_size=%v", usage.KeyCount, usage.Size, maxKeys, maxSize))
+			return nil, model.NewAppError("checkPluginKeyValueQuota", "app.plugin.kv.quota_exceeded.app_error", map[string]interface{}{"MaxKeys": maxKeys, "MaxSize": maxSize}, "plugin_id="+pluginId, http.StatusForbidden)
+		}
+	}
+
+	return delta, nil
+}
+
+// getPluginKeyValueUsage returns the usage of the plugin's key-value store, as cached unless fresh is
+// set or the cached usage has expired.
+func (a *App) getPluginKeyValueUsage(pluginId string, fresh bool) (*model.PluginKeyValueUsage, *model.AppError) {
+	if !fresh {
+		a.pluginKeyValueUsageLock.Lock()
+		cached, ok := a.pluginKeyValueUsage[pluginId]
+		a.pluginKeyValueUsageLock.Unlock()
+
+		if ok && time.Now().Before(cached.expireAt) {
+			usage := cached.usage
+			return &usage, nil
+		}
+	}
+
+	usage, err := a.Srv.Store.Plugin().GetUsageFromMaster(pluginId)
+	if err != nil {
+		mlog.Error(err.Error())
+		return nil, err
+	}
+
+	a.pluginKeyValueUsageLock.Lock()
+	defer a.pluginKeyValueUsageLock.Unlock()
+
+	if a.pluginKeyValueUsage == nil {
+		a.pluginKeyValueUsage = make(map[string]*cachedPluginKeyValueUsage)
+	}
+	a.pluginKeyValueUsage[pluginId] = &cachedPluginKeyValueUsage{
+		usage:    *usage,
+		expireAt: time.Now().Add(PLUGIN_KEY_VALUE_USAGE_CACHE_TTL),
+	}
+
+	return usage, nil
+}
+
+// addPluginKeyValueUsage accounts for a write checked by checkPluginKeyValueQuota in the cached usage
+// of the plugin's key-value store, so that it need not be read again before the next write. Deletes
+// are not accounted for, since the usage is always read afresh before a write is rejected.
+func (a *App) addPluginKeyValueUsage(pluginId string, delta *pluginKeyValueUsageDelta) {
+	if delta == nil {
+		return
+	}
+
+	a.pluginKeyValueUsageLock.Lock()
+	defer a.pluginKeyValueUsageLock.Unlock()
+
+	if cached, ok := a.pluginKeyValueUsage[pluginId]; ok {
+		cached.usage.KeyCount += delta.keys
+		cached.usage.Size += delta.size
+	}
+}
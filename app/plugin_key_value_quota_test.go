@@ -0,0 +1,135 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func requirePluginKeyValueQuotaExceeded(t *testing.T, err *model.AppError) {
+	t.Helper()
+
+	require.NotNil(t, err)
+	assert.Equal(t, "app.plugin.kv.quota_exceeded.app_error", err.Id)
+	assert.Equal(t, http.StatusForbidden, err.StatusCode)
+}
+
+func TestPluginKeyValueQuota(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.EnableKeyValueCompression = false
+		*cfg.PluginSettings.MaxKeysPerPlugin = 3
+		*cfg.PluginSettings.MaxTotalBytesPerPlugin = 100
+	})
+
+	t.Run("key count", func(t *testing.T) {
+		pluginId := model.NewId()
+
+		for i := 0; i < 3; i++ {
+			require.Nil(t, th.App.SetPluginKey(pluginId, fmt.Sprintf("key%v", i), []byte("value")))
+		}
+		requirePluginKeyValueQuotaExceeded(t, th.App.SetPluginKey(pluginId, "key3", []byte("value")))
+
+		// Existing keys can still be overwritten.
+		require.Nil(t, th.App.SetPluginKey(pluginId, "key0", []byte("new value")))
+
+		_, err := th.App.CompareAndSetPluginKey(pluginId, "key3", nil, []byte("value"))
+		requirePluginKeyValueQuotaExceeded(t, err)
+
+		_, err = th.App.IncrementPluginKey(pluginId, "counter", 1)
+		requirePluginKeyValueQuotaExceeded(t, err)
+
+		requirePluginKeyValueQuotaExceeded(t, th.App.SetPluginKeys(pluginId, map[string][]byte{"key0": []byte("value"), "key3": []byte("value")}))
+
+		// Deletes free quota.
+		require.Nil(t, th.App.DeletePluginKey(pluginId, "key1"))
+		require.Nil(t, th.App.SetPluginKey(pluginId, "key3", []byte("value")))
+	})
+
+	t.Run("total size", func(t *testing.T) {
+		pluginId := model.NewId()
+
+		require.Nil(t, th.App.SetPluginKey(pluginId, "key0", make([]byte, 60)))
+		requirePluginKeyValueQuotaExceeded(t, th.App.SetPluginKey(pluginId, "key1", make([]byte, 50)))
+		require.Nil(t, th.App.SetPluginKey(pluginId, "key1", make([]byte, 40)))
+
+		// Growing an existing value counts only the growth, while shrinking one is always allowed.
+		requirePluginKeyValueQuotaExceeded(t, th.App.SetPluginKey(pluginId, "key0", make([]byte, 61)))
+		require.Nil(t, th.App.SetPluginKey(pluginId, "key0", make([]byte, 10)))
+		require.Nil(t, th.App.SetPluginKey(pluginId, "key0", make([]byte, 60)))
+
+		deleted, err := th.App.CompareAndDeletePluginKey(pluginId, "key1", make([]byte, 40))
+		require.Nil(t, err)
+		require.True(t, deleted)
+		require.Nil(t, th.App.SetPluginKey(pluginId, "key1", make([]byte, 40)))
+	})
+
+	t.Run("other plugins unaffected", func(t *testing.T) {
+		pluginId := model.NewId()
+		otherPluginId := model.NewId()
+
+		for i := 0; i < 3; i++ {
+			require.Nil(t, th.App.SetPluginKey(pluginId, fmt.Sprintf("key%v", i), []byte("value")))
+		}
+		requirePluginKeyValueQuotaExceeded(t, th.App.SetPluginKey(pluginId, "key3", []byte("value")))
+
+		require.Nil(t, th.App.SetPluginKey(otherPluginId, "key0", []byte("value")))
+	})
+
+	t.Run("locks are exempt", func(t *testing.T) {
+		pluginId := model.NewId()
+
+		for i := 0; i < 3; i++ {
+			require.Nil(t, th.App.SetPluginKey(pluginId, fmt.Sprintf("key%v", i), []byte("value")))
+		}
+
+		token, err := th.App.LockPluginKey(pluginId, "lock", PLUGIN_KEY_VALUE_USAGE_CACHE_TTL)
+		require.Nil(t, err)
+		assert.NotEmpty(t, token)
+	})
+
+	t.Run("concurrent writes", func(t *testing.T) {
+		pluginId := model.NewId()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				th.App.SetPluginKey(pluginId, fmt.Sprintf("key%v", i), []byte("value"))
+			}(i)
+		}
+		wg.Wait()
+
+		// Concurrent writes may each be checked before any of the others are stored, but never once
+		// the quota has been reached.
+		usage, err := th.App.getPluginKeyValueUsage(pluginId, true)
+		require.Nil(t, err)
+		assert.True(t, usage.KeyCount >= 3)
+
+		requirePluginKeyValueQuotaExceeded(t, th.App.SetPluginKey(pluginId, "key", []byte("value")))
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.MaxKeysPerPlugin = 0
+			*cfg.PluginSettings.MaxTotalBytesPerPlugin = 0
+		})
+
+		pluginId := model.NewId()
+		for i := 0; i < 5; i++ {
+			require.Nil(t, th.App.SetPluginKey(pluginId, fmt.Sprintf("key%v", i), make([]byte, 100)))
+		}
+	})
+}
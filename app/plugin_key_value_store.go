@@ -4,58 +4,952 @@
 package app
 
 import (
+	"bytes"
+	"compress/gzip"
 	"crypto/sha256"
 	"encoding/base64"
+	"io/ioutil"
 	"net/http"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
 
 	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
 )
 
+const (
+	PLUGIN_KEY_VALUE_CLEANUP_INTERVAL = 1 * time.Hour
+
+	// PLUGIN_KEY_VALUE_LOCK_PREFIX is prepended to the names of advisory locks before hashing them, so
+	// that they do not collide with the plugin's keys, including those still stored hashed.
+	PLUGIN_KEY_VALUE_LOCK_PREFIX = "\x00lock:"
+)
+
+// pluginKeyValueCompressedPrefix marks values stored gzip-compressed by SetPluginKey, and is followed
+// by the compressed value. Its last byte is the version of that encoding.
+var pluginKeyValueCompressedPrefix = []byte("\x00MMKVZ\x01")
+
+// getKeyHash returns the hash under which keys were stored before they were stored as given.
 func getKeyHash(key string) string {
 	hash := sha256.New()
 	hash.Write([]byte(key))
 	return base64.StdEncoding.EncodeToString(hash.Sum(nil))
 }
 
+// getStoredPluginKey returns the key under which the plugin's key is stored, first moving it from
+// where it was stored before keys were stored as given. Keys too long to be stored as given can only
+// have been stored hashed, so they are still read and deleted from there.
+func (a *App) getStoredPluginKey(pluginId, key string) (string, *model.AppError) {
+	if utf8.RuneCountInString(key) > model.KEY_VALUE_KEY_MAX_RUNES {
+		return getKeyHash(key), nil
+	}
+
+	if err := a.migrateHashedPluginKey(pluginId, key); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// migrateHashedPluginKey moves the key-value pair stored under the hash of the plugin's key, if any,
+// to the key itself. A pair already stored under the key itself is newer, so it is kept.
+func (a *App) migrateHashedPluginKey(pluginId, key string) *model.AppError {
+	if utf8.RuneCountInString(key) > model.KEY_VALUE_KEY_MAX_RUNES {
+		// Writing the key will be rejected, but it can still be read and deleted where it is.
+		return nil
+	}
+
+	if a.hashedPluginKeysMigrated() {
+		return nil
+	}
+
+	hashedKey := getKeyHash(key)
+
+	if _, err := a.Srv.Store.Plugin().Get(pluginId, hashedKey); err != nil {
+		if err.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		mlog.Error(err.Error())
+		return err
+	}
+
+	// The pair is moved in a single transaction, so that it cannot be moved again by the migrations job
+	// or another request after the key itself has been written or deleted.
+	if _, err := a.Srv.Store.Plugin().MoveHashedKey(pluginId, hashedKey, key); err != nil {
+		mlog.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// getHashedPluginKeys returns the plugin's key-value pairs stored under the given hashed keys, unless
+// hashed keys are no longer looked up.
+func (a *App) getHashedPluginKeys(pluginId string, hashedKeys []string) ([]*model.PluginKeyValue, *model.AppError) {
+	if a.hashedPluginKeysMigrated() {
+		return nil, nil
+	}
+
+	kvs, err := a.Srv.Store.Plugin().GetMultiple(pluginId, hashedKeys)
+	if err != nil {
+		mlog.Error(err.Error())
+		return nil, err
+	}
+
+	return kvs, nil
+}
+
 func (a *App) SetPluginKey(pluginId string, key string, value []byte) *model.AppError {
+	return a.SetPluginKeyWithExpiry(pluginId, key, value, 0)
+}
+
+// SetPluginKeyWithExpiry stores a key-value pair for the plugin that is treated as deleted once
+// expireInSeconds have passed, or never expires if expireInSeconds is zero. A nil value deletes the
+// key instead.
+func (a *App) SetPluginKeyWithExpiry(pluginId string, key string, value []byte, expireInSeconds int64) *model.AppError {
+	if expireInSeconds < 0 {
+		return model.NewAppError("SetPluginKeyWithExpiry", "app.plugin.kv.expire_in_seconds.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if value == nil {
+		return a.DeletePluginKey(pluginId, key)
+	}
+
+	storedValue, err := a.encodeStoredPluginKeyValue(value)
+	if err != nil {
+		return err
+	}
+
+	kv := &model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      key,
+		Value:    storedValue,
+		RawKey:   key,
+	}
+
+	if expireInSeconds > 0 {
+		kv.ExpireAt = model.GetMillis() + expireInSeconds*1000
+	}
+
+	if err := a.isValidPluginKeyValue(kv); err != nil {
+		return err
+	}
+
+	if err := a.migrateHashedPluginKey(pluginId, key); err != nil {
+		return err
+	}
+
+	usageDelta, err := a.checkPluginKeyValueQuota(pluginId, []*model.PluginKeyValue{kv})
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.Srv.Store.Plugin().SaveOrUpdate(kv); err != nil {
+		mlog.Error(err.Error())
+		return err
+	}
+
+	a.addPluginKeyValueUsage(pluginId, usageDelta)
+	a.notifyPluginOfKeyValueChange(pluginId, key)
+
+	return nil
+}
+
+// SetPluginKeys stores the given key-value pairs for the plugin in a single transaction, so that
+// either all of them or none of them are stored. The pairs never expire.
+func (a *App) SetPluginKeys(pluginId string, kvs map[string][]byte) *model.AppError {
+	keys := make([]string, 0, len(kvs))
+	for key := range kvs {
+		keys = append(keys, key)
+	}
+
+	// Writing the keys in a consistent order keeps concurrent batches from deadlocking each other.
+	sort.Strings(keys)
+
+	storedKvs := make([]*model.PluginKeyValue, 0, len(keys))
+	hashedKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		storedValue, err := a.encodeStoredPluginKeyValue(kvs[key])
+		if err != nil {
+			return err
+		}
+
+		kv := &model.PluginKeyValue{
+			PluginId: pluginId,
+			Key:      key,
+			Value:    storedValue,
+			RawKey:   key,
+		}
+		if err := a.isValidPluginKeyValue(kv); err != nil {
+			return err
+		}
+
+		storedKvs = append(storedKvs, kv)
+		hashedKeys = append(hashedKeys, getKeyHash(key))
+	}
+
+	hashedKvs, err := a.getHashedPluginKeys(pluginId, hashedKeys)
+	if err != nil {
+		return err
+	}
+
+	usageDelta, err := a.checkPluginKeyValueQuota(pluginId, storedKvs)
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.Srv.Store.Plugin().SaveOrUpdateMultiple(storedKvs); err != nil {
+		mlog.Error(err.Error())
+		return err
+	}
+
+	a.addPluginKeyValueUsage(pluginId, usageDelta)
+
+	// The values just stored supersede those stored where keys were stored before being stored as given.
+	for _, kv := range hashedKvs {
+		if _, err := a.Srv.Store.Plugin().Delete(pluginId, kv.Key); err != nil {
+			mlog.Error(err.Error())
+			return err
+		}
+	}
+
+	for _, key := range keys {
+		a.notifyPluginOfKeyValueChange(pluginId, key)
+	}
+
+	return nil
+}
+
+// SetPluginKeysAtomic makes the given sets and deletes of the plugin's keys in order in a single
+// transaction, so that either all of them or none of them are made. Set values never expire, and sets
+// of a nil value are made as deletes. If any op is invalid, none are made and the error names its key.
+func (a *App) SetPluginKeysAtomic(pluginId string, ops []model.PluginKVOp) *model.AppError {
+	if len(ops) == 0 {
+		return nil
+	}
+
+	storedOps := make([]model.PluginKVOp, 0, len(ops))
+	finalKvs := make(map[string]*model.PluginKeyValue)
+	var finalKeys []string
+	var hashedKeys []string
+	var changedKeys []string
+	for _, op := range ops {
+		if op.Type == model.PLUGIN_KV_OP_SET && op.Value == nil {
+			op.Type = model.PLUGIN_KV_OP_DELETE
+		}
+
+		storedOp := op
+		if op.Type == model.PLUGIN_KV_OP_DELETE && utf8.RuneCountInString(op.Key) > model.KEY_VALUE_KEY_MAX_RUNES {
+			// Keys too long to be stored as given can only have been stored hashed.
+			storedOp.Key = getKeyHash(op.Key)
+		} else {
+			hashedKeys = append(hashedKeys, getKeyHash(op.Key))
+		}
+
+		if err := storedOp.IsValid(); err != nil {
+			return model.NewAppError("SetPluginKeysAtomic", "app.plugin.kv.atomic.invalid_op.app_error", map[string]interface{}{"Key": op.Key}, err.Error(), err.StatusCode)
+		}
+
+		var kv *model.PluginKeyValue
+		if op.Type == model.PLUGIN_KV_OP_SET {
+			storedValue, err := a.encodeStoredPluginKeyValue(op.Value)
+			if err != nil {
+				return err
+			}
+
+			kv = &model.PluginKeyValue{
+				PluginId: pluginId,
+				Key:      op.Key,
+				Value:    storedValue,
+				RawKey:   op.Key,
+			}
+			if err := a.isValidPluginKeyValue(kv); err != nil {
+				return model.NewAppError("SetPluginKeysAtomic", "app.plugin.kv.atomic.invalid_op.app_error", map[string]interface{}{"Key": op.Key}, err.Error(), err.StatusCode)
+			}
+			storedOp.Value = storedValue
+		}
+
+		if _, ok := finalKvs[storedOp.Key]; !ok {
+			finalKeys = append(finalKeys, storedOp.Key)
+			changedKeys = append(changedKeys, op.Key)
+		}
+		finalKvs[storedOp.Key] = kv
+		storedOps = append(storedOps, storedOp)
+	}
+
+	hashedKvs, err := a.getHashedPluginKeys(pluginId, hashedKeys)
+	if err != nil {
+		return err
+	}
+
+	// The ops supersede the values stored where keys were stored before being stored as given.
+	for _, kv := range hashedKvs {
+		storedOps = append(storedOps, model.PluginKVOp{Type: model.PLUGIN_KV_OP_DELETE, Key: kv.Key})
+	}
+
+	// Only the values left once all the ops are made count towards the quota.
+	storedKvs := make([]*model.PluginKeyValue, 0, len(finalKeys))
+	for _, key := range finalKeys {
+		if kv := finalKvs[key]; kv != nil {
+			storedKvs = append(storedKvs, kv)
+		}
+	}
+
+	usageDelta, err := a.checkPluginKeyValueQuota(pluginId, storedKvs)
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.Srv.Store.Plugin().SaveOrUpdateMany(pluginId, storedOps); err != nil {
+		mlog.Error(err.Error())
+		return err
+	}
+
+	a.addPluginKeyValueUsage(pluginId, usageDelta)
+	for _, key := range changedKeys {
+		a.notifyPluginOfKeyValueChange(pluginId, key)
+	}
+
+	return nil
+}
+
+// CompareAndSetPluginKey atomically sets the value of the plugin's key to newValue only if it
+// currently holds oldValue, or only if it does not exist when oldValue is nil or empty, since the two
+// cannot be told apart once sent by a plugin. A nil newValue is set as an empty value. It returns
+// whether the value was set.
+func (a *App) CompareAndSetPluginKey(pluginId string, key string, oldValue, newValue []byte) (bool, *model.AppError) {
+	if newValue == nil {
+		newValue = []byte{}
+	}
+
+	storedNewValue, err := a.encodeStoredPluginKeyValue(newValue)
+	if err != nil {
+		return false, err
+	}
+
 	kv := &model.PluginKeyValue{
 		PluginId: pluginId,
-		Key:      getKeyHash(key),
-		Value:    value,
+		Key:      key,
+		Value:    storedNewValue,
+		RawKey:   key,
+	}
+
+	if err := a.isValidPluginKeyValue(kv); err != nil {
+		return false, err
+	}
+
+	if err := a.migrateHashedPluginKey(pluginId, key); err != nil {
+		return false, err
+	}
+
+	usageDelta, err := a.checkPluginKeyValueQuota(pluginId, []*model.PluginKeyValue{kv})
+	if err != nil {
+		return false, err
+	}
+
+	set, err := a.compareAndSetPluginKeyValue(kv, oldValue)
+	if set {
+		a.addPluginKeyValueUsage(pluginId, usageDelta)
+		a.notifyPluginOfKeyValueChange(pluginId, key)
+	}
+
+	return set, err
+}
+
+func (a *App) compareAndSetPluginKeyValue(kv *model.PluginKeyValue, oldValue []byte) (bool, *model.AppError) {
+	if len(oldValue) == 0 {
+		return a.compareAndSetStoredPluginKey(kv, nil)
+	}
+
+	// The old value is usually stored with the same encoding as it would be now, unless values are
+	// encrypted, since no two encryptions of a value are the same.
+	storedOldValue, err := a.encodeStoredPluginKeyValue(oldValue)
+	if err != nil {
+		return false, err
+	}
+	if set, err := a.compareAndSetStoredPluginKey(kv, storedOldValue); err != nil || set {
+		return set, err
+	}
+
+	// Otherwise, it may be encrypted or have been stored before the compression settings changed.
+	stored, err := a.Srv.Store.Plugin().Get(kv.PluginId, kv.Key)
+	if err != nil {
+		if err.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		mlog.Error(err.Error())
+		return false, err
+	}
+
+	current := stored.Value
+	if bytes.Equal(current, storedOldValue) {
+		return false, nil
+	}
+
+	decoded, err := a.decodeStoredPluginKeyValue(current)
+	if err != nil {
+		return false, err
+	} else if !bytes.Equal(decoded, oldValue) {
+		return false, nil
 	}
 
-	result := <-a.Srv.Store.Plugin().SaveOrUpdate(kv)
+	return a.compareAndSetStoredPluginKey(kv, current)
+}
 
-	if result.Err != nil {
-		mlog.Error(result.Err.Error())
+func (a *App) compareAndSetStoredPluginKey(kv *model.PluginKeyValue, storedOldValue []byte) (bool, *model.AppError) {
+	set, err := a.Srv.Store.Plugin().CompareAndSet(kv, storedOldValue)
+	if err != nil {
+		mlog.Error(err.Error())
+		return false, err
 	}
 
-	return result.Err
+	return set, nil
 }
 
 func (a *App) GetPluginKey(pluginId string, key string) ([]byte, *model.AppError) {
-	result := <-a.Srv.Store.Plugin().Get(pluginId, getKeyHash(key))
+	value, _, err := a.GetPluginKeyWithExists(pluginId, key)
+	return value, err
+}
+
+// GetPluginKeyWithExists is like GetPluginKey, but also reports whether the key exists, so that a key
+// holding an empty value can be told apart from one that was never stored. The value of an existing
+// key is never nil.
+func (a *App) GetPluginKeyWithExists(pluginId string, key string) ([]byte, bool, *model.AppError) {
+	return a.getPluginKey(pluginId, key, model.PluginKVGetOptions{})
+}
+
+// GetPluginKeyWithOptions is like GetPluginKey, but reads the key as the options specify. Setting
+// ReadFromMaster lets a plugin read its own writes regardless of replication lag, at the cost of
+// load on the master database.
+func (a *App) GetPluginKeyWithOptions(pluginId string, key string, opts model.PluginKVGetOptions) ([]byte, *model.AppError) {
+	value, _, err := a.getPluginKey(pluginId, key, opts)
+	return value, err
+}
+
+func (a *App) getPluginKey(pluginId string, key string, opts model.PluginKVGetOptions) ([]byte, bool, *model.AppError) {
+	get := a.Srv.Store.Plugin().Get
+	if opts.ReadFromMaster || a.pluginReadsFromMaster(pluginId) {
+		get = a.Srv.Store.Plugin().GetFromMaster
+	}
+
+	// Keys too long to be stored as given can only have been stored hashed.
+	storedKey := key
+	if utf8.RuneCountInString(key) > model.KEY_VALUE_KEY_MAX_RUNES {
+		storedKey = getKeyHash(key)
+	}
+
+	kv, err := get(pluginId, storedKey)
+	if err != nil && err.StatusCode == http.StatusNotFound && storedKey == key && !a.hashedPluginKeysMigrated() {
+		// The key may not have been written since keys were stored as given, in which case it is
+		// moved now.
+		kv, err = get(pluginId, getKeyHash(key))
+		if err == nil {
+			if err := a.migrateHashedPluginKey(pluginId, key); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	if err != nil {
+		if err.StatusCode == http.StatusNotFound {
+			return nil, false, nil
+		}
+		mlog.Error(err.Error())
+		return nil, false, err
+	}
+
+	value, err := a.decodeStoredPluginKeyValue(kv.Value)
+	if err != nil {
+		return nil, false, err
+	}
+	if value == nil {
+		value = []byte{}
+	}
+
+	return value, true, nil
+}
+
+// GetPluginKeys returns the values of the plugin's keys in a single query. Non-existent keys are
+// omitted from the result.
+func (a *App) GetPluginKeys(pluginId string, keys []string) (map[string][]byte, *model.AppError) {
+	lookUpHashedKeys := !a.hashedPluginKeysMigrated()
+
+	storedKeys := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		storedKeys = append(storedKeys, key)
+		if lookUpHashedKeys {
+			storedKeys = append(storedKeys, getKeyHash(key))
+		}
+	}
 
-	if result.Err != nil {
-		if result.Err.StatusCode == http.StatusNotFound {
-			return nil, nil
+	getMultiple := a.Srv.Store.Plugin().GetMultiple
+	if a.pluginReadsFromMaster(pluginId) {
+		getMultiple = a.Srv.Store.Plugin().GetMultipleFromMaster
+	}
+
+	kvs, err := getMultiple(pluginId, storedKeys)
+	if err != nil {
+		mlog.Error(err.Error())
+		return nil, err
+	}
+
+	stored := make(map[string][]byte)
+	for _, kv := range kvs {
+		stored[kv.Key] = kv.Value
+	}
+
+	values := make(map[string][]byte)
+	for _, key := range keys {
+		if value, ok := stored[key]; ok {
+			decoded, err := a.decodeStoredPluginKeyValue(value)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = decoded
+			continue
+		}
+
+		if !lookUpHashedKeys {
+			continue
+		}
+
+		value, ok := stored[getKeyHash(key)]
+		if !ok {
+			continue
 		}
-		mlog.Error(result.Err.Error())
-		return nil, result.Err
+
+		if err := a.migrateHashedPluginKey(pluginId, key); err != nil {
+			return nil, err
+		}
+
+		decoded, err := a.decodeStoredPluginKeyValue(value)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = decoded
+	}
+
+	return values, nil
+}
+
+// beginPluginReadAfterWrite records that a request to the plugin expecting to read its own writes is
+// in progress, returning a function that records that it has finished. Reads made through the API
+// cannot be attributed to the request that caused them, so all of the plugin's reads are served by the
+// master database in the meantime.
+func (a *App) beginPluginReadAfterWrite(pluginId string) func() {
+	a.pluginReadAfterWriteLock.Lock()
+	defer a.pluginReadAfterWriteLock.Unlock()
+
+	if a.pluginReadAfterWriteRequests == nil {
+		a.pluginReadAfterWriteRequests = make(map[string]int)
 	}
+	a.pluginReadAfterWriteRequests[pluginId]++
 
-	kv := result.Data.(*model.PluginKeyValue)
+	return func() {
+		a.pluginReadAfterWriteLock.Lock()
+		defer a.pluginReadAfterWriteLock.Unlock()
 
-	return kv.Value, nil
+		if a.pluginReadAfterWriteRequests[pluginId]--; a.pluginReadAfterWriteRequests[pluginId] == 0 {
+			delete(a.pluginReadAfterWriteRequests, pluginId)
+		}
+	}
+}
+
+// pluginReadsFromMaster returns whether the plugin's key-value pairs should be read from the master
+// database, so as not to miss writes yet to reach the read replicas.
+func (a *App) pluginReadsFromMaster(pluginId string) bool {
+	a.pluginReadAfterWriteLock.Lock()
+	defer a.pluginReadAfterWriteLock.Unlock()
+
+	return a.pluginReadAfterWriteRequests[pluginId] > 0
+}
+
+// ListPluginKeys returns a page of the keys stored by the plugin. Keys not written since keys could
+// be listed are omitted.
+func (a *App) ListPluginKeys(pluginId string, page, perPage int) ([]string, *model.AppError) {
+	if page < 0 || perPage <= 0 {
+		return nil, model.NewAppError("ListPluginKeys", "app.plugin.kv.list.invalid_page.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	keys, err := a.Srv.Store.Plugin().List(pluginId, page*perPage, perPage)
+	if err != nil {
+		mlog.Error(err.Error())
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// ListPluginKeysWithPrefix returns a page of the keys stored by the plugin that begin with the given
+// prefix, which is matched literally.
+func (a *App) ListPluginKeysWithPrefix(pluginId, prefix string, page, perPage int) ([]string, *model.AppError) {
+	if page < 0 || perPage <= 0 {
+		return nil, model.NewAppError("ListPluginKeysWithPrefix", "app.plugin.kv.list.invalid_page.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	keys, err := a.Srv.Store.Plugin().ListWithPrefix(pluginId, prefix, page*perPage, perPage)
+	if err != nil {
+		mlog.Error(err.Error())
+		return nil, err
+	}
+
+	return keys, nil
 }
 
 func (a *App) DeletePluginKey(pluginId string, key string) *model.AppError {
-	result := <-a.Srv.Store.Plugin().Delete(pluginId, getKeyHash(key))
+	_, err := a.DeletePluginKeyIfExists(pluginId, key)
+	return err
+}
+
+// DeletePluginKeyIfExists deletes the plugin's key, returning whether it existed. Deleting a missing
+// key is not an error.
+func (a *App) DeletePluginKeyIfExists(pluginId string, key string) (bool, *model.AppError) {
+	storedKey, err := a.getStoredPluginKey(pluginId, key)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := a.Srv.Store.Plugin().Delete(pluginId, storedKey)
+	if err != nil {
+		mlog.Error(err.Error())
+		return false, err
+	}
+
+	if deleted {
+		a.notifyPluginOfKeyValueChange(pluginId, key)
+	}
+
+	return deleted, nil
+}
+
+// DeleteAllPluginKeys deletes every key stored by the plugin, and only those. Plugins implementing
+// KVHasChanged are notified once, with an empty key, rather than of each deleted key.
+func (a *App) DeleteAllPluginKeys(pluginId string) *model.AppError {
+	if _, err := a.Srv.Store.Plugin().DeleteAllForPlugin(pluginId); err != nil {
+		mlog.Error(err.Error())
+		return err
+	}
+
+	a.pluginKeyValueUsageLock.Lock()
+	delete(a.pluginKeyValueUsage, pluginId)
+	a.pluginKeyValueUsageLock.Unlock()
+
+	a.notifyPluginOfKeyValueChange(pluginId, "")
+
+	return nil
+}
+
+// CompareAndDeletePluginKey atomically deletes the plugin's key only if it currently holds oldValue,
+// returning whether it was deleted. A missing key or a different value is not an error.
+func (a *App) CompareAndDeletePluginKey(pluginId string, key string, oldValue []byte) (bool, *model.AppError) {
+	deleted, err := a.compareAndDeletePluginKey(pluginId, key, oldValue)
+	if deleted {
+		a.notifyPluginOfKeyValueChange(pluginId, key)
+	}
+
+	return deleted, err
+}
+
+func (a *App) compareAndDeletePluginKey(pluginId string, key string, oldValue []byte) (bool, *model.AppError) {
+	storedKey, err := a.getStoredPluginKey(pluginId, key)
+	if err != nil {
+		return false, err
+	}
+
+	// The old value is usually stored with the same encoding as it would be now, unless values are
+	// encrypted, since no two encryptions of a value are the same.
+	storedOldValue, err := a.encodeStoredPluginKeyValue(oldValue)
+	if err != nil {
+		return false, err
+	}
+	if deleted, err := a.compareAndDeleteStoredPluginKey(pluginId, storedKey, storedOldValue); err != nil || deleted {
+		return deleted, err
+	}
+
+	// Otherwise, it may be encrypted or have been stored before the compression settings changed.
+	stored, err := a.Srv.Store.Plugin().Get(pluginId, storedKey)
+	if err != nil {
+		if err.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		mlog.Error(err.Error())
+		return false, err
+	}
+
+	current := stored.Value
+	if bytes.Equal(current, storedOldValue) {
+		return false, nil
+	}
+
+	decoded, err := a.decodeStoredPluginKeyValue(current)
+	if err != nil {
+		return false, err
+	} else if !bytes.Equal(decoded, oldValue) {
+		return false, nil
+	}
+
+	return a.compareAndDeleteStoredPluginKey(pluginId, storedKey, current)
+}
+
+func (a *App) compareAndDeleteStoredPluginKey(pluginId, storedKey string, storedOldValue []byte) (bool, *model.AppError) {
+	deleted, err := a.Srv.Store.Plugin().CompareAndDelete(pluginId, storedKey, storedOldValue)
+	if err != nil {
+		mlog.Error(err.Error())
+		return false, err
+	}
+
+	return deleted, nil
+}
+
+// IncrementPluginKey atomically adds delta to the counter stored as a decimal number under the
+// plugin's key, returning its new value. A missing or expired key starts from delta.
+func (a *App) IncrementPluginKey(pluginId, key string, delta int64) (int64, *model.AppError) {
+	if err := a.migrateHashedPluginKey(pluginId, key); err != nil {
+		return 0, err
+	}
+
+	// The new value is not known until the counter is incremented, so delta stands in for its size.
+	usageDelta, err := a.checkPluginKeyValueQuota(pluginId, []*model.PluginKeyValue{{
+		PluginId: pluginId,
+		Key:      key,
+		Value:    []byte(strconv.FormatInt(delta, 10)),
+	}})
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := a.Srv.Store.Plugin().Increment(pluginId, key, delta)
+	if err != nil {
+		if err.StatusCode != http.StatusBadRequest {
+			mlog.Error(err.Error())
+		}
+		return 0, err
+	}
+
+	a.addPluginKeyValueUsage(pluginId, usageDelta)
+	a.notifyPluginOfKeyValueChange(pluginId, key)
+
+	return value, nil
+}
+
+// CopyPluginKey stores the value of the plugin's key under newKey as well, along with its expiry. It
+// fails with a conflict error if newKey already exists, and a not found error if the key does not.
+func (a *App) CopyPluginKey(pluginId, key, newKey string) *model.AppError {
+	storedKey, err := a.getStoredPluginKey(pluginId, key)
+	if err != nil {
+		return err
+	}
+
+	if err := a.migrateHashedPluginKey(pluginId, newKey); err != nil {
+		return err
+	}
+
+	kv, err := a.Srv.Store.Plugin().GetFromMaster(pluginId, storedKey)
+	if err != nil {
+		return err
+	}
+
+	usageDelta, err := a.checkPluginKeyValueQuota(pluginId, []*model.PluginKeyValue{{
+		PluginId: pluginId,
+		Key:      newKey,
+		Value:    kv.Value,
+	}})
+	if err != nil {
+		return err
+	}
+
+	copied, err := a.Srv.Store.Plugin().Copy(pluginId, storedKey, newKey)
+	if err != nil {
+		if err.StatusCode == http.StatusInternalServerError {
+			mlog.Error(err.Error())
+		}
+		return err
+	}
+	if !copied {
+		return model.NewAppError("CopyPluginKey", "app.plugin.kv.key_exists.app_error", map[string]interface{}{"Key": newKey}, "plugin_id="+pluginId, http.StatusConflict)
+	}
+
+	a.addPluginKeyValueUsage(pluginId, usageDelta)
+	a.notifyPluginOfKeyValueChange(pluginId, newKey)
+
+	return nil
+}
+
+// MovePluginKey atomically moves the value of the plugin's key to newKey, along with its expiry. It
+// fails with a conflict error if newKey already exists, leaving the key in place, and a not found
+// error if the key does not exist.
+func (a *App) MovePluginKey(pluginId, key, newKey string) *model.AppError {
+	storedKey, err := a.getStoredPluginKey(pluginId, key)
+	if err != nil {
+		return err
+	}
+
+	if err := a.migrateHashedPluginKey(pluginId, newKey); err != nil {
+		return err
+	}
+
+	moved, err := a.Srv.Store.Plugin().Move(pluginId, storedKey, newKey)
+	if err != nil {
+		if err.StatusCode == http.StatusInternalServerError {
+			mlog.Error(err.Error())
+		}
+		return err
+	}
+	if !moved {
+		return model.NewAppError("MovePluginKey", "app.plugin.kv.key_exists.app_error", map[string]interface{}{"Key": newKey}, "plugin_id="+pluginId, http.StatusConflict)
+	}
+
+	a.notifyPluginOfKeyValueChange(pluginId, key)
+	a.notifyPluginOfKeyValueChange(pluginId, newKey)
+
+	return nil
+}
+
+// RenamePluginKeys passes each of the plugin's keys to transform, moving its value to the returned key,
+// or deleting it if keep is false. Keys not written since keys could be listed are left untouched.
+//
+// A key whose new key already exists is left in place, and the conflict is returned in the map of
+// failed keys, without stopping the other keys from being renamed. Any other error stops the rename,
+// leaving the keys already renamed as they are.
+func (a *App) RenamePluginKeys(pluginId string, transform func(key string) (newKey string, keep bool)) (map[string]*model.AppError, *model.AppError) {
+	// All keys are listed first, since renaming them changes the pages they are listed in.
+	var keys []string
+	for page := 0; ; page++ {
+		pageKeys, err := a.ListPluginKeys(pluginId, page, PLUGIN_DATA_EXPORT_BATCH_SIZE)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, pageKeys...)
+
+		if len(pageKeys) < PLUGIN_DATA_EXPORT_BATCH_SIZE {
+			break
+		}
+	}
+
+	failed := make(map[string]*model.AppError)
+	for _, key := range keys {
+		newKey, keep := transform(key)
+		if !keep {
+			if err := a.DeletePluginKey(pluginId, key); err != nil {
+				return failed, err
+			}
+			continue
+		}
+
+		if newKey == key {
+			continue
+		}
+
+		if err := a.MovePluginKey(pluginId, key, newKey); err != nil {
+			switch err.StatusCode {
+			case http.StatusNotFound:
+				// The key was deleted or expired since it was listed.
+			case http.StatusConflict, http.StatusBadRequest:
+				failed[key] = err
+			default:
+				return failed, err
+			}
+		}
+	}
+
+	return failed, nil
+}
+
+// LockPluginKey attempts to take the plugin's advisory lock named by key, returning a new token
+// identifying this acquisition if it was taken, or an empty string otherwise. The lock expires after
+// ttl unless released sooner with UnlockPluginKey and the token.
+func (a *App) LockPluginKey(pluginId, key string, ttl time.Duration) (string, *model.AppError) {
+	if ttl < time.Millisecond {
+		return "", model.NewAppError("LockPluginKey", "app.plugin.kv.lock_ttl.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	token := model.NewId()
+	lock := &model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      getKeyHash(PLUGIN_KEY_VALUE_LOCK_PREFIX + key),
+		Value:    []byte(token),
+		ExpireAt: model.GetMillis() + int64(ttl/time.Millisecond),
+	}
+
+	locked, err := a.compareAndSetStoredPluginKey(lock, nil)
+	if err != nil || !locked {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// UnlockPluginKey releases the plugin's advisory lock named by key if it is still held with the
+// given token, returning whether it was released.
+func (a *App) UnlockPluginKey(pluginId, key, token string) (bool, *model.AppError) {
+	if token == "" {
+		return false, nil
+	}
+
+	return a.compareAndDeleteStoredPluginKey(pluginId, getKeyHash(PLUGIN_KEY_VALUE_LOCK_PREFIX+key), []byte(token))
+}
+
+// DeleteAllExpiredPluginKeys removes the expired key-value pairs of all plugins from the database.
+// Expired key-value pairs are already treated as deleted, so this only reclaims their space.
+func (a *App) DeleteAllExpiredPluginKeys() {
+	deleted, err := a.Srv.Store.Plugin().DeleteAllExpired()
+	if err != nil {
+		mlog.Error("Failed to delete expired plugin key-value pairs", mlog.Err(err))
+		return
+	}
+
+	if deleted > 0 {
+		mlog.Debug("Deleted expired plugin key-value pairs", mlog.Int64("count", deleted))
+	}
+}
+
+// isValidPluginKeyValue checks a key-value pair about to be written, including that its value fits
+// within the configured limit.
+func (a *App) isValidPluginKeyValue(kv *model.PluginKeyValue) *model.AppError {
+	return kv.IsValid(*a.Config().PluginSettings.MaxKeyValueSizeBytes)
+}
+
+// encodePluginKeyValue returns the value to store for a plugin key-value pair, compressing values
+// larger than threshold bytes if compression is enabled and saves space. Values that happen to begin
+// with the compressed or encrypted value prefixes are always compressed so that they are read back
+// unchanged.
+func encodePluginKeyValue(value []byte, enableCompression bool, threshold int) []byte {
+	mustCompress := bytes.HasPrefix(value, pluginKeyValueCompressedPrefix) || bytes.HasPrefix(value, pluginKeyValueEncryptedPrefix)
+	if !mustCompress && (!enableCompression || len(value) <= threshold) {
+		return value
+	}
+
+	var buf bytes.Buffer
+	buf.Write(pluginKeyValueCompressedPrefix)
+	w := gzip.NewWriter(&buf)
+	w.Write(value)
+	w.Close()
+
+	if !mustCompress && buf.Len() >= len(value) {
+		return value
+	}
+
+	return buf.Bytes()
+}
+
+// decodePluginKeyValue reverses encodePluginKeyValue. Values stored before compression was
+// introduced are returned as is.
+func decodePluginKeyValue(stored []byte) []byte {
+	if !bytes.HasPrefix(stored, pluginKeyValueCompressedPrefix) {
+		return stored
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(stored[len(pluginKeyValueCompressedPrefix):]))
+	if err != nil {
+		return stored
+	}
+	defer r.Close()
 
-	if result.Err != nil {
-		mlog.Error(result.Err.Error())
+	value, err := ioutil.ReadAll(r)
+	if err != nil {
+		return stored
 	}
 
-	return result.Err
+	return value
 }
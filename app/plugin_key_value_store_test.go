@@ -0,0 +1,1178 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/mattermost/mattermost-server/store/storetest"
+)
+
+func TestEncodePluginKeyValue(t *testing.T) {
+	compressible := bytes.Repeat([]byte("compressible "), 200)
+	random := make([]byte, 2048)
+	rand.New(rand.NewSource(1)).Read(random)
+	prefixed := append(append([]byte{}, pluginKeyValueCompressedPrefix...), []byte("short")...)
+
+	for name, tc := range map[string]struct {
+		Value      []byte
+		Enable     bool
+		Threshold  int
+		Compressed bool
+	}{
+		"small value":              {[]byte("small"), true, 1024, false},
+		"large value":              {compressible, true, 1024, true},
+		"compression disabled":     {compressible, false, 1024, false},
+		"threshold of zero":        {bytes.Repeat([]byte("small "), 20), true, 0, true},
+		"incompressible value":     {random, true, 1024, false},
+		"value with stored prefix": {prefixed, false, 1024, true},
+		"empty value":              {[]byte{}, true, 0, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			stored := encodePluginKeyValue(tc.Value, tc.Enable, tc.Threshold)
+			assert.Equal(t, tc.Compressed, !bytes.Equal(stored, tc.Value))
+			assert.Equal(t, tc.Value, decodePluginKeyValue(stored))
+		})
+	}
+}
+
+func TestDecodePluginKeyValueCorrupt(t *testing.T) {
+	corrupt := append(append([]byte{}, pluginKeyValueCompressedPrefix...), []byte("not gzip")...)
+	assert.Equal(t, corrupt, decodePluginKeyValue(corrupt))
+}
+
+func TestPluginKeyValueCompression(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+	// Only fits once compressed.
+	value := bytes.Repeat([]byte("compressible "), model.PLUGIN_SETTINGS_DEFAULT_MAX_KEY_VALUE_SIZE/10)
+
+	require.Nil(t, th.App.SetPluginKey(pluginId, "key", value))
+	ret, err := th.App.GetPluginKey(pluginId, "key")
+	require.Nil(t, err)
+	assert.Equal(t, value, ret)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.EnableKeyValueCompression = false
+	})
+
+	ret, err = th.App.GetPluginKey(pluginId, "key")
+	require.Nil(t, err)
+	assert.Equal(t, value, ret)
+
+	err = th.App.SetPluginKey(pluginId, "key", value)
+	require.NotNil(t, err)
+	assert.Equal(t, "model.plugin_key_value.is_valid.value.app_error", err.Id)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, err.StatusCode)
+}
+
+func TestPluginKeyValueSizeLimit(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.EnableKeyValueCompression = false
+		*cfg.PluginSettings.MaxKeyValueSizeBytes = 100
+	})
+
+	pluginId := "testpluginid"
+
+	for name, tc := range map[string]struct {
+		Size  int
+		Valid bool
+	}{
+		"below the limit": {Size: 99, Valid: true},
+		"at the limit":    {Size: 100, Valid: true},
+		"above the limit": {Size: 101, Valid: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			key := model.NewId()
+			value := bytes.Repeat([]byte("a"), tc.Size)
+
+			err := th.App.SetPluginKey(pluginId, key, value)
+			ret, getErr := th.App.GetPluginKey(pluginId, key)
+			require.Nil(t, getErr)
+
+			if tc.Valid {
+				require.Nil(t, err)
+				assert.Equal(t, value, ret)
+			} else {
+				require.NotNil(t, err)
+				assert.Equal(t, "model.plugin_key_value.is_valid.value.app_error", err.Id)
+				assert.Equal(t, http.StatusRequestEntityTooLarge, err.StatusCode)
+				assert.Nil(t, ret)
+
+				_, err = th.App.CompareAndSetPluginKey(pluginId, key, nil, value)
+				require.NotNil(t, err)
+				assert.Equal(t, "model.plugin_key_value.is_valid.value.app_error", err.Id)
+
+				err = th.App.SetPluginKeys(pluginId, map[string][]byte{key: value})
+				require.NotNil(t, err)
+				assert.Equal(t, "model.plugin_key_value.is_valid.value.app_error", err.Id)
+			}
+		})
+	}
+
+	t.Run("values stored under a higher limit can still be compared", func(t *testing.T) {
+		value := bytes.Repeat([]byte("a"), 101)
+
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.MaxKeyValueSizeBytes = 200 })
+		require.Nil(t, th.App.SetPluginKey(pluginId, "large", value))
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.MaxKeyValueSizeBytes = 100 })
+
+		deleted, err := th.App.CompareAndDeletePluginKey(pluginId, "large", value)
+		require.Nil(t, err)
+		assert.True(t, deleted)
+	})
+}
+
+func BenchmarkEncodePluginKeyValue(b *testing.B) {
+	value := bytes.Repeat([]byte(`{"id":"abcdefghijklmnopqrstuvwxyz","count":12345}`), 100)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(value)))
+	for i := 0; i < b.N; i++ {
+		encodePluginKeyValue(value, true, model.PLUGIN_SETTINGS_DEFAULT_KV_COMPRESSION_SIZE)
+	}
+}
+
+func BenchmarkDecodePluginKeyValue(b *testing.B) {
+	value := bytes.Repeat([]byte(`{"id":"abcdefghijklmnopqrstuvwxyz","count":12345}`), 100)
+	stored := encodePluginKeyValue(value, true, model.PLUGIN_SETTINGS_DEFAULT_KV_COMPRESSION_SIZE)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(value)))
+	for i := 0; i < b.N; i++ {
+		decodePluginKeyValue(stored)
+	}
+}
+
+func TestSetPluginKeyWithExpiry(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+
+	require.Nil(t, th.App.SetPluginKeyWithExpiry(pluginId, "key", []byte("value"), 60))
+	ret, err := th.App.GetPluginKey(pluginId, "key")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("value"), ret)
+
+	require.Nil(t, th.App.SetPluginKeyWithExpiry(pluginId, "key", []byte("value"), 1))
+	time.Sleep(1100 * time.Millisecond)
+	ret, err = th.App.GetPluginKey(pluginId, "key")
+	require.Nil(t, err)
+	assert.Nil(t, ret)
+
+	th.App.DeleteAllExpiredPluginKeys()
+	usage, err := th.App.Srv.Store.Plugin().GetUsage(pluginId)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), usage.KeyCount)
+
+	err = th.App.SetPluginKeyWithExpiry(pluginId, "key", []byte("value"), -1)
+	require.NotNil(t, err)
+	assert.Equal(t, "app.plugin.kv.expire_in_seconds.app_error", err.Id)
+}
+
+func TestSetPluginKeyNil(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+
+	require.Nil(t, th.App.SetPluginKey(pluginId, "key", []byte("value")))
+	require.Nil(t, th.App.SetPluginKey(pluginId, "key", nil))
+
+	_, err := th.App.Srv.Store.Plugin().Get(pluginId, "key")
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+	_, exists, err := th.App.GetPluginKeyWithExists(pluginId, "key")
+	require.Nil(t, err)
+	assert.False(t, exists)
+
+	// Deleting a key that does not exist is not an error.
+	require.Nil(t, th.App.SetPluginKeyWithExpiry(pluginId, "missing", nil, 60))
+
+	// Unlike a nil value, an empty value is stored.
+	require.Nil(t, th.App.SetPluginKey(pluginId, "empty", []byte{}))
+	_, exists, err = th.App.GetPluginKeyWithExists(pluginId, "empty")
+	require.Nil(t, err)
+	assert.True(t, exists)
+
+	require.Nil(t, th.App.SetPluginKeysAtomic(pluginId, []model.PluginKVOp{
+		{Type: model.PLUGIN_KV_OP_SET, Key: "empty", Value: nil},
+	}))
+	_, exists, err = th.App.GetPluginKeyWithExists(pluginId, "empty")
+	require.Nil(t, err)
+	assert.False(t, exists)
+}
+
+func TestCompareAndSetPluginKey(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+
+	set, err := th.App.CompareAndSetPluginKey(pluginId, "key", nil, []byte("first"))
+	require.Nil(t, err)
+	assert.True(t, set)
+
+	set, err = th.App.CompareAndSetPluginKey(pluginId, "key", nil, []byte("other"))
+	require.Nil(t, err)
+	assert.False(t, set)
+
+	set, err = th.App.CompareAndSetPluginKey(pluginId, "key", []byte("wrong"), []byte("second"))
+	require.Nil(t, err)
+	assert.False(t, set)
+
+	set, err = th.App.CompareAndSetPluginKey(pluginId, "key", []byte("first"), []byte("second"))
+	require.Nil(t, err)
+	assert.True(t, set)
+
+	ret, err := th.App.GetPluginKey(pluginId, "key")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("second"), ret)
+
+	set, err = th.App.CompareAndSetPluginKey(pluginId, "missing", []byte("value"), []byte("second"))
+	require.Nil(t, err)
+	assert.False(t, set)
+
+	t.Run("value stored with other compression settings", func(t *testing.T) {
+		large := bytes.Repeat([]byte("compressible "), 200)
+
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.EnableKeyValueCompression = false
+		})
+		require.Nil(t, th.App.SetPluginKey(pluginId, "large", large))
+
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.EnableKeyValueCompression = true
+		})
+		set, err := th.App.CompareAndSetPluginKey(pluginId, "large", large, []byte("small"))
+		require.Nil(t, err)
+		assert.True(t, set)
+	})
+
+	t.Run("concurrent writers", func(t *testing.T) {
+		var wg sync.WaitGroup
+		var succeeded int32
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				set, err := th.App.CompareAndSetPluginKey(pluginId, "job", nil, []byte(model.NewId()))
+				require.Nil(t, err)
+				if set {
+					atomic.AddInt32(&succeeded, 1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), succeeded)
+	})
+}
+
+func TestDeletePluginKeyIfExists(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+
+	require.Nil(t, th.App.SetPluginKey(pluginId, "key", []byte("value")))
+
+	deleted, err := th.App.DeletePluginKeyIfExists(pluginId, "key")
+	require.Nil(t, err)
+	assert.True(t, deleted)
+
+	ret, err := th.App.GetPluginKey(pluginId, "key")
+	require.Nil(t, err)
+	assert.Nil(t, ret)
+
+	deleted, err = th.App.DeletePluginKeyIfExists(pluginId, "key")
+	require.Nil(t, err)
+	assert.False(t, deleted)
+
+	assert.Nil(t, th.App.DeletePluginKey(pluginId, "key"))
+}
+
+func TestDeleteAllPluginKeys(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+	otherPluginId := "othertestpluginid"
+
+	require.Nil(t, th.App.SetPluginKey(pluginId, "key1", []byte("value1")))
+	require.Nil(t, th.App.SetPluginKey(pluginId, "key2", []byte("value2")))
+	require.Nil(t, th.App.SetPluginKey(otherPluginId, "key1", []byte("other value")))
+	defer th.App.DeleteAllPluginKeys(otherPluginId)
+
+	require.Nil(t, th.App.DeleteAllPluginKeys(pluginId))
+
+	for _, key := range []string{"key1", "key2"} {
+		ret, err := th.App.GetPluginKey(pluginId, key)
+		require.Nil(t, err)
+		assert.Nil(t, ret)
+	}
+
+	ret, err := th.App.GetPluginKey(otherPluginId, "key1")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("other value"), ret)
+
+	// Deleting the keys of a plugin with none is not an error.
+	assert.Nil(t, th.App.DeleteAllPluginKeys(pluginId))
+}
+
+func TestCompareAndDeletePluginKey(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+
+	require.Nil(t, th.App.SetPluginKey(pluginId, "lock", []byte("owner")))
+
+	deleted, err := th.App.CompareAndDeletePluginKey(pluginId, "lock", []byte("other"))
+	require.Nil(t, err)
+	assert.False(t, deleted)
+
+	deleted, err = th.App.CompareAndDeletePluginKey(pluginId, "lock", []byte("owner"))
+	require.Nil(t, err)
+	assert.True(t, deleted)
+
+	ret, err := th.App.GetPluginKey(pluginId, "lock")
+	require.Nil(t, err)
+	assert.Nil(t, ret)
+
+	deleted, err = th.App.CompareAndDeletePluginKey(pluginId, "lock", []byte("owner"))
+	require.Nil(t, err)
+	assert.False(t, deleted)
+
+	t.Run("value stored with other compression settings", func(t *testing.T) {
+		large := bytes.Repeat([]byte("compressible "), 200)
+
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.EnableKeyValueCompression = false
+		})
+		require.Nil(t, th.App.SetPluginKey(pluginId, "large", large))
+
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.EnableKeyValueCompression = true
+		})
+		deleted, err := th.App.CompareAndDeletePluginKey(pluginId, "large", large)
+		require.Nil(t, err)
+		assert.True(t, deleted)
+	})
+}
+
+func TestHashedPluginKeys(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+
+	storeHashed := func(key string, value []byte) {
+		_, err := th.App.Srv.Store.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+			PluginId: pluginId,
+			Key:      getKeyHash(key),
+			Value:    value,
+		})
+		require.Nil(t, err)
+	}
+
+	t.Run("read", func(t *testing.T) {
+		storeHashed("read", []byte("value"))
+
+		ret, err := th.App.GetPluginKey(pluginId, "read")
+		require.Nil(t, err)
+		assert.Equal(t, []byte("value"), ret)
+
+		// The key has been moved to where it is stored as given.
+		_, appErr := th.App.Srv.Store.Plugin().Get(pluginId, getKeyHash("read"))
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+
+		kv, appErr := th.App.Srv.Store.Plugin().Get(pluginId, "read")
+		require.Nil(t, appErr)
+		assert.Equal(t, []byte("value"), kv.Value)
+	})
+
+	t.Run("written", func(t *testing.T) {
+		storeHashed("written", []byte("old"))
+
+		set, err := th.App.CompareAndSetPluginKey(pluginId, "written", nil, []byte("new"))
+		require.Nil(t, err)
+		assert.False(t, set)
+
+		set, err = th.App.CompareAndSetPluginKey(pluginId, "written", []byte("old"), []byte("new"))
+		require.Nil(t, err)
+		assert.True(t, set)
+
+		ret, err := th.App.GetPluginKey(pluginId, "written")
+		require.Nil(t, err)
+		assert.Equal(t, []byte("new"), ret)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		storeHashed("deleted", []byte("value"))
+
+		require.Nil(t, th.App.DeletePluginKey(pluginId, "deleted"))
+
+		ret, err := th.App.GetPluginKey(pluginId, "deleted")
+		require.Nil(t, err)
+		assert.Nil(t, ret)
+	})
+
+	t.Run("too long to store as given", func(t *testing.T) {
+		longKey := strings.Repeat("a", model.KEY_VALUE_KEY_MAX_RUNES+1)
+		storeHashed(longKey, []byte("value"))
+
+		ret, err := th.App.GetPluginKey(pluginId, longKey)
+		require.Nil(t, err)
+		assert.Equal(t, []byte("value"), ret)
+
+		assert.NotNil(t, th.App.SetPluginKey(pluginId, longKey, []byte("new")))
+
+		require.Nil(t, th.App.DeletePluginKey(pluginId, longKey))
+
+		ret, err = th.App.GetPluginKey(pluginId, longKey)
+		require.Nil(t, err)
+		assert.Nil(t, ret)
+	})
+}
+
+func TestPluginKeysBatch(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+
+	require.Nil(t, th.App.SetPluginKeys(pluginId, map[string][]byte{
+		"a": []byte("1"),
+		"b": []byte("2"),
+	}))
+
+	values, err := th.App.GetPluginKeys(pluginId, []string{"a", "b", "missing"})
+	require.Nil(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, values)
+
+	t.Run("all or nothing", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.MaxKeyValueSizeBytes = 100 })
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.MaxKeyValueSizeBytes = model.PLUGIN_SETTINGS_DEFAULT_MAX_KEY_VALUE_SIZE
+		})
+
+		err := th.App.SetPluginKeys(pluginId, map[string][]byte{
+			"a":     []byte("changed"),
+			"large": []byte(strings.Repeat("a", 101)),
+		})
+		require.NotNil(t, err)
+
+		values, err := th.App.GetPluginKeys(pluginId, []string{"a", "large"})
+		require.Nil(t, err)
+		assert.Equal(t, map[string][]byte{"a": []byte("1")}, values)
+
+		err = th.App.SetPluginKeys(pluginId, map[string][]byte{
+			"a": []byte("changed"),
+			strings.Repeat("a", model.KEY_VALUE_KEY_MAX_RUNES+1): []byte("value"),
+		})
+		require.NotNil(t, err)
+
+		ret, err := th.App.GetPluginKey(pluginId, "a")
+		require.Nil(t, err)
+		assert.Equal(t, []byte("1"), ret)
+	})
+
+	t.Run("hashed keys", func(t *testing.T) {
+		_, err := th.App.Srv.Store.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+			PluginId: pluginId,
+			Key:      getKeyHash("hashed"),
+			Value:    []byte("old"),
+		})
+		require.Nil(t, err)
+
+		values, err := th.App.GetPluginKeys(pluginId, []string{"hashed"})
+		require.Nil(t, err)
+		assert.Equal(t, map[string][]byte{"hashed": []byte("old")}, values)
+
+		_, err = th.App.Srv.Store.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+			PluginId: pluginId,
+			Key:      getKeyHash("replaced"),
+			Value:    []byte("old"),
+		})
+		require.Nil(t, err)
+		require.Nil(t, th.App.SetPluginKeys(pluginId, map[string][]byte{"replaced": []byte("new")}))
+
+		_, err = th.App.Srv.Store.Plugin().Get(pluginId, getKeyHash("replaced"))
+		assert.NotNil(t, err)
+
+		ret, err := th.App.GetPluginKey(pluginId, "replaced")
+		require.Nil(t, err)
+		assert.Equal(t, []byte("new"), ret)
+	})
+
+	t.Run("compressed values", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.EnableKeyValueCompression = true
+			*cfg.PluginSettings.KeyValueCompressionThreshold = 0
+		})
+
+		value := bytes.Repeat([]byte("compressible"), 100)
+		require.Nil(t, th.App.SetPluginKeys(pluginId, map[string][]byte{"compressed": value}))
+
+		values, err := th.App.GetPluginKeys(pluginId, []string{"compressed"})
+		require.Nil(t, err)
+		assert.Equal(t, value, values["compressed"])
+	})
+}
+
+func TestSetPluginKeysAtomic(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+
+	require.Nil(t, th.App.SetPluginKey(pluginId, "deleted", []byte("value")))
+
+	require.Nil(t, th.App.SetPluginKeysAtomic(pluginId, []model.PluginKVOp{
+		{Type: model.PLUGIN_KV_OP_SET, Key: "a", Value: []byte("1")},
+		{Type: model.PLUGIN_KV_OP_SET, Key: "b", Value: []byte("2")},
+		{Type: model.PLUGIN_KV_OP_DELETE, Key: "deleted"},
+	}))
+
+	values, err := th.App.GetPluginKeys(pluginId, []string{"a", "b", "deleted"})
+	require.Nil(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, values)
+
+	require.Nil(t, th.App.SetPluginKeysAtomic(pluginId, nil))
+
+	t.Run("all or nothing", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.MaxKeyValueSizeBytes = 100 })
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.MaxKeyValueSizeBytes = model.PLUGIN_SETTINGS_DEFAULT_MAX_KEY_VALUE_SIZE
+		})
+
+		for name, op := range map[string]model.PluginKVOp{
+			"value too large": {Type: model.PLUGIN_KV_OP_SET, Key: "large", Value: []byte(strings.Repeat("a", 101))},
+			"key too long":    {Type: model.PLUGIN_KV_OP_SET, Key: strings.Repeat("a", model.KEY_VALUE_KEY_MAX_RUNES+1), Value: []byte("value")},
+			"unknown type":    {Type: "rename", Key: "unknown"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				err := th.App.SetPluginKeysAtomic(pluginId, []model.PluginKVOp{
+					{Type: model.PLUGIN_KV_OP_SET, Key: "a", Value: []byte("changed")},
+					op,
+					{Type: model.PLUGIN_KV_OP_DELETE, Key: "b"},
+				})
+				require.NotNil(t, err)
+				assert.Equal(t, "app.plugin.kv.atomic.invalid_op.app_error", err.Id)
+				assert.Contains(t, err.Error(), op.Key)
+
+				values, err := th.App.GetPluginKeys(pluginId, []string{"a", "b"})
+				require.Nil(t, err)
+				assert.Equal(t, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, values)
+			})
+		}
+	})
+
+	t.Run("constraint violation mid-batch", func(t *testing.T) {
+		// Invalid UTF-8 passes validation but is rejected by the database.
+		err := th.App.SetPluginKeysAtomic(pluginId, []model.PluginKVOp{
+			{Type: model.PLUGIN_KV_OP_SET, Key: "a", Value: []byte("changed")},
+			{Type: model.PLUGIN_KV_OP_SET, Key: "bad\xff", Value: []byte("value")},
+			{Type: model.PLUGIN_KV_OP_DELETE, Key: "b"},
+		})
+		require.NotNil(t, err)
+
+		values, err := th.App.GetPluginKeys(pluginId, []string{"a", "b"})
+		require.Nil(t, err)
+		assert.Equal(t, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, values)
+	})
+
+	t.Run("hashed keys", func(t *testing.T) {
+		for _, key := range []string{"replaced", "removed"} {
+			_, err := th.App.Srv.Store.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+				PluginId: pluginId,
+				Key:      getKeyHash(key),
+				Value:    []byte("old"),
+			})
+			require.Nil(t, err)
+		}
+
+		require.Nil(t, th.App.SetPluginKeysAtomic(pluginId, []model.PluginKVOp{
+			{Type: model.PLUGIN_KV_OP_SET, Key: "replaced", Value: []byte("new")},
+			{Type: model.PLUGIN_KV_OP_DELETE, Key: "removed"},
+		}))
+
+		for _, key := range []string{"replaced", "removed"} {
+			_, err := th.App.Srv.Store.Plugin().Get(pluginId, getKeyHash(key))
+			assert.NotNil(t, err)
+		}
+
+		values, err := th.App.GetPluginKeys(pluginId, []string{"replaced", "removed"})
+		require.Nil(t, err)
+		assert.Equal(t, map[string][]byte{"replaced": []byte("new")}, values)
+	})
+
+	t.Run("compressed values", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.EnableKeyValueCompression = true
+			*cfg.PluginSettings.KeyValueCompressionThreshold = 0
+		})
+
+		value := bytes.Repeat([]byte("compressible"), 100)
+		require.Nil(t, th.App.SetPluginKeysAtomic(pluginId, []model.PluginKVOp{
+			{Type: model.PLUGIN_KV_OP_SET, Key: "compressed", Value: value},
+		}))
+
+		ret, err := th.App.GetPluginKey(pluginId, "compressed")
+		require.Nil(t, err)
+		assert.Equal(t, value, ret)
+	})
+}
+
+func TestPluginReadAfterWrite(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	serve := func(header string) (readsFromMaster bool) {
+		request := httptest.NewRequest(http.MethodGet, "/plugins/testpluginid/endpoint", nil)
+		if header != "" {
+			request.Header.Set(model.HEADER_READ_AFTER_WRITE, header)
+		}
+
+		th.App.servePluginRequest(nil, mux.SetURLVars(request, map[string]string{"plugin_id": "testpluginid"}), func(_ *plugin.Context, _ http.ResponseWriter, r *http.Request) {
+			readsFromMaster = th.App.pluginReadsFromMaster("testpluginid")
+			assert.False(t, th.App.pluginReadsFromMaster("otherpluginid"))
+		})
+
+		return
+	}
+
+	assert.False(t, serve(""))
+	assert.True(t, serve("true"))
+	assert.False(t, th.App.pluginReadsFromMaster("testpluginid"))
+
+	// Reads are served by the master until every such request has finished.
+	endFirst := th.App.beginPluginReadAfterWrite("testpluginid")
+	endSecond := th.App.beginPluginReadAfterWrite("testpluginid")
+	endFirst()
+	assert.True(t, th.App.pluginReadsFromMaster("testpluginid"))
+	endSecond()
+	assert.False(t, th.App.pluginReadsFromMaster("testpluginid"))
+
+	// The values read are the same either way.
+	require.Nil(t, th.App.SetPluginKey("testpluginid", "key", []byte("value")))
+	defer th.App.beginPluginReadAfterWrite("testpluginid")()
+
+	ret, err := th.App.GetPluginKey("testpluginid", "key")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("value"), ret)
+
+	values, err := th.App.GetPluginKeys("testpluginid", []string{"key", "missing"})
+	require.Nil(t, err)
+	assert.Equal(t, map[string][]byte{"key": []byte("value")}, values)
+}
+
+func TestGetPluginKeyWithOptions(t *testing.T) {
+	// The replica has yet to see the latest write to the key, which only the master holds.
+	mockStore := &storetest.Store{}
+	mockStore.PluginStore.On("Get", "testpluginid", getKeyHash("key")).Return(nil, model.NewAppError("SqlPluginStore.Get", "store.sql_plugin_store.get.app_error", nil, "", http.StatusNotFound))
+	mockStore.PluginStore.On("Get", "testpluginid", "key").Return(&model.PluginKeyValue{PluginId: "testpluginid", Key: "key", Value: []byte("stale")}, nil)
+	mockStore.PluginStore.On("GetFromMaster", "testpluginid", "key").Return(&model.PluginKeyValue{PluginId: "testpluginid", Key: "key", Value: []byte("fresh")}, nil)
+
+	app := App{
+		Srv: &Server{
+			Store: mockStore,
+		},
+	}
+	cfg := &model.Config{}
+	cfg.SetDefaults()
+	app.config.Store(cfg)
+
+	t.Run("reads from the replica by default", func(t *testing.T) {
+		ret, err := app.GetPluginKeyWithOptions("testpluginid", "key", model.PluginKVGetOptions{})
+		require.Nil(t, err)
+		assert.Equal(t, []byte("stale"), ret)
+
+		ret, err = app.GetPluginKey("testpluginid", "key")
+		require.Nil(t, err)
+		assert.Equal(t, []byte("stale"), ret)
+
+		mockStore.PluginStore.AssertNotCalled(t, "GetFromMaster", "testpluginid", "key")
+	})
+
+	t.Run("reads from the master when asked to", func(t *testing.T) {
+		ret, err := app.GetPluginKeyWithOptions("testpluginid", "key", model.PluginKVGetOptions{ReadFromMaster: true})
+		require.Nil(t, err)
+		assert.Equal(t, []byte("fresh"), ret)
+
+		mockStore.PluginStore.AssertNumberOfCalls(t, "GetFromMaster", 1)
+	})
+}
+
+func TestGetPluginKeyWithExists(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+
+	value, exists, err := th.App.GetPluginKeyWithExists(pluginId, "missing")
+	require.Nil(t, err)
+	assert.False(t, exists)
+	assert.Nil(t, value)
+
+	for name, stored := range map[string][]byte{
+		"empty": {},
+		"nil":   nil,
+		"value": []byte("value"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Nil(t, th.App.SetPluginKey(pluginId, name, stored))
+
+			value, exists, err := th.App.GetPluginKeyWithExists(pluginId, name)
+			require.Nil(t, err)
+			assert.True(t, exists)
+			require.NotNil(t, value)
+			assert.Equal(t, string(stored), string(value))
+
+			require.Nil(t, th.App.DeletePluginKey(pluginId, name))
+			_, exists, err = th.App.GetPluginKeyWithExists(pluginId, name)
+			require.Nil(t, err)
+			assert.False(t, exists)
+		})
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		require.Nil(t, th.App.SetPluginKeyWithExpiry(pluginId, "expired", []byte{}, 1))
+		time.Sleep(1100 * time.Millisecond)
+
+		_, exists, err := th.App.GetPluginKeyWithExists(pluginId, "expired")
+		require.Nil(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestListPluginKeys(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+
+	keys, err := th.App.ListPluginKeys(pluginId, 0, 10)
+	require.Nil(t, err)
+	assert.Empty(t, keys)
+
+	for _, key := range []string{"key1", "key2", "key3"} {
+		require.Nil(t, th.App.SetPluginKey(pluginId, key, []byte("value")))
+	}
+	set, err := th.App.CompareAndSetPluginKey(pluginId, "key4", nil, []byte("value"))
+	require.Nil(t, err)
+	require.True(t, set)
+	require.Nil(t, th.App.SetPluginKey("otherpluginid", "other", []byte("value")))
+
+	page1, err := th.App.ListPluginKeys(pluginId, 0, 3)
+	require.Nil(t, err)
+	page2, err := th.App.ListPluginKeys(pluginId, 1, 3)
+	require.Nil(t, err)
+	assert.Len(t, page1, 3)
+	assert.ElementsMatch(t, []string{"key1", "key2", "key3", "key4"}, append(page1, page2...))
+
+	_, err = th.App.ListPluginKeys(pluginId, -1, 10)
+	assert.NotNil(t, err)
+
+	_, err = th.App.ListPluginKeys(pluginId, 0, 0)
+	assert.NotNil(t, err)
+}
+
+func TestListPluginKeysWithPrefix(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+
+	for _, key := range []string{"user_1_settings", "user_2_settings", "userX1_settings", "user%1", "team_1"} {
+		require.Nil(t, th.App.SetPluginKey(pluginId, key, []byte("value")))
+	}
+	require.Nil(t, th.App.SetPluginKey("otherpluginid", "user_3_settings", []byte("value")))
+
+	keys, err := th.App.ListPluginKeysWithPrefix(pluginId, "user_", 0, 10)
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []string{"user_1_settings", "user_2_settings"}, keys)
+
+	keys, err = th.App.ListPluginKeysWithPrefix(pluginId, "user%", 0, 10)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"user%1"}, keys)
+
+	_, err = th.App.ListPluginKeysWithPrefix(pluginId, "user_", -1, 10)
+	assert.NotNil(t, err)
+
+	_, err = th.App.ListPluginKeysWithPrefix(pluginId, "user_", 0, 0)
+	assert.NotNil(t, err)
+}
+
+func TestIncrementPluginKey(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+
+	t.Run("concurrent increments", func(t *testing.T) {
+		const goroutines = 10
+		const increments = 20
+
+		var wg sync.WaitGroup
+		for i := 0; i < goroutines; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for j := 0; j < increments; j++ {
+					_, err := th.App.IncrementPluginKey(pluginId, "counter", 1)
+					assert.Nil(t, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		ret, err := th.App.GetPluginKey(pluginId, "counter")
+		require.Nil(t, err)
+		assert.Equal(t, []byte("200"), ret)
+
+		value, err := th.App.IncrementPluginKey(pluginId, "counter", -201)
+		require.Nil(t, err)
+		assert.Equal(t, int64(-1), value)
+	})
+
+	t.Run("hashed key", func(t *testing.T) {
+		_, err := th.App.Srv.Store.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+			PluginId: pluginId,
+			Key:      getKeyHash("hashed"),
+			Value:    []byte("41"),
+		})
+		require.Nil(t, err)
+
+		value, err := th.App.IncrementPluginKey(pluginId, "hashed", 1)
+		require.Nil(t, err)
+		assert.Equal(t, int64(42), value)
+	})
+
+	t.Run("not a number", func(t *testing.T) {
+		require.Nil(t, th.App.SetPluginKey(pluginId, "text", []byte("value")))
+
+		_, err := th.App.IncrementPluginKey(pluginId, "text", 1)
+		require.NotNil(t, err)
+		assert.Equal(t, "store.sql_plugin_store.increment.not_a_number.app_error", err.Id)
+	})
+
+	t.Run("key too long", func(t *testing.T) {
+		_, err := th.App.IncrementPluginKey(pluginId, strings.Repeat("a", model.KEY_VALUE_KEY_MAX_RUNES+1), 1)
+		require.NotNil(t, err)
+		assert.Equal(t, "model.plugin_key_value.is_valid.key.app_error", err.Id)
+	})
+}
+
+func TestCopyAndMovePluginKey(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "com.example." + model.NewId()
+	defer func() {
+		th.App.DeleteAllPluginKeys(pluginId)
+	}()
+
+	require.Nil(t, th.App.SetPluginKeyWithExpiry(pluginId, "key", []byte("value"), 60))
+	require.Nil(t, th.App.SetPluginKey(pluginId, "existing", []byte("existing")))
+
+	require.Nil(t, th.App.CopyPluginKey(pluginId, "key", "copy"))
+	for _, key := range []string{"key", "copy"} {
+		ret, err := th.App.GetPluginKey(pluginId, key)
+		require.Nil(t, err)
+		assert.Equal(t, []byte("value"), ret)
+	}
+
+	// The copy expires along with the key.
+	kv, err := th.App.Srv.Store.Plugin().Get(pluginId, "copy")
+	require.Nil(t, err)
+	assert.NotZero(t, kv.ExpireAt)
+
+	err = th.App.CopyPluginKey(pluginId, "key", "existing")
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusConflict, err.StatusCode)
+
+	err = th.App.MovePluginKey(pluginId, "key", "existing")
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusConflict, err.StatusCode)
+
+	require.Nil(t, th.App.MovePluginKey(pluginId, "key", "moved"))
+	ret, err := th.App.GetPluginKey(pluginId, "key")
+	require.Nil(t, err)
+	assert.Nil(t, ret)
+	ret, err = th.App.GetPluginKey(pluginId, "moved")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("value"), ret)
+
+	err = th.App.MovePluginKey(pluginId, "key", "other")
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+	t.Run("hashed key", func(t *testing.T) {
+		_, err := th.App.Srv.Store.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+			PluginId: pluginId,
+			Key:      getKeyHash("hashed"),
+			Value:    []byte("hashed value"),
+		})
+		require.Nil(t, err)
+
+		require.Nil(t, th.App.MovePluginKey(pluginId, "hashed", "unhashed"))
+		ret, err := th.App.GetPluginKey(pluginId, "unhashed")
+		require.Nil(t, err)
+		assert.Equal(t, []byte("hashed value"), ret)
+	})
+}
+
+func TestRenamePluginKeys(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "com.example." + model.NewId()
+	defer func() {
+		th.App.DeleteAllPluginKeys(pluginId)
+	}()
+
+	require.Nil(t, th.App.SetPluginKeys(pluginId, map[string][]byte{
+		"user:1":  []byte("one"),
+		"user:2":  []byte("two"),
+		"user:3":  []byte("three"),
+		"u/3":     []byte("already migrated"),
+		"stale:1": []byte("stale"),
+		"config":  []byte("config"),
+	}))
+
+	failed, err := th.App.RenamePluginKeys(pluginId, func(key string) (string, bool) {
+		switch {
+		case strings.HasPrefix(key, "user:"):
+			return "u/" + strings.TrimPrefix(key, "user:"), true
+		case strings.HasPrefix(key, "stale:"):
+			return "", false
+		default:
+			return key, true
+		}
+	})
+	require.Nil(t, err)
+
+	// The colliding key is reported and left in place, without stopping the others.
+	if assert.Len(t, failed, 1) {
+		assert.Equal(t, http.StatusConflict, failed["user:3"].StatusCode)
+	}
+
+	for key, expected := range map[string][]byte{
+		"u/1":     []byte("one"),
+		"u/2":     []byte("two"),
+		"u/3":     []byte("already migrated"),
+		"user:1":  nil,
+		"user:2":  nil,
+		"user:3":  []byte("three"),
+		"stale:1": nil,
+		"config":  []byte("config"),
+	} {
+		ret, err := th.App.GetPluginKey(pluginId, key)
+		require.Nil(t, err)
+		assert.Equal(t, expected, ret, key)
+	}
+}
+
+func TestLockPluginKey(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+
+	token1, err := th.App.LockPluginKey(pluginId, "key", time.Minute)
+	require.Nil(t, err)
+	assert.NotEmpty(t, token1)
+
+	token, err := th.App.LockPluginKey(pluginId, "key", time.Minute)
+	require.Nil(t, err)
+	assert.Empty(t, token)
+
+	// Locks are kept apart from the plugin's keys.
+	ret, err := th.App.GetPluginKey(pluginId, "key")
+	require.Nil(t, err)
+	assert.Nil(t, ret)
+
+	unlocked, err := th.App.UnlockPluginKey(pluginId, "key", model.NewId())
+	require.Nil(t, err)
+	assert.False(t, unlocked)
+
+	unlocked, err = th.App.UnlockPluginKey(pluginId, "key", "")
+	require.Nil(t, err)
+	assert.False(t, unlocked)
+
+	unlocked, err = th.App.UnlockPluginKey(pluginId, "key", token1)
+	require.Nil(t, err)
+	assert.True(t, unlocked)
+
+	token2, err := th.App.LockPluginKey(pluginId, "key", time.Minute)
+	require.Nil(t, err)
+	assert.NotEmpty(t, token2)
+	assert.NotEqual(t, token1, token2)
+
+	// The token of an earlier acquisition does not release a later one.
+	unlocked, err = th.App.UnlockPluginKey(pluginId, "key", token1)
+	require.Nil(t, err)
+	assert.False(t, unlocked)
+
+	t.Run("expired lock", func(t *testing.T) {
+		expired, err := th.App.LockPluginKey(pluginId, "expiring", 100*time.Millisecond)
+		require.Nil(t, err)
+		require.NotEmpty(t, expired)
+
+		time.Sleep(200 * time.Millisecond)
+
+		token, err := th.App.LockPluginKey(pluginId, "expiring", time.Minute)
+		require.Nil(t, err)
+		assert.NotEmpty(t, token)
+
+		unlocked, err := th.App.UnlockPluginKey(pluginId, "expiring", expired)
+		require.Nil(t, err)
+		assert.False(t, unlocked)
+	})
+
+	t.Run("invalid ttl", func(t *testing.T) {
+		_, err := th.App.LockPluginKey(pluginId, "invalid", 0)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestPluginLockContention(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	manifest := &model.Manifest{Id: "testpluginid"}
+
+	const nodes = 4
+	const lockersPerNode = 4
+	const iterations = 10
+
+	var holders, maxHolders, acquisitions int32
+	counter := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < nodes; i++ {
+		// Each plugin API stands in for the plugin running on a separate node of a cluster.
+		helpers := &plugin.HelpersImpl{API: NewPluginAPI(th.App, manifest)}
+
+		for j := 0; j < lockersPerNode; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for k := 0; k < iterations; k++ {
+					err := helpers.WithLock("hot", time.Minute, func() {
+						current := atomic.AddInt32(&holders, 1)
+						defer atomic.AddInt32(&holders, -1)
+
+						for {
+							highest := atomic.LoadInt32(&maxHolders)
+							if current <= highest || atomic.CompareAndSwapInt32(&maxHolders, highest, current) {
+								break
+							}
+						}
+
+						value := counter
+						time.Sleep(time.Duration(rand.Intn(2)) * time.Millisecond)
+						counter = value + 1
+
+						atomic.AddInt32(&acquisitions, 1)
+					})
+					assert.Nil(t, err)
+				}
+			}()
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Minute):
+		require.FailNow(t, "timed out waiting for lockers to finish")
+	}
+
+	assert.EqualValues(t, 1, maxHolders)
+	assert.EqualValues(t, nodes*lockersPerNode*iterations, acquisitions)
+	assert.Equal(t, nodes*lockersPerNode*iterations, counter)
+
+	t.Run("crashed holder", func(t *testing.T) {
+		crashed := NewPluginAPI(th.App, manifest)
+		token, err := crashed.KVLock("abandoned", 100*time.Millisecond)
+		require.Nil(t, err)
+		require.NotEmpty(t, token)
+
+		ran := false
+		helpers := &plugin.HelpersImpl{API: NewPluginAPI(th.App, manifest)}
+		require.Nil(t, helpers.WithLock("abandoned", time.Minute, func() {
+			ran = true
+		}))
+		assert.True(t, ran)
+	})
+
+	t.Run("expired holder on the same node", func(t *testing.T) {
+		// Both goroutines share the plugin API, as they would within a single plugin process.
+		api := NewPluginAPI(th.App, manifest)
+
+		slowToken, err := api.KVLock("expiring", 100*time.Millisecond)
+		require.Nil(t, err)
+		require.NotEmpty(t, slowToken)
+
+		time.Sleep(200 * time.Millisecond)
+
+		taken := make(chan string)
+		released := make(chan struct{})
+		go func() {
+			token, err := api.KVLock("expiring", time.Minute)
+			assert.Nil(t, err)
+			taken <- token
+			<-released
+			assert.Nil(t, api.KVUnlock("expiring", token))
+		}()
+
+		token := <-taken
+		require.NotEmpty(t, token)
+
+		// The goroutine whose lock expired releasing it late must not free the lock taken since.
+		require.Nil(t, api.KVUnlock("expiring", slowToken))
+		other, err := api.KVLock("expiring", time.Minute)
+		require.Nil(t, err)
+		assert.Empty(t, other)
+
+		close(released)
+		time.Sleep(100 * time.Millisecond)
+
+		other, err = api.KVLock("expiring", time.Minute)
+		require.Nil(t, err)
+		assert.NotEmpty(t, other)
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+type pluginLifecycleEvent struct {
+	hookId   int
+	manifest *model.Manifest
+}
+
+// notifyPluginsOfActivation queues the OnPluginActivated hook for the plugin with the given manifest.
+func (a *App) notifyPluginsOfActivation(manifest *model.Manifest) {
+	a.queuePluginLifecycleEvent(pluginLifecycleEvent{plugin.OnPluginActivatedId, manifest})
+}
+
+// notifyPluginsOfDeactivation queues the OnPluginDeactivated hook for the plugin with the given
+// manifest.
+func (a *App) notifyPluginsOfDeactivation(manifest *model.Manifest) {
+	a.queuePluginLifecycleEvent(pluginLifecycleEvent{plugin.OnPluginDeactivatedId, manifest})
+}
+
+// queuePluginLifecycleEvent delivers plugin lifecycle hooks in order on a single goroutine without
+// blocking the caller. Plugins receiving the hooks may change the state of other plugins in
+// response, which queues further events rather than re-entering the code activating plugins.
+func (a *App) queuePluginLifecycleEvent(event pluginLifecycleEvent) {
+	if event.manifest == nil {
+		return
+	}
+
+	a.pluginLifecycleLock.Lock()
+	defer a.pluginLifecycleLock.Unlock()
+
+	a.pluginLifecycleQueue = append(a.pluginLifecycleQueue, event)
+	if a.pluginLifecycleDraining {
+		return
+	}
+
+	a.pluginLifecycleDraining = true
+	a.Go(a.drainPluginLifecycleEvents)
+}
+
+func (a *App) drainPluginLifecycleEvents() {
+	for {
+		a.pluginLifecycleLock.Lock()
+		if len(a.pluginLifecycleQueue) == 0 {
+			a.pluginLifecycleDraining = false
+			a.pluginLifecycleLock.Unlock()
+			return
+		}
+		event := a.pluginLifecycleQueue[0]
+		a.pluginLifecycleQueue = a.pluginLifecycleQueue[1:]
+		a.pluginLifecycleLock.Unlock()
+
+		a.deliverPluginLifecycleEvent(event)
+	}
+}
+
+func (a *App) deliverPluginLifecycleEvent(event pluginLifecycleEvent) {
+	if !a.PluginsReady() {
+		return
+	}
+
+	a.Plugins.RunMultiPluginHookWithId(func(pluginId string, hooks plugin.Hooks) bool {
+		if pluginId == event.manifest.Id {
+			return true
+		}
+
+		if event.hookId == plugin.OnPluginActivatedId {
+			hooks.OnPluginActivated(event.manifest)
+		} else {
+			hooks.OnPluginDeactivated(event.manifest)
+		}
+		return true
+	}, event.hookId)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+const (
+	PLUGIN_METRICS_REQUEST_TIMEOUT   = 5 * time.Second
+	PLUGIN_METRICS_RATE_PER_SEC      = 2
+	PLUGIN_METRICS_RATE_MAX_BURST    = 10
+	PLUGIN_METRICS_MEMORY_STORE_SIZE = 1000
+)
+
+// InitPluginMetricsRoutes registers /plugins/{plugin_id}/metrics on the given router, routing
+// requests to the ServeMetrics hook of the plugin. It is called with the router of the metrics
+// listener when a metrics server is registered, so that plugins do not need to open listening
+// ports of their own. Requests are answered only while metrics are enabled.
+func (a *App) InitPluginMetricsRoutes(router *mux.Router) error {
+	rateLimiter, err := NewRateLimiter(&model.RateLimitSettings{
+		PerSec:           model.NewInt(PLUGIN_METRICS_RATE_PER_SEC),
+		MaxBurst:         model.NewInt(PLUGIN_METRICS_RATE_MAX_BURST),
+		MemoryStoreSize:  model.NewInt(PLUGIN_METRICS_MEMORY_STORE_SIZE),
+		VaryByRemoteAddr: model.NewBool(false),
+		VaryByUser:       model.NewBool(false),
+	})
+	if err != nil {
+		return err
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rateLimiter.RateLimitWriter(mux.Vars(r)["plugin_id"], w) {
+			return
+		}
+
+		a.ServePluginMetricsRequest(w, r)
+	})
+
+	router.Handle("/plugins/{plugin_id:[A-Za-z0-9\\_\\-\\.]+}/metrics", http.TimeoutHandler(handler, PLUGIN_METRICS_REQUEST_TIMEOUT, "")).Methods("GET")
+
+	return nil
+}
+
+// ServePluginMetricsRequest passes the request to the ServeMetrics hook of the plugin identified by
+// the plugin_id route variable. Plugins not implementing the hook respond with a 404.
+func (a *App) ServePluginMetricsRequest(w http.ResponseWriter, r *http.Request) {
+	if !*a.Config().MetricsSettings.Enable || !a.PluginsReady() {
+		http.NotFound(w, r)
+		return
+	}
+
+	pluginId := mux.Vars(r)["plugin_id"]
+	hooks, err := a.Plugins.HooksForPlugin(pluginId)
+	if err != nil {
+		a.Log.Debug("Access to metrics route for non-existent plugin", mlog.String("missing_plugin_id", pluginId), mlog.Err(err))
+		http.NotFound(w, r)
+		return
+	}
+
+	// The metrics listener is unauthenticated, so make sure nothing resembling credentials is
+	// passed along to the plugin.
+	r.Header.Del(model.HEADER_AUTH)
+	r.Header.Del("Cookie")
+	r.Header.Del("Mattermost-User-Id")
+	r.URL.Path = "/" + path.Base(r.URL.Path)
+	r.URL.RawQuery = ""
+	r = plugin.RequestWithID(r, pluginId)
+
+	hooks.ServeMetrics(newPluginContext(), w, r)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// The keys below are stored without hashing, so they can never collide with the keys of plugins,
+// which are always stored as base64-encoded hashes.
+const (
+	PLUGIN_SCHEMA_VERSION_KEY = "mmi_schema_version"
+	PLUGIN_MIGRATION_LOCK_KEY = "mmi_migration_lock"
+
+	PLUGIN_MIGRATION_LOCK_TIMEOUT       = 10 * time.Minute
+	PLUGIN_MIGRATION_LOCK_POLL_INTERVAL = time.Second
+)
+
+// migratePluginSchema invokes migrate if the schema version stored for the plugin is lower than
+// the one declared by its manifest, and records the new version once migrate succeeds.
+//
+// A lock held in the plugin's key-value store ensures only one server of a cluster migrates at a
+// time. Other servers wait for the lock, then find the stored version up to date.
+func (a *App) migratePluginSchema(manifest *model.Manifest, migrate func(fromVersion, toVersion int) error) error {
+	for {
+		acquired, err := a.acquirePluginMigrationLock(manifest.Id)
+		if err != nil {
+			return err
+		}
+		if acquired {
+			break
+		}
+
+		time.Sleep(PLUGIN_MIGRATION_LOCK_POLL_INTERVAL)
+	}
+	defer a.releasePluginMigrationLock(manifest.Id)
+
+	var storedVersion []byte
+	fromVersion := 0
+	if kv, appErr := a.Srv.Store.Plugin().Get(manifest.Id, PLUGIN_SCHEMA_VERSION_KEY); appErr != nil {
+		if appErr.StatusCode != http.StatusNotFound {
+			return appErr
+		}
+	} else {
+		storedVersion = kv.Value
+		version, err := strconv.Atoi(string(storedVersion))
+		if err != nil {
+			return model.NewAppError("migratePluginSchema", "app.plugin.migrate.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		fromVersion = version
+	}
+
+	if fromVersion >= manifest.SchemaVersion {
+		return nil
+	}
+
+	a.Log.Info("Migrating plugin schema", mlog.String("plugin_id", manifest.Id), mlog.Int("from_version", fromVersion), mlog.Int("to_version", manifest.SchemaVersion))
+
+	if err := migrate(fromVersion, manifest.SchemaVersion); err != nil {
+		return err
+	}
+
+	kv := &model.PluginKeyValue{
+		PluginId: manifest.Id,
+		Key:      PLUGIN_SCHEMA_VERSION_KEY,
+		Value:    []byte(strconv.Itoa(manifest.SchemaVersion)),
+	}
+	if set, err := a.Srv.Store.Plugin().CompareAndSet(kv, storedVersion); err != nil {
+		return err
+	} else if !set {
+		return model.NewAppError("migratePluginSchema", "app.plugin.migrate.app_error", nil, "schema version changed during migration", http.StatusConflict)
+	}
+
+	return nil
+}
+
+// acquirePluginMigrationLock attempts to take the migration lock of the given plugin, taking over
+// locks that have expired because the server holding them went away.
+func (a *App) acquirePluginMigrationLock(pluginId string) (bool, *model.AppError) {
+	lock := &model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      PLUGIN_MIGRATION_LOCK_KEY,
+		Value:    []byte(strconv.FormatInt(model.GetMillis()+int64(PLUGIN_MIGRATION_LOCK_TIMEOUT/time.Millisecond), 10)),
+	}
+
+	if set, appErr := a.Srv.Store.Plugin().CompareAndSet(lock, nil); appErr != nil {
+		return false, appErr
+	} else if set {
+		return true, nil
+	}
+
+	existing, appErr := a.Srv.Store.Plugin().Get(pluginId, PLUGIN_MIGRATION_LOCK_KEY)
+	if appErr != nil {
+		if appErr.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, appErr
+	}
+
+	if expireAt, err := strconv.ParseInt(string(existing.Value), 10, 64); err == nil && expireAt > model.GetMillis() {
+		return false, nil
+	}
+
+	return a.Srv.Store.Plugin().CompareAndSet(lock, existing.Value)
+}
+
+func (a *App) releasePluginMigrationLock(pluginId string) {
+	if _, err := a.Srv.Store.Plugin().Delete(pluginId, PLUGIN_MIGRATION_LOCK_KEY); err != nil {
+		a.Log.Error("Failed to release plugin migration lock", mlog.String("plugin_id", pluginId), mlog.Err(err))
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigratePluginSchema(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	manifest := &model.Manifest{Id: model.NewId(), SchemaVersion: 2}
+
+	var calls [][2]int
+	migrate := func(fromVersion, toVersion int) error {
+		calls = append(calls, [2]int{fromVersion, toVersion})
+		return nil
+	}
+
+	require.NoError(t, th.App.migratePluginSchema(manifest, migrate))
+	assert.Equal(t, [][2]int{{0, 2}}, calls)
+
+	// Nothing to do until the version is bumped again
+	require.NoError(t, th.App.migratePluginSchema(manifest, migrate))
+	assert.Len(t, calls, 1)
+
+	manifest.SchemaVersion = 3
+	require.NoError(t, th.App.migratePluginSchema(manifest, migrate))
+	assert.Equal(t, [2]int{2, 3}, calls[1])
+
+	t.Run("failed migration keeps the stored version", func(t *testing.T) {
+		manifest.SchemaVersion = 4
+		err := th.App.migratePluginSchema(manifest, func(fromVersion, toVersion int) error {
+			return errors.New("failed")
+		})
+		require.Error(t, err)
+
+		kv, appErr := th.App.Srv.Store.Plugin().Get(manifest.Id, PLUGIN_SCHEMA_VERSION_KEY)
+		require.Nil(t, appErr)
+		assert.Equal(t, "3", string(kv.Value))
+	})
+
+	t.Run("expired lock is taken over", func(t *testing.T) {
+		_, appErr := th.App.Srv.Store.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+			PluginId: manifest.Id,
+			Key:      PLUGIN_MIGRATION_LOCK_KEY,
+			Value:    []byte(strconv.FormatInt(model.GetMillis()-1000, 10)),
+		})
+		require.Nil(t, appErr)
+
+		acquired, err := th.App.acquirePluginMigrationLock(manifest.Id)
+		require.Nil(t, err)
+		assert.True(t, acquired)
+
+		acquired, err = th.App.acquirePluginMigrationLock(manifest.Id)
+		require.Nil(t, err)
+		assert.False(t, acquired)
+
+		th.App.releasePluginMigrationLock(manifest.Id)
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/utils"
+)
+
+const (
+	PLUGIN_NOTIFICATION_RATE_PER_SEC      = 5
+	PLUGIN_NOTIFICATION_RATE_MAX_BURST    = 50
+	PLUGIN_NOTIFICATION_MEMORY_STORE_SIZE = 1000
+)
+
+func (a *App) getPluginNotificationRateLimiter() *RateLimiter {
+	a.pluginNotificationRateLimiterOnce.Do(func() {
+		rateLimiter, err := NewRateLimiter(&model.RateLimitSettings{
+			PerSec:           model.NewInt(PLUGIN_NOTIFICATION_RATE_PER_SEC),
+			MaxBurst:         model.NewInt(PLUGIN_NOTIFICATION_RATE_MAX_BURST),
+			MemoryStoreSize:  model.NewInt(PLUGIN_NOTIFICATION_MEMORY_STORE_SIZE),
+			VaryByRemoteAddr: model.NewBool(false),
+			VaryByUser:       model.NewBool(false),
+		})
+		if err != nil {
+			mlog.Error("Unable to create plugin notification rate limiter", mlog.Err(err))
+			return
+		}
+		a.pluginNotificationRateLimiter = rateLimiter
+	})
+
+	return a.pluginNotificationRateLimiter
+}
+
+// SendPluginNotification sends a desktop and mobile notification to the user on behalf of the given
+// plugin without creating a post. The user's notification preferences for the channel, muted channels
+// and Do Not Disturb status are respected just as for a mention, in which case nothing is sent. Each
+// plugin is rate limited separately.
+func (a *App) SendPluginNotification(pluginId, userId string, notification *model.PluginNotification) *model.AppError {
+	if err := notification.IsValid(); err != nil {
+		return err
+	}
+
+	if rateLimiter := a.getPluginNotificationRateLimiter(); rateLimiter == nil || rateLimiter.IsLimited(pluginId) {
+		return model.NewAppError("SendPluginNotification", "app.plugin.notify_user.rate_limited.app_error", nil, "plugin_id="+pluginId, http.StatusTooManyRequests)
+	}
+
+	user, err := a.GetUser(userId)
+	if err != nil {
+		return err
+	}
+
+	channel, err := a.GetChannel(notification.ChannelId)
+	if err != nil {
+		return err
+	}
+
+	member, err := a.GetChannelMember(channel.Id, user.Id)
+	if err != nil {
+		return model.NewAppError("SendPluginNotification", "app.plugin.notify_user.not_member.app_error", nil, "user_id="+user.Id+", channel_id="+channel.Id, http.StatusForbidden)
+	}
+
+	status, err := a.GetStatus(user.Id)
+	if err != nil {
+		status = &model.Status{UserId: user.Id, Status: model.STATUS_OFFLINE, Manual: false, LastActivityAt: 0, ActiveChannel: ""}
+	}
+
+	if status.Status == model.STATUS_DND || status.Status == model.STATUS_OUT_OF_OFFICE {
+		return nil
+	}
+
+	if member.NotifyProps[model.MARK_UNREAD_NOTIFY_PROP] == model.CHANNEL_MARK_UNREAD_MENTION {
+		return nil
+	}
+
+	if doNotifyPropsAllowPluginNotification(user.NotifyProps, member.NotifyProps, model.DESKTOP_NOTIFY_PROP) {
+		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_PLUGIN_NOTIFICATION, "", "", user.Id, nil)
+		message.Add("plugin_id", pluginId)
+		message.Add("team_id", channel.TeamId)
+		message.Add("channel_id", channel.Id)
+		message.Add("post_id", notification.PostId)
+		message.Add("title", notification.Title)
+		message.Add("message", notification.Message)
+		a.Publish(message)
+	}
+
+	if a.canSendPushNotifications() &&
+		doNotifyPropsAllowPluginNotification(user.NotifyProps, member.NotifyProps, model.PUSH_NOTIFY_PROP) &&
+		DoesStatusAllowPushNotification(user.NotifyProps, status, channel.Id) {
+		if err := a.sendPluginPushNotification(user, channel, notification); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doNotifyPropsAllowPluginNotification treats a plugin notification as a mention of the user, so it
+// is only suppressed when notifications of the given kind are turned off.
+func doNotifyPropsAllowPluginNotification(userNotifyProps, channelNotifyProps model.StringMap, prop string) bool {
+	level := userNotifyProps[prop]
+	if channelLevel, ok := channelNotifyProps[prop]; ok && channelLevel != model.CHANNEL_NOTIFY_DEFAULT {
+		level = channelLevel
+	}
+
+	return level != model.USER_NOTIFY_NONE
+}
+
+func (a *App) sendPluginPushNotification(user *model.User, channel *model.Channel, notification *model.PluginNotification) *model.AppError {
+	sessions, err := a.getMobileAppSessions(user.Id)
+	if err != nil {
+		return err
+	}
+
+	msg := model.PushNotification{}
+	if badge := <-a.Srv.Store.User().GetUnreadCount(user.Id); badge.Err != nil {
+		msg.Badge = 1
+		mlog.Error("We could not get the unread message count for the user", mlog.String("user_id", user.Id), mlog.Err(badge.Err))
+	} else {
+		msg.Badge = int(badge.Data.(int64))
+	}
+
+	msg.Version = model.PUSH_MESSAGE_V2
+	msg.Type = model.PUSH_TYPE_MESSAGE
+	msg.TeamId = channel.TeamId
+	msg.ChannelId = channel.Id
+	msg.PostId = notification.PostId
+
+	contentsConfig := *a.Config().EmailSettings.PushNotificationContents
+	if contentsConfig != model.GENERIC_NO_CHANNEL_NOTIFICATION {
+		msg.ChannelName = channel.DisplayName
+	}
+
+	if contentsConfig == model.FULL_NOTIFICATION {
+		msg.Message = notification.Message
+		if notification.Title != "" {
+			msg.Message = notification.Title + ": " + notification.Message
+		}
+	} else {
+		msg.Message = utils.GetUserTranslations(user.Locale)("app.plugin.notify_user.push_message")
+	}
+
+	a.sendPushNotificationToSessions(msg, user.Id, sessions)
+
+	return nil
+}
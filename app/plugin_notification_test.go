@@ -0,0 +1,81 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestDoNotifyPropsAllowPluginNotification(t *testing.T) {
+	testCases := []struct {
+		Description   string
+		UserLevel     string
+		ChannelLevel  string
+		ExpectAllowed bool
+	}{
+		{"user all, channel default", model.USER_NOTIFY_ALL, model.CHANNEL_NOTIFY_DEFAULT, true},
+		{"user mention, channel default", model.USER_NOTIFY_MENTION, model.CHANNEL_NOTIFY_DEFAULT, true},
+		{"user none, channel default", model.USER_NOTIFY_NONE, model.CHANNEL_NOTIFY_DEFAULT, false},
+		{"user none, channel unset", model.USER_NOTIFY_NONE, "", false},
+		{"user none, channel mention", model.USER_NOTIFY_NONE, model.CHANNEL_NOTIFY_MENTION, true},
+		{"user all, channel none", model.USER_NOTIFY_ALL, model.CHANNEL_NOTIFY_NONE, false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Description, func(t *testing.T) {
+			userNotifyProps := model.StringMap{model.PUSH_NOTIFY_PROP: testCase.UserLevel}
+			channelNotifyProps := model.StringMap{}
+			if testCase.ChannelLevel != "" {
+				channelNotifyProps[model.PUSH_NOTIFY_PROP] = testCase.ChannelLevel
+			}
+
+			assert.Equal(t, testCase.ExpectAllowed, doNotifyPropsAllowPluginNotification(userNotifyProps, channelNotifyProps, model.PUSH_NOTIFY_PROP))
+		})
+	}
+}
+
+func TestSendPluginNotification(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	notification := &model.PluginNotification{
+		ChannelId: th.BasicChannel.Id,
+		Title:     "title",
+		Message:   "message",
+	}
+
+	require.Nil(t, th.App.SendPluginNotification("testplugin", th.BasicUser.Id, notification))
+
+	t.Run("invalid notification", func(t *testing.T) {
+		err := th.App.SendPluginNotification("testplugin", th.BasicUser.Id, &model.PluginNotification{ChannelId: th.BasicChannel.Id})
+		require.NotNil(t, err)
+		assert.Equal(t, "model.plugin_notification.is_valid.message.app_error", err.Id)
+	})
+
+	t.Run("not a channel member", func(t *testing.T) {
+		user := th.CreateUser()
+
+		err := th.App.SendPluginNotification("testplugin", user.Id, notification)
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusForbidden, err.StatusCode)
+	})
+
+	t.Run("rate limited per plugin", func(t *testing.T) {
+		var err *model.AppError
+		for i := 0; i <= PLUGIN_NOTIFICATION_RATE_MAX_BURST+1 && err == nil; i++ {
+			err = th.App.SendPluginNotification("spammyplugin", th.BasicUser.Id, notification)
+		}
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, err.StatusCode)
+
+		// Other plugins are unaffected
+		require.Nil(t, th.App.SendPluginNotification("otherplugin", th.BasicUser.Id, notification))
+	})
+}
@@ -0,0 +1,380 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/mattermost/mattermost-server/utils"
+)
+
+const (
+	// PLUGIN_OAUTH_CONNECT_PATH and PLUGIN_OAUTH_COMPLETE_PATH are served by the server, rather than
+	// the plugin, under the routes of each plugin that has registered an OAuth provider.
+	PLUGIN_OAUTH_CONNECT_PATH  = "/oauth/connect"
+	PLUGIN_OAUTH_COMPLETE_PATH = "/oauth/complete"
+
+	// COOKIE_PLUGIN_OAUTH ties the state passed to a plugin's OAuth provider to the browser that was
+	// sent there, so that a user cannot be made to complete a connection started by someone else.
+	COOKIE_PLUGIN_OAUTH = "MMPLUGINOAUTH"
+)
+
+// RegisterPluginOAuthProvider registers the OAuth provider through which users connect their
+// accounts in an external service to the plugin, replacing any the plugin registered before.
+func (a *App) RegisterPluginOAuthProvider(pluginId string, provider model.PluginOAuthProvider) *model.AppError {
+	if err := provider.IsValid(); err != nil {
+		return err
+	}
+
+	provider.Scopes = append([]string(nil), provider.Scopes...)
+
+	a.pluginOAuthProvidersLock.Lock()
+	defer a.pluginOAuthProvidersLock.Unlock()
+
+	if a.pluginOAuthProviders == nil {
+		a.pluginOAuthProviders = make(map[string]*model.PluginOAuthProvider)
+	}
+	a.pluginOAuthProviders[pluginId] = &provider
+
+	return nil
+}
+
+func (a *App) UnregisterPluginOAuthProvider(pluginId string) {
+	a.pluginOAuthProvidersLock.Lock()
+	defer a.pluginOAuthProvidersLock.Unlock()
+
+	delete(a.pluginOAuthProviders, pluginId)
+}
+
+func (a *App) getPluginOAuthProvider(pluginId string) *model.PluginOAuthProvider {
+	a.pluginOAuthProvidersLock.RLock()
+	defer a.pluginOAuthProvidersLock.RUnlock()
+
+	return a.pluginOAuthProviders[pluginId]
+}
+
+// pluginOAuthHandler returns the handler for a request to the routes the server serves for the
+// plugin's OAuth provider, or nil if the request is for the plugin or it has no provider.
+func (a *App) pluginOAuthHandler(pluginId string, r *http.Request) func(*plugin.Context, http.ResponseWriter, *http.Request) {
+	provider := a.getPluginOAuthProvider(pluginId)
+	if provider == nil {
+		return nil
+	}
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/plugins/"+pluginId+PLUGIN_OAUTH_CONNECT_PATH):
+		return func(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
+			a.connectPluginOAuth(pluginId, provider, w, r)
+		}
+	case strings.HasSuffix(r.URL.Path, "/plugins/"+pluginId+PLUGIN_OAUTH_COMPLETE_PATH):
+		return func(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
+			a.completePluginOAuth(pluginId, provider, w, r)
+		}
+	}
+
+	return nil
+}
+
+func generatePluginOAuthStateTokenExtra(pluginId, userId, cookie string) string {
+	return pluginId + ":" + userId + ":" + cookie
+}
+
+func (a *App) pluginOAuthCookiePath(pluginId string) string {
+	subpath, _ := utils.GetSubpathFromConfig(a.Config())
+	return path.Join(subpath, "plugins", pluginId, "oauth")
+}
+
+func (a *App) pluginOAuthRedirectURI(pluginId string) string {
+	return a.GetSiteURL() + "/plugins/" + pluginId + PLUGIN_OAUTH_COMPLETE_PATH
+}
+
+func (a *App) renderPluginOAuthError(w http.ResponseWriter, r *http.Request, err *model.AppError) {
+	a.Log.Error("Failed to connect a user to a plugin's OAuth provider", mlog.String("request_id", r.Header.Get(model.HEADER_REQUEST_ID)), mlog.Err(err))
+	err.Translate(utils.T)
+	utils.RenderWebAppError(a.Config(), w, r, err, a.AsymmetricSigningKey())
+}
+
+// connectPluginOAuth sends the user to the plugin's OAuth provider to authorize the plugin to access
+// their account in the external service.
+func (a *App) connectPluginOAuth(pluginId string, provider *model.PluginOAuthProvider, w http.ResponseWriter, r *http.Request) {
+	userId := r.Header.Get("Mattermost-User-Id")
+	if userId == "" {
+		a.renderPluginOAuthError(w, r, model.NewAppError("connectPluginOAuth", "api.context.session_expired.app_error", nil, "plugin_id="+pluginId, http.StatusUnauthorized))
+		return
+	}
+
+	cookieValue := model.NewId()
+	stateToken, err := a.CreateOAuthStateToken(generatePluginOAuthStateTokenExtra(pluginId, userId, cookieValue))
+	if err != nil {
+		a.renderPluginOAuthError(w, r, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     COOKIE_PLUGIN_OAUTH,
+		Value:    cookieValue,
+		Path:     a.pluginOAuthCookiePath(pluginId),
+		MaxAge:   OAUTH_COOKIE_MAX_AGE_SECONDS,
+		Expires:  time.Unix(model.GetMillis()/1000+int64(OAUTH_COOKIE_MAX_AGE_SECONDS), 0),
+		HttpOnly: true,
+		Secure:   GetProtocol(r) == "https",
+	})
+
+	authURL, _ := url.Parse(provider.AuthURL)
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", provider.ClientId)
+	query.Set("redirect_uri", a.pluginOAuthRedirectURI(pluginId))
+	query.Set("state", stateToken.Token)
+	if len(provider.Scopes) > 0 {
+		query.Set("scope", strings.Join(provider.Scopes, " "))
+	}
+	authURL.RawQuery = query.Encode()
+
+	http.Redirect(w, r, authURL.String(), http.StatusFound)
+}
+
+// completePluginOAuth exchanges the code with which the plugin's OAuth provider sent the user back
+// for a token, which is stored for the plugin to act on the user's behalf.
+func (a *App) completePluginOAuth(pluginId string, provider *model.PluginOAuthProvider, w http.ResponseWriter, r *http.Request) {
+	userId := r.Header.Get("Mattermost-User-Id")
+	if userId == "" {
+		a.renderPluginOAuthError(w, r, model.NewAppError("completePluginOAuth", "api.context.session_expired.app_error", nil, "plugin_id="+pluginId, http.StatusUnauthorized))
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("error") != "" {
+		a.renderPluginOAuthError(w, r, model.NewAppError("completePluginOAuth", "app.plugin.oauth.denied.app_error", nil, "plugin_id="+pluginId+", error="+query.Get("error"), http.StatusBadRequest))
+		return
+	}
+
+	stateToken, err := a.GetOAuthStateToken(query.Get("state"))
+	if err != nil {
+		a.renderPluginOAuthError(w, r, err)
+		return
+	}
+
+	// The state must have been created for this plugin and user, in this browser.
+	cookie, _ := r.Cookie(COOKIE_PLUGIN_OAUTH)
+	if cookie == nil || stateToken.Extra != generatePluginOAuthStateTokenExtra(pluginId, userId, cookie.Value) {
+		a.renderPluginOAuthError(w, r, model.NewAppError("completePluginOAuth", "api.oauth.invalid_state_token.app_error", nil, "plugin_id="+pluginId, http.StatusBadRequest))
+		return
+	}
+
+	a.DeleteToken(stateToken)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     COOKIE_PLUGIN_OAUTH,
+		Value:    "",
+		Path:     a.pluginOAuthCookiePath(pluginId),
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+
+	token, err := a.requestPluginOAuthToken(provider, url.Values{
+		"grant_type":   {model.ACCESS_TOKEN_GRANT_TYPE},
+		"code":         {query.Get("code")},
+		"redirect_uri": {a.pluginOAuthRedirectURI(pluginId)},
+	})
+	if err != nil {
+		a.renderPluginOAuthError(w, r, err)
+		return
+	}
+
+	if err := a.savePluginOAuthToken(pluginId, userId, token); err != nil {
+		a.renderPluginOAuthError(w, r, err)
+		return
+	}
+
+	redirectURL := a.GetSiteURL() + "/"
+	if provider.RedirectPath != "" {
+		redirectURL = a.GetSiteURL() + "/plugins/" + pluginId + provider.RedirectPath
+	}
+
+	http.Redirect(w, r, redirectURL, http.StatusFound)
+}
+
+// requestPluginOAuthToken requests a token from the plugin's OAuth provider, authenticating the
+// request with the provider's client credentials.
+func (a *App) requestPluginOAuthToken(provider *model.PluginOAuthProvider, params url.Values) (*model.PluginOAuthToken, *model.AppError) {
+	params.Set("client_id", provider.ClientId)
+	params.Set("client_secret", provider.ClientSecret)
+
+	req, _ := http.NewRequest("POST", provider.TokenURL, strings.NewReader(params.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.HTTPClient(false).Do(req)
+	if err != nil {
+		return nil, model.NewAppError("requestPluginOAuthToken", "app.plugin.oauth.token_request.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+	defer consumeAndClose(resp)
+
+	ar := model.AccessResponseFromJson(resp.Body)
+	if resp.StatusCode != http.StatusOK || ar == nil || ar.AccessToken == "" {
+		return nil, model.NewAppError("requestPluginOAuthToken", "app.plugin.oauth.token_request.app_error", nil, "status_code="+resp.Status, http.StatusInternalServerError)
+	}
+
+	token := &model.PluginOAuthToken{
+		AccessToken:  ar.AccessToken,
+		TokenType:    ar.TokenType,
+		RefreshToken: ar.RefreshToken,
+	}
+	if ar.ExpiresIn > 0 {
+		token.Expiry = model.GetMillis() + int64(ar.ExpiresIn)*1000
+	}
+
+	return token, nil
+}
+
+// pluginOAuthEncryptionKey returns the key with which the tokens of users connected to plugins' OAuth
+// providers are encrypted. It is derived from the server's signing key, so that the tokens are
+// encrypted whether or not plugin key-value pairs are.
+func (a *App) pluginOAuthEncryptionKey() (*pluginKeyValueEncryptionKey, *model.AppError) {
+	signingKey := a.AsymmetricSigningKey()
+	if signingKey == nil {
+		return nil, model.NewAppError("pluginOAuthEncryptionKey", "app.plugin.oauth.encryption_key.app_error", nil, "missing signing key", http.StatusInternalServerError)
+	}
+
+	key, err := newPluginKeyValueEncryptionKey("plugin_oauth:" + string(signingKey.D.Bytes()))
+	if err != nil {
+		return nil, model.NewAppError("pluginOAuthEncryptionKey", "app.plugin.oauth.encryption_key.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return key, nil
+}
+
+func (a *App) savePluginOAuthToken(pluginId, userId string, token *model.PluginOAuthToken) *model.AppError {
+	key, appErr := a.pluginOAuthEncryptionKey()
+	if appErr != nil {
+		return appErr
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return model.NewAppError("savePluginOAuthToken", "app.plugin.oauth.encrypt.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	encrypted, err := encryptPluginKeyValue(data, key)
+	if err != nil {
+		return model.NewAppError("savePluginOAuthToken", "app.plugin.oauth.encrypt.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	connection := &model.PluginOAuthConnection{
+		PluginId: pluginId,
+		UserId:   userId,
+		Token:    base64.StdEncoding.EncodeToString(encrypted),
+	}
+	if result := <-a.Srv.Store.PluginOAuthConnection().Save(connection); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func (a *App) getStoredPluginOAuthToken(pluginId, userId string) (*model.PluginOAuthToken, *model.AppError) {
+	result := <-a.Srv.Store.PluginOAuthConnection().Get(pluginId, userId)
+	if result.Err != nil {
+		if result.Err.StatusCode == http.StatusNotFound {
+			return nil, model.NewAppError("getStoredPluginOAuthToken", "app.plugin.oauth.not_connected.app_error", nil, "plugin_id="+pluginId+", user_id="+userId, http.StatusNotFound)
+		}
+		return nil, result.Err
+	}
+	connection := result.Data.(*model.PluginOAuthConnection)
+
+	key, appErr := a.pluginOAuthEncryptionKey()
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	encrypted, err := base64.StdEncoding.DecodeString(connection.Token)
+	if err != nil {
+		return nil, model.NewAppError("getStoredPluginOAuthToken", "app.plugin.oauth.decrypt.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	data, err := decryptPluginKeyValue(encrypted, []*pluginKeyValueEncryptionKey{key})
+	if err != nil {
+		return nil, model.NewAppError("getStoredPluginOAuthToken", "app.plugin.oauth.decrypt.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	var token model.PluginOAuthToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, model.NewAppError("getStoredPluginOAuthToken", "app.plugin.oauth.decrypt.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return &token, nil
+}
+
+// GetPluginOAuthToken returns the token with which the plugin acts on behalf of the user in the
+// external service they connected to the plugin's OAuth provider, refreshing it first if it has
+// expired. The refresh token is kept by the server.
+func (a *App) GetPluginOAuthToken(pluginId, userId string) (*model.PluginOAuthToken, *model.AppError) {
+	token, err := a.getStoredPluginOAuthToken(pluginId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.IsExpired(model.GetMillis()) {
+		if token, err = a.refreshPluginOAuthToken(pluginId, userId); err != nil {
+			return nil, err
+		}
+	}
+
+	token.RefreshToken = ""
+	return token, nil
+}
+
+func (a *App) refreshPluginOAuthToken(pluginId, userId string) (*model.PluginOAuthToken, *model.AppError) {
+	// Providers may only accept each refresh token once, so tokens are refreshed one at a time, and
+	// only if they were not refreshed while waiting.
+	a.pluginOAuthRefreshLock.Lock()
+	defer a.pluginOAuthRefreshLock.Unlock()
+
+	token, err := a.getStoredPluginOAuthToken(pluginId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.IsExpired(model.GetMillis()) {
+		return token, nil
+	}
+
+	provider := a.getPluginOAuthProvider(pluginId)
+	if provider == nil {
+		return nil, model.NewAppError("refreshPluginOAuthToken", "app.plugin.oauth.no_provider.app_error", nil, "plugin_id="+pluginId, http.StatusBadRequest)
+	}
+
+	if token.RefreshToken == "" {
+		return nil, model.NewAppError("refreshPluginOAuthToken", "app.plugin.oauth.token_expired.app_error", nil, "plugin_id="+pluginId+", user_id="+userId, http.StatusUnauthorized)
+	}
+
+	refreshed, err := a.requestPluginOAuthToken(provider, url.Values{
+		"grant_type":    {model.REFRESH_TOKEN_GRANT_TYPE},
+		"refresh_token": {token.RefreshToken},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Providers that do not rotate refresh tokens do not return a new one.
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = token.RefreshToken
+	}
+
+	if err := a.savePluginOAuthToken(pluginId, userId, refreshed); err != nil {
+		return nil, err
+	}
+
+	return refreshed, nil
+}
@@ -0,0 +1,372 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+// newFakeOAuthProvider starts an OAuth 2.0 provider that grants access1 in exchange for the code
+// "code", and access2 in exchange for the refresh token it granted with it.
+func newFakeOAuthProvider(t *testing.T) (*httptest.Server, *int32) {
+	var refreshes int32
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		if r.Form.Get("client_id") != "client" || r.Form.Get("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		response := map[string]interface{}{"token_type": "bearer", "expires_in": 3600}
+		switch {
+		case r.Form.Get("grant_type") == "authorization_code" && r.Form.Get("code") == "code":
+			response["access_token"] = "access1"
+			response["refresh_token"] = "refresh1"
+		case r.Form.Get("grant_type") == "refresh_token" && r.Form.Get("refresh_token") == "refresh1":
+			atomic.AddInt32(&refreshes, 1)
+			response["access_token"] = "access2"
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+
+	return httptest.NewServer(handler), &refreshes
+}
+
+func TestPluginOAuth(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.ServiceSettings.AllowedUntrustedInternalConnections = "localhost 127.0.0.1"
+	})
+
+	provider, refreshes := newFakeOAuthProvider(t)
+	defer provider.Close()
+
+	SetAppEnvironmentWithPlugins(t,
+		[]string{fmt.Sprintf(`
+		package main
+
+		import (
+			"net/http"
+
+			"github.com/mattermost/mattermost-server/model"
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) OnActivate() error {
+			if err := p.API.RegisterOAuthProvider(model.PluginOAuthProvider{
+				ClientId:     "client",
+				ClientSecret: "secret",
+				AuthURL:      "%s/authorize",
+				TokenURL:     "%s/token",
+				Scopes:       []string{"read", "write"},
+				RedirectPath: "/connected",
+			}); err != nil {
+				return err
+			}
+			return nil
+		}
+
+		func (p *MyPlugin) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
+			token, err := p.API.GetOAuthToken(r.Header.Get("Mattermost-User-Id"))
+			if err != nil {
+				http.Error(w, err.Id, err.StatusCode)
+				return
+			}
+			w.Write([]byte(token.AccessToken + token.RefreshToken))
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`, provider.URL, provider.URL)}, th.App, th.App.NewPluginAPI)
+
+	pluginId := th.App.Plugins.Active()[0].Manifest.Id
+
+	session, err := th.App.CreateSession(&model.Session{UserId: th.BasicUser.Id, Roles: th.BasicUser.GetRawRoles()})
+	require.Nil(t, err)
+	otherSession, err := th.App.CreateSession(&model.Session{UserId: th.BasicUser2.Id, Roles: th.BasicUser2.GetRawRoles()})
+	require.Nil(t, err)
+
+	serve := func(path string, session *model.Session, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/plugins/"+pluginId+path, nil)
+		if session != nil {
+			request.Header.Set(model.HEADER_AUTH, model.HEADER_BEARER+" "+session.Token)
+		}
+		for _, cookie := range cookies {
+			request.AddCookie(cookie)
+		}
+
+		recorder := httptest.NewRecorder()
+		th.App.ServePluginRequest(recorder, mux.SetURLVars(request, map[string]string{"plugin_id": pluginId}))
+		return recorder
+	}
+
+	connect := func(t *testing.T) (string, *http.Cookie) {
+		recorder := serve(PLUGIN_OAUTH_CONNECT_PATH, session)
+		require.Equal(t, http.StatusFound, recorder.Code)
+
+		location, err := url.Parse(recorder.Header().Get("Location"))
+		require.NoError(t, err)
+		assert.Equal(t, provider.URL+"/authorize", location.Scheme+"://"+location.Host+location.Path)
+		assert.Equal(t, "code", location.Query().Get("response_type"))
+		assert.Equal(t, "client", location.Query().Get("client_id"))
+		assert.Equal(t, th.App.GetSiteURL()+"/plugins/"+pluginId+PLUGIN_OAUTH_COMPLETE_PATH, location.Query().Get("redirect_uri"))
+		assert.Equal(t, "read write", location.Query().Get("scope"))
+		assert.NotContains(t, location.RawQuery, "secret")
+
+		cookies := recorder.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, COOKIE_PLUGIN_OAUTH, cookies[0].Name)
+		assert.True(t, cookies[0].HttpOnly)
+
+		return location.Query().Get("state"), cookies[0]
+	}
+
+	t.Run("not connected", func(t *testing.T) {
+		recorder := serve("/token", session)
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "app.plugin.oauth.not_connected.app_error")
+	})
+
+	t.Run("connecting requires a session", func(t *testing.T) {
+		recorder := serve(PLUGIN_OAUTH_CONNECT_PATH, nil)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Empty(t, recorder.Result().Cookies())
+	})
+
+	t.Run("state must have been created in the same browser", func(t *testing.T) {
+		state, _ := connect(t)
+
+		recorder := serve(PLUGIN_OAUTH_COMPLETE_PATH+"?code=code&state="+url.QueryEscape(state), session)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		recorder = serve(PLUGIN_OAUTH_COMPLETE_PATH+"?code=code&state="+url.QueryEscape(state), session, &http.Cookie{Name: COOKIE_PLUGIN_OAUTH, Value: model.NewId()})
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("state must have been created for the same user", func(t *testing.T) {
+		state, cookie := connect(t)
+
+		recorder := serve(PLUGIN_OAUTH_COMPLETE_PATH+"?code=code&state="+url.QueryEscape(state), otherSession, cookie)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		_, err := th.App.GetPluginOAuthToken(pluginId, th.BasicUser2.Id)
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusNotFound, err.StatusCode)
+	})
+
+	t.Run("provider denied access", func(t *testing.T) {
+		state, cookie := connect(t)
+
+		recorder := serve(PLUGIN_OAUTH_COMPLETE_PATH+"?error=access_denied&state="+url.QueryEscape(state), session, cookie)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("connect", func(t *testing.T) {
+		state, cookie := connect(t)
+
+		recorder := serve(PLUGIN_OAUTH_COMPLETE_PATH+"?code=code&state="+url.QueryEscape(state), session, cookie)
+		require.Equal(t, http.StatusFound, recorder.Code)
+		assert.Equal(t, th.App.GetSiteURL()+"/plugins/"+pluginId+"/connected", recorder.Header().Get("Location"))
+
+		// The state may only be used once.
+		recorder = serve(PLUGIN_OAUTH_COMPLETE_PATH+"?code=code&state="+url.QueryEscape(state), session, cookie)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		// The token is encrypted at rest.
+		result := <-th.App.Srv.Store.PluginOAuthConnection().Get(pluginId, th.BasicUser.Id)
+		require.Nil(t, result.Err)
+		stored, decodeErr := base64.StdEncoding.DecodeString(result.Data.(*model.PluginOAuthConnection).Token)
+		require.NoError(t, decodeErr)
+		assert.NotContains(t, string(stored), "access1")
+		assert.NotContains(t, string(stored), "refresh1")
+
+		// The plugin gets the access token, but not the refresh token.
+		recorder = serve("/token", session)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "access1", recorder.Body.String())
+		assert.Equal(t, int32(0), atomic.LoadInt32(refreshes))
+	})
+
+	t.Run("expired tokens are refreshed", func(t *testing.T) {
+		require.Nil(t, th.App.savePluginOAuthToken(pluginId, th.BasicUser.Id, &model.PluginOAuthToken{
+			AccessToken:  "access1",
+			RefreshToken: "refresh1",
+			Expiry:       model.GetMillis() - 1000,
+		}))
+
+		recorder := serve("/token", session)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "access2", recorder.Body.String())
+		assert.Equal(t, int32(1), atomic.LoadInt32(refreshes))
+
+		// The refreshed token is stored, keeping the refresh token the provider did not replace.
+		token, err := th.App.getStoredPluginOAuthToken(pluginId, th.BasicUser.Id)
+		require.Nil(t, err)
+		assert.Equal(t, "access2", token.AccessToken)
+		assert.Equal(t, "refresh1", token.RefreshToken)
+		assert.False(t, token.IsExpired(model.GetMillis()))
+
+		recorder = serve("/token", session)
+		assert.Equal(t, "access2", recorder.Body.String())
+		assert.Equal(t, int32(1), atomic.LoadInt32(refreshes))
+	})
+
+	t.Run("expired tokens without a refresh token", func(t *testing.T) {
+		require.Nil(t, th.App.savePluginOAuthToken(pluginId, th.BasicUser.Id, &model.PluginOAuthToken{
+			AccessToken: "access1",
+			Expiry:      model.GetMillis() - 1000,
+		}))
+
+		_, err := th.App.GetPluginOAuthToken(pluginId, th.BasicUser.Id)
+		require.NotNil(t, err)
+		assert.Equal(t, "app.plugin.oauth.token_expired.app_error", err.Id)
+	})
+
+	t.Run("other routes are served by the plugin", func(t *testing.T) {
+		th.App.UnregisterPluginOAuthProvider(pluginId)
+
+		recorder := serve(PLUGIN_OAUTH_CONNECT_PATH, session)
+		assert.NotEqual(t, http.StatusFound, recorder.Code)
+	})
+}
+
+func TestRegisterPluginOAuthProvider(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := model.NewId()
+	defer th.App.UnregisterPluginOAuthProvider(pluginId)
+
+	err := th.App.RegisterPluginOAuthProvider(pluginId, model.PluginOAuthProvider{ClientId: "client", AuthURL: "not a url", TokenURL: "https://example.com/token"})
+	require.NotNil(t, err)
+	assert.Nil(t, th.App.getPluginOAuthProvider(pluginId))
+
+	provider := model.PluginOAuthProvider{ClientId: "client", AuthURL: "https://example.com/authorize", TokenURL: "https://example.com/token", Scopes: []string{"read"}}
+	require.Nil(t, th.App.RegisterPluginOAuthProvider(pluginId, provider))
+
+	// The registered provider is a copy.
+	provider.Scopes[0] = "write"
+	assert.Equal(t, []string{"read"}, th.App.getPluginOAuthProvider(pluginId).Scopes)
+
+	request := httptest.NewRequest(http.MethodGet, "/plugins/"+pluginId+PLUGIN_OAUTH_CONNECT_PATH, nil)
+	assert.NotNil(t, th.App.pluginOAuthHandler(pluginId, request))
+	request = httptest.NewRequest(http.MethodGet, "/plugins/"+pluginId+"/oauth/other", nil)
+	assert.Nil(t, th.App.pluginOAuthHandler(pluginId, request))
+	request = httptest.NewRequest(http.MethodGet, "/plugins/"+pluginId+PLUGIN_OAUTH_CONNECT_PATH, nil)
+	assert.Nil(t, th.App.pluginOAuthHandler(model.NewId(), request))
+}
+
+func TestPluginOAuthRevocation(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = true })
+
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginDir)
+
+	pluginId := model.NewId()
+	require.NoError(t, os.Mkdir(filepath.Join(pluginDir, pluginId), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pluginDir, pluginId, "plugin.json"), []byte(`{"id": "`+pluginId+`", "webapp": {"bundle_path": "../bundle.js"}}`), 0600))
+
+	env, err := plugin.NewEnvironment(th.App.NewPluginAPI, pluginDir, pluginDir, th.App.Log)
+	require.NoError(t, err)
+	th.App.Plugins = env
+
+	otherPluginId := model.NewId()
+	for _, id := range []string{pluginId, otherPluginId} {
+		for _, userId := range []string{th.BasicUser.Id, th.BasicUser2.Id} {
+			require.Nil(t, th.App.savePluginOAuthToken(id, userId, &model.PluginOAuthToken{AccessToken: "access"}))
+		}
+	}
+	require.Nil(t, th.App.RegisterPluginOAuthProvider(pluginId, model.PluginOAuthProvider{ClientId: "client", AuthURL: "https://example.com/authorize", TokenURL: "https://example.com/token"}))
+
+	isConnected := func(pluginId, userId string) bool {
+		_, err := th.App.GetPluginOAuthToken(pluginId, userId)
+		if err != nil {
+			require.Equal(t, http.StatusNotFound, err.StatusCode)
+			return false
+		}
+		return true
+	}
+
+	t.Run("user deactivated", func(t *testing.T) {
+		_, err := th.App.UpdateActive(th.BasicUser2, false)
+		require.Nil(t, err)
+
+		assert.False(t, isConnected(pluginId, th.BasicUser2.Id))
+		assert.False(t, isConnected(otherPluginId, th.BasicUser2.Id))
+		assert.True(t, isConnected(pluginId, th.BasicUser.Id))
+	})
+
+	t.Run("plugin removed", func(t *testing.T) {
+		require.Nil(t, th.App.RemovePlugin(pluginId))
+
+		assert.False(t, isConnected(pluginId, th.BasicUser.Id))
+		assert.True(t, isConnected(otherPluginId, th.BasicUser.Id))
+		assert.Nil(t, th.App.getPluginOAuthProvider(pluginId))
+	})
+}
+
+func TestPluginOAuthTokenRequest(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.AllowedUntrustedInternalConnections = "localhost 127.0.0.1"
+	})
+
+	server, _ := newFakeOAuthProvider(t)
+	defer server.Close()
+
+	provider := &model.PluginOAuthProvider{ClientId: "client", ClientSecret: "secret", TokenURL: server.URL + "/token"}
+
+	token, err := th.App.requestPluginOAuthToken(provider, url.Values{"grant_type": {"authorization_code"}, "code": {"code"}})
+	require.Nil(t, err)
+	assert.Equal(t, "access1", token.AccessToken)
+	assert.Equal(t, "refresh1", token.RefreshToken)
+	assert.Equal(t, "bearer", strings.ToLower(token.TokenType))
+	assert.InDelta(t, model.GetMillis()+3600*1000, token.Expiry, 60*1000)
+
+	_, err = th.App.requestPluginOAuthToken(provider, url.Values{"grant_type": {"authorization_code"}, "code": {"wrong"}})
+	assert.NotNil(t, err)
+
+	provider.ClientSecret = "wrong"
+	_, err = th.App.requestPluginOAuthToken(provider, url.Values{"grant_type": {"authorization_code"}, "code": {"code"}})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const PLUGIN_POST_METADATA_CLEANUP_BATCH_SIZE = 100
+
+// AddPostMetadata attaches the given JSON to the post under the namespace, delivered to clients in
+// the post's props under plugin_metadata[namespace]. Unlike an edit, this does not set the post's
+// EditAt. Empty data removes the namespace's metadata. A namespace on a post belongs to the first
+// plugin to attach metadata under it, until that plugin removes it or is itself removed.
+func (a *App) AddPostMetadata(pluginId, postId, namespace string, data []byte) *model.AppError {
+	if !model.IsValidPluginPostMetadataNamespace(namespace) {
+		return model.NewAppError("AddPostMetadata", "app.plugin.post_metadata.namespace.app_error", map[string]interface{}{"Max": model.PLUGIN_POST_METADATA_NAMESPACE_MAX_LENGTH}, "namespace="+namespace, http.StatusBadRequest)
+	}
+
+	if len(data) > model.PLUGIN_POST_METADATA_MAX_BYTES {
+		return model.NewAppError("AddPostMetadata", "app.plugin.post_metadata.too_large.app_error", map[string]interface{}{"Namespace": namespace, "Max": model.PLUGIN_POST_METADATA_MAX_BYTES}, "", http.StatusRequestEntityTooLarge)
+	}
+
+	var value interface{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return model.NewAppError("AddPostMetadata", "app.plugin.post_metadata.invalid_json.app_error", map[string]interface{}{"Namespace": namespace}, err.Error(), http.StatusBadRequest)
+		}
+	}
+
+	post, err := a.GetSinglePost(postId)
+	if err != nil {
+		return err
+	}
+
+	owned := false
+	if result := <-a.Srv.Store.PluginPostMetadata().Get(postId, namespace); result.Err == nil {
+		if owner := result.Data.(*model.PluginPostMetadata).PluginId; owner != pluginId {
+			return model.NewAppError("AddPostMetadata", "app.plugin.post_metadata.owned.app_error", map[string]interface{}{"Namespace": namespace}, "owner="+owner, http.StatusForbidden)
+		}
+		owned = true
+	} else if result.Err.StatusCode != http.StatusNotFound {
+		return result.Err
+	}
+
+	if value == nil {
+		if !owned {
+			return nil
+		}
+		return a.removePostMetadata(post, namespace)
+	}
+
+	// The namespace is claimed before the metadata is attached, so that metadata is never left on a
+	// post without a record of the plugin to remove it along with.
+	if result := <-a.Srv.Store.PluginPostMetadata().Save(&model.PluginPostMetadata{PostId: postId, Namespace: namespace, PluginId: pluginId}); result.Err != nil {
+		return result.Err
+	}
+
+	post.SetPluginMetadata(namespace, value)
+	if result := <-a.Srv.Store.Post().Overwrite(post); result.Err != nil {
+		if !owned {
+			<-a.Srv.Store.PluginPostMetadata().Delete(postId, namespace)
+		}
+		return result.Err
+	}
+
+	a.InvalidateCacheForChannelPosts(post.ChannelId)
+	a.sendPostMetadataUpdatedEvent(post, namespace, data)
+
+	return nil
+}
+
+// removePostMetadata removes the metadata attached to the post under the namespace, along with the
+// record of the plugin owning it.
+func (a *App) removePostMetadata(post *model.Post, namespace string) *model.AppError {
+	if _, ok := post.GetPluginMetadata()[namespace]; ok {
+		post.DeletePluginMetadata(namespace)
+		if result := <-a.Srv.Store.Post().Overwrite(post); result.Err != nil {
+			return result.Err
+		}
+
+		a.InvalidateCacheForChannelPosts(post.ChannelId)
+		a.sendPostMetadataUpdatedEvent(post, namespace, nil)
+	}
+
+	if result := <-a.Srv.Store.PluginPostMetadata().Delete(post.Id, namespace); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// removeAllPostMetadataForPlugin removes all the metadata the plugin has attached to posts.
+func (a *App) removeAllPostMetadataForPlugin(pluginId string) {
+	for {
+		result := <-a.Srv.Store.PluginPostMetadata().GetForPlugin(pluginId, PLUGIN_POST_METADATA_CLEANUP_BATCH_SIZE)
+		if result.Err != nil {
+			mlog.Error("Failed to get the post metadata of a removed plugin", mlog.String("plugin_id", pluginId), mlog.Err(result.Err))
+			return
+		}
+		records := result.Data.([]*model.PluginPostMetadata)
+
+		for _, record := range records {
+			post, err := a.GetSinglePost(record.PostId)
+			if err != nil && err.StatusCode != http.StatusNotFound {
+				mlog.Error("Failed to remove the post metadata of a removed plugin", mlog.String("plugin_id", pluginId), mlog.String("post_id", record.PostId), mlog.Err(err))
+				return
+			} else if err != nil {
+				// The post is gone, so only the record is left to remove.
+				post = &model.Post{Id: record.PostId}
+			}
+
+			if err := a.removePostMetadata(post, record.Namespace); err != nil {
+				mlog.Error("Failed to remove the post metadata of a removed plugin", mlog.String("plugin_id", pluginId), mlog.String("post_id", record.PostId), mlog.Err(err))
+				return
+			}
+		}
+
+		if len(records) < PLUGIN_POST_METADATA_CLEANUP_BATCH_SIZE {
+			return
+		}
+	}
+}
+
+func (a *App) sendPostMetadataUpdatedEvent(post *model.Post, namespace string, data []byte) {
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POST_METADATA_UPDATED, "", post.ChannelId, "", nil)
+	message.Add("post_id", post.Id)
+	message.Add("namespace", namespace)
+	message.Add("data", string(data))
+	message.Add("update_at", post.UpdateAt)
+	a.Publish(message)
+}
+
+// postWithoutPluginMetadata returns the post, or a copy of it without any metadata attached by
+// plugins, which is kept out of search indexes.
+func postWithoutPluginMetadata(post *model.Post) *model.Post {
+	if post.GetPluginMetadata() == nil {
+		return post
+	}
+
+	indexed := *post
+	indexed.Props = make(model.StringInterface, len(post.Props))
+	for key, value := range post.Props {
+		indexed.Props[key] = value
+	}
+	delete(indexed.Props, model.POST_PROPS_PLUGIN_METADATA)
+
+	return &indexed
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestAddPostMetadata(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	pluginId := "com.example.translations"
+	post := th.CreatePost(th.BasicChannel)
+
+	require.Nil(t, th.App.AddPostMetadata(pluginId, post.Id, "translations", []byte(`{"es":"hola"}`)))
+
+	updated, err := th.App.GetSinglePost(post.Id)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"translations": map[string]interface{}{"es": "hola"}}, updated.GetPluginMetadata())
+	assert.Equal(t, post.EditAt, updated.EditAt, "attaching metadata should not mark the post as edited")
+	assert.Equal(t, post.Message, updated.Message)
+
+	t.Run("replaced", func(t *testing.T) {
+		require.Nil(t, th.App.AddPostMetadata(pluginId, post.Id, "translations", []byte(`{"fr":"bonjour"}`)))
+
+		updated, err := th.App.GetSinglePost(post.Id)
+		require.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"fr": "bonjour"}, updated.GetPluginMetadata()["translations"])
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := th.App.AddPostMetadata(pluginId, post.Id, "with space", []byte(`{}`))
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+
+		err = th.App.AddPostMetadata(pluginId, post.Id, "translations", []byte("not json"))
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+
+		err = th.App.AddPostMetadata(pluginId, post.Id, "translations", []byte(`"`+strings.Repeat("a", model.PLUGIN_POST_METADATA_MAX_BYTES)+`"`))
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, err.StatusCode)
+
+		err = th.App.AddPostMetadata(pluginId, model.NewId(), "translations", []byte(`{}`))
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusNotFound, err.StatusCode)
+	})
+
+	t.Run("owned by another plugin", func(t *testing.T) {
+		err := th.App.AddPostMetadata("com.example.other", post.Id, "translations", []byte(`{"de":"hallo"}`))
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusForbidden, err.StatusCode)
+
+		require.Nil(t, th.App.AddPostMetadata("com.example.other", post.Id, "other", []byte(`true`)))
+
+		updated, err := th.App.GetSinglePost(post.Id)
+		require.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"fr": "bonjour"}, updated.GetPluginMetadata()["translations"])
+		assert.Equal(t, true, updated.GetPluginMetadata()["other"])
+	})
+
+	t.Run("kept when the post is edited", func(t *testing.T) {
+		edited := &model.Post{}
+		*edited = *updated
+		edited.Message = "edited"
+		edited.Props = model.StringInterface{model.POST_PROPS_PLUGIN_METADATA: map[string]interface{}{"translations": "forged"}}
+
+		_, err := th.App.UpdatePost(edited, false)
+		require.Nil(t, err)
+
+		updated, err := th.App.GetSinglePost(post.Id)
+		require.Nil(t, err)
+		assert.Equal(t, "edited", updated.Message)
+		assert.Equal(t, map[string]interface{}{"fr": "bonjour"}, updated.GetPluginMetadata()["translations"])
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		require.Nil(t, th.App.AddPostMetadata(pluginId, post.Id, "translations", nil))
+
+		updated, err := th.App.GetSinglePost(post.Id)
+		require.Nil(t, err)
+		_, ok := updated.GetPluginMetadata()["translations"]
+		assert.False(t, ok)
+
+		result := <-th.App.Srv.Store.PluginPostMetadata().Get(post.Id, "translations")
+		require.NotNil(t, result.Err)
+		assert.Equal(t, http.StatusNotFound, result.Err.StatusCode)
+
+		// The namespace can now be claimed by another plugin.
+		require.Nil(t, th.App.AddPostMetadata("com.example.other", post.Id, "translations", []byte(`{"de":"hallo"}`)))
+	})
+}
+
+func TestRemoveAllPostMetadataForPlugin(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	pluginId := "com.example.translations"
+
+	var posts []*model.Post
+	for i := 0; i < PLUGIN_POST_METADATA_CLEANUP_BATCH_SIZE+1; i++ {
+		post := th.CreatePost(th.BasicChannel)
+		require.Nil(t, th.App.AddPostMetadata(pluginId, post.Id, "translations", []byte(`{"es":"hola"}`)))
+		posts = append(posts, post)
+	}
+	require.Nil(t, th.App.AddPostMetadata("com.example.other", posts[0].Id, "other", []byte(`true`)))
+
+	deleted := th.CreatePost(th.BasicChannel)
+	require.Nil(t, th.App.AddPostMetadata(pluginId, deleted.Id, "translations", []byte(`{"es":"hola"}`)))
+	_, err := th.App.DeletePost(deleted.Id, th.BasicUser.Id)
+	require.Nil(t, err)
+
+	th.App.removeAllPostMetadataForPlugin(pluginId)
+
+	for _, post := range posts {
+		updated, err := th.App.GetSinglePost(post.Id)
+		require.Nil(t, err)
+		_, ok := updated.GetPluginMetadata()["translations"]
+		assert.False(t, ok)
+	}
+
+	updated, err := th.App.GetSinglePost(posts[0].Id)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"other": true}, updated.GetPluginMetadata())
+
+	result := <-th.App.Srv.Store.PluginPostMetadata().GetForPlugin(pluginId, 10)
+	require.Nil(t, result.Err)
+	assert.Empty(t, result.Data.([]*model.PluginPostMetadata))
+
+	// Posts are updated in place rather than edited.
+	assert.Zero(t, updated.EditAt)
+}
+
+func TestPostWithoutPluginMetadata(t *testing.T) {
+	post := &model.Post{Id: model.NewId(), Message: "hello"}
+	assert.True(t, post == postWithoutPluginMetadata(post))
+
+	post.AddProp("attachments", "kept")
+	post.SetPluginMetadata("translations", map[string]interface{}{"es": "hola"})
+
+	indexed := postWithoutPluginMetadata(post)
+	assert.Nil(t, indexed.GetPluginMetadata())
+	assert.Equal(t, "kept", indexed.Props["attachments"])
+	assert.Equal(t, post.Message, indexed.Message)
+
+	// The post itself is left alone.
+	assert.NotNil(t, post.GetPluginMetadata())
+}
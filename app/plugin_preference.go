@@ -0,0 +1,121 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// SetUserPluginSetting stores a setting for the user in the plugin's namespace of their preferences,
+// or deletes it if the value is empty. The user's clients are notified of the change like any other
+// change to their preferences.
+func (a *App) SetUserPluginSetting(pluginId, userId, key, value string) *model.AppError {
+	if err := model.IsValidPluginPreferenceKey(key); err != nil {
+		return err
+	}
+
+	category := model.PluginPreferenceCategory(pluginId)
+
+	result := <-a.Srv.Store.Preference().GetCategory(userId, category)
+	if result.Err != nil {
+		return result.Err
+	}
+	existing := result.Data.(model.Preferences)
+
+	preference := model.Preference{
+		UserId:   userId,
+		Category: category,
+		Name:     key,
+		Value:    value,
+	}
+
+	if value == "" {
+		for _, p := range existing {
+			if p.Name == key {
+				return a.DeletePreferences(userId, model.Preferences{preference})
+			}
+		}
+		return nil
+	}
+
+	count := 1
+	size := len(key) + len(value)
+	for _, p := range existing {
+		if p.Name != key {
+			count++
+			size += len(p.Name) + len(p.Value)
+		}
+	}
+
+	if count > model.PLUGIN_PREFERENCE_MAX_COUNT {
+		return model.NewAppError("SetUserPluginSetting", "app.plugin_preference.max_count.app_error", map[string]interface{}{"Max": model.PLUGIN_PREFERENCE_MAX_COUNT}, "plugin_id="+pluginId+", user_id="+userId, http.StatusBadRequest)
+	}
+
+	if size > model.PLUGIN_PREFERENCE_MAX_SIZE {
+		return model.NewAppError("SetUserPluginSetting", "app.plugin_preference.max_size.app_error", map[string]interface{}{"Max": model.PLUGIN_PREFERENCE_MAX_SIZE}, "plugin_id="+pluginId+", user_id="+userId, http.StatusRequestEntityTooLarge)
+	}
+
+	return a.UpdatePreferences(userId, model.Preferences{preference})
+}
+
+// GetUserPluginSettings returns the settings the plugin has stored for the user, keyed by name.
+func (a *App) GetUserPluginSettings(pluginId, userId string) (map[string]string, *model.AppError) {
+	result := <-a.Srv.Store.Preference().GetCategory(userId, model.PluginPreferenceCategory(pluginId))
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	settings := make(map[string]string)
+	for _, preference := range result.Data.(model.Preferences) {
+		settings[preference.Name] = preference.Value
+	}
+
+	return settings, nil
+}
+
+// GetPluginPreferencesForUser returns the settings stored for the user by each active plugin, keyed
+// by plugin id, so that they can be given to the user's clients when they start.
+func (a *App) GetPluginPreferencesForUser(userId string) (map[string]map[string]string, *model.AppError) {
+	if a.Plugins == nil || !*a.Config().PluginSettings.Enable {
+		return nil, nil
+	}
+
+	pluginIds := make(map[string]string)
+	for _, plugin := range a.Plugins.Active() {
+		pluginIds[model.PluginPreferenceCategory(plugin.Manifest.Id)] = plugin.Manifest.Id
+	}
+
+	if len(pluginIds) == 0 {
+		return nil, nil
+	}
+
+	preferences, err := a.GetPreferencesForUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var pluginPreferences map[string]map[string]string
+	for _, preference := range preferences {
+		if !model.IsPluginPreferenceCategory(preference.Category) {
+			continue
+		}
+
+		pluginId, ok := pluginIds[preference.Category]
+		if !ok {
+			continue
+		}
+
+		if pluginPreferences == nil {
+			pluginPreferences = make(map[string]map[string]string)
+		}
+		if pluginPreferences[pluginId] == nil {
+			pluginPreferences[pluginId] = make(map[string]string)
+		}
+		pluginPreferences[pluginId][preference.Name] = preference.Value
+	}
+
+	return pluginPreferences, nil
+}
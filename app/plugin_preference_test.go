@@ -0,0 +1,91 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestSetUserPluginSetting(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	pluginId := "testpluginid"
+	userId := th.BasicUser.Id
+
+	require.Nil(t, th.App.SetUserPluginSetting(pluginId, userId, "theme", "dark"))
+	require.Nil(t, th.App.SetUserPluginSetting(pluginId, userId, "layout", "compact"))
+	require.Nil(t, th.App.SetUserPluginSetting("otherpluginid", userId, "theme", "light"))
+
+	settings, err := th.App.GetUserPluginSettings(pluginId, userId)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"theme": "dark", "layout": "compact"}, settings)
+
+	// The settings are stored in the plugin's namespace of the user's preferences.
+	preference, err := th.App.GetPreferenceByCategoryAndNameForUser(userId, model.PluginPreferenceCategory(pluginId), "theme")
+	require.Nil(t, err)
+	assert.Equal(t, "dark", preference.Value)
+
+	t.Run("an empty value deletes the setting", func(t *testing.T) {
+		require.Nil(t, th.App.SetUserPluginSetting(pluginId, userId, "layout", ""))
+		require.Nil(t, th.App.SetUserPluginSetting(pluginId, userId, "missing", ""))
+
+		settings, err := th.App.GetUserPluginSettings(pluginId, userId)
+		require.Nil(t, err)
+		assert.Equal(t, map[string]string{"theme": "dark"}, settings)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		err := th.App.SetUserPluginSetting(pluginId, userId, strings.Repeat("a", model.PLUGIN_PREFERENCE_KEY_MAX_LENGTH+1), "value")
+		require.NotNil(t, err)
+		assert.Equal(t, "model.plugin_preference.is_valid.key.app_error", err.Id)
+	})
+
+	t.Run("too many settings", func(t *testing.T) {
+		countPluginId := "counttestpluginid"
+		for i := 0; i < model.PLUGIN_PREFERENCE_MAX_COUNT; i++ {
+			require.Nil(t, th.App.SetUserPluginSetting(countPluginId, userId, fmt.Sprintf("key%d", i), "value"))
+		}
+
+		err := th.App.SetUserPluginSetting(countPluginId, userId, "onemore", "value")
+		require.NotNil(t, err)
+		assert.Equal(t, "app.plugin_preference.max_count.app_error", err.Id)
+
+		// Existing settings may still be changed.
+		assert.Nil(t, th.App.SetUserPluginSetting(countPluginId, userId, "key0", "changed"))
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		sizePluginId := "sizetestpluginid"
+		value := strings.Repeat("a", 2000)
+		var err *model.AppError
+		for i := 0; i < model.PLUGIN_PREFERENCE_MAX_COUNT && err == nil; i++ {
+			err = th.App.SetUserPluginSetting(sizePluginId, userId, fmt.Sprintf("key%d", i), value)
+		}
+		require.NotNil(t, err)
+		assert.Equal(t, "app.plugin_preference.max_size.app_error", err.Id)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, err.StatusCode)
+	})
+
+	t.Run("purged with the plugin's data", func(t *testing.T) {
+		result := <-th.App.Srv.Store.Preference().DeleteAllInCategory(model.PluginPreferenceCategory(pluginId))
+		require.Nil(t, result.Err)
+
+		settings, err := th.App.GetUserPluginSettings(pluginId, userId)
+		require.Nil(t, err)
+		assert.Empty(t, settings)
+
+		settings, err = th.App.GetUserPluginSettings("otherpluginid", userId)
+		require.Nil(t, err)
+		assert.Equal(t, map[string]string{"theme": "light"}, settings)
+	})
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/utils"
+)
+
+// prepackagedPluginsSummary lists the ids of the prepackaged plugins installed and skipped, and the
+// paths of the bundles that could not be installed.
+type prepackagedPluginsSummary struct {
+	Installed []string
+	Skipped   []string
+	Failed    []string
+}
+
+// installPrepackagedPlugins installs the plugin bundles found in PluginSettings.PrepackagedDirectory,
+// along with any detached signature shipped next to them, unless the plugin is already installed at
+// the same or a newer version, or was removed by an admin.
+func (a *App) installPrepackagedPlugins() *prepackagedPluginsSummary {
+	summary := &prepackagedPluginsSummary{}
+
+	prepackagedPluginsDir, found := utils.FindDir(*a.Config().PluginSettings.PrepackagedDirectory)
+	if !found {
+		return summary
+	}
+
+	if err := filepath.Walk(prepackagedPluginsDir, func(walkPath string, info os.FileInfo, err error) error {
+		if !strings.HasSuffix(walkPath, ".tar.gz") && !strings.HasSuffix(walkPath, ".zip") {
+			return nil
+		}
+
+		manifest, installed, appErr := a.installPrepackagedPlugin(walkPath)
+		switch {
+		case appErr != nil:
+			mlog.Error("Failed to install prepackaged plugin", mlog.String("path", walkPath), mlog.Err(appErr))
+			summary.Failed = append(summary.Failed, walkPath)
+		case installed:
+			summary.Installed = append(summary.Installed, manifest.Id)
+		default:
+			summary.Skipped = append(summary.Skipped, manifest.Id)
+		}
+
+		return nil
+	}); err != nil {
+		mlog.Error("Failed to complete unpacking prepackaged plugins", mlog.Err(err))
+	}
+
+	mlog.Info(
+		"Processed prepackaged plugins",
+		mlog.String("path", prepackagedPluginsDir),
+		mlog.String("installed", strings.Join(summary.Installed, ",")),
+		mlog.String("skipped", strings.Join(summary.Skipped, ",")),
+		mlog.Int("failed", len(summary.Failed)),
+	)
+
+	return summary
+}
+
+// installPrepackagedPlugin installs the plugin bundle at the given path unless the plugin is already
+// installed at the same or a newer version, or was removed by an admin, returning its manifest and
+// whether it was installed.
+func (a *App) installPrepackagedPlugin(bundlePath string) (*model.Manifest, bool, *model.AppError) {
+	bundle, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, false, model.NewAppError("installPrepackagedPlugin", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+	defer bundle.Close()
+
+	// The bundle is extracted once to find out which plugin it holds, and again to install it.
+	tmpDir, tmpPluginDir, appErr := a.extractPluginBundle(bundle)
+	if appErr != nil {
+		return nil, false, appErr
+	}
+	manifest, _, err := model.FindManifest(tmpPluginDir)
+	os.RemoveAll(tmpDir)
+	if err != nil {
+		return nil, false, model.NewAppError("installPrepackagedPlugin", "app.plugin.manifest.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	if reason, appErr := a.skipPrepackagedPluginReason(manifest); appErr != nil {
+		return nil, false, appErr
+	} else if reason != "" {
+		mlog.Info("Skipping prepackaged plugin", mlog.String("plugin_id", manifest.Id), mlog.String("version", manifest.Version), mlog.String("reason", reason))
+		return manifest, false, nil
+	}
+
+	if _, err := bundle.Seek(0, io.SeekStart); err != nil {
+		return nil, false, model.NewAppError("installPrepackagedPlugin", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	// A detached signature may be shipped next to the bundle.
+	var signature io.Reader
+	if signatureFile, err := os.Open(bundlePath + ".sig"); err == nil {
+		defer signatureFile.Close()
+		signature = signatureFile
+	}
+
+	if manifest, appErr = a.InstallPlugin(bundle, signature, true); appErr != nil {
+		return nil, false, appErr
+	}
+
+	return manifest, true, nil
+}
+
+// skipPrepackagedPluginReason returns why the prepackaged plugin with the given manifest should not be
+// installed, or an empty string if it should be.
+func (a *App) skipPrepackagedPluginReason(manifest *model.Manifest) (string, *model.AppError) {
+	plugins, err := a.Plugins.Available()
+	if err != nil {
+		return "", model.NewAppError("skipPrepackagedPluginReason", "app.plugin.get_plugins.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	for _, p := range plugins {
+		if p.Manifest.Id != manifest.Id {
+			continue
+		}
+
+		if comparePluginVersions(manifest.Version, p.Manifest.Version) <= 0 {
+			return "installed version " + p.Manifest.Version + " is not older", nil
+		}
+
+		return "", nil
+	}
+
+	if state, ok := a.Config().PluginSettings.PluginStates[manifest.Id]; ok && state.Removed {
+		return "removed by an admin", nil
+	}
+
+	return "", nil
+}
+
+// setPluginRemoved records whether the plugin was removed by an admin, keeping the rest of its
+// configured state so that it is enabled again if reinstalled.
+func (a *App) setPluginRemoved(id string, removed bool) {
+	state, ok := a.Config().PluginSettings.PluginStates[id]
+	if (ok && state.Removed) == removed {
+		return
+	}
+
+	a.UpdateConfig(func(cfg *model.Config) {
+		state, ok := cfg.PluginSettings.PluginStates[id]
+		if !ok {
+			state = &model.PluginState{}
+			cfg.PluginSettings.PluginStates[id] = state
+		}
+		state.Removed = removed
+	})
+
+	if err := a.SaveConfig(a.Config(), true); err != nil {
+		mlog.Error("Failed to save the removal state of a plugin", mlog.String("plugin_id", id), mlog.Bool("removed", removed), mlog.Err(err))
+	}
+}
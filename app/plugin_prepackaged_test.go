@@ -0,0 +1,142 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+func TestInstallPrepackagedPlugins(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginDir)
+	webappPluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(webappPluginDir)
+	prepackagedPluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(prepackagedPluginDir)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.Directory = pluginDir
+		*cfg.PluginSettings.ClientDirectory = webappPluginDir
+		*cfg.PluginSettings.PrepackagedDirectory = prepackagedPluginDir
+	})
+
+	env, err := plugin.NewEnvironment(th.App.NewPluginAPI, pluginDir, webappPluginDir, th.App.Log)
+	require.NoError(t, err)
+	th.App.Plugins = env
+
+	makeBundle := func(id, version string) []byte {
+		var bundle bytes.Buffer
+		gzipWriter := gzip.NewWriter(&bundle)
+		tarWriter := tar.NewWriter(gzipWriter)
+		for name, contents := range map[string]string{
+			"plugin.json": `{"id": "` + id + `", "version": "` + version + `", "webapp": {"bundle_path": "main.js"}}`,
+			"main.js":     "",
+		} {
+			require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(contents)), Typeflag: tar.TypeReg}))
+			_, err := tarWriter.Write([]byte(contents))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tarWriter.Close())
+		require.NoError(t, gzipWriter.Close())
+		return bundle.Bytes()
+	}
+
+	installedVersion := func(id string) string {
+		plugins, err := th.App.Plugins.Available()
+		require.NoError(t, err)
+		for _, p := range plugins {
+			if p.Manifest.Id == id {
+				return p.Manifest.Version
+			}
+		}
+		return ""
+	}
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(prepackagedPluginDir, "otherplugin.tar.gz"), makeBundle("otherplugin", "0.0.1"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(prepackagedPluginDir, "testplugin.tar.gz"), makeBundle("testplugin", "0.0.2"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(prepackagedPluginDir, "broken.tar.gz"), []byte("not a bundle"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(prepackagedPluginDir, "README.md"), []byte("ignored"), 0600))
+
+	t.Run("fresh install", func(t *testing.T) {
+		summary := th.App.installPrepackagedPlugins()
+		assert.ElementsMatch(t, []string{"otherplugin", "testplugin"}, summary.Installed)
+		assert.Empty(t, summary.Skipped)
+		assert.Equal(t, []string{filepath.Join(prepackagedPluginDir, "broken.tar.gz")}, summary.Failed)
+		assert.Equal(t, "0.0.2", installedVersion("testplugin"))
+	})
+
+	t.Run("already installed", func(t *testing.T) {
+		summary := th.App.installPrepackagedPlugins()
+		assert.Empty(t, summary.Installed)
+		assert.ElementsMatch(t, []string{"otherplugin", "testplugin"}, summary.Skipped)
+	})
+
+	t.Run("newer version installed", func(t *testing.T) {
+		_, appErr := th.App.InstallPlugin(bytes.NewReader(makeBundle("otherplugin", "0.0.5")), nil, true)
+		require.Nil(t, appErr)
+
+		summary := th.App.installPrepackagedPlugins()
+		assert.Contains(t, summary.Skipped, "otherplugin")
+		assert.Equal(t, "0.0.5", installedVersion("otherplugin"))
+	})
+
+	t.Run("older version installed", func(t *testing.T) {
+		_, appErr := th.App.InstallPlugin(bytes.NewReader(makeBundle("testplugin", "0.0.1")), nil, true)
+		require.Nil(t, appErr)
+
+		summary := th.App.installPrepackagedPlugins()
+		assert.Equal(t, []string{"testplugin"}, summary.Installed)
+		assert.Equal(t, "0.0.2", installedVersion("testplugin"))
+	})
+
+	t.Run("removed by an admin", func(t *testing.T) {
+		require.Nil(t, th.App.RemovePlugin("testplugin"))
+		require.True(t, th.App.Config().PluginSettings.PluginStates["testplugin"].Removed)
+
+		summary := th.App.installPrepackagedPlugins()
+		assert.Empty(t, summary.Installed)
+		assert.Contains(t, summary.Skipped, "testplugin")
+		assert.Empty(t, installedVersion("testplugin"))
+	})
+
+	t.Run("reinstalled by an admin", func(t *testing.T) {
+		_, appErr := th.App.InstallPlugin(bytes.NewReader(makeBundle("testplugin", "0.0.1")), nil, false)
+		require.Nil(t, appErr)
+		assert.False(t, th.App.Config().PluginSettings.PluginStates["testplugin"].Removed)
+
+		summary := th.App.installPrepackagedPlugins()
+		assert.Equal(t, []string{"testplugin"}, summary.Installed)
+		assert.Equal(t, "0.0.2", installedVersion("testplugin"))
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.PrepackagedDirectory = filepath.Join(prepackagedPluginDir, "missing")
+		})
+
+		summary := th.App.installPrepackagedPlugins()
+		assert.Empty(t, summary.Installed)
+		assert.Empty(t, summary.Skipped)
+		assert.Empty(t, summary.Failed)
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+// limitedPluginHandler wraps the handler of a plugin's HTTP requests so that request bodies larger
+// than the route's limit are rejected with a 413 status code. Requests declaring a larger body are
+// rejected before the plugin sees them, while streamed bodies are cut off once they exceed the limit
+// and the plugin's response is replaced, so that a plugin never handles a truncated body as whole.
+func (a *App) limitedPluginHandler(manifest *model.Manifest, handler func(*plugin.Context, http.ResponseWriter, *http.Request)) func(*plugin.Context, http.ResponseWriter, *http.Request) {
+	return func(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
+		limit := a.pluginRequestBodyLimit(manifest, r.URL.Path)
+
+		if r.ContentLength > limit {
+			writePluginRequestBodyTooLarge(w, c.RequestId, limit)
+			return
+		}
+
+		if r.Body == nil {
+			handler(c, w, r)
+			return
+		}
+
+		body := &countingReadCloser{ReadCloser: r.Body}
+		r.Body = http.MaxBytesReader(w, body, limit)
+
+		limited := &limitedBodyResponseWriter{ResponseWriter: w, body: body, limit: limit, requestId: c.RequestId}
+		handler(c, limited, r)
+
+		if !limited.wroteHeader && body.read > limit {
+			writePluginRequestBodyTooLarge(w, c.RequestId, limit)
+		}
+	}
+}
+
+// pluginRequestBodyLimit returns the largest request body accepted on the given path of the plugin,
+// as declared by the route of its manifest or otherwise configured for all plugins.
+func (a *App) pluginRequestBodyLimit(manifest *model.Manifest, path string) int64 {
+	if manifest != nil {
+		if route := manifest.GetRoute(path); route != nil && route.MaxBodySize > 0 {
+			return route.MaxBodySize
+		}
+	}
+
+	if limit := *a.Config().PluginSettings.MaxRequestBodySize; limit > 0 {
+		return limit
+	}
+	return *a.Config().FileSettings.MaxFileSize
+}
+
+func writePluginRequestBodyTooLarge(w http.ResponseWriter, requestId string, limit int64) {
+	err := model.NewAppError("ServePluginRequest", "app.plugin.request_body_too_large.app_error", map[string]interface{}{"Max": limit}, "max_body_size="+strconv.FormatInt(limit, 10), http.StatusRequestEntityTooLarge)
+	err.RequestId = requestId
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(err.StatusCode)
+	w.Write([]byte(err.ToJson()))
+}
+
+// countingReadCloser counts the bytes read from a request body.
+type countingReadCloser struct {
+	io.ReadCloser
+	read int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	return n, err
+}
+
+// limitedBodyResponseWriter replaces the plugin's response with a 413 status code if the request
+// body exceeded its limit before the plugin began responding.
+type limitedBodyResponseWriter struct {
+	http.ResponseWriter
+	body        *countingReadCloser
+	limit       int64
+	requestId   string
+	wroteHeader bool
+	rejected    bool
+}
+
+func (w *limitedBodyResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if w.body.read > w.limit {
+		w.rejected = true
+		writePluginRequestBodyTooLarge(w.ResponseWriter, w.requestId, w.limit)
+		return
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *limitedBodyResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.rejected {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends any buffered data to the client, allowing plugins to stream responses.
+func (w *limitedBodyResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.rejected {
+		return
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
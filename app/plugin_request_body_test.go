@@ -0,0 +1,188 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+func TestLimitedPluginHandler(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.MaxRequestBodySize = 1024
+	})
+
+	manifest := &model.Manifest{
+		Id: "testpluginid",
+		Routes: []*model.ManifestRoute{
+			{Path: "/upload", MaxBodySize: 4096},
+			{Path: "/small", MaxBodySize: 16},
+		},
+	}
+
+	var invoked bool
+	var received []byte
+	handler := th.App.limitedPluginHandler(manifest, func(_ *plugin.Context, w http.ResponseWriter, r *http.Request) {
+		invoked = true
+		var err error
+		received, err = ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	send := func(path string, size int, chunked bool) *httptest.ResponseRecorder {
+		invoked = false
+		received = nil
+		r := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(make([]byte, size)))
+		if chunked {
+			r.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		handler(&plugin.Context{RequestId: "requestid"}, w, r)
+		return w
+	}
+
+	assertTooLarge := func(t *testing.T, w *httptest.ResponseRecorder, limit int64) {
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		err := model.AppErrorFromJson(w.Body)
+		require.NotNil(t, err)
+		assert.Equal(t, "app.plugin.request_body_too_large.app_error", err.Id)
+		assert.Equal(t, "requestid", err.RequestId)
+		assert.Equal(t, "max_body_size="+strconv.FormatInt(limit, 10), err.DetailedError)
+	}
+
+	for name, chunked := range map[string]bool{"content length": false, "chunked": true} {
+		t.Run(name, func(t *testing.T) {
+			for path, limit := range map[string]int{"/other": 1024, "/upload": 4096, "/small": 16} {
+				w := send(path, limit, chunked)
+				assert.Equal(t, http.StatusCreated, w.Code, path)
+				assert.True(t, invoked, path)
+				assert.Len(t, received, limit, path)
+
+				w = send(path, limit+1, chunked)
+				assertTooLarge(t, w, int64(limit))
+
+				// Declared bodies are rejected before the plugin sees them.
+				assert.Equal(t, chunked, invoked, path)
+			}
+		})
+	}
+
+	t.Run("the default follows the maximum file size", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.MaxRequestBodySize = 0
+			*cfg.FileSettings.MaxFileSize = 2048
+		})
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.FileSettings.MaxFileSize = 52428800
+		})
+
+		assert.Equal(t, http.StatusCreated, send("/other", 2048, true).Code)
+		assertTooLarge(t, send("/other", 2049, true), 2048)
+
+		// Routes declaring their own limit are unaffected.
+		assert.Equal(t, http.StatusCreated, send("/upload", 4096, false).Code)
+	})
+
+	t.Run("responses begun before the limit was exceeded are kept", func(t *testing.T) {
+		streaming := th.App.limitedPluginHandler(manifest, func(_ *plugin.Context, w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			w.(http.Flusher).Flush()
+			_, err := ioutil.ReadAll(r.Body)
+			assert.NotNil(t, err)
+		})
+
+		r := httptest.NewRequest(http.MethodPost, "/small", bytes.NewReader(make([]byte, 17)))
+		r.ContentLength = -1
+		w := httptest.NewRecorder()
+		streaming(&plugin.Context{}, w, r)
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.True(t, w.Flushed)
+	})
+}
+
+func TestPluginRequestBodyLimitOverRPC(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.MaxRequestBodySize = 1024
+	})
+
+	SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"io/ioutil"
+			"net/http"
+			"strconv"
+
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				p.API.KVSet("result", []byte("error"))
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			p.API.KVSet("result", []byte(strconv.Itoa(len(body))))
+			w.WriteHeader(http.StatusCreated)
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+
+	require.Len(t, th.App.Plugins.Active(), 1)
+	pluginId := th.App.Plugins.Active()[0].Manifest.Id
+
+	send := func(size int) (*httptest.ResponseRecorder, string) {
+		r := httptest.NewRequest(http.MethodPost, "/plugins/"+pluginId+"/upload", bytes.NewReader(make([]byte, size)))
+		r.ContentLength = -1
+		w := httptest.NewRecorder()
+		th.App.ServePluginRequest(w, mux.SetURLVars(r, map[string]string{"plugin_id": pluginId}))
+
+		result, err := th.App.GetPluginKey(pluginId, "result")
+		require.Nil(t, err)
+		require.Nil(t, th.App.DeletePluginKey(pluginId, "result"))
+		return w, string(result)
+	}
+
+	w, result := send(1024)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "1024", result)
+
+	// The plugin sees the body cut off as a failed read, never as a complete but truncated body.
+	w, result = send(64 * 1024)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, "error", result)
+}
@@ -6,6 +6,7 @@ package app
 import (
 	"net/http"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
@@ -15,25 +16,88 @@ import (
 	"github.com/mattermost/mattermost-server/utils"
 )
 
+// PLUGIN_STARTING_RETRY_AFTER_SECONDS is suggested to clients whose requests reach a plugin that is
+// still warm starting.
+const PLUGIN_STARTING_RETRY_AFTER_SECONDS = 5
+
+// ServePluginRequest passes the request on to the plugin named by the route. The request is identified
+// by the id in its X-Request-ID header, or a new id if it has none, which is passed to the plugin and
+// returned in the response. While a request with the X-Read-After-Write header is being served, the
+// plugin's key-value pairs are read from the master database.
 func (a *App) ServePluginRequest(w http.ResponseWriter, r *http.Request) {
+	requestId := r.Header.Get(model.HEADER_REQUEST_ID)
+	if !model.IsValidId(requestId) {
+		requestId = model.NewId()
+	}
+	r.Header.Set(model.HEADER_REQUEST_ID, requestId)
+	w.Header().Set(model.HEADER_REQUEST_ID, requestId)
+
 	if a.Plugins == nil || !*a.Config().PluginSettings.Enable {
 		err := model.NewAppError("ServePluginRequest", "app.plugin.disabled.app_error", nil, "Enable plugins to serve plugin requests", http.StatusNotImplemented)
-		a.Log.Error(err.Error())
-		w.WriteHeader(err.StatusCode)
+		err.RequestId = requestId
+		a.Log.Error(err.Error(), mlog.String("request_id", requestId))
 		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(err.StatusCode)
 		w.Write([]byte(err.ToJson()))
 		return
 	}
 
 	params := mux.Vars(r)
 	hooks, err := a.Plugins.HooksForPlugin(params["plugin_id"])
-	if err != nil {
-		a.Log.Error("Access to route for non-existent plugin", mlog.String("missing_plugin_id", params["plugin_id"]), mlog.Err(err))
+	if err == plugin.ErrPluginStarting {
+		err := model.NewAppError("ServePluginRequest", "app.plugin.starting.app_error", nil, "plugin_id="+params["plugin_id"], http.StatusServiceUnavailable)
+		err.RequestId = requestId
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", strconv.Itoa(PLUGIN_STARTING_RETRY_AFTER_SECONDS))
+		w.WriteHeader(err.StatusCode)
+		w.Write([]byte(err.ToJson()))
+		return
+	} else if err != nil {
+		a.Log.Error("Access to route for non-existent plugin", mlog.String("missing_plugin_id", params["plugin_id"]), mlog.String("request_id", requestId), mlog.Err(err))
 		http.NotFound(w, r)
 		return
 	}
 
-	a.servePluginRequest(w, r, hooks.ServeHTTP)
+	if teamId := r.Header.Get(model.HEADER_TEAM_ID); teamId != "" && !a.IsPluginAllowedOnTeam(params["plugin_id"], teamId) {
+		err := model.NewAppError("ServePluginRequest", "app.plugin.team_restricted.app_error", nil, "plugin_id="+params["plugin_id"]+", team_id="+teamId, http.StatusForbidden)
+		err.RequestId = requestId
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(err.StatusCode)
+		w.Write([]byte(err.ToJson()))
+		return
+	}
+
+	// The routes of a plugin's OAuth provider are served by the server itself, and so may set cookies
+	// outside of the plugin's namespace.
+	if handler := a.pluginOAuthHandler(params["plugin_id"], r); handler != nil {
+		a.servePluginRequest(w, r, a.limitedPluginHandler(nil, handler))
+		return
+	}
+
+	manifest := a.Plugins.Manifest(params["plugin_id"])
+	w = newPluginResponseWriter(w, a.Log, manifest, a.Config().PluginSettings.ProtectedResponseHeaders)
+	a.servePluginRequest(w, r, sessionRequiredPluginHandler(manifest, a.limitedPluginHandler(manifest, a.idempotentPluginHandler(manifest, hooks.ServeHTTP))))
+}
+
+// sessionRequiredPluginHandler rejects requests without a valid session to the routes that the
+// plugin's manifest declares as requiring one, before the plugin handles them.
+func sessionRequiredPluginHandler(manifest *model.Manifest, handler func(*plugin.Context, http.ResponseWriter, *http.Request)) func(*plugin.Context, http.ResponseWriter, *http.Request) {
+	if manifest == nil || len(manifest.Routes) == 0 {
+		return handler
+	}
+
+	return func(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
+		if route := manifest.GetRoute(r.URL.Path); route != nil && route.RequireSession && r.Header.Get("Mattermost-User-Id") == "" {
+			err := model.NewAppError("ServePluginRequest", "app.plugin.session_required.app_error", nil, "plugin_id="+manifest.Id+", path="+r.URL.Path, http.StatusUnauthorized)
+			err.RequestId = c.RequestId
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(err.StatusCode)
+			w.Write([]byte(err.ToJson()))
+			return
+		}
+
+		handler(c, w, r)
+	}
 }
 
 func (a *App) servePluginRequest(w http.ResponseWriter, r *http.Request, handler func(*plugin.Context, http.ResponseWriter, *http.Request)) {
@@ -54,6 +118,12 @@ func (a *App) servePluginRequest(w http.ResponseWriter, r *http.Request, handler
 	if token != "" {
 		if session, err := a.GetSession(token); session != nil && err == nil {
 			r.Header.Set("Mattermost-User-Id", session.UserId)
+
+			// Like requests to the API, requests with a session are also limited by user. Those
+			// without one are only limited by the server-wide rate limiter wrapping every route.
+			if a.Srv.RateLimiter != nil && a.Srv.RateLimiter.UserIdRateLimit(session.UserId, w) {
+				return
+			}
 		}
 	}
 
@@ -76,5 +146,11 @@ func (a *App) servePluginRequest(w http.ResponseWriter, r *http.Request, handler
 	r.URL.RawQuery = newQuery.Encode()
 	r.URL.Path = strings.TrimPrefix(r.URL.Path, path.Join(subpath, "plugins", params["plugin_id"]))
 
-	handler(&plugin.Context{}, w, r)
+	r = plugin.RequestWithID(r, params["plugin_id"])
+
+	if r.Header.Get(model.HEADER_READ_AFTER_WRITE) != "" {
+		defer a.beginPluginReadAfterWrite(params["plugin_id"])()
+	}
+
+	handler(&plugin.Context{RequestId: r.Header.Get(model.HEADER_REQUEST_ID)}, w, r)
 }
@@ -0,0 +1,138 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// PLUGIN_COOKIE_PREFIX begins the names of the cookies a plugin may set, followed by its id and an
+// underscore.
+const PLUGIN_COOKIE_PREFIX = "MM_PLUGIN_"
+
+// pluginResponseWriter keeps a plugin from setting cookies outside of its own namespace, such as the
+// session cookie, or from overriding protected security headers on responses served under the site
+// origin. Offending headers are removed just before the response headers are written, and are
+// otherwise passed through untouched.
+type pluginResponseWriter struct {
+	http.ResponseWriter
+	log          *mlog.Logger
+	pluginId     string
+	cookiePrefix string
+
+	// protectedHeaders maps each protected header the plugin did not declare to its values before
+	// the plugin handled the request.
+	protectedHeaders map[string][]string
+	filtered         bool
+}
+
+func newPluginResponseWriter(w http.ResponseWriter, log *mlog.Logger, manifest *model.Manifest, protected []string) *pluginResponseWriter {
+	pw := &pluginResponseWriter{
+		ResponseWriter:   w,
+		log:              log,
+		protectedHeaders: make(map[string][]string),
+	}
+
+	declared := make(map[string]bool)
+	if manifest != nil {
+		pw.pluginId = manifest.Id
+		for _, header := range manifest.SecurityHeaders {
+			declared[http.CanonicalHeaderKey(header)] = true
+		}
+	}
+	pw.cookiePrefix = PLUGIN_COOKIE_PREFIX + pw.pluginId + "_"
+
+	for _, header := range protected {
+		header = http.CanonicalHeaderKey(header)
+		if !declared[header] {
+			pw.protectedHeaders[header] = append([]string(nil), w.Header()[header]...)
+		}
+	}
+
+	return pw
+}
+
+func (w *pluginResponseWriter) WriteHeader(statusCode int) {
+	w.filterHeaders()
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *pluginResponseWriter) Write(b []byte) (int, error) {
+	w.filterHeaders()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends any buffered data to the client, allowing plugins to stream responses.
+func (w *pluginResponseWriter) Flush() {
+	w.filterHeaders()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *pluginResponseWriter) filterHeaders() {
+	if w.filtered {
+		return
+	}
+	w.filtered = true
+
+	header := w.Header()
+
+	for name, original := range w.protectedHeaders {
+		if stringSlicesEqual(header[name], original) {
+			continue
+		}
+
+		w.log.Warn("Plugin attempted to override a protected response header", mlog.String("plugin_id", w.pluginId), mlog.String("header", name))
+		if original == nil {
+			header.Del(name)
+		} else {
+			header[name] = original
+		}
+	}
+
+	cookies := header["Set-Cookie"]
+	if len(cookies) == 0 {
+		return
+	}
+
+	var allowed []string
+	for _, cookie := range cookies {
+		name := cookie
+		if i := strings.Index(cookie, "="); i >= 0 {
+			name = strings.TrimSpace(cookie[:i])
+		}
+
+		if !strings.HasPrefix(name, w.cookiePrefix) {
+			w.log.Warn("Plugin attempted to set a cookie outside of its namespace", mlog.String("plugin_id", w.pluginId), mlog.String("cookie", name))
+			continue
+		}
+
+		allowed = append(allowed, cookie)
+	}
+
+	if len(allowed) == 0 {
+		header.Del("Set-Cookie")
+	} else {
+		header["Set-Cookie"] = allowed
+	}
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestPluginResponseWriter(t *testing.T) {
+	log := mlog.NewLogger(&mlog.LoggerConfiguration{})
+	protected := []string{"Content-Security-Policy", "X-Frame-Options"}
+
+	t.Run("cookies", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		w := newPluginResponseWriter(recorder, log, &model.Manifest{Id: "myplugin"}, protected)
+
+		http.SetCookie(w, &http.Cookie{Name: model.SESSION_COOKIE_TOKEN, Value: "token"})
+		http.SetCookie(w, &http.Cookie{Name: "MM_PLUGIN_myplugin_state", Value: "allowed"})
+		http.SetCookie(w, &http.Cookie{Name: "MM_PLUGIN_otherplugin_state", Value: "denied"})
+		w.WriteHeader(http.StatusOK)
+
+		cookies := recorder.Result().Cookies()
+		if assert.Len(t, cookies, 1) {
+			assert.Equal(t, "MM_PLUGIN_myplugin_state", cookies[0].Name)
+		}
+	})
+
+	t.Run("protected headers", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		recorder.Header().Set("X-Frame-Options", "SAMEORIGIN")
+		w := newPluginResponseWriter(recorder, log, &model.Manifest{Id: "myplugin"}, protected)
+
+		w.Header().Set("X-Frame-Options", "ALLOWALL")
+		w.Header().Set("Content-Security-Policy", "default-src *")
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("body"))
+
+		assert.Equal(t, "SAMEORIGIN", recorder.Header().Get("X-Frame-Options"))
+		assert.Empty(t, recorder.Header().Get("Content-Security-Policy"))
+		assert.Equal(t, "text/plain", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, "body", recorder.Body.String())
+	})
+
+	t.Run("declared headers", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		manifest := &model.Manifest{Id: "myplugin", SecurityHeaders: []string{"content-security-policy"}}
+		w := newPluginResponseWriter(recorder, log, manifest, protected)
+
+		w.Header().Set("Content-Security-Policy", "default-src 'self'")
+		w.Header().Set("X-Frame-Options", "ALLOWALL")
+		w.WriteHeader(http.StatusNoContent)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		assert.Equal(t, "default-src 'self'", recorder.Header().Get("Content-Security-Policy"))
+		assert.Empty(t, recorder.Header().Get("X-Frame-Options"))
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		w := newPluginResponseWriter(recorder, log, &model.Manifest{Id: "myplugin"}, protected)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("X-Frame-Options", "ALLOWALL")
+		w.Write([]byte("data: 1\n\n"))
+		w.Flush()
+
+		assert.True(t, recorder.Flushed)
+		assert.Equal(t, "text/event-stream", recorder.Header().Get("Content-Type"))
+		assert.Empty(t, recorder.Header().Get("X-Frame-Options"))
+		assert.Equal(t, "data: 1\n\n", recorder.Body.String())
+	})
+}
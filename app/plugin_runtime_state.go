@@ -0,0 +1,110 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	// PLUGIN_RUNTIME_STATE_REFRESH_INTERVAL is how often a server refreshes the persisted states of
+	// its plugins and deletes those of servers that stopped refreshing theirs.
+	PLUGIN_RUNTIME_STATE_REFRESH_INTERVAL = 5 * time.Minute
+
+	// PLUGIN_RUNTIME_STATE_TTL is how long the persisted states of a server's plugins are reported
+	// after it stops refreshing them, such as when it leaves the cluster.
+	PLUGIN_RUNTIME_STATE_TTL = 3 * PLUGIN_RUNTIME_STATE_REFRESH_INTERVAL
+
+	// PLUGIN_CRASH_LOOP_FAILURE_COUNT is the number of consecutive times a plugin may fail to start
+	// on a server before it is disabled.
+	PLUGIN_CRASH_LOOP_FAILURE_COUNT = 5
+)
+
+// pluginStateChanged persists the new state of a plugin on this server, counting the consecutive
+// times it failed to start across restarts, and disables it once it has failed
+// PLUGIN_CRASH_LOOP_FAILURE_COUNT times.
+func (a *App) pluginStateChanged(pluginId string, state int, err error) {
+	runtimeState := &model.PluginRuntimeState{
+		PluginId:  pluginId,
+		ClusterId: a.GetClusterId(),
+		State:     state,
+	}
+
+	if state != model.PluginStateRunning {
+		if result := <-a.Srv.Store.PluginRuntimeState().Get(pluginId, runtimeState.ClusterId); result.Err == nil {
+			runtimeState.FailureCount = result.Data.(*model.PluginRuntimeState).FailureCount
+		}
+	}
+
+	if err != nil {
+		runtimeState.Error = err.Error()
+		runtimeState.FailureCount++
+	}
+
+	if result := <-a.Srv.Store.PluginRuntimeState().Save(runtimeState); result.Err != nil {
+		mlog.Error("Failed to save plugin runtime state", mlog.String("plugin_id", pluginId), mlog.Err(result.Err))
+	}
+
+	switch {
+	case err != nil:
+		a.recordPluginEvent(pluginId, model.PLUGIN_EVENT_FAILED, runtimeState.Error)
+	case state == model.PluginStateRunning:
+		a.recordPluginEvent(pluginId, model.PLUGIN_EVENT_ACTIVATED, "")
+	case state == model.PluginStateNotRunning:
+		a.recordPluginEvent(pluginId, model.PLUGIN_EVENT_DEACTIVATED, "")
+	}
+
+	if err != nil && runtimeState.FailureCount >= PLUGIN_CRASH_LOOP_FAILURE_COUNT {
+		// The plugin is being activated, so it is disabled once that has completed.
+		a.Go(func() {
+			mlog.Warn("Disabling plugin that repeatedly failed to start", mlog.String("plugin_id", pluginId), mlog.Int("failure_count", runtimeState.FailureCount))
+			if appErr := a.disablePluginWithReason(pluginId, model.PLUGIN_STATE_REASON_CRASH_LOOP); appErr != nil {
+				mlog.Error("Failed to disable plugin", mlog.String("plugin_id", pluginId), mlog.Err(appErr))
+			}
+		})
+	}
+}
+
+// RefreshPluginRuntimeStates keeps the persisted states of the plugins on this server from expiring,
+// and deletes those of servers that have not refreshed theirs within PLUGIN_RUNTIME_STATE_TTL.
+func (a *App) RefreshPluginRuntimeStates() {
+	now := model.GetMillis()
+
+	if result := <-a.Srv.Store.PluginRuntimeState().Touch(a.GetClusterId(), now); result.Err != nil {
+		mlog.Error("Failed to refresh plugin runtime states", mlog.Err(result.Err))
+	}
+
+	result := <-a.Srv.Store.PluginRuntimeState().DeleteStale(now - int64(PLUGIN_RUNTIME_STATE_TTL/time.Millisecond))
+	if result.Err != nil {
+		mlog.Error("Failed to delete stale plugin runtime states", mlog.Err(result.Err))
+		return
+	}
+
+	if deleted := result.Data.(int64); deleted > 0 {
+		mlog.Debug("Deleted stale plugin runtime states", mlog.Int64("count", deleted))
+	}
+}
+
+// getPluginRuntimeStates returns the persisted states of every plugin on every server that has
+// refreshed them within PLUGIN_RUNTIME_STATE_TTL.
+func (a *App) getPluginRuntimeStates() ([]*model.PluginRuntimeState, *model.AppError) {
+	result := <-a.Srv.Store.PluginRuntimeState().GetAll()
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	expiredBefore := model.GetMillis() - int64(PLUGIN_RUNTIME_STATE_TTL/time.Millisecond)
+
+	var states []*model.PluginRuntimeState
+	for _, state := range result.Data.([]*model.PluginRuntimeState) {
+		if state.UpdateAt >= expiredBefore {
+			states = append(states, state)
+		}
+	}
+
+	return states, nil
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+func TestPluginRuntimeStates(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = true })
+
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginDir)
+
+	pluginId := model.NewId()
+	require.NoError(t, os.Mkdir(filepath.Join(pluginDir, pluginId), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pluginDir, pluginId, "plugin.json"), []byte(`{"id": "`+pluginId+`", "webapp": {"bundle_path": "../bundle.js"}}`), 0600))
+
+	newEnvironment := func() *plugin.Environment {
+		env, err := plugin.NewEnvironment(th.App.NewPluginAPI, pluginDir, pluginDir, th.App.Log)
+		require.NoError(t, err)
+		env.SetStateChangeHandler(th.App.pluginStateChanged)
+		th.App.Plugins = env
+		return env
+	}
+
+	getStatus := func() *model.PluginStatus {
+		pluginStatuses, appErr := th.App.GetPluginStatuses()
+		require.Nil(t, appErr)
+		for _, status := range pluginStatuses {
+			if status.PluginId == pluginId {
+				return status
+			}
+		}
+		require.Fail(t, "plugin status not found")
+		return nil
+	}
+
+	env := newEnvironment()
+	for i := 0; i < 2; i++ {
+		env.Deactivate(pluginId)
+		_, _, err = env.Activate(pluginId)
+		require.Error(t, err)
+	}
+
+	status := getStatus()
+	assert.Equal(t, model.PluginStateFailedToStart, status.State)
+	assert.Equal(t, err.Error(), status.Error)
+	assert.Equal(t, 2, status.FailureCount)
+
+	t.Run("failures are counted across restarts", func(t *testing.T) {
+		env.Shutdown()
+
+		env = newEnvironment()
+		_, _, err = env.Activate(pluginId)
+		require.Error(t, err)
+
+		assert.Equal(t, 3, getStatus().FailureCount)
+	})
+
+	t.Run("disabled after failing repeatedly", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			cfg.PluginSettings.PluginStates[pluginId] = model.NewPluginState(true, model.PLUGIN_STATE_REASON_ADMIN)
+		})
+
+		for i := getStatus().FailureCount; i < PLUGIN_CRASH_LOOP_FAILURE_COUNT; i++ {
+			th.App.pluginStateChanged(pluginId, model.PluginStateFailedToStart, errors.New("unable to start plugin"))
+		}
+
+		// The plugin is disabled in the background.
+		for i := 0; i < 50 && th.App.Config().PluginSettings.PluginStates[pluginId].Enable; i++ {
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		state := th.App.Config().PluginSettings.PluginStates[pluginId]
+		assert.False(t, state.Enable)
+		assert.Equal(t, model.PLUGIN_STATE_REASON_CRASH_LOOP, state.Reason)
+		assert.NotZero(t, state.UpdatedAt)
+	})
+
+	t.Run("failures are reset once running", func(t *testing.T) {
+		th.App.pluginStateChanged(pluginId, model.PluginStateRunning, nil)
+
+		result := <-th.App.Srv.Store.PluginRuntimeState().Get(pluginId, th.App.GetClusterId())
+		require.Nil(t, result.Err)
+		assert.Equal(t, 0, result.Data.(*model.PluginRuntimeState).FailureCount)
+	})
+
+	t.Run("removed with the plugin", func(t *testing.T) {
+		require.Nil(t, th.App.RemovePlugin(pluginId))
+
+		result := <-th.App.Srv.Store.PluginRuntimeState().Get(pluginId, th.App.GetClusterId())
+		require.NotNil(t, result.Err)
+	})
+}
+
+func TestRefreshPluginRuntimeStates(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginId := model.NewId()
+	staleClusterId := model.NewId()
+
+	for _, clusterId := range []string{th.App.GetClusterId(), staleClusterId} {
+		result := <-th.App.Srv.Store.PluginRuntimeState().Save(&model.PluginRuntimeState{PluginId: pluginId, ClusterId: clusterId, State: model.PluginStateRunning})
+		require.Nil(t, result.Err)
+
+		result = <-th.App.Srv.Store.PluginRuntimeState().Touch(clusterId, 1000)
+		require.Nil(t, result.Err)
+	}
+
+	th.App.RefreshPluginRuntimeStates()
+
+	result := <-th.App.Srv.Store.PluginRuntimeState().Get(pluginId, th.App.GetClusterId())
+	require.Nil(t, result.Err)
+	assert.NotEqual(t, int64(1000), result.Data.(*model.PluginRuntimeState).UpdateAt)
+
+	result = <-th.App.Srv.Store.PluginRuntimeState().Get(pluginId, staleClusterId)
+	require.NotNil(t, result.Err)
+}
+
+func TestAddUnreachablePluginStatuses(t *testing.T) {
+	pluginStatuses := model.PluginStatuses{
+		{PluginId: "foo", ClusterId: "a", State: model.PluginStateRunning, Name: "Foo", Version: "1.0.0"},
+	}
+
+	runtimeStates := []*model.PluginRuntimeState{
+		{PluginId: "foo", ClusterId: "a", State: model.PluginStateRunning},
+		{PluginId: "foo", ClusterId: "b", State: model.PluginStateFailedToStart, Error: "unable to start plugin", FailureCount: 3},
+		{PluginId: "bar", ClusterId: "b", State: model.PluginStateRunning},
+	}
+
+	assert.Equal(t, model.PluginStatuses{
+		{PluginId: "foo", ClusterId: "a", State: model.PluginStateRunning, Name: "Foo", Version: "1.0.0"},
+		{PluginId: "foo", ClusterId: "b", State: model.PluginStateFailedToStart, Name: "Foo", Version: "1.0.0", Error: "unable to start plugin", FailureCount: 3},
+		{PluginId: "bar", ClusterId: "b", State: model.PluginStateRunning},
+	}, addUnreachablePluginStatuses(pluginStatuses, runtimeStates))
+}
+
+func TestAddPluginStateReasons(t *testing.T) {
+	pluginStatuses := model.PluginStatuses{
+		{PluginId: "foo", ClusterId: "a", State: model.PluginStateFailedToStart},
+		{PluginId: "foo", ClusterId: "b", State: model.PluginStateRunning},
+		{PluginId: "bar", ClusterId: "a", State: model.PluginStateNotRunning},
+		{PluginId: "baz", ClusterId: "a", State: model.PluginStateNotRunning},
+	}
+
+	addPluginStateReasons(pluginStatuses, map[string]*model.PluginState{
+		"foo": {Enable: true, Reason: model.PLUGIN_STATE_REASON_ADMIN, UpdatedAt: 1000},
+		"bar": {Enable: false, Reason: model.PLUGIN_STATE_REASON_CRASH_LOOP, UpdatedAt: 2000},
+	})
+
+	assert.Equal(t, model.PluginStatuses{
+		{PluginId: "foo", ClusterId: "a", State: model.PluginStateFailedToStart, Enabled: true, StateReason: model.PLUGIN_STATE_REASON_ADMIN, StateUpdatedAt: 1000},
+		{PluginId: "foo", ClusterId: "b", State: model.PluginStateRunning, Enabled: true, StateReason: model.PLUGIN_STATE_REASON_ADMIN, StateUpdatedAt: 1000},
+		{PluginId: "bar", ClusterId: "a", State: model.PluginStateNotRunning, StateReason: model.PLUGIN_STATE_REASON_CRASH_LOOP, StateUpdatedAt: 2000},
+		{PluginId: "baz", ClusterId: "a", State: model.PluginStateNotRunning},
+	}, pluginStatuses)
+}
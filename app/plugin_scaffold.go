@@ -0,0 +1,81 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"sort"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+// ScaffoldPlugin generates the skeleton of a new plugin for a plugin developer, implementing the
+// requested hooks against this server's plugin package. The files are returned by their paths
+// relative to the plugin's directory.
+func (a *App) ScaffoldPlugin(request *model.PluginScaffoldRequest) (map[string][]byte, *model.AppError) {
+	if err := request.IsValid(); err != nil {
+		return nil, err
+	}
+
+	hooks := make(map[string]bool)
+	for _, hook := range plugin.ScaffoldHooks() {
+		hooks[hook] = true
+	}
+	for _, hook := range request.Hooks {
+		if !hooks[hook] {
+			return nil, model.NewAppError("ScaffoldPlugin", "app.plugin.scaffold.hook.app_error", map[string]interface{}{"Hook": hook}, "plugin_id="+request.PluginId, http.StatusBadRequest)
+		}
+	}
+
+	files, err := plugin.Scaffold(request)
+	if err != nil {
+		return nil, model.NewAppError("ScaffoldPlugin", "app.plugin.scaffold.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return files, nil
+}
+
+// ScaffoldPluginArchive generates the skeleton of a new plugin as ScaffoldPlugin does, returning it
+// as a .tar.gz file containing the plugin's directory, named after the plugin.
+func (a *App) ScaffoldPluginArchive(request *model.PluginScaffoldRequest) ([]byte, *model.AppError) {
+	files, appErr := a.ScaffoldPlugin(request)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var archive bytes.Buffer
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, path := range paths {
+		if err := tarWriter.WriteHeader(&tar.Header{
+			Name:     request.PluginId + "/" + path,
+			Mode:     0644,
+			Size:     int64(len(files[path])),
+			Typeflag: tar.TypeReg,
+		}); err != nil {
+			return nil, model.NewAppError("ScaffoldPluginArchive", "app.plugin.scaffold.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		if _, err := tarWriter.Write(files[path]); err != nil {
+			return nil, model.NewAppError("ScaffoldPluginArchive", "app.plugin.scaffold.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, model.NewAppError("ScaffoldPluginArchive", "app.plugin.scaffold.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, model.NewAppError("ScaffoldPluginArchive", "app.plugin.scaffold.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return archive.Bytes(), nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/utils"
+)
+
+func TestScaffoldPlugin(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	for name, tc := range map[string]struct {
+		Request *model.PluginScaffoldRequest
+		Error   string
+	}{
+		"invalid plugin id":  {&model.PluginScaffoldRequest{PluginId: "../scaffold"}, "model.plugin_scaffold_request.is_valid.plugin_id.app_error"},
+		"unknown capability": {&model.PluginScaffoldRequest{PluginId: "scaffold", Capabilities: []string{"everything"}}, "model.plugin_scaffold_request.is_valid.capability.app_error"},
+		"unknown hook":       {&model.PluginScaffoldRequest{PluginId: "scaffold", Hooks: []string{"OnActivate", "MessageWillBeDeleted"}}, "app.plugin.scaffold.hook.app_error"},
+		"internal hook":      {&model.PluginScaffoldRequest{PluginId: "scaffold", Hooks: []string{"Implemented"}}, "app.plugin.scaffold.hook.app_error"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := th.App.ScaffoldPlugin(tc.Request)
+			require.NotNil(t, err)
+			assert.Equal(t, tc.Error, err.Id)
+			assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+		})
+	}
+
+	t.Run("archive", func(t *testing.T) {
+		request := &model.PluginScaffoldRequest{PluginId: "scaffold", Hooks: []string{"OnActivate"}}
+		files, appErr := th.App.ScaffoldPlugin(request)
+		require.Nil(t, appErr)
+
+		archive, appErr := th.App.ScaffoldPluginArchive(request)
+		require.Nil(t, appErr)
+
+		dir, err := ioutil.TempDir("", "")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		require.NoError(t, utils.ExtractTarGz(bytes.NewReader(archive), dir))
+
+		for path, contents := range files {
+			extracted, err := ioutil.ReadFile(filepath.Join(dir, "scaffold", filepath.FromSlash(path)))
+			require.NoError(t, err, path)
+			assert.Equal(t, contents, extracted, path)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// PLUGIN_SERVER_HEALTH_CACHE_DURATION is how long the server health reported to plugins is reused, so
+// that plugins polling it do not add to the load on the services it checks.
+const PLUGIN_SERVER_HEALTH_CACHE_DURATION = 5 * time.Second
+
+// GetPluginServerHealth returns the health of the server and the services it depends on, as reported
+// to plugins. The checks are run at most once every PLUGIN_SERVER_HEALTH_CACHE_DURATION, with
+// concurrent callers waiting for the same checks to finish.
+func (a *App) GetPluginServerHealth() model.PluginServerHealth {
+	a.pluginServerHealthLock.Lock()
+	defer a.pluginServerHealthLock.Unlock()
+
+	if a.pluginServerHealth != nil && model.GetMillis()-a.pluginServerHealth.CheckedAt < int64(PLUGIN_SERVER_HEALTH_CACHE_DURATION/time.Millisecond) {
+		return *a.pluginServerHealth
+	}
+
+	health := a.checkPluginServerHealth()
+	a.pluginServerHealth = &health
+
+	return health
+}
+
+func (a *App) checkPluginServerHealth() model.PluginServerHealth {
+	cfg := a.Config()
+
+	health := model.PluginServerHealth{
+		Database:             runPluginHealthCheck("database", a.Srv.Store.PingMaster),
+		DatabaseReplicas:     runPluginHealthCheck("database replicas", a.Srv.Store.PingReplicas),
+		FileStore:            runPluginHealthCheck("file store", a.testFileStoreConnection),
+		ClusterEnabled:       a.IsHighAvailability(),
+		ClusterNodeCount:     1,
+		MetricsEnabled:       *cfg.MetricsSettings.Enable,
+		ElasticsearchEnabled: *cfg.ElasticsearchSettings.EnableIndexing,
+		CheckedAt:            model.GetMillis(),
+	}
+
+	if health.ClusterEnabled {
+		health.ClusterNodeCount = len(a.GetClusterStatus())
+	}
+
+	return health
+}
+
+func (a *App) testFileStoreConnection() error {
+	backend, err := a.FileBackend()
+	if err != nil {
+		return err
+	}
+
+	if err := backend.TestConnection(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// runPluginHealthCheck times the given check. Only a generic description of a failure is reported to
+// plugins, so its details are logged instead.
+func runPluginHealthCheck(name string, check func() error) model.PluginHealthCheck {
+	start := time.Now()
+	err := check()
+
+	result := model.PluginHealthCheck{
+		Healthy: err == nil,
+		Latency: time.Since(start),
+	}
+
+	if err != nil {
+		mlog.Warn("Server health check for plugins failed", mlog.String("check", name), mlog.Err(err))
+		result.Error = name + " unreachable"
+	}
+
+	return result
+}
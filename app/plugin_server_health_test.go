@@ -0,0 +1,40 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestGetPluginServerHealth(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	health := th.App.GetPluginServerHealth()
+	assert.True(t, health.Database.Healthy)
+	assert.True(t, health.DatabaseReplicas.Healthy)
+	assert.True(t, health.FileStore.Healthy)
+	assert.Empty(t, health.FileStore.Error)
+	assert.False(t, health.ClusterEnabled)
+	assert.Equal(t, 1, health.ClusterNodeCount)
+	assert.NotZero(t, health.CheckedAt)
+
+	// Failures are only reported once the cached checks have expired.
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.FileSettings.DriverName = model.IMAGE_DRIVER_LOCAL
+		cfg.FileSettings.Directory = "/dev/null/files"
+	})
+	assert.Equal(t, health, th.App.GetPluginServerHealth())
+
+	th.App.pluginServerHealth.CheckedAt -= int64(2 * PLUGIN_SERVER_HEALTH_CACHE_DURATION / time.Millisecond)
+	health = th.App.GetPluginServerHealth()
+	assert.True(t, health.Database.Healthy)
+	assert.False(t, health.FileStore.Healthy)
+	assert.Equal(t, "file store unreachable", health.FileStore.Error)
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// pluginSettingEncryptedPrefix marks secret plugin settings stored encrypted in the configuration, and
+// is followed by the base64 encoding of the value encrypted as by encryptPluginKeyValue.
+const pluginSettingEncryptedPrefix = "encrypted:"
+
+// pluginSecretSettings returns the settings each available plugin's manifest marks secret, keyed by
+// plugin id and then by the lower-cased setting key, as settings are keyed in the configuration.
+func (a *App) pluginSecretSettings() map[string]map[string]bool {
+	if a.Plugins == nil {
+		return nil
+	}
+
+	bundles, err := a.Plugins.Available()
+	if err != nil {
+		mlog.Error("Failed to list plugins to find their secret settings", mlog.Err(err))
+		return nil
+	}
+
+	secrets := make(map[string]map[string]bool)
+	for _, bundle := range bundles {
+		if bundle.Manifest == nil || bundle.Manifest.SettingsSchema == nil {
+			continue
+		}
+
+		for _, setting := range bundle.Manifest.SettingsSchema.Settings {
+			if !setting.Secret {
+				continue
+			}
+			if secrets[bundle.Manifest.Id] == nil {
+				secrets[bundle.Manifest.Id] = make(map[string]bool)
+			}
+			secrets[bundle.Manifest.Id][strings.ToLower(setting.Key)] = true
+		}
+	}
+
+	return secrets
+}
+
+// pluginManifestSecretSettings is like pluginSecretSettings, for a single plugin.
+func pluginManifestSecretSettings(manifest *model.Manifest) map[string]bool {
+	secrets := make(map[string]bool)
+	if manifest.SettingsSchema == nil {
+		return secrets
+	}
+
+	for _, setting := range manifest.SettingsSchema.Settings {
+		if setting.Secret {
+			secrets[strings.ToLower(setting.Key)] = true
+		}
+	}
+
+	return secrets
+}
+
+func newPluginSettingsEncryptionKey(cfg *model.Config) (*pluginKeyValueEncryptionKey, error) {
+	if cfg.PluginSettings.SettingsEncryptionKey == nil || *cfg.PluginSettings.SettingsEncryptionKey == "" {
+		return nil, nil
+	}
+
+	return newPluginKeyValueEncryptionKey(*cfg.PluginSettings.SettingsEncryptionKey)
+}
+
+func encryptPluginSetting(value string, key *pluginKeyValueEncryptionKey) (string, error) {
+	encrypted, err := encryptPluginKeyValue([]byte(value), key)
+	if err != nil {
+		return "", err
+	}
+
+	return pluginSettingEncryptedPrefix + base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+// decryptPluginSetting reverses encryptPluginSetting. Values that are not encrypted are returned as is.
+func decryptPluginSetting(value string, key *pluginKeyValueEncryptionKey) (string, error) {
+	if !strings.HasPrefix(value, pluginSettingEncryptedPrefix) {
+		return value, nil
+	}
+
+	if key == nil {
+		return "", errPluginKeyValueUnknownEncryptionKey
+	}
+
+	encrypted, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, pluginSettingEncryptedPrefix))
+	if err != nil {
+		return "", errors.Wrap(err, "unable to decode value")
+	}
+
+	decrypted, err := decryptPluginKeyValue(encrypted, []*pluginKeyValueEncryptionKey{key})
+	if err != nil {
+		return "", err
+	}
+
+	return string(decrypted), nil
+}
+
+// pluginSettingEncryptedWith returns whether value was encrypted by encryptPluginSetting with key.
+func pluginSettingEncryptedWith(value string, key *pluginKeyValueEncryptionKey) bool {
+	if key == nil || !strings.HasPrefix(value, pluginSettingEncryptedPrefix) {
+		return false
+	}
+
+	encrypted, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, pluginSettingEncryptedPrefix))
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(pluginKeyValueEncryptionKeyId(encrypted), key.id)
+}
+
+// sanitizePluginSecrets replaces the secret plugin settings that are set with model.FAKE_SETTING, so
+// that they are never returned through the API.
+func sanitizePluginSecrets(cfg *model.Config, secrets map[string]map[string]bool) {
+	for pluginId, keys := range secrets {
+		settings := cfg.PluginSettings.Plugins[pluginId]
+		for key, value := range settings {
+			if keys[strings.ToLower(key)] && value != nil && value != "" {
+				settings[key] = model.FAKE_SETTING
+			}
+		}
+	}
+}
+
+// desanitizePluginSecrets restores the secret plugin settings left as model.FAKE_SETTING to their value
+// in actual, so that saving a configuration read through the API leaves them unchanged.
+func desanitizePluginSecrets(cfg, actual *model.Config, secrets map[string]map[string]bool) {
+	for pluginId, keys := range secrets {
+		settings := cfg.PluginSettings.Plugins[pluginId]
+		for key, value := range settings {
+			if !keys[strings.ToLower(key)] || value != model.FAKE_SETTING {
+				continue
+			}
+
+			delete(settings, key)
+			for actualKey, actualValue := range actual.PluginSettings.Plugins[pluginId] {
+				if strings.EqualFold(actualKey, key) {
+					settings[key] = actualValue
+				}
+			}
+		}
+	}
+}
+
+// encodePluginSecrets stores the secret plugin settings of cfg encrypted with its
+// PluginSettings.SettingsEncryptionKey, or in plain text if none is configured. Settings encrypted
+// with the key configured in actual are re-encrypted if the key has changed, while those encrypted
+// with any other key are left alone.
+func encodePluginSecrets(cfg, actual *model.Config, secrets map[string]map[string]bool) error {
+	key, err := newPluginSettingsEncryptionKey(cfg)
+	if err != nil {
+		return err
+	}
+
+	previousKey, err := newPluginSettingsEncryptionKey(actual)
+	if err != nil {
+		return err
+	}
+
+	for pluginId, keys := range secrets {
+		settings := cfg.PluginSettings.Plugins[pluginId]
+		for settingKey, value := range settings {
+			stringValue, ok := value.(string)
+			if !keys[strings.ToLower(settingKey)] || !ok || stringValue == "" || pluginSettingEncryptedWith(stringValue, key) {
+				continue
+			}
+
+			decrypted, err := decryptPluginSetting(stringValue, previousKey)
+			if err != nil {
+				// Leave the setting as is rather than prevent the rest of the configuration from being saved.
+				mlog.Warn("Failed to decrypt secret plugin setting", mlog.String("plugin_id", pluginId), mlog.String("key", settingKey), mlog.Err(err))
+				continue
+			}
+
+			if key == nil {
+				settings[settingKey] = decrypted
+				continue
+			}
+
+			if settings[settingKey], err = encryptPluginSetting(decrypted, key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// encodePluginSecrets encrypts the secret plugin settings of a configuration being saved.
+func (a *App) encodePluginSecrets(cfg *model.Config) *model.AppError {
+	if err := encodePluginSecrets(cfg, a.Config(), a.pluginSecretSettings()); err != nil {
+		return model.NewAppError("encodePluginSecrets", "app.plugin.settings.encrypt.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
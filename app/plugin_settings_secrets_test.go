@@ -0,0 +1,136 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func newTestPluginSecretsConfig(encryptionKey string, settings map[string]interface{}) *model.Config {
+	cfg := &model.Config{}
+	cfg.SetDefaults()
+	*cfg.PluginSettings.SettingsEncryptionKey = encryptionKey
+	cfg.PluginSettings.Plugins["myplugin"] = settings
+	return cfg
+}
+
+func TestPluginSecrets(t *testing.T) {
+	encryptionKey := model.NewRandomString(32)
+	secrets := map[string]map[string]bool{"myplugin": {"apitoken": true}}
+
+	t.Run("round trip", func(t *testing.T) {
+		actual := newTestPluginSecretsConfig(encryptionKey, nil)
+		cfg := newTestPluginSecretsConfig(encryptionKey, map[string]interface{}{"apitoken": "thesecret", "username": "theuser"})
+
+		require.NoError(t, encodePluginSecrets(cfg, actual, secrets))
+		stored := cfg.PluginSettings.Plugins["myplugin"]["apitoken"].(string)
+		assert.True(t, strings.HasPrefix(stored, pluginSettingEncryptedPrefix))
+		assert.NotContains(t, stored, "thesecret")
+		assert.Equal(t, "theuser", cfg.PluginSettings.Plugins["myplugin"]["username"])
+
+		key, err := newPluginSettingsEncryptionKey(cfg)
+		require.NoError(t, err)
+		decrypted, err := decryptPluginSetting(stored, key)
+		require.NoError(t, err)
+		assert.Equal(t, "thesecret", decrypted)
+
+		// Saving again leaves the encrypted setting unchanged.
+		saved := cfg.Clone()
+		require.NoError(t, encodePluginSecrets(saved, cfg, secrets))
+		assert.Equal(t, stored, saved.PluginSettings.Plugins["myplugin"]["apitoken"])
+
+		_, err = decryptPluginSetting(stored, newTestPluginKeyValueEncryptionKey(t))
+		assert.Error(t, err)
+		_, err = decryptPluginSetting(stored, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("no encryption key", func(t *testing.T) {
+		actual := newTestPluginSecretsConfig("", nil)
+		cfg := newTestPluginSecretsConfig("", map[string]interface{}{"apitoken": "thesecret"})
+
+		require.NoError(t, encodePluginSecrets(cfg, actual, secrets))
+		assert.Equal(t, "thesecret", cfg.PluginSettings.Plugins["myplugin"]["apitoken"])
+	})
+
+	t.Run("sanitize", func(t *testing.T) {
+		cfg := newTestPluginSecretsConfig(encryptionKey, map[string]interface{}{"apitoken": "thesecret", "username": "theuser"})
+		cfg.PluginSettings.Plugins["otherplugin"] = map[string]interface{}{"apitoken": "othervalue"}
+
+		sanitizePluginSecrets(cfg, secrets)
+		assert.Equal(t, model.FAKE_SETTING, cfg.PluginSettings.Plugins["myplugin"]["apitoken"])
+		assert.Equal(t, "theuser", cfg.PluginSettings.Plugins["myplugin"]["username"])
+		assert.Equal(t, "othervalue", cfg.PluginSettings.Plugins["otherplugin"]["apitoken"])
+
+		cfg = newTestPluginSecretsConfig(encryptionKey, map[string]interface{}{"apitoken": ""})
+		sanitizePluginSecrets(cfg, secrets)
+		assert.Equal(t, "", cfg.PluginSettings.Plugins["myplugin"]["apitoken"])
+	})
+
+	t.Run("placeholder save", func(t *testing.T) {
+		actual := newTestPluginSecretsConfig(encryptionKey, map[string]interface{}{"apitoken": "thesecret", "username": "theuser"})
+		require.NoError(t, encodePluginSecrets(actual, newTestPluginSecretsConfig(encryptionKey, nil), secrets))
+		stored := actual.PluginSettings.Plugins["myplugin"]["apitoken"]
+
+		cfg := actual.Clone()
+		sanitizePluginSecrets(cfg, secrets)
+		cfg.PluginSettings.Plugins["myplugin"]["username"] = "otheruser"
+
+		desanitizePluginSecrets(cfg, actual, secrets)
+		require.NoError(t, encodePluginSecrets(cfg, actual, secrets))
+		assert.Equal(t, stored, cfg.PluginSettings.Plugins["myplugin"]["apitoken"])
+		assert.Equal(t, "otheruser", cfg.PluginSettings.Plugins["myplugin"]["username"])
+	})
+
+	t.Run("placeholder save of unset secret", func(t *testing.T) {
+		actual := newTestPluginSecretsConfig(encryptionKey, map[string]interface{}{})
+		cfg := newTestPluginSecretsConfig(encryptionKey, map[string]interface{}{"ApiToken": model.FAKE_SETTING})
+
+		desanitizePluginSecrets(cfg, actual, secrets)
+		assert.NotContains(t, cfg.PluginSettings.Plugins["myplugin"], "ApiToken")
+	})
+
+	t.Run("new value replaces secret", func(t *testing.T) {
+		actual := newTestPluginSecretsConfig(encryptionKey, map[string]interface{}{"apitoken": "thesecret"})
+		require.NoError(t, encodePluginSecrets(actual, newTestPluginSecretsConfig(encryptionKey, nil), secrets))
+
+		cfg := newTestPluginSecretsConfig(encryptionKey, map[string]interface{}{"apitoken": "newsecret"})
+		desanitizePluginSecrets(cfg, actual, secrets)
+		require.NoError(t, encodePluginSecrets(cfg, actual, secrets))
+
+		key, err := newPluginSettingsEncryptionKey(cfg)
+		require.NoError(t, err)
+		decrypted, err := decryptPluginSetting(cfg.PluginSettings.Plugins["myplugin"]["apitoken"].(string), key)
+		require.NoError(t, err)
+		assert.Equal(t, "newsecret", decrypted)
+	})
+
+	t.Run("changing the encryption key", func(t *testing.T) {
+		actual := newTestPluginSecretsConfig(encryptionKey, map[string]interface{}{"apitoken": "thesecret"})
+		require.NoError(t, encodePluginSecrets(actual, newTestPluginSecretsConfig(encryptionKey, nil), secrets))
+
+		newEncryptionKey := model.NewRandomString(32)
+		cfg := actual.Clone()
+		*cfg.PluginSettings.SettingsEncryptionKey = newEncryptionKey
+		require.NoError(t, encodePluginSecrets(cfg, actual, secrets))
+
+		key, err := newPluginSettingsEncryptionKey(cfg)
+		require.NoError(t, err)
+		decrypted, err := decryptPluginSetting(cfg.PluginSettings.Plugins["myplugin"]["apitoken"].(string), key)
+		require.NoError(t, err)
+		assert.Equal(t, "thesecret", decrypted)
+
+		// Removing the key stores the secret in plain text again.
+		disabled := cfg.Clone()
+		*disabled.PluginSettings.SettingsEncryptionKey = ""
+		require.NoError(t, encodePluginSecrets(disabled, cfg, secrets))
+		assert.Equal(t, "thesecret", disabled.PluginSettings.Plugins["myplugin"]["apitoken"])
+	})
+}
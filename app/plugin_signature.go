@@ -0,0 +1,170 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/utils"
+)
+
+const (
+	// PLUGIN_SIGNATURE_MAX_SIZE is the largest detached signature, in bytes, accepted for a plugin
+	// bundle. RSA signatures are as long as the key's modulus, so this allows for 16384 bit keys.
+	PLUGIN_SIGNATURE_MAX_SIZE = 2 * 1024
+
+	// The bundle a plugin was installed from and its signature are kept in the plugin directory,
+	// named after the plugin, so that they can be verified again before the plugin is activated.
+	PLUGIN_SIGNED_BUNDLE_SUFFIX = ".bundle"
+	PLUGIN_SIGNATURE_SUFFIX     = ".bundle.sig"
+)
+
+// readPluginSignature reads the detached signature of a plugin bundle.
+func readPluginSignature(signature io.Reader) ([]byte, *model.AppError) {
+	data, err := ioutil.ReadAll(io.LimitReader(signature, PLUGIN_SIGNATURE_MAX_SIZE+1))
+	if err != nil {
+		return nil, model.NewAppError("readPluginSignature", "app.plugin.signature.read.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	if len(data) == 0 || len(data) > PLUGIN_SIGNATURE_MAX_SIZE {
+		return nil, model.NewAppError("readPluginSignature", "app.plugin.signature.malformed.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return data, nil
+}
+
+// getPluginSignaturePublicKeys loads the public keys configured in
+// PluginSettings.SignaturePublicKeyFiles. They are read each time so that keys can be rotated
+// without restarting the server.
+func (a *App) getPluginSignaturePublicKeys() ([]*rsa.PublicKey, *model.AppError) {
+	files := a.Config().PluginSettings.SignaturePublicKeyFiles
+	if len(files) == 0 {
+		return nil, model.NewAppError("getPluginSignaturePublicKeys", "app.plugin.signature.no_public_keys.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	keys := make([]*rsa.PublicKey, 0, len(files))
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, model.NewAppError("getPluginSignaturePublicKeys", "app.plugin.signature.public_key.app_error", map[string]interface{}{"File": file}, err.Error(), http.StatusInternalServerError)
+		}
+
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, model.NewAppError("getPluginSignaturePublicKeys", "app.plugin.signature.public_key.app_error", map[string]interface{}{"File": file}, "no PEM data found", http.StatusInternalServerError)
+		}
+
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, model.NewAppError("getPluginSignaturePublicKeys", "app.plugin.signature.public_key.app_error", map[string]interface{}{"File": file}, err.Error(), http.StatusInternalServerError)
+		}
+
+		rsaPublic, ok := public.(*rsa.PublicKey)
+		if !ok {
+			return nil, model.NewAppError("getPluginSignaturePublicKeys", "app.plugin.signature.public_key.app_error", map[string]interface{}{"File": file}, "not an RSA public key", http.StatusInternalServerError)
+		}
+
+		keys = append(keys, rsaPublic)
+	}
+
+	return keys, nil
+}
+
+// verifyPluginSignature checks that the signature is an RSA PKCS #1 v1.5 signature of the SHA-512
+// digest of the bundle, made with the private key of one of the configured public keys, as produced by
+// `openssl dgst -sha512 -sign key.pem -out plugin.tar.gz.sig plugin.tar.gz`.
+func (a *App) verifyPluginSignature(bundle io.Reader, signature []byte) *model.AppError {
+	keys, appErr := a.getPluginSignaturePublicKeys()
+	if appErr != nil {
+		return appErr
+	}
+
+	h := sha512.New()
+	if _, err := io.Copy(h, bundle); err != nil {
+		return model.NewAppError("verifyPluginSignature", "app.plugin.signature.read.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+	d := h.Sum(nil)
+
+	for _, key := range keys {
+		if rsa.VerifyPKCS1v15(key, crypto.SHA512, d, signature) == nil {
+			return nil
+		}
+	}
+
+	return model.NewAppError("verifyPluginSignature", "app.plugin.signature.invalid.app_error", nil, "", http.StatusBadRequest)
+}
+
+// verifyInstalledPluginSignature verifies again the signature of the bundle the plugin was installed
+// from, failing if it was installed without one.
+func (a *App) verifyInstalledPluginSignature(id string) *model.AppError {
+	pluginDir := *a.Config().PluginSettings.Directory
+
+	signature, err := ioutil.ReadFile(filepath.Join(pluginDir, id+PLUGIN_SIGNATURE_SUFFIX))
+	if os.IsNotExist(err) {
+		return model.NewAppError("verifyInstalledPluginSignature", "app.plugin.signature.missing.app_error", nil, "plugin_id="+id, http.StatusBadRequest)
+	} else if err != nil {
+		return model.NewAppError("verifyInstalledPluginSignature", "app.plugin.signature.read.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	bundle, err := os.Open(filepath.Join(pluginDir, id+PLUGIN_SIGNED_BUNDLE_SUFFIX))
+	if os.IsNotExist(err) {
+		return model.NewAppError("verifyInstalledPluginSignature", "app.plugin.signature.missing.app_error", nil, "plugin_id="+id, http.StatusBadRequest)
+	} else if err != nil {
+		return model.NewAppError("verifyInstalledPluginSignature", "app.plugin.signature.read.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+	defer bundle.Close()
+
+	return a.verifyPluginSignature(bundle, signature)
+}
+
+// checkPluginSignatureBeforeActivation verifies the signature of the installed plugin if
+// PluginSettings.RequirePluginSignature is set, recording the reason it is refused otherwise.
+func (a *App) checkPluginSignatureBeforeActivation(id string) *model.AppError {
+	if !*a.Config().PluginSettings.RequirePluginSignature {
+		return nil
+	}
+
+	if appErr := a.verifyInstalledPluginSignature(id); appErr != nil {
+		a.recordPluginEvent(id, model.PLUGIN_EVENT_FAILED, appErr.Id)
+		return appErr
+	}
+
+	return nil
+}
+
+// storePluginSignature keeps the bundle the plugin was installed from along with its signature, or
+// removes those of a previously installed version if it was installed without one.
+func (a *App) storePluginSignature(id, bundlePath string, signature []byte) error {
+	if signature == nil {
+		a.removePluginSignature(id)
+		return nil
+	}
+
+	pluginDir := *a.Config().PluginSettings.Directory
+	if err := utils.CopyFile(bundlePath, filepath.Join(pluginDir, id+PLUGIN_SIGNED_BUNDLE_SUFFIX)); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(pluginDir, id+PLUGIN_SIGNATURE_SUFFIX), signature, 0600)
+}
+
+func (a *App) removePluginSignature(id string) {
+	pluginDir := *a.Config().PluginSettings.Directory
+	for _, path := range []string{filepath.Join(pluginDir, id+PLUGIN_SIGNATURE_SUFFIX), filepath.Join(pluginDir, id+PLUGIN_SIGNED_BUNDLE_SUFFIX)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			mlog.Warn("Failed to remove the signature of a plugin", mlog.String("plugin_id", id), mlog.String("path", path), mlog.Err(err))
+		}
+	}
+}
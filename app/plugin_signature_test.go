@@ -0,0 +1,195 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+func TestInstallPluginSignature(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginDir)
+	webappPluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(webappPluginDir)
+
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&signingKey.PublicKey)
+	require.NoError(t, err)
+	keyFile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(keyFile.Name())
+	require.NoError(t, pem.Encode(keyFile, &pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+	require.NoError(t, keyFile.Close())
+	publicKeyFile := keyFile.Name()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.Directory = pluginDir
+		*cfg.PluginSettings.ClientDirectory = webappPluginDir
+		cfg.PluginSettings.SignaturePublicKeyFiles = []string{publicKeyFile}
+		*cfg.PluginSettings.RequirePluginSignature = true
+	})
+
+	env, err := plugin.NewEnvironment(th.App.NewPluginAPI, pluginDir, webappPluginDir, th.App.Log)
+	require.NoError(t, err)
+	th.App.Plugins = env
+
+	var bundle bytes.Buffer
+	gzipWriter := gzip.NewWriter(&bundle)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, contents := range map[string]string{
+		"plugin.json": `{"id": "testplugin", "webapp": {"bundle_path": "main.js"}}`,
+		"main.js":     "",
+	} {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(contents)), Typeflag: tar.TypeReg}))
+		_, err = tarWriter.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+
+	sign := func(key *rsa.PrivateKey, data []byte) []byte {
+		digest := sha512.Sum512(data)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA512, digest[:])
+		require.NoError(t, err)
+		return signature
+	}
+
+	// Bytes after the end of the gzip stream leave the bundle extractable, but change its digest.
+	tampered := append(append([]byte{}, bundle.Bytes()...), 0)
+
+	signatureFile := filepath.Join(pluginDir, "testplugin"+PLUGIN_SIGNATURE_SUFFIX)
+	bundleFile := filepath.Join(pluginDir, "testplugin"+PLUGIN_SIGNED_BUNDLE_SUFFIX)
+
+	for name, tc := range map[string]struct {
+		Bundle    []byte
+		Signature []byte
+		Expected  string
+	}{
+		"tampered bundle": {tampered, sign(signingKey, bundle.Bytes()), "app.plugin.signature.invalid.app_error"},
+		"wrong key":       {bundle.Bytes(), sign(otherKey, bundle.Bytes()), "app.plugin.signature.invalid.app_error"},
+		"empty signature": {bundle.Bytes(), []byte{}, "app.plugin.signature.malformed.app_error"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, appErr := th.App.InstallPlugin(bytes.NewReader(tc.Bundle), bytes.NewReader(tc.Signature), false)
+			require.NotNil(t, appErr)
+			assert.Equal(t, tc.Expected, appErr.Id)
+
+			_, err := os.Stat(filepath.Join(pluginDir, "testplugin"))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+
+	t.Run("unsigned", func(t *testing.T) {
+		_, appErr := th.App.InstallPlugin(bytes.NewReader(bundle.Bytes()), nil, false)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.plugin.signature.missing.app_error", appErr.Id)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		manifest, appErr := th.App.InstallPlugin(bytes.NewReader(bundle.Bytes()), bytes.NewReader(sign(signingKey, bundle.Bytes())), false)
+		require.Nil(t, appErr)
+		assert.Equal(t, "testplugin", manifest.Id)
+		assert.FileExists(t, signatureFile)
+		assert.FileExists(t, bundleFile)
+
+		require.Nil(t, th.App.EnablePlugin("testplugin"))
+		assert.True(t, th.App.Plugins.IsActive("testplugin"))
+	})
+
+	t.Run("tampered bundle is not activated", func(t *testing.T) {
+		require.Nil(t, th.App.DisablePlugin("testplugin"))
+		require.NoError(t, ioutil.WriteFile(bundleFile, tampered, 0600))
+
+		require.Nil(t, th.App.EnablePlugin("testplugin"))
+		assert.False(t, th.App.Plugins.IsActive("testplugin"))
+
+		events, appErr := th.App.GetPluginEvents("testplugin", 0, 0, 100)
+		require.Nil(t, appErr)
+		require.NotEmpty(t, events)
+		last := events[len(events)-1]
+		assert.Equal(t, model.PLUGIN_EVENT_FAILED, last.Type)
+		assert.Equal(t, "app.plugin.signature.invalid.app_error", last.Details)
+	})
+
+	t.Run("unsigned plugins are accepted unless required", func(t *testing.T) {
+		require.Nil(t, th.App.DisablePlugin("testplugin"))
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.RequirePluginSignature = false
+		})
+
+		_, appErr := th.App.InstallPlugin(bytes.NewReader(bundle.Bytes()), nil, true)
+		require.Nil(t, appErr)
+
+		// The signature of the replaced version no longer applies.
+		_, err := os.Stat(signatureFile)
+		assert.True(t, os.IsNotExist(err))
+
+		require.Nil(t, th.App.EnablePlugin("testplugin"))
+		assert.True(t, th.App.Plugins.IsActive("testplugin"))
+	})
+
+	t.Run("no public keys", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			cfg.PluginSettings.SignaturePublicKeyFiles = []string{}
+		})
+
+		_, appErr := th.App.InstallPlugin(bytes.NewReader(bundle.Bytes()), bytes.NewReader(sign(signingKey, bundle.Bytes())), true)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.plugin.signature.no_public_keys.app_error", appErr.Id)
+	})
+
+	t.Run("unreadable public key", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			cfg.PluginSettings.SignaturePublicKeyFiles = []string{publicKeyFile + ".missing"}
+		})
+
+		_, appErr := th.App.InstallPlugin(bytes.NewReader(bundle.Bytes()), bytes.NewReader(sign(signingKey, bundle.Bytes())), true)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.plugin.signature.public_key.app_error", appErr.Id)
+	})
+
+	t.Run("removal", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			cfg.PluginSettings.SignaturePublicKeyFiles = []string{publicKeyFile}
+		})
+
+		_, appErr := th.App.InstallPlugin(bytes.NewReader(bundle.Bytes()), bytes.NewReader(sign(signingKey, bundle.Bytes())), true)
+		require.Nil(t, appErr)
+		assert.FileExists(t, signatureFile)
+
+		require.Nil(t, th.App.RemovePlugin("testplugin"))
+		_, err := os.Stat(signatureFile)
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(bundleFile)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
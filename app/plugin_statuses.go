@@ -6,10 +6,12 @@ package app
 import (
 	"net/http"
 
+	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
 )
 
-// GetPluginStatuses returns the status for plugins installed on this server.
+// GetPluginStatuses returns the status for plugins installed on this server, including why they last
+// failed to start as persisted in their runtime states.
 func (a *App) GetPluginStatuses() (model.PluginStatuses, *model.AppError) {
 	if a.Plugins == nil || !*a.Config().PluginSettings.Enable {
 		return nil, model.NewAppError("GetPluginStatuses", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
@@ -20,9 +22,41 @@ func (a *App) GetPluginStatuses() (model.PluginStatuses, *model.AppError) {
 		return nil, model.NewAppError("GetPluginStatuses", "app.plugin.get_statuses.app_error", nil, err.Error(), http.StatusInternalServerError)
 	}
 
+	var bundlesRemoved, bundleCleanupAt int64
+	if *a.Config().PluginSettings.EnableBundleCleanup {
+		bundlesRemoved, bundleCleanupAt = a.GetPluginBundleCleanupStats()
+	}
+
 	// Add our cluster ID
 	for _, status := range pluginStatuses {
 		status.ClusterId = a.GetClusterId()
+		status.BundlesRemoved = bundlesRemoved
+		status.BundleCleanupAt = bundleCleanupAt
+
+		if status.State == model.PluginStateFailedToStart {
+			if err := a.Plugins.ActivationError(status.PluginId); err != nil {
+				status.Error = err.Error()
+			}
+		}
+	}
+
+	runtimeStates, appErr := a.getPluginRuntimeStates()
+	if appErr != nil {
+		mlog.Error("Failed to get plugin runtime states", mlog.Err(appErr))
+		return pluginStatuses, nil
+	}
+
+	for _, status := range pluginStatuses {
+		for _, runtimeState := range runtimeStates {
+			if runtimeState.PluginId != status.PluginId || runtimeState.ClusterId != status.ClusterId {
+				continue
+			}
+
+			status.FailureCount = runtimeState.FailureCount
+			if status.State == model.PluginStateFailedToStart && status.Error == "" {
+				status.Error = runtimeState.Error
+			}
+		}
 	}
 
 	return pluginStatuses, nil
@@ -44,9 +78,69 @@ func (a *App) GetClusterPluginStatuses() (model.PluginStatuses, *model.AppError)
 		pluginStatuses = append(pluginStatuses, clusterPluginStatuses...)
 	}
 
+	runtimeStates, appErr := a.getPluginRuntimeStates()
+	if appErr != nil {
+		mlog.Error("Failed to get plugin runtime states", mlog.Err(appErr))
+	} else {
+		pluginStatuses = addUnreachablePluginStatuses(pluginStatuses, runtimeStates)
+	}
+
+	addPluginStateReasons(pluginStatuses, a.Config().PluginSettings.PluginStates)
+
 	return pluginStatuses, nil
 }
 
+// addPluginStateReasons adds whether each plugin is enabled, and why, to their statuses. The
+// configured states are shared by the whole cluster, so they are not reported by each server.
+func addPluginStateReasons(pluginStatuses model.PluginStatuses, states map[string]*model.PluginState) {
+	for _, status := range pluginStatuses {
+		if state, ok := states[status.PluginId]; ok {
+			status.Enabled = state.Enable
+			status.StateReason = state.Reason
+			status.StateUpdatedAt = state.UpdatedAt
+		}
+	}
+}
+
+// addUnreachablePluginStatuses adds the last known statuses of plugins on servers that did not report
+// any, such as those restarting or no longer reachable, from the persisted runtime states.
+func addUnreachablePluginStatuses(pluginStatuses model.PluginStatuses, runtimeStates []*model.PluginRuntimeState) model.PluginStatuses {
+	reporting := make(map[string]bool)
+	pluginStatusesById := make(map[string]*model.PluginStatus)
+	for _, status := range pluginStatuses {
+		reporting[status.ClusterId] = true
+		if status.PluginId != "" {
+			pluginStatusesById[status.PluginId] = status
+		}
+	}
+
+	for _, runtimeState := range runtimeStates {
+		if reporting[runtimeState.ClusterId] {
+			continue
+		}
+
+		status := &model.PluginStatus{
+			PluginId:     runtimeState.PluginId,
+			ClusterId:    runtimeState.ClusterId,
+			State:        runtimeState.State,
+			Error:        runtimeState.Error,
+			FailureCount: runtimeState.FailureCount,
+		}
+
+		// Every server of a cluster is expected to have the same plugins installed.
+		if reported, ok := pluginStatusesById[runtimeState.PluginId]; ok {
+			status.PluginPath = reported.PluginPath
+			status.Name = reported.Name
+			status.Description = reported.Description
+			status.Version = reported.Version
+		}
+
+		pluginStatuses = append(pluginStatuses, status)
+	}
+
+	return pluginStatuses
+}
+
 func (a *App) notifyPluginStatusesChanged() error {
 	pluginStatuses, err := a.GetClusterPluginStatuses()
 	if err != nil {
@@ -0,0 +1,84 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// CreatePluginSubscription saves a new subscription owned by the given plugin, regardless of the
+// plugin id set on it. The channel and the user creating the subscription must exist.
+func (a *App) CreatePluginSubscription(pluginId string, subscription *model.PluginSubscription) (*model.PluginSubscription, *model.AppError) {
+	subscription.PluginId = pluginId
+
+	if _, err := a.GetChannel(subscription.ChannelId); err != nil {
+		return nil, err
+	}
+
+	if _, err := a.GetUser(subscription.CreatorId); err != nil {
+		return nil, err
+	}
+
+	result := <-a.Srv.Store.PluginSubscription().Save(subscription)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.(*model.PluginSubscription), nil
+}
+
+// GetPluginSubscriptionsForChannel returns the subscriptions of every plugin in the given channel,
+// without their payloads, for listing to users.
+func (a *App) GetPluginSubscriptionsForChannel(channelId string) ([]*model.PluginSubscription, *model.AppError) {
+	result := <-a.Srv.Store.PluginSubscription().GetForChannel(channelId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	subscriptions := result.Data.([]*model.PluginSubscription)
+	for _, subscription := range subscriptions {
+		subscription.Sanitize()
+	}
+
+	return subscriptions, nil
+}
+
+// GetPluginSubscriptionsForChannelByPlugin returns only the subscriptions owned by the given plugin
+// in the given channel, including their payloads.
+func (a *App) GetPluginSubscriptionsForChannelByPlugin(pluginId, channelId string) ([]*model.PluginSubscription, *model.AppError) {
+	result := <-a.Srv.Store.PluginSubscription().GetForChannel(channelId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	subscriptions := []*model.PluginSubscription{}
+	for _, subscription := range result.Data.([]*model.PluginSubscription) {
+		if subscription.PluginId == pluginId {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+
+	return subscriptions, nil
+}
+
+// DeletePluginSubscription deletes a subscription owned by the given plugin. The subscriptions of
+// other plugins are reported as not found.
+func (a *App) DeletePluginSubscription(pluginId, subscriptionId string) *model.AppError {
+	result := <-a.Srv.Store.PluginSubscription().Get(subscriptionId)
+	if result.Err != nil {
+		return result.Err
+	}
+
+	if result.Data.(*model.PluginSubscription).PluginId != pluginId {
+		return model.NewAppError("DeletePluginSubscription", "app.plugin.subscription.not_found.app_error", nil, "id="+subscriptionId, http.StatusNotFound)
+	}
+
+	if result := <-a.Srv.Store.PluginSubscription().Delete(subscriptionId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
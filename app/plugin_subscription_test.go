@@ -0,0 +1,84 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestPluginSubscriptions(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	subscription, err := th.App.CreatePluginSubscription("plugin1", &model.PluginSubscription{
+		PluginId:    "plugin2",
+		ChannelId:   th.BasicChannel.Id,
+		CreatorId:   th.BasicUser.Id,
+		Description: "subscription",
+		Payload:     []byte("payload"),
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "plugin1", subscription.PluginId)
+
+	_, err = th.App.CreatePluginSubscription("plugin2", &model.PluginSubscription{
+		ChannelId: th.BasicChannel.Id,
+		CreatorId: th.BasicUser.Id,
+	})
+	require.Nil(t, err)
+
+	t.Run("missing channel", func(t *testing.T) {
+		_, err := th.App.CreatePluginSubscription("plugin1", &model.PluginSubscription{
+			ChannelId: model.NewId(),
+			CreatorId: th.BasicUser.Id,
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("missing creator", func(t *testing.T) {
+		_, err := th.App.CreatePluginSubscription("plugin1", &model.PluginSubscription{
+			ChannelId: th.BasicChannel.Id,
+			CreatorId: model.NewId(),
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("plugins only list their own subscriptions", func(t *testing.T) {
+		subscriptions, err := th.App.GetPluginSubscriptionsForChannelByPlugin("plugin1", th.BasicChannel.Id)
+		require.Nil(t, err)
+		require.Len(t, subscriptions, 1)
+		assert.Equal(t, subscription.Id, subscriptions[0].Id)
+		assert.Equal(t, []byte("payload"), subscriptions[0].Payload)
+
+		subscriptions, err = th.App.GetPluginSubscriptionsForChannelByPlugin("plugin3", th.BasicChannel.Id)
+		require.Nil(t, err)
+		assert.Empty(t, subscriptions)
+	})
+
+	t.Run("listing all subscriptions removes payloads", func(t *testing.T) {
+		subscriptions, err := th.App.GetPluginSubscriptionsForChannel(th.BasicChannel.Id)
+		require.Nil(t, err)
+		require.Len(t, subscriptions, 2)
+		for _, s := range subscriptions {
+			assert.Nil(t, s.Payload)
+		}
+	})
+
+	t.Run("plugins only delete their own subscriptions", func(t *testing.T) {
+		err := th.App.DeletePluginSubscription("plugin2", subscription.Id)
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+		require.Nil(t, th.App.DeletePluginSubscription("plugin1", subscription.Id))
+
+		subscriptions, err := th.App.GetPluginSubscriptionsForChannelByPlugin("plugin1", th.BasicChannel.Id)
+		require.Nil(t, err)
+		assert.Empty(t, subscriptions)
+	})
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// GetPluginAllowedTeams returns the ids of the only teams on which the plugin is available, or an
+// empty list if it is available on every team.
+func (a *App) GetPluginAllowedTeams(pluginId string) []string {
+	return append([]string{}, a.Config().PluginSettings.PluginTeamRestrictions[pluginId]...)
+}
+
+// IsPluginAllowedOnTeam returns whether the plugin's commands, web app components and HTTP requests
+// are available on the given team.
+func (a *App) IsPluginAllowedOnTeam(pluginId, teamId string) bool {
+	allowedTeams := a.Config().PluginSettings.PluginTeamRestrictions[pluginId]
+	if len(allowedTeams) == 0 {
+		return true
+	}
+
+	for _, allowedTeamId := range allowedTeams {
+		if allowedTeamId == teamId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FilterPluginManifestsForUser removes the manifests of plugins not available to the user. If a team
+// is given, the plugins must be available on that team, and otherwise on one of the user's teams.
+func (a *App) FilterPluginManifestsForUser(manifests []*model.Manifest, userId, teamId string) []*model.Manifest {
+	var userTeamIds []string
+	if teamId != "" {
+		userTeamIds = []string{teamId}
+	} else if userId != "" && len(a.Config().PluginSettings.PluginTeamRestrictions) > 0 {
+		members, err := a.GetTeamMembersForUser(userId)
+		if err != nil {
+			mlog.Error("Failed to get teams to filter plugins for user", mlog.String("user_id", userId), mlog.Err(err))
+		}
+		for _, member := range members {
+			if member.DeleteAt == 0 {
+				userTeamIds = append(userTeamIds, member.TeamId)
+			}
+		}
+	}
+
+	filtered := []*model.Manifest{}
+	for _, manifest := range manifests {
+		if a.isPluginAllowedOnAnyTeam(manifest.Id, userTeamIds) {
+			filtered = append(filtered, manifest)
+		}
+	}
+
+	return filtered
+}
+
+func (a *App) isPluginAllowedOnAnyTeam(pluginId string, teamIds []string) bool {
+	if len(a.Config().PluginSettings.PluginTeamRestrictions[pluginId]) == 0 {
+		return true
+	}
+
+	for _, teamId := range teamIds {
+		if a.IsPluginAllowedOnTeam(pluginId, teamId) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// publishPluginEnabled tells the clients allowed to use the plugin to load its web app components.
+func (a *App) publishPluginEnabled(manifest *model.Manifest) {
+	teamIds := a.GetPluginAllowedTeams(manifest.Id)
+	if len(teamIds) == 0 {
+		teamIds = []string{""}
+	}
+
+	for _, teamId := range teamIds {
+		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_PLUGIN_ENABLED, teamId, "", "", nil)
+		message.Add("manifest", manifest.ClientManifest())
+		a.Publish(message)
+	}
+}
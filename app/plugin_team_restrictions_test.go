@@ -0,0 +1,105 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/utils"
+)
+
+func TestIsPluginAllowedOnTeam(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	teamId := model.NewId()
+	otherTeamId := model.NewId()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		cfg.PluginSettings.PluginTeamRestrictions = map[string][]string{
+			"restricted": {teamId},
+			"empty":      {},
+		}
+	})
+
+	assert.True(t, th.App.IsPluginAllowedOnTeam("restricted", teamId))
+	assert.False(t, th.App.IsPluginAllowedOnTeam("restricted", otherTeamId))
+	assert.True(t, th.App.IsPluginAllowedOnTeam("empty", otherTeamId))
+	assert.True(t, th.App.IsPluginAllowedOnTeam("unrestricted", otherTeamId))
+
+	assert.Equal(t, []string{teamId}, th.App.GetPluginAllowedTeams("restricted"))
+	assert.Equal(t, []string{}, th.App.GetPluginAllowedTeams("unrestricted"))
+}
+
+func TestFilterPluginManifestsForUser(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		cfg.PluginSettings.PluginTeamRestrictions = map[string][]string{
+			"basicteam": {th.BasicTeam.Id},
+			"otherteam": {model.NewId()},
+		}
+	})
+
+	manifests := []*model.Manifest{{Id: "basicteam"}, {Id: "otherteam"}, {Id: "unrestricted"}}
+	ids := func(manifests []*model.Manifest) []string {
+		var ids []string
+		for _, manifest := range manifests {
+			ids = append(ids, manifest.Id)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"basicteam", "unrestricted"}, ids(th.App.FilterPluginManifestsForUser(manifests, th.BasicUser.Id, "")))
+	assert.Equal(t, []string{"basicteam", "unrestricted"}, ids(th.App.FilterPluginManifestsForUser(manifests, th.BasicUser.Id, th.BasicTeam.Id)))
+	assert.Equal(t, []string{"unrestricted"}, ids(th.App.FilterPluginManifestsForUser(manifests, th.BasicUser.Id, model.NewId())))
+	assert.Equal(t, []string{"unrestricted"}, ids(th.App.FilterPluginManifestsForUser(manifests, th.CreateUser().Id, "")))
+	assert.Equal(t, []string{"unrestricted"}, ids(th.App.FilterPluginManifestsForUser(manifests, "", "")))
+}
+
+func TestPluginCommandTeamRestrictions(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	require.Nil(t, th.App.RegisterPluginCommand("restrictedplugin", &model.Command{Trigger: "restricted", AutoComplete: true}))
+	defer th.App.UnregisterPluginCommands("restrictedplugin")
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		cfg.PluginSettings.PluginTeamRestrictions = map[string][]string{"restrictedplugin": {model.NewId()}}
+	})
+
+	hasTrigger := func(commands []*model.Command) bool {
+		for _, command := range commands {
+			if command.Trigger == "restricted" {
+				return true
+			}
+		}
+		return false
+	}
+
+	commands, err := th.App.ListAutocompleteCommands(th.BasicTeam.Id, utils.T)
+	require.Nil(t, err)
+	assert.False(t, hasTrigger(commands))
+
+	commands, err = th.App.ListAllCommands(th.BasicTeam.Id, utils.T)
+	require.Nil(t, err)
+	assert.False(t, hasTrigger(commands))
+
+	cmd, _, err := th.App.ExecutePluginCommand(&model.CommandArgs{Command: "/restricted", TeamId: th.BasicTeam.Id})
+	require.Nil(t, err)
+	assert.Nil(t, cmd)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		cfg.PluginSettings.PluginTeamRestrictions = map[string][]string{"restrictedplugin": {th.BasicTeam.Id}}
+	})
+
+	commands, err = th.App.ListAutocompleteCommands(th.BasicTeam.Id, utils.T)
+	require.Nil(t, err)
+	assert.True(t, hasTrigger(commands))
+}
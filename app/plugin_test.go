@@ -8,6 +8,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/gorilla/mux"
@@ -100,6 +102,186 @@ func TestPrivateServePluginRequest(t *testing.T) {
 
 }
 
+func TestServePluginRequestRequireSession(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	session, appErr := th.App.CreateSession(&model.Session{UserId: th.BasicUser.Id})
+	require.Nil(t, appErr)
+
+	manifest := &model.Manifest{
+		Id: "id",
+		Routes: []*model.ManifestRoute{
+			{Path: "/api/*", RequireSession: true},
+			{Path: "/webhook"},
+		},
+	}
+
+	var userId string
+	var invoked bool
+	handler := sessionRequiredPluginHandler(manifest, func(_ *plugin.Context, w http.ResponseWriter, r *http.Request) {
+		invoked = true
+		userId = r.Header.Get("Mattermost-User-Id")
+	})
+
+	send := func(path, token string, header http.Header) *httptest.ResponseRecorder {
+		invoked = false
+		userId = ""
+		request := httptest.NewRequest(http.MethodGet, "/plugins/id"+path, nil)
+		for name, values := range header {
+			request.Header[name] = values
+		}
+		if token != "" {
+			request.Header.Set(model.HEADER_AUTH, model.HEADER_BEARER+" "+token)
+		}
+		request.Header.Set(model.HEADER_REQUEST_ID, "requestid")
+		recorder := httptest.NewRecorder()
+		th.App.servePluginRequest(recorder, mux.SetURLVars(request, map[string]string{"plugin_id": "id"}), handler)
+		return recorder
+	}
+
+	assertUnauthorized := func(t *testing.T, recorder *httptest.ResponseRecorder) {
+		assert.False(t, invoked)
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		err := model.AppErrorFromJson(recorder.Body)
+		require.NotNil(t, err)
+		assert.Equal(t, "app.plugin.session_required.app_error", err.Id)
+		assert.Equal(t, "requestid", err.RequestId)
+	}
+
+	t.Run("no session", func(t *testing.T) {
+		assertUnauthorized(t, send("/api/settings", "", nil))
+		assertUnauthorized(t, send("/api", "", nil))
+	})
+
+	t.Run("invalid session", func(t *testing.T) {
+		assertUnauthorized(t, send("/api/settings", model.NewId(), nil))
+	})
+
+	t.Run("spoofed user id", func(t *testing.T) {
+		assertUnauthorized(t, send("/api/settings", "", http.Header{"Mattermost-User-Id": []string{th.BasicUser.Id}}))
+	})
+
+	t.Run("valid session", func(t *testing.T) {
+		recorder := send("/api/settings", session.Token, nil)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.True(t, invoked)
+		assert.Equal(t, th.BasicUser.Id, userId)
+	})
+
+	t.Run("public route", func(t *testing.T) {
+		send("/webhook", "", nil)
+		assert.True(t, invoked)
+		assert.Empty(t, userId)
+
+		send("/other", "", nil)
+		assert.True(t, invoked)
+	})
+}
+
+func TestServePluginRequestRateLimit(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	session, appErr := th.App.CreateSession(&model.Session{UserId: th.BasicUser.Id})
+	require.Nil(t, appErr)
+
+	settings := genRateLimitSettings(true, false, "")
+	*settings.MaxBurst = 1
+	*settings.PerSec = 1
+	rateLimiter, err := NewRateLimiter(settings)
+	require.NoError(t, err)
+	th.App.Srv.RateLimiter = rateLimiter
+	defer func() {
+		th.App.Srv.RateLimiter = nil
+	}()
+
+	var invocations int
+	handler := func(_ *plugin.Context, w http.ResponseWriter, r *http.Request) {
+		invocations++
+	}
+
+	send := func(token string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/plugins/id/endpoint", nil)
+		if token != "" {
+			request.Header.Set(model.HEADER_AUTH, model.HEADER_BEARER+" "+token)
+		}
+		recorder := httptest.NewRecorder()
+		th.App.servePluginRequest(recorder, mux.SetURLVars(request, map[string]string{"plugin_id": "id"}), handler)
+		return recorder
+	}
+
+	// Requests with a session are limited by user, like requests to the API.
+	codes := []int{}
+	for i := 0; i < 5; i++ {
+		codes = append(codes, send(session.Token).Code)
+	}
+	assert.Contains(t, codes, http.StatusTooManyRequests)
+	assert.True(t, invocations < 5)
+
+	// Requests without one are left to the server-wide rate limiter.
+	invocations = 0
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, send("").Code)
+	}
+	assert.Equal(t, 5, invocations)
+}
+
+func TestServePluginRequestId(t *testing.T) {
+	th := Setup()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = false
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/plugins/id/endpoint", nil)
+		request.Header.Set(model.HEADER_REQUEST_ID, "not an id")
+		recorder := httptest.NewRecorder()
+
+		th.App.ServePluginRequest(recorder, mux.SetURLVars(request, map[string]string{"plugin_id": "id"}))
+
+		requestId := recorder.Header().Get(model.HEADER_REQUEST_ID)
+		assert.True(t, model.IsValidId(requestId))
+		assert.Equal(t, http.StatusNotImplemented, recorder.Code)
+		assert.Equal(t, requestId, model.AppErrorFromJson(recorder.Body).RequestId)
+	})
+
+	t.Run("adopted", func(t *testing.T) {
+		requestId := model.NewId()
+		request := httptest.NewRequest(http.MethodGet, "/plugins/id/endpoint", nil)
+		request.Header.Set(model.HEADER_REQUEST_ID, requestId)
+		recorder := httptest.NewRecorder()
+
+		th.App.ServePluginRequest(recorder, mux.SetURLVars(request, map[string]string{"plugin_id": "id"}))
+
+		assert.Equal(t, requestId, recorder.Header().Get(model.HEADER_REQUEST_ID))
+		assert.Equal(t, requestId, model.AppErrorFromJson(recorder.Body).RequestId)
+	})
+
+	t.Run("passed to plugin", func(t *testing.T) {
+		requestId := model.NewId()
+		request := httptest.NewRequest(http.MethodGet, "/plugins/id/endpoint", nil)
+		request.Header.Set(model.HEADER_REQUEST_ID, requestId)
+
+		th.App.servePluginRequest(nil, mux.SetURLVars(request, map[string]string{"plugin_id": "id"}), func(c *plugin.Context, _ http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, requestId, c.RequestId)
+			assert.Equal(t, requestId, r.Header.Get(model.HEADER_REQUEST_ID))
+		})
+	})
+
+	t.Run("plugin id passed to plugin", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/plugins/id/endpoint", nil)
+		request.Header.Set(model.HEADER_PLUGIN_ID, "spoofed")
+
+		th.App.servePluginRequest(nil, mux.SetURLVars(request, map[string]string{"plugin_id": "id"}), func(c *plugin.Context, _ http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "id", plugin.IDFromRequest(r))
+			assert.Equal(t, "id", r.Header.Get(model.HEADER_PLUGIN_ID))
+		})
+	})
+}
+
 func TestHandlePluginRequest(t *testing.T) {
 	th := Setup().InitBasic()
 	defer th.TearDown()
@@ -170,4 +352,40 @@ func TestGetPluginStatuses(t *testing.T) {
 	pluginStatuses, err := th.App.GetPluginStatuses()
 	require.Nil(t, err)
 	require.NotNil(t, pluginStatuses)
+
+	t.Run("bundle cleanup", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		// A directory with a corrupt manifest is reported as failing to load.
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "corrupt"), 0700))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "corrupt", "plugin.json"), []byte(`{"id": `), 0600))
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.Directory = dir
+			*cfg.PluginSettings.EnableBundleCleanup = true
+		})
+		th.App.pluginBundleCleanup.Store(&pluginBundleCleanupStats{Removed: 2, LastRunAt: 1234})
+
+		env, err := plugin.NewEnvironment(th.App.NewPluginAPI, dir, dir, th.App.Log)
+		require.NoError(t, err)
+		th.App.Plugins = env
+
+		pluginStatuses, appErr := th.App.GetPluginStatuses()
+		require.Nil(t, appErr)
+		require.NotEmpty(t, pluginStatuses)
+		for _, status := range pluginStatuses {
+			assert.Equal(t, int64(2), status.BundlesRemoved)
+			assert.Equal(t, int64(1234), status.BundleCleanupAt)
+		}
+
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.EnableBundleCleanup = false })
+
+		pluginStatuses, appErr = th.App.GetPluginStatuses()
+		require.Nil(t, appErr)
+		for _, status := range pluginStatuses {
+			assert.Zero(t, status.BundlesRemoved)
+			assert.Zero(t, status.BundleCleanupAt)
+		}
+	})
 }
@@ -0,0 +1,156 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// pendingPluginWebappReply is a message sent to a plugin's webapp component that may still be
+// replied to.
+type pendingPluginWebappReply struct {
+	pluginId string
+	userId   string
+	timer    *time.Timer
+}
+
+// SendPluginWebappMessage sends a message to the plugin's webapp component in the user's connected
+// clients, returning the id with which its webapp component may reply. The plugin's
+// WebappReplyTimedOut hook is invoked if no reply is received within the message's timeout.
+func (a *App) SendPluginWebappMessage(pluginId, userId string, message *model.PluginWebappMessage) (string, *model.AppError) {
+	if err := message.IsValid(); err != nil {
+		return "", err
+	}
+
+	if !a.isUserConnected(userId) {
+		return "", model.NewAppError("SendPluginWebappMessage", "app.plugin.webapp_message.not_connected.app_error", nil, "plugin_id="+pluginId+", user_id="+userId, http.StatusNotFound)
+	}
+
+	replyId := model.NewId()
+	timeout := time.Duration(message.Timeout()) * time.Second
+
+	a.pluginWebappRepliesLock.Lock()
+	if a.pluginWebappReplies == nil {
+		a.pluginWebappReplies = make(map[string]*pendingPluginWebappReply)
+	}
+	a.pluginWebappReplies[replyId] = &pendingPluginWebappReply{
+		pluginId: pluginId,
+		userId:   userId,
+		timer: time.AfterFunc(timeout, func() {
+			a.pluginWebappReplyTimedOut(replyId)
+		}),
+	}
+	a.pluginWebappRepliesLock.Unlock()
+
+	event := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_PLUGIN_WEBAPP_MESSAGE, "", "", userId, nil)
+	event.Add("plugin_id", pluginId)
+	event.Add("reply_id", replyId)
+	event.Add("type", message.Type)
+	event.Add("payload", string(message.Payload))
+	event.Add("expire_at", model.GetMillis()+int64(timeout/time.Millisecond))
+	a.Publish(event)
+
+	return replyId, nil
+}
+
+// isUserConnected returns whether the user has any websocket connections. Connections to other
+// servers of the cluster cannot be checked, so users are then considered connected unless their
+// status is offline.
+func (a *App) isUserConnected(userId string) bool {
+	if hub := a.GetHubForUserId(userId); hub != nil && hub.HasConnectionsForUser(userId) {
+		return true
+	}
+
+	if a.Cluster == nil {
+		return false
+	}
+
+	status, err := a.GetStatus(userId)
+	return err == nil && status.Status != model.STATUS_OFFLINE
+}
+
+// ReceivePluginWebappReply passes on the reply of the plugin's webapp component to a message sent
+// to the given user. Replies to messages sent from other servers of the cluster are passed on to
+// those servers, so they are accepted without knowing whether the message is still pending.
+func (a *App) ReceivePluginWebappReply(pluginId, userId, replyId string, payload []byte) *model.AppError {
+	if len(payload) > model.PLUGIN_WEBAPP_MESSAGE_PAYLOAD_MAX_SIZE {
+		return model.NewAppError("ReceivePluginWebappReply", "app.plugin.webapp_reply.too_large.app_error", map[string]interface{}{"Max": model.PLUGIN_WEBAPP_MESSAGE_PAYLOAD_MAX_SIZE}, "reply_id="+replyId, http.StatusRequestEntityTooLarge)
+	}
+
+	if a.receivePluginWebappReplySkipClusterSend(pluginId, userId, replyId, payload) {
+		return nil
+	}
+
+	if a.Cluster == nil {
+		return model.NewAppError("ReceivePluginWebappReply", "app.plugin.webapp_reply.not_found.app_error", nil, "reply_id="+replyId, http.StatusNotFound)
+	}
+
+	a.Cluster.SendClusterMessage(&model.ClusterMessage{
+		Event:    model.CLUSTER_EVENT_PLUGIN_WEBAPP_REPLY,
+		SendType: model.CLUSTER_SEND_BEST_EFFORT,
+		Props: map[string]string{
+			"plugin_id": pluginId,
+			"user_id":   userId,
+			"reply_id":  replyId,
+			"payload":   base64.StdEncoding.EncodeToString(payload),
+		},
+	})
+
+	return nil
+}
+
+// receivePluginWebappReplySkipClusterSend invokes the plugin's WebappReplyReceived hook if the
+// message was sent from this server to the given user and is still pending, returning whether it was.
+func (a *App) receivePluginWebappReplySkipClusterSend(pluginId, userId, replyId string, payload []byte) bool {
+	a.pluginWebappRepliesLock.Lock()
+	pending, ok := a.pluginWebappReplies[replyId]
+	if !ok || pending.pluginId != pluginId || pending.userId != userId {
+		a.pluginWebappRepliesLock.Unlock()
+		return false
+	}
+	delete(a.pluginWebappReplies, replyId)
+	a.pluginWebappRepliesLock.Unlock()
+
+	pending.timer.Stop()
+
+	a.Go(func() {
+		if !a.PluginsReady() {
+			return
+		}
+
+		hooks, err := a.Plugins.HooksForPlugin(pluginId)
+		if err != nil {
+			mlog.Debug("Unable to pass on plugin webapp reply", mlog.String("plugin_id", pluginId), mlog.Err(err))
+			return
+		}
+
+		hooks.WebappReplyReceived(replyId, payload)
+	})
+
+	return true
+}
+
+func (a *App) pluginWebappReplyTimedOut(replyId string) {
+	a.pluginWebappRepliesLock.Lock()
+	pending, ok := a.pluginWebappReplies[replyId]
+	delete(a.pluginWebappReplies, replyId)
+	a.pluginWebappRepliesLock.Unlock()
+
+	if !ok || !a.PluginsReady() {
+		return
+	}
+
+	hooks, err := a.Plugins.HooksForPlugin(pending.pluginId)
+	if err != nil {
+		mlog.Debug("Unable to notify plugin of webapp reply timeout", mlog.String("plugin_id", pending.pluginId), mlog.Err(err))
+		return
+	}
+
+	hooks.WebappReplyTimedOut(replyId)
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+// notifyPluginsOfWebSocketConnect invokes the OnWebSocketConnect hook for a newly registered
+// connection. It is called from the hub goroutine, so it must not block.
+func (a *App) notifyPluginsOfWebSocketConnect(webCon *WebConn) {
+	if !a.PluginsReady() || !a.Plugins.IsHookImplemented(plugin.OnWebSocketConnectId) {
+		return
+	}
+
+	connectionId, userId := webCon.Id, webCon.UserId
+	a.Plugins.RunMultiPluginHookAsync(func(hooks plugin.Hooks) {
+		hooks.OnWebSocketConnect(connectionId, userId)
+	}, plugin.OnWebSocketConnectId)
+}
+
+// notifyPluginsOfWebSocketDisconnect invokes the OnWebSocketDisconnect hook for a connection
+// removed from the hub. It is called from the hub goroutine, so it must not block.
+func (a *App) notifyPluginsOfWebSocketDisconnect(webCon *WebConn) {
+	if !a.PluginsReady() || !a.Plugins.IsHookImplemented(plugin.OnWebSocketDisconnectId) {
+		return
+	}
+
+	connectionId, userId := webCon.Id, webCon.UserId
+	a.Plugins.RunMultiPluginHookAsync(func(hooks plugin.Hooks) {
+		hooks.OnWebSocketDisconnect(connectionId, userId)
+	}, plugin.OnWebSocketDisconnectId)
+}
@@ -109,6 +109,10 @@ func (a *App) CreatePostMissingChannel(post *model.Post, triggerWebhooks bool) (
 }
 
 func (a *App) CreatePost(post *model.Post, channel *model.Channel, triggerWebhooks bool) (*model.Post, *model.AppError) {
+	return a.createPost(post, channel, triggerWebhooks, true)
+}
+
+func (a *App) createPost(post *model.Post, channel *model.Channel, triggerWebhooks bool, notifyUsers bool) (*model.Post, *model.AppError) {
 	post.SanitizeProps()
 
 	var pchan store.StoreChannel
@@ -163,7 +167,7 @@ func (a *App) CreatePost(post *model.Post, channel *model.Channel, triggerWebhoo
 
 	if a.PluginsReady() {
 		var rejectionReason string
-		pluginContext := &plugin.Context{}
+		pluginContext := newPluginContext()
 		a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
 			post, rejectionReason = hooks.MessageWillBePosted(pluginContext, post)
 			return post != nil
@@ -181,13 +185,10 @@ func (a *App) CreatePost(post *model.Post, channel *model.Channel, triggerWebhoo
 	}
 
 	if a.PluginsReady() {
-		a.Go(func() {
-			pluginContext := &plugin.Context{}
-			a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
-				hooks.MessageHasBeenPosted(pluginContext, rpost)
-				return true
-			}, plugin.MessageHasBeenPostedId)
-		})
+		pluginContext := newPluginContext()
+		a.Plugins.RunMultiPluginHookAsync(func(hooks plugin.Hooks) {
+			hooks.MessageHasBeenPosted(pluginContext, rpost)
+		}, plugin.MessageHasBeenPostedId)
 	}
 
 	esInterface := a.Elasticsearch
@@ -216,7 +217,7 @@ func (a *App) CreatePost(post *model.Post, channel *model.Channel, triggerWebhoo
 		}
 	}
 
-	if err := a.handlePostEvents(rpost, user, channel, triggerWebhooks, parentPostList); err != nil {
+	if err := a.handlePostEvents(rpost, user, channel, triggerWebhooks, notifyUsers, parentPostList); err != nil {
 		return nil, err
 	}
 
@@ -263,7 +264,7 @@ func (a *App) FillInPostProps(post *model.Post, channel *model.Channel) *model.A
 	return nil
 }
 
-func (a *App) handlePostEvents(post *model.Post, user *model.User, channel *model.Channel, triggerWebhooks bool, parentPostList *model.PostList) *model.AppError {
+func (a *App) handlePostEvents(post *model.Post, user *model.User, channel *model.Channel, triggerWebhooks bool, notifyUsers bool, parentPostList *model.PostList) *model.AppError {
 	var tchan store.StoreChannel
 	if len(channel.TeamId) > 0 {
 		tchan = a.Srv.Store.Team().Get(channel.TeamId)
@@ -284,7 +285,7 @@ func (a *App) handlePostEvents(post *model.Post, user *model.User, channel *mode
 	a.InvalidateCacheForChannel(channel)
 	a.InvalidateCacheForChannelPosts(channel.Id)
 
-	if _, err := a.SendNotifications(post, team, channel, user, parentPostList); err != nil {
+	if _, err := a.sendNotifications(post, team, channel, user, parentPostList, notifyUsers); err != nil {
 		return err
 	}
 
@@ -388,6 +389,12 @@ func (a *App) UpdatePost(post *model.Post, safeUpdate bool) (*model.Post, *model
 		newPost.HasReactions = post.HasReactions
 		newPost.FileIds = post.FileIds
 		newPost.Props = post.Props
+
+		// Metadata attached by plugins is only changed through AddPostMetadata.
+		delete(newPost.Props, model.POST_PROPS_PLUGIN_METADATA)
+		if metadata, ok := oldPost.Props[model.POST_PROPS_PLUGIN_METADATA]; ok {
+			newPost.AddProp(model.POST_PROPS_PLUGIN_METADATA, metadata)
+		}
 	}
 
 	if err := a.FillInPostProps(post, nil); err != nil {
@@ -396,7 +403,7 @@ func (a *App) UpdatePost(post *model.Post, safeUpdate bool) (*model.Post, *model
 
 	if a.PluginsReady() {
 		var rejectionReason string
-		pluginContext := &plugin.Context{}
+		pluginContext := newPluginContext()
 		a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
 			newPost, rejectionReason = hooks.MessageWillBeUpdated(pluginContext, newPost, oldPost)
 			return post != nil
@@ -412,13 +419,10 @@ func (a *App) UpdatePost(post *model.Post, safeUpdate bool) (*model.Post, *model
 		rpost := result.Data.(*model.Post)
 
 		if a.PluginsReady() {
-			a.Go(func() {
-				pluginContext := &plugin.Context{}
-				a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
-					hooks.MessageHasBeenUpdated(pluginContext, newPost, oldPost)
-					return true
-				}, plugin.MessageHasBeenUpdatedId)
-			})
+			pluginContext := newPluginContext()
+			a.Plugins.RunMultiPluginHookAsync(func(hooks plugin.Hooks) {
+				hooks.MessageHasBeenUpdated(pluginContext, newPost, oldPost)
+			}, plugin.MessageHasBeenUpdatedId)
 		}
 
 		esInterface := a.Elasticsearch
@@ -427,7 +431,7 @@ func (a *App) UpdatePost(post *model.Post, safeUpdate bool) (*model.Post, *model
 				if rchannel := <-a.Srv.Store.Channel().GetForPost(rpost.Id); rchannel.Err != nil {
 					mlog.Error(fmt.Sprintf("Couldn't get channel %v for post %v for Elasticsearch indexing.", rpost.ChannelId, rpost.Id))
 				} else {
-					esInterface.IndexPost(rpost, rchannel.Data.(*model.Channel).TeamId)
+					esInterface.IndexPost(postWithoutPluginMetadata(rpost), rchannel.Data.(*model.Channel).TeamId)
 				}
 			})
 		}
@@ -607,6 +611,9 @@ func (a *App) DeletePost(postId, deleteByID string) (*model.Post, *model.AppErro
 		a.Go(func() {
 			a.DeleteFlaggedPosts(post.Id)
 		})
+		a.Go(func() {
+			a.DeletePostAcknowledgements(post.Id)
+		})
 
 		esInterface := a.Elasticsearch
 		if esInterface != nil && *a.Config().ElasticsearchSettings.EnableIndexing {
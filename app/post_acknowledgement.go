@@ -0,0 +1,82 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+// RequestPostAcknowledgement flags the given post so that clients let users acknowledge it.
+func (a *App) RequestPostAcknowledgement(postId string) (*model.Post, *model.AppError) {
+	post, err := a.GetSinglePost(postId)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.Props[model.POST_PROPS_REQUESTED_ACK] == true {
+		return post, nil
+	}
+
+	post.AddProp(model.POST_PROPS_REQUESTED_ACK, true)
+
+	return a.UpdatePost(post, false)
+}
+
+// AcknowledgePost records that the given user has acknowledged a post for which acknowledgements
+// were requested. Acknowledging a post again returns the first acknowledgement.
+func (a *App) AcknowledgePost(userId, postId string) (*model.PostAcknowledgement, *model.AppError) {
+	post, err := a.GetSinglePost(postId)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.Props[model.POST_PROPS_REQUESTED_ACK] != true {
+		return nil, model.NewAppError("AcknowledgePost", "app.post_acknowledgement.not_requested.app_error", nil, "post_id="+postId, http.StatusBadRequest)
+	}
+
+	if result := <-a.Srv.Store.PostAcknowledgement().Get(postId, userId); result.Err == nil {
+		return result.Data.(*model.PostAcknowledgement), nil
+	} else if result.Err.StatusCode != http.StatusNotFound {
+		return nil, result.Err
+	}
+
+	result := <-a.Srv.Store.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: postId, UserId: userId})
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	acknowledgement := result.Data.(*model.PostAcknowledgement)
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POST_ACKNOWLEDGED, "", post.ChannelId, "", nil)
+	message.Add("acknowledgement", acknowledgement.ToJson())
+	a.Publish(message)
+
+	if a.PluginsReady() {
+		pluginContext := newPluginContext()
+		a.Plugins.RunMultiPluginHookAsync(func(hooks plugin.Hooks) {
+			hooks.PostHasBeenAcknowledged(pluginContext, postId, userId)
+		}, plugin.PostHasBeenAcknowledgedId)
+	}
+
+	return acknowledgement, nil
+}
+
+func (a *App) GetPostAcknowledgements(postId string) ([]*model.PostAcknowledgement, *model.AppError) {
+	result := <-a.Srv.Store.PostAcknowledgement().GetForPost(postId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	return result.Data.([]*model.PostAcknowledgement), nil
+}
+
+func (a *App) DeletePostAcknowledgements(postId string) {
+	if result := <-a.Srv.Store.PostAcknowledgement().DeleteAllForPost(postId); result.Err != nil {
+		mlog.Warn(fmt.Sprintf("Unable to delete acknowledgements when deleting post, err=%v", result.Err), mlog.String("post_id", postId))
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestAcknowledgePost(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	post := th.CreatePost(th.BasicChannel)
+
+	_, err := th.App.AcknowledgePost(th.BasicUser.Id, post.Id)
+	require.NotNil(t, err, "should not be able to acknowledge a post for which acknowledgements were not requested")
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+
+	post, err = th.App.RequestPostAcknowledgement(post.Id)
+	require.Nil(t, err)
+	assert.Equal(t, true, post.Props[model.POST_PROPS_REQUESTED_ACK])
+
+	acknowledgement, err := th.App.AcknowledgePost(th.BasicUser.Id, post.Id)
+	require.Nil(t, err)
+	assert.Equal(t, post.Id, acknowledgement.PostId)
+	assert.Equal(t, th.BasicUser.Id, acknowledgement.UserId)
+
+	again, err := th.App.AcknowledgePost(th.BasicUser.Id, post.Id)
+	require.Nil(t, err)
+	assert.Equal(t, acknowledgement, again, "acknowledging a post again should return the first acknowledgement")
+
+	other, err := th.App.AcknowledgePost(th.BasicUser2.Id, post.Id)
+	require.Nil(t, err)
+
+	acknowledgements, err := th.App.GetPostAcknowledgements(post.Id)
+	require.Nil(t, err)
+	assert.Equal(t, []*model.PostAcknowledgement{acknowledgement, other}, acknowledgements)
+
+	_, err = th.App.DeletePost(post.Id, th.BasicUser.Id)
+	require.Nil(t, err)
+
+	// Acknowledgements are deleted asynchronously.
+	for i := 0; i < 20 && len(acknowledgements) > 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+		acknowledgements, err = th.App.GetPostAcknowledgements(post.Id)
+		require.Nil(t, err)
+	}
+	assert.Empty(t, acknowledgements)
+}
@@ -89,6 +89,17 @@ func (rl *RateLimiter) RateLimitWriter(key string, w http.ResponseWriter) bool {
 	return limited
 }
 
+// IsLimited consumes one request for the given key, reporting whether the key is over its limit.
+func (rl *RateLimiter) IsLimited(key string) bool {
+	limited, _, err := rl.throttledRateLimiter.RateLimit(key, 1)
+	if err != nil {
+		mlog.Critical("Internal server error when rate limiting. Rate Limiting broken. Error:" + err.Error())
+		return false
+	}
+
+	return limited
+}
+
 func (rl *RateLimiter) UserIdRateLimit(userId string, w http.ResponseWriter) bool {
 	if rl.useAuth {
 		if rl.RateLimitWriter(userId, w) {
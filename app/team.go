@@ -443,13 +443,10 @@ func (a *App) JoinUserToTeam(team *model.Team, user *model.User, userRequestorId
 			actor, err = a.GetUser(userRequestorId)
 		}
 
-		a.Go(func() {
-			pluginContext := &plugin.Context{}
-			a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
-				hooks.UserHasJoinedTeam(pluginContext, tm, actor)
-				return true
-			}, plugin.UserHasJoinedTeamId)
-		})
+		pluginContext := newPluginContext()
+		a.Plugins.RunMultiPluginHookAsync(func(hooks plugin.Hooks) {
+			hooks.UserHasJoinedTeam(pluginContext, tm, actor)
+		}, plugin.UserHasJoinedTeamId)
 	}
 
 	if uua := <-a.Srv.Store.User().UpdateUpdateAt(user.Id); uua.Err != nil {
@@ -579,6 +576,16 @@ func (a *App) GetTeamMembers(teamId string, offset int, limit int) ([]*model.Tea
 	}
 }
 
+// GetTeamMembersModifiedSince returns the members of the team whose membership was created or updated
+// after the given time. Members that have left the team are included, with DeleteAt set.
+func (a *App) GetTeamMembersModifiedSince(teamId string, since int64) ([]*model.TeamMember, *model.AppError) {
+	if result := <-a.Srv.Store.Team().GetMembersModifiedSince(teamId, since); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.TeamMember), nil
+	}
+}
+
 func (a *App) GetTeamMembersByIds(teamId string, userIds []string) ([]*model.TeamMember, *model.AppError) {
 	if result := <-a.Srv.Store.Team().GetMembersByIds(teamId, userIds); result.Err != nil {
 		return nil, result.Err
@@ -773,13 +780,10 @@ func (a *App) LeaveTeam(team *model.Team, user *model.User, requestorId string)
 			actor, err = a.GetUser(requestorId)
 		}
 
-		a.Go(func() {
-			pluginContext := &plugin.Context{}
-			a.Plugins.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
-				hooks.UserHasLeftTeam(pluginContext, teamMember, actor)
-				return true
-			}, plugin.UserHasLeftTeamId)
-		})
+		pluginContext := newPluginContext()
+		a.Plugins.RunMultiPluginHookAsync(func(hooks plugin.Hooks) {
+			hooks.UserHasLeftTeam(pluginContext, teamMember, actor)
+		}, plugin.UserHasLeftTeamId)
 	}
 
 	if uua := <-a.Srv.Store.User().UpdateUpdateAt(user.Id); uua.Err != nil {
@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateTeam(t *testing.T) {
@@ -577,3 +579,31 @@ func TestAppUpdateTeamScheme(t *testing.T) {
 		t.Fatal("Wrong Team SchemeId")
 	}
 }
+
+func TestGetTeamMembersModifiedSince(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	team := th.CreateTeam()
+	user := th.CreateUser()
+
+	since := model.GetMillis() - 1
+	th.LinkUserToTeam(user, team)
+
+	members, err := th.App.GetTeamMembersModifiedSince(team.Id, since)
+	require.Nil(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, user.Id, members[0].UserId)
+	assert.Zero(t, members[0].DeleteAt)
+
+	require.Nil(t, th.App.RemoveUserFromTeam(team.Id, user.Id, th.BasicUser.Id))
+
+	members, err = th.App.GetTeamMembersModifiedSince(team.Id, since)
+	require.Nil(t, err)
+	require.Len(t, members, 1)
+	assert.NotZero(t, members[0].DeleteAt)
+
+	members, err = th.App.GetTeamMembersModifiedSince(team.Id, members[0].UpdateAt)
+	require.Nil(t, err)
+	assert.Empty(t, members)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// FollowThread subscribes the user to notifications for every reply to the thread containing the
+// given post, as if they had replied to it with comment notifications set to "any". The user must
+// be a member of the thread's channel.
+func (a *App) FollowThread(userId, postId string) *model.AppError {
+	post, err := a.GetSinglePost(postId)
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.GetChannelMember(post.ChannelId, userId); err != nil {
+		return model.NewAppError("FollowThread", "app.thread.follow.not_member.app_error", nil, "user_id="+userId+", channel_id="+post.ChannelId, http.StatusForbidden)
+	}
+
+	return a.UpdatePreferences(userId, model.Preferences{{
+		UserId:   userId,
+		Category: model.PREFERENCE_CATEGORY_FOLLOWED_THREAD,
+		Name:     threadRootId(post),
+		Value:    "true",
+	}})
+}
+
+// UnfollowThread removes the user's subscription to the thread containing the given post. The
+// user still receives notifications according to their comment settings if they took part in it.
+func (a *App) UnfollowThread(userId, postId string) *model.AppError {
+	post, err := a.GetSinglePost(postId)
+	if err != nil {
+		return err
+	}
+
+	return a.DeletePreferences(userId, model.Preferences{{
+		UserId:   userId,
+		Category: model.PREFERENCE_CATEGORY_FOLLOWED_THREAD,
+		Name:     threadRootId(post),
+	}})
+}
+
+func threadRootId(post *model.Post) string {
+	if post.RootId != "" {
+		return post.RootId
+	}
+	return post.Id
+}
+
+// getThreadFollowerIds returns the ids of the users following the thread with the given root post.
+func (a *App) getThreadFollowerIds(rootId string) ([]string, *model.AppError) {
+	result := <-a.Srv.Store.Preference().GetCategoryAndName(model.PREFERENCE_CATEGORY_FOLLOWED_THREAD, rootId)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	preferences := result.Data.(model.Preferences)
+	userIds := make([]string, 0, len(preferences))
+	for _, preference := range preferences {
+		if preference.Value == "true" {
+			userIds = append(userIds, preference.UserId)
+		}
+	}
+
+	return userIds, nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestFollowThread(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	th.AddUserToChannel(th.BasicUser2, th.BasicChannel)
+
+	rootPost := th.BasicPost
+	reply, err := th.App.CreatePost(&model.Post{
+		UserId:    th.BasicUser.Id,
+		ChannelId: th.BasicChannel.Id,
+		RootId:    rootPost.Id,
+		Message:   "reply",
+	}, th.BasicChannel, false)
+	require.Nil(t, err)
+
+	// Following a reply follows the whole thread
+	require.Nil(t, th.App.FollowThread(th.BasicUser2.Id, reply.Id))
+
+	followerIds, err := th.App.getThreadFollowerIds(rootPost.Id)
+	require.Nil(t, err)
+	assert.Equal(t, []string{th.BasicUser2.Id}, followerIds)
+
+	require.Nil(t, th.App.UnfollowThread(th.BasicUser2.Id, rootPost.Id))
+
+	followerIds, err = th.App.getThreadFollowerIds(rootPost.Id)
+	require.Nil(t, err)
+	assert.Empty(t, followerIds)
+
+	t.Run("not a channel member", func(t *testing.T) {
+		user := th.CreateUser()
+
+		err := th.App.FollowThread(user.Id, rootPost.Id)
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusForbidden, err.StatusCode)
+	})
+
+	t.Run("missing post", func(t *testing.T) {
+		require.NotNil(t, th.App.FollowThread(th.BasicUser.Id, "junk"))
+	})
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/mattermost/mattermost-server/einterfaces"
 	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
 	"github.com/mattermost/mattermost-server/store"
 	"github.com/mattermost/mattermost-server/utils"
 )
@@ -216,6 +217,13 @@ func (a *App) CreateUser(user *model.User) (*model.User, *model.AppError) {
 		message.Add("user_id", ruser.Id)
 		a.Publish(message)
 
+		if a.PluginsReady() {
+			pluginContext := newPluginContext()
+			a.Plugins.RunMultiPluginHookAsync(func(hooks plugin.Hooks) {
+				hooks.UserHasBeenCreated(pluginContext, ruser)
+			}, plugin.UserHasBeenCreatedId)
+		}
+
 		return ruser, nil
 	}
 }
@@ -887,6 +895,11 @@ func (a *App) UpdateActive(user *model.User, active bool) (*model.User, *model.A
 			if err := a.RevokeAllSessions(user.Id); err != nil {
 				return nil, err
 			}
+
+			// Plugins may no longer act on behalf of a deactivated user in external services.
+			if result := <-a.Srv.Store.PluginOAuthConnection().DeleteAllForUser(user.Id); result.Err != nil {
+				return nil, result.Err
+			}
 		}
 
 		ruser := result.Data.([2]*model.User)[0]
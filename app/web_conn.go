@@ -29,6 +29,7 @@ const (
 type WebConn struct {
 	sessionExpiresAt          int64 // This should stay at the top for 64-bit alignment of 64-bit words accessed atomically
 	App                       *App
+	Id                        string // Identifies the connection for its lifetime; not persisted across restarts
 	WebSocket                 *websocket.Conn
 	Send                      chan model.WebSocketMessage
 	sessionToken              atomic.Value
@@ -54,6 +55,7 @@ func (a *App) NewWebConn(ws *websocket.Conn, session model.Session, t goi18n.Tra
 
 	wc := &WebConn{
 		App:                a,
+		Id:                 model.NewId(),
 		Send:               make(chan model.WebSocketMessage, SEND_QUEUE_SIZE),
 		WebSocket:          ws,
 		LastUserActivityAt: model.GetMillis(),
@@ -29,6 +29,16 @@ type WebConnActivityMessage struct {
 	ActivityAt   int64
 }
 
+type webConnCloseMessage struct {
+	UserId       string
+	SessionToken string
+}
+
+type webConnCheckMessage struct {
+	UserId string
+	Result chan bool
+}
+
 type Hub struct {
 	// connectionCount should be kept first.
 	// See https://github.com/mattermost/mattermost-server/pull/7281
@@ -41,6 +51,8 @@ type Hub struct {
 	stop            chan struct{}
 	didStop         chan struct{}
 	invalidateUser  chan string
+	closeUser       chan *webConnCloseMessage
+	checkUser       chan *webConnCheckMessage
 	activity        chan *WebConnActivityMessage
 	ExplicitStop    bool
 	goroutineId     int
@@ -55,6 +67,8 @@ func (a *App) NewWebHub() *Hub {
 		stop:           make(chan struct{}),
 		didStop:        make(chan struct{}),
 		invalidateUser: make(chan string),
+		closeUser:      make(chan *webConnCloseMessage),
+		checkUser:      make(chan *webConnCheckMessage),
 		activity:       make(chan *WebConnActivityMessage),
 		ExplicitStop:   false,
 	}
@@ -338,6 +352,30 @@ func (a *App) InvalidateWebConnSessionCacheForUser(userId string) {
 	}
 }
 
+// CloseWebConnsForUser closes the user's websocket connections on every server of the cluster, or only
+// those authenticated with the given session token if it is not empty. Clients are expected to
+// reconnect, so this is only useful once the sessions they used have been revoked.
+func (a *App) CloseWebConnsForUser(userId, sessionToken string) {
+	a.CloseWebConnsForUserSkipClusterSend(userId, sessionToken)
+
+	if a.Cluster != nil {
+		msg := &model.ClusterMessage{
+			Event:    model.CLUSTER_EVENT_CLOSE_WEB_CONNS_FOR_USER,
+			SendType: model.CLUSTER_SEND_RELIABLE,
+			Data:     userId,
+			Props:    map[string]string{"session_token": sessionToken},
+		}
+		a.Cluster.SendClusterMessage(msg)
+	}
+}
+
+func (a *App) CloseWebConnsForUserSkipClusterSend(userId, sessionToken string) {
+	hub := a.GetHubForUserId(userId)
+	if hub != nil {
+		hub.CloseUser(userId, sessionToken)
+	}
+}
+
 func (a *App) UpdateWebConnUserActivity(session model.Session, activityAt int64) {
 	hub := a.GetHubForUserId(session.UserId)
 	if hub != nil {
@@ -370,6 +408,21 @@ func (h *Hub) InvalidateUser(userId string) {
 	h.invalidateUser <- userId
 }
 
+func (h *Hub) CloseUser(userId, sessionToken string) {
+	h.closeUser <- &webConnCloseMessage{UserId: userId, SessionToken: sessionToken}
+}
+
+// HasConnectionsForUser returns whether the user has any websocket connections to the hub.
+func (h *Hub) HasConnectionsForUser(userId string) bool {
+	result := make(chan bool, 1)
+	select {
+	case h.checkUser <- &webConnCheckMessage{UserId: userId, Result: result}:
+		return <-result
+	case <-h.stop:
+		return false
+	}
+}
+
 func (h *Hub) UpdateActivity(userId, sessionToken string, activityAt int64) {
 	h.activity <- &WebConnActivityMessage{UserId: userId, SessionToken: sessionToken, ActivityAt: activityAt}
 }
@@ -406,8 +459,12 @@ func (h *Hub) Start() {
 			case webCon := <-h.register:
 				connections.Add(webCon)
 				atomic.StoreInt64(&h.connectionCount, int64(len(connections.All())))
+				h.app.notifyPluginsOfWebSocketConnect(webCon)
 			case webCon := <-h.unregister:
-				connections.Remove(webCon)
+				if connections.Has(webCon) {
+					connections.Remove(webCon)
+					h.app.notifyPluginsOfWebSocketDisconnect(webCon)
+				}
 				atomic.StoreInt64(&h.connectionCount, int64(len(connections.All())))
 
 				if len(webCon.UserId) == 0 {
@@ -436,6 +493,15 @@ func (h *Hub) Start() {
 				for _, webCon := range connections.ForUser(userId) {
 					webCon.InvalidateCache()
 				}
+			case msg := <-h.closeUser:
+				for _, webCon := range connections.ForUser(msg.UserId) {
+					if msg.SessionToken == "" || webCon.GetSessionToken() == msg.SessionToken {
+						// The connection is unregistered once its pumps have stopped.
+						webCon.WebSocket.Close()
+					}
+				}
+			case msg := <-h.checkUser:
+				msg.Result <- len(connections.ForUser(msg.UserId)) > 0
 			case activity := <-h.activity:
 				for _, webCon := range connections.ForUser(activity.UserId) {
 					if webCon.GetSessionToken() == activity.SessionToken {
@@ -444,7 +510,9 @@ func (h *Hub) Start() {
 				}
 			case msg := <-h.broadcast:
 				candidates := connections.All()
-				if msg.Broadcast.UserId != "" {
+				if msg.Broadcast.ConnectionId != "" {
+					candidates = connections.ForConnection(msg.Broadcast.ConnectionId)
+				} else if msg.Broadcast.UserId != "" {
 					candidates = connections.ForUser(msg.Broadcast.UserId)
 				}
 				msg.PrecomputeJSON()
@@ -456,6 +524,7 @@ func (h *Hub) Start() {
 							mlog.Error(fmt.Sprintf("webhub.broadcast: cannot send, closing websocket for userId=%v", webCon.UserId))
 							close(webCon.Send)
 							connections.Remove(webCon)
+							h.app.notifyPluginsOfWebSocketDisconnect(webCon)
 						}
 					}
 				}
@@ -510,6 +579,7 @@ type hubConnectionIndexIndexes struct {
 type hubConnectionIndex struct {
 	connections         []*WebConn
 	connectionsByUserId map[string][]*WebConn
+	connectionsById     map[string]*WebConn
 	connectionIndexes   map[*WebConn]*hubConnectionIndexIndexes
 }
 
@@ -517,6 +587,7 @@ func newHubConnectionIndex() *hubConnectionIndex {
 	return &hubConnectionIndex{
 		connections:         make([]*WebConn, 0, model.SESSION_CACHE_SIZE),
 		connectionsByUserId: make(map[string][]*WebConn),
+		connectionsById:     make(map[string]*WebConn),
 		connectionIndexes:   make(map[*WebConn]*hubConnectionIndexIndexes),
 	}
 }
@@ -524,6 +595,7 @@ func newHubConnectionIndex() *hubConnectionIndex {
 func (i *hubConnectionIndex) Add(wc *WebConn) {
 	i.connections = append(i.connections, wc)
 	i.connectionsByUserId[wc.UserId] = append(i.connectionsByUserId[wc.UserId], wc)
+	i.connectionsById[wc.Id] = wc
 	i.connectionIndexes[wc] = &hubConnectionIndexIndexes{
 		connections:         len(i.connections) - 1,
 		connectionsByUserId: len(i.connectionsByUserId[wc.UserId]) - 1,
@@ -547,13 +619,26 @@ func (i *hubConnectionIndex) Remove(wc *WebConn) {
 	i.connectionsByUserId[wc.UserId] = userConnections[:len(userConnections)-1]
 	i.connectionIndexes[last].connectionsByUserId = indexes.connectionsByUserId
 
+	delete(i.connectionsById, wc.Id)
 	delete(i.connectionIndexes, wc)
 }
 
+func (i *hubConnectionIndex) Has(wc *WebConn) bool {
+	_, ok := i.connectionIndexes[wc]
+	return ok
+}
+
 func (i *hubConnectionIndex) ForUser(id string) []*WebConn {
 	return i.connectionsByUserId[id]
 }
 
+func (i *hubConnectionIndex) ForConnection(id string) []*WebConn {
+	if wc, ok := i.connectionsById[id]; ok {
+		return []*WebConn{wc}
+	}
+	return nil
+}
+
 func (i *hubConnectionIndex) All() []*WebConn {
 	return i.connections
 }
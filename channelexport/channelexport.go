@@ -0,0 +1,19 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package channelexport
+
+import (
+	"github.com/mattermost/mattermost-server/app"
+	tjobs "github.com/mattermost/mattermost-server/jobs/interfaces"
+)
+
+type ChannelExportJobInterfaceImpl struct {
+	App *app.App
+}
+
+func init() {
+	app.RegisterJobsChannelExportJobInterface(func(a *app.App) tjobs.ChannelExportJobInterface {
+		return &ChannelExportJobInterfaceImpl{a}
+	})
+}
@@ -37,6 +37,7 @@ func init() {
 	BulkImportCmd.Flags().Bool("apply", false, "Save the import data to the database. Use with caution - this cannot be reverted.")
 	BulkImportCmd.Flags().Bool("validate", false, "Validate the import data without making any changes to the system.")
 	BulkImportCmd.Flags().Int("workers", 2, "How many workers to run whilst doing the import.")
+	BulkImportCmd.Flags().Bool("skip-plugin-data", false, "Skip the key-value data of plugins held in the data file.")
 
 	ImportCmd.AddCommand(
 		BulkImportCmd,
@@ -112,6 +113,11 @@ func bulkImportCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Workers flag error")
 	}
 
+	skipPluginData, err := command.Flags().GetBool("skip-plugin-data")
+	if err != nil {
+		return errors.New("Skip plugin data flag error")
+	}
+
 	if len(args) != 1 {
 		return errors.New("Incorrect number of arguments.")
 	}
@@ -137,7 +143,7 @@ func bulkImportCmdF(command *cobra.Command, args []string) error {
 
 	CommandPrettyPrintln("")
 
-	if err, lineNumber := a.BulkImport(fileReader, !apply, workers); err != nil {
+	if err, lineNumber := a.BulkImport(fileReader, !apply, workers, skipPluginData); err != nil {
 		CommandPrettyPrintln(err.Error())
 		if lineNumber != 0 {
 			CommandPrettyPrintln(fmt.Sprintf("Error occurred on data file line %v", lineNumber))
@@ -5,6 +5,7 @@ package commands
 
 import (
 	"errors"
+	"os"
 
 	"context"
 
@@ -52,6 +53,14 @@ var GlobalRelayExportCmd = &cobra.Command{
 	RunE:    buildExportCmdF("globalrelay"),
 }
 
+var PluginDataExportCmd = &cobra.Command{
+	Use:     "plugin-data [file]",
+	Short:   "Export the key-value data of plugins",
+	Long:    "Export the key-value data of all plugins to a Mattermost Bulk Import File, which restores it when imported with \"import bulk\".",
+	Example: "export plugin-data plugin_data.jsonl",
+	RunE:    pluginDataExportCmdF,
+}
+
 func init() {
 	ScheduleExportCmd.Flags().String("format", "actiance", "The format to export data")
 	ScheduleExportCmd.Flags().Int64("exportFrom", -1, "The timestamp of the earliest post to export, expressed in seconds since the unix epoch.")
@@ -63,6 +72,7 @@ func init() {
 	MessageExportCmd.AddCommand(CsvExportCmd)
 	MessageExportCmd.AddCommand(ActianceExportCmd)
 	MessageExportCmd.AddCommand(GlobalRelayExportCmd)
+	MessageExportCmd.AddCommand(PluginDataExportCmd)
 	RootCmd.AddCommand(MessageExportCmd)
 }
 
@@ -150,3 +160,28 @@ func buildExportCmdF(format string) func(command *cobra.Command, args []string)
 		return nil
 	}
 }
+
+func pluginDataExportCmdF(command *cobra.Command, args []string) error {
+	a, err := InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown()
+
+	if len(args) != 1 {
+		return errors.New("Incorrect number of arguments.")
+	}
+
+	file, err := os.Create(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := a.BulkExportPluginData(file); err != nil {
+		return err
+	}
+	CommandPrettyPrintln("SUCCESS: Plugin data was exported.")
+
+	return nil
+}
@@ -5,11 +5,23 @@ package commands
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/migrations"
+	"github.com/mattermost/mattermost-server/model"
 	"github.com/spf13/cobra"
 )
 
+// PLUGIN_EVENTS_PAGE_SIZE is how many events plugin events gets at a time.
+const PLUGIN_EVENTS_PAGE_SIZE = 200
+
 var PluginCmd = &cobra.Command{
 	Use:   "plugin",
 	Short: "Management of plugins",
@@ -18,8 +30,8 @@ var PluginCmd = &cobra.Command{
 var PluginAddCmd = &cobra.Command{
 	Use:     "add [plugins]",
 	Short:   "Add plugins",
-	Long:    "Add plugins to your Mattermost server.",
-	Example: `  plugin add hovercardexample.tar.gz pluginexample.tar.gz`,
+	Long:    "Add plugins to your Mattermost server. A detached signature found next to a plugin, named after it with a .sig extension, is verified and installed along with it.",
+	Example: `  plugin add hovercardexample.tar.gz pluginexample.zip`,
 	RunE:    pluginAddCmdF,
 }
 
@@ -55,13 +67,79 @@ var PluginListCmd = &cobra.Command{
 	RunE:    pluginListCmdF,
 }
 
+var PluginValidateCmd = &cobra.Command{
+	Use:     "validate [plugins]",
+	Short:   "Validate plugins",
+	Long:    "Check whether plugin bundles would be accepted by your Mattermost server, without installing them.",
+	Example: `  plugin validate hovercardexample.tar.gz pluginexample.tar.gz`,
+	RunE:    pluginValidateCmdF,
+}
+
+var PluginScaffoldCmd = &cobra.Command{
+	Use:   "scaffold [plugin id]",
+	Short: "Generate a new plugin",
+	Long: `Generate the skeleton of a new plugin in a new directory: its manifest, a server component implementing the given hooks against this server's plugin package, and a Makefile building its bundle.
+The hooks are named as in the plugin package, such as MessageWillBePosted.`,
+	Example: `  plugin scaffold com.example.myplugin --hook OnActivate,MessageWillBePosted --capability posts:write`,
+	RunE:    pluginScaffoldCmdF,
+}
+
+var PluginEventsCmd = &cobra.Command{
+	Use:   "events [plugin id]",
+	Short: "Show the events of a plugin",
+	Long: `Show the events recorded for a plugin, oldest first, such as its installation, activation, failures to start and changes to its settings, along with the server of the cluster each happened on.
+Use --since to only show the events recorded after a time, given as a date or as a duration before now.`,
+	Example: `  plugin events com.example.myplugin
+  plugin events com.example.myplugin --since 72h
+  plugin events com.example.myplugin --since 2018-06-01`,
+	RunE: pluginEventsCmdF,
+}
+
+var PluginKVMigrationCmd = &cobra.Command{
+	Use:   "kv-migration",
+	Short: "Management of the plugin key-value migration",
+	Long:  "Check on and complete the migration of plugin keys stored as their hash to where they are stored as given.",
+}
+
+var PluginKVMigrationStatusCmd = &cobra.Command{
+	Use:     "status",
+	Short:   "Show the progress of the plugin key-value migration",
+	Long:    "Show how many plugin keys are still stored as their hash, and whether the migration has completed.",
+	Example: `  plugin kv-migration status`,
+	RunE:    pluginKVMigrationStatusCmdF,
+}
+
+var PluginKVMigrationCompleteCmd = &cobra.Command{
+	Use:   "complete",
+	Short: "Complete the plugin key-value migration",
+	Long: `Migrate the plugin keys still stored as their hash without waiting for the migrations job, then stop looking up keys under their hash.
+Keys stored before their keys were recorded can only be migrated as plugins access them. Use --force to complete the migration anyway, after which they will no longer be found.`,
+	Example: `  plugin kv-migration complete
+  plugin kv-migration complete --force`,
+	RunE: pluginKVMigrationCompleteCmdF,
+}
+
 func init() {
+	PluginScaffoldCmd.Flags().StringSlice("hook", nil, "Hooks the plugin implements.")
+	PluginScaffoldCmd.Flags().StringSlice("capability", nil, "Capabilities the plugin declares. Without any, the plugin is granted the server's default capabilities.")
+	PluginScaffoldCmd.Flags().String("output", "", "The directory to generate the plugin in, which must not already exist. Defaults to the plugin id.")
+	PluginEventsCmd.Flags().String("since", "", "Only show the events recorded after this date, such as 2018-06-01 or 2018-06-01T15:04:05Z, or this long ago, such as 72h.")
+	PluginKVMigrationCompleteCmd.Flags().Bool("force", false, "Complete the migration even if keys stored before their keys were recorded remain.")
+
+	PluginKVMigrationCmd.AddCommand(
+		PluginKVMigrationStatusCmd,
+		PluginKVMigrationCompleteCmd,
+	)
 	PluginCmd.AddCommand(
 		PluginAddCmd,
 		PluginDeleteCmd,
 		PluginEnableCmd,
 		PluginDisableCmd,
 		PluginListCmd,
+		PluginValidateCmd,
+		PluginScaffoldCmd,
+		PluginEventsCmd,
+		PluginKVMigrationCmd,
 	)
 	RootCmd.AddCommand(PluginCmd)
 }
@@ -83,12 +161,24 @@ func pluginAddCmdF(command *cobra.Command, args []string) error {
 			return err
 		}
 
-		if _, err := a.InstallPlugin(fileReader, false); err != nil {
+		var signatureReader io.Reader
+		signatureFile, err := os.Open(plugin + ".sig")
+		if err == nil {
+			signatureReader = signatureFile
+		} else if !os.IsNotExist(err) {
+			fileReader.Close()
+			return err
+		}
+
+		if _, err := a.InstallPlugin(fileReader, signatureReader, false); err != nil {
 			CommandPrintErrorln("Unable to add plugin: " + args[i] + ". Error: " + err.Error())
 		} else {
 			CommandPrettyPrintln("Added plugin: " + plugin)
 		}
 		fileReader.Close()
+		if signatureFile != nil {
+			signatureFile.Close()
+		}
 	}
 
 	return nil
@@ -127,6 +217,10 @@ func pluginEnableCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Expected at least one argument. See help text for details.")
 	}
 
+	if len(args) > 1 {
+		return setPluginStates(a, args, true)
+	}
+
 	for _, plugin := range args {
 		if err := a.EnablePlugin(plugin); err != nil {
 			CommandPrintErrorln("Unable to enable plugin: " + plugin + ". Error: " + err.Error())
@@ -149,6 +243,10 @@ func pluginDisableCmdF(command *cobra.Command, args []string) error {
 		return errors.New("Expected at least one argument. See help text for details.")
 	}
 
+	if len(args) > 1 {
+		return setPluginStates(a, args, false)
+	}
+
 	for _, plugin := range args {
 		if err := a.DisablePlugin(plugin); err != nil {
 			CommandPrintErrorln("Unable to disable plugin: " + plugin + ". Error: " + err.Error())
@@ -160,6 +258,34 @@ func pluginDisableCmdF(command *cobra.Command, args []string) error {
 	return nil
 }
 
+// setPluginStates enables or disables several plugins at once, saving the config a single time.
+func setPluginStates(a *app.App, plugins []string, enable bool) error {
+	states := make(map[string]bool, len(plugins))
+	for _, plugin := range plugins {
+		states[plugin] = enable
+	}
+
+	results, err := a.SetPluginStates(states)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		switch result.Result {
+		case model.PLUGIN_STATE_CHANGE_ENABLED:
+			CommandPrettyPrintln("Enabled plugin: " + result.PluginId)
+		case model.PLUGIN_STATE_CHANGE_DISABLED:
+			CommandPrettyPrintln("Disabled plugin: " + result.PluginId)
+		case model.PLUGIN_STATE_CHANGE_NOT_INSTALLED:
+			CommandPrintErrorln("Unable to change state of plugin: " + result.PluginId + ". Error: plugin is not installed")
+		default:
+			CommandPrintErrorln("Unable to enable plugin: " + result.PluginId + ". Error: " + result.Error)
+		}
+	}
+
+	return nil
+}
+
 func pluginListCmdF(command *cobra.Command, args []string) error {
 	a, err := InitDBCommandContextCobra(command)
 	if err != nil {
@@ -184,3 +310,213 @@ func pluginListCmdF(command *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func pluginValidateCmdF(command *cobra.Command, args []string) error {
+	a, err := InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown()
+
+	if len(args) < 1 {
+		return errors.New("Expected at least one argument. See help text for details.")
+	}
+
+	invalid := false
+	for _, plugin := range args {
+		fileReader, err := os.Open(plugin)
+		if err != nil {
+			return err
+		}
+
+		report, appErr := a.ValidatePlugin(fileReader)
+		fileReader.Close()
+		if appErr != nil {
+			return errors.New("Unable to validate plugin: " + plugin + ". Error: " + appErr.Error())
+		}
+
+		if report.Valid {
+			CommandPrettyPrintln("Valid plugin: " + plugin)
+			continue
+		}
+
+		invalid = true
+		CommandPrintErrorln("Invalid plugin: " + plugin)
+		for _, finding := range report.Findings {
+			CommandPrintErrorln("  " + finding.Error())
+		}
+	}
+
+	if invalid {
+		return errors.New("One or more plugins failed validation.")
+	}
+
+	return nil
+}
+
+func pluginScaffoldCmdF(command *cobra.Command, args []string) error {
+	a, err := InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown()
+
+	if len(args) != 1 {
+		return errors.New("Expected one argument. See help text for details.")
+	}
+
+	hooks, _ := command.Flags().GetStringSlice("hook")
+	capabilities, _ := command.Flags().GetStringSlice("capability")
+	output, _ := command.Flags().GetString("output")
+	if output == "" {
+		output = args[0]
+	}
+
+	files, appErr := a.ScaffoldPlugin(&model.PluginScaffoldRequest{
+		PluginId:     args[0],
+		Hooks:        hooks,
+		Capabilities: capabilities,
+	})
+	if appErr != nil {
+		return errors.New("Unable to generate plugin. Error: " + appErr.Error())
+	}
+
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		return errors.New("Unable to generate plugin: " + output + " already exists.")
+	}
+
+	for path, contents := range files {
+		path = filepath.Join(output, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, contents, 0644); err != nil {
+			return err
+		}
+	}
+
+	CommandPrettyPrintln("Generated plugin: " + output)
+	CommandPrettyPrintln("Run make in " + output + " to build its bundle.")
+
+	return nil
+}
+
+func pluginKVMigrationStatusCmdF(command *cobra.Command, args []string) error {
+	a, err := InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown()
+
+	status, appErr := a.GetPluginKeyValueMigrationStatus()
+	if appErr != nil {
+		return errors.New("Unable to get the plugin key-value migration status. Error: " + appErr.Error())
+	}
+
+	CommandPrettyPrintln(fmt.Sprintf("Completed: %v", status.Completed))
+	CommandPrettyPrintln(fmt.Sprintf("Keys left to migrate: %v", status.RemainingKeys))
+	CommandPrettyPrintln(fmt.Sprintf("Keys only migrated as plugins access them: %v", status.UnrecordedKeys))
+
+	if status.Completed {
+		return nil
+	}
+
+	state, job, appErr := migrations.GetMigrationState(model.MIGRATION_KEY_PLUGIN_KEY_VALUE_HASHED_KEYS, a.Srv.Store)
+	if appErr != nil {
+		return errors.New("Unable to get the plugin key-value migration job. Error: " + appErr.Error())
+	}
+
+	CommandPrettyPrintln("Migrations job: " + state)
+	if job != nil {
+		if progress := model.PluginKeyValueMigrationProgressFromJson(strings.NewReader(job.Data[migrations.JOB_DATA_KEY_MIGRATION_LAST_DONE])); progress != nil {
+			CommandPrettyPrintln(fmt.Sprintf("Keys migrated by the job: %v", progress.MovedKeys))
+		}
+		if job.Status == model.JOB_STATUS_ERROR && job.Data["error"] != "" {
+			CommandPrettyPrintln("Job error: " + job.Data["error"])
+		}
+	}
+
+	return nil
+}
+
+func pluginKVMigrationCompleteCmdF(command *cobra.Command, args []string) error {
+	a, err := InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown()
+
+	force, _ := command.Flags().GetBool("force")
+
+	if appErr := a.CompletePluginKeyValueMigration(force); appErr != nil {
+		return errors.New("Unable to complete the plugin key-value migration. Error: " + appErr.Error())
+	}
+
+	CommandPrettyPrintln("Completed the plugin key-value migration")
+
+	return nil
+}
+
+func pluginEventsCmdF(command *cobra.Command, args []string) error {
+	a, err := InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown()
+
+	if len(args) != 1 {
+		return errors.New("Expected one argument. See help text for details.")
+	}
+
+	sinceString, _ := command.Flags().GetString("since")
+	since, err := parsePluginEventsSince(sinceString, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for page := 0; ; page++ {
+		events, appErr := a.GetPluginEvents(args[0], since, page, PLUGIN_EVENTS_PAGE_SIZE)
+		if appErr != nil {
+			return errors.New("Unable to get the plugin's events. Error: " + appErr.Error())
+		}
+
+		for _, event := range events {
+			line := time.Unix(0, event.CreateAt*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+			if event.ClusterId != "" {
+				line += " " + event.ClusterId
+			}
+			line += " " + event.Type
+			if event.Details != "" {
+				line += ": " + event.Details
+			}
+			CommandPrettyPrintln(line)
+		}
+
+		if len(events) < PLUGIN_EVENTS_PAGE_SIZE {
+			return nil
+		}
+	}
+}
+
+// parsePluginEventsSince parses the --since flag of plugin events, a date or a duration before now,
+// into milliseconds.
+func parsePluginEventsSince(since string, now time.Time) (int64, error) {
+	if since == "" {
+		return 0, nil
+	}
+
+	if duration, err := time.ParseDuration(since); err == nil {
+		if duration < 0 {
+			return 0, errors.New("The duration given to --since must not be negative.")
+		}
+		return now.Add(-duration).UnixNano() / int64(time.Millisecond), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, since); err == nil {
+			return t.UnixNano() / int64(time.Millisecond), nil
+		}
+	}
+
+	return 0, errors.New("Unable to parse --since: " + since + ". Expected a date such as 2018-06-01 or a duration such as 72h.")
+}
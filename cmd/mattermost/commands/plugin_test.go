@@ -1,11 +1,14 @@
 package commands
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/mattermost/mattermost-server/api4"
+	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -38,5 +41,58 @@ func TestPlugin(t *testing.T) {
 
 	CheckCommand(t, "--config", filepath.Join(path, "test-config.json"), "plugin", "delete", "testplugin")
 
+	output := CheckCommand(t, "--config", filepath.Join(path, "test-config.json"), "plugin", "events", "testplugin", "--since", "1h")
+	assert.Contains(t, output, "installed")
+	assert.Contains(t, output, "removed")
+
 	os.Chdir(filepath.Join("cmd", "mattermost", "commands"))
 }
+
+func TestPluginScaffold(t *testing.T) {
+	th := api4.Setup()
+	defer th.TearDown()
+
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "myplugin")
+
+	CheckCommand(t, "plugin", "scaffold", "com.example.myplugin", "--hook", "OnActivate,MessageWillBePosted", "--capability", "posts:write", "--output", output)
+
+	mainGo, err := ioutil.ReadFile(filepath.Join(output, "server", "main.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(mainGo), "func (p *Plugin) MessageWillBePosted(")
+
+	manifest, _, err := model.FindManifest(output)
+	require.NoError(t, err)
+	assert.Equal(t, "com.example.myplugin", manifest.Id)
+	assert.Equal(t, []string{model.PLUGIN_CAPABILITY_POSTS_WRITE}, manifest.Capabilities)
+
+	// An existing directory is left alone.
+	assert.Error(t, RunCommand(t, "plugin", "scaffold", "com.example.myplugin", "--output", output))
+	assert.Error(t, RunCommand(t, "plugin", "scaffold", "com.example.other", "--hook", "MessageWillBeDeleted", "--output", filepath.Join(dir, "other")))
+}
+
+func TestParsePluginEventsSince(t *testing.T) {
+	now := time.Date(2018, 6, 10, 12, 0, 0, 0, time.UTC)
+	millis := func(t time.Time) int64 {
+		return t.UnixNano() / int64(time.Millisecond)
+	}
+
+	for since, expected := range map[string]int64{
+		"":                     0,
+		"72h":                  millis(now.Add(-72 * time.Hour)),
+		"30m":                  millis(now.Add(-30 * time.Minute)),
+		"2018-06-01":           millis(time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)),
+		"2018-06-01T15:04:05Z": millis(time.Date(2018, 6, 1, 15, 4, 5, 0, time.UTC)),
+	} {
+		actual, err := parsePluginEventsSince(since, now)
+		require.Nil(t, err, since)
+		assert.Equal(t, expected, actual, since)
+	}
+
+	for _, since := range []string{"yesterday", "-1h", "2018-13-01"} {
+		_, err := parsePluginEventsSince(since, now)
+		assert.NotNil(t, err, since)
+	}
+}
@@ -314,7 +314,7 @@ func sampleDataCmdF(command *cobra.Command, args []string) error {
 		if err != nil {
 			return errors.New("Unable to read correctly the temporary file.")
 		}
-		importErr, lineNumber := a.BulkImport(bulkFile, false, workers)
+		importErr, lineNumber := a.BulkImport(bulkFile, false, workers, false)
 		if importErr != nil {
 			return fmt.Errorf("%s: %s, %s (line: %d)", importErr.Where, importErr.Message, importErr.DetailedError, lineNumber)
 		}
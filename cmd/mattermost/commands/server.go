@@ -161,6 +161,30 @@ func runServer(configFileLocation string, disableConfigWatch bool, usedPlatform
 	a.Go(func() {
 		runCommandWebhookCleanupJob(a)
 	})
+	a.Go(func() {
+		runPluginBundleCleanupJob(a)
+	})
+	a.Go(func() {
+		runPluginKeyValueCleanupJob(a)
+	})
+	a.Go(func() {
+		runPluginRuntimeStateRefreshJob(a)
+	})
+	a.Go(func() {
+		runPluginKeyValueReencryptionJob(a)
+	})
+
+	a.Go(func() {
+		runChannelExportCleanupJob(a)
+	})
+
+	a.Go(func() {
+		runPluginAdminActionExpiryJob(a)
+	})
+
+	a.Go(func() {
+		runPluginEventCleanupJob(a)
+	})
 
 	if complianceI := a.Compliance; complianceI != nil {
 		complianceI.StartComplianceDailyJob()
@@ -242,6 +266,55 @@ func runSessionCleanupJob(a *app.App) {
 	}, time.Hour*24)
 }
 
+func runPluginBundleCleanupJob(a *app.App) {
+	doPluginBundleCleanup(a)
+	model.CreateRecurringTask("Plugin Bundle Cleanup", func() {
+		doPluginBundleCleanup(a)
+	}, app.PLUGIN_BUNDLE_CLEANUP_INTERVAL)
+}
+
+func runPluginKeyValueCleanupJob(a *app.App) {
+	doPluginKeyValueCleanup(a)
+	model.CreateRecurringTask("Plugin Key Value Cleanup", func() {
+		doPluginKeyValueCleanup(a)
+	}, app.PLUGIN_KEY_VALUE_CLEANUP_INTERVAL)
+}
+
+func runPluginRuntimeStateRefreshJob(a *app.App) {
+	doPluginRuntimeStateRefresh(a)
+	model.CreateRecurringTask("Plugin Runtime State Refresh", func() {
+		doPluginRuntimeStateRefresh(a)
+	}, app.PLUGIN_RUNTIME_STATE_REFRESH_INTERVAL)
+}
+
+func runPluginKeyValueReencryptionJob(a *app.App) {
+	doPluginKeyValueReencryption(a)
+	model.CreateRecurringTask("Plugin Key Value Re-encryption", func() {
+		doPluginKeyValueReencryption(a)
+	}, app.PLUGIN_KEY_VALUE_REENCRYPTION_INTERVAL)
+}
+
+func runChannelExportCleanupJob(a *app.App) {
+	doChannelExportCleanup(a)
+	model.CreateRecurringTask("Channel Export Cleanup", func() {
+		doChannelExportCleanup(a)
+	}, app.CHANNEL_EXPORT_CLEANUP_INTERVAL)
+}
+
+func runPluginAdminActionExpiryJob(a *app.App) {
+	doPluginAdminActionExpiry(a)
+	model.CreateRecurringTask("Plugin Admin Action Expiry", func() {
+		doPluginAdminActionExpiry(a)
+	}, app.PLUGIN_ADMIN_ACTION_EXPIRY_INTERVAL)
+}
+
+func runPluginEventCleanupJob(a *app.App) {
+	doPluginEventCleanup(a)
+	model.CreateRecurringTask("Plugin Event Cleanup", func() {
+		doPluginEventCleanup(a)
+	}, app.PLUGIN_EVENT_CLEANUP_INTERVAL)
+}
+
 func resetStatuses(a *app.App) {
 	if result := <-a.Srv.Store.Status().ResetAll(); result.Err != nil {
 		mlog.Error(fmt.Sprint("mattermost.reset_status.error FIXME: NOT FOUND IN TRANSLATIONS FILE", result.Err.Error()))
@@ -295,6 +368,34 @@ func doCommandWebhookCleanup(a *app.App) {
 	a.Srv.Store.CommandWebhook().Cleanup()
 }
 
+func doPluginBundleCleanup(a *app.App) {
+	a.CleanupPluginBundles()
+}
+
+func doPluginKeyValueCleanup(a *app.App) {
+	a.DeleteAllExpiredPluginKeys()
+}
+
+func doPluginRuntimeStateRefresh(a *app.App) {
+	a.RefreshPluginRuntimeStates()
+}
+
+func doPluginKeyValueReencryption(a *app.App) {
+	a.ReencryptPluginKeyValues()
+}
+
+func doChannelExportCleanup(a *app.App) {
+	a.DeleteExpiredChannelExports()
+}
+
+func doPluginAdminActionExpiry(a *app.App) {
+	a.ExpirePluginAdminActions()
+}
+
+func doPluginEventCleanup(a *app.App) {
+	a.DeleteExpiredPluginEvents()
+}
+
 func doSessionCleanup(a *app.App) {
 	a.Srv.Store.Session().Cleanup(model.GetMillis(), SESSIONS_CLEANUP_BATCH_SIZE)
 }
@@ -9,5 +9,8 @@ import (
 
 type ComplianceInterface interface {
 	StartComplianceDailyJob()
+
+	// RunComplianceJob writes the compliance report described by job. Implementations should pass
+	// each batch of posts through App.FilterPostsForExport before writing it.
 	RunComplianceJob(job *model.Compliance) *model.AppError
 }
@@ -11,5 +11,9 @@ import (
 
 type MessageExportInterface interface {
 	StartSynchronizeJob(ctx context.Context, exportFromTimestamp int64) (*model.Job, *model.AppError)
+
+	// RunExport exports the messages posted since the given time. Implementations should pass each
+	// batch of posts through App.FilterPostsForExport before writing it, so that plugins may redact
+	// their own post props.
 	RunExport(format string, since int64) *model.AppError
 }
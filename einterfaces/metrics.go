@@ -3,10 +3,18 @@
 
 package einterfaces
 
+import (
+	"github.com/gorilla/mux"
+)
+
 type MetricsInterface interface {
 	StartServer()
 	StopServer()
 
+	// Router returns the router of the metrics listener, on which the app registers routes of its
+	// own, such as those serving the metrics of plugins.
+	Router() *mux.Router
+
 	IncrementPostCreate()
 	IncrementWebhookPost()
 	IncrementPostSentEmail()
@@ -43,4 +51,14 @@ type MetricsInterface interface {
 
 	IncrementPostsSearchCounter()
 	ObservePostsSearchDuration(elapsed float64)
+
+	IncrementPluginBundleCleanup(count int)
+	IncrementPluginHookQueueOverflow(pluginId string)
+	ObservePluginHookDuration(pluginId, hookName string, elapsed float64)
+	ObservePluginHookQueueWaitDuration(pluginId string, elapsed float64)
+	SetPluginHookWorkersBusy(count int)
+
+	IncrementPluginStoreRequest(method string, pluginId string)
+	ObservePluginStoreRequestDuration(method string, pluginId string, elapsed float64)
+	ObservePluginStoreQueueWaitDuration(pluginId string, elapsed float64)
 }
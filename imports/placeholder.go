@@ -6,5 +6,6 @@ package imports
 // This is a placeholder so this package can be imported in Team Edition when it will be otherwise empty
 
 import (
+	_ "github.com/mattermost/mattermost-server/channelexport"
 	_ "github.com/mattermost/mattermost-server/migrations"
 )
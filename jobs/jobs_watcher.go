@@ -114,6 +114,13 @@ func (watcher *Watcher) PollAndNotify() {
 					default:
 					}
 				}
+			} else if job.Type == model.JOB_TYPE_CHANNEL_EXPORT {
+				if watcher.workers.ChannelExport != nil {
+					select {
+					case watcher.workers.ChannelExport.JobChannel() <- *job:
+					default:
+					}
+				}
 			}
 		}
 	}
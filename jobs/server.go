@@ -36,6 +36,7 @@ type JobServer struct {
 	ElasticsearchIndexer    ejobs.ElasticsearchIndexerInterface
 	LdapSync                ejobs.LdapSyncInterface
 	Migrations              tjobs.MigrationsJobInterface
+	ChannelExport           tjobs.ChannelExportJobInterface
 }
 
 func NewJobServer(configService ConfigService, store store.Store) *JobServer {
@@ -21,6 +21,7 @@ type Workers struct {
 	ElasticsearchAggregation model.Worker
 	LdapSync                 model.Worker
 	Migrations               model.Worker
+	ChannelExport            model.Worker
 
 	listenerId string
 }
@@ -55,6 +56,10 @@ func (srv *JobServer) InitWorkers() *Workers {
 		workers.Migrations = migrationsInterface.MakeWorker()
 	}
 
+	if channelExportInterface := srv.ChannelExport; channelExportInterface != nil {
+		workers.ChannelExport = channelExportInterface.MakeWorker()
+	}
+
 	return workers
 }
 
@@ -86,6 +91,10 @@ func (workers *Workers) Start() *Workers {
 			go workers.Migrations.Run()
 		}
 
+		if workers.ChannelExport != nil {
+			go workers.ChannelExport.Run()
+		}
+
 		go workers.Watcher.Start()
 	})
 
@@ -167,6 +176,10 @@ func (workers *Workers) Stop() *Workers {
 		workers.Migrations.Stop()
 	}
 
+	if workers.ChannelExport != nil {
+		workers.ChannelExport.Stop()
+	}
+
 	mlog.Info("Stopped workers")
 
 	return workers
@@ -32,6 +32,7 @@ func init() {
 func MakeMigrationsList() []string {
 	return []string{
 		model.MIGRATION_KEY_ADVANCED_PERMISSIONS_PHASE_2,
+		model.MIGRATION_KEY_PLUGIN_KEY_VALUE_HASHED_KEYS,
 	}
 }
 
@@ -50,7 +50,7 @@ func UseTestStore(container *storetest.RunningContainer, settings *model.SqlSett
 	testClusterInterface = &FakeClusterInterface{}
 	testStoreContainer = container
 	testStoreSqlSupplier = sqlstore.NewSqlSupplier(*settings, nil)
-	testStore = &persistentTestStore{store.NewLayeredStore(testStoreSqlSupplier, nil, testClusterInterface)}
+	testStore = &persistentTestStore{store.NewLayeredStore(testStoreSqlSupplier, nil, testClusterInterface, model.PLUGIN_SETTINGS_DEFAULT_KV_CACHE_SIZE, model.PLUGIN_SETTINGS_DEFAULT_KV_CACHE_SECONDS, 0)}
 }
 
 func StopTestStore() {
@@ -330,13 +330,16 @@ func (me *TestHelper) DeleteAllJobsByTypeAndMigrationKey(jobType string, migrati
 }
 
 type FakeClusterInterface struct {
-	clusterMessageHandler einterfaces.ClusterMessageHandler
+	clusterMessageHandlers map[string]einterfaces.ClusterMessageHandler
 }
 
 func (me *FakeClusterInterface) StartInterNodeCommunication() {}
 func (me *FakeClusterInterface) StopInterNodeCommunication()  {}
 func (me *FakeClusterInterface) RegisterClusterMessageHandler(event string, crm einterfaces.ClusterMessageHandler) {
-	me.clusterMessageHandler = crm
+	if me.clusterMessageHandlers == nil {
+		me.clusterMessageHandlers = make(map[string]einterfaces.ClusterMessageHandler)
+	}
+	me.clusterMessageHandlers[event] = crm
 }
 func (me *FakeClusterInterface) GetClusterId() string                             { return "" }
 func (me *FakeClusterInterface) IsLeader() bool                                   { return false }
@@ -354,7 +357,7 @@ func (me *FakeClusterInterface) ConfigChanged(previousConfig *model.Config, newC
 	return nil
 }
 func (me *FakeClusterInterface) sendClearRoleCacheMessage() {
-	me.clusterMessageHandler(&model.ClusterMessage{
+	me.clusterMessageHandlers[model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_ROLES](&model.ClusterMessage{
 		Event: model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_ROLES,
 	})
 }
@@ -0,0 +1,45 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package migrations
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func (worker *Worker) runPluginKeyValueHashedKeysMigration(lastDone string) (bool, string, *model.AppError) {
+	progress := &model.PluginKeyValueMigrationProgress{}
+	if len(lastDone) > 0 {
+		progress = model.PluginKeyValueMigrationProgressFromJson(strings.NewReader(lastDone))
+		if progress == nil {
+			return false, "", model.NewAppError("MigrationsWorker.runPluginKeyValueHashedKeysMigration", "migrations.worker.run_plugin_key_value_hashed_keys_migration.invalid_progress", map[string]interface{}{"progress": lastDone}, "", http.StatusInternalServerError)
+		}
+	}
+
+	if !progress.Done {
+		// Run a batch of the migration.
+		if err := worker.app.MigrateHashedPluginKeys(progress); err != nil {
+			return false, progress.ToJson(), err
+		}
+
+		if !progress.Done {
+			return false, progress.ToJson(), nil
+		}
+	}
+
+	// Keys last written before raw keys were recorded can only be moved as plugins access them, so
+	// the migration is not completed while any remain, unless a system admin forces it to be.
+	status, err := worker.app.GetPluginKeyValueMigrationStatus()
+	if err != nil {
+		return false, progress.ToJson(), err
+	}
+
+	if status.UnrecordedKeys > 0 {
+		return false, progress.ToJson(), model.NewAppError("MigrationsWorker.runPluginKeyValueHashedKeysMigration", "migrations.worker.run_plugin_key_value_hashed_keys_migration.unrecorded_keys", map[string]interface{}{"Count": status.UnrecordedKeys}, "", http.StatusInternalServerError)
+	}
+
+	return true, progress.ToJson(), nil
+}
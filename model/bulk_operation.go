@@ -0,0 +1,17 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+// The operations changing many users or team memberships at once, which plugins are told of with the
+// BulkOperationHasCompleted hook.
+const (
+	BULK_OPERATION_TYPE_IMPORT    = "import"
+	BULK_OPERATION_TYPE_LDAP_SYNC = "ldap_sync"
+)
+
+// The kinds of changes counted for the BulkOperationHasCompleted hook.
+const (
+	BULK_OPERATION_COUNT_USERS_CREATED        = "users_created"
+	BULK_OPERATION_COUNT_TEAM_MEMBERS_CHANGED = "team_members_changed"
+)
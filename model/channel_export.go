@@ -0,0 +1,41 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+const (
+	CHANNEL_EXPORT_FORMAT_CSV  = "csv"
+	CHANNEL_EXPORT_FORMAT_JSON = "json"
+
+	// CHANNEL_EXPORT_TYPE is the export type given to the PostsWillBeExported plugin hook for the
+	// posts of a channel export.
+	CHANNEL_EXPORT_TYPE = "channel"
+)
+
+// IsValidChannelExportFormat returns whether a channel can be exported in the given format.
+func IsValidChannelExportFormat(format string) bool {
+	return format == CHANNEL_EXPORT_FORMAT_CSV || format == CHANNEL_EXPORT_FORMAT_JSON
+}
+
+// ChannelExportStatus describes the progress of a channel export requested by a plugin.
+type ChannelExportStatus struct {
+	JobId     string `json:"job_id"`
+	ChannelId string `json:"channel_id"`
+	Format    string `json:"format"`
+
+	// Status is one of the JOB_STATUS_* values of the export's job.
+	Status string `json:"status"`
+
+	// Progress is the estimated percentage of the channel exported so far, and Rows the number of
+	// rows written.
+	Progress int64 `json:"progress"`
+	Rows     int64 `json:"rows"`
+
+	// FileId and Path identify the exported file once the export has succeeded, until it is deleted
+	// after PluginSettings.ChannelExportRetentionHours.
+	FileId string `json:"file_id,omitempty"`
+	Path   string `json:"path,omitempty"`
+
+	// Error describes why the export failed, if it did.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,18 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidChannelExportFormat(t *testing.T) {
+	assert.True(t, IsValidChannelExportFormat(CHANNEL_EXPORT_FORMAT_CSV))
+	assert.True(t, IsValidChannelExportFormat(CHANNEL_EXPORT_FORMAT_JSON))
+	assert.False(t, IsValidChannelExportFormat(""))
+	assert.False(t, IsValidChannelExportFormat("xml"))
+	assert.False(t, IsValidChannelExportFormat("CSV"))
+}
@@ -43,6 +43,37 @@ type ChannelMember struct {
 
 type ChannelMembers []ChannelMember
 
+// MemberAddOptions controls the side effects of adding a user to a channel. The zero value
+// keeps the default behaviour.
+type MemberAddOptions struct {
+	// SuppressJoinMessage skips the system message announcing the new member.
+	SuppressJoinMessage bool `json:"suppress_join_message"`
+
+	// SuppressNotifications skips the email and push notifications for that system message.
+	SuppressNotifications bool `json:"suppress_notifications"`
+}
+
+// ChannelMemberChanges describes how the membership of a channel changed since a point in time.
+type ChannelMemberChanges struct {
+	// Members holds the current members who joined the channel or whose membership was updated,
+	// including changes to their roles, notification preferences or last viewed time.
+	Members ChannelMembers `json:"members"`
+
+	// RemovedUserIds holds the users who left or were removed from the channel and are no longer members.
+	RemovedUserIds []string `json:"removed_user_ids"`
+}
+
+func (o *ChannelMemberChanges) ToJson() string {
+	b, _ := json.Marshal(o)
+	return string(b)
+}
+
+func ChannelMemberChangesFromJson(data io.Reader) *ChannelMemberChanges {
+	var o *ChannelMemberChanges
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
 func (o *ChannelMembers) ToJson() string {
 	if b, err := json.Marshal(o); err != nil {
 		return "[]"
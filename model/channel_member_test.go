@@ -83,3 +83,19 @@ func TestChannelUnreadJson(t *testing.T) {
 		t.Fatal("MentionCount do not match")
 	}
 }
+
+func TestChannelMemberChangesJson(t *testing.T) {
+	o := ChannelMemberChanges{
+		Members:        ChannelMembers{{ChannelId: NewId(), UserId: NewId()}},
+		RemovedUserIds: []string{NewId()},
+	}
+	ro := ChannelMemberChangesFromJson(strings.NewReader(o.ToJson()))
+
+	if len(ro.Members) != 1 || o.Members[0].UserId != ro.Members[0].UserId {
+		t.Fatal("Members do not match")
+	}
+
+	if len(ro.RemovedUserIds) != 1 || o.RemovedUserIds[0] != ro.RemovedUserIds[0] {
+		t.Fatal("RemovedUserIds do not match")
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ChannelModeration describes whether the members of a channel may post. While posting is
+// restricted through the plugin API, SchemeId is the channel scheme doing so and PreviousSchemeId
+// the scheme, if any, that the channel returns to once posting is allowed again.
+type ChannelModeration struct {
+	ChannelId        string `json:"channel_id"`
+	MembersCanPost   bool   `json:"members_can_post"`
+	Moderated        bool   `json:"moderated"`
+	SchemeId         string `json:"scheme_id"`
+	PreviousSchemeId string `json:"previous_scheme_id"`
+}
+
+func (o *ChannelModeration) ToJson() string {
+	b, _ := json.Marshal(o)
+	return string(b)
+}
+
+func ChannelModerationFromJson(data io.Reader) *ChannelModeration {
+	var o *ChannelModeration
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
@@ -30,6 +30,10 @@ const (
 	HEADER_AUTH               = "Authorization"
 	HEADER_REQUESTED_WITH     = "X-Requested-With"
 	HEADER_REQUESTED_WITH_XML = "XMLHttpRequest"
+	HEADER_TEAM_ID            = "X-Team-ID"
+	HEADER_PLUGIN_ID          = "X-Mattermost-Plugin-ID"
+	HEADER_READ_AFTER_WRITE   = "X-Read-After-Write"
+	HEADER_IDEMPOTENT_REPLAY  = "X-Mattermost-Idempotent-Replay"
 	STATUS                    = "status"
 	STATUS_OK                 = "OK"
 	STATUS_FAIL               = "FAIL"
@@ -1709,6 +1713,16 @@ func (c *Client4) GetChannelStats(channelId string, etag string) (*ChannelStats,
 	}
 }
 
+// GetChannelPluginSubscriptions returns the subscriptions of every plugin in a channel.
+func (c *Client4) GetChannelPluginSubscriptions(channelId string) ([]*PluginSubscription, *Response) {
+	if r, err := c.DoApiGet(c.GetChannelRoute(channelId)+"/plugin_subscriptions", ""); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return PluginSubscriptionListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // GetPinnedPosts gets a list of pinned posts.
 func (c *Client4) GetPinnedPosts(channelId string, etag string) (*PostList, *Response) {
 	if r, err := c.DoApiGet(c.GetChannelRoute(channelId)+"/pinned", etag); err != nil {
@@ -2019,6 +2033,27 @@ func (c *Client4) UnpinPost(postId string) (bool, *Response) {
 	}
 }
 
+// AcknowledgePost records that a user has acknowledged a post for which acknowledgements were
+// requested.
+func (c *Client4) AcknowledgePost(userId, postId string) (*PostAcknowledgement, *Response) {
+	if r, err := c.DoApiPost(c.GetUserRoute(userId)+c.GetPostRoute(postId)+"/ack", ""); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return PostAcknowledgementFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetPostAcknowledgements returns the acknowledgements of a post, oldest first.
+func (c *Client4) GetPostAcknowledgements(postId string) ([]*PostAcknowledgement, *Response) {
+	if r, err := c.DoApiGet(c.GetPostRoute(postId)+"/acks", ""); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return PostAcknowledgementListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // GetPost gets a single post.
 func (c *Client4) GetPost(postId string, etag string) (*Post, *Response) {
 	if r, err := c.DoApiGet(c.GetPostRoute(postId), etag); err != nil {
@@ -3647,9 +3682,26 @@ func (c *Client4) GetChannelsForScheme(schemeId string, page int, perPage int) (
 
 // Plugin Section
 
-// UploadPlugin takes an io.Reader stream pointing to the contents of a .tar.gz plugin.
+// UploadPlugin takes an io.Reader stream pointing to the contents of a .tar.gz or .zip plugin.
 // WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
 func (c *Client4) UploadPlugin(file io.Reader) (*Manifest, *Response) {
+	return c.uploadPlugin(file, nil, false)
+}
+
+// UploadPluginForced will upload a plugin, replacing any installed plugin with the same id.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) UploadPluginForced(file io.Reader) (*Manifest, *Response) {
+	return c.uploadPlugin(file, nil, true)
+}
+
+// UploadPluginWithSignature will upload a plugin along with a detached signature of its bundle,
+// replacing any installed plugin with the same id if force is set.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) UploadPluginWithSignature(file, signature io.Reader, force bool) (*Manifest, *Response) {
+	return c.uploadPlugin(file, signature, force)
+}
+
+func (c *Client4) uploadPlugin(file, signature io.Reader, force bool) (*Manifest, *Response) {
 	body := new(bytes.Buffer)
 	writer := multipart.NewWriter(body)
 
@@ -3659,6 +3711,20 @@ func (c *Client4) UploadPlugin(file io.Reader) (*Manifest, *Response) {
 		return nil, &Response{Error: NewAppError("UploadPlugin", "model.client.writer.app_error", nil, err.Error(), 0)}
 	}
 
+	if signature != nil {
+		if part, err := writer.CreateFormFile("signature", "plugin.tar.gz.sig"); err != nil {
+			return nil, &Response{Error: NewAppError("UploadPlugin", "model.client.writer.app_error", nil, err.Error(), 0)}
+		} else if _, err = io.Copy(part, signature); err != nil {
+			return nil, &Response{Error: NewAppError("UploadPlugin", "model.client.writer.app_error", nil, err.Error(), 0)}
+		}
+	}
+
+	if force {
+		if err := writer.WriteField("force", "true"); err != nil {
+			return nil, &Response{Error: NewAppError("UploadPlugin", "model.client.writer.app_error", nil, err.Error(), 0)}
+		}
+	}
+
 	if err := writer.Close(); err != nil {
 		return nil, &Response{Error: NewAppError("UploadPlugin", "model.client.writer.app_error", nil, err.Error(), 0)}
 	}
@@ -3683,6 +3749,60 @@ func (c *Client4) UploadPlugin(file io.Reader) (*Manifest, *Response) {
 	}
 }
 
+// ValidatePlugin takes an io.Reader stream pointing to the contents of a .tar.gz or .zip plugin and
+// reports whether the server would accept it, without installing it.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) ValidatePlugin(file io.Reader) (*PluginValidationReport, *Response) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	if part, err := writer.CreateFormFile("plugin", "plugin.tar.gz"); err != nil {
+		return nil, &Response{Error: NewAppError("ValidatePlugin", "model.client.writer.app_error", nil, err.Error(), 0)}
+	} else if _, err = io.Copy(part, file); err != nil {
+		return nil, &Response{Error: NewAppError("ValidatePlugin", "model.client.writer.app_error", nil, err.Error(), 0)}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, &Response{Error: NewAppError("ValidatePlugin", "model.client.writer.app_error", nil, err.Error(), 0)}
+	}
+
+	rq, _ := http.NewRequest("POST", c.ApiUrl+c.GetPluginsRoute()+"/validate", body)
+	rq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	if len(c.AuthToken) > 0 {
+		rq.Header.Set(HEADER_AUTH, c.AuthType+" "+c.AuthToken)
+	}
+
+	if rp, err := c.HttpClient.Do(rq); err != nil || rp == nil {
+		return nil, BuildErrorResponse(rp, NewAppError("ValidatePlugin", "model.client.connecting.app_error", nil, err.Error(), 0))
+	} else {
+		defer closeBody(rp)
+
+		if rp.StatusCode >= 300 {
+			return nil, BuildErrorResponse(rp, AppErrorFromJson(rp.Body))
+		} else {
+			return PluginValidationReportFromJson(rp.Body), BuildResponse(rp)
+		}
+	}
+}
+
+// ScaffoldPlugin generates the skeleton of a new plugin, implementing the requested hooks against
+// the server's plugin package, and returns it as a .tar.gz file. The server must be in developer
+// mode.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) ScaffoldPlugin(request *PluginScaffoldRequest) ([]byte, *Response) {
+	if r, err := c.DoApiPost(c.GetPluginsRoute()+"/scaffold", request.ToJson()); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, BuildErrorResponse(r, NewAppError("ScaffoldPlugin", "model.client.read_file.app_error", nil, err.Error(), r.StatusCode))
+		}
+		return data, BuildResponse(r)
+	}
+}
+
 // GetPlugins will return a list of plugin manifests for currently active plugins.
 // WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
 func (c *Client4) GetPlugins() (*PluginsResponse, *Response) {
@@ -3717,6 +3837,146 @@ func (c *Client4) RemovePlugin(id string) (bool, *Response) {
 	}
 }
 
+// RemovePluginWithData will deactivate and delete a plugin, also purging the data it stored
+// when deleteData is set.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) RemovePluginWithData(id string, deleteData bool) (bool, *Response) {
+	if r, err := c.DoApiDelete(c.GetPluginRoute(id) + fmt.Sprintf("?delete_data=%v", deleteData)); err != nil {
+		return false, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// RemoveBrokenPlugin will delete a directory that could not be loaded as a plugin, given the path
+// reported for it by GetPlugins.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) RemoveBrokenPlugin(path string) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetPluginsRoute()+"/remove_broken", MapToJson(map[string]string{"path": path})); err != nil {
+		return false, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// ExportPluginData will return the key-value data of a plugin, with each pair on a line of JSON.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) ExportPluginData(id string) ([]byte, *Response) {
+	if r, err := c.DoApiGet(c.GetPluginRoute(id)+"/data", ""); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, BuildErrorResponse(r, NewAppError("ExportPluginData", "model.client.read_file.app_error", nil, err.Error(), r.StatusCode))
+		}
+		return data, BuildResponse(r)
+	}
+}
+
+// ImportPluginData will store the key-value data of a plugin, in the format returned by
+// ExportPluginData, reporting the lines that could not be imported.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) ImportPluginData(id string, data []byte) (*PluginDataImportResult, *Response) {
+	if r, err := c.DoApiPost(c.GetPluginRoute(id)+"/data", string(data)); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return PluginDataImportResultFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetPluginKeys will return a page of the keys a plugin has stored in its key-value store.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) GetPluginKeys(id string, page, perPage int) ([]string, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetPluginRoute(id)+"/keys"+query, ""); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return ArrayFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetPluginEvents will return a page of the events recorded for a plugin at or after the given time,
+// in milliseconds, oldest first.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) GetPluginEvents(id string, since int64, page, perPage int) ([]*PluginEvent, *Response) {
+	query := fmt.Sprintf("?since=%v&page=%v&per_page=%v", since, page, perPage)
+	if r, err := c.DoApiGet(c.GetPluginRoute(id)+"/events"+query, ""); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return PluginEventListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// ReplyToPluginWebappMessage will send the reply of a plugin's webapp component to a message the
+// plugin sent to the current user.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) ReplyToPluginWebappMessage(id, replyId string, payload []byte) (bool, *Response) {
+	if r, err := c.DoApiPost(c.GetPluginRoute(id)+"/webapp_replies/"+replyId, string(payload)); err != nil {
+		return false, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return CheckStatusOK(r), BuildResponse(r)
+	}
+}
+
+// GetPluginKey will return the value a plugin has stored under the given key.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) GetPluginKey(id, key string) (*PluginDataEntry, *Response) {
+	if r, err := c.DoApiGet(c.GetPluginRoute(id)+"/keys/"+url.PathEscape(key), ""); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return PluginDataEntryFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetPluginRemovalPreview will return everything that removing a plugin would delete.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) GetPluginRemovalPreview(id string) (*PluginRemovalInventory, *Response) {
+	if r, err := c.DoApiGet(c.GetPluginRoute(id)+"/removal_preview", ""); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return PluginRemovalInventoryFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetPluginApiSpec returns the OpenAPI document declared by the given active plugin. The etag of a
+// previous response may be given to avoid downloading the document again if it is unchanged.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) GetPluginApiSpec(id, etag string) ([]byte, *Response) {
+	if r, err := c.DoApiGet(c.GetPluginRoute(id)+"/api_spec", etag); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		if r.StatusCode == http.StatusNotModified {
+			return nil, BuildResponse(r)
+		}
+		if data, err := ioutil.ReadAll(r.Body); err != nil {
+			return nil, BuildErrorResponse(r, NewAppError("GetPluginApiSpec", "model.client.read_file.app_error", nil, err.Error(), r.StatusCode))
+		} else {
+			return data, BuildResponse(r)
+		}
+	}
+}
+
+// GetPluginApiSpecs returns the routes declared by every active plugin with an API spec.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) GetPluginApiSpecs() ([]*PluginApiSpec, *Response) {
+	if r, err := c.DoApiGet(c.GetPluginsRoute()+"/specs", ""); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return PluginApiSpecListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // GetWebappPlugins will return a list of plugins that the webapp should download.
 // WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
 func (c *Client4) GetWebappPlugins() ([]*Manifest, *Response) {
@@ -3728,6 +3988,18 @@ func (c *Client4) GetWebappPlugins() ([]*Manifest, *Response) {
 	}
 }
 
+// GetWebappPluginsForTeam will return a list of plugins that the webapp should download for use on
+// the given team.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) GetWebappPluginsForTeam(teamId string) ([]*Manifest, *Response) {
+	if r, err := c.DoApiGet(c.GetPluginsRoute()+"/webapp?team_id="+url.QueryEscape(teamId), ""); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return ManifestListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // ActivatePlugin will activate an plugin installed.
 // WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
 func (c *Client4) EnablePlugin(id string) (bool, *Response) {
@@ -3750,6 +4022,55 @@ func (c *Client4) DisablePlugin(id string) (bool, *Response) {
 	}
 }
 
+// SetPluginStates will enable or disable each of the given plugins at once, returning the outcome
+// for each plugin.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) SetPluginStates(states map[string]bool) (PluginStateChangeResults, *Response) {
+	if r, err := c.DoApiPost(c.GetPluginsRoute()+"/states", MapBoolToJson(states)); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return PluginStateChangeResultsFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// GetPluginAdminActions will return a page of the plugin requests for privileged actions that are
+// waiting for a system admin to approve or deny them.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) GetPluginAdminActions(page, perPage int) ([]*PluginAdminActionRequest, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	if r, err := c.DoApiGet(c.GetPluginsRoute()+"/admin_actions"+query, ""); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return PluginAdminActionRequestListFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// ApprovePluginAdminAction will approve a plugin's request for a privileged action, which the
+// server then carries out, returning the resolved request.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) ApprovePluginAdminAction(requestId string) (*PluginAdminActionRequest, *Response) {
+	if r, err := c.DoApiPost(c.GetPluginsRoute()+"/admin_actions/"+requestId+"/approve", ""); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return PluginAdminActionRequestFromJson(r.Body), BuildResponse(r)
+	}
+}
+
+// DenyPluginAdminAction will deny a plugin's request for a privileged action, returning the
+// resolved request.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) DenyPluginAdminAction(requestId string) (*PluginAdminActionRequest, *Response) {
+	if r, err := c.DoApiPost(c.GetPluginsRoute()+"/admin_actions/"+requestId+"/deny", ""); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	} else {
+		defer closeBody(r)
+		return PluginAdminActionRequestFromJson(r.Body), BuildResponse(r)
+	}
+}
+
 // UpdateChannelScheme will update a channel's scheme.
 func (c *Client4) UpdateChannelScheme(channelId, schemeId string) (bool, *Response) {
 	sip := &SchemeIDPatch{SchemeID: &schemeId}
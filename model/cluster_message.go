@@ -21,8 +21,12 @@ const (
 	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL                      = "inv_channel"
 	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_USER                         = "inv_user"
 	CLUSTER_EVENT_CLEAR_SESSION_CACHE_FOR_USER                      = "clear_session_user"
+	CLUSTER_EVENT_CLOSE_WEB_CONNS_FOR_USER                          = "close_web_conns_user"
 	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_ROLES                        = "inv_roles"
 	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_SCHEMES                      = "inv_schemes"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_PLUGIN_KEY_VALUES            = "inv_plugin_key_values"
+	CLUSTER_EVENT_PLUGIN_KEY_VALUE_HAS_CHANGED                      = "plugin_key_value_has_changed"
+	CLUSTER_EVENT_PLUGIN_WEBAPP_REPLY                               = "plugin_webapp_reply"
 
 	CLUSTER_SEND_BEST_EFFORT = "best_effort"
 	CLUSTER_SEND_RELIABLE    = "reliable"
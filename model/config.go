@@ -158,8 +158,28 @@ const (
 	DATA_RETENTION_SETTINGS_DEFAULT_FILE_RETENTION_DAYS     = 365
 	DATA_RETENTION_SETTINGS_DEFAULT_DELETION_JOB_START_TIME = "02:00"
 
-	PLUGIN_SETTINGS_DEFAULT_DIRECTORY        = "./plugins"
-	PLUGIN_SETTINGS_DEFAULT_CLIENT_DIRECTORY = "./client/plugins"
+	PLUGIN_SETTINGS_DEFAULT_DIRECTORY                        = "./plugins"
+	PLUGIN_SETTINGS_DEFAULT_CLIENT_DIRECTORY                 = "./client/plugins"
+	PLUGIN_SETTINGS_DEFAULT_PREPACKAGED_DIRECTORY            = "./prepackaged_plugins"
+	PLUGIN_SETTINGS_DEFAULT_MAX_BUNDLE_SIZE                  = 50 * 1024 * 1024
+	PLUGIN_SETTINGS_DEFAULT_MAX_EXTRACTED_SIZE               = 200 * 1024 * 1024
+	PLUGIN_SETTINGS_DEFAULT_MAX_INSTALLED_PLUGINS            = 100
+	PLUGIN_SETTINGS_DEFAULT_KV_COMPRESSION_SIZE              = 1024
+	PLUGIN_SETTINGS_DEFAULT_MAX_KEY_VALUE_SIZE               = 1024 * 1024
+	PLUGIN_SETTINGS_DEFAULT_KV_CACHE_SIZE                    = 10000
+	PLUGIN_SETTINGS_DEFAULT_KV_CACHE_SECONDS                 = 60
+	PLUGIN_SETTINGS_DEFAULT_ADMIN_ACTION_REQUEST_TTL_MINUTES = 1440
+
+	PLUGIN_SETTINGS_DEFAULT_MAX_CHANNEL_EXPORT_ROWS        = 1000000
+	PLUGIN_SETTINGS_DEFAULT_CHANNEL_EXPORT_RETENTION_HOURS = 24
+	PLUGIN_SETTINGS_DEFAULT_EVENT_RETENTION_DAYS           = 30
+
+	PLUGIN_SETTINGS_DEFAULT_BULK_HOOK_THRESHOLD     = 100
+	PLUGIN_SETTINGS_DEFAULT_BULK_HOOK_BATCH_SIZE    = 500
+	PLUGIN_SETTINGS_DEFAULT_BULK_HOOK_PACING_MILLIS = 1000
+
+	PLUGIN_SETTINGS_DEFAULT_CAPABILITIES_ALL  = "all"
+	PLUGIN_SETTINGS_DEFAULT_CAPABILITIES_NONE = "none"
 
 	TIMEZONE_SETTINGS_DEFAULT_SUPPORTED_TIMEZONES_PATH = "timezones.json"
 
@@ -1770,6 +1790,16 @@ func (s *JobSettings) SetDefaults() {
 
 type PluginState struct {
 	Enable bool
+
+	// Reason is why the plugin was last enabled or disabled, as one of the PLUGIN_STATE_REASON_*
+	// codes, and UpdatedAt the time in milliseconds at which it was. Both are empty for states saved
+	// before they were recorded.
+	Reason    string `json:",omitempty"`
+	UpdatedAt int64  `json:",omitempty"`
+
+	// Removed is set once an admin has removed the plugin, so that it is not installed again from
+	// PluginSettings.PrepackagedDirectory unless it is installed explicitly first.
+	Removed bool `json:",omitempty"`
 }
 
 type PluginSettings struct {
@@ -1777,8 +1807,85 @@ type PluginSettings struct {
 	EnableUploads   *bool
 	Directory       *string
 	ClientDirectory *string
-	Plugins         map[string]map[string]interface{}
-	PluginStates    map[string]*PluginState
+	// PrepackagedDirectory holds the plugin bundles installed when the server starts, unless the
+	// plugin is installed at the same or a newer version or was removed by an admin. Relative paths
+	// are looked for from the working directory and from the directory of the server binary.
+	PrepackagedDirectory *string
+	MaxBundleSize        *int64
+	MaxExtractedSize     *int64
+	MaxInstalledPlugins  *int
+	EnableBundleCleanup  *bool
+	// EnableKeyValueCompression gzips plugin key-value store values larger than
+	// KeyValueCompressionThreshold bytes before storing them.
+	EnableKeyValueCompression    *bool
+	KeyValueCompressionThreshold *int
+	// MaxKeyValueSizeBytes is the largest value plugins may store in the key-value store, counted
+	// once compressed and encrypted. Large values may also need MySQL's max_allowed_packet to be raised.
+	MaxKeyValueSizeBytes *int
+	// KeyValueCacheSize is the number of plugin key-value pairs cached in memory by each server,
+	// each for at most KeyValueCacheSeconds. A size of zero disables the cache. Changes require a
+	// server restart.
+	KeyValueCacheSize    *int
+	KeyValueCacheSeconds *int
+	// KeyValueMaxConcurrentOperations is the number of plugin key-value reads and writes each server
+	// sends to the database at the same time, keeping database connections free for other requests
+	// when plugins make many of them. Zero means unlimited. Changes require a server restart.
+	KeyValueMaxConcurrentOperations *int
+	// AdminActionRequestTTLMinutes is how long a plugin's request for a privileged action waits for
+	// a system admin to approve it before expiring.
+	AdminActionRequestTTLMinutes *int
+	// KeyValueEncryptionKey, when set, is used to encrypt values written to the plugin key-value
+	// store. Values encrypted with PreviousKeyValueEncryptionKeys can still be read, and are
+	// re-encrypted with the current key in the background.
+	KeyValueEncryptionKey          *string
+	PreviousKeyValueEncryptionKeys []string
+	// SettingsEncryptionKey, when set, is used to encrypt the plugin settings that their manifest
+	// marks secret. Secret settings are re-encrypted when it is changed through the API.
+	SettingsEncryptionKey *string
+	// MaxKeysPerPlugin and MaxTotalBytesPerPlugin limit the number of key-value pairs each plugin may
+	// store and the total size of their values, counted as stored. Zero means unlimited.
+	MaxKeysPerPlugin       *int
+	MaxTotalBytesPerPlugin *int64
+	// MaxChannelExportRows is the most rows, counting members, posts and files, that a channel export
+	// requested by a plugin may hold before it fails. The exported file is deleted once
+	// ChannelExportRetentionHours have passed.
+	MaxChannelExportRows        *int
+	ChannelExportRetentionHours *int
+	// DefaultCapabilities is either "all" or "none", granting plugins whose manifest does not declare
+	// their capabilities every capability of the plugin API, or none of them.
+	DefaultCapabilities *string
+	// ProtectedResponseHeaders are the headers plugins may not set on responses to their HTTP
+	// requests unless declared in their manifest's security_headers.
+	ProtectedResponseHeaders []string
+	// AllowPluginCSPExtensions allows the external origins declared in the manifests of active
+	// plugins with a webapp to be added to the Content-Security-Policy of the webapp.
+	AllowPluginCSPExtensions *bool
+	// MaxRequestBodySize is the largest body, in bytes, of the HTTP requests passed on to plugins,
+	// unless a route of the plugin's manifest declares its own. Zero means FileSettings.MaxFileSize.
+	MaxRequestBodySize *int64
+	// EventRetentionDays is how long the entries of the plugins' event logs are kept.
+	EventRetentionDays *int
+	// HookWorkerPoolSize is the number of workers shared by all plugins to run the hooks that do not
+	// block the server, such as MessageHasBeenPosted. Zero scales it to the number of CPUs. Changes
+	// take effect when the server restarts.
+	HookWorkerPoolSize *int
+	// Operations changing more than BulkHookThreshold users or team memberships at once, such as bulk
+	// imports, report them to plugins in batches of at most BulkHookBatchSize once complete. Plugins
+	// that do not implement the batch hooks receive the individual hooks instead, pausing for
+	// BulkHookPacingMilliseconds after each batch.
+	BulkHookThreshold          *int
+	BulkHookBatchSize          *int
+	BulkHookPacingMilliseconds *int
+	// SignaturePublicKeyFiles are the PEM encoded RSA public keys against which the detached
+	// signatures of plugin bundles are verified. When RequirePluginSignature is set, plugins are only
+	// installed and activated with a signature made by one of them.
+	SignaturePublicKeyFiles []string
+	RequirePluginSignature  *bool
+	// PluginTeamRestrictions maps plugin ids to the only teams on which their commands, web app
+	// components and HTTP requests are available. Plugins not listed are available on every team.
+	PluginTeamRestrictions map[string][]string
+	Plugins                map[string]map[string]interface{}
+	PluginStates           map[string]*PluginState
 }
 
 func (s *PluginSettings) SetDefaults() {
@@ -1806,6 +1913,134 @@ func (s *PluginSettings) SetDefaults() {
 		*s.ClientDirectory = PLUGIN_SETTINGS_DEFAULT_CLIENT_DIRECTORY
 	}
 
+	if s.PrepackagedDirectory == nil {
+		s.PrepackagedDirectory = NewString(PLUGIN_SETTINGS_DEFAULT_PREPACKAGED_DIRECTORY)
+	}
+
+	if *s.PrepackagedDirectory == "" {
+		*s.PrepackagedDirectory = PLUGIN_SETTINGS_DEFAULT_PREPACKAGED_DIRECTORY
+	}
+
+	if s.MaxBundleSize == nil {
+		s.MaxBundleSize = NewInt64(PLUGIN_SETTINGS_DEFAULT_MAX_BUNDLE_SIZE)
+	}
+
+	if s.MaxExtractedSize == nil {
+		s.MaxExtractedSize = NewInt64(PLUGIN_SETTINGS_DEFAULT_MAX_EXTRACTED_SIZE)
+	}
+
+	if s.MaxInstalledPlugins == nil {
+		s.MaxInstalledPlugins = NewInt(PLUGIN_SETTINGS_DEFAULT_MAX_INSTALLED_PLUGINS)
+	}
+
+	if s.EnableBundleCleanup == nil {
+		s.EnableBundleCleanup = NewBool(false)
+	}
+
+	if s.EnableKeyValueCompression == nil {
+		s.EnableKeyValueCompression = NewBool(true)
+	}
+
+	if s.KeyValueCompressionThreshold == nil {
+		s.KeyValueCompressionThreshold = NewInt(PLUGIN_SETTINGS_DEFAULT_KV_COMPRESSION_SIZE)
+	}
+
+	if s.MaxKeyValueSizeBytes == nil {
+		s.MaxKeyValueSizeBytes = NewInt(PLUGIN_SETTINGS_DEFAULT_MAX_KEY_VALUE_SIZE)
+	}
+
+	if s.KeyValueCacheSize == nil {
+		s.KeyValueCacheSize = NewInt(PLUGIN_SETTINGS_DEFAULT_KV_CACHE_SIZE)
+	}
+
+	if s.KeyValueCacheSeconds == nil {
+		s.KeyValueCacheSeconds = NewInt(PLUGIN_SETTINGS_DEFAULT_KV_CACHE_SECONDS)
+	}
+
+	if s.KeyValueMaxConcurrentOperations == nil {
+		s.KeyValueMaxConcurrentOperations = NewInt(0)
+	}
+
+	if s.AdminActionRequestTTLMinutes == nil {
+		s.AdminActionRequestTTLMinutes = NewInt(PLUGIN_SETTINGS_DEFAULT_ADMIN_ACTION_REQUEST_TTL_MINUTES)
+	}
+
+	if s.KeyValueEncryptionKey == nil {
+		s.KeyValueEncryptionKey = NewString("")
+	}
+
+	if s.PreviousKeyValueEncryptionKeys == nil {
+		s.PreviousKeyValueEncryptionKeys = []string{}
+	}
+
+	if s.SettingsEncryptionKey == nil {
+		s.SettingsEncryptionKey = NewString("")
+	}
+
+	if s.MaxKeysPerPlugin == nil {
+		s.MaxKeysPerPlugin = NewInt(0)
+	}
+
+	if s.MaxTotalBytesPerPlugin == nil {
+		s.MaxTotalBytesPerPlugin = NewInt64(0)
+	}
+
+	if s.MaxChannelExportRows == nil {
+		s.MaxChannelExportRows = NewInt(PLUGIN_SETTINGS_DEFAULT_MAX_CHANNEL_EXPORT_ROWS)
+	}
+
+	if s.ChannelExportRetentionHours == nil {
+		s.ChannelExportRetentionHours = NewInt(PLUGIN_SETTINGS_DEFAULT_CHANNEL_EXPORT_RETENTION_HOURS)
+	}
+
+	if s.DefaultCapabilities == nil {
+		s.DefaultCapabilities = NewString(PLUGIN_SETTINGS_DEFAULT_CAPABILITIES_ALL)
+	}
+
+	if s.ProtectedResponseHeaders == nil {
+		s.ProtectedResponseHeaders = []string{"Content-Security-Policy", "Strict-Transport-Security", "X-Frame-Options"}
+	}
+
+	if s.AllowPluginCSPExtensions == nil {
+		s.AllowPluginCSPExtensions = NewBool(false)
+	}
+
+	if s.MaxRequestBodySize == nil {
+		s.MaxRequestBodySize = NewInt64(0)
+	}
+
+	if s.EventRetentionDays == nil {
+		s.EventRetentionDays = NewInt(PLUGIN_SETTINGS_DEFAULT_EVENT_RETENTION_DAYS)
+	}
+
+	if s.HookWorkerPoolSize == nil {
+		s.HookWorkerPoolSize = NewInt(0)
+	}
+
+	if s.BulkHookThreshold == nil {
+		s.BulkHookThreshold = NewInt(PLUGIN_SETTINGS_DEFAULT_BULK_HOOK_THRESHOLD)
+	}
+
+	if s.BulkHookBatchSize == nil {
+		s.BulkHookBatchSize = NewInt(PLUGIN_SETTINGS_DEFAULT_BULK_HOOK_BATCH_SIZE)
+	}
+
+	if s.BulkHookPacingMilliseconds == nil {
+		s.BulkHookPacingMilliseconds = NewInt(PLUGIN_SETTINGS_DEFAULT_BULK_HOOK_PACING_MILLIS)
+	}
+
+	if s.SignaturePublicKeyFiles == nil {
+		s.SignaturePublicKeyFiles = []string{}
+	}
+
+	if s.RequirePluginSignature == nil {
+		s.RequirePluginSignature = NewBool(false)
+	}
+
+	if s.PluginTeamRestrictions == nil {
+		s.PluginTeamRestrictions = make(map[string][]string)
+	}
+
 	if s.Plugins == nil {
 		s.Plugins = make(map[string]map[string]interface{})
 	}
@@ -2094,6 +2329,10 @@ func (o *Config) IsValid() *AppError {
 		return err
 	}
 
+	if err := o.PluginSettings.isValid(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -2440,6 +2679,112 @@ func (drs *DataRetentionSettings) isValid() *AppError {
 	return nil
 }
 
+func (ps *PluginSettings) isValid() *AppError {
+	if *ps.MaxBundleSize <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.max_bundle_size.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.MaxExtractedSize <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.max_extracted_size.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.MaxInstalledPlugins <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.max_installed_plugins.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.KeyValueCompressionThreshold < 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.key_value_compression_threshold.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.MaxKeyValueSizeBytes <= 0 || *ps.MaxKeyValueSizeBytes > KEY_VALUE_VALUE_MAX_BYTES {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.max_key_value_size.app_error", map[string]interface{}{"Max": KEY_VALUE_VALUE_MAX_BYTES}, "", http.StatusBadRequest)
+	}
+
+	if *ps.KeyValueCacheSize < 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.key_value_cache_size.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.KeyValueCacheSeconds <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.key_value_cache_seconds.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.KeyValueMaxConcurrentOperations < 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.key_value_max_concurrent_operations.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.AdminActionRequestTTLMinutes <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.admin_action_request_ttl_minutes.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	for _, key := range append([]string{*ps.KeyValueEncryptionKey}, ps.PreviousKeyValueEncryptionKeys...) {
+		if len(key) > 0 && len(key) < 32 {
+			return NewAppError("Config.IsValid", "model.config.is_valid.plugin.key_value_encryption_key.app_error", nil, "", http.StatusBadRequest)
+		}
+	}
+
+	if len(*ps.SettingsEncryptionKey) > 0 && len(*ps.SettingsEncryptionKey) < 32 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.settings_encryption_key.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.MaxKeysPerPlugin < 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.max_keys_per_plugin.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.MaxTotalBytesPerPlugin < 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.max_total_bytes_per_plugin.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.MaxChannelExportRows <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.max_channel_export_rows.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.ChannelExportRetentionHours <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.channel_export_retention_hours.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.MaxRequestBodySize < 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.max_request_body_size.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.EventRetentionDays <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.event_retention_days.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.HookWorkerPoolSize < 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.hook_worker_pool_size.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.BulkHookThreshold < 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.bulk_hook_threshold.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.BulkHookBatchSize <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.bulk_hook_batch_size.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.BulkHookPacingMilliseconds < 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.bulk_hook_pacing_milliseconds.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.RequirePluginSignature && len(ps.SignaturePublicKeyFiles) == 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.signature_public_key_files.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *ps.DefaultCapabilities != PLUGIN_SETTINGS_DEFAULT_CAPABILITIES_ALL && *ps.DefaultCapabilities != PLUGIN_SETTINGS_DEFAULT_CAPABILITIES_NONE {
+		return NewAppError("Config.IsValid", "model.config.is_valid.plugin.default_capabilities.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	for pluginId, teamIds := range ps.PluginTeamRestrictions {
+		for _, teamId := range teamIds {
+			if !IsValidId(teamId) {
+				return NewAppError("Config.IsValid", "model.config.is_valid.plugin.team_restrictions.app_error", map[string]interface{}{"PluginId": pluginId}, "team_id="+teamId, http.StatusBadRequest)
+			}
+		}
+	}
+
+	return nil
+}
+
 func (ls *LocalizationSettings) isValid() *AppError {
 	if len(*ls.AvailableLocales) > 0 {
 		if !strings.Contains(*ls.AvailableLocales, *ls.DefaultClientLocale) {
@@ -2545,4 +2890,16 @@ func (o *Config) Sanitize() {
 	}
 
 	*o.ElasticsearchSettings.Password = FAKE_SETTING
+
+	if len(*o.PluginSettings.KeyValueEncryptionKey) > 0 {
+		*o.PluginSettings.KeyValueEncryptionKey = FAKE_SETTING
+	}
+
+	for i := range o.PluginSettings.PreviousKeyValueEncryptionKeys {
+		o.PluginSettings.PreviousKeyValueEncryptionKeys[i] = FAKE_SETTING
+	}
+
+	if len(*o.PluginSettings.SettingsEncryptionKey) > 0 {
+		*o.PluginSettings.SettingsEncryptionKey = FAKE_SETTING
+	}
 }
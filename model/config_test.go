@@ -561,3 +561,131 @@ func TestListenAddressIsValidated(t *testing.T) {
 	}
 
 }
+
+func TestPluginSettingsIsValidLimits(t *testing.T) {
+	ps := &PluginSettings{}
+	ps.SetDefaults()
+	require.Nil(t, ps.isValid())
+
+	*ps.MaxBundleSize = 0
+	require.NotNil(t, ps.isValid())
+	*ps.MaxBundleSize = PLUGIN_SETTINGS_DEFAULT_MAX_BUNDLE_SIZE
+
+	*ps.MaxExtractedSize = -1
+	require.NotNil(t, ps.isValid())
+	*ps.MaxExtractedSize = PLUGIN_SETTINGS_DEFAULT_MAX_EXTRACTED_SIZE
+
+	*ps.MaxInstalledPlugins = 0
+	require.NotNil(t, ps.isValid())
+	*ps.MaxInstalledPlugins = PLUGIN_SETTINGS_DEFAULT_MAX_INSTALLED_PLUGINS
+
+	*ps.KeyValueCompressionThreshold = -1
+	require.NotNil(t, ps.isValid())
+	*ps.KeyValueCompressionThreshold = 0
+	require.Nil(t, ps.isValid())
+
+	*ps.MaxKeyValueSizeBytes = 0
+	require.NotNil(t, ps.isValid())
+	*ps.MaxKeyValueSizeBytes = KEY_VALUE_VALUE_MAX_BYTES + 1
+	require.NotNil(t, ps.isValid())
+	*ps.MaxKeyValueSizeBytes = KEY_VALUE_VALUE_MAX_BYTES
+	require.Nil(t, ps.isValid())
+
+	*ps.KeyValueCacheSize = -1
+	require.NotNil(t, ps.isValid())
+	*ps.KeyValueCacheSize = 0
+	require.Nil(t, ps.isValid())
+
+	*ps.KeyValueMaxConcurrentOperations = -1
+	require.NotNil(t, ps.isValid())
+	*ps.KeyValueMaxConcurrentOperations = 0
+	require.Nil(t, ps.isValid())
+
+	*ps.AdminActionRequestTTLMinutes = 0
+	require.NotNil(t, ps.isValid())
+	*ps.AdminActionRequestTTLMinutes = 60
+	require.Nil(t, ps.isValid())
+
+	*ps.KeyValueCacheSeconds = 0
+	require.NotNil(t, ps.isValid())
+	*ps.KeyValueCacheSeconds = PLUGIN_SETTINGS_DEFAULT_KV_CACHE_SECONDS
+	require.Nil(t, ps.isValid())
+
+	*ps.KeyValueEncryptionKey = "tooshort"
+	require.NotNil(t, ps.isValid())
+	*ps.KeyValueEncryptionKey = NewRandomString(32)
+	require.Nil(t, ps.isValid())
+
+	ps.PreviousKeyValueEncryptionKeys = []string{"tooshort"}
+	require.NotNil(t, ps.isValid())
+	ps.PreviousKeyValueEncryptionKeys = []string{NewRandomString(32)}
+	require.Nil(t, ps.isValid())
+
+	*ps.SettingsEncryptionKey = "tooshort"
+	require.NotNil(t, ps.isValid())
+	*ps.SettingsEncryptionKey = NewRandomString(32)
+	require.Nil(t, ps.isValid())
+
+	*ps.MaxKeysPerPlugin = -1
+	require.NotNil(t, ps.isValid())
+	*ps.MaxKeysPerPlugin = 100
+	require.Nil(t, ps.isValid())
+
+	*ps.MaxTotalBytesPerPlugin = -1
+	require.NotNil(t, ps.isValid())
+	*ps.MaxTotalBytesPerPlugin = 1024 * 1024
+	require.Nil(t, ps.isValid())
+
+	*ps.MaxChannelExportRows = 0
+	require.NotNil(t, ps.isValid())
+	*ps.MaxChannelExportRows = PLUGIN_SETTINGS_DEFAULT_MAX_CHANNEL_EXPORT_ROWS
+	require.Nil(t, ps.isValid())
+
+	*ps.ChannelExportRetentionHours = 0
+	require.NotNil(t, ps.isValid())
+	*ps.ChannelExportRetentionHours = PLUGIN_SETTINGS_DEFAULT_CHANNEL_EXPORT_RETENTION_HOURS
+	require.Nil(t, ps.isValid())
+
+	*ps.MaxRequestBodySize = -1
+	require.NotNil(t, ps.isValid())
+	*ps.MaxRequestBodySize = 1024
+	require.Nil(t, ps.isValid())
+
+	*ps.EventRetentionDays = 0
+	require.NotNil(t, ps.isValid())
+	*ps.EventRetentionDays = PLUGIN_SETTINGS_DEFAULT_EVENT_RETENTION_DAYS
+	require.Nil(t, ps.isValid())
+
+	*ps.BulkHookThreshold = -1
+	require.NotNil(t, ps.isValid())
+	*ps.BulkHookThreshold = 0
+	require.Nil(t, ps.isValid())
+
+	*ps.BulkHookBatchSize = 0
+	require.NotNil(t, ps.isValid())
+	*ps.BulkHookBatchSize = PLUGIN_SETTINGS_DEFAULT_BULK_HOOK_BATCH_SIZE
+	require.Nil(t, ps.isValid())
+
+	*ps.BulkHookPacingMilliseconds = -1
+	require.NotNil(t, ps.isValid())
+	*ps.BulkHookPacingMilliseconds = 0
+	require.Nil(t, ps.isValid())
+
+	*ps.RequirePluginSignature = true
+	require.NotNil(t, ps.isValid())
+	ps.SignaturePublicKeyFiles = []string{"plugin-signing.pem"}
+	require.Nil(t, ps.isValid())
+	*ps.RequirePluginSignature = false
+
+	*ps.DefaultCapabilities = "some"
+	require.NotNil(t, ps.isValid())
+	*ps.DefaultCapabilities = PLUGIN_SETTINGS_DEFAULT_CAPABILITIES_NONE
+	require.Nil(t, ps.isValid())
+	*ps.DefaultCapabilities = PLUGIN_SETTINGS_DEFAULT_CAPABILITIES_ALL
+	require.Nil(t, ps.isValid())
+
+	ps.PluginTeamRestrictions["com.example.plugin"] = []string{"notateamid"}
+	require.NotNil(t, ps.isValid())
+	ps.PluginTeamRestrictions["com.example.plugin"] = []string{NewId()}
+	require.Nil(t, ps.isValid())
+}
@@ -8,13 +8,30 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"gopkg.in/yaml.v2"
 )
 
+const (
+	PLUGIN_ID_MIN_LENGTH = 3
+	PLUGIN_ID_MAX_LENGTH = 190
+)
+
+var (
+	// validPluginManifestId matches the ids accepted for newly installed plugins. They are used as
+	// directory names, so may not begin with a period, and are lowercased when enabling plugins.
+	validPluginManifestId = regexp.MustCompile(`^[a-z0-9][a-z0-9\-_\.]*$`)
+
+	// validPluginVersion matches semantic versions, such as 1.2.3 or 1.2.3-rc1+build.5.
+	validPluginVersion = regexp.MustCompile(`^(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(-[0-9A-Za-z\-\.]+)?(\+[0-9A-Za-z\-\.]+)?$`)
+)
+
 type PluginOption struct {
 	// The display name for the option.
 	DisplayName string `json:"display_name" yaml:"display_name"`
@@ -63,6 +80,12 @@ type PluginSetting struct {
 	// For "radio" or "dropdown" settings, this is the list of pre-defined options that the user can choose
 	// from.
 	Options []*PluginOption `json:"options,omitempty" yaml:"options,omitempty"`
+
+	// Secret marks "text" and "generated" settings, such as passwords and API tokens, that are never
+	// returned through the API once saved. They are stored encrypted when
+	// PluginSettings.SettingsEncryptionKey is configured, and only the plugin itself is given them
+	// decrypted, through LoadPluginConfiguration.
+	Secret bool `json:"secret,omitempty" yaml:"secret,omitempty"`
 }
 
 type PluginSettingsSchema struct {
@@ -96,7 +119,7 @@ type PluginSettingsSchema struct {
 //               default: false
 type Manifest struct {
 	// The id is a globally unique identifier that represents your plugin. Ids must be at least
-	// 3 characters, at most 190 characters and must match ^[a-zA-Z0-9-_\.]+$.
+	// 3 characters, at most 190 characters and must match ^[a-z0-9][a-z0-9-_\.]*$.
 	// Reverse-DNS notation using a name you control is a good option, e.g. "com.mycompany.myplugin".
 	Id string `json:"id" yaml:"id"`
 
@@ -106,9 +129,24 @@ type Manifest struct {
 	// A description of what your plugin is and does.
 	Description string `json:"description,omitempty" yaml:"description,omitempty"`
 
-	// A version number for your plugin. Semantic versioning is recommended: http://semver.org
+	// A version number for your plugin, which must be a semantic version if set: http://semver.org
 	Version string `json:"version" yaml:"version"`
 
+	// The oldest server version your plugin runs on, as a semantic version such as "5.1.0". Servers
+	// older than this refuse to install or activate your plugin.
+	MinServerVersion string `json:"min_server_version,omitempty" yaml:"min_server_version,omitempty"`
+
+	// The version of the layout of the data your plugin keeps in the key-value store. Whenever it
+	// is increased, the OnMigrate hook is invoked exactly once across the cluster during the next
+	// activation of your plugin.
+	SchemaVersion int `json:"schema_version,omitempty" yaml:"schema_version,omitempty"`
+
+	// If set, the server-side portion of your plugin is reported as starting while its OnActivate hook
+	// runs, rather than as not installed. Meanwhile, HTTP requests to your plugin are answered with
+	// 503 Service Unavailable and hooks are queued, to be invoked in order once OnActivate succeeds.
+	// Recommended for plugins that take a long time to activate.
+	WarmStart bool `json:"warm_start,omitempty" yaml:"warm_start,omitempty"`
+
 	// Server defines the server-side portion of your plugin.
 	Server *ManifestServer `json:"server,omitempty" yaml:"server,omitempty"`
 
@@ -121,6 +159,68 @@ type Manifest struct {
 	// To allow administrators to configure your plugin via the Mattermost system console, you can
 	// provide your settings schema.
 	SettingsSchema *PluginSettingsSchema `json:"settings_schema,omitempty" yaml:"settings_schema,omitempty"`
+
+	// The security headers, such as Content-Security-Policy, that your plugin sets on responses to
+	// its HTTP requests. Headers the server protects are otherwise removed from those responses.
+	SecurityHeaders []string `json:"security_headers,omitempty" yaml:"security_headers,omitempty"`
+
+	// The path to an OpenAPI document in JSON describing the HTTP API your plugin serves under
+	// /plugins/{id}. This should be relative to the root of your bundle and the location of the
+	// manifest file. The server makes it available so that your API can be discovered.
+	ApiSpec string `json:"api_spec,omitempty" yaml:"api_spec,omitempty"`
+
+	// The capabilities of the plugin API your plugin uses, such as "posts:write" or "kv", shown to
+	// administrators when installing it. Calls to the plugin API outside of them fail with a
+	// permission error. Plugins that leave this unset are granted the capabilities configured in the
+	// server's PluginSettings.DefaultCapabilities, while an empty list grants none.
+	Capabilities []string `json:"capabilities" yaml:"capabilities"`
+
+	// Options for the HTTP routes your plugin serves under /plugins/{id}. Routes need only be
+	// listed here to opt into server-side handling, such as replay protection for webhooks.
+	Routes []*ManifestRoute `json:"routes,omitempty" yaml:"routes,omitempty"`
+}
+
+type ManifestRoute struct {
+	// Path is the path of the route under /plugins/{id}, such as "/webhook". A path ending in "/*"
+	// also matches every path beneath it.
+	Path string `json:"path" yaml:"path"`
+
+	// IdempotencyHeader names a request header identifying each delivery to the route, such as
+	// "X-Delivery-Id". If set, your plugin only handles the first request with a given value of
+	// the header, and repeated deliveries are answered with the status code of the first. Requests
+	// without the header are always handled. Deliveries your plugin fails to handle with a 5xx
+	// status code are forgotten, so that they are handled again when retried.
+	IdempotencyHeader string `json:"idempotency_header,omitempty" yaml:"idempotency_header,omitempty"`
+
+	// IdempotencyTTLSeconds is how long deliveries are remembered for, defaulting to a day. The
+	// server also only remembers a bounded number of the most recent deliveries to each plugin.
+	IdempotencyTTLSeconds int `json:"idempotency_ttl_seconds,omitempty" yaml:"idempotency_ttl_seconds,omitempty"`
+
+	// IdempotencyPendingTTLSeconds is how long a delivery your plugin is still handling is held
+	// for, defaulting to five minutes. Repeated deliveries are asked to retry until it is handled,
+	// or, should the server fail while handling it, until it is released and handled again.
+	IdempotencyPendingTTLSeconds int `json:"idempotency_pending_ttl_seconds,omitempty" yaml:"idempotency_pending_ttl_seconds,omitempty"`
+
+	// MaxBodySize is the largest request body, in bytes, your plugin accepts on the route, either
+	// higher or lower than the server's PluginSettings.MaxRequestBodySize used by default. Larger
+	// requests are rejected with a 413 status code before your plugin handles them.
+	MaxBodySize int64 `json:"max_body_size,omitempty" yaml:"max_body_size,omitempty"`
+
+	// RequireSession rejects requests to the route without a valid session with a 401 status code
+	// before your plugin handles them, so that the Mattermost-User-Id header is always set on the
+	// requests your plugin receives. Routes that do not require a session, such as webhooks, are
+	// still served without cookies or credentials.
+	RequireSession bool `json:"require_session,omitempty" yaml:"require_session,omitempty"`
+}
+
+// Matches returns true if the route applies to the given path under /plugins/{id}.
+func (r *ManifestRoute) Matches(path string) bool {
+	if strings.HasSuffix(r.Path, "/*") {
+		prefix := strings.TrimSuffix(r.Path, "*")
+		return path == strings.TrimSuffix(prefix, "/") || strings.HasPrefix(path, prefix)
+	}
+
+	return path == r.Path
 }
 
 type ManifestServer struct {
@@ -155,6 +255,107 @@ type ManifestWebapp struct {
 
 	// BundleHash is the 64-bit FNV-1a hash of the webapp bundle, computed when the plugin is loaded
 	BundleHash []byte `json:"-"`
+
+	// The external origins your webapp loads resources from, by Content-Security-Policy directive,
+	// such as {"frame-src": ["https://www.youtube.com"]}. They are shown to administrators when
+	// enabling your plugin, and only allowed while it is active if the server's
+	// PluginSettings.AllowPluginCSPExtensions is enabled.
+	ContentSecurityPolicy map[string][]string `json:"content_security_policy,omitempty" yaml:"content_security_policy,omitempty"`
+}
+
+// IsValid checks that the manifest identifies the plugin, that its version, if any, is a semantic
+// version, and that it has a server or webapp component at paths within the plugin's bundle. Whether
+// those files exist is only checked when installing the plugin.
+func (m *Manifest) IsValid() *AppError {
+	if utf8.RuneCountInString(m.Id) < PLUGIN_ID_MIN_LENGTH || utf8.RuneCountInString(m.Id) > PLUGIN_ID_MAX_LENGTH || !validPluginManifestId.MatchString(m.Id) {
+		return NewAppError("Manifest.IsValid", "model.manifest.is_valid.id.app_error", map[string]interface{}{"Min": PLUGIN_ID_MIN_LENGTH, "Max": PLUGIN_ID_MAX_LENGTH}, "id="+m.Id, http.StatusBadRequest)
+	}
+
+	if m.Version != "" && !validPluginVersion.MatchString(m.Version) {
+		return NewAppError("Manifest.IsValid", "model.manifest.is_valid.version.app_error", map[string]interface{}{"Version": m.Version}, "id="+m.Id, http.StatusBadRequest)
+	}
+
+	if m.MinServerVersion != "" && !validPluginVersion.MatchString(m.MinServerVersion) {
+		return NewAppError("Manifest.IsValid", "model.manifest.is_valid.min_server_version.app_error", map[string]interface{}{"Version": m.MinServerVersion}, "id="+m.Id, http.StatusBadRequest)
+	}
+
+	if !m.HasServer() && !m.HasWebapp() {
+		return NewAppError("Manifest.IsValid", "model.manifest.is_valid.components.app_error", nil, "id="+m.Id, http.StatusBadRequest)
+	}
+
+	if m.HasServer() {
+		server := m.Server
+		if server == nil {
+			server = m.Backend
+		}
+
+		executables := []string{server.Executable}
+		if server.Executables != nil {
+			executables = append(executables, server.Executables.LinuxAmd64, server.Executables.DarwinAmd64, server.Executables.WindowsAmd64)
+		}
+
+		found := false
+		for _, executable := range executables {
+			if executable == "" {
+				continue
+			}
+			found = true
+
+			if !isValidManifestPath(executable) {
+				return NewAppError("Manifest.IsValid", "model.manifest.is_valid.executable.app_error", map[string]interface{}{"Path": executable}, "id="+m.Id, http.StatusBadRequest)
+			}
+		}
+
+		if !found {
+			return NewAppError("Manifest.IsValid", "model.manifest.is_valid.executable.app_error", map[string]interface{}{"Path": ""}, "id="+m.Id, http.StatusBadRequest)
+		}
+	}
+
+	if m.HasWebapp() && !isValidManifestPath(m.Webapp.BundlePath) {
+		return NewAppError("Manifest.IsValid", "model.manifest.is_valid.webapp_bundle.app_error", map[string]interface{}{"Path": m.Webapp.BundlePath}, "id="+m.Id, http.StatusBadRequest)
+	}
+
+	for _, route := range m.Routes {
+		if route != nil && route.MaxBodySize < 0 {
+			return NewAppError("Manifest.IsValid", "model.manifest.is_valid.route_max_body_size.app_error", map[string]interface{}{"Path": route.Path}, "id="+m.Id, http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
+// MeetsMinServerVersion returns whether the given server version is at least the plugin's
+// MinServerVersion, if any. Prerelease and build suffixes are ignored, so that release candidates
+// of a version satisfy plugins requiring it.
+func (m *Manifest) MeetsMinServerVersion(serverVersion string) bool {
+	if m.MinServerVersion == "" {
+		return true
+	}
+
+	serverMajor, serverMinor, serverPatch := SplitVersion(trimVersionSuffix(serverVersion))
+	minMajor, minMinor, minPatch := SplitVersion(trimVersionSuffix(m.MinServerVersion))
+
+	if serverMajor != minMajor {
+		return serverMajor > minMajor
+	}
+	if serverMinor != minMinor {
+		return serverMinor > minMinor
+	}
+	return serverPatch >= minPatch
+}
+
+// trimVersionSuffix removes any prerelease or build suffix from a semantic version.
+func trimVersionSuffix(version string) string {
+	if i := strings.IndexAny(version, "-+"); i != -1 {
+		return version[:i]
+	}
+	return version
+}
+
+// isValidManifestPath returns whether the path is that of a file within the plugin's bundle.
+func isValidManifestPath(path string) bool {
+	path = filepath.Clean(path)
+	return path != "." && !filepath.IsAbs(path) && path != ".." && !strings.HasPrefix(path, ".."+string(filepath.Separator))
 }
 
 func (m *Manifest) ToJson() string {
@@ -189,6 +390,8 @@ func (m *Manifest) ClientManifest() *Manifest {
 	cm.Name = ""
 	cm.Description = ""
 	cm.Server = nil
+	cm.ApiSpec = ""
+	cm.Routes = nil
 	if cm.Webapp != nil {
 		cm.Webapp = new(ManifestWebapp)
 		*cm.Webapp = *m.Webapp
@@ -232,14 +435,46 @@ func (m *Manifest) GetExecutableForRuntime(goOs, goArch string) string {
 	return executable
 }
 
+// GetRoute returns the options for the route serving the given path under /plugins/{id}, or nil if
+// none are declared. The first matching route is used.
+func (m *Manifest) GetRoute(path string) *ManifestRoute {
+	for _, route := range m.Routes {
+		if route != nil && route.Matches(path) {
+			return route
+		}
+	}
+
+	return nil
+}
+
 func (m *Manifest) HasServer() bool {
 	return m.Server != nil || m.Backend != nil
 }
 
+func (m *Manifest) HasApiSpec() bool {
+	return m.ApiSpec != ""
+}
+
 func (m *Manifest) HasWebapp() bool {
 	return m.Webapp != nil
 }
 
+// DeclaresCapabilities returns whether the manifest lists the capabilities of the plugin API used
+// by the plugin, even if none.
+func (m *Manifest) DeclaresCapabilities() bool {
+	return m.Capabilities != nil
+}
+
+// HasCapability returns whether the manifest declares the given capability.
+func (m *Manifest) HasCapability(capability string) bool {
+	for _, c := range m.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
 // FindManifest will find and parse the manifest in a given directory.
 //
 // In all cases other than a does-not-exist error, path is set to the path of the manifest file that was
@@ -6,6 +6,7 @@ package model
 import (
 	"encoding/json"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -63,7 +64,8 @@ func TestFindManifest(t *testing.T) {
 
 func TestManifestUnmarshal(t *testing.T) {
 	expected := Manifest{
-		Id: "theid",
+		Id:               "theid",
+		MinServerVersion: "5.1.0",
 		Server: &ManifestServer{
 			Executable: "theexecutable",
 			Executables: &ManifestExecutables{
@@ -94,6 +96,11 @@ func TestManifestUnmarshal(t *testing.T) {
 					},
 					Default: "thedefault",
 				},
+				&PluginSetting{
+					Key:    "thesecret",
+					Type:   "text",
+					Secret: true,
+				},
 			},
 		},
 	}
@@ -101,6 +108,7 @@ func TestManifestUnmarshal(t *testing.T) {
 	var yamlResult Manifest
 	require.NoError(t, yaml.Unmarshal([]byte(`
 id: theid
+min_server_version: 5.1.0
 server:
     executable: theexecutable
     executables:
@@ -123,12 +131,16 @@ settings_schema:
               - display_name: theoptiondisplayname
                 value: thevalue
           default: thedefault
+        - key: thesecret
+          type: text
+          secret: true
 `), &yamlResult))
 	assert.Equal(t, expected, yamlResult)
 
 	var jsonResult Manifest
 	require.NoError(t, json.Unmarshal([]byte(`{
 	"id": "theid",
+	"min_server_version": "5.1.0",
 	"server": {
 		"executable": "theexecutable",
 		"executables": {
@@ -158,6 +170,11 @@ settings_schema:
 					}
 				],
 				"default": "thedefault"
+			},
+			{
+				"key": "thesecret",
+				"type": "text",
+				"secret": true
 			}
 		]
     }
@@ -594,3 +611,121 @@ func TestManifestHasWebapp(t *testing.T) {
 		})
 	}
 }
+
+func TestManifestIsValid(t *testing.T) {
+	webapp := &ManifestWebapp{BundlePath: "webapp/main.js"}
+	server := &ManifestServer{Executable: "server/plugin"}
+
+	for name, tc := range map[string]struct {
+		Manifest *Manifest
+		Error    string
+	}{
+		"valid webapp":                 {&Manifest{Id: "com.example.plugin", Version: "1.2.3", Webapp: webapp}, ""},
+		"valid server":                 {&Manifest{Id: "my_plugin-2", Server: server}, ""},
+		"valid deprecated backend":     {&Manifest{Id: "myplugin", Backend: server}, ""},
+		"valid platform executables":   {&Manifest{Id: "myplugin", Server: &ManifestServer{Executables: &ManifestExecutables{LinuxAmd64: "server/plugin-linux-amd64"}}}, ""},
+		"valid prerelease version":     {&Manifest{Id: "myplugin", Version: "1.0.0-rc1+build.5", Webapp: webapp}, ""},
+		"empty id":                     {&Manifest{Webapp: webapp}, "model.manifest.is_valid.id.app_error"},
+		"short id":                     {&Manifest{Id: "ab", Webapp: webapp}, "model.manifest.is_valid.id.app_error"},
+		"long id":                      {&Manifest{Id: strings.Repeat("a", PLUGIN_ID_MAX_LENGTH+1), Webapp: webapp}, "model.manifest.is_valid.id.app_error"},
+		"uppercase id":                 {&Manifest{Id: "MyPlugin", Webapp: webapp}, "model.manifest.is_valid.id.app_error"},
+		"id traversing upward":         {&Manifest{Id: "../plugin", Webapp: webapp}, "model.manifest.is_valid.id.app_error"},
+		"hidden id":                    {&Manifest{Id: ".myplugin", Webapp: webapp}, "model.manifest.is_valid.id.app_error"},
+		"invalid version":              {&Manifest{Id: "myplugin", Version: "1.0", Webapp: webapp}, "model.manifest.is_valid.version.app_error"},
+		"prefixed version":             {&Manifest{Id: "myplugin", Version: "v1.0.0", Webapp: webapp}, "model.manifest.is_valid.version.app_error"},
+		"valid min server version":     {&Manifest{Id: "myplugin", MinServerVersion: "5.1.0-rc1", Webapp: webapp}, ""},
+		"invalid min server version":   {&Manifest{Id: "myplugin", MinServerVersion: "5.1", Webapp: webapp}, "model.manifest.is_valid.min_server_version.app_error"},
+		"no components":                {&Manifest{Id: "myplugin"}, "model.manifest.is_valid.components.app_error"},
+		"no executable":                {&Manifest{Id: "myplugin", Server: &ManifestServer{}}, "model.manifest.is_valid.executable.app_error"},
+		"absolute executable":          {&Manifest{Id: "myplugin", Server: &ManifestServer{Executable: "/bin/sh"}}, "model.manifest.is_valid.executable.app_error"},
+		"executable traversing upward": {&Manifest{Id: "myplugin", Server: &ManifestServer{Executables: &ManifestExecutables{DarwinAmd64: "../plugin"}}}, "model.manifest.is_valid.executable.app_error"},
+		"no webapp bundle":             {&Manifest{Id: "myplugin", Webapp: &ManifestWebapp{}}, "model.manifest.is_valid.webapp_bundle.app_error"},
+		"bundle traversing upward":     {&Manifest{Id: "myplugin", Webapp: &ManifestWebapp{BundlePath: "webapp/../../main.js"}}, "model.manifest.is_valid.webapp_bundle.app_error"},
+		"route max body size":          {&Manifest{Id: "myplugin", Server: server, Routes: []*ManifestRoute{{Path: "/upload", MaxBodySize: 1024}}}, ""},
+		"negative route max body size": {&Manifest{Id: "myplugin", Server: server, Routes: []*ManifestRoute{{Path: "/upload", MaxBodySize: -1}}}, "model.manifest.is_valid.route_max_body_size.app_error"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.Manifest.IsValid()
+			if tc.Error == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Equal(t, tc.Error, err.Id)
+				assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+			}
+		})
+	}
+}
+
+func TestManifestMeetsMinServerVersion(t *testing.T) {
+	for _, tc := range []struct {
+		MinServerVersion string
+		ServerVersion    string
+		Expected         bool
+	}{
+		{"", "5.1.0", true},
+		{"5.1.0", "5.1.0", true},
+		{"5.0.9", "5.1.0", true},
+		{"4.10.0", "5.1.0", true},
+		{"5.1.1", "5.1.0", false},
+		{"5.2.0", "5.1.0", false},
+		{"6.0.0", "5.1.0", false},
+		{"5.1.0-rc1", "5.1.0", true},
+		{"5.1.0", "5.1.0-rc1", true},
+		{"5.1.0+build.5", "5.1.0", true},
+		{"5.2.0", "5.2.0-rc1", true},
+		{"5.2.0", "5.1.0-rc1", false},
+	} {
+		m := &Manifest{Id: "myplugin", MinServerVersion: tc.MinServerVersion}
+		assert.Equal(t, tc.Expected, m.MeetsMinServerVersion(tc.ServerVersion), "%v on %v", tc.MinServerVersion, tc.ServerVersion)
+	}
+}
+
+func TestManifestCapabilities(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Yaml     string
+		Json     string
+		Declared bool
+	}{
+		"undeclared": {"id: theid\n", `{"id": "theid"}`, false},
+		"null":       {"id: theid\ncapabilities:\n", `{"id": "theid", "capabilities": null}`, false},
+		"none":       {"id: theid\ncapabilities: []\n", `{"id": "theid", "capabilities": []}`, true},
+		"some":       {"id: theid\ncapabilities: [kv, posts:write]\n", `{"id": "theid", "capabilities": ["kv", "posts:write"]}`, true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var yamlResult Manifest
+			require.NoError(t, yaml.Unmarshal([]byte(tc.Yaml), &yamlResult))
+			assert.Equal(t, tc.Declared, yamlResult.DeclaresCapabilities())
+
+			var jsonResult Manifest
+			require.NoError(t, json.Unmarshal([]byte(tc.Json), &jsonResult))
+			assert.Equal(t, tc.Declared, jsonResult.DeclaresCapabilities())
+			assert.Equal(t, yamlResult.Capabilities, jsonResult.Capabilities)
+
+			// Declaring no capabilities must survive being sent to administrators.
+			assert.Equal(t, tc.Declared, ManifestFromJson(strings.NewReader(jsonResult.ToJson())).DeclaresCapabilities())
+
+			assert.Equal(t, name == "some", jsonResult.HasCapability(PLUGIN_CAPABILITY_KV))
+			assert.False(t, jsonResult.HasCapability(PLUGIN_CAPABILITY_USERS_WRITE))
+		})
+	}
+}
+
+func TestManifestGetRoute(t *testing.T) {
+	webhook := &ManifestRoute{Path: "/webhook", IdempotencyHeader: "X-Delivery-Id"}
+	events := &ManifestRoute{Path: "/events/*", IdempotencyHeader: "X-Event-Id"}
+	manifest := &Manifest{
+		Routes: []*ManifestRoute{webhook, events},
+	}
+
+	assert.Equal(t, webhook, manifest.GetRoute("/webhook"))
+	assert.Nil(t, manifest.GetRoute("/webhook/other"))
+	assert.Nil(t, manifest.GetRoute("/webhooks"))
+
+	assert.Equal(t, events, manifest.GetRoute("/events"))
+	assert.Equal(t, events, manifest.GetRoute("/events/"))
+	assert.Equal(t, events, manifest.GetRoute("/events/github/push"))
+	assert.Nil(t, manifest.GetRoute("/eventsource"))
+
+	assert.Nil(t, (&Manifest{}).GetRoute("/webhook"))
+	assert.Nil(t, manifest.ClientManifest().Routes)
+}
@@ -5,4 +5,8 @@ package model
 
 const (
 	MIGRATION_KEY_ADVANCED_PERMISSIONS_PHASE_2 = "migration_advanced_permissions_phase_2"
+
+	// MIGRATION_KEY_PLUGIN_KEY_VALUE_HASHED_KEYS is recorded once no plugin keys are left stored as
+	// their hash, after which they are no longer looked up there.
+	MIGRATION_KEY_PLUGIN_KEY_VALUE_HASHED_KEYS = "migration_plugin_key_value_hashed_keys"
 )
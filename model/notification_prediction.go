@@ -0,0 +1,21 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+)
+
+// NotificationPrediction describes which notifications a user would receive for a post in a channel,
+// given their current status and notification preferences.
+type NotificationPrediction struct {
+	Desktop bool `json:"desktop"`
+	Push    bool `json:"push"`
+	Email   bool `json:"email"`
+}
+
+func (p *NotificationPrediction) ToJson() string {
+	b, _ := json.Marshal(p)
+	return string(b)
+}
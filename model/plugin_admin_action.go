@@ -0,0 +1,167 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+// The privileged actions that plugins may ask a system admin to approve. Only these actions can be
+// requested, and the server alone decides how each is carried out.
+const (
+	// PLUGIN_ADMIN_ACTION_DELETE_USER permanently deletes the user given by the user_id parameter.
+	PLUGIN_ADMIN_ACTION_DELETE_USER = "delete_user"
+
+	// PLUGIN_ADMIN_ACTION_UPDATE_CONFIG merges the JSON object given by the config parameter into
+	// the server's configuration.
+	PLUGIN_ADMIN_ACTION_UPDATE_CONFIG = "update_config"
+)
+
+const (
+	PLUGIN_ADMIN_ACTION_PARAM_USER_ID = "user_id"
+	PLUGIN_ADMIN_ACTION_PARAM_CONFIG  = "config"
+)
+
+const (
+	PLUGIN_ADMIN_ACTION_STATUS_PENDING  = "pending"
+	PLUGIN_ADMIN_ACTION_STATUS_APPROVED = "approved"
+	PLUGIN_ADMIN_ACTION_STATUS_DENIED   = "denied"
+	PLUGIN_ADMIN_ACTION_STATUS_EXPIRED  = "expired"
+
+	// PLUGIN_ADMIN_ACTION_STATUS_FAILED is recorded when an approved action could not be carried
+	// out.
+	PLUGIN_ADMIN_ACTION_STATUS_FAILED = "failed"
+)
+
+const (
+	PLUGIN_ADMIN_ACTION_DESCRIPTION_MAX_RUNES = 1024
+	PLUGIN_ADMIN_ACTION_PARAMS_MAX_LENGTH     = 16 * 1024
+	PLUGIN_ADMIN_ACTION_ERROR_MAX_LENGTH      = 1024
+)
+
+var pluginAdminActionParams = map[string][]string{
+	PLUGIN_ADMIN_ACTION_DELETE_USER:   {PLUGIN_ADMIN_ACTION_PARAM_USER_ID},
+	PLUGIN_ADMIN_ACTION_UPDATE_CONFIG: {PLUGIN_ADMIN_ACTION_PARAM_CONFIG},
+}
+
+// PluginAdminActionRequest is a plugin's request for a privileged action to be carried out on its
+// behalf once a system admin approves it.
+type PluginAdminActionRequest struct {
+	Id       string    `json:"id"`
+	PluginId string    `json:"plugin_id"`
+	Action   string    `json:"action"`
+	Params   StringMap `json:"params"`
+
+	// Description is shown to system admins to explain why the plugin needs the action.
+	Description string `json:"description"`
+
+	Status   string `json:"status"`
+	CreateAt int64  `json:"create_at"`
+
+	// ExpireAt is the time, in milliseconds, after which the request can no longer be approved.
+	ExpireAt int64 `json:"expire_at"`
+
+	ResolvedBy string `json:"resolved_by,omitempty"`
+	ResolveAt  int64  `json:"resolve_at,omitempty"`
+
+	// Error describes why an approved action could not be carried out.
+	Error string `json:"error,omitempty"`
+}
+
+func (r *PluginAdminActionRequest) ToJson() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func PluginAdminActionRequestFromJson(data io.Reader) *PluginAdminActionRequest {
+	var r *PluginAdminActionRequest
+	json.NewDecoder(data).Decode(&r)
+	return r
+}
+
+func PluginAdminActionRequestListToJson(l []*PluginAdminActionRequest) string {
+	b, _ := json.Marshal(l)
+	return string(b)
+}
+
+func PluginAdminActionRequestListFromJson(data io.Reader) []*PluginAdminActionRequest {
+	var l []*PluginAdminActionRequest
+	json.NewDecoder(data).Decode(&l)
+	return l
+}
+
+func (r *PluginAdminActionRequest) PreSave() {
+	if r.Id == "" {
+		r.Id = NewId()
+	}
+
+	if r.CreateAt == 0 {
+		r.CreateAt = GetMillis()
+	}
+
+	if r.Status == "" {
+		r.Status = PLUGIN_ADMIN_ACTION_STATUS_PENDING
+	}
+}
+
+// IsPending returns whether the request is still waiting for a system admin as of the given time in
+// milliseconds.
+func (r *PluginAdminActionRequest) IsPending(now int64) bool {
+	return r.Status == PLUGIN_ADMIN_ACTION_STATUS_PENDING && now < r.ExpireAt
+}
+
+func (r *PluginAdminActionRequest) IsValid() *AppError {
+	if !IsValidId(r.Id) {
+		return NewAppError("PluginAdminActionRequest.IsValid", "model.plugin_admin_action_request.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(r.PluginId) == 0 || utf8.RuneCountInString(r.PluginId) > KEY_VALUE_PLUGIN_ID_MAX_RUNES {
+		return NewAppError("PluginAdminActionRequest.IsValid", "model.plugin_admin_action_request.is_valid.plugin_id.app_error", nil, "id="+r.Id, http.StatusBadRequest)
+	}
+
+	params, ok := pluginAdminActionParams[r.Action]
+	if !ok {
+		return NewAppError("PluginAdminActionRequest.IsValid", "model.plugin_admin_action_request.is_valid.action.app_error", map[string]interface{}{"Action": r.Action}, "id="+r.Id, http.StatusBadRequest)
+	}
+
+	if len(r.Params) != len(params) || len(MapToJson(r.Params)) > PLUGIN_ADMIN_ACTION_PARAMS_MAX_LENGTH {
+		return NewAppError("PluginAdminActionRequest.IsValid", "model.plugin_admin_action_request.is_valid.params.app_error", map[string]interface{}{"Action": r.Action}, "id="+r.Id, http.StatusBadRequest)
+	}
+	for _, param := range params {
+		if len(r.Params[param]) == 0 {
+			return NewAppError("PluginAdminActionRequest.IsValid", "model.plugin_admin_action_request.is_valid.params.app_error", map[string]interface{}{"Action": r.Action}, "id="+r.Id, http.StatusBadRequest)
+		}
+	}
+
+	if utf8.RuneCountInString(r.Description) > PLUGIN_ADMIN_ACTION_DESCRIPTION_MAX_RUNES {
+		return NewAppError("PluginAdminActionRequest.IsValid", "model.plugin_admin_action_request.is_valid.description.app_error", map[string]interface{}{"Max": PLUGIN_ADMIN_ACTION_DESCRIPTION_MAX_RUNES}, "id="+r.Id, http.StatusBadRequest)
+	}
+
+	switch r.Status {
+	case PLUGIN_ADMIN_ACTION_STATUS_PENDING, PLUGIN_ADMIN_ACTION_STATUS_APPROVED, PLUGIN_ADMIN_ACTION_STATUS_DENIED, PLUGIN_ADMIN_ACTION_STATUS_EXPIRED, PLUGIN_ADMIN_ACTION_STATUS_FAILED:
+	default:
+		return NewAppError("PluginAdminActionRequest.IsValid", "model.plugin_admin_action_request.is_valid.status.app_error", nil, "id="+r.Id, http.StatusBadRequest)
+	}
+
+	if r.CreateAt == 0 {
+		return NewAppError("PluginAdminActionRequest.IsValid", "model.plugin_admin_action_request.is_valid.create_at.app_error", nil, "id="+r.Id, http.StatusBadRequest)
+	}
+
+	if r.ExpireAt <= r.CreateAt {
+		return NewAppError("PluginAdminActionRequest.IsValid", "model.plugin_admin_action_request.is_valid.expire_at.app_error", nil, "id="+r.Id, http.StatusBadRequest)
+	}
+
+	if len(r.ResolvedBy) > 0 && !IsValidId(r.ResolvedBy) {
+		return NewAppError("PluginAdminActionRequest.IsValid", "model.plugin_admin_action_request.is_valid.resolved_by.app_error", nil, "id="+r.Id, http.StatusBadRequest)
+	}
+
+	if len(r.Error) > PLUGIN_ADMIN_ACTION_ERROR_MAX_LENGTH {
+		return NewAppError("PluginAdminActionRequest.IsValid", "model.plugin_admin_action_request.is_valid.error.app_error", nil, "id="+r.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
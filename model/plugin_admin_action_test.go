@@ -0,0 +1,84 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginAdminActionRequestIsValid(t *testing.T) {
+	r := &PluginAdminActionRequest{
+		PluginId: "com.example.plugin",
+		Action:   PLUGIN_ADMIN_ACTION_DELETE_USER,
+		Params:   StringMap{PLUGIN_ADMIN_ACTION_PARAM_USER_ID: NewId()},
+	}
+	assert.NotNil(t, r.IsValid())
+
+	r.PreSave()
+	r.ExpireAt = r.CreateAt + 60*1000
+	require.Nil(t, r.IsValid())
+	assert.Equal(t, PLUGIN_ADMIN_ACTION_STATUS_PENDING, r.Status)
+
+	r.PluginId = ""
+	assert.NotNil(t, r.IsValid())
+	r.PluginId = "com.example.plugin"
+
+	// Only actions that the server knows how to carry out may be requested.
+	r.Action = "execute_command"
+	assert.NotNil(t, r.IsValid())
+	r.Action = PLUGIN_ADMIN_ACTION_DELETE_USER
+
+	r.Params = StringMap{}
+	assert.NotNil(t, r.IsValid())
+	r.Params = StringMap{PLUGIN_ADMIN_ACTION_PARAM_CONFIG: "{}"}
+	assert.NotNil(t, r.IsValid())
+	r.Params = StringMap{PLUGIN_ADMIN_ACTION_PARAM_USER_ID: NewId(), "other": "value"}
+	assert.NotNil(t, r.IsValid())
+	r.Params = StringMap{PLUGIN_ADMIN_ACTION_PARAM_USER_ID: NewId()}
+
+	r.Description = strings.Repeat("a", PLUGIN_ADMIN_ACTION_DESCRIPTION_MAX_RUNES+1)
+	assert.NotNil(t, r.IsValid())
+	r.Description = "Remove a departed user"
+
+	r.Status = "unknown"
+	assert.NotNil(t, r.IsValid())
+	r.Status = PLUGIN_ADMIN_ACTION_STATUS_APPROVED
+
+	r.ExpireAt = r.CreateAt
+	assert.NotNil(t, r.IsValid())
+	r.ExpireAt = r.CreateAt + 60*1000
+
+	r.ResolvedBy = "admin"
+	assert.NotNil(t, r.IsValid())
+	r.ResolvedBy = NewId()
+
+	assert.Nil(t, r.IsValid())
+}
+
+func TestPluginAdminActionRequestIsPending(t *testing.T) {
+	now := GetMillis()
+
+	r := &PluginAdminActionRequest{Status: PLUGIN_ADMIN_ACTION_STATUS_PENDING, ExpireAt: now + 1000}
+	assert.True(t, r.IsPending(now))
+	assert.False(t, r.IsPending(now+1000))
+
+	r.Status = PLUGIN_ADMIN_ACTION_STATUS_DENIED
+	assert.False(t, r.IsPending(now))
+}
+
+func TestPluginAdminActionRequestJson(t *testing.T) {
+	r := &PluginAdminActionRequest{
+		Id:       NewId(),
+		PluginId: "com.example.plugin",
+		Action:   PLUGIN_ADMIN_ACTION_DELETE_USER,
+		Params:   StringMap{PLUGIN_ADMIN_ACTION_PARAM_USER_ID: NewId()},
+	}
+
+	assert.Equal(t, r, PluginAdminActionRequestFromJson(strings.NewReader(r.ToJson())))
+	assert.Equal(t, []*PluginAdminActionRequest{r}, PluginAdminActionRequestListFromJson(strings.NewReader(PluginAdminActionRequestListToJson([]*PluginAdminActionRequest{r}))))
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// openApiMethods are the operations an OpenAPI path item may declare, in the order listed.
+var openApiMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// PluginApiSpec summarizes the HTTP API a plugin declares in the OpenAPI document it ships.
+type PluginApiSpec struct {
+	PluginId string            `json:"plugin_id"`
+	Routes   []*PluginApiRoute `json:"routes"`
+}
+
+// PluginApiRoute is an operation declared by a plugin, with its path relative to /plugins/{id}.
+type PluginApiRoute struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// PluginApiRoutesFromOpenApi checks that the given data is an OpenAPI or Swagger document in JSON,
+// returning the operations it declares ordered by path and then method.
+func PluginApiRoutesFromOpenApi(data []byte) ([]*PluginApiRoute, error) {
+	var doc struct {
+		OpenApi string                                `json:"openapi"`
+		Swagger string                                `json:"swagger"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	if doc.OpenApi == "" && doc.Swagger == "" {
+		return nil, errors.New("missing openapi version")
+	}
+
+	if doc.Paths == nil {
+		return nil, errors.New("missing paths")
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("path %q does not begin with /", path)
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	routes := []*PluginApiRoute{}
+	for _, path := range paths {
+		for _, method := range openApiMethods {
+			raw, ok := doc.Paths[path][method]
+			if !ok {
+				continue
+			}
+
+			var operation struct {
+				Summary string `json:"summary"`
+			}
+			if err := json.Unmarshal(raw, &operation); err != nil {
+				return nil, fmt.Errorf("invalid %s operation for path %q: %v", method, path, err)
+			}
+
+			routes = append(routes, &PluginApiRoute{
+				Method:  strings.ToUpper(method),
+				Path:    path,
+				Summary: operation.Summary,
+			})
+		}
+	}
+
+	return routes, nil
+}
+
+func PluginApiSpecListToJson(specs []*PluginApiSpec) string {
+	b, _ := json.Marshal(specs)
+	return string(b)
+}
+
+func PluginApiSpecListFromJson(data io.Reader) []*PluginApiSpec {
+	var specs []*PluginApiSpec
+	json.NewDecoder(data).Decode(&specs)
+	return specs
+}
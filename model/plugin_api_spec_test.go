@@ -0,0 +1,67 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginApiRoutesFromOpenApi(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		routes, err := PluginApiRoutesFromOpenApi([]byte(`{
+			"openapi": "3.0.0",
+			"paths": {
+				"/status": {
+					"summary": "not an operation",
+					"get": {"summary": "Get the status"}
+				},
+				"/items/{id}": {
+					"parameters": [],
+					"delete": {},
+					"get": {"summary": "Get an item"}
+				}
+			}
+		}`))
+		require.Nil(t, err)
+		assert.Equal(t, []*PluginApiRoute{
+			{Method: "GET", Path: "/items/{id}", Summary: "Get an item"},
+			{Method: "DELETE", Path: "/items/{id}"},
+			{Method: "GET", Path: "/status", Summary: "Get the status"},
+		}, routes)
+	})
+
+	t.Run("swagger", func(t *testing.T) {
+		routes, err := PluginApiRoutesFromOpenApi([]byte(`{"swagger": "2.0", "paths": {}}`))
+		require.Nil(t, err)
+		assert.Empty(t, routes)
+	})
+
+	for name, data := range map[string]string{
+		"not json":          "openapi: 3.0.0",
+		"no version":        `{"paths": {}}`,
+		"no paths":          `{"openapi": "3.0.0"}`,
+		"relative path":     `{"openapi": "3.0.0", "paths": {"status": {}}}`,
+		"invalid operation": `{"openapi": "3.0.0", "paths": {"/status": {"get": []}}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := PluginApiRoutesFromOpenApi([]byte(data))
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestPluginApiSpecListJson(t *testing.T) {
+	specs := []*PluginApiSpec{
+		{
+			PluginId: "theid",
+			Routes:   []*PluginApiRoute{{Method: "GET", Path: "/status"}},
+		},
+	}
+
+	assert.Equal(t, specs, PluginApiSpecListFromJson(strings.NewReader(PluginApiSpecListToJson(specs))))
+}
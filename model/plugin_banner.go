@@ -0,0 +1,67 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"unicode/utf8"
+)
+
+const (
+	PLUGIN_BANNER_TEXT_MAX_RUNES = 1024
+)
+
+var pluginBannerColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// PluginBanner is an announcement banner registered by a plugin. It is shown to clients, including
+// on the login page, in the same place as the banner configured in the announcement settings.
+type PluginBanner struct {
+	Text string `json:"text"`
+
+	// Color and TextColor are hex colors, defaulting to those of the announcement settings.
+	Color     string `json:"color,omitempty"`
+	TextColor string `json:"text_color,omitempty"`
+
+	AllowDismissal bool `json:"allow_dismissal"`
+}
+
+func (b *PluginBanner) IsValid() *AppError {
+	if b.Text == "" || utf8.RuneCountInString(b.Text) > PLUGIN_BANNER_TEXT_MAX_RUNES {
+		return NewAppError("PluginBanner.IsValid", "model.plugin_banner.is_valid.text.app_error", map[string]interface{}{"Max": PLUGIN_BANNER_TEXT_MAX_RUNES}, "", http.StatusBadRequest)
+	}
+
+	if b.Color != "" && !pluginBannerColorPattern.MatchString(b.Color) {
+		return NewAppError("PluginBanner.IsValid", "model.plugin_banner.is_valid.color.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if b.TextColor != "" && !pluginBannerColorPattern.MatchString(b.TextColor) {
+		return NewAppError("PluginBanner.IsValid", "model.plugin_banner.is_valid.text_color.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (b *PluginBanner) SetDefaults() {
+	if b.Color == "" {
+		b.Color = ANNOUNCEMENT_SETTINGS_DEFAULT_BANNER_COLOR
+	}
+
+	if b.TextColor == "" {
+		b.TextColor = ANNOUNCEMENT_SETTINGS_DEFAULT_BANNER_TEXT_COLOR
+	}
+}
+
+func (b *PluginBanner) ToJson() string {
+	bytes, _ := json.Marshal(b)
+	return string(bytes)
+}
+
+func PluginBannerFromJson(data io.Reader) *PluginBanner {
+	var b *PluginBanner
+	json.NewDecoder(data).Decode(&b)
+	return b
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginBannerJson(t *testing.T) {
+	banner := &PluginBanner{
+		Text:           "UNCLASSIFIED//FOUO",
+		Color:          "#007a33",
+		TextColor:      "#fff",
+		AllowDismissal: true,
+	}
+
+	json := banner.ToJson()
+	assert.Equal(t, banner, PluginBannerFromJson(strings.NewReader(json)))
+	assert.Equal(t, (*PluginBanner)(nil), PluginBannerFromJson(strings.NewReader("junk")))
+}
+
+func TestPluginBannerIsValid(t *testing.T) {
+	testCases := []struct {
+		Description string
+		Banner      *PluginBanner
+		ExpectedErr string
+	}{
+		{
+			"valid",
+			&PluginBanner{Text: "text"},
+			"",
+		},
+		{
+			"valid with colors",
+			&PluginBanner{Text: "text", Color: "#007A33", TextColor: "#fff"},
+			"",
+		},
+		{
+			"empty text",
+			&PluginBanner{},
+			"model.plugin_banner.is_valid.text.app_error",
+		},
+		{
+			"text too long",
+			&PluginBanner{Text: strings.Repeat("a", PLUGIN_BANNER_TEXT_MAX_RUNES+1)},
+			"model.plugin_banner.is_valid.text.app_error",
+		},
+		{
+			"invalid color",
+			&PluginBanner{Text: "text", Color: "green"},
+			"model.plugin_banner.is_valid.color.app_error",
+		},
+		{
+			"invalid text color",
+			&PluginBanner{Text: "text", TextColor: "#ffff"},
+			"model.plugin_banner.is_valid.text_color.app_error",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Description, func(t *testing.T) {
+			err := testCase.Banner.IsValid()
+			if testCase.ExpectedErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Equal(t, testCase.ExpectedErr, err.Id)
+			}
+		})
+	}
+}
+
+func TestPluginBannerSetDefaults(t *testing.T) {
+	banner := &PluginBanner{Text: "text"}
+	banner.SetDefaults()
+	assert.Equal(t, ANNOUNCEMENT_SETTINGS_DEFAULT_BANNER_COLOR, banner.Color)
+	assert.Equal(t, ANNOUNCEMENT_SETTINGS_DEFAULT_BANNER_TEXT_COLOR, banner.TextColor)
+
+	banner = &PluginBanner{Text: "text", Color: "#000", TextColor: "#fff"}
+	banner.SetDefaults()
+	assert.Equal(t, "#000", banner.Color)
+	assert.Equal(t, "#fff", banner.TextColor)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+// The capabilities a plugin may declare in its manifest, each granting access to part of the plugin
+// API.
+const (
+	PLUGIN_CAPABILITY_CONFIG_READ     = "config:read"
+	PLUGIN_CAPABILITY_CONFIG_WRITE    = "config:write"
+	PLUGIN_CAPABILITY_COMMANDS        = "commands"
+	PLUGIN_CAPABILITY_USERS_READ      = "users:read"
+	PLUGIN_CAPABILITY_USERS_WRITE     = "users:write"
+	PLUGIN_CAPABILITY_SESSIONS        = "sessions"
+	PLUGIN_CAPABILITY_TEAMS_READ      = "teams:read"
+	PLUGIN_CAPABILITY_TEAMS_WRITE     = "teams:write"
+	PLUGIN_CAPABILITY_CHANNELS_READ   = "channels:read"
+	PLUGIN_CAPABILITY_CHANNELS_WRITE  = "channels:write"
+	PLUGIN_CAPABILITY_CHANNELS_EXPORT = "channels:export"
+	PLUGIN_CAPABILITY_POSTS_READ      = "posts:read"
+	PLUGIN_CAPABILITY_POSTS_WRITE     = "posts:write"
+	PLUGIN_CAPABILITY_FILES_READ      = "files:read"
+	PLUGIN_CAPABILITY_FILES_WRITE     = "files:write"
+	PLUGIN_CAPABILITY_NOTIFICATIONS   = "notifications"
+	PLUGIN_CAPABILITY_ANNOUNCEMENTS   = "announcements"
+	PLUGIN_CAPABILITY_SUBSCRIPTIONS   = "subscriptions"
+	PLUGIN_CAPABILITY_OAUTH           = "oauth"
+	PLUGIN_CAPABILITY_KV              = "kv"
+	PLUGIN_CAPABILITY_WEBSOCKET       = "websocket"
+	PLUGIN_CAPABILITY_ADMIN_ACTIONS   = "admin_actions"
+)
+
+// PluginCapabilities lists every capability a plugin may declare.
+var PluginCapabilities = []string{
+	PLUGIN_CAPABILITY_CONFIG_READ,
+	PLUGIN_CAPABILITY_CONFIG_WRITE,
+	PLUGIN_CAPABILITY_COMMANDS,
+	PLUGIN_CAPABILITY_USERS_READ,
+	PLUGIN_CAPABILITY_USERS_WRITE,
+	PLUGIN_CAPABILITY_SESSIONS,
+	PLUGIN_CAPABILITY_TEAMS_READ,
+	PLUGIN_CAPABILITY_TEAMS_WRITE,
+	PLUGIN_CAPABILITY_CHANNELS_READ,
+	PLUGIN_CAPABILITY_CHANNELS_WRITE,
+	PLUGIN_CAPABILITY_CHANNELS_EXPORT,
+	PLUGIN_CAPABILITY_POSTS_READ,
+	PLUGIN_CAPABILITY_POSTS_WRITE,
+	PLUGIN_CAPABILITY_FILES_READ,
+	PLUGIN_CAPABILITY_FILES_WRITE,
+	PLUGIN_CAPABILITY_NOTIFICATIONS,
+	PLUGIN_CAPABILITY_ANNOUNCEMENTS,
+	PLUGIN_CAPABILITY_SUBSCRIPTIONS,
+	PLUGIN_CAPABILITY_OAUTH,
+	PLUGIN_CAPABILITY_KV,
+	PLUGIN_CAPABILITY_WEBSOCKET,
+	PLUGIN_CAPABILITY_ADMIN_ACTIONS,
+}
+
+// IsValidPluginCapability returns whether the capability is one a plugin may declare.
+func IsValidPluginCapability(capability string) bool {
+	for _, c := range PluginCapabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidPluginCapability(t *testing.T) {
+	for _, capability := range PluginCapabilities {
+		assert.True(t, IsValidPluginCapability(capability), capability)
+	}
+
+	assert.False(t, IsValidPluginCapability(""))
+	assert.False(t, IsValidPluginCapability("posts"))
+	assert.False(t, IsValidPluginCapability("POSTS:WRITE"))
+}
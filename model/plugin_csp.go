@@ -0,0 +1,51 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// PluginCSPDirectives lists the Content-Security-Policy directives for which plugin webapps may
+// declare external origins.
+var PluginCSPDirectives = []string{
+	"connect-src",
+	"font-src",
+	"frame-src",
+	"img-src",
+	"media-src",
+	"script-src",
+	"style-src",
+}
+
+var pluginCSPHostRegex = regexp.MustCompile(`^(\*\.)?[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$`)
+
+// IsValidPluginCSPDirective returns whether plugin webapps may declare external origins for the
+// directive.
+func IsValidPluginCSPDirective(directive string) bool {
+	for _, d := range PluginCSPDirectives {
+		if d == directive {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidPluginCSPSource returns whether the source is an http or https origin, optionally with a
+// port and a wildcard for subdomains, such as https://*.example.com:8443. Keywords such as
+// 'unsafe-inline', bare schemes such as data: and paths are not allowed.
+func IsValidPluginCSPSource(source string) bool {
+	u, err := url.Parse(source)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil {
+		return false
+	}
+
+	if u.Opaque != "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.ForceQuery || u.Fragment != "" {
+		return false
+	}
+
+	return pluginCSPHostRegex.MatchString(strings.ToLower(u.Hostname()))
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidPluginCSPDirective(t *testing.T) {
+	assert.True(t, IsValidPluginCSPDirective("frame-src"))
+	assert.True(t, IsValidPluginCSPDirective("img-src"))
+	assert.False(t, IsValidPluginCSPDirective("frame-ancestors"))
+	assert.False(t, IsValidPluginCSPDirective("default-src"))
+	assert.False(t, IsValidPluginCSPDirective(""))
+}
+
+func TestIsValidPluginCSPSource(t *testing.T) {
+	for source, valid := range map[string]bool{
+		"https://www.youtube.com":     true,
+		"https://maps.example.com/":   true,
+		"http://localhost:8065":       true,
+		"https://*.example.com":       true,
+		"HTTPS://Tiles.Example.COM":   true,
+		"https://example.com/embed":   false,
+		"https://example.com?x=1":     false,
+		"https://example.com#top":     false,
+		"https://user@example.com":    false,
+		"https://example.com;img-src": false,
+		"https://example.com 'self'":  false,
+		"https://*":                   false,
+		"https://www.*.example.com":   false,
+		"ftp://example.com":           false,
+		"data:":                       false,
+		"*":                           false,
+		"'self'":                      false,
+		"'unsafe-inline'":             false,
+		"example.com":                 false,
+		"":                            false,
+	} {
+		assert.Equal(t, valid, IsValidPluginCSPSource(source), source)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// PluginDataEntry is a key-value pair of a plugin, as inspected by system admins or in an export of
+// the plugin's data, in which each pair is written as a line of JSON. Values are base64 encoded.
+type PluginDataEntry struct {
+	Key      string `json:"key"`
+	Value    []byte `json:"value"`
+	ExpireAt int64  `json:"expire_at,omitempty"`
+}
+
+func (e *PluginDataEntry) ToJson() string {
+	b, _ := json.Marshal(e)
+	return string(b)
+}
+
+func PluginDataEntryFromJson(data io.Reader) *PluginDataEntry {
+	var e *PluginDataEntry
+	json.NewDecoder(data).Decode(&e)
+	return e
+}
+
+// PluginDataImportRowError describes a line of a plugin data import that could not be imported.
+type PluginDataImportRowError struct {
+	// Line is the number of the line, starting at 1.
+	Line  int       `json:"line"`
+	Key   string    `json:"key,omitempty"`
+	Error *AppError `json:"error"`
+}
+
+// PluginDataImportResult summarizes an import of a plugin's data. Lines that could not be imported
+// do not prevent the others from being imported.
+type PluginDataImportResult struct {
+	Imported int                         `json:"imported"`
+	Errors   []*PluginDataImportRowError `json:"errors"`
+}
+
+func (r *PluginDataImportResult) ToJson() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func PluginDataImportResultFromJson(data io.Reader) *PluginDataImportResult {
+	var r *PluginDataImportResult
+	json.NewDecoder(data).Decode(&r)
+	return r
+}
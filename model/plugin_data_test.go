@@ -0,0 +1,48 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginDataEntryJson(t *testing.T) {
+	entry := &PluginDataEntry{Key: "key", Value: []byte{0, 1, 2, 255}, ExpireAt: 10}
+
+	b, err := json.Marshal(entry)
+	require.NoError(t, err)
+	assert.Equal(t, `{"key":"key","value":"AAEC/w==","expire_at":10}`, string(b))
+
+	var decoded *PluginDataEntry
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, entry, decoded)
+
+	assert.Equal(t, entry, PluginDataEntryFromJson(strings.NewReader(entry.ToJson())))
+	assert.Equal(t, (*PluginDataEntry)(nil), PluginDataEntryFromJson(strings.NewReader("junk")))
+}
+
+func TestPluginDataImportResultJson(t *testing.T) {
+	result := &PluginDataImportResult{
+		Imported: 2,
+		Errors: []*PluginDataImportRowError{
+			{Line: 3, Key: "key", Error: NewAppError("where", "id", nil, "details", http.StatusBadRequest)},
+		},
+	}
+
+	decoded := PluginDataImportResultFromJson(strings.NewReader(result.ToJson()))
+	require.NotNil(t, decoded)
+	assert.Equal(t, 2, decoded.Imported)
+	require.Len(t, decoded.Errors, 1)
+	assert.Equal(t, 3, decoded.Errors[0].Line)
+	assert.Equal(t, "key", decoded.Errors[0].Key)
+	assert.Equal(t, "id", decoded.Errors[0].Error.Id)
+
+	assert.Equal(t, (*PluginDataImportResult)(nil), PluginDataImportResultFromJson(strings.NewReader("junk")))
+}
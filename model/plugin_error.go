@@ -0,0 +1,12 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+// PluginError describes a directory in the plugin directory that could not be loaded as a plugin,
+// such as one whose manifest is missing or cannot be parsed. Such directories can only be removed by
+// path, since no plugin id is known for them.
+type PluginError struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
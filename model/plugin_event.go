@@ -0,0 +1,116 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+// The events recorded in a plugin's event log.
+const (
+	PLUGIN_EVENT_INSTALLED   = "installed"
+	PLUGIN_EVENT_UPGRADED    = "upgraded"
+	PLUGIN_EVENT_REMOVED     = "removed"
+	PLUGIN_EVENT_ACTIVATED   = "activated"
+	PLUGIN_EVENT_DEACTIVATED = "deactivated"
+
+	// PLUGIN_EVENT_FAILED is recorded when a plugin fails to start, with the reason in its details.
+	PLUGIN_EVENT_FAILED = "failed"
+
+	// PLUGIN_EVENT_AUTO_DISABLED is recorded when the server disables a plugin by itself, such as
+	// after it repeatedly failed to start, with the reason in its details.
+	PLUGIN_EVENT_AUTO_DISABLED = "auto_disabled"
+
+	// PLUGIN_EVENT_CONFIG_CHANGED is recorded when a plugin is notified of a change to its settings.
+	PLUGIN_EVENT_CONFIG_CHANGED = "config_changed"
+
+	PLUGIN_EVENT_QUOTA_EXCEEDED = "quota_exceeded"
+)
+
+const PLUGIN_EVENT_DETAILS_MAX_RUNES = 1024
+
+var pluginEventTypes = map[string]bool{
+	PLUGIN_EVENT_INSTALLED:      true,
+	PLUGIN_EVENT_UPGRADED:       true,
+	PLUGIN_EVENT_REMOVED:        true,
+	PLUGIN_EVENT_ACTIVATED:      true,
+	PLUGIN_EVENT_DEACTIVATED:    true,
+	PLUGIN_EVENT_FAILED:         true,
+	PLUGIN_EVENT_AUTO_DISABLED:  true,
+	PLUGIN_EVENT_CONFIG_CHANGED: true,
+	PLUGIN_EVENT_QUOTA_EXCEEDED: true,
+}
+
+// PluginEvent is an entry of a plugin's event log, recording something that happened to the plugin
+// on one of the servers of the cluster.
+type PluginEvent struct {
+	Id       string `json:"id"`
+	PluginId string `json:"plugin_id"`
+	Type     string `json:"type"`
+
+	// ClusterId identifies the server on which the event happened. It is empty for servers that are
+	// not part of a cluster.
+	ClusterId string `json:"cluster_id"`
+
+	// Details describe the event, such as the reason a plugin failed to start.
+	Details string `json:"details,omitempty"`
+
+	CreateAt int64 `json:"create_at"`
+}
+
+func PluginEventListToJson(l []*PluginEvent) string {
+	b, _ := json.Marshal(l)
+	return string(b)
+}
+
+func PluginEventListFromJson(data io.Reader) []*PluginEvent {
+	var l []*PluginEvent
+	json.NewDecoder(data).Decode(&l)
+	return l
+}
+
+func (e *PluginEvent) PreSave() {
+	if e.Id == "" {
+		e.Id = NewId()
+	}
+
+	if e.CreateAt == 0 {
+		e.CreateAt = GetMillis()
+	}
+
+	if utf8.RuneCountInString(e.Details) > PLUGIN_EVENT_DETAILS_MAX_RUNES {
+		e.Details = string([]rune(e.Details)[:PLUGIN_EVENT_DETAILS_MAX_RUNES])
+	}
+}
+
+func (e *PluginEvent) IsValid() *AppError {
+	if !IsValidId(e.Id) {
+		return NewAppError("PluginEvent.IsValid", "model.plugin_event.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(e.PluginId) == 0 || utf8.RuneCountInString(e.PluginId) > KEY_VALUE_PLUGIN_ID_MAX_RUNES {
+		return NewAppError("PluginEvent.IsValid", "model.plugin_event.is_valid.plugin_id.app_error", nil, "id="+e.Id, http.StatusBadRequest)
+	}
+
+	if !pluginEventTypes[e.Type] {
+		return NewAppError("PluginEvent.IsValid", "model.plugin_event.is_valid.type.app_error", map[string]interface{}{"Type": e.Type}, "id="+e.Id, http.StatusBadRequest)
+	}
+
+	if len(e.ClusterId) > PLUGIN_RUNTIME_STATE_CLUSTER_ID_MAX_LENGTH {
+		return NewAppError("PluginEvent.IsValid", "model.plugin_event.is_valid.cluster_id.app_error", nil, "id="+e.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(e.Details) > PLUGIN_EVENT_DETAILS_MAX_RUNES {
+		return NewAppError("PluginEvent.IsValid", "model.plugin_event.is_valid.details.app_error", map[string]interface{}{"Max": PLUGIN_EVENT_DETAILS_MAX_RUNES}, "id="+e.Id, http.StatusBadRequest)
+	}
+
+	if e.CreateAt == 0 {
+		return NewAppError("PluginEvent.IsValid", "model.plugin_event.is_valid.create_at.app_error", nil, "id="+e.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
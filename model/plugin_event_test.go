@@ -0,0 +1,51 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginEventIsValid(t *testing.T) {
+	e := &PluginEvent{
+		PluginId: "com.example.plugin",
+		Type:     PLUGIN_EVENT_FAILED,
+		Details:  strings.Repeat("é", PLUGIN_EVENT_DETAILS_MAX_RUNES+1),
+	}
+	assert.NotNil(t, e.IsValid())
+
+	// Long details are truncated rather than failing to be recorded.
+	e.PreSave()
+	require.Nil(t, e.IsValid())
+	assert.Equal(t, strings.Repeat("é", PLUGIN_EVENT_DETAILS_MAX_RUNES), e.Details)
+
+	e.PluginId = ""
+	assert.NotNil(t, e.IsValid())
+	e.PluginId = "com.example.plugin"
+
+	e.Type = "exploded"
+	assert.NotNil(t, e.IsValid())
+	e.Type = PLUGIN_EVENT_ACTIVATED
+
+	e.ClusterId = strings.Repeat("a", PLUGIN_RUNTIME_STATE_CLUSTER_ID_MAX_LENGTH+1)
+	assert.NotNil(t, e.IsValid())
+	e.ClusterId = NewId()
+	require.Nil(t, e.IsValid())
+
+	e.Details = strings.Repeat("a", PLUGIN_EVENT_DETAILS_MAX_RUNES+1)
+	assert.NotNil(t, e.IsValid())
+}
+
+func TestPluginEventListJson(t *testing.T) {
+	events := []*PluginEvent{
+		{Id: NewId(), PluginId: "com.example.plugin", Type: PLUGIN_EVENT_INSTALLED, Details: "version=1.0.0", CreateAt: 1},
+		{Id: NewId(), PluginId: "com.example.plugin", Type: PLUGIN_EVENT_ACTIVATED, ClusterId: NewId(), CreateAt: 2},
+	}
+
+	assert.Equal(t, events, PluginEventListFromJson(strings.NewReader(PluginEventListToJson(events))))
+}
@@ -4,29 +4,147 @@
 package model
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
 	"unicode/utf8"
 )
 
 const (
 	KEY_VALUE_PLUGIN_ID_MAX_RUNES = 190
-	KEY_VALUE_KEY_MAX_RUNES       = 50
+	KEY_VALUE_KEY_MAX_RUNES       = 150
+	KEY_VALUE_RAW_KEY_MAX_RUNES   = 1024
+
+	// KEY_VALUE_VALUE_MAX_BYTES is the most the value column can hold, being a MEDIUMBLOB on MySQL.
+	// PluginSettings.MaxKeyValueSizeBytes limits values further.
+	KEY_VALUE_VALUE_MAX_BYTES = 16*1024*1024 - 1
 )
 
 type PluginKeyValue struct {
 	PluginId string `json:"plugin_id"`
-	Key      string `json:"key" db:"PKey"`
-	Value    []byte `json:"value" db:"PValue"`
+
+	// Key is the key as given by the plugin. Keys written before keys were stored as given are
+	// stored as their hash until they are next accessed or moved by the migrations job.
+	Key   string `json:"key" db:"PKey"`
+	Value []byte `json:"value" db:"PValue"`
+
+	// RawKey is the key as given by the plugin, so that the plugin's keys can be listed while some are
+	// still stored as their hash. It is empty for internal keys and keys last written before raw keys
+	// were recorded.
+	RawKey string `json:"raw_key"`
+
+	// ExpireAt is the time in milliseconds after which the key-value pair is treated as deleted, or
+	// zero if it never expires.
+	ExpireAt int64 `json:"expire_at"`
 }
 
-func (kv *PluginKeyValue) IsValid() *AppError {
+// IsValid checks the key-value pair, including that it has a value and that its value, as stored, is
+// at most maxValueSize bytes. Keys are deleted rather than set to a nil value.
+func (kv *PluginKeyValue) IsValid(maxValueSize int) *AppError {
 	if len(kv.PluginId) == 0 || utf8.RuneCountInString(kv.PluginId) > KEY_VALUE_PLUGIN_ID_MAX_RUNES {
-		return NewAppError("PluginKeyValue.IsValid", "model.plugin_key_value.is_valid.plugin_id.app_error", map[string]interface{}{"Max": KEY_VALUE_KEY_MAX_RUNES, "Min": 0}, "key="+kv.Key, http.StatusBadRequest)
+		return NewAppError("PluginKeyValue.IsValid", "model.plugin_key_value.is_valid.plugin_id.app_error", map[string]interface{}{"Max": KEY_VALUE_PLUGIN_ID_MAX_RUNES, "Min": 0}, "key="+kv.Key, http.StatusBadRequest)
 	}
 
 	if len(kv.Key) == 0 || utf8.RuneCountInString(kv.Key) > KEY_VALUE_KEY_MAX_RUNES {
 		return NewAppError("PluginKeyValue.IsValid", "model.plugin_key_value.is_valid.key.app_error", map[string]interface{}{"Max": KEY_VALUE_KEY_MAX_RUNES, "Min": 0}, "key="+kv.Key, http.StatusBadRequest)
 	}
 
+	if utf8.RuneCountInString(kv.RawKey) > KEY_VALUE_RAW_KEY_MAX_RUNES {
+		return NewAppError("PluginKeyValue.IsValid", "model.plugin_key_value.is_valid.raw_key.app_error", map[string]interface{}{"Max": KEY_VALUE_RAW_KEY_MAX_RUNES}, "key="+kv.Key, http.StatusBadRequest)
+	}
+
+	if kv.Value == nil {
+		return NewAppError("PluginKeyValue.IsValid", "model.plugin_key_value.is_valid.value_nil.app_error", map[string]interface{}{"Key": kv.Key}, "key="+kv.Key, http.StatusBadRequest)
+	}
+
+	if len(kv.Value) > maxValueSize {
+		return NewAppError("PluginKeyValue.IsValid", "model.plugin_key_value.is_valid.value.app_error", map[string]interface{}{"Key": kv.Key, "Size": len(kv.Value), "Max": maxValueSize}, "key="+kv.Key, http.StatusRequestEntityTooLarge)
+	}
+
+	if kv.ExpireAt < 0 {
+		return NewAppError("PluginKeyValue.IsValid", "model.plugin_key_value.is_valid.expire_at.app_error", nil, "key="+kv.Key, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// PluginKeyValueUsage summarizes the key-value pairs stored by a plugin.
+type PluginKeyValueUsage struct {
+	KeyCount int64 `json:"key_count"`
+	Size     int64 `json:"size"`
+}
+
+// PluginKeyValueMigrationStatus reports the progress of moving the plugin keys still stored as their
+// hash to where they are stored as given.
+type PluginKeyValueMigrationStatus struct {
+	// Completed is set once hashed keys are no longer looked up.
+	Completed bool `json:"completed"`
+
+	// RemainingKeys counts the hashed keys whose raw keys were recorded, which the migrations job
+	// moves.
+	RemainingKeys int64 `json:"remaining_keys"`
+
+	// UnrecordedKeys counts the hashed keys last written before raw keys were recorded. They can only
+	// be moved as plugins access them, and are no longer found once the migration is completed.
+	UnrecordedKeys int64 `json:"unrecorded_keys"`
+}
+
+// PluginKeyValueMigrationProgress records how far the migrations job has got moving the plugin keys
+// still stored as their hash, so that it resumes from there after a restart.
+type PluginKeyValueMigrationProgress struct {
+	// LastPluginId and LastKey identify the last hashed key looked at, in order of plugin id and key.
+	LastPluginId string `json:"last_plugin_id"`
+	LastKey      string `json:"last_key"`
+
+	MovedKeys int64 `json:"moved_keys"`
+
+	// Done is set once no hashed keys are left to look at.
+	Done bool `json:"done"`
+}
+
+func (p *PluginKeyValueMigrationProgress) ToJson() string {
+	b, _ := json.Marshal(p)
+	return string(b)
+}
+
+func PluginKeyValueMigrationProgressFromJson(data io.Reader) *PluginKeyValueMigrationProgress {
+	var p *PluginKeyValueMigrationProgress
+	json.NewDecoder(data).Decode(&p)
+	return p
+}
+
+// PluginKVGetOptions controls how a plugin's key-value pair is read. The zero value keeps the default
+// behaviour of reading from a read replica when one is configured.
+type PluginKVGetOptions struct {
+	// ReadFromMaster reads from the master database, so that a value written immediately before is
+	// seen even if the read replicas have yet to catch up.
+	ReadFromMaster bool `json:"read_from_master"`
+}
+
+const (
+	PLUGIN_KV_OP_SET    = "set"
+	PLUGIN_KV_OP_DELETE = "delete"
+)
+
+// PluginKVOp is a write to a plugin's key-value store made together with others, so that either all
+// of them are made or none of them are.
+type PluginKVOp struct {
+	// Type is either PLUGIN_KV_OP_SET or PLUGIN_KV_OP_DELETE.
+	Type  string `json:"type"`
+	Key   string `json:"key"`
+	Value []byte `json:"value,omitempty"`
+}
+
+// IsValid checks the type and key of the op. The value of a set is checked once stored, like any
+// other value.
+func (op *PluginKVOp) IsValid() *AppError {
+	if op.Type != PLUGIN_KV_OP_SET && op.Type != PLUGIN_KV_OP_DELETE {
+		return NewAppError("PluginKVOp.IsValid", "model.plugin_kv_op.is_valid.type.app_error", map[string]interface{}{"Key": op.Key}, "type="+op.Type, http.StatusBadRequest)
+	}
+
+	if len(op.Key) == 0 || utf8.RuneCountInString(op.Key) > KEY_VALUE_KEY_MAX_RUNES {
+		return NewAppError("PluginKVOp.IsValid", "model.plugin_kv_op.is_valid.key.app_error", map[string]interface{}{"Key": op.Key, "Max": KEY_VALUE_KEY_MAX_RUNES}, "key="+op.Key, http.StatusBadRequest)
+	}
+
 	return nil
 }
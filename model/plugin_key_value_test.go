@@ -4,6 +4,7 @@
 package model
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,12 +12,97 @@ import (
 
 func TestPluginKeyIsValid(t *testing.T) {
 	kv := PluginKeyValue{PluginId: "someid", Key: "somekey", Value: []byte("somevalue")}
-	assert.Nil(t, kv.IsValid())
+	assert.Nil(t, kv.IsValid(KEY_VALUE_VALUE_MAX_BYTES))
 
 	kv.PluginId = ""
-	assert.NotNil(t, kv.IsValid())
+	assert.NotNil(t, kv.IsValid(KEY_VALUE_VALUE_MAX_BYTES))
 
 	kv.PluginId = "someid"
 	kv.Key = ""
-	assert.NotNil(t, kv.IsValid())
+	assert.NotNil(t, kv.IsValid(KEY_VALUE_VALUE_MAX_BYTES))
+
+	kv.Key = strings.Repeat("a", KEY_VALUE_KEY_MAX_RUNES+1)
+	assert.NotNil(t, kv.IsValid(KEY_VALUE_VALUE_MAX_BYTES))
+
+	kv.Key = strings.Repeat("a", KEY_VALUE_KEY_MAX_RUNES)
+	assert.Nil(t, kv.IsValid(KEY_VALUE_VALUE_MAX_BYTES))
+
+	kv.Key = "somekey"
+	kv.RawKey = strings.Repeat("a", KEY_VALUE_RAW_KEY_MAX_RUNES+1)
+	assert.NotNil(t, kv.IsValid(KEY_VALUE_VALUE_MAX_BYTES))
+
+	kv.RawKey = strings.Repeat("a", KEY_VALUE_RAW_KEY_MAX_RUNES)
+	assert.Nil(t, kv.IsValid(KEY_VALUE_VALUE_MAX_BYTES))
+
+	kv.ExpireAt = -1
+	assert.NotNil(t, kv.IsValid(KEY_VALUE_VALUE_MAX_BYTES))
+
+	kv.ExpireAt = GetMillis()
+	assert.Nil(t, kv.IsValid(KEY_VALUE_VALUE_MAX_BYTES))
+}
+
+func TestPluginKeyIsValidValueSize(t *testing.T) {
+	kv := PluginKeyValue{PluginId: "someid", Key: "somekey"}
+
+	for name, tc := range map[string]struct {
+		Size  int
+		Valid bool
+	}{
+		"empty":           {Size: 0, Valid: true},
+		"below the limit": {Size: 99, Valid: true},
+		"at the limit":    {Size: 100, Valid: true},
+		"above the limit": {Size: 101, Valid: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			kv.Value = make([]byte, tc.Size)
+
+			err := kv.IsValid(100)
+			if tc.Valid {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Equal(t, "model.plugin_key_value.is_valid.value.app_error", err.Id)
+				assert.Equal(t, map[string]interface{}{"Key": "somekey", "Size": 101, "Max": 100}, err.params)
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		kv.Value = nil
+
+		err := kv.IsValid(100)
+		if assert.NotNil(t, err) {
+			assert.Equal(t, "model.plugin_key_value.is_valid.value_nil.app_error", err.Id)
+		}
+	})
+}
+
+func TestPluginKVOpIsValid(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Op    PluginKVOp
+		Error string
+	}{
+		"set":          {Op: PluginKVOp{Type: PLUGIN_KV_OP_SET, Key: "key", Value: []byte("value")}},
+		"empty set":    {Op: PluginKVOp{Type: PLUGIN_KV_OP_SET, Key: "key"}},
+		"delete":       {Op: PluginKVOp{Type: PLUGIN_KV_OP_DELETE, Key: "key"}},
+		"unknown type": {Op: PluginKVOp{Type: "increment", Key: "key"}, Error: "model.plugin_kv_op.is_valid.type.app_error"},
+		"no key":       {Op: PluginKVOp{Type: PLUGIN_KV_OP_DELETE}, Error: "model.plugin_kv_op.is_valid.key.app_error"},
+		"long key":     {Op: PluginKVOp{Type: PLUGIN_KV_OP_SET, Key: strings.Repeat("a", KEY_VALUE_KEY_MAX_RUNES+1)}, Error: "model.plugin_kv_op.is_valid.key.app_error"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.Op.IsValid()
+			if tc.Error == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Equal(t, tc.Error, err.Id)
+				assert.Equal(t, tc.Op.Key, err.params["Key"])
+			}
+		})
+	}
+}
+
+func TestPluginKeyValueMigrationProgressJson(t *testing.T) {
+	progress := &PluginKeyValueMigrationProgress{LastPluginId: "someid", LastKey: "somekey", MovedKeys: 10}
+	assert.Equal(t, progress, PluginKeyValueMigrationProgressFromJson(strings.NewReader(progress.ToJson())))
+
+	assert.Nil(t, PluginKeyValueMigrationProgressFromJson(strings.NewReader("garbage")))
 }
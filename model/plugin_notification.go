@@ -0,0 +1,60 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	PLUGIN_NOTIFICATION_TITLE_MAX_RUNES   = 64
+	PLUGIN_NOTIFICATION_MESSAGE_MAX_RUNES = 1024
+)
+
+// PluginNotification is a desktop and mobile notification sent to a user by a plugin without
+// creating a post.
+type PluginNotification struct {
+	// ChannelId is the channel that the notification relates to, opened when it is clicked.
+	ChannelId string `json:"channel_id"`
+
+	// PostId optionally identifies the post within the channel that the notification relates to.
+	PostId string `json:"post_id,omitempty"`
+
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message"`
+}
+
+func (n *PluginNotification) IsValid() *AppError {
+	if !IsValidId(n.ChannelId) {
+		return NewAppError("PluginNotification.IsValid", "model.plugin_notification.is_valid.channel_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if n.PostId != "" && !IsValidId(n.PostId) {
+		return NewAppError("PluginNotification.IsValid", "model.plugin_notification.is_valid.post_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(n.Title) > PLUGIN_NOTIFICATION_TITLE_MAX_RUNES {
+		return NewAppError("PluginNotification.IsValid", "model.plugin_notification.is_valid.title.app_error", map[string]interface{}{"Max": PLUGIN_NOTIFICATION_TITLE_MAX_RUNES}, "", http.StatusBadRequest)
+	}
+
+	if n.Message == "" || utf8.RuneCountInString(n.Message) > PLUGIN_NOTIFICATION_MESSAGE_MAX_RUNES {
+		return NewAppError("PluginNotification.IsValid", "model.plugin_notification.is_valid.message.app_error", map[string]interface{}{"Max": PLUGIN_NOTIFICATION_MESSAGE_MAX_RUNES}, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (n *PluginNotification) ToJson() string {
+	b, _ := json.Marshal(n)
+	return string(b)
+}
+
+func PluginNotificationFromJson(data io.Reader) *PluginNotification {
+	var n *PluginNotification
+	json.NewDecoder(data).Decode(&n)
+	return n
+}
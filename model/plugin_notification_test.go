@@ -0,0 +1,79 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginNotificationJson(t *testing.T) {
+	notification := &PluginNotification{
+		ChannelId: NewId(),
+		PostId:    NewId(),
+		Title:     "title",
+		Message:   "message",
+	}
+
+	json := notification.ToJson()
+	assert.Equal(t, notification, PluginNotificationFromJson(strings.NewReader(json)))
+	assert.Equal(t, (*PluginNotification)(nil), PluginNotificationFromJson(strings.NewReader("junk")))
+}
+
+func TestPluginNotificationIsValid(t *testing.T) {
+	testCases := []struct {
+		Description  string
+		Notification *PluginNotification
+		ExpectedErr  string
+	}{
+		{
+			"valid",
+			&PluginNotification{ChannelId: NewId(), Message: "message"},
+			"",
+		},
+		{
+			"valid with post and title",
+			&PluginNotification{ChannelId: NewId(), PostId: NewId(), Title: "title", Message: "message"},
+			"",
+		},
+		{
+			"invalid channel id",
+			&PluginNotification{ChannelId: "junk", Message: "message"},
+			"model.plugin_notification.is_valid.channel_id.app_error",
+		},
+		{
+			"invalid post id",
+			&PluginNotification{ChannelId: NewId(), PostId: "junk", Message: "message"},
+			"model.plugin_notification.is_valid.post_id.app_error",
+		},
+		{
+			"title too long",
+			&PluginNotification{ChannelId: NewId(), Title: strings.Repeat("a", PLUGIN_NOTIFICATION_TITLE_MAX_RUNES+1), Message: "message"},
+			"model.plugin_notification.is_valid.title.app_error",
+		},
+		{
+			"empty message",
+			&PluginNotification{ChannelId: NewId()},
+			"model.plugin_notification.is_valid.message.app_error",
+		},
+		{
+			"message too long",
+			&PluginNotification{ChannelId: NewId(), Message: strings.Repeat("a", PLUGIN_NOTIFICATION_MESSAGE_MAX_RUNES+1)},
+			"model.plugin_notification.is_valid.message.app_error",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Description, func(t *testing.T) {
+			err := testCase.Notification.IsValid()
+			if testCase.ExpectedErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Equal(t, testCase.ExpectedErr, err.Id)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// PLUGIN_OAUTH_TOKEN_MAX_LENGTH limits the size of the encrypted token stored for each user connected
+// to a plugin's OAuth provider.
+const PLUGIN_OAUTH_TOKEN_MAX_LENGTH = 16000
+
+// PluginOAuthProvider configures the OAuth 2.0 provider through which the server connects users to
+// an external service on behalf of a plugin. The server sends users to AuthURL from
+// /plugins/{id}/oauth/connect, and exchanges the code they return with for a token at TokenURL once
+// they reach /plugins/{id}/oauth/complete, which must be registered with the provider as the
+// redirect URL.
+type PluginOAuthProvider struct {
+	ClientId     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	AuthURL      string   `json:"auth_url"`
+	TokenURL     string   `json:"token_url"`
+	Scopes       []string `json:"scopes,omitempty"`
+
+	// RedirectPath is the path, relative to the plugin's routes, to which users are sent once they
+	// have connected their account. Users are sent to the site's root if it is empty.
+	RedirectPath string `json:"redirect_path,omitempty"`
+}
+
+func (p *PluginOAuthProvider) IsValid() *AppError {
+	if len(p.ClientId) == 0 {
+		return NewAppError("PluginOAuthProvider.IsValid", "model.plugin_oauth_provider.is_valid.client_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidHttpUrl(p.AuthURL) {
+		return NewAppError("PluginOAuthProvider.IsValid", "model.plugin_oauth_provider.is_valid.auth_url.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidHttpUrl(p.TokenURL) {
+		return NewAppError("PluginOAuthProvider.IsValid", "model.plugin_oauth_provider.is_valid.token_url.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	// The path is appended to the plugin's routes, so it may not lead anywhere else.
+	if p.RedirectPath != "" && (!strings.HasPrefix(p.RedirectPath, "/") || strings.HasPrefix(p.RedirectPath, "//") || strings.Contains(p.RedirectPath, "..")) {
+		return NewAppError("PluginOAuthProvider.IsValid", "model.plugin_oauth_provider.is_valid.redirect_path.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// PluginOAuthToken is the token with which a plugin acts on behalf of a user in the external service
+// the user connected to the plugin's OAuth provider.
+type PluginOAuthToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type,omitempty"`
+
+	// RefreshToken is only ever seen by the server, which refreshes the access token as needed.
+	RefreshToken string `json:"refresh_token,omitempty"`
+
+	// Expiry is the time, in milliseconds, at which the access token expires, or 0 if it does not.
+	Expiry int64 `json:"expiry,omitempty"`
+}
+
+// IsExpired returns whether the token has expired or is about to, as of the given time in
+// milliseconds.
+func (t *PluginOAuthToken) IsExpired(now int64) bool {
+	// Tokens are refreshed a little early, so that they do not expire while a plugin is using them.
+	return t.Expiry != 0 && t.Expiry-10*1000 <= now
+}
+
+// PluginOAuthConnection records the token, encrypted by the server, of a user who has connected
+// their account in an external service to a plugin's OAuth provider.
+type PluginOAuthConnection struct {
+	PluginId string `json:"plugin_id"`
+	UserId   string `json:"user_id"`
+	Token    string `json:"-"`
+	UpdateAt int64  `json:"update_at"`
+}
+
+func (c *PluginOAuthConnection) PreSave() {
+	c.UpdateAt = GetMillis()
+}
+
+func (c *PluginOAuthConnection) IsValid() *AppError {
+	if len(c.PluginId) == 0 || utf8.RuneCountInString(c.PluginId) > KEY_VALUE_PLUGIN_ID_MAX_RUNES {
+		return NewAppError("PluginOAuthConnection.IsValid", "model.plugin_oauth_connection.is_valid.plugin_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidId(c.UserId) {
+		return NewAppError("PluginOAuthConnection.IsValid", "model.plugin_oauth_connection.is_valid.user_id.app_error", nil, "plugin_id="+c.PluginId, http.StatusBadRequest)
+	}
+
+	if len(c.Token) == 0 || len(c.Token) > PLUGIN_OAUTH_TOKEN_MAX_LENGTH {
+		return NewAppError("PluginOAuthConnection.IsValid", "model.plugin_oauth_connection.is_valid.token.app_error", nil, "plugin_id="+c.PluginId, http.StatusBadRequest)
+	}
+
+	if c.UpdateAt == 0 {
+		return NewAppError("PluginOAuthConnection.IsValid", "model.plugin_oauth_connection.is_valid.update_at.app_error", nil, "plugin_id="+c.PluginId, http.StatusBadRequest)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginOAuthProviderIsValid(t *testing.T) {
+	p := &PluginOAuthProvider{
+		ClientId:     "client",
+		ClientSecret: "secret",
+		AuthURL:      "https://example.com/oauth/authorize",
+		TokenURL:     "https://example.com/oauth/token",
+	}
+	assert.Nil(t, p.IsValid())
+
+	p.ClientId = ""
+	assert.NotNil(t, p.IsValid())
+	p.ClientId = "client"
+
+	p.AuthURL = "example.com/oauth/authorize"
+	assert.NotNil(t, p.IsValid())
+	p.AuthURL = "https://example.com/oauth/authorize"
+
+	p.TokenURL = ""
+	assert.NotNil(t, p.IsValid())
+	p.TokenURL = "https://example.com/oauth/token"
+
+	for _, path := range []string{"/connected", "/settings?connected=true"} {
+		p.RedirectPath = path
+		assert.Nil(t, p.IsValid(), path)
+	}
+
+	for _, path := range []string{"connected", "//example.com", "/../../admin_console"} {
+		p.RedirectPath = path
+		assert.NotNil(t, p.IsValid(), path)
+	}
+}
+
+func TestPluginOAuthTokenIsExpired(t *testing.T) {
+	now := GetMillis()
+
+	assert.False(t, (&PluginOAuthToken{AccessToken: "token"}).IsExpired(now))
+	assert.False(t, (&PluginOAuthToken{AccessToken: "token", Expiry: now + 60*1000}).IsExpired(now))
+	assert.True(t, (&PluginOAuthToken{AccessToken: "token", Expiry: now + 5*1000}).IsExpired(now))
+	assert.True(t, (&PluginOAuthToken{AccessToken: "token", Expiry: now - 1}).IsExpired(now))
+}
+
+func TestPluginOAuthConnectionIsValid(t *testing.T) {
+	c := &PluginOAuthConnection{
+		PluginId: "com.example.plugin",
+		UserId:   NewId(),
+		Token:    "encrypted",
+	}
+	assert.NotNil(t, c.IsValid())
+
+	c.PreSave()
+	assert.Nil(t, c.IsValid())
+
+	c.PluginId = ""
+	assert.NotNil(t, c.IsValid())
+	c.PluginId = "com.example.plugin"
+
+	c.UserId = "user"
+	assert.NotNil(t, c.IsValid())
+	c.UserId = NewId()
+
+	c.Token = ""
+	assert.NotNil(t, c.IsValid())
+
+	c.Token = strings.Repeat("a", PLUGIN_OAUTH_TOKEN_MAX_LENGTH+1)
+	assert.NotNil(t, c.IsValid())
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"net/http"
+	"regexp"
+	"unicode/utf8"
+)
+
+const (
+	PLUGIN_POST_METADATA_NAMESPACE_MAX_LENGTH = 64
+
+	// PLUGIN_POST_METADATA_MAX_BYTES limits the data attached to a post under each namespace, which
+	// must still fit within the post's props alongside everything else in them.
+	PLUGIN_POST_METADATA_MAX_BYTES = 2048
+)
+
+var validPluginPostMetadataNamespace = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// IsValidPluginPostMetadataNamespace returns whether a plugin may attach metadata to posts under
+// the given namespace.
+func IsValidPluginPostMetadataNamespace(namespace string) bool {
+	return len(namespace) <= PLUGIN_POST_METADATA_NAMESPACE_MAX_LENGTH && validPluginPostMetadataNamespace.MatchString(namespace)
+}
+
+// PluginPostMetadata records that a plugin has attached metadata to a post under a namespace. The
+// metadata itself is stored in the post's props, so this only tracks which plugin owns it, so that it
+// can be removed along with the plugin.
+type PluginPostMetadata struct {
+	PostId    string `json:"post_id"`
+	Namespace string `json:"namespace"`
+	PluginId  string `json:"plugin_id"`
+	UpdateAt  int64  `json:"update_at"`
+}
+
+func (m *PluginPostMetadata) PreSave() {
+	m.UpdateAt = GetMillis()
+}
+
+func (m *PluginPostMetadata) IsValid() *AppError {
+	if len(m.PostId) != 26 {
+		return NewAppError("PluginPostMetadata.IsValid", "model.plugin_post_metadata.is_valid.post_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidPluginPostMetadataNamespace(m.Namespace) {
+		return NewAppError("PluginPostMetadata.IsValid", "model.plugin_post_metadata.is_valid.namespace.app_error", map[string]interface{}{"Max": PLUGIN_POST_METADATA_NAMESPACE_MAX_LENGTH}, "post_id="+m.PostId, http.StatusBadRequest)
+	}
+
+	if len(m.PluginId) == 0 || utf8.RuneCountInString(m.PluginId) > KEY_VALUE_PLUGIN_ID_MAX_RUNES {
+		return NewAppError("PluginPostMetadata.IsValid", "model.plugin_post_metadata.is_valid.plugin_id.app_error", nil, "post_id="+m.PostId, http.StatusBadRequest)
+	}
+
+	if m.UpdateAt == 0 {
+		return NewAppError("PluginPostMetadata.IsValid", "model.plugin_post_metadata.is_valid.update_at.app_error", nil, "post_id="+m.PostId, http.StatusBadRequest)
+	}
+
+	return nil
+}
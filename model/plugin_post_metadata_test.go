@@ -0,0 +1,44 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidPluginPostMetadataNamespace(t *testing.T) {
+	for _, namespace := range []string{"translations", "com.example.plugin", "a", "translations_v2-beta", strings.Repeat("a", PLUGIN_POST_METADATA_NAMESPACE_MAX_LENGTH)} {
+		assert.True(t, IsValidPluginPostMetadataNamespace(namespace), namespace)
+	}
+
+	for _, namespace := range []string{"", ".hidden", "-flag", "with space", "slash/namespace", "ünïcode", strings.Repeat("a", PLUGIN_POST_METADATA_NAMESPACE_MAX_LENGTH+1)} {
+		assert.False(t, IsValidPluginPostMetadataNamespace(namespace), namespace)
+	}
+}
+
+func TestPluginPostMetadataIsValid(t *testing.T) {
+	m := &PluginPostMetadata{
+		PostId:    NewId(),
+		Namespace: "translations",
+		PluginId:  "com.example.plugin",
+	}
+	assert.NotNil(t, m.IsValid())
+
+	m.PreSave()
+	assert.Nil(t, m.IsValid())
+
+	m.PostId = "invalid"
+	assert.NotNil(t, m.IsValid())
+	m.PostId = NewId()
+
+	m.Namespace = "with space"
+	assert.NotNil(t, m.IsValid())
+	m.Namespace = "translations"
+
+	m.PluginId = ""
+	assert.NotNil(t, m.IsValid())
+}
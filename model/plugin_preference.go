@@ -0,0 +1,45 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const (
+	// PLUGIN_PREFERENCE_CATEGORY_PREFIX begins the category of the preferences that plugins store for
+	// users, followed by a hash of the plugin id since plugin ids are longer than categories may be.
+	PLUGIN_PREFERENCE_CATEGORY_PREFIX = "pp_"
+
+	PLUGIN_PREFERENCE_KEY_MAX_LENGTH = 32
+
+	// PLUGIN_PREFERENCE_MAX_COUNT and PLUGIN_PREFERENCE_MAX_SIZE bound the settings a plugin stores
+	// for each user, all of which are sent to the user's clients when they start.
+	PLUGIN_PREFERENCE_MAX_COUNT = 100
+	PLUGIN_PREFERENCE_MAX_SIZE  = 32 * 1024
+)
+
+// PluginPreferenceCategory returns the category of the preferences stored by the given plugin.
+func PluginPreferenceCategory(pluginId string) string {
+	hash := sha256.Sum256([]byte(pluginId))
+	return (PLUGIN_PREFERENCE_CATEGORY_PREFIX + hex.EncodeToString(hash[:]))[:32]
+}
+
+// IsPluginPreferenceCategory returns true if preferences of the given category were stored by a
+// plugin.
+func IsPluginPreferenceCategory(category string) bool {
+	return strings.HasPrefix(category, PLUGIN_PREFERENCE_CATEGORY_PREFIX)
+}
+
+// IsValidPluginPreferenceKey checks the key under which a plugin stores a setting for a user.
+func IsValidPluginPreferenceKey(key string) *AppError {
+	if len(key) == 0 || len(key) > PLUGIN_PREFERENCE_KEY_MAX_LENGTH {
+		return NewAppError("IsValidPluginPreferenceKey", "model.plugin_preference.is_valid.key.app_error", map[string]interface{}{"Max": PLUGIN_PREFERENCE_KEY_MAX_LENGTH}, "key="+key, http.StatusBadRequest)
+	}
+
+	return nil
+}
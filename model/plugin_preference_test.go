@@ -0,0 +1,30 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginPreferenceCategory(t *testing.T) {
+	category := PluginPreferenceCategory(strings.Repeat("a", KEY_VALUE_PLUGIN_ID_MAX_RUNES))
+	assert.Len(t, category, 32)
+	assert.True(t, IsPluginPreferenceCategory(category))
+	assert.Nil(t, (&Preference{UserId: NewId(), Category: category, Name: "key"}).IsValid())
+
+	assert.Equal(t, PluginPreferenceCategory("foo"), PluginPreferenceCategory("foo"))
+	assert.NotEqual(t, PluginPreferenceCategory("foo"), PluginPreferenceCategory("bar"))
+
+	assert.False(t, IsPluginPreferenceCategory(PREFERENCE_CATEGORY_THEME))
+}
+
+func TestIsValidPluginPreferenceKey(t *testing.T) {
+	assert.Nil(t, IsValidPluginPreferenceKey("key"))
+	assert.Nil(t, IsValidPluginPreferenceKey(strings.Repeat("a", PLUGIN_PREFERENCE_KEY_MAX_LENGTH)))
+	assert.NotNil(t, IsValidPluginPreferenceKey(""))
+	assert.NotNil(t, IsValidPluginPreferenceKey(strings.Repeat("a", PLUGIN_PREFERENCE_KEY_MAX_LENGTH+1)))
+}
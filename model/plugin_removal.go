@@ -0,0 +1,46 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// PluginRemovalInventory lists everything belonging to an installed plugin that removing it affects.
+// It is returned both as a preview before removal and as a summary of what a removal deleted.
+// Plugins cannot own bots, and webhooks are not attributed to the plugins that create them, so
+// neither is listed.
+type PluginRemovalInventory struct {
+	PluginId string `json:"plugin_id"`
+
+	// BundlePath and BundleSize describe the extracted plugin bundle on the server.
+	BundlePath string `json:"bundle_path"`
+	BundleSize int64  `json:"bundle_size"`
+
+	// WebappPath and WebappSize describe the webapp artifacts served to clients, if any.
+	WebappPath string `json:"webapp_path,omitempty"`
+	WebappSize int64  `json:"webapp_size"`
+
+	// KeyValueUsage describes the data stored by the plugin through the key-value store.
+	KeyValueUsage *PluginKeyValueUsage `json:"key_value_usage"`
+
+	// Commands are the slash commands currently registered by the plugin.
+	Commands []*Command `json:"commands"`
+
+	// DataDeleted reports whether the plugin's data was purged rather than orphaned. It is only set
+	// in the summary of a removal.
+	DataDeleted bool `json:"data_deleted"`
+}
+
+func (i *PluginRemovalInventory) ToJson() string {
+	b, _ := json.Marshal(i)
+	return string(b)
+}
+
+func PluginRemovalInventoryFromJson(data io.Reader) *PluginRemovalInventory {
+	var i *PluginRemovalInventory
+	json.NewDecoder(data).Decode(&i)
+	return i
+}
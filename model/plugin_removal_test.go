@@ -0,0 +1,28 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginRemovalInventoryJson(t *testing.T) {
+	inventory := &PluginRemovalInventory{
+		PluginId:      "theid",
+		BundlePath:    "plugins/theid",
+		BundleSize:    100,
+		WebappPath:    "client/plugins/theid",
+		WebappSize:    10,
+		KeyValueUsage: &PluginKeyValueUsage{KeyCount: 2, Size: 20},
+		Commands:      []*Command{{Trigger: "trigger", TeamId: NewId()}},
+		DataDeleted:   true,
+	}
+
+	json := inventory.ToJson()
+	assert.Equal(t, inventory, PluginRemovalInventoryFromJson(strings.NewReader(json)))
+	assert.Equal(t, (*PluginRemovalInventory)(nil), PluginRemovalInventoryFromJson(strings.NewReader("junk")))
+}
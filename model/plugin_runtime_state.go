@@ -0,0 +1,62 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	PLUGIN_RUNTIME_STATE_CLUSTER_ID_MAX_LENGTH = 64
+	PLUGIN_RUNTIME_STATE_ERROR_MAX_RUNES       = 1024
+)
+
+// PluginRuntimeState records the state of a plugin on one server of a cluster. It is persisted
+// whenever the state changes, so that the reason a plugin is not running survives a restart of the
+// server and is reported the same way whichever server answers.
+type PluginRuntimeState struct {
+	PluginId  string `json:"plugin_id"`
+	ClusterId string `json:"cluster_id"`
+	State     int    `json:"state"`
+
+	// Error is the reason the plugin last failed to start, if it did.
+	Error string `json:"error,omitempty"`
+
+	// FailureCount is the number of consecutive times the plugin failed to start, reset once it is
+	// running.
+	FailureCount int `json:"failure_count"`
+
+	// UpdateAt is refreshed periodically by the server the state belongs to, so that the states of
+	// servers that have left the cluster expire.
+	UpdateAt int64 `json:"update_at"`
+}
+
+func (s *PluginRuntimeState) PreSave() {
+	s.UpdateAt = GetMillis()
+
+	if utf8.RuneCountInString(s.Error) > PLUGIN_RUNTIME_STATE_ERROR_MAX_RUNES {
+		s.Error = string([]rune(s.Error)[:PLUGIN_RUNTIME_STATE_ERROR_MAX_RUNES])
+	}
+}
+
+func (s *PluginRuntimeState) IsValid() *AppError {
+	if len(s.PluginId) == 0 || utf8.RuneCountInString(s.PluginId) > KEY_VALUE_PLUGIN_ID_MAX_RUNES {
+		return NewAppError("PluginRuntimeState.IsValid", "model.plugin_runtime_state.is_valid.plugin_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(s.ClusterId) > PLUGIN_RUNTIME_STATE_CLUSTER_ID_MAX_LENGTH {
+		return NewAppError("PluginRuntimeState.IsValid", "model.plugin_runtime_state.is_valid.cluster_id.app_error", nil, "plugin_id="+s.PluginId, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(s.Error) > PLUGIN_RUNTIME_STATE_ERROR_MAX_RUNES {
+		return NewAppError("PluginRuntimeState.IsValid", "model.plugin_runtime_state.is_valid.error.app_error", map[string]interface{}{"Max": PLUGIN_RUNTIME_STATE_ERROR_MAX_RUNES}, "plugin_id="+s.PluginId, http.StatusBadRequest)
+	}
+
+	if s.UpdateAt == 0 {
+		return NewAppError("PluginRuntimeState.IsValid", "model.plugin_runtime_state.is_valid.update_at.app_error", nil, "plugin_id="+s.PluginId, http.StatusBadRequest)
+	}
+
+	return nil
+}
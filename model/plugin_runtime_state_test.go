@@ -0,0 +1,50 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginRuntimeStateIsValid(t *testing.T) {
+	s := &PluginRuntimeState{
+		PluginId:  "com.example.plugin",
+		ClusterId: NewId(),
+		State:     PluginStateFailedToStart,
+		Error:     "unable to start plugin",
+	}
+	assert.NotNil(t, s.IsValid())
+
+	s.PreSave()
+	assert.Nil(t, s.IsValid())
+
+	s.PluginId = ""
+	assert.NotNil(t, s.IsValid())
+	s.PluginId = "com.example.plugin"
+
+	s.ClusterId = ""
+	assert.Nil(t, s.IsValid())
+
+	s.ClusterId = strings.Repeat("a", PLUGIN_RUNTIME_STATE_CLUSTER_ID_MAX_LENGTH+1)
+	assert.NotNil(t, s.IsValid())
+	s.ClusterId = NewId()
+
+	s.Error = strings.Repeat("a", PLUGIN_RUNTIME_STATE_ERROR_MAX_RUNES+1)
+	assert.NotNil(t, s.IsValid())
+}
+
+func TestPluginRuntimeStatePreSave(t *testing.T) {
+	s := &PluginRuntimeState{
+		PluginId: "com.example.plugin",
+		Error:    strings.Repeat("é", PLUGIN_RUNTIME_STATE_ERROR_MAX_RUNES+1),
+	}
+	s.PreSave()
+
+	assert.NotZero(t, s.UpdateAt)
+	assert.Equal(t, strings.Repeat("é", PLUGIN_RUNTIME_STATE_ERROR_MAX_RUNES), s.Error)
+	assert.Nil(t, s.IsValid())
+}
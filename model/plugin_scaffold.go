@@ -0,0 +1,55 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+// PLUGIN_SCAFFOLD_VERSION is the version given to newly generated plugins.
+const PLUGIN_SCAFFOLD_VERSION = "0.1.0"
+
+// PluginScaffoldRequest describes the skeleton of a new plugin to be generated for a plugin
+// developer.
+type PluginScaffoldRequest struct {
+	PluginId string `json:"plugin_id"`
+
+	// Hooks are the names of the hooks, such as "MessageWillBePosted", implemented by the plugin's
+	// server component.
+	Hooks []string `json:"hooks"`
+
+	// Capabilities are declared in the plugin's manifest. If none are given, the plugin is granted
+	// the server's default capabilities.
+	Capabilities []string `json:"capabilities"`
+}
+
+func (r *PluginScaffoldRequest) ToJson() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func PluginScaffoldRequestFromJson(data io.Reader) *PluginScaffoldRequest {
+	var r *PluginScaffoldRequest
+	json.NewDecoder(data).Decode(&r)
+	return r
+}
+
+// IsValid checks the plugin id and capabilities. The hooks can only be checked against the plugin
+// package.
+func (r *PluginScaffoldRequest) IsValid() *AppError {
+	if utf8.RuneCountInString(r.PluginId) < PLUGIN_ID_MIN_LENGTH || utf8.RuneCountInString(r.PluginId) > PLUGIN_ID_MAX_LENGTH || !validPluginManifestId.MatchString(r.PluginId) {
+		return NewAppError("PluginScaffoldRequest.IsValid", "model.plugin_scaffold_request.is_valid.plugin_id.app_error", map[string]interface{}{"Min": PLUGIN_ID_MIN_LENGTH, "Max": PLUGIN_ID_MAX_LENGTH}, "plugin_id="+r.PluginId, http.StatusBadRequest)
+	}
+
+	for _, capability := range r.Capabilities {
+		if !IsValidPluginCapability(capability) {
+			return NewAppError("PluginScaffoldRequest.IsValid", "model.plugin_scaffold_request.is_valid.capability.app_error", map[string]interface{}{"Capability": capability}, "plugin_id="+r.PluginId, http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
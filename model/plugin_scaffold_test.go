@@ -0,0 +1,36 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginScaffoldRequestIsValid(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Request PluginScaffoldRequest
+		Error   string
+	}{
+		"valid":              {Request: PluginScaffoldRequest{PluginId: "com.example.plugin", Hooks: []string{"OnActivate"}, Capabilities: []string{PLUGIN_CAPABILITY_KV}}},
+		"no capabilities":    {Request: PluginScaffoldRequest{PluginId: "com.example.plugin"}},
+		"short plugin id":    {Request: PluginScaffoldRequest{PluginId: "ab"}, Error: "model.plugin_scaffold_request.is_valid.plugin_id.app_error"},
+		"invalid plugin id":  {Request: PluginScaffoldRequest{PluginId: "Com.Example"}, Error: "model.plugin_scaffold_request.is_valid.plugin_id.app_error"},
+		"unknown capability": {Request: PluginScaffoldRequest{PluginId: "com.example.plugin", Capabilities: []string{"everything"}}, Error: "model.plugin_scaffold_request.is_valid.capability.app_error"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.Request.IsValid()
+			if tc.Error == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Equal(t, tc.Error, err.Id)
+			}
+		})
+	}
+
+	request := &PluginScaffoldRequest{PluginId: "com.example.plugin", Hooks: []string{"OnActivate"}}
+	assert.Equal(t, request, PluginScaffoldRequestFromJson(strings.NewReader(request.ToJson())))
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"time"
+)
+
+// PluginServerHealth reports the health of the server and the services it depends on to plugins, so
+// that they may explain their own failures. It deliberately omits anything identifying the
+// underlying infrastructure, such as hosts or connection strings.
+type PluginServerHealth struct {
+	// Database is the health of the master database.
+	Database PluginHealthCheck `json:"database"`
+
+	// DatabaseReplicas is the health of the read replicas, or of the master database if there are
+	// none, since it then serves reads.
+	DatabaseReplicas PluginHealthCheck `json:"database_replicas"`
+
+	// FileStore is the health of the file store used for uploaded files and plugin bundles.
+	FileStore PluginHealthCheck `json:"file_store"`
+
+	// ClusterEnabled is whether the server runs as one node of a high availability cluster, and
+	// ClusterNodeCount the number of nodes known to it.
+	ClusterEnabled   bool `json:"cluster_enabled"`
+	ClusterNodeCount int  `json:"cluster_node_count"`
+
+	MetricsEnabled       bool `json:"metrics_enabled"`
+	ElasticsearchEnabled bool `json:"elasticsearch_enabled"`
+
+	// CheckedAt is when the checks were run, in milliseconds since the epoch. Results are reused for
+	// a few seconds, so this may be slightly in the past.
+	CheckedAt int64 `json:"checked_at"`
+}
+
+// PluginHealthCheck is the outcome of checking that a service can be reached.
+type PluginHealthCheck struct {
+	Healthy bool          `json:"healthy"`
+	Latency time.Duration `json:"latency"`
+
+	// Error describes why the service is unhealthy, without the details of the underlying error.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	PLUGIN_STATE_CHANGE_ENABLED       = "enabled"
+	PLUGIN_STATE_CHANGE_DISABLED      = "disabled"
+	PLUGIN_STATE_CHANGE_FAILED        = "failed"
+	PLUGIN_STATE_CHANGE_NOT_INSTALLED = "not_installed"
+)
+
+// PluginStateChangeResult reports the outcome of enabling or disabling a single plugin as part of a
+// bulk change of plugin states.
+type PluginStateChangeResult struct {
+	PluginId string `json:"plugin_id"`
+	Result   string `json:"result"`
+
+	// Error describes why the plugin failed to be enabled, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+type PluginStateChangeResults []*PluginStateChangeResult
+
+func (r PluginStateChangeResults) ToJson() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func PluginStateChangeResultsFromJson(data io.Reader) PluginStateChangeResults {
+	var r PluginStateChangeResults
+	json.NewDecoder(data).Decode(&r)
+	return r
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginStateChangeResultsJson(t *testing.T) {
+	results := PluginStateChangeResults{
+		{PluginId: "enabled.plugin", Result: PLUGIN_STATE_CHANGE_ENABLED},
+		{PluginId: "failed.plugin", Result: PLUGIN_STATE_CHANGE_FAILED, Error: "failed to start"},
+	}
+
+	json := results.ToJson()
+	assert.Equal(t, results, PluginStateChangeResultsFromJson(strings.NewReader(json)))
+	assert.Nil(t, PluginStateChangeResultsFromJson(strings.NewReader("junk")))
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	goi18n "github.com/nicksnyder/go-i18n/i18n"
+)
+
+// Reasons recorded with a plugin's configured state for why it was last enabled or disabled.
+const (
+	// PLUGIN_STATE_REASON_ADMIN is recorded when the plugin was enabled or disabled on request.
+	PLUGIN_STATE_REASON_ADMIN = "admin"
+
+	// PLUGIN_STATE_REASON_ACTIVATION_FAILED is recorded when the plugin was disabled again because it
+	// failed to start once enabled.
+	PLUGIN_STATE_REASON_ACTIVATION_FAILED = "activation_failed"
+
+	// PLUGIN_STATE_REASON_CRASH_LOOP is recorded when the plugin was disabled after failing to start
+	// too many consecutive times.
+	PLUGIN_STATE_REASON_CRASH_LOOP = "crash_loop"
+
+	// PLUGIN_STATE_REASON_PENDING_UPGRADE_REVIEW is recorded when the plugin was disabled on being
+	// upgraded to a version requesting capabilities that the installed version did not, until they are
+	// reviewed and the plugin enabled again.
+	PLUGIN_STATE_REASON_PENDING_UPGRADE_REVIEW = "pending_upgrade_review"
+)
+
+var pluginStateReasonMessageIds = map[string]string{
+	PLUGIN_STATE_REASON_ADMIN:                  "model.plugin_state.reason.admin",
+	PLUGIN_STATE_REASON_ACTIVATION_FAILED:      "model.plugin_state.reason.activation_failed",
+	PLUGIN_STATE_REASON_CRASH_LOOP:             "model.plugin_state.reason.crash_loop",
+	PLUGIN_STATE_REASON_PENDING_UPGRADE_REVIEW: "model.plugin_state.reason.pending_upgrade_review",
+}
+
+// NewPluginState returns the configured state of a plugin enabled or disabled now for the given
+// reason.
+func NewPluginState(enable bool, reason string) *PluginState {
+	return &PluginState{
+		Enable:    enable,
+		Reason:    reason,
+		UpdatedAt: GetMillis(),
+	}
+}
+
+// PluginStateReasonMessage returns the localized description of the given reason for a plugin's
+// state, or an empty string if the reason is not known.
+func PluginStateReasonMessage(reason string, T goi18n.TranslateFunc) string {
+	id, ok := pluginStateReasonMessageIds[reason]
+	if !ok {
+		return ""
+	}
+
+	return T(id)
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginStateJson(t *testing.T) {
+	t.Run("without reason", func(t *testing.T) {
+		config := ConfigFromJson(strings.NewReader(`{"PluginSettings": {"PluginStates": {"foo": {"Enable": true}}}}`))
+		require.NotNil(t, config)
+		assert.Equal(t, &PluginState{Enable: true}, config.PluginSettings.PluginStates["foo"])
+		assert.NotContains(t, config.ToJson(), "Reason")
+		assert.NotContains(t, config.ToJson(), "Removed")
+	})
+
+	t.Run("with reason", func(t *testing.T) {
+		config := &Config{}
+		config.SetDefaults()
+		config.PluginSettings.PluginStates["foo"] = &PluginState{Enable: false, Reason: PLUGIN_STATE_REASON_CRASH_LOOP, UpdatedAt: 1000}
+
+		decoded := ConfigFromJson(strings.NewReader(config.ToJson()))
+		require.NotNil(t, decoded)
+		assert.Equal(t, config.PluginSettings.PluginStates["foo"], decoded.PluginSettings.PluginStates["foo"])
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		config := ConfigFromJson(strings.NewReader(`{"PluginSettings": {"PluginStates": {"foo": {"Enable": true, "Removed": true}}}}`))
+		require.NotNil(t, config)
+		assert.Equal(t, &PluginState{Enable: true, Removed: true}, config.PluginSettings.PluginStates["foo"])
+	})
+}
+
+func TestNewPluginState(t *testing.T) {
+	state := NewPluginState(true, PLUGIN_STATE_REASON_ADMIN)
+	assert.True(t, state.Enable)
+	assert.Equal(t, PLUGIN_STATE_REASON_ADMIN, state.Reason)
+	assert.NotZero(t, state.UpdatedAt)
+}
+
+func TestPluginStateReasonMessage(t *testing.T) {
+	T := func(translationID string, args ...interface{}) string {
+		return "translated " + translationID
+	}
+
+	assert.Equal(t, "translated model.plugin_state.reason.crash_loop", PluginStateReasonMessage(PLUGIN_STATE_REASON_CRASH_LOOP, T))
+	assert.Equal(t, "", PluginStateReasonMessage("", T))
+	assert.Equal(t, "", PluginStateReasonMessage("unknown", T))
+}
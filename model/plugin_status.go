@@ -10,11 +10,12 @@ import (
 
 const (
 	PluginStateNotRunning          = 0
-	PluginStateStarting            = 1 // unused by server
+	PluginStateStarting            = 1
 	PluginStateRunning             = 2
 	PluginStateFailedToStart       = 3
-	PluginStateFailedToStayRunning = 4 // unused by server
+	PluginStateFailedToStayRunning = 4 // the plugin's process exited while it was running
 	PluginStateStopping            = 5 // unused by server
+	PluginStateFailedToLoad        = 6
 )
 
 // PluginStatus provides a cluster-aware view of installed plugins.
@@ -26,6 +27,29 @@ type PluginStatus struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Version     string `json:"version"`
+
+	// Error is the reason the plugin failed to load, in which case only its path is known, the
+	// reason it last failed to start, or that its process exited while it was running.
+	Error string `json:"error,omitempty"`
+
+	// FailureCount is the number of consecutive times the plugin failed to start.
+	FailureCount int `json:"failure_count,omitempty"`
+
+	// Enabled is whether the plugin is configured to be running, which it may not be if it failed to
+	// start.
+	Enabled bool `json:"enabled"`
+
+	// StateReason is why the plugin was last enabled or disabled, as one of the PLUGIN_STATE_REASON_*
+	// codes, StateReasonMessage its localized description, and StateUpdatedAt the time at which it was.
+	StateReason        string `json:"state_reason,omitempty"`
+	StateReasonMessage string `json:"state_reason_message,omitempty"`
+	StateUpdatedAt     int64  `json:"state_updated_at,omitempty"`
+
+	// BundlesRemoved is the number of orphaned plugin directories removed by the server reporting the
+	// status since it started, and BundleCleanupAt when it last looked for them. Both are only reported
+	// while PluginSettings.EnableBundleCleanup is set.
+	BundlesRemoved  int64 `json:"bundles_removed,omitempty"`
+	BundleCleanupAt int64 `json:"bundle_cleanup_at,omitempty"`
 }
 
 type PluginStatuses []*PluginStatus
@@ -0,0 +1,96 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	PLUGIN_SUBSCRIPTION_DESCRIPTION_MAX_RUNES = 1024
+	PLUGIN_SUBSCRIPTION_PAYLOAD_MAX_BYTES     = 8192
+)
+
+// PluginSubscription records that a plugin delivers something to a channel on behalf of the user
+// who set it up, such as the activity of a repository, so that the integrations in a channel can be
+// listed across plugins.
+type PluginSubscription struct {
+	Id        string `json:"id"`
+	PluginId  string `json:"plugin_id"`
+	ChannelId string `json:"channel_id"`
+	CreatorId string `json:"creator_id"`
+	CreateAt  int64  `json:"create_at"`
+
+	// Description is shown to users listing the subscriptions of a channel.
+	Description string `json:"description"`
+
+	// Payload is arbitrary data kept by the plugin, which is only ever returned to it.
+	Payload []byte `json:"payload,omitempty"`
+}
+
+func (s *PluginSubscription) PreSave() {
+	if s.Id == "" {
+		s.Id = NewId()
+	}
+
+	if s.CreateAt == 0 {
+		s.CreateAt = GetMillis()
+	}
+}
+
+func (s *PluginSubscription) IsValid() *AppError {
+	if !IsValidId(s.Id) {
+		return NewAppError("PluginSubscription.IsValid", "model.plugin_subscription.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(s.PluginId) == 0 || utf8.RuneCountInString(s.PluginId) > KEY_VALUE_PLUGIN_ID_MAX_RUNES {
+		return NewAppError("PluginSubscription.IsValid", "model.plugin_subscription.is_valid.plugin_id.app_error", nil, "id="+s.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidId(s.ChannelId) {
+		return NewAppError("PluginSubscription.IsValid", "model.plugin_subscription.is_valid.channel_id.app_error", nil, "id="+s.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidId(s.CreatorId) {
+		return NewAppError("PluginSubscription.IsValid", "model.plugin_subscription.is_valid.creator_id.app_error", nil, "id="+s.Id, http.StatusBadRequest)
+	}
+
+	if s.CreateAt == 0 {
+		return NewAppError("PluginSubscription.IsValid", "model.plugin_subscription.is_valid.create_at.app_error", nil, "id="+s.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(s.Description) > PLUGIN_SUBSCRIPTION_DESCRIPTION_MAX_RUNES {
+		return NewAppError("PluginSubscription.IsValid", "model.plugin_subscription.is_valid.description.app_error", map[string]interface{}{"Max": PLUGIN_SUBSCRIPTION_DESCRIPTION_MAX_RUNES}, "id="+s.Id, http.StatusBadRequest)
+	}
+
+	if len(s.Payload) > PLUGIN_SUBSCRIPTION_PAYLOAD_MAX_BYTES {
+		return NewAppError("PluginSubscription.IsValid", "model.plugin_subscription.is_valid.payload.app_error", map[string]interface{}{"Max": PLUGIN_SUBSCRIPTION_PAYLOAD_MAX_BYTES}, "id="+s.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// Sanitize removes the payload, which is private to the plugin owning the subscription.
+func (s *PluginSubscription) Sanitize() {
+	s.Payload = nil
+}
+
+func (s *PluginSubscription) ToJson() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func PluginSubscriptionListToJson(l []*PluginSubscription) string {
+	b, _ := json.Marshal(l)
+	return string(b)
+}
+
+func PluginSubscriptionListFromJson(data io.Reader) []*PluginSubscription {
+	var l []*PluginSubscription
+	json.NewDecoder(data).Decode(&l)
+	return l
+}
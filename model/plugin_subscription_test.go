@@ -0,0 +1,55 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginSubscriptionIsValid(t *testing.T) {
+	s := &PluginSubscription{
+		PluginId:    "com.example.plugin",
+		ChannelId:   NewId(),
+		CreatorId:   NewId(),
+		Description: "Activity of example/repo",
+		Payload:     []byte(`{"repo":"example/repo"}`),
+	}
+	assert.NotNil(t, s.IsValid())
+
+	s.PreSave()
+	assert.Nil(t, s.IsValid())
+
+	s.PluginId = ""
+	assert.NotNil(t, s.IsValid())
+	s.PluginId = "com.example.plugin"
+
+	s.ChannelId = "junk"
+	assert.NotNil(t, s.IsValid())
+	s.ChannelId = NewId()
+
+	s.CreatorId = ""
+	assert.NotNil(t, s.IsValid())
+	s.CreatorId = NewId()
+
+	s.Description = strings.Repeat("a", PLUGIN_SUBSCRIPTION_DESCRIPTION_MAX_RUNES+1)
+	assert.NotNil(t, s.IsValid())
+	s.Description = ""
+	assert.Nil(t, s.IsValid())
+
+	s.Payload = make([]byte, PLUGIN_SUBSCRIPTION_PAYLOAD_MAX_BYTES+1)
+	assert.NotNil(t, s.IsValid())
+}
+
+func TestPluginSubscriptionListJson(t *testing.T) {
+	s := &PluginSubscription{PluginId: "com.example.plugin", ChannelId: NewId(), CreatorId: NewId(), Payload: []byte("payload")}
+	s.PreSave()
+	s.Sanitize()
+
+	l := PluginSubscriptionListFromJson(strings.NewReader(PluginSubscriptionListToJson([]*PluginSubscription{s})))
+	assert.Equal(t, []*PluginSubscription{s}, l)
+	assert.NotContains(t, s.ToJson(), "payload\":")
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// PluginValidationReport describes whether a plugin bundle would be accepted for installation,
+// listing every problem found with it. Warnings describe problems that do not prevent installation.
+type PluginValidationReport struct {
+	Manifest *Manifest   `json:"manifest,omitempty"`
+	Valid    bool        `json:"valid"`
+	Findings []*AppError `json:"findings"`
+	Warnings []*AppError `json:"warnings,omitempty"`
+}
+
+func (r *PluginValidationReport) ToJson() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func PluginValidationReportFromJson(data io.Reader) *PluginValidationReport {
+	var r *PluginValidationReport
+	json.NewDecoder(data).Decode(&r)
+	return r
+}
+
+// PluginInstallResult describes a newly installed plugin. It is encoded as the plugin's manifest,
+// alongside warnings about any problems found that did not prevent its installation.
+type PluginInstallResult struct {
+	*Manifest
+	Warnings []*AppError `json:"warnings,omitempty"`
+}
+
+func (r *PluginInstallResult) ToJson() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func PluginInstallResultFromJson(data io.Reader) *PluginInstallResult {
+	var r *PluginInstallResult
+	json.NewDecoder(data).Decode(&r)
+	return r
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginValidationReportJson(t *testing.T) {
+	report := &PluginValidationReport{
+		Manifest: &Manifest{
+			Id: "theid",
+			Webapp: &ManifestWebapp{
+				BundlePath: "thebundlepath",
+			},
+		},
+		Valid: false,
+		Findings: []*AppError{
+			NewAppError("where", "some.id", nil, "details", http.StatusBadRequest),
+		},
+	}
+
+	json := report.ToJson()
+	newReport := PluginValidationReportFromJson(strings.NewReader(json))
+	assert.Equal(t, report.Manifest, newReport.Manifest)
+	assert.False(t, newReport.Valid)
+	assert.Len(t, newReport.Findings, 1)
+	assert.Equal(t, "some.id", newReport.Findings[0].Id)
+	assert.Equal(t, "details", newReport.Findings[0].DetailedError)
+	assert.Equal(t, (*PluginValidationReport)(nil), PluginValidationReportFromJson(strings.NewReader("junk")))
+}
+
+func TestPluginInstallResultJson(t *testing.T) {
+	result := &PluginInstallResult{
+		Manifest: &Manifest{
+			Id:      "theid",
+			ApiSpec: "api.json",
+		},
+		Warnings: []*AppError{
+			NewAppError("where", "some.id", nil, "details", http.StatusBadRequest),
+		},
+	}
+
+	json := result.ToJson()
+	newResult := PluginInstallResultFromJson(strings.NewReader(json))
+	assert.Equal(t, result.Manifest, newResult.Manifest)
+	assert.Len(t, newResult.Warnings, 1)
+	assert.Equal(t, "some.id", newResult.Warnings[0].Id)
+
+	// Clients expecting just the manifest can still read it.
+	assert.Equal(t, result.Manifest, ManifestFromJson(strings.NewReader(json)))
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	PLUGIN_WEBAPP_MESSAGE_TYPE_MAX_RUNES = 64
+
+	// PLUGIN_WEBAPP_MESSAGE_PAYLOAD_MAX_SIZE is the largest payload, in bytes, of messages sent to a
+	// plugin's webapp component and of the replies it sends back.
+	PLUGIN_WEBAPP_MESSAGE_PAYLOAD_MAX_SIZE = 64 * 1024
+
+	PLUGIN_WEBAPP_MESSAGE_DEFAULT_TIMEOUT_SECONDS = 60
+	PLUGIN_WEBAPP_MESSAGE_MAX_TIMEOUT_SECONDS     = 60 * 60
+)
+
+// PluginWebappMessage is a message sent by a plugin to its webapp component in a user's clients,
+// which may reply to it.
+type PluginWebappMessage struct {
+	// Type identifies the message to the plugin's webapp component, which may handle several.
+	Type string `json:"type"`
+
+	// Payload is passed on to the plugin's webapp component as text, typically JSON.
+	Payload []byte `json:"payload,omitempty"`
+
+	// TimeoutSeconds is how long the server waits for a reply, after which the plugin is told that
+	// none was received. Zero means PLUGIN_WEBAPP_MESSAGE_DEFAULT_TIMEOUT_SECONDS.
+	TimeoutSeconds int64 `json:"timeout_seconds,omitempty"`
+}
+
+func (m *PluginWebappMessage) IsValid() *AppError {
+	if len(m.Type) == 0 || utf8.RuneCountInString(m.Type) > PLUGIN_WEBAPP_MESSAGE_TYPE_MAX_RUNES {
+		return NewAppError("PluginWebappMessage.IsValid", "model.plugin_webapp_message.is_valid.type.app_error", map[string]interface{}{"Max": PLUGIN_WEBAPP_MESSAGE_TYPE_MAX_RUNES}, "", http.StatusBadRequest)
+	}
+
+	if len(m.Payload) > PLUGIN_WEBAPP_MESSAGE_PAYLOAD_MAX_SIZE {
+		return NewAppError("PluginWebappMessage.IsValid", "model.plugin_webapp_message.is_valid.payload.app_error", map[string]interface{}{"Max": PLUGIN_WEBAPP_MESSAGE_PAYLOAD_MAX_SIZE}, "type="+m.Type, http.StatusRequestEntityTooLarge)
+	}
+
+	if m.TimeoutSeconds < 0 || m.TimeoutSeconds > PLUGIN_WEBAPP_MESSAGE_MAX_TIMEOUT_SECONDS {
+		return NewAppError("PluginWebappMessage.IsValid", "model.plugin_webapp_message.is_valid.timeout.app_error", map[string]interface{}{"Max": PLUGIN_WEBAPP_MESSAGE_MAX_TIMEOUT_SECONDS}, "type="+m.Type, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// Timeout returns how long, in seconds, the server waits for a reply to the message.
+func (m *PluginWebappMessage) Timeout() int64 {
+	if m.TimeoutSeconds == 0 {
+		return PLUGIN_WEBAPP_MESSAGE_DEFAULT_TIMEOUT_SECONDS
+	}
+	return m.TimeoutSeconds
+}
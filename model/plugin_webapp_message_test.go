@@ -0,0 +1,39 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginWebappMessageIsValid(t *testing.T) {
+	message := &PluginWebappMessage{
+		Type:    "confirm",
+		Payload: []byte(`{"question": "Delete the repository?"}`),
+	}
+	require.Nil(t, message.IsValid())
+	assert.Equal(t, int64(PLUGIN_WEBAPP_MESSAGE_DEFAULT_TIMEOUT_SECONDS), message.Timeout())
+
+	message.TimeoutSeconds = 10
+	require.Nil(t, message.IsValid())
+	assert.Equal(t, int64(10), message.Timeout())
+
+	for name, invalid := range map[string]*PluginWebappMessage{
+		"missing type":       {},
+		"long type":          {Type: strings.Repeat("a", PLUGIN_WEBAPP_MESSAGE_TYPE_MAX_RUNES+1)},
+		"negative timeout":   {Type: "confirm", TimeoutSeconds: -1},
+		"excessive timeout":  {Type: "confirm", TimeoutSeconds: PLUGIN_WEBAPP_MESSAGE_MAX_TIMEOUT_SECONDS + 1},
+		"oversized payloads": {Type: "confirm", Payload: make([]byte, PLUGIN_WEBAPP_MESSAGE_PAYLOAD_MAX_SIZE+1)},
+	} {
+		assert.NotNil(t, invalid.IsValid(), name)
+	}
+
+	message = &PluginWebappMessage{Type: "confirm", Payload: make([]byte, PLUGIN_WEBAPP_MESSAGE_PAYLOAD_MAX_SIZE+1)}
+	assert.Equal(t, http.StatusRequestEntityTooLarge, message.IsValid().StatusCode)
+}
@@ -10,11 +10,34 @@ import (
 
 type PluginInfo struct {
 	Manifest
+
+	// Enabled is whether the plugin is configured to be running, which it may not be if it failed to
+	// start.
+	Enabled bool `json:"enabled"`
+
+	// StateReason is why the plugin was last enabled or disabled, as one of the PLUGIN_STATE_REASON_*
+	// codes, StateReasonMessage its localized description, and StateUpdatedAt the time at which it was.
+	StateReason        string `json:"state_reason,omitempty"`
+	StateReasonMessage string `json:"state_reason_message,omitempty"`
+	StateUpdatedAt     int64  `json:"state_updated_at,omitempty"`
+}
+
+// PluginLimits reports the number of installed plugins alongside the configured install limits,
+// allowing clients to warn before a limit is reached.
+type PluginLimits struct {
+	InstalledPlugins    int   `json:"installed_plugins"`
+	MaxInstalledPlugins int   `json:"max_installed_plugins"`
+	MaxBundleSize       int64 `json:"max_bundle_size"`
+	MaxExtractedSize    int64 `json:"max_extracted_size"`
 }
 
 type PluginsResponse struct {
 	Active   []*PluginInfo `json:"active"`
 	Inactive []*PluginInfo `json:"inactive"`
+	Limits   *PluginLimits `json:"limits,omitempty"`
+
+	// Errors lists the directories in the plugin directory that could not be loaded as plugins.
+	Errors []*PluginError `json:"errors,omitempty"`
 }
 
 func (m *PluginsResponse) ToJson() string {
@@ -50,6 +50,9 @@ const (
 	PROPS_ADD_CHANNEL_MEMBER    = "add_channel_member"
 	POST_PROPS_ADDED_USER_ID    = "addedUserId"
 	POST_PROPS_DELETE_BY        = "deleteBy"
+	POST_PROPS_REQUESTED_ACK    = "requested_ack"
+	POST_PROPS_PLUGIN_METADATA  = "plugin_metadata"
+	POST_PROPS_DELETED_FILE_IDS = "deleted_file_ids"
 )
 
 type Post struct {
@@ -243,6 +246,8 @@ func (o *Post) IsValid(maxPostSize int) *AppError {
 func (o *Post) SanitizeProps() {
 	membersToSanitize := []string{
 		PROPS_ADD_CHANNEL_MEMBER,
+		POST_PROPS_PLUGIN_METADATA,
+		POST_PROPS_DELETED_FILE_IDS,
 	}
 
 	for _, member := range membersToSanitize {
@@ -296,6 +301,80 @@ func (o *Post) AddProp(key string, value interface{}) {
 	o.Props[key] = value
 }
 
+// GetPluginMetadata returns the metadata attached to the post by plugins, keyed by namespace.
+func (o *Post) GetPluginMetadata() map[string]interface{} {
+	metadata, _ := o.Props[POST_PROPS_PLUGIN_METADATA].(map[string]interface{})
+	return metadata
+}
+
+// SetPluginMetadata attaches the given metadata to the post under the namespace, replacing any
+// already there.
+func (o *Post) SetPluginMetadata(namespace string, data interface{}) {
+	metadata := make(map[string]interface{})
+	for key, value := range o.GetPluginMetadata() {
+		metadata[key] = value
+	}
+	metadata[namespace] = data
+
+	o.AddProp(POST_PROPS_PLUGIN_METADATA, metadata)
+}
+
+// DeletePluginMetadata removes the metadata attached to the post under the namespace, if any.
+func (o *Post) DeletePluginMetadata(namespace string) {
+	metadata := make(map[string]interface{})
+	for key, value := range o.GetPluginMetadata() {
+		if key != namespace {
+			metadata[key] = value
+		}
+	}
+
+	if len(metadata) == 0 {
+		delete(o.Props, POST_PROPS_PLUGIN_METADATA)
+		return
+	}
+
+	o.AddProp(POST_PROPS_PLUGIN_METADATA, metadata)
+}
+
+// GetDeletedFileIds returns the ids of the files deleted after being attached to the post.
+func (o *Post) GetDeletedFileIds() []string {
+	values, _ := o.Props[POST_PROPS_DELETED_FILE_IDS].([]interface{})
+
+	var fileIds []string
+	for _, value := range values {
+		if fileId, ok := value.(string); ok {
+			fileIds = append(fileIds, fileId)
+		}
+	}
+	return fileIds
+}
+
+// DetachFile removes the file from the post, recording its id in the post's props so that clients
+// can show that a file was deleted. It returns whether the file was attached to the post.
+func (o *Post) DetachFile(fileId string) bool {
+	var fileIds StringArray
+	for _, id := range o.FileIds {
+		if id != fileId {
+			fileIds = append(fileIds, id)
+		}
+	}
+	if len(fileIds) == len(o.FileIds) {
+		return false
+	}
+	if fileIds == nil {
+		fileIds = StringArray{}
+	}
+	o.FileIds = fileIds
+
+	var deleted []interface{}
+	for _, id := range o.GetDeletedFileIds() {
+		deleted = append(deleted, id)
+	}
+	o.AddProp(POST_PROPS_DELETED_FILE_IDS, append(deleted, fileId))
+
+	return true
+}
+
 func (o *Post) IsSystemMessage() bool {
 	return len(o.Type) >= len(POST_SYSTEM_MESSAGE_PREFIX) && o.Type[:len(POST_SYSTEM_MESSAGE_PREFIX)] == POST_SYSTEM_MESSAGE_PREFIX
 }
@@ -0,0 +1,62 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// PostAcknowledgement records that a user has acknowledged a post for which a plugin requested
+// acknowledgements, such as an emergency broadcast.
+type PostAcknowledgement struct {
+	PostId         string `json:"post_id"`
+	UserId         string `json:"user_id"`
+	AcknowledgedAt int64  `json:"acknowledged_at"`
+}
+
+func (a *PostAcknowledgement) PreSave() {
+	if a.AcknowledgedAt == 0 {
+		a.AcknowledgedAt = GetMillis()
+	}
+}
+
+func (a *PostAcknowledgement) IsValid() *AppError {
+	if !IsValidId(a.PostId) {
+		return NewAppError("PostAcknowledgement.IsValid", "model.post_acknowledgement.is_valid.post_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidId(a.UserId) {
+		return NewAppError("PostAcknowledgement.IsValid", "model.post_acknowledgement.is_valid.user_id.app_error", nil, "post_id="+a.PostId, http.StatusBadRequest)
+	}
+
+	if a.AcknowledgedAt == 0 {
+		return NewAppError("PostAcknowledgement.IsValid", "model.post_acknowledgement.is_valid.acknowledged_at.app_error", nil, "post_id="+a.PostId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (a *PostAcknowledgement) ToJson() string {
+	b, _ := json.Marshal(a)
+	return string(b)
+}
+
+func PostAcknowledgementFromJson(data io.Reader) *PostAcknowledgement {
+	var a *PostAcknowledgement
+	json.NewDecoder(data).Decode(&a)
+	return a
+}
+
+func PostAcknowledgementListToJson(l []*PostAcknowledgement) string {
+	b, _ := json.Marshal(l)
+	return string(b)
+}
+
+func PostAcknowledgementListFromJson(data io.Reader) []*PostAcknowledgement {
+	var l []*PostAcknowledgement
+	json.NewDecoder(data).Decode(&l)
+	return l
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostAcknowledgementIsValid(t *testing.T) {
+	a := &PostAcknowledgement{
+		PostId: NewId(),
+		UserId: NewId(),
+	}
+	assert.NotNil(t, a.IsValid())
+
+	a.PreSave()
+	assert.Nil(t, a.IsValid())
+
+	a.PostId = "junk"
+	assert.NotNil(t, a.IsValid())
+	a.PostId = NewId()
+
+	a.UserId = ""
+	assert.NotNil(t, a.IsValid())
+}
+
+func TestPostAcknowledgementJson(t *testing.T) {
+	a := &PostAcknowledgement{PostId: NewId(), UserId: NewId()}
+	a.PreSave()
+
+	assert.Equal(t, a, PostAcknowledgementFromJson(strings.NewReader(a.ToJson())))
+
+	l := PostAcknowledgementListFromJson(strings.NewReader(PostAcknowledgementListToJson([]*PostAcknowledgement{a})))
+	assert.Equal(t, []*PostAcknowledgement{a}, l)
+}
@@ -133,6 +133,57 @@ func TestPostChannelMentions(t *testing.T) {
 	assert.Equal(t, []string{"a", "b", "c", "d"}, post.ChannelMentions())
 }
 
+func TestPostPluginMetadata(t *testing.T) {
+	post := &Post{Message: "test"}
+	assert.Nil(t, post.GetPluginMetadata())
+
+	post.SetPluginMetadata("translations", map[string]interface{}{"es": "prueba"})
+	post.SetPluginMetadata("other", "value")
+	assert.Equal(t, map[string]interface{}{
+		"translations": map[string]interface{}{"es": "prueba"},
+		"other":        "value",
+	}, post.GetPluginMetadata())
+
+	// Metadata survives a round trip through JSON, as when the post is stored.
+	post = PostFromJson(strings.NewReader(post.ToJson()))
+	post.SetPluginMetadata("translations", map[string]interface{}{"fr": "essai"})
+	assert.Equal(t, map[string]interface{}{"fr": "essai"}, post.GetPluginMetadata()["translations"])
+
+	post.DeletePluginMetadata("translations")
+	assert.Equal(t, map[string]interface{}{"other": "value"}, post.GetPluginMetadata())
+
+	post.DeletePluginMetadata("other")
+	_, ok := post.Props[POST_PROPS_PLUGIN_METADATA]
+	assert.False(t, ok)
+
+	// Users cannot attach plugin metadata themselves.
+	post.SetPluginMetadata("translations", "forged")
+	post.SanitizeProps()
+	assert.Nil(t, post.GetPluginMetadata())
+}
+
+func TestPostDetachFile(t *testing.T) {
+	post := &Post{Message: "test", FileIds: StringArray{"file1", "file2"}}
+	assert.Nil(t, post.GetDeletedFileIds())
+
+	assert.True(t, post.DetachFile("file1"))
+	assert.Equal(t, StringArray{"file2"}, post.FileIds)
+	assert.Equal(t, []string{"file1"}, post.GetDeletedFileIds())
+
+	assert.False(t, post.DetachFile("file1"))
+	assert.Equal(t, []string{"file1"}, post.GetDeletedFileIds())
+
+	// Deleted files survive a round trip through JSON, as when the post is stored.
+	post = PostFromJson(strings.NewReader(post.ToJson()))
+	assert.True(t, post.DetachFile("file2"))
+	assert.Equal(t, StringArray{}, post.FileIds)
+	assert.Equal(t, []string{"file1", "file2"}, post.GetDeletedFileIds())
+
+	// Users cannot mark files as deleted themselves.
+	post.SanitizeProps()
+	assert.Nil(t, post.GetDeletedFileIds())
+}
+
 func TestPostSanitizeProps(t *testing.T) {
 	post1 := &Post{
 		Message: "test",
@@ -19,6 +19,9 @@ const (
 	PREFERENCE_CATEGORY_FLAGGED_POST        = "flagged_post"
 	PREFERENCE_CATEGORY_FAVORITE_CHANNEL    = "favorite_channel"
 
+	PREFERENCE_CATEGORY_FOLLOWED_THREAD = "followed_thread"
+	// the name for followed_thread is the id of the thread's root post
+
 	PREFERENCE_CATEGORY_DISPLAY_SETTINGS = "display_settings"
 	PREFERENCE_NAME_COLLAPSE_SETTING     = "collapse_previews"
 
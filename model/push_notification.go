@@ -19,6 +19,10 @@ const (
 	PUSH_TYPE_CLEAR   = "clear"
 	PUSH_MESSAGE_V2   = "v2"
 
+	// The maximum length of the message text in a push notification, leaving room for the rest
+	// of the payload within the limits of the push services
+	PUSH_MESSAGE_MAX_RUNES = 1024
+
 	// The category is set to handle a set of interactive Actions
 	// with the push notifications
 	CATEGORY_CAN_REPLY = "CAN_REPLY"
@@ -15,6 +15,7 @@ type TeamMember struct {
 	UserId        string `json:"user_id"`
 	Roles         string `json:"roles"`
 	DeleteAt      int64  `json:"delete_at"`
+	UpdateAt      int64  `json:"update_at"`
 	SchemeUser    bool   `json:"scheme_user"`
 	SchemeAdmin   bool   `json:"scheme_admin"`
 	ExplicitRoles string `json:"explicit_roles"`
@@ -89,7 +90,12 @@ func (o *TeamMember) IsValid() *AppError {
 	return nil
 }
 
+func (o *TeamMember) PreSave() {
+	o.UpdateAt = GetMillis()
+}
+
 func (o *TeamMember) PreUpdate() {
+	o.UpdateAt = GetMillis()
 }
 
 func (o *TeamMember) GetRoles() []string {
@@ -4,6 +4,7 @@
 package model
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -74,6 +75,10 @@ type User struct {
 	MfaActive          bool      `json:"mfa_active,omitempty"`
 	MfaSecret          string    `json:"mfa_secret,omitempty"`
 	LastActivityAt     int64     `db:"-" json:"last_activity_at,omitempty"`
+
+	// PluginPreferences holds the settings stored for the user by each active plugin, keyed by plugin
+	// id. It is only set when the user fetches themselves.
+	PluginPreferences map[string]map[string]string `db:"-" json:"plugin_preferences,omitempty"`
 }
 
 type UserPatch struct {
@@ -348,6 +353,12 @@ func (u *UserAuth) ToJson() string {
 
 // Generate a valid strong etag so the browser can cache the results
 func (u *User) Etag(showFullName, showEmail bool) string {
+	if len(u.PluginPreferences) > 0 {
+		// Plugin settings change without updating the user.
+		b, _ := json.Marshal(u.PluginPreferences)
+		return Etag(u.Id, u.UpdateAt, showFullName, showEmail, fmt.Sprintf("%x", sha256.Sum256(b)))
+	}
+
 	return Etag(u.Id, u.UpdateAt, showFullName, showEmail)
 }
 
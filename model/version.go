@@ -78,6 +78,12 @@ func init() {
 	}
 }
 
+// IsDevBuild returns whether the server was built for development rather than released, in which
+// case it may provide APIs newer than CurrentVersion.
+func IsDevBuild() bool {
+	return BuildNumber == "" || BuildNumber == "dev"
+}
+
 func SplitVersion(version string) (int64, int64, int64) {
 	parts := strings.Split(version, ".")
 
@@ -4,6 +4,8 @@
 package plugin
 
 import (
+	"time"
+
 	"github.com/hashicorp/go-plugin"
 	"github.com/mattermost/mattermost-server/model"
 )
@@ -13,6 +15,10 @@ import (
 //
 // Plugins obtain access to the API by embedding MattermostPlugin and accessing the API member
 // directly.
+//
+// Each method requires the capabilities listed for it in APICapabilities, to be declared in the
+// plugin's manifest. Calls outside of them fail with an app.plugin.capability.denied.app_error
+// error, or return the zero value for methods returning no error.
 type API interface {
 	// LoadPluginConfiguration loads the plugin's configuration. dest should be a pointer to a
 	// struct that the configuration JSON can be unmarshalled to.
@@ -31,6 +37,25 @@ type API interface {
 	// SaveConfig sets the given config and persists the changes
 	SaveConfig(config *model.Config) *model.AppError
 
+	// GetSiteURL returns the configured site URL, without a trailing slash, for building links back
+	// to the server.
+	GetSiteURL() string
+
+	// GetSiteName returns the configured site name.
+	GetSiteName() string
+
+	// GetSupportEmail returns the configured support email address.
+	GetSupportEmail() string
+
+	// GetBrandImage returns the custom brand image uploaded by the system administrator. A not found
+	// error is returned when custom branding is disabled or no image has been uploaded.
+	GetBrandImage() ([]byte, *model.AppError)
+
+	// GetAllowedTeams returns the ids of the only teams on which the plugin's commands, web app
+	// components and HTTP requests are available, as configured by the system administrator. An
+	// empty list means the plugin is available on every team.
+	GetAllowedTeams() []string
+
 	// CreateUser creates a user.
 	CreateUser(user *model.User) (*model.User, *model.AppError)
 
@@ -49,6 +74,19 @@ type API interface {
 	// UpdateUser updates a user.
 	UpdateUser(user *model.User) (*model.User, *model.AppError)
 
+	// GetUserNotifyProps gets a user's notification preferences.
+	GetUserNotifyProps(userId string) (model.StringMap, *model.AppError)
+
+	// SetUserPluginSetting stores a setting for a user in the plugin's namespace of the user's
+	// preferences, or deletes it if the value is empty. The settings of active plugins are included
+	// under plugin_preferences when the user fetches themselves, so that webapp components can use
+	// them as soon as they start. A plugin may store up to 100 settings for each user, of at most
+	// 32KB in total, and keys are at most 32 bytes.
+	SetUserPluginSetting(userId, key, value string) *model.AppError
+
+	// GetUserPluginSettings gets the settings the plugin has stored for a user, keyed by name.
+	GetUserPluginSettings(userId string) (map[string]string, *model.AppError)
+
 	// GetUserStatus will get a user's status.
 	GetUserStatus(userId string) (*model.Status, *model.AppError)
 
@@ -59,6 +97,17 @@ type API interface {
 	// The status parameter can be: "online", "away", "dnd", or "offline".
 	UpdateUserStatus(userId, status string) (*model.Status, *model.AppError)
 
+	// GetSessions gets the sessions of a user, without their tokens.
+	GetSessions(userId string) ([]*model.Session, *model.AppError)
+
+	// RevokeSession revokes a session, closing the websocket connections authenticated with it so
+	// that its user must log in again.
+	RevokeSession(sessionId string) *model.AppError
+
+	// RevokeAllSessionsForUser revokes all sessions of a user, including those of OAuth apps, and
+	// closes all of the user's websocket connections.
+	RevokeAllSessionsForUser(userId string) *model.AppError
+
 	// CreateTeam creates a team.
 	CreateTeam(team *model.Team) (*model.Team, *model.AppError)
 
@@ -92,15 +141,37 @@ type API interface {
 	// GetTeamMember returns a specific membership.
 	GetTeamMember(teamId, userId string) (*model.TeamMember, *model.AppError)
 
+	// GetTeamMembersModifiedSince returns the memberships of a specific team that were created or
+	// updated, including by the user leaving the team, after the given time in milliseconds. Memberships
+	// of users that have left the team have a non-zero DeleteAt. Memberships are ordered by UpdateAt.
+	//
+	// Changes are found by comparing timestamps taken from the clock of the server that made them, and
+	// a change may only become visible some time after it is stamped. A plugin that passes the time of
+	// its previous run as since can therefore miss changes: rather than a timestamp from its own clock,
+	// it should pass the newest timestamp it has seen, less a safety margin of a few minutes, and
+	// expect to see some changes more than once.
+	GetTeamMembersModifiedSince(teamId string, since int64) ([]*model.TeamMember, *model.AppError)
+
+	// GetTeamStats returns the member counts of a specific team.
+	GetTeamStats(teamId string) (*model.TeamStats, *model.AppError)
+
 	// UpdateTeamMemberRoles updates the role for a team membership.
 	UpdateTeamMemberRoles(teamId, userId, newRoles string) (*model.TeamMember, *model.AppError)
 
 	// CreateChannel creates a channel.
 	CreateChannel(channel *model.Channel) (*model.Channel, *model.AppError)
 
-	// DeleteChannel deletes a channel.
+	// DeleteChannel archives a channel. The default channel of a team cannot be archived.
 	DeleteChannel(channelId string) *model.AppError
 
+	// RestoreChannel restores an archived channel. Channels belonging to an archived team cannot be
+	// restored.
+	RestoreChannel(channelId string) (*model.Channel, *model.AppError)
+
+	// ConvertChannelToPrivate converts a public channel to a private one. The default channel of a
+	// team cannot be converted.
+	ConvertChannelToPrivate(channelId string) (*model.Channel, *model.AppError)
+
 	// GetPublicChannelsForTeam gets a list of all channels.
 	GetPublicChannelsForTeam(teamId string, offset, limit int) (*model.ChannelList, *model.AppError)
 
@@ -122,21 +193,63 @@ type API interface {
 	// UpdateChannel updates a channel.
 	UpdateChannel(channel *model.Channel) (*model.Channel, *model.AppError)
 
-	// AddChannelMember creates a channel membership for a user.
-	AddChannelMember(channelId, userId string) (*model.ChannelMember, *model.AppError)
+	// SetChannelModerationForMembers sets whether the members of a channel may post, for example to
+	// make it a read-only announcements channel. Restricting posting moves the channel to a channel
+	// scheme copied from its roles, under which only channel admins and users permitted to post in
+	// all channels may post, while posts created through the plugin API are unaffected. Allowing
+	// posting again returns the channel to the scheme it had before. Clients are notified with a
+	// channel_scheme_updated WebSocket event. Direct and group message channels cannot be moderated.
+	SetChannelModerationForMembers(channelId string, allowPost bool) *model.AppError
+
+	// GetChannelModeration reports whether the members of a channel may post, and whether posting
+	// has been restricted with SetChannelModerationForMembers.
+	GetChannelModeration(channelId string) (*model.ChannelModeration, *model.AppError)
+
+	// AddChannelMember creates a channel membership for a user. The options allow the system message
+	// announcing the membership, or its notifications, to be suppressed when adding users in bulk.
+	AddChannelMember(channelId, userId string, opts model.MemberAddOptions) (*model.ChannelMember, *model.AppError)
 
 	// GetChannelMember gets a channel membership for a user.
 	GetChannelMember(channelId, userId string) (*model.ChannelMember, *model.AppError)
 
+	// GetChannelMembersModifiedSince returns the memberships of a specific channel that were created or
+	// updated after the given time in milliseconds, along with the users that have left the channel
+	// since then. Updates include changes to roles and notification preferences, and the user viewing
+	// the channel. Departures are not reported once they have been purged by data retention.
+	//
+	// Changes are found by comparing timestamps taken from the clock of the server that made them, and
+	// a change may only become visible some time after it is stamped. A plugin that passes the time of
+	// its previous run as since can therefore miss changes: rather than a timestamp from its own clock,
+	// it should pass the newest timestamp it has seen, less a safety margin of a few minutes, and
+	// expect to see some changes more than once.
+	GetChannelMembersModifiedSince(channelId string, since int64) (*model.ChannelMemberChanges, *model.AppError)
+
+	// GetChannelStats returns the member count of a specific channel.
+	GetChannelStats(channelId string) (*model.ChannelStats, *model.AppError)
+
 	// UpdateChannelMemberRoles updates a user's roles for a channel.
 	UpdateChannelMemberRoles(channelId, userId, newRoles string) (*model.ChannelMember, *model.AppError)
 
+	// GetChannelMemberNotifyProps gets a user's notification preferences for a channel, which
+	// override their own unless set to "default".
+	GetChannelMemberNotifyProps(channelId, userId string) (model.StringMap, *model.AppError)
+
 	// UpdateChannelMemberNotifications updates a user's notification properties for a channel.
 	UpdateChannelMemberNotifications(channelId, userId string, notifications map[string]string) (*model.ChannelMember, *model.AppError)
 
 	// DeleteChannelMember deletes a channel membership for a user.
 	DeleteChannelMember(channelId, userId string) *model.AppError
 
+	// ExportChannel starts exporting the members, posts and file metadata of a channel to the file
+	// store in the given format, either model.CHANNEL_EXPORT_FORMAT_CSV or
+	// model.CHANNEL_EXPORT_FORMAT_JSON, returning the id of the export's job. The
+	// ChannelExportHasCompleted hook is invoked once the export has been written. Exports holding
+	// more rows than PluginSettings.MaxChannelExportRows fail.
+	ExportChannel(channelId string, format string) (string, *model.AppError)
+
+	// GetExportStatus gets the progress of a channel export started by the plugin.
+	GetExportStatus(jobId string) (*model.ChannelExportStatus, *model.AppError)
+
 	// CreatePost creates a post.
 	CreatePost(post *model.Post) (*model.Post, *model.AppError)
 
@@ -152,41 +265,242 @@ type API interface {
 	// UpdatePost updates a post.
 	UpdatePost(post *model.Post) (*model.Post, *model.AppError)
 
-	// KVSet will store a key-value pair, unique per plugin.
+	// AddPostMetadata attaches the given JSON to a post under the namespace, delivered to clients in
+	// the post's props under plugin_metadata[namespace] without marking the post as edited. Clients
+	// are notified with a post_metadata_updated WebSocket event. Empty data removes the metadata. A
+	// namespace on a post belongs to the first plugin to use it, and its metadata is removed along
+	// with the plugin.
+	AddPostMetadata(postId, namespace string, data []byte) *model.AppError
+
+	// RequestPostAcknowledgement flags a post so that clients let users acknowledge having seen it.
+	// The PostHasBeenAcknowledged hook is invoked as each user does.
+	RequestPostAcknowledgement(postId string) *model.AppError
+
+	// GetPostAcknowledgements gets the acknowledgements of a post, oldest first.
+	GetPostAcknowledgements(postId string) ([]*model.PostAcknowledgement, *model.AppError)
+
+	// FollowThreadForUser subscribes a user to notifications for all replies to the thread containing
+	// the given post. The user must be a member of the thread's channel.
+	FollowThreadForUser(userId, postId string) *model.AppError
+
+	// UnfollowThreadForUser removes a user's subscription to the thread containing the given post.
+	UnfollowThreadForUser(userId, postId string) *model.AppError
+
+	// DeleteFile permanently deletes a file along with its thumbnail and preview, and detaches it from
+	// the posts it is attached to, which list its id in their deleted_file_ids prop instead. Clients
+	// are notified with a post_edited WebSocket event for each post. Deleting a file that no longer
+	// exists does nothing.
+	DeleteFile(fileId string) *model.AppError
+
+	// GetFileThumbnail gets the JPEG thumbnail of an image file.
+	GetFileThumbnail(fileId string) ([]byte, *model.AppError)
+
+	// NotifyUser sends a desktop and mobile notification to a user without creating a post. The
+	// user's notification preferences and Do Not Disturb status are respected, and notifications
+	// sent by each plugin are rate limited.
+	NotifyUser(userId string, notification model.PluginNotification) *model.AppError
+
+	// WouldUserBeNotified predicts which desktop, push and email notifications a user would currently
+	// receive for a post in a channel, mentioning them or not, taking into account their status, muted
+	// channels and notification preferences the same way as when notifications are sent.
+	WouldUserBeNotified(userId, channelId string, mention bool) (model.NotificationPrediction, *model.AppError)
+
+	// SetAnnouncementBanner shows an announcement banner to all users, including on the login page,
+	// replacing any banner set before by the plugin. A banner with empty text clears it. The banner
+	// is persisted across server restarts, and is cleared when the plugin is deactivated.
+	//
+	// A banner enabled in the System Console takes precedence wherever it is shown. If several
+	// plugins set a banner, the one whose plugin id sorts first is shown.
+	SetAnnouncementBanner(banner model.PluginBanner) *model.AppError
+
+	// CreateSubscription records that the plugin delivers something to a channel, such as the
+	// activity of a repository, on behalf of the user who set it up. The description is shown to
+	// users listing the integrations of the channel, while the payload is only returned to the
+	// plugin. Subscriptions are deleted when the plugin is removed.
+	CreateSubscription(subscription model.PluginSubscription) (*model.PluginSubscription, *model.AppError)
+
+	// ListSubscriptionsForChannel returns the plugin's own subscriptions in a channel.
+	ListSubscriptionsForChannel(channelId string) ([]*model.PluginSubscription, *model.AppError)
+
+	// DeleteSubscription deletes one of the plugin's own subscriptions.
+	DeleteSubscription(id string) *model.AppError
+
+	// RegisterOAuthProvider registers the OAuth 2.0 provider through which users connect their
+	// accounts in an external service to the plugin, typically from OnActivate. Users are sent to
+	// /plugins/{id}/oauth/connect to connect their account, and the provider must be configured to
+	// redirect them to /plugins/{id}/oauth/complete, both of which are then served by the server
+	// rather than the plugin. Tokens are stored encrypted, and are revoked when the plugin is removed
+	// or the user is deactivated.
+	RegisterOAuthProvider(provider model.PluginOAuthProvider) *model.AppError
+
+	// GetOAuthToken gets the token with which to act on behalf of a user in the external service
+	// they connected to the plugin's OAuth provider, refreshing it first if it has expired. It fails
+	// with a 404 status code if the user has not connected their account.
+	GetOAuthToken(userId string) (*model.PluginOAuthToken, *model.AppError)
+
+	// RequestAdminAction asks system admins to approve a privileged action, such as permanently
+	// deleting a user, which the server carries out on the plugin's behalf only once approved. Only
+	// the actions listed in model/plugin_admin_action.go may be requested. The plugin is told the
+	// outcome through its AdminActionHasBeenResolved hook, including when the request expires after
+	// PluginSettings.AdminActionRequestTTLMinutes without being approved.
+	RequestAdminAction(action model.PluginAdminActionRequest) (*model.PluginAdminActionRequest, *model.AppError)
+
+	// KVSet will store a key-value pair, unique per plugin. Writes that would take the plugin beyond
+	// the number of keys or total size allowed by the server's PluginSettings fail with an error
+	// whose Id is app.plugin.kv.quota_exceeded.app_error, while overwriting a value with a smaller
+	// one or deleting a key is always allowed. Setting a nil or empty value, which cannot be told
+	// apart once sent by a plugin, deletes the key.
 	KVSet(key string, value []byte) *model.AppError
 
+	// KVSetWithExpiry will store a key-value pair, unique per plugin, that is treated as deleted once
+	// expireInSeconds have passed. An expireInSeconds of zero never expires.
+	KVSetWithExpiry(key string, value []byte, expireInSeconds int64) *model.AppError
+
+	// KVSetMultiple will store the given key-value pairs, unique per plugin, all at once: either all of
+	// them are stored or none of them are.
+	KVSetMultiple(kvs map[string][]byte) *model.AppError
+
+	// KVSetAtomic will make the given sets and deletes of keys, unique per plugin, in order and all at
+	// once: either all of them are made or none of them are. Set values never expire.
+	KVSetAtomic(ops []model.PluginKVOp) *model.AppError
+
+	// KVCompareAndSet will atomically set the value of a key only if it currently holds oldValue, or
+	// only if it does not exist when oldValue is nil or empty, returning whether the value was set.
+	// Use it to coordinate across the servers of a cluster, such as to claim a job.
+	KVCompareAndSet(key string, oldValue, newValue []byte) (bool, *model.AppError)
+
+	// KVIncrement will atomically add delta to the counter stored under a key, returning its new
+	// value. A non-existent key starts from delta. The counter is stored as a decimal number, so it
+	// may also be read with KVGet, but a key holding anything else cannot be incremented.
+	KVIncrement(key string, delta int64) (int64, *model.AppError)
+
 	// KVGet will retrieve a value based on the key. Returns nil for non-existent keys.
 	KVGet(key string) ([]byte, *model.AppError)
 
+	// KVGetWithExists is like KVGet, but also reports whether the key exists, so that a key holding
+	// an empty value can be told apart from a non-existent key.
+	KVGetWithExists(key string) ([]byte, bool, *model.AppError)
+
+	// KVGetWithOptions is like KVGet, but reads the key as the options specify. Setting
+	// ReadFromMaster reads from the master database, so that a value written immediately before is
+	// seen even when read replicas lag behind. Reserve it for reads that need it, as it adds load to
+	// the master database.
+	KVGetWithOptions(key string, opts model.PluginKVGetOptions) ([]byte, *model.AppError)
+
+	// KVGetMultiple will retrieve the values of the given keys at once. Non-existent keys are omitted
+	// from the result.
+	KVGetMultiple(keys []string) (map[string][]byte, *model.AppError)
+
+	// KVList will return a page of the keys stored by the plugin. Keys longer than 1024 characters,
+	// and keys not written since the server started recording them, are not listed.
+	KVList(page, perPage int) ([]string, *model.AppError)
+
+	// KVListWithPrefix is like KVList, but only returns the keys beginning with the given prefix.
+	// The prefix is matched literally, so it may contain characters such as % and _.
+	KVListWithPrefix(prefix string, page, perPage int) ([]string, *model.AppError)
+
 	// KVDelete will remove a key-value pair. Returns nil for non-existent keys.
 	KVDelete(key string) *model.AppError
 
+	// KVDeleteAll removes all key-value pairs stored by the plugin, invoking the KVHasChanged hook
+	// once with an empty key rather than for each of them. The keys of other plugins are never
+	// affected.
+	KVDeleteAll() *model.AppError
+
+	// KVCompareAndDelete will atomically remove a key-value pair only if it currently holds oldValue,
+	// returning whether it was removed. Use it to release a key claimed with KVCompareAndSet.
+	KVCompareAndDelete(key string, oldValue []byte) (bool, *model.AppError)
+
+	// KVLock will attempt to take an advisory lock named by key, returning a token identifying this
+	// acquisition of the lock if it was taken, or an empty string if it is held. The lock is released
+	// by KVUnlock with the token or once ttl has passed, so that it does not outlive a crashed plugin,
+	// and must be taken again to be held longer. Locks are independent of the plugin's key-value
+	// pairs, and are not re-entrant: taking a lock already held fails, even for its holder.
+	KVLock(key string, ttl time.Duration) (string, *model.AppError)
+
+	// KVUnlock will release the lock named by key if it is still held with the token returned by
+	// KVLock. Locks that have expired, including those since taken again by another goroutine or
+	// server, are left alone.
+	KVUnlock(key, token string) *model.AppError
+
+	// GetServerHealth reports whether the database and file store can be reached, along with which
+	// optional services are enabled, so that a plugin may surface actionable errors of its own. The
+	// checks are cached for a few seconds, so polling this does not add load to those services.
+	GetServerHealth() model.PluginServerHealth
+
+	// IsHighAvailability returns whether the server runs as one node of a high availability
+	// cluster, in which case other instances of the plugin run on the other nodes.
+	IsHighAvailability() bool
+
+	// KVSetAndNotify will store a key-value pair like KVSet and, only once the write has been
+	// committed, publish the event like PublishWebSocketEvent. The event is not published if the
+	// write fails.
+	//
+	// Clients handling the event may be served by another server of a cluster whose read replicas
+	// have yet to see the write. To read the value back, they should send the X-Read-After-Write
+	// header with their follow-up request to the plugin, so that KVGet and KVGetMultiple read from
+	// the master database while it is served. Reads made without the header may still return the
+	// previous value until the replicas catch up.
+	KVSetAndNotify(key string, value []byte, event string, payload map[string]interface{}, broadcast *model.WebsocketBroadcast) *model.AppError
+
 	// PublishWebSocketEvent sends an event to WebSocket connections.
 	// event is the type and will be prepended with "custom_<pluginid>_"
 	// payload is the data sent with the event. Interface values must be primitive Go types or mattermost-server/model types
 	// broadcast determines to which users to send the event
 	PublishWebSocketEvent(event string, payload map[string]interface{}, broadcast *model.WebsocketBroadcast)
 
+	// WebSocketBroadcastToConnection sends an event to the single websocket connection identified by
+	// webConnID, as reported by the OnWebSocketConnect hook. Like PublishWebSocketEvent, the event type
+	// will be prepended with "custom_<pluginid>_". Any broadcast set on the event is ignored.
+	WebSocketBroadcastToConnection(webConnID string, event *model.WebSocketEvent)
+
+	// SendToUserWebapp sends a message to the plugin's webapp component in the user's connected
+	// clients as a plugin_webapp_message websocket event, returning the reply id identifying the
+	// message. The webapp component may reply by posting to /plugins/<pluginid>/webapp_replies/<reply id>,
+	// which invokes the plugin's WebappReplyReceived hook, while its WebappReplyTimedOut hook is
+	// invoked if no reply is received within the message's timeout. Delivery is best-effort.
+	//
+	// Messages to users without any websocket connections are dropped, failing with an error whose
+	// Id is app.plugin.webapp_message.not_connected.app_error. On a cluster, users connected only to
+	// other servers are considered connected unless their status is offline.
+	SendToUserWebapp(userId string, message model.PluginWebappMessage) (string, *model.AppError)
+
+	// StripMarkdown returns the text of a message without its markdown formatting, as the server
+	// does when extracting mentions.
+	StripMarkdown(message string) string
+
+	// TruncateForNotification shortens a message to at most maxRunes runes, ending it with an
+	// ellipsis, as the server does when building the text of push notifications.
+	TruncateForNotification(message string, maxRunes int) string
+
+	// RenderMessageToHTML renders a message as it appears in the body of the server's email
+	// notifications.
+	RenderMessageToHTML(message string) (string, *model.AppError)
+
+	// GetRequestId returns the id of the HTTP request or event that caused the hook invocation with
+	// the given context, for correlating the plugin's activity with the server log.
+	GetRequestId(c *Context) string
+
 	// LogDebug writes a log message to the Mattermost server log file.
-	// Appropriate context such as the plugin name will already be added as fields so plugins
+	// Appropriate context such as the plugin name and request id will already be added as fields so plugins
 	// do not need to add that info.
 	// keyValuePairs should be primitive go types or other values that can be encoded by encoding/gob
 	LogDebug(msg string, keyValuePairs ...interface{})
 
 	// LogInfo writes a log message to the Mattermost server log file.
-	// Appropriate context such as the plugin name will already be added as fields so plugins
+	// Appropriate context such as the plugin name and request id will already be added as fields so plugins
 	// do not need to add that info.
 	// keyValuePairs should be primitive go types or other values that can be encoded by encoding/gob
 	LogInfo(msg string, keyValuePairs ...interface{})
 
 	// LogError writes a log message to the Mattermost server log file.
-	// Appropriate context such as the plugin name will already be added as fields so plugins
+	// Appropriate context such as the plugin name and request id will already be added as fields so plugins
 	// do not need to add that info.
 	// keyValuePairs should be primitive go types or other values that can be encoded by encoding/gob
 	LogError(msg string, keyValuePairs ...interface{})
 
 	// LogWarn writes a log message to the Mattermost server log file.
-	// Appropriate context such as the plugin name will already be added as fields so plugins
+	// Appropriate context such as the plugin name and request id will already be added as fields so plugins
 	// do not need to add that info.
 	// keyValuePairs should be primitive go types or other values that can be encoded by encoding/gob
 	LogWarn(msg string, keyValuePairs ...interface{})
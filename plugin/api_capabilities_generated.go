@@ -0,0 +1,908 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+// Code generated by "make pluginapi"
+// DO NOT EDIT
+
+package plugin
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func (_a *capabilityCheckedAPI) LoadPluginConfiguration(dest interface{}) (_r0 error) {
+	if _err := _a.check("LoadPluginConfiguration"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.LoadPluginConfiguration(dest)
+}
+
+func (_a *capabilityCheckedAPI) RegisterCommand(command *model.Command) (_r0 error) {
+	if _err := _a.check("RegisterCommand"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.RegisterCommand(command)
+}
+
+func (_a *capabilityCheckedAPI) UnregisterCommand(teamId, trigger string) (_r0 error) {
+	if _err := _a.check("UnregisterCommand"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.UnregisterCommand(teamId, trigger)
+}
+
+func (_a *capabilityCheckedAPI) GetConfig() (_r0 *model.Config) {
+	if _err := _a.check("GetConfig"); _err != nil {
+		return
+	}
+	return _a.api.GetConfig()
+}
+
+func (_a *capabilityCheckedAPI) SaveConfig(config *model.Config) (_r0 *model.AppError) {
+	if _err := _a.check("SaveConfig"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.SaveConfig(config)
+}
+
+func (_a *capabilityCheckedAPI) GetSiteURL() (_r0 string) {
+	if _err := _a.check("GetSiteURL"); _err != nil {
+		return
+	}
+	return _a.api.GetSiteURL()
+}
+
+func (_a *capabilityCheckedAPI) GetSiteName() (_r0 string) {
+	if _err := _a.check("GetSiteName"); _err != nil {
+		return
+	}
+	return _a.api.GetSiteName()
+}
+
+func (_a *capabilityCheckedAPI) GetSupportEmail() (_r0 string) {
+	if _err := _a.check("GetSupportEmail"); _err != nil {
+		return
+	}
+	return _a.api.GetSupportEmail()
+}
+
+func (_a *capabilityCheckedAPI) GetBrandImage() (_r0 []byte, _r1 *model.AppError) {
+	if _err := _a.check("GetBrandImage"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetBrandImage()
+}
+
+func (_a *capabilityCheckedAPI) GetAllowedTeams() (_r0 []string) {
+	if _err := _a.check("GetAllowedTeams"); _err != nil {
+		return
+	}
+	return _a.api.GetAllowedTeams()
+}
+
+func (_a *capabilityCheckedAPI) CreateUser(user *model.User) (_r0 *model.User, _r1 *model.AppError) {
+	if _err := _a.check("CreateUser"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.CreateUser(user)
+}
+
+func (_a *capabilityCheckedAPI) DeleteUser(userId string) (_r0 *model.AppError) {
+	if _err := _a.check("DeleteUser"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.DeleteUser(userId)
+}
+
+func (_a *capabilityCheckedAPI) GetUser(userId string) (_r0 *model.User, _r1 *model.AppError) {
+	if _err := _a.check("GetUser"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetUser(userId)
+}
+
+func (_a *capabilityCheckedAPI) GetUserByEmail(email string) (_r0 *model.User, _r1 *model.AppError) {
+	if _err := _a.check("GetUserByEmail"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetUserByEmail(email)
+}
+
+func (_a *capabilityCheckedAPI) GetUserByUsername(name string) (_r0 *model.User, _r1 *model.AppError) {
+	if _err := _a.check("GetUserByUsername"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetUserByUsername(name)
+}
+
+func (_a *capabilityCheckedAPI) UpdateUser(user *model.User) (_r0 *model.User, _r1 *model.AppError) {
+	if _err := _a.check("UpdateUser"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.UpdateUser(user)
+}
+
+func (_a *capabilityCheckedAPI) GetUserNotifyProps(userId string) (_r0 model.StringMap, _r1 *model.AppError) {
+	if _err := _a.check("GetUserNotifyProps"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetUserNotifyProps(userId)
+}
+
+func (_a *capabilityCheckedAPI) SetUserPluginSetting(userId, key, value string) (_r0 *model.AppError) {
+	if _err := _a.check("SetUserPluginSetting"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.SetUserPluginSetting(userId, key, value)
+}
+
+func (_a *capabilityCheckedAPI) GetUserPluginSettings(userId string) (_r0 map[string]string, _r1 *model.AppError) {
+	if _err := _a.check("GetUserPluginSettings"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetUserPluginSettings(userId)
+}
+
+func (_a *capabilityCheckedAPI) GetUserStatus(userId string) (_r0 *model.Status, _r1 *model.AppError) {
+	if _err := _a.check("GetUserStatus"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetUserStatus(userId)
+}
+
+func (_a *capabilityCheckedAPI) GetUserStatusesByIds(userIds []string) (_r0 []*model.Status, _r1 *model.AppError) {
+	if _err := _a.check("GetUserStatusesByIds"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetUserStatusesByIds(userIds)
+}
+
+func (_a *capabilityCheckedAPI) UpdateUserStatus(userId, status string) (_r0 *model.Status, _r1 *model.AppError) {
+	if _err := _a.check("UpdateUserStatus"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.UpdateUserStatus(userId, status)
+}
+
+func (_a *capabilityCheckedAPI) GetSessions(userId string) (_r0 []*model.Session, _r1 *model.AppError) {
+	if _err := _a.check("GetSessions"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetSessions(userId)
+}
+
+func (_a *capabilityCheckedAPI) RevokeSession(sessionId string) (_r0 *model.AppError) {
+	if _err := _a.check("RevokeSession"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.RevokeSession(sessionId)
+}
+
+func (_a *capabilityCheckedAPI) RevokeAllSessionsForUser(userId string) (_r0 *model.AppError) {
+	if _err := _a.check("RevokeAllSessionsForUser"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.RevokeAllSessionsForUser(userId)
+}
+
+func (_a *capabilityCheckedAPI) CreateTeam(team *model.Team) (_r0 *model.Team, _r1 *model.AppError) {
+	if _err := _a.check("CreateTeam"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.CreateTeam(team)
+}
+
+func (_a *capabilityCheckedAPI) DeleteTeam(teamId string) (_r0 *model.AppError) {
+	if _err := _a.check("DeleteTeam"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.DeleteTeam(teamId)
+}
+
+func (_a *capabilityCheckedAPI) GetTeams() (_r0 []*model.Team, _r1 *model.AppError) {
+	if _err := _a.check("GetTeams"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetTeams()
+}
+
+func (_a *capabilityCheckedAPI) GetTeam(teamId string) (_r0 *model.Team, _r1 *model.AppError) {
+	if _err := _a.check("GetTeam"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetTeam(teamId)
+}
+
+func (_a *capabilityCheckedAPI) GetTeamByName(name string) (_r0 *model.Team, _r1 *model.AppError) {
+	if _err := _a.check("GetTeamByName"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetTeamByName(name)
+}
+
+func (_a *capabilityCheckedAPI) UpdateTeam(team *model.Team) (_r0 *model.Team, _r1 *model.AppError) {
+	if _err := _a.check("UpdateTeam"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.UpdateTeam(team)
+}
+
+func (_a *capabilityCheckedAPI) CreateTeamMember(teamId, userId string) (_r0 *model.TeamMember, _r1 *model.AppError) {
+	if _err := _a.check("CreateTeamMember"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.CreateTeamMember(teamId, userId)
+}
+
+func (_a *capabilityCheckedAPI) CreateTeamMembers(teamId string, userIds []string, requestorId string) (_r0 []*model.TeamMember, _r1 *model.AppError) {
+	if _err := _a.check("CreateTeamMembers"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.CreateTeamMembers(teamId, userIds, requestorId)
+}
+
+func (_a *capabilityCheckedAPI) DeleteTeamMember(teamId, userId, requestorId string) (_r0 *model.AppError) {
+	if _err := _a.check("DeleteTeamMember"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.DeleteTeamMember(teamId, userId, requestorId)
+}
+
+func (_a *capabilityCheckedAPI) GetTeamMembers(teamId string, offset, limit int) (_r0 []*model.TeamMember, _r1 *model.AppError) {
+	if _err := _a.check("GetTeamMembers"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetTeamMembers(teamId, offset, limit)
+}
+
+func (_a *capabilityCheckedAPI) GetTeamMember(teamId, userId string) (_r0 *model.TeamMember, _r1 *model.AppError) {
+	if _err := _a.check("GetTeamMember"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetTeamMember(teamId, userId)
+}
+
+func (_a *capabilityCheckedAPI) GetTeamMembersModifiedSince(teamId string, since int64) (_r0 []*model.TeamMember, _r1 *model.AppError) {
+	if _err := _a.check("GetTeamMembersModifiedSince"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetTeamMembersModifiedSince(teamId, since)
+}
+
+func (_a *capabilityCheckedAPI) GetTeamStats(teamId string) (_r0 *model.TeamStats, _r1 *model.AppError) {
+	if _err := _a.check("GetTeamStats"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetTeamStats(teamId)
+}
+
+func (_a *capabilityCheckedAPI) UpdateTeamMemberRoles(teamId, userId, newRoles string) (_r0 *model.TeamMember, _r1 *model.AppError) {
+	if _err := _a.check("UpdateTeamMemberRoles"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.UpdateTeamMemberRoles(teamId, userId, newRoles)
+}
+
+func (_a *capabilityCheckedAPI) CreateChannel(channel *model.Channel) (_r0 *model.Channel, _r1 *model.AppError) {
+	if _err := _a.check("CreateChannel"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.CreateChannel(channel)
+}
+
+func (_a *capabilityCheckedAPI) DeleteChannel(channelId string) (_r0 *model.AppError) {
+	if _err := _a.check("DeleteChannel"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.DeleteChannel(channelId)
+}
+
+func (_a *capabilityCheckedAPI) RestoreChannel(channelId string) (_r0 *model.Channel, _r1 *model.AppError) {
+	if _err := _a.check("RestoreChannel"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.RestoreChannel(channelId)
+}
+
+func (_a *capabilityCheckedAPI) ConvertChannelToPrivate(channelId string) (_r0 *model.Channel, _r1 *model.AppError) {
+	if _err := _a.check("ConvertChannelToPrivate"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.ConvertChannelToPrivate(channelId)
+}
+
+func (_a *capabilityCheckedAPI) GetPublicChannelsForTeam(teamId string, offset, limit int) (_r0 *model.ChannelList, _r1 *model.AppError) {
+	if _err := _a.check("GetPublicChannelsForTeam"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetPublicChannelsForTeam(teamId, offset, limit)
+}
+
+func (_a *capabilityCheckedAPI) GetChannel(channelId string) (_r0 *model.Channel, _r1 *model.AppError) {
+	if _err := _a.check("GetChannel"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetChannel(channelId)
+}
+
+func (_a *capabilityCheckedAPI) GetChannelByName(teamId, name string, includeDeleted bool) (_r0 *model.Channel, _r1 *model.AppError) {
+	if _err := _a.check("GetChannelByName"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetChannelByName(teamId, name, includeDeleted)
+}
+
+func (_a *capabilityCheckedAPI) GetChannelByNameForTeamName(teamName, channelName string, includeDeleted bool) (_r0 *model.Channel, _r1 *model.AppError) {
+	if _err := _a.check("GetChannelByNameForTeamName"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetChannelByNameForTeamName(teamName, channelName, includeDeleted)
+}
+
+func (_a *capabilityCheckedAPI) GetDirectChannel(userId1, userId2 string) (_r0 *model.Channel, _r1 *model.AppError) {
+	if _err := _a.check("GetDirectChannel"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetDirectChannel(userId1, userId2)
+}
+
+func (_a *capabilityCheckedAPI) GetGroupChannel(userIds []string) (_r0 *model.Channel, _r1 *model.AppError) {
+	if _err := _a.check("GetGroupChannel"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetGroupChannel(userIds)
+}
+
+func (_a *capabilityCheckedAPI) UpdateChannel(channel *model.Channel) (_r0 *model.Channel, _r1 *model.AppError) {
+	if _err := _a.check("UpdateChannel"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.UpdateChannel(channel)
+}
+
+func (_a *capabilityCheckedAPI) SetChannelModerationForMembers(channelId string, allowPost bool) (_r0 *model.AppError) {
+	if _err := _a.check("SetChannelModerationForMembers"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.SetChannelModerationForMembers(channelId, allowPost)
+}
+
+func (_a *capabilityCheckedAPI) GetChannelModeration(channelId string) (_r0 *model.ChannelModeration, _r1 *model.AppError) {
+	if _err := _a.check("GetChannelModeration"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetChannelModeration(channelId)
+}
+
+func (_a *capabilityCheckedAPI) AddChannelMember(channelId, userId string, opts model.MemberAddOptions) (_r0 *model.ChannelMember, _r1 *model.AppError) {
+	if _err := _a.check("AddChannelMember"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.AddChannelMember(channelId, userId, opts)
+}
+
+func (_a *capabilityCheckedAPI) GetChannelMember(channelId, userId string) (_r0 *model.ChannelMember, _r1 *model.AppError) {
+	if _err := _a.check("GetChannelMember"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetChannelMember(channelId, userId)
+}
+
+func (_a *capabilityCheckedAPI) GetChannelMembersModifiedSince(channelId string, since int64) (_r0 *model.ChannelMemberChanges, _r1 *model.AppError) {
+	if _err := _a.check("GetChannelMembersModifiedSince"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetChannelMembersModifiedSince(channelId, since)
+}
+
+func (_a *capabilityCheckedAPI) GetChannelStats(channelId string) (_r0 *model.ChannelStats, _r1 *model.AppError) {
+	if _err := _a.check("GetChannelStats"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetChannelStats(channelId)
+}
+
+func (_a *capabilityCheckedAPI) UpdateChannelMemberRoles(channelId, userId, newRoles string) (_r0 *model.ChannelMember, _r1 *model.AppError) {
+	if _err := _a.check("UpdateChannelMemberRoles"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.UpdateChannelMemberRoles(channelId, userId, newRoles)
+}
+
+func (_a *capabilityCheckedAPI) GetChannelMemberNotifyProps(channelId, userId string) (_r0 model.StringMap, _r1 *model.AppError) {
+	if _err := _a.check("GetChannelMemberNotifyProps"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetChannelMemberNotifyProps(channelId, userId)
+}
+
+func (_a *capabilityCheckedAPI) UpdateChannelMemberNotifications(channelId, userId string, notifications map[string]string) (_r0 *model.ChannelMember, _r1 *model.AppError) {
+	if _err := _a.check("UpdateChannelMemberNotifications"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.UpdateChannelMemberNotifications(channelId, userId, notifications)
+}
+
+func (_a *capabilityCheckedAPI) DeleteChannelMember(channelId, userId string) (_r0 *model.AppError) {
+	if _err := _a.check("DeleteChannelMember"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.DeleteChannelMember(channelId, userId)
+}
+
+func (_a *capabilityCheckedAPI) ExportChannel(channelId string, format string) (_r0 string, _r1 *model.AppError) {
+	if _err := _a.check("ExportChannel"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.ExportChannel(channelId, format)
+}
+
+func (_a *capabilityCheckedAPI) GetExportStatus(jobId string) (_r0 *model.ChannelExportStatus, _r1 *model.AppError) {
+	if _err := _a.check("GetExportStatus"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetExportStatus(jobId)
+}
+
+func (_a *capabilityCheckedAPI) CreatePost(post *model.Post) (_r0 *model.Post, _r1 *model.AppError) {
+	if _err := _a.check("CreatePost"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.CreatePost(post)
+}
+
+func (_a *capabilityCheckedAPI) SendEphemeralPost(userId string, post *model.Post) (_r0 *model.Post) {
+	if _err := _a.check("SendEphemeralPost"); _err != nil {
+		return
+	}
+	return _a.api.SendEphemeralPost(userId, post)
+}
+
+func (_a *capabilityCheckedAPI) DeletePost(postId string) (_r0 *model.AppError) {
+	if _err := _a.check("DeletePost"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.DeletePost(postId)
+}
+
+func (_a *capabilityCheckedAPI) GetPost(postId string) (_r0 *model.Post, _r1 *model.AppError) {
+	if _err := _a.check("GetPost"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetPost(postId)
+}
+
+func (_a *capabilityCheckedAPI) UpdatePost(post *model.Post) (_r0 *model.Post, _r1 *model.AppError) {
+	if _err := _a.check("UpdatePost"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.UpdatePost(post)
+}
+
+func (_a *capabilityCheckedAPI) AddPostMetadata(postId, namespace string, data []byte) (_r0 *model.AppError) {
+	if _err := _a.check("AddPostMetadata"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.AddPostMetadata(postId, namespace, data)
+}
+
+func (_a *capabilityCheckedAPI) RequestPostAcknowledgement(postId string) (_r0 *model.AppError) {
+	if _err := _a.check("RequestPostAcknowledgement"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.RequestPostAcknowledgement(postId)
+}
+
+func (_a *capabilityCheckedAPI) GetPostAcknowledgements(postId string) (_r0 []*model.PostAcknowledgement, _r1 *model.AppError) {
+	if _err := _a.check("GetPostAcknowledgements"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetPostAcknowledgements(postId)
+}
+
+func (_a *capabilityCheckedAPI) FollowThreadForUser(userId, postId string) (_r0 *model.AppError) {
+	if _err := _a.check("FollowThreadForUser"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.FollowThreadForUser(userId, postId)
+}
+
+func (_a *capabilityCheckedAPI) UnfollowThreadForUser(userId, postId string) (_r0 *model.AppError) {
+	if _err := _a.check("UnfollowThreadForUser"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.UnfollowThreadForUser(userId, postId)
+}
+
+func (_a *capabilityCheckedAPI) DeleteFile(fileId string) (_r0 *model.AppError) {
+	if _err := _a.check("DeleteFile"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.DeleteFile(fileId)
+}
+
+func (_a *capabilityCheckedAPI) GetFileThumbnail(fileId string) (_r0 []byte, _r1 *model.AppError) {
+	if _err := _a.check("GetFileThumbnail"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetFileThumbnail(fileId)
+}
+
+func (_a *capabilityCheckedAPI) NotifyUser(userId string, notification model.PluginNotification) (_r0 *model.AppError) {
+	if _err := _a.check("NotifyUser"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.NotifyUser(userId, notification)
+}
+
+func (_a *capabilityCheckedAPI) WouldUserBeNotified(userId, channelId string, mention bool) (_r0 model.NotificationPrediction, _r1 *model.AppError) {
+	if _err := _a.check("WouldUserBeNotified"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.WouldUserBeNotified(userId, channelId, mention)
+}
+
+func (_a *capabilityCheckedAPI) SetAnnouncementBanner(banner model.PluginBanner) (_r0 *model.AppError) {
+	if _err := _a.check("SetAnnouncementBanner"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.SetAnnouncementBanner(banner)
+}
+
+func (_a *capabilityCheckedAPI) CreateSubscription(subscription model.PluginSubscription) (_r0 *model.PluginSubscription, _r1 *model.AppError) {
+	if _err := _a.check("CreateSubscription"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.CreateSubscription(subscription)
+}
+
+func (_a *capabilityCheckedAPI) ListSubscriptionsForChannel(channelId string) (_r0 []*model.PluginSubscription, _r1 *model.AppError) {
+	if _err := _a.check("ListSubscriptionsForChannel"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.ListSubscriptionsForChannel(channelId)
+}
+
+func (_a *capabilityCheckedAPI) DeleteSubscription(id string) (_r0 *model.AppError) {
+	if _err := _a.check("DeleteSubscription"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.DeleteSubscription(id)
+}
+
+func (_a *capabilityCheckedAPI) RegisterOAuthProvider(provider model.PluginOAuthProvider) (_r0 *model.AppError) {
+	if _err := _a.check("RegisterOAuthProvider"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.RegisterOAuthProvider(provider)
+}
+
+func (_a *capabilityCheckedAPI) GetOAuthToken(userId string) (_r0 *model.PluginOAuthToken, _r1 *model.AppError) {
+	if _err := _a.check("GetOAuthToken"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.GetOAuthToken(userId)
+}
+
+func (_a *capabilityCheckedAPI) RequestAdminAction(action model.PluginAdminActionRequest) (_r0 *model.PluginAdminActionRequest, _r1 *model.AppError) {
+	if _err := _a.check("RequestAdminAction"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.RequestAdminAction(action)
+}
+
+func (_a *capabilityCheckedAPI) KVSet(key string, value []byte) (_r0 *model.AppError) {
+	if _err := _a.check("KVSet"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.KVSet(key, value)
+}
+
+func (_a *capabilityCheckedAPI) KVSetWithExpiry(key string, value []byte, expireInSeconds int64) (_r0 *model.AppError) {
+	if _err := _a.check("KVSetWithExpiry"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.KVSetWithExpiry(key, value, expireInSeconds)
+}
+
+func (_a *capabilityCheckedAPI) KVSetMultiple(kvs map[string][]byte) (_r0 *model.AppError) {
+	if _err := _a.check("KVSetMultiple"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.KVSetMultiple(kvs)
+}
+
+func (_a *capabilityCheckedAPI) KVSetAtomic(ops []model.PluginKVOp) (_r0 *model.AppError) {
+	if _err := _a.check("KVSetAtomic"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.KVSetAtomic(ops)
+}
+
+func (_a *capabilityCheckedAPI) KVCompareAndSet(key string, oldValue, newValue []byte) (_r0 bool, _r1 *model.AppError) {
+	if _err := _a.check("KVCompareAndSet"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.KVCompareAndSet(key, oldValue, newValue)
+}
+
+func (_a *capabilityCheckedAPI) KVIncrement(key string, delta int64) (_r0 int64, _r1 *model.AppError) {
+	if _err := _a.check("KVIncrement"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.KVIncrement(key, delta)
+}
+
+func (_a *capabilityCheckedAPI) KVGet(key string) (_r0 []byte, _r1 *model.AppError) {
+	if _err := _a.check("KVGet"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.KVGet(key)
+}
+
+func (_a *capabilityCheckedAPI) KVGetWithExists(key string) (_r0 []byte, _r1 bool, _r2 *model.AppError) {
+	if _err := _a.check("KVGetWithExists"); _err != nil {
+		_r2 = _err
+		return
+	}
+	return _a.api.KVGetWithExists(key)
+}
+
+func (_a *capabilityCheckedAPI) KVGetWithOptions(key string, opts model.PluginKVGetOptions) (_r0 []byte, _r1 *model.AppError) {
+	if _err := _a.check("KVGetWithOptions"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.KVGetWithOptions(key, opts)
+}
+
+func (_a *capabilityCheckedAPI) KVGetMultiple(keys []string) (_r0 map[string][]byte, _r1 *model.AppError) {
+	if _err := _a.check("KVGetMultiple"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.KVGetMultiple(keys)
+}
+
+func (_a *capabilityCheckedAPI) KVList(page, perPage int) (_r0 []string, _r1 *model.AppError) {
+	if _err := _a.check("KVList"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.KVList(page, perPage)
+}
+
+func (_a *capabilityCheckedAPI) KVListWithPrefix(prefix string, page, perPage int) (_r0 []string, _r1 *model.AppError) {
+	if _err := _a.check("KVListWithPrefix"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.KVListWithPrefix(prefix, page, perPage)
+}
+
+func (_a *capabilityCheckedAPI) KVDelete(key string) (_r0 *model.AppError) {
+	if _err := _a.check("KVDelete"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.KVDelete(key)
+}
+
+func (_a *capabilityCheckedAPI) KVDeleteAll() (_r0 *model.AppError) {
+	if _err := _a.check("KVDeleteAll"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.KVDeleteAll()
+}
+
+func (_a *capabilityCheckedAPI) KVCompareAndDelete(key string, oldValue []byte) (_r0 bool, _r1 *model.AppError) {
+	if _err := _a.check("KVCompareAndDelete"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.KVCompareAndDelete(key, oldValue)
+}
+
+func (_a *capabilityCheckedAPI) KVLock(key string, ttl time.Duration) (_r0 string, _r1 *model.AppError) {
+	if _err := _a.check("KVLock"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.KVLock(key, ttl)
+}
+
+func (_a *capabilityCheckedAPI) KVUnlock(key, token string) (_r0 *model.AppError) {
+	if _err := _a.check("KVUnlock"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.KVUnlock(key, token)
+}
+
+func (_a *capabilityCheckedAPI) GetServerHealth() (_r0 model.PluginServerHealth) {
+	if _err := _a.check("GetServerHealth"); _err != nil {
+		return
+	}
+	return _a.api.GetServerHealth()
+}
+
+func (_a *capabilityCheckedAPI) IsHighAvailability() (_r0 bool) {
+	if _err := _a.check("IsHighAvailability"); _err != nil {
+		return
+	}
+	return _a.api.IsHighAvailability()
+}
+
+func (_a *capabilityCheckedAPI) KVSetAndNotify(key string, value []byte, event string, payload map[string]interface{}, broadcast *model.WebsocketBroadcast) (_r0 *model.AppError) {
+	if _err := _a.check("KVSetAndNotify"); _err != nil {
+		_r0 = _err
+		return
+	}
+	return _a.api.KVSetAndNotify(key, value, event, payload, broadcast)
+}
+
+func (_a *capabilityCheckedAPI) PublishWebSocketEvent(event string, payload map[string]interface{}, broadcast *model.WebsocketBroadcast) {
+	if _err := _a.check("PublishWebSocketEvent"); _err != nil {
+		return
+	}
+	_a.api.PublishWebSocketEvent(event, payload, broadcast)
+}
+
+func (_a *capabilityCheckedAPI) WebSocketBroadcastToConnection(webConnID string, event *model.WebSocketEvent) {
+	if _err := _a.check("WebSocketBroadcastToConnection"); _err != nil {
+		return
+	}
+	_a.api.WebSocketBroadcastToConnection(webConnID, event)
+}
+
+func (_a *capabilityCheckedAPI) SendToUserWebapp(userId string, message model.PluginWebappMessage) (_r0 string, _r1 *model.AppError) {
+	if _err := _a.check("SendToUserWebapp"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.SendToUserWebapp(userId, message)
+}
+
+func (_a *capabilityCheckedAPI) StripMarkdown(message string) (_r0 string) {
+	if _err := _a.check("StripMarkdown"); _err != nil {
+		return
+	}
+	return _a.api.StripMarkdown(message)
+}
+
+func (_a *capabilityCheckedAPI) TruncateForNotification(message string, maxRunes int) (_r0 string) {
+	if _err := _a.check("TruncateForNotification"); _err != nil {
+		return
+	}
+	return _a.api.TruncateForNotification(message, maxRunes)
+}
+
+func (_a *capabilityCheckedAPI) RenderMessageToHTML(message string) (_r0 string, _r1 *model.AppError) {
+	if _err := _a.check("RenderMessageToHTML"); _err != nil {
+		_r1 = _err
+		return
+	}
+	return _a.api.RenderMessageToHTML(message)
+}
+
+func (_a *capabilityCheckedAPI) GetRequestId(c *Context) (_r0 string) {
+	if _err := _a.check("GetRequestId"); _err != nil {
+		return
+	}
+	return _a.api.GetRequestId(c)
+}
+
+func (_a *capabilityCheckedAPI) LogDebug(msg string, keyValuePairs ...interface{}) {
+	if _err := _a.check("LogDebug"); _err != nil {
+		return
+	}
+	_a.api.LogDebug(msg, keyValuePairs...)
+}
+
+func (_a *capabilityCheckedAPI) LogInfo(msg string, keyValuePairs ...interface{}) {
+	if _err := _a.check("LogInfo"); _err != nil {
+		return
+	}
+	_a.api.LogInfo(msg, keyValuePairs...)
+}
+
+func (_a *capabilityCheckedAPI) LogError(msg string, keyValuePairs ...interface{}) {
+	if _err := _a.check("LogError"); _err != nil {
+		return
+	}
+	_a.api.LogError(msg, keyValuePairs...)
+}
+
+func (_a *capabilityCheckedAPI) LogWarn(msg string, keyValuePairs ...interface{}) {
+	if _err := _a.check("LogWarn"); _err != nil {
+		return
+	}
+	_a.api.LogWarn(msg, keyValuePairs...)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package plugin
+
+import (
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// APICapabilities maps every method of the API to the capabilities a plugin must be granted to call
+// it, which are none for methods any plugin may call. Every method must be listed, so that none can
+// be added without deciding which plugins may call it.
+var APICapabilities = map[string][]string{
+	"LoadPluginConfiguration": nil,
+	"RegisterCommand":         {model.PLUGIN_CAPABILITY_COMMANDS},
+	"UnregisterCommand":       {model.PLUGIN_CAPABILITY_COMMANDS},
+	"GetConfig":               {model.PLUGIN_CAPABILITY_CONFIG_READ},
+	"SaveConfig":              {model.PLUGIN_CAPABILITY_CONFIG_WRITE},
+	"GetSiteURL":              nil,
+	"GetSiteName":             nil,
+	"GetSupportEmail":         nil,
+	"GetBrandImage":           nil,
+	"GetAllowedTeams":         nil,
+
+	"CreateUser":            {model.PLUGIN_CAPABILITY_USERS_WRITE},
+	"DeleteUser":            {model.PLUGIN_CAPABILITY_USERS_WRITE},
+	"GetUser":               {model.PLUGIN_CAPABILITY_USERS_READ},
+	"GetUserByEmail":        {model.PLUGIN_CAPABILITY_USERS_READ},
+	"GetUserByUsername":     {model.PLUGIN_CAPABILITY_USERS_READ},
+	"UpdateUser":            {model.PLUGIN_CAPABILITY_USERS_WRITE},
+	"GetUserNotifyProps":    {model.PLUGIN_CAPABILITY_USERS_READ},
+	"SetUserPluginSetting":  {model.PLUGIN_CAPABILITY_USERS_WRITE},
+	"GetUserPluginSettings": {model.PLUGIN_CAPABILITY_USERS_READ},
+	"GetUserStatus":         {model.PLUGIN_CAPABILITY_USERS_READ},
+	"GetUserStatusesByIds":  {model.PLUGIN_CAPABILITY_USERS_READ},
+	"UpdateUserStatus":      {model.PLUGIN_CAPABILITY_USERS_WRITE},
+
+	"GetSessions":              {model.PLUGIN_CAPABILITY_SESSIONS},
+	"RevokeSession":            {model.PLUGIN_CAPABILITY_SESSIONS},
+	"RevokeAllSessionsForUser": {model.PLUGIN_CAPABILITY_SESSIONS},
+
+	"CreateTeam":                  {model.PLUGIN_CAPABILITY_TEAMS_WRITE},
+	"DeleteTeam":                  {model.PLUGIN_CAPABILITY_TEAMS_WRITE},
+	"GetTeams":                    {model.PLUGIN_CAPABILITY_TEAMS_READ},
+	"GetTeam":                     {model.PLUGIN_CAPABILITY_TEAMS_READ},
+	"GetTeamByName":               {model.PLUGIN_CAPABILITY_TEAMS_READ},
+	"UpdateTeam":                  {model.PLUGIN_CAPABILITY_TEAMS_WRITE},
+	"CreateTeamMember":            {model.PLUGIN_CAPABILITY_TEAMS_WRITE},
+	"CreateTeamMembers":           {model.PLUGIN_CAPABILITY_TEAMS_WRITE},
+	"DeleteTeamMember":            {model.PLUGIN_CAPABILITY_TEAMS_WRITE},
+	"GetTeamMembers":              {model.PLUGIN_CAPABILITY_TEAMS_READ},
+	"GetTeamMember":               {model.PLUGIN_CAPABILITY_TEAMS_READ},
+	"GetTeamMembersModifiedSince": {model.PLUGIN_CAPABILITY_TEAMS_READ},
+	"GetTeamStats":                {model.PLUGIN_CAPABILITY_TEAMS_READ},
+	"UpdateTeamMemberRoles":       {model.PLUGIN_CAPABILITY_TEAMS_WRITE},
+
+	"CreateChannel":               {model.PLUGIN_CAPABILITY_CHANNELS_WRITE},
+	"DeleteChannel":               {model.PLUGIN_CAPABILITY_CHANNELS_WRITE},
+	"RestoreChannel":              {model.PLUGIN_CAPABILITY_CHANNELS_WRITE},
+	"ConvertChannelToPrivate":     {model.PLUGIN_CAPABILITY_CHANNELS_WRITE},
+	"GetPublicChannelsForTeam":    {model.PLUGIN_CAPABILITY_CHANNELS_READ},
+	"GetChannel":                  {model.PLUGIN_CAPABILITY_CHANNELS_READ},
+	"GetChannelByName":            {model.PLUGIN_CAPABILITY_CHANNELS_READ},
+	"GetChannelByNameForTeamName": {model.PLUGIN_CAPABILITY_CHANNELS_READ},
+	// Direct and group channels are created if they do not exist yet.
+	"GetDirectChannel":                 {model.PLUGIN_CAPABILITY_CHANNELS_WRITE},
+	"GetGroupChannel":                  {model.PLUGIN_CAPABILITY_CHANNELS_WRITE},
+	"UpdateChannel":                    {model.PLUGIN_CAPABILITY_CHANNELS_WRITE},
+	"SetChannelModerationForMembers":   {model.PLUGIN_CAPABILITY_CHANNELS_WRITE},
+	"GetChannelModeration":             {model.PLUGIN_CAPABILITY_CHANNELS_READ},
+	"AddChannelMember":                 {model.PLUGIN_CAPABILITY_CHANNELS_WRITE},
+	"GetChannelMember":                 {model.PLUGIN_CAPABILITY_CHANNELS_READ},
+	"GetChannelMembersModifiedSince":   {model.PLUGIN_CAPABILITY_CHANNELS_READ},
+	"GetChannelStats":                  {model.PLUGIN_CAPABILITY_CHANNELS_READ},
+	"UpdateChannelMemberRoles":         {model.PLUGIN_CAPABILITY_CHANNELS_WRITE},
+	"GetChannelMemberNotifyProps":      {model.PLUGIN_CAPABILITY_CHANNELS_READ},
+	"UpdateChannelMemberNotifications": {model.PLUGIN_CAPABILITY_CHANNELS_WRITE},
+	"DeleteChannelMember":              {model.PLUGIN_CAPABILITY_CHANNELS_WRITE},
+	"ExportChannel":                    {model.PLUGIN_CAPABILITY_CHANNELS_EXPORT},
+	"GetExportStatus":                  {model.PLUGIN_CAPABILITY_CHANNELS_EXPORT},
+
+	"CreatePost":                 {model.PLUGIN_CAPABILITY_POSTS_WRITE},
+	"SendEphemeralPost":          {model.PLUGIN_CAPABILITY_POSTS_WRITE},
+	"DeletePost":                 {model.PLUGIN_CAPABILITY_POSTS_WRITE},
+	"GetPost":                    {model.PLUGIN_CAPABILITY_POSTS_READ},
+	"UpdatePost":                 {model.PLUGIN_CAPABILITY_POSTS_WRITE},
+	"AddPostMetadata":            {model.PLUGIN_CAPABILITY_POSTS_WRITE},
+	"RequestPostAcknowledgement": {model.PLUGIN_CAPABILITY_POSTS_WRITE},
+	"GetPostAcknowledgements":    {model.PLUGIN_CAPABILITY_POSTS_READ},
+	"FollowThreadForUser":        {model.PLUGIN_CAPABILITY_POSTS_WRITE},
+	"UnfollowThreadForUser":      {model.PLUGIN_CAPABILITY_POSTS_WRITE},
+
+	"DeleteFile":       {model.PLUGIN_CAPABILITY_FILES_WRITE},
+	"GetFileThumbnail": {model.PLUGIN_CAPABILITY_FILES_READ},
+
+	"NotifyUser":            {model.PLUGIN_CAPABILITY_NOTIFICATIONS},
+	"WouldUserBeNotified":   {model.PLUGIN_CAPABILITY_NOTIFICATIONS},
+	"SetAnnouncementBanner": {model.PLUGIN_CAPABILITY_ANNOUNCEMENTS},
+
+	"CreateSubscription":          {model.PLUGIN_CAPABILITY_SUBSCRIPTIONS},
+	"ListSubscriptionsForChannel": {model.PLUGIN_CAPABILITY_SUBSCRIPTIONS},
+	"DeleteSubscription":          {model.PLUGIN_CAPABILITY_SUBSCRIPTIONS},
+
+	"RegisterOAuthProvider": {model.PLUGIN_CAPABILITY_OAUTH},
+	"GetOAuthToken":         {model.PLUGIN_CAPABILITY_OAUTH},
+
+	"RequestAdminAction": {model.PLUGIN_CAPABILITY_ADMIN_ACTIONS},
+
+	"KVSet":              {model.PLUGIN_CAPABILITY_KV},
+	"KVSetWithExpiry":    {model.PLUGIN_CAPABILITY_KV},
+	"KVSetMultiple":      {model.PLUGIN_CAPABILITY_KV},
+	"KVSetAtomic":        {model.PLUGIN_CAPABILITY_KV},
+	"KVCompareAndSet":    {model.PLUGIN_CAPABILITY_KV},
+	"KVIncrement":        {model.PLUGIN_CAPABILITY_KV},
+	"KVGet":              {model.PLUGIN_CAPABILITY_KV},
+	"KVGetWithExists":    {model.PLUGIN_CAPABILITY_KV},
+	"KVGetWithOptions":   {model.PLUGIN_CAPABILITY_KV},
+	"KVGetMultiple":      {model.PLUGIN_CAPABILITY_KV},
+	"KVList":             {model.PLUGIN_CAPABILITY_KV},
+	"KVListWithPrefix":   {model.PLUGIN_CAPABILITY_KV},
+	"KVDelete":           {model.PLUGIN_CAPABILITY_KV},
+	"KVDeleteAll":        {model.PLUGIN_CAPABILITY_KV},
+	"KVCompareAndDelete": {model.PLUGIN_CAPABILITY_KV},
+	"KVLock":             {model.PLUGIN_CAPABILITY_KV},
+	"KVUnlock":           {model.PLUGIN_CAPABILITY_KV},
+
+	"GetServerHealth":    nil,
+	"IsHighAvailability": nil,
+
+	"KVSetAndNotify":                 {model.PLUGIN_CAPABILITY_KV, model.PLUGIN_CAPABILITY_WEBSOCKET},
+	"PublishWebSocketEvent":          {model.PLUGIN_CAPABILITY_WEBSOCKET},
+	"WebSocketBroadcastToConnection": {model.PLUGIN_CAPABILITY_WEBSOCKET},
+	"SendToUserWebapp":               {model.PLUGIN_CAPABILITY_WEBSOCKET},
+
+	"StripMarkdown":           nil,
+	"TruncateForNotification": nil,
+	"RenderMessageToHTML":     nil,
+	"GetRequestId":            nil,
+	"LogDebug":                nil,
+	"LogInfo":                 nil,
+	"LogError":                nil,
+	"LogWarn":                 nil,
+}
+
+// NewCapabilityCheckedAPI returns an API that calls check with the name of each method before
+// calling it on api. Methods for which check returns an error are not called, but return the
+// error as their *model.AppError or error result, and the zero value for any other result.
+func NewCapabilityCheckedAPI(api API, check func(method string) *model.AppError) API {
+	return &capabilityCheckedAPI{api: api, check: check}
+}
+
+type capabilityCheckedAPI struct {
+	api   API
+	check func(method string) *model.AppError
+}
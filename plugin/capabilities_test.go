@@ -0,0 +1,77 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package plugin_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+)
+
+func TestAPICapabilities(t *testing.T) {
+	apiType := reflect.TypeOf((*plugin.API)(nil)).Elem()
+
+	for i := 0; i < apiType.NumMethod(); i++ {
+		name := apiType.Method(i).Name
+		_, ok := plugin.APICapabilities[name]
+		assert.True(t, ok, "API method %v must be mapped to the capabilities it requires in plugin.APICapabilities", name)
+	}
+
+	for name, capabilities := range plugin.APICapabilities {
+		_, ok := apiType.MethodByName(name)
+		assert.True(t, ok, "plugin.APICapabilities maps %v, which is not an API method", name)
+
+		for _, capability := range capabilities {
+			assert.True(t, model.IsValidPluginCapability(capability), "%v requires unknown capability %v", name, capability)
+		}
+	}
+}
+
+func TestCapabilityCheckedAPI(t *testing.T) {
+	denied := model.NewAppError("check", "plugin_api.capability.app_error", nil, "", http.StatusForbidden)
+
+	var checked []string
+	check := func(method string) *model.AppError {
+		checked = append(checked, method)
+		if method == "GetUser" || method == "KVSet" || method == "GetConfig" || method == "PublishWebSocketEvent" {
+			return denied
+		}
+		return nil
+	}
+
+	api := &plugintest.API{}
+	defer api.AssertExpectations(t)
+	checkedAPI := plugin.NewCapabilityCheckedAPI(api, check)
+
+	t.Run("denied", func(t *testing.T) {
+		user, err := checkedAPI.GetUser("userid")
+		assert.Nil(t, user)
+		assert.Equal(t, denied, err)
+
+		assert.Equal(t, denied, checkedAPI.KVSet("key", []byte("value")))
+
+		assert.Nil(t, checkedAPI.GetConfig())
+
+		checkedAPI.PublishWebSocketEvent("event", nil, nil)
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		api.On("GetTeam", "teamid").Return(&model.Team{Id: "teamid"}, nil).Once()
+		team, err := checkedAPI.GetTeam("teamid")
+		require.Nil(t, err)
+		assert.Equal(t, "teamid", team.Id)
+
+		api.On("LogDebug", "message", "key", "value").Once()
+		checkedAPI.LogDebug("message", "key", "value")
+	})
+
+	assert.Equal(t, []string{"GetUser", "KVSet", "GetConfig", "PublishWebSocketEvent", "GetTeam", "LogDebug"}, checked)
+}
@@ -35,6 +35,9 @@ type MattermostPlugin struct {
 	// API exposes the plugin api, and becomes available just prior to the OnActive hook.
 	API API
 
+	// Helpers provides higher level functionality built on the API, and becomes available with it.
+	Helpers Helpers
+
 	selfRef interface{} // This is so we can unmarshal into our parent
 }
 
@@ -42,6 +45,7 @@ type MattermostPlugin struct {
 // OnActivate hook, exposing the API for use by the plugin.
 func (p *MattermostPlugin) SetAPI(api API) {
 	p.API = api
+	p.Helpers = &HelpersImpl{API: api}
 }
 
 // SetSelfRef is called by ClientMain to maintain a pointer to the plugin interface originally
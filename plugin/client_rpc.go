@@ -25,6 +25,16 @@ import (
 
 var hookNameToId map[string]int = make(map[string]int)
 
+// hookName returns the name of the hook with the given id, or an empty string if it is unknown.
+func hookName(hookId int) string {
+	for name, id := range hookNameToId {
+		if id == hookId {
+			return name
+		}
+	}
+	return ""
+}
+
 type hooksRPCClient struct {
 	client      *rpc.Client
 	log         *mlog.Logger
@@ -37,6 +47,7 @@ type hooksRPCServer struct {
 	impl         interface{}
 	muxBroker    *plugin.MuxBroker
 	apiRPCClient *apiRPCClient
+	requests     *hookRequests
 }
 
 // Implements hashicorp/go-plugin/plugin.Plugin interface to connect the hooks of a plugin
@@ -47,7 +58,7 @@ type hooksPlugin struct {
 }
 
 func (p *hooksPlugin) Server(b *plugin.MuxBroker) (interface{}, error) {
-	return &hooksRPCServer{impl: p.hooks, muxBroker: b}, nil
+	return &hooksRPCServer{impl: p.hooks, muxBroker: b, requests: &hookRequests{}}, nil
 }
 
 func (p *hooksPlugin) Client(b *plugin.MuxBroker, client *rpc.Client) (interface{}, error) {
@@ -55,11 +66,14 @@ func (p *hooksPlugin) Client(b *plugin.MuxBroker, client *rpc.Client) (interface
 }
 
 type apiRPCClient struct {
-	client *rpc.Client
+	client    *rpc.Client
+	muxBroker *plugin.MuxBroker
+	requests  *hookRequests
 }
 
 type apiRPCServer struct {
-	impl API
+	impl      API
+	muxBroker *plugin.MuxBroker
 }
 
 // Registering some types used by MM for encoding/gob used by rpc
@@ -81,10 +95,18 @@ var _ Hooks = &hooksRPCClient{}
 //
 
 func (g *hooksRPCClient) Implemented() (impl []string, err error) {
-	err = g.client.Call("Plugin.Implemented", struct{}{}, &impl)
-	for _, hookName := range impl {
+	var reported []string
+	err = g.client.Call("Plugin.Implemented", struct{}{}, &reported)
+	for _, hookName := range reported {
 		if hookId, ok := hookNameToId[hookName]; ok {
 			g.implemented[hookId] = true
+			impl = append(impl, hookName)
+		} else if hook, ok := deprecatedHooksByName[hookName]; ok {
+			// Calls are adapted to the deprecated signature by the plugin, so the hook is reported
+			// under its current name.
+			g.log.Warn("Plugin implements a deprecated signature of a hook and should be rebuilt against the current plugin package.", mlog.String("hook", hookName))
+			g.implemented[hook.hookId] = true
+			impl = append(impl, hook.hookName)
 		}
 	}
 	return
@@ -127,7 +149,7 @@ func (s *hooksRPCServer) Implemented(args struct{}, reply *[]string) error {
 		}
 		methods = append(methods, method.Name)
 	}
-	*reply = methods
+	*reply = append(methods, implementedDeprecatedHooks(s.impl)...)
 	return nil
 }
 
@@ -142,7 +164,8 @@ type Z_OnActivateReturns struct {
 func (g *hooksRPCClient) OnActivate() error {
 	muxId := g.muxBroker.NextId()
 	go g.muxBroker.AcceptAndServe(muxId, &apiRPCServer{
-		impl: g.apiImpl,
+		impl:      g.apiImpl,
+		muxBroker: g.muxBroker,
 	})
 
 	_args := &Z_OnActivateArgs{
@@ -163,16 +186,16 @@ func (s *hooksRPCServer) OnActivate(args *Z_OnActivateArgs, returns *Z_OnActivat
 	}
 
 	s.apiRPCClient = &apiRPCClient{
-		client: rpc.NewClient(connection),
+		client:    rpc.NewClient(connection),
+		muxBroker: s.muxBroker,
+		requests:  s.requests,
 	}
 
 	if mmplugin, ok := s.impl.(interface {
 		SetAPI(api API)
-		OnConfigurationChange() error
-	}); !ok {
-	} else {
+	}); ok {
 		mmplugin.SetAPI(s.apiRPCClient)
-		mmplugin.OnConfigurationChange()
+		s.OnConfigurationChange(&Z_OnConfigurationChangeArgs{}, &Z_OnConfigurationChangeReturns{})
 	}
 
 	// Capture output of standard logger because go-plugin
@@ -240,6 +263,12 @@ func (g *hooksRPCClient) ServeHTTP(c *Context, w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	g.forwardHTTPRequest("Plugin.ServeHTTP", c, w, r)
+}
+
+// forwardHTTPRequest streams the given request to the plugin's implementation of an HTTP hook,
+// relaying the response back through w.
+func (g *hooksRPCClient) forwardHTTPRequest(serviceMethod string, c *Context, w http.ResponseWriter, r *http.Request) {
 	serveHTTPStreamId := g.muxBroker.NextId()
 	go func() {
 		connection, err := g.muxBroker.Accept(serveHTTPStreamId)
@@ -286,19 +315,35 @@ func (g *hooksRPCClient) ServeHTTP(c *Context, w http.ResponseWriter, r *http.Re
 		RequestURI: r.RequestURI,
 	}
 
-	if err := g.client.Call("Plugin.ServeHTTP", Z_ServeHTTPArgs{
+	if err := g.client.Call(serviceMethod, Z_ServeHTTPArgs{
 		Context:              c,
 		ResponseWriterStream: serveHTTPStreamId,
 		Request:              forwardedRequest,
 		RequestBodyStream:    requestBodyStreamId,
 	}, nil); err != nil {
-		g.log.Error("Plugin failed to ServeHTTP, RPC call failed", mlog.Err(err))
+		g.log.Error("Plugin failed to serve HTTP request, RPC call failed", mlog.String("method", serviceMethod), mlog.String("request_id", c.requestId()), mlog.Err(err))
 		http.Error(w, "500 internal server error", http.StatusInternalServerError)
 	}
 	return
 }
 
 func (s *hooksRPCServer) ServeHTTP(args *Z_ServeHTTPArgs, returns *struct{}) error {
+	return s.serveHTTPRequest(args, func(c *Context, w http.ResponseWriter, r *http.Request) {
+		if hook, ok := s.impl.(interface {
+			ServeHTTP(c *Context, w http.ResponseWriter, r *http.Request)
+		}); ok {
+			hook.ServeHTTP(c, w, r)
+		} else if hook, ok := s.impl.(deprecatedServeHTTP); ok {
+			hook.ServeHTTP(w, r)
+		} else {
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// serveHTTPRequest connects to the response writer and request body streams described by args and
+// passes the reassembled request to handler.
+func (s *hooksRPCServer) serveHTTPRequest(args *Z_ServeHTTPArgs, handler func(c *Context, w http.ResponseWriter, r *http.Request)) error {
 	connection, err := s.muxBroker.Dial(args.ResponseWriterStream)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "[ERROR] Can't connect to remote response writer stream, error: %v", err.Error())
@@ -320,14 +365,74 @@ func (s *hooksRPCServer) ServeHTTP(args *Z_ServeHTTPArgs, returns *struct{}) err
 	}
 	defer r.Body.Close()
 
+	// The request context does not survive the RPC call, so restore the plugin id from the header.
+	if pluginId := r.Header.Get(model.HEADER_PLUGIN_ID); pluginId != "" {
+		r = RequestWithID(r, pluginId)
+	}
+
+	defer s.requests.begin(args.Context)()
+	handler(args.Context, w, r)
+
+	return nil
+}
+
+func init() {
+	hookNameToId["ServeMetrics"] = ServeMetricsId
+}
+
+func (g *hooksRPCClient) ServeMetrics(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !g.implemented[ServeMetricsId] {
+		http.NotFound(w, r)
+		return
+	}
+
+	g.forwardHTTPRequest("Plugin.ServeMetrics", c, w, r)
+}
+
+func (s *hooksRPCServer) ServeMetrics(args *Z_ServeHTTPArgs, returns *struct{}) error {
+	return s.serveHTTPRequest(args, func(c *Context, w http.ResponseWriter, r *http.Request) {
+		if hook, ok := s.impl.(interface {
+			ServeMetrics(c *Context, w http.ResponseWriter, r *http.Request)
+		}); ok {
+			hook.ServeMetrics(c, w, r)
+		} else {
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func init() {
+	hookNameToId["OnConfigurationChange"] = OnConfigurationChangeId
+}
+
+type Z_OnConfigurationChangeArgs struct {
+}
+
+type Z_OnConfigurationChangeReturns struct {
+	A error
+}
+
+func (g *hooksRPCClient) OnConfigurationChange() error {
+	_args := &Z_OnConfigurationChangeArgs{}
+	_returns := &Z_OnConfigurationChangeReturns{}
+	if g.implemented[OnConfigurationChangeId] {
+		if err := g.client.Call("Plugin.OnConfigurationChange", _args, _returns); err != nil {
+			g.log.Error("RPC call OnConfigurationChange to plugin failed.", mlog.Err(err))
+		}
+	}
+	return _returns.A
+}
+
+func (s *hooksRPCServer) OnConfigurationChange(args *Z_OnConfigurationChangeArgs, returns *Z_OnConfigurationChangeReturns) error {
 	if hook, ok := s.impl.(interface {
-		ServeHTTP(c *Context, w http.ResponseWriter, r *http.Request)
+		OnConfigurationChange() error
 	}); ok {
-		hook.ServeHTTP(args.Context, w, r)
+		returns.A = hook.OnConfigurationChange()
+	} else if hook, ok := s.impl.(deprecatedOnConfigurationChange); ok {
+		hook.OnConfigurationChange()
 	} else {
-		http.NotFound(w, r)
+		return fmt.Errorf("Hook OnConfigurationChange called but not implemented.")
 	}
-
 	return nil
 }
 
@@ -407,9 +512,320 @@ func (s *hooksRPCServer) FileWillBeUploaded(args *Z_FileWillBeUploadedArgs, retu
 	if hook, ok := s.impl.(interface {
 		FileWillBeUploaded(c *Context, info *model.FileInfo, file io.Reader, output io.Writer) (*model.FileInfo, string)
 	}); ok {
+		defer s.requests.begin(args.A)()
 		returns.A, returns.B = hook.FileWillBeUploaded(args.A, args.B, fileReader, returnFileWriter)
 	} else {
 		return fmt.Errorf("Hook FileWillBeUploaded called but not implemented.")
 	}
 	return nil
 }
+
+type Z_KVSetArgs struct {
+	A string
+	B []byte
+
+	// BStream identifies the stream over which a value of BSize bytes is sent instead of B, if the
+	// value is larger than rpcStreamThreshold.
+	BStream uint32
+	BSize   int
+}
+
+type Z_KVSetReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) KVSet(key string, value []byte) *model.AppError {
+	_args := &Z_KVSetArgs{A: key}
+	if len(value) > rpcStreamThreshold {
+		_args.BStream, _args.BSize = serveBytesStream(g.muxBroker, value), len(value)
+	} else {
+		_args.B = value
+	}
+	_returns := &Z_KVSetReturns{}
+	if err := g.client.Call("Plugin.KVSet", _args, _returns); err != nil {
+		log.Printf("RPC call to KVSet API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) KVSet(args *Z_KVSetArgs, returns *Z_KVSetReturns) error {
+	value := args.B
+	if args.BStream != 0 {
+		var err error
+		if value, err = readBytesStream(s.muxBroker, args.BStream, args.BSize); err != nil {
+			return fmt.Errorf("Can't read value stream for KVSet: %v", err.Error())
+		}
+	}
+
+	if hook, ok := s.impl.(interface {
+		KVSet(key string, value []byte) *model.AppError
+	}); ok {
+		returns.A = hook.KVSet(args.A, value)
+	} else {
+		return fmt.Errorf("API KVSet called but not implemented.")
+	}
+	return nil
+}
+
+type Z_KVGetArgs struct {
+	A string
+}
+
+type Z_KVGetReturns struct {
+	A []byte
+	B *model.AppError
+
+	// AStream identifies the stream over which a value of ASize bytes is sent instead of A, if the
+	// value is larger than rpcStreamThreshold.
+	AStream uint32
+	ASize   int
+}
+
+func (g *apiRPCClient) KVGet(key string) ([]byte, *model.AppError) {
+	_args := &Z_KVGetArgs{key}
+	_returns := &Z_KVGetReturns{}
+	if err := g.client.Call("Plugin.KVGet", _args, _returns); err != nil {
+		log.Printf("RPC call to KVGet API failed: %s", err.Error())
+	}
+	if _returns.AStream != 0 {
+		value, err := readBytesStream(g.muxBroker, _returns.AStream, _returns.ASize)
+		if err != nil {
+			log.Printf("RPC call to KVGet API failed to read value stream: %s", err.Error())
+			return nil, model.NewAppError("KVGet", "plugin.rpc.stream.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		return value, _returns.B
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) KVGet(args *Z_KVGetArgs, returns *Z_KVGetReturns) error {
+	if hook, ok := s.impl.(interface {
+		KVGet(key string) ([]byte, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.KVGet(args.A)
+	} else {
+		return fmt.Errorf("API KVGet called but not implemented.")
+	}
+
+	if len(returns.A) > rpcStreamThreshold {
+		returns.AStream, returns.ASize = serveBytesStream(s.muxBroker, returns.A), len(returns.A)
+		returns.A = nil
+	}
+	return nil
+}
+
+type Z_KVGetWithExistsArgs struct {
+	A string
+}
+
+type Z_KVGetWithExistsReturns struct {
+	A []byte
+	B bool
+	C *model.AppError
+
+	// AStream identifies the stream over which a value of ASize bytes is sent instead of A, if the
+	// value is larger than rpcStreamThreshold.
+	AStream uint32
+	ASize   int
+}
+
+func (g *apiRPCClient) KVGetWithExists(key string) ([]byte, bool, *model.AppError) {
+	_args := &Z_KVGetWithExistsArgs{key}
+	_returns := &Z_KVGetWithExistsReturns{}
+	if err := g.client.Call("Plugin.KVGetWithExists", _args, _returns); err != nil {
+		log.Printf("RPC call to KVGetWithExists API failed: %s", err.Error())
+	}
+	if _returns.AStream != 0 {
+		value, err := readBytesStream(g.muxBroker, _returns.AStream, _returns.ASize)
+		if err != nil {
+			log.Printf("RPC call to KVGetWithExists API failed to read value stream: %s", err.Error())
+			return nil, false, model.NewAppError("KVGetWithExists", "plugin.rpc.stream.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		return value, _returns.B, _returns.C
+	}
+	// Empty values do not survive encoding, but the value of an existing key is never nil.
+	if _returns.B && _returns.A == nil {
+		_returns.A = []byte{}
+	}
+	return _returns.A, _returns.B, _returns.C
+}
+
+func (s *apiRPCServer) KVGetWithExists(args *Z_KVGetWithExistsArgs, returns *Z_KVGetWithExistsReturns) error {
+	if hook, ok := s.impl.(interface {
+		KVGetWithExists(key string) ([]byte, bool, *model.AppError)
+	}); ok {
+		returns.A, returns.B, returns.C = hook.KVGetWithExists(args.A)
+	} else {
+		return fmt.Errorf("API KVGetWithExists called but not implemented.")
+	}
+
+	if len(returns.A) > rpcStreamThreshold {
+		returns.AStream, returns.ASize = serveBytesStream(s.muxBroker, returns.A), len(returns.A)
+		returns.A = nil
+	}
+	return nil
+}
+
+type Z_KVGetWithOptionsArgs struct {
+	A string
+	B model.PluginKVGetOptions
+}
+
+type Z_KVGetWithOptionsReturns struct {
+	A []byte
+	B *model.AppError
+
+	// AStream identifies the stream over which a value of ASize bytes is sent instead of A, if the
+	// value is larger than rpcStreamThreshold.
+	AStream uint32
+	ASize   int
+}
+
+func (g *apiRPCClient) KVGetWithOptions(key string, opts model.PluginKVGetOptions) ([]byte, *model.AppError) {
+	_args := &Z_KVGetWithOptionsArgs{key, opts}
+	_returns := &Z_KVGetWithOptionsReturns{}
+	if err := g.client.Call("Plugin.KVGetWithOptions", _args, _returns); err != nil {
+		log.Printf("RPC call to KVGetWithOptions API failed: %s", err.Error())
+	}
+	if _returns.AStream != 0 {
+		value, err := readBytesStream(g.muxBroker, _returns.AStream, _returns.ASize)
+		if err != nil {
+			log.Printf("RPC call to KVGetWithOptions API failed to read value stream: %s", err.Error())
+			return nil, model.NewAppError("KVGetWithOptions", "plugin.rpc.stream.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		return value, _returns.B
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) KVGetWithOptions(args *Z_KVGetWithOptionsArgs, returns *Z_KVGetWithOptionsReturns) error {
+	if hook, ok := s.impl.(interface {
+		KVGetWithOptions(key string, opts model.PluginKVGetOptions) ([]byte, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.KVGetWithOptions(args.A, args.B)
+	} else {
+		return fmt.Errorf("API KVGetWithOptions called but not implemented.")
+	}
+
+	if len(returns.A) > rpcStreamThreshold {
+		returns.AStream, returns.ASize = serveBytesStream(s.muxBroker, returns.A), len(returns.A)
+		returns.A = nil
+	}
+	return nil
+}
+
+// GetRequestId is answered by the plugin itself, since the request id is part of the context.
+func (g *apiRPCClient) GetRequestId(c *Context) string {
+	return c.requestId()
+}
+
+// The logging APIs attach the request id of the hook invocation in progress, if any, to the log
+// messages before sending them to the server.
+
+type Z_LogDebugArgs struct {
+	A string
+	B []interface{}
+}
+
+type Z_LogDebugReturns struct {
+}
+
+func (g *apiRPCClient) LogDebug(msg string, keyValuePairs ...interface{}) {
+	_args := &Z_LogDebugArgs{msg, g.requests.withRequestId(keyValuePairs)}
+	_returns := &Z_LogDebugReturns{}
+	if err := g.client.Call("Plugin.LogDebug", _args, _returns); err != nil {
+		log.Printf("RPC call to LogDebug API failed: %s", err.Error())
+	}
+}
+
+func (s *apiRPCServer) LogDebug(args *Z_LogDebugArgs, returns *Z_LogDebugReturns) error {
+	if hook, ok := s.impl.(interface {
+		LogDebug(msg string, keyValuePairs ...interface{})
+	}); ok {
+		hook.LogDebug(args.A, args.B...)
+	} else {
+		return fmt.Errorf("API LogDebug called but not implemented.")
+	}
+	return nil
+}
+
+type Z_LogInfoArgs struct {
+	A string
+	B []interface{}
+}
+
+type Z_LogInfoReturns struct {
+}
+
+func (g *apiRPCClient) LogInfo(msg string, keyValuePairs ...interface{}) {
+	_args := &Z_LogInfoArgs{msg, g.requests.withRequestId(keyValuePairs)}
+	_returns := &Z_LogInfoReturns{}
+	if err := g.client.Call("Plugin.LogInfo", _args, _returns); err != nil {
+		log.Printf("RPC call to LogInfo API failed: %s", err.Error())
+	}
+}
+
+func (s *apiRPCServer) LogInfo(args *Z_LogInfoArgs, returns *Z_LogInfoReturns) error {
+	if hook, ok := s.impl.(interface {
+		LogInfo(msg string, keyValuePairs ...interface{})
+	}); ok {
+		hook.LogInfo(args.A, args.B...)
+	} else {
+		return fmt.Errorf("API LogInfo called but not implemented.")
+	}
+	return nil
+}
+
+type Z_LogErrorArgs struct {
+	A string
+	B []interface{}
+}
+
+type Z_LogErrorReturns struct {
+}
+
+func (g *apiRPCClient) LogError(msg string, keyValuePairs ...interface{}) {
+	_args := &Z_LogErrorArgs{msg, g.requests.withRequestId(keyValuePairs)}
+	_returns := &Z_LogErrorReturns{}
+	if err := g.client.Call("Plugin.LogError", _args, _returns); err != nil {
+		log.Printf("RPC call to LogError API failed: %s", err.Error())
+	}
+}
+
+func (s *apiRPCServer) LogError(args *Z_LogErrorArgs, returns *Z_LogErrorReturns) error {
+	if hook, ok := s.impl.(interface {
+		LogError(msg string, keyValuePairs ...interface{})
+	}); ok {
+		hook.LogError(args.A, args.B...)
+	} else {
+		return fmt.Errorf("API LogError called but not implemented.")
+	}
+	return nil
+}
+
+type Z_LogWarnArgs struct {
+	A string
+	B []interface{}
+}
+
+type Z_LogWarnReturns struct {
+}
+
+func (g *apiRPCClient) LogWarn(msg string, keyValuePairs ...interface{}) {
+	_args := &Z_LogWarnArgs{msg, g.requests.withRequestId(keyValuePairs)}
+	_returns := &Z_LogWarnReturns{}
+	if err := g.client.Call("Plugin.LogWarn", _args, _returns); err != nil {
+		log.Printf("RPC call to LogWarn API failed: %s", err.Error())
+	}
+}
+
+func (s *apiRPCServer) LogWarn(args *Z_LogWarnArgs, returns *Z_LogWarnReturns) error {
+	if hook, ok := s.impl.(interface {
+		LogWarn(msg string, keyValuePairs ...interface{})
+	}); ok {
+		hook.LogWarn(args.A, args.B...)
+	} else {
+		return fmt.Errorf("API LogWarn called but not implemented.")
+	}
+	return nil
+}
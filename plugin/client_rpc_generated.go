@@ -9,6 +9,7 @@ package plugin
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
@@ -48,34 +49,36 @@ func (s *hooksRPCServer) OnDeactivate(args *Z_OnDeactivateArgs, returns *Z_OnDea
 }
 
 func init() {
-	hookNameToId["OnConfigurationChange"] = OnConfigurationChangeId
+	hookNameToId["OnMigrate"] = OnMigrateId
 }
 
-type Z_OnConfigurationChangeArgs struct {
+type Z_OnMigrateArgs struct {
+	A int
+	B int
 }
 
-type Z_OnConfigurationChangeReturns struct {
+type Z_OnMigrateReturns struct {
 	A error
 }
 
-func (g *hooksRPCClient) OnConfigurationChange() error {
-	_args := &Z_OnConfigurationChangeArgs{}
-	_returns := &Z_OnConfigurationChangeReturns{}
-	if g.implemented[OnConfigurationChangeId] {
-		if err := g.client.Call("Plugin.OnConfigurationChange", _args, _returns); err != nil {
-			g.log.Error("RPC call OnConfigurationChange to plugin failed.", mlog.Err(err))
+func (g *hooksRPCClient) OnMigrate(fromVersion, toVersion int) error {
+	_args := &Z_OnMigrateArgs{fromVersion, toVersion}
+	_returns := &Z_OnMigrateReturns{}
+	if g.implemented[OnMigrateId] {
+		if err := g.client.Call("Plugin.OnMigrate", _args, _returns); err != nil {
+			g.log.Error("RPC call OnMigrate to plugin failed.", mlog.Err(err))
 		}
 	}
 	return _returns.A
 }
 
-func (s *hooksRPCServer) OnConfigurationChange(args *Z_OnConfigurationChangeArgs, returns *Z_OnConfigurationChangeReturns) error {
+func (s *hooksRPCServer) OnMigrate(args *Z_OnMigrateArgs, returns *Z_OnMigrateReturns) error {
 	if hook, ok := s.impl.(interface {
-		OnConfigurationChange() error
+		OnMigrate(fromVersion, toVersion int) error
 	}); ok {
-		returns.A = hook.OnConfigurationChange()
+		returns.A = hook.OnMigrate(args.A, args.B)
 	} else {
-		return fmt.Errorf("Hook OnConfigurationChange called but not implemented.")
+		return fmt.Errorf("Hook OnMigrate called but not implemented.")
 	}
 	return nil
 }
@@ -99,7 +102,7 @@ func (g *hooksRPCClient) ExecuteCommand(c *Context, args *model.CommandArgs) (*m
 	_returns := &Z_ExecuteCommandReturns{}
 	if g.implemented[ExecuteCommandId] {
 		if err := g.client.Call("Plugin.ExecuteCommand", _args, _returns); err != nil {
-			g.log.Error("RPC call ExecuteCommand to plugin failed.", mlog.Err(err))
+			g.log.Error("RPC call ExecuteCommand to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
 		}
 	}
 	return _returns.A, _returns.B
@@ -109,6 +112,7 @@ func (s *hooksRPCServer) ExecuteCommand(args *Z_ExecuteCommandArgs, returns *Z_E
 	if hook, ok := s.impl.(interface {
 		ExecuteCommand(c *Context, args *model.CommandArgs) (*model.CommandResponse, *model.AppError)
 	}); ok {
+		defer s.requests.begin(args.A)()
 		returns.A, returns.B = hook.ExecuteCommand(args.A, args.B)
 	} else {
 		return fmt.Errorf("Hook ExecuteCommand called but not implemented.")
@@ -135,7 +139,7 @@ func (g *hooksRPCClient) MessageWillBePosted(c *Context, post *model.Post) (*mod
 	_returns := &Z_MessageWillBePostedReturns{}
 	if g.implemented[MessageWillBePostedId] {
 		if err := g.client.Call("Plugin.MessageWillBePosted", _args, _returns); err != nil {
-			g.log.Error("RPC call MessageWillBePosted to plugin failed.", mlog.Err(err))
+			g.log.Error("RPC call MessageWillBePosted to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
 		}
 	}
 	return _returns.A, _returns.B
@@ -145,6 +149,7 @@ func (s *hooksRPCServer) MessageWillBePosted(args *Z_MessageWillBePostedArgs, re
 	if hook, ok := s.impl.(interface {
 		MessageWillBePosted(c *Context, post *model.Post) (*model.Post, string)
 	}); ok {
+		defer s.requests.begin(args.A)()
 		returns.A, returns.B = hook.MessageWillBePosted(args.A, args.B)
 	} else {
 		return fmt.Errorf("Hook MessageWillBePosted called but not implemented.")
@@ -172,7 +177,7 @@ func (g *hooksRPCClient) MessageWillBeUpdated(c *Context, newPost, oldPost *mode
 	_returns := &Z_MessageWillBeUpdatedReturns{}
 	if g.implemented[MessageWillBeUpdatedId] {
 		if err := g.client.Call("Plugin.MessageWillBeUpdated", _args, _returns); err != nil {
-			g.log.Error("RPC call MessageWillBeUpdated to plugin failed.", mlog.Err(err))
+			g.log.Error("RPC call MessageWillBeUpdated to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
 		}
 	}
 	return _returns.A, _returns.B
@@ -182,6 +187,7 @@ func (s *hooksRPCServer) MessageWillBeUpdated(args *Z_MessageWillBeUpdatedArgs,
 	if hook, ok := s.impl.(interface {
 		MessageWillBeUpdated(c *Context, newPost, oldPost *model.Post) (*model.Post, string)
 	}); ok {
+		defer s.requests.begin(args.A)()
 		returns.A, returns.B = hook.MessageWillBeUpdated(args.A, args.B, args.C)
 	} else {
 		return fmt.Errorf("Hook MessageWillBeUpdated called but not implemented.")
@@ -206,7 +212,7 @@ func (g *hooksRPCClient) MessageHasBeenPosted(c *Context, post *model.Post) {
 	_returns := &Z_MessageHasBeenPostedReturns{}
 	if g.implemented[MessageHasBeenPostedId] {
 		if err := g.client.Call("Plugin.MessageHasBeenPosted", _args, _returns); err != nil {
-			g.log.Error("RPC call MessageHasBeenPosted to plugin failed.", mlog.Err(err))
+			g.log.Error("RPC call MessageHasBeenPosted to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
 		}
 	}
 	return
@@ -216,6 +222,7 @@ func (s *hooksRPCServer) MessageHasBeenPosted(args *Z_MessageHasBeenPostedArgs,
 	if hook, ok := s.impl.(interface {
 		MessageHasBeenPosted(c *Context, post *model.Post)
 	}); ok {
+		defer s.requests.begin(args.A)()
 		hook.MessageHasBeenPosted(args.A, args.B)
 	} else {
 		return fmt.Errorf("Hook MessageHasBeenPosted called but not implemented.")
@@ -241,7 +248,7 @@ func (g *hooksRPCClient) MessageHasBeenUpdated(c *Context, newPost, oldPost *mod
 	_returns := &Z_MessageHasBeenUpdatedReturns{}
 	if g.implemented[MessageHasBeenUpdatedId] {
 		if err := g.client.Call("Plugin.MessageHasBeenUpdated", _args, _returns); err != nil {
-			g.log.Error("RPC call MessageHasBeenUpdated to plugin failed.", mlog.Err(err))
+			g.log.Error("RPC call MessageHasBeenUpdated to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
 		}
 	}
 	return
@@ -251,6 +258,7 @@ func (s *hooksRPCServer) MessageHasBeenUpdated(args *Z_MessageHasBeenUpdatedArgs
 	if hook, ok := s.impl.(interface {
 		MessageHasBeenUpdated(c *Context, newPost, oldPost *model.Post)
 	}); ok {
+		defer s.requests.begin(args.A)()
 		hook.MessageHasBeenUpdated(args.A, args.B, args.C)
 	} else {
 		return fmt.Errorf("Hook MessageHasBeenUpdated called but not implemented.")
@@ -258,6 +266,42 @@ func (s *hooksRPCServer) MessageHasBeenUpdated(args *Z_MessageHasBeenUpdatedArgs
 	return nil
 }
 
+func init() {
+	hookNameToId["PostHasBeenAcknowledged"] = PostHasBeenAcknowledgedId
+}
+
+type Z_PostHasBeenAcknowledgedArgs struct {
+	A *Context
+	B string
+	C string
+}
+
+type Z_PostHasBeenAcknowledgedReturns struct {
+}
+
+func (g *hooksRPCClient) PostHasBeenAcknowledged(c *Context, postId, userId string) {
+	_args := &Z_PostHasBeenAcknowledgedArgs{c, postId, userId}
+	_returns := &Z_PostHasBeenAcknowledgedReturns{}
+	if g.implemented[PostHasBeenAcknowledgedId] {
+		if err := g.client.Call("Plugin.PostHasBeenAcknowledged", _args, _returns); err != nil {
+			g.log.Error("RPC call PostHasBeenAcknowledged to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
+		}
+	}
+	return
+}
+
+func (s *hooksRPCServer) PostHasBeenAcknowledged(args *Z_PostHasBeenAcknowledgedArgs, returns *Z_PostHasBeenAcknowledgedReturns) error {
+	if hook, ok := s.impl.(interface {
+		PostHasBeenAcknowledged(c *Context, postId, userId string)
+	}); ok {
+		defer s.requests.begin(args.A)()
+		hook.PostHasBeenAcknowledged(args.A, args.B, args.C)
+	} else {
+		return fmt.Errorf("Hook PostHasBeenAcknowledged called but not implemented.")
+	}
+	return nil
+}
+
 func init() {
 	hookNameToId["ChannelHasBeenCreated"] = ChannelHasBeenCreatedId
 }
@@ -275,7 +319,7 @@ func (g *hooksRPCClient) ChannelHasBeenCreated(c *Context, channel *model.Channe
 	_returns := &Z_ChannelHasBeenCreatedReturns{}
 	if g.implemented[ChannelHasBeenCreatedId] {
 		if err := g.client.Call("Plugin.ChannelHasBeenCreated", _args, _returns); err != nil {
-			g.log.Error("RPC call ChannelHasBeenCreated to plugin failed.", mlog.Err(err))
+			g.log.Error("RPC call ChannelHasBeenCreated to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
 		}
 	}
 	return
@@ -285,6 +329,7 @@ func (s *hooksRPCServer) ChannelHasBeenCreated(args *Z_ChannelHasBeenCreatedArgs
 	if hook, ok := s.impl.(interface {
 		ChannelHasBeenCreated(c *Context, channel *model.Channel)
 	}); ok {
+		defer s.requests.begin(args.A)()
 		hook.ChannelHasBeenCreated(args.A, args.B)
 	} else {
 		return fmt.Errorf("Hook ChannelHasBeenCreated called but not implemented.")
@@ -310,7 +355,7 @@ func (g *hooksRPCClient) UserHasJoinedChannel(c *Context, channelMember *model.C
 	_returns := &Z_UserHasJoinedChannelReturns{}
 	if g.implemented[UserHasJoinedChannelId] {
 		if err := g.client.Call("Plugin.UserHasJoinedChannel", _args, _returns); err != nil {
-			g.log.Error("RPC call UserHasJoinedChannel to plugin failed.", mlog.Err(err))
+			g.log.Error("RPC call UserHasJoinedChannel to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
 		}
 	}
 	return
@@ -320,6 +365,7 @@ func (s *hooksRPCServer) UserHasJoinedChannel(args *Z_UserHasJoinedChannelArgs,
 	if hook, ok := s.impl.(interface {
 		UserHasJoinedChannel(c *Context, channelMember *model.ChannelMember, actor *model.User)
 	}); ok {
+		defer s.requests.begin(args.A)()
 		hook.UserHasJoinedChannel(args.A, args.B, args.C)
 	} else {
 		return fmt.Errorf("Hook UserHasJoinedChannel called but not implemented.")
@@ -345,7 +391,7 @@ func (g *hooksRPCClient) UserHasLeftChannel(c *Context, channelMember *model.Cha
 	_returns := &Z_UserHasLeftChannelReturns{}
 	if g.implemented[UserHasLeftChannelId] {
 		if err := g.client.Call("Plugin.UserHasLeftChannel", _args, _returns); err != nil {
-			g.log.Error("RPC call UserHasLeftChannel to plugin failed.", mlog.Err(err))
+			g.log.Error("RPC call UserHasLeftChannel to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
 		}
 	}
 	return
@@ -355,6 +401,7 @@ func (s *hooksRPCServer) UserHasLeftChannel(args *Z_UserHasLeftChannelArgs, retu
 	if hook, ok := s.impl.(interface {
 		UserHasLeftChannel(c *Context, channelMember *model.ChannelMember, actor *model.User)
 	}); ok {
+		defer s.requests.begin(args.A)()
 		hook.UserHasLeftChannel(args.A, args.B, args.C)
 	} else {
 		return fmt.Errorf("Hook UserHasLeftChannel called but not implemented.")
@@ -380,7 +427,7 @@ func (g *hooksRPCClient) UserHasJoinedTeam(c *Context, teamMember *model.TeamMem
 	_returns := &Z_UserHasJoinedTeamReturns{}
 	if g.implemented[UserHasJoinedTeamId] {
 		if err := g.client.Call("Plugin.UserHasJoinedTeam", _args, _returns); err != nil {
-			g.log.Error("RPC call UserHasJoinedTeam to plugin failed.", mlog.Err(err))
+			g.log.Error("RPC call UserHasJoinedTeam to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
 		}
 	}
 	return
@@ -390,6 +437,7 @@ func (s *hooksRPCServer) UserHasJoinedTeam(args *Z_UserHasJoinedTeamArgs, return
 	if hook, ok := s.impl.(interface {
 		UserHasJoinedTeam(c *Context, teamMember *model.TeamMember, actor *model.User)
 	}); ok {
+		defer s.requests.begin(args.A)()
 		hook.UserHasJoinedTeam(args.A, args.B, args.C)
 	} else {
 		return fmt.Errorf("Hook UserHasJoinedTeam called but not implemented.")
@@ -415,7 +463,7 @@ func (g *hooksRPCClient) UserHasLeftTeam(c *Context, teamMember *model.TeamMembe
 	_returns := &Z_UserHasLeftTeamReturns{}
 	if g.implemented[UserHasLeftTeamId] {
 		if err := g.client.Call("Plugin.UserHasLeftTeam", _args, _returns); err != nil {
-			g.log.Error("RPC call UserHasLeftTeam to plugin failed.", mlog.Err(err))
+			g.log.Error("RPC call UserHasLeftTeam to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
 		}
 	}
 	return
@@ -425,6 +473,7 @@ func (s *hooksRPCServer) UserHasLeftTeam(args *Z_UserHasLeftTeamArgs, returns *Z
 	if hook, ok := s.impl.(interface {
 		UserHasLeftTeam(c *Context, teamMember *model.TeamMember, actor *model.User)
 	}); ok {
+		defer s.requests.begin(args.A)()
 		hook.UserHasLeftTeam(args.A, args.B, args.C)
 	} else {
 		return fmt.Errorf("Hook UserHasLeftTeam called but not implemented.")
@@ -433,1446 +482,3434 @@ func (s *hooksRPCServer) UserHasLeftTeam(args *Z_UserHasLeftTeamArgs, returns *Z
 }
 
 func init() {
-	hookNameToId["UserWillLogIn"] = UserWillLogInId
+	hookNameToId["UserHasBeenCreated"] = UserHasBeenCreatedId
 }
 
-type Z_UserWillLogInArgs struct {
+type Z_UserHasBeenCreatedArgs struct {
 	A *Context
 	B *model.User
 }
 
-type Z_UserWillLogInReturns struct {
-	A string
+type Z_UserHasBeenCreatedReturns struct {
 }
 
-func (g *hooksRPCClient) UserWillLogIn(c *Context, user *model.User) string {
-	_args := &Z_UserWillLogInArgs{c, user}
-	_returns := &Z_UserWillLogInReturns{}
-	if g.implemented[UserWillLogInId] {
-		if err := g.client.Call("Plugin.UserWillLogIn", _args, _returns); err != nil {
-			g.log.Error("RPC call UserWillLogIn to plugin failed.", mlog.Err(err))
+func (g *hooksRPCClient) UserHasBeenCreated(c *Context, user *model.User) {
+	_args := &Z_UserHasBeenCreatedArgs{c, user}
+	_returns := &Z_UserHasBeenCreatedReturns{}
+	if g.implemented[UserHasBeenCreatedId] {
+		if err := g.client.Call("Plugin.UserHasBeenCreated", _args, _returns); err != nil {
+			g.log.Error("RPC call UserHasBeenCreated to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
 		}
 	}
-	return _returns.A
+	return
 }
 
-func (s *hooksRPCServer) UserWillLogIn(args *Z_UserWillLogInArgs, returns *Z_UserWillLogInReturns) error {
+func (s *hooksRPCServer) UserHasBeenCreated(args *Z_UserHasBeenCreatedArgs, returns *Z_UserHasBeenCreatedReturns) error {
 	if hook, ok := s.impl.(interface {
-		UserWillLogIn(c *Context, user *model.User) string
+		UserHasBeenCreated(c *Context, user *model.User)
 	}); ok {
-		returns.A = hook.UserWillLogIn(args.A, args.B)
+		defer s.requests.begin(args.A)()
+		hook.UserHasBeenCreated(args.A, args.B)
 	} else {
-		return fmt.Errorf("Hook UserWillLogIn called but not implemented.")
+		return fmt.Errorf("Hook UserHasBeenCreated called but not implemented.")
 	}
 	return nil
 }
 
 func init() {
-	hookNameToId["UserHasLoggedIn"] = UserHasLoggedInId
+	hookNameToId["UsersHaveBeenCreated"] = UsersHaveBeenCreatedId
 }
 
-type Z_UserHasLoggedInArgs struct {
+type Z_UsersHaveBeenCreatedArgs struct {
 	A *Context
-	B *model.User
+	B []*model.User
 }
 
-type Z_UserHasLoggedInReturns struct {
+type Z_UsersHaveBeenCreatedReturns struct {
 }
 
-func (g *hooksRPCClient) UserHasLoggedIn(c *Context, user *model.User) {
-	_args := &Z_UserHasLoggedInArgs{c, user}
-	_returns := &Z_UserHasLoggedInReturns{}
-	if g.implemented[UserHasLoggedInId] {
-		if err := g.client.Call("Plugin.UserHasLoggedIn", _args, _returns); err != nil {
-			g.log.Error("RPC call UserHasLoggedIn to plugin failed.", mlog.Err(err))
+func (g *hooksRPCClient) UsersHaveBeenCreated(c *Context, users []*model.User) {
+	_args := &Z_UsersHaveBeenCreatedArgs{c, users}
+	_returns := &Z_UsersHaveBeenCreatedReturns{}
+	if g.implemented[UsersHaveBeenCreatedId] {
+		if err := g.client.Call("Plugin.UsersHaveBeenCreated", _args, _returns); err != nil {
+			g.log.Error("RPC call UsersHaveBeenCreated to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
 		}
 	}
 	return
 }
 
-func (s *hooksRPCServer) UserHasLoggedIn(args *Z_UserHasLoggedInArgs, returns *Z_UserHasLoggedInReturns) error {
+func (s *hooksRPCServer) UsersHaveBeenCreated(args *Z_UsersHaveBeenCreatedArgs, returns *Z_UsersHaveBeenCreatedReturns) error {
 	if hook, ok := s.impl.(interface {
-		UserHasLoggedIn(c *Context, user *model.User)
+		UsersHaveBeenCreated(c *Context, users []*model.User)
 	}); ok {
-		hook.UserHasLoggedIn(args.A, args.B)
+		defer s.requests.begin(args.A)()
+		hook.UsersHaveBeenCreated(args.A, args.B)
 	} else {
-		return fmt.Errorf("Hook UserHasLoggedIn called but not implemented.")
+		return fmt.Errorf("Hook UsersHaveBeenCreated called but not implemented.")
 	}
 	return nil
 }
 
-type Z_RegisterCommandArgs struct {
-	A *model.Command
+func init() {
+	hookNameToId["TeamMembersHaveChanged"] = TeamMembersHaveChangedId
 }
 
-type Z_RegisterCommandReturns struct {
-	A error
+type Z_TeamMembersHaveChangedArgs struct {
+	A *Context
+	B []*model.TeamMember
 }
 
-func (g *apiRPCClient) RegisterCommand(command *model.Command) error {
-	_args := &Z_RegisterCommandArgs{command}
-	_returns := &Z_RegisterCommandReturns{}
-	if err := g.client.Call("Plugin.RegisterCommand", _args, _returns); err != nil {
-		log.Printf("RPC call to RegisterCommand API failed: %s", err.Error())
+type Z_TeamMembersHaveChangedReturns struct {
+}
+
+func (g *hooksRPCClient) TeamMembersHaveChanged(c *Context, teamMembers []*model.TeamMember) {
+	_args := &Z_TeamMembersHaveChangedArgs{c, teamMembers}
+	_returns := &Z_TeamMembersHaveChangedReturns{}
+	if g.implemented[TeamMembersHaveChangedId] {
+		if err := g.client.Call("Plugin.TeamMembersHaveChanged", _args, _returns); err != nil {
+			g.log.Error("RPC call TeamMembersHaveChanged to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
+		}
 	}
-	return _returns.A
+	return
 }
 
-func (s *apiRPCServer) RegisterCommand(args *Z_RegisterCommandArgs, returns *Z_RegisterCommandReturns) error {
+func (s *hooksRPCServer) TeamMembersHaveChanged(args *Z_TeamMembersHaveChangedArgs, returns *Z_TeamMembersHaveChangedReturns) error {
 	if hook, ok := s.impl.(interface {
-		RegisterCommand(command *model.Command) error
+		TeamMembersHaveChanged(c *Context, teamMembers []*model.TeamMember)
 	}); ok {
-		returns.A = hook.RegisterCommand(args.A)
+		defer s.requests.begin(args.A)()
+		hook.TeamMembersHaveChanged(args.A, args.B)
 	} else {
-		return fmt.Errorf("API RegisterCommand called but not implemented.")
+		return fmt.Errorf("Hook TeamMembersHaveChanged called but not implemented.")
 	}
 	return nil
 }
 
-type Z_UnregisterCommandArgs struct {
+func init() {
+	hookNameToId["BulkOperationHasCompleted"] = BulkOperationHasCompletedId
+}
+
+type Z_BulkOperationHasCompletedArgs struct {
 	A string
-	B string
+	B map[string]int
 }
 
-type Z_UnregisterCommandReturns struct {
-	A error
+type Z_BulkOperationHasCompletedReturns struct {
 }
 
-func (g *apiRPCClient) UnregisterCommand(teamId, trigger string) error {
-	_args := &Z_UnregisterCommandArgs{teamId, trigger}
-	_returns := &Z_UnregisterCommandReturns{}
-	if err := g.client.Call("Plugin.UnregisterCommand", _args, _returns); err != nil {
-		log.Printf("RPC call to UnregisterCommand API failed: %s", err.Error())
+func (g *hooksRPCClient) BulkOperationHasCompleted(opType string, counts map[string]int) {
+	_args := &Z_BulkOperationHasCompletedArgs{opType, counts}
+	_returns := &Z_BulkOperationHasCompletedReturns{}
+	if g.implemented[BulkOperationHasCompletedId] {
+		if err := g.client.Call("Plugin.BulkOperationHasCompleted", _args, _returns); err != nil {
+			g.log.Error("RPC call BulkOperationHasCompleted to plugin failed.", mlog.Err(err))
+		}
 	}
-	return _returns.A
+	return
 }
 
-func (s *apiRPCServer) UnregisterCommand(args *Z_UnregisterCommandArgs, returns *Z_UnregisterCommandReturns) error {
+func (s *hooksRPCServer) BulkOperationHasCompleted(args *Z_BulkOperationHasCompletedArgs, returns *Z_BulkOperationHasCompletedReturns) error {
 	if hook, ok := s.impl.(interface {
-		UnregisterCommand(teamId, trigger string) error
+		BulkOperationHasCompleted(opType string, counts map[string]int)
 	}); ok {
-		returns.A = hook.UnregisterCommand(args.A, args.B)
+		hook.BulkOperationHasCompleted(args.A, args.B)
 	} else {
-		return fmt.Errorf("API UnregisterCommand called but not implemented.")
+		return fmt.Errorf("Hook BulkOperationHasCompleted called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetConfigArgs struct {
+func init() {
+	hookNameToId["OnWebSocketConnect"] = OnWebSocketConnectId
 }
 
-type Z_GetConfigReturns struct {
-	A *model.Config
+type Z_OnWebSocketConnectArgs struct {
+	A string
+	B string
 }
 
-func (g *apiRPCClient) GetConfig() *model.Config {
-	_args := &Z_GetConfigArgs{}
-	_returns := &Z_GetConfigReturns{}
-	if err := g.client.Call("Plugin.GetConfig", _args, _returns); err != nil {
-		log.Printf("RPC call to GetConfig API failed: %s", err.Error())
+type Z_OnWebSocketConnectReturns struct {
+}
+
+func (g *hooksRPCClient) OnWebSocketConnect(webConnID, userId string) {
+	_args := &Z_OnWebSocketConnectArgs{webConnID, userId}
+	_returns := &Z_OnWebSocketConnectReturns{}
+	if g.implemented[OnWebSocketConnectId] {
+		if err := g.client.Call("Plugin.OnWebSocketConnect", _args, _returns); err != nil {
+			g.log.Error("RPC call OnWebSocketConnect to plugin failed.", mlog.Err(err))
+		}
 	}
-	return _returns.A
+	return
 }
 
-func (s *apiRPCServer) GetConfig(args *Z_GetConfigArgs, returns *Z_GetConfigReturns) error {
+func (s *hooksRPCServer) OnWebSocketConnect(args *Z_OnWebSocketConnectArgs, returns *Z_OnWebSocketConnectReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetConfig() *model.Config
+		OnWebSocketConnect(webConnID, userId string)
 	}); ok {
-		returns.A = hook.GetConfig()
+		hook.OnWebSocketConnect(args.A, args.B)
 	} else {
-		return fmt.Errorf("API GetConfig called but not implemented.")
+		return fmt.Errorf("Hook OnWebSocketConnect called but not implemented.")
 	}
 	return nil
 }
 
-type Z_SaveConfigArgs struct {
-	A *model.Config
+func init() {
+	hookNameToId["OnWebSocketDisconnect"] = OnWebSocketDisconnectId
 }
 
-type Z_SaveConfigReturns struct {
-	A *model.AppError
+type Z_OnWebSocketDisconnectArgs struct {
+	A string
+	B string
 }
 
-func (g *apiRPCClient) SaveConfig(config *model.Config) *model.AppError {
-	_args := &Z_SaveConfigArgs{config}
-	_returns := &Z_SaveConfigReturns{}
-	if err := g.client.Call("Plugin.SaveConfig", _args, _returns); err != nil {
-		log.Printf("RPC call to SaveConfig API failed: %s", err.Error())
+type Z_OnWebSocketDisconnectReturns struct {
+}
+
+func (g *hooksRPCClient) OnWebSocketDisconnect(webConnID, userId string) {
+	_args := &Z_OnWebSocketDisconnectArgs{webConnID, userId}
+	_returns := &Z_OnWebSocketDisconnectReturns{}
+	if g.implemented[OnWebSocketDisconnectId] {
+		if err := g.client.Call("Plugin.OnWebSocketDisconnect", _args, _returns); err != nil {
+			g.log.Error("RPC call OnWebSocketDisconnect to plugin failed.", mlog.Err(err))
+		}
 	}
-	return _returns.A
+	return
 }
 
-func (s *apiRPCServer) SaveConfig(args *Z_SaveConfigArgs, returns *Z_SaveConfigReturns) error {
+func (s *hooksRPCServer) OnWebSocketDisconnect(args *Z_OnWebSocketDisconnectArgs, returns *Z_OnWebSocketDisconnectReturns) error {
 	if hook, ok := s.impl.(interface {
-		SaveConfig(config *model.Config) *model.AppError
+		OnWebSocketDisconnect(webConnID, userId string)
 	}); ok {
-		returns.A = hook.SaveConfig(args.A)
+		hook.OnWebSocketDisconnect(args.A, args.B)
 	} else {
-		return fmt.Errorf("API SaveConfig called but not implemented.")
+		return fmt.Errorf("Hook OnWebSocketDisconnect called but not implemented.")
 	}
 	return nil
 }
 
-type Z_CreateUserArgs struct {
-	A *model.User
+func init() {
+	hookNameToId["OnPluginActivated"] = OnPluginActivatedId
 }
 
-type Z_CreateUserReturns struct {
-	A *model.User
-	B *model.AppError
+type Z_OnPluginActivatedArgs struct {
+	A *model.Manifest
 }
 
-func (g *apiRPCClient) CreateUser(user *model.User) (*model.User, *model.AppError) {
-	_args := &Z_CreateUserArgs{user}
-	_returns := &Z_CreateUserReturns{}
-	if err := g.client.Call("Plugin.CreateUser", _args, _returns); err != nil {
-		log.Printf("RPC call to CreateUser API failed: %s", err.Error())
+type Z_OnPluginActivatedReturns struct {
+}
+
+func (g *hooksRPCClient) OnPluginActivated(manifest *model.Manifest) {
+	_args := &Z_OnPluginActivatedArgs{manifest}
+	_returns := &Z_OnPluginActivatedReturns{}
+	if g.implemented[OnPluginActivatedId] {
+		if err := g.client.Call("Plugin.OnPluginActivated", _args, _returns); err != nil {
+			g.log.Error("RPC call OnPluginActivated to plugin failed.", mlog.Err(err))
+		}
 	}
-	return _returns.A, _returns.B
+	return
 }
 
-func (s *apiRPCServer) CreateUser(args *Z_CreateUserArgs, returns *Z_CreateUserReturns) error {
+func (s *hooksRPCServer) OnPluginActivated(args *Z_OnPluginActivatedArgs, returns *Z_OnPluginActivatedReturns) error {
 	if hook, ok := s.impl.(interface {
-		CreateUser(user *model.User) (*model.User, *model.AppError)
+		OnPluginActivated(manifest *model.Manifest)
 	}); ok {
-		returns.A, returns.B = hook.CreateUser(args.A)
+		hook.OnPluginActivated(args.A)
 	} else {
-		return fmt.Errorf("API CreateUser called but not implemented.")
+		return fmt.Errorf("Hook OnPluginActivated called but not implemented.")
 	}
 	return nil
 }
 
-type Z_DeleteUserArgs struct {
-	A string
+func init() {
+	hookNameToId["OnPluginDeactivated"] = OnPluginDeactivatedId
 }
 
-type Z_DeleteUserReturns struct {
-	A *model.AppError
+type Z_OnPluginDeactivatedArgs struct {
+	A *model.Manifest
 }
 
-func (g *apiRPCClient) DeleteUser(userId string) *model.AppError {
-	_args := &Z_DeleteUserArgs{userId}
-	_returns := &Z_DeleteUserReturns{}
-	if err := g.client.Call("Plugin.DeleteUser", _args, _returns); err != nil {
-		log.Printf("RPC call to DeleteUser API failed: %s", err.Error())
+type Z_OnPluginDeactivatedReturns struct {
+}
+
+func (g *hooksRPCClient) OnPluginDeactivated(manifest *model.Manifest) {
+	_args := &Z_OnPluginDeactivatedArgs{manifest}
+	_returns := &Z_OnPluginDeactivatedReturns{}
+	if g.implemented[OnPluginDeactivatedId] {
+		if err := g.client.Call("Plugin.OnPluginDeactivated", _args, _returns); err != nil {
+			g.log.Error("RPC call OnPluginDeactivated to plugin failed.", mlog.Err(err))
+		}
 	}
-	return _returns.A
+	return
 }
 
-func (s *apiRPCServer) DeleteUser(args *Z_DeleteUserArgs, returns *Z_DeleteUserReturns) error {
+func (s *hooksRPCServer) OnPluginDeactivated(args *Z_OnPluginDeactivatedArgs, returns *Z_OnPluginDeactivatedReturns) error {
 	if hook, ok := s.impl.(interface {
-		DeleteUser(userId string) *model.AppError
+		OnPluginDeactivated(manifest *model.Manifest)
 	}); ok {
-		returns.A = hook.DeleteUser(args.A)
+		hook.OnPluginDeactivated(args.A)
 	} else {
-		return fmt.Errorf("API DeleteUser called but not implemented.")
+		return fmt.Errorf("Hook OnPluginDeactivated called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetUserArgs struct {
-	A string
+func init() {
+	hookNameToId["OnDataImported"] = OnDataImportedId
 }
 
-type Z_GetUserReturns struct {
-	A *model.User
-	B *model.AppError
+type Z_OnDataImportedArgs struct {
 }
 
-func (g *apiRPCClient) GetUser(userId string) (*model.User, *model.AppError) {
-	_args := &Z_GetUserArgs{userId}
-	_returns := &Z_GetUserReturns{}
-	if err := g.client.Call("Plugin.GetUser", _args, _returns); err != nil {
-		log.Printf("RPC call to GetUser API failed: %s", err.Error())
+type Z_OnDataImportedReturns struct {
+}
+
+func (g *hooksRPCClient) OnDataImported() {
+	_args := &Z_OnDataImportedArgs{}
+	_returns := &Z_OnDataImportedReturns{}
+	if g.implemented[OnDataImportedId] {
+		if err := g.client.Call("Plugin.OnDataImported", _args, _returns); err != nil {
+			g.log.Error("RPC call OnDataImported to plugin failed.", mlog.Err(err))
+		}
 	}
-	return _returns.A, _returns.B
+	return
 }
 
-func (s *apiRPCServer) GetUser(args *Z_GetUserArgs, returns *Z_GetUserReturns) error {
+func (s *hooksRPCServer) OnDataImported(args *Z_OnDataImportedArgs, returns *Z_OnDataImportedReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetUser(userId string) (*model.User, *model.AppError)
+		OnDataImported()
 	}); ok {
-		returns.A, returns.B = hook.GetUser(args.A)
+		hook.OnDataImported()
 	} else {
-		return fmt.Errorf("API GetUser called but not implemented.")
+		return fmt.Errorf("Hook OnDataImported called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetUserByEmailArgs struct {
+func init() {
+	hookNameToId["ChannelExportHasCompleted"] = ChannelExportHasCompletedId
+}
+
+type Z_ChannelExportHasCompletedArgs struct {
 	A string
+	B string
 }
 
-type Z_GetUserByEmailReturns struct {
-	A *model.User
-	B *model.AppError
+type Z_ChannelExportHasCompletedReturns struct {
 }
 
-func (g *apiRPCClient) GetUserByEmail(email string) (*model.User, *model.AppError) {
-	_args := &Z_GetUserByEmailArgs{email}
-	_returns := &Z_GetUserByEmailReturns{}
-	if err := g.client.Call("Plugin.GetUserByEmail", _args, _returns); err != nil {
-		log.Printf("RPC call to GetUserByEmail API failed: %s", err.Error())
+func (g *hooksRPCClient) ChannelExportHasCompleted(jobId, fileId string) {
+	_args := &Z_ChannelExportHasCompletedArgs{jobId, fileId}
+	_returns := &Z_ChannelExportHasCompletedReturns{}
+	if g.implemented[ChannelExportHasCompletedId] {
+		if err := g.client.Call("Plugin.ChannelExportHasCompleted", _args, _returns); err != nil {
+			g.log.Error("RPC call ChannelExportHasCompleted to plugin failed.", mlog.Err(err))
+		}
 	}
-	return _returns.A, _returns.B
+	return
 }
 
-func (s *apiRPCServer) GetUserByEmail(args *Z_GetUserByEmailArgs, returns *Z_GetUserByEmailReturns) error {
+func (s *hooksRPCServer) ChannelExportHasCompleted(args *Z_ChannelExportHasCompletedArgs, returns *Z_ChannelExportHasCompletedReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetUserByEmail(email string) (*model.User, *model.AppError)
+		ChannelExportHasCompleted(jobId, fileId string)
 	}); ok {
-		returns.A, returns.B = hook.GetUserByEmail(args.A)
+		hook.ChannelExportHasCompleted(args.A, args.B)
 	} else {
-		return fmt.Errorf("API GetUserByEmail called but not implemented.")
+		return fmt.Errorf("Hook ChannelExportHasCompleted called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetUserByUsernameArgs struct {
+func init() {
+	hookNameToId["KVHasChanged"] = KVHasChangedId
+}
+
+type Z_KVHasChangedArgs struct {
 	A string
 }
 
-type Z_GetUserByUsernameReturns struct {
-	A *model.User
-	B *model.AppError
+type Z_KVHasChangedReturns struct {
 }
 
-func (g *apiRPCClient) GetUserByUsername(name string) (*model.User, *model.AppError) {
-	_args := &Z_GetUserByUsernameArgs{name}
-	_returns := &Z_GetUserByUsernameReturns{}
-	if err := g.client.Call("Plugin.GetUserByUsername", _args, _returns); err != nil {
-		log.Printf("RPC call to GetUserByUsername API failed: %s", err.Error())
+func (g *hooksRPCClient) KVHasChanged(key string) {
+	_args := &Z_KVHasChangedArgs{key}
+	_returns := &Z_KVHasChangedReturns{}
+	if g.implemented[KVHasChangedId] {
+		if err := g.client.Call("Plugin.KVHasChanged", _args, _returns); err != nil {
+			g.log.Error("RPC call KVHasChanged to plugin failed.", mlog.Err(err))
+		}
 	}
-	return _returns.A, _returns.B
+	return
 }
 
-func (s *apiRPCServer) GetUserByUsername(args *Z_GetUserByUsernameArgs, returns *Z_GetUserByUsernameReturns) error {
+func (s *hooksRPCServer) KVHasChanged(args *Z_KVHasChangedArgs, returns *Z_KVHasChangedReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetUserByUsername(name string) (*model.User, *model.AppError)
+		KVHasChanged(key string)
 	}); ok {
-		returns.A, returns.B = hook.GetUserByUsername(args.A)
+		hook.KVHasChanged(args.A)
 	} else {
-		return fmt.Errorf("API GetUserByUsername called but not implemented.")
+		return fmt.Errorf("Hook KVHasChanged called but not implemented.")
 	}
 	return nil
 }
 
-type Z_UpdateUserArgs struct {
-	A *model.User
+func init() {
+	hookNameToId["AdminActionHasBeenResolved"] = AdminActionHasBeenResolvedId
 }
 
-type Z_UpdateUserReturns struct {
-	A *model.User
-	B *model.AppError
+type Z_AdminActionHasBeenResolvedArgs struct {
+	A string
+	B bool
 }
 
-func (g *apiRPCClient) UpdateUser(user *model.User) (*model.User, *model.AppError) {
-	_args := &Z_UpdateUserArgs{user}
-	_returns := &Z_UpdateUserReturns{}
-	if err := g.client.Call("Plugin.UpdateUser", _args, _returns); err != nil {
-		log.Printf("RPC call to UpdateUser API failed: %s", err.Error())
+type Z_AdminActionHasBeenResolvedReturns struct {
+}
+
+func (g *hooksRPCClient) AdminActionHasBeenResolved(requestId string, approved bool) {
+	_args := &Z_AdminActionHasBeenResolvedArgs{requestId, approved}
+	_returns := &Z_AdminActionHasBeenResolvedReturns{}
+	if g.implemented[AdminActionHasBeenResolvedId] {
+		if err := g.client.Call("Plugin.AdminActionHasBeenResolved", _args, _returns); err != nil {
+			g.log.Error("RPC call AdminActionHasBeenResolved to plugin failed.", mlog.Err(err))
+		}
 	}
-	return _returns.A, _returns.B
+	return
 }
 
-func (s *apiRPCServer) UpdateUser(args *Z_UpdateUserArgs, returns *Z_UpdateUserReturns) error {
+func (s *hooksRPCServer) AdminActionHasBeenResolved(args *Z_AdminActionHasBeenResolvedArgs, returns *Z_AdminActionHasBeenResolvedReturns) error {
 	if hook, ok := s.impl.(interface {
-		UpdateUser(user *model.User) (*model.User, *model.AppError)
+		AdminActionHasBeenResolved(requestId string, approved bool)
 	}); ok {
-		returns.A, returns.B = hook.UpdateUser(args.A)
+		hook.AdminActionHasBeenResolved(args.A, args.B)
 	} else {
-		return fmt.Errorf("API UpdateUser called but not implemented.")
+		return fmt.Errorf("Hook AdminActionHasBeenResolved called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetUserStatusArgs struct {
+func init() {
+	hookNameToId["WebappReplyReceived"] = WebappReplyReceivedId
+}
+
+type Z_WebappReplyReceivedArgs struct {
 	A string
+	B []byte
 }
 
-type Z_GetUserStatusReturns struct {
+type Z_WebappReplyReceivedReturns struct {
+}
+
+func (g *hooksRPCClient) WebappReplyReceived(replyId string, payload []byte) {
+	_args := &Z_WebappReplyReceivedArgs{replyId, payload}
+	_returns := &Z_WebappReplyReceivedReturns{}
+	if g.implemented[WebappReplyReceivedId] {
+		if err := g.client.Call("Plugin.WebappReplyReceived", _args, _returns); err != nil {
+			g.log.Error("RPC call WebappReplyReceived to plugin failed.", mlog.Err(err))
+		}
+	}
+	return
+}
+
+func (s *hooksRPCServer) WebappReplyReceived(args *Z_WebappReplyReceivedArgs, returns *Z_WebappReplyReceivedReturns) error {
+	if hook, ok := s.impl.(interface {
+		WebappReplyReceived(replyId string, payload []byte)
+	}); ok {
+		hook.WebappReplyReceived(args.A, args.B)
+	} else {
+		return fmt.Errorf("Hook WebappReplyReceived called but not implemented.")
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["WebappReplyTimedOut"] = WebappReplyTimedOutId
+}
+
+type Z_WebappReplyTimedOutArgs struct {
+	A string
+}
+
+type Z_WebappReplyTimedOutReturns struct {
+}
+
+func (g *hooksRPCClient) WebappReplyTimedOut(replyId string) {
+	_args := &Z_WebappReplyTimedOutArgs{replyId}
+	_returns := &Z_WebappReplyTimedOutReturns{}
+	if g.implemented[WebappReplyTimedOutId] {
+		if err := g.client.Call("Plugin.WebappReplyTimedOut", _args, _returns); err != nil {
+			g.log.Error("RPC call WebappReplyTimedOut to plugin failed.", mlog.Err(err))
+		}
+	}
+	return
+}
+
+func (s *hooksRPCServer) WebappReplyTimedOut(args *Z_WebappReplyTimedOutArgs, returns *Z_WebappReplyTimedOutReturns) error {
+	if hook, ok := s.impl.(interface {
+		WebappReplyTimedOut(replyId string)
+	}); ok {
+		hook.WebappReplyTimedOut(args.A)
+	} else {
+		return fmt.Errorf("Hook WebappReplyTimedOut called but not implemented.")
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["UserWillLogIn"] = UserWillLogInId
+}
+
+type Z_UserWillLogInArgs struct {
+	A *Context
+	B *model.User
+}
+
+type Z_UserWillLogInReturns struct {
+	A string
+}
+
+func (g *hooksRPCClient) UserWillLogIn(c *Context, user *model.User) string {
+	_args := &Z_UserWillLogInArgs{c, user}
+	_returns := &Z_UserWillLogInReturns{}
+	if g.implemented[UserWillLogInId] {
+		if err := g.client.Call("Plugin.UserWillLogIn", _args, _returns); err != nil {
+			g.log.Error("RPC call UserWillLogIn to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
+		}
+	}
+	return _returns.A
+}
+
+func (s *hooksRPCServer) UserWillLogIn(args *Z_UserWillLogInArgs, returns *Z_UserWillLogInReturns) error {
+	if hook, ok := s.impl.(interface {
+		UserWillLogIn(c *Context, user *model.User) string
+	}); ok {
+		defer s.requests.begin(args.A)()
+		returns.A = hook.UserWillLogIn(args.A, args.B)
+	} else {
+		return fmt.Errorf("Hook UserWillLogIn called but not implemented.")
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["UserHasLoggedIn"] = UserHasLoggedInId
+}
+
+type Z_UserHasLoggedInArgs struct {
+	A *Context
+	B *model.User
+}
+
+type Z_UserHasLoggedInReturns struct {
+}
+
+func (g *hooksRPCClient) UserHasLoggedIn(c *Context, user *model.User) {
+	_args := &Z_UserHasLoggedInArgs{c, user}
+	_returns := &Z_UserHasLoggedInReturns{}
+	if g.implemented[UserHasLoggedInId] {
+		if err := g.client.Call("Plugin.UserHasLoggedIn", _args, _returns); err != nil {
+			g.log.Error("RPC call UserHasLoggedIn to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
+		}
+	}
+	return
+}
+
+func (s *hooksRPCServer) UserHasLoggedIn(args *Z_UserHasLoggedInArgs, returns *Z_UserHasLoggedInReturns) error {
+	if hook, ok := s.impl.(interface {
+		UserHasLoggedIn(c *Context, user *model.User)
+	}); ok {
+		defer s.requests.begin(args.A)()
+		hook.UserHasLoggedIn(args.A, args.B)
+	} else {
+		return fmt.Errorf("Hook UserHasLoggedIn called but not implemented.")
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["PostsWillBeExported"] = PostsWillBeExportedId
+}
+
+type Z_PostsWillBeExportedArgs struct {
+	A *Context
+	B []*model.Post
+	C string
+}
+
+type Z_PostsWillBeExportedReturns struct {
+	A []*model.Post
+}
+
+func (g *hooksRPCClient) PostsWillBeExported(c *Context, posts []*model.Post, exportType string) []*model.Post {
+	_args := &Z_PostsWillBeExportedArgs{c, posts, exportType}
+	_returns := &Z_PostsWillBeExportedReturns{}
+	if g.implemented[PostsWillBeExportedId] {
+		if err := g.client.Call("Plugin.PostsWillBeExported", _args, _returns); err != nil {
+			g.log.Error("RPC call PostsWillBeExported to plugin failed.", mlog.String("request_id", _args.A.requestId()), mlog.Err(err))
+		}
+	}
+	return _returns.A
+}
+
+func (s *hooksRPCServer) PostsWillBeExported(args *Z_PostsWillBeExportedArgs, returns *Z_PostsWillBeExportedReturns) error {
+	if hook, ok := s.impl.(interface {
+		PostsWillBeExported(c *Context, posts []*model.Post, exportType string) []*model.Post
+	}); ok {
+		defer s.requests.begin(args.A)()
+		returns.A = hook.PostsWillBeExported(args.A, args.B, args.C)
+	} else {
+		return fmt.Errorf("Hook PostsWillBeExported called but not implemented.")
+	}
+	return nil
+}
+
+type Z_RegisterCommandArgs struct {
+	A *model.Command
+}
+
+type Z_RegisterCommandReturns struct {
+	A error
+}
+
+func (g *apiRPCClient) RegisterCommand(command *model.Command) error {
+	_args := &Z_RegisterCommandArgs{command}
+	_returns := &Z_RegisterCommandReturns{}
+	if err := g.client.Call("Plugin.RegisterCommand", _args, _returns); err != nil {
+		log.Printf("RPC call to RegisterCommand API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) RegisterCommand(args *Z_RegisterCommandArgs, returns *Z_RegisterCommandReturns) error {
+	if hook, ok := s.impl.(interface {
+		RegisterCommand(command *model.Command) error
+	}); ok {
+		returns.A = hook.RegisterCommand(args.A)
+	} else {
+		return fmt.Errorf("API RegisterCommand called but not implemented.")
+	}
+	return nil
+}
+
+type Z_UnregisterCommandArgs struct {
+	A string
+	B string
+}
+
+type Z_UnregisterCommandReturns struct {
+	A error
+}
+
+func (g *apiRPCClient) UnregisterCommand(teamId, trigger string) error {
+	_args := &Z_UnregisterCommandArgs{teamId, trigger}
+	_returns := &Z_UnregisterCommandReturns{}
+	if err := g.client.Call("Plugin.UnregisterCommand", _args, _returns); err != nil {
+		log.Printf("RPC call to UnregisterCommand API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) UnregisterCommand(args *Z_UnregisterCommandArgs, returns *Z_UnregisterCommandReturns) error {
+	if hook, ok := s.impl.(interface {
+		UnregisterCommand(teamId, trigger string) error
+	}); ok {
+		returns.A = hook.UnregisterCommand(args.A, args.B)
+	} else {
+		return fmt.Errorf("API UnregisterCommand called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetConfigArgs struct {
+}
+
+type Z_GetConfigReturns struct {
+	A *model.Config
+}
+
+func (g *apiRPCClient) GetConfig() *model.Config {
+	_args := &Z_GetConfigArgs{}
+	_returns := &Z_GetConfigReturns{}
+	if err := g.client.Call("Plugin.GetConfig", _args, _returns); err != nil {
+		log.Printf("RPC call to GetConfig API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) GetConfig(args *Z_GetConfigArgs, returns *Z_GetConfigReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetConfig() *model.Config
+	}); ok {
+		returns.A = hook.GetConfig()
+	} else {
+		return fmt.Errorf("API GetConfig called but not implemented.")
+	}
+	return nil
+}
+
+type Z_SaveConfigArgs struct {
+	A *model.Config
+}
+
+type Z_SaveConfigReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) SaveConfig(config *model.Config) *model.AppError {
+	_args := &Z_SaveConfigArgs{config}
+	_returns := &Z_SaveConfigReturns{}
+	if err := g.client.Call("Plugin.SaveConfig", _args, _returns); err != nil {
+		log.Printf("RPC call to SaveConfig API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) SaveConfig(args *Z_SaveConfigArgs, returns *Z_SaveConfigReturns) error {
+	if hook, ok := s.impl.(interface {
+		SaveConfig(config *model.Config) *model.AppError
+	}); ok {
+		returns.A = hook.SaveConfig(args.A)
+	} else {
+		return fmt.Errorf("API SaveConfig called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetSiteURLArgs struct {
+}
+
+type Z_GetSiteURLReturns struct {
+	A string
+}
+
+func (g *apiRPCClient) GetSiteURL() string {
+	_args := &Z_GetSiteURLArgs{}
+	_returns := &Z_GetSiteURLReturns{}
+	if err := g.client.Call("Plugin.GetSiteURL", _args, _returns); err != nil {
+		log.Printf("RPC call to GetSiteURL API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) GetSiteURL(args *Z_GetSiteURLArgs, returns *Z_GetSiteURLReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetSiteURL() string
+	}); ok {
+		returns.A = hook.GetSiteURL()
+	} else {
+		return fmt.Errorf("API GetSiteURL called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetSiteNameArgs struct {
+}
+
+type Z_GetSiteNameReturns struct {
+	A string
+}
+
+func (g *apiRPCClient) GetSiteName() string {
+	_args := &Z_GetSiteNameArgs{}
+	_returns := &Z_GetSiteNameReturns{}
+	if err := g.client.Call("Plugin.GetSiteName", _args, _returns); err != nil {
+		log.Printf("RPC call to GetSiteName API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) GetSiteName(args *Z_GetSiteNameArgs, returns *Z_GetSiteNameReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetSiteName() string
+	}); ok {
+		returns.A = hook.GetSiteName()
+	} else {
+		return fmt.Errorf("API GetSiteName called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetSupportEmailArgs struct {
+}
+
+type Z_GetSupportEmailReturns struct {
+	A string
+}
+
+func (g *apiRPCClient) GetSupportEmail() string {
+	_args := &Z_GetSupportEmailArgs{}
+	_returns := &Z_GetSupportEmailReturns{}
+	if err := g.client.Call("Plugin.GetSupportEmail", _args, _returns); err != nil {
+		log.Printf("RPC call to GetSupportEmail API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) GetSupportEmail(args *Z_GetSupportEmailArgs, returns *Z_GetSupportEmailReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetSupportEmail() string
+	}); ok {
+		returns.A = hook.GetSupportEmail()
+	} else {
+		return fmt.Errorf("API GetSupportEmail called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetBrandImageArgs struct {
+}
+
+type Z_GetBrandImageReturns struct {
+	A []byte
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetBrandImage() ([]byte, *model.AppError) {
+	_args := &Z_GetBrandImageArgs{}
+	_returns := &Z_GetBrandImageReturns{}
+	if err := g.client.Call("Plugin.GetBrandImage", _args, _returns); err != nil {
+		log.Printf("RPC call to GetBrandImage API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetBrandImage(args *Z_GetBrandImageArgs, returns *Z_GetBrandImageReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetBrandImage() ([]byte, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetBrandImage()
+	} else {
+		return fmt.Errorf("API GetBrandImage called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetAllowedTeamsArgs struct {
+}
+
+type Z_GetAllowedTeamsReturns struct {
+	A []string
+}
+
+func (g *apiRPCClient) GetAllowedTeams() []string {
+	_args := &Z_GetAllowedTeamsArgs{}
+	_returns := &Z_GetAllowedTeamsReturns{}
+	if err := g.client.Call("Plugin.GetAllowedTeams", _args, _returns); err != nil {
+		log.Printf("RPC call to GetAllowedTeams API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) GetAllowedTeams(args *Z_GetAllowedTeamsArgs, returns *Z_GetAllowedTeamsReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetAllowedTeams() []string
+	}); ok {
+		returns.A = hook.GetAllowedTeams()
+	} else {
+		return fmt.Errorf("API GetAllowedTeams called but not implemented.")
+	}
+	return nil
+}
+
+type Z_CreateUserArgs struct {
+	A *model.User
+}
+
+type Z_CreateUserReturns struct {
+	A *model.User
+	B *model.AppError
+}
+
+func (g *apiRPCClient) CreateUser(user *model.User) (*model.User, *model.AppError) {
+	_args := &Z_CreateUserArgs{user}
+	_returns := &Z_CreateUserReturns{}
+	if err := g.client.Call("Plugin.CreateUser", _args, _returns); err != nil {
+		log.Printf("RPC call to CreateUser API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) CreateUser(args *Z_CreateUserArgs, returns *Z_CreateUserReturns) error {
+	if hook, ok := s.impl.(interface {
+		CreateUser(user *model.User) (*model.User, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.CreateUser(args.A)
+	} else {
+		return fmt.Errorf("API CreateUser called but not implemented.")
+	}
+	return nil
+}
+
+type Z_DeleteUserArgs struct {
+	A string
+}
+
+type Z_DeleteUserReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) DeleteUser(userId string) *model.AppError {
+	_args := &Z_DeleteUserArgs{userId}
+	_returns := &Z_DeleteUserReturns{}
+	if err := g.client.Call("Plugin.DeleteUser", _args, _returns); err != nil {
+		log.Printf("RPC call to DeleteUser API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) DeleteUser(args *Z_DeleteUserArgs, returns *Z_DeleteUserReturns) error {
+	if hook, ok := s.impl.(interface {
+		DeleteUser(userId string) *model.AppError
+	}); ok {
+		returns.A = hook.DeleteUser(args.A)
+	} else {
+		return fmt.Errorf("API DeleteUser called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetUserArgs struct {
+	A string
+}
+
+type Z_GetUserReturns struct {
+	A *model.User
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetUser(userId string) (*model.User, *model.AppError) {
+	_args := &Z_GetUserArgs{userId}
+	_returns := &Z_GetUserReturns{}
+	if err := g.client.Call("Plugin.GetUser", _args, _returns); err != nil {
+		log.Printf("RPC call to GetUser API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetUser(args *Z_GetUserArgs, returns *Z_GetUserReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetUser(userId string) (*model.User, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetUser(args.A)
+	} else {
+		return fmt.Errorf("API GetUser called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetUserByEmailArgs struct {
+	A string
+}
+
+type Z_GetUserByEmailReturns struct {
+	A *model.User
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetUserByEmail(email string) (*model.User, *model.AppError) {
+	_args := &Z_GetUserByEmailArgs{email}
+	_returns := &Z_GetUserByEmailReturns{}
+	if err := g.client.Call("Plugin.GetUserByEmail", _args, _returns); err != nil {
+		log.Printf("RPC call to GetUserByEmail API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetUserByEmail(args *Z_GetUserByEmailArgs, returns *Z_GetUserByEmailReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetUserByEmail(email string) (*model.User, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetUserByEmail(args.A)
+	} else {
+		return fmt.Errorf("API GetUserByEmail called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetUserByUsernameArgs struct {
+	A string
+}
+
+type Z_GetUserByUsernameReturns struct {
+	A *model.User
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetUserByUsername(name string) (*model.User, *model.AppError) {
+	_args := &Z_GetUserByUsernameArgs{name}
+	_returns := &Z_GetUserByUsernameReturns{}
+	if err := g.client.Call("Plugin.GetUserByUsername", _args, _returns); err != nil {
+		log.Printf("RPC call to GetUserByUsername API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetUserByUsername(args *Z_GetUserByUsernameArgs, returns *Z_GetUserByUsernameReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetUserByUsername(name string) (*model.User, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetUserByUsername(args.A)
+	} else {
+		return fmt.Errorf("API GetUserByUsername called but not implemented.")
+	}
+	return nil
+}
+
+type Z_UpdateUserArgs struct {
+	A *model.User
+}
+
+type Z_UpdateUserReturns struct {
+	A *model.User
+	B *model.AppError
+}
+
+func (g *apiRPCClient) UpdateUser(user *model.User) (*model.User, *model.AppError) {
+	_args := &Z_UpdateUserArgs{user}
+	_returns := &Z_UpdateUserReturns{}
+	if err := g.client.Call("Plugin.UpdateUser", _args, _returns); err != nil {
+		log.Printf("RPC call to UpdateUser API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) UpdateUser(args *Z_UpdateUserArgs, returns *Z_UpdateUserReturns) error {
+	if hook, ok := s.impl.(interface {
+		UpdateUser(user *model.User) (*model.User, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.UpdateUser(args.A)
+	} else {
+		return fmt.Errorf("API UpdateUser called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetUserNotifyPropsArgs struct {
+	A string
+}
+
+type Z_GetUserNotifyPropsReturns struct {
+	A model.StringMap
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetUserNotifyProps(userId string) (model.StringMap, *model.AppError) {
+	_args := &Z_GetUserNotifyPropsArgs{userId}
+	_returns := &Z_GetUserNotifyPropsReturns{}
+	if err := g.client.Call("Plugin.GetUserNotifyProps", _args, _returns); err != nil {
+		log.Printf("RPC call to GetUserNotifyProps API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetUserNotifyProps(args *Z_GetUserNotifyPropsArgs, returns *Z_GetUserNotifyPropsReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetUserNotifyProps(userId string) (model.StringMap, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetUserNotifyProps(args.A)
+	} else {
+		return fmt.Errorf("API GetUserNotifyProps called but not implemented.")
+	}
+	return nil
+}
+
+type Z_SetUserPluginSettingArgs struct {
+	A string
+	B string
+	C string
+}
+
+type Z_SetUserPluginSettingReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) SetUserPluginSetting(userId, key, value string) *model.AppError {
+	_args := &Z_SetUserPluginSettingArgs{userId, key, value}
+	_returns := &Z_SetUserPluginSettingReturns{}
+	if err := g.client.Call("Plugin.SetUserPluginSetting", _args, _returns); err != nil {
+		log.Printf("RPC call to SetUserPluginSetting API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) SetUserPluginSetting(args *Z_SetUserPluginSettingArgs, returns *Z_SetUserPluginSettingReturns) error {
+	if hook, ok := s.impl.(interface {
+		SetUserPluginSetting(userId, key, value string) *model.AppError
+	}); ok {
+		returns.A = hook.SetUserPluginSetting(args.A, args.B, args.C)
+	} else {
+		return fmt.Errorf("API SetUserPluginSetting called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetUserPluginSettingsArgs struct {
+	A string
+}
+
+type Z_GetUserPluginSettingsReturns struct {
+	A map[string]string
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetUserPluginSettings(userId string) (map[string]string, *model.AppError) {
+	_args := &Z_GetUserPluginSettingsArgs{userId}
+	_returns := &Z_GetUserPluginSettingsReturns{}
+	if err := g.client.Call("Plugin.GetUserPluginSettings", _args, _returns); err != nil {
+		log.Printf("RPC call to GetUserPluginSettings API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetUserPluginSettings(args *Z_GetUserPluginSettingsArgs, returns *Z_GetUserPluginSettingsReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetUserPluginSettings(userId string) (map[string]string, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetUserPluginSettings(args.A)
+	} else {
+		return fmt.Errorf("API GetUserPluginSettings called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetUserStatusArgs struct {
+	A string
+}
+
+type Z_GetUserStatusReturns struct {
+	A *model.Status
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetUserStatus(userId string) (*model.Status, *model.AppError) {
+	_args := &Z_GetUserStatusArgs{userId}
+	_returns := &Z_GetUserStatusReturns{}
+	if err := g.client.Call("Plugin.GetUserStatus", _args, _returns); err != nil {
+		log.Printf("RPC call to GetUserStatus API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetUserStatus(args *Z_GetUserStatusArgs, returns *Z_GetUserStatusReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetUserStatus(userId string) (*model.Status, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetUserStatus(args.A)
+	} else {
+		return fmt.Errorf("API GetUserStatus called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetUserStatusesByIdsArgs struct {
+	A []string
+}
+
+type Z_GetUserStatusesByIdsReturns struct {
+	A []*model.Status
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetUserStatusesByIds(userIds []string) ([]*model.Status, *model.AppError) {
+	_args := &Z_GetUserStatusesByIdsArgs{userIds}
+	_returns := &Z_GetUserStatusesByIdsReturns{}
+	if err := g.client.Call("Plugin.GetUserStatusesByIds", _args, _returns); err != nil {
+		log.Printf("RPC call to GetUserStatusesByIds API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetUserStatusesByIds(args *Z_GetUserStatusesByIdsArgs, returns *Z_GetUserStatusesByIdsReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetUserStatusesByIds(userIds []string) ([]*model.Status, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetUserStatusesByIds(args.A)
+	} else {
+		return fmt.Errorf("API GetUserStatusesByIds called but not implemented.")
+	}
+	return nil
+}
+
+type Z_UpdateUserStatusArgs struct {
+	A string
+	B string
+}
+
+type Z_UpdateUserStatusReturns struct {
 	A *model.Status
 	B *model.AppError
 }
 
-func (g *apiRPCClient) GetUserStatus(userId string) (*model.Status, *model.AppError) {
-	_args := &Z_GetUserStatusArgs{userId}
-	_returns := &Z_GetUserStatusReturns{}
-	if err := g.client.Call("Plugin.GetUserStatus", _args, _returns); err != nil {
-		log.Printf("RPC call to GetUserStatus API failed: %s", err.Error())
+func (g *apiRPCClient) UpdateUserStatus(userId, status string) (*model.Status, *model.AppError) {
+	_args := &Z_UpdateUserStatusArgs{userId, status}
+	_returns := &Z_UpdateUserStatusReturns{}
+	if err := g.client.Call("Plugin.UpdateUserStatus", _args, _returns); err != nil {
+		log.Printf("RPC call to UpdateUserStatus API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) UpdateUserStatus(args *Z_UpdateUserStatusArgs, returns *Z_UpdateUserStatusReturns) error {
+	if hook, ok := s.impl.(interface {
+		UpdateUserStatus(userId, status string) (*model.Status, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.UpdateUserStatus(args.A, args.B)
+	} else {
+		return fmt.Errorf("API UpdateUserStatus called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetSessionsArgs struct {
+	A string
+}
+
+type Z_GetSessionsReturns struct {
+	A []*model.Session
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetSessions(userId string) ([]*model.Session, *model.AppError) {
+	_args := &Z_GetSessionsArgs{userId}
+	_returns := &Z_GetSessionsReturns{}
+	if err := g.client.Call("Plugin.GetSessions", _args, _returns); err != nil {
+		log.Printf("RPC call to GetSessions API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetSessions(args *Z_GetSessionsArgs, returns *Z_GetSessionsReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetSessions(userId string) ([]*model.Session, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetSessions(args.A)
+	} else {
+		return fmt.Errorf("API GetSessions called but not implemented.")
+	}
+	return nil
+}
+
+type Z_RevokeSessionArgs struct {
+	A string
+}
+
+type Z_RevokeSessionReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) RevokeSession(sessionId string) *model.AppError {
+	_args := &Z_RevokeSessionArgs{sessionId}
+	_returns := &Z_RevokeSessionReturns{}
+	if err := g.client.Call("Plugin.RevokeSession", _args, _returns); err != nil {
+		log.Printf("RPC call to RevokeSession API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) RevokeSession(args *Z_RevokeSessionArgs, returns *Z_RevokeSessionReturns) error {
+	if hook, ok := s.impl.(interface {
+		RevokeSession(sessionId string) *model.AppError
+	}); ok {
+		returns.A = hook.RevokeSession(args.A)
+	} else {
+		return fmt.Errorf("API RevokeSession called but not implemented.")
+	}
+	return nil
+}
+
+type Z_RevokeAllSessionsForUserArgs struct {
+	A string
+}
+
+type Z_RevokeAllSessionsForUserReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) RevokeAllSessionsForUser(userId string) *model.AppError {
+	_args := &Z_RevokeAllSessionsForUserArgs{userId}
+	_returns := &Z_RevokeAllSessionsForUserReturns{}
+	if err := g.client.Call("Plugin.RevokeAllSessionsForUser", _args, _returns); err != nil {
+		log.Printf("RPC call to RevokeAllSessionsForUser API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) RevokeAllSessionsForUser(args *Z_RevokeAllSessionsForUserArgs, returns *Z_RevokeAllSessionsForUserReturns) error {
+	if hook, ok := s.impl.(interface {
+		RevokeAllSessionsForUser(userId string) *model.AppError
+	}); ok {
+		returns.A = hook.RevokeAllSessionsForUser(args.A)
+	} else {
+		return fmt.Errorf("API RevokeAllSessionsForUser called but not implemented.")
+	}
+	return nil
+}
+
+type Z_CreateTeamArgs struct {
+	A *model.Team
+}
+
+type Z_CreateTeamReturns struct {
+	A *model.Team
+	B *model.AppError
+}
+
+func (g *apiRPCClient) CreateTeam(team *model.Team) (*model.Team, *model.AppError) {
+	_args := &Z_CreateTeamArgs{team}
+	_returns := &Z_CreateTeamReturns{}
+	if err := g.client.Call("Plugin.CreateTeam", _args, _returns); err != nil {
+		log.Printf("RPC call to CreateTeam API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) CreateTeam(args *Z_CreateTeamArgs, returns *Z_CreateTeamReturns) error {
+	if hook, ok := s.impl.(interface {
+		CreateTeam(team *model.Team) (*model.Team, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.CreateTeam(args.A)
+	} else {
+		return fmt.Errorf("API CreateTeam called but not implemented.")
+	}
+	return nil
+}
+
+type Z_DeleteTeamArgs struct {
+	A string
+}
+
+type Z_DeleteTeamReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) DeleteTeam(teamId string) *model.AppError {
+	_args := &Z_DeleteTeamArgs{teamId}
+	_returns := &Z_DeleteTeamReturns{}
+	if err := g.client.Call("Plugin.DeleteTeam", _args, _returns); err != nil {
+		log.Printf("RPC call to DeleteTeam API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) DeleteTeam(args *Z_DeleteTeamArgs, returns *Z_DeleteTeamReturns) error {
+	if hook, ok := s.impl.(interface {
+		DeleteTeam(teamId string) *model.AppError
+	}); ok {
+		returns.A = hook.DeleteTeam(args.A)
+	} else {
+		return fmt.Errorf("API DeleteTeam called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetTeamsArgs struct {
+}
+
+type Z_GetTeamsReturns struct {
+	A []*model.Team
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetTeams() ([]*model.Team, *model.AppError) {
+	_args := &Z_GetTeamsArgs{}
+	_returns := &Z_GetTeamsReturns{}
+	if err := g.client.Call("Plugin.GetTeams", _args, _returns); err != nil {
+		log.Printf("RPC call to GetTeams API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetTeams(args *Z_GetTeamsArgs, returns *Z_GetTeamsReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetTeams() ([]*model.Team, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetTeams()
+	} else {
+		return fmt.Errorf("API GetTeams called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetTeamArgs struct {
+	A string
+}
+
+type Z_GetTeamReturns struct {
+	A *model.Team
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetTeam(teamId string) (*model.Team, *model.AppError) {
+	_args := &Z_GetTeamArgs{teamId}
+	_returns := &Z_GetTeamReturns{}
+	if err := g.client.Call("Plugin.GetTeam", _args, _returns); err != nil {
+		log.Printf("RPC call to GetTeam API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetTeam(args *Z_GetTeamArgs, returns *Z_GetTeamReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetTeam(teamId string) (*model.Team, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetTeam(args.A)
+	} else {
+		return fmt.Errorf("API GetTeam called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetTeamByNameArgs struct {
+	A string
+}
+
+type Z_GetTeamByNameReturns struct {
+	A *model.Team
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetTeamByName(name string) (*model.Team, *model.AppError) {
+	_args := &Z_GetTeamByNameArgs{name}
+	_returns := &Z_GetTeamByNameReturns{}
+	if err := g.client.Call("Plugin.GetTeamByName", _args, _returns); err != nil {
+		log.Printf("RPC call to GetTeamByName API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetTeamByName(args *Z_GetTeamByNameArgs, returns *Z_GetTeamByNameReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetTeamByName(name string) (*model.Team, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetTeamByName(args.A)
+	} else {
+		return fmt.Errorf("API GetTeamByName called but not implemented.")
+	}
+	return nil
+}
+
+type Z_UpdateTeamArgs struct {
+	A *model.Team
+}
+
+type Z_UpdateTeamReturns struct {
+	A *model.Team
+	B *model.AppError
+}
+
+func (g *apiRPCClient) UpdateTeam(team *model.Team) (*model.Team, *model.AppError) {
+	_args := &Z_UpdateTeamArgs{team}
+	_returns := &Z_UpdateTeamReturns{}
+	if err := g.client.Call("Plugin.UpdateTeam", _args, _returns); err != nil {
+		log.Printf("RPC call to UpdateTeam API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) UpdateTeam(args *Z_UpdateTeamArgs, returns *Z_UpdateTeamReturns) error {
+	if hook, ok := s.impl.(interface {
+		UpdateTeam(team *model.Team) (*model.Team, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.UpdateTeam(args.A)
+	} else {
+		return fmt.Errorf("API UpdateTeam called but not implemented.")
+	}
+	return nil
+}
+
+type Z_CreateTeamMemberArgs struct {
+	A string
+	B string
+}
+
+type Z_CreateTeamMemberReturns struct {
+	A *model.TeamMember
+	B *model.AppError
+}
+
+func (g *apiRPCClient) CreateTeamMember(teamId, userId string) (*model.TeamMember, *model.AppError) {
+	_args := &Z_CreateTeamMemberArgs{teamId, userId}
+	_returns := &Z_CreateTeamMemberReturns{}
+	if err := g.client.Call("Plugin.CreateTeamMember", _args, _returns); err != nil {
+		log.Printf("RPC call to CreateTeamMember API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) CreateTeamMember(args *Z_CreateTeamMemberArgs, returns *Z_CreateTeamMemberReturns) error {
+	if hook, ok := s.impl.(interface {
+		CreateTeamMember(teamId, userId string) (*model.TeamMember, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.CreateTeamMember(args.A, args.B)
+	} else {
+		return fmt.Errorf("API CreateTeamMember called but not implemented.")
+	}
+	return nil
+}
+
+type Z_CreateTeamMembersArgs struct {
+	A string
+	B []string
+	C string
+}
+
+type Z_CreateTeamMembersReturns struct {
+	A []*model.TeamMember
+	B *model.AppError
+}
+
+func (g *apiRPCClient) CreateTeamMembers(teamId string, userIds []string, requestorId string) ([]*model.TeamMember, *model.AppError) {
+	_args := &Z_CreateTeamMembersArgs{teamId, userIds, requestorId}
+	_returns := &Z_CreateTeamMembersReturns{}
+	if err := g.client.Call("Plugin.CreateTeamMembers", _args, _returns); err != nil {
+		log.Printf("RPC call to CreateTeamMembers API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) CreateTeamMembers(args *Z_CreateTeamMembersArgs, returns *Z_CreateTeamMembersReturns) error {
+	if hook, ok := s.impl.(interface {
+		CreateTeamMembers(teamId string, userIds []string, requestorId string) ([]*model.TeamMember, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.CreateTeamMembers(args.A, args.B, args.C)
+	} else {
+		return fmt.Errorf("API CreateTeamMembers called but not implemented.")
+	}
+	return nil
+}
+
+type Z_DeleteTeamMemberArgs struct {
+	A string
+	B string
+	C string
+}
+
+type Z_DeleteTeamMemberReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) DeleteTeamMember(teamId, userId, requestorId string) *model.AppError {
+	_args := &Z_DeleteTeamMemberArgs{teamId, userId, requestorId}
+	_returns := &Z_DeleteTeamMemberReturns{}
+	if err := g.client.Call("Plugin.DeleteTeamMember", _args, _returns); err != nil {
+		log.Printf("RPC call to DeleteTeamMember API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) DeleteTeamMember(args *Z_DeleteTeamMemberArgs, returns *Z_DeleteTeamMemberReturns) error {
+	if hook, ok := s.impl.(interface {
+		DeleteTeamMember(teamId, userId, requestorId string) *model.AppError
+	}); ok {
+		returns.A = hook.DeleteTeamMember(args.A, args.B, args.C)
+	} else {
+		return fmt.Errorf("API DeleteTeamMember called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetTeamMembersArgs struct {
+	A string
+	B int
+	C int
+}
+
+type Z_GetTeamMembersReturns struct {
+	A []*model.TeamMember
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetTeamMembers(teamId string, offset, limit int) ([]*model.TeamMember, *model.AppError) {
+	_args := &Z_GetTeamMembersArgs{teamId, offset, limit}
+	_returns := &Z_GetTeamMembersReturns{}
+	if err := g.client.Call("Plugin.GetTeamMembers", _args, _returns); err != nil {
+		log.Printf("RPC call to GetTeamMembers API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetTeamMembers(args *Z_GetTeamMembersArgs, returns *Z_GetTeamMembersReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetTeamMembers(teamId string, offset, limit int) ([]*model.TeamMember, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetTeamMembers(args.A, args.B, args.C)
+	} else {
+		return fmt.Errorf("API GetTeamMembers called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetTeamMemberArgs struct {
+	A string
+	B string
+}
+
+type Z_GetTeamMemberReturns struct {
+	A *model.TeamMember
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetTeamMember(teamId, userId string) (*model.TeamMember, *model.AppError) {
+	_args := &Z_GetTeamMemberArgs{teamId, userId}
+	_returns := &Z_GetTeamMemberReturns{}
+	if err := g.client.Call("Plugin.GetTeamMember", _args, _returns); err != nil {
+		log.Printf("RPC call to GetTeamMember API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetTeamMember(args *Z_GetTeamMemberArgs, returns *Z_GetTeamMemberReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetTeamMember(teamId, userId string) (*model.TeamMember, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetTeamMember(args.A, args.B)
+	} else {
+		return fmt.Errorf("API GetTeamMember called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetTeamMembersModifiedSinceArgs struct {
+	A string
+	B int64
+}
+
+type Z_GetTeamMembersModifiedSinceReturns struct {
+	A []*model.TeamMember
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetTeamMembersModifiedSince(teamId string, since int64) ([]*model.TeamMember, *model.AppError) {
+	_args := &Z_GetTeamMembersModifiedSinceArgs{teamId, since}
+	_returns := &Z_GetTeamMembersModifiedSinceReturns{}
+	if err := g.client.Call("Plugin.GetTeamMembersModifiedSince", _args, _returns); err != nil {
+		log.Printf("RPC call to GetTeamMembersModifiedSince API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetTeamMembersModifiedSince(args *Z_GetTeamMembersModifiedSinceArgs, returns *Z_GetTeamMembersModifiedSinceReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetTeamMembersModifiedSince(teamId string, since int64) ([]*model.TeamMember, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetTeamMembersModifiedSince(args.A, args.B)
+	} else {
+		return fmt.Errorf("API GetTeamMembersModifiedSince called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetTeamStatsArgs struct {
+	A string
+}
+
+type Z_GetTeamStatsReturns struct {
+	A *model.TeamStats
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetTeamStats(teamId string) (*model.TeamStats, *model.AppError) {
+	_args := &Z_GetTeamStatsArgs{teamId}
+	_returns := &Z_GetTeamStatsReturns{}
+	if err := g.client.Call("Plugin.GetTeamStats", _args, _returns); err != nil {
+		log.Printf("RPC call to GetTeamStats API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetTeamStats(args *Z_GetTeamStatsArgs, returns *Z_GetTeamStatsReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetTeamStats(teamId string) (*model.TeamStats, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetTeamStats(args.A)
+	} else {
+		return fmt.Errorf("API GetTeamStats called but not implemented.")
+	}
+	return nil
+}
+
+type Z_UpdateTeamMemberRolesArgs struct {
+	A string
+	B string
+	C string
+}
+
+type Z_UpdateTeamMemberRolesReturns struct {
+	A *model.TeamMember
+	B *model.AppError
+}
+
+func (g *apiRPCClient) UpdateTeamMemberRoles(teamId, userId, newRoles string) (*model.TeamMember, *model.AppError) {
+	_args := &Z_UpdateTeamMemberRolesArgs{teamId, userId, newRoles}
+	_returns := &Z_UpdateTeamMemberRolesReturns{}
+	if err := g.client.Call("Plugin.UpdateTeamMemberRoles", _args, _returns); err != nil {
+		log.Printf("RPC call to UpdateTeamMemberRoles API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) UpdateTeamMemberRoles(args *Z_UpdateTeamMemberRolesArgs, returns *Z_UpdateTeamMemberRolesReturns) error {
+	if hook, ok := s.impl.(interface {
+		UpdateTeamMemberRoles(teamId, userId, newRoles string) (*model.TeamMember, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.UpdateTeamMemberRoles(args.A, args.B, args.C)
+	} else {
+		return fmt.Errorf("API UpdateTeamMemberRoles called but not implemented.")
+	}
+	return nil
+}
+
+type Z_CreateChannelArgs struct {
+	A *model.Channel
+}
+
+type Z_CreateChannelReturns struct {
+	A *model.Channel
+	B *model.AppError
+}
+
+func (g *apiRPCClient) CreateChannel(channel *model.Channel) (*model.Channel, *model.AppError) {
+	_args := &Z_CreateChannelArgs{channel}
+	_returns := &Z_CreateChannelReturns{}
+	if err := g.client.Call("Plugin.CreateChannel", _args, _returns); err != nil {
+		log.Printf("RPC call to CreateChannel API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) CreateChannel(args *Z_CreateChannelArgs, returns *Z_CreateChannelReturns) error {
+	if hook, ok := s.impl.(interface {
+		CreateChannel(channel *model.Channel) (*model.Channel, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.CreateChannel(args.A)
+	} else {
+		return fmt.Errorf("API CreateChannel called but not implemented.")
+	}
+	return nil
+}
+
+type Z_DeleteChannelArgs struct {
+	A string
+}
+
+type Z_DeleteChannelReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) DeleteChannel(channelId string) *model.AppError {
+	_args := &Z_DeleteChannelArgs{channelId}
+	_returns := &Z_DeleteChannelReturns{}
+	if err := g.client.Call("Plugin.DeleteChannel", _args, _returns); err != nil {
+		log.Printf("RPC call to DeleteChannel API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) DeleteChannel(args *Z_DeleteChannelArgs, returns *Z_DeleteChannelReturns) error {
+	if hook, ok := s.impl.(interface {
+		DeleteChannel(channelId string) *model.AppError
+	}); ok {
+		returns.A = hook.DeleteChannel(args.A)
+	} else {
+		return fmt.Errorf("API DeleteChannel called but not implemented.")
+	}
+	return nil
+}
+
+type Z_RestoreChannelArgs struct {
+	A string
+}
+
+type Z_RestoreChannelReturns struct {
+	A *model.Channel
+	B *model.AppError
+}
+
+func (g *apiRPCClient) RestoreChannel(channelId string) (*model.Channel, *model.AppError) {
+	_args := &Z_RestoreChannelArgs{channelId}
+	_returns := &Z_RestoreChannelReturns{}
+	if err := g.client.Call("Plugin.RestoreChannel", _args, _returns); err != nil {
+		log.Printf("RPC call to RestoreChannel API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) RestoreChannel(args *Z_RestoreChannelArgs, returns *Z_RestoreChannelReturns) error {
+	if hook, ok := s.impl.(interface {
+		RestoreChannel(channelId string) (*model.Channel, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.RestoreChannel(args.A)
+	} else {
+		return fmt.Errorf("API RestoreChannel called but not implemented.")
+	}
+	return nil
+}
+
+type Z_ConvertChannelToPrivateArgs struct {
+	A string
+}
+
+type Z_ConvertChannelToPrivateReturns struct {
+	A *model.Channel
+	B *model.AppError
+}
+
+func (g *apiRPCClient) ConvertChannelToPrivate(channelId string) (*model.Channel, *model.AppError) {
+	_args := &Z_ConvertChannelToPrivateArgs{channelId}
+	_returns := &Z_ConvertChannelToPrivateReturns{}
+	if err := g.client.Call("Plugin.ConvertChannelToPrivate", _args, _returns); err != nil {
+		log.Printf("RPC call to ConvertChannelToPrivate API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) ConvertChannelToPrivate(args *Z_ConvertChannelToPrivateArgs, returns *Z_ConvertChannelToPrivateReturns) error {
+	if hook, ok := s.impl.(interface {
+		ConvertChannelToPrivate(channelId string) (*model.Channel, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.ConvertChannelToPrivate(args.A)
+	} else {
+		return fmt.Errorf("API ConvertChannelToPrivate called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetPublicChannelsForTeamArgs struct {
+	A string
+	B int
+	C int
+}
+
+type Z_GetPublicChannelsForTeamReturns struct {
+	A *model.ChannelList
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetPublicChannelsForTeam(teamId string, offset, limit int) (*model.ChannelList, *model.AppError) {
+	_args := &Z_GetPublicChannelsForTeamArgs{teamId, offset, limit}
+	_returns := &Z_GetPublicChannelsForTeamReturns{}
+	if err := g.client.Call("Plugin.GetPublicChannelsForTeam", _args, _returns); err != nil {
+		log.Printf("RPC call to GetPublicChannelsForTeam API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetPublicChannelsForTeam(args *Z_GetPublicChannelsForTeamArgs, returns *Z_GetPublicChannelsForTeamReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetPublicChannelsForTeam(teamId string, offset, limit int) (*model.ChannelList, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetPublicChannelsForTeam(args.A, args.B, args.C)
+	} else {
+		return fmt.Errorf("API GetPublicChannelsForTeam called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetChannelArgs struct {
+	A string
+}
+
+type Z_GetChannelReturns struct {
+	A *model.Channel
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetChannel(channelId string) (*model.Channel, *model.AppError) {
+	_args := &Z_GetChannelArgs{channelId}
+	_returns := &Z_GetChannelReturns{}
+	if err := g.client.Call("Plugin.GetChannel", _args, _returns); err != nil {
+		log.Printf("RPC call to GetChannel API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetChannel(args *Z_GetChannelArgs, returns *Z_GetChannelReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetChannel(channelId string) (*model.Channel, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetChannel(args.A)
+	} else {
+		return fmt.Errorf("API GetChannel called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetChannelByNameArgs struct {
+	A string
+	B string
+	C bool
+}
+
+type Z_GetChannelByNameReturns struct {
+	A *model.Channel
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetChannelByName(teamId, name string, includeDeleted bool) (*model.Channel, *model.AppError) {
+	_args := &Z_GetChannelByNameArgs{teamId, name, includeDeleted}
+	_returns := &Z_GetChannelByNameReturns{}
+	if err := g.client.Call("Plugin.GetChannelByName", _args, _returns); err != nil {
+		log.Printf("RPC call to GetChannelByName API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetChannelByName(args *Z_GetChannelByNameArgs, returns *Z_GetChannelByNameReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetChannelByName(teamId, name string, includeDeleted bool) (*model.Channel, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetChannelByName(args.A, args.B, args.C)
+	} else {
+		return fmt.Errorf("API GetChannelByName called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetChannelByNameForTeamNameArgs struct {
+	A string
+	B string
+	C bool
+}
+
+type Z_GetChannelByNameForTeamNameReturns struct {
+	A *model.Channel
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetChannelByNameForTeamName(teamName, channelName string, includeDeleted bool) (*model.Channel, *model.AppError) {
+	_args := &Z_GetChannelByNameForTeamNameArgs{teamName, channelName, includeDeleted}
+	_returns := &Z_GetChannelByNameForTeamNameReturns{}
+	if err := g.client.Call("Plugin.GetChannelByNameForTeamName", _args, _returns); err != nil {
+		log.Printf("RPC call to GetChannelByNameForTeamName API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetChannelByNameForTeamName(args *Z_GetChannelByNameForTeamNameArgs, returns *Z_GetChannelByNameForTeamNameReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetChannelByNameForTeamName(teamName, channelName string, includeDeleted bool) (*model.Channel, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetChannelByNameForTeamName(args.A, args.B, args.C)
+	} else {
+		return fmt.Errorf("API GetChannelByNameForTeamName called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetDirectChannelArgs struct {
+	A string
+	B string
+}
+
+type Z_GetDirectChannelReturns struct {
+	A *model.Channel
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetDirectChannel(userId1, userId2 string) (*model.Channel, *model.AppError) {
+	_args := &Z_GetDirectChannelArgs{userId1, userId2}
+	_returns := &Z_GetDirectChannelReturns{}
+	if err := g.client.Call("Plugin.GetDirectChannel", _args, _returns); err != nil {
+		log.Printf("RPC call to GetDirectChannel API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetDirectChannel(args *Z_GetDirectChannelArgs, returns *Z_GetDirectChannelReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetDirectChannel(userId1, userId2 string) (*model.Channel, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetDirectChannel(args.A, args.B)
+	} else {
+		return fmt.Errorf("API GetDirectChannel called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetGroupChannelArgs struct {
+	A []string
+}
+
+type Z_GetGroupChannelReturns struct {
+	A *model.Channel
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetGroupChannel(userIds []string) (*model.Channel, *model.AppError) {
+	_args := &Z_GetGroupChannelArgs{userIds}
+	_returns := &Z_GetGroupChannelReturns{}
+	if err := g.client.Call("Plugin.GetGroupChannel", _args, _returns); err != nil {
+		log.Printf("RPC call to GetGroupChannel API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetGroupChannel(args *Z_GetGroupChannelArgs, returns *Z_GetGroupChannelReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetGroupChannel(userIds []string) (*model.Channel, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetGroupChannel(args.A)
+	} else {
+		return fmt.Errorf("API GetGroupChannel called but not implemented.")
+	}
+	return nil
+}
+
+type Z_UpdateChannelArgs struct {
+	A *model.Channel
+}
+
+type Z_UpdateChannelReturns struct {
+	A *model.Channel
+	B *model.AppError
+}
+
+func (g *apiRPCClient) UpdateChannel(channel *model.Channel) (*model.Channel, *model.AppError) {
+	_args := &Z_UpdateChannelArgs{channel}
+	_returns := &Z_UpdateChannelReturns{}
+	if err := g.client.Call("Plugin.UpdateChannel", _args, _returns); err != nil {
+		log.Printf("RPC call to UpdateChannel API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) UpdateChannel(args *Z_UpdateChannelArgs, returns *Z_UpdateChannelReturns) error {
+	if hook, ok := s.impl.(interface {
+		UpdateChannel(channel *model.Channel) (*model.Channel, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.UpdateChannel(args.A)
+	} else {
+		return fmt.Errorf("API UpdateChannel called but not implemented.")
+	}
+	return nil
+}
+
+type Z_SetChannelModerationForMembersArgs struct {
+	A string
+	B bool
+}
+
+type Z_SetChannelModerationForMembersReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) SetChannelModerationForMembers(channelId string, allowPost bool) *model.AppError {
+	_args := &Z_SetChannelModerationForMembersArgs{channelId, allowPost}
+	_returns := &Z_SetChannelModerationForMembersReturns{}
+	if err := g.client.Call("Plugin.SetChannelModerationForMembers", _args, _returns); err != nil {
+		log.Printf("RPC call to SetChannelModerationForMembers API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) SetChannelModerationForMembers(args *Z_SetChannelModerationForMembersArgs, returns *Z_SetChannelModerationForMembersReturns) error {
+	if hook, ok := s.impl.(interface {
+		SetChannelModerationForMembers(channelId string, allowPost bool) *model.AppError
+	}); ok {
+		returns.A = hook.SetChannelModerationForMembers(args.A, args.B)
+	} else {
+		return fmt.Errorf("API SetChannelModerationForMembers called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetChannelModerationArgs struct {
+	A string
+}
+
+type Z_GetChannelModerationReturns struct {
+	A *model.ChannelModeration
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetChannelModeration(channelId string) (*model.ChannelModeration, *model.AppError) {
+	_args := &Z_GetChannelModerationArgs{channelId}
+	_returns := &Z_GetChannelModerationReturns{}
+	if err := g.client.Call("Plugin.GetChannelModeration", _args, _returns); err != nil {
+		log.Printf("RPC call to GetChannelModeration API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetChannelModeration(args *Z_GetChannelModerationArgs, returns *Z_GetChannelModerationReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetChannelModeration(channelId string) (*model.ChannelModeration, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetChannelModeration(args.A)
+	} else {
+		return fmt.Errorf("API GetChannelModeration called but not implemented.")
+	}
+	return nil
+}
+
+type Z_AddChannelMemberArgs struct {
+	A string
+	B string
+	C model.MemberAddOptions
+}
+
+type Z_AddChannelMemberReturns struct {
+	A *model.ChannelMember
+	B *model.AppError
+}
+
+func (g *apiRPCClient) AddChannelMember(channelId, userId string, opts model.MemberAddOptions) (*model.ChannelMember, *model.AppError) {
+	_args := &Z_AddChannelMemberArgs{channelId, userId, opts}
+	_returns := &Z_AddChannelMemberReturns{}
+	if err := g.client.Call("Plugin.AddChannelMember", _args, _returns); err != nil {
+		log.Printf("RPC call to AddChannelMember API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) AddChannelMember(args *Z_AddChannelMemberArgs, returns *Z_AddChannelMemberReturns) error {
+	if hook, ok := s.impl.(interface {
+		AddChannelMember(channelId, userId string, opts model.MemberAddOptions) (*model.ChannelMember, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.AddChannelMember(args.A, args.B, args.C)
+	} else {
+		return fmt.Errorf("API AddChannelMember called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetChannelMemberArgs struct {
+	A string
+	B string
+}
+
+type Z_GetChannelMemberReturns struct {
+	A *model.ChannelMember
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetChannelMember(channelId, userId string) (*model.ChannelMember, *model.AppError) {
+	_args := &Z_GetChannelMemberArgs{channelId, userId}
+	_returns := &Z_GetChannelMemberReturns{}
+	if err := g.client.Call("Plugin.GetChannelMember", _args, _returns); err != nil {
+		log.Printf("RPC call to GetChannelMember API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetChannelMember(args *Z_GetChannelMemberArgs, returns *Z_GetChannelMemberReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetChannelMember(channelId, userId string) (*model.ChannelMember, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetChannelMember(args.A, args.B)
+	} else {
+		return fmt.Errorf("API GetChannelMember called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetChannelMembersModifiedSinceArgs struct {
+	A string
+	B int64
+}
+
+type Z_GetChannelMembersModifiedSinceReturns struct {
+	A *model.ChannelMemberChanges
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetChannelMembersModifiedSince(channelId string, since int64) (*model.ChannelMemberChanges, *model.AppError) {
+	_args := &Z_GetChannelMembersModifiedSinceArgs{channelId, since}
+	_returns := &Z_GetChannelMembersModifiedSinceReturns{}
+	if err := g.client.Call("Plugin.GetChannelMembersModifiedSince", _args, _returns); err != nil {
+		log.Printf("RPC call to GetChannelMembersModifiedSince API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetChannelMembersModifiedSince(args *Z_GetChannelMembersModifiedSinceArgs, returns *Z_GetChannelMembersModifiedSinceReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetChannelMembersModifiedSince(channelId string, since int64) (*model.ChannelMemberChanges, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetChannelMembersModifiedSince(args.A, args.B)
+	} else {
+		return fmt.Errorf("API GetChannelMembersModifiedSince called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetChannelStatsArgs struct {
+	A string
+}
+
+type Z_GetChannelStatsReturns struct {
+	A *model.ChannelStats
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetChannelStats(channelId string) (*model.ChannelStats, *model.AppError) {
+	_args := &Z_GetChannelStatsArgs{channelId}
+	_returns := &Z_GetChannelStatsReturns{}
+	if err := g.client.Call("Plugin.GetChannelStats", _args, _returns); err != nil {
+		log.Printf("RPC call to GetChannelStats API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetChannelStats(args *Z_GetChannelStatsArgs, returns *Z_GetChannelStatsReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetChannelStats(channelId string) (*model.ChannelStats, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetChannelStats(args.A)
+	} else {
+		return fmt.Errorf("API GetChannelStats called but not implemented.")
+	}
+	return nil
+}
+
+type Z_UpdateChannelMemberRolesArgs struct {
+	A string
+	B string
+	C string
+}
+
+type Z_UpdateChannelMemberRolesReturns struct {
+	A *model.ChannelMember
+	B *model.AppError
+}
+
+func (g *apiRPCClient) UpdateChannelMemberRoles(channelId, userId, newRoles string) (*model.ChannelMember, *model.AppError) {
+	_args := &Z_UpdateChannelMemberRolesArgs{channelId, userId, newRoles}
+	_returns := &Z_UpdateChannelMemberRolesReturns{}
+	if err := g.client.Call("Plugin.UpdateChannelMemberRoles", _args, _returns); err != nil {
+		log.Printf("RPC call to UpdateChannelMemberRoles API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) UpdateChannelMemberRoles(args *Z_UpdateChannelMemberRolesArgs, returns *Z_UpdateChannelMemberRolesReturns) error {
+	if hook, ok := s.impl.(interface {
+		UpdateChannelMemberRoles(channelId, userId, newRoles string) (*model.ChannelMember, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.UpdateChannelMemberRoles(args.A, args.B, args.C)
+	} else {
+		return fmt.Errorf("API UpdateChannelMemberRoles called but not implemented.")
+	}
+	return nil
+}
+
+type Z_GetChannelMemberNotifyPropsArgs struct {
+	A string
+	B string
+}
+
+type Z_GetChannelMemberNotifyPropsReturns struct {
+	A model.StringMap
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetChannelMemberNotifyProps(channelId, userId string) (model.StringMap, *model.AppError) {
+	_args := &Z_GetChannelMemberNotifyPropsArgs{channelId, userId}
+	_returns := &Z_GetChannelMemberNotifyPropsReturns{}
+	if err := g.client.Call("Plugin.GetChannelMemberNotifyProps", _args, _returns); err != nil {
+		log.Printf("RPC call to GetChannelMemberNotifyProps API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) GetUserStatus(args *Z_GetUserStatusArgs, returns *Z_GetUserStatusReturns) error {
+func (s *apiRPCServer) GetChannelMemberNotifyProps(args *Z_GetChannelMemberNotifyPropsArgs, returns *Z_GetChannelMemberNotifyPropsReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetUserStatus(userId string) (*model.Status, *model.AppError)
+		GetChannelMemberNotifyProps(channelId, userId string) (model.StringMap, *model.AppError)
 	}); ok {
-		returns.A, returns.B = hook.GetUserStatus(args.A)
+		returns.A, returns.B = hook.GetChannelMemberNotifyProps(args.A, args.B)
 	} else {
-		return fmt.Errorf("API GetUserStatus called but not implemented.")
+		return fmt.Errorf("API GetChannelMemberNotifyProps called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetUserStatusesByIdsArgs struct {
-	A []string
+type Z_UpdateChannelMemberNotificationsArgs struct {
+	A string
+	B string
+	C map[string]string
 }
 
-type Z_GetUserStatusesByIdsReturns struct {
-	A []*model.Status
+type Z_UpdateChannelMemberNotificationsReturns struct {
+	A *model.ChannelMember
 	B *model.AppError
 }
 
-func (g *apiRPCClient) GetUserStatusesByIds(userIds []string) ([]*model.Status, *model.AppError) {
-	_args := &Z_GetUserStatusesByIdsArgs{userIds}
-	_returns := &Z_GetUserStatusesByIdsReturns{}
-	if err := g.client.Call("Plugin.GetUserStatusesByIds", _args, _returns); err != nil {
-		log.Printf("RPC call to GetUserStatusesByIds API failed: %s", err.Error())
+func (g *apiRPCClient) UpdateChannelMemberNotifications(channelId, userId string, notifications map[string]string) (*model.ChannelMember, *model.AppError) {
+	_args := &Z_UpdateChannelMemberNotificationsArgs{channelId, userId, notifications}
+	_returns := &Z_UpdateChannelMemberNotificationsReturns{}
+	if err := g.client.Call("Plugin.UpdateChannelMemberNotifications", _args, _returns); err != nil {
+		log.Printf("RPC call to UpdateChannelMemberNotifications API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) GetUserStatusesByIds(args *Z_GetUserStatusesByIdsArgs, returns *Z_GetUserStatusesByIdsReturns) error {
+func (s *apiRPCServer) UpdateChannelMemberNotifications(args *Z_UpdateChannelMemberNotificationsArgs, returns *Z_UpdateChannelMemberNotificationsReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetUserStatusesByIds(userIds []string) ([]*model.Status, *model.AppError)
+		UpdateChannelMemberNotifications(channelId, userId string, notifications map[string]string) (*model.ChannelMember, *model.AppError)
 	}); ok {
-		returns.A, returns.B = hook.GetUserStatusesByIds(args.A)
+		returns.A, returns.B = hook.UpdateChannelMemberNotifications(args.A, args.B, args.C)
 	} else {
-		return fmt.Errorf("API GetUserStatusesByIds called but not implemented.")
+		return fmt.Errorf("API UpdateChannelMemberNotifications called but not implemented.")
 	}
 	return nil
 }
 
-type Z_UpdateUserStatusArgs struct {
+type Z_DeleteChannelMemberArgs struct {
 	A string
 	B string
 }
 
-type Z_UpdateUserStatusReturns struct {
-	A *model.Status
+type Z_DeleteChannelMemberReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) DeleteChannelMember(channelId, userId string) *model.AppError {
+	_args := &Z_DeleteChannelMemberArgs{channelId, userId}
+	_returns := &Z_DeleteChannelMemberReturns{}
+	if err := g.client.Call("Plugin.DeleteChannelMember", _args, _returns); err != nil {
+		log.Printf("RPC call to DeleteChannelMember API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) DeleteChannelMember(args *Z_DeleteChannelMemberArgs, returns *Z_DeleteChannelMemberReturns) error {
+	if hook, ok := s.impl.(interface {
+		DeleteChannelMember(channelId, userId string) *model.AppError
+	}); ok {
+		returns.A = hook.DeleteChannelMember(args.A, args.B)
+	} else {
+		return fmt.Errorf("API DeleteChannelMember called but not implemented.")
+	}
+	return nil
+}
+
+type Z_ExportChannelArgs struct {
+	A string
+	B string
+}
+
+type Z_ExportChannelReturns struct {
+	A string
 	B *model.AppError
 }
 
-func (g *apiRPCClient) UpdateUserStatus(userId, status string) (*model.Status, *model.AppError) {
-	_args := &Z_UpdateUserStatusArgs{userId, status}
-	_returns := &Z_UpdateUserStatusReturns{}
-	if err := g.client.Call("Plugin.UpdateUserStatus", _args, _returns); err != nil {
-		log.Printf("RPC call to UpdateUserStatus API failed: %s", err.Error())
+func (g *apiRPCClient) ExportChannel(channelId string, format string) (string, *model.AppError) {
+	_args := &Z_ExportChannelArgs{channelId, format}
+	_returns := &Z_ExportChannelReturns{}
+	if err := g.client.Call("Plugin.ExportChannel", _args, _returns); err != nil {
+		log.Printf("RPC call to ExportChannel API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) UpdateUserStatus(args *Z_UpdateUserStatusArgs, returns *Z_UpdateUserStatusReturns) error {
+func (s *apiRPCServer) ExportChannel(args *Z_ExportChannelArgs, returns *Z_ExportChannelReturns) error {
 	if hook, ok := s.impl.(interface {
-		UpdateUserStatus(userId, status string) (*model.Status, *model.AppError)
+		ExportChannel(channelId string, format string) (string, *model.AppError)
 	}); ok {
-		returns.A, returns.B = hook.UpdateUserStatus(args.A, args.B)
+		returns.A, returns.B = hook.ExportChannel(args.A, args.B)
 	} else {
-		return fmt.Errorf("API UpdateUserStatus called but not implemented.")
+		return fmt.Errorf("API ExportChannel called but not implemented.")
 	}
 	return nil
 }
 
-type Z_CreateTeamArgs struct {
-	A *model.Team
+type Z_GetExportStatusArgs struct {
+	A string
 }
 
-type Z_CreateTeamReturns struct {
-	A *model.Team
+type Z_GetExportStatusReturns struct {
+	A *model.ChannelExportStatus
 	B *model.AppError
 }
 
-func (g *apiRPCClient) CreateTeam(team *model.Team) (*model.Team, *model.AppError) {
-	_args := &Z_CreateTeamArgs{team}
-	_returns := &Z_CreateTeamReturns{}
-	if err := g.client.Call("Plugin.CreateTeam", _args, _returns); err != nil {
-		log.Printf("RPC call to CreateTeam API failed: %s", err.Error())
+func (g *apiRPCClient) GetExportStatus(jobId string) (*model.ChannelExportStatus, *model.AppError) {
+	_args := &Z_GetExportStatusArgs{jobId}
+	_returns := &Z_GetExportStatusReturns{}
+	if err := g.client.Call("Plugin.GetExportStatus", _args, _returns); err != nil {
+		log.Printf("RPC call to GetExportStatus API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) CreateTeam(args *Z_CreateTeamArgs, returns *Z_CreateTeamReturns) error {
+func (s *apiRPCServer) GetExportStatus(args *Z_GetExportStatusArgs, returns *Z_GetExportStatusReturns) error {
 	if hook, ok := s.impl.(interface {
-		CreateTeam(team *model.Team) (*model.Team, *model.AppError)
+		GetExportStatus(jobId string) (*model.ChannelExportStatus, *model.AppError)
 	}); ok {
-		returns.A, returns.B = hook.CreateTeam(args.A)
+		returns.A, returns.B = hook.GetExportStatus(args.A)
 	} else {
-		return fmt.Errorf("API CreateTeam called but not implemented.")
+		return fmt.Errorf("API GetExportStatus called but not implemented.")
 	}
 	return nil
 }
 
-type Z_DeleteTeamArgs struct {
+type Z_CreatePostArgs struct {
+	A *model.Post
+}
+
+type Z_CreatePostReturns struct {
+	A *model.Post
+	B *model.AppError
+}
+
+func (g *apiRPCClient) CreatePost(post *model.Post) (*model.Post, *model.AppError) {
+	_args := &Z_CreatePostArgs{post}
+	_returns := &Z_CreatePostReturns{}
+	if err := g.client.Call("Plugin.CreatePost", _args, _returns); err != nil {
+		log.Printf("RPC call to CreatePost API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) CreatePost(args *Z_CreatePostArgs, returns *Z_CreatePostReturns) error {
+	if hook, ok := s.impl.(interface {
+		CreatePost(post *model.Post) (*model.Post, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.CreatePost(args.A)
+	} else {
+		return fmt.Errorf("API CreatePost called but not implemented.")
+	}
+	return nil
+}
+
+type Z_SendEphemeralPostArgs struct {
 	A string
+	B *model.Post
 }
 
-type Z_DeleteTeamReturns struct {
+type Z_SendEphemeralPostReturns struct {
+	A *model.Post
+}
+
+func (g *apiRPCClient) SendEphemeralPost(userId string, post *model.Post) *model.Post {
+	_args := &Z_SendEphemeralPostArgs{userId, post}
+	_returns := &Z_SendEphemeralPostReturns{}
+	if err := g.client.Call("Plugin.SendEphemeralPost", _args, _returns); err != nil {
+		log.Printf("RPC call to SendEphemeralPost API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) SendEphemeralPost(args *Z_SendEphemeralPostArgs, returns *Z_SendEphemeralPostReturns) error {
+	if hook, ok := s.impl.(interface {
+		SendEphemeralPost(userId string, post *model.Post) *model.Post
+	}); ok {
+		returns.A = hook.SendEphemeralPost(args.A, args.B)
+	} else {
+		return fmt.Errorf("API SendEphemeralPost called but not implemented.")
+	}
+	return nil
+}
+
+type Z_DeletePostArgs struct {
+	A string
+}
+
+type Z_DeletePostReturns struct {
 	A *model.AppError
 }
 
-func (g *apiRPCClient) DeleteTeam(teamId string) *model.AppError {
-	_args := &Z_DeleteTeamArgs{teamId}
-	_returns := &Z_DeleteTeamReturns{}
-	if err := g.client.Call("Plugin.DeleteTeam", _args, _returns); err != nil {
-		log.Printf("RPC call to DeleteTeam API failed: %s", err.Error())
+func (g *apiRPCClient) DeletePost(postId string) *model.AppError {
+	_args := &Z_DeletePostArgs{postId}
+	_returns := &Z_DeletePostReturns{}
+	if err := g.client.Call("Plugin.DeletePost", _args, _returns); err != nil {
+		log.Printf("RPC call to DeletePost API failed: %s", err.Error())
 	}
 	return _returns.A
 }
 
-func (s *apiRPCServer) DeleteTeam(args *Z_DeleteTeamArgs, returns *Z_DeleteTeamReturns) error {
+func (s *apiRPCServer) DeletePost(args *Z_DeletePostArgs, returns *Z_DeletePostReturns) error {
 	if hook, ok := s.impl.(interface {
-		DeleteTeam(teamId string) *model.AppError
+		DeletePost(postId string) *model.AppError
 	}); ok {
-		returns.A = hook.DeleteTeam(args.A)
+		returns.A = hook.DeletePost(args.A)
 	} else {
-		return fmt.Errorf("API DeleteTeam called but not implemented.")
+		return fmt.Errorf("API DeletePost called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetTeamsArgs struct {
+type Z_GetPostArgs struct {
+	A string
 }
 
-type Z_GetTeamsReturns struct {
-	A []*model.Team
+type Z_GetPostReturns struct {
+	A *model.Post
 	B *model.AppError
 }
 
-func (g *apiRPCClient) GetTeams() ([]*model.Team, *model.AppError) {
-	_args := &Z_GetTeamsArgs{}
-	_returns := &Z_GetTeamsReturns{}
-	if err := g.client.Call("Plugin.GetTeams", _args, _returns); err != nil {
-		log.Printf("RPC call to GetTeams API failed: %s", err.Error())
+func (g *apiRPCClient) GetPost(postId string) (*model.Post, *model.AppError) {
+	_args := &Z_GetPostArgs{postId}
+	_returns := &Z_GetPostReturns{}
+	if err := g.client.Call("Plugin.GetPost", _args, _returns); err != nil {
+		log.Printf("RPC call to GetPost API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) GetTeams(args *Z_GetTeamsArgs, returns *Z_GetTeamsReturns) error {
+func (s *apiRPCServer) GetPost(args *Z_GetPostArgs, returns *Z_GetPostReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetTeams() ([]*model.Team, *model.AppError)
+		GetPost(postId string) (*model.Post, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetPost(args.A)
+	} else {
+		return fmt.Errorf("API GetPost called but not implemented.")
+	}
+	return nil
+}
+
+type Z_UpdatePostArgs struct {
+	A *model.Post
+}
+
+type Z_UpdatePostReturns struct {
+	A *model.Post
+	B *model.AppError
+}
+
+func (g *apiRPCClient) UpdatePost(post *model.Post) (*model.Post, *model.AppError) {
+	_args := &Z_UpdatePostArgs{post}
+	_returns := &Z_UpdatePostReturns{}
+	if err := g.client.Call("Plugin.UpdatePost", _args, _returns); err != nil {
+		log.Printf("RPC call to UpdatePost API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) UpdatePost(args *Z_UpdatePostArgs, returns *Z_UpdatePostReturns) error {
+	if hook, ok := s.impl.(interface {
+		UpdatePost(post *model.Post) (*model.Post, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.UpdatePost(args.A)
+	} else {
+		return fmt.Errorf("API UpdatePost called but not implemented.")
+	}
+	return nil
+}
+
+type Z_AddPostMetadataArgs struct {
+	A string
+	B string
+	C []byte
+}
+
+type Z_AddPostMetadataReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) AddPostMetadata(postId, namespace string, data []byte) *model.AppError {
+	_args := &Z_AddPostMetadataArgs{postId, namespace, data}
+	_returns := &Z_AddPostMetadataReturns{}
+	if err := g.client.Call("Plugin.AddPostMetadata", _args, _returns); err != nil {
+		log.Printf("RPC call to AddPostMetadata API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) AddPostMetadata(args *Z_AddPostMetadataArgs, returns *Z_AddPostMetadataReturns) error {
+	if hook, ok := s.impl.(interface {
+		AddPostMetadata(postId, namespace string, data []byte) *model.AppError
 	}); ok {
-		returns.A, returns.B = hook.GetTeams()
+		returns.A = hook.AddPostMetadata(args.A, args.B, args.C)
 	} else {
-		return fmt.Errorf("API GetTeams called but not implemented.")
+		return fmt.Errorf("API AddPostMetadata called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetTeamArgs struct {
+type Z_RequestPostAcknowledgementArgs struct {
 	A string
 }
 
-type Z_GetTeamReturns struct {
-	A *model.Team
-	B *model.AppError
+type Z_RequestPostAcknowledgementReturns struct {
+	A *model.AppError
 }
 
-func (g *apiRPCClient) GetTeam(teamId string) (*model.Team, *model.AppError) {
-	_args := &Z_GetTeamArgs{teamId}
-	_returns := &Z_GetTeamReturns{}
-	if err := g.client.Call("Plugin.GetTeam", _args, _returns); err != nil {
-		log.Printf("RPC call to GetTeam API failed: %s", err.Error())
+func (g *apiRPCClient) RequestPostAcknowledgement(postId string) *model.AppError {
+	_args := &Z_RequestPostAcknowledgementArgs{postId}
+	_returns := &Z_RequestPostAcknowledgementReturns{}
+	if err := g.client.Call("Plugin.RequestPostAcknowledgement", _args, _returns); err != nil {
+		log.Printf("RPC call to RequestPostAcknowledgement API failed: %s", err.Error())
 	}
-	return _returns.A, _returns.B
+	return _returns.A
 }
 
-func (s *apiRPCServer) GetTeam(args *Z_GetTeamArgs, returns *Z_GetTeamReturns) error {
+func (s *apiRPCServer) RequestPostAcknowledgement(args *Z_RequestPostAcknowledgementArgs, returns *Z_RequestPostAcknowledgementReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetTeam(teamId string) (*model.Team, *model.AppError)
+		RequestPostAcknowledgement(postId string) *model.AppError
 	}); ok {
-		returns.A, returns.B = hook.GetTeam(args.A)
+		returns.A = hook.RequestPostAcknowledgement(args.A)
 	} else {
-		return fmt.Errorf("API GetTeam called but not implemented.")
+		return fmt.Errorf("API RequestPostAcknowledgement called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetTeamByNameArgs struct {
+type Z_GetPostAcknowledgementsArgs struct {
 	A string
 }
 
-type Z_GetTeamByNameReturns struct {
-	A *model.Team
+type Z_GetPostAcknowledgementsReturns struct {
+	A []*model.PostAcknowledgement
 	B *model.AppError
 }
 
-func (g *apiRPCClient) GetTeamByName(name string) (*model.Team, *model.AppError) {
-	_args := &Z_GetTeamByNameArgs{name}
-	_returns := &Z_GetTeamByNameReturns{}
-	if err := g.client.Call("Plugin.GetTeamByName", _args, _returns); err != nil {
-		log.Printf("RPC call to GetTeamByName API failed: %s", err.Error())
+func (g *apiRPCClient) GetPostAcknowledgements(postId string) ([]*model.PostAcknowledgement, *model.AppError) {
+	_args := &Z_GetPostAcknowledgementsArgs{postId}
+	_returns := &Z_GetPostAcknowledgementsReturns{}
+	if err := g.client.Call("Plugin.GetPostAcknowledgements", _args, _returns); err != nil {
+		log.Printf("RPC call to GetPostAcknowledgements API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) GetTeamByName(args *Z_GetTeamByNameArgs, returns *Z_GetTeamByNameReturns) error {
+func (s *apiRPCServer) GetPostAcknowledgements(args *Z_GetPostAcknowledgementsArgs, returns *Z_GetPostAcknowledgementsReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetTeamByName(name string) (*model.Team, *model.AppError)
+		GetPostAcknowledgements(postId string) ([]*model.PostAcknowledgement, *model.AppError)
 	}); ok {
-		returns.A, returns.B = hook.GetTeamByName(args.A)
+		returns.A, returns.B = hook.GetPostAcknowledgements(args.A)
 	} else {
-		return fmt.Errorf("API GetTeamByName called but not implemented.")
+		return fmt.Errorf("API GetPostAcknowledgements called but not implemented.")
 	}
 	return nil
 }
 
-type Z_UpdateTeamArgs struct {
-	A *model.Team
+type Z_FollowThreadForUserArgs struct {
+	A string
+	B string
 }
 
-type Z_UpdateTeamReturns struct {
-	A *model.Team
-	B *model.AppError
+type Z_FollowThreadForUserReturns struct {
+	A *model.AppError
 }
 
-func (g *apiRPCClient) UpdateTeam(team *model.Team) (*model.Team, *model.AppError) {
-	_args := &Z_UpdateTeamArgs{team}
-	_returns := &Z_UpdateTeamReturns{}
-	if err := g.client.Call("Plugin.UpdateTeam", _args, _returns); err != nil {
-		log.Printf("RPC call to UpdateTeam API failed: %s", err.Error())
+func (g *apiRPCClient) FollowThreadForUser(userId, postId string) *model.AppError {
+	_args := &Z_FollowThreadForUserArgs{userId, postId}
+	_returns := &Z_FollowThreadForUserReturns{}
+	if err := g.client.Call("Plugin.FollowThreadForUser", _args, _returns); err != nil {
+		log.Printf("RPC call to FollowThreadForUser API failed: %s", err.Error())
 	}
-	return _returns.A, _returns.B
+	return _returns.A
 }
 
-func (s *apiRPCServer) UpdateTeam(args *Z_UpdateTeamArgs, returns *Z_UpdateTeamReturns) error {
+func (s *apiRPCServer) FollowThreadForUser(args *Z_FollowThreadForUserArgs, returns *Z_FollowThreadForUserReturns) error {
 	if hook, ok := s.impl.(interface {
-		UpdateTeam(team *model.Team) (*model.Team, *model.AppError)
+		FollowThreadForUser(userId, postId string) *model.AppError
 	}); ok {
-		returns.A, returns.B = hook.UpdateTeam(args.A)
+		returns.A = hook.FollowThreadForUser(args.A, args.B)
 	} else {
-		return fmt.Errorf("API UpdateTeam called but not implemented.")
+		return fmt.Errorf("API FollowThreadForUser called but not implemented.")
 	}
 	return nil
 }
 
-type Z_CreateTeamMemberArgs struct {
+type Z_UnfollowThreadForUserArgs struct {
 	A string
 	B string
 }
 
-type Z_CreateTeamMemberReturns struct {
-	A *model.TeamMember
-	B *model.AppError
+type Z_UnfollowThreadForUserReturns struct {
+	A *model.AppError
 }
 
-func (g *apiRPCClient) CreateTeamMember(teamId, userId string) (*model.TeamMember, *model.AppError) {
-	_args := &Z_CreateTeamMemberArgs{teamId, userId}
-	_returns := &Z_CreateTeamMemberReturns{}
-	if err := g.client.Call("Plugin.CreateTeamMember", _args, _returns); err != nil {
-		log.Printf("RPC call to CreateTeamMember API failed: %s", err.Error())
+func (g *apiRPCClient) UnfollowThreadForUser(userId, postId string) *model.AppError {
+	_args := &Z_UnfollowThreadForUserArgs{userId, postId}
+	_returns := &Z_UnfollowThreadForUserReturns{}
+	if err := g.client.Call("Plugin.UnfollowThreadForUser", _args, _returns); err != nil {
+		log.Printf("RPC call to UnfollowThreadForUser API failed: %s", err.Error())
 	}
-	return _returns.A, _returns.B
+	return _returns.A
 }
 
-func (s *apiRPCServer) CreateTeamMember(args *Z_CreateTeamMemberArgs, returns *Z_CreateTeamMemberReturns) error {
+func (s *apiRPCServer) UnfollowThreadForUser(args *Z_UnfollowThreadForUserArgs, returns *Z_UnfollowThreadForUserReturns) error {
 	if hook, ok := s.impl.(interface {
-		CreateTeamMember(teamId, userId string) (*model.TeamMember, *model.AppError)
+		UnfollowThreadForUser(userId, postId string) *model.AppError
 	}); ok {
-		returns.A, returns.B = hook.CreateTeamMember(args.A, args.B)
+		returns.A = hook.UnfollowThreadForUser(args.A, args.B)
 	} else {
-		return fmt.Errorf("API CreateTeamMember called but not implemented.")
+		return fmt.Errorf("API UnfollowThreadForUser called but not implemented.")
 	}
 	return nil
 }
 
-type Z_CreateTeamMembersArgs struct {
+type Z_DeleteFileArgs struct {
 	A string
-	B []string
-	C string
 }
 
-type Z_CreateTeamMembersReturns struct {
-	A []*model.TeamMember
-	B *model.AppError
+type Z_DeleteFileReturns struct {
+	A *model.AppError
 }
 
-func (g *apiRPCClient) CreateTeamMembers(teamId string, userIds []string, requestorId string) ([]*model.TeamMember, *model.AppError) {
-	_args := &Z_CreateTeamMembersArgs{teamId, userIds, requestorId}
-	_returns := &Z_CreateTeamMembersReturns{}
-	if err := g.client.Call("Plugin.CreateTeamMembers", _args, _returns); err != nil {
-		log.Printf("RPC call to CreateTeamMembers API failed: %s", err.Error())
+func (g *apiRPCClient) DeleteFile(fileId string) *model.AppError {
+	_args := &Z_DeleteFileArgs{fileId}
+	_returns := &Z_DeleteFileReturns{}
+	if err := g.client.Call("Plugin.DeleteFile", _args, _returns); err != nil {
+		log.Printf("RPC call to DeleteFile API failed: %s", err.Error())
 	}
-	return _returns.A, _returns.B
+	return _returns.A
 }
 
-func (s *apiRPCServer) CreateTeamMembers(args *Z_CreateTeamMembersArgs, returns *Z_CreateTeamMembersReturns) error {
+func (s *apiRPCServer) DeleteFile(args *Z_DeleteFileArgs, returns *Z_DeleteFileReturns) error {
 	if hook, ok := s.impl.(interface {
-		CreateTeamMembers(teamId string, userIds []string, requestorId string) ([]*model.TeamMember, *model.AppError)
+		DeleteFile(fileId string) *model.AppError
 	}); ok {
-		returns.A, returns.B = hook.CreateTeamMembers(args.A, args.B, args.C)
+		returns.A = hook.DeleteFile(args.A)
 	} else {
-		return fmt.Errorf("API CreateTeamMembers called but not implemented.")
+		return fmt.Errorf("API DeleteFile called but not implemented.")
 	}
 	return nil
 }
 
-type Z_DeleteTeamMemberArgs struct {
+type Z_GetFileThumbnailArgs struct {
 	A string
-	B string
-	C string
 }
 
-type Z_DeleteTeamMemberReturns struct {
-	A *model.AppError
+type Z_GetFileThumbnailReturns struct {
+	A []byte
+	B *model.AppError
 }
 
-func (g *apiRPCClient) DeleteTeamMember(teamId, userId, requestorId string) *model.AppError {
-	_args := &Z_DeleteTeamMemberArgs{teamId, userId, requestorId}
-	_returns := &Z_DeleteTeamMemberReturns{}
-	if err := g.client.Call("Plugin.DeleteTeamMember", _args, _returns); err != nil {
-		log.Printf("RPC call to DeleteTeamMember API failed: %s", err.Error())
+func (g *apiRPCClient) GetFileThumbnail(fileId string) ([]byte, *model.AppError) {
+	_args := &Z_GetFileThumbnailArgs{fileId}
+	_returns := &Z_GetFileThumbnailReturns{}
+	if err := g.client.Call("Plugin.GetFileThumbnail", _args, _returns); err != nil {
+		log.Printf("RPC call to GetFileThumbnail API failed: %s", err.Error())
 	}
-	return _returns.A
+	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) DeleteTeamMember(args *Z_DeleteTeamMemberArgs, returns *Z_DeleteTeamMemberReturns) error {
+func (s *apiRPCServer) GetFileThumbnail(args *Z_GetFileThumbnailArgs, returns *Z_GetFileThumbnailReturns) error {
 	if hook, ok := s.impl.(interface {
-		DeleteTeamMember(teamId, userId, requestorId string) *model.AppError
+		GetFileThumbnail(fileId string) ([]byte, *model.AppError)
 	}); ok {
-		returns.A = hook.DeleteTeamMember(args.A, args.B, args.C)
+		returns.A, returns.B = hook.GetFileThumbnail(args.A)
 	} else {
-		return fmt.Errorf("API DeleteTeamMember called but not implemented.")
+		return fmt.Errorf("API GetFileThumbnail called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetTeamMembersArgs struct {
+type Z_NotifyUserArgs struct {
 	A string
-	B int
-	C int
+	B model.PluginNotification
 }
 
-type Z_GetTeamMembersReturns struct {
-	A []*model.TeamMember
-	B *model.AppError
+type Z_NotifyUserReturns struct {
+	A *model.AppError
 }
 
-func (g *apiRPCClient) GetTeamMembers(teamId string, offset, limit int) ([]*model.TeamMember, *model.AppError) {
-	_args := &Z_GetTeamMembersArgs{teamId, offset, limit}
-	_returns := &Z_GetTeamMembersReturns{}
-	if err := g.client.Call("Plugin.GetTeamMembers", _args, _returns); err != nil {
-		log.Printf("RPC call to GetTeamMembers API failed: %s", err.Error())
+func (g *apiRPCClient) NotifyUser(userId string, notification model.PluginNotification) *model.AppError {
+	_args := &Z_NotifyUserArgs{userId, notification}
+	_returns := &Z_NotifyUserReturns{}
+	if err := g.client.Call("Plugin.NotifyUser", _args, _returns); err != nil {
+		log.Printf("RPC call to NotifyUser API failed: %s", err.Error())
 	}
-	return _returns.A, _returns.B
+	return _returns.A
 }
 
-func (s *apiRPCServer) GetTeamMembers(args *Z_GetTeamMembersArgs, returns *Z_GetTeamMembersReturns) error {
+func (s *apiRPCServer) NotifyUser(args *Z_NotifyUserArgs, returns *Z_NotifyUserReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetTeamMembers(teamId string, offset, limit int) ([]*model.TeamMember, *model.AppError)
+		NotifyUser(userId string, notification model.PluginNotification) *model.AppError
 	}); ok {
-		returns.A, returns.B = hook.GetTeamMembers(args.A, args.B, args.C)
+		returns.A = hook.NotifyUser(args.A, args.B)
 	} else {
-		return fmt.Errorf("API GetTeamMembers called but not implemented.")
+		return fmt.Errorf("API NotifyUser called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetTeamMemberArgs struct {
+type Z_WouldUserBeNotifiedArgs struct {
 	A string
 	B string
+	C bool
 }
 
-type Z_GetTeamMemberReturns struct {
-	A *model.TeamMember
+type Z_WouldUserBeNotifiedReturns struct {
+	A model.NotificationPrediction
 	B *model.AppError
 }
 
-func (g *apiRPCClient) GetTeamMember(teamId, userId string) (*model.TeamMember, *model.AppError) {
-	_args := &Z_GetTeamMemberArgs{teamId, userId}
-	_returns := &Z_GetTeamMemberReturns{}
-	if err := g.client.Call("Plugin.GetTeamMember", _args, _returns); err != nil {
-		log.Printf("RPC call to GetTeamMember API failed: %s", err.Error())
+func (g *apiRPCClient) WouldUserBeNotified(userId, channelId string, mention bool) (model.NotificationPrediction, *model.AppError) {
+	_args := &Z_WouldUserBeNotifiedArgs{userId, channelId, mention}
+	_returns := &Z_WouldUserBeNotifiedReturns{}
+	if err := g.client.Call("Plugin.WouldUserBeNotified", _args, _returns); err != nil {
+		log.Printf("RPC call to WouldUserBeNotified API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) GetTeamMember(args *Z_GetTeamMemberArgs, returns *Z_GetTeamMemberReturns) error {
+func (s *apiRPCServer) WouldUserBeNotified(args *Z_WouldUserBeNotifiedArgs, returns *Z_WouldUserBeNotifiedReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetTeamMember(teamId, userId string) (*model.TeamMember, *model.AppError)
+		WouldUserBeNotified(userId, channelId string, mention bool) (model.NotificationPrediction, *model.AppError)
 	}); ok {
-		returns.A, returns.B = hook.GetTeamMember(args.A, args.B)
+		returns.A, returns.B = hook.WouldUserBeNotified(args.A, args.B, args.C)
 	} else {
-		return fmt.Errorf("API GetTeamMember called but not implemented.")
+		return fmt.Errorf("API WouldUserBeNotified called but not implemented.")
 	}
 	return nil
 }
 
-type Z_UpdateTeamMemberRolesArgs struct {
-	A string
-	B string
-	C string
+type Z_SetAnnouncementBannerArgs struct {
+	A model.PluginBanner
 }
 
-type Z_UpdateTeamMemberRolesReturns struct {
-	A *model.TeamMember
-	B *model.AppError
+type Z_SetAnnouncementBannerReturns struct {
+	A *model.AppError
 }
 
-func (g *apiRPCClient) UpdateTeamMemberRoles(teamId, userId, newRoles string) (*model.TeamMember, *model.AppError) {
-	_args := &Z_UpdateTeamMemberRolesArgs{teamId, userId, newRoles}
-	_returns := &Z_UpdateTeamMemberRolesReturns{}
-	if err := g.client.Call("Plugin.UpdateTeamMemberRoles", _args, _returns); err != nil {
-		log.Printf("RPC call to UpdateTeamMemberRoles API failed: %s", err.Error())
+func (g *apiRPCClient) SetAnnouncementBanner(banner model.PluginBanner) *model.AppError {
+	_args := &Z_SetAnnouncementBannerArgs{banner}
+	_returns := &Z_SetAnnouncementBannerReturns{}
+	if err := g.client.Call("Plugin.SetAnnouncementBanner", _args, _returns); err != nil {
+		log.Printf("RPC call to SetAnnouncementBanner API failed: %s", err.Error())
 	}
-	return _returns.A, _returns.B
+	return _returns.A
 }
 
-func (s *apiRPCServer) UpdateTeamMemberRoles(args *Z_UpdateTeamMemberRolesArgs, returns *Z_UpdateTeamMemberRolesReturns) error {
+func (s *apiRPCServer) SetAnnouncementBanner(args *Z_SetAnnouncementBannerArgs, returns *Z_SetAnnouncementBannerReturns) error {
 	if hook, ok := s.impl.(interface {
-		UpdateTeamMemberRoles(teamId, userId, newRoles string) (*model.TeamMember, *model.AppError)
+		SetAnnouncementBanner(banner model.PluginBanner) *model.AppError
 	}); ok {
-		returns.A, returns.B = hook.UpdateTeamMemberRoles(args.A, args.B, args.C)
+		returns.A = hook.SetAnnouncementBanner(args.A)
 	} else {
-		return fmt.Errorf("API UpdateTeamMemberRoles called but not implemented.")
+		return fmt.Errorf("API SetAnnouncementBanner called but not implemented.")
 	}
 	return nil
 }
 
-type Z_CreateChannelArgs struct {
-	A *model.Channel
+type Z_CreateSubscriptionArgs struct {
+	A model.PluginSubscription
 }
 
-type Z_CreateChannelReturns struct {
-	A *model.Channel
+type Z_CreateSubscriptionReturns struct {
+	A *model.PluginSubscription
 	B *model.AppError
 }
 
-func (g *apiRPCClient) CreateChannel(channel *model.Channel) (*model.Channel, *model.AppError) {
-	_args := &Z_CreateChannelArgs{channel}
-	_returns := &Z_CreateChannelReturns{}
-	if err := g.client.Call("Plugin.CreateChannel", _args, _returns); err != nil {
-		log.Printf("RPC call to CreateChannel API failed: %s", err.Error())
+func (g *apiRPCClient) CreateSubscription(subscription model.PluginSubscription) (*model.PluginSubscription, *model.AppError) {
+	_args := &Z_CreateSubscriptionArgs{subscription}
+	_returns := &Z_CreateSubscriptionReturns{}
+	if err := g.client.Call("Plugin.CreateSubscription", _args, _returns); err != nil {
+		log.Printf("RPC call to CreateSubscription API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) CreateChannel(args *Z_CreateChannelArgs, returns *Z_CreateChannelReturns) error {
+func (s *apiRPCServer) CreateSubscription(args *Z_CreateSubscriptionArgs, returns *Z_CreateSubscriptionReturns) error {
 	if hook, ok := s.impl.(interface {
-		CreateChannel(channel *model.Channel) (*model.Channel, *model.AppError)
+		CreateSubscription(subscription model.PluginSubscription) (*model.PluginSubscription, *model.AppError)
 	}); ok {
-		returns.A, returns.B = hook.CreateChannel(args.A)
+		returns.A, returns.B = hook.CreateSubscription(args.A)
 	} else {
-		return fmt.Errorf("API CreateChannel called but not implemented.")
+		return fmt.Errorf("API CreateSubscription called but not implemented.")
 	}
 	return nil
 }
 
-type Z_DeleteChannelArgs struct {
+type Z_ListSubscriptionsForChannelArgs struct {
 	A string
 }
 
-type Z_DeleteChannelReturns struct {
-	A *model.AppError
+type Z_ListSubscriptionsForChannelReturns struct {
+	A []*model.PluginSubscription
+	B *model.AppError
 }
 
-func (g *apiRPCClient) DeleteChannel(channelId string) *model.AppError {
-	_args := &Z_DeleteChannelArgs{channelId}
-	_returns := &Z_DeleteChannelReturns{}
-	if err := g.client.Call("Plugin.DeleteChannel", _args, _returns); err != nil {
-		log.Printf("RPC call to DeleteChannel API failed: %s", err.Error())
+func (g *apiRPCClient) ListSubscriptionsForChannel(channelId string) ([]*model.PluginSubscription, *model.AppError) {
+	_args := &Z_ListSubscriptionsForChannelArgs{channelId}
+	_returns := &Z_ListSubscriptionsForChannelReturns{}
+	if err := g.client.Call("Plugin.ListSubscriptionsForChannel", _args, _returns); err != nil {
+		log.Printf("RPC call to ListSubscriptionsForChannel API failed: %s", err.Error())
 	}
-	return _returns.A
+	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) DeleteChannel(args *Z_DeleteChannelArgs, returns *Z_DeleteChannelReturns) error {
+func (s *apiRPCServer) ListSubscriptionsForChannel(args *Z_ListSubscriptionsForChannelArgs, returns *Z_ListSubscriptionsForChannelReturns) error {
 	if hook, ok := s.impl.(interface {
-		DeleteChannel(channelId string) *model.AppError
+		ListSubscriptionsForChannel(channelId string) ([]*model.PluginSubscription, *model.AppError)
 	}); ok {
-		returns.A = hook.DeleteChannel(args.A)
+		returns.A, returns.B = hook.ListSubscriptionsForChannel(args.A)
 	} else {
-		return fmt.Errorf("API DeleteChannel called but not implemented.")
+		return fmt.Errorf("API ListSubscriptionsForChannel called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetPublicChannelsForTeamArgs struct {
+type Z_DeleteSubscriptionArgs struct {
 	A string
-	B int
-	C int
 }
 
-type Z_GetPublicChannelsForTeamReturns struct {
-	A *model.ChannelList
-	B *model.AppError
+type Z_DeleteSubscriptionReturns struct {
+	A *model.AppError
 }
 
-func (g *apiRPCClient) GetPublicChannelsForTeam(teamId string, offset, limit int) (*model.ChannelList, *model.AppError) {
-	_args := &Z_GetPublicChannelsForTeamArgs{teamId, offset, limit}
-	_returns := &Z_GetPublicChannelsForTeamReturns{}
-	if err := g.client.Call("Plugin.GetPublicChannelsForTeam", _args, _returns); err != nil {
-		log.Printf("RPC call to GetPublicChannelsForTeam API failed: %s", err.Error())
+func (g *apiRPCClient) DeleteSubscription(id string) *model.AppError {
+	_args := &Z_DeleteSubscriptionArgs{id}
+	_returns := &Z_DeleteSubscriptionReturns{}
+	if err := g.client.Call("Plugin.DeleteSubscription", _args, _returns); err != nil {
+		log.Printf("RPC call to DeleteSubscription API failed: %s", err.Error())
 	}
-	return _returns.A, _returns.B
+	return _returns.A
 }
 
-func (s *apiRPCServer) GetPublicChannelsForTeam(args *Z_GetPublicChannelsForTeamArgs, returns *Z_GetPublicChannelsForTeamReturns) error {
+func (s *apiRPCServer) DeleteSubscription(args *Z_DeleteSubscriptionArgs, returns *Z_DeleteSubscriptionReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetPublicChannelsForTeam(teamId string, offset, limit int) (*model.ChannelList, *model.AppError)
+		DeleteSubscription(id string) *model.AppError
 	}); ok {
-		returns.A, returns.B = hook.GetPublicChannelsForTeam(args.A, args.B, args.C)
+		returns.A = hook.DeleteSubscription(args.A)
 	} else {
-		return fmt.Errorf("API GetPublicChannelsForTeam called but not implemented.")
+		return fmt.Errorf("API DeleteSubscription called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetChannelArgs struct {
-	A string
+type Z_RegisterOAuthProviderArgs struct {
+	A model.PluginOAuthProvider
 }
 
-type Z_GetChannelReturns struct {
-	A *model.Channel
-	B *model.AppError
+type Z_RegisterOAuthProviderReturns struct {
+	A *model.AppError
 }
 
-func (g *apiRPCClient) GetChannel(channelId string) (*model.Channel, *model.AppError) {
-	_args := &Z_GetChannelArgs{channelId}
-	_returns := &Z_GetChannelReturns{}
-	if err := g.client.Call("Plugin.GetChannel", _args, _returns); err != nil {
-		log.Printf("RPC call to GetChannel API failed: %s", err.Error())
+func (g *apiRPCClient) RegisterOAuthProvider(provider model.PluginOAuthProvider) *model.AppError {
+	_args := &Z_RegisterOAuthProviderArgs{provider}
+	_returns := &Z_RegisterOAuthProviderReturns{}
+	if err := g.client.Call("Plugin.RegisterOAuthProvider", _args, _returns); err != nil {
+		log.Printf("RPC call to RegisterOAuthProvider API failed: %s", err.Error())
 	}
-	return _returns.A, _returns.B
+	return _returns.A
 }
 
-func (s *apiRPCServer) GetChannel(args *Z_GetChannelArgs, returns *Z_GetChannelReturns) error {
+func (s *apiRPCServer) RegisterOAuthProvider(args *Z_RegisterOAuthProviderArgs, returns *Z_RegisterOAuthProviderReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetChannel(channelId string) (*model.Channel, *model.AppError)
+		RegisterOAuthProvider(provider model.PluginOAuthProvider) *model.AppError
 	}); ok {
-		returns.A, returns.B = hook.GetChannel(args.A)
+		returns.A = hook.RegisterOAuthProvider(args.A)
 	} else {
-		return fmt.Errorf("API GetChannel called but not implemented.")
+		return fmt.Errorf("API RegisterOAuthProvider called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetChannelByNameArgs struct {
+type Z_GetOAuthTokenArgs struct {
 	A string
-	B string
-	C bool
 }
 
-type Z_GetChannelByNameReturns struct {
-	A *model.Channel
+type Z_GetOAuthTokenReturns struct {
+	A *model.PluginOAuthToken
 	B *model.AppError
 }
 
-func (g *apiRPCClient) GetChannelByName(teamId, name string, includeDeleted bool) (*model.Channel, *model.AppError) {
-	_args := &Z_GetChannelByNameArgs{teamId, name, includeDeleted}
-	_returns := &Z_GetChannelByNameReturns{}
-	if err := g.client.Call("Plugin.GetChannelByName", _args, _returns); err != nil {
-		log.Printf("RPC call to GetChannelByName API failed: %s", err.Error())
+func (g *apiRPCClient) GetOAuthToken(userId string) (*model.PluginOAuthToken, *model.AppError) {
+	_args := &Z_GetOAuthTokenArgs{userId}
+	_returns := &Z_GetOAuthTokenReturns{}
+	if err := g.client.Call("Plugin.GetOAuthToken", _args, _returns); err != nil {
+		log.Printf("RPC call to GetOAuthToken API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) GetChannelByName(args *Z_GetChannelByNameArgs, returns *Z_GetChannelByNameReturns) error {
+func (s *apiRPCServer) GetOAuthToken(args *Z_GetOAuthTokenArgs, returns *Z_GetOAuthTokenReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetChannelByName(teamId, name string, includeDeleted bool) (*model.Channel, *model.AppError)
+		GetOAuthToken(userId string) (*model.PluginOAuthToken, *model.AppError)
 	}); ok {
-		returns.A, returns.B = hook.GetChannelByName(args.A, args.B, args.C)
+		returns.A, returns.B = hook.GetOAuthToken(args.A)
 	} else {
-		return fmt.Errorf("API GetChannelByName called but not implemented.")
+		return fmt.Errorf("API GetOAuthToken called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetChannelByNameForTeamNameArgs struct {
-	A string
-	B string
-	C bool
+type Z_RequestAdminActionArgs struct {
+	A model.PluginAdminActionRequest
 }
 
-type Z_GetChannelByNameForTeamNameReturns struct {
-	A *model.Channel
+type Z_RequestAdminActionReturns struct {
+	A *model.PluginAdminActionRequest
 	B *model.AppError
 }
 
-func (g *apiRPCClient) GetChannelByNameForTeamName(teamName, channelName string, includeDeleted bool) (*model.Channel, *model.AppError) {
-	_args := &Z_GetChannelByNameForTeamNameArgs{teamName, channelName, includeDeleted}
-	_returns := &Z_GetChannelByNameForTeamNameReturns{}
-	if err := g.client.Call("Plugin.GetChannelByNameForTeamName", _args, _returns); err != nil {
-		log.Printf("RPC call to GetChannelByNameForTeamName API failed: %s", err.Error())
+func (g *apiRPCClient) RequestAdminAction(action model.PluginAdminActionRequest) (*model.PluginAdminActionRequest, *model.AppError) {
+	_args := &Z_RequestAdminActionArgs{action}
+	_returns := &Z_RequestAdminActionReturns{}
+	if err := g.client.Call("Plugin.RequestAdminAction", _args, _returns); err != nil {
+		log.Printf("RPC call to RequestAdminAction API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) GetChannelByNameForTeamName(args *Z_GetChannelByNameForTeamNameArgs, returns *Z_GetChannelByNameForTeamNameReturns) error {
+func (s *apiRPCServer) RequestAdminAction(args *Z_RequestAdminActionArgs, returns *Z_RequestAdminActionReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetChannelByNameForTeamName(teamName, channelName string, includeDeleted bool) (*model.Channel, *model.AppError)
+		RequestAdminAction(action model.PluginAdminActionRequest) (*model.PluginAdminActionRequest, *model.AppError)
 	}); ok {
-		returns.A, returns.B = hook.GetChannelByNameForTeamName(args.A, args.B, args.C)
+		returns.A, returns.B = hook.RequestAdminAction(args.A)
 	} else {
-		return fmt.Errorf("API GetChannelByNameForTeamName called but not implemented.")
+		return fmt.Errorf("API RequestAdminAction called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetDirectChannelArgs struct {
+type Z_KVSetWithExpiryArgs struct {
 	A string
-	B string
+	B []byte
+	C int64
 }
 
-type Z_GetDirectChannelReturns struct {
-	A *model.Channel
-	B *model.AppError
+type Z_KVSetWithExpiryReturns struct {
+	A *model.AppError
 }
 
-func (g *apiRPCClient) GetDirectChannel(userId1, userId2 string) (*model.Channel, *model.AppError) {
-	_args := &Z_GetDirectChannelArgs{userId1, userId2}
-	_returns := &Z_GetDirectChannelReturns{}
-	if err := g.client.Call("Plugin.GetDirectChannel", _args, _returns); err != nil {
-		log.Printf("RPC call to GetDirectChannel API failed: %s", err.Error())
+func (g *apiRPCClient) KVSetWithExpiry(key string, value []byte, expireInSeconds int64) *model.AppError {
+	_args := &Z_KVSetWithExpiryArgs{key, value, expireInSeconds}
+	_returns := &Z_KVSetWithExpiryReturns{}
+	if err := g.client.Call("Plugin.KVSetWithExpiry", _args, _returns); err != nil {
+		log.Printf("RPC call to KVSetWithExpiry API failed: %s", err.Error())
 	}
-	return _returns.A, _returns.B
+	return _returns.A
 }
 
-func (s *apiRPCServer) GetDirectChannel(args *Z_GetDirectChannelArgs, returns *Z_GetDirectChannelReturns) error {
+func (s *apiRPCServer) KVSetWithExpiry(args *Z_KVSetWithExpiryArgs, returns *Z_KVSetWithExpiryReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetDirectChannel(userId1, userId2 string) (*model.Channel, *model.AppError)
+		KVSetWithExpiry(key string, value []byte, expireInSeconds int64) *model.AppError
 	}); ok {
-		returns.A, returns.B = hook.GetDirectChannel(args.A, args.B)
+		returns.A = hook.KVSetWithExpiry(args.A, args.B, args.C)
 	} else {
-		return fmt.Errorf("API GetDirectChannel called but not implemented.")
+		return fmt.Errorf("API KVSetWithExpiry called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetGroupChannelArgs struct {
-	A []string
+type Z_KVSetMultipleArgs struct {
+	A map[string][]byte
 }
 
-type Z_GetGroupChannelReturns struct {
-	A *model.Channel
-	B *model.AppError
+type Z_KVSetMultipleReturns struct {
+	A *model.AppError
 }
 
-func (g *apiRPCClient) GetGroupChannel(userIds []string) (*model.Channel, *model.AppError) {
-	_args := &Z_GetGroupChannelArgs{userIds}
-	_returns := &Z_GetGroupChannelReturns{}
-	if err := g.client.Call("Plugin.GetGroupChannel", _args, _returns); err != nil {
-		log.Printf("RPC call to GetGroupChannel API failed: %s", err.Error())
+func (g *apiRPCClient) KVSetMultiple(kvs map[string][]byte) *model.AppError {
+	_args := &Z_KVSetMultipleArgs{kvs}
+	_returns := &Z_KVSetMultipleReturns{}
+	if err := g.client.Call("Plugin.KVSetMultiple", _args, _returns); err != nil {
+		log.Printf("RPC call to KVSetMultiple API failed: %s", err.Error())
 	}
-	return _returns.A, _returns.B
+	return _returns.A
 }
 
-func (s *apiRPCServer) GetGroupChannel(args *Z_GetGroupChannelArgs, returns *Z_GetGroupChannelReturns) error {
+func (s *apiRPCServer) KVSetMultiple(args *Z_KVSetMultipleArgs, returns *Z_KVSetMultipleReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetGroupChannel(userIds []string) (*model.Channel, *model.AppError)
+		KVSetMultiple(kvs map[string][]byte) *model.AppError
 	}); ok {
-		returns.A, returns.B = hook.GetGroupChannel(args.A)
+		returns.A = hook.KVSetMultiple(args.A)
 	} else {
-		return fmt.Errorf("API GetGroupChannel called but not implemented.")
+		return fmt.Errorf("API KVSetMultiple called but not implemented.")
 	}
 	return nil
 }
 
-type Z_UpdateChannelArgs struct {
-	A *model.Channel
+type Z_KVSetAtomicArgs struct {
+	A []model.PluginKVOp
 }
 
-type Z_UpdateChannelReturns struct {
-	A *model.Channel
-	B *model.AppError
+type Z_KVSetAtomicReturns struct {
+	A *model.AppError
 }
 
-func (g *apiRPCClient) UpdateChannel(channel *model.Channel) (*model.Channel, *model.AppError) {
-	_args := &Z_UpdateChannelArgs{channel}
-	_returns := &Z_UpdateChannelReturns{}
-	if err := g.client.Call("Plugin.UpdateChannel", _args, _returns); err != nil {
-		log.Printf("RPC call to UpdateChannel API failed: %s", err.Error())
+func (g *apiRPCClient) KVSetAtomic(ops []model.PluginKVOp) *model.AppError {
+	_args := &Z_KVSetAtomicArgs{ops}
+	_returns := &Z_KVSetAtomicReturns{}
+	if err := g.client.Call("Plugin.KVSetAtomic", _args, _returns); err != nil {
+		log.Printf("RPC call to KVSetAtomic API failed: %s", err.Error())
 	}
-	return _returns.A, _returns.B
+	return _returns.A
 }
 
-func (s *apiRPCServer) UpdateChannel(args *Z_UpdateChannelArgs, returns *Z_UpdateChannelReturns) error {
+func (s *apiRPCServer) KVSetAtomic(args *Z_KVSetAtomicArgs, returns *Z_KVSetAtomicReturns) error {
 	if hook, ok := s.impl.(interface {
-		UpdateChannel(channel *model.Channel) (*model.Channel, *model.AppError)
+		KVSetAtomic(ops []model.PluginKVOp) *model.AppError
 	}); ok {
-		returns.A, returns.B = hook.UpdateChannel(args.A)
+		returns.A = hook.KVSetAtomic(args.A)
 	} else {
-		return fmt.Errorf("API UpdateChannel called but not implemented.")
+		return fmt.Errorf("API KVSetAtomic called but not implemented.")
 	}
 	return nil
 }
 
-type Z_AddChannelMemberArgs struct {
+type Z_KVCompareAndSetArgs struct {
 	A string
-	B string
+	B []byte
+	C []byte
 }
 
-type Z_AddChannelMemberReturns struct {
-	A *model.ChannelMember
+type Z_KVCompareAndSetReturns struct {
+	A bool
 	B *model.AppError
 }
 
-func (g *apiRPCClient) AddChannelMember(channelId, userId string) (*model.ChannelMember, *model.AppError) {
-	_args := &Z_AddChannelMemberArgs{channelId, userId}
-	_returns := &Z_AddChannelMemberReturns{}
-	if err := g.client.Call("Plugin.AddChannelMember", _args, _returns); err != nil {
-		log.Printf("RPC call to AddChannelMember API failed: %s", err.Error())
+func (g *apiRPCClient) KVCompareAndSet(key string, oldValue, newValue []byte) (bool, *model.AppError) {
+	_args := &Z_KVCompareAndSetArgs{key, oldValue, newValue}
+	_returns := &Z_KVCompareAndSetReturns{}
+	if err := g.client.Call("Plugin.KVCompareAndSet", _args, _returns); err != nil {
+		log.Printf("RPC call to KVCompareAndSet API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) AddChannelMember(args *Z_AddChannelMemberArgs, returns *Z_AddChannelMemberReturns) error {
+func (s *apiRPCServer) KVCompareAndSet(args *Z_KVCompareAndSetArgs, returns *Z_KVCompareAndSetReturns) error {
 	if hook, ok := s.impl.(interface {
-		AddChannelMember(channelId, userId string) (*model.ChannelMember, *model.AppError)
+		KVCompareAndSet(key string, oldValue, newValue []byte) (bool, *model.AppError)
 	}); ok {
-		returns.A, returns.B = hook.AddChannelMember(args.A, args.B)
+		returns.A, returns.B = hook.KVCompareAndSet(args.A, args.B, args.C)
 	} else {
-		return fmt.Errorf("API AddChannelMember called but not implemented.")
+		return fmt.Errorf("API KVCompareAndSet called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetChannelMemberArgs struct {
+type Z_KVIncrementArgs struct {
 	A string
-	B string
+	B int64
 }
 
-type Z_GetChannelMemberReturns struct {
-	A *model.ChannelMember
+type Z_KVIncrementReturns struct {
+	A int64
 	B *model.AppError
 }
 
-func (g *apiRPCClient) GetChannelMember(channelId, userId string) (*model.ChannelMember, *model.AppError) {
-	_args := &Z_GetChannelMemberArgs{channelId, userId}
-	_returns := &Z_GetChannelMemberReturns{}
-	if err := g.client.Call("Plugin.GetChannelMember", _args, _returns); err != nil {
-		log.Printf("RPC call to GetChannelMember API failed: %s", err.Error())
+func (g *apiRPCClient) KVIncrement(key string, delta int64) (int64, *model.AppError) {
+	_args := &Z_KVIncrementArgs{key, delta}
+	_returns := &Z_KVIncrementReturns{}
+	if err := g.client.Call("Plugin.KVIncrement", _args, _returns); err != nil {
+		log.Printf("RPC call to KVIncrement API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) GetChannelMember(args *Z_GetChannelMemberArgs, returns *Z_GetChannelMemberReturns) error {
+func (s *apiRPCServer) KVIncrement(args *Z_KVIncrementArgs, returns *Z_KVIncrementReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetChannelMember(channelId, userId string) (*model.ChannelMember, *model.AppError)
+		KVIncrement(key string, delta int64) (int64, *model.AppError)
 	}); ok {
-		returns.A, returns.B = hook.GetChannelMember(args.A, args.B)
+		returns.A, returns.B = hook.KVIncrement(args.A, args.B)
 	} else {
-		return fmt.Errorf("API GetChannelMember called but not implemented.")
+		return fmt.Errorf("API KVIncrement called but not implemented.")
 	}
 	return nil
 }
 
-type Z_UpdateChannelMemberRolesArgs struct {
-	A string
-	B string
-	C string
+type Z_KVGetMultipleArgs struct {
+	A []string
 }
 
-type Z_UpdateChannelMemberRolesReturns struct {
-	A *model.ChannelMember
+type Z_KVGetMultipleReturns struct {
+	A map[string][]byte
 	B *model.AppError
 }
 
-func (g *apiRPCClient) UpdateChannelMemberRoles(channelId, userId, newRoles string) (*model.ChannelMember, *model.AppError) {
-	_args := &Z_UpdateChannelMemberRolesArgs{channelId, userId, newRoles}
-	_returns := &Z_UpdateChannelMemberRolesReturns{}
-	if err := g.client.Call("Plugin.UpdateChannelMemberRoles", _args, _returns); err != nil {
-		log.Printf("RPC call to UpdateChannelMemberRoles API failed: %s", err.Error())
+func (g *apiRPCClient) KVGetMultiple(keys []string) (map[string][]byte, *model.AppError) {
+	_args := &Z_KVGetMultipleArgs{keys}
+	_returns := &Z_KVGetMultipleReturns{}
+	if err := g.client.Call("Plugin.KVGetMultiple", _args, _returns); err != nil {
+		log.Printf("RPC call to KVGetMultiple API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) UpdateChannelMemberRoles(args *Z_UpdateChannelMemberRolesArgs, returns *Z_UpdateChannelMemberRolesReturns) error {
+func (s *apiRPCServer) KVGetMultiple(args *Z_KVGetMultipleArgs, returns *Z_KVGetMultipleReturns) error {
 	if hook, ok := s.impl.(interface {
-		UpdateChannelMemberRoles(channelId, userId, newRoles string) (*model.ChannelMember, *model.AppError)
+		KVGetMultiple(keys []string) (map[string][]byte, *model.AppError)
 	}); ok {
-		returns.A, returns.B = hook.UpdateChannelMemberRoles(args.A, args.B, args.C)
+		returns.A, returns.B = hook.KVGetMultiple(args.A)
 	} else {
-		return fmt.Errorf("API UpdateChannelMemberRoles called but not implemented.")
+		return fmt.Errorf("API KVGetMultiple called but not implemented.")
 	}
 	return nil
 }
 
-type Z_UpdateChannelMemberNotificationsArgs struct {
-	A string
-	B string
-	C map[string]string
+type Z_KVListArgs struct {
+	A int
+	B int
 }
 
-type Z_UpdateChannelMemberNotificationsReturns struct {
-	A *model.ChannelMember
+type Z_KVListReturns struct {
+	A []string
 	B *model.AppError
 }
 
-func (g *apiRPCClient) UpdateChannelMemberNotifications(channelId, userId string, notifications map[string]string) (*model.ChannelMember, *model.AppError) {
-	_args := &Z_UpdateChannelMemberNotificationsArgs{channelId, userId, notifications}
-	_returns := &Z_UpdateChannelMemberNotificationsReturns{}
-	if err := g.client.Call("Plugin.UpdateChannelMemberNotifications", _args, _returns); err != nil {
-		log.Printf("RPC call to UpdateChannelMemberNotifications API failed: %s", err.Error())
+func (g *apiRPCClient) KVList(page, perPage int) ([]string, *model.AppError) {
+	_args := &Z_KVListArgs{page, perPage}
+	_returns := &Z_KVListReturns{}
+	if err := g.client.Call("Plugin.KVList", _args, _returns); err != nil {
+		log.Printf("RPC call to KVList API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) UpdateChannelMemberNotifications(args *Z_UpdateChannelMemberNotificationsArgs, returns *Z_UpdateChannelMemberNotificationsReturns) error {
+func (s *apiRPCServer) KVList(args *Z_KVListArgs, returns *Z_KVListReturns) error {
 	if hook, ok := s.impl.(interface {
-		UpdateChannelMemberNotifications(channelId, userId string, notifications map[string]string) (*model.ChannelMember, *model.AppError)
+		KVList(page, perPage int) ([]string, *model.AppError)
 	}); ok {
-		returns.A, returns.B = hook.UpdateChannelMemberNotifications(args.A, args.B, args.C)
+		returns.A, returns.B = hook.KVList(args.A, args.B)
 	} else {
-		return fmt.Errorf("API UpdateChannelMemberNotifications called but not implemented.")
+		return fmt.Errorf("API KVList called but not implemented.")
 	}
 	return nil
 }
 
-type Z_DeleteChannelMemberArgs struct {
+type Z_KVListWithPrefixArgs struct {
 	A string
-	B string
+	B int
+	C int
 }
 
-type Z_DeleteChannelMemberReturns struct {
-	A *model.AppError
+type Z_KVListWithPrefixReturns struct {
+	A []string
+	B *model.AppError
 }
 
-func (g *apiRPCClient) DeleteChannelMember(channelId, userId string) *model.AppError {
-	_args := &Z_DeleteChannelMemberArgs{channelId, userId}
-	_returns := &Z_DeleteChannelMemberReturns{}
-	if err := g.client.Call("Plugin.DeleteChannelMember", _args, _returns); err != nil {
-		log.Printf("RPC call to DeleteChannelMember API failed: %s", err.Error())
+func (g *apiRPCClient) KVListWithPrefix(prefix string, page, perPage int) ([]string, *model.AppError) {
+	_args := &Z_KVListWithPrefixArgs{prefix, page, perPage}
+	_returns := &Z_KVListWithPrefixReturns{}
+	if err := g.client.Call("Plugin.KVListWithPrefix", _args, _returns); err != nil {
+		log.Printf("RPC call to KVListWithPrefix API failed: %s", err.Error())
 	}
-	return _returns.A
+	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) DeleteChannelMember(args *Z_DeleteChannelMemberArgs, returns *Z_DeleteChannelMemberReturns) error {
+func (s *apiRPCServer) KVListWithPrefix(args *Z_KVListWithPrefixArgs, returns *Z_KVListWithPrefixReturns) error {
 	if hook, ok := s.impl.(interface {
-		DeleteChannelMember(channelId, userId string) *model.AppError
+		KVListWithPrefix(prefix string, page, perPage int) ([]string, *model.AppError)
 	}); ok {
-		returns.A = hook.DeleteChannelMember(args.A, args.B)
+		returns.A, returns.B = hook.KVListWithPrefix(args.A, args.B, args.C)
 	} else {
-		return fmt.Errorf("API DeleteChannelMember called but not implemented.")
+		return fmt.Errorf("API KVListWithPrefix called but not implemented.")
 	}
 	return nil
 }
 
-type Z_CreatePostArgs struct {
-	A *model.Post
+type Z_KVDeleteArgs struct {
+	A string
 }
 
-type Z_CreatePostReturns struct {
-	A *model.Post
-	B *model.AppError
+type Z_KVDeleteReturns struct {
+	A *model.AppError
 }
 
-func (g *apiRPCClient) CreatePost(post *model.Post) (*model.Post, *model.AppError) {
-	_args := &Z_CreatePostArgs{post}
-	_returns := &Z_CreatePostReturns{}
-	if err := g.client.Call("Plugin.CreatePost", _args, _returns); err != nil {
-		log.Printf("RPC call to CreatePost API failed: %s", err.Error())
+func (g *apiRPCClient) KVDelete(key string) *model.AppError {
+	_args := &Z_KVDeleteArgs{key}
+	_returns := &Z_KVDeleteReturns{}
+	if err := g.client.Call("Plugin.KVDelete", _args, _returns); err != nil {
+		log.Printf("RPC call to KVDelete API failed: %s", err.Error())
 	}
-	return _returns.A, _returns.B
+	return _returns.A
 }
 
-func (s *apiRPCServer) CreatePost(args *Z_CreatePostArgs, returns *Z_CreatePostReturns) error {
+func (s *apiRPCServer) KVDelete(args *Z_KVDeleteArgs, returns *Z_KVDeleteReturns) error {
 	if hook, ok := s.impl.(interface {
-		CreatePost(post *model.Post) (*model.Post, *model.AppError)
+		KVDelete(key string) *model.AppError
 	}); ok {
-		returns.A, returns.B = hook.CreatePost(args.A)
+		returns.A = hook.KVDelete(args.A)
 	} else {
-		return fmt.Errorf("API CreatePost called but not implemented.")
+		return fmt.Errorf("API KVDelete called but not implemented.")
 	}
 	return nil
 }
 
-type Z_SendEphemeralPostArgs struct {
-	A string
-	B *model.Post
+type Z_KVDeleteAllArgs struct {
 }
 
-type Z_SendEphemeralPostReturns struct {
-	A *model.Post
+type Z_KVDeleteAllReturns struct {
+	A *model.AppError
 }
 
-func (g *apiRPCClient) SendEphemeralPost(userId string, post *model.Post) *model.Post {
-	_args := &Z_SendEphemeralPostArgs{userId, post}
-	_returns := &Z_SendEphemeralPostReturns{}
-	if err := g.client.Call("Plugin.SendEphemeralPost", _args, _returns); err != nil {
-		log.Printf("RPC call to SendEphemeralPost API failed: %s", err.Error())
+func (g *apiRPCClient) KVDeleteAll() *model.AppError {
+	_args := &Z_KVDeleteAllArgs{}
+	_returns := &Z_KVDeleteAllReturns{}
+	if err := g.client.Call("Plugin.KVDeleteAll", _args, _returns); err != nil {
+		log.Printf("RPC call to KVDeleteAll API failed: %s", err.Error())
 	}
 	return _returns.A
 }
 
-func (s *apiRPCServer) SendEphemeralPost(args *Z_SendEphemeralPostArgs, returns *Z_SendEphemeralPostReturns) error {
+func (s *apiRPCServer) KVDeleteAll(args *Z_KVDeleteAllArgs, returns *Z_KVDeleteAllReturns) error {
 	if hook, ok := s.impl.(interface {
-		SendEphemeralPost(userId string, post *model.Post) *model.Post
+		KVDeleteAll() *model.AppError
 	}); ok {
-		returns.A = hook.SendEphemeralPost(args.A, args.B)
+		returns.A = hook.KVDeleteAll()
 	} else {
-		return fmt.Errorf("API SendEphemeralPost called but not implemented.")
+		return fmt.Errorf("API KVDeleteAll called but not implemented.")
 	}
 	return nil
 }
 
-type Z_DeletePostArgs struct {
+type Z_KVCompareAndDeleteArgs struct {
 	A string
+	B []byte
 }
 
-type Z_DeletePostReturns struct {
-	A *model.AppError
+type Z_KVCompareAndDeleteReturns struct {
+	A bool
+	B *model.AppError
 }
 
-func (g *apiRPCClient) DeletePost(postId string) *model.AppError {
-	_args := &Z_DeletePostArgs{postId}
-	_returns := &Z_DeletePostReturns{}
-	if err := g.client.Call("Plugin.DeletePost", _args, _returns); err != nil {
-		log.Printf("RPC call to DeletePost API failed: %s", err.Error())
+func (g *apiRPCClient) KVCompareAndDelete(key string, oldValue []byte) (bool, *model.AppError) {
+	_args := &Z_KVCompareAndDeleteArgs{key, oldValue}
+	_returns := &Z_KVCompareAndDeleteReturns{}
+	if err := g.client.Call("Plugin.KVCompareAndDelete", _args, _returns); err != nil {
+		log.Printf("RPC call to KVCompareAndDelete API failed: %s", err.Error())
 	}
-	return _returns.A
+	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) DeletePost(args *Z_DeletePostArgs, returns *Z_DeletePostReturns) error {
+func (s *apiRPCServer) KVCompareAndDelete(args *Z_KVCompareAndDeleteArgs, returns *Z_KVCompareAndDeleteReturns) error {
 	if hook, ok := s.impl.(interface {
-		DeletePost(postId string) *model.AppError
+		KVCompareAndDelete(key string, oldValue []byte) (bool, *model.AppError)
 	}); ok {
-		returns.A = hook.DeletePost(args.A)
+		returns.A, returns.B = hook.KVCompareAndDelete(args.A, args.B)
 	} else {
-		return fmt.Errorf("API DeletePost called but not implemented.")
+		return fmt.Errorf("API KVCompareAndDelete called but not implemented.")
 	}
 	return nil
 }
 
-type Z_GetPostArgs struct {
+type Z_KVLockArgs struct {
 	A string
+	B time.Duration
 }
 
-type Z_GetPostReturns struct {
-	A *model.Post
+type Z_KVLockReturns struct {
+	A string
 	B *model.AppError
 }
 
-func (g *apiRPCClient) GetPost(postId string) (*model.Post, *model.AppError) {
-	_args := &Z_GetPostArgs{postId}
-	_returns := &Z_GetPostReturns{}
-	if err := g.client.Call("Plugin.GetPost", _args, _returns); err != nil {
-		log.Printf("RPC call to GetPost API failed: %s", err.Error())
+func (g *apiRPCClient) KVLock(key string, ttl time.Duration) (string, *model.AppError) {
+	_args := &Z_KVLockArgs{key, ttl}
+	_returns := &Z_KVLockReturns{}
+	if err := g.client.Call("Plugin.KVLock", _args, _returns); err != nil {
+		log.Printf("RPC call to KVLock API failed: %s", err.Error())
 	}
 	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) GetPost(args *Z_GetPostArgs, returns *Z_GetPostReturns) error {
+func (s *apiRPCServer) KVLock(args *Z_KVLockArgs, returns *Z_KVLockReturns) error {
 	if hook, ok := s.impl.(interface {
-		GetPost(postId string) (*model.Post, *model.AppError)
+		KVLock(key string, ttl time.Duration) (string, *model.AppError)
 	}); ok {
-		returns.A, returns.B = hook.GetPost(args.A)
+		returns.A, returns.B = hook.KVLock(args.A, args.B)
 	} else {
-		return fmt.Errorf("API GetPost called but not implemented.")
+		return fmt.Errorf("API KVLock called but not implemented.")
 	}
 	return nil
 }
 
-type Z_UpdatePostArgs struct {
-	A *model.Post
+type Z_KVUnlockArgs struct {
+	A string
+	B string
 }
 
-type Z_UpdatePostReturns struct {
-	A *model.Post
-	B *model.AppError
+type Z_KVUnlockReturns struct {
+	A *model.AppError
 }
 
-func (g *apiRPCClient) UpdatePost(post *model.Post) (*model.Post, *model.AppError) {
-	_args := &Z_UpdatePostArgs{post}
-	_returns := &Z_UpdatePostReturns{}
-	if err := g.client.Call("Plugin.UpdatePost", _args, _returns); err != nil {
-		log.Printf("RPC call to UpdatePost API failed: %s", err.Error())
+func (g *apiRPCClient) KVUnlock(key, token string) *model.AppError {
+	_args := &Z_KVUnlockArgs{key, token}
+	_returns := &Z_KVUnlockReturns{}
+	if err := g.client.Call("Plugin.KVUnlock", _args, _returns); err != nil {
+		log.Printf("RPC call to KVUnlock API failed: %s", err.Error())
 	}
-	return _returns.A, _returns.B
+	return _returns.A
 }
 
-func (s *apiRPCServer) UpdatePost(args *Z_UpdatePostArgs, returns *Z_UpdatePostReturns) error {
+func (s *apiRPCServer) KVUnlock(args *Z_KVUnlockArgs, returns *Z_KVUnlockReturns) error {
 	if hook, ok := s.impl.(interface {
-		UpdatePost(post *model.Post) (*model.Post, *model.AppError)
+		KVUnlock(key, token string) *model.AppError
 	}); ok {
-		returns.A, returns.B = hook.UpdatePost(args.A)
+		returns.A = hook.KVUnlock(args.A, args.B)
 	} else {
-		return fmt.Errorf("API UpdatePost called but not implemented.")
+		return fmt.Errorf("API KVUnlock called but not implemented.")
 	}
 	return nil
 }
 
-type Z_KVSetArgs struct {
-	A string
-	B []byte
+type Z_GetServerHealthArgs struct {
 }
 
-type Z_KVSetReturns struct {
-	A *model.AppError
+type Z_GetServerHealthReturns struct {
+	A model.PluginServerHealth
 }
 
-func (g *apiRPCClient) KVSet(key string, value []byte) *model.AppError {
-	_args := &Z_KVSetArgs{key, value}
-	_returns := &Z_KVSetReturns{}
-	if err := g.client.Call("Plugin.KVSet", _args, _returns); err != nil {
-		log.Printf("RPC call to KVSet API failed: %s", err.Error())
+func (g *apiRPCClient) GetServerHealth() model.PluginServerHealth {
+	_args := &Z_GetServerHealthArgs{}
+	_returns := &Z_GetServerHealthReturns{}
+	if err := g.client.Call("Plugin.GetServerHealth", _args, _returns); err != nil {
+		log.Printf("RPC call to GetServerHealth API failed: %s", err.Error())
 	}
 	return _returns.A
 }
 
-func (s *apiRPCServer) KVSet(args *Z_KVSetArgs, returns *Z_KVSetReturns) error {
+func (s *apiRPCServer) GetServerHealth(args *Z_GetServerHealthArgs, returns *Z_GetServerHealthReturns) error {
 	if hook, ok := s.impl.(interface {
-		KVSet(key string, value []byte) *model.AppError
+		GetServerHealth() model.PluginServerHealth
 	}); ok {
-		returns.A = hook.KVSet(args.A, args.B)
+		returns.A = hook.GetServerHealth()
 	} else {
-		return fmt.Errorf("API KVSet called but not implemented.")
+		return fmt.Errorf("API GetServerHealth called but not implemented.")
 	}
 	return nil
 }
 
-type Z_KVGetArgs struct {
-	A string
+type Z_IsHighAvailabilityArgs struct {
 }
 
-type Z_KVGetReturns struct {
-	A []byte
-	B *model.AppError
+type Z_IsHighAvailabilityReturns struct {
+	A bool
 }
 
-func (g *apiRPCClient) KVGet(key string) ([]byte, *model.AppError) {
-	_args := &Z_KVGetArgs{key}
-	_returns := &Z_KVGetReturns{}
-	if err := g.client.Call("Plugin.KVGet", _args, _returns); err != nil {
-		log.Printf("RPC call to KVGet API failed: %s", err.Error())
+func (g *apiRPCClient) IsHighAvailability() bool {
+	_args := &Z_IsHighAvailabilityArgs{}
+	_returns := &Z_IsHighAvailabilityReturns{}
+	if err := g.client.Call("Plugin.IsHighAvailability", _args, _returns); err != nil {
+		log.Printf("RPC call to IsHighAvailability API failed: %s", err.Error())
 	}
-	return _returns.A, _returns.B
+	return _returns.A
 }
 
-func (s *apiRPCServer) KVGet(args *Z_KVGetArgs, returns *Z_KVGetReturns) error {
+func (s *apiRPCServer) IsHighAvailability(args *Z_IsHighAvailabilityArgs, returns *Z_IsHighAvailabilityReturns) error {
 	if hook, ok := s.impl.(interface {
-		KVGet(key string) ([]byte, *model.AppError)
+		IsHighAvailability() bool
 	}); ok {
-		returns.A, returns.B = hook.KVGet(args.A)
+		returns.A = hook.IsHighAvailability()
 	} else {
-		return fmt.Errorf("API KVGet called but not implemented.")
+		return fmt.Errorf("API IsHighAvailability called but not implemented.")
 	}
 	return nil
 }
 
-type Z_KVDeleteArgs struct {
+type Z_KVSetAndNotifyArgs struct {
 	A string
+	B []byte
+	C string
+	D map[string]interface{}
+	E *model.WebsocketBroadcast
 }
 
-type Z_KVDeleteReturns struct {
+type Z_KVSetAndNotifyReturns struct {
 	A *model.AppError
 }
 
-func (g *apiRPCClient) KVDelete(key string) *model.AppError {
-	_args := &Z_KVDeleteArgs{key}
-	_returns := &Z_KVDeleteReturns{}
-	if err := g.client.Call("Plugin.KVDelete", _args, _returns); err != nil {
-		log.Printf("RPC call to KVDelete API failed: %s", err.Error())
+func (g *apiRPCClient) KVSetAndNotify(key string, value []byte, event string, payload map[string]interface{}, broadcast *model.WebsocketBroadcast) *model.AppError {
+	_args := &Z_KVSetAndNotifyArgs{key, value, event, payload, broadcast}
+	_returns := &Z_KVSetAndNotifyReturns{}
+	if err := g.client.Call("Plugin.KVSetAndNotify", _args, _returns); err != nil {
+		log.Printf("RPC call to KVSetAndNotify API failed: %s", err.Error())
 	}
 	return _returns.A
 }
 
-func (s *apiRPCServer) KVDelete(args *Z_KVDeleteArgs, returns *Z_KVDeleteReturns) error {
+func (s *apiRPCServer) KVSetAndNotify(args *Z_KVSetAndNotifyArgs, returns *Z_KVSetAndNotifyReturns) error {
 	if hook, ok := s.impl.(interface {
-		KVDelete(key string) *model.AppError
+		KVSetAndNotify(key string, value []byte, event string, payload map[string]interface{}, broadcast *model.WebsocketBroadcast) *model.AppError
 	}); ok {
-		returns.A = hook.KVDelete(args.A)
+		returns.A = hook.KVSetAndNotify(args.A, args.B, args.C, args.D, args.E)
 	} else {
-		return fmt.Errorf("API KVDelete called but not implemented.")
+		return fmt.Errorf("API KVSetAndNotify called but not implemented.")
 	}
 	return nil
 }
@@ -1906,114 +3943,146 @@ func (s *apiRPCServer) PublishWebSocketEvent(args *Z_PublishWebSocketEventArgs,
 	return nil
 }
 
-type Z_LogDebugArgs struct {
+type Z_WebSocketBroadcastToConnectionArgs struct {
 	A string
-	B []interface{}
+	B *model.WebSocketEvent
 }
 
-type Z_LogDebugReturns struct {
+type Z_WebSocketBroadcastToConnectionReturns struct {
 }
 
-func (g *apiRPCClient) LogDebug(msg string, keyValuePairs ...interface{}) {
-	_args := &Z_LogDebugArgs{msg, keyValuePairs}
-	_returns := &Z_LogDebugReturns{}
-	if err := g.client.Call("Plugin.LogDebug", _args, _returns); err != nil {
-		log.Printf("RPC call to LogDebug API failed: %s", err.Error())
+func (g *apiRPCClient) WebSocketBroadcastToConnection(webConnID string, event *model.WebSocketEvent) {
+	_args := &Z_WebSocketBroadcastToConnectionArgs{webConnID, event}
+	_returns := &Z_WebSocketBroadcastToConnectionReturns{}
+	if err := g.client.Call("Plugin.WebSocketBroadcastToConnection", _args, _returns); err != nil {
+		log.Printf("RPC call to WebSocketBroadcastToConnection API failed: %s", err.Error())
 	}
 	return
 }
 
-func (s *apiRPCServer) LogDebug(args *Z_LogDebugArgs, returns *Z_LogDebugReturns) error {
+func (s *apiRPCServer) WebSocketBroadcastToConnection(args *Z_WebSocketBroadcastToConnectionArgs, returns *Z_WebSocketBroadcastToConnectionReturns) error {
 	if hook, ok := s.impl.(interface {
-		LogDebug(msg string, keyValuePairs ...interface{})
+		WebSocketBroadcastToConnection(webConnID string, event *model.WebSocketEvent)
 	}); ok {
-		hook.LogDebug(args.A, args.B...)
+		hook.WebSocketBroadcastToConnection(args.A, args.B)
 	} else {
-		return fmt.Errorf("API LogDebug called but not implemented.")
+		return fmt.Errorf("API WebSocketBroadcastToConnection called but not implemented.")
 	}
 	return nil
 }
 
-type Z_LogInfoArgs struct {
+type Z_SendToUserWebappArgs struct {
 	A string
-	B []interface{}
+	B model.PluginWebappMessage
 }
 
-type Z_LogInfoReturns struct {
+type Z_SendToUserWebappReturns struct {
+	A string
+	B *model.AppError
 }
 
-func (g *apiRPCClient) LogInfo(msg string, keyValuePairs ...interface{}) {
-	_args := &Z_LogInfoArgs{msg, keyValuePairs}
-	_returns := &Z_LogInfoReturns{}
-	if err := g.client.Call("Plugin.LogInfo", _args, _returns); err != nil {
-		log.Printf("RPC call to LogInfo API failed: %s", err.Error())
+func (g *apiRPCClient) SendToUserWebapp(userId string, message model.PluginWebappMessage) (string, *model.AppError) {
+	_args := &Z_SendToUserWebappArgs{userId, message}
+	_returns := &Z_SendToUserWebappReturns{}
+	if err := g.client.Call("Plugin.SendToUserWebapp", _args, _returns); err != nil {
+		log.Printf("RPC call to SendToUserWebapp API failed: %s", err.Error())
 	}
-	return
+	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) LogInfo(args *Z_LogInfoArgs, returns *Z_LogInfoReturns) error {
+func (s *apiRPCServer) SendToUserWebapp(args *Z_SendToUserWebappArgs, returns *Z_SendToUserWebappReturns) error {
 	if hook, ok := s.impl.(interface {
-		LogInfo(msg string, keyValuePairs ...interface{})
+		SendToUserWebapp(userId string, message model.PluginWebappMessage) (string, *model.AppError)
 	}); ok {
-		hook.LogInfo(args.A, args.B...)
+		returns.A, returns.B = hook.SendToUserWebapp(args.A, args.B)
 	} else {
-		return fmt.Errorf("API LogInfo called but not implemented.")
+		return fmt.Errorf("API SendToUserWebapp called but not implemented.")
 	}
 	return nil
 }
 
-type Z_LogErrorArgs struct {
+type Z_StripMarkdownArgs struct {
 	A string
-	B []interface{}
 }
 
-type Z_LogErrorReturns struct {
+type Z_StripMarkdownReturns struct {
+	A string
 }
 
-func (g *apiRPCClient) LogError(msg string, keyValuePairs ...interface{}) {
-	_args := &Z_LogErrorArgs{msg, keyValuePairs}
-	_returns := &Z_LogErrorReturns{}
-	if err := g.client.Call("Plugin.LogError", _args, _returns); err != nil {
-		log.Printf("RPC call to LogError API failed: %s", err.Error())
+func (g *apiRPCClient) StripMarkdown(message string) string {
+	_args := &Z_StripMarkdownArgs{message}
+	_returns := &Z_StripMarkdownReturns{}
+	if err := g.client.Call("Plugin.StripMarkdown", _args, _returns); err != nil {
+		log.Printf("RPC call to StripMarkdown API failed: %s", err.Error())
 	}
-	return
+	return _returns.A
 }
 
-func (s *apiRPCServer) LogError(args *Z_LogErrorArgs, returns *Z_LogErrorReturns) error {
+func (s *apiRPCServer) StripMarkdown(args *Z_StripMarkdownArgs, returns *Z_StripMarkdownReturns) error {
 	if hook, ok := s.impl.(interface {
-		LogError(msg string, keyValuePairs ...interface{})
+		StripMarkdown(message string) string
 	}); ok {
-		hook.LogError(args.A, args.B...)
+		returns.A = hook.StripMarkdown(args.A)
 	} else {
-		return fmt.Errorf("API LogError called but not implemented.")
+		return fmt.Errorf("API StripMarkdown called but not implemented.")
 	}
 	return nil
 }
 
-type Z_LogWarnArgs struct {
+type Z_TruncateForNotificationArgs struct {
 	A string
-	B []interface{}
+	B int
 }
 
-type Z_LogWarnReturns struct {
+type Z_TruncateForNotificationReturns struct {
+	A string
 }
 
-func (g *apiRPCClient) LogWarn(msg string, keyValuePairs ...interface{}) {
-	_args := &Z_LogWarnArgs{msg, keyValuePairs}
-	_returns := &Z_LogWarnReturns{}
-	if err := g.client.Call("Plugin.LogWarn", _args, _returns); err != nil {
-		log.Printf("RPC call to LogWarn API failed: %s", err.Error())
+func (g *apiRPCClient) TruncateForNotification(message string, maxRunes int) string {
+	_args := &Z_TruncateForNotificationArgs{message, maxRunes}
+	_returns := &Z_TruncateForNotificationReturns{}
+	if err := g.client.Call("Plugin.TruncateForNotification", _args, _returns); err != nil {
+		log.Printf("RPC call to TruncateForNotification API failed: %s", err.Error())
 	}
-	return
+	return _returns.A
+}
+
+func (s *apiRPCServer) TruncateForNotification(args *Z_TruncateForNotificationArgs, returns *Z_TruncateForNotificationReturns) error {
+	if hook, ok := s.impl.(interface {
+		TruncateForNotification(message string, maxRunes int) string
+	}); ok {
+		returns.A = hook.TruncateForNotification(args.A, args.B)
+	} else {
+		return fmt.Errorf("API TruncateForNotification called but not implemented.")
+	}
+	return nil
+}
+
+type Z_RenderMessageToHTMLArgs struct {
+	A string
+}
+
+type Z_RenderMessageToHTMLReturns struct {
+	A string
+	B *model.AppError
+}
+
+func (g *apiRPCClient) RenderMessageToHTML(message string) (string, *model.AppError) {
+	_args := &Z_RenderMessageToHTMLArgs{message}
+	_returns := &Z_RenderMessageToHTMLReturns{}
+	if err := g.client.Call("Plugin.RenderMessageToHTML", _args, _returns); err != nil {
+		log.Printf("RPC call to RenderMessageToHTML API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
 }
 
-func (s *apiRPCServer) LogWarn(args *Z_LogWarnArgs, returns *Z_LogWarnReturns) error {
+func (s *apiRPCServer) RenderMessageToHTML(args *Z_RenderMessageToHTMLArgs, returns *Z_RenderMessageToHTMLReturns) error {
 	if hook, ok := s.impl.(interface {
-		LogWarn(msg string, keyValuePairs ...interface{})
+		RenderMessageToHTML(message string) (string, *model.AppError)
 	}); ok {
-		hook.LogWarn(args.A, args.B...)
+		returns.A, returns.B = hook.RenderMessageToHTML(args.A)
 	} else {
-		return fmt.Errorf("API LogWarn called but not implemented.")
+		return fmt.Errorf("API RenderMessageToHTML called but not implemented.")
 	}
 	return nil
 }
@@ -3,8 +3,90 @@
 
 package plugin
 
+import (
+	"sync"
+)
+
 // Context passes through metadata about the request or hook event.
-//
-// It is currently a placeholder while the implementation details are sorted out.
 type Context struct {
+	// RequestId identifies the HTTP request or event that caused the hook invocation. It is sent to
+	// plugins in the X-Request-ID header of HTTP requests, and is attached to the log messages
+	// written through the API while handling the hook invocation.
+	RequestId string
+}
+
+func (c *Context) requestId() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.RequestId
+}
+
+// hookRequests tracks the request ids of the hook invocations in progress within a plugin, so that
+// log messages written through the API can be attributed to the request that caused them.
+type hookRequests struct {
+	mutex sync.Mutex
+	ids   map[string]int
+}
+
+// begin records that a hook invocation with the given context has started, returning a function
+// that records that it has finished.
+func (r *hookRequests) begin(c *Context) func() {
+	requestId := c.requestId()
+	if requestId == "" {
+		return func() {}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.ids == nil {
+		r.ids = make(map[string]int)
+	}
+	r.ids[requestId]++
+
+	return func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		if r.ids[requestId]--; r.ids[requestId] == 0 {
+			delete(r.ids, requestId)
+		}
+	}
+}
+
+// current returns the request id of the hook invocations in progress, or nothing if there are none
+// or they were caused by different requests, since which of them a log message belongs to is then
+// unknown.
+func (r *hookRequests) current() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.ids) != 1 {
+		return ""
+	}
+
+	for requestId := range r.ids {
+		return requestId
+	}
+
+	return ""
+}
+
+// withRequestId adds the current request id to the given log key-value pairs, unless there is none
+// or one is already given.
+func (r *hookRequests) withRequestId(keyValuePairs []interface{}) []interface{} {
+	requestId := r.current()
+	if requestId == "" {
+		return keyValuePairs
+	}
+
+	for i := 0; i+1 < len(keyValuePairs); i += 2 {
+		if key, ok := keyValuePairs[i].(string); ok && key == "request_id" {
+			return keyValuePairs
+		}
+	}
+
+	return append(keyValuePairs, "request_id", requestId)
 }
@@ -0,0 +1,41 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookRequests(t *testing.T) {
+	requests := &hookRequests{}
+	assert.Equal(t, "", requests.current())
+	assert.Equal(t, []interface{}{"key", "value"}, requests.withRequestId([]interface{}{"key", "value"}))
+
+	endFirst := requests.begin(&Context{RequestId: "first"})
+	assert.Equal(t, "first", requests.current())
+	assert.Equal(t, []interface{}{"key", "value", "request_id", "first"}, requests.withRequestId([]interface{}{"key", "value"}))
+	assert.Equal(t, []interface{}{"request_id", "given"}, requests.withRequestId([]interface{}{"request_id", "given"}))
+
+	// Nested invocations for the same request keep it current
+	endNested := requests.begin(&Context{RequestId: "first"})
+	endNested()
+	assert.Equal(t, "first", requests.current())
+
+	// Invocations without a request id are ignored
+	requests.begin(&Context{})()
+	requests.begin(nil)()
+	assert.Equal(t, "first", requests.current())
+
+	// Concurrent invocations for different requests are ambiguous
+	endSecond := requests.begin(&Context{RequestId: "second"})
+	assert.Equal(t, "", requests.current())
+
+	endFirst()
+	assert.Equal(t, "second", requests.current())
+
+	endSecond()
+	assert.Equal(t, "", requests.current())
+}
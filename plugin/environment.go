@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
@@ -20,16 +21,39 @@ import (
 type apiImplCreatorFunc func(*model.Manifest) API
 type supervisorCreatorFunc func(*model.BundleInfo, *mlog.Logger, API) (*supervisor, error)
 
+// schemaMigratorFunc is invoked while activating a plugin that declares a schema version and
+// implements OnMigrate. It is responsible for determining whether a migration is pending and, if
+// so, invoking migrate.
+type schemaMigratorFunc func(manifest *model.Manifest, migrate func(fromVersion, toVersion int) error) error
+
+// stateChangeHandlerFunc is invoked whenever a plugin is activated or deactivated, with the reason
+// it failed to start if it did.
+type stateChangeHandlerFunc func(pluginId string, state int, err error)
+
 // multiPluginHookRunnerFunc is a callback function to invoke as part of RunMultiPluginHook.
 //
 // Return false to stop the hook from iterating to subsequent plugins.
 type multiPluginHookRunnerFunc func(hooks Hooks) bool
 
+// multiPluginHookWithIdRunnerFunc is a callback function to invoke as part of
+// RunMultiPluginHookWithId, receiving the id of the plugin whose hooks are passed.
+//
+// Return false to stop the hook from iterating to subsequent plugins.
+type multiPluginHookWithIdRunnerFunc func(pluginId string, hooks Hooks) bool
+
+// asyncPluginHookRunnerFunc is a callback function to invoke as part of RunMultiPluginHookAsync.
+type asyncPluginHookRunnerFunc func(hooks Hooks)
+
 type activePlugin struct {
 	BundleInfo *model.BundleInfo
 	State      int
 
-	supervisor *supervisor
+	supervisor      *supervisor
+	activationError error
+
+	// warmStart is set for plugins activated with a warm start, queueing their hooks until they
+	// are running.
+	warmStart *warmStart
 }
 
 // Environment represents the execution environment of active plugins.
@@ -37,11 +61,21 @@ type activePlugin struct {
 // It is meant for use by the Mattermost server to manipulate, interact with and report on the set
 // of active plugins.
 type Environment struct {
-	activePlugins   sync.Map
-	logger          *mlog.Logger
-	newAPIImpl      apiImplCreatorFunc
-	pluginDir       string
-	webappPluginDir string
+	activePlugins            sync.Map
+	logger                   *mlog.Logger
+	newAPIImpl               apiImplCreatorFunc
+	schemaMigrator           schemaMigratorFunc
+	hookQueueOverflowHandler hookQueueOverflowHandlerFunc
+	stateChangeHandler       stateChangeHandlerFunc
+	hookMetrics              HookMetrics
+	pluginDir                string
+	webappPluginDir          string
+
+	// hookWorkerPool runs the hooks invoked with RunMultiPluginHookAsync. It is started with
+	// hookWorkerPoolSize workers when first needed.
+	hookWorkerPoolLock sync.Mutex
+	hookWorkerPool     *hookWorkerPool
+	hookWorkerPoolSize int
 }
 
 func NewEnvironment(newAPIImpl apiImplCreatorFunc, pluginDir string, webappPluginDir string, logger *mlog.Logger) (*Environment, error) {
@@ -53,32 +87,103 @@ func NewEnvironment(newAPIImpl apiImplCreatorFunc, pluginDir string, webappPlugi
 	}, nil
 }
 
+// SetSchemaMigrator sets the function used to migrate the key-value data of plugins during
+// activation. Without one, the OnMigrate hook is never invoked.
+func (env *Environment) SetSchemaMigrator(schemaMigrator schemaMigratorFunc) {
+	env.schemaMigrator = schemaMigrator
+}
+
+// SetHookQueueOverflowHandler sets the function invoked whenever a hook invocation for a plugin is
+// dropped because too many are already queued, while it is warm starting or for asynchronous hooks.
+func (env *Environment) SetHookQueueOverflowHandler(hookQueueOverflowHandler hookQueueOverflowHandlerFunc) {
+	env.hookQueueOverflowHandler = hookQueueOverflowHandler
+}
+
+// SetHookMetrics sets the metrics recording hook invocations.
+func (env *Environment) SetHookMetrics(hookMetrics HookMetrics) {
+	env.hookMetrics = hookMetrics
+}
+
+// SetHookWorkerPoolSize sets the number of workers running the hooks invoked with
+// RunMultiPluginHookAsync, or DefaultHookWorkerPoolSize if size is zero. It has no effect once
+// asynchronous hooks have been invoked.
+func (env *Environment) SetHookWorkerPoolSize(size int) {
+	env.hookWorkerPoolLock.Lock()
+	defer env.hookWorkerPoolLock.Unlock()
+
+	env.hookWorkerPoolSize = size
+}
+
+// getHookWorkerPool returns the pool running asynchronous hooks, starting it if needed. Once the
+// environment has been shut down, the closed pool drops any further invocations.
+func (env *Environment) getHookWorkerPool() *hookWorkerPool {
+	env.hookWorkerPoolLock.Lock()
+	defer env.hookWorkerPoolLock.Unlock()
+
+	if env.hookWorkerPool == nil {
+		env.hookWorkerPool = newHookWorkerPool(env.hookWorkerPoolSize, env.hookMetrics, env.hookQueueOverflowHandler)
+	}
+
+	return env.hookWorkerPool
+}
+
+// SetStateChangeHandler sets the function invoked whenever a plugin starts, fails to start or is
+// deactivated. It is not invoked for the plugins deactivated by Shutdown, whose last state is
+// expected to outlive the environment.
+func (env *Environment) SetStateChangeHandler(stateChangeHandler stateChangeHandlerFunc) {
+	env.stateChangeHandler = stateChangeHandler
+}
+
+// notifyStateChange invokes the state change handler, if any.
+func (env *Environment) notifyStateChange(pluginId string, state int, err error) {
+	if env.stateChangeHandler != nil {
+		env.stateChangeHandler(pluginId, state, err)
+	}
+}
+
 // Performs a full scan of the given path.
 //
-// This function will return info for all subdirectories that appear to be plugins (i.e. all
-// subdirectories containing plugin manifest files, regardless of whether they could actually be
-// parsed).
+// This function will return info for all subdirectories whose plugin manifests could be parsed,
+// along with an error for every other subdirectory, so that a single unreadable or corrupt bundle
+// does not prevent the others from being loaded.
 //
 // Plugins are found non-recursively and paths beginning with a dot are always ignored.
-func scanSearchPath(path string) ([]*model.BundleInfo, error) {
+func scanSearchPath(path string) ([]*model.BundleInfo, []*model.PluginError, error) {
 	files, err := ioutil.ReadDir(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var ret []*model.BundleInfo
+	var pluginErrors []*model.PluginError
 	for _, file := range files {
 		if !file.IsDir() || file.Name()[0] == '.' {
 			continue
 		}
-		if info := model.BundleInfoForPath(filepath.Join(path, file.Name())); info.ManifestPath != "" {
+
+		info := model.BundleInfoForPath(filepath.Join(path, file.Name()))
+		if info.Manifest != nil {
 			ret = append(ret, info)
+			continue
+		}
+
+		reason := "no manifest found"
+		if info.ManifestPath != "" && info.ManifestError != nil {
+			reason = info.ManifestError.Error()
 		}
+		pluginErrors = append(pluginErrors, &model.PluginError{Path: info.Path, Reason: reason})
 	}
-	return ret, nil
+	return ret, pluginErrors, nil
 }
 
-// Returns a list of all plugins within the environment.
+// Returns a list of all plugins within the environment whose manifests could be read.
 func (env *Environment) Available() ([]*model.BundleInfo, error) {
+	plugins, _, err := scanSearchPath(env.pluginDir)
+	return plugins, err
+}
+
+// AvailableWithErrors is like Available, but also returns the directories within the environment
+// that could not be loaded as plugins.
+func (env *Environment) AvailableWithErrors() ([]*model.BundleInfo, []*model.PluginError, error) {
 	return scanSearchPath(env.pluginDir)
 }
 
@@ -100,23 +205,36 @@ func (env *Environment) IsActive(id string) bool {
 	return ok
 }
 
+// ActivationError returns the reason the plugin with the given id failed to start when last
+// activated, or nil if it is running or has not been activated.
+func (env *Environment) ActivationError(id string) error {
+	if plugin, ok := env.activePlugins.Load(id); ok {
+		return plugin.(activePlugin).activationError
+	}
+
+	return nil
+}
+
 // Statuses returns a list of plugin statuses representing the state of every plugin
 func (env *Environment) Statuses() (model.PluginStatuses, error) {
-	plugins, err := env.Available()
+	plugins, pluginErrors, err := env.AvailableWithErrors()
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to get plugin statuses")
 	}
 
-	pluginStatuses := make(model.PluginStatuses, 0, len(plugins))
+	pluginStatuses := make(model.PluginStatuses, 0, len(plugins)+len(pluginErrors))
 	for _, plugin := range plugins {
-		// For now we don't handle bad manifests, we should
-		if plugin.Manifest == nil {
-			continue
-		}
-
 		pluginState := model.PluginStateNotRunning
+		pluginError := ""
 		if plugin, ok := env.activePlugins.Load(plugin.Manifest.Id); ok {
-			pluginState = plugin.(activePlugin).State
+			activePlugin := plugin.(activePlugin)
+			pluginState = activePlugin.State
+
+			// The process of a plugin that crashed is only restarted once it is activated again.
+			if pluginState == model.PluginStateRunning && activePlugin.supervisor != nil && activePlugin.supervisor.Exited() {
+				pluginState = model.PluginStateFailedToStayRunning
+				pluginError = "plugin process exited unexpectedly"
+			}
 		}
 
 		status := &model.PluginStatus{
@@ -126,11 +244,20 @@ func (env *Environment) Statuses() (model.PluginStatuses, error) {
 			Name:        plugin.Manifest.Name,
 			Description: plugin.Manifest.Description,
 			Version:     plugin.Manifest.Version,
+			Error:       pluginError,
 		}
 
 		pluginStatuses = append(pluginStatuses, status)
 	}
 
+	for _, pluginError := range pluginErrors {
+		pluginStatuses = append(pluginStatuses, &model.PluginStatus{
+			PluginPath: pluginError.Path,
+			State:      model.PluginStateFailedToLoad,
+			Error:      pluginError.Reason,
+		})
+	}
+
 	return pluginStatuses, nil
 }
 
@@ -146,7 +273,7 @@ func (env *Environment) Activate(id string) (manifest *model.Manifest, activated
 	}
 	var pluginInfo *model.BundleInfo
 	for _, p := range plugins {
-		if p.Manifest != nil && p.Manifest.Id == id {
+		if p.Manifest.Id == id {
 			if pluginInfo != nil {
 				return nil, false, fmt.Errorf("multiple plugins found: %v", id)
 			}
@@ -163,17 +290,39 @@ func (env *Environment) Activate(id string) (manifest *model.Manifest, activated
 			activePlugin.State = model.PluginStateRunning
 		} else {
 			activePlugin.State = model.PluginStateFailedToStart
+			activePlugin.activationError = reterr
+		}
+
+		// The hooks queued while warm starting are replayed before the plugin is marked running,
+		// so that hooks invoked in the meantime are queued behind them rather than overtaking them.
+		if activePlugin.warmStart != nil {
+			if reterr == nil {
+				activePlugin.warmStart.replay(activePlugin.supervisor.Hooks())
+			} else {
+				activePlugin.warmStart.discard()
+			}
 		}
+
 		env.activePlugins.Store(pluginInfo.Manifest.Id, activePlugin)
+		env.notifyStateChange(pluginInfo.Manifest.Id, activePlugin.State, reterr)
 	}()
 
+	// Plugins installed before the server was downgraded, or copied into the plugin directory, are
+	// only checked for the server version they require now.
+	if !pluginInfo.Manifest.MeetsMinServerVersion(model.CurrentVersion) {
+		if !model.IsDevBuild() {
+			return nil, false, fmt.Errorf("plugin requires server version %v or later, but this server is version %v", pluginInfo.Manifest.MinServerVersion, model.CurrentVersion)
+		}
+		env.logger.Warn("Activating plugin requiring a newer server version on a development build", mlog.String("plugin_id", id), mlog.String("min_server_version", pluginInfo.Manifest.MinServerVersion), mlog.String("server_version", model.CurrentVersion))
+	}
+
 	if pluginInfo.Manifest.Webapp != nil {
 		bundlePath := filepath.Clean(pluginInfo.Manifest.Webapp.BundlePath)
 		if bundlePath == "" || bundlePath[0] == '.' {
 			return nil, false, fmt.Errorf("invalid webapp bundle path")
 		}
 		bundlePath = filepath.Join(env.pluginDir, id, bundlePath)
-		destinationPath := filepath.Join(env.webappPluginDir, id)
+		destinationPath := env.WebappPath(id)
 
 		if err := os.RemoveAll(destinationPath); err != nil {
 			return nil, false, errors.Wrapf(err, "unable to remove old webapp bundle directory: %v", destinationPath)
@@ -203,16 +352,58 @@ func (env *Environment) Activate(id string) (manifest *model.Manifest, activated
 	}
 
 	if pluginInfo.Manifest.HasServer() {
-		supervisor, err := newSupervisor(pluginInfo, env.logger, env.newAPIImpl(pluginInfo.Manifest))
+		var supervisor *supervisor
+		if pluginInfo.Manifest.WarmStart {
+			supervisor, err = env.warmStartSupervisor(pluginInfo, &activePlugin)
+		} else {
+			supervisor, err = newSupervisor(pluginInfo, env.logger, env.newAPIImpl(pluginInfo.Manifest))
+		}
 		if err != nil {
 			return nil, false, errors.Wrapf(err, "unable to start plugin: %v", id)
 		}
+
+		if env.schemaMigrator != nil && pluginInfo.Manifest.SchemaVersion > 0 && supervisor.Implements(OnMigrateId) {
+			if err := env.schemaMigrator(pluginInfo.Manifest, supervisor.Hooks().OnMigrate); err != nil {
+				supervisor.Shutdown()
+				return nil, false, errors.Wrapf(err, "unable to migrate plugin: %v", id)
+			}
+		}
+
 		activePlugin.supervisor = supervisor
 	}
 
 	return pluginInfo.Manifest, true, nil
 }
 
+// warmStartSupervisor starts the plugin's process and makes the plugin visible as starting before
+// activating it, so that its HTTP requests are answered as temporarily unavailable and its hooks are
+// queued while OnActivate runs, rather than the plugin appearing not to exist.
+func (env *Environment) warmStartSupervisor(pluginInfo *model.BundleInfo, activePlugin *activePlugin) (*supervisor, error) {
+	supervisor, err := startSupervisor(pluginInfo, env.logger, env.newAPIImpl(pluginInfo.Manifest))
+	if err != nil {
+		return nil, err
+	}
+
+	activePlugin.warmStart = &warmStart{
+		pluginId: pluginInfo.Manifest.Id,
+		size:     warmStartHookQueueSize,
+		overflow: env.hookQueueOverflowHandler,
+	}
+
+	starting := *activePlugin
+	starting.State = model.PluginStateStarting
+	starting.supervisor = supervisor
+	env.activePlugins.Store(pluginInfo.Manifest.Id, starting)
+	env.notifyStateChange(pluginInfo.Manifest.Id, starting.State, nil)
+
+	if err := supervisor.Hooks().OnActivate(); err != nil {
+		supervisor.Shutdown()
+		return nil, err
+	}
+
+	return supervisor, nil
+}
+
 // Deactivates the plugin with the given id.
 func (env *Environment) Deactivate(id string) bool {
 	p, ok := env.activePlugins.Load(id)
@@ -230,11 +421,33 @@ func (env *Environment) Deactivate(id string) bool {
 		activePlugin.supervisor.Shutdown()
 	}
 
+	env.hookWorkerPoolLock.Lock()
+	if env.hookWorkerPool != nil {
+		env.hookWorkerPool.discard(id)
+	}
+	env.hookWorkerPoolLock.Unlock()
+
+	env.notifyStateChange(id, model.PluginStateNotRunning, nil)
+
 	return true
 }
 
+// WebappPath returns the directory from which the webapp artifacts of the plugin with the given id
+// are served once it has been activated.
+func (env *Environment) WebappPath(id string) string {
+	return filepath.Join(env.webappPluginDir, id)
+}
+
 // Shutdown deactivates all plugins and gracefully shuts down the environment.
 func (env *Environment) Shutdown() {
+	// Asynchronous hooks still queued are dropped, and those running are waited for before the
+	// plugins handling them are shut down.
+	env.hookWorkerPoolLock.Lock()
+	if env.hookWorkerPool != nil {
+		env.hookWorkerPool.close()
+	}
+	env.hookWorkerPoolLock.Unlock()
+
 	env.activePlugins.Range(func(key, value interface{}) bool {
 		activePlugin := value.(activePlugin)
 
@@ -251,12 +464,24 @@ func (env *Environment) Shutdown() {
 	})
 }
 
+// Manifest returns the manifest of the active plugin with the given id, or nil if it is not active.
+func (env *Environment) Manifest(id string) *model.Manifest {
+	if p, ok := env.activePlugins.Load(id); ok {
+		return p.(activePlugin).BundleInfo.Manifest
+	}
+
+	return nil
+}
+
 // HooksForPlugin returns the hooks API for the plugin with the given id.
 //
 // Consider using RunMultiPluginHook instead.
 func (env *Environment) HooksForPlugin(id string) (Hooks, error) {
 	if p, ok := env.activePlugins.Load(id); ok {
 		activePlugin := p.(activePlugin)
+		if activePlugin.State == model.PluginStateStarting {
+			return nil, ErrPluginStarting
+		}
 		if activePlugin.supervisor != nil {
 			return activePlugin.supervisor.Hooks(), nil
 		}
@@ -270,16 +495,102 @@ func (env *Environment) HooksForPlugin(id string) (Hooks, error) {
 // If hookRunnerFunc returns false, iteration will not continue. The iteration order among active
 // plugins is not specified.
 func (env *Environment) RunMultiPluginHook(hookRunnerFunc multiPluginHookRunnerFunc, hookId int) {
+	env.RunMultiPluginHookWithId(func(pluginId string, hooks Hooks) bool {
+		return hookRunnerFunc(hooks)
+	}, hookId)
+}
+
+// RunMultiPluginHookWithId behaves like RunMultiPluginHook, but also passes the id of each plugin
+// to hookRunnerFunc, for hooks whose results must be attributed to the plugin that produced them.
+//
+// Hooks for a plugin that is still warm starting are queued and run once it is running, so their
+// results cannot affect the caller and they never stop the iteration.
+func (env *Environment) RunMultiPluginHookWithId(hookRunnerFunc multiPluginHookWithIdRunnerFunc, hookId int) {
+	env.activePlugins.Range(func(key, value interface{}) bool {
+		activePlugin := value.(activePlugin)
+
+		if activePlugin.supervisor == nil || !activePlugin.supervisor.Implements(hookId) {
+			return true
+		}
+		if activePlugin.State == model.PluginStateStarting {
+			pluginId := key.(string)
+			activePlugin.warmStart.run(func(hooks Hooks) {
+				hookRunnerFunc(pluginId, hooks)
+			})
+			return true
+		}
+
+		start := time.Now()
+		cont := hookRunnerFunc(key.(string), activePlugin.supervisor.Hooks())
+		env.observeHookDuration(key.(string), hookId, start)
+
+		return cont
+	})
+}
+
+// RunMultiPluginHookAsync invokes hookRunnerFunc for each plugin that implements the given hookId
+// without waiting for the plugins to handle it. The invocations are run by a bounded pool of
+// workers shared by all asynchronous hooks, and dropped if a plugin falls too far behind.
+func (env *Environment) RunMultiPluginHookAsync(hookRunnerFunc asyncPluginHookRunnerFunc, hookId int) {
+	pool := env.getHookWorkerPool()
+
 	env.activePlugins.Range(func(key, value interface{}) bool {
 		activePlugin := value.(activePlugin)
+		pluginId := key.(string)
 
 		if activePlugin.supervisor == nil || !activePlugin.supervisor.Implements(hookId) {
 			return true
 		}
-		if !hookRunnerFunc(activePlugin.supervisor.Hooks()) {
+		if activePlugin.State == model.PluginStateStarting {
+			activePlugin.warmStart.run(func(hooks Hooks) {
+				hookRunnerFunc(hooks)
+			})
+			return true
+		}
+
+		hooks := activePlugin.supervisor.Hooks()
+		pool.submit(pluginId, func() {
+			start := time.Now()
+			hookRunnerFunc(hooks)
+			env.observeHookDuration(pluginId, hookId, start)
+		})
+
+		return true
+	})
+}
+
+// observeHookDuration records how long the plugin took to handle the hook since start.
+func (env *Environment) observeHookDuration(pluginId string, hookId int, start time.Time) {
+	if env.hookMetrics != nil {
+		env.hookMetrics.ObservePluginHookDuration(pluginId, hookName(hookId), time.Since(start).Seconds())
+	}
+}
+
+// IsHookImplemented returns true if at least one active plugin implements the given hook.
+//
+// Callers can use this to skip preparing expensive hook arguments when no plugin would receive them.
+func (env *Environment) IsHookImplemented(hookId int) bool {
+	implemented := false
+	env.activePlugins.Range(func(key, value interface{}) bool {
+		activePlugin := value.(activePlugin)
+		if activePlugin.supervisor != nil && activePlugin.supervisor.Implements(hookId) {
+			implemented = true
 			return false
 		}
 
 		return true
 	})
+
+	return implemented
+}
+
+// PluginImplementsHook returns true if the active plugin with the given id implements the given
+// hook.
+func (env *Environment) PluginImplementsHook(id string, hookId int) bool {
+	if p, ok := env.activePlugins.Load(id); ok {
+		activePlugin := p.(activePlugin)
+		return activePlugin.supervisor != nil && activePlugin.supervisor.Implements(hookId)
+	}
+
+	return false
 }
@@ -0,0 +1,246 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package plugin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestScanSearchPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeBundle := func(name, manifest string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.Mkdir(path, 0700))
+		if manifest != "" {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(path, "plugin.json"), []byte(manifest), 0600))
+		}
+		return path
+	}
+
+	writeBundle("valid", `{"id": "valid"}`)
+	writeBundle(".hidden", `{"id": "hidden"}`)
+	corrupt := writeBundle("corrupt", `{"id": `)
+	missing := writeBundle("missing", "")
+
+	t.Run("readable bundles are returned alongside errors", func(t *testing.T) {
+		bundles, pluginErrors, err := scanSearchPath(dir)
+		require.NoError(t, err)
+
+		require.Len(t, bundles, 1)
+		assert.Equal(t, "valid", bundles[0].Manifest.Id)
+
+		reasons := make(map[string]string, len(pluginErrors))
+		for _, pluginError := range pluginErrors {
+			reasons[pluginError.Path] = pluginError.Reason
+		}
+		require.Len(t, reasons, 2)
+		assert.Contains(t, reasons, corrupt)
+		assert.NotEmpty(t, reasons[corrupt])
+		assert.Equal(t, "no manifest found", reasons[missing])
+	})
+
+	t.Run("unreadable directory", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("permissions are not enforced for root")
+		}
+
+		unreadable := writeBundle("unreadable", `{"id": "unreadable"}`)
+		require.NoError(t, os.Chmod(unreadable, 0))
+		defer os.Chmod(unreadable, 0700)
+
+		bundles, pluginErrors, err := scanSearchPath(dir)
+		require.NoError(t, err)
+		require.Len(t, bundles, 1)
+
+		var found *model.PluginError
+		for _, pluginError := range pluginErrors {
+			if pluginError.Path == unreadable {
+				found = pluginError
+			}
+		}
+		require.NotNil(t, found)
+		assert.NotEmpty(t, found.Reason)
+	})
+
+	t.Run("statuses", func(t *testing.T) {
+		env, err := NewEnvironment(nil, dir, dir, nil)
+		require.NoError(t, err)
+
+		statuses, err := env.Statuses()
+		require.NoError(t, err)
+
+		states := make(map[string]*model.PluginStatus, len(statuses))
+		for _, status := range statuses {
+			states[status.PluginPath] = status
+		}
+		require.Contains(t, states, missing)
+		assert.Equal(t, model.PluginStateFailedToLoad, states[missing].State)
+		assert.Equal(t, "no manifest found", states[missing].Error)
+		assert.Equal(t, model.PluginStateNotRunning, states[filepath.Join(dir, "valid")].State)
+	})
+}
+
+func TestEnvironmentStateChangeHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for id, manifest := range map[string]string{
+		"valid":  `{"id": "valid"}`,
+		"broken": `{"id": "broken", "webapp": {"bundle_path": "../bundle.js"}}`,
+	} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, id), 0700))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, id, "plugin.json"), []byte(manifest), 0600))
+	}
+
+	env, err := NewEnvironment(nil, dir, dir, nil)
+	require.NoError(t, err)
+
+	type stateChange struct {
+		pluginId string
+		state    int
+		failed   bool
+	}
+	var stateChanges []stateChange
+	env.SetStateChangeHandler(func(pluginId string, state int, err error) {
+		stateChanges = append(stateChanges, stateChange{pluginId, state, err != nil})
+	})
+
+	_, _, err = env.Activate("valid")
+	require.NoError(t, err)
+	_, _, err = env.Activate("broken")
+	require.Error(t, err)
+	assert.True(t, env.Deactivate("valid"))
+
+	assert.Equal(t, []stateChange{
+		{"valid", model.PluginStateRunning, false},
+		{"broken", model.PluginStateFailedToStart, true},
+		{"valid", model.PluginStateNotRunning, false},
+	}, stateChanges)
+
+	// The last state of the plugins deactivated by shutting down is kept
+	env.Shutdown()
+	assert.Len(t, stateChanges, 3)
+}
+
+func TestEnvironmentActivateMinServerVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for id, minServerVersion := range map[string]string{
+		"older":      "5.0.0",
+		"exact":      model.CurrentVersion,
+		"prerelease": model.CurrentVersion + "-rc1",
+		"newer":      "99.0.0",
+	} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, id), 0700))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, id, "plugin.json"), []byte(`{"id": "`+id+`", "min_server_version": "`+minServerVersion+`"}`), 0600))
+	}
+
+	buildNumber := model.BuildNumber
+	defer func() { model.BuildNumber = buildNumber }()
+
+	t.Run("release build", func(t *testing.T) {
+		model.BuildNumber = "1234"
+
+		env, err := NewEnvironment(nil, dir, dir, mlog.NewLogger(&mlog.LoggerConfiguration{}))
+		require.NoError(t, err)
+		defer env.Shutdown()
+
+		for _, id := range []string{"older", "exact", "prerelease"} {
+			_, activated, err := env.Activate(id)
+			require.NoError(t, err, id)
+			assert.True(t, activated, id)
+		}
+
+		_, activated, err := env.Activate("newer")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "99.0.0")
+		assert.Contains(t, err.Error(), model.CurrentVersion)
+		assert.False(t, activated)
+		assert.Equal(t, err, env.ActivationError("newer"))
+	})
+
+	t.Run("development build", func(t *testing.T) {
+		model.BuildNumber = "dev"
+
+		env, err := NewEnvironment(nil, dir, dir, mlog.NewLogger(&mlog.LoggerConfiguration{}))
+		require.NoError(t, err)
+		defer env.Shutdown()
+
+		_, activated, err := env.Activate("newer")
+		require.NoError(t, err)
+		assert.True(t, activated)
+	})
+}
+
+func TestEnvironmentStatusesCrashedPlugin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	compileGo(t, `
+		package main
+
+		import (
+			"os"
+			"time"
+
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) OnActivate() error {
+			go func() {
+				time.Sleep(500 * time.Millisecond)
+				os.Exit(1)
+			}()
+			return nil
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`, filepath.Join(dir, "crashing", "backend.exe"))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "crashing", "plugin.json"), []byte(`{"id": "crashing", "backend": {"executable": "backend.exe"}}`), 0600))
+
+	env, err := NewEnvironment(func(*model.Manifest) API { return nil }, dir, dir, mlog.NewLogger(&mlog.LoggerConfiguration{}))
+	require.NoError(t, err)
+	defer env.Shutdown()
+
+	_, _, err = env.Activate("crashing")
+	require.NoError(t, err)
+
+	statuses, err := env.Statuses()
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, model.PluginStateRunning, statuses[0].State)
+	assert.Empty(t, statuses[0].Error)
+
+	time.Sleep(2 * time.Second)
+
+	statuses, err = env.Statuses()
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, model.PluginStateFailedToStayRunning, statuses[0].State)
+	assert.NotEmpty(t, statuses[0].Error)
+	assert.True(t, env.IsActive("crashing"))
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package plugin
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	lockRetryMinInterval = 10 * time.Millisecond
+	lockRetryMaxInterval = 500 * time.Millisecond
+)
+
+// Helpers provides higher level functionality built on top of the plugin API.
+type Helpers interface {
+	// WithLock waits until the advisory lock named by key is taken, runs fn while holding it, and
+	// then releases it. The lock expires after ttl even if fn has not yet returned, so ttl should
+	// comfortably exceed the time fn takes.
+	//
+	// Locks are not re-entrant: calling WithLock for a key from within fn for the same key
+	// deadlocks until the outer lock expires.
+	WithLock(key string, ttl time.Duration, fn func()) *model.AppError
+}
+
+// HelpersImpl implements the Helpers interface using the given plugin API.
+type HelpersImpl struct {
+	API API
+}
+
+func (h *HelpersImpl) WithLock(key string, ttl time.Duration, fn func()) (err *model.AppError) {
+	interval := lockRetryMinInterval
+	var token string
+	for {
+		var lockErr *model.AppError
+		token, lockErr = h.API.KVLock(key, ttl)
+		if lockErr != nil {
+			return lockErr
+		}
+		if token != "" {
+			break
+		}
+
+		time.Sleep(interval)
+		if interval *= 2; interval > lockRetryMaxInterval {
+			interval = lockRetryMaxInterval
+		}
+	}
+
+	defer func() {
+		if unlockErr := h.API.KVUnlock(key, token); unlockErr != nil && err == nil {
+			err = unlockErr
+		}
+	}()
+	fn()
+
+	return nil
+}
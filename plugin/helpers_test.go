@@ -0,0 +1,74 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package plugin_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+)
+
+func TestWithLock(t *testing.T) {
+	t.Run("runs once the lock is taken", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+
+		api.On("KVLock", "key", time.Minute).Return("", nil).Twice()
+		api.On("KVLock", "key", time.Minute).Return("token", nil).Once()
+		api.On("KVUnlock", "key", "token").Return(nil).Once()
+
+		ran := false
+		err := (&plugin.HelpersImpl{API: api}).WithLock("key", time.Minute, func() {
+			ran = true
+		})
+		require.Nil(t, err)
+		assert.True(t, ran)
+	})
+
+	t.Run("does not run if the lock cannot be taken", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+
+		lockErr := model.NewAppError("KVLock", "id", nil, "", http.StatusInternalServerError)
+		api.On("KVLock", "key", time.Minute).Return("", lockErr).Once()
+
+		err := (&plugin.HelpersImpl{API: api}).WithLock("key", time.Minute, func() {
+			t.Fatal("should not run without the lock")
+		})
+		assert.Equal(t, lockErr, err)
+	})
+
+	t.Run("reports failing to unlock", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+
+		unlockErr := model.NewAppError("KVUnlock", "id", nil, "", http.StatusInternalServerError)
+		api.On("KVLock", "key", time.Minute).Return("token", nil).Once()
+		api.On("KVUnlock", "key", "token").Return(unlockErr).Once()
+
+		err := (&plugin.HelpersImpl{API: api}).WithLock("key", time.Minute, func() {})
+		assert.Equal(t, unlockErr, err)
+	})
+
+	t.Run("unlocks if the function panics", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+
+		api.On("KVLock", "key", time.Minute).Return("token", nil).Once()
+		api.On("KVUnlock", "key", "token").Return(nil).Once()
+
+		assert.Panics(t, func() {
+			(&plugin.HelpersImpl{API: api}).WithLock("key", time.Minute, func() {
+				panic("failed")
+			})
+		})
+	})
+}
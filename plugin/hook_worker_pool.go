@@ -0,0 +1,217 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package plugin
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// hookWorkerPoolQueueSize bounds the asynchronous hook invocations queued for each plugin. Further
+// invocations are dropped until the plugin catches up.
+const hookWorkerPoolQueueSize = 1000
+
+// DefaultHookWorkerPoolSize returns the number of workers running asynchronous hooks when no size
+// is configured, scaled to the number of CPUs since most of their time is spent waiting on plugins.
+func DefaultHookWorkerPoolSize() int {
+	return 4 * runtime.NumCPU()
+}
+
+// HookMetrics receives measurements of hook invocations. It is satisfied by the server's metrics.
+type HookMetrics interface {
+	// ObservePluginHookDuration records how long a plugin took to handle a hook, in seconds.
+	ObservePluginHookDuration(pluginId, hookName string, elapsed float64)
+
+	// ObservePluginHookQueueWaitDuration records how long an asynchronous hook invocation waited
+	// for a worker, in seconds.
+	ObservePluginHookQueueWaitDuration(pluginId string, elapsed float64)
+
+	// SetPluginHookWorkersBusy records how many of the workers running asynchronous hooks are busy.
+	SetPluginHookWorkersBusy(count int)
+}
+
+type hookTask struct {
+	run      func()
+	queuedAt time.Time
+}
+
+// hookWorkerPool runs asynchronous hook invocations on a fixed number of goroutines. Invocations
+// are queued per plugin and the workers take them from each plugin in turn. No plugin may occupy
+// more than half of the workers, so that a plugin slow to handle its hooks delays its own
+// invocations rather than those of other plugins.
+type hookWorkerPool struct {
+	size           int
+	perPluginLimit int
+	metrics        HookMetrics
+	overflow       hookQueueOverflowHandlerFunc
+
+	lock    sync.Mutex
+	cond    *sync.Cond
+	queues  map[string][]hookTask
+	order   []string
+	turn    int
+	running map[string]int
+	busy    int
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+func newHookWorkerPool(size int, metrics HookMetrics, overflow hookQueueOverflowHandlerFunc) *hookWorkerPool {
+	if size <= 0 {
+		size = DefaultHookWorkerPoolSize()
+	}
+
+	perPluginLimit := (size + 1) / 2
+
+	pool := &hookWorkerPool{
+		size:           size,
+		perPluginLimit: perPluginLimit,
+		metrics:        metrics,
+		overflow:       overflow,
+		queues:         make(map[string][]hookTask),
+		running:        make(map[string]int),
+	}
+	pool.cond = sync.NewCond(&pool.lock)
+
+	pool.wg.Add(size)
+	for i := 0; i < size; i++ {
+		go pool.work()
+	}
+
+	return pool
+}
+
+// submit queues f to run on behalf of the plugin. The invocation is dropped if too many are
+// already queued for the plugin, or the pool has been closed.
+func (p *hookWorkerPool) submit(pluginId string, f func()) {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return
+	}
+
+	queue, ok := p.queues[pluginId]
+	if len(queue) >= hookWorkerPoolQueueSize {
+		p.lock.Unlock()
+		if p.overflow != nil {
+			p.overflow(pluginId)
+		}
+		return
+	}
+
+	if !ok {
+		p.order = append(p.order, pluginId)
+	}
+	p.queues[pluginId] = append(queue, hookTask{run: f, queuedAt: time.Now()})
+	p.lock.Unlock()
+
+	p.cond.Signal()
+}
+
+// discard drops the invocations queued for a plugin that has been deactivated.
+func (p *hookWorkerPool) discard(pluginId string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.queues[pluginId]; ok {
+		p.removeQueue(pluginId)
+	}
+}
+
+// close drops all queued invocations and waits for those running to return.
+func (p *hookWorkerPool) close() {
+	p.lock.Lock()
+	p.closed = true
+	p.queues = make(map[string][]hookTask)
+	p.order = nil
+	p.lock.Unlock()
+
+	p.cond.Broadcast()
+	p.wg.Wait()
+}
+
+func (p *hookWorkerPool) work() {
+	defer p.wg.Done()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for {
+		pluginId, task, ok := p.next()
+		for !ok && !p.closed {
+			p.cond.Wait()
+			pluginId, task, ok = p.next()
+		}
+		if p.closed {
+			return
+		}
+
+		p.running[pluginId]++
+		p.busy++
+		p.observeBusy()
+		p.lock.Unlock()
+
+		if p.metrics != nil {
+			p.metrics.ObservePluginHookQueueWaitDuration(pluginId, time.Since(task.queuedAt).Seconds())
+		}
+		task.run()
+
+		p.lock.Lock()
+		p.running[pluginId]--
+		if p.running[pluginId] == 0 {
+			delete(p.running, pluginId)
+		}
+		p.busy--
+		p.observeBusy()
+
+		// The plugin may have been held back by its limit while this invocation ran, and this worker
+		// takes the next invocation itself, so another worker is woken to take any further one.
+		p.cond.Signal()
+	}
+}
+
+// next takes the next invocation to run, from the first plugin after the last one served that has
+// queued invocations and is below its limit of workers. The lock must be held.
+func (p *hookWorkerPool) next() (string, hookTask, bool) {
+	for i := 0; i < len(p.order); i++ {
+		index := (p.turn + i) % len(p.order)
+		pluginId := p.order[index]
+		if p.running[pluginId] >= p.perPluginLimit {
+			continue
+		}
+
+		queue := p.queues[pluginId]
+		task := queue[0]
+		if len(queue) == 1 {
+			p.removeQueue(pluginId)
+			p.turn = index
+		} else {
+			p.queues[pluginId] = queue[1:]
+			p.turn = index + 1
+		}
+
+		return pluginId, task, true
+	}
+
+	return "", hookTask{}, false
+}
+
+// removeQueue removes the plugin's queue and its turn. The lock must be held.
+func (p *hookWorkerPool) removeQueue(pluginId string) {
+	delete(p.queues, pluginId)
+	for i, id := range p.order {
+		if id == pluginId {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// observeBusy records the number of busy workers. The lock must be held.
+func (p *hookWorkerPool) observeBusy() {
+	if p.metrics != nil {
+		p.metrics.SetPluginHookWorkersBusy(p.busy)
+	}
+}
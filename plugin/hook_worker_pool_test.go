@@ -0,0 +1,266 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package plugin
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testHookMetrics struct {
+	lock       sync.Mutex
+	queueWaits map[string]int
+	maxBusy    int
+}
+
+func newTestHookMetrics() *testHookMetrics {
+	return &testHookMetrics{
+		queueWaits: make(map[string]int),
+	}
+}
+
+func (m *testHookMetrics) ObservePluginHookDuration(pluginId, hookName string, elapsed float64) {
+}
+
+func (m *testHookMetrics) ObservePluginHookQueueWaitDuration(pluginId string, elapsed float64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.queueWaits[pluginId]++
+}
+
+func (m *testHookMetrics) SetPluginHookWorkersBusy(count int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if count > m.maxBusy {
+		m.maxBusy = count
+	}
+}
+
+func TestHookWorkerPool(t *testing.T) {
+	t.Run("runs every invocation on a bounded number of workers", func(t *testing.T) {
+		metrics := newTestHookMetrics()
+		pool := newHookWorkerPool(4, metrics, nil)
+		defer pool.close()
+
+		var running, maxRunning, ran int32
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			pool.submit(fmt.Sprintf("plugin%d", i%5), func() {
+				defer wg.Done()
+				n := atomic.AddInt32(&running, 1)
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+					if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				atomic.AddInt32(&ran, 1)
+			})
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(100), ran)
+		assert.True(t, maxRunning <= 4, "%d invocations ran at once", maxRunning)
+
+		metrics.lock.Lock()
+		defer metrics.lock.Unlock()
+		assert.Equal(t, 20, metrics.queueWaits["plugin0"])
+		assert.True(t, metrics.maxBusy <= 4)
+	})
+
+	t.Run("a slow plugin does not starve the others", func(t *testing.T) {
+		pool := newHookWorkerPool(4, nil, nil)
+
+		release := make(chan struct{})
+		for i := 0; i < 100; i++ {
+			pool.submit("slow", func() { <-release })
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			pool.submit("fast", wg.Done)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "invocations for the fast plugin were starved")
+		}
+
+		close(release)
+		pool.close()
+	})
+
+	t.Run("takes invocations from each plugin in turn", func(t *testing.T) {
+		pool := newHookWorkerPool(1, nil, nil)
+		defer pool.close()
+
+		// The only worker is held until every invocation is queued.
+		release := make(chan struct{})
+		started := make(chan struct{})
+		pool.submit("block", func() {
+			close(started)
+			<-release
+		})
+		<-started
+
+		var lock sync.Mutex
+		var order []string
+		var wg sync.WaitGroup
+		record := func(pluginId string) func() {
+			wg.Add(1)
+			return func() {
+				lock.Lock()
+				order = append(order, pluginId)
+				lock.Unlock()
+				wg.Done()
+			}
+		}
+		for i := 0; i < 3; i++ {
+			pool.submit("a", record("a"))
+		}
+		pool.submit("b", record("b"))
+		pool.submit("c", record("c"))
+
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, []string{"a", "b", "c", "a", "a"}, order)
+	})
+
+	t.Run("drops invocations beyond the queue size", func(t *testing.T) {
+		var overflows int32
+		pool := newHookWorkerPool(1, nil, func(pluginId string) {
+			assert.Equal(t, "foo", pluginId)
+			atomic.AddInt32(&overflows, 1)
+		})
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+		pool.submit("foo", func() {
+			close(started)
+			<-release
+		})
+		<-started
+
+		for i := 0; i < hookWorkerPoolQueueSize+5; i++ {
+			pool.submit("foo", func() {})
+		}
+		assert.Equal(t, int32(5), atomic.LoadInt32(&overflows))
+
+		close(release)
+		pool.close()
+	})
+
+	t.Run("close drops queued invocations", func(t *testing.T) {
+		pool := newHookWorkerPool(1, nil, nil)
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+		pool.submit("foo", func() {
+			close(started)
+			<-release
+		})
+		<-started
+
+		var ran int32
+		pool.submit("foo", func() { atomic.AddInt32(&ran, 1) })
+		pool.submit("bar", func() { atomic.AddInt32(&ran, 1) })
+
+		go close(release)
+		pool.close()
+
+		// Invocations submitted after closing are dropped too.
+		pool.submit("foo", func() { atomic.AddInt32(&ran, 1) })
+		assert.Equal(t, int32(0), atomic.LoadInt32(&ran))
+	})
+
+	t.Run("discard drops the invocations queued for a plugin", func(t *testing.T) {
+		pool := newHookWorkerPool(1, nil, nil)
+		defer pool.close()
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+		pool.submit("foo", func() {
+			close(started)
+			<-release
+		})
+		<-started
+
+		var ran int32
+		pool.submit("foo", func() { atomic.AddInt32(&ran, 1) })
+		done := make(chan struct{})
+		pool.submit("bar", func() { close(done) })
+
+		pool.discard("foo")
+		close(release)
+		<-done
+
+		assert.Equal(t, int32(0), atomic.LoadInt32(&ran))
+	})
+}
+
+// TestHookWorkerPoolBurst checks that a burst of asynchronous hooks, such as a storm of posts with
+// several plugins implementing MessageHasBeenPosted, does not grow the number of goroutines.
+func TestHookWorkerPoolBurst(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping load test in short mode")
+	}
+
+	const size = 8
+	before := runtime.NumGoroutine()
+
+	var wg sync.WaitGroup
+	pool := newHookWorkerPool(size, nil, func(string) { wg.Done() })
+	defer pool.close()
+
+	var maxGoroutines int
+	for i := 0; i < 5000; i++ {
+		wg.Add(1)
+		pool.submit(fmt.Sprintf("plugin%d", i%5), func() {
+			defer wg.Done()
+			time.Sleep(100 * time.Microsecond)
+		})
+
+		if i%100 == 0 {
+			if n := runtime.NumGoroutine(); n > maxGoroutines {
+				maxGoroutines = n
+			}
+		}
+	}
+	wg.Wait()
+
+	assert.True(t, maxGoroutines <= before+size+2, "%d goroutines during the burst, from %d before", maxGoroutines, before)
+}
+
+func BenchmarkHookWorkerPool(b *testing.B) {
+	// Invocations dropped because the workers fell behind count as handled.
+	var wg sync.WaitGroup
+	pool := newHookWorkerPool(DefaultHookWorkerPoolSize(), nil, func(string) { wg.Done() })
+	defer pool.close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		wg.Add(1)
+		pool.submit(fmt.Sprintf("plugin%d", i%5), wg.Done)
+	}
+	wg.Wait()
+}
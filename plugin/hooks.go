@@ -15,24 +15,42 @@ import (
 // Feel free to add more, but do not change existing assignments. Follow the naming convention of
 // <HookName>Id as the autogenerated glue code depends on that.
 const (
-	OnActivateId            = 0
-	OnDeactivateId          = 1
-	ServeHTTPId             = 2
-	OnConfigurationChangeId = 3
-	ExecuteCommandId        = 4
-	MessageWillBePostedId   = 5
-	MessageWillBeUpdatedId  = 6
-	MessageHasBeenPostedId  = 7
-	MessageHasBeenUpdatedId = 8
-	UserHasJoinedChannelId  = 9
-	UserHasLeftChannelId    = 10
-	UserHasJoinedTeamId     = 11
-	UserHasLeftTeamId       = 12
-	ChannelHasBeenCreatedId = 13
-	FileWillBeUploadedId    = 14
-	UserWillLogInId         = 15
-	UserHasLoggedInId       = 16
-	TotalHooksId            = iota
+	OnActivateId                 = 0
+	OnDeactivateId               = 1
+	ServeHTTPId                  = 2
+	OnConfigurationChangeId      = 3
+	ExecuteCommandId             = 4
+	MessageWillBePostedId        = 5
+	MessageWillBeUpdatedId       = 6
+	MessageHasBeenPostedId       = 7
+	MessageHasBeenUpdatedId      = 8
+	UserHasJoinedChannelId       = 9
+	UserHasLeftChannelId         = 10
+	UserHasJoinedTeamId          = 11
+	UserHasLeftTeamId            = 12
+	ChannelHasBeenCreatedId      = 13
+	FileWillBeUploadedId         = 14
+	UserWillLogInId              = 15
+	UserHasLoggedInId            = 16
+	PostsWillBeExportedId        = 17
+	ServeMetricsId               = 18
+	OnMigrateId                  = 19
+	OnWebSocketConnectId         = 20
+	OnWebSocketDisconnectId      = 21
+	OnPluginActivatedId          = 22
+	OnPluginDeactivatedId        = 23
+	OnDataImportedId             = 24
+	PostHasBeenAcknowledgedId    = 25
+	ChannelExportHasCompletedId  = 26
+	KVHasChangedId               = 27
+	AdminActionHasBeenResolvedId = 28
+	WebappReplyReceivedId        = 29
+	WebappReplyTimedOutId        = 30
+	UserHasBeenCreatedId         = 31
+	UsersHaveBeenCreatedId       = 32
+	TeamMembersHaveChangedId     = 33
+	BulkOperationHasCompletedId  = 34
+	TotalHooksId                 = iota
 )
 
 // Hooks describes the methods a plugin may implement to automatically receive the corresponding
@@ -55,6 +73,15 @@ type Hooks interface {
 	// will stop receiving hooks just prior to this method being called.
 	OnDeactivate() error
 
+	// OnMigrate is invoked when the plugin is activated with a manifest declaring a schema version
+	// greater than the one its key-value data was last migrated to. fromVersion is 0 if the data
+	// was never migrated. If an error is returned, activation of the plugin fails and the stored
+	// schema version is left unchanged.
+	//
+	// OnMigrate is invoked after OnActivate, but before the plugin receives any other hooks, and on
+	// only one server of a cluster at a time.
+	OnMigrate(fromVersion, toVersion int) error
+
 	// OnConfigurationChange is invoked when configuration changes may have been made.
 	OnConfigurationChange() error
 
@@ -62,9 +89,16 @@ type Hooks interface {
 	// the /plugins/{id} path will be routed to the plugin.
 	//
 	// The Mattermost-User-Id header will be present if (and only if) the request is by an
-	// authenticated user.
+	// authenticated user. The id of the plugin serving the request is available from IDFromRequest.
 	ServeHTTP(c *Context, w http.ResponseWriter, r *http.Request)
 
+	// ServeMetrics allows the plugin to expose metrics in the Prometheus text format. When metrics
+	// are enabled, requests to /plugins/{id}/metrics on the metrics listener are routed to the
+	// plugin, so that plugin metrics are scraped alongside those of the server.
+	//
+	// These requests are never authenticated, are rate limited and time out after a few seconds.
+	ServeMetrics(c *Context, w http.ResponseWriter, r *http.Request)
+
 	// ExecuteCommand executes a command that has been previously registered via the RegisterCommand
 	// API.
 	ExecuteCommand(c *Context, args *model.CommandArgs) (*model.CommandResponse, *model.AppError)
@@ -102,6 +136,11 @@ type Hooks interface {
 	// created the post.
 	MessageHasBeenUpdated(c *Context, newPost, oldPost *model.Post)
 
+	// PostHasBeenAcknowledged is invoked after a user has acknowledged a post for which
+	// acknowledgements were requested with API.RequestPostAcknowledgement. It is invoked once per
+	// user, even if the user acknowledges the post again.
+	PostHasBeenAcknowledged(c *Context, postId, userId string)
+
 	// ChannelHasBeenCreated is invoked after the channel has been committed to the database.
 	ChannelHasBeenCreated(c *Context, channel *model.Channel)
 
@@ -121,6 +160,108 @@ type Hooks interface {
 	// If actor is not nil, the user was removed from the team by the actor.
 	UserHasLeftTeam(c *Context, teamMember *model.TeamMember, actor *model.User)
 
+	// UserHasBeenCreated is invoked after a user account has been committed to the database, unless it
+	// was created by an operation creating many users at once, which are reported by
+	// UsersHaveBeenCreated instead.
+	UserHasBeenCreated(c *Context, user *model.User)
+
+	// UsersHaveBeenCreated is invoked in place of UserHasBeenCreated once an operation creating more
+	// than PluginSettings.BulkHookThreshold users at once, such as a bulk import, has completed. Users
+	// are passed in batches of at most PluginSettings.BulkHookBatchSize, and the next batch is only
+	// passed once the plugin has returned from the previous one.
+	//
+	// Plugins that do not implement this hook receive UserHasBeenCreated for each user instead, in
+	// batches paced by PluginSettings.BulkHookPacingMilliseconds.
+	UsersHaveBeenCreated(c *Context, users []*model.User)
+
+	// TeamMembersHaveChanged is invoked in place of UserHasJoinedTeam and UserHasLeftTeam once an
+	// operation changing more than PluginSettings.BulkHookThreshold team memberships at once has
+	// completed. Memberships that were removed have a non-zero DeleteAt. They are batched like the
+	// users passed to UsersHaveBeenCreated, and likewise delivered as paced UserHasJoinedTeam and
+	// UserHasLeftTeam hooks, without an actor, to plugins that do not implement this hook.
+	TeamMembersHaveChanged(c *Context, teamMembers []*model.TeamMember)
+
+	// BulkOperationHasCompleted is invoked once the hooks reporting the changes made by an operation
+	// changing many users or team memberships at once have been delivered to the plugin, so that it
+	// may reconcile its data rather than tracking each change. opType is one of the
+	// model.BULK_OPERATION_TYPE_* constants, and counts holds the number of changes of each kind,
+	// keyed by the model.BULK_OPERATION_COUNT_* constants.
+	BulkOperationHasCompleted(opType string, counts map[string]int)
+
+	// OnWebSocketConnect is invoked after a websocket connection is opened and authenticated for a
+	// user. webConnID identifies the connection for as long as it stays open, and may be passed to
+	// API.WebSocketBroadcastToConnection. Connection ids are not preserved across server restarts.
+	//
+	// This hook is invoked asynchronously, and only for plugins that implement it.
+	OnWebSocketConnect(webConnID, userId string)
+
+	// OnWebSocketDisconnect is invoked after a websocket connection previously reported by
+	// OnWebSocketConnect is closed.
+	//
+	// This hook is invoked asynchronously, and only for plugins that implement it.
+	OnWebSocketDisconnect(webConnID, userId string)
+
+	// OnPluginActivated is invoked after another plugin is activated, including when the server
+	// starts up, with the manifest of that plugin. It is not invoked for the plugin itself.
+	//
+	// This hook is invoked asynchronously, after any plugin_enabled websocket event for the plugin
+	// has been published. Plugin lifecycle hooks are delivered one at a time in the order the
+	// changes occurred, so a plugin may safely enable or disable other plugins in response.
+	OnPluginActivated(manifest *model.Manifest)
+
+	// OnPluginDeactivated is invoked after another plugin is deactivated or removed, with the
+	// manifest of that plugin. It is not invoked when the server shuts down.
+	//
+	// Like OnPluginActivated, this hook is invoked asynchronously, after any plugin_disabled
+	// websocket event for the plugin has been published.
+	OnPluginDeactivated(manifest *model.Manifest)
+
+	// OnDataImported is invoked after a bulk import has been applied, including any key-value data
+	// exported from the plugin on another server. Since the ids of imported users, teams and
+	// channels differ from those on the exporting server, plugins may use this hook to fix up any
+	// references to them kept in their data.
+	OnDataImported()
+
+	// ChannelExportHasCompleted is invoked once a channel export requested by the plugin with
+	// API.ExportChannel has been written to the file store, identifying the job returned by
+	// API.ExportChannel and the info of the exported file. Exports that fail do not invoke the hook,
+	// but are reported by API.GetExportStatus.
+	//
+	// The hook is only invoked for the plugin that requested the export, on whichever server ran it.
+	ChannelExportHasCompleted(jobId, fileId string)
+
+	// KVHasChanged is invoked after one of the plugin's keys has been set or deleted through the
+	// API, including by batch, compare-and-set and increment calls, so that plugins caching their
+	// own key-value data can invalidate it. It is invoked on every server of a cluster, including the
+	// one that made the change, with only the key; the new value must be read with API.KVGet. When
+	// API.KVDeleteAll deletes all of the plugin's keys, it is invoked once with an empty key.
+	//
+	// This hook is invoked asynchronously and on a best-effort basis: notifications may be lost or
+	// arrive out of order, and are dropped while the plugin changes its keys faster than a few times
+	// a second.
+	KVHasChanged(key string)
+
+	// AdminActionHasBeenResolved is invoked once a request made by the plugin with
+	// API.RequestAdminAction is no longer pending, identifying the request returned by
+	// API.RequestAdminAction. Approved is true only if a system admin approved the request and the
+	// server carried out the action; it is false if the request was denied, expired, or the action
+	// failed.
+	//
+	// The hook is only invoked for the plugin that made the request, on whichever server resolved it.
+	AdminActionHasBeenResolved(requestId string, approved bool)
+
+	// WebappReplyReceived is invoked when the plugin's webapp component replies to a message sent
+	// with API.SendToUserWebapp, identifying the message by the reply id returned by
+	// API.SendToUserWebapp. Only the first reply to a message is passed on, and only from the user the
+	// message was sent to.
+	//
+	// The hook is invoked on whichever server the message was sent from.
+	WebappReplyReceived(replyId string, payload []byte)
+
+	// WebappReplyTimedOut is invoked when no reply to a message sent with API.SendToUserWebapp was
+	// received within the message's timeout, in which case WebappReplyReceived is not invoked for it.
+	WebappReplyTimedOut(replyId string)
+
 	// UserWillLogIn before the login of the user is returned. Returning a non empty string will reject the login event.
 	// If you don't need to reject the login event, see UserHasLoggedIn
 	UserWillLogIn(c *Context, user *model.User) string
@@ -134,4 +275,13 @@ type Hooks interface {
 	// Note that this method will be called for files uploaded by plugins, including the plugin that uploaded the post.
 	// FileInfo.Size will be automatically set properly if you modify the file.
 	FileWillBeUploaded(c *Context, info *model.FileInfo, file io.Reader, output io.Writer) (*model.FileInfo, string)
+
+	// PostsWillBeExported is invoked by compliance, message and channel exports with a batch of posts
+	// before they are written to the export. exportType is one of the model.COMPLIANCE_EXPORT_TYPE_*
+	// values, or model.CHANNEL_EXPORT_TYPE.
+	// Return the posts with any sensitive props redacted or removed.
+	//
+	// Only changes to props whose keys begin with "<plugin id>_" are kept; every other change to the
+	// returned posts is discarded, as is the whole batch if the number of posts returned differs.
+	PostsWillBeExported(c *Context, posts []*model.Post, exportType string) []*model.Post
 }
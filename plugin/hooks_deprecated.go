@@ -0,0 +1,79 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package plugin
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// deprecatedHook describes an older signature of a hook that plugins may still implement.
+//
+// Plugins implementing one report it during activation under a versioned name, and the server
+// treats them as implementing the hook, logging a deprecation warning. Calls to the hook are
+// adapted to the older signature by dropping the parameters and results it lacks, so a signature
+// is only listed here when doing so is safe. Entries are removed one release cycle after the
+// signature changed.
+type deprecatedHook struct {
+	hookName string
+	hookId   int
+	version  int
+
+	// signature is an interface with a single method, the older signature of the hook.
+	signature reflect.Type
+}
+
+// name returns the name under which plugins report implementing the deprecated signature.
+func (h deprecatedHook) name() string {
+	return fmt.Sprintf("%s@v%d", h.hookName, h.version)
+}
+
+// deprecatedServeHTTP is the signature of ServeHTTP before the plugin context was passed to it.
+type deprecatedServeHTTP interface {
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+}
+
+// deprecatedOnConfigurationChange is the signature of OnConfigurationChange before it could reject
+// a configuration by returning an error.
+type deprecatedOnConfigurationChange interface {
+	OnConfigurationChange()
+}
+
+var deprecatedHooks = []deprecatedHook{
+	{
+		hookName:  "ServeHTTP",
+		hookId:    ServeHTTPId,
+		version:   1,
+		signature: reflect.TypeOf((*deprecatedServeHTTP)(nil)).Elem(),
+	},
+	{
+		hookName:  "OnConfigurationChange",
+		hookId:    OnConfigurationChangeId,
+		version:   1,
+		signature: reflect.TypeOf((*deprecatedOnConfigurationChange)(nil)).Elem(),
+	},
+}
+
+// deprecatedHooksByName indexes deprecatedHooks by the names under which they are reported.
+var deprecatedHooksByName = make(map[string]deprecatedHook)
+
+func init() {
+	for _, hook := range deprecatedHooks {
+		deprecatedHooksByName[hook.name()] = hook
+	}
+}
+
+// implementedDeprecatedHooks returns the names of the deprecated hook signatures implemented by
+// impl.
+func implementedDeprecatedHooks(impl interface{}) []string {
+	implType := reflect.TypeOf(impl)
+	var names []string
+	for _, hook := range deprecatedHooks {
+		if implType.Implements(hook.signature) {
+			names = append(names, hook.name())
+		}
+	}
+	return names
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package plugin_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+)
+
+func TestDeprecatedHooks(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Hook             string
+		Method           string
+		DeprecatedLogged bool
+	}{
+		"ServeHTTP": {
+			Hook: "ServeHTTP",
+			Method: `
+				func (p *MyPlugin) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
+					w.Write([]byte("served " + r.URL.Path))
+				}
+			`,
+		},
+		"ServeHTTP without context": {
+			Hook: "ServeHTTP",
+			Method: `
+				func (p *MyPlugin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+					w.Write([]byte("served " + r.URL.Path))
+				}
+			`,
+			DeprecatedLogged: true,
+		},
+		"OnConfigurationChange": {
+			Hook: "OnConfigurationChange",
+			Method: `
+				func (p *MyPlugin) OnConfigurationChange() error {
+					p.API.LogInfo("configuration changed")
+					return nil
+				}
+			`,
+		},
+		"OnConfigurationChange without error": {
+			Hook: "OnConfigurationChange",
+			Method: `
+				func (p *MyPlugin) OnConfigurationChange() {
+					p.API.LogInfo("configuration changed")
+				}
+			`,
+			DeprecatedLogged: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			pluginDir, err := ioutil.TempDir("", "")
+			require.NoError(t, err)
+			defer os.RemoveAll(pluginDir)
+
+			compilePlugin(t, `
+				package main
+
+				import (
+					"net/http"
+
+					"github.com/mattermost/mattermost-server/plugin"
+				)
+
+				var _ http.Handler
+
+				type MyPlugin struct {
+					plugin.MattermostPlugin
+				}
+				`+tc.Method+`
+				func main() {
+					plugin.ClientMain(&MyPlugin{})
+				}
+			`, filepath.Join(pluginDir, "testplugin", "backend.exe"))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(pluginDir, "testplugin", "plugin.json"), []byte(`{"id": "testplugin", "backend": {"executable": "backend.exe"}}`), 0600))
+
+			logFile := filepath.Join(pluginDir, "server.log")
+			logger := mlog.NewLogger(&mlog.LoggerConfiguration{
+				EnableFile:   true,
+				FileJson:     true,
+				FileLevel:    "debug",
+				FileLocation: logFile,
+			})
+
+			api := &plugintest.API{}
+			api.On("LoadPluginConfiguration", mock.Anything).Return(nil).Maybe()
+			if tc.Hook == "OnConfigurationChange" {
+				// Once on activation, and once when invoked below.
+				api.On("LogInfo", "configuration changed").Return().Twice()
+			}
+			defer api.AssertExpectations(t)
+
+			env, err := plugin.NewEnvironment(func(*model.Manifest) plugin.API { return api }, pluginDir, pluginDir, logger)
+			require.NoError(t, err)
+			defer env.Shutdown()
+
+			_, _, err = env.Activate("testplugin")
+			require.NoError(t, err)
+
+			hooks, err := env.HooksForPlugin("testplugin")
+			require.NoError(t, err)
+
+			switch tc.Hook {
+			case "ServeHTTP":
+				assert.True(t, env.IsHookImplemented(plugin.ServeHTTPId))
+
+				w := httptest.NewRecorder()
+				hooks.ServeHTTP(&plugin.Context{}, w, httptest.NewRequest(http.MethodGet, "/path", nil))
+				assert.Equal(t, http.StatusOK, w.Code)
+				assert.Equal(t, "served /path", w.Body.String())
+			case "OnConfigurationChange":
+				assert.True(t, env.IsHookImplemented(plugin.OnConfigurationChangeId))
+				assert.NoError(t, hooks.OnConfigurationChange())
+			}
+
+			logs, err := ioutil.ReadFile(logFile)
+			require.NoError(t, err)
+
+			logged := false
+			for _, line := range strings.Split(string(logs), "\n") {
+				var record map[string]interface{}
+				if json.Unmarshal([]byte(line), &record) == nil && strings.Contains(record["msg"].(string), "deprecated signature") {
+					assert.Equal(t, "testplugin", record["plugin_id"])
+					assert.Equal(t, tc.Hook+"@v1", record["hook"])
+					logged = true
+				}
+			}
+			assert.Equal(t, tc.DeprecatedLogged, logged)
+		})
+	}
+}
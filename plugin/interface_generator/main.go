@@ -69,6 +69,21 @@ func FieldListToNames(fieldList *ast.FieldList, fileset *token.FileSet) string {
 	return strings.Join(result, ", ")
 }
 
+// FieldListHasContext returns whether the first parameter is a *Context, as passed to hooks invoked
+// for a request or event.
+func FieldListHasContext(fieldList *ast.FieldList, fileset *token.FileSet) bool {
+	if fieldList == nil || len(fieldList.List) == 0 {
+		return false
+	}
+
+	typeNameBuffer := &bytes.Buffer{}
+	if err := printer.Fprint(typeNameBuffer, fileset, fieldList.List[0].Type); err != nil {
+		panic(err)
+	}
+
+	return typeNameBuffer.String() == "*Context"
+}
+
 func FieldListDestruct(structPrefix string, fieldList *ast.FieldList, fileset *token.FileSet) string {
 	result := []string{}
 	if fieldList == nil || len(fieldList.List) == 0 {
@@ -130,6 +145,84 @@ func FieldListToStructList(fieldList *ast.FieldList, fileset *token.FileSet) str
 	return strings.Join(result, "\n\t")
 }
 
+// FieldListToNamedResults names the results of a method _r0, _r1 and so on, so that they can be
+// returned without listing their zero values.
+func FieldListToNamedResults(fieldList *ast.FieldList, fileset *token.FileSet) string {
+	if fieldList == nil || len(fieldList.List) == 0 {
+		return ""
+	}
+
+	result := []string{}
+	for _, field := range fieldList.List {
+		typeNameBuffer := &bytes.Buffer{}
+		if err := printer.Fprint(typeNameBuffer, fileset, field.Type); err != nil {
+			panic(err)
+		}
+
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			result = append(result, fmt.Sprintf("_r%d %s", len(result), typeNameBuffer.String()))
+		}
+	}
+
+	return "(" + strings.Join(result, ", ") + ")"
+}
+
+// FieldListToErrorAssignments assigns the given error to each of the named results of a method that
+// is an error or *model.AppError.
+func FieldListToErrorAssignments(errName string, fieldList *ast.FieldList, fileset *token.FileSet) string {
+	if fieldList == nil || len(fieldList.List) == 0 {
+		return ""
+	}
+
+	result := ""
+	index := 0
+	for _, field := range fieldList.List {
+		typeNameBuffer := &bytes.Buffer{}
+		if err := printer.Fprint(typeNameBuffer, fileset, field.Type); err != nil {
+			panic(err)
+		}
+		typeName := typeNameBuffer.String()
+
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			if typeName == "error" || typeName == "*model.AppError" {
+				result += fmt.Sprintf("_r%d = %s\n\t\t", index, errName)
+			}
+			index++
+		}
+	}
+
+	return result
+}
+
+// FieldListToCallArgs lists the names of the parameters of a method to pass them on to another
+// call, expanding any variadic parameter.
+func FieldListToCallArgs(fieldList *ast.FieldList, fileset *token.FileSet) string {
+	result := []string{}
+	if fieldList == nil || len(fieldList.List) == 0 {
+		return ""
+	}
+	for _, field := range fieldList.List {
+		_, variadic := field.Type.(*ast.Ellipsis)
+		for _, name := range field.Names {
+			if variadic {
+				result = append(result, name.Name+"...")
+			} else {
+				result = append(result, name.Name)
+			}
+		}
+	}
+
+	return strings.Join(result, ", ")
+}
+
 func goList(dir string) ([]string, error) {
 	cmd := exec.Command("go", "list", "-f", "{{.Dir}}", dir)
 	bytes, err := cmd.Output()
@@ -226,7 +319,7 @@ func (g *hooksRPCClient) {{.Name}}{{funcStyle .Params}} {{funcStyle .Return}} {
 	_returns := &{{.Name | obscure}}Returns{}
 	if g.implemented[{{.Name}}Id] {
 		if err := g.client.Call("Plugin.{{.Name}}", _args, _returns); err != nil {
-			g.log.Error("RPC call {{.Name}} to plugin failed.", mlog.Err(err))
+			g.log.Error("RPC call {{.Name}} to plugin failed.", {{if hasContext .Params}}mlog.String("request_id", _args.A.requestId()), {{end}}mlog.Err(err))
 		}
 	}
 	return {{destruct "_returns." .Return}}
@@ -236,7 +329,8 @@ func (s *hooksRPCServer) {{.Name}}(args *{{.Name | obscure}}Args, returns *{{.Na
 	if hook, ok := s.impl.(interface {
 		{{.Name}}{{funcStyle .Params}} {{funcStyle .Return}}
 	}); ok {
-		{{if .Return}}{{destruct "returns." .Return}} = {{end}}hook.{{.Name}}({{destruct "args." .Params}})
+		{{if hasContext .Params}}defer s.requests.begin(args.A)()
+		{{end}}{{if .Return}}{{destruct "returns." .Return}} = {{end}}hook.{{.Name}}({{destruct "args." .Params}})
 	} else {
 		return fmt.Errorf("Hook {{.Name}} called but not implemented.")
 	}
@@ -276,6 +370,25 @@ func (s *apiRPCServer) {{.Name}}(args *{{.Name | obscure}}Args, returns *{{.Name
 {{end}}
 `
 
+var capabilitiesTemplate = `// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+// Code generated by "make pluginapi"
+// DO NOT EDIT
+
+package plugin
+
+{{range .APIMethods}}
+
+func (_a *capabilityCheckedAPI) {{.Name}}{{funcStyle .Params}} {{namedResults .Return}} {
+	if _err := _a.check("{{.Name}}"); _err != nil {
+		{{errorAssignments "_err" .Return}}return
+	}
+	{{if .Return}}return {{end}}_a.api.{{.Name}}({{callArgs .Params}})
+}
+{{end}}
+`
+
 type MethodParams struct {
 	Name   string
 	Params *ast.FieldList
@@ -287,8 +400,8 @@ type HooksTemplateParams struct {
 	APIMethods   []MethodParams
 }
 
-func generateGlue(info *PluginInterfaceInfo) {
-	templateFunctions := map[string]interface{}{
+func makeTemplateFunctions(info *PluginInterfaceInfo) map[string]interface{} {
+	return map[string]interface{}{
 		"funcStyle":   func(fields *ast.FieldList) string { return FieldListToFuncList(fields, info.FileSet) },
 		"structStyle": func(fields *ast.FieldList) string { return FieldListToStructList(fields, info.FileSet) },
 		"valuesOnly":  func(fields *ast.FieldList) string { return FieldListToNames(fields, info.FileSet) },
@@ -298,9 +411,36 @@ func generateGlue(info *PluginInterfaceInfo) {
 		"obscure": func(name string) string {
 			return "Z_" + name
 		},
+		"hasContext": func(fields *ast.FieldList) bool {
+			return FieldListHasContext(fields, info.FileSet)
+		},
+		"namedResults": func(fields *ast.FieldList) string { return FieldListToNamedResults(fields, info.FileSet) },
+		"errorAssignments": func(errName string, fields *ast.FieldList) string {
+			return FieldListToErrorAssignments(errName, fields, info.FileSet)
+		},
+		"callArgs": func(fields *ast.FieldList) string { return FieldListToCallArgs(fields, info.FileSet) },
 	}
+}
 
-	hooksTemplate, err := template.New("hooks").Funcs(templateFunctions).Parse(hooksTemplate)
+// writeGenerated formats the generated code with goimports and writes it to the given file of the
+// plugin package.
+func writeGenerated(code *bytes.Buffer, fileName string) {
+	importsBuffer := &bytes.Buffer{}
+	cmd := exec.Command("goimports")
+	cmd.Stdin = code
+	cmd.Stdout = importsBuffer
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		panic(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(getPluginPackageDir(), fileName), importsBuffer.Bytes(), 0664); err != nil {
+		panic(err)
+	}
+}
+
+func generateGlue(info *PluginInterfaceInfo) {
+	hooksTemplate, err := template.New("hooks").Funcs(makeTemplateFunctions(info)).Parse(hooksTemplate)
 	if err != nil {
 		panic(err)
 	}
@@ -323,18 +463,29 @@ func generateGlue(info *PluginInterfaceInfo) {
 	templateResult := &bytes.Buffer{}
 	hooksTemplate.Execute(templateResult, &templateParams)
 
-	importsBuffer := &bytes.Buffer{}
-	cmd := exec.Command("goimports")
-	cmd.Stdin = templateResult
-	cmd.Stdout = importsBuffer
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
+	writeGenerated(templateResult, "client_rpc_generated.go")
+}
+
+// generateCapabilityChecks generates the methods of capabilityCheckedAPI, checking the capabilities
+// of the plugin before every call to the API, including those excluded from the RPC glue.
+func generateCapabilityChecks(info *PluginInterfaceInfo) {
+	capabilitiesTemplate, err := template.New("capabilities").Funcs(makeTemplateFunctions(info)).Parse(capabilitiesTemplate)
+	if err != nil {
 		panic(err)
 	}
 
-	if err := ioutil.WriteFile(filepath.Join(getPluginPackageDir(), "client_rpc_generated.go"), importsBuffer.Bytes(), 0664); err != nil {
-		panic(err)
+	templateParams := HooksTemplateParams{}
+	for _, api := range info.API {
+		templateParams.APIMethods = append(templateParams.APIMethods, MethodParams{
+			Name:   api.FuncName,
+			Params: api.Args,
+			Return: api.Results,
+		})
 	}
+	templateResult := &bytes.Buffer{}
+	capabilitiesTemplate.Execute(templateResult, &templateParams)
+
+	writeGenerated(templateResult, "api_capabilities_generated.go")
 }
 
 func getPluginPackageDir() string {
@@ -355,7 +506,18 @@ func removeExcluded(info *PluginInterfaceInfo) *PluginInterfaceInfo {
 			"Implemented",
 			"LoadPluginConfiguration",
 			"ServeHTTP",
+			"ServeMetrics",
+			"OnConfigurationChange",
 			"FileWillBeUploaded",
+			"KVSet",
+			"KVGet",
+			"KVGetWithExists",
+			"KVGetWithOptions",
+			"GetRequestId",
+			"LogDebug",
+			"LogInfo",
+			"LogError",
+			"LogWarn",
 		}
 		for _, exclusion := range excluded {
 			if exclusion == item {
@@ -392,6 +554,8 @@ func main() {
 		fmt.Println("Unable to get plugin info: " + err.Error())
 	}
 
+	generateCapabilityChecks(info)
+
 	info = removeExcluded(info)
 
 	generateGlue(info)
@@ -22,17 +22,56 @@ func (rwc *rwc) Close() (err error) {
 	return
 }
 
+// remoteIOReader reads from an io.Reader served over conn by serveIOReader. Each read requests up to
+// len(b) bytes, answered by a frame whose varint header is the number of bytes that follow, zero at
+// the end of the stream, or the negated length of the message of an error the served reader failed
+// with, which is returned as a remoteIOError.
 type remoteIOReader struct {
 	conn io.ReadWriteCloser
+	r    *bufio.Reader
+}
+
+// remoteIOError is the error returned by a remote reader when the reader it was served from failed.
+type remoteIOError string
+
+func (e remoteIOError) Error() string {
+	return string(e)
 }
 
 func (r *remoteIOReader) Read(b []byte) (int, error) {
-	var buf [10]byte
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	var buf [binary.MaxVarintLen64]byte
 	n := binary.PutVarint(buf[:], int64(len(b)))
 	if _, err := r.conn.Write(buf[:n]); err != nil {
 		return 0, err
 	}
-	return r.conn.Read(b)
+
+	size, err := binary.ReadVarint(r.r)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case size == 0:
+		return 0, io.EOF
+	case size > 0:
+		if size > int64(len(b)) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return io.ReadFull(r.r, b[:size])
+	default:
+		if -size > maxRemoteIOErrorLength {
+			return 0, io.ErrUnexpectedEOF
+		}
+		message := make([]byte, -size)
+		if _, err := io.ReadFull(r.r, message); err != nil {
+			return 0, err
+		}
+		return 0, remoteIOError(message)
+	}
 }
 
 func (r *remoteIOReader) Close() error {
@@ -40,20 +79,63 @@ func (r *remoteIOReader) Close() error {
 }
 
 func connectIOReader(conn io.ReadWriteCloser) io.ReadCloser {
-	return &remoteIOReader{conn}
+	return &remoteIOReader{conn: conn, r: bufio.NewReader(conn)}
 }
 
+// maxRemoteIOErrorLength is the longest error message sent over a stream served by serveIOReader.
+const maxRemoteIOErrorLength = 1024
+
+// serveIOReader serves r over conn to a reader connected with connectIOReader, until r is exhausted,
+// fails, or conn is closed. Errors other than io.EOF are passed on to the remote reader, so that a
+// failed read is never mistaken for the end of the stream.
 func serveIOReader(r io.Reader, conn io.ReadWriteCloser) {
 	cr := bufio.NewReader(conn)
 	defer conn.Close()
-	buf := make([]byte, 32*1024)
+	buf := make([]byte, binary.MaxVarintLen64+32*1024)
+	data := buf[binary.MaxVarintLen64:]
+	var readErr error
 	for {
 		n, err := binary.ReadVarint(cr)
-		if err != nil {
+		if err != nil || n <= 0 {
 			break
 		}
-		if written, err := io.CopyBuffer(conn, io.LimitReader(r, n), buf); err != nil || written < n {
+		if n > int64(len(data)) {
+			n = int64(len(data))
+		}
+
+		read := 0
+		for read == 0 && readErr == nil {
+			read, readErr = r.Read(data[:n])
+		}
+
+		// Data read along with an error is sent first, and the error with the next frame. The header
+		// is written just before the data, so that the frame is sent with a single write.
+		if read > 0 {
+			var header [binary.MaxVarintLen64]byte
+			headerLen := binary.PutVarint(header[:], int64(read))
+			start := binary.MaxVarintLen64 - headerLen
+			copy(buf[start:], header[:headerLen])
+			if _, err := conn.Write(buf[start : binary.MaxVarintLen64+read]); err != nil {
+				break
+			}
+			continue
+		}
+
+		if readErr == io.EOF {
+			headerLen := binary.PutVarint(buf, 0)
+			conn.Write(buf[:headerLen])
 			break
 		}
+
+		message := readErr.Error()
+		if message == "" {
+			message = "remote read failed"
+		}
+		if len(message) > maxRemoteIOErrorLength {
+			message = message[:maxRemoteIOErrorLength]
+		}
+		headerLen := binary.PutVarint(buf, -int64(len(message)))
+		conn.Write(append(buf[:headerLen:headerLen], message...))
+		break
 	}
 }
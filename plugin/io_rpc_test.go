@@ -0,0 +1,98 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/mlog"
+)
+
+func TestRemoteIOReader(t *testing.T) {
+	serve := func(r io.Reader) io.ReadCloser {
+		local, remote := net.Pipe()
+		go serveIOReader(r, remote)
+		return connectIOReader(local)
+	}
+
+	t.Run("whole stream", func(t *testing.T) {
+		data := bytes.Repeat([]byte("abcdefgh"), 20*1024)
+		r := serve(bytes.NewReader(data))
+		defer r.Close()
+
+		received, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, data, received)
+	})
+
+	t.Run("failed read", func(t *testing.T) {
+		failure := errors.New("http: request body too large")
+		r := serve(io.MultiReader(bytes.NewReader([]byte("partial")), &failingReader{failure}))
+		defer r.Close()
+
+		// The failure is not mistaken for the end of the stream.
+		received, err := ioutil.ReadAll(r)
+		require.Error(t, err)
+		assert.NotEqual(t, io.EOF, err)
+		assert.Equal(t, failure.Error(), err.Error())
+		assert.Equal(t, []byte("partial"), received)
+	})
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+type bodyReadingPlugin struct {
+	apiTestPlugin
+	readErr error
+}
+
+func (p *bodyReadingPlugin) ServeHTTP(c *Context, w http.ResponseWriter, r *http.Request) {
+	if _, p.readErr = ioutil.ReadAll(r.Body); p.readErr != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestServeHTTPBodyReadError(t *testing.T) {
+	p := &bodyReadingPlugin{}
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		"hooks": &hooksPlugin{
+			hooks: p,
+			log:   mlog.NewLogger(&mlog.LoggerConfiguration{}),
+		},
+	}, nil)
+
+	raw, err := client.Dispense("hooks")
+	require.NoError(t, err)
+	hooks := raw.(Hooks)
+	_, err = hooks.Implemented()
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(make([]byte, 64*1024)))
+	r.Body = http.MaxBytesReader(w, r.Body, 1024)
+	hooks.ServeHTTP(&Context{}, w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	require.Error(t, p.readErr)
+	assert.Contains(t, p.readErr.Error(), "request body too large")
+}
@@ -6,19 +6,21 @@ package plugintest
 
 import mock "github.com/stretchr/testify/mock"
 import model "github.com/mattermost/mattermost-server/model"
+import plugin "github.com/mattermost/mattermost-server/plugin"
+import time "time"
 
 // API is an autogenerated mock type for the API type
 type API struct {
 	mock.Mock
 }
 
-// AddChannelMember provides a mock function with given fields: channelId, userId
-func (_m *API) AddChannelMember(channelId string, userId string) (*model.ChannelMember, *model.AppError) {
-	ret := _m.Called(channelId, userId)
+// AddChannelMember provides a mock function with given fields: channelId, userId, opts
+func (_m *API) AddChannelMember(channelId string, userId string, opts model.MemberAddOptions) (*model.ChannelMember, *model.AppError) {
+	ret := _m.Called(channelId, userId, opts)
 
 	var r0 *model.ChannelMember
-	if rf, ok := ret.Get(0).(func(string, string) *model.ChannelMember); ok {
-		r0 = rf(channelId, userId)
+	if rf, ok := ret.Get(0).(func(string, string, model.MemberAddOptions) *model.ChannelMember); ok {
+		r0 = rf(channelId, userId, opts)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.ChannelMember)
@@ -26,8 +28,49 @@ func (_m *API) AddChannelMember(channelId string, userId string) (*model.Channel
 	}
 
 	var r1 *model.AppError
-	if rf, ok := ret.Get(1).(func(string, string) *model.AppError); ok {
-		r1 = rf(channelId, userId)
+	if rf, ok := ret.Get(1).(func(string, string, model.MemberAddOptions) *model.AppError); ok {
+		r1 = rf(channelId, userId, opts)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// AddPostMetadata provides a mock function with given fields: postId, namespace, data
+func (_m *API) AddPostMetadata(postId string, namespace string, data []byte) *model.AppError {
+	ret := _m.Called(postId, namespace, data)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string, string, []byte) *model.AppError); ok {
+		r0 = rf(postId, namespace, data)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
+// ConvertChannelToPrivate provides a mock function with given fields: channelId
+func (_m *API) ConvertChannelToPrivate(channelId string) (*model.Channel, *model.AppError) {
+	ret := _m.Called(channelId)
+
+	var r0 *model.Channel
+	if rf, ok := ret.Get(0).(func(string) *model.Channel); ok {
+		r0 = rf(channelId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Channel)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(channelId)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*model.AppError)
@@ -87,6 +130,31 @@ func (_m *API) CreatePost(post *model.Post) (*model.Post, *model.AppError) {
 	return r0, r1
 }
 
+// CreateSubscription provides a mock function with given fields: subscription
+func (_m *API) CreateSubscription(subscription model.PluginSubscription) (*model.PluginSubscription, *model.AppError) {
+	ret := _m.Called(subscription)
+
+	var r0 *model.PluginSubscription
+	if rf, ok := ret.Get(0).(func(model.PluginSubscription) *model.PluginSubscription); ok {
+		r0 = rf(subscription)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PluginSubscription)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(model.PluginSubscription) *model.AppError); ok {
+		r1 = rf(subscription)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 // CreateTeam provides a mock function with given fields: team
 func (_m *API) CreateTeam(team *model.Team) (*model.Team, *model.AppError) {
 	ret := _m.Called(team)
@@ -219,6 +287,22 @@ func (_m *API) DeleteChannelMember(channelId string, userId string) *model.AppEr
 	return r0
 }
 
+// DeleteFile provides a mock function with given fields: fileId
+func (_m *API) DeleteFile(fileId string) *model.AppError {
+	ret := _m.Called(fileId)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string) *model.AppError); ok {
+		r0 = rf(fileId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
 // DeletePost provides a mock function with given fields: postId
 func (_m *API) DeletePost(postId string) *model.AppError {
 	ret := _m.Called(postId)
@@ -235,6 +319,22 @@ func (_m *API) DeletePost(postId string) *model.AppError {
 	return r0
 }
 
+// DeleteSubscription provides a mock function with given fields: id
+func (_m *API) DeleteSubscription(id string) *model.AppError {
+	ret := _m.Called(id)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string) *model.AppError); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
 // DeleteTeam provides a mock function with given fields: teamId
 func (_m *API) DeleteTeam(teamId string) *model.AppError {
 	ret := _m.Called(teamId)
@@ -283,6 +383,86 @@ func (_m *API) DeleteUser(userId string) *model.AppError {
 	return r0
 }
 
+// ExportChannel provides a mock function with given fields: channelId, format
+func (_m *API) ExportChannel(channelId string, format string) (string, *model.AppError) {
+	ret := _m.Called(channelId, format)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(channelId, format)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string) *model.AppError); ok {
+		r1 = rf(channelId, format)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// FollowThreadForUser provides a mock function with given fields: userId, postId
+func (_m *API) FollowThreadForUser(userId string, postId string) *model.AppError {
+	ret := _m.Called(userId, postId)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string, string) *model.AppError); ok {
+		r0 = rf(userId, postId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
+// GetAllowedTeams provides a mock function with given fields:
+func (_m *API) GetAllowedTeams() []string {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// GetBrandImage provides a mock function with given fields:
+func (_m *API) GetBrandImage() ([]byte, *model.AppError) {
+	ret := _m.Called()
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func() []byte); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func() *model.AppError); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 // GetChannel provides a mock function with given fields: channelId
 func (_m *API) GetChannel(channelId string) (*model.Channel, *model.AppError) {
 	ret := _m.Called(channelId)
@@ -383,38 +563,22 @@ func (_m *API) GetChannelMember(channelId string, userId string) (*model.Channel
 	return r0, r1
 }
 
-// GetConfig provides a mock function with given fields:
-func (_m *API) GetConfig() *model.Config {
-	ret := _m.Called()
-
-	var r0 *model.Config
-	if rf, ok := ret.Get(0).(func() *model.Config); ok {
-		r0 = rf()
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Config)
-		}
-	}
-
-	return r0
-}
-
-// GetDirectChannel provides a mock function with given fields: userId1, userId2
-func (_m *API) GetDirectChannel(userId1 string, userId2 string) (*model.Channel, *model.AppError) {
-	ret := _m.Called(userId1, userId2)
+// GetChannelMemberNotifyProps provides a mock function with given fields: channelId, userId
+func (_m *API) GetChannelMemberNotifyProps(channelId string, userId string) (model.StringMap, *model.AppError) {
+	ret := _m.Called(channelId, userId)
 
-	var r0 *model.Channel
-	if rf, ok := ret.Get(0).(func(string, string) *model.Channel); ok {
-		r0 = rf(userId1, userId2)
+	var r0 model.StringMap
+	if rf, ok := ret.Get(0).(func(string, string) model.StringMap); ok {
+		r0 = rf(channelId, userId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Channel)
+			r0 = ret.Get(0).(model.StringMap)
 		}
 	}
 
 	var r1 *model.AppError
 	if rf, ok := ret.Get(1).(func(string, string) *model.AppError); ok {
-		r1 = rf(userId1, userId2)
+		r1 = rf(channelId, userId)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*model.AppError)
@@ -424,22 +588,22 @@ func (_m *API) GetDirectChannel(userId1 string, userId2 string) (*model.Channel,
 	return r0, r1
 }
 
-// GetGroupChannel provides a mock function with given fields: userIds
-func (_m *API) GetGroupChannel(userIds []string) (*model.Channel, *model.AppError) {
-	ret := _m.Called(userIds)
+// GetChannelMembersModifiedSince provides a mock function with given fields: channelId, since
+func (_m *API) GetChannelMembersModifiedSince(channelId string, since int64) (*model.ChannelMemberChanges, *model.AppError) {
+	ret := _m.Called(channelId, since)
 
-	var r0 *model.Channel
-	if rf, ok := ret.Get(0).(func([]string) *model.Channel); ok {
-		r0 = rf(userIds)
+	var r0 *model.ChannelMemberChanges
+	if rf, ok := ret.Get(0).(func(string, int64) *model.ChannelMemberChanges); ok {
+		r0 = rf(channelId, since)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Channel)
+			r0 = ret.Get(0).(*model.ChannelMemberChanges)
 		}
 	}
 
 	var r1 *model.AppError
-	if rf, ok := ret.Get(1).(func([]string) *model.AppError); ok {
-		r1 = rf(userIds)
+	if rf, ok := ret.Get(1).(func(string, int64) *model.AppError); ok {
+		r1 = rf(channelId, since)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*model.AppError)
@@ -449,22 +613,22 @@ func (_m *API) GetGroupChannel(userIds []string) (*model.Channel, *model.AppErro
 	return r0, r1
 }
 
-// GetPost provides a mock function with given fields: postId
-func (_m *API) GetPost(postId string) (*model.Post, *model.AppError) {
-	ret := _m.Called(postId)
+// GetChannelModeration provides a mock function with given fields: channelId
+func (_m *API) GetChannelModeration(channelId string) (*model.ChannelModeration, *model.AppError) {
+	ret := _m.Called(channelId)
 
-	var r0 *model.Post
-	if rf, ok := ret.Get(0).(func(string) *model.Post); ok {
-		r0 = rf(postId)
+	var r0 *model.ChannelModeration
+	if rf, ok := ret.Get(0).(func(string) *model.ChannelModeration); ok {
+		r0 = rf(channelId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Post)
+			r0 = ret.Get(0).(*model.ChannelModeration)
 		}
 	}
 
 	var r1 *model.AppError
 	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
-		r1 = rf(postId)
+		r1 = rf(channelId)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*model.AppError)
@@ -474,22 +638,22 @@ func (_m *API) GetPost(postId string) (*model.Post, *model.AppError) {
 	return r0, r1
 }
 
-// GetPublicChannelsForTeam provides a mock function with given fields: teamId, offset, limit
-func (_m *API) GetPublicChannelsForTeam(teamId string, offset int, limit int) (*model.ChannelList, *model.AppError) {
-	ret := _m.Called(teamId, offset, limit)
+// GetChannelStats provides a mock function with given fields: channelId
+func (_m *API) GetChannelStats(channelId string) (*model.ChannelStats, *model.AppError) {
+	ret := _m.Called(channelId)
 
-	var r0 *model.ChannelList
-	if rf, ok := ret.Get(0).(func(string, int, int) *model.ChannelList); ok {
-		r0 = rf(teamId, offset, limit)
+	var r0 *model.ChannelStats
+	if rf, ok := ret.Get(0).(func(string) *model.ChannelStats); ok {
+		r0 = rf(channelId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.ChannelList)
+			r0 = ret.Get(0).(*model.ChannelStats)
 		}
 	}
 
 	var r1 *model.AppError
-	if rf, ok := ret.Get(1).(func(string, int, int) *model.AppError); ok {
-		r1 = rf(teamId, offset, limit)
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(channelId)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*model.AppError)
@@ -499,22 +663,38 @@ func (_m *API) GetPublicChannelsForTeam(teamId string, offset int, limit int) (*
 	return r0, r1
 }
 
-// GetTeam provides a mock function with given fields: teamId
-func (_m *API) GetTeam(teamId string) (*model.Team, *model.AppError) {
-	ret := _m.Called(teamId)
+// GetConfig provides a mock function with given fields:
+func (_m *API) GetConfig() *model.Config {
+	ret := _m.Called()
 
-	var r0 *model.Team
-	if rf, ok := ret.Get(0).(func(string) *model.Team); ok {
-		r0 = rf(teamId)
+	var r0 *model.Config
+	if rf, ok := ret.Get(0).(func() *model.Config); ok {
+		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Team)
+			r0 = ret.Get(0).(*model.Config)
+		}
+	}
+
+	return r0
+}
+
+// GetDirectChannel provides a mock function with given fields: userId1, userId2
+func (_m *API) GetDirectChannel(userId1 string, userId2 string) (*model.Channel, *model.AppError) {
+	ret := _m.Called(userId1, userId2)
+
+	var r0 *model.Channel
+	if rf, ok := ret.Get(0).(func(string, string) *model.Channel); ok {
+		r0 = rf(userId1, userId2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Channel)
 		}
 	}
 
 	var r1 *model.AppError
-	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
-		r1 = rf(teamId)
+	if rf, ok := ret.Get(1).(func(string, string) *model.AppError); ok {
+		r1 = rf(userId1, userId2)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*model.AppError)
@@ -524,22 +704,22 @@ func (_m *API) GetTeam(teamId string) (*model.Team, *model.AppError) {
 	return r0, r1
 }
 
-// GetTeamByName provides a mock function with given fields: name
-func (_m *API) GetTeamByName(name string) (*model.Team, *model.AppError) {
-	ret := _m.Called(name)
+// GetExportStatus provides a mock function with given fields: jobId
+func (_m *API) GetExportStatus(jobId string) (*model.ChannelExportStatus, *model.AppError) {
+	ret := _m.Called(jobId)
 
-	var r0 *model.Team
-	if rf, ok := ret.Get(0).(func(string) *model.Team); ok {
-		r0 = rf(name)
+	var r0 *model.ChannelExportStatus
+	if rf, ok := ret.Get(0).(func(string) *model.ChannelExportStatus); ok {
+		r0 = rf(jobId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Team)
+			r0 = ret.Get(0).(*model.ChannelExportStatus)
 		}
 	}
 
 	var r1 *model.AppError
 	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
-		r1 = rf(name)
+		r1 = rf(jobId)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*model.AppError)
@@ -549,22 +729,22 @@ func (_m *API) GetTeamByName(name string) (*model.Team, *model.AppError) {
 	return r0, r1
 }
 
-// GetTeamMember provides a mock function with given fields: teamId, userId
-func (_m *API) GetTeamMember(teamId string, userId string) (*model.TeamMember, *model.AppError) {
-	ret := _m.Called(teamId, userId)
+// GetFileThumbnail provides a mock function with given fields: fileId
+func (_m *API) GetFileThumbnail(fileId string) ([]byte, *model.AppError) {
+	ret := _m.Called(fileId)
 
-	var r0 *model.TeamMember
-	if rf, ok := ret.Get(0).(func(string, string) *model.TeamMember); ok {
-		r0 = rf(teamId, userId)
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(string) []byte); ok {
+		r0 = rf(fileId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.TeamMember)
+			r0 = ret.Get(0).([]byte)
 		}
 	}
 
 	var r1 *model.AppError
-	if rf, ok := ret.Get(1).(func(string, string) *model.AppError); ok {
-		r1 = rf(teamId, userId)
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(fileId)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*model.AppError)
@@ -574,8 +754,303 @@ func (_m *API) GetTeamMember(teamId string, userId string) (*model.TeamMember, *
 	return r0, r1
 }
 
-// GetTeamMembers provides a mock function with given fields: teamId, offset, limit
-func (_m *API) GetTeamMembers(teamId string, offset int, limit int) ([]*model.TeamMember, *model.AppError) {
+// GetGroupChannel provides a mock function with given fields: userIds
+func (_m *API) GetGroupChannel(userIds []string) (*model.Channel, *model.AppError) {
+	ret := _m.Called(userIds)
+
+	var r0 *model.Channel
+	if rf, ok := ret.Get(0).(func([]string) *model.Channel); ok {
+		r0 = rf(userIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Channel)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func([]string) *model.AppError); ok {
+		r1 = rf(userIds)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetOAuthToken provides a mock function with given fields: userId
+func (_m *API) GetOAuthToken(userId string) (*model.PluginOAuthToken, *model.AppError) {
+	ret := _m.Called(userId)
+
+	var r0 *model.PluginOAuthToken
+	if rf, ok := ret.Get(0).(func(string) *model.PluginOAuthToken); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PluginOAuthToken)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetPost provides a mock function with given fields: postId
+func (_m *API) GetPost(postId string) (*model.Post, *model.AppError) {
+	ret := _m.Called(postId)
+
+	var r0 *model.Post
+	if rf, ok := ret.Get(0).(func(string) *model.Post); ok {
+		r0 = rf(postId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Post)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(postId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetPostAcknowledgements provides a mock function with given fields: postId
+func (_m *API) GetPostAcknowledgements(postId string) ([]*model.PostAcknowledgement, *model.AppError) {
+	ret := _m.Called(postId)
+
+	var r0 []*model.PostAcknowledgement
+	if rf, ok := ret.Get(0).(func(string) []*model.PostAcknowledgement); ok {
+		r0 = rf(postId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PostAcknowledgement)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(postId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetPublicChannelsForTeam provides a mock function with given fields: teamId, offset, limit
+func (_m *API) GetPublicChannelsForTeam(teamId string, offset int, limit int) (*model.ChannelList, *model.AppError) {
+	ret := _m.Called(teamId, offset, limit)
+
+	var r0 *model.ChannelList
+	if rf, ok := ret.Get(0).(func(string, int, int) *model.ChannelList); ok {
+		r0 = rf(teamId, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ChannelList)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, int, int) *model.AppError); ok {
+		r1 = rf(teamId, offset, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetRequestId provides a mock function with given fields: c
+func (_m *API) GetRequestId(c *plugin.Context) string {
+	ret := _m.Called(c)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(*plugin.Context) string); ok {
+		r0 = rf(c)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetServerHealth provides a mock function with given fields:
+func (_m *API) GetServerHealth() model.PluginServerHealth {
+	ret := _m.Called()
+
+	var r0 model.PluginServerHealth
+	if rf, ok := ret.Get(0).(func() model.PluginServerHealth); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(model.PluginServerHealth)
+	}
+
+	return r0
+}
+
+// GetSessions provides a mock function with given fields: userId
+func (_m *API) GetSessions(userId string) ([]*model.Session, *model.AppError) {
+	ret := _m.Called(userId)
+
+	var r0 []*model.Session
+	if rf, ok := ret.Get(0).(func(string) []*model.Session); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Session)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetSiteName provides a mock function with given fields:
+func (_m *API) GetSiteName() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetSiteURL provides a mock function with given fields:
+func (_m *API) GetSiteURL() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetSupportEmail provides a mock function with given fields:
+func (_m *API) GetSupportEmail() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetTeam provides a mock function with given fields: teamId
+func (_m *API) GetTeam(teamId string) (*model.Team, *model.AppError) {
+	ret := _m.Called(teamId)
+
+	var r0 *model.Team
+	if rf, ok := ret.Get(0).(func(string) *model.Team); ok {
+		r0 = rf(teamId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Team)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(teamId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetTeamByName provides a mock function with given fields: name
+func (_m *API) GetTeamByName(name string) (*model.Team, *model.AppError) {
+	ret := _m.Called(name)
+
+	var r0 *model.Team
+	if rf, ok := ret.Get(0).(func(string) *model.Team); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Team)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(name)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetTeamMember provides a mock function with given fields: teamId, userId
+func (_m *API) GetTeamMember(teamId string, userId string) (*model.TeamMember, *model.AppError) {
+	ret := _m.Called(teamId, userId)
+
+	var r0 *model.TeamMember
+	if rf, ok := ret.Get(0).(func(string, string) *model.TeamMember); ok {
+		r0 = rf(teamId, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TeamMember)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string) *model.AppError); ok {
+		r1 = rf(teamId, userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetTeamMembers provides a mock function with given fields: teamId, offset, limit
+func (_m *API) GetTeamMembers(teamId string, offset int, limit int) ([]*model.TeamMember, *model.AppError) {
 	ret := _m.Called(teamId, offset, limit)
 
 	var r0 []*model.TeamMember
@@ -599,47 +1074,783 @@ func (_m *API) GetTeamMembers(teamId string, offset int, limit int) ([]*model.Te
 	return r0, r1
 }
 
-// GetTeams provides a mock function with given fields:
-func (_m *API) GetTeams() ([]*model.Team, *model.AppError) {
-	ret := _m.Called()
+// GetTeamMembersModifiedSince provides a mock function with given fields: teamId, since
+func (_m *API) GetTeamMembersModifiedSince(teamId string, since int64) ([]*model.TeamMember, *model.AppError) {
+	ret := _m.Called(teamId, since)
+
+	var r0 []*model.TeamMember
+	if rf, ok := ret.Get(0).(func(string, int64) []*model.TeamMember); ok {
+		r0 = rf(teamId, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.TeamMember)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, int64) *model.AppError); ok {
+		r1 = rf(teamId, since)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetTeamStats provides a mock function with given fields: teamId
+func (_m *API) GetTeamStats(teamId string) (*model.TeamStats, *model.AppError) {
+	ret := _m.Called(teamId)
+
+	var r0 *model.TeamStats
+	if rf, ok := ret.Get(0).(func(string) *model.TeamStats); ok {
+		r0 = rf(teamId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TeamStats)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(teamId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetTeams provides a mock function with given fields:
+func (_m *API) GetTeams() ([]*model.Team, *model.AppError) {
+	ret := _m.Called()
+
+	var r0 []*model.Team
+	if rf, ok := ret.Get(0).(func() []*model.Team); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Team)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func() *model.AppError); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetUser provides a mock function with given fields: userId
+func (_m *API) GetUser(userId string) (*model.User, *model.AppError) {
+	ret := _m.Called(userId)
+
+	var r0 *model.User
+	if rf, ok := ret.Get(0).(func(string) *model.User); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetUserByEmail provides a mock function with given fields: email
+func (_m *API) GetUserByEmail(email string) (*model.User, *model.AppError) {
+	ret := _m.Called(email)
+
+	var r0 *model.User
+	if rf, ok := ret.Get(0).(func(string) *model.User); ok {
+		r0 = rf(email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(email)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetUserByUsername provides a mock function with given fields: name
+func (_m *API) GetUserByUsername(name string) (*model.User, *model.AppError) {
+	ret := _m.Called(name)
+
+	var r0 *model.User
+	if rf, ok := ret.Get(0).(func(string) *model.User); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(name)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetUserNotifyProps provides a mock function with given fields: userId
+func (_m *API) GetUserNotifyProps(userId string) (model.StringMap, *model.AppError) {
+	ret := _m.Called(userId)
+
+	var r0 model.StringMap
+	if rf, ok := ret.Get(0).(func(string) model.StringMap); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(model.StringMap)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetUserPluginSettings provides a mock function with given fields: userId
+func (_m *API) GetUserPluginSettings(userId string) (map[string]string, *model.AppError) {
+	ret := _m.Called(userId)
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func(string) map[string]string); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetUserStatus provides a mock function with given fields: userId
+func (_m *API) GetUserStatus(userId string) (*model.Status, *model.AppError) {
+	ret := _m.Called(userId)
+
+	var r0 *model.Status
+	if rf, ok := ret.Get(0).(func(string) *model.Status); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Status)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(userId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetUserStatusesByIds provides a mock function with given fields: userIds
+func (_m *API) GetUserStatusesByIds(userIds []string) ([]*model.Status, *model.AppError) {
+	ret := _m.Called(userIds)
+
+	var r0 []*model.Status
+	if rf, ok := ret.Get(0).(func([]string) []*model.Status); ok {
+		r0 = rf(userIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Status)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func([]string) *model.AppError); ok {
+		r1 = rf(userIds)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// IsHighAvailability provides a mock function with given fields:
+func (_m *API) IsHighAvailability() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// KVCompareAndDelete provides a mock function with given fields: key, oldValue
+func (_m *API) KVCompareAndDelete(key string, oldValue []byte) (bool, *model.AppError) {
+	ret := _m.Called(key, oldValue)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, []byte) bool); ok {
+		r0 = rf(key, oldValue)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, []byte) *model.AppError); ok {
+		r1 = rf(key, oldValue)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// KVCompareAndSet provides a mock function with given fields: key, oldValue, newValue
+func (_m *API) KVCompareAndSet(key string, oldValue []byte, newValue []byte) (bool, *model.AppError) {
+	ret := _m.Called(key, oldValue, newValue)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, []byte, []byte) bool); ok {
+		r0 = rf(key, oldValue, newValue)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, []byte, []byte) *model.AppError); ok {
+		r1 = rf(key, oldValue, newValue)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// KVDelete provides a mock function with given fields: key
+func (_m *API) KVDelete(key string) *model.AppError {
+	ret := _m.Called(key)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string) *model.AppError); ok {
+		r0 = rf(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
+// KVDeleteAll provides a mock function with given fields: 
+func (_m *API) KVDeleteAll() *model.AppError {
+	ret := _m.Called()
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func() *model.AppError); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
+// KVGet provides a mock function with given fields: key
+func (_m *API) KVGet(key string) ([]byte, *model.AppError) {
+	ret := _m.Called(key)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(string) []byte); ok {
+		r0 = rf(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(key)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// KVGetMultiple provides a mock function with given fields: keys
+func (_m *API) KVGetMultiple(keys []string) (map[string][]byte, *model.AppError) {
+	ret := _m.Called(keys)
+
+	var r0 map[string][]byte
+	if rf, ok := ret.Get(0).(func([]string) map[string][]byte); ok {
+		r0 = rf(keys)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string][]byte)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func([]string) *model.AppError); ok {
+		r1 = rf(keys)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// KVGetWithExists provides a mock function with given fields: key
+func (_m *API) KVGetWithExists(key string) ([]byte, bool, *model.AppError) {
+	ret := _m.Called(key)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(string) []byte); ok {
+		r0 = rf(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	var r2 *model.AppError
+	if rf, ok := ret.Get(2).(func(string) *model.AppError); ok {
+		r2 = rf(key)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(*model.AppError)
+		}
+	}
+
+	return r0, r1, r2
+}
+
+// KVGetWithOptions provides a mock function with given fields: key, opts
+func (_m *API) KVGetWithOptions(key string, opts model.PluginKVGetOptions) ([]byte, *model.AppError) {
+	ret := _m.Called(key, opts)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(string, model.PluginKVGetOptions) []byte); ok {
+		r0 = rf(key, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, model.PluginKVGetOptions) *model.AppError); ok {
+		r1 = rf(key, opts)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// KVIncrement provides a mock function with given fields: key, delta
+func (_m *API) KVIncrement(key string, delta int64) (int64, *model.AppError) {
+	ret := _m.Called(key, delta)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(string, int64) int64); ok {
+		r0 = rf(key, delta)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, int64) *model.AppError); ok {
+		r1 = rf(key, delta)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// KVList provides a mock function with given fields: page, perPage
+func (_m *API) KVList(page int, perPage int) ([]string, *model.AppError) {
+	ret := _m.Called(page, perPage)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(int, int) []string); ok {
+		r0 = rf(page, perPage)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(int, int) *model.AppError); ok {
+		r1 = rf(page, perPage)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// KVListWithPrefix provides a mock function with given fields: prefix, page, perPage
+func (_m *API) KVListWithPrefix(prefix string, page int, perPage int) ([]string, *model.AppError) {
+	ret := _m.Called(prefix, page, perPage)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string, int, int) []string); ok {
+		r0 = rf(prefix, page, perPage)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, int, int) *model.AppError); ok {
+		r1 = rf(prefix, page, perPage)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// KVLock provides a mock function with given fields: key, ttl
+func (_m *API) KVLock(key string, ttl time.Duration) (string, *model.AppError) {
+	ret := _m.Called(key, ttl)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, time.Duration) string); ok {
+		r0 = rf(key, ttl)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, time.Duration) *model.AppError); ok {
+		r1 = rf(key, ttl)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// KVSet provides a mock function with given fields: key, value
+func (_m *API) KVSet(key string, value []byte) *model.AppError {
+	ret := _m.Called(key, value)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string, []byte) *model.AppError); ok {
+		r0 = rf(key, value)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
+// KVSetAndNotify provides a mock function with given fields: key, value, event, payload, broadcast
+func (_m *API) KVSetAndNotify(key string, value []byte, event string, payload map[string]interface{}, broadcast *model.WebsocketBroadcast) *model.AppError {
+	ret := _m.Called(key, value, event, payload, broadcast)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string, []byte, string, map[string]interface{}, *model.WebsocketBroadcast) *model.AppError); ok {
+		r0 = rf(key, value, event, payload, broadcast)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
+// KVSetAtomic provides a mock function with given fields: ops
+func (_m *API) KVSetAtomic(ops []model.PluginKVOp) *model.AppError {
+	ret := _m.Called(ops)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func([]model.PluginKVOp) *model.AppError); ok {
+		r0 = rf(ops)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
+// KVSetMultiple provides a mock function with given fields: kvs
+func (_m *API) KVSetMultiple(kvs map[string][]byte) *model.AppError {
+	ret := _m.Called(kvs)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(map[string][]byte) *model.AppError); ok {
+		r0 = rf(kvs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
+// KVSetWithExpiry provides a mock function with given fields: key, value, expireInSeconds
+func (_m *API) KVSetWithExpiry(key string, value []byte, expireInSeconds int64) *model.AppError {
+	ret := _m.Called(key, value, expireInSeconds)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string, []byte, int64) *model.AppError); ok {
+		r0 = rf(key, value, expireInSeconds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
+// KVUnlock provides a mock function with given fields: key, token
+func (_m *API) KVUnlock(key string, token string) *model.AppError {
+	ret := _m.Called(key, token)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string, string) *model.AppError); ok {
+		r0 = rf(key, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
+// ListSubscriptionsForChannel provides a mock function with given fields: channelId
+func (_m *API) ListSubscriptionsForChannel(channelId string) ([]*model.PluginSubscription, *model.AppError) {
+	ret := _m.Called(channelId)
+
+	var r0 []*model.PluginSubscription
+	if rf, ok := ret.Get(0).(func(string) []*model.PluginSubscription); ok {
+		r0 = rf(channelId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PluginSubscription)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(channelId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// LoadPluginConfiguration provides a mock function with given fields: dest
+func (_m *API) LoadPluginConfiguration(dest interface{}) error {
+	ret := _m.Called(dest)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(interface{}) error); ok {
+		r0 = rf(dest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LogDebug provides a mock function with given fields: msg, keyValuePairs
+func (_m *API) LogDebug(msg string, keyValuePairs ...interface{}) {
+	var _ca []interface{}
+	_ca = append(_ca, msg)
+	_ca = append(_ca, keyValuePairs...)
+	_m.Called(_ca...)
+}
+
+// LogError provides a mock function with given fields: msg, keyValuePairs
+func (_m *API) LogError(msg string, keyValuePairs ...interface{}) {
+	var _ca []interface{}
+	_ca = append(_ca, msg)
+	_ca = append(_ca, keyValuePairs...)
+	_m.Called(_ca...)
+}
+
+// LogInfo provides a mock function with given fields: msg, keyValuePairs
+func (_m *API) LogInfo(msg string, keyValuePairs ...interface{}) {
+	var _ca []interface{}
+	_ca = append(_ca, msg)
+	_ca = append(_ca, keyValuePairs...)
+	_m.Called(_ca...)
+}
+
+// LogWarn provides a mock function with given fields: msg, keyValuePairs
+func (_m *API) LogWarn(msg string, keyValuePairs ...interface{}) {
+	var _ca []interface{}
+	_ca = append(_ca, msg)
+	_ca = append(_ca, keyValuePairs...)
+	_m.Called(_ca...)
+}
+
+// NotifyUser provides a mock function with given fields: userId, notification
+func (_m *API) NotifyUser(userId string, notification model.PluginNotification) *model.AppError {
+	ret := _m.Called(userId, notification)
 
-	var r0 []*model.Team
-	if rf, ok := ret.Get(0).(func() []*model.Team); ok {
-		r0 = rf()
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string, model.PluginNotification) *model.AppError); ok {
+		r0 = rf(userId, notification)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*model.Team)
+			r0 = ret.Get(0).(*model.AppError)
 		}
 	}
 
-	var r1 *model.AppError
-	if rf, ok := ret.Get(1).(func() *model.AppError); ok {
-		r1 = rf()
+	return r0
+}
+
+// PublishWebSocketEvent provides a mock function with given fields: event, payload, broadcast
+func (_m *API) PublishWebSocketEvent(event string, payload map[string]interface{}, broadcast *model.WebsocketBroadcast) {
+	_m.Called(event, payload, broadcast)
+}
+
+// RegisterCommand provides a mock function with given fields: command
+func (_m *API) RegisterCommand(command *model.Command) error {
+	ret := _m.Called(command)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Command) error); ok {
+		r0 = rf(command)
 	} else {
-		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*model.AppError)
-		}
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// GetUser provides a mock function with given fields: userId
-func (_m *API) GetUser(userId string) (*model.User, *model.AppError) {
-	ret := _m.Called(userId)
+// RegisterOAuthProvider provides a mock function with given fields: provider
+func (_m *API) RegisterOAuthProvider(provider model.PluginOAuthProvider) *model.AppError {
+	ret := _m.Called(provider)
 
-	var r0 *model.User
-	if rf, ok := ret.Get(0).(func(string) *model.User); ok {
-		r0 = rf(userId)
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(model.PluginOAuthProvider) *model.AppError); ok {
+		r0 = rf(provider)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.User)
+			r0 = ret.Get(0).(*model.AppError)
 		}
 	}
 
+	return r0
+}
+
+// RenderMessageToHTML provides a mock function with given fields: message
+func (_m *API) RenderMessageToHTML(message string) (string, *model.AppError) {
+	ret := _m.Called(message)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(message)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
 	var r1 *model.AppError
 	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
-		r1 = rf(userId)
+		r1 = rf(message)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*model.AppError)
@@ -649,22 +1860,22 @@ func (_m *API) GetUser(userId string) (*model.User, *model.AppError) {
 	return r0, r1
 }
 
-// GetUserByEmail provides a mock function with given fields: email
-func (_m *API) GetUserByEmail(email string) (*model.User, *model.AppError) {
-	ret := _m.Called(email)
+// RequestAdminAction provides a mock function with given fields: action
+func (_m *API) RequestAdminAction(action model.PluginAdminActionRequest) (*model.PluginAdminActionRequest, *model.AppError) {
+	ret := _m.Called(action)
 
-	var r0 *model.User
-	if rf, ok := ret.Get(0).(func(string) *model.User); ok {
-		r0 = rf(email)
+	var r0 *model.PluginAdminActionRequest
+	if rf, ok := ret.Get(0).(func(model.PluginAdminActionRequest) *model.PluginAdminActionRequest); ok {
+		r0 = rf(action)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.User)
+			r0 = ret.Get(0).(*model.PluginAdminActionRequest)
 		}
 	}
 
 	var r1 *model.AppError
-	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
-		r1 = rf(email)
+	if rf, ok := ret.Get(1).(func(model.PluginAdminActionRequest) *model.AppError); ok {
+		r1 = rf(action)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*model.AppError)
@@ -674,47 +1885,38 @@ func (_m *API) GetUserByEmail(email string) (*model.User, *model.AppError) {
 	return r0, r1
 }
 
-// GetUserByUsername provides a mock function with given fields: name
-func (_m *API) GetUserByUsername(name string) (*model.User, *model.AppError) {
-	ret := _m.Called(name)
+// RequestPostAcknowledgement provides a mock function with given fields: postId
+func (_m *API) RequestPostAcknowledgement(postId string) *model.AppError {
+	ret := _m.Called(postId)
 
-	var r0 *model.User
-	if rf, ok := ret.Get(0).(func(string) *model.User); ok {
-		r0 = rf(name)
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string) *model.AppError); ok {
+		r0 = rf(postId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.User)
-		}
-	}
-
-	var r1 *model.AppError
-	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
-		r1 = rf(name)
-	} else {
-		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*model.AppError)
+			r0 = ret.Get(0).(*model.AppError)
 		}
 	}
 
-	return r0, r1
+	return r0
 }
 
-// GetUserStatus provides a mock function with given fields: userId
-func (_m *API) GetUserStatus(userId string) (*model.Status, *model.AppError) {
-	ret := _m.Called(userId)
+// RestoreChannel provides a mock function with given fields: channelId
+func (_m *API) RestoreChannel(channelId string) (*model.Channel, *model.AppError) {
+	ret := _m.Called(channelId)
 
-	var r0 *model.Status
-	if rf, ok := ret.Get(0).(func(string) *model.Status); ok {
-		r0 = rf(userId)
+	var r0 *model.Channel
+	if rf, ok := ret.Get(0).(func(string) *model.Channel); ok {
+		r0 = rf(channelId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Status)
+			r0 = ret.Get(0).(*model.Channel)
 		}
 	}
 
 	var r1 *model.AppError
 	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
-		r1 = rf(userId)
+		r1 = rf(channelId)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*model.AppError)
@@ -724,38 +1926,45 @@ func (_m *API) GetUserStatus(userId string) (*model.Status, *model.AppError) {
 	return r0, r1
 }
 
-// GetUserStatusesByIds provides a mock function with given fields: userIds
-func (_m *API) GetUserStatusesByIds(userIds []string) ([]*model.Status, *model.AppError) {
-	ret := _m.Called(userIds)
+// RevokeAllSessionsForUser provides a mock function with given fields: userId
+func (_m *API) RevokeAllSessionsForUser(userId string) *model.AppError {
+	ret := _m.Called(userId)
 
-	var r0 []*model.Status
-	if rf, ok := ret.Get(0).(func([]string) []*model.Status); ok {
-		r0 = rf(userIds)
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string) *model.AppError); ok {
+		r0 = rf(userId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*model.Status)
+			r0 = ret.Get(0).(*model.AppError)
 		}
 	}
 
-	var r1 *model.AppError
-	if rf, ok := ret.Get(1).(func([]string) *model.AppError); ok {
-		r1 = rf(userIds)
+	return r0
+}
+
+// RevokeSession provides a mock function with given fields: sessionId
+func (_m *API) RevokeSession(sessionId string) *model.AppError {
+	ret := _m.Called(sessionId)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string) *model.AppError); ok {
+		r0 = rf(sessionId)
 	} else {
-		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*model.AppError)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
 		}
 	}
 
-	return r0, r1
+	return r0
 }
 
-// KVDelete provides a mock function with given fields: key
-func (_m *API) KVDelete(key string) *model.AppError {
-	ret := _m.Called(key)
+// SaveConfig provides a mock function with given fields: config
+func (_m *API) SaveConfig(config *model.Config) *model.AppError {
+	ret := _m.Called(config)
 
 	var r0 *model.AppError
-	if rf, ok := ret.Get(0).(func(string) *model.AppError); ok {
-		r0 = rf(key)
+	if rf, ok := ret.Get(0).(func(*model.Config) *model.AppError); ok {
+		r0 = rf(config)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.AppError)
@@ -765,22 +1974,36 @@ func (_m *API) KVDelete(key string) *model.AppError {
 	return r0
 }
 
-// KVGet provides a mock function with given fields: key
-func (_m *API) KVGet(key string) ([]byte, *model.AppError) {
-	ret := _m.Called(key)
+// SendEphemeralPost provides a mock function with given fields: userId, post
+func (_m *API) SendEphemeralPost(userId string, post *model.Post) *model.Post {
+	ret := _m.Called(userId, post)
 
-	var r0 []byte
-	if rf, ok := ret.Get(0).(func(string) []byte); ok {
-		r0 = rf(key)
+	var r0 *model.Post
+	if rf, ok := ret.Get(0).(func(string, *model.Post) *model.Post); ok {
+		r0 = rf(userId, post)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]byte)
+			r0 = ret.Get(0).(*model.Post)
 		}
 	}
 
+	return r0
+}
+
+// SendToUserWebapp provides a mock function with given fields: userId, message
+func (_m *API) SendToUserWebapp(userId string, message model.PluginWebappMessage) (string, *model.AppError) {
+	ret := _m.Called(userId, message)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, model.PluginWebappMessage) string); ok {
+		r0 = rf(userId, message)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
 	var r1 *model.AppError
-	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
-		r1 = rf(key)
+	if rf, ok := ret.Get(1).(func(string, model.PluginWebappMessage) *model.AppError); ok {
+		r1 = rf(userId, message)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*model.AppError)
@@ -790,13 +2013,13 @@ func (_m *API) KVGet(key string) ([]byte, *model.AppError) {
 	return r0, r1
 }
 
-// KVSet provides a mock function with given fields: key, value
-func (_m *API) KVSet(key string, value []byte) *model.AppError {
-	ret := _m.Called(key, value)
+// SetAnnouncementBanner provides a mock function with given fields: banner
+func (_m *API) SetAnnouncementBanner(banner model.PluginBanner) *model.AppError {
+	ret := _m.Called(banner)
 
 	var r0 *model.AppError
-	if rf, ok := ret.Get(0).(func(string, []byte) *model.AppError); ok {
-		r0 = rf(key, value)
+	if rf, ok := ret.Get(0).(func(model.PluginBanner) *model.AppError); ok {
+		r0 = rf(banner)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.AppError)
@@ -806,97 +2029,76 @@ func (_m *API) KVSet(key string, value []byte) *model.AppError {
 	return r0
 }
 
-// LoadPluginConfiguration provides a mock function with given fields: dest
-func (_m *API) LoadPluginConfiguration(dest interface{}) error {
-	ret := _m.Called(dest)
+// SetChannelModerationForMembers provides a mock function with given fields: channelId, allowPost
+func (_m *API) SetChannelModerationForMembers(channelId string, allowPost bool) *model.AppError {
+	ret := _m.Called(channelId, allowPost)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(interface{}) error); ok {
-		r0 = rf(dest)
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string, bool) *model.AppError); ok {
+		r0 = rf(channelId, allowPost)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
 	}
 
 	return r0
 }
 
-// LogDebug provides a mock function with given fields: msg, keyValuePairs
-func (_m *API) LogDebug(msg string, keyValuePairs ...interface{}) {
-	var _ca []interface{}
-	_ca = append(_ca, msg)
-	_ca = append(_ca, keyValuePairs...)
-	_m.Called(_ca...)
-}
-
-// LogError provides a mock function with given fields: msg, keyValuePairs
-func (_m *API) LogError(msg string, keyValuePairs ...interface{}) {
-	var _ca []interface{}
-	_ca = append(_ca, msg)
-	_ca = append(_ca, keyValuePairs...)
-	_m.Called(_ca...)
-}
-
-// LogInfo provides a mock function with given fields: msg, keyValuePairs
-func (_m *API) LogInfo(msg string, keyValuePairs ...interface{}) {
-	var _ca []interface{}
-	_ca = append(_ca, msg)
-	_ca = append(_ca, keyValuePairs...)
-	_m.Called(_ca...)
-}
+// SetUserPluginSetting provides a mock function with given fields: userId, key, value
+func (_m *API) SetUserPluginSetting(userId string, key string, value string) *model.AppError {
+	ret := _m.Called(userId, key, value)
 
-// LogWarn provides a mock function with given fields: msg, keyValuePairs
-func (_m *API) LogWarn(msg string, keyValuePairs ...interface{}) {
-	var _ca []interface{}
-	_ca = append(_ca, msg)
-	_ca = append(_ca, keyValuePairs...)
-	_m.Called(_ca...)
-}
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string, string, string) *model.AppError); ok {
+		r0 = rf(userId, key, value)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
 
-// PublishWebSocketEvent provides a mock function with given fields: event, payload, broadcast
-func (_m *API) PublishWebSocketEvent(event string, payload map[string]interface{}, broadcast *model.WebsocketBroadcast) {
-	_m.Called(event, payload, broadcast)
+	return r0
 }
 
-// RegisterCommand provides a mock function with given fields: command
-func (_m *API) RegisterCommand(command *model.Command) error {
-	ret := _m.Called(command)
+// StripMarkdown provides a mock function with given fields: message
+func (_m *API) StripMarkdown(message string) string {
+	ret := _m.Called(message)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(*model.Command) error); ok {
-		r0 = rf(command)
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(message)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(string)
 	}
 
 	return r0
 }
 
-// SaveConfig provides a mock function with given fields: config
-func (_m *API) SaveConfig(config *model.Config) *model.AppError {
-	ret := _m.Called(config)
+// TruncateForNotification provides a mock function with given fields: message, maxRunes
+func (_m *API) TruncateForNotification(message string, maxRunes int) string {
+	ret := _m.Called(message, maxRunes)
 
-	var r0 *model.AppError
-	if rf, ok := ret.Get(0).(func(*model.Config) *model.AppError); ok {
-		r0 = rf(config)
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, int) string); ok {
+		r0 = rf(message, maxRunes)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.AppError)
-		}
+		r0 = ret.Get(0).(string)
 	}
 
 	return r0
 }
 
-// SendEphemeralPost provides a mock function with given fields: userId, post
-func (_m *API) SendEphemeralPost(userId string, post *model.Post) *model.Post {
-	ret := _m.Called(userId, post)
+// UnfollowThreadForUser provides a mock function with given fields: userId, postId
+func (_m *API) UnfollowThreadForUser(userId string, postId string) *model.AppError {
+	ret := _m.Called(userId, postId)
 
-	var r0 *model.Post
-	if rf, ok := ret.Get(0).(func(string, *model.Post) *model.Post); ok {
-		r0 = rf(userId, post)
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string, string) *model.AppError); ok {
+		r0 = rf(userId, postId)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.Post)
+			r0 = ret.Get(0).(*model.AppError)
 		}
 	}
 
@@ -1116,3 +2318,31 @@ func (_m *API) UpdateUserStatus(userId string, status string) (*model.Status, *m
 
 	return r0, r1
 }
+
+// WebSocketBroadcastToConnection provides a mock function with given fields: webConnID, event
+func (_m *API) WebSocketBroadcastToConnection(webConnID string, event *model.WebSocketEvent) {
+	_m.Called(webConnID, event)
+}
+
+// WouldUserBeNotified provides a mock function with given fields: userId, channelId, mention
+func (_m *API) WouldUserBeNotified(userId string, channelId string, mention bool) (model.NotificationPrediction, *model.AppError) {
+	ret := _m.Called(userId, channelId, mention)
+
+	var r0 model.NotificationPrediction
+	if rf, ok := ret.Get(0).(func(string, string, bool) model.NotificationPrediction); ok {
+		r0 = rf(userId, channelId, mention)
+	} else {
+		r0 = ret.Get(0).(model.NotificationPrediction)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string, bool) *model.AppError); ok {
+		r1 = rf(userId, channelId, mention)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
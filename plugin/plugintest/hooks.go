@@ -15,6 +15,21 @@ type Hooks struct {
 	mock.Mock
 }
 
+// AdminActionHasBeenResolved provides a mock function with given fields: requestId, approved
+func (_m *Hooks) AdminActionHasBeenResolved(requestId string, approved bool) {
+	_m.Called(requestId, approved)
+}
+
+// BulkOperationHasCompleted provides a mock function with given fields: opType, counts
+func (_m *Hooks) BulkOperationHasCompleted(opType string, counts map[string]int) {
+	_m.Called(opType, counts)
+}
+
+// ChannelExportHasCompleted provides a mock function with given fields: jobId, fileId
+func (_m *Hooks) ChannelExportHasCompleted(jobId string, fileId string) {
+	_m.Called(jobId, fileId)
+}
+
 // ChannelHasBeenCreated provides a mock function with given fields: c, channel
 func (_m *Hooks) ChannelHasBeenCreated(c *plugin.Context, channel *model.Channel) {
 	_m.Called(c, channel)
@@ -91,6 +106,11 @@ func (_m *Hooks) Implemented() ([]string, error) {
 	return r0, r1
 }
 
+// KVHasChanged provides a mock function with given fields: key
+func (_m *Hooks) KVHasChanged(key string) {
+	_m.Called(key)
+}
+
 // MessageHasBeenPosted provides a mock function with given fields: c, post
 func (_m *Hooks) MessageHasBeenPosted(c *plugin.Context, post *model.Post) {
 	_m.Called(c, post)
@@ -175,6 +195,11 @@ func (_m *Hooks) OnConfigurationChange() error {
 	return r0
 }
 
+// OnDataImported provides a mock function with given fields:
+func (_m *Hooks) OnDataImported() {
+	_m.Called()
+}
+
 // OnDeactivate provides a mock function with given fields:
 func (_m *Hooks) OnDeactivate() error {
 	ret := _m.Called()
@@ -189,11 +214,81 @@ func (_m *Hooks) OnDeactivate() error {
 	return r0
 }
 
+// OnMigrate provides a mock function with given fields: fromVersion, toVersion
+func (_m *Hooks) OnMigrate(fromVersion int, toVersion int) error {
+	ret := _m.Called(fromVersion, toVersion)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, int) error); ok {
+		r0 = rf(fromVersion, toVersion)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// OnPluginActivated provides a mock function with given fields: manifest
+func (_m *Hooks) OnPluginActivated(manifest *model.Manifest) {
+	_m.Called(manifest)
+}
+
+// OnPluginDeactivated provides a mock function with given fields: manifest
+func (_m *Hooks) OnPluginDeactivated(manifest *model.Manifest) {
+	_m.Called(manifest)
+}
+
+// OnWebSocketConnect provides a mock function with given fields: webConnID, userId
+func (_m *Hooks) OnWebSocketConnect(webConnID string, userId string) {
+	_m.Called(webConnID, userId)
+}
+
+// OnWebSocketDisconnect provides a mock function with given fields: webConnID, userId
+func (_m *Hooks) OnWebSocketDisconnect(webConnID string, userId string) {
+	_m.Called(webConnID, userId)
+}
+
+// PostHasBeenAcknowledged provides a mock function with given fields: c, postId, userId
+func (_m *Hooks) PostHasBeenAcknowledged(c *plugin.Context, postId string, userId string) {
+	_m.Called(c, postId, userId)
+}
+
+// PostsWillBeExported provides a mock function with given fields: c, posts, exportType
+func (_m *Hooks) PostsWillBeExported(c *plugin.Context, posts []*model.Post, exportType string) []*model.Post {
+	ret := _m.Called(c, posts, exportType)
+
+	var r0 []*model.Post
+	if rf, ok := ret.Get(0).(func(*plugin.Context, []*model.Post, string) []*model.Post); ok {
+		r0 = rf(c, posts, exportType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Post)
+		}
+	}
+
+	return r0
+}
+
 // ServeHTTP provides a mock function with given fields: c, w, r
 func (_m *Hooks) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
 	_m.Called(c, w, r)
 }
 
+// ServeMetrics provides a mock function with given fields: c, w, r
+func (_m *Hooks) ServeMetrics(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
+	_m.Called(c, w, r)
+}
+
+// TeamMembersHaveChanged provides a mock function with given fields: c, teamMembers
+func (_m *Hooks) TeamMembersHaveChanged(c *plugin.Context, teamMembers []*model.TeamMember) {
+	_m.Called(c, teamMembers)
+}
+
+// UserHasBeenCreated provides a mock function with given fields: c, user
+func (_m *Hooks) UserHasBeenCreated(c *plugin.Context, user *model.User) {
+	_m.Called(c, user)
+}
+
 // UserHasJoinedChannel provides a mock function with given fields: c, channelMember, actor
 func (_m *Hooks) UserHasJoinedChannel(c *plugin.Context, channelMember *model.ChannelMember, actor *model.User) {
 	_m.Called(c, channelMember, actor)
@@ -232,3 +327,18 @@ func (_m *Hooks) UserWillLogIn(c *plugin.Context, user *model.User) string {
 
 	return r0
 }
+
+// UsersHaveBeenCreated provides a mock function with given fields: c, users
+func (_m *Hooks) UsersHaveBeenCreated(c *plugin.Context, users []*model.User) {
+	_m.Called(c, users)
+}
+
+// WebappReplyReceived provides a mock function with given fields: replyId, payload
+func (_m *Hooks) WebappReplyReceived(replyId string, payload []byte) {
+	_m.Called(replyId, payload)
+}
+
+// WebappReplyTimedOut provides a mock function with given fields: replyId
+func (_m *Hooks) WebappReplyTimedOut(replyId string) {
+	_m.Called(replyId)
+}
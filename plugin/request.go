@@ -0,0 +1,44 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package plugin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+type contextKey int
+
+const pluginIdContextKey contextKey = iota
+
+// legacyPluginIdContextKey is the untyped key the plugin id was once stored under in the request
+// context. It is still populated for plugins that look it up directly, but will be removed in a future
+// release: use IDFromRequest instead.
+const legacyPluginIdContextKey = "plugin_id"
+
+// RequestWithID returns a copy of the request passed to a plugin's HTTP hooks that identifies the
+// plugin serving it, both in its context and in its X-Mattermost-Plugin-ID header. Any plugin id
+// already given in the header is replaced.
+func RequestWithID(r *http.Request, pluginId string) *http.Request {
+	ctx := context.WithValue(r.Context(), pluginIdContextKey, pluginId)
+	ctx = context.WithValue(ctx, legacyPluginIdContextKey, pluginId)
+
+	r = r.WithContext(ctx)
+	r.Header.Set(model.HEADER_PLUGIN_ID, pluginId)
+
+	return r
+}
+
+// IDFromRequest returns the id of the plugin serving the request passed to its ServeHTTP hook. The
+// X-Mattermost-Plugin-ID header is consulted if the request context has been replaced, such as by a
+// router that does not preserve it.
+func IDFromRequest(r *http.Request) string {
+	if pluginId, ok := r.Context().Value(pluginIdContextKey).(string); ok {
+		return pluginId
+	}
+
+	return r.Header.Get(model.HEADER_PLUGIN_ID)
+}
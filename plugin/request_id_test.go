@@ -0,0 +1,133 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package plugin_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+)
+
+func compilePlugin(t *testing.T, sourceCode, outputPath string) {
+	dir, err := ioutil.TempDir(".", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(sourceCode), 0600))
+	cmd := exec.Command("go", "build", "-o", outputPath, "main.go")
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	require.NoError(t, cmd.Run())
+}
+
+func TestRequestIdAcrossRPC(t *testing.T) {
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(pluginDir)
+
+	compilePlugin(t, `
+		package main
+
+		import (
+			"context"
+			"net/http"
+			"os"
+
+			"github.com/mattermost/mattermost-server/model"
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
+			p.API.LogInfo("serving request")
+			w.Header().Set("X-Served-By", plugin.IDFromRequest(r.WithContext(context.Background())))
+			w.Write([]byte(p.API.GetRequestId(c)))
+		}
+
+		func (p *MyPlugin) ExecuteCommand(c *plugin.Context, args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
+			if args.Command == "/crash" {
+				os.Exit(1)
+			}
+			p.API.LogWarn("executing command", "command", args.Command)
+			return &model.CommandResponse{Text: c.RequestId}, nil
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`, filepath.Join(pluginDir, "testplugin", "backend.exe"))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pluginDir, "testplugin", "plugin.json"), []byte(`{"id": "testplugin", "backend": {"executable": "backend.exe"}}`), 0600))
+
+	logFile := filepath.Join(pluginDir, "server.log")
+	logger := mlog.NewLogger(&mlog.LoggerConfiguration{
+		EnableFile:   true,
+		FileJson:     true,
+		FileLevel:    "debug",
+		FileLocation: logFile,
+	})
+
+	api := &plugintest.API{}
+	api.On("LoadPluginConfiguration", mock.Anything).Return(nil).Maybe()
+	api.On("LogInfo", "serving request", "request_id", "httprequestid").Return().Twice()
+	api.On("LogWarn", "executing command", "command", "/test", "request_id", "commandrequestid").Return().Once()
+	defer api.AssertExpectations(t)
+
+	env, err := plugin.NewEnvironment(func(*model.Manifest) plugin.API { return api }, pluginDir, pluginDir, logger)
+	require.NoError(t, err)
+	defer env.Shutdown()
+
+	_, _, err = env.Activate("testplugin")
+	require.NoError(t, err)
+
+	hooks, err := env.HooksForPlugin("testplugin")
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	hooks.ServeHTTP(&plugin.Context{RequestId: "httprequestid"}, w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "httprequestid", w.Body.String())
+	assert.Equal(t, "", w.Header().Get("X-Served-By"))
+
+	w = httptest.NewRecorder()
+	hooks.ServeHTTP(&plugin.Context{RequestId: "httprequestid"}, w, plugin.RequestWithID(httptest.NewRequest(http.MethodGet, "/", nil), "testplugin"))
+	assert.Equal(t, "testplugin", w.Header().Get("X-Served-By"))
+
+	response, appErr := hooks.ExecuteCommand(&plugin.Context{RequestId: "commandrequestid"}, &model.CommandArgs{Command: "/test"})
+	require.Nil(t, appErr)
+	assert.Equal(t, "commandrequestid", response.Text)
+
+	response, appErr = hooks.ExecuteCommand(&plugin.Context{RequestId: "crashrequestid"}, &model.CommandArgs{Command: "/crash"})
+	assert.Nil(t, response)
+	assert.Nil(t, appErr)
+
+	logs, err := ioutil.ReadFile(logFile)
+	require.NoError(t, err)
+
+	found := false
+	for _, line := range strings.Split(string(logs), "\n") {
+		var record map[string]interface{}
+		if json.Unmarshal([]byte(line), &record) == nil && strings.Contains(record["msg"].(string), "RPC call ExecuteCommand to plugin failed") {
+			assert.Equal(t, "crashrequestid", record["request_id"])
+			found = true
+		}
+	}
+	assert.True(t, found, "the failed RPC call should be logged with its request id")
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package plugin_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+func TestIDFromRequest(t *testing.T) {
+	t.Run("context", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(model.HEADER_PLUGIN_ID, "spoofed")
+
+		r = plugin.RequestWithID(r, "testplugin")
+		assert.Equal(t, "testplugin", plugin.IDFromRequest(r))
+		assert.Equal(t, "testplugin", r.Header.Get(model.HEADER_PLUGIN_ID))
+		assert.Equal(t, "testplugin", r.Context().Value("plugin_id"))
+	})
+
+	t.Run("context dropped", func(t *testing.T) {
+		r := plugin.RequestWithID(httptest.NewRequest(http.MethodGet, "/", nil), "testplugin")
+		assert.Equal(t, "testplugin", plugin.IDFromRequest(r.WithContext(context.Background())))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		assert.Equal(t, "", plugin.IDFromRequest(httptest.NewRequest(http.MethodGet, "/", nil)))
+	})
+}
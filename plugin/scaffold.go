@@ -0,0 +1,289 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// hooksType is the interface whose methods are stubbed out by Scaffold, so that generated plugins
+// always implement the hooks as this version of the server invokes them.
+var hooksType = reflect.TypeOf((*Hooks)(nil)).Elem()
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// unscaffoldedHooks are the methods of Hooks that plugins do not implement themselves.
+var unscaffoldedHooks = map[string]bool{
+	"Implemented": true,
+}
+
+// The platforms for which scaffolded plugins are built, and the paths of their executables.
+var scaffoldExecutables = &model.ManifestExecutables{
+	LinuxAmd64:   "server/dist/plugin-linux-amd64",
+	DarwinAmd64:  "server/dist/plugin-darwin-amd64",
+	WindowsAmd64: "server/dist/plugin-windows-amd64.exe",
+}
+
+// ScaffoldHooks returns the names of the hooks that a scaffolded plugin may implement, in
+// alphabetical order.
+func ScaffoldHooks() []string {
+	hooks := []string{}
+	for i := 0; i < hooksType.NumMethod(); i++ {
+		if name := hooksType.Method(i).Name; !unscaffoldedHooks[name] {
+			hooks = append(hooks, name)
+		}
+	}
+	return hooks
+}
+
+// Scaffold generates the skeleton of a new plugin: its manifest, a server component implementing
+// the requested hooks against this version of the plugin package, and a Makefile building its
+// bundle. The files are returned by their paths relative to the plugin's directory.
+func Scaffold(request *model.PluginScaffoldRequest) (map[string][]byte, error) {
+	var methods []reflect.Method
+	seen := make(map[string]bool)
+	for _, hook := range request.Hooks {
+		method, ok := hooksType.MethodByName(hook)
+		if !ok || unscaffoldedHooks[hook] {
+			return nil, fmt.Errorf("unknown hook %v", hook)
+		}
+		if !seen[hook] {
+			seen[hook] = true
+			methods = append(methods, method)
+		}
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+
+	manifest, err := json.MarshalIndent(&model.Manifest{
+		Id:               request.PluginId,
+		Name:             request.PluginId,
+		Version:          model.PLUGIN_SCAFFOLD_VERSION,
+		MinServerVersion: model.CurrentVersion,
+		Server:           &model.ManifestServer{Executables: scaffoldExecutables},
+		Capabilities:     request.Capabilities,
+	}, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+
+	mainGo, err := scaffoldMain(request.PluginId, methods)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		"plugin.json":    append(manifest, '\n'),
+		"go.mod":         scaffoldGoMod(request.PluginId),
+		"Makefile":       scaffoldMakefile(request.PluginId),
+		"server/main.go": mainGo,
+	}, nil
+}
+
+func scaffoldMain(pluginId string, methods []reflect.Method) ([]byte, error) {
+	imports := map[string]bool{
+		"github.com/mattermost/mattermost-server/plugin": true,
+	}
+
+	var stubs bytes.Buffer
+	for _, method := range methods {
+		usedNames := make(map[string]int)
+		params := make([]string, 0, method.Type.NumIn())
+		paramNames := make(map[reflect.Type]string)
+		for i := 0; i < method.Type.NumIn(); i++ {
+			paramType := method.Type.In(i)
+			paramName := scaffoldParamName(paramType, usedNames)
+			params = append(params, paramName+" "+scaffoldTypeName(paramType, imports))
+			if _, ok := paramNames[paramType]; !ok {
+				paramNames[paramType] = paramName
+			}
+		}
+
+		// The stubs leave things as they are. Hooks such as MessageWillBePosted reject what they are
+		// given if it is not returned, so parameters are returned where their type allows.
+		results := make([]string, 0, method.Type.NumOut())
+		returned := make([]string, 0, method.Type.NumOut())
+		for i := 0; i < method.Type.NumOut(); i++ {
+			resultType := method.Type.Out(i)
+			results = append(results, scaffoldTypeName(resultType, imports))
+			if paramName, ok := paramNames[resultType]; ok && resultType.Kind() != reflect.String {
+				returned = append(returned, paramName)
+			} else {
+				returned = append(returned, scaffoldZeroValue(resultType, imports))
+			}
+		}
+
+		fmt.Fprintf(&stubs, "\n// %s is invoked by the server as described by plugin.Hooks.\n", method.Name)
+		fmt.Fprintf(&stubs, "func (p *Plugin) %s(%s) ", method.Name, strings.Join(params, ", "))
+		if len(results) == 1 {
+			fmt.Fprintf(&stubs, "%s ", results[0])
+		} else if len(results) > 1 {
+			fmt.Fprintf(&stubs, "(%s) ", strings.Join(results, ", "))
+		}
+		stubs.WriteString("{\n")
+		if len(returned) > 0 {
+			fmt.Fprintf(&stubs, "return %s\n", strings.Join(returned, ", "))
+		}
+		stubs.WriteString("}\n")
+	}
+
+	// Standard library packages are imported apart from the others.
+	var standardImports, otherImports []string
+	for path := range imports {
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			otherImports = append(otherImports, strconv.Quote(path))
+		} else {
+			standardImports = append(standardImports, strconv.Quote(path))
+		}
+	}
+	sort.Strings(standardImports)
+	sort.Strings(otherImports)
+
+	var source bytes.Buffer
+	fmt.Fprintf(&source, "// Package main is the server component of the %s plugin.\n", pluginId)
+	source.WriteString("package main\n\nimport (\n")
+	if len(standardImports) > 0 {
+		source.WriteString(strings.Join(standardImports, "\n") + "\n\n")
+	}
+	source.WriteString(strings.Join(otherImports, "\n") + "\n)\n\n")
+	source.WriteString("// Plugin implements the plugin's hooks. Its exported fields are loaded from the plugin's\n")
+	source.WriteString("// configuration.\n")
+	source.WriteString("type Plugin struct {\nplugin.MattermostPlugin\n}\n")
+	source.Write(stubs.Bytes())
+	source.WriteString("\nfunc main() {\nplugin.ClientMain(&Plugin{})\n}\n")
+
+	return format.Source(source.Bytes())
+}
+
+// scaffoldTypeName returns the name of the type as written in the scaffolded plugin, recording
+// the packages it must import to refer to it.
+func scaffoldTypeName(t reflect.Type, imports map[string]bool) string {
+	addScaffoldImports(t, imports)
+	return t.String()
+}
+
+func addScaffoldImports(t reflect.Type, imports map[string]bool) {
+	if t.Name() != "" {
+		if t.PkgPath() != "" {
+			imports[t.PkgPath()] = true
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Chan:
+		addScaffoldImports(t.Elem(), imports)
+	case reflect.Map:
+		addScaffoldImports(t.Key(), imports)
+		addScaffoldImports(t.Elem(), imports)
+	}
+}
+
+// scaffoldParamName names a parameter after its type, since the names of the parameters of the
+// Hooks interface are not known at run time. Names already used by the method are numbered.
+func scaffoldParamName(t reflect.Type, usedNames map[string]int) string {
+	switch t {
+	case reflect.TypeOf((*Context)(nil)):
+		return "c"
+	case reflect.TypeOf((*http.ResponseWriter)(nil)).Elem():
+		return "w"
+	case reflect.TypeOf((*http.Request)(nil)):
+		return "r"
+	}
+
+	suffix := ""
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+		suffix = "s"
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	name := "arg"
+	if t.PkgPath() != "" {
+		first, size := utf8.DecodeRuneInString(t.Name())
+		name = string(unicode.ToLower(first)) + t.Name()[size:] + suffix
+	}
+
+	usedNames[name]++
+	if name == "arg" || usedNames[name] > 1 {
+		name += strconv.Itoa(usedNames[name])
+	}
+	return name
+}
+
+// scaffoldZeroValue returns the value that stubs return when none of their parameters will do: an
+// empty struct for pointers to structs other than errors, so that results such as a command's
+// response are not missing, and otherwise the zero value of the type.
+func scaffoldZeroValue(t reflect.Type, imports map[string]bool) string {
+	switch t.Kind() {
+	case reflect.Ptr:
+		if t.Elem().Kind() == reflect.Struct && !t.Implements(errorType) {
+			return "&" + scaffoldTypeName(t.Elem(), imports) + "{}"
+		}
+		return "nil"
+	case reflect.Slice, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+		return "nil"
+	case reflect.String:
+		return `""`
+	case reflect.Bool:
+		return "false"
+	case reflect.Struct, reflect.Array:
+		return scaffoldTypeName(t, imports) + "{}"
+	default:
+		return "0"
+	}
+}
+
+func scaffoldGoMod(pluginId string) []byte {
+	return []byte(fmt.Sprintf(`module %s
+
+go 1.12
+
+require github.com/mattermost/mattermost-server v%s+incompatible
+`, pluginId, model.CurrentVersion))
+}
+
+func scaffoldMakefile(pluginId string) []byte {
+	return []byte(fmt.Sprintf(`PLUGIN_ID ?= %s
+PLUGIN_VERSION ?= %s
+GO ?= go
+
+.PHONY: all server bundle clean
+
+all: bundle
+
+go.sum: go.mod
+	$(GO) mod tidy
+
+## Builds the server component for every platform listed in plugin.json.
+server: go.sum
+	cd server && env GOOS=linux GOARCH=amd64 $(GO) build -o ../%s
+	cd server && env GOOS=darwin GOARCH=amd64 $(GO) build -o ../%s
+	cd server && env GOOS=windows GOARCH=amd64 $(GO) build -o ../%s
+
+## Builds the plugin bundle, dist/$(PLUGIN_ID)-$(PLUGIN_VERSION).tar.gz, to be uploaded to the server.
+bundle: server
+	rm -rf dist
+	mkdir -p dist/$(PLUGIN_ID)/server
+	cp plugin.json dist/$(PLUGIN_ID)/
+	cp -r server/dist dist/$(PLUGIN_ID)/server/
+	cd dist && tar -czf $(PLUGIN_ID)-$(PLUGIN_VERSION).tar.gz $(PLUGIN_ID)
+
+clean:
+	rm -rf dist server/dist
+`, pluginId, model.PLUGIN_SCAFFOLD_VERSION, scaffoldExecutables.LinuxAmd64, scaffoldExecutables.DarwinAmd64, scaffoldExecutables.WindowsAmd64))
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package plugin
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestScaffold(t *testing.T) {
+	hooks := ScaffoldHooks()
+	assert.Contains(t, hooks, "MessageWillBePosted")
+	assert.NotContains(t, hooks, "Implemented")
+
+	files, err := Scaffold(&model.PluginScaffoldRequest{
+		PluginId:     "com.example.scaffold",
+		Hooks:        append(hooks, "OnActivate"),
+		Capabilities: []string{model.PLUGIN_CAPABILITY_POSTS_WRITE},
+	})
+	require.NoError(t, err)
+	assert.Len(t, files, 4)
+	assert.Contains(t, files, "Makefile")
+	assert.Contains(t, string(files["go.mod"]), "github.com/mattermost/mattermost-server v"+model.CurrentVersion)
+
+	manifest := model.ManifestFromJson(bytes.NewReader(files["plugin.json"]))
+	require.NotNil(t, manifest)
+	assert.Nil(t, manifest.IsValid())
+	assert.Equal(t, "com.example.scaffold", manifest.Id)
+	assert.Equal(t, model.CurrentVersion, manifest.MinServerVersion)
+	assert.Equal(t, []string{model.PLUGIN_CAPABILITY_POSTS_WRITE}, manifest.Capabilities)
+
+	// The generated server component must build against this version of the plugin package. With
+	// every hook requested, it implements Hooks, bar the method the plugin package provides itself.
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	executable := filepath.Join(dir, "plugin.exe")
+	compileGo(t, string(files["server/main.go"])+`
+		func (p *Plugin) Implemented() ([]string, error) { return nil, nil }
+
+		var _ plugin.Hooks = &Plugin{}
+	`, executable)
+
+	// Stubs leave posts, files and exports as they are.
+	mainGo := string(files["server/main.go"])
+	assert.Contains(t, mainGo, "return post, \"\"")
+	assert.Contains(t, mainGo, "return fileInfo, \"\"")
+	assert.Contains(t, mainGo, "return posts\n")
+
+	t.Run("unknown hook", func(t *testing.T) {
+		for _, hook := range []string{"MessageWillBeDeleted", "Implemented"} {
+			_, err := Scaffold(&model.PluginScaffoldRequest{PluginId: "com.example.scaffold", Hooks: []string{hook}})
+			assert.Error(t, err, hook)
+		}
+	})
+
+	t.Run("no hooks", func(t *testing.T) {
+		files, err := Scaffold(&model.PluginScaffoldRequest{PluginId: "com.example.scaffold"})
+		require.NoError(t, err)
+		compileGo(t, string(files["server/main.go"]), executable)
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/go-plugin"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// rpcStreamThreshold is the size above which byte slices crossing the RPC boundary are transferred
+// over a dedicated stream rather than gob-encoded into the call itself. Large inline payloads are
+// buffered in full by both the encoder and decoder, and block any other call on the connection
+// until they have been written.
+const rpcStreamThreshold = 1024 * 1024
+
+// serveBytesStream serves data over a new stream of the broker, returning the id of the stream for
+// the remote end to read it with readBytesStream. The data is sent in chunks as it is requested, so
+// the transfer is subject to the flow control of the underlying connection.
+func serveBytesStream(broker *plugin.MuxBroker, data []byte) uint32 {
+	streamId := broker.NextId()
+	go func() {
+		connection, err := broker.Accept(streamId)
+		if err != nil {
+			// The remote end reports failing to connect to the stream.
+			return
+		}
+		defer connection.Close()
+		serveIOReader(bytes.NewReader(data), connection)
+	}()
+
+	return streamId
+}
+
+// readBytesStream reads size bytes from the stream served by serveBytesStream. The size is given by
+// the remote end, so sizes no value could have are refused before anything is allocated.
+func readBytesStream(broker *plugin.MuxBroker, streamId uint32, size int) ([]byte, error) {
+	if size <= 0 || size > model.KEY_VALUE_VALUE_MAX_BYTES {
+		return nil, fmt.Errorf("invalid stream size %v", size)
+	}
+
+	connection, err := broker.Dial(streamId)
+	if err != nil {
+		return nil, err
+	}
+	r := connectIOReader(connection)
+	defer r.Close()
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package plugin
+
+import (
+	"bytes"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+type kvTestAPI struct {
+	API
+
+	lock   sync.Mutex
+	values map[string][]byte
+}
+
+func (api *kvTestAPI) KVSet(key string, value []byte) *model.AppError {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	api.values[key] = value
+	return nil
+}
+
+func (api *kvTestAPI) KVGet(key string) ([]byte, *model.AppError) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	return api.values[key], nil
+}
+
+func (api *kvTestAPI) KVGetWithExists(key string) ([]byte, bool, *model.AppError) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	value, ok := api.values[key]
+	return value, ok, nil
+}
+
+type apiTestPlugin struct {
+	api API
+}
+
+func (p *apiTestPlugin) SetAPI(api API) {
+	p.api = api
+}
+
+func (p *apiTestPlugin) OnConfigurationChange() error {
+	return nil
+}
+
+// connectTestPlugin activates an in-process plugin over RPC, returning the API client it was given.
+func connectTestPlugin(t testing.TB, api API) API {
+	p := &apiTestPlugin{}
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		"hooks": &hooksPlugin{
+			hooks:   p,
+			apiImpl: api,
+			log:     mlog.NewLogger(&mlog.LoggerConfiguration{}),
+		},
+	}, nil)
+
+	raw, err := client.Dispense("hooks")
+	require.NoError(t, err)
+	require.NoError(t, raw.(Hooks).OnActivate())
+	require.NotNil(t, p.api)
+
+	return p.api
+}
+
+func TestKVStreaming(t *testing.T) {
+	api := &kvTestAPI{values: map[string][]byte{}}
+	pluginAPI := connectTestPlugin(t, api)
+
+	for name, size := range map[string]int{
+		"empty":           0,
+		"inline":          1024,
+		"threshold":       rpcStreamThreshold,
+		"streamed":        rpcStreamThreshold + 1,
+		"streamed, large": 10 * 1024 * 1024,
+	} {
+		t.Run(name, func(t *testing.T) {
+			value := bytes.Repeat([]byte{'a', 'b', 'c'}, size/3+1)[:size]
+
+			require.Nil(t, pluginAPI.KVSet(name, value))
+			assert.True(t, bytes.Equal(value, api.values[name]))
+
+			received, err := pluginAPI.KVGet(name)
+			require.Nil(t, err)
+			assert.True(t, bytes.Equal(value, received))
+
+			received, exists, err := pluginAPI.KVGetWithExists(name)
+			require.Nil(t, err)
+			assert.True(t, exists)
+			assert.NotNil(t, received)
+			assert.True(t, bytes.Equal(value, received))
+		})
+	}
+
+	received, err := pluginAPI.KVGet("missing")
+	assert.Nil(t, err)
+	assert.Nil(t, received)
+
+	received, exists, err := pluginAPI.KVGetWithExists("missing")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+	assert.Nil(t, received)
+}
+
+func TestKVSetStreamSize(t *testing.T) {
+	api := &kvTestAPI{values: map[string][]byte{}}
+	server := &apiRPCServer{impl: api}
+
+	// The size of a streamed value is given by the plugin, so it must not be trusted.
+	for _, size := range []int{-1, 0, model.KEY_VALUE_VALUE_MAX_BYTES + 1, math.MaxInt64} {
+		err := server.KVSet(&Z_KVSetArgs{A: "key", BStream: 1, BSize: size}, &Z_KVSetReturns{})
+		assert.Error(t, err)
+	}
+	assert.Empty(t, api.values)
+}
+
+func benchmarkKVRoundTrip(b *testing.B, size int) {
+	api := &kvTestAPI{values: map[string][]byte{}}
+	pluginAPI := connectTestPlugin(b, api)
+	value := make([]byte, size)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := pluginAPI.KVSet("key", value); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := pluginAPI.KVGet("key"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkKVRoundTrip1KB(b *testing.B) {
+	benchmarkKVRoundTrip(b, 1024)
+}
+
+func BenchmarkKVRoundTrip10MB(b *testing.B) {
+	benchmarkKVRoundTrip(b, 10*1024*1024)
+}
@@ -23,7 +23,23 @@ type supervisor struct {
 	implemented [TotalHooksId]bool
 }
 
-func newSupervisor(pluginInfo *model.BundleInfo, parentLogger *mlog.Logger, apiImpl API) (retSupervisor *supervisor, retErr error) {
+func newSupervisor(pluginInfo *model.BundleInfo, parentLogger *mlog.Logger, apiImpl API) (*supervisor, error) {
+	supervisor, err := startSupervisor(pluginInfo, parentLogger, apiImpl)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := supervisor.Hooks().OnActivate(); err != nil {
+		supervisor.Shutdown()
+		return nil, err
+	}
+
+	return supervisor, nil
+}
+
+// startSupervisor starts the plugin's process without activating it, leaving OnActivate to the
+// caller.
+func startSupervisor(pluginInfo *model.BundleInfo, parentLogger *mlog.Logger, apiImpl API) (retSupervisor *supervisor, retErr error) {
 	supervisor := supervisor{}
 	defer func() {
 		if retErr != nil {
@@ -86,11 +102,6 @@ func newSupervisor(pluginInfo *model.BundleInfo, parentLogger *mlog.Logger, apiI
 		}
 	}
 
-	err = supervisor.Hooks().OnActivate()
-	if err != nil {
-		return nil, err
-	}
-
 	return &supervisor, nil
 }
 
@@ -100,6 +111,11 @@ func (sup *supervisor) Shutdown() {
 	}
 }
 
+// Exited returns whether the plugin's process has exited, such as after crashing.
+func (sup *supervisor) Exited() bool {
+	return sup.client != nil && sup.client.Exited()
+}
+
 func (sup *supervisor) Hooks() Hooks {
 	return sup.hooks
 }
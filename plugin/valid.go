@@ -6,11 +6,13 @@ package plugin
 import (
 	"regexp"
 	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/model"
 )
 
 const (
-	MinIdLength  = 3
-	MaxIdLength  = 190
+	MinIdLength  = model.PLUGIN_ID_MIN_LENGTH
+	MaxIdLength  = model.PLUGIN_ID_MAX_LENGTH
 	ValidIdRegex = `^[a-zA-Z0-9-_\.]+$`
 )
 
@@ -0,0 +1,93 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package plugin
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// warmStartHookQueueSize bounds the hook invocations queued for a warm starting plugin. Further
+// invocations are dropped until the plugin is running.
+const warmStartHookQueueSize = 1000
+
+// ErrPluginStarting is returned for a warm starting plugin that has not yet finished activating.
+var ErrPluginStarting = errors.New("plugin is starting")
+
+// hookQueueOverflowHandlerFunc is invoked whenever a hook invocation for a plugin is dropped because
+// its queue is full, either while the plugin is warm starting or in the pool running asynchronous
+// hooks.
+type hookQueueOverflowHandlerFunc func(pluginId string)
+
+// warmStart queues the hook invocations for a plugin whose process has been started but whose
+// OnActivate hook has not yet returned, so that they can be replayed in order once it is running.
+type warmStart struct {
+	pluginId string
+	size     int
+	overflow hookQueueOverflowHandlerFunc
+
+	lock  sync.Mutex
+	queue []func(Hooks)
+	done  bool
+
+	// hooks are those of the running plugin, or nil if it failed to activate.
+	hooks Hooks
+}
+
+// run invokes f with the plugin's hooks once it is running, or immediately if it already is. The
+// invocation is dropped if the plugin fails to activate or too many invocations are queued.
+func (ws *warmStart) run(f func(Hooks)) {
+	ws.lock.Lock()
+	if !ws.done {
+		if len(ws.queue) >= ws.size {
+			ws.lock.Unlock()
+			if ws.overflow != nil {
+				ws.overflow(ws.pluginId)
+			}
+			return
+		}
+
+		ws.queue = append(ws.queue, f)
+		ws.lock.Unlock()
+		return
+	}
+	hooks := ws.hooks
+	ws.lock.Unlock()
+
+	if hooks != nil {
+		f(hooks)
+	}
+}
+
+// replay invokes the queued invocations in order with the hooks of the now running plugin.
+//
+// The lock is not held while a hook runs, since the plugin may cause further hooks to be invoked
+// while handling it. Those are queued behind the rest, so the order is preserved.
+func (ws *warmStart) replay(hooks Hooks) {
+	for {
+		ws.lock.Lock()
+		if len(ws.queue) == 0 {
+			ws.done = true
+			ws.hooks = hooks
+			ws.lock.Unlock()
+			return
+		}
+
+		f := ws.queue[0]
+		ws.queue = ws.queue[1:]
+		ws.lock.Unlock()
+
+		f(hooks)
+	}
+}
+
+// discard drops the queued invocations of a plugin that failed to activate.
+func (ws *warmStart) discard() {
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+
+	ws.queue = nil
+	ws.done = true
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package plugin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestWarmStartQueue(t *testing.T) {
+	t.Run("replayed in order", func(t *testing.T) {
+		ws := &warmStart{pluginId: "foo", size: 10}
+
+		var order []int
+		for i := 0; i < 3; i++ {
+			i := i
+			ws.run(func(Hooks) {
+				order = append(order, i)
+
+				// Hooks invoked while replaying are queued behind the rest
+				if i == 0 {
+					ws.run(func(Hooks) { order = append(order, 3) })
+				}
+			})
+		}
+		assert.Empty(t, order)
+
+		ws.replay(&hooksRPCClient{})
+		assert.Equal(t, []int{0, 1, 2, 3}, order)
+
+		// Once running, hooks are invoked immediately
+		ws.run(func(Hooks) { order = append(order, 4) })
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		var overflowed []string
+		ws := &warmStart{pluginId: "foo", size: 2, overflow: func(pluginId string) {
+			overflowed = append(overflowed, pluginId)
+		}}
+
+		invoked := 0
+		for i := 0; i < 3; i++ {
+			ws.run(func(Hooks) { invoked++ })
+		}
+		assert.Equal(t, []string{"foo"}, overflowed)
+
+		ws.replay(&hooksRPCClient{})
+		assert.Equal(t, 2, invoked)
+	})
+
+	t.Run("discarded", func(t *testing.T) {
+		ws := &warmStart{pluginId: "foo", size: 10}
+
+		invoked := 0
+		ws.run(func(Hooks) { invoked++ })
+		ws.discard()
+		ws.run(func(Hooks) { invoked++ })
+		assert.Equal(t, 0, invoked)
+	})
+}
+
+// warmStartTestAPI blocks the plugin's OnActivate until unblocked, and records the messages of the
+// posts it is told about.
+type warmStartTestAPI struct {
+	API
+
+	unblock chan struct{}
+
+	lock   sync.Mutex
+	posted []string
+}
+
+func (api *warmStartTestAPI) LoadPluginConfiguration(dest interface{}) error {
+	return nil
+}
+
+func (api *warmStartTestAPI) KVGet(key string) ([]byte, *model.AppError) {
+	<-api.unblock
+	return nil, nil
+}
+
+func (api *warmStartTestAPI) KVSet(key string, value []byte) *model.AppError {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	api.posted = append(api.posted, string(value))
+	return nil
+}
+
+func TestEnvironmentWarmStart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	compileGo(t, `
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/model"
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) OnActivate() error {
+			p.API.KVGet("block")
+			return nil
+		}
+
+		func (p *MyPlugin) MessageHasBeenPosted(c *plugin.Context, post *model.Post) {
+			p.API.KVSet("posted", []byte(post.Message))
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`, filepath.Join(dir, "foo", "backend.exe"))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "foo", "plugin.json"), []byte(`{"id": "foo", "warm_start": true, "backend": {"executable": "backend.exe"}}`), 0600))
+
+	api := &warmStartTestAPI{unblock: make(chan struct{})}
+	env, err := NewEnvironment(func(*model.Manifest) API { return api }, dir, dir, mlog.NewLogger(&mlog.LoggerConfiguration{}))
+	require.NoError(t, err)
+	defer env.Shutdown()
+
+	activated := make(chan error)
+	go func() {
+		_, _, err := env.Activate("foo")
+		activated <- err
+	}()
+
+	state := func() int {
+		statuses, err := env.Statuses()
+		require.NoError(t, err)
+		require.Len(t, statuses, 1)
+		return statuses[0].State
+	}
+
+	for deadline := time.Now().Add(10 * time.Second); state() != model.PluginStateStarting; time.Sleep(10 * time.Millisecond) {
+		require.True(t, time.Now().Before(deadline), "plugin never started")
+	}
+	assert.True(t, env.IsActive("foo"))
+
+	_, err = env.HooksForPlugin("foo")
+	assert.Equal(t, ErrPluginStarting, err)
+
+	for _, message := range []string{"1", "2", "3"} {
+		post := &model.Post{Message: message}
+		env.RunMultiPluginHook(func(hooks Hooks) bool {
+			hooks.MessageHasBeenPosted(&Context{}, post)
+			return true
+		}, MessageHasBeenPostedId)
+	}
+
+	api.lock.Lock()
+	assert.Empty(t, api.posted)
+	api.lock.Unlock()
+
+	close(api.unblock)
+	require.NoError(t, <-activated)
+
+	assert.Equal(t, model.PluginStateRunning, state())
+	_, err = env.HooksForPlugin("foo")
+	assert.NoError(t, err)
+
+	api.lock.Lock()
+	assert.Equal(t, []string{"1", "2", "3"}, api.posted)
+	api.lock.Unlock()
+}
@@ -25,13 +25,18 @@ type LayeredStore struct {
 	ReactionStore   ReactionStore
 	RoleStore       RoleStore
 	SchemeStore     SchemeStore
+	PluginStore     PluginStore
 	DatabaseLayer   LayeredStoreDatabaseLayer
 	LocalCacheLayer *LocalCacheSupplier
 	RedisLayer      *RedisSupplier
 	LayerChainHead  LayeredStoreSupplier
 }
 
-func NewLayeredStore(db LayeredStoreDatabaseLayer, metrics einterfaces.MetricsInterface, cluster einterfaces.ClusterInterface) Store {
+// NewLayeredStore creates a store backed by db. Up to pluginKeyValueCacheSize plugin key-value pairs
+// are cached in memory for at most pluginKeyValueCacheSeconds each, and none if the size is zero.
+// Requests for the others are limited to pluginKeyValueMaxConcurrentOperations at a time, or not at
+// all if it is zero.
+func NewLayeredStore(db LayeredStoreDatabaseLayer, metrics einterfaces.MetricsInterface, cluster einterfaces.ClusterInterface, pluginKeyValueCacheSize int, pluginKeyValueCacheSeconds int, pluginKeyValueMaxConcurrentOperations int) Store {
 	store := &LayeredStore{
 		TmpContext:      context.TODO(),
 		DatabaseLayer:   db,
@@ -42,6 +47,17 @@ func NewLayeredStore(db LayeredStoreDatabaseLayer, metrics einterfaces.MetricsIn
 	store.RoleStore = &LayeredRoleStore{store}
 	store.SchemeStore = &LayeredSchemeStore{store}
 
+	store.PluginStore = db.Plugin()
+	if metrics != nil {
+		store.PluginStore = NewMetricsPluginStore(store.PluginStore, metrics)
+	}
+	if pluginKeyValueMaxConcurrentOperations > 0 {
+		store.PluginStore = NewLimitedPluginStore(store.PluginStore, pluginKeyValueMaxConcurrentOperations, metrics)
+	}
+	if pluginKeyValueCacheSize > 0 {
+		store.PluginStore = NewLocalCachePluginStore(store.PluginStore, pluginKeyValueCacheSize, int64(pluginKeyValueCacheSeconds), metrics, cluster)
+	}
+
 	// Setup the chain
 	if ENABLE_EXPERIMENTAL_REDIS {
 		mlog.Debug("Experimental redis enabled.")
@@ -162,7 +178,35 @@ func (s *LayeredStore) ChannelMemberHistory() ChannelMemberHistoryStore {
 }
 
 func (s *LayeredStore) Plugin() PluginStore {
-	return s.DatabaseLayer.Plugin()
+	return s.PluginStore
+}
+
+func (s *LayeredStore) PluginSubscription() PluginSubscriptionStore {
+	return s.DatabaseLayer.PluginSubscription()
+}
+
+func (s *LayeredStore) PostAcknowledgement() PostAcknowledgementStore {
+	return s.DatabaseLayer.PostAcknowledgement()
+}
+
+func (s *LayeredStore) PluginRuntimeState() PluginRuntimeStateStore {
+	return s.DatabaseLayer.PluginRuntimeState()
+}
+
+func (s *LayeredStore) PluginPostMetadata() PluginPostMetadataStore {
+	return s.DatabaseLayer.PluginPostMetadata()
+}
+
+func (s *LayeredStore) PluginOAuthConnection() PluginOAuthConnectionStore {
+	return s.DatabaseLayer.PluginOAuthConnection()
+}
+
+func (s *LayeredStore) PluginAdminActionRequest() PluginAdminActionRequestStore {
+	return s.DatabaseLayer.PluginAdminActionRequest()
+}
+
+func (s *LayeredStore) PluginEvent() PluginEventStore {
+	return s.DatabaseLayer.PluginEvent()
 }
 
 func (s *LayeredStore) Role() RoleStore {
@@ -205,6 +249,14 @@ func (s *LayeredStore) TotalSearchDbConnections() int {
 	return s.DatabaseLayer.TotalSearchDbConnections()
 }
 
+func (s *LayeredStore) PingMaster() error {
+	return s.DatabaseLayer.PingMaster()
+}
+
+func (s *LayeredStore) PingReplicas() error {
+	return s.DatabaseLayer.PingReplicas()
+}
+
 type LayeredReactionStore struct {
 	*LayeredStore
 }
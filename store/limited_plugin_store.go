@@ -0,0 +1,172 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/einterfaces"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// LimitedPluginStore limits the number of plugin key-value reads and writes that may use database
+// connections at the same time, so that plugins making many requests cannot starve the rest of the
+// server of connections. Further requests wait, in the order they were made, for one of those in
+// progress to complete.
+type LimitedPluginStore struct {
+	PluginStore
+	slots   chan struct{}
+	metrics einterfaces.MetricsInterface
+}
+
+// NewLimitedPluginStore allows at most maxConcurrentOperations requests to pluginStore at a time.
+// The time requests spend waiting for their turn is recorded with metrics, if not nil.
+func NewLimitedPluginStore(pluginStore PluginStore, maxConcurrentOperations int, metrics einterfaces.MetricsInterface) *LimitedPluginStore {
+	return &LimitedPluginStore{
+		PluginStore: pluginStore,
+		slots:       make(chan struct{}, maxConcurrentOperations),
+		metrics:     metrics,
+	}
+}
+
+// acquire waits until a request for the given plugin may proceed. The returned function must be
+// called once the request has completed.
+func (s *LimitedPluginStore) acquire(pluginId string) func() {
+	start := time.Now()
+	s.slots <- struct{}{}
+	if s.metrics != nil {
+		s.metrics.ObservePluginStoreQueueWaitDuration(pluginId, time.Since(start).Seconds())
+	}
+
+	return s.release
+}
+
+func (s *LimitedPluginStore) release() {
+	<-s.slots
+}
+
+func (s *LimitedPluginStore) SaveOrUpdate(kv *model.PluginKeyValue) (*model.PluginKeyValue, *model.AppError) {
+	defer s.acquire(kv.PluginId)()
+	return s.PluginStore.SaveOrUpdate(kv)
+}
+
+func (s *LimitedPluginStore) SaveOrUpdateMultiple(kvs []*model.PluginKeyValue) ([]*model.PluginKeyValue, *model.AppError) {
+	pluginId := ""
+	if len(kvs) > 0 {
+		pluginId = kvs[0].PluginId
+	}
+
+	defer s.acquire(pluginId)()
+	return s.PluginStore.SaveOrUpdateMultiple(kvs)
+}
+
+func (s *LimitedPluginStore) SaveOrUpdateMany(pluginId string, ops []model.PluginKVOp) ([]model.PluginKVOp, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.SaveOrUpdateMany(pluginId, ops)
+}
+
+func (s *LimitedPluginStore) CompareAndSet(kv *model.PluginKeyValue, oldValue []byte) (bool, *model.AppError) {
+	defer s.acquire(kv.PluginId)()
+	return s.PluginStore.CompareAndSet(kv, oldValue)
+}
+
+func (s *LimitedPluginStore) Get(pluginId, key string) (*model.PluginKeyValue, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.Get(pluginId, key)
+}
+
+func (s *LimitedPluginStore) GetFromMaster(pluginId, key string) (*model.PluginKeyValue, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.GetFromMaster(pluginId, key)
+}
+
+func (s *LimitedPluginStore) GetMultiple(pluginId string, keys []string) ([]*model.PluginKeyValue, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.GetMultiple(pluginId, keys)
+}
+
+func (s *LimitedPluginStore) GetMultipleFromMaster(pluginId string, keys []string) ([]*model.PluginKeyValue, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.GetMultipleFromMaster(pluginId, keys)
+}
+
+func (s *LimitedPluginStore) List(pluginId string, offset, limit int) ([]string, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.List(pluginId, offset, limit)
+}
+
+func (s *LimitedPluginStore) ListWithPrefix(pluginId, prefix string, offset, limit int) ([]string, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.ListWithPrefix(pluginId, prefix, offset, limit)
+}
+
+func (s *LimitedPluginStore) GetAll(offset, limit int) ([]*model.PluginKeyValue, *model.AppError) {
+	defer s.acquire("")()
+	return s.PluginStore.GetAll(offset, limit)
+}
+
+func (s *LimitedPluginStore) GetAllForPlugin(pluginId string, offset, limit int) ([]*model.PluginKeyValue, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.GetAllForPlugin(pluginId, offset, limit)
+}
+
+func (s *LimitedPluginStore) Delete(pluginId, key string) (bool, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.Delete(pluginId, key)
+}
+
+func (s *LimitedPluginStore) CompareAndDelete(pluginId, key string, oldValue []byte) (bool, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.CompareAndDelete(pluginId, key, oldValue)
+}
+
+func (s *LimitedPluginStore) Copy(pluginId, key, newKey string) (bool, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.Copy(pluginId, key, newKey)
+}
+
+func (s *LimitedPluginStore) Move(pluginId, key, newKey string) (bool, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.Move(pluginId, key, newKey)
+}
+
+func (s *LimitedPluginStore) GetHashedKeys(afterPluginId, afterKey string, limit int) ([]*model.PluginKeyValue, *model.AppError) {
+	defer s.acquire("")()
+	return s.PluginStore.GetHashedKeys(afterPluginId, afterKey, limit)
+}
+
+func (s *LimitedPluginStore) MoveHashedKey(pluginId, hashedKey, key string) (bool, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.MoveHashedKey(pluginId, hashedKey, key)
+}
+
+func (s *LimitedPluginStore) CountHashedKeys() (*model.PluginKeyValueMigrationStatus, *model.AppError) {
+	defer s.acquire("")()
+	return s.PluginStore.CountHashedKeys()
+}
+
+func (s *LimitedPluginStore) Increment(pluginId, key string, delta int64) (int64, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.Increment(pluginId, key, delta)
+}
+
+func (s *LimitedPluginStore) DeleteAllForPlugin(pluginId string) (int64, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.DeleteAllForPlugin(pluginId)
+}
+
+func (s *LimitedPluginStore) GetUsage(pluginId string) (*model.PluginKeyValueUsage, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.GetUsage(pluginId)
+}
+
+func (s *LimitedPluginStore) GetUsageFromMaster(pluginId string) (*model.PluginKeyValueUsage, *model.AppError) {
+	defer s.acquire(pluginId)()
+	return s.PluginStore.GetUsageFromMaster(pluginId)
+}
+
+func (s *LimitedPluginStore) DeleteAllExpired() (int64, *model.AppError) {
+	defer s.acquire("")()
+	return s.PluginStore.DeleteAllExpired()
+}
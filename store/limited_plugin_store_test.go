@@ -0,0 +1,100 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+// pooledPluginStore holds one of a fixed number of database connections, shared with the rest of the
+// server, for as long as each request takes.
+type pooledPluginStore struct {
+	store.PluginStore
+
+	connections chan struct{}
+	inProgress  int32
+	maxInUse    int32
+}
+
+func (s *pooledPluginStore) Get(pluginId, key string) (*model.PluginKeyValue, *model.AppError) {
+	s.connections <- struct{}{}
+	defer func() { <-s.connections }()
+
+	inProgress := atomic.AddInt32(&s.inProgress, 1)
+	defer atomic.AddInt32(&s.inProgress, -1)
+	for {
+		maxInUse := atomic.LoadInt32(&s.maxInUse)
+		if inProgress <= maxInUse || atomic.CompareAndSwapInt32(&s.maxInUse, maxInUse, inProgress) {
+			break
+		}
+	}
+
+	time.Sleep(time.Millisecond)
+	return &model.PluginKeyValue{PluginId: pluginId, Key: key}, nil
+}
+
+type queueWaitMetrics struct {
+	fakeMetrics
+
+	waits int32
+}
+
+func (m *queueWaitMetrics) ObservePluginStoreQueueWaitDuration(pluginId string, elapsed float64) {
+	atomic.AddInt32(&m.waits, 1)
+}
+
+func TestLimitedPluginStore(t *testing.T) {
+	const connections = 4
+	const maxConcurrentOperations = 2
+	const requests = 500
+
+	pluginStore := &pooledPluginStore{connections: make(chan struct{}, connections)}
+	metrics := &queueWaitMetrics{}
+	s := store.NewLimitedPluginStore(pluginStore, maxConcurrentOperations, metrics)
+
+	// A plugin hammers the key-value store.
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			kv, err := s.Get("pluginid", "key")
+			assert.Nil(t, err)
+			assert.Equal(t, "key", kv.Key)
+		}()
+	}
+
+	// Other requests always find a connection free without waiting.
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for waiting := true; waiting; {
+		select {
+		case pluginStore.connections <- struct{}{}:
+			<-pluginStore.connections
+		default:
+			assert.Fail(t, "plugin requests used every connection")
+		}
+
+		select {
+		case <-done:
+			waiting = false
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	assert.EqualValues(t, maxConcurrentOperations, atomic.LoadInt32(&pluginStore.maxInUse))
+	assert.EqualValues(t, requests, atomic.LoadInt32(&metrics.waits))
+}
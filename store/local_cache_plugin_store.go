@@ -0,0 +1,202 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/mattermost/mattermost-server/einterfaces"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/utils"
+)
+
+// LocalCachePluginStore caches the plugin key-value pairs read through Get in memory. Writes made
+// through it invalidate the pairs written on every server of the cluster.
+//
+// Pairs missing from the cache are read from the master, so that replication lag does not cause a
+// stale pair to be cached for the lifetime of the cache entry.
+type LocalCachePluginStore struct {
+	PluginStore
+	cache   *utils.Cache
+	metrics einterfaces.MetricsInterface
+	cluster einterfaces.ClusterInterface
+
+	// invalidations is incremented whenever pairs are invalidated, so that a pair read concurrently
+	// with a write is not cached.
+	invalidations uint64
+}
+
+func NewLocalCachePluginStore(pluginStore PluginStore, size int, expirySecs int64, metrics einterfaces.MetricsInterface, cluster einterfaces.ClusterInterface) *LocalCachePluginStore {
+	s := &LocalCachePluginStore{
+		PluginStore: pluginStore,
+		cache:       utils.NewLruWithParams(size, "PluginKeyValue", expirySecs, model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_PLUGIN_KEY_VALUES),
+		metrics:     metrics,
+		cluster:     cluster,
+	}
+
+	if cluster != nil {
+		cluster.RegisterClusterMessageHandler(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_PLUGIN_KEY_VALUES, s.handleClusterInvalidate)
+	}
+
+	return s
+}
+
+func pluginKeyValueCacheKey(pluginId, key string) string {
+	// Plugin ids cannot contain slashes, so the cache key is unambiguous.
+	return pluginId + "/" + key
+}
+
+func (s *LocalCachePluginStore) handleClusterInvalidate(msg *model.ClusterMessage) {
+	atomic.AddUint64(&s.invalidations, 1)
+	if msg.Data == CLEAR_CACHE_MESSAGE_DATA {
+		s.cache.Purge()
+	} else {
+		s.cache.Remove(msg.Data)
+	}
+}
+
+func (s *LocalCachePluginStore) invalidate(pluginId, key string) {
+	atomic.AddUint64(&s.invalidations, 1)
+	cacheKey := pluginKeyValueCacheKey(pluginId, key)
+	s.cache.Remove(cacheKey)
+
+	if s.metrics != nil {
+		s.metrics.IncrementMemCacheInvalidationCounter("Plugin Key Value - Remove by Key")
+	}
+
+	if s.cluster != nil {
+		s.cluster.SendClusterMessage(&model.ClusterMessage{
+			Event:    model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_PLUGIN_KEY_VALUES,
+			SendType: model.CLUSTER_SEND_BEST_EFFORT,
+			Data:     cacheKey,
+		})
+	}
+}
+
+// ClearCaches purges the cached pairs on this server only.
+func (s *LocalCachePluginStore) ClearCaches() {
+	atomic.AddUint64(&s.invalidations, 1)
+	s.cache.Purge()
+
+	if s.metrics != nil {
+		s.metrics.IncrementMemCacheInvalidationCounter("Plugin Key Value - Purge")
+	}
+}
+
+func (s *LocalCachePluginStore) clearCachesCluster() {
+	s.ClearCaches()
+	if s.cluster != nil {
+		s.cluster.SendClusterMessage(&model.ClusterMessage{
+			Event:    model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_PLUGIN_KEY_VALUES,
+			SendType: model.CLUSTER_SEND_BEST_EFFORT,
+			Data:     CLEAR_CACHE_MESSAGE_DATA,
+		})
+	}
+}
+
+// copyPluginKeyValue returns a copy of kv, so that callers cannot modify a cached pair.
+func copyPluginKeyValue(kv *model.PluginKeyValue) *model.PluginKeyValue {
+	copied := *kv
+	copied.Value = append([]byte(nil), kv.Value...)
+	return &copied
+}
+
+func (s *LocalCachePluginStore) Get(pluginId, key string) (*model.PluginKeyValue, *model.AppError) {
+	cacheKey := pluginKeyValueCacheKey(pluginId, key)
+	if cacheItem, ok := s.cache.Get(cacheKey); ok {
+		if s.metrics != nil {
+			s.metrics.IncrementMemCacheHitCounter(s.cache.Name())
+		}
+
+		kv := cacheItem.(*model.PluginKeyValue)
+		if kv.ExpireAt != 0 && kv.ExpireAt <= model.GetMillis() {
+			s.cache.Remove(cacheKey)
+			return nil, model.NewAppError("SqlPluginStore.Get", "store.sql_plugin_store.get.app_error", nil, "plugin_id="+pluginId+", key="+key, http.StatusNotFound)
+		}
+
+		return copyPluginKeyValue(kv), nil
+	}
+
+	if s.metrics != nil {
+		s.metrics.IncrementMemCacheMissCounter(s.cache.Name())
+	}
+
+	invalidations := atomic.LoadUint64(&s.invalidations)
+	kv, err := s.PluginStore.GetFromMaster(pluginId, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if atomic.LoadUint64(&s.invalidations) == invalidations {
+		s.cache.AddWithDefaultExpires(cacheKey, copyPluginKeyValue(kv))
+	}
+
+	return kv, nil
+}
+
+func (s *LocalCachePluginStore) SaveOrUpdate(kv *model.PluginKeyValue) (*model.PluginKeyValue, *model.AppError) {
+	defer s.invalidate(kv.PluginId, kv.Key)
+	return s.PluginStore.SaveOrUpdate(kv)
+}
+
+func (s *LocalCachePluginStore) SaveOrUpdateMultiple(kvs []*model.PluginKeyValue) ([]*model.PluginKeyValue, *model.AppError) {
+	saved, err := s.PluginStore.SaveOrUpdateMultiple(kvs)
+	for _, kv := range kvs {
+		s.invalidate(kv.PluginId, kv.Key)
+	}
+	return saved, err
+}
+
+func (s *LocalCachePluginStore) SaveOrUpdateMany(pluginId string, ops []model.PluginKVOp) ([]model.PluginKVOp, *model.AppError) {
+	saved, err := s.PluginStore.SaveOrUpdateMany(pluginId, ops)
+	for _, op := range ops {
+		s.invalidate(pluginId, op.Key)
+	}
+	return saved, err
+}
+
+func (s *LocalCachePluginStore) CompareAndSet(kv *model.PluginKeyValue, oldValue []byte) (bool, *model.AppError) {
+	defer s.invalidate(kv.PluginId, kv.Key)
+	return s.PluginStore.CompareAndSet(kv, oldValue)
+}
+
+func (s *LocalCachePluginStore) Delete(pluginId, key string) (bool, *model.AppError) {
+	defer s.invalidate(pluginId, key)
+	return s.PluginStore.Delete(pluginId, key)
+}
+
+func (s *LocalCachePluginStore) CompareAndDelete(pluginId, key string, oldValue []byte) (bool, *model.AppError) {
+	defer s.invalidate(pluginId, key)
+	return s.PluginStore.CompareAndDelete(pluginId, key, oldValue)
+}
+
+func (s *LocalCachePluginStore) Copy(pluginId, key, newKey string) (bool, *model.AppError) {
+	defer s.invalidate(pluginId, newKey)
+	return s.PluginStore.Copy(pluginId, key, newKey)
+}
+
+func (s *LocalCachePluginStore) Move(pluginId, key, newKey string) (bool, *model.AppError) {
+	defer s.invalidate(pluginId, key)
+	defer s.invalidate(pluginId, newKey)
+	return s.PluginStore.Move(pluginId, key, newKey)
+}
+
+func (s *LocalCachePluginStore) MoveHashedKey(pluginId, hashedKey, key string) (bool, *model.AppError) {
+	defer s.invalidate(pluginId, hashedKey)
+	defer s.invalidate(pluginId, key)
+	return s.PluginStore.MoveHashedKey(pluginId, hashedKey, key)
+}
+
+func (s *LocalCachePluginStore) Increment(pluginId, key string, delta int64) (int64, *model.AppError) {
+	defer s.invalidate(pluginId, key)
+	return s.PluginStore.Increment(pluginId, key, delta)
+}
+
+func (s *LocalCachePluginStore) DeleteAllForPlugin(pluginId string) (int64, *model.AppError) {
+	// Plugins are rarely removed, so the whole cache is cleared rather than tracking the keys
+	// cached for each plugin.
+	defer s.clearCachesCluster()
+	return s.PluginStore.DeleteAllForPlugin(pluginId)
+}
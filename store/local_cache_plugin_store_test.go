@@ -0,0 +1,200 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/einterfaces"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/mattermost/mattermost-server/store/storetest/mocks"
+)
+
+type fakeCluster struct {
+	einterfaces.ClusterInterface
+
+	handlers map[string]einterfaces.ClusterMessageHandler
+	sent     []*model.ClusterMessage
+}
+
+func (c *fakeCluster) RegisterClusterMessageHandler(event string, crm einterfaces.ClusterMessageHandler) {
+	if c.handlers == nil {
+		c.handlers = make(map[string]einterfaces.ClusterMessageHandler)
+	}
+	c.handlers[event] = crm
+}
+
+func (c *fakeCluster) SendClusterMessage(msg *model.ClusterMessage) {
+	c.sent = append(c.sent, msg)
+}
+
+// setupLocalCachePluginStore returns a cache over a store holding the given value for the key "key"
+// of the plugin "pluginid", which is read from the master as many times as the store is asked for it.
+func setupLocalCachePluginStore(t *testing.T, kv *model.PluginKeyValue) (*store.LocalCachePluginStore, *mocks.PluginStore, *fakeCluster) {
+	pluginStore := &mocks.PluginStore{}
+	pluginStore.On("GetFromMaster", "pluginid", "key").Return(func(pluginId, key string) *model.PluginKeyValue {
+		if kv == nil {
+			return nil
+		}
+		copied := *kv
+		return &copied
+	}, func(pluginId, key string) *model.AppError {
+		if kv == nil {
+			return model.NewAppError("SqlPluginStore.Get", "store.sql_plugin_store.get.app_error", nil, "", http.StatusNotFound)
+		}
+		return nil
+	})
+
+	cluster := &fakeCluster{}
+	return store.NewLocalCachePluginStore(pluginStore, 100, 60, nil, cluster), pluginStore, cluster
+}
+
+func getValue(t *testing.T, s store.PluginStore) []byte {
+	kv, err := s.Get("pluginid", "key")
+	require.Nil(t, err)
+	return kv.Value
+}
+
+func TestLocalCachePluginStoreGet(t *testing.T) {
+	t.Run("cached", func(t *testing.T) {
+		s, pluginStore, _ := setupLocalCachePluginStore(t, &model.PluginKeyValue{PluginId: "pluginid", Key: "key", Value: []byte("value")})
+
+		assert.Equal(t, []byte("value"), getValue(t, s))
+		assert.Equal(t, []byte("value"), getValue(t, s))
+		pluginStore.AssertNumberOfCalls(t, "GetFromMaster", 1)
+	})
+
+	t.Run("cached values are copied", func(t *testing.T) {
+		s, _, _ := setupLocalCachePluginStore(t, &model.PluginKeyValue{PluginId: "pluginid", Key: "key", Value: []byte("value")})
+
+		getValue(t, s)[0] = 'x'
+		assert.Equal(t, []byte("value"), getValue(t, s))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		s, pluginStore, _ := setupLocalCachePluginStore(t, nil)
+
+		for i := 0; i < 2; i++ {
+			_, err := s.Get("pluginid", "key")
+			require.NotNil(t, err)
+			assert.Equal(t, http.StatusNotFound, err.StatusCode)
+		}
+		pluginStore.AssertNumberOfCalls(t, "GetFromMaster", 2)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		s, _, _ := setupLocalCachePluginStore(t, &model.PluginKeyValue{PluginId: "pluginid", Key: "key", Value: []byte("value"), ExpireAt: model.GetMillis() + 50})
+
+		assert.Equal(t, []byte("value"), getValue(t, s))
+
+		time.Sleep(100 * time.Millisecond)
+		_, err := s.Get("pluginid", "key")
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusNotFound, err.StatusCode)
+	})
+}
+
+func TestLocalCachePluginStoreInvalidation(t *testing.T) {
+	kv := &model.PluginKeyValue{PluginId: "pluginid", Key: "key", Value: []byte("value")}
+
+	for name, write := range map[string]func(s store.PluginStore, pluginStore *mocks.PluginStore) *model.AppError{
+		"SaveOrUpdate": func(s store.PluginStore, pluginStore *mocks.PluginStore) *model.AppError {
+			updated := &model.PluginKeyValue{PluginId: "pluginid", Key: "key", Value: []byte("new value")}
+			pluginStore.On("SaveOrUpdate", updated).Return(func(*model.PluginKeyValue) *model.PluginKeyValue {
+				kv.Value = updated.Value
+				return updated
+			}, nil)
+			_, err := s.SaveOrUpdate(updated)
+			return err
+		},
+		"CompareAndSet": func(s store.PluginStore, pluginStore *mocks.PluginStore) *model.AppError {
+			updated := &model.PluginKeyValue{PluginId: "pluginid", Key: "key", Value: []byte("new value")}
+			pluginStore.On("CompareAndSet", updated, []byte("value")).Return(func(*model.PluginKeyValue, []byte) bool {
+				kv.Value = updated.Value
+				return true
+			}, nil)
+			_, err := s.CompareAndSet(updated, []byte("value"))
+			return err
+		},
+		"Delete": func(s store.PluginStore, pluginStore *mocks.PluginStore) *model.AppError {
+			pluginStore.On("Delete", "pluginid", "key").Return(func(string, string) bool {
+				kv.Value = nil
+				return true
+			}, nil)
+			_, err := s.Delete("pluginid", "key")
+			return err
+		},
+		"CompareAndDelete": func(s store.PluginStore, pluginStore *mocks.PluginStore) *model.AppError {
+			pluginStore.On("CompareAndDelete", "pluginid", "key", []byte("value")).Return(func(string, string, []byte) bool {
+				kv.Value = nil
+				return true
+			}, nil)
+			_, err := s.CompareAndDelete("pluginid", "key", []byte("value"))
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			kv.Value = []byte("value")
+			s, pluginStore, cluster := setupLocalCachePluginStore(t, kv)
+
+			assert.Equal(t, []byte("value"), getValue(t, s))
+
+			require.Nil(t, write(s, pluginStore))
+
+			assert.Equal(t, kv.Value, getValue(t, s))
+			pluginStore.AssertNumberOfCalls(t, "GetFromMaster", 2)
+
+			require.Len(t, cluster.sent, 1)
+			assert.Equal(t, model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_PLUGIN_KEY_VALUES, cluster.sent[0].Event)
+			assert.Equal(t, "pluginid/key", cluster.sent[0].Data)
+		})
+	}
+
+	t.Run("DeleteAllForPlugin", func(t *testing.T) {
+		kv.Value = []byte("value")
+		s, pluginStore, cluster := setupLocalCachePluginStore(t, kv)
+		pluginStore.On("DeleteAllForPlugin", "pluginid").Return(int64(1), nil)
+
+		getValue(t, s)
+		_, err := s.DeleteAllForPlugin("pluginid")
+		require.Nil(t, err)
+		getValue(t, s)
+		pluginStore.AssertNumberOfCalls(t, "GetFromMaster", 2)
+
+		require.Len(t, cluster.sent, 1)
+		assert.Equal(t, store.CLEAR_CACHE_MESSAGE_DATA, cluster.sent[0].Data)
+	})
+
+	t.Run("from another server", func(t *testing.T) {
+		kv.Value = []byte("value")
+		s, pluginStore, cluster := setupLocalCachePluginStore(t, kv)
+
+		getValue(t, s)
+		kv.Value = []byte("new value")
+		cluster.handlers[model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_PLUGIN_KEY_VALUES](&model.ClusterMessage{
+			Event: model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_PLUGIN_KEY_VALUES,
+			Data:  "pluginid/key",
+		})
+		assert.Equal(t, []byte("new value"), getValue(t, s))
+		pluginStore.AssertNumberOfCalls(t, "GetFromMaster", 2)
+		assert.Empty(t, cluster.sent)
+	})
+
+	t.Run("ClearCaches", func(t *testing.T) {
+		kv.Value = []byte("value")
+		s, pluginStore, cluster := setupLocalCachePluginStore(t, kv)
+
+		getValue(t, s)
+		s.ClearCaches()
+		getValue(t, s)
+		pluginStore.AssertNumberOfCalls(t, "GetFromMaster", 2)
+		assert.Empty(t, cluster.sent)
+	})
+}
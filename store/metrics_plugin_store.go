@@ -0,0 +1,52 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/einterfaces"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// MetricsPluginStore records the number and duration of the plugin key-value reads and writes that
+// reach the database, by method and plugin, so that a plugin loading the database can be identified.
+type MetricsPluginStore struct {
+	PluginStore
+	metrics einterfaces.MetricsInterface
+}
+
+func NewMetricsPluginStore(pluginStore PluginStore, metrics einterfaces.MetricsInterface) *MetricsPluginStore {
+	return &MetricsPluginStore{
+		PluginStore: pluginStore,
+		metrics:     metrics,
+	}
+}
+
+// observe records a request made at start, once it has completed.
+func (s *MetricsPluginStore) observe(method, pluginId string, start time.Time) {
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	s.metrics.IncrementPluginStoreRequest(method, pluginId)
+	s.metrics.ObservePluginStoreRequestDuration(method, pluginId, elapsed)
+}
+
+func (s *MetricsPluginStore) SaveOrUpdate(kv *model.PluginKeyValue) (*model.PluginKeyValue, *model.AppError) {
+	defer s.observe("SaveOrUpdate", kv.PluginId, time.Now())
+	return s.PluginStore.SaveOrUpdate(kv)
+}
+
+func (s *MetricsPluginStore) Get(pluginId, key string) (*model.PluginKeyValue, *model.AppError) {
+	defer s.observe("Get", pluginId, time.Now())
+	return s.PluginStore.Get(pluginId, key)
+}
+
+func (s *MetricsPluginStore) GetFromMaster(pluginId, key string) (*model.PluginKeyValue, *model.AppError) {
+	defer s.observe("GetFromMaster", pluginId, time.Now())
+	return s.PluginStore.GetFromMaster(pluginId, key)
+}
+
+func (s *MetricsPluginStore) Delete(pluginId, key string) (bool, *model.AppError) {
+	defer s.observe("Delete", pluginId, time.Now())
+	return s.PluginStore.Delete(pluginId, key)
+}
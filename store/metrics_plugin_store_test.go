@@ -0,0 +1,75 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/einterfaces"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/mattermost/mattermost-server/store/storetest/mocks"
+)
+
+type fakeMetrics struct {
+	einterfaces.MetricsInterface
+
+	mutex     sync.Mutex
+	requests  map[string]int
+	durations map[string][]float64
+}
+
+func (m *fakeMetrics) IncrementPluginStoreRequest(method string, pluginId string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.requests[method+" "+pluginId]++
+}
+
+func (m *fakeMetrics) ObservePluginStoreRequestDuration(method string, pluginId string, elapsed float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.durations[method+" "+pluginId] = append(m.durations[method+" "+pluginId], elapsed)
+}
+
+func TestMetricsPluginStore(t *testing.T) {
+	kv := &model.PluginKeyValue{PluginId: "pluginid", Key: "key", Value: []byte("value")}
+	pluginStore := &mocks.PluginStore{}
+	pluginStore.On("SaveOrUpdate", kv).Return(kv, nil)
+	pluginStore.On("Get", "pluginid", "key").Return(kv, nil)
+	pluginStore.On("Delete", "otherpluginid", "key").Return(false, model.NewAppError("SqlPluginStore.Delete", "store.sql_plugin_store.delete.app_error", nil, "", http.StatusInternalServerError))
+	pluginStore.On("List", "pluginid", 0, 10).Return([]string{"key"}, nil)
+
+	metrics := &fakeMetrics{requests: make(map[string]int), durations: make(map[string][]float64)}
+	s := store.NewMetricsPluginStore(pluginStore, metrics)
+
+	saved, err := s.SaveOrUpdate(kv)
+	require.Nil(t, err)
+	assert.Equal(t, kv, saved)
+
+	for i := 0; i < 2; i++ {
+		got, err := s.Get("pluginid", "key")
+		require.Nil(t, err)
+		assert.Equal(t, kv, got)
+	}
+
+	_, err = s.Delete("otherpluginid", "key")
+	assert.NotNil(t, err)
+
+	_, err = s.List("pluginid", 0, 10)
+	require.Nil(t, err)
+
+	assert.Equal(t, map[string]int{
+		"SaveOrUpdate pluginid": 1,
+		"Get pluginid":          2,
+		"Delete otherpluginid":  1,
+	}, metrics.requests)
+	for request, count := range metrics.requests {
+		assert.Len(t, metrics.durations[request], count, request)
+	}
+}
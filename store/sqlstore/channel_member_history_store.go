@@ -91,6 +91,25 @@ func (s SqlChannelMemberHistoryStore) GetUsersInChannelDuring(startTime int64, e
 	})
 }
 
+// GetUsersLeftSince returns the ids of the users that left the channel after the given time. A user
+// that left and later rejoined the channel is included.
+func (s SqlChannelMemberHistoryStore) GetUsersLeftSince(channelId string, since int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var userIds []string
+		query := `
+			SELECT DISTINCT UserId
+			FROM ChannelMemberHistory
+			WHERE ChannelId = :ChannelId
+			AND LeaveTime > :Since`
+
+		if _, err := s.GetReplica().Select(&userIds, query, map[string]interface{}{"ChannelId": channelId, "Since": since}); err != nil {
+			result.Err = model.NewAppError("SqlChannelMemberHistoryStore.GetUsersLeftSince", "store.sql_channel_member_history.get_users_left_since.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = userIds
+		}
+	})
+}
+
 func (s SqlChannelMemberHistoryStore) hasDataAtOrBefore(time int64) (bool, error) {
 	type NullableCountResult struct {
 		Min sql.NullInt64
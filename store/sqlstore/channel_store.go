@@ -297,6 +297,7 @@ func (s SqlChannelStore) CreateIndexesIfNotExists() {
 
 	s.CreateIndexIfNotExists("idx_channelmembers_channel_id", "ChannelMembers", "ChannelId")
 	s.CreateIndexIfNotExists("idx_channelmembers_user_id", "ChannelMembers", "UserId")
+	s.CreateCompositeIndexIfNotExists("idx_channelmembers_channel_id_last_update_at", "ChannelMembers", []string{"ChannelId", "LastUpdateAt"})
 
 	s.CreateFullTextIndexIfNotExists("idx_channel_search_txt", "Channels", "Name, DisplayName, Purpose")
 }
@@ -1074,6 +1075,21 @@ func (s SqlChannelStore) GetMembers(channelId string, offset, limit int) store.S
 	})
 }
 
+// GetMembersModifiedSince returns the members of the channel whose membership was created or updated
+// after the given time.
+func (s SqlChannelStore) GetMembersModifiedSince(channelId string, since int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var dbMembers channelMemberWithSchemeRolesList
+		_, err := s.GetReplica().Select(&dbMembers, CHANNEL_MEMBERS_WITH_SCHEME_SELECT_QUERY+"WHERE ChannelId = :ChannelId AND LastUpdateAt > :Since ORDER BY LastUpdateAt", map[string]interface{}{"ChannelId": channelId, "Since": since})
+		if err != nil {
+			result.Err = model.NewAppError("SqlChannelStore.GetMembersModifiedSince", "store.sql_channel.get_members.app_error", nil, "channel_id="+channelId+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = dbMembers.ToModel()
+	})
+}
+
 func (s SqlChannelStore) GetMember(channelId string, userId string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		var dbMember channelMemberWithSchemeRoles
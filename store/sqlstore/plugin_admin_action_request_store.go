@@ -0,0 +1,144 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+type SqlPluginAdminActionRequestStore struct {
+	SqlStore
+}
+
+func NewSqlPluginAdminActionRequestStore(sqlStore SqlStore) store.PluginAdminActionRequestStore {
+	s := &SqlPluginAdminActionRequestStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PluginAdminActionRequest{}, "PluginAdminActionRequests").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("PluginId").SetMaxSize(190)
+		table.ColMap("Action").SetMaxSize(64)
+		table.ColMap("Params").SetMaxSize(model.PLUGIN_ADMIN_ACTION_PARAMS_MAX_LENGTH)
+		table.ColMap("Description").SetMaxSize(model.PLUGIN_ADMIN_ACTION_DESCRIPTION_MAX_RUNES * 4)
+		table.ColMap("Status").SetMaxSize(32)
+		table.ColMap("ResolvedBy").SetMaxSize(26)
+		table.ColMap("Error").SetMaxSize(model.PLUGIN_ADMIN_ACTION_ERROR_MAX_LENGTH)
+	}
+
+	return s
+}
+
+func (s SqlPluginAdminActionRequestStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_plugin_admin_action_requests_plugin_id", "PluginAdminActionRequests", "PluginId")
+	s.CreateCompositeIndexIfNotExists("idx_plugin_admin_action_requests_status_expire_at", "PluginAdminActionRequests", []string{"Status", "ExpireAt"})
+}
+
+func (s SqlPluginAdminActionRequestStore) Save(request *model.PluginAdminActionRequest) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		request.PreSave()
+		if result.Err = request.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(request); err != nil {
+			result.Err = model.NewAppError("SqlPluginAdminActionRequestStore.Save", "store.sql_plugin_admin_action_request.save.app_error", nil, "plugin_id="+request.PluginId+", err="+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = request
+	})
+}
+
+func (s SqlPluginAdminActionRequestStore) Get(id string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var request model.PluginAdminActionRequest
+
+		if err := s.GetMaster().SelectOne(&request, "SELECT * FROM PluginAdminActionRequests WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlPluginAdminActionRequestStore.Get", "store.sql_plugin_admin_action_request.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			if err == sql.ErrNoRows {
+				result.Err.StatusCode = http.StatusNotFound
+			}
+			return
+		}
+
+		result.Data = &request
+	})
+}
+
+// GetPending gets the requests still waiting for a system admin as of the given time, oldest first.
+func (s SqlPluginAdminActionRequestStore) GetPending(now int64, offset, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var requests []*model.PluginAdminActionRequest
+
+		if _, err := s.GetReplica().Select(&requests, "SELECT * FROM PluginAdminActionRequests WHERE Status = :Status AND ExpireAt > :Now ORDER BY CreateAt, Id LIMIT :Limit OFFSET :Offset", map[string]interface{}{"Status": model.PLUGIN_ADMIN_ACTION_STATUS_PENDING, "Now": now, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlPluginAdminActionRequestStore.GetPending", "store.sql_plugin_admin_action_request.get_pending.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = requests
+	})
+}
+
+// GetExpired gets up to limit requests still marked as pending that expired before the given time.
+func (s SqlPluginAdminActionRequestStore) GetExpired(now int64, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var requests []*model.PluginAdminActionRequest
+
+		if _, err := s.GetMaster().Select(&requests, "SELECT * FROM PluginAdminActionRequests WHERE Status = :Status AND ExpireAt <= :Now ORDER BY ExpireAt, Id LIMIT :Limit", map[string]interface{}{"Status": model.PLUGIN_ADMIN_ACTION_STATUS_PENDING, "Now": now, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlPluginAdminActionRequestStore.GetExpired", "store.sql_plugin_admin_action_request.get_expired.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = requests
+	})
+}
+
+// UpdateStatus records the request's new status, and who resolved it, only if its status is still
+// oldStatus. The result's data is whether the request was updated, so that a request resolved
+// concurrently is only ever resolved once.
+func (s SqlPluginAdminActionRequestStore) UpdateStatus(request *model.PluginAdminActionRequest, oldStatus string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if result.Err = request.IsValid(); result.Err != nil {
+			return
+		}
+
+		sqlResult, err := s.GetMaster().Exec(
+			`UPDATE PluginAdminActionRequests
+			SET Status = :Status, ResolvedBy = :ResolvedBy, ResolveAt = :ResolveAt, Error = :Error
+			WHERE Id = :Id AND Status = :OldStatus`,
+			map[string]interface{}{
+				"Id":         request.Id,
+				"Status":     request.Status,
+				"ResolvedBy": request.ResolvedBy,
+				"ResolveAt":  request.ResolveAt,
+				"Error":      request.Error,
+				"OldStatus":  oldStatus,
+			},
+		)
+		if err != nil {
+			result.Err = model.NewAppError("SqlPluginAdminActionRequestStore.UpdateStatus", "store.sql_plugin_admin_action_request.update_status.app_error", nil, "id="+request.Id+", err="+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		rowsAffected, err := sqlResult.RowsAffected()
+		if err != nil {
+			result.Err = model.NewAppError("SqlPluginAdminActionRequestStore.UpdateStatus", "store.sql_plugin_admin_action_request.update_status.app_error", nil, "id="+request.Id+", err="+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = rowsAffected == 1
+	})
+}
+
+func (s SqlPluginAdminActionRequestStore) DeleteAllForPlugin(pluginId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec("DELETE FROM PluginAdminActionRequests WHERE PluginId = :PluginId", map[string]interface{}{"PluginId": pluginId}); err != nil {
+			result.Err = model.NewAppError("SqlPluginAdminActionRequestStore.DeleteAllForPlugin", "store.sql_plugin_admin_action_request.delete_all_for_plugin.app_error", nil, "plugin_id="+pluginId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
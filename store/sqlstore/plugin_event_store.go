@@ -0,0 +1,92 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+type SqlPluginEventStore struct {
+	SqlStore
+}
+
+func NewSqlPluginEventStore(sqlStore SqlStore) store.PluginEventStore {
+	s := &SqlPluginEventStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PluginEvent{}, "PluginEvents").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("PluginId").SetMaxSize(190)
+		table.ColMap("Type").SetMaxSize(32)
+		table.ColMap("ClusterId").SetMaxSize(model.PLUGIN_RUNTIME_STATE_CLUSTER_ID_MAX_LENGTH)
+		table.ColMap("Details").SetMaxSize(model.PLUGIN_EVENT_DETAILS_MAX_RUNES)
+	}
+
+	return s
+}
+
+func (s SqlPluginEventStore) CreateIndexesIfNotExists() {
+	s.CreateCompositeIndexIfNotExists("idx_plugin_events_plugin_id_create_at", "PluginEvents", []string{"PluginId", "CreateAt"})
+	s.CreateIndexIfNotExists("idx_plugin_events_create_at", "PluginEvents", "CreateAt")
+}
+
+func (s SqlPluginEventStore) Save(event *model.PluginEvent) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		event.PreSave()
+		if result.Err = event.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(event); err != nil {
+			result.Err = model.NewAppError("SqlPluginEventStore.Save", "store.sql_plugin_event.save.app_error", nil, "plugin_id="+event.PluginId+", err="+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = event
+	})
+}
+
+// GetForPlugin gets the plugin's events recorded at or after the given time, oldest first.
+func (s SqlPluginEventStore) GetForPlugin(pluginId string, since int64, offset, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var events []*model.PluginEvent
+
+		if _, err := s.GetReplica().Select(&events, "SELECT * FROM PluginEvents WHERE PluginId = :PluginId AND CreateAt >= :Since ORDER BY CreateAt, Id LIMIT :Limit OFFSET :Offset", map[string]interface{}{"PluginId": pluginId, "Since": since, "Limit": limit, "Offset": offset}); err != nil {
+			result.Err = model.NewAppError("SqlPluginEventStore.GetForPlugin", "store.sql_plugin_event.get_for_plugin.app_error", nil, "plugin_id="+pluginId+", err="+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = events
+	})
+}
+
+// DeleteBefore deletes up to limit events recorded before the given time. The result's data is the
+// number of events deleted.
+func (s SqlPluginEventStore) DeleteBefore(before int64, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var query string
+		if s.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+			query = "DELETE FROM PluginEvents WHERE Id = any (array (SELECT Id FROM PluginEvents WHERE CreateAt < :Before LIMIT :Limit))"
+		} else {
+			query = "DELETE FROM PluginEvents WHERE CreateAt < :Before LIMIT :Limit"
+		}
+
+		sqlResult, err := s.GetMaster().Exec(query, map[string]interface{}{"Before": before, "Limit": limit})
+		if err != nil {
+			result.Err = model.NewAppError("SqlPluginEventStore.DeleteBefore", "store.sql_plugin_event.delete_before.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		rowsAffected, err := sqlResult.RowsAffected()
+		if err != nil {
+			result.Err = model.NewAppError("SqlPluginEventStore.DeleteBefore", "store.sql_plugin_event.delete_before.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = rowsAffected
+	})
+}
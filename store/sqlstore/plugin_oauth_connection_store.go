@@ -0,0 +1,105 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+var pluginOAuthConnectionsTable = upsertTable{
+	Name:                  "PluginOAuthConnections",
+	KeyColumns:            []string{"PluginId", "UserId"},
+	Columns:               []string{"Token", "UpdateAt"},
+	UniqueConstraintNames: []string{"PRIMARY", "pluginoauthconnections_pkey"},
+}
+
+type SqlPluginOAuthConnectionStore struct {
+	SqlStore
+
+	// upsertOnConflict is set when the database is PostgreSQL 9.5 or newer, so that connections can
+	// be saved in a single INSERT ... ON CONFLICT statement.
+	upsertOnConflict bool
+}
+
+func NewSqlPluginOAuthConnectionStore(sqlStore SqlStore) store.PluginOAuthConnectionStore {
+	s := &SqlPluginOAuthConnectionStore{
+		SqlStore:         sqlStore,
+		upsertOnConflict: upsertOnConflictSupported(sqlStore, "PluginOAuthConnections"),
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PluginOAuthConnection{}, "PluginOAuthConnections").SetKeys(false, "PluginId", "UserId")
+		table.ColMap("PluginId").SetMaxSize(190)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("Token").SetMaxSize(model.PLUGIN_OAUTH_TOKEN_MAX_LENGTH)
+	}
+
+	return s
+}
+
+func (s SqlPluginOAuthConnectionStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_plugin_oauth_connections_user_id", "PluginOAuthConnections", "UserId")
+}
+
+// Save inserts or replaces the connection of the user to the plugin's OAuth provider.
+func (s SqlPluginOAuthConnectionStore) Save(connection *model.PluginOAuthConnection) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		connection.PreSave()
+		if result.Err = connection.IsValid(); result.Err != nil {
+			return
+		}
+
+		params := map[string]interface{}{"PluginId": connection.PluginId, "UserId": connection.UserId, "Token": connection.Token, "UpdateAt": connection.UpdateAt}
+		if err := upsertRow(s.GetMaster(), s.DriverName(), s.upsertOnConflict, pluginOAuthConnectionsTable, connection, params); err != nil {
+			result.Err = model.NewAppError("SqlPluginOAuthConnectionStore.Save", "store.sql_plugin_oauth_connection.save.app_error", nil, "plugin_id="+connection.PluginId+", err="+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = connection
+	})
+}
+
+func (s SqlPluginOAuthConnectionStore) Get(pluginId, userId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var connection model.PluginOAuthConnection
+
+		if err := s.GetMaster().SelectOne(&connection, "SELECT * FROM PluginOAuthConnections WHERE PluginId = :PluginId AND UserId = :UserId", map[string]interface{}{"PluginId": pluginId, "UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPluginOAuthConnectionStore.Get", "store.sql_plugin_oauth_connection.get.app_error", nil, "plugin_id="+pluginId+", err="+err.Error(), http.StatusInternalServerError)
+			if err == sql.ErrNoRows {
+				result.Err.StatusCode = http.StatusNotFound
+			}
+			return
+		}
+
+		result.Data = &connection
+	})
+}
+
+func (s SqlPluginOAuthConnectionStore) Delete(pluginId, userId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec("DELETE FROM PluginOAuthConnections WHERE PluginId = :PluginId AND UserId = :UserId", map[string]interface{}{"PluginId": pluginId, "UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPluginOAuthConnectionStore.Delete", "store.sql_plugin_oauth_connection.delete.app_error", nil, "plugin_id="+pluginId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func (s SqlPluginOAuthConnectionStore) DeleteAllForPlugin(pluginId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec("DELETE FROM PluginOAuthConnections WHERE PluginId = :PluginId", map[string]interface{}{"PluginId": pluginId}); err != nil {
+			result.Err = model.NewAppError("SqlPluginOAuthConnectionStore.DeleteAllForPlugin", "store.sql_plugin_oauth_connection.delete_all_for_plugin.app_error", nil, "plugin_id="+pluginId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func (s SqlPluginOAuthConnectionStore) DeleteAllForUser(userId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec("DELETE FROM PluginOAuthConnections WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPluginOAuthConnectionStore.DeleteAllForUser", "store.sql_plugin_oauth_connection.delete_all_for_user.app_error", nil, "user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
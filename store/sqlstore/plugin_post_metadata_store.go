@@ -0,0 +1,105 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+var pluginPostMetadataTable = upsertTable{
+	Name:                  "PluginPostMetadata",
+	KeyColumns:            []string{"PostId", "Namespace"},
+	Columns:               []string{"PluginId", "UpdateAt"},
+	UniqueConstraintNames: []string{"PRIMARY", "pluginpostmetadata_pkey"},
+}
+
+type SqlPluginPostMetadataStore struct {
+	SqlStore
+
+	// upsertOnConflict is set when the database is PostgreSQL 9.5 or newer, so that metadata can be
+	// saved in a single INSERT ... ON CONFLICT statement.
+	upsertOnConflict bool
+}
+
+func NewSqlPluginPostMetadataStore(sqlStore SqlStore) store.PluginPostMetadataStore {
+	s := &SqlPluginPostMetadataStore{
+		SqlStore:         sqlStore,
+		upsertOnConflict: upsertOnConflictSupported(sqlStore, "PluginPostMetadata"),
+	}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PluginPostMetadata{}, "PluginPostMetadata").SetKeys(false, "PostId", "Namespace")
+		table.ColMap("PostId").SetMaxSize(26)
+		table.ColMap("Namespace").SetMaxSize(model.PLUGIN_POST_METADATA_NAMESPACE_MAX_LENGTH)
+		table.ColMap("PluginId").SetMaxSize(190)
+	}
+
+	return s
+}
+
+func (s SqlPluginPostMetadataStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_plugin_post_metadata_plugin_id", "PluginPostMetadata", "PluginId")
+}
+
+// Save records that the metadata's plugin owns the namespace on the metadata's post, replacing any
+// existing record.
+func (s SqlPluginPostMetadataStore) Save(metadata *model.PluginPostMetadata) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		metadata.PreSave()
+		if result.Err = metadata.IsValid(); result.Err != nil {
+			return
+		}
+
+		params := map[string]interface{}{"PostId": metadata.PostId, "Namespace": metadata.Namespace, "PluginId": metadata.PluginId, "UpdateAt": metadata.UpdateAt}
+		if err := upsertRow(s.GetMaster(), s.DriverName(), s.upsertOnConflict, pluginPostMetadataTable, metadata, params); err != nil {
+			result.Err = model.NewAppError("SqlPluginPostMetadataStore.Save", "store.sql_plugin_post_metadata.save.app_error", nil, "post_id="+metadata.PostId+", err="+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = metadata
+	})
+}
+
+func (s SqlPluginPostMetadataStore) Get(postId, namespace string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var metadata model.PluginPostMetadata
+
+		if err := s.GetMaster().SelectOne(&metadata, "SELECT * FROM PluginPostMetadata WHERE PostId = :PostId AND Namespace = :Namespace", map[string]interface{}{"PostId": postId, "Namespace": namespace}); err != nil {
+			result.Err = model.NewAppError("SqlPluginPostMetadataStore.Get", "store.sql_plugin_post_metadata.get.app_error", nil, "post_id="+postId+", err="+err.Error(), http.StatusInternalServerError)
+			if err == sql.ErrNoRows {
+				result.Err.StatusCode = http.StatusNotFound
+			}
+			return
+		}
+
+		result.Data = &metadata
+	})
+}
+
+// GetForPlugin returns up to limit of the records of metadata owned by the plugin, ordered by post
+// and namespace.
+func (s SqlPluginPostMetadataStore) GetForPlugin(pluginId string, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var metadata []*model.PluginPostMetadata
+
+		if _, err := s.GetMaster().Select(&metadata, "SELECT * FROM PluginPostMetadata WHERE PluginId = :PluginId ORDER BY PostId, Namespace LIMIT :Limit", map[string]interface{}{"PluginId": pluginId, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlPluginPostMetadataStore.GetForPlugin", "store.sql_plugin_post_metadata.get_for_plugin.app_error", nil, "plugin_id="+pluginId+", err="+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = metadata
+	})
+}
+
+func (s SqlPluginPostMetadataStore) Delete(postId, namespace string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec("DELETE FROM PluginPostMetadata WHERE PostId = :PostId AND Namespace = :Namespace", map[string]interface{}{"PostId": postId, "Namespace": namespace}); err != nil {
+			result.Err = model.NewAppError("SqlPluginPostMetadataStore.Delete", "store.sql_plugin_post_metadata.delete.app_error", nil, "post_id="+postId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
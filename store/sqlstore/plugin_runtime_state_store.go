@@ -0,0 +1,122 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+var pluginRuntimeStateUniqueConstraintNames = []string{"PRIMARY", "pluginruntimestates_pkey"}
+
+type SqlPluginRuntimeStateStore struct {
+	SqlStore
+}
+
+func NewSqlPluginRuntimeStateStore(sqlStore SqlStore) store.PluginRuntimeStateStore {
+	s := &SqlPluginRuntimeStateStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PluginRuntimeState{}, "PluginRuntimeStates").SetKeys(false, "PluginId", "ClusterId")
+		table.ColMap("PluginId").SetMaxSize(190)
+		table.ColMap("ClusterId").SetMaxSize(model.PLUGIN_RUNTIME_STATE_CLUSTER_ID_MAX_LENGTH)
+		table.ColMap("Error").SetMaxSize(model.PLUGIN_RUNTIME_STATE_ERROR_MAX_RUNES)
+	}
+
+	return s
+}
+
+// Save inserts or replaces the state of the plugin on the server identified by the state's cluster id.
+func (s SqlPluginRuntimeStateStore) Save(state *model.PluginRuntimeState) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		state.PreSave()
+		if result.Err = state.IsValid(); result.Err != nil {
+			return
+		}
+
+		// Not every supported database has an atomic upsert, so we use separate update and insert
+		// queries, treating a conflicting insert as a concurrent save that won the race.
+		if rowsAffected, err := s.GetMaster().Update(state); err != nil {
+			result.Err = model.NewAppError("SqlPluginRuntimeStateStore.Save", "store.sql_plugin_runtime_state.save.app_error", nil, "plugin_id="+state.PluginId+", err="+err.Error(), http.StatusInternalServerError)
+			return
+		} else if rowsAffected == 0 {
+			if err := s.GetMaster().Insert(state); err != nil && !IsUniqueConstraintError(err, pluginRuntimeStateUniqueConstraintNames) {
+				result.Err = model.NewAppError("SqlPluginRuntimeStateStore.Save", "store.sql_plugin_runtime_state.save.app_error", nil, "plugin_id="+state.PluginId+", err="+err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		result.Data = state
+	})
+}
+
+func (s SqlPluginRuntimeStateStore) Get(pluginId, clusterId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var state model.PluginRuntimeState
+
+		if err := s.GetMaster().SelectOne(&state, "SELECT * FROM PluginRuntimeStates WHERE PluginId = :PluginId AND ClusterId = :ClusterId", map[string]interface{}{"PluginId": pluginId, "ClusterId": clusterId}); err != nil {
+			result.Err = model.NewAppError("SqlPluginRuntimeStateStore.Get", "store.sql_plugin_runtime_state.get.app_error", nil, "plugin_id="+pluginId+", err="+err.Error(), http.StatusInternalServerError)
+			if err == sql.ErrNoRows {
+				result.Err.StatusCode = http.StatusNotFound
+			}
+			return
+		}
+
+		result.Data = &state
+	})
+}
+
+// GetAll returns the states of every plugin on every server, ordered by plugin and server.
+func (s SqlPluginRuntimeStateStore) GetAll() store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var states []*model.PluginRuntimeState
+
+		if _, err := s.GetReplica().Select(&states, "SELECT * FROM PluginRuntimeStates ORDER BY PluginId, ClusterId"); err != nil {
+			result.Err = model.NewAppError("SqlPluginRuntimeStateStore.GetAll", "store.sql_plugin_runtime_state.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = states
+	})
+}
+
+// Touch sets the time at which the states of every plugin on the given server were last updated,
+// keeping them from expiring.
+func (s SqlPluginRuntimeStateStore) Touch(clusterId string, updateAt int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec("UPDATE PluginRuntimeStates SET UpdateAt = :UpdateAt WHERE ClusterId = :ClusterId", map[string]interface{}{"UpdateAt": updateAt, "ClusterId": clusterId}); err != nil {
+			result.Err = model.NewAppError("SqlPluginRuntimeStateStore.Touch", "store.sql_plugin_runtime_state.touch.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func (s SqlPluginRuntimeStateStore) DeleteAllForPlugin(pluginId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec("DELETE FROM PluginRuntimeStates WHERE PluginId = :PluginId", map[string]interface{}{"PluginId": pluginId}); err != nil {
+			result.Err = model.NewAppError("SqlPluginRuntimeStateStore.DeleteAllForPlugin", "store.sql_plugin_runtime_state.delete_all_for_plugin.app_error", nil, "plugin_id="+pluginId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// DeleteStale deletes the states last updated before the given time, returning the number deleted.
+func (s SqlPluginRuntimeStateStore) DeleteStale(updateBefore int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		sqlResult, err := s.GetMaster().Exec("DELETE FROM PluginRuntimeStates WHERE UpdateAt < :UpdateBefore", map[string]interface{}{"UpdateBefore": updateBefore})
+		if err != nil {
+			result.Err = model.NewAppError("SqlPluginRuntimeStateStore.DeleteStale", "store.sql_plugin_runtime_state.delete_stale.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		rowsAffected, err := sqlResult.RowsAffected()
+		if err != nil {
+			result.Err = model.NewAppError("SqlPluginRuntimeStateStore.DeleteStale", "store.sql_plugin_runtime_state.delete_stale.app_error", nil, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = rowsAffected
+	})
+}
@@ -4,91 +4,855 @@
 package sqlstore
 
 import (
+	"bytes"
 	"database/sql"
 	"fmt"
+	"math"
+	"math/rand"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/mattermost/gorp"
+	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/store"
 )
 
+// PLUGIN_STORE_MAX_ATTEMPTS bounds the attempts to write key-value pairs that are concurrently being
+// written or deleted by others.
+const PLUGIN_STORE_MAX_ATTEMPTS = 3
+
+// PLUGIN_STORE_DELETE_ALL_BATCH_SIZE is the most key-value pairs deleted by each statement when
+// deleting all those of a plugin.
+const PLUGIN_STORE_DELETE_ALL_BATCH_SIZE = 1000
+
+// PLUGIN_STORE_LOCK_MAX_ATTEMPTS bounds the attempts to save a key-value pair that the database aborts
+// because of a deadlock or a lock wait timeout, and PLUGIN_STORE_LOCK_RETRY_DELAY is the delay
+// before the first retry, doubling for each one after it.
+const (
+	PLUGIN_STORE_LOCK_MAX_ATTEMPTS = 5
+	PLUGIN_STORE_LOCK_RETRY_DELAY  = 10 * time.Millisecond
+)
+
+// pluginKeyValueUniqueConstraintNames identifies violations of the primary key of PluginKeyValueStore.
+// MySQL names the key in its error messages, while PostgreSQL names the constraint.
+var pluginKeyValueUniqueConstraintNames = []string{"PRIMARY", "pluginkeyvaluestore_pkey"}
+
+// pluginKeyValueHashedKeyLength is the length of the base64-encoded sha256 hashes under which keys
+// were stored before they were stored as given.
+const pluginKeyValueHashedKeyLength = 44
+
+// pluginCounterPattern matches the decimal numbers that Increment accepts as counters.
+var pluginCounterPattern = regexp.MustCompile(`^-?[0-9]{1,19}$`)
+
+// POSTGRES_ON_CONFLICT_MIN_VERSION is the first version of PostgreSQL, 9.5, to support
+// INSERT ... ON CONFLICT.
+const POSTGRES_ON_CONFLICT_MIN_VERSION = 90500
+
 type SqlPluginStore struct {
 	SqlStore
+
+	// upsertOnConflict is set when the database is PostgreSQL 9.5 or newer, so that key-value pairs
+	// can be saved in a single INSERT ... ON CONFLICT statement.
+	upsertOnConflict bool
 }
 
 func NewSqlPluginStore(sqlStore SqlStore) store.PluginStore {
-	s := &SqlPluginStore{sqlStore}
+	s := &SqlPluginStore{
+		SqlStore:         sqlStore,
+		upsertOnConflict: upsertOnConflictSupported(sqlStore, "PluginKeyValueStore"),
+	}
 
 	for _, db := range sqlStore.GetAllConns() {
 		table := db.AddTableWithName(model.PluginKeyValue{}, "PluginKeyValueStore").SetKeys(false, "PluginId", "Key")
-		table.ColMap("PluginId").SetMaxSize(190)
-		table.ColMap("Key").SetMaxSize(50)
-		table.ColMap("Value").SetMaxSize(8192)
+		table.ColMap("PluginId").SetMaxSize(model.KEY_VALUE_PLUGIN_ID_MAX_RUNES)
+		table.ColMap("Key").SetMaxSize(model.KEY_VALUE_KEY_MAX_RUNES)
+		table.ColMap("Value").SetMaxSize(model.KEY_VALUE_VALUE_MAX_BYTES)
+		table.ColMap("RawKey").SetMaxSize(model.KEY_VALUE_RAW_KEY_MAX_RUNES)
 	}
 
 	return s
 }
 
+// ClearCaches does nothing, since plugin key-value pairs are only cached by the LocalCache layer.
+func (ps SqlPluginStore) ClearCaches() {
+}
+
 func (ps SqlPluginStore) CreateIndexesIfNotExists() {
 }
 
-func (ps SqlPluginStore) SaveOrUpdate(kv *model.PluginKeyValue) store.StoreChannel {
-	return store.Do(func(result *store.StoreResult) {
-		if result.Err = kv.IsValid(); result.Err != nil {
-			return
-		}
-
-		if ps.DriverName() == model.DATABASE_DRIVER_POSTGRES {
-			// Unfortunately PostgreSQL pre-9.5 does not have an atomic upsert, so we use
-			// separate update and insert queries to accomplish our upsert
-			if rowsAffected, err := ps.GetMaster().Update(kv); err != nil {
-				result.Err = model.NewAppError("SqlPluginStore.SaveOrUpdate", "store.sql_plugin_store.save.app_error", nil, err.Error(), http.StatusInternalServerError)
-				return
-			} else if rowsAffected == 0 {
-				// No rows were affected by the update, so let's try an insert
-				if err := ps.GetMaster().Insert(kv); err != nil {
-					// If the error is from unique constraints violation, it's the result of a
-					// valid race and we can report success. Otherwise we have a real error and
-					// need to return it
-					if !IsUniqueConstraintError(err, []string{"PRIMARY", "PluginId", "Key", "PKey"}) {
-						result.Err = model.NewAppError("SqlPluginStore.SaveOrUpdate", "store.sql_plugin_store.save.app_error", nil, err.Error(), http.StatusInternalServerError)
-						return
-					}
+func (ps SqlPluginStore) SaveOrUpdate(kv *model.PluginKeyValue) (*model.PluginKeyValue, *model.AppError) {
+	if err := kv.IsValid(model.KEY_VALUE_VALUE_MAX_BYTES); err != nil {
+		return nil, err
+	}
+
+	if err := upsertPluginKeyValue(ps.GetMaster(), ps.DriverName(), ps.upsertOnConflict, kv); err != nil {
+		return nil, model.NewAppError("SqlPluginStore.SaveOrUpdate", "store.sql_plugin_store.save.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return kv, nil
+}
+
+// pluginKeyValueExecutor is the part of gorp.SqlExecutor used to upsert a key-value pair.
+type pluginKeyValueExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Update(list ...interface{}) (int64, error)
+	Insert(list ...interface{}) error
+}
+
+// upsertPluginKeyValue saves kv, retrying up to PLUGIN_STORE_LOCK_MAX_ATTEMPTS times with jittered
+// backoff while the database aborts the upsert because of a deadlock or a lock wait timeout.
+func upsertPluginKeyValue(executor pluginKeyValueExecutor, driverName string, upsertOnConflict bool, kv *model.PluginKeyValue) error {
+	for attempt := 1; ; attempt++ {
+		err := upsertPluginKeyValueOnce(executor, driverName, upsertOnConflict, kv)
+		if err == nil || !isLockError(err) || attempt >= PLUGIN_STORE_LOCK_MAX_ATTEMPTS {
+			return err
+		}
+
+		mlog.Debug("Retrying plugin key value upsert after a lock error", mlog.String("plugin_id", kv.PluginId), mlog.String("key", kv.Key), mlog.Int("attempt", attempt), mlog.Err(err))
+		time.Sleep(pluginKeyValueRetryBackoff(attempt))
+	}
+}
+
+func upsertPluginKeyValueOnce(executor pluginKeyValueExecutor, driverName string, upsertOnConflict bool, kv *model.PluginKeyValue) error {
+	if driverName == model.DATABASE_DRIVER_POSTGRES && upsertOnConflict {
+		_, err := executor.Exec("INSERT INTO PluginKeyValueStore (PluginId, PKey, PValue, RawKey, ExpireAt) VALUES(:PluginId, :Key, :Value, :RawKey, :ExpireAt) ON CONFLICT (PluginId, PKey) DO UPDATE SET PValue = EXCLUDED.PValue, RawKey = EXCLUDED.RawKey, ExpireAt = EXCLUDED.ExpireAt", map[string]interface{}{"PluginId": kv.PluginId, "Key": kv.Key, "Value": kv.Value, "RawKey": kv.RawKey, "ExpireAt": kv.ExpireAt})
+		return err
+	} else if driverName == model.DATABASE_DRIVER_POSTGRES {
+		// Unfortunately PostgreSQL pre-9.5 does not have an atomic upsert, so we use
+		// separate update and insert queries to accomplish our upsert
+		if rowsAffected, err := executor.Update(kv); err != nil {
+			return err
+		} else if rowsAffected == 0 {
+			// No rows were affected by the update, so let's try an insert
+			if err := executor.Insert(kv); err != nil {
+				// If the error is from unique constraints violation, it's the result of a
+				// valid race and we can report success. Otherwise we have a real error and
+				// need to return it
+				if !IsUniqueConstraintError(err, pluginKeyValueUniqueConstraintNames) {
+					return err
 				}
 			}
-		} else if ps.DriverName() == model.DATABASE_DRIVER_MYSQL {
-			if _, err := ps.GetMaster().Exec("INSERT INTO PluginKeyValueStore (PluginId, PKey, PValue) VALUES(:PluginId, :Key, :Value) ON DUPLICATE KEY UPDATE PValue = :Value", map[string]interface{}{"PluginId": kv.PluginId, "Key": kv.Key, "Value": kv.Value}); err != nil {
-				result.Err = model.NewAppError("SqlPluginStore.SaveOrUpdate", "store.sql_plugin_store.save.app_error", nil, err.Error(), http.StatusInternalServerError)
-				return
+		}
+	} else if driverName == model.DATABASE_DRIVER_MYSQL {
+		_, err := executor.Exec("INSERT INTO PluginKeyValueStore (PluginId, PKey, PValue, RawKey, ExpireAt) VALUES(:PluginId, :Key, :Value, :RawKey, :ExpireAt) ON DUPLICATE KEY UPDATE PValue = VALUES(PValue), RawKey = VALUES(RawKey), ExpireAt = VALUES(ExpireAt)", map[string]interface{}{"PluginId": kv.PluginId, "Key": kv.Key, "Value": kv.Value, "RawKey": kv.RawKey, "ExpireAt": kv.ExpireAt})
+		return err
+	}
+
+	return nil
+}
+
+// pluginKeyValueRetryBackoff returns how long to wait before retrying after the given attempt: half
+// of an exponentially growing delay, plus up to as much again at random, so that writers that
+// deadlocked each other do not retry in lockstep.
+func pluginKeyValueRetryBackoff(attempt int) time.Duration {
+	delay := PLUGIN_STORE_LOCK_RETRY_DELAY << uint(attempt-1)
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// SaveOrUpdateMultiple saves the given key-value pairs in a single transaction, so that either all of
+// them or none of them are saved.
+func (ps SqlPluginStore) SaveOrUpdateMultiple(kvs []*model.PluginKeyValue) ([]*model.PluginKeyValue, *model.AppError) {
+	for _, kv := range kvs {
+		if err := kv.IsValid(model.KEY_VALUE_VALUE_MAX_BYTES); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(kvs) == 0 {
+		return kvs, nil
+	}
+
+	params := make(map[string]interface{})
+	keyQuery := ""
+	valuesQuery := ""
+	for index, kv := range kvs {
+		suffix := strconv.Itoa(index)
+		params["PluginId"+suffix] = kv.PluginId
+		params["Key"+suffix] = kv.Key
+		params["Value"+suffix] = kv.Value
+		params["RawKey"+suffix] = kv.RawKey
+		params["ExpireAt"+suffix] = kv.ExpireAt
+
+		if len(keyQuery) > 0 {
+			keyQuery += " OR "
+			valuesQuery += ", "
+		}
+		keyQuery += "(PluginId = :PluginId" + suffix + " AND PKey = :Key" + suffix + ")"
+		valuesQuery += "(:PluginId" + suffix + ", :Key" + suffix + ", :Value" + suffix + ", :RawKey" + suffix + ", :ExpireAt" + suffix + ")"
+	}
+
+	var queries []string
+	if ps.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+		// PostgreSQL pre-9.5 has no upsert, so the existing keys are replaced instead.
+		queries = []string{
+			"DELETE FROM PluginKeyValueStore WHERE " + keyQuery,
+			"INSERT INTO PluginKeyValueStore (PluginId, PKey, PValue, RawKey, ExpireAt) VALUES " + valuesQuery,
+		}
+	} else if ps.DriverName() == model.DATABASE_DRIVER_MYSQL {
+		queries = []string{
+			"INSERT INTO PluginKeyValueStore (PluginId, PKey, PValue, RawKey, ExpireAt) VALUES " + valuesQuery + " ON DUPLICATE KEY UPDATE PValue = VALUES(PValue), RawKey = VALUES(RawKey), ExpireAt = VALUES(ExpireAt)",
+		}
+	}
+
+	for attempt := 0; attempt < PLUGIN_STORE_MAX_ATTEMPTS; attempt++ {
+		transaction, err := ps.GetMaster().Begin()
+		if err != nil {
+			return nil, model.NewAppError("SqlPluginStore.SaveOrUpdateMultiple", "store.sql_plugin_store.save.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		for _, query := range queries {
+			if _, err = transaction.Exec(query, params); err != nil {
+				break
 			}
 		}
 
-		result.Data = kv
-	})
+		if err != nil {
+			transaction.Rollback()
+
+			// A key inserted concurrently by another transaction is replaced on the next attempt.
+			if IsUniqueConstraintError(err, pluginKeyValueUniqueConstraintNames) {
+				continue
+			}
+
+			return nil, model.NewAppError("SqlPluginStore.SaveOrUpdateMultiple", "store.sql_plugin_store.save.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		if err := transaction.Commit(); err != nil {
+			return nil, model.NewAppError("SqlPluginStore.SaveOrUpdateMultiple", "store.sql_plugin_store.save.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		return kvs, nil
+	}
+
+	return nil, model.NewAppError("SqlPluginStore.SaveOrUpdateMultiple", "store.sql_plugin_store.save.app_error", nil, "too many attempts", http.StatusInternalServerError)
 }
 
-func (ps SqlPluginStore) Get(pluginId, key string) store.StoreChannel {
-	return store.Do(func(result *store.StoreResult) {
-		var kv *model.PluginKeyValue
+// SaveOrUpdateMany makes the given sets and deletes of the plugin's keys in order in a single
+// transaction, so that either all of them or none of them are made. The values of sets are stored as
+// given, under their key as raw key, and never expire.
+func (ps SqlPluginStore) SaveOrUpdateMany(pluginId string, ops []model.PluginKVOp) ([]model.PluginKVOp, *model.AppError) {
+	for _, op := range ops {
+		if err := op.IsValid(); err != nil {
+			return nil, err
+		}
 
-		if err := ps.GetReplica().SelectOne(&kv, "SELECT * FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key", map[string]interface{}{"PluginId": pluginId, "Key": key}); err != nil {
-			if err == sql.ErrNoRows {
-				result.Err = model.NewAppError("SqlPluginStore.Get", "store.sql_plugin_store.get.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusNotFound)
-			} else {
-				result.Err = model.NewAppError("SqlPluginStore.Get", "store.sql_plugin_store.get.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusInternalServerError)
+		if op.Type == model.PLUGIN_KV_OP_SET {
+			kv := &model.PluginKeyValue{PluginId: pluginId, Key: op.Key, Value: op.Value, RawKey: op.Key}
+			if err := kv.IsValid(model.KEY_VALUE_VALUE_MAX_BYTES); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var setQueries []string
+	if ps.DriverName() == model.DATABASE_DRIVER_POSTGRES && ps.upsertOnConflict {
+		setQueries = []string{
+			"INSERT INTO PluginKeyValueStore (PluginId, PKey, PValue, RawKey, ExpireAt) VALUES(:PluginId, :Key, :Value, :Key, 0) ON CONFLICT (PluginId, PKey) DO UPDATE SET PValue = EXCLUDED.PValue, RawKey = EXCLUDED.RawKey, ExpireAt = EXCLUDED.ExpireAt",
+		}
+	} else if ps.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+		// PostgreSQL pre-9.5 has no upsert, so the existing key is replaced instead.
+		setQueries = []string{
+			"DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key",
+			"INSERT INTO PluginKeyValueStore (PluginId, PKey, PValue, RawKey, ExpireAt) VALUES(:PluginId, :Key, :Value, :Key, 0)",
+		}
+	} else if ps.DriverName() == model.DATABASE_DRIVER_MYSQL {
+		setQueries = []string{
+			"INSERT INTO PluginKeyValueStore (PluginId, PKey, PValue, RawKey, ExpireAt) VALUES(:PluginId, :Key, :Value, :Key, 0) ON DUPLICATE KEY UPDATE PValue = VALUES(PValue), RawKey = VALUES(RawKey), ExpireAt = VALUES(ExpireAt)",
+		}
+	}
+	deleteQueries := []string{"DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key"}
+
+	apply := func(transaction *gorp.Transaction) (string, error) {
+		for _, op := range ops {
+			queries := deleteQueries
+			if op.Type == model.PLUGIN_KV_OP_SET {
+				queries = setQueries
+			}
+
+			for _, query := range queries {
+				if _, err := transaction.Exec(query, map[string]interface{}{"PluginId": pluginId, "Key": op.Key, "Value": op.Value}); err != nil {
+					return op.Key, err
+				}
+			}
+		}
+		return "", nil
+	}
+
+	for attempt := 0; attempt < PLUGIN_STORE_MAX_ATTEMPTS; attempt++ {
+		transaction, err := ps.GetMaster().Begin()
+		if err != nil {
+			return nil, model.NewAppError("SqlPluginStore.SaveOrUpdateMany", "store.sql_plugin_store.save.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		if key, err := apply(transaction); err != nil {
+			transaction.Rollback()
+
+			// A key inserted concurrently by another transaction is replaced on the next attempt.
+			if IsUniqueConstraintError(err, pluginKeyValueUniqueConstraintNames) {
+				continue
+			}
+
+			return nil, model.NewAppError("SqlPluginStore.SaveOrUpdateMany", "store.sql_plugin_store.save_many.app_error", map[string]interface{}{"Key": key}, "plugin_id="+pluginId+", key="+key+", "+err.Error(), http.StatusInternalServerError)
+		}
+
+		if err := transaction.Commit(); err != nil {
+			return nil, model.NewAppError("SqlPluginStore.SaveOrUpdateMany", "store.sql_plugin_store.save.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		return ops, nil
+	}
+
+	return nil, model.NewAppError("SqlPluginStore.SaveOrUpdateMany", "store.sql_plugin_store.save.app_error", nil, "too many attempts", http.StatusInternalServerError)
+}
+
+// CompareAndSet updates the value of the given key only if it currently holds oldValue, or inserts
+// it only if it does not yet exist when oldValue is nil. Expired keys are treated as not existing.
+// It returns true if the write was applied.
+func (ps SqlPluginStore) CompareAndSet(kv *model.PluginKeyValue, oldValue []byte) (bool, *model.AppError) {
+	if err := kv.IsValid(model.KEY_VALUE_VALUE_MAX_BYTES); err != nil {
+		return false, err
+	}
+
+	now := model.GetMillis()
+
+	if oldValue == nil {
+		if _, err := ps.GetMaster().Exec("DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key AND ExpireAt != 0 AND ExpireAt <= :Now", map[string]interface{}{"PluginId": kv.PluginId, "Key": kv.Key, "Now": now}); err != nil {
+			return false, model.NewAppError("SqlPluginStore.CompareAndSet", "store.sql_plugin_store.compare_and_set.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		if err := ps.GetMaster().Insert(kv); err != nil {
+			if IsUniqueConstraintError(err, pluginKeyValueUniqueConstraintNames) {
+				return false, nil
+			}
+			return false, model.NewAppError("SqlPluginStore.CompareAndSet", "store.sql_plugin_store.compare_and_set.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		return true, nil
+	}
+
+	sqlResult, err := ps.GetMaster().Exec("UPDATE PluginKeyValueStore SET PValue = :New, RawKey = :RawKey, ExpireAt = :ExpireAt WHERE PluginId = :PluginId AND PKey = :Key AND PValue = :Old AND (ExpireAt = 0 OR ExpireAt > :Now)", map[string]interface{}{"PluginId": kv.PluginId, "Key": kv.Key, "Old": oldValue, "New": kv.Value, "RawKey": kv.RawKey, "ExpireAt": kv.ExpireAt, "Now": now})
+	if err != nil {
+		return false, model.NewAppError("SqlPluginStore.CompareAndSet", "store.sql_plugin_store.compare_and_set.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	rowsAffected, err := sqlResult.RowsAffected()
+	if err != nil {
+		return false, model.NewAppError("SqlPluginStore.CompareAndSet", "store.sql_plugin_store.compare_and_set.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if rowsAffected == 0 && bytes.Equal(oldValue, kv.Value) {
+		// MySQL does not count rows whose value did not change as affected, so check whether
+		// the row holds the expected value instead.
+		count, err := ps.GetMaster().SelectInt("SELECT COUNT(*) FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key AND PValue = :Old AND ExpireAt = :ExpireAt AND (ExpireAt = 0 OR ExpireAt > :Now)", map[string]interface{}{"PluginId": kv.PluginId, "Key": kv.Key, "Old": oldValue, "ExpireAt": kv.ExpireAt, "Now": now})
+		if err != nil {
+			return false, model.NewAppError("SqlPluginStore.CompareAndSet", "store.sql_plugin_store.compare_and_set.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		rowsAffected = count
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// Get returns the key-value pair for the given key, unless it does not exist or has expired.
+func (ps SqlPluginStore) Get(pluginId, key string) (*model.PluginKeyValue, *model.AppError) {
+	return ps.get(pluginId, key, false)
+}
+
+// GetFromMaster is like Get, but reads from the master database so as to see all committed writes.
+func (ps SqlPluginStore) GetFromMaster(pluginId, key string) (*model.PluginKeyValue, *model.AppError) {
+	return ps.get(pluginId, key, true)
+}
+
+func (ps SqlPluginStore) get(pluginId, key string, master bool) (*model.PluginKeyValue, *model.AppError) {
+	var db *gorp.DbMap
+	if master {
+		db = ps.GetMaster()
+	} else {
+		db = ps.GetReplica()
+	}
+
+	var kv *model.PluginKeyValue
+
+	if err := db.SelectOne(&kv, "SELECT * FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key AND (ExpireAt = 0 OR ExpireAt > :Now)", map[string]interface{}{"PluginId": pluginId, "Key": key, "Now": model.GetMillis()}); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.NewAppError("SqlPluginStore.Get", "store.sql_plugin_store.get.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusNotFound)
+		}
+		return nil, model.NewAppError("SqlPluginStore.Get", "store.sql_plugin_store.get.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusInternalServerError)
+	}
+
+	return kv, nil
+}
+
+// GetMultiple returns the key-value pairs stored by the plugin under any of the given keys. Missing and
+// expired keys are omitted.
+func (ps SqlPluginStore) GetMultiple(pluginId string, keys []string) ([]*model.PluginKeyValue, *model.AppError) {
+	return ps.getMultiple(pluginId, keys, false)
+}
+
+// GetMultipleFromMaster is like GetMultiple, but reads from the master database so as to see all
+// committed writes.
+func (ps SqlPluginStore) GetMultipleFromMaster(pluginId string, keys []string) ([]*model.PluginKeyValue, *model.AppError) {
+	return ps.getMultiple(pluginId, keys, true)
+}
+
+func (ps SqlPluginStore) getMultiple(pluginId string, keys []string, master bool) ([]*model.PluginKeyValue, *model.AppError) {
+	kvs := []*model.PluginKeyValue{}
+	if len(keys) == 0 {
+		return kvs, nil
+	}
+
+	params := map[string]interface{}{"PluginId": pluginId, "Now": model.GetMillis()}
+	keyQuery := ""
+	for index, key := range keys {
+		if len(keyQuery) > 0 {
+			keyQuery += ", "
+		}
+
+		params["Key"+strconv.Itoa(index)] = key
+		keyQuery += ":Key" + strconv.Itoa(index)
+	}
+
+	var db *gorp.DbMap
+	if master {
+		db = ps.GetMaster()
+	} else {
+		db = ps.GetReplica()
+	}
+
+	if _, err := db.Select(&kvs, "SELECT * FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey IN ("+keyQuery+") AND (ExpireAt = 0 OR ExpireAt > :Now)", params); err != nil {
+		return nil, model.NewAppError("SqlPluginStore.GetMultiple", "store.sql_plugin_store.get.app_error", nil, fmt.Sprintf("plugin_id=%v, err=%v", pluginId, err.Error()), http.StatusInternalServerError)
+	}
+
+	return kvs, nil
+}
+
+// List returns a page of the raw keys stored by the plugin, in a stable order. Expired keys and keys
+// stored without a raw key are omitted.
+func (ps SqlPluginStore) List(pluginId string, offset, limit int) ([]string, *model.AppError) {
+	var keys []string
+	if _, err := ps.GetReplica().Select(&keys, "SELECT RawKey FROM PluginKeyValueStore WHERE PluginId = :PluginId AND RawKey != '' AND (ExpireAt = 0 OR ExpireAt > :Now) ORDER BY PKey LIMIT :Limit OFFSET :Offset", map[string]interface{}{"PluginId": pluginId, "Now": model.GetMillis(), "Limit": limit, "Offset": offset}); err != nil {
+		return nil, model.NewAppError("SqlPluginStore.List", "store.sql_plugin_store.list.app_error", nil, fmt.Sprintf("plugin_id=%v, err=%v", pluginId, err.Error()), http.StatusInternalServerError)
+	}
+
+	return keys, nil
+}
+
+// ListWithPrefix is like List, but only returns the raw keys beginning with the given prefix. The
+// prefix is matched literally, even if it contains wildcard characters.
+func (ps SqlPluginStore) ListWithPrefix(pluginId, prefix string, offset, limit int) ([]string, *model.AppError) {
+	// The escape character must itself be escaped first, so that it is matched literally too.
+	likePrefix := strings.Replace(prefix, "*", "**", -1)
+	for _, c := range escapeLikeSearchChar {
+		likePrefix = strings.Replace(likePrefix, c, "*"+c, -1)
+	}
+
+	var keys []string
+	if _, err := ps.GetReplica().Select(&keys, "SELECT RawKey FROM PluginKeyValueStore WHERE PluginId = :PluginId AND RawKey != '' AND RawKey LIKE :Prefix ESCAPE '*' AND (ExpireAt = 0 OR ExpireAt > :Now) ORDER BY PKey LIMIT :Limit OFFSET :Offset", map[string]interface{}{"PluginId": pluginId, "Prefix": likePrefix + "%", "Now": model.GetMillis(), "Limit": limit, "Offset": offset}); err != nil {
+		return nil, model.NewAppError("SqlPluginStore.ListWithPrefix", "store.sql_plugin_store.list.app_error", nil, fmt.Sprintf("plugin_id=%v, prefix=%v, err=%v", pluginId, prefix, err.Error()), http.StatusInternalServerError)
+	}
+
+	return keys, nil
+}
+
+// GetAll returns a page of the key-value pairs of all plugins, ordered by plugin id and key. Expired
+// pairs and those stored before raw keys were recorded are omitted.
+func (ps SqlPluginStore) GetAll(offset, limit int) ([]*model.PluginKeyValue, *model.AppError) {
+	var kvs []*model.PluginKeyValue
+	if _, err := ps.GetReplica().Select(&kvs, "SELECT * FROM PluginKeyValueStore WHERE RawKey != '' AND (ExpireAt = 0 OR ExpireAt > :Now) ORDER BY PluginId, PKey LIMIT :Limit OFFSET :Offset", map[string]interface{}{"Now": model.GetMillis(), "Limit": limit, "Offset": offset}); err != nil {
+		return nil, model.NewAppError("SqlPluginStore.GetAll", "store.sql_plugin_store.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return kvs, nil
+}
+
+// GetAllForPlugin is like GetAll, but only returns the key-value pairs of the given plugin, ordered by
+// key.
+func (ps SqlPluginStore) GetAllForPlugin(pluginId string, offset, limit int) ([]*model.PluginKeyValue, *model.AppError) {
+	var kvs []*model.PluginKeyValue
+	if _, err := ps.GetReplica().Select(&kvs, "SELECT * FROM PluginKeyValueStore WHERE PluginId = :PluginId AND RawKey != '' AND (ExpireAt = 0 OR ExpireAt > :Now) ORDER BY PKey LIMIT :Limit OFFSET :Offset", map[string]interface{}{"PluginId": pluginId, "Now": model.GetMillis(), "Limit": limit, "Offset": offset}); err != nil {
+		return nil, model.NewAppError("SqlPluginStore.GetAllForPlugin", "store.sql_plugin_store.get_all.app_error", nil, fmt.Sprintf("plugin_id=%v, err=%v", pluginId, err.Error()), http.StatusInternalServerError)
+	}
+
+	return kvs, nil
+}
+
+// Delete removes the given key, returning true if a row was deleted. Deleting a missing key is not an
+// error.
+func (ps SqlPluginStore) Delete(pluginId, key string) (bool, *model.AppError) {
+	sqlResult, err := ps.GetMaster().Exec("DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key", map[string]interface{}{"PluginId": pluginId, "Key": key})
+	if err != nil {
+		return false, model.NewAppError("SqlPluginStore.Delete", "store.sql_plugin_store.delete.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusInternalServerError)
+	}
+
+	rowsAffected, err := sqlResult.RowsAffected()
+	if err != nil {
+		return false, model.NewAppError("SqlPluginStore.Delete", "store.sql_plugin_store.delete.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusInternalServerError)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// Copy stores the value of the given key under newKey as well, along with its expiry, only if newKey
+// does not yet exist. Expired keys are treated as not existing. It returns true if the key was copied,
+// false if newKey already exists, and a not found error if the key does not.
+func (ps SqlPluginStore) Copy(pluginId, key, newKey string) (bool, *model.AppError) {
+	return ps.copyKey("SqlPluginStore.Copy", pluginId, key, newKey, false)
+}
+
+// Move is like Copy, but also deletes the given key in the same transaction.
+func (ps SqlPluginStore) Move(pluginId, key, newKey string) (bool, *model.AppError) {
+	return ps.copyKey("SqlPluginStore.Move", pluginId, key, newKey, true)
+}
+
+func (ps SqlPluginStore) copyKey(where, pluginId, key, newKey string, move bool) (bool, *model.AppError) {
+	// The destination is validated as a key, since the value is copied as stored.
+	if err := (&model.PluginKeyValue{PluginId: pluginId, Key: newKey, Value: []byte{}, RawKey: newKey}).IsValid(model.KEY_VALUE_VALUE_MAX_BYTES); err != nil {
+		return false, err
+	}
+
+	params := map[string]interface{}{"PluginId": pluginId, "Key": key, "NewKey": newKey, "Now": model.GetMillis()}
+	details := fmt.Sprintf("plugin_id=%v, key=%v, new_key=%v", pluginId, key, newKey)
+
+	apply := func(transaction *gorp.Transaction) (bool, *model.AppError) {
+		if _, err := transaction.Exec("DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :NewKey AND ExpireAt != 0 AND ExpireAt <= :Now", params); err != nil {
+			return false, model.NewAppError(where, "store.sql_plugin_store.copy.app_error", nil, details+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		sqlResult, err := transaction.Exec("INSERT INTO PluginKeyValueStore (PluginId, PKey, PValue, RawKey, ExpireAt) SELECT PluginId, :NewKey, PValue, :NewKey, ExpireAt FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key AND (ExpireAt = 0 OR ExpireAt > :Now)", params)
+		if err != nil {
+			if IsUniqueConstraintError(err, pluginKeyValueUniqueConstraintNames) {
+				return false, nil
+			}
+			return false, model.NewAppError(where, "store.sql_plugin_store.copy.app_error", nil, details+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		rowsAffected, err := sqlResult.RowsAffected()
+		if err != nil {
+			return false, model.NewAppError(where, "store.sql_plugin_store.copy.app_error", nil, details+", err="+err.Error(), http.StatusInternalServerError)
+		}
+		if rowsAffected == 0 {
+			return false, model.NewAppError(where, "store.sql_plugin_store.get.app_error", nil, details, http.StatusNotFound)
+		}
+
+		if move {
+			if _, err := transaction.Exec("DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key", params); err != nil {
+				return false, model.NewAppError(where, "store.sql_plugin_store.copy.app_error", nil, details+", err="+err.Error(), http.StatusInternalServerError)
 			}
-		} else {
-			result.Data = kv
 		}
-	})
+
+		return true, nil
+	}
+
+	transaction, err := ps.GetMaster().Begin()
+	if err != nil {
+		return false, model.NewAppError(where, "store.sql_plugin_store.copy.app_error", nil, details+", err="+err.Error(), http.StatusInternalServerError)
+	}
+
+	if copied, appErr := apply(transaction); !copied {
+		transaction.Rollback()
+		return false, appErr
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return false, model.NewAppError(where, "store.sql_plugin_store.copy.app_error", nil, details+", err="+err.Error(), http.StatusInternalServerError)
+	}
+
+	return true, nil
+}
+
+// GetHashedKeys returns, from the master, up to limit of the key-value pairs still stored as the hash
+// of a recorded raw key short enough to be stored as given, ordered by plugin id and key and
+// starting after the given plugin id and hashed key.
+func (ps SqlPluginStore) GetHashedKeys(afterPluginId, afterKey string, limit int) ([]*model.PluginKeyValue, *model.AppError) {
+	var kvs []*model.PluginKeyValue
+	if _, err := ps.GetMaster().Select(&kvs, "SELECT * FROM PluginKeyValueStore WHERE RawKey != '' AND PKey != RawKey AND CHAR_LENGTH(RawKey) <= :MaxKeyLength AND (PluginId > :AfterPluginId OR (PluginId = :AfterPluginId AND PKey > :AfterKey)) ORDER BY PluginId, PKey LIMIT :Limit", map[string]interface{}{"MaxKeyLength": model.KEY_VALUE_KEY_MAX_RUNES, "AfterPluginId": afterPluginId, "AfterKey": afterKey, "Limit": limit}); err != nil {
+		return nil, model.NewAppError("SqlPluginStore.GetHashedKeys", "store.sql_plugin_store.get_hashed_keys.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return kvs, nil
 }
 
-func (ps SqlPluginStore) Delete(pluginId, key string) store.StoreChannel {
-	return store.Do(func(result *store.StoreResult) {
-		if _, err := ps.GetMaster().Exec("DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key", map[string]interface{}{"PluginId": pluginId, "Key": key}); err != nil {
-			result.Err = model.NewAppError("SqlPluginStore.Delete", "store.sql_plugin_store.delete.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusInternalServerError)
+// MoveHashedKey moves the key-value pair stored under hashedKey, along with its expiry, to key,
+// unless key already holds a newer pair, in which case the hashed pair is dropped. Expired pairs are
+// dropped too. It returns true if the pair was moved, and false if it was dropped or no longer
+// exists, so that of concurrent moves of the same pair, only one ever writes key.
+func (ps SqlPluginStore) MoveHashedKey(pluginId, hashedKey, key string) (bool, *model.AppError) {
+	if err := (&model.PluginKeyValue{PluginId: pluginId, Key: key, Value: []byte{}, RawKey: key}).IsValid(model.KEY_VALUE_VALUE_MAX_BYTES); err != nil {
+		return false, err
+	}
+
+	now := model.GetMillis()
+	params := map[string]interface{}{"PluginId": pluginId, "HashedKey": hashedKey, "Key": key, "Now": now}
+	details := fmt.Sprintf("plugin_id=%v, key=%v", pluginId, key)
+
+	apply := func(transaction *gorp.Transaction) (bool, *model.AppError) {
+		// Locking the hashed pair makes concurrent moves of it wait for this one, after which they
+		// find it gone.
+		var kv model.PluginKeyValue
+		if err := transaction.SelectOne(&kv, "SELECT * FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :HashedKey FOR UPDATE", params); err == sql.ErrNoRows {
+			return false, nil
+		} else if err != nil {
+			return false, model.NewAppError("SqlPluginStore.MoveHashedKey", "store.sql_plugin_store.move_hashed_key.app_error", nil, details+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		if _, err := transaction.Exec("DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :HashedKey", params); err != nil {
+			return false, model.NewAppError("SqlPluginStore.MoveHashedKey", "store.sql_plugin_store.move_hashed_key.app_error", nil, details+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		if kv.ExpireAt != 0 && kv.ExpireAt <= now {
+			return false, nil
+		}
+
+		if _, err := transaction.Exec("DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key AND ExpireAt != 0 AND ExpireAt <= :Now", params); err != nil {
+			return false, model.NewAppError("SqlPluginStore.MoveHashedKey", "store.sql_plugin_store.move_hashed_key.app_error", nil, details+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		params["Value"] = kv.Value
+		params["ExpireAt"] = kv.ExpireAt
+
+		// A pair written under key concurrently is newer, so the insert is skipped rather than
+		// failing the transaction. Older PostgreSQL cannot skip it, so the move fails instead and is
+		// retried when the key is next accessed or migrated.
+		var query string
+		if ps.DriverName() == model.DATABASE_DRIVER_POSTGRES && ps.upsertOnConflict {
+			query = "INSERT INTO PluginKeyValueStore (PluginId, PKey, PValue, RawKey, ExpireAt) VALUES(:PluginId, :Key, :Value, :Key, :ExpireAt) ON CONFLICT (PluginId, PKey) DO NOTHING"
+		} else if ps.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+			count, err := transaction.SelectInt("SELECT COUNT(*) FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key", params)
+			if err != nil {
+				return false, model.NewAppError("SqlPluginStore.MoveHashedKey", "store.sql_plugin_store.move_hashed_key.app_error", nil, details+", err="+err.Error(), http.StatusInternalServerError)
+			} else if count > 0 {
+				return false, nil
+			}
+			query = "INSERT INTO PluginKeyValueStore (PluginId, PKey, PValue, RawKey, ExpireAt) VALUES(:PluginId, :Key, :Value, :Key, :ExpireAt)"
 		} else {
-			result.Data = true
+			query = "INSERT INTO PluginKeyValueStore (PluginId, PKey, PValue, RawKey, ExpireAt) VALUES(:PluginId, :Key, :Value, :Key, :ExpireAt) ON DUPLICATE KEY UPDATE PKey = PKey"
 		}
-	})
+
+		sqlResult, err := transaction.Exec(query, params)
+		if err != nil {
+			return false, model.NewAppError("SqlPluginStore.MoveHashedKey", "store.sql_plugin_store.move_hashed_key.app_error", nil, details+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		rowsAffected, err := sqlResult.RowsAffected()
+		if err != nil {
+			return false, model.NewAppError("SqlPluginStore.MoveHashedKey", "store.sql_plugin_store.move_hashed_key.app_error", nil, details+", err="+err.Error(), http.StatusInternalServerError)
+		}
+
+		return rowsAffected > 0, nil
+	}
+
+	transaction, err := ps.GetMaster().Begin()
+	if err != nil {
+		return false, model.NewAppError("SqlPluginStore.MoveHashedKey", "store.sql_plugin_store.move_hashed_key.app_error", nil, details+", err="+err.Error(), http.StatusInternalServerError)
+	}
+
+	moved, appErr := apply(transaction)
+	if appErr != nil {
+		transaction.Rollback()
+		return false, appErr
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return false, model.NewAppError("SqlPluginStore.MoveHashedKey", "store.sql_plugin_store.move_hashed_key.app_error", nil, details+", err="+err.Error(), http.StatusInternalServerError)
+	}
+
+	return moved, nil
+}
+
+// CountHashedKeys counts, on the master, the key-value pairs still stored as the hash of their key,
+// leaving Completed unset. Pairs without a raw key are counted as unrecorded when they are shaped like
+// a hashed plugin key and have no expiry, which internal pairs such as advisory locks always have.
+func (ps SqlPluginStore) CountHashedKeys() (*model.PluginKeyValueMigrationStatus, *model.AppError) {
+	var status model.PluginKeyValueMigrationStatus
+	if err := ps.GetMaster().SelectOne(&status, `SELECT
+			(SELECT COUNT(*) FROM PluginKeyValueStore WHERE RawKey != '' AND PKey != RawKey AND CHAR_LENGTH(RawKey) <= :MaxKeyLength) AS RemainingKeys,
+			(SELECT COUNT(*) FROM PluginKeyValueStore WHERE RawKey = '' AND ExpireAt = 0 AND CHAR_LENGTH(PKey) = :HashedKeyLength AND PKey LIKE '%=') AS UnrecordedKeys`,
+		map[string]interface{}{"MaxKeyLength": model.KEY_VALUE_KEY_MAX_RUNES, "HashedKeyLength": pluginKeyValueHashedKeyLength}); err != nil {
+		return nil, model.NewAppError("SqlPluginStore.CountHashedKeys", "store.sql_plugin_store.count_hashed_keys.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return &status, nil
+}
+
+// CompareAndDelete deletes the given key only if it currently holds oldValue. Expired keys are treated
+// as not existing. It returns true if the key was deleted.
+func (ps SqlPluginStore) CompareAndDelete(pluginId, key string, oldValue []byte) (bool, *model.AppError) {
+	sqlResult, err := ps.GetMaster().Exec("DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key AND PValue = :Old AND (ExpireAt = 0 OR ExpireAt > :Now)", map[string]interface{}{"PluginId": pluginId, "Key": key, "Old": oldValue, "Now": model.GetMillis()})
+	if err != nil {
+		return false, model.NewAppError("SqlPluginStore.CompareAndDelete", "store.sql_plugin_store.compare_and_delete.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusInternalServerError)
+	}
+
+	rowsAffected, err := sqlResult.RowsAffected()
+	if err != nil {
+		return false, model.NewAppError("SqlPluginStore.CompareAndDelete", "store.sql_plugin_store.compare_and_delete.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusInternalServerError)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// Increment atomically adds delta to the counter stored as a decimal number under the given key,
+// creating it with the value delta if it does not exist or has expired, and returns the new value of
+// the counter. A key holding anything other than a decimal number, or a counter that would overflow
+// an int64, is left unchanged and reported as a bad request.
+func (ps SqlPluginStore) Increment(pluginId, key string, delta int64) (int64, *model.AppError) {
+	kv := &model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      key,
+		Value:    []byte(strconv.FormatInt(delta, 10)),
+		RawKey:   key,
+	}
+	if err := kv.IsValid(model.KEY_VALUE_VALUE_MAX_BYTES); err != nil {
+		return 0, err
+	}
+
+	params := map[string]interface{}{"PluginId": pluginId, "Key": key, "Delta": delta}
+
+	// The counter is created outside of a transaction and only updated within one, so that
+	// transactions only ever lock existing counters and cannot deadlock each other creating them.
+	for attempt := 0; attempt < PLUGIN_STORE_MAX_ATTEMPTS; attempt++ {
+		params["Now"] = model.GetMillis()
+
+		if _, err := ps.GetMaster().Exec("DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key AND ExpireAt != 0 AND ExpireAt <= :Now", params); err != nil {
+			return 0, model.NewAppError("SqlPluginStore.Increment", "store.sql_plugin_store.increment.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusInternalServerError)
+		}
+
+		if err := ps.GetMaster().Insert(kv); err == nil {
+			return delta, nil
+		} else if !IsUniqueConstraintError(err, pluginKeyValueUniqueConstraintNames) {
+			return 0, model.NewAppError("SqlPluginStore.Increment", "store.sql_plugin_store.increment.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusInternalServerError)
+		}
+
+		transaction, err := ps.GetMaster().Begin()
+		if err != nil {
+			return 0, model.NewAppError("SqlPluginStore.Increment", "store.sql_plugin_store.increment.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusInternalServerError)
+		}
+
+		value, found, appErr := ps.incrementT(transaction, params)
+		if appErr != nil || !found {
+			transaction.Rollback()
+			if appErr != nil {
+				return 0, appErr
+			}
+
+			// The counter expired or was deleted since it could not be created, so try again.
+			continue
+		}
+
+		if err := transaction.Commit(); err != nil {
+			return 0, model.NewAppError("SqlPluginStore.Increment", "store.sql_plugin_store.increment.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusInternalServerError)
+		}
+
+		return value, nil
+	}
+
+	return 0, model.NewAppError("SqlPluginStore.Increment", "store.sql_plugin_store.increment.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=too many attempts", pluginId, key), http.StatusInternalServerError)
+}
+
+// incrementT increments the existing counter within the given transaction, returning its new value
+// and whether it was found. The counter is read and locked, then written back, rather than
+// incremented in SQL, so that values out of the range of an int64 are reported instead of failing in
+// or being clamped by the database.
+func (ps SqlPluginStore) incrementT(transaction *gorp.Transaction, params map[string]interface{}) (int64, bool, *model.AppError) {
+	pluginId, key, delta := params["PluginId"], params["Key"], params["Delta"].(int64)
+
+	var value []byte
+	if err := transaction.SelectOne(&value, "SELECT PValue FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key AND (ExpireAt = 0 OR ExpireAt > :Now) FOR UPDATE", params); err == sql.ErrNoRows {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, model.NewAppError("SqlPluginStore.Increment", "store.sql_plugin_store.increment.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusInternalServerError)
+	}
+
+	if !pluginCounterPattern.Match(value) {
+		return 0, false, model.NewAppError("SqlPluginStore.Increment", "store.sql_plugin_store.increment.not_a_number.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v", pluginId, key), http.StatusBadRequest)
+	}
+
+	counter, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return 0, false, model.NewAppError("SqlPluginStore.Increment", "store.sql_plugin_store.increment.not_a_number.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v", pluginId, key), http.StatusBadRequest)
+	}
+
+	if (delta > 0 && counter > math.MaxInt64-delta) || (delta < 0 && counter < math.MinInt64-delta) {
+		return 0, false, model.NewAppError("SqlPluginStore.Increment", "store.sql_plugin_store.increment.overflow.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, delta=%v", pluginId, key, delta), http.StatusBadRequest)
+	}
+	counter += delta
+
+	params["Value"] = []byte(strconv.FormatInt(counter, 10))
+	if _, err := transaction.Exec("UPDATE PluginKeyValueStore SET PValue = :Value WHERE PluginId = :PluginId AND PKey = :Key", params); err != nil {
+		return 0, false, model.NewAppError("SqlPluginStore.Increment", "store.sql_plugin_store.increment.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", pluginId, key, err.Error()), http.StatusInternalServerError)
+	}
+
+	return counter, true, nil
+}
+
+// DeleteAllForPlugin deletes every key-value pair stored by the plugin, returning the number deleted.
+// The pairs are deleted PLUGIN_STORE_DELETE_ALL_BATCH_SIZE at a time, so that deleting many does not
+// hold locks on the table for long.
+func (ps SqlPluginStore) DeleteAllForPlugin(pluginId string) (int64, *model.AppError) {
+	var query string
+	if ps.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+		query = "DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = any (array (SELECT PKey FROM PluginKeyValueStore WHERE PluginId = :PluginId LIMIT :Limit))"
+	} else {
+		query = "DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId LIMIT :Limit"
+	}
+
+	var deleted int64
+	for {
+		sqlResult, err := ps.GetMaster().Exec(query, map[string]interface{}{"PluginId": pluginId, "Limit": PLUGIN_STORE_DELETE_ALL_BATCH_SIZE})
+		if err != nil {
+			return 0, model.NewAppError("SqlPluginStore.DeleteAllForPlugin", "store.sql_plugin_store.delete_all.app_error", nil, fmt.Sprintf("plugin_id=%v, err=%v", pluginId, err.Error()), http.StatusInternalServerError)
+		}
+
+		rowsAffected, err := sqlResult.RowsAffected()
+		if err != nil {
+			return 0, model.NewAppError("SqlPluginStore.DeleteAllForPlugin", "store.sql_plugin_store.delete_all.app_error", nil, fmt.Sprintf("plugin_id=%v, err=%v", pluginId, err.Error()), http.StatusInternalServerError)
+		}
+
+		deleted += rowsAffected
+		if rowsAffected < PLUGIN_STORE_DELETE_ALL_BATCH_SIZE {
+			return deleted, nil
+		}
+	}
+}
+
+// GetUsage returns the number of key-value pairs stored by the plugin along with the total size of their values.
+func (ps SqlPluginStore) GetUsage(pluginId string) (*model.PluginKeyValueUsage, *model.AppError) {
+	return ps.getUsage(pluginId, false)
+}
+
+// GetUsageFromMaster is like GetUsage, but reads from the master database so as to see all committed writes.
+func (ps SqlPluginStore) GetUsageFromMaster(pluginId string) (*model.PluginKeyValueUsage, *model.AppError) {
+	return ps.getUsage(pluginId, true)
+}
+
+func (ps SqlPluginStore) getUsage(pluginId string, master bool) (*model.PluginKeyValueUsage, *model.AppError) {
+	db := ps.GetReplica()
+	if master {
+		db = ps.GetMaster()
+	}
+
+	var usage model.PluginKeyValueUsage
+	if err := db.SelectOne(&usage, "SELECT COUNT(*) AS KeyCount, COALESCE(SUM(LENGTH(PValue)), 0) AS Size FROM PluginKeyValueStore WHERE PluginId = :PluginId", map[string]interface{}{"PluginId": pluginId}); err != nil {
+		return nil, model.NewAppError("SqlPluginStore.GetUsage", "store.sql_plugin_store.get_usage.app_error", nil, fmt.Sprintf("plugin_id=%v, err=%v", pluginId, err.Error()), http.StatusInternalServerError)
+	}
+
+	return &usage, nil
+}
+
+// DeleteAllExpired deletes every expired key-value pair across all plugins, returning the number deleted.
+func (ps SqlPluginStore) DeleteAllExpired() (int64, *model.AppError) {
+	sqlResult, err := ps.GetMaster().Exec("DELETE FROM PluginKeyValueStore WHERE ExpireAt != 0 AND ExpireAt <= :Now", map[string]interface{}{"Now": model.GetMillis()})
+	if err != nil {
+		return 0, model.NewAppError("SqlPluginStore.DeleteAllExpired", "store.sql_plugin_store.delete_all_expired.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	rowsAffected, err := sqlResult.RowsAffected()
+	if err != nil {
+		return 0, model.NewAppError("SqlPluginStore.DeleteAllExpired", "store.sql_plugin_store.delete_all_expired.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return rowsAffected, nil
 }
@@ -4,11 +4,230 @@
 package sqlstore
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
 	"github.com/mattermost/mattermost-server/store/storetest"
 )
 
 func TestPluginStore(t *testing.T) {
 	StoreTest(t, storetest.TestPluginStore)
 }
+
+// postgresPluginStores returns plugin stores for the PostgreSQL store under test saving key-value
+// pairs with INSERT ... ON CONFLICT, if the server supports it, and with the legacy update then
+// insert, by name.
+func postgresPluginStores(t testing.TB) map[string]*SqlPluginStore {
+	for _, st := range storeTypes {
+		if st.Name != "PostgreSQL" {
+			continue
+		}
+
+		supplier := st.Store.(*store.LayeredStore).DatabaseLayer.(*SqlSupplier)
+		stores := map[string]*SqlPluginStore{
+			"legacy": {SqlStore: supplier},
+		}
+
+		version, err := getPostgresServerVersion(supplier.GetMaster())
+		require.NoError(t, err)
+		if version >= POSTGRES_ON_CONFLICT_MIN_VERSION {
+			assert.True(t, supplier.Plugin().(*SqlPluginStore).upsertOnConflict)
+			stores["on conflict"] = &SqlPluginStore{SqlStore: supplier, upsertOnConflict: true}
+		}
+
+		return stores
+	}
+
+	return nil
+}
+
+func TestPluginStoreSaveOrUpdatePostgres(t *testing.T) {
+	for name, ps := range postgresPluginStores(t) {
+		ps := ps
+		t.Run(name, func(t *testing.T) {
+			pluginId := model.NewId()
+
+			kv := &model.PluginKeyValue{PluginId: pluginId, Key: "key", Value: []byte("value"), RawKey: "key"}
+			_, err := ps.SaveOrUpdate(kv)
+			require.Nil(t, err)
+
+			kv = &model.PluginKeyValue{PluginId: pluginId, Key: "key", Value: []byte("new value"), RawKey: "key", ExpireAt: model.GetMillis() + 60000}
+			_, err = ps.SaveOrUpdate(kv)
+			require.Nil(t, err)
+
+			received, err := ps.Get(pluginId, "key")
+			require.Nil(t, err)
+			assert.Equal(t, kv.Value, received.Value)
+			assert.Equal(t, kv.ExpireAt, received.ExpireAt)
+
+			// Concurrent writers of the same key must all succeed, leaving one of their values.
+			var wg sync.WaitGroup
+			errs := make(chan *model.AppError, 10)
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, err := ps.SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: "concurrent", Value: []byte(fmt.Sprintf("value%d", i)), RawKey: "concurrent"})
+					errs <- err
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				assert.Nil(t, err)
+			}
+
+			received, err = ps.Get(pluginId, "concurrent")
+			require.Nil(t, err)
+			assert.Regexp(t, "^value[0-9]$", string(received.Value))
+		})
+	}
+}
+
+func BenchmarkPluginStoreSaveOrUpdatePostgres(b *testing.B) {
+	for name, ps := range postgresPluginStores(b) {
+		ps := ps
+		b.Run(name, func(b *testing.B) {
+			pluginId := model.NewId()
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					// Writers contend for a few keys, so that both inserts and updates are measured.
+					key := fmt.Sprintf("key%d", i%10)
+					if _, err := ps.SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: key, Value: []byte("value"), RawKey: key}); err != nil {
+						b.Fatal(err)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkPluginStoreGet compares reading a key-value pair directly with reading it through a store
+// channel, as every read was made before the plugin store became synchronous.
+func BenchmarkPluginStoreGet(b *testing.B) {
+	for _, st := range storeTypes {
+		ps := st.Store.(*store.LayeredStore).DatabaseLayer.(*SqlSupplier).Plugin()
+
+		kv := &model.PluginKeyValue{PluginId: model.NewId(), Key: "key", Value: []byte("value"), RawKey: "key"}
+		if _, err := ps.SaveOrUpdate(kv); err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(st.Name+"/sync", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ps.Get(kv.PluginId, kv.Key); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(st.Name+"/channel", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				result := <-store.Do(func(result *store.StoreResult) {
+					result.Data, result.Err = ps.Get(kv.PluginId, kv.Key)
+				})
+				if result.Err != nil {
+					b.Fatal(result.Err)
+				}
+			}
+		})
+
+		ps.DeleteAllForPlugin(kv.PluginId)
+	}
+}
+
+// failingPluginKeyValueExecutor fails the first failures statements it is given with err.
+type failingPluginKeyValueExecutor struct {
+	err      error
+	failures int
+	calls    int
+}
+
+func (e *failingPluginKeyValueExecutor) fail() error {
+	e.calls++
+	if e.calls <= e.failures {
+		return e.err
+	}
+	return nil
+}
+
+func (e *failingPluginKeyValueExecutor) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return nil, e.fail()
+}
+
+func (e *failingPluginKeyValueExecutor) Update(list ...interface{}) (int64, error) {
+	return 1, e.fail()
+}
+
+func (e *failingPluginKeyValueExecutor) Insert(list ...interface{}) error {
+	return e.fail()
+}
+
+func TestUpsertPluginKeyValueRetriesLockErrors(t *testing.T) {
+	kv := &model.PluginKeyValue{PluginId: model.NewId(), Key: "key", Value: []byte("value"), RawKey: "key"}
+
+	paths := []struct {
+		Name             string
+		DriverName       string
+		UpsertOnConflict bool
+		LockError        error
+	}{
+		{"mysql", model.DATABASE_DRIVER_MYSQL, false, &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}},
+		{"mysql lock wait timeout", model.DATABASE_DRIVER_MYSQL, false, &mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}},
+		{"postgres on conflict", model.DATABASE_DRIVER_POSTGRES, true, &pq.Error{Code: "40P01", Message: "deadlock detected"}},
+		{"postgres legacy", model.DATABASE_DRIVER_POSTGRES, false, &pq.Error{Code: "40P01", Message: "deadlock detected"}},
+	}
+
+	for _, path := range paths {
+		path := path
+		t.Run(path.Name, func(t *testing.T) {
+			t.Run("succeeds after retrying", func(t *testing.T) {
+				executor := &failingPluginKeyValueExecutor{err: path.LockError, failures: PLUGIN_STORE_LOCK_MAX_ATTEMPTS - 1}
+
+				assert.NoError(t, upsertPluginKeyValue(executor, path.DriverName, path.UpsertOnConflict, kv))
+				assert.Equal(t, PLUGIN_STORE_LOCK_MAX_ATTEMPTS, executor.calls)
+			})
+
+			t.Run("gives up after the last attempt", func(t *testing.T) {
+				executor := &failingPluginKeyValueExecutor{err: path.LockError, failures: PLUGIN_STORE_LOCK_MAX_ATTEMPTS}
+
+				assert.Equal(t, path.LockError, upsertPluginKeyValue(executor, path.DriverName, path.UpsertOnConflict, kv))
+				assert.Equal(t, PLUGIN_STORE_LOCK_MAX_ATTEMPTS, executor.calls)
+			})
+
+			t.Run("does not retry other errors", func(t *testing.T) {
+				otherErr := errors.New("connection refused")
+				executor := &failingPluginKeyValueExecutor{err: otherErr, failures: PLUGIN_STORE_LOCK_MAX_ATTEMPTS}
+
+				assert.Equal(t, otherErr, upsertPluginKeyValue(executor, path.DriverName, path.UpsertOnConflict, kv))
+				assert.Equal(t, 1, executor.calls)
+			})
+		})
+	}
+}
+
+func TestPluginKeyValueRetryBackoff(t *testing.T) {
+	for attempt := 1; attempt < PLUGIN_STORE_LOCK_MAX_ATTEMPTS; attempt++ {
+		delay := PLUGIN_STORE_LOCK_RETRY_DELAY << uint(attempt-1)
+		for i := 0; i < 10; i++ {
+			backoff := pluginKeyValueRetryBackoff(attempt)
+			assert.True(t, backoff >= delay/2 && backoff <= delay, "attempt %d waited %v", attempt, backoff)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+type SqlPluginSubscriptionStore struct {
+	SqlStore
+}
+
+func NewSqlPluginSubscriptionStore(sqlStore SqlStore) store.PluginSubscriptionStore {
+	s := &SqlPluginSubscriptionStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PluginSubscription{}, "PluginSubscriptions").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("PluginId").SetMaxSize(190)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("CreatorId").SetMaxSize(26)
+		table.ColMap("Description").SetMaxSize(model.PLUGIN_SUBSCRIPTION_DESCRIPTION_MAX_RUNES)
+		table.ColMap("Payload").SetMaxSize(model.PLUGIN_SUBSCRIPTION_PAYLOAD_MAX_BYTES)
+	}
+
+	return s
+}
+
+func (s SqlPluginSubscriptionStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_plugin_subscriptions_channel_id", "PluginSubscriptions", "ChannelId")
+	s.CreateIndexIfNotExists("idx_plugin_subscriptions_plugin_id", "PluginSubscriptions", "PluginId")
+}
+
+func (s SqlPluginSubscriptionStore) Save(subscription *model.PluginSubscription) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if len(subscription.Id) > 0 {
+			result.Err = model.NewAppError("SqlPluginSubscriptionStore.Save", "store.sql_plugin_subscription.save.existing.app_error", nil, "id="+subscription.Id, http.StatusBadRequest)
+			return
+		}
+
+		subscription.PreSave()
+		if result.Err = subscription.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(subscription); err != nil {
+			result.Err = model.NewAppError("SqlPluginSubscriptionStore.Save", "store.sql_plugin_subscription.save.app_error", nil, "id="+subscription.Id+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = subscription
+		}
+	})
+}
+
+func (s SqlPluginSubscriptionStore) Get(id string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var subscription model.PluginSubscription
+
+		if err := s.GetReplica().SelectOne(&subscription, "SELECT * FROM PluginSubscriptions WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlPluginSubscriptionStore.Get", "store.sql_plugin_subscription.get.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+			if err == sql.ErrNoRows {
+				result.Err.StatusCode = http.StatusNotFound
+			}
+			return
+		}
+
+		result.Data = &subscription
+	})
+}
+
+func (s SqlPluginSubscriptionStore) GetForChannel(channelId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var subscriptions []*model.PluginSubscription
+
+		if _, err := s.GetReplica().Select(&subscriptions, "SELECT * FROM PluginSubscriptions WHERE ChannelId = :ChannelId ORDER BY CreateAt", map[string]interface{}{"ChannelId": channelId}); err != nil {
+			result.Err = model.NewAppError("SqlPluginSubscriptionStore.GetForChannel", "store.sql_plugin_subscription.get_for_channel.app_error", nil, "channel_id="+channelId+", err="+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = subscriptions
+	})
+}
+
+func (s SqlPluginSubscriptionStore) Delete(id string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec("DELETE FROM PluginSubscriptions WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewAppError("SqlPluginSubscriptionStore.Delete", "store.sql_plugin_subscription.delete.app_error", nil, "id="+id+", err="+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func (s SqlPluginSubscriptionStore) DeleteAllForPlugin(pluginId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec("DELETE FROM PluginSubscriptions WHERE PluginId = :PluginId", map[string]interface{}{"PluginId": pluginId}); err != nil {
+			result.Err = model.NewAppError("SqlPluginSubscriptionStore.DeleteAllForPlugin", "store.sql_plugin_subscription.delete_all_for_plugin.app_error", nil, "plugin_id="+pluginId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
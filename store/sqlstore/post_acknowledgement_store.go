@@ -0,0 +1,96 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+type SqlPostAcknowledgementStore struct {
+	SqlStore
+}
+
+func NewSqlPostAcknowledgementStore(sqlStore SqlStore) store.PostAcknowledgementStore {
+	s := &SqlPostAcknowledgementStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PostAcknowledgement{}, "PostAcknowledgements").SetKeys(false, "PostId", "UserId")
+		table.ColMap("PostId").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+// Save records an acknowledgement. Users acknowledge a post at most once, so if the user has already
+// acknowledged it, the existing acknowledgement is returned instead.
+func (s SqlPostAcknowledgementStore) Save(acknowledgement *model.PostAcknowledgement) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		acknowledgement.PreSave()
+		if result.Err = acknowledgement.IsValid(); result.Err != nil {
+			return
+		}
+
+		if err := s.GetMaster().Insert(acknowledgement); err != nil {
+			if !IsUniqueConstraintError(err, []string{"PRIMARY", "postacknowledgements_pkey"}) {
+				result.Err = model.NewAppError("SqlPostAcknowledgementStore.Save", "store.sql_post_acknowledgement.save.app_error", nil, "post_id="+acknowledgement.PostId+", user_id="+acknowledgement.UserId+", err="+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			*result = <-s.get(acknowledgement.PostId, acknowledgement.UserId, true)
+			return
+		}
+
+		result.Data = acknowledgement
+	})
+}
+
+func (s SqlPostAcknowledgementStore) Get(postId, userId string) store.StoreChannel {
+	return s.get(postId, userId, false)
+}
+
+func (s SqlPostAcknowledgementStore) get(postId, userId string, master bool) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		db := s.GetReplica()
+		if master {
+			db = s.GetMaster()
+		}
+
+		var acknowledgement model.PostAcknowledgement
+		if err := db.SelectOne(&acknowledgement, "SELECT * FROM PostAcknowledgements WHERE PostId = :PostId AND UserId = :UserId", map[string]interface{}{"PostId": postId, "UserId": userId}); err != nil {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.Get", "store.sql_post_acknowledgement.get.app_error", nil, "post_id="+postId+", user_id="+userId+", err="+err.Error(), http.StatusInternalServerError)
+			if err == sql.ErrNoRows {
+				result.Err.StatusCode = http.StatusNotFound
+			}
+			return
+		}
+
+		result.Data = &acknowledgement
+	})
+}
+
+func (s SqlPostAcknowledgementStore) GetForPost(postId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var acknowledgements []*model.PostAcknowledgement
+
+		if _, err := s.GetReplica().Select(&acknowledgements, "SELECT * FROM PostAcknowledgements WHERE PostId = :PostId ORDER BY AcknowledgedAt, UserId", map[string]interface{}{"PostId": postId}); err != nil {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.GetForPost", "store.sql_post_acknowledgement.get_for_post.app_error", nil, "post_id="+postId+", err="+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = acknowledgements
+	})
+}
+
+func (s SqlPostAcknowledgementStore) DeleteAllForPost(postId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec("DELETE FROM PostAcknowledgements WHERE PostId = :PostId", map[string]interface{}{"PostId": postId}); err != nil {
+			result.Err = model.NewAppError("SqlPostAcknowledgementStore.DeleteAllForPost", "store.sql_post_acknowledgement.delete_all_for_post.app_error", nil, "post_id="+postId+", err="+err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
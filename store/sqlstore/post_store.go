@@ -1134,6 +1134,26 @@ func (s *SqlPostStore) GetPostsByIds(postIds []string) store.StoreChannel {
 	})
 }
 
+// GetPostsWithFileId returns the posts of the channel with the file attached, including deleted
+// posts and the edit history of posts.
+func (s *SqlPostStore) GetPostsWithFileId(channelId string, fileId string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var posts []*model.Post
+		if _, err := s.GetMaster().Select(&posts,
+			`SELECT
+				*
+			FROM
+				Posts
+			WHERE
+				ChannelId = :ChannelId
+				AND FileIds LIKE :FileId`, map[string]interface{}{"ChannelId": channelId, "FileId": "%\"" + fileId + "\"%"}); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.GetPostsWithFileId", "store.sql_post.get_posts_with_file_id.app_error", nil, "channel_id="+channelId+", file_id="+fileId+", "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = posts
+		}
+	})
+}
+
 func (s *SqlPostStore) GetPostsBatchForIndexing(startTime int64, endTime int64, limit int) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		var posts []*model.PostForIndexing
@@ -1174,6 +1194,35 @@ func (s *SqlPostStore) GetPostsBatchForIndexing(startTime int64, endTime int64,
 	})
 }
 
+// GetPostsBatchForChannelExport returns up to limit of the channel's undeleted posts, ordered by
+// creation time and id, that come after the post created at afterCreateAt with id afterId. Passing the
+// creation time and id of the last post of each batch pages through the channel without skipping or
+// repeating posts created at the same time.
+func (s *SqlPostStore) GetPostsBatchForChannelExport(channelId string, afterCreateAt int64, afterId string, limit int) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var posts []*model.Post
+		if _, err := s.GetReplica().Select(&posts,
+			`SELECT
+				*
+			FROM
+				Posts
+			WHERE
+				ChannelId = :ChannelId
+				AND DeleteAt = 0
+				AND (CreateAt > :AfterCreateAt OR (CreateAt = :AfterCreateAt AND Id > :AfterId))
+			ORDER BY
+				CreateAt ASC, Id ASC
+			LIMIT
+				:Limit`,
+			map[string]interface{}{"ChannelId": channelId, "AfterCreateAt": afterCreateAt, "AfterId": afterId, "Limit": limit}); err != nil {
+			result.Err = model.NewAppError("SqlPostStore.GetPostsBatchForChannelExport", "store.sql_post.get_posts_batch_for_channel_export.app_error", nil, "channel_id="+channelId+", err="+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result.Data = posts
+	})
+}
+
 func (s *SqlPostStore) PermanentDeleteBatch(endTime int64, limit int64) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		var query string
@@ -205,6 +205,26 @@ func (s SqlPreferenceStore) GetCategory(userId string, category string) store.St
 	})
 }
 
+// GetCategoryAndName returns the preferences of every user with the given category and name.
+func (s SqlPreferenceStore) GetCategoryAndName(category string, name string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var preferences model.Preferences
+
+		if _, err := s.GetReplica().Select(&preferences,
+			`SELECT
+				*
+			FROM
+				Preferences
+			WHERE
+				Category = :Category
+				AND Name = :Name`, map[string]interface{}{"Category": category, "Name": name}); err != nil {
+			result.Err = model.NewAppError("SqlPreferenceStore.GetCategoryAndName", "store.sql_preference.get_category_and_name.app_error", nil, err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = preferences
+		}
+	})
+}
+
 func (s SqlPreferenceStore) GetAll(userId string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		var preferences model.Preferences
@@ -289,6 +309,19 @@ func (s SqlPreferenceStore) DeleteCategoryAndName(category string, name string)
 	})
 }
 
+// DeleteAllInCategory deletes the preferences of every user with the given category.
+func (s SqlPreferenceStore) DeleteAllInCategory(category string) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		if _, err := s.GetMaster().Exec(
+			`DELETE FROM
+				Preferences
+			WHERE
+				Category = :Category`, map[string]interface{}{"Category": category}); err != nil {
+			result.Err = model.NewAppError("SqlPreferenceStore.DeleteAllInCategory", "store.sql_preference.delete.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
 func (s SqlPreferenceStore) CleanupFlagsBatch(limit int64) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		query :=
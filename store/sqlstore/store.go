@@ -57,6 +57,7 @@ type SqlStore interface {
 	RemoveTableIfExists(tableName string) bool
 	RenameColumnIfExists(tableName string, oldColumnName string, newColumnName string, colType string) bool
 	GetMaxLengthOfColumnIfExists(tableName string, columnName string) string
+	GetColumnDataTypeIfExists(tableName string, columnName string) string
 	AlterColumnTypeIfExists(tableName string, columnName string, mySqlColType string, postgresColType string) bool
 	CreateUniqueIndexIfNotExists(indexName string, tableName string, columnName string) bool
 	CreateIndexIfNotExists(indexName string, tableName string, columnName string) bool
@@ -89,6 +90,13 @@ type SqlStore interface {
 	Reaction() store.ReactionStore
 	Job() store.JobStore
 	Plugin() store.PluginStore
+	PluginSubscription() store.PluginSubscriptionStore
+	PluginRuntimeState() store.PluginRuntimeStateStore
+	PluginPostMetadata() store.PluginPostMetadataStore
+	PluginOAuthConnection() store.PluginOAuthConnectionStore
+	PluginAdminActionRequest() store.PluginAdminActionRequestStore
+	PluginEvent() store.PluginEventStore
+	PostAcknowledgement() store.PostAcknowledgementStore
 	UserAccessToken() store.UserAccessTokenStore
 	Role() store.RoleStore
 	Scheme() store.SchemeStore
@@ -64,7 +64,7 @@ func initStores() {
 				return
 			}
 			st.Container = container
-			st.Store = store.NewLayeredStore(NewSqlSupplier(*settings, nil), nil, nil)
+			st.Store = store.NewLayeredStore(NewSqlSupplier(*settings, nil), nil, nil, model.PLUGIN_SETTINGS_DEFAULT_KV_CACHE_SIZE, model.PLUGIN_SETTINGS_DEFAULT_KV_CACHE_SECONDS, 0)
 			st.Store.MarkSystemRanUnitTests()
 		}()
 	}
@@ -62,35 +62,43 @@ const (
 	EXIT_REMOVE_INDEX_SQLITE         = 136
 	EXIT_TABLE_EXISTS_SQLITE         = 137
 	EXIT_DOES_COLUMN_EXISTS_SQLITE   = 138
+	EXIT_COLUMN_DATA_TYPE            = 139
 )
 
 type SqlSupplierOldStores struct {
-	team                 store.TeamStore
-	channel              store.ChannelStore
-	post                 store.PostStore
-	user                 store.UserStore
-	audit                store.AuditStore
-	cluster              store.ClusterDiscoveryStore
-	compliance           store.ComplianceStore
-	session              store.SessionStore
-	oauth                store.OAuthStore
-	system               store.SystemStore
-	webhook              store.WebhookStore
-	command              store.CommandStore
-	commandWebhook       store.CommandWebhookStore
-	preference           store.PreferenceStore
-	license              store.LicenseStore
-	token                store.TokenStore
-	emoji                store.EmojiStore
-	status               store.StatusStore
-	fileInfo             store.FileInfoStore
-	reaction             store.ReactionStore
-	job                  store.JobStore
-	userAccessToken      store.UserAccessTokenStore
-	plugin               store.PluginStore
-	channelMemberHistory store.ChannelMemberHistoryStore
-	role                 store.RoleStore
-	scheme               store.SchemeStore
+	team                     store.TeamStore
+	channel                  store.ChannelStore
+	post                     store.PostStore
+	user                     store.UserStore
+	audit                    store.AuditStore
+	cluster                  store.ClusterDiscoveryStore
+	compliance               store.ComplianceStore
+	session                  store.SessionStore
+	oauth                    store.OAuthStore
+	system                   store.SystemStore
+	webhook                  store.WebhookStore
+	command                  store.CommandStore
+	commandWebhook           store.CommandWebhookStore
+	preference               store.PreferenceStore
+	license                  store.LicenseStore
+	token                    store.TokenStore
+	emoji                    store.EmojiStore
+	status                   store.StatusStore
+	fileInfo                 store.FileInfoStore
+	reaction                 store.ReactionStore
+	job                      store.JobStore
+	userAccessToken          store.UserAccessTokenStore
+	plugin                   store.PluginStore
+	pluginSubscription       store.PluginSubscriptionStore
+	postAcknowledgement      store.PostAcknowledgementStore
+	pluginRuntimeState       store.PluginRuntimeStateStore
+	pluginPostMetadata       store.PluginPostMetadataStore
+	pluginOAuthConnection    store.PluginOAuthConnectionStore
+	pluginAdminActionRequest store.PluginAdminActionRequestStore
+	pluginEvent              store.PluginEventStore
+	channelMemberHistory     store.ChannelMemberHistoryStore
+	role                     store.RoleStore
+	scheme                   store.SchemeStore
 }
 
 type SqlSupplier struct {
@@ -139,6 +147,13 @@ func NewSqlSupplier(settings model.SqlSettings, metrics einterfaces.MetricsInter
 	supplier.oldStores.userAccessToken = NewSqlUserAccessTokenStore(supplier)
 	supplier.oldStores.channelMemberHistory = NewSqlChannelMemberHistoryStore(supplier)
 	supplier.oldStores.plugin = NewSqlPluginStore(supplier)
+	supplier.oldStores.pluginSubscription = NewSqlPluginSubscriptionStore(supplier)
+	supplier.oldStores.postAcknowledgement = NewSqlPostAcknowledgementStore(supplier)
+	supplier.oldStores.pluginRuntimeState = NewSqlPluginRuntimeStateStore(supplier)
+	supplier.oldStores.pluginPostMetadata = NewSqlPluginPostMetadataStore(supplier)
+	supplier.oldStores.pluginOAuthConnection = NewSqlPluginOAuthConnectionStore(supplier)
+	supplier.oldStores.pluginAdminActionRequest = NewSqlPluginAdminActionRequestStore(supplier)
+	supplier.oldStores.pluginEvent = NewSqlPluginEventStore(supplier)
 
 	initSqlSupplierReactions(supplier)
 	initSqlSupplierRoles(supplier)
@@ -174,6 +189,11 @@ func NewSqlSupplier(settings model.SqlSettings, metrics einterfaces.MetricsInter
 	supplier.oldStores.job.(*SqlJobStore).CreateIndexesIfNotExists()
 	supplier.oldStores.userAccessToken.(*SqlUserAccessTokenStore).CreateIndexesIfNotExists()
 	supplier.oldStores.plugin.(*SqlPluginStore).CreateIndexesIfNotExists()
+	supplier.oldStores.pluginSubscription.(*SqlPluginSubscriptionStore).CreateIndexesIfNotExists()
+	supplier.oldStores.pluginPostMetadata.(*SqlPluginPostMetadataStore).CreateIndexesIfNotExists()
+	supplier.oldStores.pluginOAuthConnection.(*SqlPluginOAuthConnectionStore).CreateIndexesIfNotExists()
+	supplier.oldStores.pluginAdminActionRequest.(*SqlPluginAdminActionRequestStore).CreateIndexesIfNotExists()
+	supplier.oldStores.pluginEvent.(*SqlPluginEventStore).CreateIndexesIfNotExists()
 
 	supplier.oldStores.preference.(*SqlPreferenceStore).DeleteUnusedFeatures()
 
@@ -269,6 +289,12 @@ func (ss *SqlSupplier) GetCurrentSchemaVersion() string {
 	return version
 }
 
+// getPostgresServerVersion returns the version of the PostgreSQL server behind the given connection
+// as a number, such as 90500 for version 9.5.0.
+func getPostgresServerVersion(db *gorp.DbMap) (int64, error) {
+	return db.SelectInt("SELECT current_setting('server_version_num')::integer")
+}
+
 func (ss *SqlSupplier) GetMaster() *gorp.DbMap {
 	return ss.master
 }
@@ -321,6 +347,34 @@ func (ss *SqlSupplier) TotalSearchDbConnections() int {
 	return count
 }
 
+// PingMaster checks that the master database can be reached.
+func (ss *SqlSupplier) PingMaster() error {
+	return pingDbMap(ss.GetMaster())
+}
+
+// PingReplicas checks that every read replica can be reached. Without read replicas, reads are served
+// by the master, so it is checked instead.
+func (ss *SqlSupplier) PingReplicas() error {
+	if len(ss.settings.DataSourceReplicas) == 0 {
+		return ss.PingMaster()
+	}
+
+	for _, db := range ss.replicas {
+		if err := pingDbMap(db); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func pingDbMap(db *gorp.DbMap) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DB_PING_TIMEOUT_SECS*time.Second)
+	defer cancel()
+
+	return db.Db.PingContext(ctx)
+}
+
 func (ss *SqlSupplier) MarkSystemRanUnitTests() {
 	if result := <-ss.System().Get(); result.Err == nil {
 		props := result.Data.(model.StringMap)
@@ -603,6 +657,30 @@ func (ss *SqlSupplier) GetMaxLengthOfColumnIfExists(tableName string, columnName
 	return result
 }
 
+// GetColumnDataTypeIfExists returns the lower case data type of the given column, such as
+// "mediumblob", or an empty string if the column does not exist.
+func (ss *SqlSupplier) GetColumnDataTypeIfExists(tableName string, columnName string) string {
+	if !ss.DoesColumnExist(tableName, columnName) {
+		return ""
+	}
+
+	var result string
+	var err error
+	if ss.DriverName() == model.DATABASE_DRIVER_MYSQL {
+		result, err = ss.GetMaster().SelectStr("SELECT DATA_TYPE FROM information_schema.columns WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?", tableName, columnName)
+	} else if ss.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+		result, err = ss.GetMaster().SelectStr("SELECT data_type FROM information_schema.columns WHERE table_name = $1 AND column_name = $2", strings.ToLower(tableName), strings.ToLower(columnName))
+	}
+
+	if err != nil {
+		mlog.Critical(fmt.Sprintf("Failed to get data type of column %v", err))
+		time.Sleep(time.Second)
+		os.Exit(EXIT_COLUMN_DATA_TYPE)
+	}
+
+	return strings.ToLower(result)
+}
+
 func (ss *SqlSupplier) AlterColumnTypeIfExists(tableName string, columnName string, mySqlColType string, postgresColType string) bool {
 	if !ss.DoesColumnExist(tableName, columnName) {
 		return false
@@ -765,6 +843,22 @@ func (ss *SqlSupplier) RemoveIndexIfExists(indexName string, tableName string) b
 	return true
 }
 
+// isLockError returns whether the database aborted a statement because of a deadlock or a lock wait
+// timeout, after which it can be retried.
+func isLockError(err error) bool {
+	if pqErr, ok := err.(*pq.Error); ok {
+		// deadlock_detected and lock_not_available
+		return pqErr.Code == "40P01" || pqErr.Code == "55P03"
+	}
+
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+		// ER_LOCK_DEADLOCK and ER_LOCK_WAIT_TIMEOUT
+		return mysqlErr.Number == 1213 || mysqlErr.Number == 1205
+	}
+
+	return false
+}
+
 func IsUniqueConstraintError(err error, indexName []string) bool {
 	unique := false
 	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
@@ -905,6 +999,34 @@ func (ss *SqlSupplier) Plugin() store.PluginStore {
 	return ss.oldStores.plugin
 }
 
+func (ss *SqlSupplier) PluginSubscription() store.PluginSubscriptionStore {
+	return ss.oldStores.pluginSubscription
+}
+
+func (ss *SqlSupplier) PostAcknowledgement() store.PostAcknowledgementStore {
+	return ss.oldStores.postAcknowledgement
+}
+
+func (ss *SqlSupplier) PluginRuntimeState() store.PluginRuntimeStateStore {
+	return ss.oldStores.pluginRuntimeState
+}
+
+func (ss *SqlSupplier) PluginPostMetadata() store.PluginPostMetadataStore {
+	return ss.oldStores.pluginPostMetadata
+}
+
+func (ss *SqlSupplier) PluginOAuthConnection() store.PluginOAuthConnectionStore {
+	return ss.oldStores.pluginOAuthConnection
+}
+
+func (ss *SqlSupplier) PluginAdminActionRequest() store.PluginAdminActionRequestStore {
+	return ss.oldStores.pluginAdminActionRequest
+}
+
+func (ss *SqlSupplier) PluginEvent() store.PluginEventStore {
+	return ss.oldStores.pluginEvent
+}
+
 func (ss *SqlSupplier) Role() store.RoleStore {
 	return ss.oldStores.role
 }
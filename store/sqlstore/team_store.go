@@ -27,6 +27,7 @@ type teamMember struct {
 	UserId      string
 	Roles       string
 	DeleteAt    int64
+	UpdateAt    int64
 	SchemeUser  sql.NullBool
 	SchemeAdmin sql.NullBool
 }
@@ -37,6 +38,7 @@ func NewTeamMemberFromModel(tm *model.TeamMember) *teamMember {
 		UserId:      tm.UserId,
 		Roles:       tm.ExplicitRoles,
 		DeleteAt:    tm.DeleteAt,
+		UpdateAt:    tm.UpdateAt,
 		SchemeUser:  sql.NullBool{Valid: true, Bool: tm.SchemeUser},
 		SchemeAdmin: sql.NullBool{Valid: true, Bool: tm.SchemeAdmin},
 	}
@@ -47,6 +49,7 @@ type teamMemberWithSchemeRoles struct {
 	UserId                     string
 	Roles                      string
 	DeleteAt                   int64
+	UpdateAt                   int64
 	SchemeUser                 sql.NullBool
 	SchemeAdmin                sql.NullBool
 	TeamSchemeDefaultUserRole  sql.NullString
@@ -115,6 +118,7 @@ func (db teamMemberWithSchemeRoles) ToModel() *model.TeamMember {
 		UserId:        db.UserId,
 		Roles:         strings.Join(roles, " "),
 		DeleteAt:      db.DeleteAt,
+		UpdateAt:      db.UpdateAt,
 		SchemeUser:    schemeUser,
 		SchemeAdmin:   schemeAdmin,
 		ExplicitRoles: strings.Join(explicitRoles, " "),
@@ -166,6 +170,7 @@ func (s SqlTeamStore) CreateIndexesIfNotExists() {
 	s.CreateIndexIfNotExists("idx_teammembers_team_id", "TeamMembers", "TeamId")
 	s.CreateIndexIfNotExists("idx_teammembers_user_id", "TeamMembers", "UserId")
 	s.CreateIndexIfNotExists("idx_teammembers_delete_at", "TeamMembers", "DeleteAt")
+	s.CreateCompositeIndexIfNotExists("idx_teammembers_team_id_update_at", "TeamMembers", []string{"TeamId", "UpdateAt"})
 }
 
 func (s SqlTeamStore) Save(team *model.Team) store.StoreChannel {
@@ -456,6 +461,7 @@ func (s SqlTeamStore) SaveMember(member *model.TeamMember, maxUsersPerTeam int)
 			return
 		}
 
+		member.PreSave()
 		dbMember := NewTeamMemberFromModel(member)
 
 		if maxUsersPerTeam >= 0 {
@@ -554,6 +560,20 @@ func (s SqlTeamStore) GetMembers(teamId string, offset int, limit int) store.Sto
 	})
 }
 
+// GetMembersModifiedSince returns the members of the team, including those that have left it, whose
+// membership was created or updated after the given time.
+func (s SqlTeamStore) GetMembersModifiedSince(teamId string, since int64) store.StoreChannel {
+	return store.Do(func(result *store.StoreResult) {
+		var dbMembers teamMemberWithSchemeRolesList
+		_, err := s.GetReplica().Select(&dbMembers, TEAM_MEMBERS_WITH_SCHEME_SELECT_QUERY+"WHERE TeamMembers.TeamId = :TeamId AND TeamMembers.UpdateAt > :Since ORDER BY TeamMembers.UpdateAt", map[string]interface{}{"TeamId": teamId, "Since": since})
+		if err != nil {
+			result.Err = model.NewAppError("SqlTeamStore.GetMembersModifiedSince", "store.sql_team.get_members.app_error", nil, "teamId="+teamId+" "+err.Error(), http.StatusInternalServerError)
+		} else {
+			result.Data = dbMembers.ToModel()
+		}
+	})
+}
+
 func (s SqlTeamStore) GetTotalMemberCount(teamId string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		count, err := s.GetReplica().SelectInt(`
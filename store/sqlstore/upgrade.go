@@ -478,6 +478,19 @@ func UpgradeDatabaseToVersion52(sqlStore SqlStore) {
 	// if shouldPerformUpgrade(sqlStore, VERSION_5_1_0, VERSION_5_2_0) {
 	sqlStore.CreateColumnIfNotExists("OutgoingWebhooks", "Username", "varchar(64)", "varchar(64)", "")
 	sqlStore.CreateColumnIfNotExists("OutgoingWebhooks", "IconURL", "varchar(1024)", "varchar(1024)", "")
+	sqlStore.CreateColumnIfNotExists("TeamMembers", "UpdateAt", "bigint", "bigint", "0")
+	sqlStore.CreateColumnIfNotExists("PluginKeyValueStore", "ExpireAt", "bigint", "bigint", "0")
+	// Existing keys are only stored hashed, so their raw keys are recorded as they are next written.
+	sqlStore.CreateColumnIfNotExists("PluginKeyValueStore", "RawKey", "varchar(1024)", "varchar(1024)", "")
+	// Plugin keys are now stored as given rather than hashed. Existing hashed keys are moved as they
+	// are accessed.
+	if sqlStore.GetMaxLengthOfColumnIfExists("PluginKeyValueStore", "PKey") != "150" {
+		sqlStore.AlterColumnTypeIfExists("PluginKeyValueStore", "PKey", "varchar(150)", "varchar(150)")
+	}
+	// Plugin values may be up to 16MB, which needs a MEDIUMBLOB on MySQL. Postgres has always used bytea.
+	if sqlStore.DriverName() == model.DATABASE_DRIVER_MYSQL && sqlStore.GetColumnDataTypeIfExists("PluginKeyValueStore", "PValue") != "mediumblob" {
+		sqlStore.AlterColumnTypeIfExists("PluginKeyValueStore", "PValue", "mediumblob", "bytea")
+	}
 	// 	saveSchemaVersion(sqlStore, VERSION_5_2_0)
 	// }
 }
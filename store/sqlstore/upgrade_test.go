@@ -6,6 +6,9 @@ package sqlstore
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/store"
 )
@@ -39,3 +42,38 @@ func TestSaveSchemaVersion(t *testing.T) {
 		saveSchemaVersion(ss.(*store.LayeredStore).DatabaseLayer.(SqlStore), model.CurrentVersion)
 	})
 }
+
+func TestUpgradePluginKeyValueStoreValueColumn(t *testing.T) {
+	StoreTest(t, func(t *testing.T, ss store.Store) {
+		sqlStore := ss.(*store.LayeredStore).DatabaseLayer.(SqlStore)
+
+		kv := &model.PluginKeyValue{
+			PluginId: model.NewId(),
+			Key:      model.NewId(),
+			Value:    []byte(model.NewId()),
+		}
+		_, err := ss.Plugin().SaveOrUpdate(kv)
+		require.Nil(t, err)
+		defer func() {
+			ss.Plugin().Delete(kv.PluginId, kv.Key)
+		}()
+
+		expected := "bytea"
+		if sqlStore.DriverName() == model.DATABASE_DRIVER_MYSQL {
+			// Tables created by older versions may have a column too small for large values.
+			sqlStore.AlterColumnTypeIfExists("PluginKeyValueStore", "PValue", "blob", "bytea")
+			assert.Equal(t, "blob", sqlStore.GetColumnDataTypeIfExists("PluginKeyValueStore", "PValue"))
+			expected = "mediumblob"
+		}
+
+		// The upgrade may safely run more than once.
+		UpgradeDatabaseToVersion52(sqlStore)
+		UpgradeDatabaseToVersion52(sqlStore)
+
+		assert.Equal(t, expected, sqlStore.GetColumnDataTypeIfExists("PluginKeyValueStore", "PValue"))
+
+		received, err := ss.Plugin().Get(kv.PluginId, kv.Key)
+		require.Nil(t, err)
+		assert.Equal(t, kv.Value, received.Value)
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"strings"
+
+	"github.com/mattermost/gorp"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// upsertOnConflictSupported reports whether the database is PostgreSQL 9.5 or newer, and so supports
+// INSERT ... ON CONFLICT. A warning is logged if the version of PostgreSQL cannot be determined.
+func upsertOnConflictSupported(sqlStore SqlStore, table string) bool {
+	if sqlStore.DriverName() != model.DATABASE_DRIVER_POSTGRES {
+		return false
+	}
+
+	version, err := getPostgresServerVersion(sqlStore.GetMaster())
+	if err != nil {
+		mlog.Warn("Failed to get the PostgreSQL server version, so rows will be saved without INSERT ... ON CONFLICT", mlog.String("table", table), mlog.Err(err))
+		return false
+	}
+
+	return version >= POSTGRES_ON_CONFLICT_MIN_VERSION
+}
+
+// upsertTable describes a table whose rows are saved with upsertRow.
+type upsertTable struct {
+	Name string
+
+	// KeyColumns make up the primary key of the table, and Columns are the columns replaced when a
+	// row with the same key is saved. Each column is bound to the parameter of the same name.
+	KeyColumns []string
+	Columns    []string
+
+	// UniqueConstraintNames identify violations of the primary key, as for IsUniqueConstraintError.
+	UniqueConstraintNames []string
+}
+
+// query returns a single statement inserting a row, or replacing the row with the same key.
+func (t upsertTable) query(driverName string) string {
+	columns := append(append([]string{}, t.KeyColumns...), t.Columns...)
+	query := "INSERT INTO " + t.Name + " (" + strings.Join(columns, ", ") + ") VALUES(:" + strings.Join(columns, ", :") + ")"
+
+	updates := make([]string, len(t.Columns))
+	for i, column := range t.Columns {
+		if driverName == model.DATABASE_DRIVER_MYSQL {
+			updates[i] = column + " = VALUES(" + column + ")"
+		} else {
+			updates[i] = column + " = EXCLUDED." + column
+		}
+	}
+
+	if driverName == model.DATABASE_DRIVER_MYSQL {
+		return query + " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+	}
+	return query + " ON CONFLICT (" + strings.Join(t.KeyColumns, ", ") + ") DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+// upsertRow inserts row, a value of the type mapped to the table, or replaces the row with the same
+// key, binding params to the table's columns. MySQL and PostgreSQL 9.5 or newer do so in a single
+// statement. Older versions of PostgreSQL update the row, insert it if it is missing, and update it
+// again if a concurrent save inserted it first.
+func upsertRow(executor gorp.SqlExecutor, driverName string, upsertOnConflict bool, table upsertTable, row interface{}, params map[string]interface{}) error {
+	if driverName == model.DATABASE_DRIVER_MYSQL || upsertOnConflict {
+		_, err := executor.Exec(table.query(driverName), params)
+		return err
+	}
+
+	if rowsAffected, err := executor.Update(row); err != nil || rowsAffected > 0 {
+		return err
+	}
+
+	if err := executor.Insert(row); err == nil || !IsUniqueConstraintError(err, table.UniqueConstraintNames) {
+		return err
+	}
+
+	_, err := executor.Update(row)
+	return err
+}
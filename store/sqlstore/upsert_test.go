@@ -0,0 +1,23 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestUpsertTableQuery(t *testing.T) {
+	table := upsertTable{
+		Name:       "Things",
+		KeyColumns: []string{"Id", "Kind"},
+		Columns:    []string{"Value", "UpdateAt"},
+	}
+
+	assert.Equal(t, "INSERT INTO Things (Id, Kind, Value, UpdateAt) VALUES(:Id, :Kind, :Value, :UpdateAt) ON DUPLICATE KEY UPDATE Value = VALUES(Value), UpdateAt = VALUES(UpdateAt)", table.query(model.DATABASE_DRIVER_MYSQL))
+	assert.Equal(t, "INSERT INTO Things (Id, Kind, Value, UpdateAt) VALUES(:Id, :Kind, :Value, :UpdateAt) ON CONFLICT (Id, Kind) DO UPDATE SET Value = EXCLUDED.Value, UpdateAt = EXCLUDED.UpdateAt", table.query(model.DATABASE_DRIVER_POSTGRES))
+}
@@ -65,6 +65,13 @@ type Store interface {
 	UserAccessToken() UserAccessTokenStore
 	ChannelMemberHistory() ChannelMemberHistoryStore
 	Plugin() PluginStore
+	PluginSubscription() PluginSubscriptionStore
+	PluginRuntimeState() PluginRuntimeStateStore
+	PluginPostMetadata() PluginPostMetadataStore
+	PluginOAuthConnection() PluginOAuthConnectionStore
+	PluginAdminActionRequest() PluginAdminActionRequestStore
+	PluginEvent() PluginEventStore
+	PostAcknowledgement() PostAcknowledgementStore
 	MarkSystemRanUnitTests()
 	Close()
 	LockToMaster()
@@ -73,6 +80,8 @@ type Store interface {
 	TotalMasterDbConnections() int
 	TotalReadDbConnections() int
 	TotalSearchDbConnections() int
+	PingMaster() error
+	PingReplicas() error
 }
 
 type TeamStore interface {
@@ -96,6 +105,7 @@ type TeamStore interface {
 	UpdateMember(member *model.TeamMember) StoreChannel
 	GetMember(teamId string, userId string) StoreChannel
 	GetMembers(teamId string, offset int, limit int) StoreChannel
+	GetMembersModifiedSince(teamId string, since int64) StoreChannel
 	GetMembersByIds(teamId string, userIds []string) StoreChannel
 	GetTotalMemberCount(teamId string) StoreChannel
 	GetActiveMemberCount(teamId string) StoreChannel
@@ -142,6 +152,7 @@ type ChannelStore interface {
 	SaveMember(member *model.ChannelMember) StoreChannel
 	UpdateMember(member *model.ChannelMember) StoreChannel
 	GetMembers(channelId string, offset, limit int) StoreChannel
+	GetMembersModifiedSince(channelId string, since int64) StoreChannel
 	GetMember(channelId string, userId string) StoreChannel
 	GetAllChannelMembersForUser(userId string, allowFromCache bool, includeDeleted bool) StoreChannel
 	InvalidateAllChannelMembersForUser(userId string)
@@ -177,6 +188,7 @@ type ChannelMemberHistoryStore interface {
 	LogJoinEvent(userId string, channelId string, joinTime int64) StoreChannel
 	LogLeaveEvent(userId string, channelId string, leaveTime int64) StoreChannel
 	GetUsersInChannelDuring(startTime int64, endTime int64, channelId string) StoreChannel
+	GetUsersLeftSince(channelId string, since int64) StoreChannel
 	PermanentDeleteBatch(endTime int64, limit int64) StoreChannel
 }
 
@@ -205,7 +217,9 @@ type PostStore interface {
 	GetPostsCreatedAt(channelId string, time int64) StoreChannel
 	Overwrite(post *model.Post) StoreChannel
 	GetPostsByIds(postIds []string) StoreChannel
+	GetPostsWithFileId(channelId string, fileId string) StoreChannel
 	GetPostsBatchForIndexing(startTime int64, endTime int64, limit int) StoreChannel
+	GetPostsBatchForChannelExport(channelId string, afterCreateAt int64, afterId string, limit int) StoreChannel
 	PermanentDeleteBatch(endTime int64, limit int64) StoreChannel
 	GetOldest() StoreChannel
 	GetMaxPostSize() StoreChannel
@@ -386,9 +400,11 @@ type PreferenceStore interface {
 	Get(userId string, category string, name string) StoreChannel
 	GetCategory(userId string, category string) StoreChannel
 	GetAll(userId string) StoreChannel
+	GetCategoryAndName(category string, name string) StoreChannel
 	Delete(userId, category, name string) StoreChannel
 	DeleteCategory(userId string, category string) StoreChannel
 	DeleteCategoryAndName(category string, name string) StoreChannel
+	DeleteAllInCategory(category string) StoreChannel
 	PermanentDeleteByUser(userId string) StoreChannel
 	IsFeatureEnabled(feature, userId string) StoreChannel
 	CleanupFlagsBatch(limit int64) StoreChannel
@@ -479,9 +495,85 @@ type UserAccessTokenStore interface {
 }
 
 type PluginStore interface {
-	SaveOrUpdate(keyVal *model.PluginKeyValue) StoreChannel
-	Get(pluginId, key string) StoreChannel
-	Delete(pluginId, key string) StoreChannel
+	SaveOrUpdate(keyVal *model.PluginKeyValue) (*model.PluginKeyValue, *model.AppError)
+	SaveOrUpdateMultiple(keyVals []*model.PluginKeyValue) ([]*model.PluginKeyValue, *model.AppError)
+	SaveOrUpdateMany(pluginId string, ops []model.PluginKVOp) ([]model.PluginKVOp, *model.AppError)
+	CompareAndSet(keyVal *model.PluginKeyValue, oldValue []byte) (bool, *model.AppError)
+	Get(pluginId, key string) (*model.PluginKeyValue, *model.AppError)
+	GetFromMaster(pluginId, key string) (*model.PluginKeyValue, *model.AppError)
+	GetMultiple(pluginId string, keys []string) ([]*model.PluginKeyValue, *model.AppError)
+	GetMultipleFromMaster(pluginId string, keys []string) ([]*model.PluginKeyValue, *model.AppError)
+	List(pluginId string, offset, limit int) ([]string, *model.AppError)
+	ListWithPrefix(pluginId, prefix string, offset, limit int) ([]string, *model.AppError)
+	GetAll(offset, limit int) ([]*model.PluginKeyValue, *model.AppError)
+	GetAllForPlugin(pluginId string, offset, limit int) ([]*model.PluginKeyValue, *model.AppError)
+	Delete(pluginId, key string) (bool, *model.AppError)
+	CompareAndDelete(pluginId, key string, oldValue []byte) (bool, *model.AppError)
+	Copy(pluginId, key, newKey string) (bool, *model.AppError)
+	Move(pluginId, key, newKey string) (bool, *model.AppError)
+	GetHashedKeys(afterPluginId, afterKey string, limit int) ([]*model.PluginKeyValue, *model.AppError)
+	MoveHashedKey(pluginId, hashedKey, key string) (bool, *model.AppError)
+	CountHashedKeys() (*model.PluginKeyValueMigrationStatus, *model.AppError)
+	Increment(pluginId, key string, delta int64) (int64, *model.AppError)
+	DeleteAllForPlugin(pluginId string) (int64, *model.AppError)
+	GetUsage(pluginId string) (*model.PluginKeyValueUsage, *model.AppError)
+	GetUsageFromMaster(pluginId string) (*model.PluginKeyValueUsage, *model.AppError)
+	DeleteAllExpired() (int64, *model.AppError)
+	ClearCaches()
+}
+
+type PluginSubscriptionStore interface {
+	Save(subscription *model.PluginSubscription) StoreChannel
+	Get(id string) StoreChannel
+	GetForChannel(channelId string) StoreChannel
+	Delete(id string) StoreChannel
+	DeleteAllForPlugin(pluginId string) StoreChannel
+}
+
+type PostAcknowledgementStore interface {
+	Save(acknowledgement *model.PostAcknowledgement) StoreChannel
+	Get(postId, userId string) StoreChannel
+	GetForPost(postId string) StoreChannel
+	DeleteAllForPost(postId string) StoreChannel
+}
+
+type PluginRuntimeStateStore interface {
+	Save(state *model.PluginRuntimeState) StoreChannel
+	Get(pluginId, clusterId string) StoreChannel
+	GetAll() StoreChannel
+	Touch(clusterId string, updateAt int64) StoreChannel
+	DeleteAllForPlugin(pluginId string) StoreChannel
+	DeleteStale(updateBefore int64) StoreChannel
+}
+
+type PluginPostMetadataStore interface {
+	Save(metadata *model.PluginPostMetadata) StoreChannel
+	Get(postId, namespace string) StoreChannel
+	GetForPlugin(pluginId string, limit int) StoreChannel
+	Delete(postId, namespace string) StoreChannel
+}
+
+type PluginOAuthConnectionStore interface {
+	Save(connection *model.PluginOAuthConnection) StoreChannel
+	Get(pluginId, userId string) StoreChannel
+	Delete(pluginId, userId string) StoreChannel
+	DeleteAllForPlugin(pluginId string) StoreChannel
+	DeleteAllForUser(userId string) StoreChannel
+}
+
+type PluginAdminActionRequestStore interface {
+	Save(request *model.PluginAdminActionRequest) StoreChannel
+	Get(id string) StoreChannel
+	GetPending(now int64, offset, limit int) StoreChannel
+	GetExpired(now int64, limit int) StoreChannel
+	UpdateStatus(request *model.PluginAdminActionRequest, oldStatus string) StoreChannel
+	DeleteAllForPlugin(pluginId string) StoreChannel
+}
+
+type PluginEventStore interface {
+	Save(event *model.PluginEvent) StoreChannel
+	GetForPlugin(pluginId string, since int64, offset, limit int) StoreChannel
+	DeleteBefore(before int64, limit int) StoreChannel
 }
 
 type RoleStore interface {
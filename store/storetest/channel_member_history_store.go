@@ -19,6 +19,7 @@ func TestChannelMemberHistoryStore(t *testing.T, ss store.Store) {
 	t.Run("TestGetUsersInChannelAtChannelMemberHistory", func(t *testing.T) { testGetUsersInChannelAtChannelMemberHistory(t, ss) })
 	t.Run("TestGetUsersInChannelAtChannelMembers", func(t *testing.T) { testGetUsersInChannelAtChannelMembers(t, ss) })
 	t.Run("TestPermanentDeleteBatch", func(t *testing.T) { testPermanentDeleteBatch(t, ss) })
+	t.Run("TestGetUsersLeftSince", func(t *testing.T) { testGetUsersLeftSince(t, ss) })
 }
 
 func testLogJoinEvent(t *testing.T, ss store.Store) {
@@ -70,6 +71,32 @@ func testLogLeaveEvent(t *testing.T, ss store.Store) {
 	assert.Nil(t, result.Err)
 }
 
+func testGetUsersLeftSince(t *testing.T, ss store.Store) {
+	channelId := model.NewId()
+	userId1 := model.NewId()
+	userId2 := model.NewId()
+	userId3 := model.NewId()
+
+	since := model.GetMillis() - 1000
+
+	// userId1 left before the given time, userId2 after it, and userId3 is still present
+	store.Must(ss.ChannelMemberHistory().LogJoinEvent(userId1, channelId, since-200))
+	store.Must(ss.ChannelMemberHistory().LogLeaveEvent(userId1, channelId, since-100))
+	store.Must(ss.ChannelMemberHistory().LogJoinEvent(userId2, channelId, since-100))
+	store.Must(ss.ChannelMemberHistory().LogLeaveEvent(userId2, channelId, since+100))
+	store.Must(ss.ChannelMemberHistory().LogJoinEvent(userId3, channelId, since+100))
+
+	// userId2 leaving again is only reported once
+	store.Must(ss.ChannelMemberHistory().LogJoinEvent(userId2, channelId, since+200))
+	store.Must(ss.ChannelMemberHistory().LogLeaveEvent(userId2, channelId, since+300))
+
+	userIds := store.Must(ss.ChannelMemberHistory().GetUsersLeftSince(channelId, since)).([]string)
+	assert.Equal(t, []string{userId2}, userIds)
+
+	userIds = store.Must(ss.ChannelMemberHistory().GetUsersLeftSince(channelId, since+300)).([]string)
+	assert.Len(t, userIds, 0)
+}
+
 func testGetUsersInChannelAtChannelMemberHistory(t *testing.T, ss store.Store) {
 	// create a test channel
 	channel := model.Channel{
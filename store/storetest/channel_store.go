@@ -49,6 +49,7 @@ func TestChannelStore(t *testing.T, ss store.Store) {
 	t.Run("SearchMore", func(t *testing.T) { testChannelStoreSearchMore(t, ss) })
 	t.Run("SearchInTeam", func(t *testing.T) { testChannelStoreSearchInTeam(t, ss) })
 	t.Run("GetMembersByIds", func(t *testing.T) { testChannelStoreGetMembersByIds(t, ss) })
+	t.Run("GetMembersModifiedSince", func(t *testing.T) { testChannelStoreGetMembersModifiedSince(t, ss) })
 	t.Run("AnalyticsDeletedTypeCount", func(t *testing.T) { testChannelStoreAnalyticsDeletedTypeCount(t, ss) })
 	t.Run("GetPinnedPosts", func(t *testing.T) { testChannelStoreGetPinnedPosts(t, ss) })
 	t.Run("MaxChannelsPerTeam", func(t *testing.T) { testChannelStoreMaxChannelsPerTeam(t, ss) })
@@ -1425,6 +1426,49 @@ func testUpdateChannelMember(t *testing.T, ss store.Store) {
 	}
 }
 
+func testChannelStoreGetMembersModifiedSince(t *testing.T, ss store.Store) {
+	channel := store.Must(ss.Channel().Save(&model.Channel{
+		TeamId:      model.NewId(),
+		DisplayName: model.NewId(),
+		Name:        "zz" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	}, -1)).(*model.Channel)
+
+	m1 := store.Must(ss.Channel().SaveMember(&model.ChannelMember{
+		ChannelId:   channel.Id,
+		UserId:      model.NewId(),
+		NotifyProps: model.GetDefaultChannelNotifyProps(),
+	})).(*model.ChannelMember)
+	time.Sleep(2 * time.Millisecond)
+	m2 := store.Must(ss.Channel().SaveMember(&model.ChannelMember{
+		ChannelId:   channel.Id,
+		UserId:      model.NewId(),
+		NotifyProps: model.GetDefaultChannelNotifyProps(),
+	})).(*model.ChannelMember)
+
+	members := store.Must(ss.Channel().GetMembersModifiedSince(channel.Id, 0)).(*model.ChannelMembers)
+	require.Len(t, *members, 2)
+	assert.Equal(t, m1.UserId, (*members)[0].UserId)
+	assert.Equal(t, m2.UserId, (*members)[1].UserId)
+
+	members = store.Must(ss.Channel().GetMembersModifiedSince(channel.Id, m1.LastUpdateAt)).(*model.ChannelMembers)
+	require.Len(t, *members, 1)
+	assert.Equal(t, m2.UserId, (*members)[0].UserId)
+
+	// Changing roles is a modification
+	time.Sleep(2 * time.Millisecond)
+	m1.SchemeAdmin = true
+	m1 = store.Must(ss.Channel().UpdateMember(m1)).(*model.ChannelMember)
+
+	members = store.Must(ss.Channel().GetMembersModifiedSince(channel.Id, m2.LastUpdateAt)).(*model.ChannelMembers)
+	require.Len(t, *members, 1)
+	assert.Equal(t, m1.UserId, (*members)[0].UserId)
+	assert.True(t, (*members)[0].SchemeAdmin)
+
+	members = store.Must(ss.Channel().GetMembersModifiedSince(channel.Id, m1.LastUpdateAt)).(*model.ChannelMembers)
+	assert.Len(t, *members, 0)
+}
+
 func testGetMember(t *testing.T, ss store.Store) {
 	userId := model.NewId()
 
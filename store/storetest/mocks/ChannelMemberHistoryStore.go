@@ -28,6 +28,22 @@ func (_m *ChannelMemberHistoryStore) GetUsersInChannelDuring(startTime int64, en
 	return r0
 }
 
+// GetUsersLeftSince provides a mock function with given fields: channelId, since
+func (_m *ChannelMemberHistoryStore) GetUsersLeftSince(channelId string, since int64) store.StoreChannel {
+	ret := _m.Called(channelId, since)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int64) store.StoreChannel); ok {
+		r0 = rf(channelId, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // LogJoinEvent provides a mock function with given fields: userId, channelId, joinTime
 func (_m *ChannelMemberHistoryStore) LogJoinEvent(userId string, channelId string, joinTime int64) store.StoreChannel {
 	ret := _m.Called(userId, channelId, joinTime)
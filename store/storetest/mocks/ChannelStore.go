@@ -464,6 +464,22 @@ func (_m *ChannelStore) GetMembersForUser(teamId string, userId string) store.St
 	return r0
 }
 
+// GetMembersModifiedSince provides a mock function with given fields: channelId, since
+func (_m *ChannelStore) GetMembersModifiedSince(channelId string, since int64) store.StoreChannel {
+	ret := _m.Called(channelId, since)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int64) store.StoreChannel); ok {
+		r0 = rf(channelId, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // GetMoreChannels provides a mock function with given fields: teamId, userId, offset, limit
 func (_m *ChannelStore) GetMoreChannels(teamId string, userId string, offset int, limit int) store.StoreChannel {
 	ret := _m.Called(teamId, userId, offset, limit)
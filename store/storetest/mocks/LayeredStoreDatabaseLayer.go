@@ -242,6 +242,34 @@ func (_m *LayeredStoreDatabaseLayer) OAuth() store.OAuthStore {
 	return r0
 }
 
+// PingMaster provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) PingMaster() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PingReplicas provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) PingReplicas() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Plugin provides a mock function with given fields:
 func (_m *LayeredStoreDatabaseLayer) Plugin() store.PluginStore {
 	ret := _m.Called()
@@ -258,6 +286,102 @@ func (_m *LayeredStoreDatabaseLayer) Plugin() store.PluginStore {
 	return r0
 }
 
+// PluginAdminActionRequest provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) PluginAdminActionRequest() store.PluginAdminActionRequestStore {
+	ret := _m.Called()
+
+	var r0 store.PluginAdminActionRequestStore
+	if rf, ok := ret.Get(0).(func() store.PluginAdminActionRequestStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PluginAdminActionRequestStore)
+		}
+	}
+
+	return r0
+}
+
+// PluginEvent provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) PluginEvent() store.PluginEventStore {
+	ret := _m.Called()
+
+	var r0 store.PluginEventStore
+	if rf, ok := ret.Get(0).(func() store.PluginEventStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PluginEventStore)
+		}
+	}
+
+	return r0
+}
+
+// PluginOAuthConnection provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) PluginOAuthConnection() store.PluginOAuthConnectionStore {
+	ret := _m.Called()
+
+	var r0 store.PluginOAuthConnectionStore
+	if rf, ok := ret.Get(0).(func() store.PluginOAuthConnectionStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PluginOAuthConnectionStore)
+		}
+	}
+
+	return r0
+}
+
+// PluginPostMetadata provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) PluginPostMetadata() store.PluginPostMetadataStore {
+	ret := _m.Called()
+
+	var r0 store.PluginPostMetadataStore
+	if rf, ok := ret.Get(0).(func() store.PluginPostMetadataStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PluginPostMetadataStore)
+		}
+	}
+
+	return r0
+}
+
+// PluginRuntimeState provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) PluginRuntimeState() store.PluginRuntimeStateStore {
+	ret := _m.Called()
+
+	var r0 store.PluginRuntimeStateStore
+	if rf, ok := ret.Get(0).(func() store.PluginRuntimeStateStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PluginRuntimeStateStore)
+		}
+	}
+
+	return r0
+}
+
+// PluginSubscription provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) PluginSubscription() store.PluginSubscriptionStore {
+	ret := _m.Called()
+
+	var r0 store.PluginSubscriptionStore
+	if rf, ok := ret.Get(0).(func() store.PluginSubscriptionStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PluginSubscriptionStore)
+		}
+	}
+
+	return r0
+}
+
 // Post provides a mock function with given fields:
 func (_m *LayeredStoreDatabaseLayer) Post() store.PostStore {
 	ret := _m.Called()
@@ -274,6 +398,22 @@ func (_m *LayeredStoreDatabaseLayer) Post() store.PostStore {
 	return r0
 }
 
+// PostAcknowledgement provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) PostAcknowledgement() store.PostAcknowledgementStore {
+	ret := _m.Called()
+
+	var r0 store.PostAcknowledgementStore
+	if rf, ok := ret.Get(0).(func() store.PostAcknowledgementStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PostAcknowledgementStore)
+		}
+	}
+
+	return r0
+}
+
 // Preference provides a mock function with given fields:
 func (_m *LayeredStoreDatabaseLayer) Preference() store.PreferenceStore {
 	ret := _m.Called()
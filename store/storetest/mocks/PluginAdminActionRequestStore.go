@@ -0,0 +1,110 @@
+// Code generated by mockery v1.0.0
+
+// Regenerate this file using `make store-mocks`.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+import model "github.com/mattermost/mattermost-server/model"
+import store "github.com/mattermost/mattermost-server/store"
+
+// PluginAdminActionRequestStore is an autogenerated mock type for the PluginAdminActionRequestStore type
+type PluginAdminActionRequestStore struct {
+	mock.Mock
+}
+
+// DeleteAllForPlugin provides a mock function with given fields: pluginId
+func (_m *PluginAdminActionRequestStore) DeleteAllForPlugin(pluginId string) store.StoreChannel {
+	ret := _m.Called(pluginId)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(pluginId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: id
+func (_m *PluginAdminActionRequestStore) Get(id string) store.StoreChannel {
+	ret := _m.Called(id)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetExpired provides a mock function with given fields: now, limit
+func (_m *PluginAdminActionRequestStore) GetExpired(now int64, limit int) store.StoreChannel {
+	ret := _m.Called(now, limit)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(int64, int) store.StoreChannel); ok {
+		r0 = rf(now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetPending provides a mock function with given fields: now, offset, limit
+func (_m *PluginAdminActionRequestStore) GetPending(now int64, offset int, limit int) store.StoreChannel {
+	ret := _m.Called(now, offset, limit)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(int64, int, int) store.StoreChannel); ok {
+		r0 = rf(now, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// Save provides a mock function with given fields: request
+func (_m *PluginAdminActionRequestStore) Save(request *model.PluginAdminActionRequest) store.StoreChannel {
+	ret := _m.Called(request)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(*model.PluginAdminActionRequest) store.StoreChannel); ok {
+		r0 = rf(request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// UpdateStatus provides a mock function with given fields: request, oldStatus
+func (_m *PluginAdminActionRequestStore) UpdateStatus(request *model.PluginAdminActionRequest, oldStatus string) store.StoreChannel {
+	ret := _m.Called(request, oldStatus)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(*model.PluginAdminActionRequest, string) store.StoreChannel); ok {
+		r0 = rf(request, oldStatus)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
@@ -0,0 +1,62 @@
+// Code generated by mockery v1.0.0
+
+// Regenerate this file using `make store-mocks`.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+import model "github.com/mattermost/mattermost-server/model"
+import store "github.com/mattermost/mattermost-server/store"
+
+// PluginEventStore is an autogenerated mock type for the PluginEventStore type
+type PluginEventStore struct {
+	mock.Mock
+}
+
+// DeleteBefore provides a mock function with given fields: before, limit
+func (_m *PluginEventStore) DeleteBefore(before int64, limit int) store.StoreChannel {
+	ret := _m.Called(before, limit)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(int64, int) store.StoreChannel); ok {
+		r0 = rf(before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetForPlugin provides a mock function with given fields: pluginId, since, offset, limit
+func (_m *PluginEventStore) GetForPlugin(pluginId string, since int64, offset int, limit int) store.StoreChannel {
+	ret := _m.Called(pluginId, since, offset, limit)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int64, int, int) store.StoreChannel); ok {
+		r0 = rf(pluginId, since, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// Save provides a mock function with given fields: event
+func (_m *PluginEventStore) Save(event *model.PluginEvent) store.StoreChannel {
+	ret := _m.Called(event)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(*model.PluginEvent) store.StoreChannel); ok {
+		r0 = rf(event)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
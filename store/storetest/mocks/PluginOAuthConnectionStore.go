@@ -0,0 +1,94 @@
+// Code generated by mockery v1.0.0
+
+// Regenerate this file using `make store-mocks`.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+import model "github.com/mattermost/mattermost-server/model"
+import store "github.com/mattermost/mattermost-server/store"
+
+// PluginOAuthConnectionStore is an autogenerated mock type for the PluginOAuthConnectionStore type
+type PluginOAuthConnectionStore struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: pluginId, userId
+func (_m *PluginOAuthConnectionStore) Delete(pluginId string, userId string) store.StoreChannel {
+	ret := _m.Called(pluginId, userId)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, string) store.StoreChannel); ok {
+		r0 = rf(pluginId, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// DeleteAllForPlugin provides a mock function with given fields: pluginId
+func (_m *PluginOAuthConnectionStore) DeleteAllForPlugin(pluginId string) store.StoreChannel {
+	ret := _m.Called(pluginId)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(pluginId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// DeleteAllForUser provides a mock function with given fields: userId
+func (_m *PluginOAuthConnectionStore) DeleteAllForUser(userId string) store.StoreChannel {
+	ret := _m.Called(userId)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: pluginId, userId
+func (_m *PluginOAuthConnectionStore) Get(pluginId string, userId string) store.StoreChannel {
+	ret := _m.Called(pluginId, userId)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, string) store.StoreChannel); ok {
+		r0 = rf(pluginId, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// Save provides a mock function with given fields: connection
+func (_m *PluginOAuthConnectionStore) Save(connection *model.PluginOAuthConnection) store.StoreChannel {
+	ret := _m.Called(connection)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(*model.PluginOAuthConnection) store.StoreChannel); ok {
+		r0 = rf(connection)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
@@ -0,0 +1,78 @@
+// Code generated by mockery v1.0.0
+
+// Regenerate this file using `make store-mocks`.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+import model "github.com/mattermost/mattermost-server/model"
+import store "github.com/mattermost/mattermost-server/store"
+
+// PluginPostMetadataStore is an autogenerated mock type for the PluginPostMetadataStore type
+type PluginPostMetadataStore struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: postId, namespace
+func (_m *PluginPostMetadataStore) Delete(postId string, namespace string) store.StoreChannel {
+	ret := _m.Called(postId, namespace)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, string) store.StoreChannel); ok {
+		r0 = rf(postId, namespace)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: postId, namespace
+func (_m *PluginPostMetadataStore) Get(postId string, namespace string) store.StoreChannel {
+	ret := _m.Called(postId, namespace)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, string) store.StoreChannel); ok {
+		r0 = rf(postId, namespace)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetForPlugin provides a mock function with given fields: pluginId, limit
+func (_m *PluginPostMetadataStore) GetForPlugin(pluginId string, limit int) store.StoreChannel {
+	ret := _m.Called(pluginId, limit)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int) store.StoreChannel); ok {
+		r0 = rf(pluginId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// Save provides a mock function with given fields: metadata
+func (_m *PluginPostMetadataStore) Save(metadata *model.PluginPostMetadata) store.StoreChannel {
+	ret := _m.Called(metadata)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(*model.PluginPostMetadata) store.StoreChannel); ok {
+		r0 = rf(metadata)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
@@ -0,0 +1,110 @@
+// Code generated by mockery v1.0.0
+
+// Regenerate this file using `make store-mocks`.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+import model "github.com/mattermost/mattermost-server/model"
+import store "github.com/mattermost/mattermost-server/store"
+
+// PluginRuntimeStateStore is an autogenerated mock type for the PluginRuntimeStateStore type
+type PluginRuntimeStateStore struct {
+	mock.Mock
+}
+
+// DeleteAllForPlugin provides a mock function with given fields: pluginId
+func (_m *PluginRuntimeStateStore) DeleteAllForPlugin(pluginId string) store.StoreChannel {
+	ret := _m.Called(pluginId)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(pluginId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// DeleteStale provides a mock function with given fields: updateBefore
+func (_m *PluginRuntimeStateStore) DeleteStale(updateBefore int64) store.StoreChannel {
+	ret := _m.Called(updateBefore)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(int64) store.StoreChannel); ok {
+		r0 = rf(updateBefore)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: pluginId, clusterId
+func (_m *PluginRuntimeStateStore) Get(pluginId string, clusterId string) store.StoreChannel {
+	ret := _m.Called(pluginId, clusterId)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, string) store.StoreChannel); ok {
+		r0 = rf(pluginId, clusterId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields:
+func (_m *PluginRuntimeStateStore) GetAll() store.StoreChannel {
+	ret := _m.Called()
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func() store.StoreChannel); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// Save provides a mock function with given fields: state
+func (_m *PluginRuntimeStateStore) Save(state *model.PluginRuntimeState) store.StoreChannel {
+	ret := _m.Called(state)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(*model.PluginRuntimeState) store.StoreChannel); ok {
+		r0 = rf(state)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// Touch provides a mock function with given fields: clusterId, updateAt
+func (_m *PluginRuntimeStateStore) Touch(clusterId string, updateAt int64) store.StoreChannel {
+	ret := _m.Called(clusterId, updateAt)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int64) store.StoreChannel); ok {
+		r0 = rf(clusterId, updateAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
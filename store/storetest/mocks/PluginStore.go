@@ -6,57 +6,595 @@ package mocks
 
 import mock "github.com/stretchr/testify/mock"
 import model "github.com/mattermost/mattermost-server/model"
-import store "github.com/mattermost/mattermost-server/store"
 
 // PluginStore is an autogenerated mock type for the PluginStore type
 type PluginStore struct {
 	mock.Mock
 }
 
+// ClearCaches provides a mock function with given fields:
+func (_m *PluginStore) ClearCaches() {
+	_m.Called()
+}
+
+// CompareAndDelete provides a mock function with given fields: pluginId, key, oldValue
+func (_m *PluginStore) CompareAndDelete(pluginId string, key string, oldValue []byte) (bool, *model.AppError) {
+	ret := _m.Called(pluginId, key, oldValue)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string, []byte) bool); ok {
+		r0 = rf(pluginId, key, oldValue)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string, []byte) *model.AppError); ok {
+		r1 = rf(pluginId, key, oldValue)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// CompareAndSet provides a mock function with given fields: keyVal, oldValue
+func (_m *PluginStore) CompareAndSet(keyVal *model.PluginKeyValue, oldValue []byte) (bool, *model.AppError) {
+	ret := _m.Called(keyVal, oldValue)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.PluginKeyValue, []byte) bool); ok {
+		r0 = rf(keyVal, oldValue)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(*model.PluginKeyValue, []byte) *model.AppError); ok {
+		r1 = rf(keyVal, oldValue)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Copy provides a mock function with given fields: pluginId, key, newKey
+func (_m *PluginStore) Copy(pluginId string, key string, newKey string) (bool, *model.AppError) {
+	ret := _m.Called(pluginId, key, newKey)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string, string) bool); ok {
+		r0 = rf(pluginId, key, newKey)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string, string) *model.AppError); ok {
+		r1 = rf(pluginId, key, newKey)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// CountHashedKeys provides a mock function with given fields:
+func (_m *PluginStore) CountHashedKeys() (*model.PluginKeyValueMigrationStatus, *model.AppError) {
+	ret := _m.Called()
+
+	var r0 *model.PluginKeyValueMigrationStatus
+	if rf, ok := ret.Get(0).(func() *model.PluginKeyValueMigrationStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PluginKeyValueMigrationStatus)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func() *model.AppError); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: pluginId, key
-func (_m *PluginStore) Delete(pluginId string, key string) store.StoreChannel {
+func (_m *PluginStore) Delete(pluginId string, key string) (bool, *model.AppError) {
 	ret := _m.Called(pluginId, key)
 
-	var r0 store.StoreChannel
-	if rf, ok := ret.Get(0).(func(string, string) store.StoreChannel); ok {
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
 		r0 = rf(pluginId, key)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(store.StoreChannel)
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string) *model.AppError); ok {
+		r1 = rf(pluginId, key)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// DeleteAllExpired provides a mock function with given fields:
+func (_m *PluginStore) DeleteAllExpired() (int64, *model.AppError) {
+	ret := _m.Called()
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func() *model.AppError); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// DeleteAllForPlugin provides a mock function with given fields: pluginId
+func (_m *PluginStore) DeleteAllForPlugin(pluginId string) (int64, *model.AppError) {
+	ret := _m.Called(pluginId)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(pluginId)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(pluginId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
 		}
 	}
 
-	return r0
+	return r0, r1
 }
 
 // Get provides a mock function with given fields: pluginId, key
-func (_m *PluginStore) Get(pluginId string, key string) store.StoreChannel {
+func (_m *PluginStore) Get(pluginId string, key string) (*model.PluginKeyValue, *model.AppError) {
 	ret := _m.Called(pluginId, key)
 
-	var r0 store.StoreChannel
-	if rf, ok := ret.Get(0).(func(string, string) store.StoreChannel); ok {
+	var r0 *model.PluginKeyValue
+	if rf, ok := ret.Get(0).(func(string, string) *model.PluginKeyValue); ok {
 		r0 = rf(pluginId, key)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(store.StoreChannel)
+			r0 = ret.Get(0).(*model.PluginKeyValue)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string) *model.AppError); ok {
+		r1 = rf(pluginId, key)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields: offset, limit
+func (_m *PluginStore) GetAll(offset int, limit int) ([]*model.PluginKeyValue, *model.AppError) {
+	ret := _m.Called(offset, limit)
+
+	var r0 []*model.PluginKeyValue
+	if rf, ok := ret.Get(0).(func(int, int) []*model.PluginKeyValue); ok {
+		r0 = rf(offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PluginKeyValue)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(int, int) *model.AppError); ok {
+		r1 = rf(offset, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetAllForPlugin provides a mock function with given fields: pluginId, offset, limit
+func (_m *PluginStore) GetAllForPlugin(pluginId string, offset int, limit int) ([]*model.PluginKeyValue, *model.AppError) {
+	ret := _m.Called(pluginId, offset, limit)
+
+	var r0 []*model.PluginKeyValue
+	if rf, ok := ret.Get(0).(func(string, int, int) []*model.PluginKeyValue); ok {
+		r0 = rf(pluginId, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PluginKeyValue)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, int, int) *model.AppError); ok {
+		r1 = rf(pluginId, offset, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetFromMaster provides a mock function with given fields: pluginId, key
+func (_m *PluginStore) GetFromMaster(pluginId string, key string) (*model.PluginKeyValue, *model.AppError) {
+	ret := _m.Called(pluginId, key)
+
+	var r0 *model.PluginKeyValue
+	if rf, ok := ret.Get(0).(func(string, string) *model.PluginKeyValue); ok {
+		r0 = rf(pluginId, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PluginKeyValue)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string) *model.AppError); ok {
+		r1 = rf(pluginId, key)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetHashedKeys provides a mock function with given fields: afterPluginId, afterKey, limit
+func (_m *PluginStore) GetHashedKeys(afterPluginId string, afterKey string, limit int) ([]*model.PluginKeyValue, *model.AppError) {
+	ret := _m.Called(afterPluginId, afterKey, limit)
+
+	var r0 []*model.PluginKeyValue
+	if rf, ok := ret.Get(0).(func(string, string, int) []*model.PluginKeyValue); ok {
+		r0 = rf(afterPluginId, afterKey, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PluginKeyValue)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string, int) *model.AppError); ok {
+		r1 = rf(afterPluginId, afterKey, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetMultiple provides a mock function with given fields: pluginId, keys
+func (_m *PluginStore) GetMultiple(pluginId string, keys []string) ([]*model.PluginKeyValue, *model.AppError) {
+	ret := _m.Called(pluginId, keys)
+
+	var r0 []*model.PluginKeyValue
+	if rf, ok := ret.Get(0).(func(string, []string) []*model.PluginKeyValue); ok {
+		r0 = rf(pluginId, keys)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PluginKeyValue)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, []string) *model.AppError); ok {
+		r1 = rf(pluginId, keys)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetMultipleFromMaster provides a mock function with given fields: pluginId, keys
+func (_m *PluginStore) GetMultipleFromMaster(pluginId string, keys []string) ([]*model.PluginKeyValue, *model.AppError) {
+	ret := _m.Called(pluginId, keys)
+
+	var r0 []*model.PluginKeyValue
+	if rf, ok := ret.Get(0).(func(string, []string) []*model.PluginKeyValue); ok {
+		r0 = rf(pluginId, keys)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PluginKeyValue)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, []string) *model.AppError); ok {
+		r1 = rf(pluginId, keys)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetUsage provides a mock function with given fields: pluginId
+func (_m *PluginStore) GetUsage(pluginId string) (*model.PluginKeyValueUsage, *model.AppError) {
+	ret := _m.Called(pluginId)
+
+	var r0 *model.PluginKeyValueUsage
+	if rf, ok := ret.Get(0).(func(string) *model.PluginKeyValueUsage); ok {
+		r0 = rf(pluginId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PluginKeyValueUsage)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(pluginId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetUsageFromMaster provides a mock function with given fields: pluginId
+func (_m *PluginStore) GetUsageFromMaster(pluginId string) (*model.PluginKeyValueUsage, *model.AppError) {
+	ret := _m.Called(pluginId)
+
+	var r0 *model.PluginKeyValueUsage
+	if rf, ok := ret.Get(0).(func(string) *model.PluginKeyValueUsage); ok {
+		r0 = rf(pluginId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PluginKeyValueUsage)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string) *model.AppError); ok {
+		r1 = rf(pluginId)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Increment provides a mock function with given fields: pluginId, key, delta
+func (_m *PluginStore) Increment(pluginId string, key string, delta int64) (int64, *model.AppError) {
+	ret := _m.Called(pluginId, key, delta)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(string, string, int64) int64); ok {
+		r0 = rf(pluginId, key, delta)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string, int64) *model.AppError); ok {
+		r1 = rf(pluginId, key, delta)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: pluginId, offset, limit
+func (_m *PluginStore) List(pluginId string, offset int, limit int) ([]string, *model.AppError) {
+	ret := _m.Called(pluginId, offset, limit)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string, int, int) []string); ok {
+		r0 = rf(pluginId, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, int, int) *model.AppError); ok {
+		r1 = rf(pluginId, offset, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// ListWithPrefix provides a mock function with given fields: pluginId, prefix, offset, limit
+func (_m *PluginStore) ListWithPrefix(pluginId string, prefix string, offset int, limit int) ([]string, *model.AppError) {
+	ret := _m.Called(pluginId, prefix, offset, limit)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string, string, int, int) []string); ok {
+		r0 = rf(pluginId, prefix, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string, int, int) *model.AppError); ok {
+		r1 = rf(pluginId, prefix, offset, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// Move provides a mock function with given fields: pluginId, key, newKey
+func (_m *PluginStore) Move(pluginId string, key string, newKey string) (bool, *model.AppError) {
+	ret := _m.Called(pluginId, key, newKey)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string, string) bool); ok {
+		r0 = rf(pluginId, key, newKey)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string, string) *model.AppError); ok {
+		r1 = rf(pluginId, key, newKey)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
 		}
 	}
 
-	return r0
+	return r0, r1
+}
+
+// MoveHashedKey provides a mock function with given fields: pluginId, hashedKey, key
+func (_m *PluginStore) MoveHashedKey(pluginId string, hashedKey string, key string) (bool, *model.AppError) {
+	ret := _m.Called(pluginId, hashedKey, key)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string, string) bool); ok {
+		r0 = rf(pluginId, hashedKey, key)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string, string) *model.AppError); ok {
+		r1 = rf(pluginId, hashedKey, key)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
 }
 
 // SaveOrUpdate provides a mock function with given fields: keyVal
-func (_m *PluginStore) SaveOrUpdate(keyVal *model.PluginKeyValue) store.StoreChannel {
+func (_m *PluginStore) SaveOrUpdate(keyVal *model.PluginKeyValue) (*model.PluginKeyValue, *model.AppError) {
 	ret := _m.Called(keyVal)
 
-	var r0 store.StoreChannel
-	if rf, ok := ret.Get(0).(func(*model.PluginKeyValue) store.StoreChannel); ok {
+	var r0 *model.PluginKeyValue
+	if rf, ok := ret.Get(0).(func(*model.PluginKeyValue) *model.PluginKeyValue); ok {
 		r0 = rf(keyVal)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(store.StoreChannel)
+			r0 = ret.Get(0).(*model.PluginKeyValue)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(*model.PluginKeyValue) *model.AppError); ok {
+		r1 = rf(keyVal)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// SaveOrUpdateMany provides a mock function with given fields: pluginId, ops
+func (_m *PluginStore) SaveOrUpdateMany(pluginId string, ops []model.PluginKVOp) ([]model.PluginKVOp, *model.AppError) {
+	ret := _m.Called(pluginId, ops)
+
+	var r0 []model.PluginKVOp
+	if rf, ok := ret.Get(0).(func(string, []model.PluginKVOp) []model.PluginKVOp); ok {
+		r0 = rf(pluginId, ops)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.PluginKVOp)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, []model.PluginKVOp) *model.AppError); ok {
+		r1 = rf(pluginId, ops)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// SaveOrUpdateMultiple provides a mock function with given fields: keyVals
+func (_m *PluginStore) SaveOrUpdateMultiple(keyVals []*model.PluginKeyValue) ([]*model.PluginKeyValue, *model.AppError) {
+	ret := _m.Called(keyVals)
+
+	var r0 []*model.PluginKeyValue
+	if rf, ok := ret.Get(0).(func([]*model.PluginKeyValue) []*model.PluginKeyValue); ok {
+		r0 = rf(keyVals)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PluginKeyValue)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func([]*model.PluginKeyValue) *model.AppError); ok {
+		r1 = rf(keyVals)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
 		}
 	}
 
-	return r0
+	return r0, r1
 }
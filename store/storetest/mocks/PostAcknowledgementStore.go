@@ -0,0 +1,78 @@
+// Code generated by mockery v1.0.0
+
+// Regenerate this file using `make store-mocks`.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+import model "github.com/mattermost/mattermost-server/model"
+import store "github.com/mattermost/mattermost-server/store"
+
+// PostAcknowledgementStore is an autogenerated mock type for the PostAcknowledgementStore type
+type PostAcknowledgementStore struct {
+	mock.Mock
+}
+
+// DeleteAllForPost provides a mock function with given fields: postId
+func (_m *PostAcknowledgementStore) DeleteAllForPost(postId string) store.StoreChannel {
+	ret := _m.Called(postId)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(postId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: postId, userId
+func (_m *PostAcknowledgementStore) Get(postId string, userId string) store.StoreChannel {
+	ret := _m.Called(postId, userId)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, string) store.StoreChannel); ok {
+		r0 = rf(postId, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// GetForPost provides a mock function with given fields: postId
+func (_m *PostAcknowledgementStore) GetForPost(postId string) store.StoreChannel {
+	ret := _m.Called(postId)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(postId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
+// Save provides a mock function with given fields: acknowledgement
+func (_m *PostAcknowledgementStore) Save(acknowledgement *model.PostAcknowledgement) store.StoreChannel {
+	ret := _m.Called(acknowledgement)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(*model.PostAcknowledgement) store.StoreChannel); ok {
+		r0 = rf(acknowledgement)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
@@ -226,6 +226,22 @@ func (_m *PostStore) GetPostsAfter(channelId string, postId string, numPosts int
 	return r0
 }
 
+// GetPostsBatchForChannelExport provides a mock function with given fields: channelId, afterCreateAt, afterId, limit
+func (_m *PostStore) GetPostsBatchForChannelExport(channelId string, afterCreateAt int64, afterId string, limit int) store.StoreChannel {
+	ret := _m.Called(channelId, afterCreateAt, afterId, limit)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, int64, string, int) store.StoreChannel); ok {
+		r0 = rf(channelId, afterCreateAt, afterId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // GetPostsBatchForIndexing provides a mock function with given fields: startTime, endTime, limit
 func (_m *PostStore) GetPostsBatchForIndexing(startTime int64, endTime int64, limit int) store.StoreChannel {
 	ret := _m.Called(startTime, endTime, limit)
@@ -306,6 +322,22 @@ func (_m *PostStore) GetPostsSince(channelId string, time int64, allowFromCache
 	return r0
 }
 
+// GetPostsWithFileId provides a mock function with given fields: channelId, fileId
+func (_m *PostStore) GetPostsWithFileId(channelId string, fileId string) store.StoreChannel {
+	ret := _m.Called(channelId, fileId)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, string) store.StoreChannel); ok {
+		r0 = rf(channelId, fileId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // GetSingle provides a mock function with given fields: id
 func (_m *PostStore) GetSingle(id string) store.StoreChannel {
 	ret := _m.Called(id)
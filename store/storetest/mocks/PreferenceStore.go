@@ -61,6 +61,22 @@ func (_m *PreferenceStore) DeleteCategory(userId string, category string) store.
 	return r0
 }
 
+// DeleteAllInCategory provides a mock function with given fields: category
+func (_m *PreferenceStore) DeleteAllInCategory(category string) store.StoreChannel {
+	ret := _m.Called(category)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string) store.StoreChannel); ok {
+		r0 = rf(category)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // DeleteCategoryAndName provides a mock function with given fields: category, name
 func (_m *PreferenceStore) DeleteCategoryAndName(category string, name string) store.StoreChannel {
 	ret := _m.Called(category, name)
@@ -125,6 +141,22 @@ func (_m *PreferenceStore) GetCategory(userId string, category string) store.Sto
 	return r0
 }
 
+// GetCategoryAndName provides a mock function with given fields: category, name
+func (_m *PreferenceStore) GetCategoryAndName(category string, name string) store.StoreChannel {
+	ret := _m.Called(category, name)
+
+	var r0 store.StoreChannel
+	if rf, ok := ret.Get(0).(func(string, string) store.StoreChannel); ok {
+		r0 = rf(category, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.StoreChannel)
+		}
+	}
+
+	return r0
+}
+
 // IsFeatureEnabled provides a mock function with given fields: feature, userId
 func (_m *PreferenceStore) IsFeatureEnabled(feature string, userId string) store.StoreChannel {
 	ret := _m.Called(feature, userId)
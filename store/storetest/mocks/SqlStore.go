@@ -303,6 +303,20 @@ func (_m *SqlStore) GetAllConns() []*gorp.DbMap {
 	return r0
 }
 
+// GetColumnDataTypeIfExists provides a mock function with given fields: tableName, columnName
+func (_m *SqlStore) GetColumnDataTypeIfExists(tableName string, columnName string) string {
+	ret := _m.Called(tableName, columnName)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(tableName, columnName)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // GetCurrentSchemaVersion provides a mock function with given fields:
 func (_m *SqlStore) GetCurrentSchemaVersion() string {
 	ret := _m.Called()
@@ -453,6 +467,102 @@ func (_m *SqlStore) Plugin() store.PluginStore {
 	return r0
 }
 
+// PluginAdminActionRequest provides a mock function with given fields:
+func (_m *SqlStore) PluginAdminActionRequest() store.PluginAdminActionRequestStore {
+	ret := _m.Called()
+
+	var r0 store.PluginAdminActionRequestStore
+	if rf, ok := ret.Get(0).(func() store.PluginAdminActionRequestStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PluginAdminActionRequestStore)
+		}
+	}
+
+	return r0
+}
+
+// PluginEvent provides a mock function with given fields:
+func (_m *SqlStore) PluginEvent() store.PluginEventStore {
+	ret := _m.Called()
+
+	var r0 store.PluginEventStore
+	if rf, ok := ret.Get(0).(func() store.PluginEventStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PluginEventStore)
+		}
+	}
+
+	return r0
+}
+
+// PluginOAuthConnection provides a mock function with given fields:
+func (_m *SqlStore) PluginOAuthConnection() store.PluginOAuthConnectionStore {
+	ret := _m.Called()
+
+	var r0 store.PluginOAuthConnectionStore
+	if rf, ok := ret.Get(0).(func() store.PluginOAuthConnectionStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PluginOAuthConnectionStore)
+		}
+	}
+
+	return r0
+}
+
+// PluginPostMetadata provides a mock function with given fields:
+func (_m *SqlStore) PluginPostMetadata() store.PluginPostMetadataStore {
+	ret := _m.Called()
+
+	var r0 store.PluginPostMetadataStore
+	if rf, ok := ret.Get(0).(func() store.PluginPostMetadataStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PluginPostMetadataStore)
+		}
+	}
+
+	return r0
+}
+
+// PluginRuntimeState provides a mock function with given fields:
+func (_m *SqlStore) PluginRuntimeState() store.PluginRuntimeStateStore {
+	ret := _m.Called()
+
+	var r0 store.PluginRuntimeStateStore
+	if rf, ok := ret.Get(0).(func() store.PluginRuntimeStateStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PluginRuntimeStateStore)
+		}
+	}
+
+	return r0
+}
+
+// PluginSubscription provides a mock function with given fields:
+func (_m *SqlStore) PluginSubscription() store.PluginSubscriptionStore {
+	ret := _m.Called()
+
+	var r0 store.PluginSubscriptionStore
+	if rf, ok := ret.Get(0).(func() store.PluginSubscriptionStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PluginSubscriptionStore)
+		}
+	}
+
+	return r0
+}
+
 // Post provides a mock function with given fields:
 func (_m *SqlStore) Post() store.PostStore {
 	ret := _m.Called()
@@ -469,6 +579,22 @@ func (_m *SqlStore) Post() store.PostStore {
 	return r0
 }
 
+// PostAcknowledgement provides a mock function with given fields:
+func (_m *SqlStore) PostAcknowledgement() store.PostAcknowledgementStore {
+	ret := _m.Called()
+
+	var r0 store.PostAcknowledgementStore
+	if rf, ok := ret.Get(0).(func() store.PostAcknowledgementStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PostAcknowledgementStore)
+		}
+	}
+
+	return r0
+}
+
 // Preference provides a mock function with given fields:
 func (_m *SqlStore) Preference() store.PreferenceStore {
 	ret := _m.Called()
@@ -0,0 +1,150 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package storetest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginAdminActionRequestStore(t *testing.T, ss store.Store) {
+	t.Run("PluginAdminActionRequestSaveGet", func(t *testing.T) { testPluginAdminActionRequestSaveGet(t, ss) })
+	t.Run("PluginAdminActionRequestGetPendingAndExpired", func(t *testing.T) { testPluginAdminActionRequestGetPendingAndExpired(t, ss) })
+	t.Run("PluginAdminActionRequestUpdateStatus", func(t *testing.T) { testPluginAdminActionRequestUpdateStatus(t, ss) })
+	t.Run("PluginAdminActionRequestDeleteAllForPlugin", func(t *testing.T) { testPluginAdminActionRequestDeleteAllForPlugin(t, ss) })
+}
+
+func savePluginAdminActionRequest(t *testing.T, ss store.Store, pluginId string, expireAt int64) *model.PluginAdminActionRequest {
+	result := <-ss.PluginAdminActionRequest().Save(&model.PluginAdminActionRequest{
+		PluginId: pluginId,
+		Action:   model.PLUGIN_ADMIN_ACTION_DELETE_USER,
+		Params:   model.StringMap{model.PLUGIN_ADMIN_ACTION_PARAM_USER_ID: model.NewId()},
+		CreateAt: expireAt - 1000,
+		ExpireAt: expireAt,
+	})
+	require.Nil(t, result.Err)
+	return result.Data.(*model.PluginAdminActionRequest)
+}
+
+// pluginAdminActionRequestIds returns the ids of the given plugin's requests among those returned by
+// the store, which may include other tests' requests.
+func pluginAdminActionRequestIds(result store.StoreResult, pluginId string) []string {
+	ids := []string{}
+	for _, request := range result.Data.([]*model.PluginAdminActionRequest) {
+		if request.PluginId == pluginId {
+			ids = append(ids, request.Id)
+		}
+	}
+	return ids
+}
+
+func testPluginAdminActionRequestSaveGet(t *testing.T, ss store.Store) {
+	request := &model.PluginAdminActionRequest{
+		PluginId:    model.NewId(),
+		Action:      model.PLUGIN_ADMIN_ACTION_UPDATE_CONFIG,
+		Params:      model.StringMap{model.PLUGIN_ADMIN_ACTION_PARAM_CONFIG: `{"TeamSettings": {"MaxUsersPerTeam": 100}}`},
+		Description: "Allow larger teams",
+		ExpireAt:    model.GetMillis() + 60*1000,
+	}
+	result := <-ss.PluginAdminActionRequest().Save(request)
+	require.Nil(t, result.Err)
+	assert.Len(t, request.Id, 26)
+	assert.NotZero(t, request.CreateAt)
+	assert.Equal(t, model.PLUGIN_ADMIN_ACTION_STATUS_PENDING, request.Status)
+
+	result = <-ss.PluginAdminActionRequest().Get(request.Id)
+	require.Nil(t, result.Err)
+	assert.Equal(t, request, result.Data.(*model.PluginAdminActionRequest))
+
+	result = <-ss.PluginAdminActionRequest().Get(model.NewId())
+	require.NotNil(t, result.Err)
+	assert.Equal(t, http.StatusNotFound, result.Err.StatusCode)
+
+	// Only allowed actions can be saved
+	result = <-ss.PluginAdminActionRequest().Save(&model.PluginAdminActionRequest{
+		PluginId: model.NewId(),
+		Action:   "run_command",
+		Params:   model.StringMap{"command": "rm -rf /"},
+		ExpireAt: model.GetMillis() + 60*1000,
+	})
+	assert.NotNil(t, result.Err)
+}
+
+func testPluginAdminActionRequestGetPendingAndExpired(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	now := model.GetMillis()
+
+	expired := savePluginAdminActionRequest(t, ss, pluginId, now-1000)
+	pending1 := savePluginAdminActionRequest(t, ss, pluginId, now+1000)
+	pending2 := savePluginAdminActionRequest(t, ss, pluginId, now+2000)
+
+	denied := savePluginAdminActionRequest(t, ss, pluginId, now+3000)
+	denied.Status = model.PLUGIN_ADMIN_ACTION_STATUS_DENIED
+	result := <-ss.PluginAdminActionRequest().UpdateStatus(denied, model.PLUGIN_ADMIN_ACTION_STATUS_PENDING)
+	require.Nil(t, result.Err)
+
+	result = <-ss.PluginAdminActionRequest().GetPending(now, 0, 10000)
+	require.Nil(t, result.Err)
+	assert.Equal(t, []string{pending1.Id, pending2.Id}, pluginAdminActionRequestIds(result, pluginId))
+
+	result = <-ss.PluginAdminActionRequest().GetExpired(now, 10000)
+	require.Nil(t, result.Err)
+	assert.Equal(t, []string{expired.Id}, pluginAdminActionRequestIds(result, pluginId))
+
+	result = <-ss.PluginAdminActionRequest().GetExpired(now+1500, 10000)
+	require.Nil(t, result.Err)
+	assert.Equal(t, []string{expired.Id, pending1.Id}, pluginAdminActionRequestIds(result, pluginId))
+}
+
+func testPluginAdminActionRequestUpdateStatus(t *testing.T, ss store.Store) {
+	request := savePluginAdminActionRequest(t, ss, model.NewId(), model.GetMillis()+60*1000)
+
+	request.Status = model.PLUGIN_ADMIN_ACTION_STATUS_APPROVED
+	request.ResolvedBy = model.NewId()
+	request.ResolveAt = model.GetMillis()
+	result := <-ss.PluginAdminActionRequest().UpdateStatus(request, model.PLUGIN_ADMIN_ACTION_STATUS_PENDING)
+	require.Nil(t, result.Err)
+	assert.True(t, result.Data.(bool))
+
+	// A request already resolved is not resolved again
+	denied := *request
+	denied.Status = model.PLUGIN_ADMIN_ACTION_STATUS_DENIED
+	result = <-ss.PluginAdminActionRequest().UpdateStatus(&denied, model.PLUGIN_ADMIN_ACTION_STATUS_PENDING)
+	require.Nil(t, result.Err)
+	assert.False(t, result.Data.(bool))
+
+	request.Status = model.PLUGIN_ADMIN_ACTION_STATUS_FAILED
+	request.Error = "failed"
+	result = <-ss.PluginAdminActionRequest().UpdateStatus(request, model.PLUGIN_ADMIN_ACTION_STATUS_APPROVED)
+	require.Nil(t, result.Err)
+	assert.True(t, result.Data.(bool))
+
+	result = <-ss.PluginAdminActionRequest().Get(request.Id)
+	require.Nil(t, result.Err)
+	assert.Equal(t, request, result.Data.(*model.PluginAdminActionRequest))
+}
+
+func testPluginAdminActionRequestDeleteAllForPlugin(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	otherPluginId := model.NewId()
+	expireAt := model.GetMillis() + 60*1000
+
+	request := savePluginAdminActionRequest(t, ss, pluginId, expireAt)
+	otherRequest := savePluginAdminActionRequest(t, ss, otherPluginId, expireAt)
+
+	result := <-ss.PluginAdminActionRequest().DeleteAllForPlugin(pluginId)
+	require.Nil(t, result.Err)
+
+	result = <-ss.PluginAdminActionRequest().Get(request.Id)
+	require.NotNil(t, result.Err)
+	assert.Equal(t, http.StatusNotFound, result.Err.StatusCode)
+
+	result = <-ss.PluginAdminActionRequest().Get(otherRequest.Id)
+	assert.Nil(t, result.Err)
+}
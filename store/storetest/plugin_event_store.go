@@ -0,0 +1,88 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package storetest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginEventStore(t *testing.T, ss store.Store) {
+	t.Run("PluginEventSaveGetForPlugin", func(t *testing.T) { testPluginEventSaveGetForPlugin(t, ss) })
+	t.Run("PluginEventDeleteBefore", func(t *testing.T) { testPluginEventDeleteBefore(t, ss) })
+}
+
+func savePluginEvent(t *testing.T, ss store.Store, pluginId, eventType string, createAt int64) *model.PluginEvent {
+	result := <-ss.PluginEvent().Save(&model.PluginEvent{
+		PluginId: pluginId,
+		Type:     eventType,
+		CreateAt: createAt,
+	})
+	require.Nil(t, result.Err)
+	return result.Data.(*model.PluginEvent)
+}
+
+func testPluginEventSaveGetForPlugin(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+
+	event := &model.PluginEvent{
+		PluginId:  pluginId,
+		Type:      model.PLUGIN_EVENT_FAILED,
+		ClusterId: model.NewId(),
+		Details:   strings.Repeat("x", model.PLUGIN_EVENT_DETAILS_MAX_RUNES+1),
+	}
+	result := <-ss.PluginEvent().Save(event)
+	require.Nil(t, result.Err)
+	assert.Len(t, event.Id, 26)
+	assert.NotZero(t, event.CreateAt)
+	assert.Len(t, event.Details, model.PLUGIN_EVENT_DETAILS_MAX_RUNES)
+
+	older := savePluginEvent(t, ss, pluginId, model.PLUGIN_EVENT_INSTALLED, event.CreateAt-2000)
+	newer := savePluginEvent(t, ss, pluginId, model.PLUGIN_EVENT_ACTIVATED, event.CreateAt+2000)
+	savePluginEvent(t, ss, model.NewId(), model.PLUGIN_EVENT_ACTIVATED, event.CreateAt)
+
+	result = <-ss.PluginEvent().GetForPlugin(pluginId, 0, 0, 10)
+	require.Nil(t, result.Err)
+	assert.Equal(t, []*model.PluginEvent{older, event, newer}, result.Data.([]*model.PluginEvent))
+
+	result = <-ss.PluginEvent().GetForPlugin(pluginId, event.CreateAt, 0, 10)
+	require.Nil(t, result.Err)
+	assert.Equal(t, []*model.PluginEvent{event, newer}, result.Data.([]*model.PluginEvent))
+
+	result = <-ss.PluginEvent().GetForPlugin(pluginId, 0, 1, 1)
+	require.Nil(t, result.Err)
+	assert.Equal(t, []*model.PluginEvent{event}, result.Data.([]*model.PluginEvent))
+
+	// Only known event types can be saved
+	result = <-ss.PluginEvent().Save(&model.PluginEvent{PluginId: pluginId, Type: "exploded"})
+	assert.NotNil(t, result.Err)
+}
+
+func testPluginEventDeleteBefore(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+
+	// Far enough in the past not to be affected by other tests' events.
+	cutoff := int64(1000000)
+	for i := int64(0); i < 3; i++ {
+		savePluginEvent(t, ss, pluginId, model.PLUGIN_EVENT_DEACTIVATED, cutoff-10+i)
+	}
+	kept := savePluginEvent(t, ss, pluginId, model.PLUGIN_EVENT_ACTIVATED, cutoff)
+
+	result := <-ss.PluginEvent().DeleteBefore(cutoff, 2)
+	require.Nil(t, result.Err)
+	assert.Equal(t, int64(2), result.Data.(int64))
+
+	result = <-ss.PluginEvent().DeleteBefore(cutoff, 2)
+	require.Nil(t, result.Err)
+	assert.Equal(t, int64(1), result.Data.(int64))
+
+	result = <-ss.PluginEvent().GetForPlugin(pluginId, 0, 0, 10)
+	require.Nil(t, result.Err)
+	assert.Equal(t, []*model.PluginEvent{kept}, result.Data.([]*model.PluginEvent))
+}
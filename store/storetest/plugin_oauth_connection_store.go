@@ -0,0 +1,139 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package storetest
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginOAuthConnectionStore(t *testing.T, ss store.Store) {
+	t.Run("PluginOAuthConnectionSaveGetDelete", func(t *testing.T) { testPluginOAuthConnectionSaveGetDelete(t, ss) })
+	t.Run("PluginOAuthConnectionDeleteAllForPlugin", func(t *testing.T) { testPluginOAuthConnectionDeleteAllForPlugin(t, ss) })
+	t.Run("PluginOAuthConnectionDeleteAllForUser", func(t *testing.T) { testPluginOAuthConnectionDeleteAllForUser(t, ss) })
+}
+
+func savePluginOAuthConnection(t *testing.T, ss store.Store, pluginId, userId string) {
+	result := <-ss.PluginOAuthConnection().Save(&model.PluginOAuthConnection{PluginId: pluginId, UserId: userId, Token: "token"})
+	require.Nil(t, result.Err)
+}
+
+func hasPluginOAuthConnection(t *testing.T, ss store.Store, pluginId, userId string) bool {
+	result := <-ss.PluginOAuthConnection().Get(pluginId, userId)
+	if result.Err != nil {
+		require.Equal(t, http.StatusNotFound, result.Err.StatusCode)
+		return false
+	}
+	return true
+}
+
+func testPluginOAuthConnectionSaveGetDelete(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	userId := model.NewId()
+
+	result := <-ss.PluginOAuthConnection().Get(pluginId, userId)
+	require.NotNil(t, result.Err)
+	assert.Equal(t, http.StatusNotFound, result.Err.StatusCode)
+
+	connection := &model.PluginOAuthConnection{
+		PluginId: pluginId,
+		UserId:   userId,
+		Token:    "token",
+	}
+	result = <-ss.PluginOAuthConnection().Save(connection)
+	require.Nil(t, result.Err)
+	assert.NotZero(t, connection.UpdateAt)
+
+	result = <-ss.PluginOAuthConnection().Get(pluginId, userId)
+	require.Nil(t, result.Err)
+	assert.Equal(t, connection, result.Data.(*model.PluginOAuthConnection))
+
+	// Saving again replaces the existing token
+	connection = &model.PluginOAuthConnection{
+		PluginId: pluginId,
+		UserId:   userId,
+		Token:    "refreshed",
+	}
+	result = <-ss.PluginOAuthConnection().Save(connection)
+	require.Nil(t, result.Err)
+
+	result = <-ss.PluginOAuthConnection().Get(pluginId, userId)
+	require.Nil(t, result.Err)
+	assert.Equal(t, "refreshed", result.Data.(*model.PluginOAuthConnection).Token)
+
+	result = <-ss.PluginOAuthConnection().Save(&model.PluginOAuthConnection{PluginId: pluginId, UserId: userId})
+	assert.NotNil(t, result.Err)
+
+	// Concurrent saves all succeed, leaving one of their tokens
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	for i := range tokens {
+		tokens[i] = model.NewId()
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			result := <-ss.PluginOAuthConnection().Save(&model.PluginOAuthConnection{PluginId: pluginId, UserId: userId, Token: token})
+			assert.Nil(t, result.Err)
+		}(tokens[i])
+	}
+	wg.Wait()
+
+	result = <-ss.PluginOAuthConnection().Get(pluginId, userId)
+	require.Nil(t, result.Err)
+	assert.Contains(t, tokens, result.Data.(*model.PluginOAuthConnection).Token)
+
+	result = <-ss.PluginOAuthConnection().Delete(pluginId, userId)
+	require.Nil(t, result.Err)
+	assert.False(t, hasPluginOAuthConnection(t, ss, pluginId, userId))
+
+	// Deleting a connection that does not exist is not an error
+	result = <-ss.PluginOAuthConnection().Delete(pluginId, userId)
+	require.Nil(t, result.Err)
+}
+
+func testPluginOAuthConnectionDeleteAllForPlugin(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	otherPluginId := model.NewId()
+	userId := model.NewId()
+	otherUserId := model.NewId()
+
+	for _, id := range []string{pluginId, otherPluginId} {
+		savePluginOAuthConnection(t, ss, id, userId)
+		savePluginOAuthConnection(t, ss, id, otherUserId)
+	}
+
+	result := <-ss.PluginOAuthConnection().DeleteAllForPlugin(pluginId)
+	require.Nil(t, result.Err)
+
+	assert.False(t, hasPluginOAuthConnection(t, ss, pluginId, userId))
+	assert.False(t, hasPluginOAuthConnection(t, ss, pluginId, otherUserId))
+	assert.True(t, hasPluginOAuthConnection(t, ss, otherPluginId, userId))
+	assert.True(t, hasPluginOAuthConnection(t, ss, otherPluginId, otherUserId))
+}
+
+func testPluginOAuthConnectionDeleteAllForUser(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	otherPluginId := model.NewId()
+	userId := model.NewId()
+	otherUserId := model.NewId()
+
+	for _, id := range []string{pluginId, otherPluginId} {
+		savePluginOAuthConnection(t, ss, id, userId)
+		savePluginOAuthConnection(t, ss, id, otherUserId)
+	}
+
+	result := <-ss.PluginOAuthConnection().DeleteAllForUser(userId)
+	require.Nil(t, result.Err)
+
+	assert.False(t, hasPluginOAuthConnection(t, ss, pluginId, userId))
+	assert.False(t, hasPluginOAuthConnection(t, ss, otherPluginId, userId))
+	assert.True(t, hasPluginOAuthConnection(t, ss, pluginId, otherUserId))
+	assert.True(t, hasPluginOAuthConnection(t, ss, otherPluginId, otherUserId))
+}
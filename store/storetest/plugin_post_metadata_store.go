@@ -0,0 +1,122 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package storetest
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginPostMetadataStore(t *testing.T, ss store.Store) {
+	t.Run("PluginPostMetadataSaveGetDelete", func(t *testing.T) { testPluginPostMetadataSaveGetDelete(t, ss) })
+	t.Run("PluginPostMetadataGetForPlugin", func(t *testing.T) { testPluginPostMetadataGetForPlugin(t, ss) })
+}
+
+func testPluginPostMetadataSaveGetDelete(t *testing.T, ss store.Store) {
+	postId := model.NewId()
+
+	result := <-ss.PluginPostMetadata().Get(postId, "translations")
+	require.NotNil(t, result.Err)
+	assert.Equal(t, http.StatusNotFound, result.Err.StatusCode)
+
+	metadata := &model.PluginPostMetadata{
+		PostId:    postId,
+		Namespace: "translations",
+		PluginId:  "com.example.plugin",
+	}
+	result = <-ss.PluginPostMetadata().Save(metadata)
+	require.Nil(t, result.Err)
+	assert.NotZero(t, metadata.UpdateAt)
+
+	result = <-ss.PluginPostMetadata().Get(postId, "translations")
+	require.Nil(t, result.Err)
+	assert.Equal(t, metadata, result.Data.(*model.PluginPostMetadata))
+
+	// Saving again replaces the existing record
+	metadata = &model.PluginPostMetadata{
+		PostId:    postId,
+		Namespace: "translations",
+		PluginId:  "com.example.other",
+	}
+	result = <-ss.PluginPostMetadata().Save(metadata)
+	require.Nil(t, result.Err)
+
+	result = <-ss.PluginPostMetadata().Get(postId, "translations")
+	require.Nil(t, result.Err)
+	assert.Equal(t, metadata, result.Data.(*model.PluginPostMetadata))
+
+	result = <-ss.PluginPostMetadata().Save(&model.PluginPostMetadata{PostId: postId, Namespace: "with space", PluginId: "com.example.plugin"})
+	assert.NotNil(t, result.Err)
+
+	// Concurrent saves all succeed, leaving one of their records
+	var wg sync.WaitGroup
+	pluginIds := make([]string, 10)
+	for i := range pluginIds {
+		pluginIds[i] = "com.example." + model.NewId()
+		wg.Add(1)
+		go func(pluginId string) {
+			defer wg.Done()
+			result := <-ss.PluginPostMetadata().Save(&model.PluginPostMetadata{PostId: postId, Namespace: "translations", PluginId: pluginId})
+			assert.Nil(t, result.Err)
+		}(pluginIds[i])
+	}
+	wg.Wait()
+
+	result = <-ss.PluginPostMetadata().Get(postId, "translations")
+	require.Nil(t, result.Err)
+	assert.Contains(t, pluginIds, result.Data.(*model.PluginPostMetadata).PluginId)
+
+	result = <-ss.PluginPostMetadata().Delete(postId, "translations")
+	require.Nil(t, result.Err)
+
+	result = <-ss.PluginPostMetadata().Get(postId, "translations")
+	require.NotNil(t, result.Err)
+	assert.Equal(t, http.StatusNotFound, result.Err.StatusCode)
+
+	// Deleting a missing record is not an error.
+	result = <-ss.PluginPostMetadata().Delete(postId, "translations")
+	assert.Nil(t, result.Err)
+}
+
+func testPluginPostMetadataGetForPlugin(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	otherPluginId := model.NewId()
+
+	var expected []string
+	for i := 0; i < 3; i++ {
+		postId := model.NewId()
+		for _, namespace := range []string{"a", "b"} {
+			store.Must(ss.PluginPostMetadata().Save(&model.PluginPostMetadata{PostId: postId, Namespace: namespace, PluginId: pluginId}))
+			expected = append(expected, postId+"/"+namespace)
+		}
+		store.Must(ss.PluginPostMetadata().Save(&model.PluginPostMetadata{PostId: postId, Namespace: "other", PluginId: otherPluginId}))
+	}
+	sort.Strings(expected)
+
+	getForPlugin := func(limit int) []string {
+		var keys []string
+		for _, metadata := range store.Must(ss.PluginPostMetadata().GetForPlugin(pluginId, limit)).([]*model.PluginPostMetadata) {
+			assert.Equal(t, pluginId, metadata.PluginId)
+			keys = append(keys, metadata.PostId+"/"+metadata.Namespace)
+		}
+		return keys
+	}
+
+	assert.Equal(t, expected, getForPlugin(100))
+	assert.Equal(t, expected[:4], getForPlugin(4))
+
+	for _, key := range expected[:4] {
+		store.Must(ss.PluginPostMetadata().Delete(key[:26], key[27:]))
+	}
+	assert.Equal(t, expected[4:], getForPlugin(4))
+
+	assert.Empty(t, store.Must(ss.PluginPostMetadata().GetForPlugin(model.NewId(), 100)).([]*model.PluginPostMetadata))
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package storetest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginRuntimeStateStore(t *testing.T, ss store.Store) {
+	t.Run("PluginRuntimeStateSaveGet", func(t *testing.T) { testPluginRuntimeStateSaveGet(t, ss) })
+	t.Run("PluginRuntimeStateGetAll", func(t *testing.T) { testPluginRuntimeStateGetAll(t, ss) })
+	t.Run("PluginRuntimeStateTouchDeleteStale", func(t *testing.T) { testPluginRuntimeStateTouchDeleteStale(t, ss) })
+	t.Run("PluginRuntimeStateDeleteAllForPlugin", func(t *testing.T) { testPluginRuntimeStateDeleteAllForPlugin(t, ss) })
+}
+
+func getPluginRuntimeStates(t *testing.T, ss store.Store, pluginId string) map[string]*model.PluginRuntimeState {
+	result := <-ss.PluginRuntimeState().GetAll()
+	require.Nil(t, result.Err)
+
+	states := make(map[string]*model.PluginRuntimeState)
+	for _, state := range result.Data.([]*model.PluginRuntimeState) {
+		if state.PluginId == pluginId {
+			states[state.ClusterId] = state
+		}
+	}
+	return states
+}
+
+func testPluginRuntimeStateSaveGet(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	clusterId := model.NewId()
+
+	result := <-ss.PluginRuntimeState().Get(pluginId, clusterId)
+	require.NotNil(t, result.Err)
+	assert.Equal(t, http.StatusNotFound, result.Err.StatusCode)
+
+	state := &model.PluginRuntimeState{
+		PluginId:     pluginId,
+		ClusterId:    clusterId,
+		State:        model.PluginStateFailedToStart,
+		Error:        "unable to start plugin",
+		FailureCount: 1,
+	}
+	result = <-ss.PluginRuntimeState().Save(state)
+	require.Nil(t, result.Err)
+	assert.NotZero(t, state.UpdateAt)
+
+	result = <-ss.PluginRuntimeState().Get(pluginId, clusterId)
+	require.Nil(t, result.Err)
+	assert.Equal(t, state, result.Data.(*model.PluginRuntimeState))
+
+	// Saving again replaces the existing state
+	state = &model.PluginRuntimeState{
+		PluginId:  pluginId,
+		ClusterId: clusterId,
+		State:     model.PluginStateRunning,
+	}
+	result = <-ss.PluginRuntimeState().Save(state)
+	require.Nil(t, result.Err)
+
+	result = <-ss.PluginRuntimeState().Get(pluginId, clusterId)
+	require.Nil(t, result.Err)
+	assert.Equal(t, state, result.Data.(*model.PluginRuntimeState))
+
+	result = <-ss.PluginRuntimeState().Save(&model.PluginRuntimeState{ClusterId: clusterId})
+	assert.NotNil(t, result.Err)
+}
+
+func testPluginRuntimeStateGetAll(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	clusterIds := []string{"", model.NewId(), model.NewId()}
+
+	for _, clusterId := range clusterIds {
+		result := <-ss.PluginRuntimeState().Save(&model.PluginRuntimeState{PluginId: pluginId, ClusterId: clusterId, State: model.PluginStateRunning})
+		require.Nil(t, result.Err)
+	}
+
+	states := getPluginRuntimeStates(t, ss, pluginId)
+	require.Len(t, states, len(clusterIds))
+	for _, clusterId := range clusterIds {
+		require.Contains(t, states, clusterId)
+		assert.Equal(t, model.PluginStateRunning, states[clusterId].State)
+	}
+}
+
+func testPluginRuntimeStateTouchDeleteStale(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	staleClusterId := model.NewId()
+	freshClusterId := model.NewId()
+
+	for _, clusterId := range []string{staleClusterId, freshClusterId} {
+		result := <-ss.PluginRuntimeState().Save(&model.PluginRuntimeState{PluginId: pluginId, ClusterId: clusterId, State: model.PluginStateRunning})
+		require.Nil(t, result.Err)
+	}
+
+	result := <-ss.PluginRuntimeState().Touch(staleClusterId, 1000)
+	require.Nil(t, result.Err)
+
+	states := getPluginRuntimeStates(t, ss, pluginId)
+	require.Len(t, states, 2)
+	assert.Equal(t, int64(1000), states[staleClusterId].UpdateAt)
+	assert.NotEqual(t, int64(1000), states[freshClusterId].UpdateAt)
+
+	result = <-ss.PluginRuntimeState().DeleteStale(2000)
+	require.Nil(t, result.Err)
+	assert.True(t, result.Data.(int64) >= 1)
+
+	states = getPluginRuntimeStates(t, ss, pluginId)
+	require.Len(t, states, 1)
+	assert.Contains(t, states, freshClusterId)
+}
+
+func testPluginRuntimeStateDeleteAllForPlugin(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	otherPluginId := model.NewId()
+
+	for _, id := range []string{pluginId, otherPluginId} {
+		for i := 0; i < 2; i++ {
+			result := <-ss.PluginRuntimeState().Save(&model.PluginRuntimeState{PluginId: id, ClusterId: model.NewId(), State: model.PluginStateRunning})
+			require.Nil(t, result.Err)
+		}
+	}
+
+	result := <-ss.PluginRuntimeState().DeleteAllForPlugin(pluginId)
+	require.Nil(t, result.Err)
+
+	assert.Empty(t, getPluginRuntimeStates(t, ss, pluginId))
+	assert.Len(t, getPluginRuntimeStates(t, ss, otherPluginId), 2)
+}
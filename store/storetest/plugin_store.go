@@ -4,16 +4,49 @@
 package storetest
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPluginStore(t *testing.T, ss store.Store) {
 	t.Run("PluginSaveGet", func(t *testing.T) { testPluginSaveGet(t, ss) })
+	t.Run("PluginSaveGetLargeValue", func(t *testing.T) { testPluginSaveGetLargeValue(t, ss) })
+	t.Run("PluginGetMissing", func(t *testing.T) { testPluginGetMissing(t, ss) })
+	t.Run("PluginUnicodeKeys", func(t *testing.T) { testPluginUnicodeKeys(t, ss) })
+	t.Run("PluginConcurrentSaveOrUpdate", func(t *testing.T) { testPluginConcurrentSaveOrUpdate(t, ss) })
+	t.Run("PluginKeys", func(t *testing.T) { testPluginKeys(t, ss) })
+	t.Run("PluginSaveOrUpdateMultiple", func(t *testing.T) { testPluginSaveOrUpdateMultiple(t, ss) })
+	t.Run("PluginSaveOrUpdateMany", func(t *testing.T) { testPluginSaveOrUpdateMany(t, ss) })
+	t.Run("PluginGetMultiple", func(t *testing.T) { testPluginGetMultiple(t, ss) })
 	t.Run("PluginDelete", func(t *testing.T) { testPluginDelete(t, ss) })
+	t.Run("PluginList", func(t *testing.T) { testPluginList(t, ss) })
+	t.Run("PluginListWithPrefix", func(t *testing.T) { testPluginListWithPrefix(t, ss) })
+	t.Run("PluginGetAll", func(t *testing.T) { testPluginGetAll(t, ss) })
+	t.Run("PluginGetAllForPlugin", func(t *testing.T) { testPluginGetAllForPlugin(t, ss) })
+	t.Run("PluginCompareAndSet", func(t *testing.T) { testPluginCompareAndSet(t, ss) })
+	t.Run("PluginCompareAndDelete", func(t *testing.T) { testPluginCompareAndDelete(t, ss) })
+	t.Run("PluginCopy", func(t *testing.T) { testPluginCopy(t, ss) })
+	t.Run("PluginMove", func(t *testing.T) { testPluginMove(t, ss) })
+	t.Run("PluginGetHashedKeys", func(t *testing.T) { testPluginGetHashedKeys(t, ss) })
+	t.Run("PluginMoveHashedKey", func(t *testing.T) { testPluginMoveHashedKey(t, ss) })
+	t.Run("PluginMoveHashedKeyConcurrently", func(t *testing.T) { testPluginMoveHashedKeyConcurrently(t, ss) })
+	t.Run("PluginCountHashedKeys", func(t *testing.T) { testPluginCountHashedKeys(t, ss) })
+	t.Run("PluginIncrement", func(t *testing.T) { testPluginIncrement(t, ss) })
+	t.Run("PluginDeleteAllForPlugin", func(t *testing.T) { testPluginDeleteAllForPlugin(t, ss) })
+	t.Run("PluginGetUsage", func(t *testing.T) { testPluginGetUsage(t, ss) })
+	t.Run("PluginExpiry", func(t *testing.T) { testPluginExpiry(t, ss) })
 }
 
 func testPluginSaveGet(t *testing.T, ss store.Store) {
@@ -23,18 +56,17 @@ func testPluginSaveGet(t *testing.T, ss store.Store) {
 		Value:    []byte(model.NewId()),
 	}
 
-	if result := <-ss.Plugin().SaveOrUpdate(kv); result.Err != nil {
-		t.Fatal(result.Err)
+	if _, err := ss.Plugin().SaveOrUpdate(kv); err != nil {
+		t.Fatal(err)
 	}
 
 	defer func() {
-		<-ss.Plugin().Delete(kv.PluginId, kv.Key)
+		ss.Plugin().Delete(kv.PluginId, kv.Key)
 	}()
 
-	if result := <-ss.Plugin().Get(kv.PluginId, kv.Key); result.Err != nil {
-		t.Fatal(result.Err)
+	if received, err := ss.Plugin().Get(kv.PluginId, kv.Key); err != nil {
+		t.Fatal(err)
 	} else {
-		received := result.Data.(*model.PluginKeyValue)
 		assert.Equal(t, kv.PluginId, received.PluginId)
 		assert.Equal(t, kv.Key, received.Key)
 		assert.Equal(t, kv.Value, received.Value)
@@ -42,28 +74,1252 @@ func testPluginSaveGet(t *testing.T, ss store.Store) {
 
 	// Try inserting when already exists
 	kv.Value = []byte(model.NewId())
-	if result := <-ss.Plugin().SaveOrUpdate(kv); result.Err != nil {
-		t.Fatal(result.Err)
+	if _, err := ss.Plugin().SaveOrUpdate(kv); err != nil {
+		t.Fatal(err)
 	}
 
-	if result := <-ss.Plugin().Get(kv.PluginId, kv.Key); result.Err != nil {
-		t.Fatal(result.Err)
+	if received, err := ss.Plugin().Get(kv.PluginId, kv.Key); err != nil {
+		t.Fatal(err)
 	} else {
-		received := result.Data.(*model.PluginKeyValue)
 		assert.Equal(t, kv.PluginId, received.PluginId)
 		assert.Equal(t, kv.Key, received.Key)
 		assert.Equal(t, kv.Value, received.Value)
 	}
+
+	received, err := ss.Plugin().GetFromMaster(kv.PluginId, kv.Key)
+	require.Nil(t, err)
+	assert.Equal(t, kv.Value, received.Value)
+
+	_, err = ss.Plugin().GetFromMaster(kv.PluginId, model.NewId())
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.StatusCode)
+	}
+}
+
+func testPluginSaveGetLargeValue(t *testing.T, ss store.Store) {
+	kv := &model.PluginKeyValue{
+		PluginId: model.NewId(),
+		Key:      model.NewId(),
+		Value:    make([]byte, 512*1024),
+	}
+	rand.Read(kv.Value)
+
+	_, err := ss.Plugin().SaveOrUpdate(kv)
+	require.Nil(t, err)
+	defer func() {
+		ss.Plugin().Delete(kv.PluginId, kv.Key)
+	}()
+
+	received, err := ss.Plugin().Get(kv.PluginId, kv.Key)
+	require.Nil(t, err)
+	assert.Equal(t, kv.Value, received.Value)
+
+	// Updating replaces the whole value.
+	kv.Value = make([]byte, 768*1024)
+	rand.Read(kv.Value)
+	_, err = ss.Plugin().SaveOrUpdate(kv)
+	require.Nil(t, err)
+
+	received, err = ss.Plugin().Get(kv.PluginId, kv.Key)
+	require.Nil(t, err)
+	assert.Equal(t, kv.Value, received.Value)
+}
+
+func testPluginGetMissing(t *testing.T, ss store.Store) {
+	_, err := ss.Plugin().Get(model.NewId(), model.NewId())
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.StatusCode)
+	}
+}
+
+func testPluginUnicodeKeys(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	// Each of these runes takes several bytes, so keys at the limit are longer than it in bytes.
+	for _, r := range []string{"é", "日", "😀"} {
+		kv := &model.PluginKeyValue{
+			PluginId: pluginId,
+			Key:      strings.Repeat(r, model.KEY_VALUE_KEY_MAX_RUNES),
+			Value:    []byte(r),
+		}
+
+		if _, err := ss.Plugin().SaveOrUpdate(kv); err != nil {
+			t.Fatal(err)
+		}
+
+		if received, err := ss.Plugin().Get(pluginId, kv.Key); err != nil {
+			t.Fatal(err)
+		} else {
+			assert.Equal(t, kv.Key, received.Key)
+			assert.Equal(t, kv.Value, received.Value)
+		}
+
+		kv.Key += r
+		_, err := ss.Plugin().SaveOrUpdate(kv)
+		if assert.NotNil(t, err, "keys longer than the limit should be rejected") {
+			assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+		}
+	}
+}
+
+func testPluginConcurrentSaveOrUpdate(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	key := model.NewId()
+	defer func() {
+		ss.Plugin().Delete(pluginId, key)
+	}()
+
+	// Every writer races to insert the missing key, so all but one of the inserts on PostgreSQL
+	// violate the primary key, which should be treated as a successful write.
+	const writers = 10
+	values := make([][]byte, writers)
+	errs := make([]*model.AppError, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		values[i] = []byte(model.NewId())
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: key, Value: values[i]})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		assert.Nil(t, err, "writer %d", i)
+	}
+
+	received, err := ss.Plugin().GetFromMaster(pluginId, key)
+	require.Nil(t, err)
+	assert.Contains(t, values, received.Value)
+}
+
+func testPluginKeys(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+
+	// Key-value pairs are identified by both their plugin and their key, so neither can run into the other.
+	kv1 := &model.PluginKeyValue{PluginId: pluginId + "a", Key: "bc", Value: []byte("value1")}
+	kv2 := &model.PluginKeyValue{PluginId: pluginId + "ab", Key: "c", Value: []byte("value2")}
+	kv3 := &model.PluginKeyValue{PluginId: pluginId, Key: strings.Repeat("k", model.KEY_VALUE_KEY_MAX_RUNES), Value: []byte("value3")}
+
+	for _, kv := range []*model.PluginKeyValue{kv1, kv2, kv3} {
+		_, err := ss.Plugin().SaveOrUpdate(kv)
+		require.Nil(t, err)
+		defer func(kv *model.PluginKeyValue) {
+			ss.Plugin().Delete(kv.PluginId, kv.Key)
+		}(kv)
+	}
+
+	for _, kv := range []*model.PluginKeyValue{kv1, kv2, kv3} {
+		received, err := ss.Plugin().Get(kv.PluginId, kv.Key)
+		require.Nil(t, err)
+		assert.Equal(t, kv.Value, received.Value)
+	}
+
+	_, err := ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: strings.Repeat("k", model.KEY_VALUE_KEY_MAX_RUNES+1), Value: []byte("value")})
+	assert.NotNil(t, err)
+}
+
+func testPluginSaveOrUpdateMultiple(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	_, err := ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      "existing",
+		Value:    []byte("old"),
+		ExpireAt: model.GetMillis() + 60000,
+	})
+	require.Nil(t, err)
+
+	_, err = ss.Plugin().SaveOrUpdateMultiple([]*model.PluginKeyValue{
+		{PluginId: pluginId, Key: "existing", Value: []byte("new"), RawKey: "existing"},
+		{PluginId: pluginId, Key: "new", Value: []byte("value"), RawKey: "new"},
+	})
+	require.Nil(t, err)
+
+	kv, err := ss.Plugin().Get(pluginId, "existing")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("new"), kv.Value)
+	assert.Equal(t, "existing", kv.RawKey)
+	assert.Equal(t, int64(0), kv.ExpireAt)
+	kv, err = ss.Plugin().Get(pluginId, "new")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("value"), kv.Value)
+
+	// Nothing is saved if any pair is invalid
+	_, err = ss.Plugin().SaveOrUpdateMultiple([]*model.PluginKeyValue{
+		{PluginId: pluginId, Key: "valid", Value: []byte("value")},
+		{PluginId: pluginId, Key: strings.Repeat("a", model.KEY_VALUE_KEY_MAX_RUNES+1), Value: []byte("value")},
+	})
+	assert.NotNil(t, err)
+	_, err = ss.Plugin().Get(pluginId, "valid")
+	assert.NotNil(t, err)
+
+	// Concurrent batches with overlapping keys all succeed
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ss.Plugin().SaveOrUpdateMultiple([]*model.PluginKeyValue{
+				{PluginId: pluginId, Key: "a", Value: []byte("value")},
+				{PluginId: pluginId, Key: "b", Value: []byte("value")},
+			})
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	_, err = ss.Plugin().SaveOrUpdateMultiple(nil)
+	assert.Nil(t, err)
+}
+
+func testPluginSaveOrUpdateMany(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	getValue := func(t *testing.T, key string) []byte {
+		kv, err := ss.Plugin().Get(pluginId, key)
+		require.Nil(t, err)
+		return kv.Value
+	}
+
+	_, err := ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      "existing",
+		Value:    []byte("old"),
+		ExpireAt: model.GetMillis() + 60000,
+	})
+	require.Nil(t, err)
+	_, err = ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: "deleted", Value: []byte("value")})
+	require.Nil(t, err)
+
+	_, err = ss.Plugin().SaveOrUpdateMany(pluginId, []model.PluginKVOp{
+		{Type: model.PLUGIN_KV_OP_SET, Key: "existing", Value: []byte("new")},
+		{Type: model.PLUGIN_KV_OP_SET, Key: "new", Value: []byte("value")},
+		{Type: model.PLUGIN_KV_OP_DELETE, Key: "deleted"},
+		{Type: model.PLUGIN_KV_OP_DELETE, Key: "missing"},
+	})
+	require.Nil(t, err)
+
+	kv, err := ss.Plugin().Get(pluginId, "existing")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("new"), kv.Value)
+	assert.Equal(t, "existing", kv.RawKey)
+	assert.Equal(t, int64(0), kv.ExpireAt)
+	assert.Equal(t, []byte("value"), getValue(t, "new"))
+	_, err = ss.Plugin().Get(pluginId, "deleted")
+	assert.NotNil(t, err)
+
+	// Ops apply in order
+	_, err = ss.Plugin().SaveOrUpdateMany(pluginId, []model.PluginKVOp{
+		{Type: model.PLUGIN_KV_OP_SET, Key: "ordered", Value: []byte("first")},
+		{Type: model.PLUGIN_KV_OP_DELETE, Key: "ordered"},
+		{Type: model.PLUGIN_KV_OP_SET, Key: "ordered", Value: []byte("second")},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []byte("second"), getValue(t, "ordered"))
+
+	t.Run("invalid op aborts the batch", func(t *testing.T) {
+		badKey := strings.Repeat("a", model.KEY_VALUE_KEY_MAX_RUNES+1)
+		_, err := ss.Plugin().SaveOrUpdateMany(pluginId, []model.PluginKVOp{
+			{Type: model.PLUGIN_KV_OP_SET, Key: "valid", Value: []byte("value")},
+			{Type: model.PLUGIN_KV_OP_SET, Key: badKey, Value: []byte("value")},
+			{Type: model.PLUGIN_KV_OP_DELETE, Key: "new"},
+		})
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.DetailedError, badKey)
+		}
+
+		_, err = ss.Plugin().Get(pluginId, "valid")
+		assert.NotNil(t, err)
+		assert.Equal(t, []byte("value"), getValue(t, "new"))
+	})
+
+	t.Run("constraint violation mid-batch rolls back", func(t *testing.T) {
+		// Invalid UTF-8 passes validation but is rejected by the database.
+		badKey := "bad\xff\xfe"
+		_, err := ss.Plugin().SaveOrUpdateMany(pluginId, []model.PluginKVOp{
+			{Type: model.PLUGIN_KV_OP_SET, Key: "existing", Value: []byte("rolled back")},
+			{Type: model.PLUGIN_KV_OP_DELETE, Key: "new"},
+			{Type: model.PLUGIN_KV_OP_SET, Key: badKey, Value: []byte("value")},
+			{Type: model.PLUGIN_KV_OP_SET, Key: "after", Value: []byte("value")},
+		})
+		if assert.NotNil(t, err) {
+			assert.Equal(t, "store.sql_plugin_store.save_many.app_error", err.Id)
+			assert.Contains(t, err.DetailedError, "key="+badKey)
+		}
+
+		assert.Equal(t, []byte("new"), getValue(t, "existing"))
+		assert.Equal(t, []byte("value"), getValue(t, "new"))
+		_, err = ss.Plugin().Get(pluginId, "after")
+		assert.NotNil(t, err)
+	})
+
+	// Concurrent batches with overlapping keys all succeed
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ss.Plugin().SaveOrUpdateMany(pluginId, []model.PluginKVOp{
+				{Type: model.PLUGIN_KV_OP_SET, Key: "a", Value: []byte("value")},
+				{Type: model.PLUGIN_KV_OP_SET, Key: "b", Value: []byte("value")},
+			})
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	_, err = ss.Plugin().SaveOrUpdateMany(pluginId, nil)
+	assert.Nil(t, err)
+}
+
+func testPluginGetMultiple(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	_, err := ss.Plugin().SaveOrUpdateMultiple([]*model.PluginKeyValue{
+		{PluginId: pluginId, Key: "a", Value: []byte("1")},
+		{PluginId: pluginId, Key: "b", Value: []byte("2")},
+		{PluginId: pluginId, Key: "expired", Value: []byte("3"), ExpireAt: model.GetMillis() - 1000},
+	})
+	require.Nil(t, err)
+
+	kvs, err := ss.Plugin().GetMultiple(pluginId, []string{"a", "b", "expired", "missing"})
+	require.Nil(t, err)
+	values := make(map[string]string)
+	for _, kv := range kvs {
+		values[kv.Key] = string(kv.Value)
+	}
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, values)
+
+	kvs, err = ss.Plugin().GetMultipleFromMaster(pluginId, []string{"a", "expired", "missing"})
+	require.Nil(t, err)
+	if assert.Len(t, kvs, 1) {
+		assert.Equal(t, []byte("1"), kvs[0].Value)
+	}
+
+	// Other plugins' keys are not returned
+	kvs, err = ss.Plugin().GetMultiple(model.NewId(), []string{"a", "b"})
+	require.Nil(t, err)
+	assert.Empty(t, kvs)
+
+	kvs, err = ss.Plugin().GetMultiple(pluginId, nil)
+	require.Nil(t, err)
+	assert.Empty(t, kvs)
 }
 
 func testPluginDelete(t *testing.T, ss store.Store) {
-	kv := store.Must(ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+	kv, err := ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{
 		PluginId: model.NewId(),
 		Key:      model.NewId(),
 		Value:    []byte(model.NewId()),
-	})).(*model.PluginKeyValue)
+	})
+	require.Nil(t, err)
+
+	deleted, err := ss.Plugin().Delete(kv.PluginId, kv.Key)
+	require.Nil(t, err)
+	assert.True(t, deleted)
+
+	_, err = ss.Plugin().Get(kv.PluginId, kv.Key)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.StatusCode)
+	}
+
+	// Deleting a missing key is not an error, but reports that nothing was deleted.
+	deleted, err = ss.Plugin().Delete(kv.PluginId, kv.Key)
+	require.Nil(t, err)
+	assert.False(t, deleted)
+}
+
+func testPluginList(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	otherPluginId := model.NewId()
+
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+		ss.Plugin().DeleteAllForPlugin(otherPluginId)
+	}()
+
+	list := func(t *testing.T, pluginId string, offset, limit int) []string {
+		keys, err := ss.Plugin().List(pluginId, offset, limit)
+		require.Nil(t, err)
+		return keys
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, list(t, pluginId, 0, 10))
+	})
+
+	save := func(kv *model.PluginKeyValue) {
+		_, err := ss.Plugin().SaveOrUpdate(kv)
+		require.Nil(t, err)
+	}
+
+	var expected []string
+	for i := 0; i < 5; i++ {
+		key := model.NewId()
+		expected = append(expected, key)
+		save(&model.PluginKeyValue{
+			PluginId: pluginId,
+			Key:      model.NewId(),
+			RawKey:   key,
+			Value:    []byte("value"),
+		})
+	}
+
+	save(&model.PluginKeyValue{
+		PluginId: otherPluginId,
+		Key:      model.NewId(),
+		RawKey:   model.NewId(),
+		Value:    []byte("value"),
+	})
+
+	// Keys stored without a raw key, or expired, are not listed
+	save(&model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      model.NewId(),
+		Value:    []byte("value"),
+	})
+	save(&model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      model.NewId(),
+		RawKey:   model.NewId(),
+		Value:    []byte("value"),
+		ExpireAt: model.GetMillis() - 1000,
+	})
+
+	t.Run("multiple pages", func(t *testing.T) {
+		page1 := list(t, pluginId, 0, 2)
+		page2 := list(t, pluginId, 2, 2)
+		page3 := list(t, pluginId, 4, 2)
+		page4 := list(t, pluginId, 6, 2)
+
+		assert.Len(t, page1, 2)
+		assert.Len(t, page2, 2)
+		assert.Len(t, page3, 1)
+		assert.Empty(t, page4)
+
+		all := append(append(page1, page2...), page3...)
+		assert.ElementsMatch(t, expected, all)
+
+		// The order is stable
+		assert.Equal(t, all, list(t, pluginId, 0, 10))
+	})
+
+	t.Run("isolated between plugins", func(t *testing.T) {
+		keys := list(t, otherPluginId, 0, 10)
+		assert.Len(t, keys, 1)
+		assert.NotContains(t, expected, keys[0])
+	})
+}
+
+func testPluginListWithPrefix(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	otherPluginId := model.NewId()
+
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+		ss.Plugin().DeleteAllForPlugin(otherPluginId)
+	}()
+
+	save := func(pluginId, key string, expireAt int64) {
+		_, err := ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+			PluginId: pluginId,
+			Key:      key,
+			RawKey:   key,
+			Value:    []byte("value"),
+			ExpireAt: expireAt,
+		})
+		require.Nil(t, err)
+	}
+
+	listWithPrefix := func(t *testing.T, prefix string, offset, limit int) []string {
+		keys, err := ss.Plugin().ListWithPrefix(pluginId, prefix, offset, limit)
+		require.Nil(t, err)
+		return keys
+	}
+
+	for _, key := range []string{
+		"user_1_settings",
+		"user_2_settings",
+		"userX1_settings",
+		"user%",
+		"user%1",
+		"userX%",
+		"user*1",
+		"user**",
+		"userX1",
+		"team_1",
+	} {
+		save(pluginId, key, 0)
+	}
+	save(pluginId, "user_3_expired", model.GetMillis()-1000)
+	save(otherPluginId, "user_1_other", 0)
+
+	for prefix, expected := range map[string][]string{
+		"user_":  {"user_1_settings", "user_2_settings"},
+		"user_1": {"user_1_settings"},
+		"user%":  {"user%", "user%1"},
+		"user*":  {"user*1", "user**"},
+		"user**": {"user**"},
+		"team":   {"team_1"},
+		"":       {"user_1_settings", "user_2_settings", "userX1_settings", "user%", "user%1", "userX%", "user*1", "user**", "userX1", "team_1"},
+		"none":   {},
+	} {
+		t.Run("prefix "+prefix, func(t *testing.T) {
+			assert.ElementsMatch(t, expected, listWithPrefix(t, prefix, 0, 100))
+		})
+	}
+
+	t.Run("multiple pages", func(t *testing.T) {
+		page1 := listWithPrefix(t, "user_", 0, 1)
+		page2 := listWithPrefix(t, "user_", 1, 1)
+		page3 := listWithPrefix(t, "user_", 2, 1)
+
+		assert.Len(t, page1, 1)
+		assert.Len(t, page2, 1)
+		assert.Empty(t, page3)
+		assert.ElementsMatch(t, []string{"user_1_settings", "user_2_settings"}, append(page1, page2...))
+	})
+}
+
+func testPluginGetAll(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	otherPluginId := model.NewId()
+
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+		ss.Plugin().DeleteAllForPlugin(otherPluginId)
+	}()
+
+	save := func(kv *model.PluginKeyValue) {
+		_, err := ss.Plugin().SaveOrUpdate(kv)
+		require.Nil(t, err)
+	}
+
+	expected := map[string]*model.PluginKeyValue{}
+	for _, id := range []string{pluginId, pluginId, otherPluginId} {
+		kv := &model.PluginKeyValue{
+			PluginId: id,
+			Key:      model.NewId(),
+			RawKey:   model.NewId(),
+			Value:    []byte(model.NewId()),
+			ExpireAt: model.GetMillis() + 60000,
+		}
+		save(kv)
+		expected[kv.Key] = kv
+	}
+
+	// Keys stored without a raw key, or expired, are omitted
+	save(&model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      model.NewId(),
+		Value:    []byte("value"),
+	})
+	save(&model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      model.NewId(),
+		RawKey:   model.NewId(),
+		Value:    []byte("value"),
+		ExpireAt: model.GetMillis() - 1000,
+	})
+
+	// Other tests may store key-value pairs concurrently, so only those of these plugins are checked.
+	received := map[string]*model.PluginKeyValue{}
+	for offset := 0; ; offset += 2 {
+		page, err := ss.Plugin().GetAll(offset, 2)
+		require.Nil(t, err)
+		assert.True(t, len(page) <= 2)
+		for _, kv := range page {
+			if kv.PluginId == pluginId || kv.PluginId == otherPluginId {
+				received[kv.Key] = kv
+			}
+		}
+		if len(page) < 2 {
+			break
+		}
+	}
+
+	assert.Equal(t, expected, received)
+}
+
+func testPluginGetAllForPlugin(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	otherPluginId := model.NewId()
+
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+		ss.Plugin().DeleteAllForPlugin(otherPluginId)
+	}()
+
+	save := func(kv *model.PluginKeyValue) {
+		_, err := ss.Plugin().SaveOrUpdate(kv)
+		require.Nil(t, err)
+	}
+
+	expected := map[string]*model.PluginKeyValue{}
+	for i := 0; i < 3; i++ {
+		kv := &model.PluginKeyValue{
+			PluginId: pluginId,
+			Key:      model.NewId(),
+			RawKey:   model.NewId(),
+			Value:    []byte(model.NewId()),
+			ExpireAt: model.GetMillis() + 60000,
+		}
+		save(kv)
+		expected[kv.Key] = kv
+	}
+
+	// Pairs of other plugins, stored without a raw key, or expired, are omitted
+	save(&model.PluginKeyValue{
+		PluginId: otherPluginId,
+		Key:      model.NewId(),
+		RawKey:   model.NewId(),
+		Value:    []byte("value"),
+	})
+	save(&model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      model.NewId(),
+		Value:    []byte("value"),
+	})
+	save(&model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      model.NewId(),
+		RawKey:   model.NewId(),
+		Value:    []byte("value"),
+		ExpireAt: model.GetMillis() - 1000,
+	})
 
-	if result := <-ss.Plugin().Delete(kv.PluginId, kv.Key); result.Err != nil {
-		t.Fatal(result.Err)
+	page1, err := ss.Plugin().GetAllForPlugin(pluginId, 0, 2)
+	require.Nil(t, err)
+	page2, err := ss.Plugin().GetAllForPlugin(pluginId, 2, 2)
+	require.Nil(t, err)
+	assert.Len(t, page1, 2)
+	assert.Len(t, page2, 1)
+
+	received := map[string]*model.PluginKeyValue{}
+	for _, kv := range append(page1, page2...) {
+		received[kv.Key] = kv
 	}
+	assert.Equal(t, expected, received)
+}
+
+func testPluginCompareAndSet(t *testing.T, ss store.Store) {
+	kv := &model.PluginKeyValue{
+		PluginId: model.NewId(),
+		Key:      model.NewId(),
+		Value:    []byte("first"),
+	}
+
+	defer func() {
+		ss.Plugin().Delete(kv.PluginId, kv.Key)
+	}()
+
+	compareAndSet := func(kv *model.PluginKeyValue, oldValue []byte) bool {
+		ok, err := ss.Plugin().CompareAndSet(kv, oldValue)
+		require.Nil(t, err)
+		return ok
+	}
+
+	// Insert if absent
+	assert.True(t, compareAndSet(kv, nil))
+	assert.False(t, compareAndSet(kv, nil))
+
+	// Update only when holding the old value
+	kv.Value = []byte("second")
+	assert.False(t, compareAndSet(kv, []byte("wrong")))
+	assert.True(t, compareAndSet(kv, []byte("first")))
+
+	// Setting the same value reports success
+	assert.True(t, compareAndSet(kv, []byte("second")))
+
+	received, err := ss.Plugin().Get(kv.PluginId, kv.Key)
+	require.Nil(t, err)
+	assert.Equal(t, []byte("second"), received.Value)
+
+	// Missing keys are never updated
+	missing := &model.PluginKeyValue{
+		PluginId: kv.PluginId,
+		Key:      model.NewId(),
+		Value:    []byte("value"),
+	}
+	assert.False(t, compareAndSet(missing, []byte("value")))
+}
+
+func testPluginCompareAndDelete(t *testing.T, ss store.Store) {
+	kv := &model.PluginKeyValue{
+		PluginId: model.NewId(),
+		Key:      model.NewId(),
+		Value:    []byte("value"),
+	}
+	_, err := ss.Plugin().SaveOrUpdate(kv)
+	require.Nil(t, err)
+
+	defer func() {
+		ss.Plugin().Delete(kv.PluginId, kv.Key)
+	}()
+
+	compareAndDelete := func(oldValue []byte) bool {
+		ok, err := ss.Plugin().CompareAndDelete(kv.PluginId, kv.Key, oldValue)
+		require.Nil(t, err)
+		return ok
+	}
+
+	// Delete only when holding the old value
+	assert.False(t, compareAndDelete([]byte("wrong")))
+	assert.True(t, compareAndDelete([]byte("value")))
+
+	_, err = ss.Plugin().Get(kv.PluginId, kv.Key)
+	assert.NotNil(t, err)
+
+	// Missing keys are never deleted
+	assert.False(t, compareAndDelete([]byte("value")))
+
+	// Expired keys are treated as missing
+	kv.ExpireAt = model.GetMillis() - 1000
+	_, err = ss.Plugin().SaveOrUpdate(kv)
+	require.Nil(t, err)
+	assert.False(t, compareAndDelete([]byte("value")))
+}
+
+func testPluginCopy(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	expireAt := model.GetMillis() + 60000
+	for key, kv := range map[string]*model.PluginKeyValue{
+		"key":      {Value: []byte("value"), ExpireAt: expireAt},
+		"existing": {Value: []byte("existing")},
+		"expired":  {Value: []byte("expired"), ExpireAt: model.GetMillis() - 1000},
+	} {
+		kv.PluginId = pluginId
+		kv.Key = key
+		kv.RawKey = key
+		_, err := ss.Plugin().SaveOrUpdate(kv)
+		require.Nil(t, err)
+	}
+
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	copied, err := ss.Plugin().Copy(pluginId, "key", "copy")
+	require.Nil(t, err)
+	assert.True(t, copied)
+
+	for _, key := range []string{"key", "copy"} {
+		kv, err := ss.Plugin().Get(pluginId, key)
+		require.Nil(t, err)
+		assert.Equal(t, []byte("value"), kv.Value)
+		assert.Equal(t, key, kv.RawKey)
+		assert.Equal(t, expireAt, kv.ExpireAt)
+	}
+
+	// Existing keys are not overwritten
+	copied, err = ss.Plugin().Copy(pluginId, "key", "existing")
+	require.Nil(t, err)
+	assert.False(t, copied)
+
+	kv, err := ss.Plugin().Get(pluginId, "existing")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("existing"), kv.Value)
+
+	// Expired keys are treated as missing
+	copied, err = ss.Plugin().Copy(pluginId, "key", "expired")
+	require.Nil(t, err)
+	assert.True(t, copied)
+
+	_, err = ss.Plugin().Copy(pluginId, "expired", "other")
+	require.NotNil(t, err)
+	_, err = ss.Plugin().Copy(pluginId, "missing", "other")
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+	_, err = ss.Plugin().Copy(pluginId, "key", "")
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+}
+
+func testPluginMove(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	for key, value := range map[string]string{"key": "value", "existing": "existing"} {
+		_, err := ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: key, Value: []byte(value), RawKey: key})
+		require.Nil(t, err)
+	}
+
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	// The key is left in place when the new key already exists
+	moved, err := ss.Plugin().Move(pluginId, "key", "existing")
+	require.Nil(t, err)
+	assert.False(t, moved)
+
+	kv, err := ss.Plugin().Get(pluginId, "key")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("value"), kv.Value)
+
+	moved, err = ss.Plugin().Move(pluginId, "key", "moved")
+	require.Nil(t, err)
+	assert.True(t, moved)
+
+	_, err = ss.Plugin().Get(pluginId, "key")
+	assert.NotNil(t, err)
+
+	kv, err = ss.Plugin().Get(pluginId, "moved")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("value"), kv.Value)
+	assert.Equal(t, "moved", kv.RawKey)
+
+	_, err = ss.Plugin().Move(pluginId, "key", "other")
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+}
+
+// hashPluginKey returns the hash under which keys were stored before they were stored as given.
+func hashPluginKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// saveHashedPluginKey stores the key-value pair under the hash of its key, as keys were stored before
+// they were stored as given. An empty raw key is not recorded.
+func saveHashedPluginKey(t *testing.T, ss store.Store, pluginId, rawKey, key, value string) {
+	_, err := ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: hashPluginKey(key), Value: []byte(value), RawKey: rawKey})
+	require.Nil(t, err)
+}
+
+func testPluginGetHashedKeys(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	var expected []string
+	for _, key := range []string{"a", "b", "c"} {
+		saveHashedPluginKey(t, ss, pluginId, key, key, "value")
+		expected = append(expected, hashPluginKey(key))
+	}
+	sort.Strings(expected)
+
+	// Keys stored as given, keys too long to be stored as given and keys without a raw key are left out
+	_, err := ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: "given", Value: []byte("value"), RawKey: "given"})
+	require.Nil(t, err)
+	longKey := strings.Repeat("a", model.KEY_VALUE_KEY_MAX_RUNES+1)
+	saveHashedPluginKey(t, ss, pluginId, longKey, longKey, "value")
+	saveHashedPluginKey(t, ss, pluginId, "", "unrecorded", "value")
+
+	var keys []string
+	afterKey := ""
+	for {
+		kvs, err := ss.Plugin().GetHashedKeys(pluginId, afterKey, 2)
+		require.Nil(t, err)
+
+		for _, kv := range kvs {
+			if kv.PluginId == pluginId {
+				keys = append(keys, kv.Key)
+			}
+		}
+
+		if len(kvs) < 2 || kvs[len(kvs)-1].PluginId != pluginId {
+			break
+		}
+		afterKey = kvs[len(kvs)-1].Key
+	}
+	assert.Equal(t, expected, keys)
+}
+
+func testPluginMoveHashedKey(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	t.Run("moved", func(t *testing.T) {
+		expireAt := model.GetMillis() + 60*1000
+		_, err := ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: hashPluginKey("moved"), Value: []byte("value"), RawKey: "moved", ExpireAt: expireAt})
+		require.Nil(t, err)
+
+		moved, err := ss.Plugin().MoveHashedKey(pluginId, hashPluginKey("moved"), "moved")
+		require.Nil(t, err)
+		assert.True(t, moved)
+
+		_, err = ss.Plugin().Get(pluginId, hashPluginKey("moved"))
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+		kv, err := ss.Plugin().Get(pluginId, "moved")
+		require.Nil(t, err)
+		assert.Equal(t, []byte("value"), kv.Value)
+		assert.Equal(t, "moved", kv.RawKey)
+		assert.Equal(t, expireAt, kv.ExpireAt)
+
+		// The pair is only moved once
+		moved, err = ss.Plugin().MoveHashedKey(pluginId, hashPluginKey("moved"), "moved")
+		require.Nil(t, err)
+		assert.False(t, moved)
+	})
+
+	t.Run("newer pair kept", func(t *testing.T) {
+		saveHashedPluginKey(t, ss, pluginId, "", "kept", "old")
+		_, err := ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: "kept", Value: []byte("new"), RawKey: "kept"})
+		require.Nil(t, err)
+
+		moved, err := ss.Plugin().MoveHashedKey(pluginId, hashPluginKey("kept"), "kept")
+		require.Nil(t, err)
+		assert.False(t, moved)
+
+		_, err = ss.Plugin().Get(pluginId, hashPluginKey("kept"))
+		assert.NotNil(t, err)
+
+		kv, err := ss.Plugin().Get(pluginId, "kept")
+		require.Nil(t, err)
+		assert.Equal(t, []byte("new"), kv.Value)
+	})
+
+	t.Run("expired pair dropped", func(t *testing.T) {
+		_, err := ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: hashPluginKey("expired"), Value: []byte("value"), ExpireAt: model.GetMillis() - 1000})
+		require.Nil(t, err)
+
+		moved, err := ss.Plugin().MoveHashedKey(pluginId, hashPluginKey("expired"), "expired")
+		require.Nil(t, err)
+		assert.False(t, moved)
+
+		_, err = ss.Plugin().Get(pluginId, "expired")
+		assert.NotNil(t, err)
+		usage, err := ss.Plugin().GetUsage(pluginId)
+		require.Nil(t, err)
+		assert.Equal(t, int64(2), usage.KeyCount)
+	})
+
+	_, err := ss.Plugin().MoveHashedKey(pluginId, hashPluginKey("invalid"), "")
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+}
+
+func testPluginMoveHashedKeyConcurrently(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	for i := 0; i < 10; i++ {
+		key := model.NewId()
+		saveHashedPluginKey(t, ss, pluginId, key, key, "old")
+
+		// Of several concurrent moves, only one writes the key, so that a key deleted after the first
+		// move is not written again by another.
+		var wg sync.WaitGroup
+		var lock sync.Mutex
+		movedCount := 0
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				moved, err := ss.Plugin().MoveHashedKey(pluginId, hashPluginKey(key), key)
+				assert.Nil(t, err)
+				if moved {
+					lock.Lock()
+					movedCount++
+					lock.Unlock()
+
+					_, err := ss.Plugin().Delete(pluginId, key)
+					assert.Nil(t, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 1, movedCount)
+
+		_, err := ss.Plugin().Get(pluginId, key)
+		assert.NotNil(t, err)
+		_, err = ss.Plugin().Get(pluginId, hashPluginKey(key))
+		assert.NotNil(t, err)
+	}
+}
+
+func testPluginCountHashedKeys(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	before, err := ss.Plugin().CountHashedKeys()
+	require.Nil(t, err)
+
+	saveHashedPluginKey(t, ss, pluginId, "recorded", "recorded", "value")
+	saveHashedPluginKey(t, ss, pluginId, "", "unrecorded", "value")
+
+	// Keys stored as given, keys too long to be stored as given and internal keys are not counted
+	_, err = ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: "given", Value: []byte("value"), RawKey: "given"})
+	require.Nil(t, err)
+	longKey := strings.Repeat("a", model.KEY_VALUE_KEY_MAX_RUNES+1)
+	saveHashedPluginKey(t, ss, pluginId, longKey, longKey, "value")
+	_, err = ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: hashPluginKey("lock"), Value: []byte("owner"), ExpireAt: model.GetMillis() + 60*1000})
+	require.Nil(t, err)
+	_, err = ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: "mmi_schema_version", Value: []byte("1")})
+	require.Nil(t, err)
+
+	after, err := ss.Plugin().CountHashedKeys()
+	require.Nil(t, err)
+	assert.Equal(t, before.RemainingKeys+1, after.RemainingKeys)
+	assert.Equal(t, before.UnrecordedKeys+1, after.UnrecordedKeys)
+	assert.False(t, after.Completed)
+}
+
+func testPluginIncrement(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	key := model.NewId()
+
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	increment := func(key string, delta int64) int64 {
+		value, err := ss.Plugin().Increment(pluginId, key, delta)
+		require.Nil(t, err)
+		return value
+	}
+
+	getValue := func(key string) []byte {
+		kv, err := ss.Plugin().Get(pluginId, key)
+		require.Nil(t, err)
+		return kv.Value
+	}
+
+	// Missing keys start from the delta
+	assert.Equal(t, int64(5), increment(key, 5))
+	assert.Equal(t, int64(3), increment(key, -2))
+	assert.Equal(t, []byte("3"), getValue(key))
+
+	// Concurrent increments are never lost
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				_, err := ss.Plugin().Increment(pluginId, key, 1)
+				assert.Nil(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, []byte("103"), getValue(key))
+
+	// Concurrently creating a counter counts every increment
+	newKey := model.NewId()
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ss.Plugin().Increment(pluginId, newKey, 1)
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, []byte("10"), getValue(newKey))
+
+	// Expired keys start over
+	_, err := ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      key,
+		Value:    []byte("10"),
+		ExpireAt: model.GetMillis() - 1000,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), increment(key, 1))
+
+	// Values that aren't numbers are left alone
+	_, err = ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      key,
+		Value:    []byte("value"),
+	})
+	require.Nil(t, err)
+	_, err = ss.Plugin().Increment(pluginId, key, 1)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusBadRequest, err.StatusCode)
+	}
+	assert.Equal(t, []byte("value"), getValue(key))
+
+	// Counters at the limits of an int64 can reach them but not overflow them
+	for _, tc := range []struct {
+		Value    string
+		Delta    int64
+		Expected int64
+	}{
+		{"9223372036854775806", 1, math.MaxInt64},
+		{"-9223372036854775807", -1, math.MinInt64},
+		{"-9223372036854775808", math.MaxInt64, -1},
+	} {
+		_, err = ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+			PluginId: pluginId,
+			Key:      key,
+			Value:    []byte(tc.Value),
+		})
+		require.Nil(t, err)
+		assert.Equal(t, tc.Expected, increment(key, tc.Delta), tc.Value)
+	}
+
+	for _, tc := range []struct {
+		Value string
+		Delta int64
+	}{
+		{"9223372036854775807", 1},
+		{"9223372036854775000", 1000},
+		{"-9223372036854775808", -1},
+		{"-1", math.MinInt64},
+		{"9999999999999999999", 1},
+		{"-9999999999999999999", 1},
+	} {
+		_, err = ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+			PluginId: pluginId,
+			Key:      key,
+			Value:    []byte(tc.Value),
+		})
+		require.Nil(t, err)
+		_, err = ss.Plugin().Increment(pluginId, key, tc.Delta)
+		if assert.NotNil(t, err, tc.Value) {
+			assert.Equal(t, http.StatusBadRequest, err.StatusCode, tc.Value)
+		}
+		assert.Equal(t, []byte(tc.Value), getValue(key), tc.Value)
+	}
+}
+
+func testPluginDeleteAllForPlugin(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	otherPluginId := model.NewId()
+
+	for _, id := range []string{pluginId, pluginId, otherPluginId} {
+		_, err := ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+			PluginId: id,
+			Key:      model.NewId(),
+			Value:    []byte(model.NewId()),
+		})
+		require.Nil(t, err)
+	}
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(otherPluginId)
+	}()
+
+	deleteAllForPlugin := func(t *testing.T, pluginId string) int64 {
+		deleted, err := ss.Plugin().DeleteAllForPlugin(pluginId)
+		require.Nil(t, err)
+		return deleted
+	}
+
+	keyCount := func(t *testing.T, pluginId string) int64 {
+		usage, err := ss.Plugin().GetUsage(pluginId)
+		require.Nil(t, err)
+		return usage.KeyCount
+	}
+
+	assert.Equal(t, int64(2), deleteAllForPlugin(t, pluginId))
+	assert.Equal(t, int64(0), keyCount(t, pluginId))
+	assert.Equal(t, int64(1), keyCount(t, otherPluginId))
+
+	t.Run("more keys than fit in one batch", func(t *testing.T) {
+		var kvs []*model.PluginKeyValue
+		for i := 0; i < 2500; i++ {
+			kvs = append(kvs, &model.PluginKeyValue{PluginId: pluginId, Key: model.NewId(), Value: []byte("value")})
+			if len(kvs) == 500 {
+				_, err := ss.Plugin().SaveOrUpdateMultiple(kvs)
+				require.Nil(t, err)
+				kvs = nil
+			}
+		}
+
+		assert.Equal(t, int64(2500), deleteAllForPlugin(t, pluginId))
+		assert.Equal(t, int64(0), keyCount(t, pluginId))
+		assert.Equal(t, int64(1), keyCount(t, otherPluginId))
+	})
+}
+
+func testPluginGetUsage(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	usage, err := ss.Plugin().GetUsage(pluginId)
+	require.Nil(t, err)
+	assert.Equal(t, &model.PluginKeyValueUsage{KeyCount: 0, Size: 0}, usage)
+
+	_, err = ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: "a", Value: []byte("12345")})
+	require.Nil(t, err)
+	_, err = ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: "b", Value: []byte("123")})
+	require.Nil(t, err)
+
+	usage, err = ss.Plugin().GetUsage(pluginId)
+	require.Nil(t, err)
+	assert.Equal(t, &model.PluginKeyValueUsage{KeyCount: 2, Size: 8}, usage)
+
+	usage, err = ss.Plugin().GetUsageFromMaster(pluginId)
+	require.Nil(t, err)
+	assert.Equal(t, &model.PluginKeyValueUsage{KeyCount: 2, Size: 8}, usage)
+
+	_, err = ss.Plugin().Delete(pluginId, "a")
+	require.Nil(t, err)
+
+	usage, err = ss.Plugin().GetUsageFromMaster(pluginId)
+	require.Nil(t, err)
+	assert.Equal(t, &model.PluginKeyValueUsage{KeyCount: 1, Size: 3}, usage)
+}
+
+func testPluginExpiry(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	defer func() {
+		ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	expired := &model.PluginKeyValue{PluginId: pluginId, Key: "expired", Value: []byte("value"), ExpireAt: model.GetMillis() - 1000}
+	unexpired := &model.PluginKeyValue{PluginId: pluginId, Key: "unexpired", Value: []byte("value"), ExpireAt: model.GetMillis() + 60*1000}
+	permanent := &model.PluginKeyValue{PluginId: pluginId, Key: "permanent", Value: []byte("value")}
+	for _, kv := range []*model.PluginKeyValue{expired, unexpired, permanent} {
+		_, err := ss.Plugin().SaveOrUpdate(kv)
+		require.Nil(t, err)
+	}
+
+	_, err := ss.Plugin().Get(pluginId, expired.Key)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.StatusCode)
+	}
+	kv, err := ss.Plugin().Get(pluginId, unexpired.Key)
+	require.Nil(t, err)
+	assert.Equal(t, unexpired.ExpireAt, kv.ExpireAt)
+	kv, err = ss.Plugin().Get(pluginId, permanent.Key)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), kv.ExpireAt)
+
+	// Expired keys are treated as absent when comparing and setting
+	ok, err := ss.Plugin().CompareAndSet(&model.PluginKeyValue{PluginId: pluginId, Key: expired.Key, Value: []byte("new")}, []byte("value"))
+	require.Nil(t, err)
+	assert.False(t, ok)
+	ok, err = ss.Plugin().CompareAndSet(&model.PluginKeyValue{PluginId: pluginId, Key: expired.Key, Value: []byte("new"), ExpireAt: model.GetMillis() - 1000}, nil)
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	deleted, err := ss.Plugin().DeleteAllExpired()
+	require.Nil(t, err)
+	assert.True(t, deleted >= 1)
+	usage, err := ss.Plugin().GetUsage(pluginId)
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), usage.KeyCount)
 }
@@ -0,0 +1,99 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package storetest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginSubscriptionStore(t *testing.T, ss store.Store) {
+	t.Run("PluginSubscriptionSaveGet", func(t *testing.T) { testPluginSubscriptionSaveGet(t, ss) })
+	t.Run("PluginSubscriptionGetForChannel", func(t *testing.T) { testPluginSubscriptionGetForChannel(t, ss) })
+	t.Run("PluginSubscriptionDelete", func(t *testing.T) { testPluginSubscriptionDelete(t, ss) })
+	t.Run("PluginSubscriptionDeleteAllForPlugin", func(t *testing.T) { testPluginSubscriptionDeleteAllForPlugin(t, ss) })
+}
+
+func newTestPluginSubscription(pluginId, channelId string) *model.PluginSubscription {
+	return &model.PluginSubscription{
+		PluginId:    pluginId,
+		ChannelId:   channelId,
+		CreatorId:   model.NewId(),
+		Description: "subscription " + model.NewId(),
+		Payload:     []byte(model.NewId()),
+	}
+}
+
+func testPluginSubscriptionSaveGet(t *testing.T, ss store.Store) {
+	subscription := newTestPluginSubscription(model.NewId(), model.NewId())
+
+	result := <-ss.PluginSubscription().Save(subscription)
+	require.Nil(t, result.Err)
+	saved := result.Data.(*model.PluginSubscription)
+	assert.NotEmpty(t, saved.Id)
+	assert.NotZero(t, saved.CreateAt)
+
+	result = <-ss.PluginSubscription().Save(saved)
+	assert.NotNil(t, result.Err, "should not be able to save an existing subscription")
+
+	result = <-ss.PluginSubscription().Get(saved.Id)
+	require.Nil(t, result.Err)
+	assert.Equal(t, saved, result.Data.(*model.PluginSubscription))
+
+	result = <-ss.PluginSubscription().Get(model.NewId())
+	require.NotNil(t, result.Err)
+	assert.Equal(t, http.StatusNotFound, result.Err.StatusCode)
+}
+
+func testPluginSubscriptionGetForChannel(t *testing.T, ss store.Store) {
+	channelId := model.NewId()
+
+	s1 := (<-ss.PluginSubscription().Save(newTestPluginSubscription(model.NewId(), channelId))).Data.(*model.PluginSubscription)
+	s2 := (<-ss.PluginSubscription().Save(newTestPluginSubscription(model.NewId(), channelId))).Data.(*model.PluginSubscription)
+	store.Must(ss.PluginSubscription().Save(newTestPluginSubscription(model.NewId(), model.NewId())))
+
+	result := <-ss.PluginSubscription().GetForChannel(channelId)
+	require.Nil(t, result.Err)
+	subscriptions := result.Data.([]*model.PluginSubscription)
+	require.Len(t, subscriptions, 2)
+	assert.ElementsMatch(t, []string{s1.Id, s2.Id}, []string{subscriptions[0].Id, subscriptions[1].Id})
+
+	result = <-ss.PluginSubscription().GetForChannel(model.NewId())
+	require.Nil(t, result.Err)
+	assert.Empty(t, result.Data.([]*model.PluginSubscription))
+}
+
+func testPluginSubscriptionDelete(t *testing.T, ss store.Store) {
+	subscription := (<-ss.PluginSubscription().Save(newTestPluginSubscription(model.NewId(), model.NewId()))).Data.(*model.PluginSubscription)
+
+	result := <-ss.PluginSubscription().Delete(subscription.Id)
+	require.Nil(t, result.Err)
+
+	result = <-ss.PluginSubscription().Get(subscription.Id)
+	require.NotNil(t, result.Err)
+	assert.Equal(t, http.StatusNotFound, result.Err.StatusCode)
+}
+
+func testPluginSubscriptionDeleteAllForPlugin(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	channelId := model.NewId()
+
+	store.Must(ss.PluginSubscription().Save(newTestPluginSubscription(pluginId, channelId)))
+	store.Must(ss.PluginSubscription().Save(newTestPluginSubscription(pluginId, model.NewId())))
+	other := (<-ss.PluginSubscription().Save(newTestPluginSubscription(model.NewId(), channelId))).Data.(*model.PluginSubscription)
+
+	result := <-ss.PluginSubscription().DeleteAllForPlugin(pluginId)
+	require.Nil(t, result.Err)
+
+	result = <-ss.PluginSubscription().GetForChannel(channelId)
+	require.Nil(t, result.Err)
+	subscriptions := result.Data.([]*model.PluginSubscription)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, other.Id, subscriptions[0].Id)
+}
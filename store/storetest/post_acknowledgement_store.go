@@ -0,0 +1,88 @@
+// Copyright (c) 2018-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package storetest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostAcknowledgementStore(t *testing.T, ss store.Store) {
+	t.Run("PostAcknowledgementSaveGet", func(t *testing.T) { testPostAcknowledgementSaveGet(t, ss) })
+	t.Run("PostAcknowledgementSaveTwice", func(t *testing.T) { testPostAcknowledgementSaveTwice(t, ss) })
+	t.Run("PostAcknowledgementGetForPost", func(t *testing.T) { testPostAcknowledgementGetForPost(t, ss) })
+	t.Run("PostAcknowledgementDeleteAllForPost", func(t *testing.T) { testPostAcknowledgementDeleteAllForPost(t, ss) })
+}
+
+func testPostAcknowledgementSaveGet(t *testing.T, ss store.Store) {
+	acknowledgement := &model.PostAcknowledgement{PostId: model.NewId(), UserId: model.NewId()}
+
+	result := <-ss.PostAcknowledgement().Save(acknowledgement)
+	require.Nil(t, result.Err)
+	saved := result.Data.(*model.PostAcknowledgement)
+	assert.NotZero(t, saved.AcknowledgedAt)
+
+	result = <-ss.PostAcknowledgement().Get(saved.PostId, saved.UserId)
+	require.Nil(t, result.Err)
+	assert.Equal(t, saved, result.Data.(*model.PostAcknowledgement))
+
+	result = <-ss.PostAcknowledgement().Get(saved.PostId, model.NewId())
+	require.NotNil(t, result.Err)
+	assert.Equal(t, http.StatusNotFound, result.Err.StatusCode)
+
+	result = <-ss.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: "junk", UserId: model.NewId()})
+	assert.NotNil(t, result.Err)
+}
+
+func testPostAcknowledgementSaveTwice(t *testing.T, ss store.Store) {
+	first := store.Must(ss.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: model.NewId(), UserId: model.NewId(), AcknowledgedAt: 1000})).(*model.PostAcknowledgement)
+
+	result := <-ss.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: first.PostId, UserId: first.UserId, AcknowledgedAt: 2000})
+	require.Nil(t, result.Err, "acknowledging a post twice should not fail")
+	assert.Equal(t, first, result.Data.(*model.PostAcknowledgement), "the first acknowledgement should be kept")
+
+	result = <-ss.PostAcknowledgement().GetForPost(first.PostId)
+	require.Nil(t, result.Err)
+	assert.Equal(t, []*model.PostAcknowledgement{first}, result.Data.([]*model.PostAcknowledgement))
+}
+
+func testPostAcknowledgementGetForPost(t *testing.T, ss store.Store) {
+	postId := model.NewId()
+
+	a2 := store.Must(ss.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: postId, UserId: model.NewId(), AcknowledgedAt: 2000})).(*model.PostAcknowledgement)
+	a1 := store.Must(ss.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: postId, UserId: model.NewId(), AcknowledgedAt: 1000})).(*model.PostAcknowledgement)
+	store.Must(ss.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: model.NewId(), UserId: a1.UserId}))
+
+	result := <-ss.PostAcknowledgement().GetForPost(postId)
+	require.Nil(t, result.Err)
+	assert.Equal(t, []*model.PostAcknowledgement{a1, a2}, result.Data.([]*model.PostAcknowledgement))
+
+	result = <-ss.PostAcknowledgement().GetForPost(model.NewId())
+	require.Nil(t, result.Err)
+	assert.Empty(t, result.Data.([]*model.PostAcknowledgement))
+}
+
+func testPostAcknowledgementDeleteAllForPost(t *testing.T, ss store.Store) {
+	postId := model.NewId()
+	otherPostId := model.NewId()
+
+	store.Must(ss.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: postId, UserId: model.NewId()}))
+	store.Must(ss.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: postId, UserId: model.NewId()}))
+	other := store.Must(ss.PostAcknowledgement().Save(&model.PostAcknowledgement{PostId: otherPostId, UserId: model.NewId()})).(*model.PostAcknowledgement)
+
+	require.Nil(t, (<-ss.PostAcknowledgement().DeleteAllForPost(postId)).Err)
+
+	result := <-ss.PostAcknowledgement().GetForPost(postId)
+	require.Nil(t, result.Err)
+	assert.Empty(t, result.Data.([]*model.PostAcknowledgement))
+
+	result = <-ss.PostAcknowledgement().GetForPost(otherPostId)
+	require.Nil(t, result.Err)
+	assert.Equal(t, []*model.PostAcknowledgement{other}, result.Data.([]*model.PostAcknowledgement))
+}
@@ -5,6 +5,7 @@ package storetest
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -40,7 +41,9 @@ func TestPostStore(t *testing.T, ss store.Store) {
 	t.Run("GetPostsCreatedAt", func(t *testing.T) { testPostStoreGetPostsCreatedAt(t, ss) })
 	t.Run("Overwrite", func(t *testing.T) { testPostStoreOverwrite(t, ss) })
 	t.Run("GetPostsByIds", func(t *testing.T) { testPostStoreGetPostsByIds(t, ss) })
+	t.Run("GetPostsWithFileId", func(t *testing.T) { testPostStoreGetPostsWithFileId(t, ss) })
 	t.Run("GetPostsBatchForIndexing", func(t *testing.T) { testPostStoreGetPostsBatchForIndexing(t, ss) })
+	t.Run("GetPostsBatchForChannelExport", func(t *testing.T) { testPostStoreGetPostsBatchForChannelExport(t, ss) })
 	t.Run("PermanentDeleteBatch", func(t *testing.T) { testPostStorePermanentDeleteBatch(t, ss) })
 	t.Run("GetOldest", func(t *testing.T) { testPostStoreGetOldest(t, ss) })
 	t.Run("TestGetMaxPostSize", func(t *testing.T) { testGetMaxPostSize(t, ss) })
@@ -1679,6 +1682,81 @@ func testPostStoreGetPostsByIds(t *testing.T, ss store.Store) {
 	}
 }
 
+func testPostStoreGetPostsWithFileId(t *testing.T, ss store.Store) {
+	channelId := model.NewId()
+	fileId := model.NewId()
+
+	var postIds []string
+	for _, fileIds := range [][]string{{fileId}, {model.NewId(), fileId}, {model.NewId()}, {}} {
+		post := store.Must(ss.Post().Save(&model.Post{
+			ChannelId: channelId,
+			UserId:    model.NewId(),
+			Message:   "zz" + model.NewId(),
+			FileIds:   fileIds,
+		})).(*model.Post)
+		postIds = append(postIds, post.Id)
+	}
+	store.Must(ss.Post().Delete(postIds[1], model.GetMillis(), ""))
+
+	// Posts of other channels are not returned.
+	store.Must(ss.Post().Save(&model.Post{
+		ChannelId: model.NewId(),
+		UserId:    model.NewId(),
+		Message:   "zz" + model.NewId(),
+		FileIds:   []string{fileId},
+	}))
+
+	posts := store.Must(ss.Post().GetPostsWithFileId(channelId, fileId)).([]*model.Post)
+	var found []string
+	for _, post := range posts {
+		found = append(found, post.Id)
+	}
+	assert.ElementsMatch(t, postIds[:2], found)
+
+	posts = store.Must(ss.Post().GetPostsWithFileId(channelId, model.NewId())).([]*model.Post)
+	assert.Empty(t, posts)
+}
+
+func testPostStoreGetPostsBatchForChannelExport(t *testing.T, ss store.Store) {
+	channelId := model.NewId()
+	createAt := model.GetMillis()
+
+	var postIds []string
+	for i := 0; i < 5; i++ {
+		post := &model.Post{
+			ChannelId: channelId,
+			UserId:    model.NewId(),
+			Message:   "zz" + model.NewId(),
+			// Pairs of posts share a creation time, so that paging must also order by id.
+			CreateAt: createAt + int64(i/2),
+		}
+		post = store.Must(ss.Post().Save(post)).(*model.Post)
+		postIds = append(postIds, post.Id)
+	}
+	sort.Strings(postIds[0:2])
+	sort.Strings(postIds[2:4])
+
+	deleted := store.Must(ss.Post().Save(&model.Post{ChannelId: channelId, UserId: model.NewId(), Message: "deleted", CreateAt: createAt})).(*model.Post)
+	store.Must(ss.Post().Delete(deleted.Id, model.GetMillis(), ""))
+	store.Must(ss.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "other channel", CreateAt: createAt}))
+
+	var exported []string
+	afterCreateAt, afterId := int64(0), ""
+	for {
+		posts := store.Must(ss.Post().GetPostsBatchForChannelExport(channelId, afterCreateAt, afterId, 2)).([]*model.Post)
+		for _, post := range posts {
+			exported = append(exported, post.Id)
+			afterCreateAt, afterId = post.CreateAt, post.Id
+		}
+
+		if len(posts) < 2 {
+			break
+		}
+	}
+
+	assert.Equal(t, postIds, exported)
+}
+
 func testPostStoreGetPostsBatchForIndexing(t *testing.T, ss store.Store) {
 	c1 := &model.Channel{}
 	c1.TeamId = model.NewId()
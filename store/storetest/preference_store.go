@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/store"
@@ -17,11 +18,13 @@ func TestPreferenceStore(t *testing.T, ss store.Store) {
 	t.Run("PreferenceGet", func(t *testing.T) { testPreferenceGet(t, ss) })
 	t.Run("PreferenceGetCategory", func(t *testing.T) { testPreferenceGetCategory(t, ss) })
 	t.Run("PreferenceGetAll", func(t *testing.T) { testPreferenceGetAll(t, ss) })
+	t.Run("PreferenceGetCategoryAndName", func(t *testing.T) { testPreferenceGetCategoryAndName(t, ss) })
 	t.Run("PreferenceDeleteByUser", func(t *testing.T) { testPreferenceDeleteByUser(t, ss) })
 	t.Run("IsFeatureEnabled", func(t *testing.T) { testIsFeatureEnabled(t, ss) })
 	t.Run("PreferenceDelete", func(t *testing.T) { testPreferenceDelete(t, ss) })
 	t.Run("PreferenceDeleteCategory", func(t *testing.T) { testPreferenceDeleteCategory(t, ss) })
 	t.Run("PreferenceDeleteCategoryAndName", func(t *testing.T) { testPreferenceDeleteCategoryAndName(t, ss) })
+	t.Run("PreferenceDeleteAllInCategory", func(t *testing.T) { testPreferenceDeleteAllInCategory(t, ss) })
 	t.Run("PreferenceCleanupFlagsBatch", func(t *testing.T) { testPreferenceCleanupFlagsBatch(t, ss) })
 }
 
@@ -202,6 +205,59 @@ func testPreferenceGetAll(t *testing.T, ss store.Store) {
 	}
 }
 
+func testPreferenceGetCategoryAndName(t *testing.T, ss store.Store) {
+	category := model.NewId()
+	name := model.NewId()
+	userId := model.NewId()
+	userId2 := model.NewId()
+
+	preferences := model.Preferences{
+		{
+			UserId:   userId,
+			Category: category,
+			Name:     name,
+			Value:    "value1",
+		},
+		{
+			UserId:   userId2,
+			Category: category,
+			Name:     name,
+			Value:    "value2",
+		},
+		// same category, different name
+		{
+			UserId:   userId,
+			Category: category,
+			Name:     model.NewId(),
+			Value:    "value3",
+		},
+		// same name, different category
+		{
+			UserId:   userId2,
+			Category: model.NewId(),
+			Name:     name,
+			Value:    "value4",
+		},
+	}
+
+	store.Must(ss.Preference().Save(&preferences))
+
+	result := <-ss.Preference().GetCategoryAndName(category, name)
+	require.Nil(t, result.Err)
+
+	prefs := result.Data.(model.Preferences)
+	require.Len(t, prefs, 2)
+	for _, pref := range prefs {
+		assert.Equal(t, category, pref.Category)
+		assert.Equal(t, name, pref.Name)
+		assert.Contains(t, []string{userId, userId2}, pref.UserId)
+	}
+
+	result = <-ss.Preference().GetCategoryAndName(category, model.NewId())
+	require.Nil(t, result.Err)
+	assert.Len(t, result.Data.(model.Preferences), 0)
+}
+
 func testPreferenceDeleteByUser(t *testing.T, ss store.Store) {
 	userId := model.NewId()
 	category := model.PREFERENCE_CATEGORY_DIRECT_CHANNEL_SHOW
@@ -409,6 +465,30 @@ func testPreferenceDeleteCategoryAndName(t *testing.T, ss store.Store) {
 	}
 }
 
+func testPreferenceDeleteAllInCategory(t *testing.T, ss store.Store) {
+	category := model.NewId()
+	otherCategory := model.NewId()
+	userId := model.NewId()
+	userId2 := model.NewId()
+
+	store.Must(ss.Preference().Save(&model.Preferences{
+		{UserId: userId, Category: category, Name: "a", Value: "1"},
+		{UserId: userId, Category: category, Name: "b", Value: "2"},
+		{UserId: userId2, Category: category, Name: "a", Value: "3"},
+		{UserId: userId2, Category: otherCategory, Name: "a", Value: "4"},
+	}))
+
+	result := <-ss.Preference().DeleteAllInCategory(category)
+	require.Nil(t, result.Err)
+
+	prefs := store.Must(ss.Preference().GetAll(userId)).(model.Preferences)
+	assert.Len(t, prefs, 0)
+
+	prefs = store.Must(ss.Preference().GetAll(userId2)).(model.Preferences)
+	require.Len(t, prefs, 1)
+	assert.Equal(t, otherCategory, prefs[0].Category)
+}
+
 func testPreferenceCleanupFlagsBatch(t *testing.T, ss store.Store) {
 	category := model.PREFERENCE_CATEGORY_FLAGGED_POST
 	userId := model.NewId()
@@ -19,32 +19,39 @@ func NewStoreChannel(result store.StoreResult) store.StoreChannel {
 
 // Store can be used to provide mock stores for testing.
 type Store struct {
-	TeamStore                 mocks.TeamStore
-	ChannelStore              mocks.ChannelStore
-	PostStore                 mocks.PostStore
-	UserStore                 mocks.UserStore
-	AuditStore                mocks.AuditStore
-	ClusterDiscoveryStore     mocks.ClusterDiscoveryStore
-	ComplianceStore           mocks.ComplianceStore
-	SessionStore              mocks.SessionStore
-	OAuthStore                mocks.OAuthStore
-	SystemStore               mocks.SystemStore
-	WebhookStore              mocks.WebhookStore
-	CommandStore              mocks.CommandStore
-	CommandWebhookStore       mocks.CommandWebhookStore
-	PreferenceStore           mocks.PreferenceStore
-	LicenseStore              mocks.LicenseStore
-	TokenStore                mocks.TokenStore
-	EmojiStore                mocks.EmojiStore
-	StatusStore               mocks.StatusStore
-	FileInfoStore             mocks.FileInfoStore
-	ReactionStore             mocks.ReactionStore
-	JobStore                  mocks.JobStore
-	UserAccessTokenStore      mocks.UserAccessTokenStore
-	PluginStore               mocks.PluginStore
-	ChannelMemberHistoryStore mocks.ChannelMemberHistoryStore
-	RoleStore                 mocks.RoleStore
-	SchemeStore               mocks.SchemeStore
+	TeamStore                     mocks.TeamStore
+	ChannelStore                  mocks.ChannelStore
+	PostStore                     mocks.PostStore
+	UserStore                     mocks.UserStore
+	AuditStore                    mocks.AuditStore
+	ClusterDiscoveryStore         mocks.ClusterDiscoveryStore
+	ComplianceStore               mocks.ComplianceStore
+	SessionStore                  mocks.SessionStore
+	OAuthStore                    mocks.OAuthStore
+	SystemStore                   mocks.SystemStore
+	WebhookStore                  mocks.WebhookStore
+	CommandStore                  mocks.CommandStore
+	CommandWebhookStore           mocks.CommandWebhookStore
+	PreferenceStore               mocks.PreferenceStore
+	LicenseStore                  mocks.LicenseStore
+	TokenStore                    mocks.TokenStore
+	EmojiStore                    mocks.EmojiStore
+	StatusStore                   mocks.StatusStore
+	FileInfoStore                 mocks.FileInfoStore
+	ReactionStore                 mocks.ReactionStore
+	JobStore                      mocks.JobStore
+	UserAccessTokenStore          mocks.UserAccessTokenStore
+	PluginStore                   mocks.PluginStore
+	PluginSubscriptionStore       mocks.PluginSubscriptionStore
+	PostAcknowledgementStore      mocks.PostAcknowledgementStore
+	PluginRuntimeStateStore       mocks.PluginRuntimeStateStore
+	PluginPostMetadataStore       mocks.PluginPostMetadataStore
+	PluginOAuthConnectionStore    mocks.PluginOAuthConnectionStore
+	PluginAdminActionRequestStore mocks.PluginAdminActionRequestStore
+	PluginEventStore              mocks.PluginEventStore
+	ChannelMemberHistoryStore     mocks.ChannelMemberHistoryStore
+	RoleStore                     mocks.RoleStore
+	SchemeStore                   mocks.SchemeStore
 }
 
 func (s *Store) Team() store.TeamStore                         { return &s.TeamStore }
@@ -75,6 +82,27 @@ func (s *Store) Scheme() store.SchemeStore                     { return &s.Schem
 func (s *Store) ChannelMemberHistory() store.ChannelMemberHistoryStore {
 	return &s.ChannelMemberHistoryStore
 }
+func (s *Store) PluginSubscription() store.PluginSubscriptionStore {
+	return &s.PluginSubscriptionStore
+}
+func (s *Store) PostAcknowledgement() store.PostAcknowledgementStore {
+	return &s.PostAcknowledgementStore
+}
+func (s *Store) PluginRuntimeState() store.PluginRuntimeStateStore {
+	return &s.PluginRuntimeStateStore
+}
+func (s *Store) PluginPostMetadata() store.PluginPostMetadataStore {
+	return &s.PluginPostMetadataStore
+}
+func (s *Store) PluginOAuthConnection() store.PluginOAuthConnectionStore {
+	return &s.PluginOAuthConnectionStore
+}
+func (s *Store) PluginAdminActionRequest() store.PluginAdminActionRequestStore {
+	return &s.PluginAdminActionRequestStore
+}
+func (s *Store) PluginEvent() store.PluginEventStore {
+	return &s.PluginEventStore
+}
 func (s *Store) MarkSystemRanUnitTests()       { /* do nothing */ }
 func (s *Store) Close()                        { /* do nothing */ }
 func (s *Store) LockToMaster()                 { /* do nothing */ }
@@ -83,6 +111,8 @@ func (s *Store) DropAllTables()                { /* do nothing */ }
 func (s *Store) TotalMasterDbConnections() int { return 1 }
 func (s *Store) TotalReadDbConnections() int   { return 1 }
 func (s *Store) TotalSearchDbConnections() int { return 1 }
+func (s *Store) PingMaster() error             { return nil }
+func (s *Store) PingReplicas() error           { return nil }
 
 func (s *Store) AssertExpectations(t mock.TestingT) bool {
 	return mock.AssertExpectationsForObjects(t,
@@ -110,6 +140,13 @@ func (s *Store) AssertExpectations(t mock.TestingT) bool {
 		&s.UserAccessTokenStore,
 		&s.ChannelMemberHistoryStore,
 		&s.PluginStore,
+		&s.PluginSubscriptionStore,
+		&s.PostAcknowledgementStore,
+		&s.PluginRuntimeStateStore,
+		&s.PluginPostMetadataStore,
+		&s.PluginOAuthConnectionStore,
+		&s.PluginAdminActionRequestStore,
+		&s.PluginEventStore,
 		&s.RoleStore,
 		&s.SchemeStore,
 	)
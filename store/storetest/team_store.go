@@ -36,6 +36,8 @@ func TestTeamStore(t *testing.T, ss store.Store) {
 	t.Run("SaveTeamMemberMaxMembers", func(t *testing.T) { testSaveTeamMemberMaxMembers(t, ss) })
 	t.Run("GetTeamMember", func(t *testing.T) { testGetTeamMember(t, ss) })
 	t.Run("GetTeamMembersByIds", func(t *testing.T) { testGetTeamMembersByIds(t, ss) })
+	t.Run("GetTeamMembersModifiedSince", func(t *testing.T) { testGetTeamMembersModifiedSince(t, ss) })
+	t.Run("GetTeamMembersModifiedSinceOverlappingWindows", func(t *testing.T) { testGetTeamMembersModifiedSinceOverlappingWindows(t, ss) })
 	t.Run("MemberCount", func(t *testing.T) { testTeamStoreMemberCount(t, ss) })
 	t.Run("GetChannelUnreadsForAllTeams", func(t *testing.T) { testGetChannelUnreadsForAllTeams(t, ss) })
 	t.Run("GetChannelUnreadsForTeam", func(t *testing.T) { testGetChannelUnreadsForTeam(t, ss) })
@@ -896,6 +898,79 @@ func testGetTeamMembersByIds(t *testing.T, ss store.Store) {
 	}
 }
 
+func testGetTeamMembersModifiedSince(t *testing.T, ss store.Store) {
+	teamId := model.NewId()
+
+	m1 := store.Must(ss.Team().SaveMember(&model.TeamMember{TeamId: teamId, UserId: model.NewId()}, -1)).(*model.TeamMember)
+	assert.NotZero(t, m1.UpdateAt)
+	time.Sleep(2 * time.Millisecond)
+	m2 := store.Must(ss.Team().SaveMember(&model.TeamMember{TeamId: teamId, UserId: model.NewId()}, -1)).(*model.TeamMember)
+	store.Must(ss.Team().SaveMember(&model.TeamMember{TeamId: model.NewId(), UserId: m1.UserId}, -1))
+
+	members := store.Must(ss.Team().GetMembersModifiedSince(teamId, 0)).([]*model.TeamMember)
+	require.Len(t, members, 2)
+	assert.Equal(t, m1.UserId, members[0].UserId)
+	assert.Equal(t, m2.UserId, members[1].UserId)
+
+	members = store.Must(ss.Team().GetMembersModifiedSince(teamId, m1.UpdateAt)).([]*model.TeamMember)
+	require.Len(t, members, 1)
+	assert.Equal(t, m2.UserId, members[0].UserId)
+
+	// Leaving the team is a modification
+	time.Sleep(2 * time.Millisecond)
+	m1.DeleteAt = model.GetMillis()
+	m1 = store.Must(ss.Team().UpdateMember(m1)).(*model.TeamMember)
+
+	members = store.Must(ss.Team().GetMembersModifiedSince(teamId, m2.UpdateAt)).([]*model.TeamMember)
+	require.Len(t, members, 1)
+	assert.Equal(t, m1.UserId, members[0].UserId)
+	assert.NotZero(t, members[0].DeleteAt)
+	assert.Equal(t, m1.UpdateAt, members[0].UpdateAt)
+
+	members = store.Must(ss.Team().GetMembersModifiedSince(teamId, m1.UpdateAt)).([]*model.TeamMember)
+	assert.Len(t, members, 0)
+}
+
+func testGetTeamMembersModifiedSinceOverlappingWindows(t *testing.T, ss store.Store) {
+	teamId := model.NewId()
+
+	// Simulate a sync that runs after every change, passing the newest timestamp it has seen less a
+	// margin, and keeps the latest version of each membership it is given.
+	const margin = 10
+	var cursor int64
+	synced := make(map[string]*model.TeamMember)
+	sync := func() {
+		members := store.Must(ss.Team().GetMembersModifiedSince(teamId, cursor-margin)).([]*model.TeamMember)
+		for _, member := range members {
+			synced[member.UserId] = member
+			if member.UpdateAt > cursor {
+				cursor = member.UpdateAt
+			}
+		}
+	}
+
+	var userIds []string
+	for i := 0; i < 5; i++ {
+		member := store.Must(ss.Team().SaveMember(&model.TeamMember{TeamId: teamId, UserId: model.NewId()}, -1)).(*model.TeamMember)
+		userIds = append(userIds, member.UserId)
+		sync()
+
+		// Changes landing within the same millisecond as the cursor are still seen
+		if i%2 == 1 {
+			member.DeleteAt = model.GetMillis()
+			store.Must(ss.Team().UpdateMember(member))
+			sync()
+		}
+	}
+
+	require.Len(t, synced, len(userIds))
+	for _, userId := range userIds {
+		expected := store.Must(ss.Team().GetMember(teamId, userId)).(*model.TeamMember)
+		assert.Equal(t, expected.DeleteAt, synced[userId].DeleteAt)
+		assert.Equal(t, expected.UpdateAt, synced[userId].UpdateAt)
+	}
+}
+
 func testTeamStoreMemberCount(t *testing.T, ss store.Store) {
 	u1 := &model.User{}
 	u1.Email = model.NewId()
@@ -5,16 +5,101 @@ package utils
 
 import (
 	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
 	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// ErrExtractedSizeExceeded is returned by ExtractTarGzWithLimit and ExtractZipWithLimit when the
+// uncompressed contents of the archive exceed the given limit.
+var ErrExtractedSizeExceeded = errors.New("ExtractTarGz: extracted size exceeds limit")
+
+// UnsafeArchiveEntryError is returned when extracting an archive containing an entry that could write
+// outside of the destination directory, or that is neither a file, a directory nor a symlink.
+type UnsafeArchiveEntryError struct {
+	Name   string
+	Reason string
+}
+
+func (e *UnsafeArchiveEntryError) Error() string {
+	return fmt.Sprintf("unsafe archive entry %q: %s", e.Name, e.Reason)
+}
+
+// checkArchiveEntryName returns an UnsafeArchiveEntryError if the entry's name is absolute or
+// traverses upwards out of the destination directory.
+func checkArchiveEntryName(name string) error {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return &UnsafeArchiveEntryError{Name: name, Reason: "path is absolute"}
+	}
+
+	if PathTraversesUpward(name) {
+		return &UnsafeArchiveEntryError{Name: name, Reason: "path attempts to traverse upwards"}
+	}
+
+	return nil
+}
+
+// checkArchiveSymlink returns an UnsafeArchiveEntryError if the symlink entry points outside of the
+// destination directory.
+func checkArchiveSymlink(name, target string) error {
+	target = strings.Replace(target, "\\", "/", -1)
+	if filepath.IsAbs(target) || strings.HasPrefix(target, "/") || PathTraversesUpward(filepath.Join(filepath.Dir(name), target)) {
+		return &UnsafeArchiveEntryError{Name: name, Reason: "symlink points outside of the archive"}
+	}
+
+	return nil
+}
+
+// The signatures with which a .zip file may begin: that of its first entry, or that of the end of
+// its central directory if it has no entries.
+var zipSignatures = [][]byte{[]byte("PK\x03\x04"), []byte("PK\x05\x06")}
+
+// zipCreatorUnix is the host system recorded in a .zip file's entries when they were written on a
+// Unix system, and so have Unix permissions.
+const zipCreatorUnix = 3
+
+// zipSymlinkMaxLength bounds the target read from a .zip file's symlink entry.
+const zipSymlinkMaxLength = 4096
+
+// ExtractArchiveWithLimit extracts the .zip or .tar.gz file read from the given reader, telling them
+// apart by their first bytes, into the destination directory. It otherwise behaves like
+// ExtractTarGzWithLimit.
+func ExtractArchiveWithLimit(archive io.Reader, dst string, maxSize int64) error {
+	reader := bufio.NewReader(archive)
+
+	// Anything not recognised as a .zip file is left for ExtractTarGz to reject.
+	signature, _ := reader.Peek(4)
+	for _, zipSignature := range zipSignatures {
+		if bytes.Equal(signature, zipSignature) {
+			return ExtractZipWithLimit(reader, dst, maxSize)
+		}
+	}
+
+	return ExtractTarGzWithLimit(reader, dst, maxSize)
+}
+
 // ExtractTarGz takes in an io.Reader containing the bytes for a .tar.gz file and
 // a destination string to extract to.
+//
+// Entries with absolute paths or paths traversing upwards, symlinks pointing outside of the archive,
+// hard links, and device or FIFO entries are refused with an UnsafeArchiveEntryError. Symlinks
+// pointing within the archive are skipped, since they could otherwise redirect later entries.
 func ExtractTarGz(gzipStream io.Reader, dst string) error {
+	return ExtractTarGzWithLimit(gzipStream, dst, 0)
+}
+
+// ExtractTarGzWithLimit behaves like ExtractTarGz, but stops and returns ErrExtractedSizeExceeded
+// once more than maxSize bytes of file contents have been extracted. A maxSize of 0 disables the
+// limit.
+func ExtractTarGzWithLimit(gzipStream io.Reader, dst string, maxSize int64) error {
 	uncompressedStream, err := gzip.NewReader(gzipStream)
 	if err != nil {
 		return fmt.Errorf("ExtractTarGz: NewReader failed: %s", err.Error())
@@ -22,6 +107,7 @@ func ExtractTarGz(gzipStream io.Reader, dst string) error {
 	defer uncompressedStream.Close()
 
 	tarReader := tar.NewReader(uncompressedStream)
+	var extractedSize int64
 
 	for {
 		header, err := tarReader.Next()
@@ -34,21 +120,17 @@ func ExtractTarGz(gzipStream io.Reader, dst string) error {
 			return fmt.Errorf("ExtractTarGz: Next() failed: %s", err.Error())
 		}
 
+		if err := checkArchiveEntryName(header.Name); err != nil {
+			return err
+		}
+
 		switch header.Typeflag {
 		case tar.TypeDir:
-			if PathTraversesUpward(header.Name) {
-				return fmt.Errorf("ExtractTarGz: path attempts to traverse upwards")
-			}
-
 			path := filepath.Join(dst, header.Name)
 			if err := os.Mkdir(path, 0744); err != nil && !os.IsExist(err) {
 				return fmt.Errorf("ExtractTarGz: Mkdir() failed: %s", err.Error())
 			}
 		case tar.TypeReg:
-			if PathTraversesUpward(header.Name) {
-				return fmt.Errorf("ExtractTarGz: path attempts to traverse upwards")
-			}
-
 			path := filepath.Join(dst, header.Name)
 			dir := filepath.Dir(path)
 
@@ -61,9 +143,29 @@ func ExtractTarGz(gzipStream io.Reader, dst string) error {
 				return fmt.Errorf("ExtractTarGz: Create() failed: %s", err.Error())
 			}
 			defer outFile.Close()
-			if _, err := io.Copy(outFile, tarReader); err != nil {
+
+			var src io.Reader = tarReader
+			if maxSize > 0 {
+				src = io.LimitReader(tarReader, maxSize-extractedSize+1)
+			}
+
+			written, err := io.Copy(outFile, src)
+			if err != nil {
 				return fmt.Errorf("ExtractTarGz: Copy() failed: %s", err.Error())
 			}
+
+			extractedSize += written
+			if maxSize > 0 && extractedSize > maxSize {
+				return ErrExtractedSizeExceeded
+			}
+		case tar.TypeSymlink:
+			if err := checkArchiveSymlink(header.Name, header.Linkname); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			return &UnsafeArchiveEntryError{Name: header.Name, Reason: "hard links are not supported"}
+		case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+			return &UnsafeArchiveEntryError{Name: header.Name, Reason: "device and FIFO entries are not supported"}
 		default:
 			return fmt.Errorf(
 				"ExtractTarGz: unknown type: %v in %v",
@@ -74,3 +176,138 @@ func ExtractTarGz(gzipStream io.Reader, dst string) error {
 
 	return nil
 }
+
+// ExtractZip takes in an io.Reader containing the bytes for a .zip file and a destination string to
+// extract to.
+func ExtractZip(zipStream io.Reader, dst string) error {
+	return ExtractZipWithLimit(zipStream, dst, 0)
+}
+
+// ExtractZipWithLimit behaves like ExtractZip, but stops and returns ErrExtractedSizeExceeded once
+// more than maxSize bytes of file contents have been extracted. A maxSize of 0 disables the limit.
+//
+// Since the contents of a .zip file are listed at its end, the file is first copied to a temporary
+// file. Backslashes in the names of its entries, as written by some Windows tools, are taken to be
+// path separators. Unsafe entries are refused and symlinks skipped as by ExtractTarGz.
+func ExtractZipWithLimit(zipStream io.Reader, dst string, maxSize int64) error {
+	tmpFile, err := ioutil.TempFile("", "extract_zip")
+	if err != nil {
+		return fmt.Errorf("ExtractZip: TempFile() failed: %s", err.Error())
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	size, err := io.Copy(tmpFile, zipStream)
+	if err != nil {
+		return fmt.Errorf("ExtractZip: Copy() failed: %s", err.Error())
+	}
+
+	zipReader, err := zip.NewReader(tmpFile, size)
+	if err != nil {
+		return fmt.Errorf("ExtractZip: NewReader failed: %s", err.Error())
+	}
+
+	var extractedSize int64
+
+	for _, file := range zipReader.File {
+		name := strings.Replace(file.Name, "\\", "/", -1)
+		if err := checkArchiveEntryName(name); err != nil {
+			return err
+		}
+
+		path := filepath.Join(dst, name)
+		mode := file.Mode()
+
+		switch {
+		case mode.IsDir() || strings.HasSuffix(name, "/"):
+			if err := os.MkdirAll(path, 0744); err != nil {
+				return fmt.Errorf("ExtractZip: MkdirAll() failed: %s", err.Error())
+			}
+		case mode.IsRegular():
+			if err := os.MkdirAll(filepath.Dir(path), 0744); err != nil {
+				return fmt.Errorf("ExtractZip: MkdirAll() failed: %s", err.Error())
+			}
+
+			// Archives written on Windows do not record whether files are executable.
+			if file.CreatorVersion>>8 != zipCreatorUnix {
+				mode = 0744
+			}
+
+			var limit int64
+			if maxSize > 0 {
+				limit = maxSize - extractedSize + 1
+			}
+
+			written, err := extractZipFile(file, path, mode.Perm(), limit)
+			if err != nil {
+				return err
+			}
+
+			extractedSize += written
+			if maxSize > 0 && extractedSize > maxSize {
+				return ErrExtractedSizeExceeded
+			}
+		case mode&os.ModeSymlink != 0:
+			target, err := readZipSymlink(file)
+			if err != nil {
+				return err
+			}
+			if err := checkArchiveSymlink(name, target); err != nil {
+				return err
+			}
+		case mode&(os.ModeDevice|os.ModeCharDevice|os.ModeNamedPipe) != 0:
+			return &UnsafeArchiveEntryError{Name: name, Reason: "device and FIFO entries are not supported"}
+		default:
+			return fmt.Errorf(
+				"ExtractZip: unknown type: %v in %v",
+				mode.String(),
+				file.Name)
+		}
+	}
+
+	return nil
+}
+
+// readZipSymlink returns the target of the .zip file's symlink entry, which is stored as its contents.
+func readZipSymlink(file *zip.File) (string, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("ExtractZip: Open() failed: %s", err.Error())
+	}
+	defer reader.Close()
+
+	target, err := ioutil.ReadAll(io.LimitReader(reader, zipSymlinkMaxLength))
+	if err != nil {
+		return "", fmt.Errorf("ExtractZip: ReadAll() failed: %s", err.Error())
+	}
+
+	return string(target), nil
+}
+
+// extractZipFile writes the contents of the .zip file's entry to the given path, reading no more
+// than limit bytes of them unless limit is 0, and returns the number of bytes written.
+func extractZipFile(file *zip.File, path string, perm os.FileMode, limit int64) (int64, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return 0, fmt.Errorf("ExtractZip: Open() failed: %s", err.Error())
+	}
+	defer reader.Close()
+
+	outFile, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return 0, fmt.Errorf("ExtractZip: Create() failed: %s", err.Error())
+	}
+	defer outFile.Close()
+
+	var src io.Reader = reader
+	if limit > 0 {
+		src = io.LimitReader(reader, limit)
+	}
+
+	written, err := io.Copy(outFile, src)
+	if err != nil {
+		return written, fmt.Errorf("ExtractZip: Copy() failed: %s", err.Error())
+	}
+
+	return written, nil
+}
@@ -0,0 +1,399 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTarGz(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+
+	for name, contents := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0600,
+			Size:     int64(len(contents)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write(contents)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+
+	return buf.Bytes()
+}
+
+// makeZip builds a .zip file containing the given files as if written on Windows, so that their
+// names are used as given and they have no Unix permissions.
+func makeZip(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for name, contents := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+		require.NoError(t, err)
+		_, err = w.Write(contents)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func TestExtractTarGzWithLimit(t *testing.T) {
+	archive := makeTarGz(t, map[string][]byte{
+		"plugin/a.txt": bytes.Repeat([]byte("a"), 100),
+		"plugin/b.txt": bytes.Repeat([]byte("b"), 100),
+	})
+
+	t.Run("within limit", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "extract")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		assert.NoError(t, ExtractTarGzWithLimit(bytes.NewReader(archive), dir, 200))
+	})
+
+	t.Run("exceeds limit", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "extract")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		assert.Equal(t, ErrExtractedSizeExceeded, ExtractTarGzWithLimit(bytes.NewReader(archive), dir, 150))
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "extract")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		assert.NoError(t, ExtractTarGz(bytes.NewReader(archive), dir))
+	})
+}
+
+func TestExtractZipWithLimit(t *testing.T) {
+	archive := makeZip(t, map[string][]byte{
+		"plugin/a.txt": bytes.Repeat([]byte("a"), 100),
+		"plugin/b.txt": bytes.Repeat([]byte("b"), 100),
+	})
+
+	t.Run("within limit", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "extract")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		require.NoError(t, ExtractZipWithLimit(bytes.NewReader(archive), dir, 200))
+
+		contents, err := ioutil.ReadFile(filepath.Join(dir, "plugin", "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, bytes.Repeat([]byte("a"), 100), contents)
+	})
+
+	t.Run("exceeds limit", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "extract")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		assert.Equal(t, ErrExtractedSizeExceeded, ExtractZipWithLimit(bytes.NewReader(archive), dir, 150))
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "extract")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		assert.NoError(t, ExtractZip(bytes.NewReader(archive), dir))
+	})
+}
+
+func TestExtractCompressionBomb(t *testing.T) {
+	// A few hundred kilobytes of compressed zeros expanding to far more than the limit.
+	bomb := map[string][]byte{"plugin/main.js": make([]byte, 256*1024*1024)}
+
+	for name, archive := range map[string][]byte{
+		"tar.gz": makeTarGz(t, bomb),
+		"zip":    makeZip(t, bomb),
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "extract")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			require.True(t, len(archive) < 1024*1024)
+			assert.Equal(t, ErrExtractedSizeExceeded, ExtractArchiveWithLimit(bytes.NewReader(archive), dir, 1024*1024))
+
+			// No more than the limit was written.
+			info, err := os.Stat(filepath.Join(dir, "plugin", "main.js"))
+			require.NoError(t, err)
+			assert.True(t, info.Size() <= 1024*1024+1)
+		})
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	t.Run("backslash separators", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "extract")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		require.NoError(t, ExtractZip(bytes.NewReader(makeZip(t, map[string][]byte{
+			"plugin\\":                   nil,
+			"plugin\\plugin.json":        []byte("{}"),
+			"plugin\\server\\plugin.exe": []byte("executable"),
+		})), dir))
+
+		contents, err := ioutil.ReadFile(filepath.Join(dir, "plugin", "server", "plugin.exe"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("executable"), contents)
+
+		// Files from archives without Unix permissions can be executed.
+		info, err := os.Stat(filepath.Join(dir, "plugin", "server", "plugin.exe"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0744), info.Mode().Perm())
+
+		entries, err := ioutil.ReadDir(dir)
+		require.NoError(t, err)
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, "plugin", entries[0].Name())
+		}
+	})
+
+	t.Run("unix permissions", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "extract")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		header := &zip.FileHeader{Name: "plugin.json"}
+		header.SetMode(0600)
+		w, err := zw.CreateHeader(header)
+		require.NoError(t, err)
+		_, err = w.Write([]byte("{}"))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+
+		require.NoError(t, ExtractZip(&buf, dir))
+
+		info, err := os.Stat(filepath.Join(dir, "plugin.json"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	for _, name := range []string{"../evil.txt", "..\\evil.txt", "plugin\\..\\..\\evil.txt"} {
+		t.Run("traverses upwards "+name, func(t *testing.T) {
+			parent, err := ioutil.TempDir("", "extract")
+			require.NoError(t, err)
+			defer os.RemoveAll(parent)
+			dir := filepath.Join(parent, "dst")
+			require.NoError(t, os.Mkdir(dir, 0700))
+
+			assert.Error(t, ExtractZip(bytes.NewReader(makeZip(t, map[string][]byte{name: []byte("evil")})), dir))
+
+			_, err = os.Stat(filepath.Join(parent, "evil.txt"))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+
+	t.Run("not a zip file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "extract")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		assert.Error(t, ExtractZip(bytes.NewReader([]byte("not a zip file")), dir))
+	})
+}
+
+func TestExtractArchiveWithLimit(t *testing.T) {
+	files := map[string][]byte{"plugin/plugin.json": []byte("{}")}
+
+	for name, archive := range map[string][]byte{
+		"tar.gz":    makeTarGz(t, files),
+		"zip":       makeZip(t, files),
+		"empty zip": makeZip(t, nil),
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "extract")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			require.NoError(t, ExtractArchiveWithLimit(bytes.NewReader(archive), dir, 0))
+
+			if name != "empty zip" {
+				contents, err := ioutil.ReadFile(filepath.Join(dir, "plugin", "plugin.json"))
+				require.NoError(t, err)
+				assert.Equal(t, []byte("{}"), contents)
+			}
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "extract")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		assert.Error(t, ExtractArchiveWithLimit(bytes.NewReader([]byte("PK")), dir, 0))
+	})
+}
+
+// testArchiveEntry describes an entry of a crafted archive. Symlinks and hard links point to target.
+type testArchiveEntry struct {
+	name     string
+	typeflag byte
+	target   string
+	contents string
+}
+
+func makeTarGzEntries(t *testing.T, entries []testArchiveEntry) []byte {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+
+	for _, entry := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     entry.name,
+			Linkname: entry.target,
+			Mode:     0600,
+			Size:     int64(len(entry.contents)),
+			Typeflag: entry.typeflag,
+		}))
+		_, err := tw.Write([]byte(entry.contents))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+
+	return buf.Bytes()
+}
+
+// makeZipEntries builds a .zip file of the given entries, storing the targets of symlinks as their
+// contents as Unix tools do. Zip files cannot hold hard links.
+func makeZipEntries(t *testing.T, entries []testArchiveEntry) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name}
+		contents := entry.contents
+		switch entry.typeflag {
+		case tar.TypeReg:
+			header.SetMode(0600)
+		case tar.TypeSymlink:
+			header.SetMode(os.ModeSymlink | 0777)
+			contents = entry.target
+		case tar.TypeChar:
+			header.SetMode(os.ModeDevice | os.ModeCharDevice | 0600)
+		case tar.TypeBlock:
+			header.SetMode(os.ModeDevice | 0600)
+		case tar.TypeFifo:
+			header.SetMode(os.ModeNamedPipe | 0600)
+		default:
+			require.Failf(t, "unsupported entry", "%v", entry.typeflag)
+		}
+
+		w, err := zw.CreateHeader(header)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func TestExtractUnsafeEntries(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Entry testArchiveEntry
+		NoZip bool
+	}{
+		"traverses upwards":           {Entry: testArchiveEntry{name: "../../etc/cron.d/x", typeflag: tar.TypeReg, contents: "evil"}},
+		"traverses upwards in plugin": {Entry: testArchiveEntry{name: "plugin/../../x", typeflag: tar.TypeReg, contents: "evil"}},
+		"absolute path":               {Entry: testArchiveEntry{name: "/etc/cron.d/x", typeflag: tar.TypeReg, contents: "evil"}},
+		"absolute symlink":            {Entry: testArchiveEntry{name: "plugin/link", typeflag: tar.TypeSymlink, target: "/etc/passwd"}},
+		"symlink traversing upwards":  {Entry: testArchiveEntry{name: "plugin/link", typeflag: tar.TypeSymlink, target: "../../etc"}},
+		"hard link":                   {Entry: testArchiveEntry{name: "plugin/link", typeflag: tar.TypeLink, target: "plugin/plugin.json"}, NoZip: true},
+		"character device":            {Entry: testArchiveEntry{name: "plugin/tty", typeflag: tar.TypeChar}},
+		"block device":                {Entry: testArchiveEntry{name: "plugin/sda", typeflag: tar.TypeBlock}},
+		"fifo":                        {Entry: testArchiveEntry{name: "plugin/fifo", typeflag: tar.TypeFifo}},
+	} {
+		entries := []testArchiveEntry{
+			{name: "plugin/plugin.json", typeflag: tar.TypeReg, contents: "{}"},
+			tc.Entry,
+		}
+
+		archives := map[string][]byte{"tar.gz": makeTarGzEntries(t, entries)}
+		if !tc.NoZip {
+			archives["zip"] = makeZipEntries(t, entries)
+		}
+
+		for format, archive := range archives {
+			t.Run(name+" "+format, func(t *testing.T) {
+				parent, err := ioutil.TempDir("", "extract")
+				require.NoError(t, err)
+				defer os.RemoveAll(parent)
+				dir := filepath.Join(parent, "dst")
+				require.NoError(t, os.Mkdir(dir, 0700))
+
+				err = ExtractArchiveWithLimit(bytes.NewReader(archive), dir, 0)
+				require.Error(t, err)
+				unsafeErr, ok := err.(*UnsafeArchiveEntryError)
+				require.True(t, ok, err.Error())
+				assert.Equal(t, tc.Entry.name, unsafeErr.Name)
+				assert.Contains(t, err.Error(), tc.Entry.name)
+
+				// Nothing was written outside of the destination.
+				entries, err := ioutil.ReadDir(parent)
+				require.NoError(t, err)
+				assert.Len(t, entries, 1)
+			})
+		}
+	}
+}
+
+func TestExtractInternalSymlink(t *testing.T) {
+	entries := []testArchiveEntry{
+		{name: "plugin/plugin.json", typeflag: tar.TypeReg, contents: "{}"},
+		{name: "plugin/server/link", typeflag: tar.TypeSymlink, target: "../plugin.json"},
+		{name: "plugin/server/main", typeflag: tar.TypeReg, contents: "main"},
+	}
+
+	for format, archive := range map[string][]byte{
+		"tar.gz": makeTarGzEntries(t, entries),
+		"zip":    makeZipEntries(t, entries),
+	} {
+		t.Run(format, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "extract")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			require.NoError(t, ExtractArchiveWithLimit(bytes.NewReader(archive), dir, 0))
+
+			// The symlink is skipped, while the entries around it are extracted.
+			_, err = os.Lstat(filepath.Join(dir, "plugin", "server", "link"))
+			assert.True(t, os.IsNotExist(err))
+
+			contents, err := ioutil.ReadFile(filepath.Join(dir, "plugin", "server", "main"))
+			require.NoError(t, err)
+			assert.Equal(t, []byte("main"), contents)
+		})
+	}
+}
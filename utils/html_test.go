@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -102,6 +103,21 @@ func TestHTMLTemplate_RenderError(t *testing.T) {
 	assert.Equal(t, "foo", buf.String())
 }
 
+func TestPostMessageTemplate(t *testing.T) {
+	templatesDir, found := FindDir("templates")
+	require.True(t, found)
+
+	templates, err := template.ParseGlob(filepath.Join(templatesDir, "*.html"))
+	require.NoError(t, err)
+
+	expected, err := ioutil.ReadFile("testdata/post_message.html")
+	require.NoError(t, err)
+
+	htmlTemplate := NewHTMLTemplate(templates, "post_message")
+	htmlTemplate.Props["PostMessage"] = "Hello <b>@alice</b> & **team**\n> quoted"
+	assert.Equal(t, strings.TrimSuffix(string(expected), "\n"), htmlTemplate.Render())
+}
+
 func TestTranslateAsHtml(t *testing.T) {
 	assert.EqualValues(t, "<p><strong>&lt;i&gt;foo&lt;/i&gt;</strong></p>", TranslateAsHtml(i18n.TranslateFunc(htmlTestTranslationBundle.MustTfunc("en")), "foo.bold", map[string]interface{}{
 		"Foo": "<i>foo</i>",
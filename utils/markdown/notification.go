@@ -0,0 +1,78 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package markdown
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// NotificationEllipsis is appended to messages shortened by TruncateForNotification.
+const NotificationEllipsis = "..."
+
+// StripMarkdown returns the text of the given markdown without its formatting. Blocks are separated
+// by newlines, soft line breaks are replaced by spaces, and links and images are replaced by their
+// text.
+func StripMarkdown(markdown string) string {
+	document, referenceDefinitions := Parse(markdown)
+
+	var lines []string
+	InspectBlock(document, func(block Block) bool {
+		switch v := block.(type) {
+		case *Paragraph:
+			lines = append(lines, strings.TrimSpace(stripInlines(v.ParseInlines(referenceDefinitions))))
+		case *FencedCode:
+			lines = append(lines, strings.TrimSuffix(v.Code(), "\n"))
+		case *IndentedCode:
+			lines = append(lines, strings.TrimSuffix(v.Code(), "\n"))
+		}
+		return true
+	})
+
+	return strings.Join(lines, "\n")
+}
+
+func stripInlines(inlines []Inline) string {
+	var result string
+	for _, inline := range inlines {
+		switch v := inline.(type) {
+		case *Text:
+			result += v.Text
+		case *CodeSpan:
+			result += v.Code
+		case *SoftLineBreak:
+			result += " "
+		case *HardLineBreak:
+			result += "\n"
+		case *InlineLink:
+			result += stripInlines(v.Children)
+		case *InlineImage:
+			result += stripInlines(v.Children)
+		case *ReferenceLink:
+			result += stripInlines(v.Children)
+		case *ReferenceImage:
+			result += stripInlines(v.Children)
+		case *Autolink:
+			result += stripInlines(v.Children)
+		}
+	}
+	return result
+}
+
+// TruncateForNotification shortens message to at most maxRunes runes, including the trailing
+// ellipsis added when it is shortened. Trailing whitespace is removed before the ellipsis is added.
+// A maxRunes of zero or less leaves the message unchanged.
+func TruncateForNotification(message string, maxRunes int) string {
+	if maxRunes <= 0 || utf8.RuneCountInString(message) <= maxRunes {
+		return message
+	}
+
+	ellipsis := NotificationEllipsis
+	if maxRunes <= len(ellipsis) {
+		ellipsis = ""
+	}
+
+	runes := []rune(message)[:maxRunes-utf8.RuneCountInString(ellipsis)]
+	return strings.TrimRightFunc(string(runes), isWhitespace) + ellipsis
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package markdown
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripMarkdown(t *testing.T) {
+	input, err := ioutil.ReadFile("testdata/notification.md")
+	require.NoError(t, err)
+	expected, err := ioutil.ReadFile("testdata/notification.stripped.txt")
+	require.NoError(t, err)
+
+	assert.Equal(t, strings.TrimSuffix(string(expected), "\n"), StripMarkdown(string(input)))
+}
+
+func TestTruncateForNotification(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Message  string
+		MaxRunes int
+		Expected string
+	}{
+		"short":                 {"hello", 10, "hello"},
+		"exact":                 {"hello", 5, "hello"},
+		"truncated":             {"hello world", 8, "hello..."},
+		"trailing whitespace":   {"hello world", 9, "hello..."},
+		"multibyte":             {"héllo wörld", 7, "héll..."},
+		"no room for ellipsis":  {"hello", 3, "hel"},
+		"unlimited":             {"hello", 0, "hello"},
+		"negative is unlimited": {"hello", -1, "hello"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, TruncateForNotification(tc.Message, tc.MaxRunes))
+		})
+	}
+}
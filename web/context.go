@@ -330,6 +330,18 @@ func (c *Context) RequirePluginId() *Context {
 	return c
 }
 
+func (c *Context) RequirePluginKey() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.PluginKey) == 0 {
+		c.SetInvalidUrlParam("key")
+	}
+
+	return c
+}
+
 func (c *Context) RequireReportId() *Context {
 	if c.Err != nil {
 		return c
@@ -461,6 +473,28 @@ func (c *Context) RequireCommandId() *Context {
 	return c
 }
 
+func (c *Context) RequireAdminActionRequestId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.AdminActionRequestId) != 26 {
+		c.SetInvalidUrlParam("admin_action_request_id")
+	}
+	return c
+}
+
+func (c *Context) RequireWebappReplyId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if len(c.Params.WebappReplyId) != 26 {
+		c.SetInvalidUrlParam("webapp_reply_id")
+	}
+	return c
+}
+
 func (c *Context) RequireJobId() *Context {
 	if c.Err != nil {
 		return c
@@ -531,7 +565,7 @@ func (c *Context) RequireRoleName() *Context {
 func (c *Context) ToPluginContext() *plugin.Context {
 	return &plugin.Context{
 		//sessionId: c.Session.Id,
-		//requestId: c.RequestId,
+		RequestId: c.RequestId,
 		//userIp: c.IpAddress,
 	}
 }
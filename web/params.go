@@ -20,39 +20,42 @@ const (
 )
 
 type Params struct {
-	UserId         string
-	TeamId         string
-	InviteId       string
-	TokenId        string
-	ChannelId      string
-	PostId         string
-	FileId         string
-	Filename       string
-	PluginId       string
-	CommandId      string
-	HookId         string
-	ReportId       string
-	EmojiId        string
-	AppId          string
-	Email          string
-	Username       string
-	TeamName       string
-	ChannelName    string
-	PreferenceName string
-	EmojiName      string
-	Category       string
-	Service        string
-	JobId          string
-	JobType        string
-	ActionId       string
-	RoleId         string
-	RoleName       string
-	SchemeId       string
-	Scope          string
-	Page           int
-	PerPage        int
-	LogsPerPage    int
-	Permanent      bool
+	UserId               string
+	TeamId               string
+	InviteId             string
+	TokenId              string
+	ChannelId            string
+	PostId               string
+	FileId               string
+	Filename             string
+	PluginId             string
+	PluginKey            string
+	CommandId            string
+	HookId               string
+	ReportId             string
+	EmojiId              string
+	AppId                string
+	Email                string
+	Username             string
+	TeamName             string
+	ChannelName          string
+	PreferenceName       string
+	EmojiName            string
+	Category             string
+	Service              string
+	JobId                string
+	JobType              string
+	ActionId             string
+	AdminActionRequestId string
+	WebappReplyId        string
+	RoleId               string
+	RoleName             string
+	SchemeId             string
+	Scope                string
+	Page                 int
+	PerPage              int
+	LogsPerPage          int
+	Permanent            bool
 }
 
 func ParamsFromRequest(r *http.Request) *Params {
@@ -97,6 +100,10 @@ func ParamsFromRequest(r *http.Request) *Params {
 		params.PluginId = val
 	}
 
+	if val, ok := props["key"]; ok {
+		params.PluginKey = val
+	}
+
 	if val, ok := props["command_id"]; ok {
 		params.CommandId = val
 	}
@@ -149,6 +156,14 @@ func ParamsFromRequest(r *http.Request) *Params {
 		params.EmojiName = val
 	}
 
+	if val, ok := props["admin_action_request_id"]; ok {
+		params.AdminActionRequestId = val
+	}
+
+	if val, ok := props["webapp_reply_id"]; ok {
+		params.WebappReplyId = val
+	}
+
 	if val, ok := props["job_id"]; ok {
 		params.JobId = val
 	}
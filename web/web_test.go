@@ -142,7 +142,7 @@ func TestMain(m *testing.M) {
 	}
 
 	testStoreContainer = container
-	testStore = &persistentTestStore{store.NewLayeredStore(sqlstore.NewSqlSupplier(*settings, nil), nil, nil)}
+	testStore = &persistentTestStore{store.NewLayeredStore(sqlstore.NewSqlSupplier(*settings, nil), nil, nil, model.PLUGIN_SETTINGS_DEFAULT_KV_CACHE_SIZE, model.PLUGIN_SETTINGS_DEFAULT_KV_CACHE_SECONDS, 0)}
 
 	defer func() {
 		StopTestStore()